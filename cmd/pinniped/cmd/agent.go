@@ -0,0 +1,319 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/sync/singleflight"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	// agentSocketEnvVarName is the name of the env var which may provide the path of the socket of a running
+	// "pinniped agent", when the --agent-socket flag of "pinniped login oidc" is not specified.
+	agentSocketEnvVarName = "PINNIPED_AGENT_SOCKET"
+
+	// agentCredentialPath is the path of the agent's endpoint which returns a cluster credential.
+	agentCredentialPath = "/v1/credential"
+
+	// agentMinimumRemainingLifetime is how long a credential held by the agent must remain valid for the agent to
+	// return it. Credentials which expire sooner are renewed ahead of time, so that clients rarely wait for a login.
+	agentMinimumRemainingLifetime = time.Minute
+)
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(agentCommand(agentCommandRealDeps()))
+}
+
+type agentCommandDeps struct {
+	loginDeps oidcLoginCommandDeps
+	now       func() time.Time
+}
+
+func agentCommandRealDeps() agentCommandDeps {
+	return agentCommandDeps{
+		loginDeps: oidcLoginCommandRealDeps(),
+		now:       time.Now,
+	}
+}
+
+// agentCredentialRequest is sent by "pinniped login oidc" to the agent. It describes the login which the agent should
+// perform on behalf of the client.
+type agentCredentialRequest struct {
+	// Args are the arguments of "pinniped login oidc", excluding those which only apply to the client.
+	Args []string `json:"args"`
+	// Cluster is the cluster which is passed to the client by client-go, if any.
+	Cluster *clientauthv1beta1.Cluster `json:"cluster,omitempty"`
+}
+
+func agentCommand(deps agentCommandDeps) *cobra.Command {
+	var (
+		cmd = &cobra.Command{
+			Args:  cobra.NoArgs,
+			Use:   "agent",
+			Short: "Run a local agent which performs logins on behalf of kubectl",
+			Long: here.Docf(
+				`Run a local agent which performs logins on behalf of kubectl
+
+				The agent listens on a local socket which is only accessible by the current user.
+				When "pinniped login oidc" is given the path of the socket using --agent-socket,
+				or using the %s environment variable, it asks the agent for a
+				cluster credential instead of logging in itself. The agent performs each login
+				once, no matter how many kubectl invocations ask for it concurrently, and keeps
+				the resulting cluster credentials in memory until shortly before they expire.
+				When the agent cannot be reached, or fails to log in, "pinniped login oidc" logs
+				in itself.
+
+				The agent performs logins using its own environment variables, so it should be
+				started by the user who runs kubectl, e.g. in the same desktop session.`,
+				agentSocketEnvVarName,
+			),
+			SilenceUsage: true, // do not print usage message when commands fail
+		}
+		socketPath string
+	)
+	cmd.Flags().StringVar(&socketPath, "socket", filepath.Join(mustGetConfigDir(), "agent.sock"), "Path of the socket on which to listen")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		return runAgent(ctx, cmd, deps, socketPath)
+	}
+	return cmd
+}
+
+func runAgent(ctx context.Context, cmd *cobra.Command, deps agentCommandDeps, socketPath string) error {
+	pLogger, err := SetLogLevel(ctx, deps.loginDeps.lookupEnv)
+	if err != nil {
+		plog.WarningErr("Received error while setting log level", err)
+	}
+
+	listener, err := listenAgentSocket(socketPath)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Handler:           newAgentServer(ctx, deps, cmd.ErrOrStderr(), pLogger),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Pinniped agent listening on %s\n", socketPath)
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("agent stopped: %w", err)
+	}
+	return nil
+}
+
+// listenAgentSocket listens on a Unix socket at socketPath which is only accessible by the current user. A socket
+// which was left behind by an agent which is no longer running is replaced.
+func listenAgentSocket(socketPath string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("another pinniped agent is already listening on %s", socketPath)
+	}
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("could not remove stale agent socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		return nil, fmt.Errorf("could not create directory for agent socket: %w", err)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on agent socket: %w", err)
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("could not restrict access to agent socket: %w", err)
+	}
+	return listener, nil
+}
+
+// agentServer performs logins for clients, and holds the resulting cluster credentials until they are about to expire.
+type agentServer struct {
+	ctx     context.Context
+	deps    agentCommandDeps
+	stderr  io.Writer
+	pLogger plog.Logger
+
+	logins singleflight.Group
+
+	lock        sync.Mutex
+	credentials map[string]*clientauthv1beta1.ExecCredential
+}
+
+func newAgentServer(ctx context.Context, deps agentCommandDeps, stderr io.Writer, pLogger plog.Logger) *agentServer {
+	return &agentServer{
+		ctx:         ctx,
+		deps:        deps,
+		stderr:      stderr,
+		pLogger:     pLogger,
+		credentials: map[string]*clientauthv1beta1.ExecCredential{},
+	}
+}
+
+func (s *agentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != agentCredentialPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request agentCredentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	cred, err := s.credential(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(cred)
+}
+
+// credential returns the cluster credential for the request. Concurrent requests for the same login share one login.
+func (s *agentServer) credential(request *agentCredentialRequest) (*clientauthv1beta1.ExecCredential, error) {
+	keyJSON, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	key := string(keyJSON)
+
+	cred, err, _ := s.logins.Do(key, func() (interface{}, error) {
+		s.lock.Lock()
+		cred := s.credentials[key]
+		s.lock.Unlock()
+		if cred != nil && cred.Status != nil && cred.Status.ExpirationTimestamp != nil &&
+			cred.Status.ExpirationTimestamp.Time.After(s.deps.now().Add(agentMinimumRemainingLifetime)) {
+			s.pLogger.Debug("agent using cached cluster credential")
+			return cred, nil
+		}
+
+		cred, err := s.login(request.Args)
+		if err != nil {
+			return nil, err
+		}
+		s.lock.Lock()
+		s.credentials[key] = cred
+		s.lock.Unlock()
+		return cred, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cred.(*clientauthv1beta1.ExecCredential), nil
+}
+
+// login runs "pinniped login oidc" in this process. The agent keeps its own credentials in memory, so the credential
+// cache is disabled, and the login must not ask the agent for a credential in turn.
+func (s *agentServer) login(args []string) (*clientauthv1beta1.ExecCredential, error) {
+	s.pLogger.Debug("agent performing login")
+	cmd := oidcLoginCommand(s.deps.loginDeps)
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(s.stderr)
+	cmd.SetArgs(append(append([]string{}, args...), "--credential-cache=", "--agent-socket="))
+	if err := cmd.ExecuteContext(s.ctx); err != nil {
+		return nil, err
+	}
+
+	var cred clientauthv1beta1.ExecCredential
+	if err := json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return nil, fmt.Errorf("could not decode cluster credential: %w", err)
+	}
+	return &cred, nil
+}
+
+// agentSocketPath returns the path of the agent's socket which was chosen by the --agent-socket flag, or else by the
+// PINNIPED_AGENT_SOCKET env var. It returns "" when the agent should not be used.
+func agentSocketPath(flags *pflag.FlagSet, flagValue string, lookupEnv func(string) (string, bool)) string {
+	if flags.Changed("agent-socket") {
+		return flagValue
+	}
+	socketPath, _ := lookupEnv(agentSocketEnvVarName)
+	return socketPath
+}
+
+// agentArgs returns the arguments of "pinniped login oidc" which the agent needs to perform the same login.
+func agentArgs(flags *pflag.FlagSet) []string {
+	var args []string
+	flags.Visit(func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "agent-socket", "credential-cache":
+			return // these only apply to the client
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range slice.GetSlice() {
+				args = append(args, fmt.Sprintf("--%s=%s", flag.Name, value))
+			}
+			return
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+	})
+	return args
+}
+
+// agentCredential asks the agent which is listening on socketPath for a cluster credential.
+func agentCredential(ctx context.Context, socketPath string, request *agentCredentialRequest) (*clientauthv1beta1.ExecCredential, error) {
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://pinniped-agent"+agentCredentialPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to agent: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, fmt.Errorf("agent could not log in: %s", strings.TrimSpace(string(message)))
+	}
+	var cred clientauthv1beta1.ExecCredential
+	if err := json.NewDecoder(resp.Body).Decode(&cred); err != nil {
+		return nil, fmt.Errorf("could not decode cluster credential from agent: %w", err)
+	}
+	return &cred, nil
+}
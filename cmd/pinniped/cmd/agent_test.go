@@ -0,0 +1,218 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+func TestAgentCommand(t *testing.T) {
+	cfgDir := mustGetConfigDir()
+
+	var stdout bytes.Buffer
+	cmd := agentCommand(agentCommandDeps{})
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--help"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, here.Doc(`
+		Run a local agent which performs logins on behalf of kubectl
+
+		The agent listens on a local socket which is only accessible by the current user.
+		When "pinniped login oidc" is given the path of the socket using --agent-socket,
+		or using the PINNIPED_AGENT_SOCKET environment variable, it asks the agent for a
+		cluster credential instead of logging in itself. The agent performs each login
+		once, no matter how many kubectl invocations ask for it concurrently, and keeps
+		the resulting cluster credentials in memory until shortly before they expire.
+		When the agent cannot be reached, or fails to log in, "pinniped login oidc" logs
+		in itself.
+
+		The agent performs logins using its own environment variables, so it should be
+		started by the user who runs kubectl, e.g. in the same desktop session.
+
+		Usage:
+		  agent [flags]
+
+		Flags:
+		  -h, --help            help for agent
+		      --socket string   Path of the socket on which to listen (default "`+cfgDir+`/agent.sock")
+	`), stdout.String())
+}
+
+func TestAgent(t *testing.T) {
+	socketPath := filepath.Join(testutil.TempDir(t), "agent.sock")
+	sessionCachePath := filepath.Join(testutil.TempDir(t), "sessions.yaml")
+
+	var (
+		now        = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+		nowLock    sync.Mutex
+		agentLogin int32
+		loginErr   error
+	)
+	agentDeps := agentCommandDeps{
+		loginDeps: oidcLoginCommandDeps{
+			lookupEnv: func(string) (string, bool) { return "", false },
+			login: func(issuer string, clientID string, opts ...oidcclient.Option) (*oidctypes.Token, error) {
+				require.Equal(t, "test-issuer", issuer)
+				require.Equal(t, "test-client-id", clientID)
+				n := atomic.AddInt32(&agentLogin, 1)
+				if loginErr != nil {
+					return nil, loginErr
+				}
+				return &oidctypes.Token{IDToken: &oidctypes.IDToken{
+					Token:  fmt.Sprintf("agent-id-token-%d", n),
+					Expiry: metav1.NewTime(now.Add(time.Hour)),
+				}}, nil
+			},
+			keyring: &fakeKeyring{err: fmt.Errorf("some keychain error")},
+		},
+		now: func() time.Time {
+			nowLock.Lock()
+			defer nowLock.Unlock()
+			return now
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	agentErr := make(chan error, 1)
+	go func() {
+		agentCmd := agentCommand(agentDeps)
+		agentCmd.SetOut(&bytes.Buffer{})
+		agentCmd.SetErr(&bytes.Buffer{})
+		agentCmd.SetArgs([]string{"--socket", socketPath})
+		agentErr <- agentCmd.ExecuteContext(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("unix", socketPath)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// runLogin runs "pinniped login oidc" as a client of the agent, and returns its output and the ID token of its own
+	// login, if it logged in itself.
+	runLogin := func(t *testing.T, extraArgs ...string) (string, string) {
+		var localLogin string
+		cmd := oidcLoginCommand(oidcLoginCommandDeps{
+			lookupEnv: func(name string) (string, bool) {
+				if name == agentSocketEnvVarName {
+					return socketPath, true
+				}
+				return "", false
+			},
+			login: func(issuer string, clientID string, opts ...oidcclient.Option) (*oidctypes.Token, error) {
+				localLogin = "local-id-token"
+				return &oidctypes.Token{IDToken: &oidctypes.IDToken{Token: localLogin, Expiry: metav1.NewTime(now.Add(time.Hour))}}, nil
+			},
+			keyring: &fakeKeyring{err: fmt.Errorf("some keychain error")},
+		})
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{
+			"--issuer", "test-issuer",
+			"--client-id", "test-client-id",
+			"--session-cache", sessionCachePath,
+			"--credential-cache", "",
+		}, extraArgs...))
+		require.NoError(t, cmd.ExecuteContext(context.Background()))
+		return stdout.String(), localLogin
+	}
+	wantCredential := func(token string) string {
+		return `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"2023-01-02T04:04:05Z","token":"` + token + `"}}` + "\n"
+	}
+
+	t.Run("concurrent logins share one login by the agent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				out, localLogin := runLogin(t)
+				require.Equal(t, wantCredential("agent-id-token-1"), out)
+				require.Empty(t, localLogin)
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(1), atomic.LoadInt32(&agentLogin))
+	})
+
+	t.Run("different logins are performed separately", func(t *testing.T) {
+		out, localLogin := runLogin(t, "--scopes", "openid,offline_access")
+		require.Equal(t, wantCredential("agent-id-token-2"), out)
+		require.Empty(t, localLogin)
+		require.Equal(t, int32(2), atomic.LoadInt32(&agentLogin))
+	})
+
+	t.Run("credentials which are about to expire are renewed", func(t *testing.T) {
+		nowLock.Lock()
+		now = now.Add(time.Hour - agentMinimumRemainingLifetime)
+		nowLock.Unlock()
+
+		out, localLogin := runLogin(t)
+		require.Empty(t, localLogin)
+		require.Contains(t, out, `"token":"agent-id-token-3"`)
+		require.Equal(t, int32(3), atomic.LoadInt32(&agentLogin))
+	})
+
+	t.Run("the client logs in itself when the agent cannot log in", func(t *testing.T) {
+		loginErr = fmt.Errorf("some login error")
+		t.Cleanup(func() { loginErr = nil })
+
+		out, localLogin := runLogin(t, "--scopes", "openid")
+		require.Equal(t, "local-id-token", localLogin)
+		require.Contains(t, out, `"token":"local-id-token"`)
+	})
+
+	t.Run("the client logs in itself when the agent is not running", func(t *testing.T) {
+		out, localLogin := runLogin(t, "--agent-socket", filepath.Join(testutil.TempDir(t), "missing.sock"))
+		require.Equal(t, "local-id-token", localLogin)
+		require.Contains(t, out, `"token":"local-id-token"`)
+	})
+
+	t.Run("only one agent can listen on the socket", func(t *testing.T) {
+		agentCmd := agentCommand(agentDeps)
+		agentCmd.SetOut(&bytes.Buffer{})
+		agentCmd.SetErr(&bytes.Buffer{})
+		agentCmd.SetArgs([]string{"--socket", socketPath})
+		require.EqualError(t, agentCmd.ExecuteContext(context.Background()), "another pinniped agent is already listening on "+socketPath)
+	})
+
+	cancel()
+	require.NoError(t, <-agentErr)
+}
+
+func TestAgentArgs(t *testing.T) {
+	cmd := oidcLoginCommand(oidcLoginCommandDeps{})
+	require.NoError(t, cmd.ParseFlags([]string{
+		"--issuer", "test-issuer",
+		"--scopes", "openid,groups",
+		"--enable-concierge",
+		"--agent-socket", "/some/agent.sock",
+		"--credential-cache", "/some/credentials.yaml",
+	}))
+	require.Equal(t, []string{
+		"--enable-concierge=true",
+		"--issuer=test-issuer",
+		"--scopes=openid",
+		"--scopes=groups",
+	}, agentArgs(cmd.Flags()))
+}
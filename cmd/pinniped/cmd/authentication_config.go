@@ -0,0 +1,296 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	conciergev1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	supervisorconfigv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	conciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
+	"go.pinniped.dev/internal/groupsuffix"
+	"go.pinniped.dev/internal/here"
+)
+
+// authenticationConfigAPIVersion is the version of the structured authentication configuration of the Kubernetes
+// API server, which is served by Kubernetes 1.30 and later.
+const authenticationConfigAPIVersion = "apiserver.config.k8s.io/v1beta1"
+
+// celIdentifierRegexp matches the claim names which can be selected as fields in CEL expressions.
+var celIdentifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`) //nolint:gochecknoglobals
+
+// celReservedWords cannot be selected as fields in CEL expressions, even though they match celIdentifierRegexp.
+//
+//nolint:gochecknoglobals
+var celReservedWords = map[string]bool{
+	"true": true, "false": true, "null": true, "in": true,
+	"as": true, "break": true, "const": true, "continue": true, "else": true, "for": true, "function": true,
+	"if": true, "import": true, "let": true, "loop": true, "package": true, "namespace": true, "return": true,
+	"var": true, "void": true, "while": true,
+}
+
+// These types are a subset of the AuthenticationConfiguration of the Kubernetes API server. They are not vendored,
+// because they are only served by newer versions of Kubernetes than the ones which this module depends on.
+type authenticationConfiguration struct {
+	APIVersion string                   `json:"apiVersion"`
+	Kind       string                   `json:"kind"`
+	JWT        []authenticationJWTEntry `json:"jwt"`
+}
+
+type authenticationJWTEntry struct {
+	Issuer               authenticationIssuer                `json:"issuer"`
+	ClaimValidationRules []authenticationClaimValidationRule `json:"claimValidationRules,omitempty"`
+	ClaimMappings        authenticationClaimMappings         `json:"claimMappings"`
+}
+
+type authenticationIssuer struct {
+	URL                  string   `json:"url"`
+	Audiences            []string `json:"audiences"`
+	CertificateAuthority string   `json:"certificateAuthority,omitempty"`
+}
+
+type authenticationClaimValidationRule struct {
+	Expression string `json:"expression"`
+	Message    string `json:"message,omitempty"`
+}
+
+type authenticationClaimMappings struct {
+	Username authenticationClaimOrExpression `json:"username"`
+	Groups   authenticationClaimOrExpression `json:"groups"`
+}
+
+type authenticationClaimOrExpression struct {
+	Expression string `json:"expression"`
+}
+
+type authenticationConfigDeps struct {
+	getClientset getConciergeClientsetFunc
+}
+
+func authenticationConfigRealDeps() authenticationConfigDeps {
+	return authenticationConfigDeps{
+		getClientset: getRealConciergeClientset,
+	}
+}
+
+//nolint:gochecknoinits
+func init() {
+	getCmd.AddCommand(authenticationConfigCommand(authenticationConfigRealDeps()))
+}
+
+type authenticationConfigParams struct {
+	kubeconfigPath            string
+	kubeconfigContextOverride string
+	apiGroupSuffix            string
+	authenticatorName         string
+	federationDomainFile      string
+	outputPath                string
+	timeout                   time.Duration
+}
+
+func authenticationConfigCommand(deps authenticationConfigDeps) *cobra.Command {
+	var (
+		cmd = &cobra.Command{
+			Args:  cobra.NoArgs,
+			Use:   "authentication-config --federation-domain-file FILE",
+			Short: "Generate the structured authentication configuration of the Kubernetes API server for a JWTAuthenticator",
+			Long: here.Doc(
+				`Generate the structured authentication configuration of the Kubernetes API server for a JWTAuthenticator
+
+				Reads a JWTAuthenticator of the Concierge from the current (or selected) kubeconfig
+				context, and the FederationDomain of the Supervisor which issues its tokens from a
+				file, e.g. the output of "kubectl get federationdomain NAME -o yaml" in the
+				Supervisor's cluster. Writes an AuthenticationConfiguration which validates the same
+				tokens and maps them to the same usernames and groups with CEL expressions, for
+				clusters which are migrating to the native OIDC authentication of Kubernetes 1.30
+				and later. Pass it to the Kubernetes API server with --authentication-config.`,
+			),
+			SilenceUsage: true, // do not print usage message when commands fail
+		}
+		flags authenticationConfigParams
+	)
+
+	f := cmd.Flags()
+	f.StringVar(&flags.kubeconfigPath, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to kubeconfig file")
+	f.StringVar(&flags.kubeconfigContextOverride, "kubeconfig-context", "", "Kubeconfig context name (default: current active context)")
+	f.StringVar(&flags.apiGroupSuffix, "concierge-api-group-suffix", groupsuffix.PinnipedDefaultSuffix, "Concierge API group suffix")
+	f.StringVar(&flags.authenticatorName, "concierge-authenticator-name", "", "Name of the Concierge JWTAuthenticator (default: autodiscover)")
+	f.StringVar(&flags.federationDomainFile, "federation-domain-file", "", "Path of a YAML or JSON file which holds the FederationDomain of the Supervisor")
+	f.StringVarP(&flags.outputPath, "output", "o", "", "Output file path (default: stdout)")
+	f.DurationVar(&flags.timeout, "timeout", 30*time.Second, "Timeout for reading the JWTAuthenticator")
+	mustMarkRequired(cmd, "federation-domain-file")
+	mustRegisterFlagCompletion(cmd, "kubeconfig-context", completeKubeconfigContexts(&flags.kubeconfigPath))
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if flags.outputPath != "" {
+			out, err := os.Create(flags.outputPath)
+			if err != nil {
+				return fmt.Errorf("could not open output file: %w", err)
+			}
+			defer func() { _ = out.Close() }()
+			cmd.SetOut(out)
+		}
+		return runGetAuthenticationConfig(cmd.Context(), cmd.OutOrStdout(), deps, flags)
+	}
+	return cmd
+}
+
+func runGetAuthenticationConfig(ctx context.Context, out io.Writer, deps authenticationConfigDeps, flags authenticationConfigParams) error {
+	ctx, cancel := context.WithTimeout(ctx, flags.timeout)
+	defer cancel()
+
+	if err := groupsuffix.Validate(flags.apiGroupSuffix); err != nil {
+		return withErrorCode(errCodeInvalidArguments, fmt.Errorf("invalid API group suffix: %w", err))
+	}
+
+	federationDomain, err := readFederationDomain(flags.federationDomainFile)
+	if err != nil {
+		return withErrorCode(errCodeInvalidArguments, err)
+	}
+
+	clientset, err := deps.getClientset(newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride), flags.apiGroupSuffix)
+	if err != nil {
+		return fmt.Errorf("could not configure Kubernetes client: %w", err)
+	}
+	jwtAuthenticator, err := lookupJWTAuthenticator(ctx, clientset, flags.authenticatorName)
+	if err != nil {
+		return err
+	}
+
+	authenticationConfig, err := newAuthenticationConfiguration(federationDomain, jwtAuthenticator)
+	if err != nil {
+		return err
+	}
+
+	output, err := yaml.Marshal(authenticationConfig)
+	if err != nil {
+		return fmt.Errorf("could not encode AuthenticationConfiguration: %w", err)
+	}
+	_, err = out.Write(output)
+	return err
+}
+
+func readFederationDomain(path string) (*supervisorconfigv1alpha1.FederationDomain, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read --federation-domain-file: %w", err)
+	}
+	var federationDomain supervisorconfigv1alpha1.FederationDomain
+	if err := yaml.Unmarshal(data, &federationDomain); err != nil {
+		return nil, fmt.Errorf("could not decode --federation-domain-file: %w", err)
+	}
+	if federationDomain.Kind != "FederationDomain" {
+		return nil, fmt.Errorf("--federation-domain-file must hold a FederationDomain, not %q", federationDomain.Kind)
+	}
+	if federationDomain.Spec.Issuer == "" {
+		return nil, fmt.Errorf("FederationDomain %q does not have an issuer", federationDomain.Name)
+	}
+	return &federationDomain, nil
+}
+
+// lookupJWTAuthenticator gets the named JWTAuthenticator, or the only JWTAuthenticator when no name is given.
+func lookupJWTAuthenticator(ctx context.Context, clientset conciergeclientset.Interface, name string) (*conciergev1alpha1.JWTAuthenticator, error) {
+	if name != "" {
+		jwtAuthenticator, err := clientset.AuthenticationV1alpha1().JWTAuthenticators().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not get JWTAuthenticator %q: %w", name, err)
+		}
+		return jwtAuthenticator, nil
+	}
+
+	jwtAuthenticators, err := clientset.AuthenticationV1alpha1().JWTAuthenticators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JWTAuthenticator objects for autodiscovery: %w", err)
+	}
+	switch len(jwtAuthenticators.Items) {
+	case 0:
+		return nil, fmt.Errorf("no JWTAuthenticators were found")
+	case 1:
+		return &jwtAuthenticators.Items[0], nil
+	default:
+		return nil, fmt.Errorf("multiple JWTAuthenticators were found, so the --concierge-authenticator-name flag must be specified")
+	}
+}
+
+// newAuthenticationConfiguration returns the AuthenticationConfiguration which authenticates the tokens of the
+// FederationDomain in the same way as the JWTAuthenticator.
+func newAuthenticationConfiguration(
+	federationDomain *supervisorconfigv1alpha1.FederationDomain,
+	jwtAuthenticator *conciergev1alpha1.JWTAuthenticator,
+) (*authenticationConfiguration, error) {
+	spec := jwtAuthenticator.Spec
+	if spec.Issuer != federationDomain.Spec.Issuer {
+		return nil, fmt.Errorf("the issuer of JWTAuthenticator %q is %q, not the issuer of FederationDomain %q (%q)",
+			jwtAuthenticator.Name, spec.Issuer, federationDomain.Name, federationDomain.Spec.Issuer)
+	}
+
+	var certificateAuthority string
+	if spec.TLS != nil && spec.TLS.CertificateAuthorityData != "" {
+		pem, err := base64.StdEncoding.DecodeString(spec.TLS.CertificateAuthorityData)
+		if err != nil {
+			return nil, fmt.Errorf("JWTAuthenticator %q has invalid tls.certificateAuthorityData: %w", jwtAuthenticator.Name, err)
+		}
+		certificateAuthority = string(pem)
+	}
+
+	// These are the same defaults as the ones of the JWTAuthenticator, which match the claims of the Supervisor.
+	usernameClaim := spec.Claims.Username
+	if usernameClaim == "" {
+		usernameClaim = oidcapi.IDTokenClaimUsername
+	}
+	groupsClaim := spec.Claims.Groups
+	if groupsClaim == "" {
+		groupsClaim = oidcapi.IDTokenClaimGroups
+	}
+
+	entry := authenticationJWTEntry{
+		Issuer: authenticationIssuer{
+			URL:                  spec.Issuer,
+			Audiences:            []string{spec.Audience},
+			CertificateAuthority: certificateAuthority,
+		},
+		ClaimMappings: authenticationClaimMappings{
+			Username: authenticationClaimOrExpression{Expression: celClaim(usernameClaim)},
+			// The groups claim is optional, and it may be a string or a list of strings.
+			Groups: authenticationClaimOrExpression{
+				Expression: fmt.Sprintf("%s in claims ? %s : []", strconv.Quote(groupsClaim), celClaim(groupsClaim)),
+			},
+		},
+	}
+
+	// Like the JWTAuthenticator, reject email addresses which are explicitly not verified. The Kubernetes API server
+	// requires such a rule when the username is mapped from the email claim.
+	if usernameClaim == "email" {
+		entry.ClaimValidationRules = []authenticationClaimValidationRule{{
+			Expression: "claims.?email_verified.orValue(true) == true",
+			Message:    "email_verified must be true when it is present",
+		}}
+	}
+
+	return &authenticationConfiguration{
+		APIVersion: authenticationConfigAPIVersion,
+		Kind:       "AuthenticationConfiguration",
+		JWT:        []authenticationJWTEntry{entry},
+	}, nil
+}
+
+// celClaim returns the CEL expression which selects the claim of the given name.
+func celClaim(name string) string {
+	if celIdentifierRegexp.MatchString(name) && !celReservedWords[name] {
+		return "claims." + name
+	}
+	return "claims[" + strconv.Quote(name) + "]"
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+
+	conciergev1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	conciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
+	fakeconciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestGetAuthenticationConfig(t *testing.T) {
+	tmpdir := testutil.TempDir(t)
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpdir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	federationDomainPath := writeFile("federationdomain.yaml", here.Doc(`
+		apiVersion: config.supervisor.pinniped.dev/v1alpha1
+		kind: FederationDomain
+		metadata:
+		  name: my-federation-domain
+		  namespace: pinniped-supervisor
+		spec:
+		  issuer: https://example.com/issuer
+		status:
+		  status: Success
+	`))
+	otherFederationDomainPath := writeFile("other-federationdomain.json",
+		`{"apiVersion":"config.supervisor.pinniped.dev/v1alpha1","kind":"FederationDomain","metadata":{"name":"other"},"spec":{"issuer":"https://example.com/other"}}`)
+	notAFederationDomainPath := writeFile("oidcclient.yaml", "apiVersion: config.supervisor.pinniped.dev/v1alpha1\nkind: OIDCClient\n")
+	noIssuerPath := writeFile("no-issuer.yaml", "kind: FederationDomain\nmetadata:\n  name: no-issuer\n")
+	invalidPath := writeFile("invalid.yaml", "not: [valid")
+
+	jwtAuthenticator := func(name string, spec conciergev1alpha1.JWTAuthenticatorSpec) runtime.Object {
+		return &conciergev1alpha1.JWTAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+	defaultJWTAuthenticator := jwtAuthenticator("my-jwt-authenticator", conciergev1alpha1.JWTAuthenticatorSpec{
+		Issuer:   "https://example.com/issuer",
+		Audience: "my-audience",
+	})
+	caPEM := "-----BEGIN CERTIFICATE-----\nsome-ca\n-----END CERTIFICATE-----\n"
+
+	tests := []struct {
+		name             string
+		args             []string
+		conciergeObjects []runtime.Object
+		getClientsetErr  error
+		wantError        string
+		wantStdout       string
+	}{
+		{
+			name:             "defaults of the Supervisor",
+			args:             []string{"--federation-domain-file", federationDomainPath},
+			conciergeObjects: []runtime.Object{defaultJWTAuthenticator},
+			wantStdout: here.Doc(`
+				apiVersion: apiserver.config.k8s.io/v1beta1
+				jwt:
+				- claimMappings:
+				    groups:
+				      expression: '"groups" in claims ? claims.groups : []'
+				    username:
+				      expression: claims.username
+				  issuer:
+				    audiences:
+				    - my-audience
+				    url: https://example.com/issuer
+				kind: AuthenticationConfiguration
+			`),
+		},
+		{
+			name: "custom claims and CA bundle",
+			args: []string{"--federation-domain-file", federationDomainPath, "--concierge-authenticator-name", "custom"},
+			conciergeObjects: []runtime.Object{
+				defaultJWTAuthenticator,
+				jwtAuthenticator("custom", conciergev1alpha1.JWTAuthenticatorSpec{
+					Issuer:   "https://example.com/issuer",
+					Audience: "custom-audience",
+					Claims:   conciergev1alpha1.JWTTokenClaims{Username: "email", Groups: "https://example.com/groups"},
+					TLS:      &conciergev1alpha1.TLSSpec{CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte(caPEM))},
+				}),
+			},
+			wantStdout: here.Doc(`
+				apiVersion: apiserver.config.k8s.io/v1beta1
+				jwt:
+				- claimMappings:
+				    groups:
+				      expression: '"https://example.com/groups" in claims ? claims["https://example.com/groups"]
+				        : []'
+				    username:
+				      expression: claims.email
+				  claimValidationRules:
+				  - expression: claims.?email_verified.orValue(true) == true
+				    message: email_verified must be true when it is present
+				  issuer:
+				    audiences:
+				    - custom-audience
+				    certificateAuthority: |
+				      -----BEGIN CERTIFICATE-----
+				      some-ca
+				      -----END CERTIFICATE-----
+				    url: https://example.com/issuer
+				kind: AuthenticationConfiguration
+			`),
+		},
+		{
+			name:             "claim which is a reserved word of CEL",
+			args:             []string{"--federation-domain-file", federationDomainPath},
+			conciergeObjects: []runtime.Object{jwtAuthenticator("my-jwt-authenticator", conciergev1alpha1.JWTAuthenticatorSpec{Issuer: "https://example.com/issuer", Audience: "my-audience", Claims: conciergev1alpha1.JWTTokenClaims{Username: "namespace"}})},
+			wantStdout: here.Doc(`
+				apiVersion: apiserver.config.k8s.io/v1beta1
+				jwt:
+				- claimMappings:
+				    groups:
+				      expression: '"groups" in claims ? claims.groups : []'
+				    username:
+				      expression: claims["namespace"]
+				  issuer:
+				    audiences:
+				    - my-audience
+				    url: https://example.com/issuer
+				kind: AuthenticationConfiguration
+			`),
+		},
+		{
+			name:             "issuer of another FederationDomain",
+			args:             []string{"--federation-domain-file", otherFederationDomainPath},
+			conciergeObjects: []runtime.Object{defaultJWTAuthenticator},
+			wantError:        `the issuer of JWTAuthenticator "my-jwt-authenticator" is "https://example.com/issuer", not the issuer of FederationDomain "other" ("https://example.com/other")`,
+		},
+		{
+			name: "invalid CA bundle",
+			args: []string{"--federation-domain-file", federationDomainPath},
+			conciergeObjects: []runtime.Object{jwtAuthenticator("my-jwt-authenticator", conciergev1alpha1.JWTAuthenticatorSpec{
+				Issuer: "https://example.com/issuer", Audience: "my-audience", TLS: &conciergev1alpha1.TLSSpec{CertificateAuthorityData: "%%%"},
+			})},
+			wantError: `JWTAuthenticator "my-jwt-authenticator" has invalid tls.certificateAuthorityData: illegal base64 data at input byte 0`,
+		},
+		{
+			name:      "no JWTAuthenticators",
+			args:      []string{"--federation-domain-file", federationDomainPath},
+			wantError: "no JWTAuthenticators were found",
+		},
+		{
+			name:             "multiple JWTAuthenticators",
+			args:             []string{"--federation-domain-file", federationDomainPath},
+			conciergeObjects: []runtime.Object{defaultJWTAuthenticator, jwtAuthenticator("another", conciergev1alpha1.JWTAuthenticatorSpec{})},
+			wantError:        "multiple JWTAuthenticators were found, so the --concierge-authenticator-name flag must be specified",
+		},
+		{
+			name:             "JWTAuthenticator which does not exist",
+			args:             []string{"--federation-domain-file", federationDomainPath, "--concierge-authenticator-name", "missing"},
+			conciergeObjects: []runtime.Object{defaultJWTAuthenticator},
+			wantError:        `could not get JWTAuthenticator "missing": jwtauthenticators.authentication.concierge.pinniped.dev "missing" not found`,
+		},
+		{
+			name:            "client which cannot be configured",
+			args:            []string{"--federation-domain-file", federationDomainPath},
+			getClientsetErr: fmt.Errorf("some error"),
+			wantError:       "could not configure Kubernetes client: some error",
+		},
+		{
+			name:      "missing FederationDomain file",
+			args:      []string{"--federation-domain-file", filepath.Join(tmpdir, "missing.yaml")},
+			wantError: "could not read --federation-domain-file: open " + filepath.Join(tmpdir, "missing.yaml") + ": no such file or directory",
+		},
+		{
+			name:      "invalid FederationDomain file",
+			args:      []string{"--federation-domain-file", invalidPath},
+			wantError: "could not decode --federation-domain-file: error converting YAML to JSON: yaml: line 1: did not find expected ',' or ']'",
+		},
+		{
+			name:      "file which is not a FederationDomain",
+			args:      []string{"--federation-domain-file", notAFederationDomainPath},
+			wantError: `--federation-domain-file must hold a FederationDomain, not "OIDCClient"`,
+		},
+		{
+			name:      "FederationDomain without an issuer",
+			args:      []string{"--federation-domain-file", noIssuerPath},
+			wantError: `FederationDomain "no-issuer" does not have an issuer`,
+		},
+		{
+			name:      "invalid API group suffix",
+			args:      []string{"--federation-domain-file", federationDomainPath, "--concierge-api-group-suffix", ".starts.with.dot"},
+			wantError: "invalid API group suffix: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+		},
+		{
+			name:      "missing FederationDomain flag",
+			wantError: `required flag(s) "federation-domain-file" not set`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := authenticationConfigCommand(authenticationConfigDeps{
+				getClientset: func(clientConfig clientcmd.ClientConfig, apiGroupSuffix string) (conciergeclientset.Interface, error) {
+					require.Equal(t, "pinniped.dev", apiGroupSuffix)
+					if tt.getClientsetErr != nil {
+						return nil, tt.getClientsetErr
+					}
+					return fakeconciergeclientset.NewSimpleClientset(tt.conciergeObjects...), nil
+				},
+			})
+			require.NotNil(t, cmd)
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStdout, stdout.String())
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
@@ -28,3 +28,11 @@ func mustMarkDeprecated(cmd *cobra.Command, flag, usageMessage string) {
 		panic(err)
 	}
 }
+
+// mustRegisterFlagCompletion registers the function which completes the value of the given flag of the provided
+// cobra.Command. If the name is wrong, it panics.
+func mustRegisterFlagCompletion(cmd *cobra.Command, flag string, complete completionFunc) {
+	if err := cmd.RegisterFlagCompletionFunc(flag, complete); err != nil {
+		panic(err)
+	}
+}
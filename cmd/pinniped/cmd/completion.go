@@ -0,0 +1,164 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// completionTimeout limits how long a shell completion may spend querying a cluster or an issuer, so that an
+// unreachable server does not hang the user's shell.
+const completionTimeout = 5 * time.Second
+
+// completionFunc is the type of the functions which cobra calls to complete the value of a flag.
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completeKubeconfigContexts completes the names of the contexts of the kubeconfig chosen by --kubeconfig.
+func completeKubeconfigContexts(kubeconfigPath *string) completionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		kubeconfig, err := newClientConfig(*kubeconfigPath, "").RawConfig()
+		if err != nil {
+			return completionError("could not load kubeconfig", err)
+		}
+		completions := make([]string, 0, len(kubeconfig.Contexts))
+		for name, kubeContext := range kubeconfig.Contexts {
+			completions = appendCompletion(completions, toComplete, name, "cluster "+kubeContext.Cluster)
+		}
+		sort.Strings(completions)
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeConciergeAuthenticatorNames completes the names of the Concierge's JWTAuthenticators and
+// WebhookAuthenticators, or only those of the type chosen by --concierge-authenticator-type.
+func completeConciergeAuthenticatorNames(deps kubeconfigDeps, flags *getKubeconfigParams) completionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+
+		clientset, err := deps.getClientset(newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride), flags.concierge.apiGroupSuffix)
+		if err != nil {
+			return completionError("could not configure Kubernetes client", err)
+		}
+
+		var completions []string
+		authType := strings.ToLower(flags.concierge.authenticatorType)
+		if authType == "" || authType == "jwt" {
+			jwtAuths, err := clientset.AuthenticationV1alpha1().JWTAuthenticators().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return completionError("failed to list JWTAuthenticator objects", err)
+			}
+			for _, jwtAuth := range jwtAuths.Items {
+				completions = appendCompletion(completions, toComplete, jwtAuth.Name, "JWTAuthenticator")
+			}
+		}
+		if authType == "" || authType == "webhook" {
+			webhooks, err := clientset.AuthenticationV1alpha1().WebhookAuthenticators().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return completionError("failed to list WebhookAuthenticator objects", err)
+			}
+			for _, webhook := range webhooks.Items {
+				completions = appendCompletion(completions, toComplete, webhook.Name, "WebhookAuthenticator")
+			}
+		}
+		sort.Strings(completions)
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeKubeconfigUpstreamIDPNames completes the names of the upstream identity providers of the Supervisor chosen
+// by --oidc-issuer, or else by the JWTAuthenticator which "pinniped get kubeconfig" would discover.
+func completeKubeconfigUpstreamIDPNames(deps kubeconfigDeps, flags *getKubeconfigParams) completionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+
+		// Discover the issuer the same way that "pinniped get kubeconfig" would, using a copy of the flags.
+		discovered := *flags
+		if discovered.oidc.issuer == "" && !discovered.concierge.disabled {
+			clientset, err := deps.getClientset(newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride), flags.concierge.apiGroupSuffix)
+			if err != nil {
+				return completionError("could not configure Kubernetes client", err)
+			}
+			authenticator, err := lookupAuthenticator(clientset, discovered.concierge.authenticatorType, discovered.concierge.authenticatorName, logr.Discard())
+			if err != nil {
+				return completionError("could not discover the OIDC issuer", err)
+			}
+			if err := discoverAuthenticatorParams(authenticator, &discovered, logr.Discard()); err != nil {
+				return completionError("could not discover the OIDC issuer", err)
+			}
+		}
+
+		httpClient, err := newDiscoveryHTTPClient(discovered.oidc.caBundle)
+		if err != nil {
+			return completionError("could not configure HTTP client", err)
+		}
+		return completeUpstreamIDPNames(ctx, discovered.oidc.issuer, httpClient, toComplete)
+	}
+}
+
+// completeLoginUpstreamIDPNames completes the names of the upstream identity providers of the Supervisor chosen by
+// --issuer of "pinniped login oidc".
+func completeLoginUpstreamIDPNames(flags *oidcLoginFlags) completionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+
+		httpClient, err := makeClient(flags.caBundlePaths, flags.caBundleData, nil)
+		if err != nil {
+			return completionError("could not configure HTTP client", err)
+		}
+		return completeUpstreamIDPNames(ctx, flags.issuer, httpClient, toComplete)
+	}
+}
+
+// completeUpstreamIDPNames completes the names of the upstream identity providers which are advertised by the
+// Supervisor's IDP discovery endpoint.
+func completeUpstreamIDPNames(ctx context.Context, issuer string, httpClient *http.Client, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if issuer == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	pinnipedIDPsEndpoint, err := discoverIDPsDiscoveryEndpointURL(ctx, issuer, httpClient)
+	if err != nil {
+		return completionError("could not discover the Supervisor", err)
+	}
+	if pinnipedIDPsEndpoint == "" {
+		// The issuer is not a Pinniped Supervisor which supports upstream IDP discovery.
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	upstreamIDPs, err := discoverAllAvailableSupervisorUpstreamIDPs(ctx, pinnipedIDPsEndpoint, httpClient)
+	if err != nil {
+		return completionError("could not discover the upstream identity providers", err)
+	}
+	completions := make([]string, 0, len(upstreamIDPs))
+	for _, upstreamIDP := range upstreamIDPs {
+		completions = appendCompletion(completions, toComplete, upstreamIDP.Name, upstreamIDP.Type.String())
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// appendCompletion appends the value, with its description, when it starts with the text being completed.
+func appendCompletion(completions []string, toComplete, value, description string) []string {
+	if !strings.HasPrefix(value, toComplete) {
+		return completions
+	}
+	return append(completions, fmt.Sprintf("%s\t%s", value, description))
+}
+
+// completionError records why a completion failed in cobra's completion debug log, which is only written when
+// BASH_COMP_DEBUG_FILE is set, because anything printed during completion would be shown by the shell.
+func completionError(msg string, err error) ([]string, cobra.ShellCompDirective) {
+	cobra.CompDebugln(fmt.Sprintf("%s: %v", msg, err), false)
+	return nil, cobra.ShellCompDirectiveError
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+
+	conciergev1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	conciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
+	fakeconciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestCompletion(t *testing.T) {
+	kubeconfigPath := filepath.Join(testutil.TempDir(t), "kubeconfig.yaml")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(here.Doc(`
+		apiVersion: v1
+		kind: Config
+		current-context: kind-pinniped
+		contexts:
+		- name: kind-pinniped
+		  context:
+		    cluster: kind-cluster
+		    user: kind-user
+		- name: prod
+		  context:
+		    cluster: prod-cluster
+		    user: prod-user
+		- name: kind-other
+		  context:
+		    cluster: other-cluster
+		    user: other-user
+		clusters:
+		- name: kind-cluster
+		  cluster:
+		    server: https://kind.example.com
+		- name: prod-cluster
+		  cluster:
+		    server: https://prod.example.com
+		- name: other-cluster
+		  cluster:
+		    server: https://other.example.com
+		users:
+		- name: kind-user
+		  user:
+		    token: test-token
+		- name: prod-user
+		  user:
+		    token: test-token
+		- name: other-user
+		  user:
+		    token: test-token
+	`)), 0600))
+
+	var issuerURL string
+	issuerCABundle, issuerURL := testutil.TLSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_, _ = fmt.Fprintf(w, `{"issuer": %q, "discovery.supervisor.pinniped.dev/v1alpha1": {"pinniped_identity_providers_endpoint": "%s/v1alpha1/pinniped_identity_providers"}}`, issuerURL, issuerURL)
+		case "/v1alpha1/pinniped_identity_providers":
+			_, _ = fmt.Fprint(w, here.Doc(`{
+				"pinniped_identity_providers": [
+					{"name": "some-ldap-idp", "type": "ldap", "flows": ["cli_password"]},
+					{"name": "some-oidc-idp", "type": "oidc", "flows": ["browser_authcode"]},
+					{"name": "other-oidc-idp", "type": "oidc", "flows": ["browser_authcode"]}
+				]
+			}`))
+		default:
+			t.Fatalf("unexpected request for %s", r.URL.Path)
+		}
+	})
+	issuerCABundlePath := filepath.Join(testutil.TempDir(t), "ca.pem")
+	require.NoError(t, os.WriteFile(issuerCABundlePath, []byte(issuerCABundle), 0600))
+
+	conciergeObjects := []runtime.Object{
+		&conciergev1alpha1.JWTAuthenticator{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-jwt-authenticator"},
+			Spec: conciergev1alpha1.JWTAuthenticatorSpec{
+				Issuer: issuerURL,
+				TLS:    &conciergev1alpha1.TLSSpec{CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte(issuerCABundle))},
+			},
+		},
+		&conciergev1alpha1.WebhookAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: "test-webhook-authenticator"}},
+		&conciergev1alpha1.WebhookAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: "other-webhook-authenticator"}},
+	}
+	kubeconfigCmd := func(getClientsetErr error) func() *cobra.Command {
+		return func() *cobra.Command {
+			return kubeconfigCommand(kubeconfigDeps{
+				getClientset: func(clientConfig clientcmd.ClientConfig, apiGroupSuffix string) (conciergeclientset.Interface, error) {
+					if getClientsetErr != nil {
+						return nil, getClientsetErr
+					}
+					return fakeconciergeclientset.NewSimpleClientset(conciergeObjects...), nil
+				},
+			})
+		}
+	}
+
+	tests := []struct {
+		name       string
+		command    func() *cobra.Command
+		args       []string
+		wantStdout string
+	}{
+		{
+			name:       "kubeconfig contexts",
+			command:    func() *cobra.Command { return newWhoamiCommand(nil, nil) },
+			args:       []string{"--kubeconfig", kubeconfigPath, "--kubeconfig-context", ""},
+			wantStdout: "kind-other\tcluster other-cluster\nkind-pinniped\tcluster kind-cluster\nprod\tcluster prod-cluster\n:4\n",
+		},
+		{
+			name:       "kubeconfig contexts with a prefix",
+			command:    kubeconfigCmd(nil),
+			args:       []string{"--kubeconfig", kubeconfigPath, "--kubeconfig-context", "kind-"},
+			wantStdout: "kind-other\tcluster other-cluster\nkind-pinniped\tcluster kind-cluster\n:4\n",
+		},
+		{
+			name:       "kubeconfig contexts when the kubeconfig cannot be loaded",
+			command:    func() *cobra.Command { return newWhoamiCommand(nil, nil) },
+			args:       []string{"--kubeconfig", "./does/not/exist", "--kubeconfig-context", ""},
+			wantStdout: ":1\n",
+		},
+		{
+			name:       "concierge authenticator names",
+			command:    kubeconfigCmd(nil),
+			args:       []string{"--kubeconfig", kubeconfigPath, "--concierge-authenticator-name", ""},
+			wantStdout: "other-webhook-authenticator\tWebhookAuthenticator\ntest-jwt-authenticator\tJWTAuthenticator\ntest-webhook-authenticator\tWebhookAuthenticator\n:4\n",
+		},
+		{
+			name:       "concierge authenticator names of one type with a prefix",
+			command:    kubeconfigCmd(nil),
+			args:       []string{"--kubeconfig", kubeconfigPath, "--concierge-authenticator-type", "webhook", "--concierge-authenticator-name", "test-"},
+			wantStdout: "test-webhook-authenticator\tWebhookAuthenticator\n:4\n",
+		},
+		{
+			name:       "concierge authenticator names when the cluster cannot be reached",
+			command:    kubeconfigCmd(fmt.Errorf("some kube error")),
+			args:       []string{"--kubeconfig", kubeconfigPath, "--concierge-authenticator-name", ""},
+			wantStdout: ":1\n",
+		},
+		{
+			name:       "upstream identity provider names from the issuer of a discovered JWTAuthenticator",
+			command:    kubeconfigCmd(nil),
+			args:       []string{"--kubeconfig", kubeconfigPath, "--concierge-authenticator-type", "jwt", "--concierge-authenticator-name", "test-jwt-authenticator", "--upstream-identity-provider-name", ""},
+			wantStdout: "other-oidc-idp\toidc\nsome-ldap-idp\tldap\nsome-oidc-idp\toidc\n:4\n",
+		},
+		{
+			name:       "upstream identity provider names from --oidc-issuer with a prefix",
+			command:    kubeconfigCmd(fmt.Errorf("the cluster should not be used")),
+			args:       []string{"--kubeconfig", kubeconfigPath, "--oidc-issuer", issuerURL, "--oidc-ca-bundle", issuerCABundlePath, "--upstream-identity-provider-name", "some-"},
+			wantStdout: "some-ldap-idp\tldap\nsome-oidc-idp\toidc\n:4\n",
+		},
+		{
+			name:       "upstream identity provider names when the authenticator is ambiguous",
+			command:    kubeconfigCmd(nil),
+			args:       []string{"--kubeconfig", kubeconfigPath, "--upstream-identity-provider-name", ""},
+			wantStdout: ":1\n",
+		},
+		{
+			name:       "upstream identity provider names for pinniped login oidc",
+			command:    func() *cobra.Command { return oidcLoginCommand(oidcLoginCommandDeps{}) },
+			args:       []string{"--issuer", issuerURL, "--ca-bundle", issuerCABundlePath, "--upstream-identity-provider-name", "o"},
+			wantStdout: "other-oidc-idp\toidc\n:4\n",
+		},
+		{
+			name:       "upstream identity provider names for pinniped login oidc without an issuer",
+			command:    func() *cobra.Command { return oidcLoginCommand(oidcLoginCommandDeps{}) },
+			args:       []string{"--upstream-identity-provider-name", ""},
+			wantStdout: ":4\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tt.command()
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, tt.args...))
+			require.NoError(t, cmd.Execute())
+			require.Equal(t, tt.wantStdout, stdout.String())
+		})
+	}
+}
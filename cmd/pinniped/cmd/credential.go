@@ -0,0 +1,173 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"go.pinniped.dev/internal/cachecrypter"
+	"go.pinniped.dev/internal/here"
+)
+
+// credentialPassphraseEnvVarName is the name of the env var which may provide the passphrase used to encrypt and
+// decrypt exported credential files, instead of prompting for it.
+const credentialPassphraseEnvVarName = "PINNIPED_CREDENTIAL_PASSPHRASE" //nolint:gosec // this is the name of an env var, not a credential
+
+//nolint:gochecknoglobals
+var credentialCmd = &cobra.Command{
+	Use:   "credential",
+	Short: "Exports or imports cluster credentials",
+	Long: here.Doc(
+		`Exports or imports cluster credentials
+
+			Use "pinniped credential export" on a machine where you can log in using a web
+			browser, copy the exported file to another machine which uses the same
+			Pinniped-compatible kubeconfig, and use "pinniped credential import" there, so
+			that kubectl can use the cluster credential until it expires without logging in.`,
+	),
+	SilenceUsage: true, // Do not print usage message when commands fail.
+}
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(credentialCmd)
+	credentialCmd.AddCommand(credentialExportCommand(credentialCommandRealDeps()))
+	credentialCmd.AddCommand(credentialImportCommand(credentialCommandRealDeps()))
+}
+
+type credentialCommandDeps struct {
+	loginDeps       oidcLoginCommandDeps
+	promptForSecret func(context.Context, string) (string, error)
+	now             func() time.Time
+}
+
+func credentialCommandRealDeps() credentialCommandDeps {
+	return credentialCommandDeps{
+		loginDeps:       oidcLoginCommandRealDeps(),
+		promptForSecret: promptForSecret,
+		now:             time.Now,
+	}
+}
+
+// exportedCredential is the content of an exported credential file, before it is encrypted.
+type exportedCredential struct {
+	// Server is the URL of the cluster for which the credential was exported.
+	Server     string                            `json:"server"`
+	Credential *clientauthv1beta1.ExecCredential `json:"credential"`
+}
+
+// oidcLoginKubeconfig is the "pinniped login oidc" exec credential plugin of a kubeconfig context.
+type oidcLoginKubeconfig struct {
+	contextName string
+	cluster     *clientcmdapi.Cluster
+	exec        *clientcmdapi.ExecConfig
+	restConfig  *rest.Config
+	// flags are the parsed flags of the "pinniped login oidc" command of the plugin.
+	flags *pflag.FlagSet
+}
+
+// loadOIDCLoginKubeconfig finds the "pinniped login oidc" exec credential plugin of the current (or selected)
+// kubeconfig context.
+func loadOIDCLoginKubeconfig(kubeconfigPath, kubeconfigContextOverride string) (*oidcLoginKubeconfig, error) {
+	clientConfig := newClientConfig(kubeconfigPath, kubeconfigContextOverride)
+	kubeconfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not load kubeconfig: %w", err)
+	}
+	contextName := kubeconfig.CurrentContext
+	if kubeconfigContextOverride != "" {
+		contextName = kubeconfigContextOverride
+	}
+	kubeContext, ok := kubeconfig.Contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("could not find kubeconfig context %q", contextName)
+	}
+	cluster, ok := kubeconfig.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("could not find cluster %q of kubeconfig context %q", kubeContext.Cluster, contextName)
+	}
+	authInfo, ok := kubeconfig.AuthInfos[kubeContext.AuthInfo]
+	if !ok || authInfo.Exec == nil || len(authInfo.Exec.Args) < 2 ||
+		authInfo.Exec.Args[0] != "login" || authInfo.Exec.Args[1] != "oidc" {
+		return nil, fmt.Errorf("kubeconfig context %q does not use \"pinniped login oidc\"", contextName)
+	}
+
+	loginCmd := oidcLoginCommand(oidcLoginCommandDeps{})
+	if err := loginCmd.ParseFlags(authInfo.Exec.Args[2:]); err != nil {
+		return nil, fmt.Errorf("could not parse \"pinniped login oidc\" arguments from kubeconfig context %q: %w", contextName, err)
+	}
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not load kubeconfig: %w", err)
+	}
+	return &oidcLoginKubeconfig{
+		contextName: contextName,
+		cluster:     cluster,
+		exec:        authInfo.Exec,
+		restConfig:  restConfig,
+		flags:       loginCmd.Flags(),
+	}, nil
+}
+
+// credentialCrypter returns a Crypter which uses a key derived from the passphrase given by the
+// PINNIPED_CREDENTIAL_PASSPHRASE env var, or else entered by the user.
+func credentialCrypter(ctx context.Context, deps credentialCommandDeps, confirm bool) (*cachecrypter.Crypter, error) {
+	if passphrase, ok := deps.loginDeps.lookupEnv(credentialPassphraseEnvVarName); ok {
+		return cachecrypter.NewFromPassphrase([]byte(passphrase))
+	}
+	passphrase, err := deps.promptForSecret(ctx, "Enter the passphrase of the credential file: ")
+	if err != nil {
+		return nil, fmt.Errorf("could not read passphrase (%w), please set %s", err, credentialPassphraseEnvVarName)
+	}
+	if confirm {
+		again, err := deps.promptForSecret(ctx, "Enter the passphrase again: ")
+		if err != nil {
+			return nil, fmt.Errorf("could not read passphrase (%w), please set %s", err, credentialPassphraseEnvVarName)
+		}
+		if again != passphrase {
+			return nil, fmt.Errorf("the passphrases do not match")
+		}
+	}
+	return cachecrypter.NewFromPassphrase([]byte(passphrase))
+}
+
+// promptForSecret prompts for a value on stderr, and reads it from stdin without echoing it.
+func promptForSecret(ctx context.Context, promptLabel string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("stdin is not connected to a terminal")
+	}
+	if _, err := fmt.Fprint(os.Stderr, promptLabel); err != nil {
+		return "", fmt.Errorf("could not print prompt to stderr: %w", err)
+	}
+	defer func() { _, _ = fmt.Fprintln(os.Stderr) }()
+
+	type readResult struct {
+		secret []byte
+		err    error
+	}
+	readResults := make(chan readResult, 1)
+	go func() {
+		secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+		readResults <- readResult{secret, err}
+	}()
+
+	// If the context is canceled, return immediately. The ReadPassword() operation will stay hung in the background
+	// goroutine indefinitely.
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-readResults:
+		return string(r.secret), r.err
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/plog"
+)
+
+type credentialExportFlags struct {
+	kubeconfigPath            string
+	kubeconfigContextOverride string
+	file                      string
+}
+
+func credentialExportCommand(deps credentialCommandDeps) *cobra.Command {
+	var (
+		cmd = &cobra.Command{
+			Args:  cobra.NoArgs,
+			Use:   "export --file FILE",
+			Short: "Export a cluster credential to an encrypted file",
+			Long: here.Docf(
+				`Export a cluster credential to an encrypted file
+
+				Logs in using the "pinniped login oidc" command of the current (or selected)
+				kubeconfig context, which may open a web browser, and writes the resulting
+				cluster credential to a file which is encrypted using a passphrase. The
+				passphrase is read from the %s
+				environment variable, or else entered by the user. Use "pinniped credential
+				import" to import the file on another machine.
+
+				Anyone who can decrypt the file can use the credential to access the cluster
+				until it expires.`,
+				credentialPassphraseEnvVarName,
+			),
+			SilenceUsage: true, // do not print usage message when commands fail
+		}
+		flags credentialExportFlags
+	)
+	cmd.Flags().StringVar(&flags.kubeconfigPath, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to kubeconfig file")
+	cmd.Flags().StringVar(&flags.kubeconfigContextOverride, "kubeconfig-context", "", "Kubeconfig context name (default: current active context)")
+	mustRegisterFlagCompletion(cmd, "kubeconfig-context", completeKubeconfigContexts(&flags.kubeconfigPath))
+	cmd.Flags().StringVar(&flags.file, "file", "", "Path of the encrypted credential file to write")
+	mustMarkRequired(cmd, "file")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runCredentialExport(cmd, deps, flags)
+	}
+	return cmd
+}
+
+func runCredentialExport(cmd *cobra.Command, deps credentialCommandDeps, flags credentialExportFlags) error {
+	if _, err := SetLogLevel(cmd.Context(), deps.loginDeps.lookupEnv); err != nil {
+		plog.WarningErr("Received error while setting log level", err)
+	}
+
+	kubeconfig, err := loadOIDCLoginKubeconfig(flags.kubeconfigPath, flags.kubeconfigContextOverride)
+	if err != nil {
+		return err
+	}
+
+	// Read the passphrase before logging in, so that the user is not asked for it after a slow login.
+	crypter, err := credentialCrypter(cmd.Context(), deps, true)
+	if err != nil {
+		return err
+	}
+
+	// Log in using the login command of the kubeconfig. The credential cache is keyed by the arguments of the exec
+	// credential plugin, which differ from the arguments of this command, so it is not used.
+	loginCmd := oidcLoginCommand(deps.loginDeps)
+	var stdout bytes.Buffer
+	loginCmd.SetOut(&stdout)
+	loginCmd.SetErr(cmd.ErrOrStderr())
+	loginCmd.SetArgs(append(append([]string{}, kubeconfig.exec.Args[2:]...), "--credential-cache=", "--agent-socket="))
+	if err := loginCmd.ExecuteContext(cmd.Context()); err != nil {
+		return fmt.Errorf("could not log in: %w", err)
+	}
+	var cred clientauthv1beta1.ExecCredential
+	if err := json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return fmt.Errorf("could not decode cluster credential: %w", err)
+	}
+	if cred.Status == nil || cred.Status.ExpirationTimestamp == nil {
+		return fmt.Errorf("the cluster credential has no expiration time, so it cannot be exported")
+	}
+
+	plaintext, err := json.Marshal(exportedCredential{Server: kubeconfig.cluster.Server, Credential: &cred})
+	if err != nil {
+		return err
+	}
+	encrypted, err := crypter.Encrypt(plaintext)
+	if err != nil {
+		return fmt.Errorf("could not encrypt cluster credential: %w", err)
+	}
+	if err := os.WriteFile(flags.file, encrypted, 0600); err != nil {
+		return fmt.Errorf("could not write credential file: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Exported the credential for cluster %s, which is valid until %s, to %s\n",
+		kubeconfig.cluster.Server, cred.Status.ExpirationTimestamp.Time.Format(time.RFC3339), flags.file)
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+	"k8s.io/client-go/rest"
+
+	"go.pinniped.dev/internal/execcredcache"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/plog"
+)
+
+type credentialImportFlags struct {
+	kubeconfigPath            string
+	kubeconfigContextOverride string
+	file                      string
+}
+
+func credentialImportCommand(deps credentialCommandDeps) *cobra.Command {
+	var (
+		cmd = &cobra.Command{
+			Args:  cobra.NoArgs,
+			Use:   "import --file FILE",
+			Short: "Import a cluster credential from an encrypted file",
+			Long: here.Docf(
+				`Import a cluster credential from an encrypted file
+
+				Decrypts a file which was written by "pinniped credential export", and adds its
+				cluster credential to the credential cache of the "pinniped login oidc" command
+				of the current (or selected) kubeconfig context, so that kubectl uses it until
+				it expires. The kubeconfig context must use the same cluster as the kubeconfig
+				from which the credential was exported. The passphrase is read from the
+				%s environment variable, or else entered by the user.`,
+				credentialPassphraseEnvVarName,
+			),
+			SilenceUsage: true, // do not print usage message when commands fail
+		}
+		flags credentialImportFlags
+	)
+	cmd.Flags().StringVar(&flags.kubeconfigPath, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to kubeconfig file")
+	cmd.Flags().StringVar(&flags.kubeconfigContextOverride, "kubeconfig-context", "", "Kubeconfig context name (default: current active context)")
+	mustRegisterFlagCompletion(cmd, "kubeconfig-context", completeKubeconfigContexts(&flags.kubeconfigPath))
+	cmd.Flags().StringVar(&flags.file, "file", "", "Path of the encrypted credential file to read")
+	mustMarkRequired(cmd, "file")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runCredentialImport(cmd, deps, flags)
+	}
+	return cmd
+}
+
+func runCredentialImport(cmd *cobra.Command, deps credentialCommandDeps, flags credentialImportFlags) error {
+	pLogger, err := SetLogLevel(cmd.Context(), deps.loginDeps.lookupEnv)
+	if err != nil {
+		plog.WarningErr("Received error while setting log level", err)
+	}
+
+	kubeconfig, err := loadOIDCLoginKubeconfig(flags.kubeconfigPath, flags.kubeconfigContextOverride)
+	if err != nil {
+		return err
+	}
+	credentialCachePath, _ := kubeconfig.flags.GetString("credential-cache")
+	if credentialCachePath == "" {
+		return fmt.Errorf("kubeconfig context %q disables the credential cache, so a credential cannot be imported", kubeconfig.contextName)
+	}
+
+	encrypted, err := os.ReadFile(flags.file)
+	if err != nil {
+		return fmt.Errorf("could not read credential file: %w", err)
+	}
+	crypter, err := credentialCrypter(cmd.Context(), deps, false)
+	if err != nil {
+		return err
+	}
+	plaintext, err := crypter.Decrypt(encrypted)
+	if err != nil {
+		return fmt.Errorf("could not decrypt credential file (is the passphrase correct?): %w", err)
+	}
+	var exported exportedCredential
+	if bytes.Equal(plaintext, encrypted) || json.Unmarshal(plaintext, &exported) != nil || exported.Credential == nil {
+		return fmt.Errorf("%s is not a credential file written by \"pinniped credential export\"", flags.file)
+	}
+
+	if exported.Server != kubeconfig.cluster.Server {
+		return fmt.Errorf("the credential was exported for cluster %s, but kubeconfig context %q uses cluster %s",
+			exported.Server, kubeconfig.contextName, kubeconfig.cluster.Server)
+	}
+	cred := exported.Credential
+	if cred.Status == nil || cred.Status.ExpirationTimestamp == nil || !cred.Status.ExpirationTimestamp.Time.After(deps.now()) {
+		return fmt.Errorf("the credential has expired, please export a new one")
+	}
+
+	// Store the credential using the same key as the exec credential plugin of the kubeconfig, which client-go runs
+	// with the arguments of the kubeconfig and, when requested, with information about the cluster.
+	cacheKey := oidcCredentialCacheKey{Args: kubeconfig.exec.Args}
+	if kubeconfig.exec.ProvideClusterInfo {
+		if cacheKey.ClusterInfo, err = execClusterInfo(kubeconfig.restConfig); err != nil {
+			return err
+		}
+	}
+	useOSKeychain, _ := kubeconfig.flags.GetBool("use-os-keychain")
+	cacheCrypter, err := cacheCrypter(useOSKeychain, deps.loginDeps.lookupEnv, deps.loginDeps.keyring, pLogger)
+	if err != nil {
+		return err
+	}
+	execcredcache.New(credentialCachePath, credCacheOptions(cacheCrypter)...).Put(cacheKey, cred)
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Imported the credential for cluster %s, which is valid until %s\n",
+		kubeconfig.cluster.Server, cred.Status.ExpirationTimestamp.Time.Format(time.RFC3339))
+	return nil
+}
+
+// execClusterInfo returns the information about the cluster which client-go passes to an exec credential plugin in
+// the KUBERNETES_EXEC_INFO env var, as read by loadClusterInfo.
+func execClusterInfo(config *rest.Config) (*clientauthv1beta1.Cluster, error) {
+	cluster, err := rest.ConfigToExecCluster(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not get cluster information from kubeconfig: %w", err)
+	}
+	var clusterInfo clientauthv1beta1.Cluster
+	if err := clientauthv1beta1.Convert_clientauthentication_Cluster_To_v1beta1_Cluster(cluster, &clusterInfo, nil); err != nil {
+		return nil, fmt.Errorf("could not get cluster information from kubeconfig: %w", err)
+	}
+	return &clusterInfo, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+func TestCredentialExportAndImport(t *testing.T) {
+	testCA, err := certauthority.New("Test CA", time.Hour)
+	require.NoError(t, err)
+	caData := base64.StdEncoding.EncodeToString(testCA.Bundle())
+	expiry := time.Date(2100, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	writeKubeconfig := func(t *testing.T, server string, loginCommand string) (string, string) {
+		tmp := testutil.TempDir(t)
+		path := filepath.Join(tmp, "kubeconfig.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(here.Docf(`
+			apiVersion: v1
+			kind: Config
+			current-context: pinniped
+			contexts:
+			- name: pinniped
+			  context:
+			    cluster: pinniped
+			    user: pinniped
+			clusters:
+			- name: pinniped
+			  cluster:
+			    server: %s
+			    certificate-authority-data: %s
+			users:
+			- name: pinniped
+			  user:
+			    exec:
+			      apiVersion: client.authentication.k8s.io/v1beta1
+			      command: pinniped
+			      args: [%s, --issuer=test-issuer, --client-id=test-client-id, --session-cache=%s/sessions.yaml, --credential-cache=%s/credentials.yaml]
+			      provideClusterInfo: true
+		`, server, caData, loginCommand, tmp, tmp)), 0600))
+		return path, tmp
+	}
+
+	newDeps := func(t *testing.T, env map[string]string, answers ...string) credentialCommandDeps {
+		return credentialCommandDeps{
+			loginDeps: oidcLoginCommandDeps{
+				lookupEnv: func(name string) (string, bool) {
+					value, ok := env[name]
+					return value, ok
+				},
+				login: func(issuer string, clientID string, opts ...oidcclient.Option) (*oidctypes.Token, error) {
+					require.Equal(t, "test-issuer", issuer)
+					require.Equal(t, "test-client-id", clientID)
+					return &oidctypes.Token{IDToken: &oidctypes.IDToken{Token: "test-id-token", Expiry: metav1.NewTime(expiry)}}, nil
+				},
+				keyring: &fakeKeyring{err: fmt.Errorf("some keychain error")},
+			},
+			promptForSecret: func(_ context.Context, _ string) (string, error) {
+				if len(answers) == 0 {
+					return "", fmt.Errorf("some prompt error")
+				}
+				answer := answers[0]
+				answers = answers[1:]
+				return answer, nil
+			},
+			now: func() time.Time { return time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC) },
+		}
+	}
+
+	runCommand := func(export bool, deps credentialCommandDeps, args ...string) (string, string, error) {
+		cmd := credentialImportCommand(deps)
+		if export {
+			cmd = credentialExportCommand(deps)
+		}
+		var stdout, stderr bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		cmd.SetArgs(args)
+		err := cmd.ExecuteContext(context.Background())
+		return stdout.String(), stderr.String(), err
+	}
+
+	exportingKubeconfig, _ := writeKubeconfig(t, "https://cluster.example.com", "login, oidc")
+	exportedFile := filepath.Join(testutil.TempDir(t), "credential.pinniped")
+	stdout, _, err := runCommand(true, newDeps(t, map[string]string{credentialPassphraseEnvVarName: "test-passphrase"}),
+		"--kubeconfig", exportingKubeconfig, "--file", exportedFile)
+	require.NoError(t, err)
+	require.Equal(t, "Exported the credential for cluster https://cluster.example.com, which is valid until 2100-01-02T03:04:05Z, to "+exportedFile+"\n", stdout)
+	contents, err := os.ReadFile(exportedFile)
+	require.NoError(t, err)
+	require.NotContains(t, string(contents), "test-id-token")
+
+	t.Run("import", func(t *testing.T) {
+		importingKubeconfig, tmp := writeKubeconfig(t, "https://cluster.example.com", "login, oidc")
+		stdout, _, err := runCommand(false, newDeps(t, nil, "test-passphrase"), "--kubeconfig", importingKubeconfig, "--file", exportedFile)
+		require.NoError(t, err)
+		require.Equal(t, "Imported the credential for cluster https://cluster.example.com, which is valid until 2100-01-02T03:04:05Z\n", stdout)
+
+		// Run the exec credential plugin of the kubeconfig the way that client-go would, and check that it uses the
+		// imported credential without logging in.
+		originalArgs := os.Args
+		t.Cleanup(func() { os.Args = originalArgs })
+		os.Args = []string{"pinniped", "login", "oidc", "--issuer=test-issuer", "--client-id=test-client-id",
+			"--session-cache=" + tmp + "/sessions.yaml", "--credential-cache=" + tmp + "/credentials.yaml"}
+		t.Setenv("KUBERNETES_EXEC_INFO", `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1",`+
+			`"spec":{"cluster":{"server":"https://cluster.example.com","certificate-authority-data":"`+caData+`"},"interactive":false}}`)
+		loginCmd := oidcLoginCommand(oidcLoginCommandDeps{
+			lookupEnv: func(string) (string, bool) { return "", false },
+			login: func(string, string, ...oidcclient.Option) (*oidctypes.Token, error) {
+				t.Fatal("the plugin should use the imported credential instead of logging in")
+				return nil, nil
+			},
+			keyring: &fakeKeyring{err: fmt.Errorf("some keychain error")},
+		})
+		var loginStdout bytes.Buffer
+		loginCmd.SetOut(&loginStdout)
+		loginCmd.SetArgs(os.Args[3:])
+		require.NoError(t, loginCmd.ExecuteContext(context.Background()))
+		require.Equal(t, `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"2100-01-02T03:04:05Z","token":"test-id-token"}}`+"\n", loginStdout.String())
+	})
+
+	tests := []struct {
+		name       string
+		export     bool
+		server     string
+		login      string
+		args       []string
+		env        map[string]string
+		answers    []string
+		wantStderr string
+	}{
+		{
+			name:       "export passphrases do not match",
+			export:     true,
+			answers:    []string{"test-passphrase", "other-passphrase"},
+			wantStderr: "Error: the passphrases do not match\n",
+		},
+		{
+			name:       "export without a terminal",
+			export:     true,
+			wantStderr: "Error: could not read passphrase (some prompt error), please set PINNIPED_CREDENTIAL_PASSPHRASE\n",
+		},
+		{
+			name:       "kubeconfig does not use pinniped login oidc",
+			login:      "login, static, --token=test-token",
+			answers:    []string{"test-passphrase"},
+			wantStderr: "Error: kubeconfig context \"pinniped\" does not use \"pinniped login oidc\"\n",
+		},
+		{
+			name:       "import with the wrong passphrase",
+			answers:    []string{"wrong-passphrase"},
+			wantStderr: "Error: could not decrypt credential file (is the passphrase correct?): could not decrypt data: cipher: message authentication failed\n",
+		},
+		{
+			name:       "import for a different cluster",
+			server:     "https://other-cluster.example.com",
+			answers:    []string{"test-passphrase"},
+			wantStderr: "Error: the credential was exported for cluster https://cluster.example.com, but kubeconfig context \"pinniped\" uses cluster https://other-cluster.example.com\n",
+		},
+		{
+			name:       "import a file which was not exported",
+			args:       []string{"--file", exportingKubeconfig},
+			answers:    []string{"test-passphrase"},
+			wantStderr: "Error: " + exportingKubeconfig + " is not a credential file written by \"pinniped credential export\"\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.server
+			if server == "" {
+				server = "https://cluster.example.com"
+			}
+			login := tt.login
+			if login == "" {
+				login = "login, oidc"
+			}
+			kubeconfig, tmp := writeKubeconfig(t, server, login)
+			args := append([]string{"--kubeconfig", kubeconfig}, tt.args...)
+			if len(tt.args) == 0 {
+				if tt.export {
+					args = append(args, "--file", filepath.Join(tmp, "credential.pinniped"))
+				} else {
+					args = append(args, "--file", exportedFile)
+				}
+			}
+
+			_, stderr, err := runCommand(tt.export, newDeps(t, tt.env, tt.answers...), args...)
+			require.Error(t, err)
+			require.Equal(t, tt.wantStderr, stderr)
+			require.False(t, strings.Contains(stderr, "test-id-token"))
+		})
+	}
+}
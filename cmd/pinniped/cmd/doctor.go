@@ -0,0 +1,599 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	idpdiscoveryv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
+	"go.pinniped.dev/internal/cachecrypter"
+	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/net/pproxy"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/pkg/conciergeclient"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/filesession"
+)
+
+const (
+	// doctorCertificateExpiryWarning is how long before a CA certificate expires that the doctor warns about it.
+	doctorCertificateExpiryWarning = 30 * 24 * time.Hour
+
+	// doctorMaxClockSkew is the largest difference between the local clock and the clock of the Supervisor which does
+	// not cause a problem. Larger differences may cause freshly issued tokens to be rejected as not yet valid, or
+	// cached tokens to be used after they have expired.
+	doctorMaxClockSkew = time.Minute
+
+	// doctorProbeToken is the token which is sent to the Concierge to check that it answers TokenCredentialRequests.
+	// It is never valid, so the Concierge is expected to reject it.
+	doctorProbeToken = "pinniped-doctor-probe-token" //nolint:gosec // this is not a credential
+)
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(doctorCommand(doctorCommandRealDeps()))
+}
+
+type doctorCommandDeps struct {
+	lookupEnv     func(string) (string, bool)
+	exchangeToken func(context.Context, *conciergeclient.Client, string) (*clientauthv1beta1.ExecCredential, error)
+	keyring       cachecrypter.Keyring
+	systemPACURL  func() string
+	now           func() time.Time
+}
+
+func doctorCommandRealDeps() doctorCommandDeps {
+	return doctorCommandDeps{
+		lookupEnv: os.LookupEnv,
+		exchangeToken: func(ctx context.Context, client *conciergeclient.Client, token string) (*clientauthv1beta1.ExecCredential, error) {
+			return client.ExchangeToken(ctx, token)
+		},
+		keyring:      cachecrypter.OSKeyring(),
+		systemPACURL: pproxy.SystemPACURL,
+		now:          time.Now,
+	}
+}
+
+type doctorFlags struct {
+	kubeconfigPath            string
+	kubeconfigContextOverride string
+	timeout                   time.Duration
+}
+
+func doctorCommand(deps doctorCommandDeps) *cobra.Command {
+	var (
+		cmd = &cobra.Command{
+			Args:  cobra.NoArgs,
+			Use:   "doctor",
+			Short: "Diagnose problems with a Pinniped-compatible kubeconfig",
+			Long: here.Doc(
+				`Diagnose problems with a Pinniped-compatible kubeconfig
+
+				Checks the "pinniped login" command of the current (or selected) kubeconfig
+				context without logging in: that its CA bundles can be parsed and are not
+				expiring, that the cluster and the Supervisor can be reached, that the clock of
+				this machine agrees with the clock of the Supervisor, that the Concierge answers
+				TokenCredentialRequests (using a token which is never valid), and whether the
+				session cache holds a usable session. For each problem that it finds, it prints
+				the steps which may fix it.`,
+			),
+			SilenceUsage: true, // do not print usage message when commands fail
+		}
+		flags doctorFlags
+	)
+	cmd.Flags().StringVar(&flags.kubeconfigPath, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to kubeconfig file")
+	cmd.Flags().StringVar(&flags.kubeconfigContextOverride, "kubeconfig-context", "", "Kubeconfig context name (default: current active context)")
+	mustRegisterFlagCompletion(cmd, "kubeconfig-context", completeKubeconfigContexts(&flags.kubeconfigPath))
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 30*time.Second, "Timeout for all the checks which make network requests")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runDoctor(cmd, deps, flags)
+	}
+	return cmd
+}
+
+type doctorStatus string
+
+const (
+	doctorStatusOK      doctorStatus = "OK"
+	doctorStatusWarning doctorStatus = "WARNING"
+	doctorStatusProblem doctorStatus = "PROBLEM"
+)
+
+// doctor prints the result of each check as soon as it is known, and counts the problems.
+type doctor struct {
+	out      io.Writer
+	problems int
+}
+
+// report prints the result of a check, along with the steps which may fix it, if it did not pass.
+func (d *doctor) report(status doctorStatus, check, message, remediation string) {
+	_, _ = fmt.Fprintf(d.out, "%-9s %s: %s\n", "["+status+"]", check, message)
+	if remediation != "" && status != doctorStatusOK {
+		_, _ = fmt.Fprintf(d.out, "%-9s To fix: %s\n", "", remediation)
+	}
+	if status == doctorStatusProblem {
+		d.problems++
+	}
+}
+
+// doctorLogin holds the settings of the "pinniped login" command from the kubeconfig which are checked.
+type doctorLogin struct {
+	subcommand string // "oidc" or "static"
+	flags      *pflag.FlagSet
+}
+
+func (l *doctorLogin) string(name string) string {
+	value, _ := l.flags.GetString(name)
+	return value
+}
+
+func (l *doctorLogin) stringSlice(name string) []string {
+	value, _ := l.flags.GetStringSlice(name)
+	return value
+}
+
+func (l *doctorLogin) bool(name string) bool {
+	value, _ := l.flags.GetBool(name)
+	return value
+}
+
+func runDoctor(cmd *cobra.Command, deps doctorCommandDeps, flags doctorFlags) error {
+	pLogger, err := SetLogLevel(cmd.Context(), deps.lookupEnv)
+	if err != nil {
+		plog.WarningErr("Received error while setting log level", err)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), flags.timeout)
+	defer cancel()
+
+	d := &doctor{out: cmd.OutOrStdout()}
+	cluster, login := d.checkKubeconfig(flags)
+	if cluster != nil {
+		d.checkCluster(ctx, cluster)
+	}
+	if login != nil {
+		d.checkLogin(ctx, deps, pLogger, cluster, login)
+	}
+
+	if d.problems > 0 {
+		return fmt.Errorf("found %d problem(s)", d.problems)
+	}
+	_, _ = fmt.Fprintln(d.out, "No problems found.")
+	return nil
+}
+
+// checkKubeconfig finds the cluster and the "pinniped login" command of the kubeconfig context. It returns a nil
+// cluster when there is none, and a nil login when the context does not use the Pinniped CLI.
+func (d *doctor) checkKubeconfig(flags doctorFlags) (*clientcmdapi.Cluster, *doctorLogin) {
+	const check = "Kubeconfig"
+	kubeconfig, err := newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride).RawConfig()
+	if err != nil {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("could not load kubeconfig: %v", err),
+			"Check the --kubeconfig flag and the KUBECONFIG environment variable.")
+		return nil, nil
+	}
+	contextName := kubeconfig.CurrentContext
+	if flags.kubeconfigContextOverride != "" {
+		contextName = flags.kubeconfigContextOverride
+	}
+	kubeContext, ok := kubeconfig.Contexts[contextName]
+	if !ok {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("could not find kubeconfig context %q", contextName),
+			`Choose an existing context using --kubeconfig-context or "kubectl config use-context".`)
+		return nil, nil
+	}
+	cluster, ok := kubeconfig.Clusters[kubeContext.Cluster]
+	if !ok {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("kubeconfig context %q refers to cluster %q, which does not exist", contextName, kubeContext.Cluster),
+			`Generate a Pinniped-compatible kubeconfig using "pinniped get kubeconfig".`)
+		return nil, nil
+	}
+
+	authInfo, ok := kubeconfig.AuthInfos[kubeContext.AuthInfo]
+	if !ok || authInfo.Exec == nil || len(authInfo.Exec.Args) < 2 || authInfo.Exec.Args[0] != "login" ||
+		(authInfo.Exec.Args[1] != "oidc" && authInfo.Exec.Args[1] != "static") {
+		d.report(doctorStatusProblem, check, fmt.Sprintf(`kubeconfig context %q does not use "pinniped login oidc" or "pinniped login static"`, contextName),
+			`Generate a Pinniped-compatible kubeconfig using "pinniped get kubeconfig".`)
+		return cluster, nil
+	}
+
+	login := &doctorLogin{subcommand: authInfo.Exec.Args[1]}
+	loginCmd := oidcLoginCommand(oidcLoginCommandDeps{})
+	if login.subcommand == "static" {
+		loginCmd = staticLoginCommand(staticLoginDeps{})
+	}
+	if err := loginCmd.ParseFlags(authInfo.Exec.Args[2:]); err != nil {
+		d.report(doctorStatusProblem, check, fmt.Sprintf(`could not parse "pinniped login %s" arguments from kubeconfig context %q: %v`, login.subcommand, contextName, err),
+			`Generate a Pinniped-compatible kubeconfig using the "pinniped get kubeconfig" command of the same version of the Pinniped CLI.`)
+		return cluster, nil
+	}
+	login.flags = loginCmd.Flags()
+
+	d.report(doctorStatusOK, check, fmt.Sprintf(`kubeconfig context %q uses "pinniped login %s" for cluster %q`, contextName, login.subcommand, cluster.Server), "")
+	return cluster, login
+}
+
+// checkCluster checks the CA bundle of the cluster, and that the cluster can be reached.
+func (d *doctor) checkCluster(ctx context.Context, cluster *clientcmdapi.Cluster) {
+	const check = "Cluster"
+	if cluster.InsecureSkipTLSVerify {
+		d.report(doctorStatusWarning, check, "the kubeconfig does not verify the certificate of the cluster (insecure-skip-tls-verify)",
+			`Generate a Pinniped-compatible kubeconfig using "pinniped get kubeconfig", which includes the CA bundle of the cluster.`)
+	}
+
+	caData := cluster.CertificateAuthorityData
+	if len(caData) == 0 && cluster.CertificateAuthority != "" {
+		var err error
+		if caData, err = os.ReadFile(cluster.CertificateAuthority); err != nil {
+			d.report(doctorStatusProblem, "Cluster CA bundle", fmt.Sprintf("could not read %s: %v", cluster.CertificateAuthority, err),
+				`Generate a Pinniped-compatible kubeconfig using "pinniped get kubeconfig", which embeds the CA bundle of the cluster.`)
+			return
+		}
+	}
+	var rootCAs *x509.CertPool
+	if len(caData) > 0 {
+		if rootCAs = d.checkCABundle("Cluster CA bundle", caData); rootCAs == nil {
+			return
+		}
+	}
+
+	client := phttp.Default(rootCAs)
+	if cluster.InsecureSkipTLSVerify {
+		tlsConfig := ptls.Default(nil)
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // the kubeconfig asks for it
+		client = &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}}
+	}
+	resp, err := doctorGet(ctx, client, cluster.Server)
+	if err != nil {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("could not connect to %s: %v", cluster.Server, err), remediationForRequestError(err, "the cluster"))
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("%s answered with unexpected HTTP response status %d", cluster.Server, resp.StatusCode),
+			"Ask your cluster administrator to check the health of the cluster.")
+		return
+	}
+	d.report(doctorStatusOK, check, fmt.Sprintf("%s is reachable", cluster.Server), "")
+}
+
+// checkLogin checks the settings of the "pinniped login" command: the Supervisor, the Concierge, and the session cache.
+func (d *doctor) checkLogin(ctx context.Context, deps doctorCommandDeps, pLogger plog.Logger, cluster *clientcmdapi.Cluster, login *doctorLogin) {
+	proxy, err := makeProxy(login.string("proxy"), login.string("proxy-pac-url"), deps.lookupEnv, deps.systemPACURL, pLogger)
+	if err != nil {
+		d.report(doctorStatusProblem, "Proxy", err.Error(),
+			`Fix the --proxy or --proxy-pac-url arguments of "pinniped login" in the kubeconfig, e.g. by regenerating it using "pinniped get kubeconfig".`)
+		return
+	}
+
+	if login.subcommand == "oidc" {
+		d.checkSupervisor(ctx, deps, login, proxy)
+	}
+	if login.bool("enable-concierge") {
+		d.checkConcierge(ctx, deps, cluster, login, proxy)
+	}
+	if login.subcommand == "oidc" {
+		d.checkSessionCache(deps, pLogger, login)
+	}
+}
+
+// checkSupervisor checks the CA bundles of the issuer, that its OIDC discovery can be fetched, the clock skew between
+// this machine and the issuer, and that the chosen upstream identity provider exists.
+func (d *doctor) checkSupervisor(ctx context.Context, deps doctorCommandDeps, login *doctorLogin, proxy func(*http.Request) (*url.URL, error)) {
+	const check = "Supervisor discovery"
+	caBundlePaths, caBundleData := login.stringSlice("ca-bundle"), login.stringSlice("ca-bundle-data")
+	for _, path := range caBundlePaths {
+		bundle, err := os.ReadFile(path)
+		if err != nil {
+			d.report(doctorStatusProblem, "Issuer CA bundle", fmt.Sprintf("could not read %s: %v", path, err),
+				`Fix the --ca-bundle arguments of "pinniped login oidc" in the kubeconfig, or regenerate it using "pinniped get kubeconfig".`)
+			return
+		}
+		if d.checkCABundle("Issuer CA bundle", bundle) == nil {
+			return
+		}
+	}
+	for _, data := range caBundleData {
+		bundle, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			d.report(doctorStatusProblem, "Issuer CA bundle", fmt.Sprintf("could not decode --ca-bundle-data: %v", err),
+				`Regenerate the kubeconfig using "pinniped get kubeconfig".`)
+			return
+		}
+		if d.checkCABundle("Issuer CA bundle", bundle) == nil {
+			return
+		}
+	}
+
+	client, err := makeClient(caBundlePaths, caBundleData, proxy)
+	if err != nil {
+		d.report(doctorStatusProblem, check, err.Error(), `Regenerate the kubeconfig using "pinniped get kubeconfig".`)
+		return
+	}
+	issuer := login.string("issuer")
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	resp, err := doctorGet(ctx, client, discoveryURL)
+	if err != nil {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("could not fetch %s: %v", discoveryURL, err), remediationForRequestError(err, "the Supervisor"))
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	d.checkClockSkew(deps, resp)
+	if resp.StatusCode != http.StatusOK {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("%s answered with unexpected HTTP response status %d", discoveryURL, resp.StatusCode),
+			"Check that the issuer in the kubeconfig is the issuer of a FederationDomain of the Supervisor, "+
+				`or regenerate the kubeconfig using "pinniped get kubeconfig".`)
+		return
+	}
+	var discovery struct {
+		Issuer string `json:"issuer"`
+		idpdiscoveryv1alpha1.OIDCDiscoveryResponse
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("could not decode %s: %v", discoveryURL, err),
+			"Check that the issuer in the kubeconfig is the issuer of a FederationDomain of the Supervisor.")
+		return
+	}
+	if discovery.Issuer != issuer {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("the issuer is %q, but the kubeconfig expects %q", discovery.Issuer, issuer),
+			`Regenerate the kubeconfig using "pinniped get kubeconfig", or ask your administrator to fix the issuer of the FederationDomain.`)
+		return
+	}
+	d.report(doctorStatusOK, check, fmt.Sprintf("%s is reachable", issuer), "")
+
+	if discovery.SupervisorDiscovery.PinnipedIDPsEndpoint != "" {
+		d.checkUpstreamIdentityProviders(ctx, client, discovery.SupervisorDiscovery.PinnipedIDPsEndpoint, login)
+	}
+}
+
+// checkClockSkew compares the local clock with the Date header of a response.
+func (d *doctor) checkClockSkew(deps doctorCommandDeps, resp *http.Response) {
+	const check = "Clock skew"
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return // The response does not tell the time, so the clocks cannot be compared.
+	}
+	// The Date header has a precision of one second, so the fraction of a second is not part of the skew.
+	skew := deps.now().Sub(date)
+	if skew < 0 {
+		skew = -skew
+	}
+	skew = skew.Truncate(time.Second)
+	if skew > doctorMaxClockSkew {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("the clock of this machine differs from the clock of the Supervisor by %s", skew),
+			"Synchronize the clock of this machine, e.g. using NTP, or ask your administrator to synchronize the clock of the Supervisor.")
+		return
+	}
+	d.report(doctorStatusOK, check, fmt.Sprintf("the clock of this machine is within %s of the clock of the Supervisor", doctorMaxClockSkew), "")
+}
+
+// checkUpstreamIdentityProviders checks that the upstream identity provider chosen by the kubeconfig exists, or that
+// there is at least one which the user can choose during login.
+func (d *doctor) checkUpstreamIdentityProviders(ctx context.Context, client *http.Client, endpoint string, login *doctorLogin) {
+	const check = "Upstream identity providers"
+	idps, err := discoverAllAvailableSupervisorUpstreamIDPs(ctx, endpoint, client)
+	if err != nil {
+		d.report(doctorStatusProblem, check, err.Error(), "Ask your administrator to check the health of the Supervisor.")
+		return
+	}
+
+	names := make([]string, 0, len(idps))
+	for _, idp := range idps {
+		names = append(names, fmt.Sprintf("%s (%s)", idp.Name, idp.Type))
+	}
+	if name := login.string("upstream-identity-provider-name"); name != "" {
+		idpType := login.string("upstream-identity-provider-type")
+		for _, idp := range idps {
+			if idp.Name == name && idp.Type.Equals(idpType) {
+				d.report(doctorStatusOK, check, fmt.Sprintf("the kubeconfig uses %s (%s)", name, idpType), "")
+				return
+			}
+		}
+		d.report(doctorStatusProblem, check, fmt.Sprintf("the kubeconfig uses %s (%s), which the Supervisor does not have (found: %s)", name, idpType, strings.Join(names, ", ")),
+			`Regenerate the kubeconfig using "pinniped get kubeconfig" to use one of the upstream identity providers of the Supervisor.`)
+		return
+	}
+	switch len(idps) {
+	case 0:
+		d.report(doctorStatusProblem, check, "the Supervisor does not have any upstream identity providers",
+			"Ask your administrator to configure an upstream identity provider for the Supervisor.")
+	case 1:
+		d.report(doctorStatusOK, check, fmt.Sprintf("the Supervisor uses %s", names[0]), "")
+	default:
+		d.report(doctorStatusOK, check, fmt.Sprintf("found %s, and the user will be asked to choose one during login", strings.Join(names, ", ")), "")
+	}
+}
+
+// checkConcierge checks the CA bundle of the Concierge, and that the Concierge answers TokenCredentialRequests using
+// the authenticator of the kubeconfig.
+func (d *doctor) checkConcierge(ctx context.Context, deps doctorCommandDeps, cluster *clientcmdapi.Cluster, login *doctorLogin, proxy func(*http.Request) (*url.URL, error)) {
+	const check = "Concierge"
+	caBundle := login.string("concierge-ca-bundle-data")
+	if caBundle != "" {
+		bundle, err := base64.StdEncoding.DecodeString(caBundle)
+		if err != nil {
+			d.report(doctorStatusProblem, "Concierge CA bundle", fmt.Sprintf("could not decode --concierge-ca-bundle-data: %v", err),
+				`Regenerate the kubeconfig using "pinniped get kubeconfig".`)
+			return
+		}
+		if d.checkCABundle("Concierge CA bundle", bundle) == nil {
+			return
+		}
+	}
+
+	endpoint := login.string("concierge-endpoint")
+	if endpoint == "" && cluster != nil {
+		endpoint = cluster.Server
+	}
+	authenticatorType, authenticatorName := login.string("concierge-authenticator-type"), login.string("concierge-authenticator-name")
+	client, err := conciergeclient.New(
+		conciergeclient.WithEndpoint(endpoint),
+		conciergeclient.WithBase64CABundle(caBundle),
+		conciergeclient.WithAuthenticator(authenticatorType, authenticatorName),
+		conciergeclient.WithAPIGroupSuffix(login.string("concierge-api-group-suffix")),
+		conciergeclient.WithProxy(proxy),
+	)
+	if err != nil {
+		d.report(doctorStatusProblem, check, fmt.Sprintf("invalid Concierge parameters: %v", err), `Regenerate the kubeconfig using "pinniped get kubeconfig".`)
+		return
+	}
+
+	// The probe token is never valid, so a healthy Concierge answers by saying that the login failed.
+	_, err = deps.exchangeToken(ctx, client, doctorProbeToken)
+	switch {
+	case err == nil, errors.Is(err, conciergeclient.ErrLoginFailed):
+		d.report(doctorStatusOK, check, fmt.Sprintf("%s answers TokenCredentialRequests for the %s authenticator %q", endpoint, authenticatorType, authenticatorName), "")
+	case apierrors.IsNotFound(err):
+		d.report(doctorStatusProblem, check, fmt.Sprintf("%s does not serve the TokenCredentialRequest API: %v", endpoint, err),
+			"Ask your cluster administrator to check that the Concierge is installed with the API group suffix of the kubeconfig, "+
+				`and to check the status of its strategies using "kubectl get credentialissuer -o yaml".`)
+	default:
+		d.report(doctorStatusProblem, check, fmt.Sprintf("could not make a TokenCredentialRequest to %s: %v", endpoint, err),
+			remediationForRequestError(err, "the Concierge")+" "+
+				`Your cluster administrator can check the status of the Concierge strategies using "kubectl get credentialissuer -o yaml".`)
+	}
+}
+
+// checkSessionCache reports whether the session cache holds a session for the issuer which can be used without a new
+// login.
+func (d *doctor) checkSessionCache(deps doctorCommandDeps, pLogger plog.Logger, login *doctorLogin) {
+	const check = "Session cache"
+	crypter, err := cacheCrypter(login.bool("use-os-keychain"), deps.lookupEnv, deps.keyring, pLogger)
+	if err != nil {
+		d.report(doctorStatusWarning, check, err.Error(), "Fix the cache encryption settings, or the next login will fail.")
+		return
+	}
+	var sessionOptions []filesession.Option
+	if crypter != nil {
+		sessionOptions = append(sessionOptions, filesession.WithCrypter(crypter))
+	}
+	var readErr error
+	sessionOptions = append(sessionOptions, filesession.WithErrorReporter(func(err error) { readErr = err }))
+
+	issuer, path := login.string("issuer"), login.string("session-cache")
+	sessions := filesession.New(path, sessionOptions...).FindTokens(func(key oidcclient.SessionCacheKey) bool {
+		return key.Issuer == issuer
+	})
+	if readErr != nil {
+		d.report(doctorStatusWarning, check, fmt.Sprintf("could not read %s: %v", path, readErr),
+			"The session cache will be reset during the next login, which will ask you to log in again.")
+		return
+	}
+
+	var refreshable bool
+	for _, session := range sessions {
+		if idToken := session.Tokens.IDToken; idToken != nil && idToken.Expiry.Time.After(deps.now()) {
+			d.report(doctorStatusOK, check, fmt.Sprintf("found a session for %s which is valid until %s", issuer, idToken.Expiry.Time.Format(time.RFC3339)), "")
+			return
+		}
+		if session.Tokens.RefreshToken != nil && session.Tokens.RefreshToken.Token != "" {
+			refreshable = true
+		}
+	}
+	if refreshable {
+		d.report(doctorStatusOK, check, fmt.Sprintf("found an expired session for %s, which will be refreshed during the next login", issuer), "")
+		return
+	}
+	d.report(doctorStatusOK, check, fmt.Sprintf("found no session for %s, so you will be asked to log in", issuer), "")
+}
+
+// checkCABundle reports whether the PEM-encoded CA bundle has any certificates, and whether they are expired or
+// expiring soon. It returns the pool of the certificates, or nil when the bundle has none.
+func (d *doctor) checkCABundle(check string, caBundle []byte) *x509.CertPool {
+	pool := x509.NewCertPool()
+	var certs []*x509.Certificate
+	for rest := caBundle; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			d.report(doctorStatusProblem, check, fmt.Sprintf("could not parse certificate: %v", err),
+				`Regenerate the kubeconfig using "pinniped get kubeconfig", or ask your administrator for the current CA bundle.`)
+			return nil
+		}
+		certs = append(certs, cert)
+		pool.AddCert(cert)
+	}
+	if len(certs) == 0 {
+		d.report(doctorStatusProblem, check, "no certificates found",
+			`Regenerate the kubeconfig using "pinniped get kubeconfig", or ask your administrator for the current CA bundle.`)
+		return nil
+	}
+
+	now := time.Now()
+	for _, cert := range certs {
+		switch {
+		case now.After(cert.NotAfter):
+			d.report(doctorStatusProblem, check, fmt.Sprintf("certificate %q expired at %s", cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339)),
+				`Ask your administrator to renew the CA, and then regenerate the kubeconfig using "pinniped get kubeconfig".`)
+			return pool
+		case now.Add(doctorCertificateExpiryWarning).After(cert.NotAfter):
+			d.report(doctorStatusWarning, check, fmt.Sprintf("certificate %q expires at %s", cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339)),
+				`Ask your administrator whether the CA will be renewed, and then regenerate the kubeconfig using "pinniped get kubeconfig".`)
+			return pool
+		}
+	}
+	d.report(doctorStatusOK, check, fmt.Sprintf("found %d valid certificate(s)", len(certs)), "")
+	return pool
+}
+
+func doctorGet(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// remediationForRequestError describes how the user may fix an error which happened while connecting to a server.
+func remediationForRequestError(err error, server string) string {
+	var (
+		unknownAuthorityErr x509.UnknownAuthorityError
+		hostnameErr         x509.HostnameError
+		invalidCertErr      x509.CertificateInvalidError
+		dnsErr              *net.DNSError
+	)
+	switch {
+	case errors.As(err, &unknownAuthorityErr):
+		return fmt.Sprintf(`The certificate of %s is not signed by a CA of the kubeconfig. Regenerate the kubeconfig using "pinniped get kubeconfig" to get the current CA bundle.`, server)
+	case errors.As(err, &hostnameErr):
+		return fmt.Sprintf("The certificate of %s is not valid for its hostname. Check that the URL in the kubeconfig is correct, or ask your administrator to fix the certificate.", server)
+	case errors.As(err, &invalidCertErr):
+		return fmt.Sprintf("The certificate of %s is expired or not yet valid. Check the clock of this machine, or ask your administrator to renew the certificate.", server)
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("The hostname of %s could not be resolved. Check the URL in the kubeconfig, and your DNS or VPN settings.", server)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("Timed out while connecting to %s. Check your network connection, firewalls, and proxy settings, or use a longer --timeout.", server)
+	default:
+		return fmt.Sprintf("Check that %s can be reached from this machine, and check your proxy settings.", server)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+	"k8s.io/client-go/util/cert"
+
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/tlsserver"
+	"go.pinniped.dev/pkg/conciergeclient"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/filesession"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+func TestDoctorCommand(t *testing.T) {
+	var idps string
+	server := tlsserver.TLSTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_, _ = fmt.Fprintf(w, `{"issuer": "https://%s", "discovery.supervisor.pinniped.dev/v1alpha1": {"pinniped_identity_providers_endpoint": "https://%s/idps"}}`, r.Host, r.Host)
+		case "/idps":
+			_, _ = fmt.Fprint(w, idps)
+		default:
+			http.NotFound(w, r)
+		}
+	}), nil)
+	serverCA := base64.StdEncoding.EncodeToString(tlsserver.TLSTestServerCA(server))
+
+	otherCA, err := certauthority.New("Other CA", 365*24*time.Hour)
+	require.NoError(t, err)
+	expiringCA, err := certauthority.New("Expiring CA", 24*time.Hour)
+	require.NoError(t, err)
+	expiringCACert, err := cert.ParseCertsPEM(expiringCA.Bundle())
+	require.NoError(t, err)
+
+	oidcKubeconfig := func(extraArgs ...string) string {
+		args := append([]string{
+			"login", "oidc",
+			"--issuer=ISSUER",
+			"--ca-bundle-data=" + serverCA,
+			"--session-cache=TMPDIR/sessions.yaml",
+			"--enable-concierge",
+			"--concierge-authenticator-type=jwt",
+			"--concierge-authenticator-name=test-authenticator",
+			"--concierge-endpoint=ISSUER",
+			"--concierge-ca-bundle-data=" + serverCA,
+		}, extraArgs...)
+		return here.Docf(`
+			apiVersion: v1
+			kind: Config
+			current-context: pinniped
+			contexts:
+			- name: pinniped
+			  context:
+			    cluster: pinniped
+			    user: pinniped
+			clusters:
+			- name: pinniped
+			  cluster:
+			    server: ISSUER
+			    certificate-authority-data: %s
+			users:
+			- name: pinniped
+			  user:
+			    exec:
+			      apiVersion: client.authentication.k8s.io/v1beta1
+			      command: pinniped
+			      args: ["%s"]
+		`, serverCA, strings.Join(args, `", "`))
+	}
+
+	tests := []struct {
+		name string
+		// args, kubeconfig, and wantStdout may use the placeholders ISSUER and TMPDIR, which are replaced by the URL
+		// of the test server and by a per-test directory.
+		args        []string
+		kubeconfig  string
+		idps        string
+		session     *oidctypes.Token
+		clockOffset time.Duration
+		exchangeErr error
+
+		wantError              bool
+		wantStdout, wantStderr string
+	}{
+		{
+			name: "help flag passed",
+			args: []string{"--help"},
+			wantStdout: here.Doc(`
+				Diagnose problems with a Pinniped-compatible kubeconfig
+
+				Checks the "pinniped login" command of the current (or selected) kubeconfig
+				context without logging in: that its CA bundles can be parsed and are not
+				expiring, that the cluster and the Supervisor can be reached, that the clock of
+				this machine agrees with the clock of the Supervisor, that the Concierge answers
+				TokenCredentialRequests (using a token which is never valid), and whether the
+				session cache holds a usable session. For each problem that it finds, it prints
+				the steps which may fix it.
+
+				Usage:
+				  doctor [flags]
+
+				Flags:
+				  -h, --help                        help for doctor
+				      --kubeconfig string           Path to kubeconfig file
+				      --kubeconfig-context string   Kubeconfig context name (default: current active context)
+				      --timeout duration            Timeout for all the checks which make network requests (default 30s)
+			`),
+		},
+		{
+			name:        "no problems",
+			kubeconfig:  oidcKubeconfig("--upstream-identity-provider-name=some-ldap-idp", "--upstream-identity-provider-type=ldap"),
+			idps:        `{"pinniped_identity_providers": [{"name": "some-ldap-idp", "type": "ldap"}, {"name": "some-oidc-idp", "type": "oidc"}]}`,
+			session:     &oidctypes.Token{IDToken: &oidctypes.IDToken{Token: "test-id-token", Expiry: metav1.NewTime(time.Date(2100, 1, 2, 3, 4, 5, 0, time.UTC))}},
+			exchangeErr: fmt.Errorf("%w: some authentication error", conciergeclient.ErrLoginFailed),
+			wantStdout: here.Doc(`
+				[OK]      Kubeconfig: kubeconfig context "pinniped" uses "pinniped login oidc" for cluster "ISSUER"
+				[OK]      Cluster CA bundle: found 1 valid certificate(s)
+				[OK]      Cluster: ISSUER is reachable
+				[OK]      Issuer CA bundle: found 1 valid certificate(s)
+				[OK]      Clock skew: the clock of this machine is within 1m0s of the clock of the Supervisor
+				[OK]      Supervisor discovery: ISSUER is reachable
+				[OK]      Upstream identity providers: the kubeconfig uses some-ldap-idp (ldap)
+				[OK]      Concierge CA bundle: found 1 valid certificate(s)
+				[OK]      Concierge: ISSUER answers TokenCredentialRequests for the jwt authenticator "test-authenticator"
+				[OK]      Session cache: found a session for ISSUER which is valid until 2100-01-02T03:04:05Z
+				No problems found.
+			`),
+		},
+		{
+			name:       "upstream identity provider chosen during login and expired session",
+			kubeconfig: oidcKubeconfig(),
+			idps:       `{"pinniped_identity_providers": [{"name": "some-ldap-idp", "type": "ldap"}, {"name": "some-oidc-idp", "type": "oidc"}]}`,
+			session: &oidctypes.Token{
+				IDToken:      &oidctypes.IDToken{Token: "test-id-token", Expiry: metav1.NewTime(time.Now().Add(-time.Hour))},
+				RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token"},
+			},
+			wantStdout: here.Doc(`
+				[OK]      Kubeconfig: kubeconfig context "pinniped" uses "pinniped login oidc" for cluster "ISSUER"
+				[OK]      Cluster CA bundle: found 1 valid certificate(s)
+				[OK]      Cluster: ISSUER is reachable
+				[OK]      Issuer CA bundle: found 1 valid certificate(s)
+				[OK]      Clock skew: the clock of this machine is within 1m0s of the clock of the Supervisor
+				[OK]      Supervisor discovery: ISSUER is reachable
+				[OK]      Upstream identity providers: found some-ldap-idp (ldap), some-oidc-idp (oidc), and the user will be asked to choose one during login
+				[OK]      Concierge CA bundle: found 1 valid certificate(s)
+				[OK]      Concierge: ISSUER answers TokenCredentialRequests for the jwt authenticator "test-authenticator"
+				[OK]      Session cache: found an expired session for ISSUER, which will be refreshed during the next login
+				No problems found.
+			`),
+		},
+		{
+			name:        "problems with the Supervisor and the Concierge",
+			kubeconfig:  oidcKubeconfig("--upstream-identity-provider-name=some-missing-idp", "--upstream-identity-provider-type=oidc"),
+			idps:        `{"pinniped_identity_providers": [{"name": "some-ldap-idp", "type": "ldap"}]}`,
+			clockOffset: 5 * time.Minute,
+			exchangeErr: apierrors.NewNotFound(schema.GroupResource{Group: "login.concierge.pinniped.dev", Resource: "tokencredentialrequests"}, ""),
+			wantError:   true,
+			wantStdout: here.Doc(`
+				[OK]      Kubeconfig: kubeconfig context "pinniped" uses "pinniped login oidc" for cluster "ISSUER"
+				[OK]      Cluster CA bundle: found 1 valid certificate(s)
+				[OK]      Cluster: ISSUER is reachable
+				[OK]      Issuer CA bundle: found 1 valid certificate(s)
+				[PROBLEM] Clock skew: the clock of this machine differs from the clock of the Supervisor by 5m0s
+				          To fix: Synchronize the clock of this machine, e.g. using NTP, or ask your administrator to synchronize the clock of the Supervisor.
+				[OK]      Supervisor discovery: ISSUER is reachable
+				[PROBLEM] Upstream identity providers: the kubeconfig uses some-missing-idp (oidc), which the Supervisor does not have (found: some-ldap-idp (ldap))
+				          To fix: Regenerate the kubeconfig using "pinniped get kubeconfig" to use one of the upstream identity providers of the Supervisor.
+				[OK]      Concierge CA bundle: found 1 valid certificate(s)
+				[PROBLEM] Concierge: ISSUER does not serve the TokenCredentialRequest API: tokencredentialrequests.login.concierge.pinniped.dev "" not found
+				          To fix: Ask your cluster administrator to check that the Concierge is installed with the API group suffix of the kubeconfig, and to check the status of its strategies using "kubectl get credentialissuer -o yaml".
+				[OK]      Session cache: found no session for ISSUER, so you will be asked to log in
+			`),
+			wantStderr: "Error: found 3 problem(s)\n",
+		},
+		{
+			name: "CA bundle of the issuer is not the CA of the Supervisor",
+			kubeconfig: strings.ReplaceAll(oidcKubeconfig(), "--ca-bundle-data="+serverCA,
+				"--ca-bundle-data="+base64.StdEncoding.EncodeToString(otherCA.Bundle())),
+			exchangeErr: conciergeclient.ErrLoginFailed,
+			wantError:   true,
+			wantStdout: here.Doc(`
+				[OK]      Kubeconfig: kubeconfig context "pinniped" uses "pinniped login oidc" for cluster "ISSUER"
+				[OK]      Cluster CA bundle: found 1 valid certificate(s)
+				[OK]      Cluster: ISSUER is reachable
+				[OK]      Issuer CA bundle: found 1 valid certificate(s)
+				[PROBLEM] Supervisor discovery: could not fetch ISSUER/.well-known/openid-configuration: Get "ISSUER/.well-known/openid-configuration": tls: failed to verify certificate: x509: certificate signed by unknown authority
+				          To fix: The certificate of the Supervisor is not signed by a CA of the kubeconfig. Regenerate the kubeconfig using "pinniped get kubeconfig" to get the current CA bundle.
+				[OK]      Concierge CA bundle: found 1 valid certificate(s)
+				[OK]      Concierge: ISSUER answers TokenCredentialRequests for the jwt authenticator "test-authenticator"
+				[OK]      Session cache: found no session for ISSUER, so you will be asked to log in
+			`),
+			wantStderr: "Error: found 1 problem(s)\n",
+		},
+		{
+			name: "static login with an expiring Concierge CA bundle",
+			kubeconfig: here.Docf(`
+				apiVersion: v1
+				kind: Config
+				current-context: static
+				contexts:
+				- name: static
+				  context:
+				    cluster: static
+				    user: static
+				clusters:
+				- name: static
+				  cluster:
+				    server: ISSUER
+				    certificate-authority-data: %s
+				users:
+				- name: static
+				  user:
+				    exec:
+				      apiVersion: client.authentication.k8s.io/v1beta1
+				      command: pinniped
+				      args: [login, static, --token=test-token, --enable-concierge, --concierge-authenticator-type=webhook, --concierge-authenticator-name=test-authenticator, --concierge-ca-bundle-data=%s]
+			`, serverCA, base64.StdEncoding.EncodeToString(expiringCA.Bundle())),
+			exchangeErr: conciergeclient.ErrLoginFailed,
+			wantStdout: here.Doc(`
+				[OK]      Kubeconfig: kubeconfig context "static" uses "pinniped login static" for cluster "ISSUER"
+				[OK]      Cluster CA bundle: found 1 valid certificate(s)
+				[OK]      Cluster: ISSUER is reachable
+				[WARNING] Concierge CA bundle: certificate "Expiring CA" expires at EXPIRY
+				          To fix: Ask your administrator whether the CA will be renewed, and then regenerate the kubeconfig using "pinniped get kubeconfig".
+				[OK]      Concierge: ISSUER answers TokenCredentialRequests for the webhook authenticator "test-authenticator"
+				No problems found.
+			`),
+		},
+		{
+			name: "kubeconfig does not use the Pinniped CLI",
+			kubeconfig: here.Docf(`
+				apiVersion: v1
+				kind: Config
+				current-context: other
+				contexts:
+				- name: other
+				  context:
+				    cluster: other
+				    user: other
+				clusters:
+				- name: other
+				  cluster:
+				    server: ISSUER
+				    certificate-authority-data: %s
+				users:
+				- name: other
+				  user:
+				    token: test-token
+			`, serverCA),
+			wantError: true,
+			wantStdout: here.Doc(`
+				[PROBLEM] Kubeconfig: kubeconfig context "other" does not use "pinniped login oidc" or "pinniped login static"
+				          To fix: Generate a Pinniped-compatible kubeconfig using "pinniped get kubeconfig".
+				[OK]      Cluster CA bundle: found 1 valid certificate(s)
+				[OK]      Cluster: ISSUER is reachable
+			`),
+			wantStderr: "Error: found 1 problem(s)\n",
+		},
+		{
+			name:      "kubeconfig context not found",
+			args:      []string{"--kubeconfig", "testdata/kubeconfig.yaml", "--kubeconfig-context", "does-not-exist"},
+			wantError: true,
+			wantStdout: here.Doc(`
+				[PROBLEM] Kubeconfig: could not find kubeconfig context "does-not-exist"
+				          To fix: Choose an existing context using --kubeconfig-context or "kubectl config use-context".
+			`),
+			wantStderr: "Error: found 1 problem(s)\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			idps = tt.idps
+			tmp := testutil.TempDir(t)
+			replacer := strings.NewReplacer("ISSUER", server.URL, "TMPDIR", tmp, "EXPIRY", expiringCACert[0].NotAfter.Format(time.RFC3339))
+
+			args := tt.args
+			if tt.kubeconfig != "" {
+				kubeconfigPath := filepath.Join(tmp, "kubeconfig.yaml")
+				require.NoError(t, os.WriteFile(kubeconfigPath, []byte(replacer.Replace(tt.kubeconfig)), 0600))
+				args = append([]string{"--kubeconfig", kubeconfigPath}, args...)
+			}
+			if tt.session != nil {
+				filesession.New(filepath.Join(tmp, "sessions.yaml")).PutToken(
+					oidcclient.SessionCacheKey{Issuer: server.URL, ClientID: "pinniped-cli"}, tt.session)
+			}
+
+			cmd := doctorCommand(doctorCommandDeps{
+				lookupEnv: func(string) (string, bool) { return "", false },
+				exchangeToken: func(ctx context.Context, client *conciergeclient.Client, token string) (*clientauthv1beta1.ExecCredential, error) {
+					require.Equal(t, doctorProbeToken, token)
+					return nil, tt.exchangeErr
+				},
+				keyring:      &fakeKeyring{err: fmt.Errorf("some keychain error")},
+				systemPACURL: func() string { return "" },
+				now:          func() time.Time { return time.Now().Add(tt.clockOffset) },
+			})
+			require.NotNil(t, cmd)
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(args)
+			err := cmd.ExecuteContext(context.Background())
+			if tt.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, replacer.Replace(tt.wantStdout), stdout.String(), "unexpected stdout")
+			require.Equal(t, tt.wantStderr, stderr.String(), "unexpected stderr")
+		})
+	}
+}
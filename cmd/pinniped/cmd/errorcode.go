@@ -0,0 +1,93 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+
+	"go.pinniped.dev/pkg/conciergeclient"
+	"go.pinniped.dev/pkg/oidcclient"
+)
+
+// errorCode identifies the cause of a failed command, so that automation such as wrapper scripts and IDE plugins can
+// branch on it. The codes and their exit statuses are documented, so take care to never change or reuse them.
+type errorCode string
+
+const (
+	errCodeUnknown                errorCode = "ERR_UNKNOWN"
+	errCodeInvalidArguments       errorCode = "ERR_INVALID_ARGUMENTS"
+	errCodeKubeconfigInvalid      errorCode = "ERR_KUBECONFIG_INVALID"
+	errCodeLoginFailed            errorCode = "ERR_LOGIN_FAILED"
+	errCodeUpstreamRefreshRevoked errorCode = "ERR_UPSTREAM_REFRESH_REVOKED"
+	errCodeConciergeUnavailable   errorCode = "ERR_CONCIERGE_UNAVAILABLE"
+	errCodeConciergeLoginRejected errorCode = "ERR_CONCIERGE_LOGIN_REJECTED"
+	errCodeDiscoveryFailed        errorCode = "ERR_DISCOVERY_FAILED"
+	errCodeClusterUnavailable     errorCode = "ERR_CLUSTER_UNAVAILABLE"
+)
+
+// exitCodes are the exit statuses of the CLI for each errorCode.
+//
+//nolint:gochecknoglobals
+var exitCodes = map[errorCode]int{
+	errCodeUnknown:                1,
+	errCodeInvalidArguments:       2,
+	errCodeKubeconfigInvalid:      3,
+	errCodeLoginFailed:            4,
+	errCodeUpstreamRefreshRevoked: 5,
+	errCodeConciergeUnavailable:   6,
+	errCodeConciergeLoginRejected: 7,
+	errCodeDiscoveryFailed:        8,
+	errCodeClusterUnavailable:     9,
+}
+
+// codedError annotates an error with the errorCode which describes its cause.
+type codedError struct {
+	code errorCode
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// withErrorCode annotates err with the code, unless err is nil or was already annotated with a code.
+func withErrorCode(code errorCode, err error) error {
+	var coded *codedError
+	if err == nil || errors.As(err, &coded) {
+		return err
+	}
+	return &codedError{code: code, err: err}
+}
+
+// errorCodeOf returns the code with which err was annotated, or errCodeUnknown.
+func errorCodeOf(err error) errorCode {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return errCodeUnknown
+}
+
+// ExitCode returns the exit status of the CLI for the error returned by Execute.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return exitCodes[errorCodeOf(err)]
+}
+
+// loginErrorCode returns the code for an error returned by the OIDC login.
+func loginErrorCode(err error) errorCode {
+	if errors.Is(err, oidcclient.ErrRefreshRejected) {
+		return errCodeUpstreamRefreshRevoked
+	}
+	return errCodeLoginFailed
+}
+
+// exchangeErrorCode returns the code for an error returned by the Concierge credential exchange.
+func exchangeErrorCode(err error) errorCode {
+	if errors.Is(err, conciergeclient.ErrLoginFailed) {
+		return errCodeConciergeLoginRejected
+	}
+	return errCodeConciergeUnavailable
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/pkg/conciergeclient"
+	"go.pinniped.dev/pkg/oidcclient"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode errorCode
+		wantExit int
+	}{
+		{
+			name:     "no error",
+			wantCode: errCodeUnknown,
+			wantExit: 0,
+		},
+		{
+			name:     "error without a code",
+			err:      constable.Error("some error"),
+			wantCode: errCodeUnknown,
+			wantExit: 1,
+		},
+		{
+			name:     "invalid arguments",
+			err:      withErrorCode(errCodeInvalidArguments, constable.Error("some error")),
+			wantCode: errCodeInvalidArguments,
+			wantExit: 2,
+		},
+		{
+			name:     "wrapped code",
+			err:      fmt.Errorf("outer: %w", withErrorCode(errCodeKubeconfigInvalid, constable.Error("some error"))),
+			wantCode: errCodeKubeconfigInvalid,
+			wantExit: 3,
+		},
+		{
+			name:     "innermost code is kept",
+			err:      withErrorCode(errCodeUnknown, withErrorCode(errCodeDiscoveryFailed, constable.Error("some error"))),
+			wantCode: errCodeDiscoveryFailed,
+			wantExit: 8,
+		},
+		{
+			name:     "login failed",
+			err:      withErrorCode(loginErrorCode(constable.Error("some error")), constable.Error("some error")),
+			wantCode: errCodeLoginFailed,
+			wantExit: 4,
+		},
+		{
+			name:     "refresh rejected",
+			err:      withErrorCode(loginErrorCode(fmt.Errorf("login: %w", oidcclient.ErrRefreshRejected)), constable.Error("some error")),
+			wantCode: errCodeUpstreamRefreshRevoked,
+			wantExit: 5,
+		},
+		{
+			name:     "concierge unavailable",
+			err:      withErrorCode(exchangeErrorCode(constable.Error("some error")), constable.Error("some error")),
+			wantCode: errCodeConciergeUnavailable,
+			wantExit: 6,
+		},
+		{
+			name:     "concierge rejected the login",
+			err:      withErrorCode(exchangeErrorCode(fmt.Errorf("exchange: %w", conciergeclient.ErrLoginFailed)), constable.Error("some error")),
+			wantCode: errCodeConciergeLoginRejected,
+			wantExit: 7,
+		},
+		{
+			name:     "cluster unavailable",
+			err:      withErrorCode(errCodeClusterUnavailable, constable.Error("some error")),
+			wantCode: errCodeClusterUnavailable,
+			wantExit: 9,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err != nil {
+				require.Equal(t, tt.wantCode, errorCodeOf(tt.err))
+				require.Contains(t, tt.err.Error(), "some error")
+			}
+			require.Equal(t, tt.wantExit, ExitCode(tt.err))
+		})
+	}
+
+	t.Run("nil error stays nil", func(t *testing.T) {
+		require.NoError(t, withErrorCode(errCodeLoginFailed, nil))
+	})
+
+	t.Run("every code has a distinct exit status", func(t *testing.T) {
+		seen := map[int]errorCode{}
+		for code, exit := range exitCodes {
+			require.NotContains(t, seen, exit, "exit status of %s is also used by %s", code, seen[exit])
+			seen[exit] = code
+		}
+	})
+}
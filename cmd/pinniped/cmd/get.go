@@ -10,7 +10,7 @@ import (
 //nolint:gochecknoglobals
 var getCmd = &cobra.Command{
 	Use:          "get",
-	Short:        "Gets one of [kubeconfig]",
+	Short:        "Gets one of [authentication-config, kubeconfig]",
 	SilenceUsage: true, // Do not print usage message when commands fail.
 }
 
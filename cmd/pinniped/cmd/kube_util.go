@@ -1,9 +1,18 @@
-// Copyright 2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	authenticationv1alpha1 "k8s.io/api/authentication/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 
 	conciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
@@ -31,6 +40,62 @@ func getRealConciergeClientset(clientConfig clientcmd.ClientConfig, apiGroupSuff
 	return client.PinnipedConcierge, nil
 }
 
+// getSelfSubjectReviewFunc is a function that can ask the Kubernetes API server about the identity of the
+// current user using the authentication.k8s.io SelfSubjectReview API, given a clientConfig.
+type getSelfSubjectReviewFunc func(ctx context.Context, clientConfig clientcmd.ClientConfig) (*authenticationv1alpha1.SelfSubjectReview, error)
+
+// selfSubjectReviewVersions are the versions of the SelfSubjectReview API which are tried, in order of preference.
+// All versions have the same schema, so each response can be decoded into the v1alpha1 type.
+var selfSubjectReviewVersions = []string{"v1", "v1beta1", "v1alpha1"} //nolint:gochecknoglobals
+
+// getRealSelfSubjectReview creates a SelfSubjectReview using the first version of the API served by the cluster.
+// The returned review has its APIVersion and Kind set to those which were served. When the cluster does not serve
+// any version of the API, the returned error is a NotFound error.
+func getRealSelfSubjectReview(ctx context.Context, clientConfig clientcmd.ClientConfig) (*authenticationv1alpha1.SelfSubjectReview, error) {
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(&authenticationv1alpha1.SelfSubjectReview{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, version := range selfSubjectReviewVersions {
+		gv := schema.GroupVersion{Group: authenticationv1alpha1.GroupName, Version: version}
+		result := clientset.AuthenticationV1alpha1().RESTClient().Post().
+			AbsPath("/apis", gv.Group, gv.Version, "selfsubjectreviews").
+			SetHeader("Content-Type", runtime.ContentTypeJSON).
+			Body(body).
+			Do(ctx)
+		// Unlike Raw(), Error() decodes the Status returned by the server into a meaningful error.
+		if err := result.Error(); errors.IsNotFound(err) {
+			continue // this version is not served, so try the next one
+		} else if err != nil {
+			return nil, err
+		}
+		raw, err := result.Raw()
+		if err != nil {
+			return nil, err
+		}
+
+		review := &authenticationv1alpha1.SelfSubjectReview{}
+		if err := json.Unmarshal(raw, review); err != nil {
+			return nil, fmt.Errorf("could not decode SelfSubjectReview %s response: %w", gv.Version, err)
+		}
+		review.APIVersion = gv.String()
+		review.Kind = "SelfSubjectReview"
+		return review, nil
+	}
+
+	return nil, errors.NewNotFound(authenticationv1alpha1.SchemeGroupVersion.WithResource("selfsubjectreviews").GroupResource(), "")
+}
+
 // newClientConfig returns a clientcmd.ClientConfig given an optional kubeconfig path override and
 // an optional context override.
 func newClientConfig(kubeconfigPathOverride string, currentContextName string) clientcmd.ClientConfig {
@@ -0,0 +1,120 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authenticationv1alpha1 "k8s.io/api/authentication/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"go.pinniped.dev/internal/testutil/tlsserver"
+)
+
+func TestGetRealSelfSubjectReview(t *testing.T) {
+	tests := []struct {
+		name           string
+		servedVersions []string
+		forbidden      bool
+		wantAPIVersion string
+		wantErr        string
+		wantNotFound   bool
+	}{
+		{
+			name:           "v1 is preferred",
+			servedVersions: []string{"v1", "v1beta1", "v1alpha1"},
+			wantAPIVersion: "authentication.k8s.io/v1",
+		},
+		{
+			name:           "falls back to v1beta1",
+			servedVersions: []string{"v1beta1", "v1alpha1"},
+			wantAPIVersion: "authentication.k8s.io/v1beta1",
+		},
+		{
+			name:           "falls back to v1alpha1",
+			servedVersions: []string{"v1alpha1"},
+			wantAPIVersion: "authentication.k8s.io/v1alpha1",
+		},
+		{
+			name:         "no version is served",
+			wantNotFound: true,
+			wantErr:      `selfsubjectreviews.authentication.k8s.io "" not found`,
+		},
+		{
+			name:           "request is rejected",
+			servedVersions: []string{"v1"},
+			forbidden:      true,
+			wantErr:        `selfsubjectreviews.authentication.k8s.io is forbidden: User "some-username" cannot create resource "selfsubjectreviews" in API group "authentication.k8s.io" at the cluster scope`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var requestedPaths []string
+			handlers := http.NewServeMux()
+			for _, version := range tt.servedVersions {
+				version := version
+				handlers.HandleFunc("/apis/authentication.k8s.io/"+version+"/selfsubjectreviews", func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodPost, r.Method)
+					require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+					body, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					require.JSONEq(t, `{"metadata":{"creationTimestamp":null},"status":{"userInfo":{}}}`, string(body))
+
+					w.Header().Set("Content-Type", "application/json")
+					if tt.forbidden {
+						w.WriteHeader(http.StatusForbidden)
+						_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403,` +
+							`"message":"selfsubjectreviews.authentication.k8s.io is forbidden: User \"some-username\" cannot create resource \"selfsubjectreviews\" in API group \"authentication.k8s.io\" at the cluster scope",` +
+							`"details":{"group":"authentication.k8s.io","kind":"selfsubjectreviews"}}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"kind":"SelfSubjectReview","apiVersion":"authentication.k8s.io/` + version + `",` +
+						`"metadata":{"creationTimestamp":null},` +
+						`"status":{"userInfo":{"username":"some-username","uid":"some-uid","groups":["some-group"],"extra":{"some-key":["some-value"]}}}}`))
+				})
+			}
+			server := tlsserver.TLSTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestedPaths = append(requestedPaths, r.URL.Path)
+				handlers.ServeHTTP(w, r)
+			}), nil)
+
+			clientConfig := clientcmd.NewDefaultClientConfig(clientcmdapi.Config{
+				Clusters:       map[string]*clientcmdapi.Cluster{"some-cluster": {Server: server.URL, CertificateAuthorityData: tlsserver.TLSTestServerCA(server)}},
+				AuthInfos:      map[string]*clientcmdapi.AuthInfo{"some-user": {Token: "some-token"}},
+				Contexts:       map[string]*clientcmdapi.Context{"some-context": {Cluster: "some-cluster", AuthInfo: "some-user"}},
+				CurrentContext: "some-context",
+			}, &clientcmd.ConfigOverrides{})
+
+			review, err := getRealSelfSubjectReview(context.Background(), clientConfig)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Equal(t, tt.wantNotFound, errors.IsNotFound(err))
+				require.Nil(t, review)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &authenticationv1alpha1.SelfSubjectReview{
+				TypeMeta: metav1.TypeMeta{APIVersion: tt.wantAPIVersion, Kind: "SelfSubjectReview"},
+				Status: authenticationv1alpha1.SelfSubjectReviewStatus{
+					UserInfo: authenticationv1.UserInfo{
+						Username: "some-username",
+						UID:      "some-uid",
+						Groups:   []string{"some-group"},
+						Extra:    map[string]authenticationv1.ExtraValue{"some-key": {"some-value"}},
+					},
+				},
+			}, review)
+			require.Equal(t, "/apis/authentication.k8s.io/"+tt.servedVersions[0]+"/selfsubjectreviews", requestedPaths[len(requestedPaths)-1])
+		})
+	}
+}
@@ -4,6 +4,7 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"encoding/base64"
@@ -11,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -23,6 +25,7 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth" // Adds handlers for various dynamic auth plugins in client-go
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
 
 	conciergev1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
@@ -31,6 +34,7 @@ import (
 	conciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
 	"go.pinniped.dev/internal/groupsuffix"
 	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/net/pproxy"
 	"go.pinniped.dev/internal/plog"
 )
 
@@ -64,9 +68,15 @@ type getKubeconfigOIDCParams struct {
 	debugSessionCache bool
 	caBundle          caBundleFlag
 	requestAudience   string
+	proxy             string
+	proxyPACURL       string
 	upstreamIDPName   string
 	upstreamIDPType   string
 	upstreamIDPFlow   string
+	credentialHelper  string
+	username          string
+	passwordEnv       string
+	allowPasswordEnv  bool
 }
 
 type getKubeconfigConciergeParams struct {
@@ -79,6 +89,8 @@ type getKubeconfigConciergeParams struct {
 	endpoint          string
 	mode              conciergeModeFlag
 	skipWait          bool
+	tlsServerName     string
+	proxyURL          string
 }
 
 type getKubeconfigParams struct {
@@ -87,6 +99,7 @@ type getKubeconfigParams struct {
 	skipValidate              bool
 	timeout                   time.Duration
 	outputPath                string
+	outputFormat              string
 	staticToken               string
 	staticTokenEnvName        string
 	oidc                      getKubeconfigOIDCParams
@@ -124,6 +137,8 @@ func kubeconfigCommand(deps kubeconfigDeps) *cobra.Command {
 	f.Var(&flags.concierge.caBundle, "concierge-ca-bundle", "Path to TLS certificate authority bundle (PEM format, optional, can be repeated) to use when connecting to the Concierge")
 	f.StringVar(&flags.concierge.endpoint, "concierge-endpoint", "", "API base for the Concierge endpoint")
 	f.Var(&flags.concierge.mode, "concierge-mode", "Concierge mode of operation")
+	f.StringVar(&flags.concierge.tlsServerName, "concierge-tls-server-name", "", "Server name used to verify the certificate of the Concierge impersonation proxy, when it is reached through a forwarder with a different hostname")
+	f.StringVar(&flags.concierge.proxyURL, "concierge-proxy-url", "", "Proxy URL to use when connecting to the Concierge impersonation proxy")
 
 	f.StringVar(&flags.oidc.issuer, "oidc-issuer", "", "OpenID Connect issuer URL (default: autodiscover)")
 	f.StringVar(&flags.oidc.clientID, "oidc-client-id", oidcapi.ClientIDPinnipedCLI, "OpenID Connect client ID (default: autodiscover)")
@@ -135,14 +150,21 @@ func kubeconfigCommand(deps kubeconfigDeps) *cobra.Command {
 	f.Var(&flags.oidc.caBundle, "oidc-ca-bundle", "Path to TLS certificate authority bundle (PEM format, optional, can be repeated)")
 	f.BoolVar(&flags.oidc.debugSessionCache, "oidc-debug-session-cache", false, "Print debug logs related to the OpenID Connect session cache")
 	f.StringVar(&flags.oidc.requestAudience, "oidc-request-audience", "", "Request a token with an alternate audience using RFC8693 token exchange")
+	f.StringVar(&flags.oidc.proxy, "oidc-proxy", "", "During OpenID Connect login, the proxy URL to use when connecting to the issuer and the Concierge, or 'direct' to use no proxy")
+	f.StringVar(&flags.oidc.proxyPACURL, "oidc-proxy-pac-url", "", "During OpenID Connect login, the URL or path of a proxy auto-config (PAC) file which chooses the proxy to use when connecting to the issuer and the Concierge")
 	f.StringVar(&flags.oidc.upstreamIDPName, "upstream-identity-provider-name", "", "The name of the upstream identity provider used during login with a Supervisor")
 	f.StringVar(&flags.oidc.upstreamIDPType, "upstream-identity-provider-type", "", fmt.Sprintf("The type of the upstream identity provider used during login with a Supervisor (e.g. '%s', '%s', '%s')", idpdiscoveryv1alpha1.IDPTypeOIDC, idpdiscoveryv1alpha1.IDPTypeLDAP, idpdiscoveryv1alpha1.IDPTypeActiveDirectory))
 	f.StringVar(&flags.oidc.upstreamIDPFlow, "upstream-identity-provider-flow", "", fmt.Sprintf("The type of client flow to use with the upstream identity provider during login with a Supervisor (e.g. '%s', '%s')", idpdiscoveryv1alpha1.IDPFlowCLIPassword, idpdiscoveryv1alpha1.IDPFlowBrowserAuthcode))
+	f.StringVar(&flags.oidc.credentialHelper, "oidc-credential-helper", "", "During OpenID Connect login, the command which obtains tokens from the issuer instead of performing a login")
+	f.StringVar(&flags.oidc.username, "oidc-username", "", "During OpenID Connect login with the cli_password flow, the username to log in with instead of prompting for it (requires --oidc-allow-non-interactive-password)")
+	f.StringVar(&flags.oidc.passwordEnv, "oidc-password-env", "", "During OpenID Connect login with the cli_password flow, the name of the environment variable which holds the password to log in with instead of prompting for it (requires --oidc-allow-non-interactive-password)")
+	f.BoolVar(&flags.oidc.allowPasswordEnv, "oidc-allow-non-interactive-password", false, "During OpenID Connect login, allow --oidc-username and --oidc-password-env to log in without any user interaction, e.g. in CI systems")
 	f.StringVar(&flags.kubeconfigPath, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to kubeconfig file")
 	f.StringVar(&flags.kubeconfigContextOverride, "kubeconfig-context", "", "Kubeconfig context name (default: current active context)")
 	f.BoolVar(&flags.skipValidate, "skip-validation", false, "Skip final validation of the kubeconfig (default: false)")
 	f.DurationVar(&flags.timeout, "timeout", 10*time.Minute, "Timeout for autodiscovery and validation")
 	f.StringVarP(&flags.outputPath, "output", "o", "", "Output file path (default: stdout)")
+	f.StringVar(&flags.outputFormat, "output-format", outputFormatYAML, "Output format of the kubeconfig (e.g., 'yaml', 'json')")
 	f.StringVar(&flags.generatedNameSuffix, "generated-name-suffix", "-pinniped", "Suffix to append to generated cluster, context, user kubeconfig entries")
 	f.StringVar(&flags.credentialCachePath, "credential-cache", "", "Path to cluster-specific credentials cache")
 	f.StringVar(&flags.installHint, "install-hint", "The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli for more details", "This text is shown to the user when the pinniped CLI is not installed.")
@@ -154,6 +176,10 @@ func kubeconfigCommand(deps kubeconfigDeps) *cobra.Command {
 	mustMarkDeprecated(cmd, "concierge-namespace", "not needed anymore")
 	mustMarkHidden(cmd, "concierge-namespace")
 
+	mustRegisterFlagCompletion(cmd, "kubeconfig-context", completeKubeconfigContexts(&flags.kubeconfigPath))
+	mustRegisterFlagCompletion(cmd, "concierge-authenticator-name", completeConciergeAuthenticatorNames(deps, &flags))
+	mustRegisterFlagCompletion(cmd, "upstream-identity-provider-name", completeKubeconfigUpstreamIDPNames(deps, &flags))
+
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		if flags.outputPath != "" {
 			out, err := os.Create(flags.outputPath)
@@ -164,7 +190,7 @@ func kubeconfigCommand(deps kubeconfigDeps) *cobra.Command {
 			cmd.SetOut(out)
 		}
 		flags.credentialCachePathSet = cmd.Flags().Changed("credential-cache")
-		return runGetKubeconfig(cmd.Context(), cmd.OutOrStdout(), deps, flags)
+		return handleErrorOutput(cmd, kubeconfigErrorOutputFormat(flags.outputFormat), runGetKubeconfig(cmd.Context(), cmd.OutOrStdout(), deps, flags))
 	}
 	return cmd
 }
@@ -178,24 +204,40 @@ func runGetKubeconfig(ctx context.Context, out io.Writer, deps kubeconfigDeps, f
 		return err
 	}
 
+	// Validate output format and immediately return an error if it is invalid.
+	if err := validateOutputFormat(flags.outputFormat, outputFormatYAML, outputFormatJSON); err != nil {
+		return withErrorCode(errCodeInvalidArguments, err)
+	}
+
 	// Validate api group suffix and immediately return an error if it is invalid.
 	if err := groupsuffix.Validate(flags.concierge.apiGroupSuffix); err != nil {
-		return fmt.Errorf("invalid API group suffix: %w", err)
+		return withErrorCode(errCodeInvalidArguments, fmt.Errorf("invalid API group suffix: %w", err))
+	}
+
+	// Validate the proxy URL of the impersonation proxy and immediately return an error if it is invalid, since it
+	// is written to the kubeconfig, where "direct" has no meaning.
+	if flags.concierge.proxyURL != "" {
+		if strings.EqualFold(flags.concierge.proxyURL, pproxy.Direct) {
+			return withErrorCode(errCodeInvalidArguments, fmt.Errorf("invalid --concierge-proxy-url: must be a URL"))
+		}
+		if _, err := pproxy.New(pproxy.Config{Proxy: flags.concierge.proxyURL}); err != nil {
+			return withErrorCode(errCodeInvalidArguments, fmt.Errorf("invalid --concierge-proxy-url: %w", err))
+		}
 	}
 
 	clientConfig := newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride)
 	currentKubeConfig, err := clientConfig.RawConfig()
 	if err != nil {
-		return fmt.Errorf("could not load --kubeconfig: %w", err)
+		return withErrorCode(errCodeKubeconfigInvalid, fmt.Errorf("could not load --kubeconfig: %w", err))
 	}
 	currentKubeconfigNames, err := getCurrentContext(currentKubeConfig, flags)
 	if err != nil {
-		return fmt.Errorf("could not load --kubeconfig/--kubeconfig-context: %w", err)
+		return withErrorCode(errCodeKubeconfigInvalid, fmt.Errorf("could not load --kubeconfig/--kubeconfig-context: %w", err))
 	}
 	cluster := currentKubeConfig.Clusters[currentKubeconfigNames.ClusterName]
 	clientset, err := deps.getClientset(clientConfig, flags.concierge.apiGroupSuffix)
 	if err != nil {
-		return fmt.Errorf("could not configure Kubernetes client: %w", err)
+		return withErrorCode(errCodeKubeconfigInvalid, fmt.Errorf("could not configure Kubernetes client: %w", err))
 	}
 
 	// Generate the new context/cluster/user names by appending the --generated-name-suffix to the original values.
@@ -208,7 +250,7 @@ func runGetKubeconfig(ctx context.Context, out io.Writer, deps kubeconfigDeps, f
 	if !flags.concierge.disabled {
 		credentialIssuer, err := waitForCredentialIssuer(ctx, clientset, flags, deps)
 		if err != nil {
-			return err
+			return withErrorCode(errCodeDiscoveryFailed, err)
 		}
 
 		authenticator, err := lookupAuthenticator(
@@ -218,18 +260,28 @@ func runGetKubeconfig(ctx context.Context, out io.Writer, deps kubeconfigDeps, f
 			deps.log,
 		)
 		if err != nil {
-			return err
+			return withErrorCode(errCodeDiscoveryFailed, err)
 		}
 		if err := discoverConciergeParams(credentialIssuer, &flags, cluster, deps.log); err != nil {
-			return err
+			return withErrorCode(errCodeDiscoveryFailed, err)
 		}
 		if err := discoverAuthenticatorParams(authenticator, &flags, deps.log); err != nil {
-			return err
+			return withErrorCode(errCodeDiscoveryFailed, err)
+		}
+
+		if flags.concierge.mode != modeImpersonationProxy && (flags.concierge.tlsServerName != "" || flags.concierge.proxyURL != "") {
+			return withErrorCode(errCodeInvalidArguments, fmt.Errorf("--concierge-tls-server-name and --concierge-proxy-url can only be used with the impersonation proxy (--concierge-mode=ImpersonationProxy)"))
 		}
 
 		// Point kubectl at the concierge endpoint.
 		cluster.Server = flags.concierge.endpoint
 		cluster.CertificateAuthorityData = flags.concierge.caBundle
+		if flags.concierge.tlsServerName != "" {
+			cluster.TLSServerName = flags.concierge.tlsServerName
+		}
+		if flags.concierge.proxyURL != "" {
+			cluster.ProxyURL = flags.concierge.proxyURL
+		}
 	}
 
 	// If there is an issuer, and if any upstream IDP flags are not already set, then try to discover Supervisor upstream IDP details.
@@ -237,21 +289,21 @@ func runGetKubeconfig(ctx context.Context, out io.Writer, deps kubeconfigDeps, f
 	// that we can't know, like the name of an IDP that they are going to define in the future.
 	if len(flags.oidc.issuer) > 0 && (flags.oidc.upstreamIDPType == "" || flags.oidc.upstreamIDPName == "" || flags.oidc.upstreamIDPFlow == "") {
 		if err := discoverSupervisorUpstreamIDP(ctx, &flags, deps.log); err != nil {
-			return err
+			return withErrorCode(errCodeDiscoveryFailed, err)
 		}
 	}
 
 	execConfig, err := newExecConfig(deps, flags)
 	if err != nil {
-		return err
+		return withErrorCode(errCodeInvalidArguments, err)
 	}
 
 	kubeconfig := newExecKubeconfig(cluster, execConfig, newKubeconfigNames)
 	if err := validateKubeconfig(ctx, flags, kubeconfig, deps.log); err != nil {
-		return err
+		return withErrorCode(errCodeClusterUnavailable, err)
 	}
 
-	return writeConfigAsYAML(out, kubeconfig)
+	return writeConfig(out, kubeconfig, flags.outputFormat)
 }
 
 func newExecConfig(deps kubeconfigDeps, flags getKubeconfigParams) (*clientcmdapi.ExecConfig, error) {
@@ -279,6 +331,12 @@ func newExecConfig(deps kubeconfigDeps, flags getKubeconfigParams) (*clientcmdap
 			"--concierge-endpoint="+flags.concierge.endpoint,
 			"--concierge-ca-bundle-data="+base64.StdEncoding.EncodeToString(flags.concierge.caBundle),
 		)
+		if flags.concierge.tlsServerName != "" {
+			execConfig.Args = append(execConfig.Args, "--concierge-tls-server-name="+flags.concierge.tlsServerName)
+		}
+		if flags.concierge.proxyURL != "" {
+			execConfig.Args = append(execConfig.Args, "--concierge-proxy="+flags.concierge.proxyURL)
+		}
 	}
 
 	// If --credential-cache is set, pass it through.
@@ -335,6 +393,12 @@ func newExecConfig(deps kubeconfigDeps, flags getKubeconfigParams) (*clientcmdap
 		}
 		execConfig.Args = append(execConfig.Args, "--request-audience="+flags.oidc.requestAudience)
 	}
+	if flags.oidc.proxy != "" {
+		execConfig.Args = append(execConfig.Args, "--proxy="+flags.oidc.proxy)
+	}
+	if flags.oidc.proxyPACURL != "" {
+		execConfig.Args = append(execConfig.Args, "--proxy-pac-url="+flags.oidc.proxyPACURL)
+	}
 	if flags.oidc.upstreamIDPName != "" {
 		execConfig.Args = append(execConfig.Args, "--upstream-identity-provider-name="+flags.oidc.upstreamIDPName)
 	}
@@ -344,6 +408,18 @@ func newExecConfig(deps kubeconfigDeps, flags getKubeconfigParams) (*clientcmdap
 	if flags.oidc.upstreamIDPFlow != "" {
 		execConfig.Args = append(execConfig.Args, "--upstream-identity-provider-flow="+flags.oidc.upstreamIDPFlow)
 	}
+	if flags.oidc.credentialHelper != "" {
+		execConfig.Args = append(execConfig.Args, "--credential-helper="+flags.oidc.credentialHelper)
+	}
+	if flags.oidc.username != "" {
+		execConfig.Args = append(execConfig.Args, "--username="+flags.oidc.username)
+	}
+	if flags.oidc.passwordEnv != "" {
+		execConfig.Args = append(execConfig.Args, "--password-env="+flags.oidc.passwordEnv)
+	}
+	if flags.oidc.allowPasswordEnv {
+		execConfig.Args = append(execConfig.Args, "--allow-non-interactive-password")
+	}
 
 	return execConfig, nil
 }
@@ -637,11 +713,30 @@ func lookupAuthenticator(clientset conciergeclientset.Interface, authType, authN
 	return results[0], nil
 }
 
-func writeConfigAsYAML(out io.Writer, config clientcmdapi.Config) error {
+// kubeconfigErrorOutputFormat returns the format used to describe errors of "pinniped get kubeconfig". The kubeconfig
+// is always structured, so only describe errors in a structured format when JSON was explicitly requested.
+func kubeconfigErrorOutputFormat(outputFormat string) string {
+	if outputFormat == outputFormatJSON {
+		return outputFormatJSON
+	}
+	return outputFormatText
+}
+
+func writeConfig(out io.Writer, config clientcmdapi.Config, outputFormat string) error {
 	output, err := clientcmd.Write(config)
 	if err != nil {
 		return err
 	}
+	if outputFormat == outputFormatJSON {
+		if output, err = yaml.YAMLToJSON(output); err != nil {
+			return err
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, output, "", "  "); err != nil {
+			return err
+		}
+		output = append(indented.Bytes(), '\n')
+	}
 	_, err = out.Write(output)
 	if err != nil {
 		return fmt.Errorf("could not write output: %w", err)
@@ -668,7 +763,16 @@ func validateKubeconfig(ctx context.Context, flags getKubeconfigParams, kubeconf
 		return fmt.Errorf("invalid kubeconfig (no certificateAuthorityData)")
 	}
 
-	httpClient := phttp.Default(kubeconfigCA)
+	var proxy func(*http.Request) (*url.URL, error)
+	if cluster.ProxyURL != "" {
+		proxyURL, err := url.Parse(cluster.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid kubeconfig (invalid proxy-url): %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	httpClient := phttp.DefaultWithServerName(kubeconfigCA, cluster.TLSServerName, proxy)
 	httpClient.Timeout = 10 * time.Second
 
 	ticker := time.NewTicker(2 * time.Second)
@@ -764,6 +868,13 @@ func discoverSupervisorUpstreamIDP(ctx context.Context, flags *getKubeconfigPara
 	if err != nil {
 		return err
 	}
+	if selectedIDPName == "" {
+		// Continue without putting an upstream IDP into the kubeconfig, so that the user can choose one during each
+		// login instead of needing a separate kubeconfig for each upstream IDP.
+		log.Info("multiple Supervisor upstream identity providers were found, so the upstream identity provider will be chosen during login",
+			"upstreams", discoveredUpstreamIDPs)
+		return nil
+	}
 
 	selectedIDPFlow, err := selectUpstreamIDPFlow(discoveredIDPFlows, selectedIDPName, selectedIDPType, flags.oidc.upstreamIDPFlow, log)
 	if err != nil {
@@ -896,12 +1007,9 @@ func selectUpstreamIDPNameAndType(pinnipedIDPs []idpdiscoveryv1alpha1.PinnipedID
 		// The user did not specify any name or type, but there is only one found, so select it.
 		return pinnipedIDPs[0].Name, pinnipedIDPs[0].Type, pinnipedIDPs[0].Flows, nil
 	default:
-		// The user did not specify any name or type, and there is more than one found.
-		return "", "", nil, fmt.Errorf(
-			"multiple Supervisor upstream identity providers were found, "+
-				"so the --upstream-identity-provider-name/--upstream-identity-provider-type flags must be specified. "+
-				"Found these upstreams: %s",
-			pinnipedIDPsString)
+		// The user did not specify any name or type, and there is more than one found, so do not select any.
+		// The login command will let the user choose among them.
+		return "", "", nil, nil
 	}
 }
 
@@ -129,7 +129,9 @@ func TestGetKubeconfig(t *testing.T) {
 				      --concierge-credential-issuer string       Concierge CredentialIssuer object to use for autodiscovery (default: autodiscover)
 				      --concierge-endpoint string                API base for the Concierge endpoint
 				      --concierge-mode mode                      Concierge mode of operation (default TokenCredentialRequestAPI)
+				      --concierge-proxy-url string               Proxy URL to use when connecting to the Concierge impersonation proxy
 				      --concierge-skip-wait                      Skip waiting for any pending Concierge strategies to become ready (default: false)
+				      --concierge-tls-server-name string         Server name used to verify the certificate of the Concierge impersonation proxy, when it is reached through a forwarder with a different hostname
 				      --credential-cache string                  Path to cluster-specific credentials cache
 				      --generated-name-suffix string             Suffix to append to generated cluster, context, user kubeconfig entries (default "-pinniped")
 				  -h, --help                                     help for kubeconfig
@@ -137,15 +139,22 @@ func TestGetKubeconfig(t *testing.T) {
 				      --kubeconfig string                        Path to kubeconfig file
 				      --kubeconfig-context string                Kubeconfig context name (default: current active context)
 				      --no-concierge                             Generate a configuration which does not use the Concierge, but sends the credential to the cluster directly
+				      --oidc-allow-non-interactive-password      During OpenID Connect login, allow --oidc-username and --oidc-password-env to log in without any user interaction, e.g. in CI systems
 				      --oidc-ca-bundle path                      Path to TLS certificate authority bundle (PEM format, optional, can be repeated)
 				      --oidc-client-id string                    OpenID Connect client ID (default: autodiscover) (default "pinniped-cli")
+				      --oidc-credential-helper string            During OpenID Connect login, the command which obtains tokens from the issuer instead of performing a login
 				      --oidc-issuer string                       OpenID Connect issuer URL (default: autodiscover)
 				      --oidc-listen-port uint16                  TCP port for localhost listener (authorization code flow only)
+				      --oidc-password-env string                 During OpenID Connect login with the cli_password flow, the name of the environment variable which holds the password to log in with instead of prompting for it (requires --oidc-allow-non-interactive-password)
+				      --oidc-proxy string                        During OpenID Connect login, the proxy URL to use when connecting to the issuer and the Concierge, or 'direct' to use no proxy
+				      --oidc-proxy-pac-url string                During OpenID Connect login, the URL or path of a proxy auto-config (PAC) file which chooses the proxy to use when connecting to the issuer and the Concierge
 				      --oidc-request-audience string             Request a token with an alternate audience using RFC8693 token exchange
 				      --oidc-scopes strings                      OpenID Connect scopes to request during login (default [offline_access,openid,pinniped:request-audience,username,groups])
 				      --oidc-session-cache string                Path to OpenID Connect session cache file
 				      --oidc-skip-browser                        During OpenID Connect login, skip opening the browser (just print the URL)
+				      --oidc-username string                     During OpenID Connect login with the cli_password flow, the username to log in with instead of prompting for it (requires --oidc-allow-non-interactive-password)
 				  -o, --output string                            Output file path (default: stdout)
+				      --output-format string                     Output format of the kubeconfig (e.g., 'yaml', 'json') (default "yaml")
 				      --skip-validation                          Skip final validation of the kubeconfig (default: false)
 				      --static-token string                      Instead of doing an OIDC-based login, specify a static token
 				      --static-token-env string                  Instead of doing an OIDC-based login, read a static token from the environment
@@ -783,6 +792,60 @@ func TestGetKubeconfig(t *testing.T) {
 				return testutil.WantExactErrorString(`Error: invalid API group suffix: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')` + "\n")
 			},
 		},
+		{
+			name: "invalid concierge proxy URL",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--concierge-proxy-url", "ftp://proxy.example.com",
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(`Error: invalid --concierge-proxy-url: invalid proxy URL "ftp://proxy.example.com": scheme must be "http", "https", or "socks5"` + "\n")
+			},
+		},
+		{
+			name: "direct concierge proxy URL",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--concierge-proxy-url", "direct",
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(`Error: invalid --concierge-proxy-url: must be a URL` + "\n")
+			},
+		},
+		{
+			name: "concierge TLS server name without the impersonation proxy",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--concierge-authenticator-type", "webhook",
+					"--concierge-authenticator-name", "test-authenticator",
+					"--concierge-mode", "TokenCredentialRequestAPI",
+					"--concierge-endpoint", "https://explicit-concierge-endpoint.example.com",
+					"--concierge-ca-bundle", testConciergeCABundlePath,
+					"--concierge-tls-server-name", "concierge.example.com",
+					"--skip-validation",
+				}
+			},
+			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
+				return []runtime.Object{
+					credentialIssuer(),
+					&conciergev1alpha1.WebhookAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: "test-authenticator"}},
+				}
+			},
+			wantLogs: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					`"level"=0 "msg"="discovered CredentialIssuer"  "name"="test-credential-issuer"`,
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(`Error: --concierge-tls-server-name and --concierge-proxy-url can only be used with the impersonation proxy (--concierge-mode=ImpersonationProxy)` + "\n")
+			},
+		},
 		{
 			name: "when OIDC discovery document 400s",
 			args: func(issuerCABundle string, issuerURL string) []string {
@@ -889,15 +952,13 @@ func TestGetKubeconfig(t *testing.T) {
 		{
 			name: "when IDP discovery document contains multiple IDPs and no name or type flags are given",
 			args: func(issuerCABundle string, issuerURL string) []string {
+				f := testutil.WriteStringToTempFile(t, "testca-*.pem", issuerCABundle)
 				return []string{
 					"--kubeconfig", "./testdata/kubeconfig.yaml",
 					"--skip-validation",
-				}
-			},
-			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
-				return []runtime.Object{
-					credentialIssuer(),
-					jwtAuthenticator(issuerCABundle, issuerURL),
+					"--no-concierge",
+					"--oidc-issuer", issuerURL,
+					"--oidc-ca-bundle", f.Name(),
 				}
 			},
 			oidcDiscoveryResponse: happyOIDCDiscoveryResponse,
@@ -907,23 +968,46 @@ func TestGetKubeconfig(t *testing.T) {
 					{"name": "some-oidc-idp", "type": "oidc"}
 				]
 			}`),
-			wantLogs: func(issuerCABundle string, issuerURL string) []string {
-				return []string{
-					`"level"=0 "msg"="discovered CredentialIssuer"  "name"="test-credential-issuer"`,
-					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"`,
-					`"level"=0 "msg"="discovered Concierge endpoint"  "endpoint"="https://fake-server-url-value"`,
-					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "roots"=0`,
-					`"level"=0 "msg"="discovered JWTAuthenticator"  "name"="test-authenticator"`,
-					fmt.Sprintf(`"level"=0 "msg"="discovered OIDC issuer"  "issuer"="%s"`, issuerURL),
-					`"level"=0 "msg"="discovered OIDC audience"  "audience"="test-audience"`,
-					`"level"=0 "msg"="discovered OIDC CA bundle"  "roots"=1`,
-				}
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Docf(`
+					apiVersion: v1
+					clusters:
+					- cluster:
+						certificate-authority-data: ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						server: https://fake-server-url-value
+					  name: kind-cluster-pinniped
+					contexts:
+					- context:
+						cluster: kind-cluster-pinniped
+						user: kind-user-pinniped
+					  name: kind-context-pinniped
+					current-context: kind-context-pinniped
+					kind: Config
+					preferences: {}
+					users:
+					- name: kind-user-pinniped
+					  user:
+						exec:
+						  apiVersion: client.authentication.k8s.io/v1beta1
+						  args:
+						  - login
+						  - oidc
+						  - --issuer=%s
+						  - --client-id=pinniped-cli
+						  - --scopes=offline_access,openid,pinniped:request-audience,username,groups
+						  - --ca-bundle-data=%s
+						  command: '.../path/to/pinniped'
+						  env: []
+						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
+             for more details
+						  provideClusterInfo: true
+					`,
+					issuerURL,
+					base64.StdEncoding.EncodeToString([]byte(issuerCABundle)))
 			},
-			wantError: true,
-			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
-				return testutil.WantExactErrorString(`Error: multiple Supervisor upstream identity providers were found, ` +
-					`so the --upstream-identity-provider-name/--upstream-identity-provider-type flags must be specified. ` +
-					`Found these upstreams: [{"name":"some-ldap-idp","type":"ldap"},{"name":"some-oidc-idp","type":"oidc"}]` + "\n")
+			wantLogs: func(_ string, _ string) []string {
+				return []string{`"level"=0 "msg"="multiple Supervisor upstream identity providers were found, so the upstream identity provider will be chosen during login"  ` +
+					`"upstreams"=[{"name":"some-ldap-idp","type":"ldap"},{"name":"some-oidc-idp","type":"oidc"}]`}
 			},
 		},
 		{
@@ -1443,6 +1527,117 @@ func TestGetKubeconfig(t *testing.T) {
 			`)
 			},
 		},
+		{
+			name: "valid static token with json output format",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--static-token", "test-token",
+					"--skip-validation",
+					"--output-format", "json",
+				}
+			},
+			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
+				return []runtime.Object{
+					credentialIssuer(),
+					&conciergev1alpha1.WebhookAuthenticator{ObjectMeta: metav1.ObjectMeta{Name: "test-authenticator"}},
+				}
+			},
+			wantLogs: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					`"level"=0 "msg"="discovered CredentialIssuer"  "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "endpoint"="https://fake-server-url-value"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "roots"=0`,
+					`"level"=0 "msg"="discovered WebhookAuthenticator"  "name"="test-authenticator"`,
+				}
+			},
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Doc(`
+					{
+					  "apiVersion": "v1",
+					  "clusters": [
+					    {
+					      "cluster": {
+					        "certificate-authority-data": "ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==",
+					        "server": "https://fake-server-url-value"
+					      },
+					      "name": "kind-cluster-pinniped"
+					    }
+					  ],
+					  "contexts": [
+					    {
+					      "context": {
+					        "cluster": "kind-cluster-pinniped",
+					        "user": "kind-user-pinniped"
+					      },
+					      "name": "kind-context-pinniped"
+					    }
+					  ],
+					  "current-context": "kind-context-pinniped",
+					  "kind": "Config",
+					  "preferences": {},
+					  "users": [
+					    {
+					      "name": "kind-user-pinniped",
+					      "user": {
+					        "exec": {
+					          "apiVersion": "client.authentication.k8s.io/v1beta1",
+					          "args": [
+					            "login",
+					            "static",
+					            "--enable-concierge",
+					            "--concierge-api-group-suffix=pinniped.dev",
+					            "--concierge-authenticator-name=test-authenticator",
+					            "--concierge-authenticator-type=webhook",
+					            "--concierge-endpoint=https://fake-server-url-value",
+					            "--concierge-ca-bundle-data=ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==",
+					            "--token=test-token"
+					          ],
+					          "command": ".../path/to/pinniped",
+					          "env": [],
+					          "installHint": "The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli for more details",
+					          "provideClusterInfo": true
+					        }
+					      }
+					    }
+					  ]
+					}
+				`)
+			},
+		},
+		{
+			name: "invalid output format",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--static-token", "test-token",
+					"--output-format", "invalid-format",
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(`Error: unknown output format: "invalid-format"` + "\n")
+			},
+		},
+		{
+			name: "error with json output format",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./does/not/exist",
+					"--output-format", "json",
+				}
+			},
+			wantError: true,
+			wantStderr: func(issuerCABundle string, issuerURL string) testutil.RequireErrorStringFunc {
+				return testutil.WantExactErrorString(here.Doc(`
+					{
+					  "error": "could not load --kubeconfig: stat ./does/not/exist: no such file or directory",
+					  "code": "ERR_KUBECONFIG_INVALID"
+					}
+				`))
+			},
+		},
 		{
 			name: "valid static token from env var",
 			args: func(issuerCABundle string, issuerURL string) []string {
@@ -1602,6 +1797,8 @@ func TestGetKubeconfig(t *testing.T) {
 					"--oidc-session-cache", "/path/to/cache/dir/sessions.yaml",
 					"--oidc-debug-session-cache",
 					"--oidc-request-audience", "test-audience",
+					"--oidc-proxy", "http://proxy.example.com:3128",
+					"--oidc-proxy-pac-url", "https://wpad.example.com/proxy.pac",
 					"--skip-validation",
 					"--generated-name-suffix", "-sso",
 					"--credential-cache", "/path/to/cache/dir/credentials.yaml",
@@ -1656,6 +1853,8 @@ func TestGetKubeconfig(t *testing.T) {
 						  - --session-cache=/path/to/cache/dir/sessions.yaml
 						  - --debug-session-cache
 						  - --request-audience=test-audience
+						  - --proxy=http://proxy.example.com:3128
+						  - --proxy-pac-url=https://wpad.example.com/proxy.pac
 						  command: '.../path/to/pinniped'
 						  env: []
 						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
@@ -1772,6 +1971,124 @@ func TestGetKubeconfig(t *testing.T) {
 						  command: '.../path/to/pinniped'
 						  env: []
 						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
+             for more details
+						  provideClusterInfo: true
+					`,
+					base64.StdEncoding.EncodeToString(testConciergeCA.Bundle()),
+					base64.StdEncoding.EncodeToString(testConciergeCA.Bundle()),
+					issuerURL,
+					base64.StdEncoding.EncodeToString([]byte(issuerCABundle)),
+				)
+			},
+		},
+		{
+			name: "configure impersonation proxy with a TLS server name and a proxy URL",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--concierge-mode", "ImpersonationProxy",
+					"--concierge-tls-server-name", "impersonation-proxy.example.com",
+					"--concierge-proxy-url", "http://proxy.example.com:3128",
+					"--skip-validation",
+				}
+			},
+			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
+				return []runtime.Object{
+					&configv1alpha1.CredentialIssuer{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-credential-issuer"},
+						Status: configv1alpha1.CredentialIssuerStatus{
+							Strategies: []configv1alpha1.CredentialIssuerStrategy{
+								// This TokenCredentialRequestAPI strategy would normally be chosen, but
+								// --concierge-mode=ImpersonationProxy should force it to be skipped.
+								{
+									Type:           "SomeType",
+									Status:         configv1alpha1.SuccessStrategyStatus,
+									Reason:         "SomeReason",
+									Message:        "Some message",
+									LastUpdateTime: metav1.Now(),
+									Frontend: &configv1alpha1.CredentialIssuerFrontend{
+										Type: configv1alpha1.TokenCredentialRequestAPIFrontendType,
+										TokenCredentialRequestAPIInfo: &configv1alpha1.TokenCredentialRequestAPIInfo{
+											Server:                   "https://token-credential-request-api-endpoint.test",
+											CertificateAuthorityData: "dGVzdC10Y3ItYXBpLWNh",
+										},
+									},
+								},
+								// The endpoint and CA from this impersonation proxy strategy should be autodiscovered.
+								{
+									Type:           "SomeOtherType",
+									Status:         configv1alpha1.SuccessStrategyStatus,
+									Reason:         "SomeOtherReason",
+									Message:        "Some other message",
+									LastUpdateTime: metav1.Now(),
+									Frontend: &configv1alpha1.CredentialIssuerFrontend{
+										Type: configv1alpha1.ImpersonationProxyFrontendType,
+										ImpersonationProxyInfo: &configv1alpha1.ImpersonationProxyInfo{
+											Endpoint:                 "https://impersonation-proxy-endpoint.test",
+											CertificateAuthorityData: base64.StdEncoding.EncodeToString(testConciergeCA.Bundle()),
+										},
+									},
+								},
+							},
+						},
+					},
+					jwtAuthenticator(issuerCABundle, issuerURL),
+				}
+			},
+			oidcDiscoveryResponse: onlyIssuerOIDCDiscoveryResponse,
+			wantLogs: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					`"level"=0 "msg"="discovered CredentialIssuer"  "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "endpoint"="https://impersonation-proxy-endpoint.test"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "roots"=1`,
+					`"level"=0 "msg"="discovered JWTAuthenticator"  "name"="test-authenticator"`,
+					fmt.Sprintf(`"level"=0 "msg"="discovered OIDC issuer"  "issuer"="%s"`, issuerURL),
+					`"level"=0 "msg"="discovered OIDC audience"  "audience"="test-audience"`,
+					`"level"=0 "msg"="discovered OIDC CA bundle"  "roots"=1`,
+				}
+			},
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Docf(`
+					apiVersion: v1
+					clusters:
+					- cluster:
+						certificate-authority-data: %s
+						proxy-url: http://proxy.example.com:3128
+						server: https://impersonation-proxy-endpoint.test
+						tls-server-name: impersonation-proxy.example.com
+					  name: kind-cluster-pinniped
+					contexts:
+					- context:
+						cluster: kind-cluster-pinniped
+						user: kind-user-pinniped
+					  name: kind-context-pinniped
+					current-context: kind-context-pinniped
+					kind: Config
+					preferences: {}
+					users:
+					- name: kind-user-pinniped
+					  user:
+						exec:
+						  apiVersion: client.authentication.k8s.io/v1beta1
+						  args:
+						  - login
+						  - oidc
+						  - --enable-concierge
+						  - --concierge-api-group-suffix=pinniped.dev
+						  - --concierge-authenticator-name=test-authenticator
+						  - --concierge-authenticator-type=jwt
+						  - --concierge-endpoint=https://impersonation-proxy-endpoint.test
+						  - --concierge-ca-bundle-data=%s
+						  - --concierge-tls-server-name=impersonation-proxy.example.com
+						  - --concierge-proxy=http://proxy.example.com:3128
+						  - --issuer=%s
+						  - --client-id=pinniped-cli
+						  - --scopes=offline_access,openid,pinniped:request-audience,username,groups
+						  - --ca-bundle-data=%s
+						  - --request-audience=test-audience
+						  command: '.../path/to/pinniped'
+						  env: []
+						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
              for more details
 						  provideClusterInfo: true
 					`,
@@ -2275,7 +2592,7 @@ func TestGetKubeconfig(t *testing.T) {
 			},
 		},
 		{
-			name: "when all upstream IDP related flags are sent, pass them through without performing IDP discovery",
+			name: "when all upstream IDP related flags and a credential helper are sent, pass them through without performing IDP discovery",
 			args: func(issuerCABundle string, issuerURL string) []string {
 				return []string{
 					"--kubeconfig", "./testdata/kubeconfig.yaml",
@@ -2283,6 +2600,7 @@ func TestGetKubeconfig(t *testing.T) {
 					"--upstream-identity-provider-name=some-oidc-idp",
 					"--upstream-identity-provider-type=oidc",
 					"--upstream-identity-provider-flow=foobar",
+					"--oidc-credential-helper=pinniped-credential-helper --some-arg",
 				}
 			},
 			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
@@ -2342,6 +2660,91 @@ func TestGetKubeconfig(t *testing.T) {
 						  - --upstream-identity-provider-name=some-oidc-idp
 						  - --upstream-identity-provider-type=oidc
 						  - --upstream-identity-provider-flow=foobar
+						  - --credential-helper=pinniped-credential-helper --some-arg
+						  command: '.../path/to/pinniped'
+						  env: []
+						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
+             for more details
+						  provideClusterInfo: true
+					`,
+					issuerURL,
+					base64.StdEncoding.EncodeToString([]byte(issuerCABundle)))
+			},
+		},
+		{
+			name: "when a username and password env are sent for a non-interactive login, pass them through",
+			args: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					"--kubeconfig", "./testdata/kubeconfig.yaml",
+					"--skip-validation",
+					"--upstream-identity-provider-name=some-ldap-idp",
+					"--upstream-identity-provider-type=ldap",
+					"--upstream-identity-provider-flow=cli_password",
+					"--oidc-username=ci-bot",
+					"--oidc-password-env=CI_BOT_PASSWORD",
+					"--oidc-allow-non-interactive-password",
+				}
+			},
+			conciergeObjects: func(issuerCABundle string, issuerURL string) []runtime.Object {
+				return []runtime.Object{
+					credentialIssuer(),
+					jwtAuthenticator(issuerCABundle, issuerURL),
+				}
+			},
+			oidcDiscoveryStatusCode: http.StatusNotFound, // should not get called by the client in this case
+			wantLogs: func(issuerCABundle string, issuerURL string) []string {
+				return []string{
+					`"level"=0 "msg"="discovered CredentialIssuer"  "name"="test-credential-issuer"`,
+					`"level"=0 "msg"="discovered Concierge operating in TokenCredentialRequest API mode"`,
+					`"level"=0 "msg"="discovered Concierge endpoint"  "endpoint"="https://fake-server-url-value"`,
+					`"level"=0 "msg"="discovered Concierge certificate authority bundle"  "roots"=0`,
+					`"level"=0 "msg"="discovered JWTAuthenticator"  "name"="test-authenticator"`,
+					fmt.Sprintf(`"level"=0 "msg"="discovered OIDC issuer"  "issuer"="%s"`, issuerURL),
+					`"level"=0 "msg"="discovered OIDC audience"  "audience"="test-audience"`,
+					`"level"=0 "msg"="discovered OIDC CA bundle"  "roots"=1`,
+				}
+			},
+			wantStdout: func(issuerCABundle string, issuerURL string) string {
+				return here.Docf(`
+					apiVersion: v1
+					clusters:
+					- cluster:
+						certificate-authority-data: ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						server: https://fake-server-url-value
+					  name: kind-cluster-pinniped
+					contexts:
+					- context:
+						cluster: kind-cluster-pinniped
+						user: kind-user-pinniped
+					  name: kind-context-pinniped
+					current-context: kind-context-pinniped
+					kind: Config
+					preferences: {}
+					users:
+					- name: kind-user-pinniped
+					  user:
+						exec:
+						  apiVersion: client.authentication.k8s.io/v1beta1
+						  args:
+						  - login
+						  - oidc
+						  - --enable-concierge
+						  - --concierge-api-group-suffix=pinniped.dev
+						  - --concierge-authenticator-name=test-authenticator
+						  - --concierge-authenticator-type=jwt
+						  - --concierge-endpoint=https://fake-server-url-value
+						  - --concierge-ca-bundle-data=ZmFrZS1jZXJ0aWZpY2F0ZS1hdXRob3JpdHktZGF0YS12YWx1ZQ==
+						  - --issuer=%s
+						  - --client-id=pinniped-cli
+						  - --scopes=offline_access,openid,pinniped:request-audience,username,groups
+						  - --ca-bundle-data=%s
+						  - --request-audience=test-audience
+						  - --upstream-identity-provider-name=some-ldap-idp
+						  - --upstream-identity-provider-type=ldap
+						  - --upstream-identity-provider-flow=cli_password
+						  - --username=ci-bot
+						  - --password-env=CI_BOT_PASSWORD
+						  - --allow-non-interactive-password
 						  command: '.../path/to/pinniped'
 						  env: []
 						  installHint: The pinniped CLI does not appear to be installed.  See https://get.pinniped.dev/cli
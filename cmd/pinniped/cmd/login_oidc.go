@@ -4,27 +4,36 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gofrs/flock"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
 
 	idpdiscoveryv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/cachecrypter"
 	"go.pinniped.dev/internal/execcredcache"
 	"go.pinniped.dev/internal/groupsuffix"
 	"go.pinniped.dev/internal/here"
 	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/net/pproxy"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/pkg/conciergeclient"
 	"go.pinniped.dev/pkg/oidcclient"
@@ -39,6 +48,28 @@ const (
 	// which specifies "cli_password" when using an IDE plugin where there is no interactive CLI available. This allows
 	// the user to use one kubeconfig file for both flows.
 	upstreamIdentityProviderFlowEnvVarName = "PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW"
+
+	// cachePassphraseEnvVarName and cachePassphraseFileEnvVarName are the names of the env vars which may provide a
+	// passphrase, or a file which contains a passphrase, from which to derive the key used to encrypt the session and
+	// credential caches. This is meant for platforms which have no OS keychain. When set, it takes precedence over the
+	// OS keychain.
+	cachePassphraseEnvVarName     = "PINNIPED_CACHE_PASSPHRASE"
+	cachePassphraseFileEnvVarName = "PINNIPED_CACHE_PASSPHRASE_FILE" //nolint:gosec // this is the name of an env var, not a credential
+
+	// browserCommandEnvVarName is the name of the env var which may provide the command used to open the web browser.
+	// It takes precedence over the --browser-command flag, so that each user may choose how to open their web browser
+	// without editing their kubeconfig file.
+	browserCommandEnvVarName = "PINNIPED_BROWSER"
+
+	// loginFlowAuthCode and loginFlowDeviceCode are the values of the --flow flag, which chooses how the CLI
+	// obtains tokens from the issuer.
+	loginFlowAuthCode   = "auth_code"
+	loginFlowDeviceCode = "device_code"
+
+	// backgroundRefreshWindow is how long before its expiry a cached cluster credential is refreshed in the
+	// background, by starting this command again with the hidden backgroundRefreshArg.
+	backgroundRefreshWindow = 1 * time.Minute
+	backgroundRefreshArg    = "--background-refresh"
 )
 
 //nolint:gochecknoinits
@@ -47,9 +78,14 @@ func init() {
 }
 
 type oidcLoginCommandDeps struct {
-	lookupEnv     func(string) (string, bool)
-	login         func(string, string, ...oidcclient.Option) (*oidctypes.Token, error)
-	exchangeToken func(context.Context, *conciergeclient.Client, string) (*clientauthv1beta1.ExecCredential, error)
+	lookupEnv      func(string) (string, bool)
+	login          func(string, string, ...oidcclient.Option) (*oidctypes.Token, error)
+	exchangeToken  func(context.Context, *conciergeclient.Client, string) (*clientauthv1beta1.ExecCredential, error)
+	keyring        cachecrypter.Keyring
+	systemPACURL   func() string
+	promptForValue func(context.Context, string) (string, error)
+
+	startBackgroundRefresh func(args []string) error
 }
 
 func oidcLoginCommandRealDeps() oidcLoginCommandDeps {
@@ -59,6 +95,11 @@ func oidcLoginCommandRealDeps() oidcLoginCommandDeps {
 		exchangeToken: func(ctx context.Context, client *conciergeclient.Client, token string) (*clientauthv1beta1.ExecCredential, error) {
 			return client.ExchangeToken(ctx, token)
 		},
+		keyring:        cachecrypter.OSKeyring(),
+		systemPACURL:   pproxy.SystemPACURL,
+		promptForValue: promptForValue,
+
+		startBackgroundRefresh: startBackgroundRefresh,
 	}
 }
 
@@ -68,10 +109,13 @@ type oidcLoginFlags struct {
 	listenPort                   uint16
 	scopes                       []string
 	skipBrowser                  bool
+	browserCommand               string
 	skipListen                   bool
 	sessionCachePath             string
 	caBundlePaths                []string
 	caBundleData                 []string
+	proxy                        string
+	proxyPACURL                  string
 	debugSessionCache            bool
 	requestAudience              string
 	conciergeEnabled             bool
@@ -80,10 +124,21 @@ type oidcLoginFlags struct {
 	conciergeEndpoint            string
 	conciergeCABundle            string
 	conciergeAPIGroupSuffix      string
+	conciergeTLSServerName       string
+	conciergeProxy               string
 	credentialCachePath          string
+	useOSKeychain                bool
+	errorFormat                  string
+	flow                         string
 	upstreamIdentityProviderName string
 	upstreamIdentityProviderType string
 	upstreamIdentityProviderFlow string
+	agentSocket                  string
+	credentialHelper             string
+	username                     string
+	passwordEnv                  string
+	allowNonInteractivePassword  bool
+	backgroundRefresh            bool
 }
 
 func oidcLoginCommand(deps oidcLoginCommandDeps) *cobra.Command {
@@ -113,10 +168,13 @@ func oidcLoginCommand(deps oidcLoginCommandDeps) *cobra.Command {
 	cmd.Flags().Uint16Var(&flags.listenPort, "listen-port", 0, "TCP port for localhost listener (authorization code flow only)")
 	cmd.Flags().StringSliceVar(&flags.scopes, "scopes", []string{oidcapi.ScopeOfflineAccess, oidcapi.ScopeOpenID, oidcapi.ScopeRequestAudience, oidcapi.ScopeUsername, oidcapi.ScopeGroups}, "OIDC scopes to request during login")
 	cmd.Flags().BoolVar(&flags.skipBrowser, "skip-browser", false, "Skip opening the browser (just print the URL)")
+	cmd.Flags().StringVar(&flags.browserCommand, "browser-command", "", fmt.Sprintf("Command used to open the browser, to which the URL is appended, or in which %%s is replaced by the URL (default: the default browser of the OS, overridden by $%s)", browserCommandEnvVarName))
 	cmd.Flags().BoolVar(&flags.skipListen, "skip-listen", false, "Skip starting a localhost callback listener (manual copy/paste flow only)")
 	cmd.Flags().StringVar(&flags.sessionCachePath, "session-cache", filepath.Join(mustGetConfigDir(), "sessions.yaml"), "Path to session cache file")
 	cmd.Flags().StringSliceVar(&flags.caBundlePaths, "ca-bundle", nil, "Path to TLS certificate authority bundle (PEM format, optional, can be repeated)")
 	cmd.Flags().StringSliceVar(&flags.caBundleData, "ca-bundle-data", nil, "Base64 encoded TLS certificate authority bundle (base64 encoded PEM format, optional, can be repeated)")
+	cmd.Flags().StringVar(&flags.proxy, "proxy", "", "Proxy URL to use when connecting to the issuer and the Concierge, or 'direct' to use no proxy (default: use the proxy environment variables, or else the proxy auto-config of the OS)")
+	cmd.Flags().StringVar(&flags.proxyPACURL, "proxy-pac-url", "", "URL or path of a proxy auto-config (PAC) file which chooses the proxy to use when connecting to the issuer and the Concierge")
 	cmd.Flags().BoolVar(&flags.debugSessionCache, "debug-session-cache", false, "Print debug logs related to the session cache")
 	cmd.Flags().StringVar(&flags.requestAudience, "request-audience", "", "Request a token with an alternate audience using RFC8693 token exchange")
 	cmd.Flags().BoolVar(&flags.conciergeEnabled, "enable-concierge", false, "Use the Concierge to login")
@@ -126,16 +184,34 @@ func oidcLoginCommand(deps oidcLoginCommandDeps) *cobra.Command {
 	cmd.Flags().StringVar(&flags.conciergeEndpoint, "concierge-endpoint", "", "API base for the Concierge endpoint")
 	cmd.Flags().StringVar(&flags.conciergeCABundle, "concierge-ca-bundle-data", "", "CA bundle to use when connecting to the Concierge")
 	cmd.Flags().StringVar(&flags.conciergeAPIGroupSuffix, "concierge-api-group-suffix", groupsuffix.PinnipedDefaultSuffix, "Concierge API group suffix")
+	cmd.Flags().StringVar(&flags.conciergeTLSServerName, "concierge-tls-server-name", "", "Server name used to verify the certificate of the Concierge endpoint (default: the hostname of the endpoint)")
+	cmd.Flags().StringVar(&flags.conciergeProxy, "concierge-proxy", "", "Proxy URL to use when connecting to the Concierge, or 'direct' to use no proxy (default: the proxy used to connect to the issuer)")
 	cmd.Flags().StringVar(&flags.credentialCachePath, "credential-cache", filepath.Join(mustGetConfigDir(), "credentials.yaml"), "Path to cluster-specific credentials cache (\"\" disables the cache)")
+	cmd.Flags().BoolVar(&flags.useOSKeychain, "use-os-keychain", true, "Encrypt the session and credential caches using a key stored in the OS keychain, when one is available")
+	cmd.Flags().StringVar(&flags.errorFormat, "error-format", outputFormatText, "Format used to describe errors on stderr (e.g., 'text', 'json', 'yaml')")
+	cmd.Flags().StringVar(&flags.flow, "flow", loginFlowAuthCode, fmt.Sprintf("The OAuth 2.0 flow used to obtain tokens from the issuer (e.g., '%s', '%s')", loginFlowAuthCode, loginFlowDeviceCode))
 	cmd.Flags().StringVar(&flags.upstreamIdentityProviderName, "upstream-identity-provider-name", "", "The name of the upstream identity provider used during login with a Supervisor")
 	cmd.Flags().StringVar(&flags.upstreamIdentityProviderType, "upstream-identity-provider-type", idpdiscoveryv1alpha1.IDPTypeOIDC.String(), fmt.Sprintf("The type of the upstream identity provider used during login with a Supervisor (e.g. '%s', '%s', '%s')", idpdiscoveryv1alpha1.IDPTypeOIDC, idpdiscoveryv1alpha1.IDPTypeLDAP, idpdiscoveryv1alpha1.IDPTypeActiveDirectory))
 	cmd.Flags().StringVar(&flags.upstreamIdentityProviderFlow, "upstream-identity-provider-flow", "", fmt.Sprintf("The type of client flow to use with the upstream identity provider during login with a Supervisor (e.g. '%s', '%s')", idpdiscoveryv1alpha1.IDPFlowBrowserAuthcode, idpdiscoveryv1alpha1.IDPFlowCLIPassword))
+	cmd.Flags().StringVar(&flags.credentialHelper, "credential-helper", "", "Command which obtains tokens from the issuer instead of performing a login, for example using a hardware token (see the Pinniped documentation for the protocol)")
+	cmd.Flags().StringVar(&flags.username, "username", "", "Username to log in with, instead of prompting for it, when using the cli_password upstream identity provider flow (requires --allow-non-interactive-password)")
+	cmd.Flags().StringVar(&flags.passwordEnv, "password-env", "", "Name of the environment variable which holds the password to log in with, instead of prompting for it, when using the cli_password upstream identity provider flow (requires --allow-non-interactive-password)")
+	cmd.Flags().BoolVar(&flags.allowNonInteractivePassword, "allow-non-interactive-password", false, "Allow --username and --password-env to log in without any user interaction, e.g. in CI systems")
+	cmd.Flags().BoolVar(&flags.backgroundRefresh, "background-refresh", false, "Only refresh the cached credential, without any user interaction (used by the background refresh)")
+	cmd.Flags().StringVar(&flags.agentSocket, "agent-socket", "", fmt.Sprintf("Path of the socket of a running \"pinniped agent\" which performs the login, if it can be reached (default: $%s)", agentSocketEnvVarName))
 
 	// --skip-listen is mainly needed for testing. We'll leave it hidden until we have a non-testing use case.
 	mustMarkHidden(cmd, "skip-listen")
 	mustMarkHidden(cmd, "debug-session-cache")
+	mustMarkHidden(cmd, "background-refresh")
 	mustMarkRequired(cmd, "issuer")
-	cmd.RunE = func(cmd *cobra.Command, args []string) error { return runOIDCLogin(cmd, deps, flags) }
+	mustRegisterFlagCompletion(cmd, "upstream-identity-provider-name", completeLoginUpstreamIDPNames(&flags))
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(flags.errorFormat, outputFormatText, outputFormatJSON, outputFormatYAML); err != nil {
+			return withErrorCode(errCodeInvalidArguments, err)
+		}
+		return handleErrorOutput(cmd, flags.errorFormat, runOIDCLogin(cmd, deps, flags))
+	}
 
 	mustMarkDeprecated(cmd, "concierge-namespace", "not needed anymore")
 	mustMarkHidden(cmd, "concierge-namespace")
@@ -149,8 +225,28 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 		plog.WarningErr("Received error while setting log level", err)
 	}
 
+	// When a "pinniped agent" is running, let it perform the login, so that concurrent invocations share one login.
+	if socketPath := agentSocketPath(cmd.Flags(), flags.agentSocket, deps.lookupEnv); socketPath != "" && !flags.backgroundRefresh {
+		request := &agentCredentialRequest{Args: agentArgs(cmd.Flags()), Cluster: loadClusterInfo()}
+		cred, err := agentCredential(cmd.Context(), socketPath, request)
+		if err == nil {
+			pLogger.Debug("using cluster credential from the agent", "socket", socketPath)
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(cred)
+		}
+		pLogger.Debug("not using the agent", "socket", socketPath, "error", err.Error())
+	}
+
+	// Encrypt the caches using a key derived from a passphrase or from the OS keychain, if possible.
+	crypter, err := cacheCrypter(flags.useOSKeychain, deps.lookupEnv, deps.keyring, pLogger)
+	if err != nil {
+		return err
+	}
+
 	// Initialize the session cache.
 	var sessionOptions []filesession.Option
+	if crypter != nil {
+		sessionOptions = append(sessionOptions, filesession.WithCrypter(crypter))
+	}
 
 	// If the hidden --debug-session-cache option is passed, log all the errors from the session cache.
 	if flags.debugSessionCache {
@@ -180,6 +276,19 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 	if flags.upstreamIdentityProviderName != "" {
 		opts = append(opts, oidcclient.WithUpstreamIdentityProvider(
 			flags.upstreamIdentityProviderName, flags.upstreamIdentityProviderType))
+	} else {
+		// Let the user choose among the upstream identity providers of the Supervisor when a new login is needed,
+		// so that one kubeconfig can be used with any of them.
+		var specifiedIDPType idpdiscoveryv1alpha1.IDPType
+		if cmd.Flags().Changed("upstream-identity-provider-type") {
+			specifiedIDPType = idpdiscoveryv1alpha1.IDPType(flags.upstreamIdentityProviderType)
+		}
+		opts = append(opts, oidcclient.WithUpstreamIdentityProviderChooser(
+			func(ctx context.Context, idps []idpdiscoveryv1alpha1.PinnipedIDP) ([]oidcclient.Option, error) {
+				return chooseUpstreamIdentityProvider(ctx, idps, specifiedIDPType,
+					idpdiscoveryv1alpha1.IDPFlow(flags.upstreamIdentityProviderFlow), deps)
+			},
+		))
 	}
 
 	flowOpts, err := flowOptions(
@@ -188,27 +297,70 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 		deps,
 	)
 	if err != nil {
-		return err
+		return withErrorCode(errCodeInvalidArguments, err)
 	}
 	opts = append(opts, flowOpts...)
 
+	// Unless a flow was explicitly chosen, the login falls back from the authorization code flow to the device
+	// authorization grant or to the manual copy/paste flow when it detects that no web browser is available.
+	switch flags.flow {
+	case loginFlowAuthCode:
+		if cmd.Flags().Changed("flow") {
+			opts = append(opts, oidcclient.WithSkipHeadlessDetection())
+		}
+	case loginFlowDeviceCode:
+		if len(flowOpts) > 0 {
+			return withErrorCode(errCodeInvalidArguments, fmt.Errorf("--flow %s cannot be used with the %s upstream identity provider flow", loginFlowDeviceCode, idpdiscoveryv1alpha1.IDPFlowCLIPassword))
+		}
+		opts = append(opts, oidcclient.WithDeviceAuthorizationGrant())
+	default:
+		return withErrorCode(errCodeInvalidArguments, fmt.Errorf("--flow value not recognized: %s (supported values: %s)",
+			flags.flow, strings.Join([]string{loginFlowAuthCode, loginFlowDeviceCode}, ", ")))
+	}
+
+	// --username and --password-env let automation such as CI systems log in without any user interaction.
+	if flags.username != "" || flags.passwordEnv != "" {
+		credentialOpts, err := nonInteractivePasswordOptions(flags, len(flowOpts) > 0, deps.lookupEnv, cmd.ErrOrStderr())
+		if err != nil {
+			return withErrorCode(errCodeInvalidArguments, err)
+		}
+		opts = append(opts, credentialOpts...)
+	}
+
+	proxy, err := makeProxy(flags.proxy, flags.proxyPACURL, deps.lookupEnv, deps.systemPACURL, pLogger)
+	if err != nil {
+		return withErrorCode(errCodeInvalidArguments, err)
+	}
+
 	var concierge *conciergeclient.Client
 	if flags.conciergeEnabled {
-		var err error
+		conciergeProxy, err := makeConciergeProxy(flags.conciergeProxy, proxy, pLogger)
+		if err != nil {
+			return withErrorCode(errCodeInvalidArguments, err)
+		}
 		concierge, err = conciergeclient.New(
 			conciergeclient.WithEndpoint(flags.conciergeEndpoint),
 			conciergeclient.WithBase64CABundle(flags.conciergeCABundle),
 			conciergeclient.WithAuthenticator(flags.conciergeAuthenticatorType, flags.conciergeAuthenticatorName),
 			conciergeclient.WithAPIGroupSuffix(flags.conciergeAPIGroupSuffix),
+			conciergeclient.WithTLSServerName(flags.conciergeTLSServerName),
+			conciergeclient.WithProxy(conciergeProxy),
 		)
 		if err != nil {
-			return fmt.Errorf("invalid Concierge parameters: %w", err)
+			return withErrorCode(errCodeInvalidArguments, fmt.Errorf("invalid Concierge parameters: %w", err))
 		}
 	}
 
-	// --skip-browser skips opening the browser.
+	// --skip-browser skips opening the browser. The user opens the printed URL themselves, so there is no need
+	// to detect whether a web browser is available.
 	if flags.skipBrowser {
-		opts = append(opts, oidcclient.WithSkipBrowserOpen())
+		opts = append(opts, oidcclient.WithSkipBrowserOpen(), oidcclient.WithSkipHeadlessDetection())
+	} else {
+		browserOpts, err := browserCommandOptions(flags.browserCommand, deps.lookupEnv)
+		if err != nil {
+			return withErrorCode(errCodeInvalidArguments, err)
+		}
+		opts = append(opts, browserOpts...)
 	}
 
 	// --skip-listen skips starting the localhost callback listener.
@@ -216,26 +368,60 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 		opts = append(opts, oidcclient.WithSkipListen())
 	}
 
-	if len(flags.caBundlePaths) > 0 || len(flags.caBundleData) > 0 {
-		client, err := makeClient(flags.caBundlePaths, flags.caBundleData)
+	// --credential-helper lets an external command obtain the tokens from the issuer.
+	if flags.credentialHelper != "" {
+		command, err := splitCommand(flags.credentialHelper)
+		if err != nil {
+			return withErrorCode(errCodeInvalidArguments, fmt.Errorf("invalid --credential-helper: %w", err))
+		}
+		opts = append(opts, oidcclient.WithCredentialHelper(command))
+	}
+
+	if len(flags.caBundlePaths) > 0 || len(flags.caBundleData) > 0 || proxy != nil {
+		client, err := makeClient(flags.caBundlePaths, flags.caBundleData, proxy)
 		if err != nil {
 			return err
 		}
 		opts = append(opts, oidcclient.WithClient(client))
 	}
+	// The hidden --background-refresh flag is passed to the process which is started below to refresh the cached
+	// credential. It must never interact with the user, and only one such process refreshes the credential at a time.
+	if flags.backgroundRefresh {
+		if flags.credentialCachePath == "" {
+			return nil
+		}
+		lock := flock.New(flags.credentialCachePath + ".refresh.lock")
+		locked, err := lock.TryLock()
+		if err != nil {
+			return fmt.Errorf("could not lock credential cache for background refresh: %w", err)
+		}
+		if !locked {
+			pLogger.Debug("cluster credential is already being refreshed in the background")
+			return nil
+		}
+		defer func() { _ = lock.Unlock() }()
+		opts = append(opts, oidcclient.WithRefreshOnly())
+	}
+
 	// Look up cached credentials based on a hash of all the CLI arguments and the cluster info.
-	cacheKey := struct {
-		Args        []string                   `json:"args"`
-		ClusterInfo *clientauthv1beta1.Cluster `json:"cluster"`
-	}{
-		Args:        os.Args[1:],
+	cacheKey := oidcCredentialCacheKey{
+		Args:        credentialCacheKeyArgs(os.Args[1:]),
 		ClusterInfo: loadClusterInfo(),
 	}
 	var credCache *execcredcache.Cache
 	if flags.credentialCachePath != "" {
-		credCache = execcredcache.New(flags.credentialCachePath)
-		if cred := credCache.Get(cacheKey); cred != nil {
+		credCache = execcredcache.New(flags.credentialCachePath, credCacheOptions(crypter)...)
+		if flags.backgroundRefresh {
+			pLogger.Debug("refreshing cluster credential in the background")
+		} else if cred := credCache.Get(cacheKey); cred != nil {
 			pLogger.Debug("using cached cluster credential.")
+			// Refresh the credential in the background shortly before it expires, so that long-running commands such as
+			// "kubectl get --watch" do not stall on a new login when it expires, as long as the session can be refreshed.
+			if expiresWithin(cred, backgroundRefreshWindow) {
+				if err := deps.startBackgroundRefresh(append(append([]string{}, os.Args[1:]...), backgroundRefreshArg)); err != nil {
+					pLogger.Debug("could not start background refresh of cluster credential", "error", err.Error())
+				}
+			}
 			return json.NewEncoder(cmd.OutOrStdout()).Encode(cred)
 		}
 	}
@@ -244,7 +430,7 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 	// Do the basic login to get an OIDC token.
 	token, err := deps.login(flags.issuer, flags.clientID, opts...)
 	if err != nil {
-		return fmt.Errorf("could not complete Pinniped login: %w", err)
+		return withErrorCode(loginErrorCode(err), fmt.Errorf("could not complete Pinniped login: %w", err))
 	}
 	cred := tokenCredential(token)
 
@@ -256,7 +442,7 @@ func runOIDCLogin(cmd *cobra.Command, deps oidcLoginCommandDeps, flags oidcLogin
 
 		cred, err = deps.exchangeToken(ctx, concierge, token.IDToken.Token)
 		if err != nil {
-			return fmt.Errorf("could not complete Concierge credential exchange: %w", err)
+			return withErrorCode(exchangeErrorCode(err), fmt.Errorf("could not complete Concierge credential exchange: %w", err))
 		}
 		pLogger.Debug("Successfully exchanged token for cluster credential.")
 	} else {
@@ -325,7 +511,71 @@ func flowOptions(
 	}
 }
 
-func makeClient(caBundlePaths []string, caBundleData []string) (*http.Client, error) {
+// chooseUpstreamIdentityProvider asks the user to choose one of the discovered upstream identity providers of the
+// Supervisor, unless only one of them has the specified type, and returns the options to log in using it.
+func chooseUpstreamIdentityProvider(
+	ctx context.Context,
+	pinnipedIDPs []idpdiscoveryv1alpha1.PinnipedIDP,
+	specifiedIDPType idpdiscoveryv1alpha1.IDPType,
+	specifiedFlow idpdiscoveryv1alpha1.IDPFlow,
+	deps oidcLoginCommandDeps,
+) ([]oidcclient.Option, error) {
+	pinnipedIDPsString, _ := json.Marshal(pinnipedIDPs)
+
+	var candidates []idpdiscoveryv1alpha1.PinnipedIDP
+	for _, idp := range pinnipedIDPs {
+		if specifiedIDPType == "" || idp.Type.Equals(specifiedIDPType.String()) {
+			candidates = append(candidates, idp)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf(
+			"no Supervisor upstream identity providers of type %q were found. "+
+				"Found these upstreams: %s", specifiedIDPType, pinnipedIDPsString)
+	}
+
+	chosen := candidates[0]
+	if len(candidates) > 1 {
+		var prompt strings.Builder
+		prompt.WriteString("Choose an upstream identity provider:\n")
+		for i, idp := range candidates {
+			fmt.Fprintf(&prompt, "  %d) %s (%s)\n", i+1, idp.Name, idp.Type)
+		}
+		fmt.Fprintf(&prompt, "Enter a number (1-%d): ", len(candidates))
+
+		answer, err := deps.promptForValue(ctx, prompt.String())
+		if err != nil {
+			return nil, fmt.Errorf(
+				"multiple Supervisor upstream identity providers were found, and the user could not be asked to choose one (%v), "+
+					"so the --upstream-identity-provider-name/--upstream-identity-provider-type flags must be specified. "+
+					"Found these upstreams: %s",
+				err, pinnipedIDPsString)
+		}
+		choice, err := strconv.Atoi(answer)
+		if err != nil || choice < 1 || choice > len(candidates) {
+			return nil, fmt.Errorf("invalid upstream identity provider choice %q (must be a number from 1 to %d)", answer, len(candidates))
+		}
+		chosen = candidates[choice-1]
+	}
+
+	// Like "pinniped get kubeconfig", default to the first flow which the Supervisor lists for the upstream.
+	flow := specifiedFlow
+	if flow == "" && len(chosen.Flows) > 0 {
+		flow = chosen.Flows[0]
+	}
+	flowOpts, err := flowOptions(chosen.Type, flow, deps)
+	if err != nil {
+		return nil, err
+	}
+	return append([]oidcclient.Option{oidcclient.WithUpstreamIdentityProvider(chosen.Name, chosen.Type.String())}, flowOpts...), nil
+}
+
+// makeClient returns an HTTP client which trusts the given CA bundles, or the system's trusted CAs when none are given,
+// and which chooses its proxy using the proxy func, or the proxy environment variables when it is nil.
+func makeClient(caBundlePaths []string, caBundleData []string, proxy func(*http.Request) (*url.URL, error)) (*http.Client, error) {
+	if len(caBundlePaths) == 0 && len(caBundleData) == 0 {
+		return phttp.DefaultWithProxy(nil, proxy), nil
+	}
 	pool := x509.NewCertPool()
 	for _, p := range caBundlePaths {
 		pem, err := os.ReadFile(p)
@@ -341,7 +591,58 @@ func makeClient(caBundlePaths []string, caBundleData []string) (*http.Client, er
 		}
 		pool.AppendCertsFromPEM(pem)
 	}
-	return phttp.Default(pool), nil
+	return phttp.DefaultWithProxy(pool, proxy), nil
+}
+
+// makeProxy returns the proxy func for the requests to the issuer and the Concierge, from the --proxy and
+// --proxy-pac-url flags, or else from the proxy environment variables or the proxy auto-config of the operating
+// system. It returns nil when the proxy should be chosen using the proxy environment variables.
+func makeProxy(proxy, proxyPACURL string, lookupEnv func(string) (string, bool), systemPACURL func() string, pLogger plog.Logger) (func(*http.Request) (*url.URL, error), error) {
+	return pproxy.New(pproxy.Config{
+		Proxy:        proxy,
+		PACURL:       proxyPACURL,
+		LookupEnv:    lookupEnv,
+		SystemPACURL: systemPACURL,
+		Logger:       pLogger,
+	})
+}
+
+// oidcCredentialCacheKey is the key of a cluster credential in the credential cache. It is the same for every
+// invocation of the same exec credential plugin for the same cluster.
+type oidcCredentialCacheKey struct {
+	Args        []string                   `json:"args"`
+	ClusterInfo *clientauthv1beta1.Cluster `json:"cluster"`
+}
+
+// credentialCacheKeyArgs returns the arguments which are part of the credential cache key, so that the process which
+// refreshes a credential in the background uses the same key as the process which started it.
+func credentialCacheKeyArgs(args []string) []string {
+	result := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg != backgroundRefreshArg {
+			result = append(result, arg)
+		}
+	}
+	return result
+}
+
+// expiresWithin returns true when the credential expires within the duration.
+func expiresWithin(cred *clientauthv1beta1.ExecCredential, d time.Duration) bool {
+	return cred.Status != nil && cred.Status.ExpirationTimestamp != nil && time.Until(cred.Status.ExpirationTimestamp.Time) < d
+}
+
+// startBackgroundRefresh starts this executable with the given arguments, without waiting for it to exit. Its stdin,
+// stdout, and stderr are discarded, so that the caller of the credential plugin does not wait for it either.
+func startBackgroundRefresh(args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	refresh := exec.Command(self, args...) //nolint:gosec // these are the arguments of the current process
+	if err := refresh.Start(); err != nil {
+		return err
+	}
+	return refresh.Process.Release()
 }
 
 func tokenCredential(token *oidctypes.Token) *clientauthv1beta1.ExecCredential {
@@ -360,6 +661,47 @@ func tokenCredential(token *oidctypes.Token) *clientauthv1beta1.ExecCredential {
 	return &cred
 }
 
+// cacheCrypter returns a Crypter which encrypts the CLI's cache files. When a passphrase was provided by env var, the key
+// is derived from that passphrase. Otherwise, the key is kept in the OS keychain. It returns nil when there is no
+// passphrase and the OS keychain should not be used or is not available, in which case the cache files will not be
+// encrypted.
+func cacheCrypter(useOSKeychain bool, lookupEnv func(string) (string, bool), kr cachecrypter.Keyring, pLogger plog.Logger) (*cachecrypter.Crypter, error) {
+	passphrase, hasPassphrase := lookupEnv(cachePassphraseEnvVarName)
+	if passphraseFile, ok := lookupEnv(cachePassphraseFileEnvVarName); ok && !hasPassphrase {
+		contents, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read cache encryption passphrase from %s: %w", cachePassphraseFileEnvVarName, err)
+		}
+		passphrase, hasPassphrase = strings.TrimRight(string(contents), "\r\n"), true
+	}
+	if hasPassphrase {
+		crypter, err := cachecrypter.NewFromPassphrase([]byte(passphrase))
+		if err != nil {
+			return nil, err
+		}
+		pLogger.Debug("encrypting caches using a key derived from a passphrase")
+		return crypter, nil
+	}
+
+	if !useOSKeychain {
+		return nil, nil
+	}
+	crypter, err := cachecrypter.New(kr)
+	if err != nil {
+		pLogger.Debug("not encrypting caches because the OS keychain is not available", "error", err.Error())
+		return nil, nil
+	}
+	return crypter, nil
+}
+
+// credCacheOptions returns the options for a credential cache which is encrypted by crypter, when it is not nil.
+func credCacheOptions(crypter *cachecrypter.Crypter) []execcredcache.Option {
+	if crypter == nil {
+		return nil
+	}
+	return []execcredcache.Option{execcredcache.WithCrypter(crypter)}
+}
+
 func SetLogLevel(ctx context.Context, lookupEnv func(string) (string, bool)) (plog.Logger, error) {
 	debug, _ := lookupEnv("PINNIPED_DEBUG")
 	if debug == "true" {
@@ -372,6 +714,145 @@ func SetLogLevel(ctx context.Context, lookupEnv func(string) (string, bool)) (pl
 	return logger, nil
 }
 
+// browserCommandOptions returns the options to open the web browser using the command from the PINNIPED_BROWSER env
+// var or the --browser-command flag, if either is set.
+func browserCommandOptions(browserCommand string, lookupEnv func(string) (string, bool)) ([]oidcclient.Option, error) {
+	source := "--browser-command"
+	if value, ok := lookupEnv(browserCommandEnvVarName); ok && value != "" {
+		browserCommand, source = value, browserCommandEnvVarName
+	}
+	if browserCommand == "" {
+		return nil, nil
+	}
+	command, err := splitCommand(browserCommand)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", source, err)
+	}
+	if len(command) == 0 {
+		return nil, nil
+	}
+	return []oidcclient.Option{oidcclient.WithBrowserCommand(command)}, nil
+}
+
+// promptForValue prints the prompt label to stderr and reads a line from stdin, which must be a terminal.
+func promptForValue(ctx context.Context, promptLabel string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("stdin is not connected to a terminal")
+	}
+	if _, err := fmt.Fprint(os.Stderr, promptLabel); err != nil {
+		return "", fmt.Errorf("could not print prompt to stderr: %w", err)
+	}
+
+	type readResult struct {
+		text string
+		err  error
+	}
+	readResults := make(chan readResult, 1)
+	go func() {
+		text, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		readResults <- readResult{text, err}
+	}()
+
+	// If the context is canceled, return immediately. The ReadString() operation will stay hung in the background
+	// goroutine indefinitely.
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-readResults:
+		return strings.TrimSpace(r.text), r.err
+	}
+}
+
+// nonInteractivePasswordOptions returns the options to log in using the --username and the password from the
+// environment variable named by --password-env. Since a password in the environment is easily leaked, they must be
+// explicitly allowed using --allow-non-interactive-password, and using them prints a warning to stderr.
+func nonInteractivePasswordOptions(flags oidcLoginFlags, usesCLIFlow bool, lookupEnv func(string) (string, bool), stderr io.Writer) ([]oidcclient.Option, error) {
+	if !flags.allowNonInteractivePassword {
+		return nil, fmt.Errorf("--username and --password-env require --allow-non-interactive-password")
+	}
+	if flags.username == "" || flags.passwordEnv == "" {
+		return nil, fmt.Errorf("--username and --password-env must be used together")
+	}
+	if !usesCLIFlow {
+		return nil, fmt.Errorf("--username and --password-env can only be used with the %s upstream identity provider flow", idpdiscoveryv1alpha1.IDPFlowCLIPassword)
+	}
+	password, _ := lookupEnv(flags.passwordEnv)
+	if password == "" {
+		return nil, fmt.Errorf("--password-env variable %q is not set", flags.passwordEnv)
+	}
+	_, _ = fmt.Fprintf(stderr, "Warning: logging in as %q using the password from $%s without any user interaction, which is only intended for automation such as CI systems\n", flags.username, flags.passwordEnv)
+	return []oidcclient.Option{oidcclient.WithCLICredentials(flags.username, password)}, nil
+}
+
+// makeConciergeProxy returns the proxy func for the requests to the Concierge. The --concierge-proxy flag takes
+// precedence over the proxy func which was chosen for all requests, since the Concierge may be reached through a
+// different proxy, e.g. when its impersonation proxy is reached through a TCP forwarder.
+func makeConciergeProxy(conciergeProxy string, proxy func(*http.Request) (*url.URL, error), pLogger plog.Logger) (func(*http.Request) (*url.URL, error), error) {
+	if conciergeProxy == "" {
+		return proxy, nil
+	}
+	p, err := pproxy.New(pproxy.Config{Proxy: conciergeProxy, Logger: pLogger})
+	if err != nil {
+		return nil, fmt.Errorf("invalid --concierge-proxy: %w", err)
+	}
+	return p, nil
+}
+
+// splitCommand splits a command line into words at whitespace, like a POSIX shell but without any expansions. Single
+// quotes preserve everything between them. Double quotes preserve everything except backslashes which escape a double
+// quote or a backslash, so that Windows paths may be double-quoted. Outside of quotes, a backslash escapes any character.
+func splitCommand(command string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+		escape bool
+	)
+	for _, r := range command {
+		switch {
+		case escape:
+			if quote == '"' && r != '"' && r != '\\' {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escape = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\' && quote != '\'':
+			escape, inWord = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escape {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", command)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
 /*
 mustGetConfigDir returns a directory that follows the XDG base directory convention:
 
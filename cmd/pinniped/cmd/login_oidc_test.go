@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,13 +15,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gofrs/flock"
 	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
 	clocktesting "k8s.io/utils/clock/testing"
 
+	idpdiscoveryv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
 	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/execcredcache"
 	"go.pinniped.dev/internal/here"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/testutil"
@@ -37,6 +42,8 @@ func TestLoginOIDCCommand(t *testing.T) {
 	tmpdir := testutil.TempDir(t)
 	testCABundlePath := filepath.Join(tmpdir, "testca.pem")
 	require.NoError(t, os.WriteFile(testCABundlePath, testCA.Bundle(), 0600))
+	testPassphrasePath := filepath.Join(tmpdir, "passphrase")
+	require.NoError(t, os.WriteFile(testPassphrasePath, []byte("some-passphrase\n"), 0600))
 
 	time1 := time.Date(3020, 10, 12, 13, 14, 15, 16, time.UTC)
 
@@ -49,6 +56,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 		args             []string
 		loginErr         error
 		conciergeErr     error
+		keyringErr       error
 		env              map[string]string
 		wantError        bool
 		wantStdout       string
@@ -74,6 +82,9 @@ func TestLoginOIDCCommand(t *testing.T) {
 				  oidc --issuer ISSUER [flags]
 
 				Flags:
+				      --agent-socket string                      Path of the socket of a running "pinniped agent" which performs the login, if it can be reached (default: $PINNIPED_AGENT_SOCKET)
+				      --allow-non-interactive-password           Allow --username and --password-env to log in without any user interaction, e.g. in CI systems
+				      --browser-command string                   Command used to open the browser, to which the URL is appended, or in which %s is replaced by the URL (default: the default browser of the OS, overridden by $PINNIPED_BROWSER)
 				      --ca-bundle strings                        Path to TLS certificate authority bundle (PEM format, optional, can be repeated)
 				      --ca-bundle-data strings                   Base64 encoded TLS certificate authority bundle (base64 encoded PEM format, optional, can be repeated)
 				      --client-id string                         OpenID Connect client ID (default "pinniped-cli")
@@ -82,11 +93,19 @@ func TestLoginOIDCCommand(t *testing.T) {
 				      --concierge-authenticator-type string      Concierge authenticator type (e.g., 'webhook', 'jwt')
 				      --concierge-ca-bundle-data string          CA bundle to use when connecting to the Concierge
 				      --concierge-endpoint string                API base for the Concierge endpoint
+				      --concierge-proxy string                   Proxy URL to use when connecting to the Concierge, or 'direct' to use no proxy (default: the proxy used to connect to the issuer)
+				      --concierge-tls-server-name string         Server name used to verify the certificate of the Concierge endpoint (default: the hostname of the endpoint)
 				      --credential-cache string                  Path to cluster-specific credentials cache ("" disables the cache) (default "` + cfgDir + `/credentials.yaml")
+				      --credential-helper string                 Command which obtains tokens from the issuer instead of performing a login, for example using a hardware token (see the Pinniped documentation for the protocol)
 				      --enable-concierge                         Use the Concierge to login
+				      --error-format string                      Format used to describe errors on stderr (e.g., 'text', 'json', 'yaml') (default "text")
+				      --flow string                              The OAuth 2.0 flow used to obtain tokens from the issuer (e.g., 'auth_code', 'device_code') (default "auth_code")
 				  -h, --help                                     help for oidc
 				      --issuer string                            OpenID Connect issuer URL
 				      --listen-port uint16                       TCP port for localhost listener (authorization code flow only)
+				      --password-env string                      Name of the environment variable which holds the password to log in with, instead of prompting for it, when using the cli_password upstream identity provider flow (requires --allow-non-interactive-password)
+				      --proxy string                             Proxy URL to use when connecting to the issuer and the Concierge, or 'direct' to use no proxy (default: use the proxy environment variables, or else the proxy auto-config of the OS)
+				      --proxy-pac-url string                     URL or path of a proxy auto-config (PAC) file which chooses the proxy to use when connecting to the issuer and the Concierge
 				      --request-audience string                  Request a token with an alternate audience using RFC8693 token exchange
 				      --scopes strings                           OIDC scopes to request during login (default [offline_access,openid,pinniped:request-audience,username,groups])
 				      --session-cache string                     Path to session cache file (default "` + cfgDir + `/sessions.yaml")
@@ -94,6 +113,8 @@ func TestLoginOIDCCommand(t *testing.T) {
 					  --upstream-identity-provider-flow string   The type of client flow to use with the upstream identity provider during login with a Supervisor (e.g. 'browser_authcode', 'cli_password')
 					  --upstream-identity-provider-name string   The name of the upstream identity provider used during login with a Supervisor
 					  --upstream-identity-provider-type string   The type of the upstream identity provider used during login with a Supervisor (e.g. 'oidc', 'ldap', 'activedirectory') (default "oidc")
+				      --use-os-keychain                          Encrypt the session and credential caches using a key stored in the OS keychain, when one is available (default true)
+				      --username string                          Username to log in with, instead of prompting for it, when using the cli_password upstream identity provider flow (requires --allow-non-interactive-password)
 			`),
 		},
 		{
@@ -140,6 +161,18 @@ func TestLoginOIDCCommand(t *testing.T) {
 				Error: could not read --ca-bundle-data: illegal base64 data at input byte 7
 			`),
 		},
+		{
+			name: "invalid proxy",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--proxy", "ftp://proxy.example.com",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid proxy URL "ftp://proxy.example.com": scheme must be "http", "https", or "socks5"
+			`),
+		},
 		{
 			name: "invalid API group suffix",
 			args: []string{
@@ -155,6 +188,21 @@ func TestLoginOIDCCommand(t *testing.T) {
 				Error: invalid Concierge parameters: invalid API group suffix: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')
 			`),
 		},
+		{
+			name: "invalid concierge proxy",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--enable-concierge",
+				"--concierge-authenticator-type", "jwt",
+				"--concierge-authenticator-name", "test-authenticator",
+				"--concierge-endpoint", "https://127.0.0.1:1234/",
+				"--concierge-proxy", "ftp://proxy.example.com",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid --concierge-proxy: invalid proxy URL "ftp://proxy.example.com": scheme must be "http", "https", or "socks5"
+			`),
+		},
 		{
 			name: "invalid upstream type is an error",
 			args: []string{
@@ -166,6 +214,32 @@ func TestLoginOIDCCommand(t *testing.T) {
 				Error: --upstream-identity-provider-type value not recognized: invalid (supported values: oidc, ldap, activedirectory)
 			`),
 		},
+		{
+			name: "invalid upstream type is an error with json error format",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--upstream-identity-provider-type", "invalid",
+				"--error-format", "json",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				{
+				  "error": "--upstream-identity-provider-type value not recognized: invalid (supported values: oidc, ldap, activedirectory)",
+				  "code": "ERR_INVALID_ARGUMENTS"
+				}
+			`),
+		},
+		{
+			name: "invalid error format",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--error-format", "invalid-format",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: unknown output format: "invalid-format"
+			`),
+		},
 		{
 			name: "invalid upstream type when flow override env var is used is still an error",
 			args: []string{
@@ -186,7 +260,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-type", "oidc",
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -198,7 +272,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-flow", "cli_password",
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
-			wantOptionsCount: 5,
+			wantOptionsCount: 6,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -210,7 +284,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-flow", "browser_authcode",
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -223,7 +297,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
 			env:              map[string]string{"PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW": "cli_password"},
-			wantOptionsCount: 5,
+			wantOptionsCount: 6,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -236,7 +310,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
 			env:              map[string]string{"PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW": "browser_authcode"},
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -276,9 +350,83 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-type", "ldap",
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
-			wantOptionsCount: 5,
+			wantOptionsCount: 6,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
+		{
+			name: "username and password env without --allow-non-interactive-password",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--upstream-identity-provider-type", "ldap",
+				"--username", "some-username",
+				"--password-env", "SOME_PASSWORD",
+			},
+			env:       map[string]string{"SOME_PASSWORD": "some-password"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --username and --password-env require --allow-non-interactive-password
+			`),
+		},
+		{
+			name: "username without password env",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--upstream-identity-provider-type", "ldap",
+				"--username", "some-username",
+				"--allow-non-interactive-password",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --username and --password-env must be used together
+			`),
+		},
+		{
+			name: "username and password env with the browser_authcode flow",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--upstream-identity-provider-type", "oidc",
+				"--username", "some-username",
+				"--password-env", "SOME_PASSWORD",
+				"--allow-non-interactive-password",
+			},
+			env:       map[string]string{"SOME_PASSWORD": "some-password"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --username and --password-env can only be used with the cli_password upstream identity provider flow
+			`),
+		},
+		{
+			name: "password env variable is not set",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--upstream-identity-provider-type", "ldap",
+				"--username", "some-username",
+				"--password-env", "SOME_PASSWORD",
+				"--allow-non-interactive-password",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --password-env variable "SOME_PASSWORD" is not set
+			`),
+		},
+		{
+			name: "ldap upstream type with username and password env",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--upstream-identity-provider-type", "ldap",
+				"--username", "some-username",
+				"--password-env", "SOME_PASSWORD",
+				"--allow-non-interactive-password",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:              map[string]string{"SOME_PASSWORD": "some-password"},
+			wantOptionsCount: 7,
+			wantStderr: here.Doc(`
+				Warning: logging in as "some-username" using the password from $SOME_PASSWORD without any user interaction, which is only intended for automation such as CI systems
+			`),
+			wantStdout: `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
 		{
 			name: "activedirectory upstream type with default flow is allowed",
 			args: []string{
@@ -287,7 +435,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-type", "activedirectory",
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
-			wantOptionsCount: 5,
+			wantOptionsCount: 6,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -299,7 +447,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-flow", "cli_password",
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
-			wantOptionsCount: 5,
+			wantOptionsCount: 6,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -311,9 +459,71 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-flow", "browser_authcode",
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
+		{
+			name: "explicit auth code flow skips headless detection",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--flow", "auth_code",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 6,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "device code flow is allowed",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--flow", "device_code",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 6,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "device code flow with ldap upstream type and browser_authcode flow is allowed",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--flow", "device_code",
+				"--upstream-identity-provider-type", "ldap",
+				"--upstream-identity-provider-flow", "browser_authcode",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 6,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "device code flow with CLI upstream flow is an error",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--flow", "device_code",
+				"--upstream-identity-provider-type", "ldap",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --flow device_code cannot be used with the cli_password upstream identity provider flow
+			`),
+		},
+		{
+			name: "unsupported flow is an error",
+			args: []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--flow", "foo",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: --flow value not recognized: foo (supported values: auth_code, device_code)
+			`),
+		},
 		{
 			name: "ldap upstream type with CLI flow in flow override env var is allowed",
 			args: []string{
@@ -324,7 +534,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
 			env:              map[string]string{"PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW": "cli_password"},
-			wantOptionsCount: 5,
+			wantOptionsCount: 6,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -337,7 +547,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
 			env:              map[string]string{"PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW": "browser_authcode"},
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -378,7 +588,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-flow", "cli_password",
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
-			wantOptionsCount: 5,
+			wantOptionsCount: 6,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -390,7 +600,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-flow", "browser_authcode",
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -403,7 +613,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
 			env:              map[string]string{"PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW": "cli_password"},
-			wantOptionsCount: 5,
+			wantOptionsCount: 6,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -416,7 +626,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
 			env:              map[string]string{"PINNIPED_UPSTREAM_IDENTITY_PROVIDER_FLOW": "browser_authcode"},
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 		},
 		{
@@ -456,7 +666,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
 			loginErr:         fmt.Errorf("some login error"),
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
 			wantError:        true,
 			wantStderr: here.Doc(`
 				Error: could not complete Pinniped login: some login error
@@ -474,7 +684,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
 			conciergeErr:     fmt.Errorf("some concierge error"),
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
 			wantError:        true,
 			wantStderr: here.Doc(`
 				Error: could not complete Concierge credential exchange: some concierge error
@@ -488,11 +698,187 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
 			},
 			env:              map[string]string{"PINNIPED_DEBUG": "true"},
-			wantOptionsCount: 4,
+			wantOptionsCount: 5,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+			wantLogs: []string{
+				nowStr + `  pinniped-login  cmd/login_oidc.go:429  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:449  No concierge configured, skipping token credential exchange`,
+			},
+		},
+		{
+			name: "invalid browser command",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--browser-command", "'unterminated",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid --browser-command: unterminated quote or escape in "'unterminated"
+			`),
+		},
+		{
+			name: "invalid browser command from env var",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--browser-command", "some-browser",
+			},
+			env:       map[string]string{"PINNIPED_BROWSER": `"unterminated`},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid PINNIPED_BROWSER: unterminated quote or escape in "\"unterminated"
+			`),
+		},
+		{
+			name: "success with a browser command",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--browser-command", "some-browser --some-profile",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 6,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "browser command is not used with --skip-browser",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--skip-browser",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:              map[string]string{"PINNIPED_BROWSER": "some-browser"},
+			wantOptionsCount: 7,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "invalid credential helper",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--credential-helper", "'unterminated",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid --credential-helper: unterminated quote or escape in "'unterminated"
+			`),
+		},
+		{
+			name: "success with a credential helper",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--credential-helper", "pinniped-credential-helper --some-arg",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 6,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "success with a proxy",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--proxy", "http://proxy.example.com:3128",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			wantOptionsCount: 6,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+		},
+		{
+			name: "success when the OS keychain is not available",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:              map[string]string{"PINNIPED_DEBUG": "true"},
+			keyringErr:       fmt.Errorf("some keychain error"),
+			wantOptionsCount: 5,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+			wantLogs: []string{
+				nowStr + `  pinniped-login  cmd/login_oidc.go:691  not encrypting caches because the OS keychain is not available  {"error": "could not get cache encryption key from OS keychain: some keychain error"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:429  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:449  No concierge configured, skipping token credential exchange`,
+			},
+		},
+		{
+			name: "success with a cache encryption passphrase",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:              map[string]string{"PINNIPED_DEBUG": "true", "PINNIPED_CACHE_PASSPHRASE": "some-passphrase"},
+			keyringErr:       fmt.Errorf("the OS keychain should not be used"),
+			wantOptionsCount: 5,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
 			wantLogs: []string{
-				nowStr + `  pinniped-login  cmd/login_oidc.go:243  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
-				nowStr + `  pinniped-login  cmd/login_oidc.go:263  No concierge configured, skipping token credential exchange`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:682  encrypting caches using a key derived from a passphrase`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:429  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:449  No concierge configured, skipping token credential exchange`,
+			},
+		},
+		{
+			name: "success with a cache encryption passphrase file",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:              map[string]string{"PINNIPED_DEBUG": "true", "PINNIPED_CACHE_PASSPHRASE_FILE": testPassphrasePath},
+			keyringErr:       fmt.Errorf("the OS keychain should not be used"),
+			wantOptionsCount: 5,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+			wantLogs: []string{
+				nowStr + `  pinniped-login  cmd/login_oidc.go:682  encrypting caches using a key derived from a passphrase`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:429  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:449  No concierge configured, skipping token credential exchange`,
+			},
+		},
+		{
+			name: "cache encryption passphrase file cannot be read",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:       map[string]string{"PINNIPED_CACHE_PASSPHRASE_FILE": "/does/not/exist"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: could not read cache encryption passphrase from PINNIPED_CACHE_PASSPHRASE_FILE: open /does/not/exist: no such file or directory
+			`),
+		},
+		{
+			name: "empty cache encryption passphrase",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:       map[string]string{"PINNIPED_CACHE_PASSPHRASE": ""},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: cache encryption passphrase must not be empty
+			`),
+		},
+		{
+			name: "success without using the OS keychain",
+			args: []string{
+				"--client-id", "test-client-id",
+				"--issuer", "test-issuer",
+				"--use-os-keychain=false",
+				"--credential-cache", "", // must specify --credential-cache or else the cache file on disk causes test pollution
+			},
+			env:              map[string]string{"PINNIPED_DEBUG": "true"},
+			keyringErr:       fmt.Errorf("the OS keychain should not be used"),
+			wantOptionsCount: 5,
+			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"expirationTimestamp":"3020-10-12T13:14:15Z","token":"test-id-token"}}` + "\n",
+			wantLogs: []string{
+				nowStr + `  pinniped-login  cmd/login_oidc.go:429  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:449  No concierge configured, skipping token credential exchange`,
 			},
 		},
 		{
@@ -518,13 +904,13 @@ func TestLoginOIDCCommand(t *testing.T) {
 				"--upstream-identity-provider-type", "ldap",
 			},
 			env:              map[string]string{"PINNIPED_DEBUG": "true"},
-			wantOptionsCount: 11,
+			wantOptionsCount: 12,
 			wantStdout:       `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"interactive":false},"status":{"token":"exchanged-token"}}` + "\n",
 			wantLogs: []string{
-				nowStr + `  pinniped-login  cmd/login_oidc.go:243  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
-				nowStr + `  pinniped-login  cmd/login_oidc.go:253  Exchanging token for cluster credential  {"endpoint": "https://127.0.0.1:1234/", "authenticator type": "webhook", "authenticator name": "test-authenticator"}`,
-				nowStr + `  pinniped-login  cmd/login_oidc.go:261  Successfully exchanged token for cluster credential.`,
-				nowStr + `  pinniped-login  cmd/login_oidc.go:268  caching cluster credential for future use.`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:429  Performing OIDC login  {"issuer": "test-issuer", "client id": "test-client-id"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:439  Exchanging token for cluster credential  {"endpoint": "https://127.0.0.1:1234/", "authenticator type": "webhook", "authenticator name": "test-authenticator"}`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:447  Successfully exchanged token for cluster credential.`,
+				nowStr + `  pinniped-login  cmd/login_oidc.go:454  caching cluster credential for future use.`,
 			},
 		},
 	}
@@ -572,6 +958,7 @@ func TestLoginOIDCCommand(t *testing.T) {
 						},
 					}, nil
 				},
+				keyring: &fakeKeyring{err: tt.keyringErr},
 			})
 			require.NotNil(t, cmd)
 
@@ -594,6 +981,34 @@ func TestLoginOIDCCommand(t *testing.T) {
 	}
 }
 
+// fakeKeyring is an in-memory cachecrypter.Keyring. When err is set, it behaves like an unavailable OS keychain.
+type fakeKeyring struct {
+	err     error
+	secrets map[string]string
+}
+
+func (k *fakeKeyring) Get(service, user string) (string, error) {
+	if k.err != nil {
+		return "", k.err
+	}
+	secret, ok := k.secrets[service+"/"+user]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k *fakeKeyring) Set(service, user, secret string) error {
+	if k.err != nil {
+		return k.err
+	}
+	if k.secrets == nil {
+		k.secrets = map[string]string{}
+	}
+	k.secrets[service+"/"+user] = secret
+	return nil
+}
+
 func logLines(logs string) []string {
 	if len(logs) == 0 {
 		return nil
@@ -601,3 +1016,254 @@ func logLines(logs string) []string {
 
 	return strings.Split(strings.TrimSpace(logs), "\n")
 }
+
+func TestLoginOIDCCommandBackgroundRefresh(t *testing.T) {
+	cacheKey := oidcCredentialCacheKey{Args: credentialCacheKeyArgs(os.Args[1:]), ClusterInfo: loadClusterInfo()}
+	expiry := metav1.NewTime(time.Date(3020, 10, 12, 13, 14, 15, 0, time.UTC))
+
+	tests := []struct {
+		name              string
+		cachedExpiresIn   time.Duration
+		backgroundRefresh bool
+		refreshRunning    bool
+		wantStdout        string
+		wantRefreshArgs   []string
+		wantLogin         bool
+		wantCachedToken   string
+	}{
+		{
+			name:            "cached credential which expires soon is returned and refreshed in the background",
+			cachedExpiresIn: 30 * time.Second,
+			wantStdout:      "cached-token",
+			wantRefreshArgs: append(append([]string{}, os.Args[1:]...), "--background-refresh"),
+			wantCachedToken: "cached-token",
+		},
+		{
+			name:            "cached credential which does not expire soon is returned",
+			cachedExpiresIn: time.Hour,
+			wantStdout:      "cached-token",
+			wantCachedToken: "cached-token",
+		},
+		{
+			name:              "background refresh replaces the cached credential",
+			cachedExpiresIn:   30 * time.Second,
+			backgroundRefresh: true,
+			wantStdout:        "refreshed-id-token",
+			wantLogin:         true,
+			wantCachedToken:   "refreshed-id-token",
+		},
+		{
+			name:              "background refresh does nothing while another background refresh is running",
+			cachedExpiresIn:   30 * time.Second,
+			backgroundRefresh: true,
+			refreshRunning:    true,
+			wantCachedToken:   "cached-token",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tmpdir := testutil.TempDir(t)
+			credentialCachePath := filepath.Join(tmpdir, "credentials.yaml")
+			cachedExpiry := metav1.NewTime(time.Now().Add(tt.cachedExpiresIn))
+			execcredcache.New(credentialCachePath).Put(cacheKey, &clientauthv1beta1.ExecCredential{
+				Status: &clientauthv1beta1.ExecCredentialStatus{Token: "cached-token", ExpirationTimestamp: &cachedExpiry},
+			})
+			if tt.refreshRunning {
+				lock := flock.New(credentialCachePath + ".refresh.lock")
+				locked, err := lock.TryLock()
+				require.NoError(t, err)
+				require.True(t, locked)
+				t.Cleanup(func() { require.NoError(t, lock.Unlock()) })
+			}
+
+			var (
+				gotRefreshArgs []string
+				gotLogin       bool
+				gotOptions     []oidcclient.Option
+			)
+			cmd := oidcLoginCommand(oidcLoginCommandDeps{
+				lookupEnv: func(string) (string, bool) { return "", false },
+				login: func(issuer string, clientID string, opts ...oidcclient.Option) (*oidctypes.Token, error) {
+					gotLogin = true
+					gotOptions = opts
+					return &oidctypes.Token{IDToken: &oidctypes.IDToken{Token: "refreshed-id-token", Expiry: expiry}}, nil
+				},
+				startBackgroundRefresh: func(args []string) error {
+					gotRefreshArgs = args
+					return nil
+				},
+			})
+			args := []string{
+				"--issuer", "test-issuer",
+				"--client-id", "test-client-id",
+				"--session-cache", filepath.Join(tmpdir, "sessions.yaml"),
+				"--credential-cache", credentialCachePath,
+				"--use-os-keychain=false",
+			}
+			if tt.backgroundRefresh {
+				args = append(args, "--background-refresh")
+			}
+			var stdout bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(args)
+			require.NoError(t, cmd.Execute())
+
+			if tt.wantStdout == "" {
+				require.Empty(t, stdout.String())
+			} else {
+				require.Contains(t, stdout.String(), `"token":"`+tt.wantStdout+`"`)
+			}
+			require.Equal(t, tt.wantRefreshArgs, gotRefreshArgs)
+			require.Equal(t, tt.wantLogin, gotLogin)
+			if tt.wantLogin {
+				// The background refresh must never interact with the user, so it adds oidcclient.WithRefreshOnly().
+				require.Len(t, gotOptions, 6)
+			}
+			cached := execcredcache.New(credentialCachePath).Get(cacheKey)
+			require.NotNil(t, cached)
+			require.Equal(t, tt.wantCachedToken, cached.Status.Token)
+		})
+	}
+}
+
+func TestChooseUpstreamIdentityProvider(t *testing.T) {
+	ldapIDP := idpdiscoveryv1alpha1.PinnipedIDP{Name: "some-ldap-idp", Type: idpdiscoveryv1alpha1.IDPTypeLDAP}
+	oidcIDP := idpdiscoveryv1alpha1.PinnipedIDP{
+		Name:  "some-oidc-idp",
+		Type:  idpdiscoveryv1alpha1.IDPTypeOIDC,
+		Flows: []idpdiscoveryv1alpha1.IDPFlow{idpdiscoveryv1alpha1.IDPFlowCLIPassword, idpdiscoveryv1alpha1.IDPFlowBrowserAuthcode},
+	}
+	bothIDPs := []idpdiscoveryv1alpha1.PinnipedIDP{ldapIDP, oidcIDP}
+	wantPrompt := here.Doc(`
+		Choose an upstream identity provider:
+		  1) some-ldap-idp (ldap)
+		  2) some-oidc-idp (oidc)
+		Enter a number (1-2): `)
+
+	tests := []struct {
+		name             string
+		idps             []idpdiscoveryv1alpha1.PinnipedIDP
+		specifiedIDPType idpdiscoveryv1alpha1.IDPType
+		specifiedFlow    idpdiscoveryv1alpha1.IDPFlow
+		answer           string
+		answerErr        error
+		wantPrompt       string
+		wantOptionsCount int
+		wantErr          string
+	}{
+		{
+			name:             "only one upstream",
+			idps:             []idpdiscoveryv1alpha1.PinnipedIDP{ldapIDP},
+			wantOptionsCount: 2,
+		},
+		{
+			name:             "only one upstream of the specified type",
+			idps:             bothIDPs,
+			specifiedIDPType: idpdiscoveryv1alpha1.IDPTypeLDAP,
+			wantOptionsCount: 2,
+		},
+		{
+			name:             "no upstreams of the specified type",
+			idps:             bothIDPs,
+			specifiedIDPType: idpdiscoveryv1alpha1.IDPTypeActiveDirectory,
+			wantErr: `no Supervisor upstream identity providers of type "activedirectory" were found. ` +
+				`Found these upstreams: [{"name":"some-ldap-idp","type":"ldap"},{"name":"some-oidc-idp","type":"oidc","flows":["cli_password","browser_authcode"]}]`,
+		},
+		{
+			name:             "the user chooses an upstream which uses the first flow that it lists",
+			idps:             bothIDPs,
+			answer:           "2",
+			wantPrompt:       wantPrompt,
+			wantOptionsCount: 2,
+		},
+		{
+			name:             "the user chooses an upstream which uses the specified flow",
+			idps:             bothIDPs,
+			specifiedFlow:    idpdiscoveryv1alpha1.IDPFlowBrowserAuthcode,
+			answer:           "2",
+			wantPrompt:       wantPrompt,
+			wantOptionsCount: 1,
+		},
+		{
+			name:          "the specified flow is not supported by the chosen upstream",
+			idps:          bothIDPs,
+			specifiedFlow: "some-flow",
+			answer:        "1",
+			wantPrompt:    wantPrompt,
+			wantErr:       `--upstream-identity-provider-flow value not recognized for identity provider type "ldap": some-flow (supported values: cli_password, browser_authcode)`,
+		},
+		{
+			name:       "the user cannot be asked",
+			idps:       bothIDPs,
+			answerErr:  errors.New("stdin is not connected to a terminal"),
+			wantPrompt: wantPrompt,
+			wantErr: `multiple Supervisor upstream identity providers were found, and the user could not be asked to choose one (stdin is not connected to a terminal), ` +
+				`so the --upstream-identity-provider-name/--upstream-identity-provider-type flags must be specified. ` +
+				`Found these upstreams: [{"name":"some-ldap-idp","type":"ldap"},{"name":"some-oidc-idp","type":"oidc","flows":["cli_password","browser_authcode"]}]`,
+		},
+		{
+			name:       "the user gives an invalid answer",
+			idps:       bothIDPs,
+			answer:     "3",
+			wantPrompt: wantPrompt,
+			wantErr:    `invalid upstream identity provider choice "3" (must be a number from 1 to 2)`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPrompt string
+			deps := oidcLoginCommandDeps{
+				lookupEnv: func(string) (string, bool) { return "", false },
+				promptForValue: func(_ context.Context, promptLabel string) (string, error) {
+					gotPrompt = promptLabel
+					return tt.answer, tt.answerErr
+				},
+			}
+
+			opts, err := chooseUpstreamIdentityProvider(context.Background(), tt.idps, tt.specifiedIDPType, tt.specifiedFlow, deps)
+			require.Equal(t, tt.wantPrompt, gotPrompt)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, opts, tt.wantOptionsCount)
+		})
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+		wantErr string
+	}{
+		{name: "empty", command: "", want: nil},
+		{name: "only whitespace", command: " \t ", want: nil},
+		{name: "words", command: "  some-browser\t--some-flag  some-arg ", want: []string{"some-browser", "--some-flag", "some-arg"}},
+		{name: "single quotes", command: `some-browser '--profile=My Profile' 'a\b"c'`, want: []string{"some-browser", "--profile=My Profile", `a\b"c`}},
+		{name: "double quotes", command: `"C:\Program Files\Browser\browser.exe" "say \"hi\" \\ 'there'"`, want: []string{`C:\Program Files\Browser\browser.exe`, `say "hi" \ 'there'`}},
+		{name: "escapes", command: `some\ browser \'quoted\' \\`, want: []string{"some browser", "'quoted'", `\`}},
+		{name: "empty quoted words", command: `some-browser '' ""`, want: []string{"some-browser", "", ""}},
+		{name: "adjacent quotes", command: `some-browser --a='b c'"d e"f`, want: []string{"some-browser", "--a=b cd ef"}},
+		{name: "unterminated single quote", command: `some-browser 'arg`, wantErr: `unterminated quote or escape in "some-browser 'arg"`},
+		{name: "unterminated double quote", command: `some-browser "arg`, wantErr: `unterminated quote or escape in "some-browser \"arg"`},
+		{name: "trailing escape", command: `some-browser \`, wantErr: `unterminated quote or escape in "some-browser \\"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitCommand(tt.command)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -14,9 +14,11 @@ import (
 	"github.com/spf13/cobra"
 	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
 
+	"go.pinniped.dev/internal/cachecrypter"
 	"go.pinniped.dev/internal/execcredcache"
 	"go.pinniped.dev/internal/groupsuffix"
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/net/pproxy"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/pkg/conciergeclient"
 	"go.pinniped.dev/pkg/oidcclient/oidctypes"
@@ -30,6 +32,8 @@ func init() {
 type staticLoginDeps struct {
 	lookupEnv     func(string) (string, bool)
 	exchangeToken func(context.Context, *conciergeclient.Client, string) (*clientauthv1beta1.ExecCredential, error)
+	keyring       cachecrypter.Keyring
+	systemPACURL  func() string
 }
 
 func staticLoginRealDeps() staticLoginDeps {
@@ -38,6 +42,8 @@ func staticLoginRealDeps() staticLoginDeps {
 		exchangeToken: func(ctx context.Context, client *conciergeclient.Client, token string) (*clientauthv1beta1.ExecCredential, error) {
 			return client.ExchangeToken(ctx, token)
 		},
+		keyring:      cachecrypter.OSKeyring(),
+		systemPACURL: pproxy.SystemPACURL,
 	}
 }
 
@@ -50,7 +56,13 @@ type staticLoginParams struct {
 	conciergeEndpoint          string
 	conciergeCABundle          string
 	conciergeAPIGroupSuffix    string
+	conciergeTLSServerName     string
+	conciergeProxy             string
+	proxy                      string
+	proxyPACURL                string
 	credentialCachePath        string
+	useOSKeychain              bool
+	errorFormat                string
 }
 
 func staticLoginCommand(deps staticLoginDeps) *cobra.Command {
@@ -84,9 +96,20 @@ func staticLoginCommand(deps staticLoginDeps) *cobra.Command {
 	cmd.Flags().StringVar(&flags.conciergeEndpoint, "concierge-endpoint", "", "API base for the Concierge endpoint")
 	cmd.Flags().StringVar(&flags.conciergeCABundle, "concierge-ca-bundle-data", "", "CA bundle to use when connecting to the Concierge")
 	cmd.Flags().StringVar(&flags.conciergeAPIGroupSuffix, "concierge-api-group-suffix", groupsuffix.PinnipedDefaultSuffix, "Concierge API group suffix")
+	cmd.Flags().StringVar(&flags.conciergeTLSServerName, "concierge-tls-server-name", "", "Server name used to verify the certificate of the Concierge endpoint (default: the hostname of the endpoint)")
+	cmd.Flags().StringVar(&flags.conciergeProxy, "concierge-proxy", "", "Proxy URL to use when connecting to the Concierge, or 'direct' to use no proxy (default: the proxy chosen by --proxy)")
+	cmd.Flags().StringVar(&flags.proxy, "proxy", "", "Proxy URL to use when connecting to the Concierge, or 'direct' to use no proxy (default: use the proxy environment variables, or else the proxy auto-config of the OS)")
+	cmd.Flags().StringVar(&flags.proxyPACURL, "proxy-pac-url", "", "URL or path of a proxy auto-config (PAC) file which chooses the proxy to use when connecting to the Concierge")
 	cmd.Flags().StringVar(&flags.credentialCachePath, "credential-cache", filepath.Join(mustGetConfigDir(), "credentials.yaml"), "Path to cluster-specific credentials cache (\"\" disables the cache)")
+	cmd.Flags().BoolVar(&flags.useOSKeychain, "use-os-keychain", true, "Encrypt the credential cache using a key stored in the OS keychain, when one is available")
+	cmd.Flags().StringVar(&flags.errorFormat, "error-format", outputFormatText, "Format used to describe errors on stderr (e.g., 'text', 'json', 'yaml')")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error { return runStaticLogin(cmd, deps, flags) }
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(flags.errorFormat, outputFormatText, outputFormatJSON, outputFormatYAML); err != nil {
+			return withErrorCode(errCodeInvalidArguments, err)
+		}
+		return handleErrorOutput(cmd, flags.errorFormat, runStaticLogin(cmd, deps, flags))
+	}
 
 	mustMarkDeprecated(cmd, "concierge-namespace", "not needed anymore")
 	mustMarkHidden(cmd, "concierge-namespace")
@@ -102,20 +125,29 @@ func runStaticLogin(cmd *cobra.Command, deps staticLoginDeps, flags staticLoginP
 	}
 
 	if flags.staticToken == "" && flags.staticTokenEnvName == "" {
-		return fmt.Errorf("one of --token or --token-env must be set")
+		return withErrorCode(errCodeInvalidArguments, fmt.Errorf("one of --token or --token-env must be set"))
 	}
 
 	var concierge *conciergeclient.Client
 	if flags.conciergeEnabled {
-		var err error
+		proxy, err := makeProxy(flags.proxy, flags.proxyPACURL, deps.lookupEnv, deps.systemPACURL, pLogger)
+		if err != nil {
+			return withErrorCode(errCodeInvalidArguments, err)
+		}
+		proxy, err = makeConciergeProxy(flags.conciergeProxy, proxy, pLogger)
+		if err != nil {
+			return withErrorCode(errCodeInvalidArguments, err)
+		}
 		concierge, err = conciergeclient.New(
 			conciergeclient.WithEndpoint(flags.conciergeEndpoint),
 			conciergeclient.WithBase64CABundle(flags.conciergeCABundle),
 			conciergeclient.WithAuthenticator(flags.conciergeAuthenticatorType, flags.conciergeAuthenticatorName),
 			conciergeclient.WithAPIGroupSuffix(flags.conciergeAPIGroupSuffix),
+			conciergeclient.WithTLSServerName(flags.conciergeTLSServerName),
+			conciergeclient.WithProxy(proxy),
 		)
 		if err != nil {
-			return fmt.Errorf("invalid Concierge parameters: %w", err)
+			return withErrorCode(errCodeInvalidArguments, fmt.Errorf("invalid Concierge parameters: %w", err))
 		}
 	}
 
@@ -127,10 +159,10 @@ func runStaticLogin(cmd *cobra.Command, deps staticLoginDeps, flags staticLoginP
 		var ok bool
 		token, ok = deps.lookupEnv(flags.staticTokenEnvName)
 		if !ok {
-			return fmt.Errorf("--token-env variable %q is not set", flags.staticTokenEnvName)
+			return withErrorCode(errCodeInvalidArguments, fmt.Errorf("--token-env variable %q is not set", flags.staticTokenEnvName))
 		}
 		if token == "" {
-			return fmt.Errorf("--token-env variable %q is empty", flags.staticTokenEnvName)
+			return withErrorCode(errCodeInvalidArguments, fmt.Errorf("--token-env variable %q is empty", flags.staticTokenEnvName))
 		}
 	}
 	cred := tokenCredential(&oidctypes.Token{IDToken: &oidctypes.IDToken{Token: token}})
@@ -147,7 +179,11 @@ func runStaticLogin(cmd *cobra.Command, deps staticLoginDeps, flags staticLoginP
 	}
 	var credCache *execcredcache.Cache
 	if flags.credentialCachePath != "" {
-		credCache = execcredcache.New(flags.credentialCachePath)
+		crypter, err := cacheCrypter(flags.useOSKeychain, deps.lookupEnv, deps.keyring, pLogger)
+		if err != nil {
+			return err
+		}
+		credCache = execcredcache.New(flags.credentialCachePath, credCacheOptions(crypter)...)
 		if cred := credCache.Get(cacheKey); cred != nil {
 			pLogger.Debug("using cached cluster credential.")
 			return json.NewEncoder(out).Encode(cred)
@@ -163,7 +199,7 @@ func runStaticLogin(cmd *cobra.Command, deps staticLoginDeps, flags staticLoginP
 		var err error
 		cred, err = deps.exchangeToken(ctx, concierge, token)
 		if err != nil {
-			return fmt.Errorf("could not complete Concierge credential exchange: %w", err)
+			return withErrorCode(exchangeErrorCode(err), fmt.Errorf("could not complete Concierge credential exchange: %w", err))
 		}
 		pLogger.Debug("exchanged static token for cluster credential")
 	}
@@ -73,11 +73,17 @@ func TestLoginStaticCommand(t *testing.T) {
 				      --concierge-authenticator-type string   Concierge authenticator type (e.g., 'webhook', 'jwt')
 				      --concierge-ca-bundle-data string       CA bundle to use when connecting to the Concierge
 				      --concierge-endpoint string             API base for the Concierge endpoint
+				      --concierge-proxy string                Proxy URL to use when connecting to the Concierge, or 'direct' to use no proxy (default: the proxy chosen by --proxy)
+				      --concierge-tls-server-name string      Server name used to verify the certificate of the Concierge endpoint (default: the hostname of the endpoint)
 				      --credential-cache string               Path to cluster-specific credentials cache ("" disables the cache) (default "` + cfgDir + `/credentials.yaml")
 				      --enable-concierge                      Use the Concierge to login
+				      --error-format string                   Format used to describe errors on stderr (e.g., 'text', 'json', 'yaml') (default "text")
 				  -h, --help                                  help for static
+				      --proxy string                          Proxy URL to use when connecting to the Concierge, or 'direct' to use no proxy (default: use the proxy environment variables, or else the proxy auto-config of the OS)
+				      --proxy-pac-url string                  URL or path of a proxy auto-config (PAC) file which chooses the proxy to use when connecting to the Concierge
 				      --token string                          Static token to present during login
 				      --token-env string                      Environment variable containing a static token
+				      --use-os-keychain                       Encrypt the credential cache using a key stored in the OS keychain, when one is available (default true)
 			`),
 		},
 		{
@@ -88,6 +94,34 @@ func TestLoginStaticCommand(t *testing.T) {
 				Error: one of --token or --token-env must be set
 			`),
 		},
+		{
+			name:      "missing required flags with json error format",
+			args:      []string{"--error-format", "json"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				{
+				  "error": "one of --token or --token-env must be set",
+				  "code": "ERR_INVALID_ARGUMENTS"
+				}
+			`),
+		},
+		{
+			name:      "missing required flags with yaml error format",
+			args:      []string{"--error-format", "yaml"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				code: ERR_INVALID_ARGUMENTS
+				error: one of --token or --token-env must be set
+			`),
+		},
+		{
+			name:      "invalid error format",
+			args:      []string{"--token", "test-token", "--error-format", "invalid-format"},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: unknown output format: "invalid-format"
+			`),
+		},
 		{
 			name: "missing concierge flags",
 			args: []string{
@@ -99,6 +133,36 @@ func TestLoginStaticCommand(t *testing.T) {
 				Error: invalid Concierge parameters: endpoint must not be empty
 			`),
 		},
+		{
+			name: "invalid proxy",
+			args: []string{
+				"--token", "test-token",
+				"--enable-concierge",
+				"--concierge-endpoint", "https://127.0.0.1/",
+				"--concierge-authenticator-type", "webhook",
+				"--concierge-authenticator-name", "test-authenticator",
+				"--proxy", "ftp://proxy.example.com",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid proxy URL "ftp://proxy.example.com": scheme must be "http", "https", or "socks5"
+			`),
+		},
+		{
+			name: "invalid concierge proxy",
+			args: []string{
+				"--token", "test-token",
+				"--enable-concierge",
+				"--concierge-endpoint", "https://127.0.0.1/",
+				"--concierge-authenticator-type", "webhook",
+				"--concierge-authenticator-name", "test-authenticator",
+				"--concierge-proxy", "ftp://proxy.example.com",
+			},
+			wantError: true,
+			wantStderr: here.Doc(`
+				Error: invalid --concierge-proxy: invalid proxy URL "ftp://proxy.example.com": scheme must be "http", "https", or "socks5"
+			`),
+		},
 		{
 			name: "missing env var",
 			args: []string{
@@ -148,7 +212,7 @@ func TestLoginStaticCommand(t *testing.T) {
 				Error: could not complete Concierge credential exchange: some concierge error
 			`),
 			wantLogs: []string{
-				nowStr + `  pinniped-login  cmd/login_static.go:159  exchanging static token for cluster credential  {"endpoint": "https://127.0.0.1/", "authenticator type": "webhook", "authenticator name": "test-authenticator"}`,
+				nowStr + `  pinniped-login  cmd/login_static.go:195  exchanging static token for cluster credential  {"endpoint": "https://127.0.0.1/", "authenticator type": "webhook", "authenticator name": "test-authenticator"}`,
 			},
 		},
 		{
@@ -202,6 +266,7 @@ func TestLoginStaticCommand(t *testing.T) {
 						},
 					}, nil
 				},
+				keyring: &fakeKeyring{},
 			})
 			require.NotNil(t, cmd)
 
@@ -0,0 +1,260 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/cachecrypter"
+	"go.pinniped.dev/internal/execcredcache"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/net/pproxy"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/filesession"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+//nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(logoutCommand(logoutCommandRealDeps()))
+}
+
+type logoutCommandDeps struct {
+	lookupEnv    func(string) (string, bool)
+	logout       func(string, string, *oidctypes.Token, ...oidcclient.Option) error
+	keyring      cachecrypter.Keyring
+	systemPACURL func() string
+}
+
+func logoutCommandRealDeps() logoutCommandDeps {
+	return logoutCommandDeps{
+		lookupEnv:    os.LookupEnv,
+		logout:       oidcclient.Logout,
+		keyring:      cachecrypter.OSKeyring(),
+		systemPACURL: pproxy.SystemPACURL,
+	}
+}
+
+type logoutFlags struct {
+	issuer                    string
+	clientID                  string
+	kubeconfigPath            string
+	kubeconfigContextOverride string
+	sessionCachePath          string
+	credentialCachePath       string
+	caBundlePaths             []string
+	caBundleData              []string
+	proxy                     string
+	proxyPACURL               string
+	useOSKeychain             bool
+	skipRevocation            bool
+	endSession                bool
+	skipBrowser               bool
+	browserCommand            string
+}
+
+func logoutCommand(deps logoutCommandDeps) *cobra.Command {
+	var (
+		cmd = &cobra.Command{
+			Args:  cobra.NoArgs,
+			Use:   "logout",
+			Short: "Log out of an OpenID Connect issuer",
+			Long: here.Doc(
+				`Log out of an OpenID Connect issuer
+
+					Removes the sessions for the issuer and client ID from the session cache. Unless
+					--skip-revocation is specified, the refresh token of each removed session is also
+					revoked at the issuer, so that the session can no longer be used.
+
+					The issuer and client ID are either given by --issuer and --client-id, or are read
+					from the "pinniped login oidc" command of a Pinniped-compatible kubeconfig. When
+					they are read from the kubeconfig, the cluster credential of that kubeconfig
+					context is also removed from the credential cache. Other cached cluster
+					credentials are left alone, and expire within minutes.
+
+					The Pinniped Supervisor does not support RP-Initiated Logout, so --end-session
+					does nothing when the issuer is a Supervisor.`,
+			),
+			SilenceUsage: true, // do not print usage message when commands fail
+		}
+		flags logoutFlags
+	)
+	cmd.Flags().StringVar(&flags.issuer, "issuer", "", "OpenID Connect issuer URL (default: read from the kubeconfig)")
+	cmd.Flags().StringVar(&flags.clientID, "client-id", oidcapi.ClientIDPinnipedCLI, "OpenID Connect client ID (default: read from the kubeconfig when --issuer is not given)")
+	cmd.Flags().StringVar(&flags.kubeconfigPath, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to kubeconfig file")
+	cmd.Flags().StringVar(&flags.kubeconfigContextOverride, "kubeconfig-context", "", "Kubeconfig context name (default: current active context)")
+	mustRegisterFlagCompletion(cmd, "kubeconfig-context", completeKubeconfigContexts(&flags.kubeconfigPath))
+	cmd.Flags().StringVar(&flags.sessionCachePath, "session-cache", filepath.Join(mustGetConfigDir(), "sessions.yaml"), "Path to session cache file")
+	cmd.Flags().StringVar(&flags.credentialCachePath, "credential-cache", filepath.Join(mustGetConfigDir(), "credentials.yaml"), "Path to cluster-specific credentials cache (\"\" disables the cache)")
+	cmd.Flags().StringSliceVar(&flags.caBundlePaths, "ca-bundle", nil, "Path to TLS certificate authority bundle (PEM format, optional, can be repeated)")
+	cmd.Flags().StringSliceVar(&flags.caBundleData, "ca-bundle-data", nil, "Base64 encoded TLS certificate authority bundle (base64 encoded PEM format, optional, can be repeated)")
+	cmd.Flags().StringVar(&flags.proxy, "proxy", "", "Proxy URL to use when connecting to the issuer, or 'direct' to use no proxy (default: use the proxy environment variables, or else the proxy auto-config of the OS)")
+	cmd.Flags().StringVar(&flags.proxyPACURL, "proxy-pac-url", "", "URL or path of a proxy auto-config (PAC) file which chooses the proxy to use when connecting to the issuer")
+	cmd.Flags().BoolVar(&flags.useOSKeychain, "use-os-keychain", true, "Decrypt the session and credential caches using a key stored in the OS keychain, when one is available")
+	cmd.Flags().BoolVar(&flags.skipRevocation, "skip-revocation", false, "Only remove the sessions from the local caches, without revoking them at the issuer")
+	cmd.Flags().BoolVar(&flags.endSession, "end-session", false, "Also end the browser session at the issuer, when it supports OpenID Connect RP-Initiated Logout (the Pinniped Supervisor does not)")
+	cmd.Flags().BoolVar(&flags.skipBrowser, "skip-browser", false, "Skip opening the browser to end the session (just print the URL)")
+	cmd.Flags().StringVar(&flags.browserCommand, "browser-command", "", fmt.Sprintf("Command used to open the browser to end the session, to which the URL is appended, or in which %%s is replaced by the URL (default: the default browser of the OS, overridden by $%s)", browserCommandEnvVarName))
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runLogout(cmd, deps, flags)
+	}
+	return cmd
+}
+
+func runLogout(cmd *cobra.Command, deps logoutCommandDeps, flags logoutFlags) error {
+	pLogger, err := SetLogLevel(cmd.Context(), deps.lookupEnv)
+	if err != nil {
+		plog.WarningErr("Received error while setting log level", err)
+	}
+
+	// When no issuer was given, use the settings of the login command in the kubeconfig, unless they were overridden.
+	var credentialCacheKey *oidcCredentialCacheKey
+	if flags.issuer == "" {
+		if credentialCacheKey, err = flagsFromKubeconfig(cmd, &flags); err != nil {
+			return err
+		}
+	}
+
+	crypter, err := cacheCrypter(flags.useOSKeychain, deps.lookupEnv, deps.keyring, pLogger)
+	if err != nil {
+		return err
+	}
+	var sessionOptions []filesession.Option
+	if crypter != nil {
+		sessionOptions = append(sessionOptions, filesession.WithCrypter(crypter))
+	}
+
+	sessions := filesession.New(flags.sessionCachePath, sessionOptions...).DeleteTokens(func(key oidcclient.SessionCacheKey) bool {
+		return key.Issuer == flags.issuer && key.ClientID == flags.clientID
+	})
+	pLogger.Debug("removed cached sessions", "issuer", flags.issuer, "clientID", flags.clientID, "count", len(sessions))
+
+	// The keys of the credential cache are hashed, so only the credential of the kubeconfig context can be found.
+	if credentialCacheKey != nil && flags.credentialCachePath != "" {
+		execcredcache.New(flags.credentialCachePath, credCacheOptions(crypter)...).Delete(credentialCacheKey)
+	}
+
+	if !flags.skipRevocation {
+		opts := []oidcclient.Option{
+			oidcclient.WithContext(cmd.Context()),
+			oidcclient.WithLogger(plog.Logr()), //nolint:staticcheck  // old code with lots of log statements
+		}
+		proxy, err := makeProxy(flags.proxy, flags.proxyPACURL, deps.lookupEnv, deps.systemPACURL, pLogger)
+		if err != nil {
+			return err
+		}
+		if len(flags.caBundlePaths) > 0 || len(flags.caBundleData) > 0 || proxy != nil {
+			client, err := makeClient(flags.caBundlePaths, flags.caBundleData, proxy)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, oidcclient.WithClient(client))
+		}
+		if flags.skipBrowser {
+			opts = append(opts, oidcclient.WithSkipBrowserOpen())
+		} else {
+			browserOpts, err := browserCommandOptions(flags.browserCommand, deps.lookupEnv)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, browserOpts...)
+		}
+
+		for i := range sessions {
+			sessionOpts := opts
+			// Only end the browser session once, since all the sessions were started by the same browser login.
+			if flags.endSession && i == 0 {
+				sessionOpts = append(sessionOpts, oidcclient.WithEndSession())
+			}
+			if err := deps.logout(flags.issuer, sessions[i].Key.ClientID, &sessions[i].Tokens, sessionOpts...); err != nil {
+				return fmt.Errorf("removed cached sessions, but could not log out of %s: %w", flags.issuer, err)
+			}
+		}
+	}
+
+	if len(sessions) == 0 {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No cached sessions found for %s\n", flags.issuer)
+		return nil
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Logged out of %s\n", flags.issuer)
+	return nil
+}
+
+// flagsFromKubeconfig sets the issuer, and any of the client ID, cache, CA bundle, and proxy flags which were not given
+// on the command line, from the "pinniped login oidc" exec credential plugin of the current (or selected) kubeconfig
+// context. It returns the key of the cluster credential of that plugin in the credential cache.
+func flagsFromKubeconfig(cmd *cobra.Command, flags *logoutFlags) (*oidcCredentialCacheKey, error) {
+	kubeconfig, err := newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride).RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not load kubeconfig: %w", err)
+	}
+	contextName := kubeconfig.CurrentContext
+	if flags.kubeconfigContextOverride != "" {
+		contextName = flags.kubeconfigContextOverride
+	}
+	kubeContext, ok := kubeconfig.Contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("could not find kubeconfig context %q", contextName)
+	}
+	authInfo, ok := kubeconfig.AuthInfos[kubeContext.AuthInfo]
+	if !ok || authInfo.Exec == nil || len(authInfo.Exec.Args) < 2 ||
+		authInfo.Exec.Args[0] != "login" || authInfo.Exec.Args[1] != "oidc" {
+		return nil, fmt.Errorf("kubeconfig context %q does not use \"pinniped login oidc\", please specify --issuer", contextName)
+	}
+
+	loginCmd := oidcLoginCommand(oidcLoginCommandDeps{})
+	if err := loginCmd.ParseFlags(authInfo.Exec.Args[2:]); err != nil {
+		return nil, fmt.Errorf("could not parse \"pinniped login oidc\" arguments from kubeconfig context %q: %w", contextName, err)
+	}
+	loginFlags := loginCmd.Flags()
+	if flags.issuer, err = loginFlags.GetString("issuer"); err != nil || flags.issuer == "" {
+		return nil, fmt.Errorf("kubeconfig context %q has no --issuer, please specify --issuer", contextName)
+	}
+	// The login command's flags have the same names, types, and defaults as the flags of this command.
+	if !cmd.Flags().Changed("client-id") {
+		flags.clientID, _ = loginFlags.GetString("client-id")
+	}
+	if !cmd.Flags().Changed("session-cache") {
+		flags.sessionCachePath, _ = loginFlags.GetString("session-cache")
+	}
+	if !cmd.Flags().Changed("credential-cache") {
+		flags.credentialCachePath, _ = loginFlags.GetString("credential-cache")
+	}
+	if !cmd.Flags().Changed("ca-bundle") {
+		flags.caBundlePaths, _ = loginFlags.GetStringSlice("ca-bundle")
+	}
+	if !cmd.Flags().Changed("ca-bundle-data") {
+		flags.caBundleData, _ = loginFlags.GetStringSlice("ca-bundle-data")
+	}
+	if !cmd.Flags().Changed("proxy") {
+		flags.proxy, _ = loginFlags.GetString("proxy")
+	}
+	if !cmd.Flags().Changed("proxy-pac-url") {
+		flags.proxyPACURL, _ = loginFlags.GetString("proxy-pac-url")
+	}
+	if !cmd.Flags().Changed("use-os-keychain") {
+		flags.useOSKeychain, _ = loginFlags.GetBool("use-os-keychain")
+	}
+
+	// Use the same key as the exec credential plugin, which client-go runs with the arguments of the kubeconfig and,
+	// when requested, with information about the cluster.
+	credentialCacheKey := &oidcCredentialCacheKey{Args: authInfo.Exec.Args}
+	if authInfo.Exec.ProvideClusterInfo {
+		restConfig, err := newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("could not load kubeconfig: %w", err)
+		}
+		if credentialCacheKey.ClusterInfo, err = execClusterInfo(restConfig); err != nil {
+			return nil, err
+		}
+	}
+	return credentialCacheKey, nil
+}
@@ -0,0 +1,353 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/execcredcache"
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/pkg/oidcclient"
+	"go.pinniped.dev/pkg/oidcclient/filesession"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+func TestLogoutCommand(t *testing.T) {
+	cfgDir := mustGetConfigDir()
+
+	testCA, err := certauthority.New("Test CA", 1*time.Hour)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		// args may use the placeholders TMPDIR and KUBECONFIG, which are replaced by per-test paths.
+		args       []string
+		kubeconfig string
+		logoutErr  error
+		// clusterCredentialKey is the key of a cluster credential which is added to the credential cache. Its args may
+		// use the placeholder TMPDIR.
+		clusterCredentialKey *oidcCredentialCacheKey
+
+		wantError                    bool
+		wantStdout, wantStderr       string
+		wantLogoutClientIDs          []string
+		wantOptionsCounts            []int
+		wantRemainingIssuers         []string
+		wantClusterCredentialRemoved bool
+	}{
+		{
+			name: "help flag passed",
+			args: []string{"--help"},
+			wantStdout: here.Doc(`
+				Log out of an OpenID Connect issuer
+
+				Removes the sessions for the issuer and client ID from the session cache. Unless
+				--skip-revocation is specified, the refresh token of each removed session is also
+				revoked at the issuer, so that the session can no longer be used.
+
+				The issuer and client ID are either given by --issuer and --client-id, or are read
+				from the "pinniped login oidc" command of a Pinniped-compatible kubeconfig. When
+				they are read from the kubeconfig, the cluster credential of that kubeconfig
+				context is also removed from the credential cache. Other cached cluster
+				credentials are left alone, and expire within minutes.
+
+				The Pinniped Supervisor does not support RP-Initiated Logout, so --end-session
+				does nothing when the issuer is a Supervisor.
+
+				Usage:
+				  logout [flags]
+
+				Flags:
+				      --browser-command string      Command used to open the browser to end the session, to which the URL is appended, or in which %s is replaced by the URL (default: the default browser of the OS, overridden by $PINNIPED_BROWSER)
+				      --ca-bundle strings           Path to TLS certificate authority bundle (PEM format, optional, can be repeated)
+				      --ca-bundle-data strings      Base64 encoded TLS certificate authority bundle (base64 encoded PEM format, optional, can be repeated)
+				      --client-id string            OpenID Connect client ID (default: read from the kubeconfig when --issuer is not given) (default "pinniped-cli")
+				      --credential-cache string     Path to cluster-specific credentials cache ("" disables the cache) (default "` + cfgDir + `/credentials.yaml")
+				      --end-session                 Also end the browser session at the issuer, when it supports OpenID Connect RP-Initiated Logout (the Pinniped Supervisor does not)
+				  -h, --help                        help for logout
+				      --issuer string               OpenID Connect issuer URL (default: read from the kubeconfig)
+				      --kubeconfig string           Path to kubeconfig file
+				      --kubeconfig-context string   Kubeconfig context name (default: current active context)
+				      --proxy string                Proxy URL to use when connecting to the issuer, or 'direct' to use no proxy (default: use the proxy environment variables, or else the proxy auto-config of the OS)
+				      --proxy-pac-url string        URL or path of a proxy auto-config (PAC) file which chooses the proxy to use when connecting to the issuer
+				      --session-cache string        Path to session cache file (default "` + cfgDir + `/sessions.yaml")
+				      --skip-browser                Skip opening the browser to end the session (just print the URL)
+				      --skip-revocation             Only remove the sessions from the local caches, without revoking them at the issuer
+				      --use-os-keychain             Decrypt the session and credential caches using a key stored in the OS keychain, when one is available (default true)
+			`),
+			wantRemainingIssuers: []string{"test-issuer", "test-issuer", "test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "issuer flag",
+			args:                 []string{"--issuer", "test-issuer"},
+			wantStdout:           "Logged out of test-issuer\n",
+			wantLogoutClientIDs:  []string{"test-client-id-1", "test-client-id-1"},
+			wantOptionsCounts:    []int{2, 2},
+			wantRemainingIssuers: []string{"test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "client ID flag",
+			args:                 []string{"--issuer", "test-issuer", "--client-id", "test-client-id-2"},
+			wantStdout:           "Logged out of test-issuer\n",
+			wantLogoutClientIDs:  []string{"test-client-id-2"},
+			wantOptionsCounts:    []int{2},
+			wantRemainingIssuers: []string{"test-issuer", "test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "no sessions for issuer",
+			args:                 []string{"--issuer", "test-unknown-issuer"},
+			wantStdout:           "No cached sessions found for test-unknown-issuer\n",
+			wantRemainingIssuers: []string{"test-issuer", "test-issuer", "test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "skip revocation",
+			args:                 []string{"--issuer", "test-issuer", "--skip-revocation"},
+			wantStdout:           "Logged out of test-issuer\n",
+			wantRemainingIssuers: []string{"test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "end session only once",
+			args:                 []string{"--issuer", "test-issuer", "--end-session", "--skip-browser", "--ca-bundle-data", base64.StdEncoding.EncodeToString(testCA.Bundle())},
+			wantStdout:           "Logged out of test-issuer\n",
+			wantLogoutClientIDs:  []string{"test-client-id-1", "test-client-id-1"},
+			wantOptionsCounts:    []int{5, 4},
+			wantRemainingIssuers: []string{"test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "end session with a browser command",
+			args:                 []string{"--issuer", "test-issuer", "--end-session", "--browser-command", "some-browser --some-profile"},
+			wantStdout:           "Logged out of test-issuer\n",
+			wantLogoutClientIDs:  []string{"test-client-id-1", "test-client-id-1"},
+			wantOptionsCounts:    []int{4, 3},
+			wantRemainingIssuers: []string{"test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "invalid browser command",
+			args:                 []string{"--issuer", "test-issuer", "--browser-command", "'unterminated"},
+			wantError:            true,
+			wantStderr:           "Error: invalid --browser-command: unterminated quote or escape in \"'unterminated\"\n",
+			wantRemainingIssuers: []string{"test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "credential cache disabled",
+			args:                 []string{"--issuer", "test-issuer", "--credential-cache", ""},
+			wantStdout:           "Logged out of test-issuer\n",
+			wantLogoutClientIDs:  []string{"test-client-id-1", "test-client-id-1"},
+			wantOptionsCounts:    []int{2, 2},
+			wantRemainingIssuers: []string{"test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "invalid CA bundle",
+			args:                 []string{"--issuer", "test-issuer", "--ca-bundle-data", "invalid-base64"},
+			wantError:            true,
+			wantStderr:           "Error: could not read --ca-bundle-data: illegal base64 data at input byte 7\n",
+			wantRemainingIssuers: []string{"test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "invalid proxy",
+			args:                 []string{"--issuer", "test-issuer", "--proxy", "ftp://proxy.example.com"},
+			wantError:            true,
+			wantStderr:           "Error: invalid proxy URL \"ftp://proxy.example.com\": scheme must be \"http\", \"https\", or \"socks5\"\n",
+			wantRemainingIssuers: []string{"test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "revocation error",
+			args:                 []string{"--issuer", "test-issuer"},
+			logoutErr:            fmt.Errorf("some revocation error"),
+			wantError:            true,
+			wantStderr:           "Error: removed cached sessions, but could not log out of test-issuer: some revocation error\n",
+			wantLogoutClientIDs:  []string{"test-client-id-1"},
+			wantOptionsCounts:    []int{2},
+			wantRemainingIssuers: []string{"test-issuer", "test-other-issuer"},
+		},
+		{
+			name: "issuer, caches, and proxy from kubeconfig",
+			args: []string{"--kubeconfig", "KUBECONFIG"},
+			kubeconfig: here.Doc(`
+				apiVersion: v1
+				kind: Config
+				current-context: pinniped
+				contexts:
+				- name: pinniped
+				  context:
+				    cluster: pinniped
+				    user: pinniped
+				clusters:
+				- name: pinniped
+				  cluster:
+				    server: https://fake-server-url-value
+				users:
+				- name: pinniped
+				  user:
+				    exec:
+				      apiVersion: client.authentication.k8s.io/v1beta1
+				      command: pinniped
+				      args:
+				      - login
+				      - oidc
+				      - --issuer=test-issuer
+				      - --client-id=test-client-id-1
+				      - --session-cache=TMPDIR/sessions.yaml
+				      - --credential-cache=TMPDIR/credentials.yaml
+				      - --proxy=http://proxy.example.com:3128
+				      provideClusterInfo: true
+			`),
+			clusterCredentialKey: &oidcCredentialCacheKey{
+				Args: []string{
+					"login",
+					"oidc",
+					"--issuer=test-issuer",
+					"--client-id=test-client-id-1",
+					"--session-cache=TMPDIR/sessions.yaml",
+					"--credential-cache=TMPDIR/credentials.yaml",
+					"--proxy=http://proxy.example.com:3128",
+				},
+				ClusterInfo: &clientauthv1beta1.Cluster{Server: "https://fake-server-url-value"},
+			},
+			wantStdout:                   "Logged out of test-issuer\n",
+			wantLogoutClientIDs:          []string{"test-client-id-1", "test-client-id-1"},
+			wantOptionsCounts:            []int{3, 3},
+			wantRemainingIssuers:         []string{"test-issuer", "test-other-issuer"},
+			wantClusterCredentialRemoved: true,
+		},
+		{
+			name: "kubeconfig does not use pinniped login oidc",
+			args: []string{"--kubeconfig", "KUBECONFIG"},
+			kubeconfig: here.Doc(`
+				apiVersion: v1
+				kind: Config
+				current-context: static
+				contexts:
+				- name: static
+				  context:
+				    cluster: static
+				    user: static
+				clusters:
+				- name: static
+				  cluster:
+				    server: https://fake-server-url-value
+				users:
+				- name: static
+				  user:
+				    exec:
+				      apiVersion: client.authentication.k8s.io/v1beta1
+				      command: pinniped
+				      args: [login, static, --token=test-token]
+			`),
+			wantError:            true,
+			wantStderr:           "Error: kubeconfig context \"static\" does not use \"pinniped login oidc\", please specify --issuer\n",
+			wantRemainingIssuers: []string{"test-issuer", "test-issuer", "test-issuer", "test-other-issuer"},
+		},
+		{
+			name:                 "kubeconfig context not found",
+			args:                 []string{"--kubeconfig", "testdata/kubeconfig.yaml", "--kubeconfig-context", "does-not-exist"},
+			wantError:            true,
+			wantStderr:           "Error: could not find kubeconfig context \"does-not-exist\"\n",
+			wantRemainingIssuers: []string{"test-issuer", "test-issuer", "test-issuer", "test-other-issuer"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := testutil.TempDir(t)
+			sessionCachePath := filepath.Join(tmp, "sessions.yaml")
+			credentialCachePath := filepath.Join(tmp, "credentials.yaml")
+
+			// Unless the test uses a kubeconfig, point the command at the per-test caches.
+			args := tt.args
+			if tt.kubeconfig != "" {
+				kubeconfigPath := filepath.Join(tmp, "kubeconfig.yaml")
+				require.NoError(t, os.WriteFile(kubeconfigPath, []byte(strings.ReplaceAll(tt.kubeconfig, "TMPDIR", tmp)), 0600))
+				args = append([]string{}, args...)
+				for i := range args {
+					args[i] = strings.ReplaceAll(args[i], "KUBECONFIG", kubeconfigPath)
+				}
+			} else {
+				args = append([]string{"--session-cache", sessionCachePath, "--credential-cache", credentialCachePath, "--client-id", "test-client-id-1"}, args...)
+			}
+
+			// Populate the caches. The credential cache is never encrypted by this test, so that it is easy to inspect.
+			sessionCache := filesession.New(sessionCachePath)
+			for i, key := range []oidcclient.SessionCacheKey{
+				{Issuer: "test-issuer", ClientID: "test-client-id-1"},
+				{Issuer: "test-issuer", ClientID: "test-client-id-1", Scopes: []string{"openid", "test-scope"}},
+				{Issuer: "test-issuer", ClientID: "test-client-id-2"},
+				{Issuer: "test-other-issuer", ClientID: "test-client-id-1"},
+			} {
+				sessionCache.PutToken(key, &oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: fmt.Sprintf("test-refresh-token-%d", i)}})
+			}
+			credCache := execcredcache.New(credentialCachePath)
+			cred := &clientauthv1beta1.ExecCredential{Status: &clientauthv1beta1.ExecCredentialStatus{
+				Token:               "test-token",
+				ExpirationTimestamp: &metav1.Time{Time: time.Now().Add(time.Hour)},
+			}}
+			credCache.Put("test-key", cred)
+			var clusterCredentialKey *oidcCredentialCacheKey
+			if tt.clusterCredentialKey != nil {
+				clusterCredentialKey = &oidcCredentialCacheKey{ClusterInfo: tt.clusterCredentialKey.ClusterInfo}
+				for _, arg := range tt.clusterCredentialKey.Args {
+					clusterCredentialKey.Args = append(clusterCredentialKey.Args, strings.ReplaceAll(arg, "TMPDIR", tmp))
+				}
+				credCache.Put(clusterCredentialKey, cred)
+			}
+
+			var (
+				gotClientIDs     []string
+				gotOptionsCounts []int
+			)
+			cmd := logoutCommand(logoutCommandDeps{
+				lookupEnv: func(string) (string, bool) { return "", false },
+				logout: func(issuer string, clientID string, token *oidctypes.Token, opts ...oidcclient.Option) error {
+					require.Equal(t, "test-issuer", issuer)
+					require.NotNil(t, token.RefreshToken)
+					gotClientIDs = append(gotClientIDs, clientID)
+					gotOptionsCounts = append(gotOptionsCounts, len(opts))
+					return tt.logoutErr
+				},
+				keyring: &fakeKeyring{err: fmt.Errorf("some keychain error")},
+			})
+			require.NotNil(t, cmd)
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(args)
+			err := cmd.ExecuteContext(context.Background())
+			if tt.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantStdout, stdout.String(), "unexpected stdout")
+			require.Equal(t, tt.wantStderr, stderr.String(), "unexpected stderr")
+			require.Equal(t, tt.wantLogoutClientIDs, gotClientIDs)
+			require.Equal(t, tt.wantOptionsCounts, gotOptionsCounts)
+
+			var remainingIssuers []string
+			for _, session := range sessionCache.DeleteTokens(func(oidcclient.SessionCacheKey) bool { return true }) {
+				remainingIssuers = append(remainingIssuers, session.Key.Issuer)
+			}
+			require.Equal(t, tt.wantRemainingIssuers, remainingIssuers)
+			// Credentials for other kubeconfig contexts are never removed.
+			require.NotNil(t, credCache.Get("test-key"))
+			if clusterCredentialKey != nil {
+				require.Equal(t, tt.wantClusterCredentialRemoved, credCache.Get(clusterCredentialKey) == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+	outputFormatYAML = "yaml"
+)
+
+// errorOutput is the schema of the document which describes a failed command when a structured output format
+// was requested. Take care when changing it, since automation may depend on it.
+type errorOutput struct {
+	// Error is the message of the error which caused the command to fail.
+	Error string `json:"error"`
+
+	// Code identifies the cause of the error (e.g. "ERR_LOGIN_FAILED"). It is one of the documented codes, which
+	// correspond to the exit status of the CLI.
+	Code errorCode `json:"code"`
+}
+
+// validateOutputFormat returns an error when the given format is not one of the allowed formats.
+func validateOutputFormat(format string, allowed ...string) error {
+	for _, a := range allowed {
+		if format == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format: %q", format)
+}
+
+// isStructuredOutputFormat returns true when the given format is meant to be parsed by automation.
+func isStructuredOutputFormat(format string) bool {
+	return format == outputFormatJSON || format == outputFormatYAML
+}
+
+// writeStructuredOutput writes obj to out as indented JSON or as YAML, based on its json struct tags.
+func writeStructuredOutput(out io.Writer, format string, obj interface{}) error {
+	var (
+		output []byte
+		err    error
+	)
+	switch format {
+	case outputFormatJSON:
+		output, err = json.MarshalIndent(obj, "", "  ")
+		output = append(output, '\n')
+	case outputFormatYAML:
+		output, err = yaml.Marshal(obj)
+	default:
+		return fmt.Errorf("unknown output format: %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(output)
+	return err
+}
+
+// handleErrorOutput describes err in the requested structured output format on the command's stderr, in which case
+// cobra will not also print the error. It returns err unchanged, so that the command still fails.
+func handleErrorOutput(cmd *cobra.Command, format string, err error) error {
+	if err == nil || !isStructuredOutputFormat(format) {
+		return err
+	}
+	if writeErr := writeStructuredOutput(cmd.ErrOrStderr(), format, &errorOutput{Error: err.Error(), Code: errorCodeOf(err)}); writeErr != nil {
+		return err
+	}
+	cmd.SilenceErrors = true
+	return err
+}
@@ -23,6 +23,14 @@ var rootCmd = &cobra.Command{
 	SilenceUsage: true, // do not print usage message when commands fail
 }
 
+//nolint:gochecknoinits
+func init() {
+	// Unknown or invalid flags are reported with the same exit status as the other invalid arguments.
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withErrorCode(errCodeInvalidArguments, err)
+	})
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
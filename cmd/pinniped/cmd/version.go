@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
@@ -7,7 +7,17 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	apimachineryversion "k8s.io/apimachinery/pkg/version"
 	"k8s.io/component-base/version"
+
+	"go.pinniped.dev/internal/crypto/fips"
+	"go.pinniped.dev/internal/here"
+)
+
+// The range of Kubernetes versions for which Pinniped generates its API clients (see hack/lib/kube-versions.txt).
+const (
+	minSupportedKubernetesVersion = "1.19"
+	maxSupportedKubernetesVersion = "1.26"
 )
 
 //nolint:gochecknoinits
@@ -15,14 +25,50 @@ func init() {
 	rootCmd.AddCommand(newVersionCommand())
 }
 
+// versionInfo is the structured output of the version command. It adds build and compatibility information to the
+// version.Info which is printed by the text output.
+type versionInfo struct {
+	apimachineryversion.Info
+	FIPSEnabled                 bool                        `json:"fipsEnabled"`
+	SupportedKubernetesVersions supportedKubernetesVersions `json:"supportedKubernetesVersions"`
+}
+
+type supportedKubernetesVersions struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
 func newVersionCommand() *cobra.Command {
-	return &cobra.Command{
+	var outputFormat string // e.g., yaml, json, text
+	cmd := &cobra.Command{
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := validateOutputFormat(outputFormat, outputFormatText, outputFormatJSON, outputFormatYAML); err != nil {
+				return err
+			}
+			if isStructuredOutputFormat(outputFormat) {
+				info := versionInfo{
+					Info:        version.Get(),
+					FIPSEnabled: fips.Enabled,
+					SupportedKubernetesVersions: supportedKubernetesVersions{
+						Min: minSupportedKubernetesVersion,
+						Max: maxSupportedKubernetesVersion,
+					},
+				}
+				return handleErrorOutput(cmd, outputFormat, writeStructuredOutput(cmd.OutOrStdout(), outputFormat, info))
+			}
 			fmt.Fprintf(cmd.OutOrStdout(), "%#v\n", version.Get())
 			return nil
 		},
 		Args:  cobra.NoArgs, // do not accept positional arguments for this command
 		Use:   "version",
 		Short: "Print the version of this Pinniped CLI",
+		Long: here.Doc(`
+			Print the version of this Pinniped CLI
+
+			The JSON and YAML output formats also include whether the CLI was built in FIPS
+			mode, and the range of Kubernetes versions that it supports.`,
+		),
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", outputFormatText, "Output format (e.g., 'yaml', 'json', 'text')")
+	return cmd
 }
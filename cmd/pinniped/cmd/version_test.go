@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
@@ -19,21 +19,61 @@ var (
 		  version \[flags\]
 
 		Flags:
-		  -h, --help   help for version
+		  -h, --help            help for version
+		  -o, --output string   Output format \(e.g., 'yaml', 'json', 'text'\) \(default "text"\)
 
 		`)
 
 	knownGoodHelpRegexpForVersion = here.Doc(`
 		Print the version of this Pinniped CLI
 
+		The JSON and YAML output formats also include whether the CLI was built in FIPS
+		mode, and the range of Kubernetes versions that it supports.
+
 		Usage:
 		  version \[flags\]
 
 		Flags:
-		  -h, --help   help for version
+		  -h, --help            help for version
+		  -o, --output string   Output format \(e.g., 'yaml', 'json', 'text'\) \(default "text"\)
 		`)
 
 	emptyVersionRegexp = `version.Info{Major:"", Minor:"", GitVersion:".*", GitCommit:".*", GitTreeState:"", BuildDate:".*", GoVersion:".*", Compiler:".*", Platform:".*/.*"}`
+
+	jsonVersionRegexp = here.Doc(`
+		{
+		  "major": "",
+		  "minor": "",
+		  "gitVersion": ".*",
+		  "gitCommit": ".*",
+		  "gitTreeState": "",
+		  "buildDate": ".*",
+		  "goVersion": ".*",
+		  "compiler": ".*",
+		  "platform": ".*/.*",
+		  "fipsEnabled": false,
+		  "supportedKubernetesVersions": {
+		    "min": "1.19",
+		    "max": "1.26"
+		  }
+		}
+		`)
+
+	yamlVersionRegexp = here.Doc(`
+		buildDate: .*
+		compiler: .*
+		fipsEnabled: false
+		gitCommit: .*
+		gitTreeState: ""
+		gitVersion: .*
+		goVersion: .*
+		major: ""
+		minor: ""
+		platform: .*/.*
+		supportedKubernetesVersions:
+		  max: "1.26"
+		  min: "1.19"
+		`)
 )
 
 func TestNewVersionCmd(t *testing.T) {
@@ -49,6 +89,22 @@ func TestNewVersionCmd(t *testing.T) {
 			args:             []string{},
 			wantStdoutRegexp: emptyVersionRegexp + "\n",
 		},
+		{
+			name:             "json output",
+			args:             []string{"--output", "json"},
+			wantStdoutRegexp: "^" + jsonVersionRegexp + "$",
+		},
+		{
+			name:             "yaml output",
+			args:             []string{"-o", "yaml"},
+			wantStdoutRegexp: "^" + yamlVersionRegexp + "$",
+		},
+		{
+			name:             "invalid output format",
+			args:             []string{"-o", "invalid-format"},
+			wantError:        true,
+			wantStderrRegexp: `Error: unknown output format: "invalid-format"`,
+		},
 		{
 			name:             "help flag passed",
 			args:             []string{"--help"},
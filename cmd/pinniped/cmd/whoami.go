@@ -8,10 +8,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	authenticationv1alpha1 "k8s.io/api/authentication/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -26,7 +28,7 @@ import (
 
 //nolint:gochecknoinits
 func init() {
-	rootCmd.AddCommand(newWhoamiCommand(getRealConciergeClientset))
+	rootCmd.AddCommand(newWhoamiCommand(getRealConciergeClientset, getRealSelfSubjectReview))
 }
 
 type whoamiFlags struct {
@@ -36,6 +38,8 @@ type whoamiFlags struct {
 	kubeconfigContextOverride string
 
 	apiGroupSuffix string
+
+	showImpersonation bool
 }
 
 type clusterInfo struct {
@@ -43,11 +47,34 @@ type clusterInfo struct {
 	url  string
 }
 
-func newWhoamiCommand(getClientset getConciergeClientsetFunc) *cobra.Command {
+// userInfo is the identity of the current user, along with the API object which reported it.
+type userInfo struct {
+	username string
+	uid      string
+	groups   []string
+	extra    map[string][]string
+
+	// originalUser is the user who impersonated this user through the impersonation proxy, if it was reported.
+	originalUser *userInfo
+
+	// source describes the API which reported the identity, e.g., "Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)".
+	source string
+	// obj is the API response, which is printed as-is for JSON and YAML output.
+	obj runtime.Object
+}
+
+func newWhoamiCommand(getClientset getConciergeClientsetFunc, getSelfSubjectReview getSelfSubjectReviewFunc) *cobra.Command {
 	cmd := &cobra.Command{
-		Args:         cobra.NoArgs, // do not accept positional arguments for this command
-		Use:          "whoami",
-		Short:        "Print information about the current user",
+		Args:  cobra.NoArgs, // do not accept positional arguments for this command
+		Use:   "whoami",
+		Short: "Print information about the current user",
+		Long: here.Doc(
+			`Print information about the current user
+
+			The identity is reported by the Pinniped WhoAmI API of the Concierge. When that API
+			is not installed, the authentication.k8s.io SelfSubjectReview API of the cluster is
+			used instead, if the cluster supports it.`,
+		),
 		SilenceUsage: true, // do not print usage message when commands fail
 	}
 	flags := &whoamiFlags{}
@@ -57,45 +84,108 @@ func newWhoamiCommand(getClientset getConciergeClientsetFunc) *cobra.Command {
 	f.StringVarP(&flags.outputFormat, "output", "o", "text", "Output format (e.g., 'yaml', 'json', 'text')")
 	f.StringVar(&flags.kubeconfigPath, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to kubeconfig file")
 	f.StringVar(&flags.kubeconfigContextOverride, "kubeconfig-context", "", "Kubeconfig context name (default: current active context)")
+	mustRegisterFlagCompletion(cmd, "kubeconfig-context", completeKubeconfigContexts(&flags.kubeconfigPath))
 	f.StringVar(&flags.apiGroupSuffix, "api-group-suffix", groupsuffix.PinnipedDefaultSuffix, "Concierge API group suffix")
+	f.BoolVar(&flags.showImpersonation, "show-impersonation", false, "Also show the original user when impersonating another user through the impersonation proxy")
 
 	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
-		return runWhoami(cmd.OutOrStdout(), getClientset, flags)
+		return handleErrorOutput(cmd, flags.outputFormat, runWhoami(cmd.OutOrStdout(), getClientset, getSelfSubjectReview, flags))
 	}
 
 	return cmd
 }
 
-func runWhoami(output io.Writer, getClientset getConciergeClientsetFunc, flags *whoamiFlags) error {
+func runWhoami(output io.Writer, getClientset getConciergeClientsetFunc, getSelfSubjectReview getSelfSubjectReviewFunc, flags *whoamiFlags) error {
+	if err := validateOutputFormat(flags.outputFormat, outputFormatText, outputFormatJSON, outputFormatYAML); err != nil {
+		return withErrorCode(errCodeInvalidArguments, err)
+	}
+
 	clientConfig := newClientConfig(flags.kubeconfigPath, flags.kubeconfigContextOverride)
 	clientset, err := getClientset(clientConfig, flags.apiGroupSuffix)
 	if err != nil {
-		return fmt.Errorf("could not configure Kubernetes client: %w", err)
+		return withErrorCode(errCodeKubeconfigInvalid, fmt.Errorf("could not configure Kubernetes client: %w", err))
 	}
 
 	clusterInfo, err := getCurrentCluster(clientConfig, flags.kubeconfigContextOverride)
 	if err != nil {
-		return fmt.Errorf("could not get current cluster info: %w", err)
+		return withErrorCode(errCodeKubeconfigInvalid, fmt.Errorf("could not get current cluster info: %w", err))
 	}
 
 	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*20)
 	defer cancelFunc()
-	whoAmI, err := clientset.IdentityV1alpha1().WhoAmIRequests().Create(ctx, &identityv1alpha1.WhoAmIRequest{}, metav1.CreateOptions{})
-	if err != nil {
-		hint := ""
-		if errors.IsNotFound(err) {
-			hint = " (is the Pinniped WhoAmI API running and healthy?)"
+	whoAmI, err := clientset.IdentityV1alpha1().WhoAmIRequests().Create(ctx, &identityv1alpha1.WhoAmIRequest{
+		Spec: identityv1alpha1.WhoAmIRequestSpec{IncludeImpersonation: flags.showImpersonation},
+	}, metav1.CreateOptions{})
+	var user *userInfo
+	switch {
+	case err == nil:
+		user = userInfoFromWhoAmI(whoAmI, flags.apiGroupSuffix)
+	case errors.IsNotFound(err):
+		// The WhoAmI API is not installed, so ask the cluster itself, when it supports SelfSubjectReview.
+		review, reviewErr := getSelfSubjectReview(ctx, clientConfig)
+		if errors.IsNotFound(reviewErr) {
+			return withErrorCode(errCodeClusterUnavailable, fmt.Errorf("could not complete WhoAmIRequest (is the Pinniped WhoAmI API running and healthy?): %w", err))
 		}
-		return fmt.Errorf("could not complete WhoAmIRequest%s: %w", hint, err)
+		if reviewErr != nil {
+			return withErrorCode(errCodeClusterUnavailable, fmt.Errorf("could not complete SelfSubjectReview: %w", reviewErr))
+		}
+		user = userInfoFromSelfSubjectReview(review)
+	default:
+		return withErrorCode(errCodeClusterUnavailable, fmt.Errorf("could not complete WhoAmIRequest: %w", err))
 	}
 
-	if err := writeWhoamiOutput(output, flags, clusterInfo, whoAmI); err != nil {
+	if err := writeWhoamiOutput(output, flags, clusterInfo, user); err != nil {
 		return fmt.Errorf("could not write output: %w", err)
 	}
 
 	return nil
 }
 
+func userInfoFromWhoAmI(whoAmI *identityv1alpha1.WhoAmIRequest, apiGroupSuffix string) *userInfo {
+	_, _, identityGV := conciergescheme.New(apiGroupSuffix)
+
+	// Ensure that these fields are set so that the JSON/YAML output tells the full story.
+	whoAmI.APIVersion = identityGV.String()
+	whoAmI.Kind = "WhoAmIRequest"
+
+	user := userInfoFromWhoAmIUser(&whoAmI.Status.KubernetesUserInfo.User)
+	if whoAmI.Status.OriginalUser != nil {
+		user.originalUser = userInfoFromWhoAmIUser(whoAmI.Status.OriginalUser)
+	}
+	user.source = fmt.Sprintf("Pinniped WhoAmI API (%s)", identityGV)
+	user.obj = whoAmI
+	return user
+}
+
+func userInfoFromWhoAmIUser(user *identityv1alpha1.UserInfo) *userInfo {
+	extra := make(map[string][]string, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = v
+	}
+	return &userInfo{
+		username: user.Username,
+		uid:      user.UID,
+		groups:   user.Groups,
+		extra:    extra,
+	}
+}
+
+func userInfoFromSelfSubjectReview(review *authenticationv1alpha1.SelfSubjectReview) *userInfo {
+	user := review.Status.UserInfo
+	extra := make(map[string][]string, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = v
+	}
+	return &userInfo{
+		username: user.Username,
+		uid:      user.UID,
+		groups:   user.Groups,
+		extra:    extra,
+		source:   fmt.Sprintf("Kubernetes SelfSubjectReview API (%s)", review.APIVersion),
+		obj:      review,
+	}
+}
+
 func getCurrentCluster(clientConfig clientcmd.ClientConfig, currentContextNameOverride string) (*clusterInfo, error) {
 	currentKubeConfig, err := clientConfig.RawConfig()
 	if err != nil {
@@ -121,20 +211,20 @@ func getCurrentCluster(clientConfig clientcmd.ClientConfig, currentContextNameOv
 	return &clusterInfo{name: ctx.Cluster, url: cluster.Server}, nil
 }
 
-func writeWhoamiOutput(output io.Writer, flags *whoamiFlags, cInfo *clusterInfo, whoAmI *identityv1alpha1.WhoAmIRequest) error {
+func writeWhoamiOutput(output io.Writer, flags *whoamiFlags, cInfo *clusterInfo, user *userInfo) error {
 	switch flags.outputFormat {
-	case "text":
-		return writeWhoamiOutputText(output, cInfo, whoAmI)
-	case "json":
-		return writeWhoamiOutputJSON(output, flags.apiGroupSuffix, whoAmI)
-	case "yaml":
-		return writeWhoamiOutputYAML(output, flags.apiGroupSuffix, whoAmI)
+	case outputFormatText:
+		return writeWhoamiOutputText(output, cInfo, user)
+	case outputFormatJSON:
+		return writeWhoamiOutputJSON(output, flags.apiGroupSuffix, user)
+	case outputFormatYAML:
+		return writeWhoamiOutputYAML(output, flags.apiGroupSuffix, user)
 	default:
 		return fmt.Errorf("unknown output format: %q", flags.outputFormat)
 	}
 }
 
-func writeWhoamiOutputText(output io.Writer, clusterInfo *clusterInfo, whoAmI *identityv1alpha1.WhoAmIRequest) error {
+func writeWhoamiOutputText(output io.Writer, clusterInfo *clusterInfo, user *userInfo) error {
 	fmt.Fprint(output, here.Docf(`
 		Current cluster info:
 
@@ -143,22 +233,46 @@ func writeWhoamiOutputText(output io.Writer, clusterInfo *clusterInfo, whoAmI *i
 
 		Current user info:
 
-		Username: %s
-		Groups: %s
-`, clusterInfo.name, clusterInfo.url, whoAmI.Status.KubernetesUserInfo.User.Username, prettyStrings(whoAmI.Status.KubernetesUserInfo.User.Groups)))
+`, clusterInfo.name, clusterInfo.url))
+	writeWhoamiUserText(output, user)
+	if user.originalUser != nil {
+		fmt.Fprint(output, "\nOriginal user info:\n\n")
+		writeWhoamiUserText(output, user.originalUser)
+	}
+	fmt.Fprintf(output, "\nSource: %s\n", user.source)
 	return nil
 }
 
-func writeWhoamiOutputJSON(output io.Writer, apiGroupSuffix string, whoAmI *identityv1alpha1.WhoAmIRequest) error {
-	return serialize(output, apiGroupSuffix, whoAmI, runtime.ContentTypeJSON)
+func writeWhoamiUserText(output io.Writer, user *userInfo) {
+	fmt.Fprintf(output, "Username: %s\n", user.username)
+	if user.uid != "" {
+		fmt.Fprintf(output, "UID: %s\n", user.uid)
+	}
+	fmt.Fprintf(output, "Groups: %s\n", prettyStrings(user.groups))
+	if len(user.extra) > 0 {
+		fmt.Fprintln(output, "Extra:")
+		keys := make([]string, 0, len(user.extra))
+		for k := range user.extra {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(output, "  %s: %s\n", k, prettyStrings(user.extra[k]))
+		}
+	}
+}
+
+func writeWhoamiOutputJSON(output io.Writer, apiGroupSuffix string, user *userInfo) error {
+	return serialize(output, apiGroupSuffix, user.obj, runtime.ContentTypeJSON)
 }
 
-func writeWhoamiOutputYAML(output io.Writer, apiGroupSuffix string, whoAmI *identityv1alpha1.WhoAmIRequest) error {
-	return serialize(output, apiGroupSuffix, whoAmI, runtime.ContentTypeYAML)
+func writeWhoamiOutputYAML(output io.Writer, apiGroupSuffix string, user *userInfo) error {
+	return serialize(output, apiGroupSuffix, user.obj, runtime.ContentTypeYAML)
 }
 
-func serialize(output io.Writer, apiGroupSuffix string, whoAmI *identityv1alpha1.WhoAmIRequest, contentType string) error {
-	scheme, _, identityGV := conciergescheme.New(apiGroupSuffix)
+// serialize writes obj, which must already have its APIVersion and Kind set, in the given content type.
+func serialize(output io.Writer, apiGroupSuffix string, obj runtime.Object, contentType string) error {
+	scheme, _, _ := conciergescheme.New(apiGroupSuffix)
 	codecs := serializer.NewCodecFactory(scheme)
 	respInfo, ok := runtime.SerializerInfoForMediaType(codecs.SupportedMediaTypes(), contentType)
 	if !ok {
@@ -172,11 +286,7 @@ func serialize(output io.Writer, apiGroupSuffix string, whoAmI *identityv1alpha1
 		serializer = respInfo.Serializer
 	}
 
-	// Ensure that these fields are set so that the JSON/YAML output tells the full story.
-	whoAmI.APIVersion = identityGV.String()
-	whoAmI.Kind = "WhoAmIRequest"
-
-	return serializer.Encode(whoAmI, output)
+	return serializer.Encode(obj, output)
 }
 
 func prettyStrings(ss []string) string {
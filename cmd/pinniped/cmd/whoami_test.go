@@ -1,17 +1,22 @@
-// Copyright 2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package cmd
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authenticationv1alpha1 "k8s.io/api/authentication/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubetesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/strings/slices"
 
 	identityv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/identity/v1alpha1"
 	conciergeclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
@@ -27,6 +32,10 @@ func TestWhoami(t *testing.T) {
 		groupsOverride         []string
 		gettingClientsetErr    error
 		callingAPIErr          error
+		withUIDAndExtra        bool
+		withOriginalUser       bool
+		whoAmINotInstalled     bool
+		selfSubjectReviewErr   error
 		wantError              bool
 		wantStdout, wantStderr string
 	}{
@@ -36,6 +45,10 @@ func TestWhoami(t *testing.T) {
 			wantStdout: here.Doc(`
 				Print information about the current user
 
+				The identity is reported by the Pinniped WhoAmI API of the Concierge. When that API
+				is not installed, the authentication.k8s.io SelfSubjectReview API of the cluster is
+				used instead, if the cluster supports it.
+
 				Usage:
 				  whoami [flags]
 
@@ -45,6 +58,7 @@ func TestWhoami(t *testing.T) {
 				      --kubeconfig string           Path to kubeconfig file
 				      --kubeconfig-context string   Kubeconfig context name (default: current active context)
 				  -o, --output string               Output format (e.g., 'yaml', 'json', 'text') (default "text")
+				      --show-impersonation          Also show the original user when impersonating another user through the impersonation proxy
 			`),
 		},
 		{
@@ -60,6 +74,8 @@ func TestWhoami(t *testing.T) {
 
 				Username: some-username
 				Groups: some-group-0, some-group-1
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
 			`),
 		},
 		{
@@ -75,6 +91,8 @@ func TestWhoami(t *testing.T) {
 
 				Username: some-username
 				Groups: some-group-0, some-group-1
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
 			`),
 		},
 		{
@@ -91,6 +109,8 @@ func TestWhoami(t *testing.T) {
 
 				Username: some-username
 				Groups: some-group-0
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
 			`),
 		},
 		{
@@ -107,6 +127,8 @@ func TestWhoami(t *testing.T) {
 
 				Username: some-username
 				Groups: 
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
 			`),
 		},
 		{
@@ -193,6 +215,176 @@ func TestWhoami(t *testing.T) {
 				      username: some-username
 			`),
 		},
+		{
+			name:            "text output with uid and extra",
+			args:            []string{"--kubeconfig", "testdata/kubeconfig.yaml"},
+			withUIDAndExtra: true,
+			wantStdout: here.Doc(`
+				Current cluster info:
+
+				Name: kind-cluster
+				URL: https://fake-server-url-value
+
+				Current user info:
+
+				Username: some-username
+				UID: some-uid
+				Groups: some-group-0, some-group-1
+				Extra:
+				  some-extra-key-0: some-extra-value-0
+				  some-extra-key-1: some-extra-value-1, some-extra-value-2
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
+			`),
+		},
+		{
+			name:             "text output with original user",
+			args:             []string{"--kubeconfig", "testdata/kubeconfig.yaml", "--show-impersonation"},
+			withOriginalUser: true,
+			wantStdout: here.Doc(`
+				Current cluster info:
+
+				Name: kind-cluster
+				URL: https://fake-server-url-value
+
+				Current user info:
+
+				Username: some-username
+				Groups: some-group-0, some-group-1
+
+				Original user info:
+
+				Username: some-original-username
+				UID: some-original-uid
+				Groups: some-original-group
+				Extra:
+				  some-original-extra-key: some-original-extra-value
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
+			`),
+		},
+		{
+			name: "text output without original user when showing impersonation",
+			args: []string{"--kubeconfig", "testdata/kubeconfig.yaml", "--show-impersonation"},
+			wantStdout: here.Doc(`
+				Current cluster info:
+
+				Name: kind-cluster
+				URL: https://fake-server-url-value
+
+				Current user info:
+
+				Username: some-username
+				Groups: some-group-0, some-group-1
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
+			`),
+		},
+		{
+			name:             "yaml output with original user",
+			args:             []string{"--kubeconfig", "testdata/kubeconfig.yaml", "--show-impersonation", "--output", "yaml"},
+			withOriginalUser: true,
+			wantStdout: here.Doc(`
+				apiVersion: identity.concierge.pinniped.dev/v1alpha1
+				kind: WhoAmIRequest
+				metadata:
+				  creationTimestamp: null
+				spec: {}
+				status:
+				  kubernetesUserInfo:
+				    user:
+				      groups:
+				      - some-group-0
+				      - some-group-1
+				      username: some-username
+				  originalUser:
+				    extra:
+				      some-original-extra-key:
+				      - some-original-extra-value
+				    groups:
+				    - some-original-group
+				    uid: some-original-uid
+				    username: some-original-username
+			`),
+		},
+		{
+			name:               "text output from SelfSubjectReview when WhoAmI API is not installed",
+			args:               []string{"--kubeconfig", "testdata/kubeconfig.yaml"},
+			whoAmINotInstalled: true,
+			wantStdout: here.Doc(`
+				Current cluster info:
+
+				Name: kind-cluster
+				URL: https://fake-server-url-value
+
+				Current user info:
+
+				Username: some-username
+				UID: some-uid
+				Groups: some-group-0, some-group-1
+				Extra:
+				  some-extra-key-0: some-extra-value-0
+				  some-extra-key-1: some-extra-value-1, some-extra-value-2
+
+				Source: Kubernetes SelfSubjectReview API (authentication.k8s.io/v1beta1)
+			`),
+		},
+		{
+			name:               "json output from SelfSubjectReview when WhoAmI API is not installed",
+			args:               []string{"--kubeconfig", "testdata/kubeconfig.yaml", "-o", "json"},
+			whoAmINotInstalled: true,
+			wantStdout: here.Doc(`
+				{
+				  "kind": "SelfSubjectReview",
+				  "apiVersion": "authentication.k8s.io/v1beta1",
+				  "metadata": {
+				    "creationTimestamp": null
+				  },
+				  "status": {
+				    "userInfo": {
+				      "username": "some-username",
+				      "uid": "some-uid",
+				      "groups": [
+				        "some-group-0",
+				        "some-group-1"
+				      ],
+				      "extra": {
+				        "some-extra-key-0": [
+				          "some-extra-value-0"
+				        ],
+				        "some-extra-key-1": [
+				          "some-extra-value-1",
+				          "some-extra-value-2"
+				        ]
+				      }
+				    }
+				  }
+				}`),
+		},
+		{
+			name:               "yaml output from SelfSubjectReview when WhoAmI API is not installed",
+			args:               []string{"--kubeconfig", "testdata/kubeconfig.yaml", "-o", "yaml"},
+			whoAmINotInstalled: true,
+			wantStdout: here.Doc(`
+				apiVersion: authentication.k8s.io/v1beta1
+				kind: SelfSubjectReview
+				metadata:
+				  creationTimestamp: null
+				status:
+				  userInfo:
+				    extra:
+				      some-extra-key-0:
+				      - some-extra-value-0
+				      some-extra-key-1:
+				      - some-extra-value-1
+				      - some-extra-value-2
+				    groups:
+				    - some-group-0
+				    - some-group-1
+				    uid: some-uid
+				    username: some-username
+			`),
+		},
 		{
 			name:       "extra args",
 			args:       []string{"extra-arg"},
@@ -221,6 +413,8 @@ func TestWhoami(t *testing.T) {
 
 				Username: some-username
 				Groups: some-group-0, some-group-1
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
 			`),
 		},
 		{
@@ -239,6 +433,8 @@ func TestWhoami(t *testing.T) {
 
 				Username: some-username
 				Groups: some-group-0, some-group-1
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
 			`),
 		},
 		{
@@ -257,8 +453,29 @@ func TestWhoami(t *testing.T) {
 
 				Username: some-username
 				Groups: some-group-0, some-group-1
+
+				Source: Pinniped WhoAmI API (identity.concierge.pinniped.dev/v1alpha1)
 			`),
 		},
+		{
+			name:       "invalid output format",
+			args:       []string{"--kubeconfig", "testdata/kubeconfig.yaml", "--output", "invalid-format"},
+			wantError:  true,
+			wantStderr: "Error: unknown output format: \"invalid-format\"\n",
+		},
+		{
+			name:       "cannot get cluster info with json output",
+			args:       []string{"--kubeconfig", "this-file-does-not-exist", "--output", "json"},
+			wantError:  true,
+			wantStderr: "{\n  \"error\": \"could not get current cluster info: stat this-file-does-not-exist: no such file or directory\",\n  \"code\": \"ERR_KUBECONFIG_INVALID\"\n}\n",
+		},
+		{
+			name:          "calling API fails with yaml output",
+			args:          []string{"--output", "yaml"},
+			callingAPIErr: constable.Error("some API error"),
+			wantError:     true,
+			wantStderr:    "code: ERR_CLUSTER_UNAVAILABLE\nerror: 'could not complete WhoAmIRequest: some API error'\n",
+		},
 		{
 			name:                "getting clientset fails",
 			gettingClientsetErr: constable.Error("some get clientset error"),
@@ -272,10 +489,18 @@ func TestWhoami(t *testing.T) {
 			wantStderr:    "Error: could not complete WhoAmIRequest: some API error\n",
 		},
 		{
-			name:          "calling API fails because WhoAmI API is not installed",
-			callingAPIErr: errors.NewNotFound(identityv1alpha1.SchemeGroupVersion.WithResource("whoamirequests").GroupResource(), "whatever"),
-			wantError:     true,
-			wantStderr:    "Error: could not complete WhoAmIRequest (is the Pinniped WhoAmI API running and healthy?): whoamirequests.identity.concierge.pinniped.dev \"whatever\" not found\n",
+			name:                 "calling API fails because neither the WhoAmI API nor the SelfSubjectReview API is installed",
+			whoAmINotInstalled:   true,
+			selfSubjectReviewErr: errors.NewNotFound(authenticationv1alpha1.SchemeGroupVersion.WithResource("selfsubjectreviews").GroupResource(), ""),
+			wantError:            true,
+			wantStderr:           "Error: could not complete WhoAmIRequest (is the Pinniped WhoAmI API running and healthy?): whoamirequests.identity.concierge.pinniped.dev \"whatever\" not found\n",
+		},
+		{
+			name:                 "calling SelfSubjectReview API fails when WhoAmI API is not installed",
+			whoAmINotInstalled:   true,
+			selfSubjectReviewErr: constable.Error("some review error"),
+			wantError:            true,
+			wantStderr:           "Error: could not complete SelfSubjectReview: some review error\n",
 		},
 	}
 	for _, test := range tests {
@@ -286,28 +511,72 @@ func TestWhoami(t *testing.T) {
 					return nil, test.gettingClientsetErr
 				}
 				clientset := fakeconciergeclientset.NewSimpleClientset()
-				clientset.PrependReactor("create", "whoamirequests", func(_ kubetesting.Action) (bool, runtime.Object, error) {
+				clientset.PrependReactor("create", "whoamirequests", func(action kubetesting.Action) (bool, runtime.Object, error) {
 					if test.callingAPIErr != nil {
 						return true, nil, test.callingAPIErr
 					}
+					if test.whoAmINotInstalled {
+						return true, nil, errors.NewNotFound(identityv1alpha1.SchemeGroupVersion.WithResource("whoamirequests").GroupResource(), "whatever")
+					}
 					groups := []string{"some-group-0", "some-group-1"}
 					if test.groupsOverride != nil {
 						groups = test.groupsOverride
 					}
-					return true, &identityv1alpha1.WhoAmIRequest{
+					user := identityv1alpha1.UserInfo{
+						Username: "some-username",
+						Groups:   groups,
+					}
+					if test.withUIDAndExtra {
+						user.UID = "some-uid"
+						user.Extra = map[string]identityv1alpha1.ExtraValue{
+							"some-extra-key-0": {"some-extra-value-0"},
+							"some-extra-key-1": {"some-extra-value-1", "some-extra-value-2"},
+						}
+					}
+					request := action.(kubetesting.CreateAction).GetObject().(*identityv1alpha1.WhoAmIRequest)
+					require.Equal(t, slices.Contains(test.args, "--show-impersonation"), request.Spec.IncludeImpersonation)
+					response := &identityv1alpha1.WhoAmIRequest{
 						Status: identityv1alpha1.WhoAmIRequestStatus{
 							KubernetesUserInfo: identityv1alpha1.KubernetesUserInfo{
-								User: identityv1alpha1.UserInfo{
-									Username: "some-username",
-									Groups:   groups,
-								},
+								User: user,
 							},
 						},
-					}, nil
+					}
+					if test.withOriginalUser {
+						response.Status.OriginalUser = &identityv1alpha1.UserInfo{
+							Username: "some-original-username",
+							UID:      "some-original-uid",
+							Groups:   []string{"some-original-group"},
+							Extra: map[string]identityv1alpha1.ExtraValue{
+								"some-original-extra-key": {"some-original-extra-value"},
+							},
+						}
+					}
+					return true, response, nil
 				})
 				return clientset, nil
 			}
-			cmd := newWhoamiCommand(getClientset)
+			getSelfSubjectReview := func(_ context.Context, _ clientcmd.ClientConfig) (*authenticationv1alpha1.SelfSubjectReview, error) {
+				require.True(t, test.whoAmINotInstalled, "SelfSubjectReview should only be used when the WhoAmI API is not installed")
+				if test.selfSubjectReviewErr != nil {
+					return nil, test.selfSubjectReviewErr
+				}
+				return &authenticationv1alpha1.SelfSubjectReview{
+					TypeMeta: metav1.TypeMeta{APIVersion: "authentication.k8s.io/v1beta1", Kind: "SelfSubjectReview"},
+					Status: authenticationv1alpha1.SelfSubjectReviewStatus{
+						UserInfo: authenticationv1.UserInfo{
+							Username: "some-username",
+							UID:      "some-uid",
+							Groups:   []string{"some-group-0", "some-group-1"},
+							Extra: map[string]authenticationv1.ExtraValue{
+								"some-extra-key-0": {"some-extra-value-0"},
+								"some-extra-key-1": {"some-extra-value-1", "some-extra-value-2"},
+							},
+						},
+					},
+				}, nil
+			}
+			cmd := newWhoamiCommand(getClientset, getSelfSubjectReview)
 
 			stdout, stderr := bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{})
 			cmd.SetOut(stdout)
@@ -1,8 +0,0 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
-// SPDX-License-Identifier: Apache-2.0
-
-// +k8s:deepcopy-gen=package
-// +groupName=identity.concierge.pinniped.dev
-
-// Package identity is the internal version of the Pinniped identity API.
-package identity
@@ -1,8 +0,0 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
-// SPDX-License-Identifier: Apache-2.0
-
-// +k8s:deepcopy-gen=package
-// +groupName=login.concierge.pinniped.dev
-
-// Package login is the internal version of the Pinniped login API.
-package login
@@ -1,9 +0,0 @@
-// This go.mod file is generated by ./hack/codegen.sh.
-module go.pinniped.dev/generated/1.17/apis
-
-go 1.13
-
-require (
-	k8s.io/api v0.17.17
-	k8s.io/apimachinery v0.17.17
-)
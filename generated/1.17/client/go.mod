@@ -1,14 +0,0 @@
-// This go.mod file is generated by ./hack/codegen.sh.
-module go.pinniped.dev/generated/1.17/client
-
-go 1.13
-
-require (
-	github.com/go-openapi/spec v0.0.0-20160808142527-6aced65f8501
-	go.pinniped.dev/generated/1.17/apis v0.0.0
-	k8s.io/apimachinery v0.17.17
-	k8s.io/client-go v0.17.17
-	k8s.io/kube-openapi v0.0.0-20200410145947-bcb3869e6f29
-)
-
-replace go.pinniped.dev/generated/1.17/apis => ../apis
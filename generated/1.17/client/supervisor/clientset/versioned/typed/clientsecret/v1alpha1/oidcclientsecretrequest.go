@@ -1,49 +0,0 @@
-// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
-// SPDX-License-Identifier: Apache-2.0
-
-// Code generated by client-gen. DO NOT EDIT.
-
-package v1alpha1
-
-import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/clientsecret/v1alpha1"
-	rest "k8s.io/client-go/rest"
-)
-
-// OIDCClientSecretRequestsGetter has a method to return a OIDCClientSecretRequestInterface.
-// A group's client should implement this interface.
-type OIDCClientSecretRequestsGetter interface {
-	OIDCClientSecretRequests(namespace string) OIDCClientSecretRequestInterface
-}
-
-// OIDCClientSecretRequestInterface has methods to work with OIDCClientSecretRequest resources.
-type OIDCClientSecretRequestInterface interface {
-	Create(*v1alpha1.OIDCClientSecretRequest) (*v1alpha1.OIDCClientSecretRequest, error)
-	OIDCClientSecretRequestExpansion
-}
-
-// oIDCClientSecretRequests implements OIDCClientSecretRequestInterface
-type oIDCClientSecretRequests struct {
-	client rest.Interface
-	ns     string
-}
-
-// newOIDCClientSecretRequests returns a OIDCClientSecretRequests
-func newOIDCClientSecretRequests(c *ClientsecretV1alpha1Client, namespace string) *oIDCClientSecretRequests {
-	return &oIDCClientSecretRequests{
-		client: c.RESTClient(),
-		ns:     namespace,
-	}
-}
-
-// Create takes the representation of a oIDCClientSecretRequest and creates it.  Returns the server's representation of the oIDCClientSecretRequest, and an error, if there is any.
-func (c *oIDCClientSecretRequests) Create(oIDCClientSecretRequest *v1alpha1.OIDCClientSecretRequest) (result *v1alpha1.OIDCClientSecretRequest, err error) {
-	result = &v1alpha1.OIDCClientSecretRequest{}
-	err = c.client.Post().
-		Namespace(c.ns).
-		Resource("oidcclientsecretrequests").
-		Body(oIDCClientSecretRequest).
-		Do().
-		Into(result)
-	return
-}
@@ -1,9 +0,0 @@
-// This go.mod file is generated by ./hack/codegen.sh.
-module go.pinniped.dev/generated/1.18/apis
-
-go 1.13
-
-require (
-	k8s.io/api v0.18.18
-	k8s.io/apimachinery v0.18.18
-)
@@ -1,8 +0,0 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
-// SPDX-License-Identifier: Apache-2.0
-
-// +k8s:deepcopy-gen=package
-// +groupName=clientsecret.supervisor.pinniped.dev
-
-// Package clientsecret is the internal version of the Pinniped client secret API.
-package clientsecret
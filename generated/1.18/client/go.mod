@@ -1,14 +0,0 @@
-// This go.mod file is generated by ./hack/codegen.sh.
-module go.pinniped.dev/generated/1.18/client
-
-go 1.13
-
-require (
-	github.com/go-openapi/spec v0.0.0-20160808142527-6aced65f8501
-	go.pinniped.dev/generated/1.18/apis v0.0.0
-	k8s.io/apimachinery v0.18.18
-	k8s.io/client-go v0.18.18
-	k8s.io/kube-openapi v0.0.0-20200410145947-61e04a5be9a6
-)
-
-replace go.pinniped.dev/generated/1.18/apis => ../apis
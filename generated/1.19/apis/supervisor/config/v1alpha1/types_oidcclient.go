@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -21,7 +21,7 @@ const (
 // +kubebuilder:validation:Pattern=`^https://.+|^http://(127\.0\.0\.1|\[::1\])(:\d+)?/`
 type RedirectURI string
 
-// +kubebuilder:validation:Enum="authorization_code";"refresh_token";"urn:ietf:params:oauth:grant-type:token-exchange"
+// +kubebuilder:validation:Enum="authorization_code";"refresh_token";"urn:ietf:params:oauth:grant-type:token-exchange";"client_credentials"
 type GrantType string
 
 // +kubebuilder:validation:Enum="openid";"offline_access";"username";"groups";"pinniped:request-audience"
@@ -48,6 +48,8 @@ type OIDCClientSpec struct {
 	// - urn:ietf:params:oauth:grant-type:token-exchange: allows the client to perform RFC8693 token exchange,
 	//   which is a step in the process to be able to get a cluster credential for the user.
 	//   This grant must be listed if allowedScopes lists pinniped:request-audience.
+	// - client_credentials: allows the client to get tokens for itself, without any user, e.g. for service-to-service
+	//   access to a gateway which trusts the Supervisor. This grant requires clientCredentials to be set.
 	// +listType=set
 	// +kubebuilder:validation:MinItems=1
 	AllowedGrantTypes []GrantType `json:"allowedGrantTypes"`
@@ -71,6 +73,134 @@ type OIDCClientSpec struct {
 	// +listType=set
 	// +kubebuilder:validation:MinItems=1
 	AllowedScopes []Scope `json:"allowedScopes"`
+
+	// requiredGroups optionally restricts which users may log in with this client. When not empty, only users who are
+	// members of at least one of these groups can complete an authorization flow for this client, and all other users
+	// are denied with an access_denied error. The groups of the user are checked even when the client does not request
+	// the groups scope.
+	// +optional
+	// +listType=set
+	RequiredGroups []string `json:"requiredGroups,omitempty"`
+
+	// groupsClaim optionally filters and limits the group names in the groups claim of the ID tokens which are issued
+	// to this client, e.g. for applications which cannot handle users who are members of thousands of groups.
+	// It does not change the groups in the cluster-scoped ID tokens which this client may request with RFC8693 token
+	// exchanges, nor the groups which are checked by requiredGroups.
+	// +optional
+	GroupsClaim *OIDCClientGroupsClaim `json:"groupsClaim,omitempty"`
+
+	// clientCredentials configures the tokens which this client can get for itself, without any user, by using the
+	// client_credentials grant. It must be set when allowedGrantTypes lists client_credentials.
+	// +optional
+	ClientCredentials *OIDCClientCredentials `json:"clientCredentials,omitempty"`
+
+	// clientSecretPolicy optionally restricts the age and the number of the client secrets of this client.
+	// +optional
+	ClientSecretPolicy *OIDCClientSecretPolicy `json:"clientSecretPolicy,omitempty"`
+
+	// tokenProfile optionally shapes the JWTs which are issued for this client, i.e. its ID tokens, the tokens of its
+	// RFC8693 token exchanges and the tokens of its client_credentials grant, so that the OIDC federation of a cloud
+	// provider accepts them, e.g. to assume AWS IAM roles, to impersonate GCP service accounts with workload identity
+	// federation, or to get Azure tokens with federated identity credentials.
+	// +optional
+	TokenProfile *OIDCClientTokenProfile `json:"tokenProfile,omitempty"`
+}
+
+// OIDCClientCredentials configures the client_credentials grant of an OIDCClient.
+type OIDCClientCredentials struct {
+	// allowedAudiences is the list of the audiences which this client may request for the tokens which it gets with
+	// the client_credentials grant, e.g. the audience which is expected by a gateway. Each token has exactly one audience.
+	// When the client does not request an audience and this list has only one audience, that audience is used.
+	// The audiences may not contain ".pinniped.dev" and may not be "pinniped-cli".
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	AllowedAudiences []string `json:"allowedAudiences"`
+
+	// allowedScopes is an optional list of the scopes which this client may request for the tokens which it gets with
+	// the client_credentials grant, e.g. "gateway:read". The granted scopes are listed in the "scope" claim of the tokens.
+	// +optional
+	// +listType=set
+	AllowedScopes []string `json:"allowedScopes,omitempty"`
+}
+
+// OIDCClientGroupsClaim filters and limits the groups claim of the ID tokens which are issued to an OIDCClient.
+type OIDCClientGroupsClaim struct {
+	// allowedPatterns is an optional list of regular expressions in the RE2 syntax which is used by Go, e.g. "eng-.*".
+	// When not empty, only the group names which match at least one of these patterns are included in the groups claim.
+	// Each pattern must match the whole group name.
+	// +optional
+	// +listType=set
+	AllowedPatterns []string `json:"allowedPatterns,omitempty"`
+
+	// maxGroups is the optional maximum number of group names in the groups claim. When the user is a member of more
+	// groups than this after they were filtered by allowedPatterns, only the first maxGroups group names in alphabetical
+	// order are included, and the ID token also contains the claim "groupsOverflow" with the value true.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxGroups int32 `json:"maxGroups,omitempty"`
+}
+
+// OIDCClientSecretPolicy restricts the client secrets of an OIDCClient.
+type OIDCClientSecretPolicy struct {
+	// maxAge is the maximum age of each client secret of this client, e.g. "2160h" for 90 days.
+	// Client secrets which are older than this have expired and can no longer be used to authenticate the client.
+	// Client secrets which were generated before the Supervisor started to keep track of their creation are not
+	// affected.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// maxSecrets is the maximum number of client secrets which this client may have at the same time.
+	// Requests to generate or keep more client secrets than this are rejected. When not set, the limit is 5.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=5
+	MaxSecrets int32 `json:"maxSecrets,omitempty"`
+}
+
+// OIDCClientCloudProvider is a cloud provider whose OIDC federation accepts the tokens of an OIDCClient.
+//
+// +kubebuilder:validation:Enum=AWS;GCP;Azure
+type OIDCClientCloudProvider string
+
+const (
+	// OIDCClientCloudProviderAWS shapes the tokens for AWS IAM OIDC identity providers.
+	OIDCClientCloudProviderAWS = OIDCClientCloudProvider("AWS")
+
+	// OIDCClientCloudProviderGCP shapes the tokens for GCP workload identity federation.
+	OIDCClientCloudProviderGCP = OIDCClientCloudProvider("GCP")
+
+	// OIDCClientCloudProviderAzure shapes the tokens for Azure federated identity credentials.
+	OIDCClientCloudProviderAzure = OIDCClientCloudProvider("Azure")
+)
+
+// OIDCClientSubjectFormat is the format of the sub claim of the tokens of an OIDCClient.
+//
+// +kubebuilder:validation:Enum=Username;Default
+type OIDCClientSubjectFormat string
+
+const (
+	// OIDCClientSubjectFormatUsername uses the username of the user as the sub claim.
+	OIDCClientSubjectFormatUsername = OIDCClientSubjectFormat("Username")
+
+	// OIDCClientSubjectFormatDefault keeps the default sub claim of the Supervisor.
+	OIDCClientSubjectFormatDefault = OIDCClientSubjectFormat("Default")
+)
+
+// OIDCClientTokenProfile shapes the JWTs which are issued for an OIDCClient, so that the OIDC federation of a cloud
+// provider accepts them.
+type OIDCClientTokenProfile struct {
+	// cloudProvider is the cloud provider which accepts the tokens, i.e. AWS, GCP or Azure. The aud claim of the tokens
+	// is a single string instead of a list of strings, as expected by all of them. Tokens for GCP are not issued when
+	// their sub claim is longer than the 127 bytes which are allowed by GCP.
+	CloudProvider OIDCClientCloudProvider `json:"cloudProvider"`
+
+	// subjectFormat is the format of the sub claim of the tokens. "Username", the default, uses the username of the
+	// user, which can be matched by the trust policies of cloud roles. "Default" keeps the default sub claim of the
+	// Supervisor, which identifies the user by their upstream identity provider and their subject there. The sub claim
+	// is unchanged when the token does not have a username claim, e.g. for the client_credentials grant, whose sub
+	// claim is the client ID.
+	// +optional
+	SubjectFormat OIDCClientSubjectFormat `json:"subjectFormat,omitempty"`
 }
 
 // OIDCClientStatus is a struct that describes the actual state of an OIDCClient.
@@ -85,7 +215,7 @@ type OIDCClientStatus struct {
 	// +patchStrategy=merge
 	// +listType=map
 	// +listMapKey=type
-	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 
 	// totalClientSecrets is the current number of client secrets that are detected for this OIDCClient.
 	// +optional
@@ -101,6 +231,7 @@ type OIDCClientStatus struct {
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 type OIDCClient struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
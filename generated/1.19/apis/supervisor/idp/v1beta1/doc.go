@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // +k8s:openapi-gen=true
@@ -7,5 +7,5 @@
 // +groupName=idp.supervisor.pinniped.dev
 // +groupGoName=IDP
 
-// Package v1alpha1 is the v1alpha1 version of the Pinniped supervisor identity provider (IDP) API.
-package v1alpha1
+// Package v1beta1 is the v1beta1 version of the Pinniped supervisor identity provider (IDP) API.
+package v1beta1
@@ -0,0 +1,47 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecondFactor describes the second factors which a user has enrolled to log in through the Supervisor, i.e. their
+// WebAuthn credentials and their TOTP secret. Deleting a SecondFactor resets all second factors of the user, so they
+// must enroll new ones during their next login.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SecondFactor struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta // metadata.name is derived from the downstream subject of the user
+
+	// +optional
+	Status SecondFactorStatus
+}
+
+// Status of the SecondFactor.
+type SecondFactorStatus struct {
+	// Subject is the downstream subject of the user.
+	Subject string
+
+	// Username is the downstream username of the user when they most recently enrolled a second factor.
+	Username string
+
+	// WebAuthnCredentials is the number of WebAuthn credentials which the user has registered.
+	// +optional
+	WebAuthnCredentials int32
+
+	// TOTPEnrolled is true when the user has enrolled a TOTP secret.
+	// +optional
+	TOTPEnrolled bool
+}
+
+// SecondFactorList is a list of SecondFactor objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SecondFactorList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	// Items is a list of SecondFactor.
+	Items []SecondFactor
+}
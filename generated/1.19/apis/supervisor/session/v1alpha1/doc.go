@@ -0,0 +1,11 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen=package
+// +k8s:conversion-gen=go.pinniped.dev/generated/1.19/apis/supervisor/session
+// +k8s:defaulter-gen=TypeMeta
+// +groupName=session.supervisor.pinniped.dev
+
+// Package v1alpha1 is the v1alpha1 version of the Pinniped session API.
+package v1alpha1
@@ -0,0 +1,239 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by conversion-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	unsafe "unsafe"
+
+	session "go.pinniped.dev/generated/1.19/apis/supervisor/session"
+	conversion "k8s.io/apimachinery/pkg/conversion"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	localSchemeBuilder.Register(RegisterConversions)
+}
+
+// RegisterConversions adds conversion functions to the given scheme.
+// Public to allow building arbitrary schemes.
+func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*DownstreamSession)(nil), (*session.DownstreamSession)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DownstreamSession_To_session_DownstreamSession(a.(*DownstreamSession), b.(*session.DownstreamSession), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*session.DownstreamSession)(nil), (*DownstreamSession)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_session_DownstreamSession_To_v1alpha1_DownstreamSession(a.(*session.DownstreamSession), b.(*DownstreamSession), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*DownstreamSessionList)(nil), (*session.DownstreamSessionList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DownstreamSessionList_To_session_DownstreamSessionList(a.(*DownstreamSessionList), b.(*session.DownstreamSessionList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*session.DownstreamSessionList)(nil), (*DownstreamSessionList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_session_DownstreamSessionList_To_v1alpha1_DownstreamSessionList(a.(*session.DownstreamSessionList), b.(*DownstreamSessionList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*DownstreamSessionStatus)(nil), (*session.DownstreamSessionStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DownstreamSessionStatus_To_session_DownstreamSessionStatus(a.(*DownstreamSessionStatus), b.(*session.DownstreamSessionStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*session.DownstreamSessionStatus)(nil), (*DownstreamSessionStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_session_DownstreamSessionStatus_To_v1alpha1_DownstreamSessionStatus(a.(*session.DownstreamSessionStatus), b.(*DownstreamSessionStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SecondFactor)(nil), (*session.SecondFactor)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_SecondFactor_To_session_SecondFactor(a.(*SecondFactor), b.(*session.SecondFactor), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*session.SecondFactor)(nil), (*SecondFactor)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_session_SecondFactor_To_v1alpha1_SecondFactor(a.(*session.SecondFactor), b.(*SecondFactor), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SecondFactorList)(nil), (*session.SecondFactorList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_SecondFactorList_To_session_SecondFactorList(a.(*SecondFactorList), b.(*session.SecondFactorList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*session.SecondFactorList)(nil), (*SecondFactorList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_session_SecondFactorList_To_v1alpha1_SecondFactorList(a.(*session.SecondFactorList), b.(*SecondFactorList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SecondFactorStatus)(nil), (*session.SecondFactorStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_SecondFactorStatus_To_session_SecondFactorStatus(a.(*SecondFactorStatus), b.(*session.SecondFactorStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*session.SecondFactorStatus)(nil), (*SecondFactorStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_session_SecondFactorStatus_To_v1alpha1_SecondFactorStatus(a.(*session.SecondFactorStatus), b.(*SecondFactorStatus), scope)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func autoConvert_v1alpha1_DownstreamSession_To_session_DownstreamSession(in *DownstreamSession, out *session.DownstreamSession, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_DownstreamSessionStatus_To_session_DownstreamSessionStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_DownstreamSession_To_session_DownstreamSession is an autogenerated conversion function.
+func Convert_v1alpha1_DownstreamSession_To_session_DownstreamSession(in *DownstreamSession, out *session.DownstreamSession, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DownstreamSession_To_session_DownstreamSession(in, out, s)
+}
+
+func autoConvert_session_DownstreamSession_To_v1alpha1_DownstreamSession(in *session.DownstreamSession, out *DownstreamSession, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_session_DownstreamSessionStatus_To_v1alpha1_DownstreamSessionStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_session_DownstreamSession_To_v1alpha1_DownstreamSession is an autogenerated conversion function.
+func Convert_session_DownstreamSession_To_v1alpha1_DownstreamSession(in *session.DownstreamSession, out *DownstreamSession, s conversion.Scope) error {
+	return autoConvert_session_DownstreamSession_To_v1alpha1_DownstreamSession(in, out, s)
+}
+
+func autoConvert_v1alpha1_DownstreamSessionList_To_session_DownstreamSessionList(in *DownstreamSessionList, out *session.DownstreamSessionList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]session.DownstreamSession)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1alpha1_DownstreamSessionList_To_session_DownstreamSessionList is an autogenerated conversion function.
+func Convert_v1alpha1_DownstreamSessionList_To_session_DownstreamSessionList(in *DownstreamSessionList, out *session.DownstreamSessionList, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DownstreamSessionList_To_session_DownstreamSessionList(in, out, s)
+}
+
+func autoConvert_session_DownstreamSessionList_To_v1alpha1_DownstreamSessionList(in *session.DownstreamSessionList, out *DownstreamSessionList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]DownstreamSession)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_session_DownstreamSessionList_To_v1alpha1_DownstreamSessionList is an autogenerated conversion function.
+func Convert_session_DownstreamSessionList_To_v1alpha1_DownstreamSessionList(in *session.DownstreamSessionList, out *DownstreamSessionList, s conversion.Scope) error {
+	return autoConvert_session_DownstreamSessionList_To_v1alpha1_DownstreamSessionList(in, out, s)
+}
+
+func autoConvert_v1alpha1_DownstreamSessionStatus_To_session_DownstreamSessionStatus(in *DownstreamSessionStatus, out *session.DownstreamSessionStatus, s conversion.Scope) error {
+	out.Username = in.Username
+	out.IdentityProviderName = in.IdentityProviderName
+	out.IdentityProviderType = in.IdentityProviderType
+	out.ClientID = in.ClientID
+	out.AuthenticationTime = in.AuthenticationTime
+	out.LastRefreshTime = in.LastRefreshTime
+	return nil
+}
+
+// Convert_v1alpha1_DownstreamSessionStatus_To_session_DownstreamSessionStatus is an autogenerated conversion function.
+func Convert_v1alpha1_DownstreamSessionStatus_To_session_DownstreamSessionStatus(in *DownstreamSessionStatus, out *session.DownstreamSessionStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DownstreamSessionStatus_To_session_DownstreamSessionStatus(in, out, s)
+}
+
+func autoConvert_session_DownstreamSessionStatus_To_v1alpha1_DownstreamSessionStatus(in *session.DownstreamSessionStatus, out *DownstreamSessionStatus, s conversion.Scope) error {
+	out.Username = in.Username
+	out.IdentityProviderName = in.IdentityProviderName
+	out.IdentityProviderType = in.IdentityProviderType
+	out.ClientID = in.ClientID
+	out.AuthenticationTime = in.AuthenticationTime
+	out.LastRefreshTime = in.LastRefreshTime
+	return nil
+}
+
+// Convert_session_DownstreamSessionStatus_To_v1alpha1_DownstreamSessionStatus is an autogenerated conversion function.
+func Convert_session_DownstreamSessionStatus_To_v1alpha1_DownstreamSessionStatus(in *session.DownstreamSessionStatus, out *DownstreamSessionStatus, s conversion.Scope) error {
+	return autoConvert_session_DownstreamSessionStatus_To_v1alpha1_DownstreamSessionStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_SecondFactor_To_session_SecondFactor(in *SecondFactor, out *session.SecondFactor, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_SecondFactorStatus_To_session_SecondFactorStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_SecondFactor_To_session_SecondFactor is an autogenerated conversion function.
+func Convert_v1alpha1_SecondFactor_To_session_SecondFactor(in *SecondFactor, out *session.SecondFactor, s conversion.Scope) error {
+	return autoConvert_v1alpha1_SecondFactor_To_session_SecondFactor(in, out, s)
+}
+
+func autoConvert_session_SecondFactor_To_v1alpha1_SecondFactor(in *session.SecondFactor, out *SecondFactor, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_session_SecondFactorStatus_To_v1alpha1_SecondFactorStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_session_SecondFactor_To_v1alpha1_SecondFactor is an autogenerated conversion function.
+func Convert_session_SecondFactor_To_v1alpha1_SecondFactor(in *session.SecondFactor, out *SecondFactor, s conversion.Scope) error {
+	return autoConvert_session_SecondFactor_To_v1alpha1_SecondFactor(in, out, s)
+}
+
+func autoConvert_v1alpha1_SecondFactorList_To_session_SecondFactorList(in *SecondFactorList, out *session.SecondFactorList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]session.SecondFactor)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1alpha1_SecondFactorList_To_session_SecondFactorList is an autogenerated conversion function.
+func Convert_v1alpha1_SecondFactorList_To_session_SecondFactorList(in *SecondFactorList, out *session.SecondFactorList, s conversion.Scope) error {
+	return autoConvert_v1alpha1_SecondFactorList_To_session_SecondFactorList(in, out, s)
+}
+
+func autoConvert_session_SecondFactorList_To_v1alpha1_SecondFactorList(in *session.SecondFactorList, out *SecondFactorList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]SecondFactor)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_session_SecondFactorList_To_v1alpha1_SecondFactorList is an autogenerated conversion function.
+func Convert_session_SecondFactorList_To_v1alpha1_SecondFactorList(in *session.SecondFactorList, out *SecondFactorList, s conversion.Scope) error {
+	return autoConvert_session_SecondFactorList_To_v1alpha1_SecondFactorList(in, out, s)
+}
+
+func autoConvert_v1alpha1_SecondFactorStatus_To_session_SecondFactorStatus(in *SecondFactorStatus, out *session.SecondFactorStatus, s conversion.Scope) error {
+	out.Subject = in.Subject
+	out.Username = in.Username
+	out.WebAuthnCredentials = in.WebAuthnCredentials
+	out.TOTPEnrolled = in.TOTPEnrolled
+	return nil
+}
+
+// Convert_v1alpha1_SecondFactorStatus_To_session_SecondFactorStatus is an autogenerated conversion function.
+func Convert_v1alpha1_SecondFactorStatus_To_session_SecondFactorStatus(in *SecondFactorStatus, out *session.SecondFactorStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_SecondFactorStatus_To_session_SecondFactorStatus(in, out, s)
+}
+
+func autoConvert_session_SecondFactorStatus_To_v1alpha1_SecondFactorStatus(in *session.SecondFactorStatus, out *SecondFactorStatus, s conversion.Scope) error {
+	out.Subject = in.Subject
+	out.Username = in.Username
+	out.WebAuthnCredentials = in.WebAuthnCredentials
+	out.TOTPEnrolled = in.TOTPEnrolled
+	return nil
+}
+
+// Convert_session_SecondFactorStatus_To_v1alpha1_SecondFactorStatus is an autogenerated conversion function.
+func Convert_session_SecondFactorStatus_To_v1alpha1_SecondFactorStatus(in *session.SecondFactorStatus, out *SecondFactorStatus, s conversion.Scope) error {
+	return autoConvert_session_SecondFactorStatus_To_v1alpha1_SecondFactorStatus(in, out, s)
+}
@@ -8,7 +8,7 @@ package fake
 import (
 	"context"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/concierge/authentication/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/concierge/authentication/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,27 +19,27 @@ import (
 
 // FakeJWTAuthenticators implements JWTAuthenticatorInterface
 type FakeJWTAuthenticators struct {
-	Fake *FakeAuthenticationV1alpha1
+	Fake *FakeAuthenticationV1beta1
 }
 
-var jwtauthenticatorsResource = schema.GroupVersionResource{Group: "authentication.concierge.pinniped.dev", Version: "v1alpha1", Resource: "jwtauthenticators"}
+var jwtauthenticatorsResource = schema.GroupVersionResource{Group: "authentication.concierge.pinniped.dev", Version: "v1beta1", Resource: "jwtauthenticators"}
 
-var jwtauthenticatorsKind = schema.GroupVersionKind{Group: "authentication.concierge.pinniped.dev", Version: "v1alpha1", Kind: "JWTAuthenticator"}
+var jwtauthenticatorsKind = schema.GroupVersionKind{Group: "authentication.concierge.pinniped.dev", Version: "v1beta1", Kind: "JWTAuthenticator"}
 
 // Get takes name of the jWTAuthenticator, and returns the corresponding jWTAuthenticator object, and an error if there is any.
-func (c *FakeJWTAuthenticators) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.JWTAuthenticator, err error) {
+func (c *FakeJWTAuthenticators) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.JWTAuthenticator, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootGetAction(jwtauthenticatorsResource, name), &v1alpha1.JWTAuthenticator{})
+		Invokes(testing.NewRootGetAction(jwtauthenticatorsResource, name), &v1beta1.JWTAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.JWTAuthenticator), err
+	return obj.(*v1beta1.JWTAuthenticator), err
 }
 
 // List takes label and field selectors, and returns the list of JWTAuthenticators that match those selectors.
-func (c *FakeJWTAuthenticators) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.JWTAuthenticatorList, err error) {
+func (c *FakeJWTAuthenticators) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.JWTAuthenticatorList, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootListAction(jwtauthenticatorsResource, jwtauthenticatorsKind, opts), &v1alpha1.JWTAuthenticatorList{})
+		Invokes(testing.NewRootListAction(jwtauthenticatorsResource, jwtauthenticatorsKind, opts), &v1beta1.JWTAuthenticatorList{})
 	if obj == nil {
 		return nil, err
 	}
@@ -48,8 +48,8 @@ func (c *FakeJWTAuthenticators) List(ctx context.Context, opts v1.ListOptions) (
 	if label == nil {
 		label = labels.Everything()
 	}
-	list := &v1alpha1.JWTAuthenticatorList{ListMeta: obj.(*v1alpha1.JWTAuthenticatorList).ListMeta}
-	for _, item := range obj.(*v1alpha1.JWTAuthenticatorList).Items {
+	list := &v1beta1.JWTAuthenticatorList{ListMeta: obj.(*v1beta1.JWTAuthenticatorList).ListMeta}
+	for _, item := range obj.(*v1beta1.JWTAuthenticatorList).Items {
 		if label.Matches(labels.Set(item.Labels)) {
 			list.Items = append(list.Items, item)
 		}
@@ -64,40 +64,40 @@ func (c *FakeJWTAuthenticators) Watch(ctx context.Context, opts v1.ListOptions)
 }
 
 // Create takes the representation of a jWTAuthenticator and creates it.  Returns the server's representation of the jWTAuthenticator, and an error, if there is any.
-func (c *FakeJWTAuthenticators) Create(ctx context.Context, jWTAuthenticator *v1alpha1.JWTAuthenticator, opts v1.CreateOptions) (result *v1alpha1.JWTAuthenticator, err error) {
+func (c *FakeJWTAuthenticators) Create(ctx context.Context, jWTAuthenticator *v1beta1.JWTAuthenticator, opts v1.CreateOptions) (result *v1beta1.JWTAuthenticator, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootCreateAction(jwtauthenticatorsResource, jWTAuthenticator), &v1alpha1.JWTAuthenticator{})
+		Invokes(testing.NewRootCreateAction(jwtauthenticatorsResource, jWTAuthenticator), &v1beta1.JWTAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.JWTAuthenticator), err
+	return obj.(*v1beta1.JWTAuthenticator), err
 }
 
 // Update takes the representation of a jWTAuthenticator and updates it. Returns the server's representation of the jWTAuthenticator, and an error, if there is any.
-func (c *FakeJWTAuthenticators) Update(ctx context.Context, jWTAuthenticator *v1alpha1.JWTAuthenticator, opts v1.UpdateOptions) (result *v1alpha1.JWTAuthenticator, err error) {
+func (c *FakeJWTAuthenticators) Update(ctx context.Context, jWTAuthenticator *v1beta1.JWTAuthenticator, opts v1.UpdateOptions) (result *v1beta1.JWTAuthenticator, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootUpdateAction(jwtauthenticatorsResource, jWTAuthenticator), &v1alpha1.JWTAuthenticator{})
+		Invokes(testing.NewRootUpdateAction(jwtauthenticatorsResource, jWTAuthenticator), &v1beta1.JWTAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.JWTAuthenticator), err
+	return obj.(*v1beta1.JWTAuthenticator), err
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakeJWTAuthenticators) UpdateStatus(ctx context.Context, jWTAuthenticator *v1alpha1.JWTAuthenticator, opts v1.UpdateOptions) (*v1alpha1.JWTAuthenticator, error) {
+func (c *FakeJWTAuthenticators) UpdateStatus(ctx context.Context, jWTAuthenticator *v1beta1.JWTAuthenticator, opts v1.UpdateOptions) (*v1beta1.JWTAuthenticator, error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootUpdateSubresourceAction(jwtauthenticatorsResource, "status", jWTAuthenticator), &v1alpha1.JWTAuthenticator{})
+		Invokes(testing.NewRootUpdateSubresourceAction(jwtauthenticatorsResource, "status", jWTAuthenticator), &v1beta1.JWTAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.JWTAuthenticator), err
+	return obj.(*v1beta1.JWTAuthenticator), err
 }
 
 // Delete takes name of the jWTAuthenticator and deletes it. Returns an error if one occurs.
 func (c *FakeJWTAuthenticators) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
-		Invokes(testing.NewRootDeleteAction(jwtauthenticatorsResource, name), &v1alpha1.JWTAuthenticator{})
+		Invokes(testing.NewRootDeleteAction(jwtauthenticatorsResource, name), &v1beta1.JWTAuthenticator{})
 	return err
 }
 
@@ -105,16 +105,16 @@ func (c *FakeJWTAuthenticators) Delete(ctx context.Context, name string, opts v1
 func (c *FakeJWTAuthenticators) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	action := testing.NewRootDeleteCollectionAction(jwtauthenticatorsResource, listOpts)
 
-	_, err := c.Fake.Invokes(action, &v1alpha1.JWTAuthenticatorList{})
+	_, err := c.Fake.Invokes(action, &v1beta1.JWTAuthenticatorList{})
 	return err
 }
 
 // Patch applies the patch and returns the patched jWTAuthenticator.
-func (c *FakeJWTAuthenticators) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.JWTAuthenticator, err error) {
+func (c *FakeJWTAuthenticators) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.JWTAuthenticator, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootPatchSubresourceAction(jwtauthenticatorsResource, name, pt, data, subresources...), &v1alpha1.JWTAuthenticator{})
+		Invokes(testing.NewRootPatchSubresourceAction(jwtauthenticatorsResource, name, pt, data, subresources...), &v1beta1.JWTAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.JWTAuthenticator), err
+	return obj.(*v1beta1.JWTAuthenticator), err
 }
@@ -8,7 +8,7 @@ package fake
 import (
 	"context"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/concierge/authentication/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/concierge/authentication/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,27 +19,27 @@ import (
 
 // FakeWebhookAuthenticators implements WebhookAuthenticatorInterface
 type FakeWebhookAuthenticators struct {
-	Fake *FakeAuthenticationV1alpha1
+	Fake *FakeAuthenticationV1beta1
 }
 
-var webhookauthenticatorsResource = schema.GroupVersionResource{Group: "authentication.concierge.pinniped.dev", Version: "v1alpha1", Resource: "webhookauthenticators"}
+var webhookauthenticatorsResource = schema.GroupVersionResource{Group: "authentication.concierge.pinniped.dev", Version: "v1beta1", Resource: "webhookauthenticators"}
 
-var webhookauthenticatorsKind = schema.GroupVersionKind{Group: "authentication.concierge.pinniped.dev", Version: "v1alpha1", Kind: "WebhookAuthenticator"}
+var webhookauthenticatorsKind = schema.GroupVersionKind{Group: "authentication.concierge.pinniped.dev", Version: "v1beta1", Kind: "WebhookAuthenticator"}
 
 // Get takes name of the webhookAuthenticator, and returns the corresponding webhookAuthenticator object, and an error if there is any.
-func (c *FakeWebhookAuthenticators) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
+func (c *FakeWebhookAuthenticators) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.WebhookAuthenticator, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootGetAction(webhookauthenticatorsResource, name), &v1alpha1.WebhookAuthenticator{})
+		Invokes(testing.NewRootGetAction(webhookauthenticatorsResource, name), &v1beta1.WebhookAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.WebhookAuthenticator), err
+	return obj.(*v1beta1.WebhookAuthenticator), err
 }
 
 // List takes label and field selectors, and returns the list of WebhookAuthenticators that match those selectors.
-func (c *FakeWebhookAuthenticators) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.WebhookAuthenticatorList, err error) {
+func (c *FakeWebhookAuthenticators) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.WebhookAuthenticatorList, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootListAction(webhookauthenticatorsResource, webhookauthenticatorsKind, opts), &v1alpha1.WebhookAuthenticatorList{})
+		Invokes(testing.NewRootListAction(webhookauthenticatorsResource, webhookauthenticatorsKind, opts), &v1beta1.WebhookAuthenticatorList{})
 	if obj == nil {
 		return nil, err
 	}
@@ -48,8 +48,8 @@ func (c *FakeWebhookAuthenticators) List(ctx context.Context, opts v1.ListOption
 	if label == nil {
 		label = labels.Everything()
 	}
-	list := &v1alpha1.WebhookAuthenticatorList{ListMeta: obj.(*v1alpha1.WebhookAuthenticatorList).ListMeta}
-	for _, item := range obj.(*v1alpha1.WebhookAuthenticatorList).Items {
+	list := &v1beta1.WebhookAuthenticatorList{ListMeta: obj.(*v1beta1.WebhookAuthenticatorList).ListMeta}
+	for _, item := range obj.(*v1beta1.WebhookAuthenticatorList).Items {
 		if label.Matches(labels.Set(item.Labels)) {
 			list.Items = append(list.Items, item)
 		}
@@ -64,40 +64,40 @@ func (c *FakeWebhookAuthenticators) Watch(ctx context.Context, opts v1.ListOptio
 }
 
 // Create takes the representation of a webhookAuthenticator and creates it.  Returns the server's representation of the webhookAuthenticator, and an error, if there is any.
-func (c *FakeWebhookAuthenticators) Create(ctx context.Context, webhookAuthenticator *v1alpha1.WebhookAuthenticator, opts v1.CreateOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
+func (c *FakeWebhookAuthenticators) Create(ctx context.Context, webhookAuthenticator *v1beta1.WebhookAuthenticator, opts v1.CreateOptions) (result *v1beta1.WebhookAuthenticator, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootCreateAction(webhookauthenticatorsResource, webhookAuthenticator), &v1alpha1.WebhookAuthenticator{})
+		Invokes(testing.NewRootCreateAction(webhookauthenticatorsResource, webhookAuthenticator), &v1beta1.WebhookAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.WebhookAuthenticator), err
+	return obj.(*v1beta1.WebhookAuthenticator), err
 }
 
 // Update takes the representation of a webhookAuthenticator and updates it. Returns the server's representation of the webhookAuthenticator, and an error, if there is any.
-func (c *FakeWebhookAuthenticators) Update(ctx context.Context, webhookAuthenticator *v1alpha1.WebhookAuthenticator, opts v1.UpdateOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
+func (c *FakeWebhookAuthenticators) Update(ctx context.Context, webhookAuthenticator *v1beta1.WebhookAuthenticator, opts v1.UpdateOptions) (result *v1beta1.WebhookAuthenticator, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootUpdateAction(webhookauthenticatorsResource, webhookAuthenticator), &v1alpha1.WebhookAuthenticator{})
+		Invokes(testing.NewRootUpdateAction(webhookauthenticatorsResource, webhookAuthenticator), &v1beta1.WebhookAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.WebhookAuthenticator), err
+	return obj.(*v1beta1.WebhookAuthenticator), err
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakeWebhookAuthenticators) UpdateStatus(ctx context.Context, webhookAuthenticator *v1alpha1.WebhookAuthenticator, opts v1.UpdateOptions) (*v1alpha1.WebhookAuthenticator, error) {
+func (c *FakeWebhookAuthenticators) UpdateStatus(ctx context.Context, webhookAuthenticator *v1beta1.WebhookAuthenticator, opts v1.UpdateOptions) (*v1beta1.WebhookAuthenticator, error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootUpdateSubresourceAction(webhookauthenticatorsResource, "status", webhookAuthenticator), &v1alpha1.WebhookAuthenticator{})
+		Invokes(testing.NewRootUpdateSubresourceAction(webhookauthenticatorsResource, "status", webhookAuthenticator), &v1beta1.WebhookAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.WebhookAuthenticator), err
+	return obj.(*v1beta1.WebhookAuthenticator), err
 }
 
 // Delete takes name of the webhookAuthenticator and deletes it. Returns an error if one occurs.
 func (c *FakeWebhookAuthenticators) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
-		Invokes(testing.NewRootDeleteAction(webhookauthenticatorsResource, name), &v1alpha1.WebhookAuthenticator{})
+		Invokes(testing.NewRootDeleteAction(webhookauthenticatorsResource, name), &v1beta1.WebhookAuthenticator{})
 	return err
 }
 
@@ -105,16 +105,16 @@ func (c *FakeWebhookAuthenticators) Delete(ctx context.Context, name string, opt
 func (c *FakeWebhookAuthenticators) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	action := testing.NewRootDeleteCollectionAction(webhookauthenticatorsResource, listOpts)
 
-	_, err := c.Fake.Invokes(action, &v1alpha1.WebhookAuthenticatorList{})
+	_, err := c.Fake.Invokes(action, &v1beta1.WebhookAuthenticatorList{})
 	return err
 }
 
 // Patch applies the patch and returns the patched webhookAuthenticator.
-func (c *FakeWebhookAuthenticators) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WebhookAuthenticator, err error) {
+func (c *FakeWebhookAuthenticators) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.WebhookAuthenticator, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootPatchSubresourceAction(webhookauthenticatorsResource, name, pt, data, subresources...), &v1alpha1.WebhookAuthenticator{})
+		Invokes(testing.NewRootPatchSubresourceAction(webhookauthenticatorsResource, name, pt, data, subresources...), &v1beta1.WebhookAuthenticator{})
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.WebhookAuthenticator), err
+	return obj.(*v1beta1.WebhookAuthenticator), err
 }
@@ -3,14 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/concierge/authentication/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.18/client/concierge/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/concierge/authentication/v1beta1"
+	scheme "go.pinniped.dev/generated/1.19/client/concierge/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -25,15 +25,15 @@ type JWTAuthenticatorsGetter interface {
 
 // JWTAuthenticatorInterface has methods to work with JWTAuthenticator resources.
 type JWTAuthenticatorInterface interface {
-	Create(ctx context.Context, jWTAuthenticator *v1alpha1.JWTAuthenticator, opts v1.CreateOptions) (*v1alpha1.JWTAuthenticator, error)
-	Update(ctx context.Context, jWTAuthenticator *v1alpha1.JWTAuthenticator, opts v1.UpdateOptions) (*v1alpha1.JWTAuthenticator, error)
-	UpdateStatus(ctx context.Context, jWTAuthenticator *v1alpha1.JWTAuthenticator, opts v1.UpdateOptions) (*v1alpha1.JWTAuthenticator, error)
+	Create(ctx context.Context, jWTAuthenticator *v1beta1.JWTAuthenticator, opts v1.CreateOptions) (*v1beta1.JWTAuthenticator, error)
+	Update(ctx context.Context, jWTAuthenticator *v1beta1.JWTAuthenticator, opts v1.UpdateOptions) (*v1beta1.JWTAuthenticator, error)
+	UpdateStatus(ctx context.Context, jWTAuthenticator *v1beta1.JWTAuthenticator, opts v1.UpdateOptions) (*v1beta1.JWTAuthenticator, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
-	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.JWTAuthenticator, error)
-	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.JWTAuthenticatorList, error)
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.JWTAuthenticator, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.JWTAuthenticatorList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
-	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.JWTAuthenticator, err error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.JWTAuthenticator, err error)
 	JWTAuthenticatorExpansion
 }
 
@@ -43,15 +43,15 @@ type jWTAuthenticators struct {
 }
 
 // newJWTAuthenticators returns a JWTAuthenticators
-func newJWTAuthenticators(c *AuthenticationV1alpha1Client) *jWTAuthenticators {
+func newJWTAuthenticators(c *AuthenticationV1beta1Client) *jWTAuthenticators {
 	return &jWTAuthenticators{
 		client: c.RESTClient(),
 	}
 }
 
 // Get takes name of the jWTAuthenticator, and returns the corresponding jWTAuthenticator object, and an error if there is any.
-func (c *jWTAuthenticators) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.JWTAuthenticator, err error) {
-	result = &v1alpha1.JWTAuthenticator{}
+func (c *jWTAuthenticators) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.JWTAuthenticator, err error) {
+	result = &v1beta1.JWTAuthenticator{}
 	err = c.client.Get().
 		Resource("jwtauthenticators").
 		Name(name).
@@ -62,12 +62,12 @@ func (c *jWTAuthenticators) Get(ctx context.Context, name string, options v1.Get
 }
 
 // List takes label and field selectors, and returns the list of JWTAuthenticators that match those selectors.
-func (c *jWTAuthenticators) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.JWTAuthenticatorList, err error) {
+func (c *jWTAuthenticators) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.JWTAuthenticatorList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.JWTAuthenticatorList{}
+	result = &v1beta1.JWTAuthenticatorList{}
 	err = c.client.Get().
 		Resource("jwtauthenticators").
 		VersionedParams(&opts, scheme.ParameterCodec).
@@ -92,8 +92,8 @@ func (c *jWTAuthenticators) Watch(ctx context.Context, opts v1.ListOptions) (wat
 }
 
 // Create takes the representation of a jWTAuthenticator and creates it.  Returns the server's representation of the jWTAuthenticator, and an error, if there is any.
-func (c *jWTAuthenticators) Create(ctx context.Context, jWTAuthenticator *v1alpha1.JWTAuthenticator, opts v1.CreateOptions) (result *v1alpha1.JWTAuthenticator, err error) {
-	result = &v1alpha1.JWTAuthenticator{}
+func (c *jWTAuthenticators) Create(ctx context.Context, jWTAuthenticator *v1beta1.JWTAuthenticator, opts v1.CreateOptions) (result *v1beta1.JWTAuthenticator, err error) {
+	result = &v1beta1.JWTAuthenticator{}
 	err = c.client.Post().
 		Resource("jwtauthenticators").
 		VersionedParams(&opts, scheme.ParameterCodec).
@@ -104,8 +104,8 @@ func (c *jWTAuthenticators) Create(ctx context.Context, jWTAuthenticator *v1alph
 }
 
 // Update takes the representation of a jWTAuthenticator and updates it. Returns the server's representation of the jWTAuthenticator, and an error, if there is any.
-func (c *jWTAuthenticators) Update(ctx context.Context, jWTAuthenticator *v1alpha1.JWTAuthenticator, opts v1.UpdateOptions) (result *v1alpha1.JWTAuthenticator, err error) {
-	result = &v1alpha1.JWTAuthenticator{}
+func (c *jWTAuthenticators) Update(ctx context.Context, jWTAuthenticator *v1beta1.JWTAuthenticator, opts v1.UpdateOptions) (result *v1beta1.JWTAuthenticator, err error) {
+	result = &v1beta1.JWTAuthenticator{}
 	err = c.client.Put().
 		Resource("jwtauthenticators").
 		Name(jWTAuthenticator.Name).
@@ -118,8 +118,8 @@ func (c *jWTAuthenticators) Update(ctx context.Context, jWTAuthenticator *v1alph
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *jWTAuthenticators) UpdateStatus(ctx context.Context, jWTAuthenticator *v1alpha1.JWTAuthenticator, opts v1.UpdateOptions) (result *v1alpha1.JWTAuthenticator, err error) {
-	result = &v1alpha1.JWTAuthenticator{}
+func (c *jWTAuthenticators) UpdateStatus(ctx context.Context, jWTAuthenticator *v1beta1.JWTAuthenticator, opts v1.UpdateOptions) (result *v1beta1.JWTAuthenticator, err error) {
+	result = &v1beta1.JWTAuthenticator{}
 	err = c.client.Put().
 		Resource("jwtauthenticators").
 		Name(jWTAuthenticator.Name).
@@ -157,8 +157,8 @@ func (c *jWTAuthenticators) DeleteCollection(ctx context.Context, opts v1.Delete
 }
 
 // Patch applies the patch and returns the patched jWTAuthenticator.
-func (c *jWTAuthenticators) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.JWTAuthenticator, err error) {
-	result = &v1alpha1.JWTAuthenticator{}
+func (c *jWTAuthenticators) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.JWTAuthenticator, err error) {
+	result = &v1beta1.JWTAuthenticator{}
 	err = c.client.Patch(pt).
 		Resource("jwtauthenticators").
 		Name(name).
@@ -3,14 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/concierge/authentication/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.18/client/concierge/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/concierge/authentication/v1beta1"
+	scheme "go.pinniped.dev/generated/1.19/client/concierge/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -25,15 +25,15 @@ type WebhookAuthenticatorsGetter interface {
 
 // WebhookAuthenticatorInterface has methods to work with WebhookAuthenticator resources.
 type WebhookAuthenticatorInterface interface {
-	Create(ctx context.Context, webhookAuthenticator *v1alpha1.WebhookAuthenticator, opts v1.CreateOptions) (*v1alpha1.WebhookAuthenticator, error)
-	Update(ctx context.Context, webhookAuthenticator *v1alpha1.WebhookAuthenticator, opts v1.UpdateOptions) (*v1alpha1.WebhookAuthenticator, error)
-	UpdateStatus(ctx context.Context, webhookAuthenticator *v1alpha1.WebhookAuthenticator, opts v1.UpdateOptions) (*v1alpha1.WebhookAuthenticator, error)
+	Create(ctx context.Context, webhookAuthenticator *v1beta1.WebhookAuthenticator, opts v1.CreateOptions) (*v1beta1.WebhookAuthenticator, error)
+	Update(ctx context.Context, webhookAuthenticator *v1beta1.WebhookAuthenticator, opts v1.UpdateOptions) (*v1beta1.WebhookAuthenticator, error)
+	UpdateStatus(ctx context.Context, webhookAuthenticator *v1beta1.WebhookAuthenticator, opts v1.UpdateOptions) (*v1beta1.WebhookAuthenticator, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
-	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.WebhookAuthenticator, error)
-	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.WebhookAuthenticatorList, error)
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.WebhookAuthenticator, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.WebhookAuthenticatorList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
-	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WebhookAuthenticator, err error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.WebhookAuthenticator, err error)
 	WebhookAuthenticatorExpansion
 }
 
@@ -43,15 +43,15 @@ type webhookAuthenticators struct {
 }
 
 // newWebhookAuthenticators returns a WebhookAuthenticators
-func newWebhookAuthenticators(c *AuthenticationV1alpha1Client) *webhookAuthenticators {
+func newWebhookAuthenticators(c *AuthenticationV1beta1Client) *webhookAuthenticators {
 	return &webhookAuthenticators{
 		client: c.RESTClient(),
 	}
 }
 
 // Get takes name of the webhookAuthenticator, and returns the corresponding webhookAuthenticator object, and an error if there is any.
-func (c *webhookAuthenticators) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
-	result = &v1alpha1.WebhookAuthenticator{}
+func (c *webhookAuthenticators) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.WebhookAuthenticator, err error) {
+	result = &v1beta1.WebhookAuthenticator{}
 	err = c.client.Get().
 		Resource("webhookauthenticators").
 		Name(name).
@@ -62,12 +62,12 @@ func (c *webhookAuthenticators) Get(ctx context.Context, name string, options v1
 }
 
 // List takes label and field selectors, and returns the list of WebhookAuthenticators that match those selectors.
-func (c *webhookAuthenticators) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.WebhookAuthenticatorList, err error) {
+func (c *webhookAuthenticators) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.WebhookAuthenticatorList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.WebhookAuthenticatorList{}
+	result = &v1beta1.WebhookAuthenticatorList{}
 	err = c.client.Get().
 		Resource("webhookauthenticators").
 		VersionedParams(&opts, scheme.ParameterCodec).
@@ -92,8 +92,8 @@ func (c *webhookAuthenticators) Watch(ctx context.Context, opts v1.ListOptions)
 }
 
 // Create takes the representation of a webhookAuthenticator and creates it.  Returns the server's representation of the webhookAuthenticator, and an error, if there is any.
-func (c *webhookAuthenticators) Create(ctx context.Context, webhookAuthenticator *v1alpha1.WebhookAuthenticator, opts v1.CreateOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
-	result = &v1alpha1.WebhookAuthenticator{}
+func (c *webhookAuthenticators) Create(ctx context.Context, webhookAuthenticator *v1beta1.WebhookAuthenticator, opts v1.CreateOptions) (result *v1beta1.WebhookAuthenticator, err error) {
+	result = &v1beta1.WebhookAuthenticator{}
 	err = c.client.Post().
 		Resource("webhookauthenticators").
 		VersionedParams(&opts, scheme.ParameterCodec).
@@ -104,8 +104,8 @@ func (c *webhookAuthenticators) Create(ctx context.Context, webhookAuthenticator
 }
 
 // Update takes the representation of a webhookAuthenticator and updates it. Returns the server's representation of the webhookAuthenticator, and an error, if there is any.
-func (c *webhookAuthenticators) Update(ctx context.Context, webhookAuthenticator *v1alpha1.WebhookAuthenticator, opts v1.UpdateOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
-	result = &v1alpha1.WebhookAuthenticator{}
+func (c *webhookAuthenticators) Update(ctx context.Context, webhookAuthenticator *v1beta1.WebhookAuthenticator, opts v1.UpdateOptions) (result *v1beta1.WebhookAuthenticator, err error) {
+	result = &v1beta1.WebhookAuthenticator{}
 	err = c.client.Put().
 		Resource("webhookauthenticators").
 		Name(webhookAuthenticator.Name).
@@ -118,8 +118,8 @@ func (c *webhookAuthenticators) Update(ctx context.Context, webhookAuthenticator
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *webhookAuthenticators) UpdateStatus(ctx context.Context, webhookAuthenticator *v1alpha1.WebhookAuthenticator, opts v1.UpdateOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
-	result = &v1alpha1.WebhookAuthenticator{}
+func (c *webhookAuthenticators) UpdateStatus(ctx context.Context, webhookAuthenticator *v1beta1.WebhookAuthenticator, opts v1.UpdateOptions) (result *v1beta1.WebhookAuthenticator, err error) {
+	result = &v1beta1.WebhookAuthenticator{}
 	err = c.client.Put().
 		Resource("webhookauthenticators").
 		Name(webhookAuthenticator.Name).
@@ -157,8 +157,8 @@ func (c *webhookAuthenticators) DeleteCollection(ctx context.Context, opts v1.De
 }
 
 // Patch applies the patch and returns the patched webhookAuthenticator.
-func (c *webhookAuthenticators) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WebhookAuthenticator, err error) {
-	result = &v1alpha1.WebhookAuthenticator{}
+func (c *webhookAuthenticators) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.WebhookAuthenticator, err error) {
+	result = &v1beta1.WebhookAuthenticator{}
 	err = c.client.Patch(pt).
 		Resource("webhookauthenticators").
 		Name(name).
@@ -3,14 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/concierge/config/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.18/client/concierge/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/concierge/config/v1beta1"
+	scheme "go.pinniped.dev/generated/1.19/client/concierge/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -25,15 +25,15 @@ type CredentialIssuersGetter interface {
 
 // CredentialIssuerInterface has methods to work with CredentialIssuer resources.
 type CredentialIssuerInterface interface {
-	Create(ctx context.Context, credentialIssuer *v1alpha1.CredentialIssuer, opts v1.CreateOptions) (*v1alpha1.CredentialIssuer, error)
-	Update(ctx context.Context, credentialIssuer *v1alpha1.CredentialIssuer, opts v1.UpdateOptions) (*v1alpha1.CredentialIssuer, error)
-	UpdateStatus(ctx context.Context, credentialIssuer *v1alpha1.CredentialIssuer, opts v1.UpdateOptions) (*v1alpha1.CredentialIssuer, error)
+	Create(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.CreateOptions) (*v1beta1.CredentialIssuer, error)
+	Update(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.UpdateOptions) (*v1beta1.CredentialIssuer, error)
+	UpdateStatus(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.UpdateOptions) (*v1beta1.CredentialIssuer, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
-	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.CredentialIssuer, error)
-	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.CredentialIssuerList, error)
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.CredentialIssuer, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.CredentialIssuerList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
-	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.CredentialIssuer, err error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CredentialIssuer, err error)
 	CredentialIssuerExpansion
 }
 
@@ -43,15 +43,15 @@ type credentialIssuers struct {
 }
 
 // newCredentialIssuers returns a CredentialIssuers
-func newCredentialIssuers(c *ConfigV1alpha1Client) *credentialIssuers {
+func newCredentialIssuers(c *ConfigV1beta1Client) *credentialIssuers {
 	return &credentialIssuers{
 		client: c.RESTClient(),
 	}
 }
 
 // Get takes name of the credentialIssuer, and returns the corresponding credentialIssuer object, and an error if there is any.
-func (c *credentialIssuers) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Get().
 		Resource("credentialissuers").
 		Name(name).
@@ -62,12 +62,12 @@ func (c *credentialIssuers) Get(ctx context.Context, name string, options v1.Get
 }
 
 // List takes label and field selectors, and returns the list of CredentialIssuers that match those selectors.
-func (c *credentialIssuers) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.CredentialIssuerList, err error) {
+func (c *credentialIssuers) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.CredentialIssuerList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.CredentialIssuerList{}
+	result = &v1beta1.CredentialIssuerList{}
 	err = c.client.Get().
 		Resource("credentialissuers").
 		VersionedParams(&opts, scheme.ParameterCodec).
@@ -92,8 +92,8 @@ func (c *credentialIssuers) Watch(ctx context.Context, opts v1.ListOptions) (wat
 }
 
 // Create takes the representation of a credentialIssuer and creates it.  Returns the server's representation of the credentialIssuer, and an error, if there is any.
-func (c *credentialIssuers) Create(ctx context.Context, credentialIssuer *v1alpha1.CredentialIssuer, opts v1.CreateOptions) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) Create(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.CreateOptions) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Post().
 		Resource("credentialissuers").
 		VersionedParams(&opts, scheme.ParameterCodec).
@@ -104,8 +104,8 @@ func (c *credentialIssuers) Create(ctx context.Context, credentialIssuer *v1alph
 }
 
 // Update takes the representation of a credentialIssuer and updates it. Returns the server's representation of the credentialIssuer, and an error, if there is any.
-func (c *credentialIssuers) Update(ctx context.Context, credentialIssuer *v1alpha1.CredentialIssuer, opts v1.UpdateOptions) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) Update(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.UpdateOptions) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Put().
 		Resource("credentialissuers").
 		Name(credentialIssuer.Name).
@@ -118,8 +118,8 @@ func (c *credentialIssuers) Update(ctx context.Context, credentialIssuer *v1alph
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *credentialIssuers) UpdateStatus(ctx context.Context, credentialIssuer *v1alpha1.CredentialIssuer, opts v1.UpdateOptions) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) UpdateStatus(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.UpdateOptions) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Put().
 		Resource("credentialissuers").
 		Name(credentialIssuer.Name).
@@ -157,8 +157,8 @@ func (c *credentialIssuers) DeleteCollection(ctx context.Context, opts v1.Delete
 }
 
 // Patch applies the patch and returns the patched credentialIssuer.
-func (c *credentialIssuers) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Patch(pt).
 		Resource("credentialissuers").
 		Name(name).
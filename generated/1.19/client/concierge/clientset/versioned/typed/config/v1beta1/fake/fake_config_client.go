@@ -6,22 +6,22 @@
 package fake
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/client/concierge/clientset/versioned/typed/config/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.19/client/concierge/clientset/versioned/typed/config/v1beta1"
 	rest "k8s.io/client-go/rest"
 	testing "k8s.io/client-go/testing"
 )
 
-type FakeConfigV1alpha1 struct {
+type FakeConfigV1beta1 struct {
 	*testing.Fake
 }
 
-func (c *FakeConfigV1alpha1) CredentialIssuers() v1alpha1.CredentialIssuerInterface {
+func (c *FakeConfigV1beta1) CredentialIssuers() v1beta1.CredentialIssuerInterface {
 	return &FakeCredentialIssuers{c}
 }
 
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
-func (c *FakeConfigV1alpha1) RESTClient() rest.Interface {
+func (c *FakeConfigV1beta1) RESTClient() rest.Interface {
 	var ret *rest.RESTClient
 	return ret
 }
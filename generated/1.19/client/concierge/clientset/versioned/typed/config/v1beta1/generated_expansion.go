@@ -3,6 +3,6 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 type CredentialIssuerExpansion interface{}
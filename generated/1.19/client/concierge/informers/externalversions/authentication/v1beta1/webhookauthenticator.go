@@ -3,16 +3,16 @@
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	time "time"
 
-	authenticationv1alpha1 "go.pinniped.dev/generated/1.18/apis/concierge/authentication/v1alpha1"
-	versioned "go.pinniped.dev/generated/1.18/client/concierge/clientset/versioned"
-	internalinterfaces "go.pinniped.dev/generated/1.18/client/concierge/informers/externalversions/internalinterfaces"
-	v1alpha1 "go.pinniped.dev/generated/1.18/client/concierge/listers/authentication/v1alpha1"
+	authenticationv1beta1 "go.pinniped.dev/generated/1.19/apis/concierge/authentication/v1beta1"
+	versioned "go.pinniped.dev/generated/1.19/client/concierge/clientset/versioned"
+	internalinterfaces "go.pinniped.dev/generated/1.19/client/concierge/informers/externalversions/internalinterfaces"
+	v1beta1 "go.pinniped.dev/generated/1.19/client/concierge/listers/authentication/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -23,7 +23,7 @@ import (
 // WebhookAuthenticators.
 type WebhookAuthenticatorInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.WebhookAuthenticatorLister
+	Lister() v1beta1.WebhookAuthenticatorLister
 }
 
 type webhookAuthenticatorInformer struct {
@@ -48,16 +48,16 @@ func NewFilteredWebhookAuthenticatorInformer(client versioned.Interface, resyncP
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.AuthenticationV1alpha1().WebhookAuthenticators().List(context.TODO(), options)
+				return client.AuthenticationV1beta1().WebhookAuthenticators().List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.AuthenticationV1alpha1().WebhookAuthenticators().Watch(context.TODO(), options)
+				return client.AuthenticationV1beta1().WebhookAuthenticators().Watch(context.TODO(), options)
 			},
 		},
-		&authenticationv1alpha1.WebhookAuthenticator{},
+		&authenticationv1beta1.WebhookAuthenticator{},
 		resyncPeriod,
 		indexers,
 	)
@@ -68,9 +68,9 @@ func (f *webhookAuthenticatorInformer) defaultInformer(client versioned.Interfac
 }
 
 func (f *webhookAuthenticatorInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&authenticationv1alpha1.WebhookAuthenticator{}, f.defaultInformer)
+	return f.factory.InformerFor(&authenticationv1beta1.WebhookAuthenticator{}, f.defaultInformer)
 }
 
-func (f *webhookAuthenticatorInformer) Lister() v1alpha1.WebhookAuthenticatorLister {
-	return v1alpha1.NewWebhookAuthenticatorLister(f.Informer().GetIndexer())
+func (f *webhookAuthenticatorInformer) Lister() v1beta1.WebhookAuthenticatorLister {
+	return v1beta1.NewWebhookAuthenticatorLister(f.Informer().GetIndexer())
 }
@@ -3,16 +3,16 @@
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	time "time"
 
-	configv1alpha1 "go.pinniped.dev/generated/1.18/apis/concierge/config/v1alpha1"
-	versioned "go.pinniped.dev/generated/1.18/client/concierge/clientset/versioned"
-	internalinterfaces "go.pinniped.dev/generated/1.18/client/concierge/informers/externalversions/internalinterfaces"
-	v1alpha1 "go.pinniped.dev/generated/1.18/client/concierge/listers/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.19/apis/concierge/config/v1beta1"
+	versioned "go.pinniped.dev/generated/1.19/client/concierge/clientset/versioned"
+	internalinterfaces "go.pinniped.dev/generated/1.19/client/concierge/informers/externalversions/internalinterfaces"
+	v1beta1 "go.pinniped.dev/generated/1.19/client/concierge/listers/config/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -23,7 +23,7 @@ import (
 // CredentialIssuers.
 type CredentialIssuerInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.CredentialIssuerLister
+	Lister() v1beta1.CredentialIssuerLister
 }
 
 type credentialIssuerInformer struct {
@@ -48,16 +48,16 @@ func NewFilteredCredentialIssuerInformer(client versioned.Interface, resyncPerio
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.ConfigV1alpha1().CredentialIssuers().List(context.TODO(), options)
+				return client.ConfigV1beta1().CredentialIssuers().List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.ConfigV1alpha1().CredentialIssuers().Watch(context.TODO(), options)
+				return client.ConfigV1beta1().CredentialIssuers().Watch(context.TODO(), options)
 			},
 		},
-		&configv1alpha1.CredentialIssuer{},
+		&configv1beta1.CredentialIssuer{},
 		resyncPeriod,
 		indexers,
 	)
@@ -68,9 +68,9 @@ func (f *credentialIssuerInformer) defaultInformer(client versioned.Interface, r
 }
 
 func (f *credentialIssuerInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&configv1alpha1.CredentialIssuer{}, f.defaultInformer)
+	return f.factory.InformerFor(&configv1beta1.CredentialIssuer{}, f.defaultInformer)
 }
 
-func (f *credentialIssuerInformer) Lister() v1alpha1.CredentialIssuerLister {
-	return v1alpha1.NewCredentialIssuerLister(f.Informer().GetIndexer())
+func (f *credentialIssuerInformer) Lister() v1beta1.CredentialIssuerLister {
+	return v1beta1.NewCredentialIssuerLister(f.Informer().GetIndexer())
 }
@@ -3,21 +3,24 @@
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/concierge/config/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/concierge/config/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 )
 
 // CredentialIssuerLister helps list CredentialIssuers.
+// All objects returned here must be treated as read-only.
 type CredentialIssuerLister interface {
 	// List lists all CredentialIssuers in the indexer.
-	List(selector labels.Selector) (ret []*v1alpha1.CredentialIssuer, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.CredentialIssuer, err error)
 	// Get retrieves the CredentialIssuer from the index for a given name.
-	Get(name string) (*v1alpha1.CredentialIssuer, error)
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.CredentialIssuer, error)
 	CredentialIssuerListerExpansion
 }
 
@@ -32,21 +35,21 @@ func NewCredentialIssuerLister(indexer cache.Indexer) CredentialIssuerLister {
 }
 
 // List lists all CredentialIssuers in the indexer.
-func (s *credentialIssuerLister) List(selector labels.Selector) (ret []*v1alpha1.CredentialIssuer, err error) {
+func (s *credentialIssuerLister) List(selector labels.Selector) (ret []*v1beta1.CredentialIssuer, err error) {
 	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.CredentialIssuer))
+		ret = append(ret, m.(*v1beta1.CredentialIssuer))
 	})
 	return ret, err
 }
 
 // Get retrieves the CredentialIssuer from the index for a given name.
-func (s *credentialIssuerLister) Get(name string) (*v1alpha1.CredentialIssuer, error) {
+func (s *credentialIssuerLister) Get(name string) (*v1beta1.CredentialIssuer, error) {
 	obj, exists, err := s.indexer.GetByKey(name)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewNotFound(v1alpha1.Resource("credentialissuer"), name)
+		return nil, errors.NewNotFound(v1beta1.Resource("credentialissuer"), name)
 	}
-	return obj.(*v1alpha1.CredentialIssuer), nil
+	return obj.(*v1beta1.CredentialIssuer), nil
 }
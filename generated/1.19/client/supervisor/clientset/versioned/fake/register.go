@@ -8,7 +8,10 @@ package fake
 import (
 	clientsecretv1alpha1 "go.pinniped.dev/generated/1.19/apis/supervisor/clientsecret/v1alpha1"
 	configv1alpha1 "go.pinniped.dev/generated/1.19/apis/supervisor/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/config/v1beta1"
 	idpv1alpha1 "go.pinniped.dev/generated/1.19/apis/supervisor/idp/v1alpha1"
+	idpv1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/idp/v1beta1"
+	sessionv1alpha1 "go.pinniped.dev/generated/1.19/apis/supervisor/session/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -22,7 +25,10 @@ var codecs = serializer.NewCodecFactory(scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
 	clientsecretv1alpha1.AddToScheme,
 	configv1alpha1.AddToScheme,
+	configv1beta1.AddToScheme,
 	idpv1alpha1.AddToScheme,
+	idpv1beta1.AddToScheme,
+	sessionv1alpha1.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
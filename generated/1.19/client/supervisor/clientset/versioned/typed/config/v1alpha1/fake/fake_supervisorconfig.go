@@ -0,0 +1,120 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "go.pinniped.dev/generated/1.19/apis/supervisor/config/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeSupervisorConfigs implements SupervisorConfigInterface
+type FakeSupervisorConfigs struct {
+	Fake *FakeConfigV1alpha1
+}
+
+var supervisorconfigsResource = schema.GroupVersionResource{Group: "config.supervisor.pinniped.dev", Version: "v1alpha1", Resource: "supervisorconfigs"}
+
+var supervisorconfigsKind = schema.GroupVersionKind{Group: "config.supervisor.pinniped.dev", Version: "v1alpha1", Kind: "SupervisorConfig"}
+
+// Get takes name of the supervisorConfig, and returns the corresponding supervisorConfig object, and an error if there is any.
+func (c *FakeSupervisorConfigs) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.SupervisorConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(supervisorconfigsResource, name), &v1alpha1.SupervisorConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SupervisorConfig), err
+}
+
+// List takes label and field selectors, and returns the list of SupervisorConfigs that match those selectors.
+func (c *FakeSupervisorConfigs) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.SupervisorConfigList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(supervisorconfigsResource, supervisorconfigsKind, opts), &v1alpha1.SupervisorConfigList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.SupervisorConfigList{ListMeta: obj.(*v1alpha1.SupervisorConfigList).ListMeta}
+	for _, item := range obj.(*v1alpha1.SupervisorConfigList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested supervisorConfigs.
+func (c *FakeSupervisorConfigs) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(supervisorconfigsResource, opts))
+}
+
+// Create takes the representation of a supervisorConfig and creates it.  Returns the server's representation of the supervisorConfig, and an error, if there is any.
+func (c *FakeSupervisorConfigs) Create(ctx context.Context, supervisorConfig *v1alpha1.SupervisorConfig, opts v1.CreateOptions) (result *v1alpha1.SupervisorConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(supervisorconfigsResource, supervisorConfig), &v1alpha1.SupervisorConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SupervisorConfig), err
+}
+
+// Update takes the representation of a supervisorConfig and updates it. Returns the server's representation of the supervisorConfig, and an error, if there is any.
+func (c *FakeSupervisorConfigs) Update(ctx context.Context, supervisorConfig *v1alpha1.SupervisorConfig, opts v1.UpdateOptions) (result *v1alpha1.SupervisorConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(supervisorconfigsResource, supervisorConfig), &v1alpha1.SupervisorConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SupervisorConfig), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeSupervisorConfigs) UpdateStatus(ctx context.Context, supervisorConfig *v1alpha1.SupervisorConfig, opts v1.UpdateOptions) (*v1alpha1.SupervisorConfig, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(supervisorconfigsResource, "status", supervisorConfig), &v1alpha1.SupervisorConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SupervisorConfig), err
+}
+
+// Delete takes name of the supervisorConfig and deletes it. Returns an error if one occurs.
+func (c *FakeSupervisorConfigs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(supervisorconfigsResource, name), &v1alpha1.SupervisorConfig{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeSupervisorConfigs) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(supervisorconfigsResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.SupervisorConfigList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched supervisorConfig.
+func (c *FakeSupervisorConfigs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.SupervisorConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(supervisorconfigsResource, name, pt, data, subresources...), &v1alpha1.SupervisorConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SupervisorConfig), err
+}
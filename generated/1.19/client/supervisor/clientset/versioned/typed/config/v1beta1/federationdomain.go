@@ -3,14 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/config/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.18/client/supervisor/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/config/v1beta1"
+	scheme "go.pinniped.dev/generated/1.19/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -25,15 +25,15 @@ type FederationDomainsGetter interface {
 
 // FederationDomainInterface has methods to work with FederationDomain resources.
 type FederationDomainInterface interface {
-	Create(ctx context.Context, federationDomain *v1alpha1.FederationDomain, opts v1.CreateOptions) (*v1alpha1.FederationDomain, error)
-	Update(ctx context.Context, federationDomain *v1alpha1.FederationDomain, opts v1.UpdateOptions) (*v1alpha1.FederationDomain, error)
-	UpdateStatus(ctx context.Context, federationDomain *v1alpha1.FederationDomain, opts v1.UpdateOptions) (*v1alpha1.FederationDomain, error)
+	Create(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.CreateOptions) (*v1beta1.FederationDomain, error)
+	Update(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (*v1beta1.FederationDomain, error)
+	UpdateStatus(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (*v1beta1.FederationDomain, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
-	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.FederationDomain, error)
-	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.FederationDomainList, error)
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.FederationDomain, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.FederationDomainList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
-	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.FederationDomain, err error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.FederationDomain, err error)
 	FederationDomainExpansion
 }
 
@@ -44,7 +44,7 @@ type federationDomains struct {
 }
 
 // newFederationDomains returns a FederationDomains
-func newFederationDomains(c *ConfigV1alpha1Client, namespace string) *federationDomains {
+func newFederationDomains(c *ConfigV1beta1Client, namespace string) *federationDomains {
 	return &federationDomains{
 		client: c.RESTClient(),
 		ns:     namespace,
@@ -52,8 +52,8 @@ func newFederationDomains(c *ConfigV1alpha1Client, namespace string) *federation
 }
 
 // Get takes name of the federationDomain, and returns the corresponding federationDomain object, and an error if there is any.
-func (c *federationDomains) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("federationdomains").
@@ -65,12 +65,12 @@ func (c *federationDomains) Get(ctx context.Context, name string, options v1.Get
 }
 
 // List takes label and field selectors, and returns the list of FederationDomains that match those selectors.
-func (c *federationDomains) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.FederationDomainList, err error) {
+func (c *federationDomains) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.FederationDomainList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.FederationDomainList{}
+	result = &v1beta1.FederationDomainList{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("federationdomains").
@@ -97,8 +97,8 @@ func (c *federationDomains) Watch(ctx context.Context, opts v1.ListOptions) (wat
 }
 
 // Create takes the representation of a federationDomain and creates it.  Returns the server's representation of the federationDomain, and an error, if there is any.
-func (c *federationDomains) Create(ctx context.Context, federationDomain *v1alpha1.FederationDomain, opts v1.CreateOptions) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) Create(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.CreateOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Post().
 		Namespace(c.ns).
 		Resource("federationdomains").
@@ -110,8 +110,8 @@ func (c *federationDomains) Create(ctx context.Context, federationDomain *v1alph
 }
 
 // Update takes the representation of a federationDomain and updates it. Returns the server's representation of the federationDomain, and an error, if there is any.
-func (c *federationDomains) Update(ctx context.Context, federationDomain *v1alpha1.FederationDomain, opts v1.UpdateOptions) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) Update(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("federationdomains").
@@ -125,8 +125,8 @@ func (c *federationDomains) Update(ctx context.Context, federationDomain *v1alph
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *federationDomains) UpdateStatus(ctx context.Context, federationDomain *v1alpha1.FederationDomain, opts v1.UpdateOptions) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) UpdateStatus(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("federationdomains").
@@ -167,8 +167,8 @@ func (c *federationDomains) DeleteCollection(ctx context.Context, opts v1.Delete
 }
 
 // Patch applies the patch and returns the patched federationDomain.
-func (c *federationDomains) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
 		Resource("federationdomains").
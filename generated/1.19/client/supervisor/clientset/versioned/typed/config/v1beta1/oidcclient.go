@@ -3,14 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/config/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.18/client/supervisor/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/config/v1beta1"
+	scheme "go.pinniped.dev/generated/1.19/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -25,15 +25,15 @@ type OIDCClientsGetter interface {
 
 // OIDCClientInterface has methods to work with OIDCClient resources.
 type OIDCClientInterface interface {
-	Create(ctx context.Context, oIDCClient *v1alpha1.OIDCClient, opts v1.CreateOptions) (*v1alpha1.OIDCClient, error)
-	Update(ctx context.Context, oIDCClient *v1alpha1.OIDCClient, opts v1.UpdateOptions) (*v1alpha1.OIDCClient, error)
-	UpdateStatus(ctx context.Context, oIDCClient *v1alpha1.OIDCClient, opts v1.UpdateOptions) (*v1alpha1.OIDCClient, error)
+	Create(ctx context.Context, oIDCClient *v1beta1.OIDCClient, opts v1.CreateOptions) (*v1beta1.OIDCClient, error)
+	Update(ctx context.Context, oIDCClient *v1beta1.OIDCClient, opts v1.UpdateOptions) (*v1beta1.OIDCClient, error)
+	UpdateStatus(ctx context.Context, oIDCClient *v1beta1.OIDCClient, opts v1.UpdateOptions) (*v1beta1.OIDCClient, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
-	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.OIDCClient, error)
-	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.OIDCClientList, error)
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.OIDCClient, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.OIDCClientList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
-	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.OIDCClient, err error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.OIDCClient, err error)
 	OIDCClientExpansion
 }
 
@@ -44,7 +44,7 @@ type oIDCClients struct {
 }
 
 // newOIDCClients returns a OIDCClients
-func newOIDCClients(c *ConfigV1alpha1Client, namespace string) *oIDCClients {
+func newOIDCClients(c *ConfigV1beta1Client, namespace string) *oIDCClients {
 	return &oIDCClients{
 		client: c.RESTClient(),
 		ns:     namespace,
@@ -52,8 +52,8 @@ func newOIDCClients(c *ConfigV1alpha1Client, namespace string) *oIDCClients {
 }
 
 // Get takes name of the oIDCClient, and returns the corresponding oIDCClient object, and an error if there is any.
-func (c *oIDCClients) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.OIDCClient, err error) {
-	result = &v1alpha1.OIDCClient{}
+func (c *oIDCClients) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.OIDCClient, err error) {
+	result = &v1beta1.OIDCClient{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("oidcclients").
@@ -65,12 +65,12 @@ func (c *oIDCClients) Get(ctx context.Context, name string, options v1.GetOption
 }
 
 // List takes label and field selectors, and returns the list of OIDCClients that match those selectors.
-func (c *oIDCClients) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.OIDCClientList, err error) {
+func (c *oIDCClients) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.OIDCClientList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.OIDCClientList{}
+	result = &v1beta1.OIDCClientList{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("oidcclients").
@@ -97,8 +97,8 @@ func (c *oIDCClients) Watch(ctx context.Context, opts v1.ListOptions) (watch.Int
 }
 
 // Create takes the representation of a oIDCClient and creates it.  Returns the server's representation of the oIDCClient, and an error, if there is any.
-func (c *oIDCClients) Create(ctx context.Context, oIDCClient *v1alpha1.OIDCClient, opts v1.CreateOptions) (result *v1alpha1.OIDCClient, err error) {
-	result = &v1alpha1.OIDCClient{}
+func (c *oIDCClients) Create(ctx context.Context, oIDCClient *v1beta1.OIDCClient, opts v1.CreateOptions) (result *v1beta1.OIDCClient, err error) {
+	result = &v1beta1.OIDCClient{}
 	err = c.client.Post().
 		Namespace(c.ns).
 		Resource("oidcclients").
@@ -110,8 +110,8 @@ func (c *oIDCClients) Create(ctx context.Context, oIDCClient *v1alpha1.OIDCClien
 }
 
 // Update takes the representation of a oIDCClient and updates it. Returns the server's representation of the oIDCClient, and an error, if there is any.
-func (c *oIDCClients) Update(ctx context.Context, oIDCClient *v1alpha1.OIDCClient, opts v1.UpdateOptions) (result *v1alpha1.OIDCClient, err error) {
-	result = &v1alpha1.OIDCClient{}
+func (c *oIDCClients) Update(ctx context.Context, oIDCClient *v1beta1.OIDCClient, opts v1.UpdateOptions) (result *v1beta1.OIDCClient, err error) {
+	result = &v1beta1.OIDCClient{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("oidcclients").
@@ -125,8 +125,8 @@ func (c *oIDCClients) Update(ctx context.Context, oIDCClient *v1alpha1.OIDCClien
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *oIDCClients) UpdateStatus(ctx context.Context, oIDCClient *v1alpha1.OIDCClient, opts v1.UpdateOptions) (result *v1alpha1.OIDCClient, err error) {
-	result = &v1alpha1.OIDCClient{}
+func (c *oIDCClients) UpdateStatus(ctx context.Context, oIDCClient *v1beta1.OIDCClient, opts v1.UpdateOptions) (result *v1beta1.OIDCClient, err error) {
+	result = &v1beta1.OIDCClient{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("oidcclients").
@@ -167,8 +167,8 @@ func (c *oIDCClients) DeleteCollection(ctx context.Context, opts v1.DeleteOption
 }
 
 // Patch applies the patch and returns the patched oIDCClient.
-func (c *oIDCClients) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.OIDCClient, err error) {
-	result = &v1alpha1.OIDCClient{}
+func (c *oIDCClients) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.OIDCClient, err error) {
+	result = &v1beta1.OIDCClient{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
 		Resource("oidcclients").
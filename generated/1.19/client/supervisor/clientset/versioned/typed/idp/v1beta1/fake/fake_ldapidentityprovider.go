@@ -8,7 +8,7 @@ package fake
 import (
 	"context"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/idp/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/idp/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,29 +19,29 @@ import (
 
 // FakeLDAPIdentityProviders implements LDAPIdentityProviderInterface
 type FakeLDAPIdentityProviders struct {
-	Fake *FakeIDPV1alpha1
+	Fake *FakeIDPV1beta1
 	ns   string
 }
 
-var ldapidentityprovidersResource = schema.GroupVersionResource{Group: "idp.supervisor.pinniped.dev", Version: "v1alpha1", Resource: "ldapidentityproviders"}
+var ldapidentityprovidersResource = schema.GroupVersionResource{Group: "idp.supervisor.pinniped.dev", Version: "v1beta1", Resource: "ldapidentityproviders"}
 
-var ldapidentityprovidersKind = schema.GroupVersionKind{Group: "idp.supervisor.pinniped.dev", Version: "v1alpha1", Kind: "LDAPIdentityProvider"}
+var ldapidentityprovidersKind = schema.GroupVersionKind{Group: "idp.supervisor.pinniped.dev", Version: "v1beta1", Kind: "LDAPIdentityProvider"}
 
 // Get takes name of the lDAPIdentityProvider, and returns the corresponding lDAPIdentityProvider object, and an error if there is any.
-func (c *FakeLDAPIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
+func (c *FakeLDAPIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewGetAction(ldapidentityprovidersResource, c.ns, name), &v1alpha1.LDAPIdentityProvider{})
+		Invokes(testing.NewGetAction(ldapidentityprovidersResource, c.ns, name), &v1beta1.LDAPIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.LDAPIdentityProvider), err
+	return obj.(*v1beta1.LDAPIdentityProvider), err
 }
 
 // List takes label and field selectors, and returns the list of LDAPIdentityProviders that match those selectors.
-func (c *FakeLDAPIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.LDAPIdentityProviderList, err error) {
+func (c *FakeLDAPIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.LDAPIdentityProviderList, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewListAction(ldapidentityprovidersResource, ldapidentityprovidersKind, c.ns, opts), &v1alpha1.LDAPIdentityProviderList{})
+		Invokes(testing.NewListAction(ldapidentityprovidersResource, ldapidentityprovidersKind, c.ns, opts), &v1beta1.LDAPIdentityProviderList{})
 
 	if obj == nil {
 		return nil, err
@@ -51,8 +51,8 @@ func (c *FakeLDAPIdentityProviders) List(ctx context.Context, opts v1.ListOption
 	if label == nil {
 		label = labels.Everything()
 	}
-	list := &v1alpha1.LDAPIdentityProviderList{ListMeta: obj.(*v1alpha1.LDAPIdentityProviderList).ListMeta}
-	for _, item := range obj.(*v1alpha1.LDAPIdentityProviderList).Items {
+	list := &v1beta1.LDAPIdentityProviderList{ListMeta: obj.(*v1beta1.LDAPIdentityProviderList).ListMeta}
+	for _, item := range obj.(*v1beta1.LDAPIdentityProviderList).Items {
 		if label.Matches(labels.Set(item.Labels)) {
 			list.Items = append(list.Items, item)
 		}
@@ -68,43 +68,43 @@ func (c *FakeLDAPIdentityProviders) Watch(ctx context.Context, opts v1.ListOptio
 }
 
 // Create takes the representation of a lDAPIdentityProvider and creates it.  Returns the server's representation of the lDAPIdentityProvider, and an error, if there is any.
-func (c *FakeLDAPIdentityProviders) Create(ctx context.Context, lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider, opts v1.CreateOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
+func (c *FakeLDAPIdentityProviders) Create(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.CreateOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewCreateAction(ldapidentityprovidersResource, c.ns, lDAPIdentityProvider), &v1alpha1.LDAPIdentityProvider{})
+		Invokes(testing.NewCreateAction(ldapidentityprovidersResource, c.ns, lDAPIdentityProvider), &v1beta1.LDAPIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.LDAPIdentityProvider), err
+	return obj.(*v1beta1.LDAPIdentityProvider), err
 }
 
 // Update takes the representation of a lDAPIdentityProvider and updates it. Returns the server's representation of the lDAPIdentityProvider, and an error, if there is any.
-func (c *FakeLDAPIdentityProviders) Update(ctx context.Context, lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider, opts v1.UpdateOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
+func (c *FakeLDAPIdentityProviders) Update(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewUpdateAction(ldapidentityprovidersResource, c.ns, lDAPIdentityProvider), &v1alpha1.LDAPIdentityProvider{})
+		Invokes(testing.NewUpdateAction(ldapidentityprovidersResource, c.ns, lDAPIdentityProvider), &v1beta1.LDAPIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.LDAPIdentityProvider), err
+	return obj.(*v1beta1.LDAPIdentityProvider), err
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakeLDAPIdentityProviders) UpdateStatus(ctx context.Context, lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider, opts v1.UpdateOptions) (*v1alpha1.LDAPIdentityProvider, error) {
+func (c *FakeLDAPIdentityProviders) UpdateStatus(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (*v1beta1.LDAPIdentityProvider, error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewUpdateSubresourceAction(ldapidentityprovidersResource, "status", c.ns, lDAPIdentityProvider), &v1alpha1.LDAPIdentityProvider{})
+		Invokes(testing.NewUpdateSubresourceAction(ldapidentityprovidersResource, "status", c.ns, lDAPIdentityProvider), &v1beta1.LDAPIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.LDAPIdentityProvider), err
+	return obj.(*v1beta1.LDAPIdentityProvider), err
 }
 
 // Delete takes name of the lDAPIdentityProvider and deletes it. Returns an error if one occurs.
 func (c *FakeLDAPIdentityProviders) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
-		Invokes(testing.NewDeleteAction(ldapidentityprovidersResource, c.ns, name), &v1alpha1.LDAPIdentityProvider{})
+		Invokes(testing.NewDeleteAction(ldapidentityprovidersResource, c.ns, name), &v1beta1.LDAPIdentityProvider{})
 
 	return err
 }
@@ -113,17 +113,17 @@ func (c *FakeLDAPIdentityProviders) Delete(ctx context.Context, name string, opt
 func (c *FakeLDAPIdentityProviders) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	action := testing.NewDeleteCollectionAction(ldapidentityprovidersResource, c.ns, listOpts)
 
-	_, err := c.Fake.Invokes(action, &v1alpha1.LDAPIdentityProviderList{})
+	_, err := c.Fake.Invokes(action, &v1beta1.LDAPIdentityProviderList{})
 	return err
 }
 
 // Patch applies the patch and returns the patched lDAPIdentityProvider.
-func (c *FakeLDAPIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.LDAPIdentityProvider, err error) {
+func (c *FakeLDAPIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.LDAPIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewPatchSubresourceAction(ldapidentityprovidersResource, c.ns, name, pt, data, subresources...), &v1alpha1.LDAPIdentityProvider{})
+		Invokes(testing.NewPatchSubresourceAction(ldapidentityprovidersResource, c.ns, name, pt, data, subresources...), &v1beta1.LDAPIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.LDAPIdentityProvider), err
+	return obj.(*v1beta1.LDAPIdentityProvider), err
 }
@@ -3,14 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/idp/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.18/client/supervisor/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/idp/v1beta1"
+	scheme "go.pinniped.dev/generated/1.19/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -25,15 +25,15 @@ type LDAPIdentityProvidersGetter interface {
 
 // LDAPIdentityProviderInterface has methods to work with LDAPIdentityProvider resources.
 type LDAPIdentityProviderInterface interface {
-	Create(ctx context.Context, lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider, opts v1.CreateOptions) (*v1alpha1.LDAPIdentityProvider, error)
-	Update(ctx context.Context, lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider, opts v1.UpdateOptions) (*v1alpha1.LDAPIdentityProvider, error)
-	UpdateStatus(ctx context.Context, lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider, opts v1.UpdateOptions) (*v1alpha1.LDAPIdentityProvider, error)
+	Create(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.CreateOptions) (*v1beta1.LDAPIdentityProvider, error)
+	Update(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (*v1beta1.LDAPIdentityProvider, error)
+	UpdateStatus(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (*v1beta1.LDAPIdentityProvider, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
-	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.LDAPIdentityProvider, error)
-	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.LDAPIdentityProviderList, error)
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.LDAPIdentityProvider, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.LDAPIdentityProviderList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
-	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.LDAPIdentityProvider, err error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.LDAPIdentityProvider, err error)
 	LDAPIdentityProviderExpansion
 }
 
@@ -44,7 +44,7 @@ type lDAPIdentityProviders struct {
 }
 
 // newLDAPIdentityProviders returns a LDAPIdentityProviders
-func newLDAPIdentityProviders(c *IDPV1alpha1Client, namespace string) *lDAPIdentityProviders {
+func newLDAPIdentityProviders(c *IDPV1beta1Client, namespace string) *lDAPIdentityProviders {
 	return &lDAPIdentityProviders{
 		client: c.RESTClient(),
 		ns:     namespace,
@@ -52,8 +52,8 @@ func newLDAPIdentityProviders(c *IDPV1alpha1Client, namespace string) *lDAPIdent
 }
 
 // Get takes name of the lDAPIdentityProvider, and returns the corresponding lDAPIdentityProvider object, and an error if there is any.
-func (c *lDAPIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
@@ -65,12 +65,12 @@ func (c *lDAPIdentityProviders) Get(ctx context.Context, name string, options v1
 }
 
 // List takes label and field selectors, and returns the list of LDAPIdentityProviders that match those selectors.
-func (c *lDAPIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.LDAPIdentityProviderList, err error) {
+func (c *lDAPIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.LDAPIdentityProviderList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.LDAPIdentityProviderList{}
+	result = &v1beta1.LDAPIdentityProviderList{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
@@ -97,8 +97,8 @@ func (c *lDAPIdentityProviders) Watch(ctx context.Context, opts v1.ListOptions)
 }
 
 // Create takes the representation of a lDAPIdentityProvider and creates it.  Returns the server's representation of the lDAPIdentityProvider, and an error, if there is any.
-func (c *lDAPIdentityProviders) Create(ctx context.Context, lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider, opts v1.CreateOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) Create(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.CreateOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Post().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
@@ -110,8 +110,8 @@ func (c *lDAPIdentityProviders) Create(ctx context.Context, lDAPIdentityProvider
 }
 
 // Update takes the representation of a lDAPIdentityProvider and updates it. Returns the server's representation of the lDAPIdentityProvider, and an error, if there is any.
-func (c *lDAPIdentityProviders) Update(ctx context.Context, lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider, opts v1.UpdateOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) Update(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
@@ -125,8 +125,8 @@ func (c *lDAPIdentityProviders) Update(ctx context.Context, lDAPIdentityProvider
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *lDAPIdentityProviders) UpdateStatus(ctx context.Context, lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider, opts v1.UpdateOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) UpdateStatus(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
@@ -167,8 +167,8 @@ func (c *lDAPIdentityProviders) DeleteCollection(ctx context.Context, opts v1.De
 }
 
 // Patch applies the patch and returns the patched lDAPIdentityProvider.
-func (c *lDAPIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
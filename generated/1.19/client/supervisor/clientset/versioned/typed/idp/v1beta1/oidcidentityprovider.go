@@ -3,14 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/idp/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.18/client/supervisor/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/idp/v1beta1"
+	scheme "go.pinniped.dev/generated/1.19/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -25,15 +25,15 @@ type OIDCIdentityProvidersGetter interface {
 
 // OIDCIdentityProviderInterface has methods to work with OIDCIdentityProvider resources.
 type OIDCIdentityProviderInterface interface {
-	Create(ctx context.Context, oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider, opts v1.CreateOptions) (*v1alpha1.OIDCIdentityProvider, error)
-	Update(ctx context.Context, oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider, opts v1.UpdateOptions) (*v1alpha1.OIDCIdentityProvider, error)
-	UpdateStatus(ctx context.Context, oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider, opts v1.UpdateOptions) (*v1alpha1.OIDCIdentityProvider, error)
+	Create(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.CreateOptions) (*v1beta1.OIDCIdentityProvider, error)
+	Update(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (*v1beta1.OIDCIdentityProvider, error)
+	UpdateStatus(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (*v1beta1.OIDCIdentityProvider, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
-	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.OIDCIdentityProvider, error)
-	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.OIDCIdentityProviderList, error)
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.OIDCIdentityProvider, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.OIDCIdentityProviderList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
-	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.OIDCIdentityProvider, err error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.OIDCIdentityProvider, err error)
 	OIDCIdentityProviderExpansion
 }
 
@@ -44,7 +44,7 @@ type oIDCIdentityProviders struct {
 }
 
 // newOIDCIdentityProviders returns a OIDCIdentityProviders
-func newOIDCIdentityProviders(c *IDPV1alpha1Client, namespace string) *oIDCIdentityProviders {
+func newOIDCIdentityProviders(c *IDPV1beta1Client, namespace string) *oIDCIdentityProviders {
 	return &oIDCIdentityProviders{
 		client: c.RESTClient(),
 		ns:     namespace,
@@ -52,8 +52,8 @@ func newOIDCIdentityProviders(c *IDPV1alpha1Client, namespace string) *oIDCIdent
 }
 
 // Get takes name of the oIDCIdentityProvider, and returns the corresponding oIDCIdentityProvider object, and an error if there is any.
-func (c *oIDCIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
@@ -65,12 +65,12 @@ func (c *oIDCIdentityProviders) Get(ctx context.Context, name string, options v1
 }
 
 // List takes label and field selectors, and returns the list of OIDCIdentityProviders that match those selectors.
-func (c *oIDCIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.OIDCIdentityProviderList, err error) {
+func (c *oIDCIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.OIDCIdentityProviderList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.OIDCIdentityProviderList{}
+	result = &v1beta1.OIDCIdentityProviderList{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
@@ -97,8 +97,8 @@ func (c *oIDCIdentityProviders) Watch(ctx context.Context, opts v1.ListOptions)
 }
 
 // Create takes the representation of a oIDCIdentityProvider and creates it.  Returns the server's representation of the oIDCIdentityProvider, and an error, if there is any.
-func (c *oIDCIdentityProviders) Create(ctx context.Context, oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider, opts v1.CreateOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) Create(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.CreateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Post().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
@@ -110,8 +110,8 @@ func (c *oIDCIdentityProviders) Create(ctx context.Context, oIDCIdentityProvider
 }
 
 // Update takes the representation of a oIDCIdentityProvider and updates it. Returns the server's representation of the oIDCIdentityProvider, and an error, if there is any.
-func (c *oIDCIdentityProviders) Update(ctx context.Context, oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider, opts v1.UpdateOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) Update(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
@@ -125,8 +125,8 @@ func (c *oIDCIdentityProviders) Update(ctx context.Context, oIDCIdentityProvider
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *oIDCIdentityProviders) UpdateStatus(ctx context.Context, oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider, opts v1.UpdateOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) UpdateStatus(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
@@ -167,8 +167,8 @@ func (c *oIDCIdentityProviders) DeleteCollection(ctx context.Context, opts v1.De
 }
 
 // Patch applies the patch and returns the patched oIDCIdentityProvider.
-func (c *oIDCIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
@@ -0,0 +1,102 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "go.pinniped.dev/generated/1.19/apis/supervisor/session/v1alpha1"
+	scheme "go.pinniped.dev/generated/1.19/client/supervisor/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rest "k8s.io/client-go/rest"
+)
+
+// DownstreamSessionsGetter has a method to return a DownstreamSessionInterface.
+// A group's client should implement this interface.
+type DownstreamSessionsGetter interface {
+	DownstreamSessions(namespace string) DownstreamSessionInterface
+}
+
+// DownstreamSessionInterface has methods to work with DownstreamSession resources.
+type DownstreamSessionInterface interface {
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.DownstreamSession, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.DownstreamSessionList, error)
+	DownstreamSessionExpansion
+}
+
+// downstreamSessions implements DownstreamSessionInterface
+type downstreamSessions struct {
+	client rest.Interface
+	ns     string
+}
+
+// newDownstreamSessions returns a DownstreamSessions
+func newDownstreamSessions(c *SessionV1alpha1Client, namespace string) *downstreamSessions {
+	return &downstreamSessions{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the downstreamSession, and returns the corresponding downstreamSession object, and an error if there is any.
+func (c *downstreamSessions) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.DownstreamSession, err error) {
+	result = &v1alpha1.DownstreamSession{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("downstreamsessions").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of DownstreamSessions that match those selectors.
+func (c *downstreamSessions) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.DownstreamSessionList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.DownstreamSessionList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("downstreamsessions").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the downstreamSession and deletes it. Returns an error if one occurs.
+func (c *downstreamSessions) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("downstreamsessions").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *downstreamSessions) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("downstreamsessions").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
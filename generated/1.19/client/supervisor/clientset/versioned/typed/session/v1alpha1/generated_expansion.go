@@ -5,4 +5,6 @@
 
 package v1alpha1
 
-type TokenCredentialRequestExpansion interface{}
+type DownstreamSessionExpansion interface{}
+
+type SecondFactorExpansion interface{}
@@ -0,0 +1,102 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "go.pinniped.dev/generated/1.19/apis/supervisor/session/v1alpha1"
+	scheme "go.pinniped.dev/generated/1.19/client/supervisor/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rest "k8s.io/client-go/rest"
+)
+
+// SecondFactorsGetter has a method to return a SecondFactorInterface.
+// A group's client should implement this interface.
+type SecondFactorsGetter interface {
+	SecondFactors(namespace string) SecondFactorInterface
+}
+
+// SecondFactorInterface has methods to work with SecondFactor resources.
+type SecondFactorInterface interface {
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.SecondFactor, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.SecondFactorList, error)
+	SecondFactorExpansion
+}
+
+// secondFactors implements SecondFactorInterface
+type secondFactors struct {
+	client rest.Interface
+	ns     string
+}
+
+// newSecondFactors returns a SecondFactors
+func newSecondFactors(c *SessionV1alpha1Client, namespace string) *secondFactors {
+	return &secondFactors{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the secondFactor, and returns the corresponding secondFactor object, and an error if there is any.
+func (c *secondFactors) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.SecondFactor, err error) {
+	result = &v1alpha1.SecondFactor{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("secondfactors").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of SecondFactors that match those selectors.
+func (c *secondFactors) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.SecondFactorList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.SecondFactorList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("secondfactors").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the secondFactor and deletes it. Returns an error if one occurs.
+func (c *secondFactors) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("secondfactors").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *secondFactors) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("secondfactors").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
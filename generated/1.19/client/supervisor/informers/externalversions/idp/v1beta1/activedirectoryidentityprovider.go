@@ -3,16 +3,16 @@
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	time "time"
 
-	idpv1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/idp/v1alpha1"
-	versioned "go.pinniped.dev/generated/1.18/client/supervisor/clientset/versioned"
-	internalinterfaces "go.pinniped.dev/generated/1.18/client/supervisor/informers/externalversions/internalinterfaces"
-	v1alpha1 "go.pinniped.dev/generated/1.18/client/supervisor/listers/idp/v1alpha1"
+	idpv1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/idp/v1beta1"
+	versioned "go.pinniped.dev/generated/1.19/client/supervisor/clientset/versioned"
+	internalinterfaces "go.pinniped.dev/generated/1.19/client/supervisor/informers/externalversions/internalinterfaces"
+	v1beta1 "go.pinniped.dev/generated/1.19/client/supervisor/listers/idp/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -23,7 +23,7 @@ import (
 // ActiveDirectoryIdentityProviders.
 type ActiveDirectoryIdentityProviderInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.ActiveDirectoryIdentityProviderLister
+	Lister() v1beta1.ActiveDirectoryIdentityProviderLister
 }
 
 type activeDirectoryIdentityProviderInformer struct {
@@ -49,16 +49,16 @@ func NewFilteredActiveDirectoryIdentityProviderInformer(client versioned.Interfa
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.IDPV1alpha1().ActiveDirectoryIdentityProviders(namespace).List(context.TODO(), options)
+				return client.IDPV1beta1().ActiveDirectoryIdentityProviders(namespace).List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.IDPV1alpha1().ActiveDirectoryIdentityProviders(namespace).Watch(context.TODO(), options)
+				return client.IDPV1beta1().ActiveDirectoryIdentityProviders(namespace).Watch(context.TODO(), options)
 			},
 		},
-		&idpv1alpha1.ActiveDirectoryIdentityProvider{},
+		&idpv1beta1.ActiveDirectoryIdentityProvider{},
 		resyncPeriod,
 		indexers,
 	)
@@ -69,9 +69,9 @@ func (f *activeDirectoryIdentityProviderInformer) defaultInformer(client version
 }
 
 func (f *activeDirectoryIdentityProviderInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&idpv1alpha1.ActiveDirectoryIdentityProvider{}, f.defaultInformer)
+	return f.factory.InformerFor(&idpv1beta1.ActiveDirectoryIdentityProvider{}, f.defaultInformer)
 }
 
-func (f *activeDirectoryIdentityProviderInformer) Lister() v1alpha1.ActiveDirectoryIdentityProviderLister {
-	return v1alpha1.NewActiveDirectoryIdentityProviderLister(f.Informer().GetIndexer())
+func (f *activeDirectoryIdentityProviderInformer) Lister() v1beta1.ActiveDirectoryIdentityProviderLister {
+	return v1beta1.NewActiveDirectoryIdentityProviderLister(f.Informer().GetIndexer())
 }
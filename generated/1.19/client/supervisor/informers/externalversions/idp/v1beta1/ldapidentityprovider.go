@@ -3,16 +3,16 @@
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
 	"context"
 	time "time"
 
-	idpv1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/idp/v1alpha1"
-	versioned "go.pinniped.dev/generated/1.18/client/supervisor/clientset/versioned"
-	internalinterfaces "go.pinniped.dev/generated/1.18/client/supervisor/informers/externalversions/internalinterfaces"
-	v1alpha1 "go.pinniped.dev/generated/1.18/client/supervisor/listers/idp/v1alpha1"
+	idpv1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/idp/v1beta1"
+	versioned "go.pinniped.dev/generated/1.19/client/supervisor/clientset/versioned"
+	internalinterfaces "go.pinniped.dev/generated/1.19/client/supervisor/informers/externalversions/internalinterfaces"
+	v1beta1 "go.pinniped.dev/generated/1.19/client/supervisor/listers/idp/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -23,7 +23,7 @@ import (
 // LDAPIdentityProviders.
 type LDAPIdentityProviderInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.LDAPIdentityProviderLister
+	Lister() v1beta1.LDAPIdentityProviderLister
 }
 
 type lDAPIdentityProviderInformer struct {
@@ -49,16 +49,16 @@ func NewFilteredLDAPIdentityProviderInformer(client versioned.Interface, namespa
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.IDPV1alpha1().LDAPIdentityProviders(namespace).List(context.TODO(), options)
+				return client.IDPV1beta1().LDAPIdentityProviders(namespace).List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.IDPV1alpha1().LDAPIdentityProviders(namespace).Watch(context.TODO(), options)
+				return client.IDPV1beta1().LDAPIdentityProviders(namespace).Watch(context.TODO(), options)
 			},
 		},
-		&idpv1alpha1.LDAPIdentityProvider{},
+		&idpv1beta1.LDAPIdentityProvider{},
 		resyncPeriod,
 		indexers,
 	)
@@ -69,9 +69,9 @@ func (f *lDAPIdentityProviderInformer) defaultInformer(client versioned.Interfac
 }
 
 func (f *lDAPIdentityProviderInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&idpv1alpha1.LDAPIdentityProvider{}, f.defaultInformer)
+	return f.factory.InformerFor(&idpv1beta1.LDAPIdentityProvider{}, f.defaultInformer)
 }
 
-func (f *lDAPIdentityProviderInformer) Lister() v1alpha1.LDAPIdentityProviderLister {
-	return v1alpha1.NewLDAPIdentityProviderLister(f.Informer().GetIndexer())
+func (f *lDAPIdentityProviderInformer) Lister() v1beta1.LDAPIdentityProviderLister {
+	return v1beta1.NewLDAPIdentityProviderLister(f.Informer().GetIndexer())
 }
@@ -3,19 +3,21 @@
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/config/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/config/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 )
 
 // OIDCClientLister helps list OIDCClients.
+// All objects returned here must be treated as read-only.
 type OIDCClientLister interface {
 	// List lists all OIDCClients in the indexer.
-	List(selector labels.Selector) (ret []*v1alpha1.OIDCClient, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.OIDCClient, err error)
 	// OIDCClients returns an object that can list and get OIDCClients.
 	OIDCClients(namespace string) OIDCClientNamespaceLister
 	OIDCClientListerExpansion
@@ -32,9 +34,9 @@ func NewOIDCClientLister(indexer cache.Indexer) OIDCClientLister {
 }
 
 // List lists all OIDCClients in the indexer.
-func (s *oIDCClientLister) List(selector labels.Selector) (ret []*v1alpha1.OIDCClient, err error) {
+func (s *oIDCClientLister) List(selector labels.Selector) (ret []*v1beta1.OIDCClient, err error) {
 	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.OIDCClient))
+		ret = append(ret, m.(*v1beta1.OIDCClient))
 	})
 	return ret, err
 }
@@ -45,11 +47,14 @@ func (s *oIDCClientLister) OIDCClients(namespace string) OIDCClientNamespaceList
 }
 
 // OIDCClientNamespaceLister helps list and get OIDCClients.
+// All objects returned here must be treated as read-only.
 type OIDCClientNamespaceLister interface {
 	// List lists all OIDCClients in the indexer for a given namespace.
-	List(selector labels.Selector) (ret []*v1alpha1.OIDCClient, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.OIDCClient, err error)
 	// Get retrieves the OIDCClient from the indexer for a given namespace and name.
-	Get(name string) (*v1alpha1.OIDCClient, error)
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.OIDCClient, error)
 	OIDCClientNamespaceListerExpansion
 }
 
@@ -61,21 +66,21 @@ type oIDCClientNamespaceLister struct {
 }
 
 // List lists all OIDCClients in the indexer for a given namespace.
-func (s oIDCClientNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.OIDCClient, err error) {
+func (s oIDCClientNamespaceLister) List(selector labels.Selector) (ret []*v1beta1.OIDCClient, err error) {
 	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.OIDCClient))
+		ret = append(ret, m.(*v1beta1.OIDCClient))
 	})
 	return ret, err
 }
 
 // Get retrieves the OIDCClient from the indexer for a given namespace and name.
-func (s oIDCClientNamespaceLister) Get(name string) (*v1alpha1.OIDCClient, error) {
+func (s oIDCClientNamespaceLister) Get(name string) (*v1beta1.OIDCClient, error) {
 	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewNotFound(v1alpha1.Resource("oidcclient"), name)
+		return nil, errors.NewNotFound(v1beta1.Resource("oidcclient"), name)
 	}
-	return obj.(*v1alpha1.OIDCClient), nil
+	return obj.(*v1beta1.OIDCClient), nil
 }
@@ -3,19 +3,21 @@
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/idp/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.19/apis/supervisor/idp/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 )
 
 // OIDCIdentityProviderLister helps list OIDCIdentityProviders.
+// All objects returned here must be treated as read-only.
 type OIDCIdentityProviderLister interface {
 	// List lists all OIDCIdentityProviders in the indexer.
-	List(selector labels.Selector) (ret []*v1alpha1.OIDCIdentityProvider, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.OIDCIdentityProvider, err error)
 	// OIDCIdentityProviders returns an object that can list and get OIDCIdentityProviders.
 	OIDCIdentityProviders(namespace string) OIDCIdentityProviderNamespaceLister
 	OIDCIdentityProviderListerExpansion
@@ -32,9 +34,9 @@ func NewOIDCIdentityProviderLister(indexer cache.Indexer) OIDCIdentityProviderLi
 }
 
 // List lists all OIDCIdentityProviders in the indexer.
-func (s *oIDCIdentityProviderLister) List(selector labels.Selector) (ret []*v1alpha1.OIDCIdentityProvider, err error) {
+func (s *oIDCIdentityProviderLister) List(selector labels.Selector) (ret []*v1beta1.OIDCIdentityProvider, err error) {
 	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.OIDCIdentityProvider))
+		ret = append(ret, m.(*v1beta1.OIDCIdentityProvider))
 	})
 	return ret, err
 }
@@ -45,11 +47,14 @@ func (s *oIDCIdentityProviderLister) OIDCIdentityProviders(namespace string) OID
 }
 
 // OIDCIdentityProviderNamespaceLister helps list and get OIDCIdentityProviders.
+// All objects returned here must be treated as read-only.
 type OIDCIdentityProviderNamespaceLister interface {
 	// List lists all OIDCIdentityProviders in the indexer for a given namespace.
-	List(selector labels.Selector) (ret []*v1alpha1.OIDCIdentityProvider, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.OIDCIdentityProvider, err error)
 	// Get retrieves the OIDCIdentityProvider from the indexer for a given namespace and name.
-	Get(name string) (*v1alpha1.OIDCIdentityProvider, error)
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.OIDCIdentityProvider, error)
 	OIDCIdentityProviderNamespaceListerExpansion
 }
 
@@ -61,21 +66,21 @@ type oIDCIdentityProviderNamespaceLister struct {
 }
 
 // List lists all OIDCIdentityProviders in the indexer for a given namespace.
-func (s oIDCIdentityProviderNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.OIDCIdentityProvider, err error) {
+func (s oIDCIdentityProviderNamespaceLister) List(selector labels.Selector) (ret []*v1beta1.OIDCIdentityProvider, err error) {
 	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.OIDCIdentityProvider))
+		ret = append(ret, m.(*v1beta1.OIDCIdentityProvider))
 	})
 	return ret, err
 }
 
 // Get retrieves the OIDCIdentityProvider from the indexer for a given namespace and name.
-func (s oIDCIdentityProviderNamespaceLister) Get(name string) (*v1alpha1.OIDCIdentityProvider, error) {
+func (s oIDCIdentityProviderNamespaceLister) Get(name string) (*v1beta1.OIDCIdentityProvider, error) {
 	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewNotFound(v1alpha1.Resource("oidcidentityprovider"), name)
+		return nil, errors.NewNotFound(v1beta1.Resource("oidcidentityprovider"), name)
 	}
-	return obj.(*v1alpha1.OIDCIdentityProvider), nil
+	return obj.(*v1beta1.OIDCIdentityProvider), nil
 }
@@ -0,0 +1,86 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.19/apis/supervisor/session/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DownstreamSessionLister helps list DownstreamSessions.
+// All objects returned here must be treated as read-only.
+type DownstreamSessionLister interface {
+	// List lists all DownstreamSessions in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.DownstreamSession, err error)
+	// DownstreamSessions returns an object that can list and get DownstreamSessions.
+	DownstreamSessions(namespace string) DownstreamSessionNamespaceLister
+	DownstreamSessionListerExpansion
+}
+
+// downstreamSessionLister implements the DownstreamSessionLister interface.
+type downstreamSessionLister struct {
+	indexer cache.Indexer
+}
+
+// NewDownstreamSessionLister returns a new DownstreamSessionLister.
+func NewDownstreamSessionLister(indexer cache.Indexer) DownstreamSessionLister {
+	return &downstreamSessionLister{indexer: indexer}
+}
+
+// List lists all DownstreamSessions in the indexer.
+func (s *downstreamSessionLister) List(selector labels.Selector) (ret []*v1alpha1.DownstreamSession, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.DownstreamSession))
+	})
+	return ret, err
+}
+
+// DownstreamSessions returns an object that can list and get DownstreamSessions.
+func (s *downstreamSessionLister) DownstreamSessions(namespace string) DownstreamSessionNamespaceLister {
+	return downstreamSessionNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// DownstreamSessionNamespaceLister helps list and get DownstreamSessions.
+// All objects returned here must be treated as read-only.
+type DownstreamSessionNamespaceLister interface {
+	// List lists all DownstreamSessions in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.DownstreamSession, err error)
+	// Get retrieves the DownstreamSession from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.DownstreamSession, error)
+	DownstreamSessionNamespaceListerExpansion
+}
+
+// downstreamSessionNamespaceLister implements the DownstreamSessionNamespaceLister
+// interface.
+type downstreamSessionNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all DownstreamSessions in the indexer for a given namespace.
+func (s downstreamSessionNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.DownstreamSession, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.DownstreamSession))
+	})
+	return ret, err
+}
+
+// Get retrieves the DownstreamSession from the indexer for a given namespace and name.
+func (s downstreamSessionNamespaceLister) Get(name string) (*v1alpha1.DownstreamSession, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("downstreamsession"), name)
+	}
+	return obj.(*v1alpha1.DownstreamSession), nil
+}
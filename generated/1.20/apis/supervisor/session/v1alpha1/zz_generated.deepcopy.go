@@ -13,105 +13,104 @@ import (
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ExtraValue) DeepCopyInto(out *ExtraValue) {
-	{
-		in := &in
-		*out = make(ExtraValue, len(*in))
-		copy(*out, *in)
-		return
-	}
+func (in *DownstreamSession) DeepCopyInto(out *DownstreamSession) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtraValue.
-func (in ExtraValue) DeepCopy() ExtraValue {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownstreamSession.
+func (in *DownstreamSession) DeepCopy() *DownstreamSession {
 	if in == nil {
 		return nil
 	}
-	out := new(ExtraValue)
+	out := new(DownstreamSession)
 	in.DeepCopyInto(out)
-	return *out
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DownstreamSession) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubernetesUserInfo) DeepCopyInto(out *KubernetesUserInfo) {
+func (in *DownstreamSessionList) DeepCopyInto(out *DownstreamSessionList) {
 	*out = *in
-	in.User.DeepCopyInto(&out.User)
-	if in.Audiences != nil {
-		in, out := &in.Audiences, &out.Audiences
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DownstreamSession, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesUserInfo.
-func (in *KubernetesUserInfo) DeepCopy() *KubernetesUserInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownstreamSessionList.
+func (in *DownstreamSessionList) DeepCopy() *DownstreamSessionList {
 	if in == nil {
 		return nil
 	}
-	out := new(KubernetesUserInfo)
+	out := new(DownstreamSessionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DownstreamSessionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *UserInfo) DeepCopyInto(out *UserInfo) {
+func (in *DownstreamSessionStatus) DeepCopyInto(out *DownstreamSessionStatus) {
 	*out = *in
-	if in.Groups != nil {
-		in, out := &in.Groups, &out.Groups
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Extra != nil {
-		in, out := &in.Extra, &out.Extra
-		*out = make(map[string]ExtraValue, len(*in))
-		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make(ExtraValue, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
-		}
-	}
+	in.AuthenticationTime.DeepCopyInto(&out.AuthenticationTime)
+	in.LastRefreshTime.DeepCopyInto(&out.LastRefreshTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserInfo.
-func (in *UserInfo) DeepCopy() *UserInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownstreamSessionStatus.
+func (in *DownstreamSessionStatus) DeepCopy() *DownstreamSessionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(UserInfo)
+	out := new(DownstreamSessionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WhoAmIRequest) DeepCopyInto(out *WhoAmIRequest) {
+func (in *SecondFactor) DeepCopyInto(out *SecondFactor) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	in.Status.DeepCopyInto(&out.Status)
+	out.Status = in.Status
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WhoAmIRequest.
-func (in *WhoAmIRequest) DeepCopy() *WhoAmIRequest {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecondFactor.
+func (in *SecondFactor) DeepCopy() *SecondFactor {
 	if in == nil {
 		return nil
 	}
-	out := new(WhoAmIRequest)
+	out := new(SecondFactor)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WhoAmIRequest) DeepCopyObject() runtime.Object {
+func (in *SecondFactor) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -119,13 +118,13 @@ func (in *WhoAmIRequest) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WhoAmIRequestList) DeepCopyInto(out *WhoAmIRequestList) {
+func (in *SecondFactorList) DeepCopyInto(out *SecondFactorList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]WhoAmIRequest, len(*in))
+		*out = make([]SecondFactor, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -133,18 +132,18 @@ func (in *WhoAmIRequestList) DeepCopyInto(out *WhoAmIRequestList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WhoAmIRequestList.
-func (in *WhoAmIRequestList) DeepCopy() *WhoAmIRequestList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecondFactorList.
+func (in *SecondFactorList) DeepCopy() *SecondFactorList {
 	if in == nil {
 		return nil
 	}
-	out := new(WhoAmIRequestList)
+	out := new(SecondFactorList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WhoAmIRequestList) DeepCopyObject() runtime.Object {
+func (in *SecondFactorList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -152,34 +151,17 @@ func (in *WhoAmIRequestList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WhoAmIRequestSpec) DeepCopyInto(out *WhoAmIRequestSpec) {
-	*out = *in
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WhoAmIRequestSpec.
-func (in *WhoAmIRequestSpec) DeepCopy() *WhoAmIRequestSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(WhoAmIRequestSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WhoAmIRequestStatus) DeepCopyInto(out *WhoAmIRequestStatus) {
+func (in *SecondFactorStatus) DeepCopyInto(out *SecondFactorStatus) {
 	*out = *in
-	in.KubernetesUserInfo.DeepCopyInto(&out.KubernetesUserInfo)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WhoAmIRequestStatus.
-func (in *WhoAmIRequestStatus) DeepCopy() *WhoAmIRequestStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecondFactorStatus.
+func (in *SecondFactorStatus) DeepCopy() *SecondFactorStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(WhoAmIRequestStatus)
+	out := new(SecondFactorStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -9,7 +9,9 @@ import (
 	"fmt"
 
 	authenticationv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/authentication/v1alpha1"
+	authenticationv1beta1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/authentication/v1beta1"
 	configv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/config/v1beta1"
 	identityv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/identity/v1alpha1"
 	loginv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/login/v1alpha1"
 	discovery "k8s.io/client-go/discovery"
@@ -20,7 +22,9 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	AuthenticationV1alpha1() authenticationv1alpha1.AuthenticationV1alpha1Interface
+	AuthenticationV1beta1() authenticationv1beta1.AuthenticationV1beta1Interface
 	ConfigV1alpha1() configv1alpha1.ConfigV1alpha1Interface
+	ConfigV1beta1() configv1beta1.ConfigV1beta1Interface
 	IdentityV1alpha1() identityv1alpha1.IdentityV1alpha1Interface
 	LoginV1alpha1() loginv1alpha1.LoginV1alpha1Interface
 }
@@ -30,7 +34,9 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	authenticationV1alpha1 *authenticationv1alpha1.AuthenticationV1alpha1Client
+	authenticationV1beta1  *authenticationv1beta1.AuthenticationV1beta1Client
 	configV1alpha1         *configv1alpha1.ConfigV1alpha1Client
+	configV1beta1          *configv1beta1.ConfigV1beta1Client
 	identityV1alpha1       *identityv1alpha1.IdentityV1alpha1Client
 	loginV1alpha1          *loginv1alpha1.LoginV1alpha1Client
 }
@@ -40,11 +46,21 @@ func (c *Clientset) AuthenticationV1alpha1() authenticationv1alpha1.Authenticati
 	return c.authenticationV1alpha1
 }
 
+// AuthenticationV1beta1 retrieves the AuthenticationV1beta1Client
+func (c *Clientset) AuthenticationV1beta1() authenticationv1beta1.AuthenticationV1beta1Interface {
+	return c.authenticationV1beta1
+}
+
 // ConfigV1alpha1 retrieves the ConfigV1alpha1Client
 func (c *Clientset) ConfigV1alpha1() configv1alpha1.ConfigV1alpha1Interface {
 	return c.configV1alpha1
 }
 
+// ConfigV1beta1 retrieves the ConfigV1beta1Client
+func (c *Clientset) ConfigV1beta1() configv1beta1.ConfigV1beta1Interface {
+	return c.configV1beta1
+}
+
 // IdentityV1alpha1 retrieves the IdentityV1alpha1Client
 func (c *Clientset) IdentityV1alpha1() identityv1alpha1.IdentityV1alpha1Interface {
 	return c.identityV1alpha1
@@ -80,10 +96,18 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.authenticationV1beta1, err = authenticationv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.configV1alpha1, err = configv1alpha1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
 	}
+	cs.configV1beta1, err = configv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.identityV1alpha1, err = identityv1alpha1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -105,7 +129,9 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.authenticationV1alpha1 = authenticationv1alpha1.NewForConfigOrDie(c)
+	cs.authenticationV1beta1 = authenticationv1beta1.NewForConfigOrDie(c)
 	cs.configV1alpha1 = configv1alpha1.NewForConfigOrDie(c)
+	cs.configV1beta1 = configv1beta1.NewForConfigOrDie(c)
 	cs.identityV1alpha1 = identityv1alpha1.NewForConfigOrDie(c)
 	cs.loginV1alpha1 = loginv1alpha1.NewForConfigOrDie(c)
 
@@ -117,7 +143,9 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.authenticationV1alpha1 = authenticationv1alpha1.New(c)
+	cs.authenticationV1beta1 = authenticationv1beta1.New(c)
 	cs.configV1alpha1 = configv1alpha1.New(c)
+	cs.configV1beta1 = configv1beta1.New(c)
 	cs.identityV1alpha1 = identityv1alpha1.New(c)
 	cs.loginV1alpha1 = loginv1alpha1.New(c)
 
@@ -9,8 +9,12 @@ import (
 	clientset "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned"
 	authenticationv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/authentication/v1alpha1"
 	fakeauthenticationv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/authentication/v1alpha1/fake"
+	authenticationv1beta1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/authentication/v1beta1"
+	fakeauthenticationv1beta1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/authentication/v1beta1/fake"
 	configv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/config/v1alpha1"
 	fakeconfigv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/config/v1alpha1/fake"
+	configv1beta1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/config/v1beta1"
+	fakeconfigv1beta1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/config/v1beta1/fake"
 	identityv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/identity/v1alpha1"
 	fakeidentityv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/identity/v1alpha1/fake"
 	loginv1alpha1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/login/v1alpha1"
@@ -74,11 +78,21 @@ func (c *Clientset) AuthenticationV1alpha1() authenticationv1alpha1.Authenticati
 	return &fakeauthenticationv1alpha1.FakeAuthenticationV1alpha1{Fake: &c.Fake}
 }
 
+// AuthenticationV1beta1 retrieves the AuthenticationV1beta1Client
+func (c *Clientset) AuthenticationV1beta1() authenticationv1beta1.AuthenticationV1beta1Interface {
+	return &fakeauthenticationv1beta1.FakeAuthenticationV1beta1{Fake: &c.Fake}
+}
+
 // ConfigV1alpha1 retrieves the ConfigV1alpha1Client
 func (c *Clientset) ConfigV1alpha1() configv1alpha1.ConfigV1alpha1Interface {
 	return &fakeconfigv1alpha1.FakeConfigV1alpha1{Fake: &c.Fake}
 }
 
+// ConfigV1beta1 retrieves the ConfigV1beta1Client
+func (c *Clientset) ConfigV1beta1() configv1beta1.ConfigV1beta1Interface {
+	return &fakeconfigv1beta1.FakeConfigV1beta1{Fake: &c.Fake}
+}
+
 // IdentityV1alpha1 retrieves the IdentityV1alpha1Client
 func (c *Clientset) IdentityV1alpha1() identityv1alpha1.IdentityV1alpha1Interface {
 	return &fakeidentityv1alpha1.FakeIdentityV1alpha1{Fake: &c.Fake}
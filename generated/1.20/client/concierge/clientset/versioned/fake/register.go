@@ -7,7 +7,9 @@ package fake
 
 import (
 	authenticationv1alpha1 "go.pinniped.dev/generated/1.20/apis/concierge/authentication/v1alpha1"
+	authenticationv1beta1 "go.pinniped.dev/generated/1.20/apis/concierge/authentication/v1beta1"
 	configv1alpha1 "go.pinniped.dev/generated/1.20/apis/concierge/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.20/apis/concierge/config/v1beta1"
 	identityv1alpha1 "go.pinniped.dev/generated/1.20/apis/concierge/identity/v1alpha1"
 	loginv1alpha1 "go.pinniped.dev/generated/1.20/apis/concierge/login/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -22,7 +24,9 @@ var codecs = serializer.NewCodecFactory(scheme)
 
 var localSchemeBuilder = runtime.SchemeBuilder{
 	authenticationv1alpha1.AddToScheme,
+	authenticationv1beta1.AddToScheme,
 	configv1alpha1.AddToScheme,
+	configv1beta1.AddToScheme,
 	identityv1alpha1.AddToScheme,
 	loginv1alpha1.AddToScheme,
 }
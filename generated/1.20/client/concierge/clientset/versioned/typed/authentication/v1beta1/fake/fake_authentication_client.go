@@ -6,26 +6,26 @@
 package fake
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.18/client/concierge/clientset/versioned/typed/authentication/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/typed/authentication/v1beta1"
 	rest "k8s.io/client-go/rest"
 	testing "k8s.io/client-go/testing"
 )
 
-type FakeAuthenticationV1alpha1 struct {
+type FakeAuthenticationV1beta1 struct {
 	*testing.Fake
 }
 
-func (c *FakeAuthenticationV1alpha1) JWTAuthenticators() v1alpha1.JWTAuthenticatorInterface {
+func (c *FakeAuthenticationV1beta1) JWTAuthenticators() v1beta1.JWTAuthenticatorInterface {
 	return &FakeJWTAuthenticators{c}
 }
 
-func (c *FakeAuthenticationV1alpha1) WebhookAuthenticators() v1alpha1.WebhookAuthenticatorInterface {
+func (c *FakeAuthenticationV1beta1) WebhookAuthenticators() v1beta1.WebhookAuthenticatorInterface {
 	return &FakeWebhookAuthenticators{c}
 }
 
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
-func (c *FakeAuthenticationV1alpha1) RESTClient() rest.Interface {
+func (c *FakeAuthenticationV1beta1) RESTClient() rest.Interface {
 	var ret *rest.RESTClient
 	return ret
 }
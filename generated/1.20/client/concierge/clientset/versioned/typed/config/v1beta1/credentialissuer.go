@@ -3,13 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
+	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/concierge/config/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.17/client/concierge/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/concierge/config/v1beta1"
+	scheme "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -24,15 +25,15 @@ type CredentialIssuersGetter interface {
 
 // CredentialIssuerInterface has methods to work with CredentialIssuer resources.
 type CredentialIssuerInterface interface {
-	Create(*v1alpha1.CredentialIssuer) (*v1alpha1.CredentialIssuer, error)
-	Update(*v1alpha1.CredentialIssuer) (*v1alpha1.CredentialIssuer, error)
-	UpdateStatus(*v1alpha1.CredentialIssuer) (*v1alpha1.CredentialIssuer, error)
-	Delete(name string, options *v1.DeleteOptions) error
-	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
-	Get(name string, options v1.GetOptions) (*v1alpha1.CredentialIssuer, error)
-	List(opts v1.ListOptions) (*v1alpha1.CredentialIssuerList, error)
-	Watch(opts v1.ListOptions) (watch.Interface, error)
-	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.CredentialIssuer, err error)
+	Create(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.CreateOptions) (*v1beta1.CredentialIssuer, error)
+	Update(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.UpdateOptions) (*v1beta1.CredentialIssuer, error)
+	UpdateStatus(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.UpdateOptions) (*v1beta1.CredentialIssuer, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.CredentialIssuer, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.CredentialIssuerList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CredentialIssuer, err error)
 	CredentialIssuerExpansion
 }
 
@@ -42,42 +43,42 @@ type credentialIssuers struct {
 }
 
 // newCredentialIssuers returns a CredentialIssuers
-func newCredentialIssuers(c *ConfigV1alpha1Client) *credentialIssuers {
+func newCredentialIssuers(c *ConfigV1beta1Client) *credentialIssuers {
 	return &credentialIssuers{
 		client: c.RESTClient(),
 	}
 }
 
 // Get takes name of the credentialIssuer, and returns the corresponding credentialIssuer object, and an error if there is any.
-func (c *credentialIssuers) Get(name string, options v1.GetOptions) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Get().
 		Resource("credentialissuers").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // List takes label and field selectors, and returns the list of CredentialIssuers that match those selectors.
-func (c *credentialIssuers) List(opts v1.ListOptions) (result *v1alpha1.CredentialIssuerList, err error) {
+func (c *credentialIssuers) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.CredentialIssuerList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.CredentialIssuerList{}
+	result = &v1beta1.CredentialIssuerList{}
 	err = c.client.Get().
 		Resource("credentialissuers").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Watch returns a watch.Interface that watches the requested credentialIssuers.
-func (c *credentialIssuers) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *credentialIssuers) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -87,81 +88,84 @@ func (c *credentialIssuers) Watch(opts v1.ListOptions) (watch.Interface, error)
 		Resource("credentialissuers").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Watch()
+		Watch(ctx)
 }
 
 // Create takes the representation of a credentialIssuer and creates it.  Returns the server's representation of the credentialIssuer, and an error, if there is any.
-func (c *credentialIssuers) Create(credentialIssuer *v1alpha1.CredentialIssuer) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) Create(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.CreateOptions) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Post().
 		Resource("credentialissuers").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(credentialIssuer).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Update takes the representation of a credentialIssuer and updates it. Returns the server's representation of the credentialIssuer, and an error, if there is any.
-func (c *credentialIssuers) Update(credentialIssuer *v1alpha1.CredentialIssuer) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) Update(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.UpdateOptions) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Put().
 		Resource("credentialissuers").
 		Name(credentialIssuer.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(credentialIssuer).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-
-func (c *credentialIssuers) UpdateStatus(credentialIssuer *v1alpha1.CredentialIssuer) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) UpdateStatus(ctx context.Context, credentialIssuer *v1beta1.CredentialIssuer, opts v1.UpdateOptions) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Put().
 		Resource("credentialissuers").
 		Name(credentialIssuer.Name).
 		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(credentialIssuer).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Delete takes name of the credentialIssuer and deletes it. Returns an error if one occurs.
-func (c *credentialIssuers) Delete(name string, options *v1.DeleteOptions) error {
+func (c *credentialIssuers) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
 		Resource("credentialissuers").
 		Name(name).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *credentialIssuers) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+func (c *credentialIssuers) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	var timeout time.Duration
-	if listOptions.TimeoutSeconds != nil {
-		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
 		Resource("credentialissuers").
-		VersionedParams(&listOptions, scheme.ParameterCodec).
+		VersionedParams(&listOpts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // Patch applies the patch and returns the patched credentialIssuer.
-func (c *credentialIssuers) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.CredentialIssuer, err error) {
-	result = &v1alpha1.CredentialIssuer{}
+func (c *credentialIssuers) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CredentialIssuer, err error) {
+	result = &v1beta1.CredentialIssuer{}
 	err = c.client.Patch(pt).
 		Resource("credentialissuers").
-		SubResource(subresources...).
 		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(data).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
@@ -3,15 +3,16 @@
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
+	"context"
 	time "time"
 
-	authenticationv1alpha1 "go.pinniped.dev/generated/1.17/apis/concierge/authentication/v1alpha1"
-	versioned "go.pinniped.dev/generated/1.17/client/concierge/clientset/versioned"
-	internalinterfaces "go.pinniped.dev/generated/1.17/client/concierge/informers/externalversions/internalinterfaces"
-	v1alpha1 "go.pinniped.dev/generated/1.17/client/concierge/listers/authentication/v1alpha1"
+	authenticationv1beta1 "go.pinniped.dev/generated/1.20/apis/concierge/authentication/v1beta1"
+	versioned "go.pinniped.dev/generated/1.20/client/concierge/clientset/versioned"
+	internalinterfaces "go.pinniped.dev/generated/1.20/client/concierge/informers/externalversions/internalinterfaces"
+	v1beta1 "go.pinniped.dev/generated/1.20/client/concierge/listers/authentication/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -22,7 +23,7 @@ import (
 // JWTAuthenticators.
 type JWTAuthenticatorInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.JWTAuthenticatorLister
+	Lister() v1beta1.JWTAuthenticatorLister
 }
 
 type jWTAuthenticatorInformer struct {
@@ -47,16 +48,16 @@ func NewFilteredJWTAuthenticatorInformer(client versioned.Interface, resyncPerio
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.AuthenticationV1alpha1().JWTAuthenticators().List(options)
+				return client.AuthenticationV1beta1().JWTAuthenticators().List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.AuthenticationV1alpha1().JWTAuthenticators().Watch(options)
+				return client.AuthenticationV1beta1().JWTAuthenticators().Watch(context.TODO(), options)
 			},
 		},
-		&authenticationv1alpha1.JWTAuthenticator{},
+		&authenticationv1beta1.JWTAuthenticator{},
 		resyncPeriod,
 		indexers,
 	)
@@ -67,9 +68,9 @@ func (f *jWTAuthenticatorInformer) defaultInformer(client versioned.Interface, r
 }
 
 func (f *jWTAuthenticatorInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&authenticationv1alpha1.JWTAuthenticator{}, f.defaultInformer)
+	return f.factory.InformerFor(&authenticationv1beta1.JWTAuthenticator{}, f.defaultInformer)
 }
 
-func (f *jWTAuthenticatorInformer) Lister() v1alpha1.JWTAuthenticatorLister {
-	return v1alpha1.NewJWTAuthenticatorLister(f.Informer().GetIndexer())
+func (f *jWTAuthenticatorInformer) Lister() v1beta1.JWTAuthenticatorLister {
+	return v1beta1.NewJWTAuthenticatorLister(f.Informer().GetIndexer())
 }
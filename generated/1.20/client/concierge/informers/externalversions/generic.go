@@ -9,7 +9,9 @@ import (
 	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.20/apis/concierge/authentication/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/concierge/authentication/v1beta1"
 	configv1alpha1 "go.pinniped.dev/generated/1.20/apis/concierge/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.20/apis/concierge/config/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -46,10 +48,20 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1alpha1.SchemeGroupVersion.WithResource("webhookauthenticators"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Authentication().V1alpha1().WebhookAuthenticators().Informer()}, nil
 
+		// Group=authentication.concierge.pinniped.dev, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("jwtauthenticators"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Authentication().V1beta1().JWTAuthenticators().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("webhookauthenticators"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Authentication().V1beta1().WebhookAuthenticators().Informer()}, nil
+
 		// Group=config.concierge.pinniped.dev, Version=v1alpha1
 	case configv1alpha1.SchemeGroupVersion.WithResource("credentialissuers"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Config().V1alpha1().CredentialIssuers().Informer()}, nil
 
+		// Group=config.concierge.pinniped.dev, Version=v1beta1
+	case configv1beta1.SchemeGroupVersion.WithResource("credentialissuers"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Config().V1beta1().CredentialIssuers().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
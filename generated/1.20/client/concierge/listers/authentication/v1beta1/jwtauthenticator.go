@@ -3,21 +3,24 @@
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/concierge/authentication/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/concierge/authentication/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 )
 
 // JWTAuthenticatorLister helps list JWTAuthenticators.
+// All objects returned here must be treated as read-only.
 type JWTAuthenticatorLister interface {
 	// List lists all JWTAuthenticators in the indexer.
-	List(selector labels.Selector) (ret []*v1alpha1.JWTAuthenticator, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.JWTAuthenticator, err error)
 	// Get retrieves the JWTAuthenticator from the index for a given name.
-	Get(name string) (*v1alpha1.JWTAuthenticator, error)
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.JWTAuthenticator, error)
 	JWTAuthenticatorListerExpansion
 }
 
@@ -32,21 +35,21 @@ func NewJWTAuthenticatorLister(indexer cache.Indexer) JWTAuthenticatorLister {
 }
 
 // List lists all JWTAuthenticators in the indexer.
-func (s *jWTAuthenticatorLister) List(selector labels.Selector) (ret []*v1alpha1.JWTAuthenticator, err error) {
+func (s *jWTAuthenticatorLister) List(selector labels.Selector) (ret []*v1beta1.JWTAuthenticator, err error) {
 	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.JWTAuthenticator))
+		ret = append(ret, m.(*v1beta1.JWTAuthenticator))
 	})
 	return ret, err
 }
 
 // Get retrieves the JWTAuthenticator from the index for a given name.
-func (s *jWTAuthenticatorLister) Get(name string) (*v1alpha1.JWTAuthenticator, error) {
+func (s *jWTAuthenticatorLister) Get(name string) (*v1beta1.JWTAuthenticator, error) {
 	obj, exists, err := s.indexer.GetByKey(name)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewNotFound(v1alpha1.Resource("jwtauthenticator"), name)
+		return nil, errors.NewNotFound(v1beta1.Resource("jwtauthenticator"), name)
 	}
-	return obj.(*v1alpha1.JWTAuthenticator), nil
+	return obj.(*v1beta1.JWTAuthenticator), nil
 }
@@ -3,21 +3,24 @@
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/concierge/authentication/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/concierge/authentication/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 )
 
 // WebhookAuthenticatorLister helps list WebhookAuthenticators.
+// All objects returned here must be treated as read-only.
 type WebhookAuthenticatorLister interface {
 	// List lists all WebhookAuthenticators in the indexer.
-	List(selector labels.Selector) (ret []*v1alpha1.WebhookAuthenticator, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.WebhookAuthenticator, err error)
 	// Get retrieves the WebhookAuthenticator from the index for a given name.
-	Get(name string) (*v1alpha1.WebhookAuthenticator, error)
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.WebhookAuthenticator, error)
 	WebhookAuthenticatorListerExpansion
 }
 
@@ -32,21 +35,21 @@ func NewWebhookAuthenticatorLister(indexer cache.Indexer) WebhookAuthenticatorLi
 }
 
 // List lists all WebhookAuthenticators in the indexer.
-func (s *webhookAuthenticatorLister) List(selector labels.Selector) (ret []*v1alpha1.WebhookAuthenticator, err error) {
+func (s *webhookAuthenticatorLister) List(selector labels.Selector) (ret []*v1beta1.WebhookAuthenticator, err error) {
 	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.WebhookAuthenticator))
+		ret = append(ret, m.(*v1beta1.WebhookAuthenticator))
 	})
 	return ret, err
 }
 
 // Get retrieves the WebhookAuthenticator from the index for a given name.
-func (s *webhookAuthenticatorLister) Get(name string) (*v1alpha1.WebhookAuthenticator, error) {
+func (s *webhookAuthenticatorLister) Get(name string) (*v1beta1.WebhookAuthenticator, error) {
 	obj, exists, err := s.indexer.GetByKey(name)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewNotFound(v1alpha1.Resource("webhookauthenticator"), name)
+		return nil, errors.NewNotFound(v1beta1.Resource("webhookauthenticator"), name)
 	}
-	return obj.(*v1alpha1.WebhookAuthenticator), nil
+	return obj.(*v1beta1.WebhookAuthenticator), nil
 }
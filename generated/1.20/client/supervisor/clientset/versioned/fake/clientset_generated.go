@@ -11,8 +11,14 @@ import (
 	fakeclientsecretv1alpha1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/clientsecret/v1alpha1/fake"
 	configv1alpha1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/config/v1alpha1"
 	fakeconfigv1alpha1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/config/v1alpha1/fake"
+	configv1beta1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/config/v1beta1"
+	fakeconfigv1beta1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/config/v1beta1/fake"
 	idpv1alpha1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/idp/v1alpha1"
 	fakeidpv1alpha1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/idp/v1alpha1/fake"
+	idpv1beta1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/idp/v1beta1"
+	fakeidpv1beta1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/idp/v1beta1/fake"
+	sessionv1alpha1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/session/v1alpha1"
+	fakesessionv1alpha1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/session/v1alpha1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -77,7 +83,22 @@ func (c *Clientset) ConfigV1alpha1() configv1alpha1.ConfigV1alpha1Interface {
 	return &fakeconfigv1alpha1.FakeConfigV1alpha1{Fake: &c.Fake}
 }
 
+// ConfigV1beta1 retrieves the ConfigV1beta1Client
+func (c *Clientset) ConfigV1beta1() configv1beta1.ConfigV1beta1Interface {
+	return &fakeconfigv1beta1.FakeConfigV1beta1{Fake: &c.Fake}
+}
+
 // IDPV1alpha1 retrieves the IDPV1alpha1Client
 func (c *Clientset) IDPV1alpha1() idpv1alpha1.IDPV1alpha1Interface {
 	return &fakeidpv1alpha1.FakeIDPV1alpha1{Fake: &c.Fake}
 }
+
+// IDPV1beta1 retrieves the IDPV1beta1Client
+func (c *Clientset) IDPV1beta1() idpv1beta1.IDPV1beta1Interface {
+	return &fakeidpv1beta1.FakeIDPV1beta1{Fake: &c.Fake}
+}
+
+// SessionV1alpha1 retrieves the SessionV1alpha1Client
+func (c *Clientset) SessionV1alpha1() sessionv1alpha1.SessionV1alpha1Interface {
+	return &fakesessionv1alpha1.FakeSessionV1alpha1{Fake: &c.Fake}
+}
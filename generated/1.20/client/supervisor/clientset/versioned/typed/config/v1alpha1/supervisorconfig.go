@@ -0,0 +1,171 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "go.pinniped.dev/generated/1.20/apis/supervisor/config/v1alpha1"
+	scheme "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// SupervisorConfigsGetter has a method to return a SupervisorConfigInterface.
+// A group's client should implement this interface.
+type SupervisorConfigsGetter interface {
+	SupervisorConfigs() SupervisorConfigInterface
+}
+
+// SupervisorConfigInterface has methods to work with SupervisorConfig resources.
+type SupervisorConfigInterface interface {
+	Create(ctx context.Context, supervisorConfig *v1alpha1.SupervisorConfig, opts v1.CreateOptions) (*v1alpha1.SupervisorConfig, error)
+	Update(ctx context.Context, supervisorConfig *v1alpha1.SupervisorConfig, opts v1.UpdateOptions) (*v1alpha1.SupervisorConfig, error)
+	UpdateStatus(ctx context.Context, supervisorConfig *v1alpha1.SupervisorConfig, opts v1.UpdateOptions) (*v1alpha1.SupervisorConfig, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.SupervisorConfig, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.SupervisorConfigList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.SupervisorConfig, err error)
+	SupervisorConfigExpansion
+}
+
+// supervisorConfigs implements SupervisorConfigInterface
+type supervisorConfigs struct {
+	client rest.Interface
+}
+
+// newSupervisorConfigs returns a SupervisorConfigs
+func newSupervisorConfigs(c *ConfigV1alpha1Client) *supervisorConfigs {
+	return &supervisorConfigs{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the supervisorConfig, and returns the corresponding supervisorConfig object, and an error if there is any.
+func (c *supervisorConfigs) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.SupervisorConfig, err error) {
+	result = &v1alpha1.SupervisorConfig{}
+	err = c.client.Get().
+		Resource("supervisorconfigs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of SupervisorConfigs that match those selectors.
+func (c *supervisorConfigs) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.SupervisorConfigList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.SupervisorConfigList{}
+	err = c.client.Get().
+		Resource("supervisorconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested supervisorConfigs.
+func (c *supervisorConfigs) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("supervisorconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a supervisorConfig and creates it.  Returns the server's representation of the supervisorConfig, and an error, if there is any.
+func (c *supervisorConfigs) Create(ctx context.Context, supervisorConfig *v1alpha1.SupervisorConfig, opts v1.CreateOptions) (result *v1alpha1.SupervisorConfig, err error) {
+	result = &v1alpha1.SupervisorConfig{}
+	err = c.client.Post().
+		Resource("supervisorconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(supervisorConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a supervisorConfig and updates it. Returns the server's representation of the supervisorConfig, and an error, if there is any.
+func (c *supervisorConfigs) Update(ctx context.Context, supervisorConfig *v1alpha1.SupervisorConfig, opts v1.UpdateOptions) (result *v1alpha1.SupervisorConfig, err error) {
+	result = &v1alpha1.SupervisorConfig{}
+	err = c.client.Put().
+		Resource("supervisorconfigs").
+		Name(supervisorConfig.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(supervisorConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *supervisorConfigs) UpdateStatus(ctx context.Context, supervisorConfig *v1alpha1.SupervisorConfig, opts v1.UpdateOptions) (result *v1alpha1.SupervisorConfig, err error) {
+	result = &v1alpha1.SupervisorConfig{}
+	err = c.client.Put().
+		Resource("supervisorconfigs").
+		Name(supervisorConfig.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(supervisorConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the supervisorConfig and deletes it. Returns an error if one occurs.
+func (c *supervisorConfigs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("supervisorconfigs").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *supervisorConfigs) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("supervisorconfigs").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched supervisorConfig.
+func (c *supervisorConfigs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.SupervisorConfig, err error) {
+	result = &v1alpha1.SupervisorConfig{}
+	err = c.client.Patch(pt).
+		Resource("supervisorconfigs").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -6,26 +6,26 @@
 package fake
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.18/client/supervisor/clientset/versioned/typed/config/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/config/v1beta1"
 	rest "k8s.io/client-go/rest"
 	testing "k8s.io/client-go/testing"
 )
 
-type FakeConfigV1alpha1 struct {
+type FakeConfigV1beta1 struct {
 	*testing.Fake
 }
 
-func (c *FakeConfigV1alpha1) FederationDomains(namespace string) v1alpha1.FederationDomainInterface {
+func (c *FakeConfigV1beta1) FederationDomains(namespace string) v1beta1.FederationDomainInterface {
 	return &FakeFederationDomains{c, namespace}
 }
 
-func (c *FakeConfigV1alpha1) OIDCClients(namespace string) v1alpha1.OIDCClientInterface {
+func (c *FakeConfigV1beta1) OIDCClients(namespace string) v1beta1.OIDCClientInterface {
 	return &FakeOIDCClients{c, namespace}
 }
 
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
-func (c *FakeConfigV1alpha1) RESTClient() rest.Interface {
+func (c *FakeConfigV1beta1) RESTClient() rest.Interface {
 	var ret *rest.RESTClient
 	return ret
 }
@@ -6,7 +6,9 @@
 package fake
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/config/v1alpha1"
+	"context"
+
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/config/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -17,29 +19,29 @@ import (
 
 // FakeFederationDomains implements FederationDomainInterface
 type FakeFederationDomains struct {
-	Fake *FakeConfigV1alpha1
+	Fake *FakeConfigV1beta1
 	ns   string
 }
 
-var federationdomainsResource = schema.GroupVersionResource{Group: "config.supervisor.pinniped.dev", Version: "v1alpha1", Resource: "federationdomains"}
+var federationdomainsResource = schema.GroupVersionResource{Group: "config.supervisor.pinniped.dev", Version: "v1beta1", Resource: "federationdomains"}
 
-var federationdomainsKind = schema.GroupVersionKind{Group: "config.supervisor.pinniped.dev", Version: "v1alpha1", Kind: "FederationDomain"}
+var federationdomainsKind = schema.GroupVersionKind{Group: "config.supervisor.pinniped.dev", Version: "v1beta1", Kind: "FederationDomain"}
 
 // Get takes name of the federationDomain, and returns the corresponding federationDomain object, and an error if there is any.
-func (c *FakeFederationDomains) Get(name string, options v1.GetOptions) (result *v1alpha1.FederationDomain, err error) {
+func (c *FakeFederationDomains) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.FederationDomain, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewGetAction(federationdomainsResource, c.ns, name), &v1alpha1.FederationDomain{})
+		Invokes(testing.NewGetAction(federationdomainsResource, c.ns, name), &v1beta1.FederationDomain{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.FederationDomain), err
+	return obj.(*v1beta1.FederationDomain), err
 }
 
 // List takes label and field selectors, and returns the list of FederationDomains that match those selectors.
-func (c *FakeFederationDomains) List(opts v1.ListOptions) (result *v1alpha1.FederationDomainList, err error) {
+func (c *FakeFederationDomains) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.FederationDomainList, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewListAction(federationdomainsResource, federationdomainsKind, c.ns, opts), &v1alpha1.FederationDomainList{})
+		Invokes(testing.NewListAction(federationdomainsResource, federationdomainsKind, c.ns, opts), &v1beta1.FederationDomainList{})
 
 	if obj == nil {
 		return nil, err
@@ -49,8 +51,8 @@ func (c *FakeFederationDomains) List(opts v1.ListOptions) (result *v1alpha1.Fede
 	if label == nil {
 		label = labels.Everything()
 	}
-	list := &v1alpha1.FederationDomainList{ListMeta: obj.(*v1alpha1.FederationDomainList).ListMeta}
-	for _, item := range obj.(*v1alpha1.FederationDomainList).Items {
+	list := &v1beta1.FederationDomainList{ListMeta: obj.(*v1beta1.FederationDomainList).ListMeta}
+	for _, item := range obj.(*v1beta1.FederationDomainList).Items {
 		if label.Matches(labels.Set(item.Labels)) {
 			list.Items = append(list.Items, item)
 		}
@@ -59,69 +61,69 @@ func (c *FakeFederationDomains) List(opts v1.ListOptions) (result *v1alpha1.Fede
 }
 
 // Watch returns a watch.Interface that watches the requested federationDomains.
-func (c *FakeFederationDomains) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *FakeFederationDomains) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	return c.Fake.
 		InvokesWatch(testing.NewWatchAction(federationdomainsResource, c.ns, opts))
 
 }
 
 // Create takes the representation of a federationDomain and creates it.  Returns the server's representation of the federationDomain, and an error, if there is any.
-func (c *FakeFederationDomains) Create(federationDomain *v1alpha1.FederationDomain) (result *v1alpha1.FederationDomain, err error) {
+func (c *FakeFederationDomains) Create(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.CreateOptions) (result *v1beta1.FederationDomain, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewCreateAction(federationdomainsResource, c.ns, federationDomain), &v1alpha1.FederationDomain{})
+		Invokes(testing.NewCreateAction(federationdomainsResource, c.ns, federationDomain), &v1beta1.FederationDomain{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.FederationDomain), err
+	return obj.(*v1beta1.FederationDomain), err
 }
 
 // Update takes the representation of a federationDomain and updates it. Returns the server's representation of the federationDomain, and an error, if there is any.
-func (c *FakeFederationDomains) Update(federationDomain *v1alpha1.FederationDomain) (result *v1alpha1.FederationDomain, err error) {
+func (c *FakeFederationDomains) Update(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (result *v1beta1.FederationDomain, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewUpdateAction(federationdomainsResource, c.ns, federationDomain), &v1alpha1.FederationDomain{})
+		Invokes(testing.NewUpdateAction(federationdomainsResource, c.ns, federationDomain), &v1beta1.FederationDomain{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.FederationDomain), err
+	return obj.(*v1beta1.FederationDomain), err
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakeFederationDomains) UpdateStatus(federationDomain *v1alpha1.FederationDomain) (*v1alpha1.FederationDomain, error) {
+func (c *FakeFederationDomains) UpdateStatus(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (*v1beta1.FederationDomain, error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewUpdateSubresourceAction(federationdomainsResource, "status", c.ns, federationDomain), &v1alpha1.FederationDomain{})
+		Invokes(testing.NewUpdateSubresourceAction(federationdomainsResource, "status", c.ns, federationDomain), &v1beta1.FederationDomain{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.FederationDomain), err
+	return obj.(*v1beta1.FederationDomain), err
 }
 
 // Delete takes name of the federationDomain and deletes it. Returns an error if one occurs.
-func (c *FakeFederationDomains) Delete(name string, options *v1.DeleteOptions) error {
+func (c *FakeFederationDomains) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
-		Invokes(testing.NewDeleteAction(federationdomainsResource, c.ns, name), &v1alpha1.FederationDomain{})
+		Invokes(testing.NewDeleteAction(federationdomainsResource, c.ns, name), &v1beta1.FederationDomain{})
 
 	return err
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *FakeFederationDomains) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
-	action := testing.NewDeleteCollectionAction(federationdomainsResource, c.ns, listOptions)
+func (c *FakeFederationDomains) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(federationdomainsResource, c.ns, listOpts)
 
-	_, err := c.Fake.Invokes(action, &v1alpha1.FederationDomainList{})
+	_, err := c.Fake.Invokes(action, &v1beta1.FederationDomainList{})
 	return err
 }
 
 // Patch applies the patch and returns the patched federationDomain.
-func (c *FakeFederationDomains) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.FederationDomain, err error) {
+func (c *FakeFederationDomains) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.FederationDomain, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewPatchSubresourceAction(federationdomainsResource, c.ns, name, pt, data, subresources...), &v1alpha1.FederationDomain{})
+		Invokes(testing.NewPatchSubresourceAction(federationdomainsResource, c.ns, name, pt, data, subresources...), &v1beta1.FederationDomain{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.FederationDomain), err
+	return obj.(*v1beta1.FederationDomain), err
 }
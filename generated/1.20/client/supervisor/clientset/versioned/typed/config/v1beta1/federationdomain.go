@@ -3,13 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
+	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/config/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/config/v1beta1"
+	scheme "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -24,15 +25,15 @@ type FederationDomainsGetter interface {
 
 // FederationDomainInterface has methods to work with FederationDomain resources.
 type FederationDomainInterface interface {
-	Create(*v1alpha1.FederationDomain) (*v1alpha1.FederationDomain, error)
-	Update(*v1alpha1.FederationDomain) (*v1alpha1.FederationDomain, error)
-	UpdateStatus(*v1alpha1.FederationDomain) (*v1alpha1.FederationDomain, error)
-	Delete(name string, options *v1.DeleteOptions) error
-	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
-	Get(name string, options v1.GetOptions) (*v1alpha1.FederationDomain, error)
-	List(opts v1.ListOptions) (*v1alpha1.FederationDomainList, error)
-	Watch(opts v1.ListOptions) (watch.Interface, error)
-	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.FederationDomain, err error)
+	Create(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.CreateOptions) (*v1beta1.FederationDomain, error)
+	Update(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (*v1beta1.FederationDomain, error)
+	UpdateStatus(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (*v1beta1.FederationDomain, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.FederationDomain, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.FederationDomainList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.FederationDomain, err error)
 	FederationDomainExpansion
 }
 
@@ -43,7 +44,7 @@ type federationDomains struct {
 }
 
 // newFederationDomains returns a FederationDomains
-func newFederationDomains(c *ConfigV1alpha1Client, namespace string) *federationDomains {
+func newFederationDomains(c *ConfigV1beta1Client, namespace string) *federationDomains {
 	return &federationDomains{
 		client: c.RESTClient(),
 		ns:     namespace,
@@ -51,37 +52,37 @@ func newFederationDomains(c *ConfigV1alpha1Client, namespace string) *federation
 }
 
 // Get takes name of the federationDomain, and returns the corresponding federationDomain object, and an error if there is any.
-func (c *federationDomains) Get(name string, options v1.GetOptions) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("federationdomains").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // List takes label and field selectors, and returns the list of FederationDomains that match those selectors.
-func (c *federationDomains) List(opts v1.ListOptions) (result *v1alpha1.FederationDomainList, err error) {
+func (c *federationDomains) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.FederationDomainList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.FederationDomainList{}
+	result = &v1beta1.FederationDomainList{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("federationdomains").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Watch returns a watch.Interface that watches the requested federationDomains.
-func (c *federationDomains) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *federationDomains) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -92,87 +93,90 @@ func (c *federationDomains) Watch(opts v1.ListOptions) (watch.Interface, error)
 		Resource("federationdomains").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Watch()
+		Watch(ctx)
 }
 
 // Create takes the representation of a federationDomain and creates it.  Returns the server's representation of the federationDomain, and an error, if there is any.
-func (c *federationDomains) Create(federationDomain *v1alpha1.FederationDomain) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) Create(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.CreateOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Post().
 		Namespace(c.ns).
 		Resource("federationdomains").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(federationDomain).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Update takes the representation of a federationDomain and updates it. Returns the server's representation of the federationDomain, and an error, if there is any.
-func (c *federationDomains) Update(federationDomain *v1alpha1.FederationDomain) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) Update(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("federationdomains").
 		Name(federationDomain.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(federationDomain).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-
-func (c *federationDomains) UpdateStatus(federationDomain *v1alpha1.FederationDomain) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) UpdateStatus(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("federationdomains").
 		Name(federationDomain.Name).
 		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(federationDomain).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Delete takes name of the federationDomain and deletes it. Returns an error if one occurs.
-func (c *federationDomains) Delete(name string, options *v1.DeleteOptions) error {
+func (c *federationDomains) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("federationdomains").
 		Name(name).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *federationDomains) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+func (c *federationDomains) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	var timeout time.Duration
-	if listOptions.TimeoutSeconds != nil {
-		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("federationdomains").
-		VersionedParams(&listOptions, scheme.ParameterCodec).
+		VersionedParams(&listOpts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // Patch applies the patch and returns the patched federationDomain.
-func (c *federationDomains) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.FederationDomain, err error) {
-	result = &v1alpha1.FederationDomain{}
+func (c *federationDomains) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
 		Resource("federationdomains").
-		SubResource(subresources...).
 		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(data).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
@@ -3,13 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
+	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/idp/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
+	scheme "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -24,15 +25,15 @@ type ActiveDirectoryIdentityProvidersGetter interface {
 
 // ActiveDirectoryIdentityProviderInterface has methods to work with ActiveDirectoryIdentityProvider resources.
 type ActiveDirectoryIdentityProviderInterface interface {
-	Create(*v1alpha1.ActiveDirectoryIdentityProvider) (*v1alpha1.ActiveDirectoryIdentityProvider, error)
-	Update(*v1alpha1.ActiveDirectoryIdentityProvider) (*v1alpha1.ActiveDirectoryIdentityProvider, error)
-	UpdateStatus(*v1alpha1.ActiveDirectoryIdentityProvider) (*v1alpha1.ActiveDirectoryIdentityProvider, error)
-	Delete(name string, options *v1.DeleteOptions) error
-	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
-	Get(name string, options v1.GetOptions) (*v1alpha1.ActiveDirectoryIdentityProvider, error)
-	List(opts v1.ListOptions) (*v1alpha1.ActiveDirectoryIdentityProviderList, error)
-	Watch(opts v1.ListOptions) (watch.Interface, error)
-	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error)
+	Create(ctx context.Context, activeDirectoryIdentityProvider *v1beta1.ActiveDirectoryIdentityProvider, opts v1.CreateOptions) (*v1beta1.ActiveDirectoryIdentityProvider, error)
+	Update(ctx context.Context, activeDirectoryIdentityProvider *v1beta1.ActiveDirectoryIdentityProvider, opts v1.UpdateOptions) (*v1beta1.ActiveDirectoryIdentityProvider, error)
+	UpdateStatus(ctx context.Context, activeDirectoryIdentityProvider *v1beta1.ActiveDirectoryIdentityProvider, opts v1.UpdateOptions) (*v1beta1.ActiveDirectoryIdentityProvider, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.ActiveDirectoryIdentityProvider, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.ActiveDirectoryIdentityProviderList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.ActiveDirectoryIdentityProvider, err error)
 	ActiveDirectoryIdentityProviderExpansion
 }
 
@@ -43,7 +44,7 @@ type activeDirectoryIdentityProviders struct {
 }
 
 // newActiveDirectoryIdentityProviders returns a ActiveDirectoryIdentityProviders
-func newActiveDirectoryIdentityProviders(c *IDPV1alpha1Client, namespace string) *activeDirectoryIdentityProviders {
+func newActiveDirectoryIdentityProviders(c *IDPV1beta1Client, namespace string) *activeDirectoryIdentityProviders {
 	return &activeDirectoryIdentityProviders{
 		client: c.RESTClient(),
 		ns:     namespace,
@@ -51,37 +52,37 @@ func newActiveDirectoryIdentityProviders(c *IDPV1alpha1Client, namespace string)
 }
 
 // Get takes name of the activeDirectoryIdentityProvider, and returns the corresponding activeDirectoryIdentityProvider object, and an error if there is any.
-func (c *activeDirectoryIdentityProviders) Get(name string, options v1.GetOptions) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
-	result = &v1alpha1.ActiveDirectoryIdentityProvider{}
+func (c *activeDirectoryIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.ActiveDirectoryIdentityProvider, err error) {
+	result = &v1beta1.ActiveDirectoryIdentityProvider{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("activedirectoryidentityproviders").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // List takes label and field selectors, and returns the list of ActiveDirectoryIdentityProviders that match those selectors.
-func (c *activeDirectoryIdentityProviders) List(opts v1.ListOptions) (result *v1alpha1.ActiveDirectoryIdentityProviderList, err error) {
+func (c *activeDirectoryIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.ActiveDirectoryIdentityProviderList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.ActiveDirectoryIdentityProviderList{}
+	result = &v1beta1.ActiveDirectoryIdentityProviderList{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("activedirectoryidentityproviders").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Watch returns a watch.Interface that watches the requested activeDirectoryIdentityProviders.
-func (c *activeDirectoryIdentityProviders) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *activeDirectoryIdentityProviders) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -92,87 +93,90 @@ func (c *activeDirectoryIdentityProviders) Watch(opts v1.ListOptions) (watch.Int
 		Resource("activedirectoryidentityproviders").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Watch()
+		Watch(ctx)
 }
 
 // Create takes the representation of a activeDirectoryIdentityProvider and creates it.  Returns the server's representation of the activeDirectoryIdentityProvider, and an error, if there is any.
-func (c *activeDirectoryIdentityProviders) Create(activeDirectoryIdentityProvider *v1alpha1.ActiveDirectoryIdentityProvider) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
-	result = &v1alpha1.ActiveDirectoryIdentityProvider{}
+func (c *activeDirectoryIdentityProviders) Create(ctx context.Context, activeDirectoryIdentityProvider *v1beta1.ActiveDirectoryIdentityProvider, opts v1.CreateOptions) (result *v1beta1.ActiveDirectoryIdentityProvider, err error) {
+	result = &v1beta1.ActiveDirectoryIdentityProvider{}
 	err = c.client.Post().
 		Namespace(c.ns).
 		Resource("activedirectoryidentityproviders").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(activeDirectoryIdentityProvider).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Update takes the representation of a activeDirectoryIdentityProvider and updates it. Returns the server's representation of the activeDirectoryIdentityProvider, and an error, if there is any.
-func (c *activeDirectoryIdentityProviders) Update(activeDirectoryIdentityProvider *v1alpha1.ActiveDirectoryIdentityProvider) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
-	result = &v1alpha1.ActiveDirectoryIdentityProvider{}
+func (c *activeDirectoryIdentityProviders) Update(ctx context.Context, activeDirectoryIdentityProvider *v1beta1.ActiveDirectoryIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.ActiveDirectoryIdentityProvider, err error) {
+	result = &v1beta1.ActiveDirectoryIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("activedirectoryidentityproviders").
 		Name(activeDirectoryIdentityProvider.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(activeDirectoryIdentityProvider).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-
-func (c *activeDirectoryIdentityProviders) UpdateStatus(activeDirectoryIdentityProvider *v1alpha1.ActiveDirectoryIdentityProvider) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
-	result = &v1alpha1.ActiveDirectoryIdentityProvider{}
+func (c *activeDirectoryIdentityProviders) UpdateStatus(ctx context.Context, activeDirectoryIdentityProvider *v1beta1.ActiveDirectoryIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.ActiveDirectoryIdentityProvider, err error) {
+	result = &v1beta1.ActiveDirectoryIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("activedirectoryidentityproviders").
 		Name(activeDirectoryIdentityProvider.Name).
 		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(activeDirectoryIdentityProvider).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Delete takes name of the activeDirectoryIdentityProvider and deletes it. Returns an error if one occurs.
-func (c *activeDirectoryIdentityProviders) Delete(name string, options *v1.DeleteOptions) error {
+func (c *activeDirectoryIdentityProviders) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("activedirectoryidentityproviders").
 		Name(name).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *activeDirectoryIdentityProviders) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+func (c *activeDirectoryIdentityProviders) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	var timeout time.Duration
-	if listOptions.TimeoutSeconds != nil {
-		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("activedirectoryidentityproviders").
-		VersionedParams(&listOptions, scheme.ParameterCodec).
+		VersionedParams(&listOpts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // Patch applies the patch and returns the patched activeDirectoryIdentityProvider.
-func (c *activeDirectoryIdentityProviders) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
-	result = &v1alpha1.ActiveDirectoryIdentityProvider{}
+func (c *activeDirectoryIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.ActiveDirectoryIdentityProvider, err error) {
+	result = &v1beta1.ActiveDirectoryIdentityProvider{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
 		Resource("activedirectoryidentityproviders").
-		SubResource(subresources...).
 		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(data).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
@@ -6,7 +6,9 @@
 package fake
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/idp/v1alpha1"
+	"context"
+
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -17,29 +19,29 @@ import (
 
 // FakeActiveDirectoryIdentityProviders implements ActiveDirectoryIdentityProviderInterface
 type FakeActiveDirectoryIdentityProviders struct {
-	Fake *FakeIDPV1alpha1
+	Fake *FakeIDPV1beta1
 	ns   string
 }
 
-var activedirectoryidentityprovidersResource = schema.GroupVersionResource{Group: "idp.supervisor.pinniped.dev", Version: "v1alpha1", Resource: "activedirectoryidentityproviders"}
+var activedirectoryidentityprovidersResource = schema.GroupVersionResource{Group: "idp.supervisor.pinniped.dev", Version: "v1beta1", Resource: "activedirectoryidentityproviders"}
 
-var activedirectoryidentityprovidersKind = schema.GroupVersionKind{Group: "idp.supervisor.pinniped.dev", Version: "v1alpha1", Kind: "ActiveDirectoryIdentityProvider"}
+var activedirectoryidentityprovidersKind = schema.GroupVersionKind{Group: "idp.supervisor.pinniped.dev", Version: "v1beta1", Kind: "ActiveDirectoryIdentityProvider"}
 
 // Get takes name of the activeDirectoryIdentityProvider, and returns the corresponding activeDirectoryIdentityProvider object, and an error if there is any.
-func (c *FakeActiveDirectoryIdentityProviders) Get(name string, options v1.GetOptions) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+func (c *FakeActiveDirectoryIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.ActiveDirectoryIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewGetAction(activedirectoryidentityprovidersResource, c.ns, name), &v1alpha1.ActiveDirectoryIdentityProvider{})
+		Invokes(testing.NewGetAction(activedirectoryidentityprovidersResource, c.ns, name), &v1beta1.ActiveDirectoryIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.ActiveDirectoryIdentityProvider), err
+	return obj.(*v1beta1.ActiveDirectoryIdentityProvider), err
 }
 
 // List takes label and field selectors, and returns the list of ActiveDirectoryIdentityProviders that match those selectors.
-func (c *FakeActiveDirectoryIdentityProviders) List(opts v1.ListOptions) (result *v1alpha1.ActiveDirectoryIdentityProviderList, err error) {
+func (c *FakeActiveDirectoryIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.ActiveDirectoryIdentityProviderList, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewListAction(activedirectoryidentityprovidersResource, activedirectoryidentityprovidersKind, c.ns, opts), &v1alpha1.ActiveDirectoryIdentityProviderList{})
+		Invokes(testing.NewListAction(activedirectoryidentityprovidersResource, activedirectoryidentityprovidersKind, c.ns, opts), &v1beta1.ActiveDirectoryIdentityProviderList{})
 
 	if obj == nil {
 		return nil, err
@@ -49,8 +51,8 @@ func (c *FakeActiveDirectoryIdentityProviders) List(opts v1.ListOptions) (result
 	if label == nil {
 		label = labels.Everything()
 	}
-	list := &v1alpha1.ActiveDirectoryIdentityProviderList{ListMeta: obj.(*v1alpha1.ActiveDirectoryIdentityProviderList).ListMeta}
-	for _, item := range obj.(*v1alpha1.ActiveDirectoryIdentityProviderList).Items {
+	list := &v1beta1.ActiveDirectoryIdentityProviderList{ListMeta: obj.(*v1beta1.ActiveDirectoryIdentityProviderList).ListMeta}
+	for _, item := range obj.(*v1beta1.ActiveDirectoryIdentityProviderList).Items {
 		if label.Matches(labels.Set(item.Labels)) {
 			list.Items = append(list.Items, item)
 		}
@@ -59,69 +61,69 @@ func (c *FakeActiveDirectoryIdentityProviders) List(opts v1.ListOptions) (result
 }
 
 // Watch returns a watch.Interface that watches the requested activeDirectoryIdentityProviders.
-func (c *FakeActiveDirectoryIdentityProviders) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *FakeActiveDirectoryIdentityProviders) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	return c.Fake.
 		InvokesWatch(testing.NewWatchAction(activedirectoryidentityprovidersResource, c.ns, opts))
 
 }
 
 // Create takes the representation of a activeDirectoryIdentityProvider and creates it.  Returns the server's representation of the activeDirectoryIdentityProvider, and an error, if there is any.
-func (c *FakeActiveDirectoryIdentityProviders) Create(activeDirectoryIdentityProvider *v1alpha1.ActiveDirectoryIdentityProvider) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+func (c *FakeActiveDirectoryIdentityProviders) Create(ctx context.Context, activeDirectoryIdentityProvider *v1beta1.ActiveDirectoryIdentityProvider, opts v1.CreateOptions) (result *v1beta1.ActiveDirectoryIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewCreateAction(activedirectoryidentityprovidersResource, c.ns, activeDirectoryIdentityProvider), &v1alpha1.ActiveDirectoryIdentityProvider{})
+		Invokes(testing.NewCreateAction(activedirectoryidentityprovidersResource, c.ns, activeDirectoryIdentityProvider), &v1beta1.ActiveDirectoryIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.ActiveDirectoryIdentityProvider), err
+	return obj.(*v1beta1.ActiveDirectoryIdentityProvider), err
 }
 
 // Update takes the representation of a activeDirectoryIdentityProvider and updates it. Returns the server's representation of the activeDirectoryIdentityProvider, and an error, if there is any.
-func (c *FakeActiveDirectoryIdentityProviders) Update(activeDirectoryIdentityProvider *v1alpha1.ActiveDirectoryIdentityProvider) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+func (c *FakeActiveDirectoryIdentityProviders) Update(ctx context.Context, activeDirectoryIdentityProvider *v1beta1.ActiveDirectoryIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.ActiveDirectoryIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewUpdateAction(activedirectoryidentityprovidersResource, c.ns, activeDirectoryIdentityProvider), &v1alpha1.ActiveDirectoryIdentityProvider{})
+		Invokes(testing.NewUpdateAction(activedirectoryidentityprovidersResource, c.ns, activeDirectoryIdentityProvider), &v1beta1.ActiveDirectoryIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.ActiveDirectoryIdentityProvider), err
+	return obj.(*v1beta1.ActiveDirectoryIdentityProvider), err
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakeActiveDirectoryIdentityProviders) UpdateStatus(activeDirectoryIdentityProvider *v1alpha1.ActiveDirectoryIdentityProvider) (*v1alpha1.ActiveDirectoryIdentityProvider, error) {
+func (c *FakeActiveDirectoryIdentityProviders) UpdateStatus(ctx context.Context, activeDirectoryIdentityProvider *v1beta1.ActiveDirectoryIdentityProvider, opts v1.UpdateOptions) (*v1beta1.ActiveDirectoryIdentityProvider, error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewUpdateSubresourceAction(activedirectoryidentityprovidersResource, "status", c.ns, activeDirectoryIdentityProvider), &v1alpha1.ActiveDirectoryIdentityProvider{})
+		Invokes(testing.NewUpdateSubresourceAction(activedirectoryidentityprovidersResource, "status", c.ns, activeDirectoryIdentityProvider), &v1beta1.ActiveDirectoryIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.ActiveDirectoryIdentityProvider), err
+	return obj.(*v1beta1.ActiveDirectoryIdentityProvider), err
 }
 
 // Delete takes name of the activeDirectoryIdentityProvider and deletes it. Returns an error if one occurs.
-func (c *FakeActiveDirectoryIdentityProviders) Delete(name string, options *v1.DeleteOptions) error {
+func (c *FakeActiveDirectoryIdentityProviders) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
-		Invokes(testing.NewDeleteAction(activedirectoryidentityprovidersResource, c.ns, name), &v1alpha1.ActiveDirectoryIdentityProvider{})
+		Invokes(testing.NewDeleteAction(activedirectoryidentityprovidersResource, c.ns, name), &v1beta1.ActiveDirectoryIdentityProvider{})
 
 	return err
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *FakeActiveDirectoryIdentityProviders) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
-	action := testing.NewDeleteCollectionAction(activedirectoryidentityprovidersResource, c.ns, listOptions)
+func (c *FakeActiveDirectoryIdentityProviders) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(activedirectoryidentityprovidersResource, c.ns, listOpts)
 
-	_, err := c.Fake.Invokes(action, &v1alpha1.ActiveDirectoryIdentityProviderList{})
+	_, err := c.Fake.Invokes(action, &v1beta1.ActiveDirectoryIdentityProviderList{})
 	return err
 }
 
 // Patch applies the patch and returns the patched activeDirectoryIdentityProvider.
-func (c *FakeActiveDirectoryIdentityProviders) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+func (c *FakeActiveDirectoryIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.ActiveDirectoryIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewPatchSubresourceAction(activedirectoryidentityprovidersResource, c.ns, name, pt, data, subresources...), &v1alpha1.ActiveDirectoryIdentityProvider{})
+		Invokes(testing.NewPatchSubresourceAction(activedirectoryidentityprovidersResource, c.ns, name, pt, data, subresources...), &v1beta1.ActiveDirectoryIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.ActiveDirectoryIdentityProvider), err
+	return obj.(*v1beta1.ActiveDirectoryIdentityProvider), err
 }
@@ -6,30 +6,30 @@
 package fake
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/typed/idp/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/typed/idp/v1beta1"
 	rest "k8s.io/client-go/rest"
 	testing "k8s.io/client-go/testing"
 )
 
-type FakeIDPV1alpha1 struct {
+type FakeIDPV1beta1 struct {
 	*testing.Fake
 }
 
-func (c *FakeIDPV1alpha1) ActiveDirectoryIdentityProviders(namespace string) v1alpha1.ActiveDirectoryIdentityProviderInterface {
+func (c *FakeIDPV1beta1) ActiveDirectoryIdentityProviders(namespace string) v1beta1.ActiveDirectoryIdentityProviderInterface {
 	return &FakeActiveDirectoryIdentityProviders{c, namespace}
 }
 
-func (c *FakeIDPV1alpha1) LDAPIdentityProviders(namespace string) v1alpha1.LDAPIdentityProviderInterface {
+func (c *FakeIDPV1beta1) LDAPIdentityProviders(namespace string) v1beta1.LDAPIdentityProviderInterface {
 	return &FakeLDAPIdentityProviders{c, namespace}
 }
 
-func (c *FakeIDPV1alpha1) OIDCIdentityProviders(namespace string) v1alpha1.OIDCIdentityProviderInterface {
+func (c *FakeIDPV1beta1) OIDCIdentityProviders(namespace string) v1beta1.OIDCIdentityProviderInterface {
 	return &FakeOIDCIdentityProviders{c, namespace}
 }
 
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
-func (c *FakeIDPV1alpha1) RESTClient() rest.Interface {
+func (c *FakeIDPV1beta1) RESTClient() rest.Interface {
 	var ret *rest.RESTClient
 	return ret
 }
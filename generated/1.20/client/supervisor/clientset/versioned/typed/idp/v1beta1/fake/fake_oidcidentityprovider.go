@@ -6,7 +6,9 @@
 package fake
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/idp/v1alpha1"
+	"context"
+
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -17,29 +19,29 @@ import (
 
 // FakeOIDCIdentityProviders implements OIDCIdentityProviderInterface
 type FakeOIDCIdentityProviders struct {
-	Fake *FakeIDPV1alpha1
+	Fake *FakeIDPV1beta1
 	ns   string
 }
 
-var oidcidentityprovidersResource = schema.GroupVersionResource{Group: "idp.supervisor.pinniped.dev", Version: "v1alpha1", Resource: "oidcidentityproviders"}
+var oidcidentityprovidersResource = schema.GroupVersionResource{Group: "idp.supervisor.pinniped.dev", Version: "v1beta1", Resource: "oidcidentityproviders"}
 
-var oidcidentityprovidersKind = schema.GroupVersionKind{Group: "idp.supervisor.pinniped.dev", Version: "v1alpha1", Kind: "OIDCIdentityProvider"}
+var oidcidentityprovidersKind = schema.GroupVersionKind{Group: "idp.supervisor.pinniped.dev", Version: "v1beta1", Kind: "OIDCIdentityProvider"}
 
 // Get takes name of the oIDCIdentityProvider, and returns the corresponding oIDCIdentityProvider object, and an error if there is any.
-func (c *FakeOIDCIdentityProviders) Get(name string, options v1.GetOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
+func (c *FakeOIDCIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewGetAction(oidcidentityprovidersResource, c.ns, name), &v1alpha1.OIDCIdentityProvider{})
+		Invokes(testing.NewGetAction(oidcidentityprovidersResource, c.ns, name), &v1beta1.OIDCIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.OIDCIdentityProvider), err
+	return obj.(*v1beta1.OIDCIdentityProvider), err
 }
 
 // List takes label and field selectors, and returns the list of OIDCIdentityProviders that match those selectors.
-func (c *FakeOIDCIdentityProviders) List(opts v1.ListOptions) (result *v1alpha1.OIDCIdentityProviderList, err error) {
+func (c *FakeOIDCIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.OIDCIdentityProviderList, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewListAction(oidcidentityprovidersResource, oidcidentityprovidersKind, c.ns, opts), &v1alpha1.OIDCIdentityProviderList{})
+		Invokes(testing.NewListAction(oidcidentityprovidersResource, oidcidentityprovidersKind, c.ns, opts), &v1beta1.OIDCIdentityProviderList{})
 
 	if obj == nil {
 		return nil, err
@@ -49,8 +51,8 @@ func (c *FakeOIDCIdentityProviders) List(opts v1.ListOptions) (result *v1alpha1.
 	if label == nil {
 		label = labels.Everything()
 	}
-	list := &v1alpha1.OIDCIdentityProviderList{ListMeta: obj.(*v1alpha1.OIDCIdentityProviderList).ListMeta}
-	for _, item := range obj.(*v1alpha1.OIDCIdentityProviderList).Items {
+	list := &v1beta1.OIDCIdentityProviderList{ListMeta: obj.(*v1beta1.OIDCIdentityProviderList).ListMeta}
+	for _, item := range obj.(*v1beta1.OIDCIdentityProviderList).Items {
 		if label.Matches(labels.Set(item.Labels)) {
 			list.Items = append(list.Items, item)
 		}
@@ -59,69 +61,69 @@ func (c *FakeOIDCIdentityProviders) List(opts v1.ListOptions) (result *v1alpha1.
 }
 
 // Watch returns a watch.Interface that watches the requested oIDCIdentityProviders.
-func (c *FakeOIDCIdentityProviders) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *FakeOIDCIdentityProviders) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	return c.Fake.
 		InvokesWatch(testing.NewWatchAction(oidcidentityprovidersResource, c.ns, opts))
 
 }
 
 // Create takes the representation of a oIDCIdentityProvider and creates it.  Returns the server's representation of the oIDCIdentityProvider, and an error, if there is any.
-func (c *FakeOIDCIdentityProviders) Create(oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider) (result *v1alpha1.OIDCIdentityProvider, err error) {
+func (c *FakeOIDCIdentityProviders) Create(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.CreateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewCreateAction(oidcidentityprovidersResource, c.ns, oIDCIdentityProvider), &v1alpha1.OIDCIdentityProvider{})
+		Invokes(testing.NewCreateAction(oidcidentityprovidersResource, c.ns, oIDCIdentityProvider), &v1beta1.OIDCIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.OIDCIdentityProvider), err
+	return obj.(*v1beta1.OIDCIdentityProvider), err
 }
 
 // Update takes the representation of a oIDCIdentityProvider and updates it. Returns the server's representation of the oIDCIdentityProvider, and an error, if there is any.
-func (c *FakeOIDCIdentityProviders) Update(oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider) (result *v1alpha1.OIDCIdentityProvider, err error) {
+func (c *FakeOIDCIdentityProviders) Update(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewUpdateAction(oidcidentityprovidersResource, c.ns, oIDCIdentityProvider), &v1alpha1.OIDCIdentityProvider{})
+		Invokes(testing.NewUpdateAction(oidcidentityprovidersResource, c.ns, oIDCIdentityProvider), &v1beta1.OIDCIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.OIDCIdentityProvider), err
+	return obj.(*v1beta1.OIDCIdentityProvider), err
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakeOIDCIdentityProviders) UpdateStatus(oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider) (*v1alpha1.OIDCIdentityProvider, error) {
+func (c *FakeOIDCIdentityProviders) UpdateStatus(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (*v1beta1.OIDCIdentityProvider, error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewUpdateSubresourceAction(oidcidentityprovidersResource, "status", c.ns, oIDCIdentityProvider), &v1alpha1.OIDCIdentityProvider{})
+		Invokes(testing.NewUpdateSubresourceAction(oidcidentityprovidersResource, "status", c.ns, oIDCIdentityProvider), &v1beta1.OIDCIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.OIDCIdentityProvider), err
+	return obj.(*v1beta1.OIDCIdentityProvider), err
 }
 
 // Delete takes name of the oIDCIdentityProvider and deletes it. Returns an error if one occurs.
-func (c *FakeOIDCIdentityProviders) Delete(name string, options *v1.DeleteOptions) error {
+func (c *FakeOIDCIdentityProviders) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
-		Invokes(testing.NewDeleteAction(oidcidentityprovidersResource, c.ns, name), &v1alpha1.OIDCIdentityProvider{})
+		Invokes(testing.NewDeleteAction(oidcidentityprovidersResource, c.ns, name), &v1beta1.OIDCIdentityProvider{})
 
 	return err
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *FakeOIDCIdentityProviders) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
-	action := testing.NewDeleteCollectionAction(oidcidentityprovidersResource, c.ns, listOptions)
+func (c *FakeOIDCIdentityProviders) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(oidcidentityprovidersResource, c.ns, listOpts)
 
-	_, err := c.Fake.Invokes(action, &v1alpha1.OIDCIdentityProviderList{})
+	_, err := c.Fake.Invokes(action, &v1beta1.OIDCIdentityProviderList{})
 	return err
 }
 
 // Patch applies the patch and returns the patched oIDCIdentityProvider.
-func (c *FakeOIDCIdentityProviders) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.OIDCIdentityProvider, err error) {
+func (c *FakeOIDCIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.OIDCIdentityProvider, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewPatchSubresourceAction(oidcidentityprovidersResource, c.ns, name, pt, data, subresources...), &v1alpha1.OIDCIdentityProvider{})
+		Invokes(testing.NewPatchSubresourceAction(oidcidentityprovidersResource, c.ns, name, pt, data, subresources...), &v1beta1.OIDCIdentityProvider{})
 
 	if obj == nil {
 		return nil, err
 	}
-	return obj.(*v1alpha1.OIDCIdentityProvider), err
+	return obj.(*v1beta1.OIDCIdentityProvider), err
 }
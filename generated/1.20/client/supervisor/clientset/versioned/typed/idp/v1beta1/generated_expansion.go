@@ -3,7 +3,7 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 type ActiveDirectoryIdentityProviderExpansion interface{}
 
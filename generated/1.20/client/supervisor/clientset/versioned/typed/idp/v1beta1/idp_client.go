@@ -3,40 +3,40 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/idp/v1alpha1"
-	"go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
+	"go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/scheme"
 	rest "k8s.io/client-go/rest"
 )
 
-type IDPV1alpha1Interface interface {
+type IDPV1beta1Interface interface {
 	RESTClient() rest.Interface
 	ActiveDirectoryIdentityProvidersGetter
 	LDAPIdentityProvidersGetter
 	OIDCIdentityProvidersGetter
 }
 
-// IDPV1alpha1Client is used to interact with features provided by the idp.supervisor.pinniped.dev group.
-type IDPV1alpha1Client struct {
+// IDPV1beta1Client is used to interact with features provided by the idp.supervisor.pinniped.dev group.
+type IDPV1beta1Client struct {
 	restClient rest.Interface
 }
 
-func (c *IDPV1alpha1Client) ActiveDirectoryIdentityProviders(namespace string) ActiveDirectoryIdentityProviderInterface {
+func (c *IDPV1beta1Client) ActiveDirectoryIdentityProviders(namespace string) ActiveDirectoryIdentityProviderInterface {
 	return newActiveDirectoryIdentityProviders(c, namespace)
 }
 
-func (c *IDPV1alpha1Client) LDAPIdentityProviders(namespace string) LDAPIdentityProviderInterface {
+func (c *IDPV1beta1Client) LDAPIdentityProviders(namespace string) LDAPIdentityProviderInterface {
 	return newLDAPIdentityProviders(c, namespace)
 }
 
-func (c *IDPV1alpha1Client) OIDCIdentityProviders(namespace string) OIDCIdentityProviderInterface {
+func (c *IDPV1beta1Client) OIDCIdentityProviders(namespace string) OIDCIdentityProviderInterface {
 	return newOIDCIdentityProviders(c, namespace)
 }
 
-// NewForConfig creates a new IDPV1alpha1Client for the given config.
-func NewForConfig(c *rest.Config) (*IDPV1alpha1Client, error) {
+// NewForConfig creates a new IDPV1beta1Client for the given config.
+func NewForConfig(c *rest.Config) (*IDPV1beta1Client, error) {
 	config := *c
 	if err := setConfigDefaults(&config); err != nil {
 		return nil, err
@@ -45,12 +45,12 @@ func NewForConfig(c *rest.Config) (*IDPV1alpha1Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &IDPV1alpha1Client{client}, nil
+	return &IDPV1beta1Client{client}, nil
 }
 
-// NewForConfigOrDie creates a new IDPV1alpha1Client for the given config and
+// NewForConfigOrDie creates a new IDPV1beta1Client for the given config and
 // panics if there is an error in the config.
-func NewForConfigOrDie(c *rest.Config) *IDPV1alpha1Client {
+func NewForConfigOrDie(c *rest.Config) *IDPV1beta1Client {
 	client, err := NewForConfig(c)
 	if err != nil {
 		panic(err)
@@ -58,13 +58,13 @@ func NewForConfigOrDie(c *rest.Config) *IDPV1alpha1Client {
 	return client
 }
 
-// New creates a new IDPV1alpha1Client for the given RESTClient.
-func New(c rest.Interface) *IDPV1alpha1Client {
-	return &IDPV1alpha1Client{c}
+// New creates a new IDPV1beta1Client for the given RESTClient.
+func New(c rest.Interface) *IDPV1beta1Client {
+	return &IDPV1beta1Client{c}
 }
 
 func setConfigDefaults(config *rest.Config) error {
-	gv := v1alpha1.SchemeGroupVersion
+	gv := v1beta1.SchemeGroupVersion
 	config.GroupVersion = &gv
 	config.APIPath = "/apis"
 	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
@@ -78,7 +78,7 @@ func setConfigDefaults(config *rest.Config) error {
 
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
-func (c *IDPV1alpha1Client) RESTClient() rest.Interface {
+func (c *IDPV1beta1Client) RESTClient() rest.Interface {
 	if c == nil {
 		return nil
 	}
@@ -3,13 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
+	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/idp/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
+	scheme "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -24,15 +25,15 @@ type LDAPIdentityProvidersGetter interface {
 
 // LDAPIdentityProviderInterface has methods to work with LDAPIdentityProvider resources.
 type LDAPIdentityProviderInterface interface {
-	Create(*v1alpha1.LDAPIdentityProvider) (*v1alpha1.LDAPIdentityProvider, error)
-	Update(*v1alpha1.LDAPIdentityProvider) (*v1alpha1.LDAPIdentityProvider, error)
-	UpdateStatus(*v1alpha1.LDAPIdentityProvider) (*v1alpha1.LDAPIdentityProvider, error)
-	Delete(name string, options *v1.DeleteOptions) error
-	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
-	Get(name string, options v1.GetOptions) (*v1alpha1.LDAPIdentityProvider, error)
-	List(opts v1.ListOptions) (*v1alpha1.LDAPIdentityProviderList, error)
-	Watch(opts v1.ListOptions) (watch.Interface, error)
-	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.LDAPIdentityProvider, err error)
+	Create(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.CreateOptions) (*v1beta1.LDAPIdentityProvider, error)
+	Update(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (*v1beta1.LDAPIdentityProvider, error)
+	UpdateStatus(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (*v1beta1.LDAPIdentityProvider, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.LDAPIdentityProvider, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.LDAPIdentityProviderList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.LDAPIdentityProvider, err error)
 	LDAPIdentityProviderExpansion
 }
 
@@ -43,7 +44,7 @@ type lDAPIdentityProviders struct {
 }
 
 // newLDAPIdentityProviders returns a LDAPIdentityProviders
-func newLDAPIdentityProviders(c *IDPV1alpha1Client, namespace string) *lDAPIdentityProviders {
+func newLDAPIdentityProviders(c *IDPV1beta1Client, namespace string) *lDAPIdentityProviders {
 	return &lDAPIdentityProviders{
 		client: c.RESTClient(),
 		ns:     namespace,
@@ -51,37 +52,37 @@ func newLDAPIdentityProviders(c *IDPV1alpha1Client, namespace string) *lDAPIdent
 }
 
 // Get takes name of the lDAPIdentityProvider, and returns the corresponding lDAPIdentityProvider object, and an error if there is any.
-func (c *lDAPIdentityProviders) Get(name string, options v1.GetOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // List takes label and field selectors, and returns the list of LDAPIdentityProviders that match those selectors.
-func (c *lDAPIdentityProviders) List(opts v1.ListOptions) (result *v1alpha1.LDAPIdentityProviderList, err error) {
+func (c *lDAPIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.LDAPIdentityProviderList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.LDAPIdentityProviderList{}
+	result = &v1beta1.LDAPIdentityProviderList{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Watch returns a watch.Interface that watches the requested lDAPIdentityProviders.
-func (c *lDAPIdentityProviders) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *lDAPIdentityProviders) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -92,87 +93,90 @@ func (c *lDAPIdentityProviders) Watch(opts v1.ListOptions) (watch.Interface, err
 		Resource("ldapidentityproviders").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Watch()
+		Watch(ctx)
 }
 
 // Create takes the representation of a lDAPIdentityProvider and creates it.  Returns the server's representation of the lDAPIdentityProvider, and an error, if there is any.
-func (c *lDAPIdentityProviders) Create(lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) Create(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.CreateOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Post().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(lDAPIdentityProvider).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Update takes the representation of a lDAPIdentityProvider and updates it. Returns the server's representation of the lDAPIdentityProvider, and an error, if there is any.
-func (c *lDAPIdentityProviders) Update(lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) Update(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
 		Name(lDAPIdentityProvider.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(lDAPIdentityProvider).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-
-func (c *lDAPIdentityProviders) UpdateStatus(lDAPIdentityProvider *v1alpha1.LDAPIdentityProvider) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) UpdateStatus(ctx context.Context, lDAPIdentityProvider *v1beta1.LDAPIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
 		Name(lDAPIdentityProvider.Name).
 		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(lDAPIdentityProvider).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Delete takes name of the lDAPIdentityProvider and deletes it. Returns an error if one occurs.
-func (c *lDAPIdentityProviders) Delete(name string, options *v1.DeleteOptions) error {
+func (c *lDAPIdentityProviders) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
 		Name(name).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *lDAPIdentityProviders) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+func (c *lDAPIdentityProviders) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	var timeout time.Duration
-	if listOptions.TimeoutSeconds != nil {
-		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
-		VersionedParams(&listOptions, scheme.ParameterCodec).
+		VersionedParams(&listOpts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // Patch applies the patch and returns the patched lDAPIdentityProvider.
-func (c *lDAPIdentityProviders) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.LDAPIdentityProvider, err error) {
-	result = &v1alpha1.LDAPIdentityProvider{}
+func (c *lDAPIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.LDAPIdentityProvider, err error) {
+	result = &v1beta1.LDAPIdentityProvider{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
 		Resource("ldapidentityproviders").
-		SubResource(subresources...).
 		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(data).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
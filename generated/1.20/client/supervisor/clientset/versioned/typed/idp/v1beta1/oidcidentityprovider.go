@@ -3,13 +3,14 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
+	"context"
 	"time"
 
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/idp/v1alpha1"
-	scheme "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
+	scheme "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -24,15 +25,15 @@ type OIDCIdentityProvidersGetter interface {
 
 // OIDCIdentityProviderInterface has methods to work with OIDCIdentityProvider resources.
 type OIDCIdentityProviderInterface interface {
-	Create(*v1alpha1.OIDCIdentityProvider) (*v1alpha1.OIDCIdentityProvider, error)
-	Update(*v1alpha1.OIDCIdentityProvider) (*v1alpha1.OIDCIdentityProvider, error)
-	UpdateStatus(*v1alpha1.OIDCIdentityProvider) (*v1alpha1.OIDCIdentityProvider, error)
-	Delete(name string, options *v1.DeleteOptions) error
-	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
-	Get(name string, options v1.GetOptions) (*v1alpha1.OIDCIdentityProvider, error)
-	List(opts v1.ListOptions) (*v1alpha1.OIDCIdentityProviderList, error)
-	Watch(opts v1.ListOptions) (watch.Interface, error)
-	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.OIDCIdentityProvider, err error)
+	Create(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.CreateOptions) (*v1beta1.OIDCIdentityProvider, error)
+	Update(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (*v1beta1.OIDCIdentityProvider, error)
+	UpdateStatus(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (*v1beta1.OIDCIdentityProvider, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.OIDCIdentityProvider, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.OIDCIdentityProviderList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.OIDCIdentityProvider, err error)
 	OIDCIdentityProviderExpansion
 }
 
@@ -43,7 +44,7 @@ type oIDCIdentityProviders struct {
 }
 
 // newOIDCIdentityProviders returns a OIDCIdentityProviders
-func newOIDCIdentityProviders(c *IDPV1alpha1Client, namespace string) *oIDCIdentityProviders {
+func newOIDCIdentityProviders(c *IDPV1beta1Client, namespace string) *oIDCIdentityProviders {
 	return &oIDCIdentityProviders{
 		client: c.RESTClient(),
 		ns:     namespace,
@@ -51,37 +52,37 @@ func newOIDCIdentityProviders(c *IDPV1alpha1Client, namespace string) *oIDCIdent
 }
 
 // Get takes name of the oIDCIdentityProvider, and returns the corresponding oIDCIdentityProvider object, and an error if there is any.
-func (c *oIDCIdentityProviders) Get(name string, options v1.GetOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // List takes label and field selectors, and returns the list of OIDCIdentityProviders that match those selectors.
-func (c *oIDCIdentityProviders) List(opts v1.ListOptions) (result *v1alpha1.OIDCIdentityProviderList, err error) {
+func (c *oIDCIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.OIDCIdentityProviderList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha1.OIDCIdentityProviderList{}
+	result = &v1beta1.OIDCIdentityProviderList{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Watch returns a watch.Interface that watches the requested oIDCIdentityProviders.
-func (c *oIDCIdentityProviders) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *oIDCIdentityProviders) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -92,87 +93,90 @@ func (c *oIDCIdentityProviders) Watch(opts v1.ListOptions) (watch.Interface, err
 		Resource("oidcidentityproviders").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Watch()
+		Watch(ctx)
 }
 
 // Create takes the representation of a oIDCIdentityProvider and creates it.  Returns the server's representation of the oIDCIdentityProvider, and an error, if there is any.
-func (c *oIDCIdentityProviders) Create(oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) Create(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.CreateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Post().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(oIDCIdentityProvider).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Update takes the representation of a oIDCIdentityProvider and updates it. Returns the server's representation of the oIDCIdentityProvider, and an error, if there is any.
-func (c *oIDCIdentityProviders) Update(oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) Update(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
 		Name(oIDCIdentityProvider.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(oIDCIdentityProvider).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-
-func (c *oIDCIdentityProviders) UpdateStatus(oIDCIdentityProvider *v1alpha1.OIDCIdentityProvider) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) UpdateStatus(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
 		Name(oIDCIdentityProvider.Name).
 		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(oIDCIdentityProvider).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Delete takes name of the oIDCIdentityProvider and deletes it. Returns an error if one occurs.
-func (c *oIDCIdentityProviders) Delete(name string, options *v1.DeleteOptions) error {
+func (c *oIDCIdentityProviders) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
 		Name(name).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *oIDCIdentityProviders) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+func (c *oIDCIdentityProviders) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	var timeout time.Duration
-	if listOptions.TimeoutSeconds != nil {
-		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
-		VersionedParams(&listOptions, scheme.ParameterCodec).
+		VersionedParams(&listOpts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // Patch applies the patch and returns the patched oIDCIdentityProvider.
-func (c *oIDCIdentityProviders) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.OIDCIdentityProvider, err error) {
-	result = &v1alpha1.OIDCIdentityProvider{}
+func (c *oIDCIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.OIDCIdentityProvider, err error) {
+	result = &v1beta1.OIDCIdentityProvider{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
 		Resource("oidcidentityproviders").
-		SubResource(subresources...).
 		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(data).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
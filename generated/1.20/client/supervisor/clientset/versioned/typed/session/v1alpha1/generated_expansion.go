@@ -5,4 +5,6 @@
 
 package v1alpha1
 
-type OIDCClientSecretRequestExpansion interface{}
+type DownstreamSessionExpansion interface{}
+
+type SecondFactorExpansion interface{}
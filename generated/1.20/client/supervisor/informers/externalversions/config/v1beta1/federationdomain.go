@@ -3,15 +3,16 @@
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
+	"context"
 	time "time"
 
-	configv1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/config/v1alpha1"
-	versioned "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned"
-	internalinterfaces "go.pinniped.dev/generated/1.17/client/supervisor/informers/externalversions/internalinterfaces"
-	v1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/listers/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/config/v1beta1"
+	versioned "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned"
+	internalinterfaces "go.pinniped.dev/generated/1.20/client/supervisor/informers/externalversions/internalinterfaces"
+	v1beta1 "go.pinniped.dev/generated/1.20/client/supervisor/listers/config/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -22,7 +23,7 @@ import (
 // FederationDomains.
 type FederationDomainInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.FederationDomainLister
+	Lister() v1beta1.FederationDomainLister
 }
 
 type federationDomainInformer struct {
@@ -48,16 +49,16 @@ func NewFilteredFederationDomainInformer(client versioned.Interface, namespace s
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.ConfigV1alpha1().FederationDomains(namespace).List(options)
+				return client.ConfigV1beta1().FederationDomains(namespace).List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.ConfigV1alpha1().FederationDomains(namespace).Watch(options)
+				return client.ConfigV1beta1().FederationDomains(namespace).Watch(context.TODO(), options)
 			},
 		},
-		&configv1alpha1.FederationDomain{},
+		&configv1beta1.FederationDomain{},
 		resyncPeriod,
 		indexers,
 	)
@@ -68,9 +69,9 @@ func (f *federationDomainInformer) defaultInformer(client versioned.Interface, r
 }
 
 func (f *federationDomainInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&configv1alpha1.FederationDomain{}, f.defaultInformer)
+	return f.factory.InformerFor(&configv1beta1.FederationDomain{}, f.defaultInformer)
 }
 
-func (f *federationDomainInformer) Lister() v1alpha1.FederationDomainLister {
-	return v1alpha1.NewFederationDomainLister(f.Informer().GetIndexer())
+func (f *federationDomainInformer) Lister() v1beta1.FederationDomainLister {
+	return v1beta1.NewFederationDomainLister(f.Informer().GetIndexer())
 }
@@ -3,15 +3,16 @@
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
+	"context"
 	time "time"
 
-	configv1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/config/v1alpha1"
-	versioned "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned"
-	internalinterfaces "go.pinniped.dev/generated/1.17/client/supervisor/informers/externalversions/internalinterfaces"
-	v1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/listers/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/config/v1beta1"
+	versioned "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned"
+	internalinterfaces "go.pinniped.dev/generated/1.20/client/supervisor/informers/externalversions/internalinterfaces"
+	v1beta1 "go.pinniped.dev/generated/1.20/client/supervisor/listers/config/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -22,7 +23,7 @@ import (
 // OIDCClients.
 type OIDCClientInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.OIDCClientLister
+	Lister() v1beta1.OIDCClientLister
 }
 
 type oIDCClientInformer struct {
@@ -48,16 +49,16 @@ func NewFilteredOIDCClientInformer(client versioned.Interface, namespace string,
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.ConfigV1alpha1().OIDCClients(namespace).List(options)
+				return client.ConfigV1beta1().OIDCClients(namespace).List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.ConfigV1alpha1().OIDCClients(namespace).Watch(options)
+				return client.ConfigV1beta1().OIDCClients(namespace).Watch(context.TODO(), options)
 			},
 		},
-		&configv1alpha1.OIDCClient{},
+		&configv1beta1.OIDCClient{},
 		resyncPeriod,
 		indexers,
 	)
@@ -68,9 +69,9 @@ func (f *oIDCClientInformer) defaultInformer(client versioned.Interface, resyncP
 }
 
 func (f *oIDCClientInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&configv1alpha1.OIDCClient{}, f.defaultInformer)
+	return f.factory.InformerFor(&configv1beta1.OIDCClient{}, f.defaultInformer)
 }
 
-func (f *oIDCClientInformer) Lister() v1alpha1.OIDCClientLister {
-	return v1alpha1.NewOIDCClientLister(f.Informer().GetIndexer())
+func (f *oIDCClientInformer) Lister() v1beta1.OIDCClientLister {
+	return v1beta1.NewOIDCClientLister(f.Informer().GetIndexer())
 }
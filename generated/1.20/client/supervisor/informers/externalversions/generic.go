@@ -9,7 +9,9 @@ import (
 	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.20/apis/supervisor/config/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/config/v1beta1"
 	idpv1alpha1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1alpha1"
+	idpv1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -45,6 +47,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Config().V1alpha1().FederationDomains().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("oidcclients"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Config().V1alpha1().OIDCClients().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("supervisorconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Config().V1alpha1().SupervisorConfigs().Informer()}, nil
+
+		// Group=config.supervisor.pinniped.dev, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("federationdomains"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Config().V1beta1().FederationDomains().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("oidcclients"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Config().V1beta1().OIDCClients().Informer()}, nil
 
 		// Group=idp.supervisor.pinniped.dev, Version=v1alpha1
 	case idpv1alpha1.SchemeGroupVersion.WithResource("activedirectoryidentityproviders"):
@@ -54,6 +64,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case idpv1alpha1.SchemeGroupVersion.WithResource("oidcidentityproviders"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.IDP().V1alpha1().OIDCIdentityProviders().Informer()}, nil
 
+		// Group=idp.supervisor.pinniped.dev, Version=v1beta1
+	case idpv1beta1.SchemeGroupVersion.WithResource("activedirectoryidentityproviders"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.IDP().V1beta1().ActiveDirectoryIdentityProviders().Informer()}, nil
+	case idpv1beta1.SchemeGroupVersion.WithResource("ldapidentityproviders"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.IDP().V1beta1().LDAPIdentityProviders().Informer()}, nil
+	case idpv1beta1.SchemeGroupVersion.WithResource("oidcidentityproviders"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.IDP().V1beta1().OIDCIdentityProviders().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -3,15 +3,16 @@
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
+	"context"
 	time "time"
 
-	idpv1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/idp/v1alpha1"
-	versioned "go.pinniped.dev/generated/1.17/client/supervisor/clientset/versioned"
-	internalinterfaces "go.pinniped.dev/generated/1.17/client/supervisor/informers/externalversions/internalinterfaces"
-	v1alpha1 "go.pinniped.dev/generated/1.17/client/supervisor/listers/idp/v1alpha1"
+	idpv1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
+	versioned "go.pinniped.dev/generated/1.20/client/supervisor/clientset/versioned"
+	internalinterfaces "go.pinniped.dev/generated/1.20/client/supervisor/informers/externalversions/internalinterfaces"
+	v1beta1 "go.pinniped.dev/generated/1.20/client/supervisor/listers/idp/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -22,7 +23,7 @@ import (
 // OIDCIdentityProviders.
 type OIDCIdentityProviderInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.OIDCIdentityProviderLister
+	Lister() v1beta1.OIDCIdentityProviderLister
 }
 
 type oIDCIdentityProviderInformer struct {
@@ -48,16 +49,16 @@ func NewFilteredOIDCIdentityProviderInformer(client versioned.Interface, namespa
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.IDPV1alpha1().OIDCIdentityProviders(namespace).List(options)
+				return client.IDPV1beta1().OIDCIdentityProviders(namespace).List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.IDPV1alpha1().OIDCIdentityProviders(namespace).Watch(options)
+				return client.IDPV1beta1().OIDCIdentityProviders(namespace).Watch(context.TODO(), options)
 			},
 		},
-		&idpv1alpha1.OIDCIdentityProvider{},
+		&idpv1beta1.OIDCIdentityProvider{},
 		resyncPeriod,
 		indexers,
 	)
@@ -68,9 +69,9 @@ func (f *oIDCIdentityProviderInformer) defaultInformer(client versioned.Interfac
 }
 
 func (f *oIDCIdentityProviderInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&idpv1alpha1.OIDCIdentityProvider{}, f.defaultInformer)
+	return f.factory.InformerFor(&idpv1beta1.OIDCIdentityProvider{}, f.defaultInformer)
 }
 
-func (f *oIDCIdentityProviderInformer) Lister() v1alpha1.OIDCIdentityProviderLister {
-	return v1alpha1.NewOIDCIdentityProviderLister(f.Informer().GetIndexer())
+func (f *oIDCIdentityProviderInformer) Lister() v1beta1.OIDCIdentityProviderLister {
+	return v1beta1.NewOIDCIdentityProviderLister(f.Informer().GetIndexer())
 }
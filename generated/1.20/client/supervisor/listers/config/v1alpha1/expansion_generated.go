@@ -20,3 +20,7 @@ type OIDCClientListerExpansion interface{}
 // OIDCClientNamespaceListerExpansion allows custom methods to be added to
 // OIDCClientNamespaceLister.
 type OIDCClientNamespaceListerExpansion interface{}
+
+// SupervisorConfigListerExpansion allows custom methods to be added to
+// SupervisorConfigLister.
+type SupervisorConfigListerExpansion interface{}
@@ -3,7 +3,7 @@
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 // FederationDomainListerExpansion allows custom methods to be added to
 // FederationDomainLister.
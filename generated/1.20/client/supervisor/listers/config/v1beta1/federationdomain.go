@@ -3,19 +3,21 @@
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/supervisor/config/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/config/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 )
 
 // FederationDomainLister helps list FederationDomains.
+// All objects returned here must be treated as read-only.
 type FederationDomainLister interface {
 	// List lists all FederationDomains in the indexer.
-	List(selector labels.Selector) (ret []*v1alpha1.FederationDomain, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.FederationDomain, err error)
 	// FederationDomains returns an object that can list and get FederationDomains.
 	FederationDomains(namespace string) FederationDomainNamespaceLister
 	FederationDomainListerExpansion
@@ -32,9 +34,9 @@ func NewFederationDomainLister(indexer cache.Indexer) FederationDomainLister {
 }
 
 // List lists all FederationDomains in the indexer.
-func (s *federationDomainLister) List(selector labels.Selector) (ret []*v1alpha1.FederationDomain, err error) {
+func (s *federationDomainLister) List(selector labels.Selector) (ret []*v1beta1.FederationDomain, err error) {
 	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.FederationDomain))
+		ret = append(ret, m.(*v1beta1.FederationDomain))
 	})
 	return ret, err
 }
@@ -45,11 +47,14 @@ func (s *federationDomainLister) FederationDomains(namespace string) FederationD
 }
 
 // FederationDomainNamespaceLister helps list and get FederationDomains.
+// All objects returned here must be treated as read-only.
 type FederationDomainNamespaceLister interface {
 	// List lists all FederationDomains in the indexer for a given namespace.
-	List(selector labels.Selector) (ret []*v1alpha1.FederationDomain, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.FederationDomain, err error)
 	// Get retrieves the FederationDomain from the indexer for a given namespace and name.
-	Get(name string) (*v1alpha1.FederationDomain, error)
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.FederationDomain, error)
 	FederationDomainNamespaceListerExpansion
 }
 
@@ -61,21 +66,21 @@ type federationDomainNamespaceLister struct {
 }
 
 // List lists all FederationDomains in the indexer for a given namespace.
-func (s federationDomainNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.FederationDomain, err error) {
+func (s federationDomainNamespaceLister) List(selector labels.Selector) (ret []*v1beta1.FederationDomain, err error) {
 	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.FederationDomain))
+		ret = append(ret, m.(*v1beta1.FederationDomain))
 	})
 	return ret, err
 }
 
 // Get retrieves the FederationDomain from the indexer for a given namespace and name.
-func (s federationDomainNamespaceLister) Get(name string) (*v1alpha1.FederationDomain, error) {
+func (s federationDomainNamespaceLister) Get(name string) (*v1beta1.FederationDomain, error) {
 	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewNotFound(v1alpha1.Resource("federationdomain"), name)
+		return nil, errors.NewNotFound(v1beta1.Resource("federationdomain"), name)
 	}
-	return obj.(*v1alpha1.FederationDomain), nil
+	return obj.(*v1beta1.FederationDomain), nil
 }
@@ -3,19 +3,21 @@
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/idp/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 )
 
 // ActiveDirectoryIdentityProviderLister helps list ActiveDirectoryIdentityProviders.
+// All objects returned here must be treated as read-only.
 type ActiveDirectoryIdentityProviderLister interface {
 	// List lists all ActiveDirectoryIdentityProviders in the indexer.
-	List(selector labels.Selector) (ret []*v1alpha1.ActiveDirectoryIdentityProvider, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.ActiveDirectoryIdentityProvider, err error)
 	// ActiveDirectoryIdentityProviders returns an object that can list and get ActiveDirectoryIdentityProviders.
 	ActiveDirectoryIdentityProviders(namespace string) ActiveDirectoryIdentityProviderNamespaceLister
 	ActiveDirectoryIdentityProviderListerExpansion
@@ -32,9 +34,9 @@ func NewActiveDirectoryIdentityProviderLister(indexer cache.Indexer) ActiveDirec
 }
 
 // List lists all ActiveDirectoryIdentityProviders in the indexer.
-func (s *activeDirectoryIdentityProviderLister) List(selector labels.Selector) (ret []*v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+func (s *activeDirectoryIdentityProviderLister) List(selector labels.Selector) (ret []*v1beta1.ActiveDirectoryIdentityProvider, err error) {
 	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.ActiveDirectoryIdentityProvider))
+		ret = append(ret, m.(*v1beta1.ActiveDirectoryIdentityProvider))
 	})
 	return ret, err
 }
@@ -45,11 +47,14 @@ func (s *activeDirectoryIdentityProviderLister) ActiveDirectoryIdentityProviders
 }
 
 // ActiveDirectoryIdentityProviderNamespaceLister helps list and get ActiveDirectoryIdentityProviders.
+// All objects returned here must be treated as read-only.
 type ActiveDirectoryIdentityProviderNamespaceLister interface {
 	// List lists all ActiveDirectoryIdentityProviders in the indexer for a given namespace.
-	List(selector labels.Selector) (ret []*v1alpha1.ActiveDirectoryIdentityProvider, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.ActiveDirectoryIdentityProvider, err error)
 	// Get retrieves the ActiveDirectoryIdentityProvider from the indexer for a given namespace and name.
-	Get(name string) (*v1alpha1.ActiveDirectoryIdentityProvider, error)
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.ActiveDirectoryIdentityProvider, error)
 	ActiveDirectoryIdentityProviderNamespaceListerExpansion
 }
 
@@ -61,21 +66,21 @@ type activeDirectoryIdentityProviderNamespaceLister struct {
 }
 
 // List lists all ActiveDirectoryIdentityProviders in the indexer for a given namespace.
-func (s activeDirectoryIdentityProviderNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+func (s activeDirectoryIdentityProviderNamespaceLister) List(selector labels.Selector) (ret []*v1beta1.ActiveDirectoryIdentityProvider, err error) {
 	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.ActiveDirectoryIdentityProvider))
+		ret = append(ret, m.(*v1beta1.ActiveDirectoryIdentityProvider))
 	})
 	return ret, err
 }
 
 // Get retrieves the ActiveDirectoryIdentityProvider from the indexer for a given namespace and name.
-func (s activeDirectoryIdentityProviderNamespaceLister) Get(name string) (*v1alpha1.ActiveDirectoryIdentityProvider, error) {
+func (s activeDirectoryIdentityProviderNamespaceLister) Get(name string) (*v1beta1.ActiveDirectoryIdentityProvider, error) {
 	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewNotFound(v1alpha1.Resource("activedirectoryidentityprovider"), name)
+		return nil, errors.NewNotFound(v1beta1.Resource("activedirectoryidentityprovider"), name)
 	}
-	return obj.(*v1alpha1.ActiveDirectoryIdentityProvider), nil
+	return obj.(*v1beta1.ActiveDirectoryIdentityProvider), nil
 }
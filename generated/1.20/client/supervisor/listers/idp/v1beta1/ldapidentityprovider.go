@@ -3,19 +3,21 @@
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.18/apis/supervisor/idp/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.20/apis/supervisor/idp/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 )
 
 // LDAPIdentityProviderLister helps list LDAPIdentityProviders.
+// All objects returned here must be treated as read-only.
 type LDAPIdentityProviderLister interface {
 	// List lists all LDAPIdentityProviders in the indexer.
-	List(selector labels.Selector) (ret []*v1alpha1.LDAPIdentityProvider, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.LDAPIdentityProvider, err error)
 	// LDAPIdentityProviders returns an object that can list and get LDAPIdentityProviders.
 	LDAPIdentityProviders(namespace string) LDAPIdentityProviderNamespaceLister
 	LDAPIdentityProviderListerExpansion
@@ -32,9 +34,9 @@ func NewLDAPIdentityProviderLister(indexer cache.Indexer) LDAPIdentityProviderLi
 }
 
 // List lists all LDAPIdentityProviders in the indexer.
-func (s *lDAPIdentityProviderLister) List(selector labels.Selector) (ret []*v1alpha1.LDAPIdentityProvider, err error) {
+func (s *lDAPIdentityProviderLister) List(selector labels.Selector) (ret []*v1beta1.LDAPIdentityProvider, err error) {
 	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.LDAPIdentityProvider))
+		ret = append(ret, m.(*v1beta1.LDAPIdentityProvider))
 	})
 	return ret, err
 }
@@ -45,11 +47,14 @@ func (s *lDAPIdentityProviderLister) LDAPIdentityProviders(namespace string) LDA
 }
 
 // LDAPIdentityProviderNamespaceLister helps list and get LDAPIdentityProviders.
+// All objects returned here must be treated as read-only.
 type LDAPIdentityProviderNamespaceLister interface {
 	// List lists all LDAPIdentityProviders in the indexer for a given namespace.
-	List(selector labels.Selector) (ret []*v1alpha1.LDAPIdentityProvider, err error)
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.LDAPIdentityProvider, err error)
 	// Get retrieves the LDAPIdentityProvider from the indexer for a given namespace and name.
-	Get(name string) (*v1alpha1.LDAPIdentityProvider, error)
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.LDAPIdentityProvider, error)
 	LDAPIdentityProviderNamespaceListerExpansion
 }
 
@@ -61,21 +66,21 @@ type lDAPIdentityProviderNamespaceLister struct {
 }
 
 // List lists all LDAPIdentityProviders in the indexer for a given namespace.
-func (s lDAPIdentityProviderNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.LDAPIdentityProvider, err error) {
+func (s lDAPIdentityProviderNamespaceLister) List(selector labels.Selector) (ret []*v1beta1.LDAPIdentityProvider, err error) {
 	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
-		ret = append(ret, m.(*v1alpha1.LDAPIdentityProvider))
+		ret = append(ret, m.(*v1beta1.LDAPIdentityProvider))
 	})
 	return ret, err
 }
 
 // Get retrieves the LDAPIdentityProvider from the indexer for a given namespace and name.
-func (s lDAPIdentityProviderNamespaceLister) Get(name string) (*v1alpha1.LDAPIdentityProvider, error) {
+func (s lDAPIdentityProviderNamespaceLister) Get(name string) (*v1beta1.LDAPIdentityProvider, error) {
 	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.NewNotFound(v1alpha1.Resource("ldapidentityprovider"), name)
+		return nil, errors.NewNotFound(v1beta1.Resource("ldapidentityprovider"), name)
 	}
-	return obj.(*v1alpha1.LDAPIdentityProvider), nil
+	return obj.(*v1beta1.LDAPIdentityProvider), nil
 }
@@ -12,6 +12,7 @@ import (
 	unsafe "unsafe"
 
 	clientsecret "go.pinniped.dev/generated/1.21/apis/supervisor/clientsecret"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -23,6 +24,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*OIDCClientSecret)(nil), (*clientsecret.OIDCClientSecret)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_OIDCClientSecret_To_clientsecret_OIDCClientSecret(a.(*OIDCClientSecret), b.(*clientsecret.OIDCClientSecret), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*clientsecret.OIDCClientSecret)(nil), (*OIDCClientSecret)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_clientsecret_OIDCClientSecret_To_v1alpha1_OIDCClientSecret(a.(*clientsecret.OIDCClientSecret), b.(*OIDCClientSecret), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*OIDCClientSecretRequest)(nil), (*clientsecret.OIDCClientSecretRequest)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_OIDCClientSecretRequest_To_clientsecret_OIDCClientSecretRequest(a.(*OIDCClientSecretRequest), b.(*clientsecret.OIDCClientSecretRequest), scope)
 	}); err != nil {
@@ -66,6 +77,32 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1alpha1_OIDCClientSecret_To_clientsecret_OIDCClientSecret(in *OIDCClientSecret, out *clientsecret.OIDCClientSecret, s conversion.Scope) error {
+	out.ID = in.ID
+	out.CreationTimestamp = (*v1.Time)(unsafe.Pointer(in.CreationTimestamp))
+	out.LastUsedTimestamp = (*v1.Time)(unsafe.Pointer(in.LastUsedTimestamp))
+	out.ExpirationTimestamp = (*v1.Time)(unsafe.Pointer(in.ExpirationTimestamp))
+	return nil
+}
+
+// Convert_v1alpha1_OIDCClientSecret_To_clientsecret_OIDCClientSecret is an autogenerated conversion function.
+func Convert_v1alpha1_OIDCClientSecret_To_clientsecret_OIDCClientSecret(in *OIDCClientSecret, out *clientsecret.OIDCClientSecret, s conversion.Scope) error {
+	return autoConvert_v1alpha1_OIDCClientSecret_To_clientsecret_OIDCClientSecret(in, out, s)
+}
+
+func autoConvert_clientsecret_OIDCClientSecret_To_v1alpha1_OIDCClientSecret(in *clientsecret.OIDCClientSecret, out *OIDCClientSecret, s conversion.Scope) error {
+	out.ID = in.ID
+	out.CreationTimestamp = (*v1.Time)(unsafe.Pointer(in.CreationTimestamp))
+	out.LastUsedTimestamp = (*v1.Time)(unsafe.Pointer(in.LastUsedTimestamp))
+	out.ExpirationTimestamp = (*v1.Time)(unsafe.Pointer(in.ExpirationTimestamp))
+	return nil
+}
+
+// Convert_clientsecret_OIDCClientSecret_To_v1alpha1_OIDCClientSecret is an autogenerated conversion function.
+func Convert_clientsecret_OIDCClientSecret_To_v1alpha1_OIDCClientSecret(in *clientsecret.OIDCClientSecret, out *OIDCClientSecret, s conversion.Scope) error {
+	return autoConvert_clientsecret_OIDCClientSecret_To_v1alpha1_OIDCClientSecret(in, out, s)
+}
+
 func autoConvert_v1alpha1_OIDCClientSecretRequest_To_clientsecret_OIDCClientSecretRequest(in *OIDCClientSecretRequest, out *clientsecret.OIDCClientSecretRequest, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_OIDCClientSecretRequestSpec_To_clientsecret_OIDCClientSecretRequestSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -123,6 +160,8 @@ func Convert_clientsecret_OIDCClientSecretRequestList_To_v1alpha1_OIDCClientSecr
 func autoConvert_v1alpha1_OIDCClientSecretRequestSpec_To_clientsecret_OIDCClientSecretRequestSpec(in *OIDCClientSecretRequestSpec, out *clientsecret.OIDCClientSecretRequestSpec, s conversion.Scope) error {
 	out.GenerateNewSecret = in.GenerateNewSecret
 	out.RevokeOldSecrets = in.RevokeOldSecrets
+	out.RevokeSecrets = *(*[]string)(unsafe.Pointer(&in.RevokeSecrets))
+	out.ExpiresIn = (*v1.Duration)(unsafe.Pointer(in.ExpiresIn))
 	return nil
 }
 
@@ -134,6 +173,8 @@ func Convert_v1alpha1_OIDCClientSecretRequestSpec_To_clientsecret_OIDCClientSecr
 func autoConvert_clientsecret_OIDCClientSecretRequestSpec_To_v1alpha1_OIDCClientSecretRequestSpec(in *clientsecret.OIDCClientSecretRequestSpec, out *OIDCClientSecretRequestSpec, s conversion.Scope) error {
 	out.GenerateNewSecret = in.GenerateNewSecret
 	out.RevokeOldSecrets = in.RevokeOldSecrets
+	out.RevokeSecrets = *(*[]string)(unsafe.Pointer(&in.RevokeSecrets))
+	out.ExpiresIn = (*v1.Duration)(unsafe.Pointer(in.ExpiresIn))
 	return nil
 }
 
@@ -145,6 +186,7 @@ func Convert_clientsecret_OIDCClientSecretRequestSpec_To_v1alpha1_OIDCClientSecr
 func autoConvert_v1alpha1_OIDCClientSecretRequestStatus_To_clientsecret_OIDCClientSecretRequestStatus(in *OIDCClientSecretRequestStatus, out *clientsecret.OIDCClientSecretRequestStatus, s conversion.Scope) error {
 	out.GeneratedSecret = in.GeneratedSecret
 	out.TotalClientSecrets = in.TotalClientSecrets
+	out.Secrets = *(*[]clientsecret.OIDCClientSecret)(unsafe.Pointer(&in.Secrets))
 	return nil
 }
 
@@ -156,6 +198,7 @@ func Convert_v1alpha1_OIDCClientSecretRequestStatus_To_clientsecret_OIDCClientSe
 func autoConvert_clientsecret_OIDCClientSecretRequestStatus_To_v1alpha1_OIDCClientSecretRequestStatus(in *clientsecret.OIDCClientSecretRequestStatus, out *OIDCClientSecretRequestStatus, s conversion.Scope) error {
 	out.GeneratedSecret = in.GeneratedSecret
 	out.TotalClientSecrets = in.TotalClientSecrets
+	out.Secrets = *(*[]OIDCClientSecret)(unsafe.Pointer(&in.Secrets))
 	return nil
 }
 
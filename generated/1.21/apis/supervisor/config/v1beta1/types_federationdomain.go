@@ -0,0 +1,348 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:Enum=Success;Duplicate;Invalid;SameIssuerHostMustUseSameSecret
+type FederationDomainStatusCondition string
+
+const (
+	SuccessFederationDomainStatusCondition                         = FederationDomainStatusCondition("Success")
+	DuplicateFederationDomainStatusCondition                       = FederationDomainStatusCondition("Duplicate")
+	SameIssuerHostMustUseSameSecretFederationDomainStatusCondition = FederationDomainStatusCondition("SameIssuerHostMustUseSameSecret")
+	InvalidFederationDomainStatusCondition                         = FederationDomainStatusCondition("Invalid")
+)
+
+// FederationDomainLoginWeekday is a day of the week on which a FederationDomainLoginTimeWindow applies.
+//
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type FederationDomainLoginWeekday string
+
+// FederationDomainLoginTimeWindow is a recurring period of time during which logins are allowed.
+type FederationDomainLoginTimeWindow struct {
+	// Days is an optional list of the days of the week on which this window starts, e.g. "Monday". When empty, the
+	// window starts on every day.
+	//
+	// +optional
+	// +listType=set
+	Days []FederationDomainLoginWeekday `json:"days,omitempty"`
+
+	// Start is the time of day at which this window starts, in the 24-hour format HH:MM, e.g. "08:00".
+	//
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End is the time of day at which this window ends, in the 24-hour format HH:MM, e.g. "18:00". Logins at End
+	// itself are not allowed. When End is not after Start, the window ends on the next day, e.g. a window from
+	// "22:00" to "06:00" allows logins during the night.
+	//
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+
+	// TimeZone is the optional name of the time zone of Days, Start, and End in the IANA Time Zone database, e.g.
+	// "Europe/Berlin". Defaults to "UTC".
+	//
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// FederationDomainLoginPolicy is a struct that describes which logins to a FederationDomain are allowed after the
+// user has authenticated with the upstream identity provider.
+type FederationDomainLoginPolicy struct {
+	// AllowedEmailDomains is an optional list of the domains of the users who are allowed to log in, e.g.
+	// "example.com". The domain of a user is the part of their downstream username after the last "@", and is
+	// compared without regard to case. Users whose username has no domain are not allowed to log in.
+	//
+	// +optional
+	// +listType=set
+	AllowedEmailDomains []string `json:"allowedEmailDomains,omitempty"`
+
+	// AllowedGroups is an optional list of downstream group names. Only the users who are a member of at least one
+	// of these groups are allowed to log in.
+	//
+	// +optional
+	// +listType=set
+	AllowedGroups []string `json:"allowedGroups,omitempty"`
+
+	// AllowedSourceCIDRs is an optional list of IP address ranges in CIDR notation, e.g. "10.0.0.0/8". Only the
+	// logins from clients whose IP address is in at least one of these ranges are allowed. The IP address of a
+	// client is taken from the forwarding headers of trusted proxies when the Supervisor is configured to trust them.
+	//
+	// +optional
+	// +listType=set
+	AllowedSourceCIDRs []string `json:"allowedSourceCIDRs,omitempty"`
+
+	// TimeWindows is an optional list of the periods of time during which logins are allowed. Logins are only
+	// allowed when they happen during at least one of these windows.
+	//
+	// +optional
+	TimeWindows []FederationDomainLoginTimeWindow `json:"timeWindows,omitempty"`
+}
+
+// FederationDomainTLSSpec is a struct that describes the TLS configuration for an OIDC Provider.
+type FederationDomainTLSSpec struct {
+	// SecretName is an optional name of a Secret in the same namespace, of type `kubernetes.io/tls`, which contains
+	// the TLS serving certificate for the HTTPS endpoints served by this FederationDomain. When provided, the TLS Secret
+	// named here must contain keys named `tls.crt` and `tls.key` that contain the certificate and private key to use
+	// for TLS.
+	//
+	// Server Name Indication (SNI) is an extension to the Transport Layer Security (TLS) supported by all major browsers.
+	//
+	// SecretName is required if you would like to use different TLS certificates for issuers of different hostnames.
+	// SNI requests do not include port numbers, so all issuers with the same DNS hostname must use the same
+	// SecretName value even if they have different port numbers.
+	//
+	// SecretName is not required when you would like to use only the HTTP endpoints (e.g. when the HTTP listener is
+	// configured to listen on loopback interfaces or UNIX domain sockets for traffic from a service mesh sidecar).
+	// It is also not required when you would like all requests to this OIDC Provider's HTTPS endpoints to
+	// use the default TLS certificate, which is configured elsewhere.
+	//
+	// When your Issuer URL's host is an IP address, then this field is ignored. SNI does not work for IP addresses.
+	//
+	// The Secret may be managed by another tool, e.g. it may be the Secret named by the spec.secretName of a
+	// cert-manager Certificate. Changes to the Secret, such as when its certificate is renewed, are loaded
+	// automatically without restarting the Supervisor. The TLSCertificateValid condition in the status of this
+	// FederationDomain reports whether the certificate is currently valid for the hosts of this FederationDomain.
+	//
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// FederationDomainTOTPEnforcement enumerates the users of a FederationDomain who must enter a code from an
+// authenticator app after logging in with their password.
+//
+// +kubebuilder:validation:Enum=IfEnrolled;Required
+type FederationDomainTOTPEnforcement string
+
+const (
+	// FederationDomainTOTPEnforcementIfEnrolled requires a code from the users who have enrolled an authenticator
+	// app, and offers the other users to enroll one after they have logged in with their password.
+	FederationDomainTOTPEnforcementIfEnrolled = FederationDomainTOTPEnforcement("IfEnrolled")
+
+	// FederationDomainTOTPEnforcementRequired requires a code from all users. The users who have not enrolled an
+	// authenticator app yet must enroll one after they have logged in with their password.
+	FederationDomainTOTPEnforcementRequired = FederationDomainTOTPEnforcement("Required")
+)
+
+// FederationDomainTOTPSpec is a struct that describes the TOTP second factor of a FederationDomain.
+type FederationDomainTOTPSpec struct {
+	// Enforcement determines which users must enter a time-based one-time password (TOTP) from an authenticator app
+	// after logging in with their password. Allowed values are "IfEnrolled" and "Required".
+	//
+	// When "IfEnrolled", the users who have enrolled an authenticator app must enter a code, and the other users are
+	// offered to enroll one after logging in. When "Required", all users must enter a code, and the users who have
+	// not enrolled an authenticator app yet must enroll one after logging in.
+	Enforcement FederationDomainTOTPEnforcement `json:"enforcement"`
+}
+
+// FederationDomainWebAuthnEnforcement enumerates the users of a FederationDomain who must verify a WebAuthn
+// credential after logging in with their password.
+//
+// +kubebuilder:validation:Enum=IfEnrolled;Required
+type FederationDomainWebAuthnEnforcement string
+
+const (
+	// FederationDomainWebAuthnEnforcementIfEnrolled requires a WebAuthn credential from the users who have enrolled
+	// one, and offers the other users to enroll one after they have logged in with their password.
+	FederationDomainWebAuthnEnforcementIfEnrolled = FederationDomainWebAuthnEnforcement("IfEnrolled")
+
+	// FederationDomainWebAuthnEnforcementRequired requires a WebAuthn credential from all users. The users who have
+	// not enrolled one yet must enroll one after they have logged in with their password.
+	FederationDomainWebAuthnEnforcementRequired = FederationDomainWebAuthnEnforcement("Required")
+)
+
+// FederationDomainWebAuthnSpec is a struct that describes the WebAuthn second factor of a FederationDomain.
+type FederationDomainWebAuthnSpec struct {
+	// Enforcement determines which users must verify a WebAuthn credential, e.g. a security key or a platform
+	// authenticator, after logging in with their password. Allowed values are "IfEnrolled" and "Required".
+	//
+	// When "IfEnrolled", the users who have enrolled a credential must verify it, and the other users are offered
+	// to enroll one after logging in. When "Required", all users must verify a credential, and the users who have
+	// not enrolled one yet must enroll one after logging in.
+	Enforcement FederationDomainWebAuthnEnforcement `json:"enforcement"`
+}
+
+// FederationDomainSpec is a struct that describes an OIDC Provider.
+type FederationDomainSpec struct {
+	// Issuer is the OIDC Provider's issuer, per the OIDC Discovery Metadata document, as well as the
+	// identifier that it will use for the iss claim in issued JWTs. This field will also be used as
+	// the base URL for any endpoints used by the OIDC Provider (e.g., if your issuer is
+	// https://example.com/foo, then your authorization endpoint will look like
+	// https://example.com/foo/some/path/to/auth/endpoint).
+	//
+	// See
+	// https://openid.net/specs/openid-connect-discovery-1_0.html#rfc.section.3 for more information.
+	// +kubebuilder:validation:MinLength=1
+	Issuer string `json:"issuer"`
+
+	// AliasHosts is an optional list of additional hosts at which this FederationDomain is also served, which can be
+	// useful when migrating to a new issuer hostname or when using split-horizon DNS. Each entry has the same format
+	// as the host of the Issuer URL, i.e. a DNS hostname or an IP address, optionally followed by a port number.
+	//
+	// Requests to an alias host are handled as if the issuer was the Issuer URL with its host replaced by the alias
+	// host, so the discovery document, the endpoint URLs, and the iss claim of issued ID tokens all use the alias host
+	// which was used by the client. All hosts of a FederationDomain share the same signing keys and TLS secretName.
+	// When using upstream OIDC identity providers, each alias host's callback URL must also be allowed as a
+	// redirect URI by the upstream provider.
+	//
+	// +optional
+	// +listType=set
+	AliasHosts []string `json:"aliasHosts,omitempty"`
+
+	// IssuerMigration optionally continues to serve the previous issuer URL of this FederationDomain for a grace period
+	// after its Issuer was changed, so that renaming an issuer does not force all users to log in again at the same time.
+	// Until AcceptUntil, the previous issuer is served at its own URL like an alias of the Issuer, with the same signing
+	// keys, sessions and TLS secretName, so the sessions which were started with the previous issuer can still be
+	// refreshed, and its ID tokens can still be validated. New logins should use the Issuer. When using upstream OIDC
+	// identity providers, the previous issuer's callback URL must stay allowed as a redirect URI by the upstream provider
+	// until AcceptUntil.
+	//
+	// +optional
+	IssuerMigration *FederationDomainIssuerMigration `json:"issuerMigration,omitempty"`
+
+	// AllowedCORSOrigins is an optional list of the origins of browser-based applications, e.g. single-page apps,
+	// which are allowed to make cross-origin requests to the discovery, JWKS, and token endpoints of this
+	// FederationDomain. Each entry is a scheme and host with an optional port, e.g. https://app.example.com.
+	// Wildcards are not supported. Cross-origin requests are never allowed to include credentials such as cookies.
+	//
+	// +optional
+	// +listType=set
+	AllowedCORSOrigins []string `json:"allowedCORSOrigins,omitempty"`
+
+	// TLS configures how this FederationDomain is served over Transport Layer Security (TLS).
+	// +optional
+	TLS *FederationDomainTLSSpec `json:"tls,omitempty"`
+
+	// WebAuthn optionally adds a WebAuthn second factor to the logins with the password of an LDAP or Active
+	// Directory identity provider, for organizations whose directory cannot enforce multi-factor authentication
+	// itself. After the password was verified, the browser-based login asks the user to verify a WebAuthn
+	// credential, e.g. a security key. The credentials which users enroll are stored by the Supervisor in Secrets in
+	// its namespace, and are bound to the hostname of the Issuer URL, so users must enroll separately for each of the
+	// AliasHosts which they use. Logins without a browser, i.e. the CLI-based password flow, are rejected for users
+	// who must verify a credential. Logins with OIDC identity providers are not affected.
+	//
+	// +optional
+	WebAuthn *FederationDomainWebAuthnSpec `json:"webAuthn,omitempty"`
+
+	// TOTP optionally adds a second factor of time-based one-time passwords (TOTP) from an authenticator app to the
+	// logins with the password of an LDAP or Active Directory identity provider, for users who have no security key.
+	// After the password was verified, the browser-based login asks the user to enter a code from their authenticator
+	// app, or to enroll the app by scanning a QR code. The TOTP secrets which users enroll are stored encrypted by the
+	// Supervisor in Secrets in its namespace, and can be used with all hosts of the FederationDomain. Logins without a
+	// browser, i.e. the CLI-based password flow, are rejected for users who must enter a code. Logins with OIDC
+	// identity providers are not affected. TOTP cannot be enabled together with WebAuthn.
+	//
+	// +optional
+	TOTP *FederationDomainTOTPSpec `json:"totp,omitempty"`
+
+	// LoginPolicy optionally restricts which users are allowed to log in to this FederationDomain, based on their
+	// email domain, their group memberships, the IP address of their client, or the time of the login. The policy is
+	// evaluated after the user has authenticated with the upstream identity provider, and all of its configured
+	// restrictions must be satisfied. Denied logins are rejected with an error page and are recorded in the audit
+	// log. Each login policy restriction which is not configured allows all logins.
+	//
+	// +optional
+	LoginPolicy *FederationDomainLoginPolicy `json:"loginPolicy,omitempty"`
+}
+
+// FederationDomainIssuerMigration describes a previous issuer of a FederationDomain which continues to be served
+// for a grace period.
+type FederationDomainIssuerMigration struct {
+	// PreviousIssuer is the issuer URL which was used by the FederationDomain before its Issuer was changed. It must
+	// be a valid issuer URL which is different from the Issuer and from the Issuer as seen from each of the AliasHosts.
+	// +kubebuilder:validation:MinLength=1
+	PreviousIssuer string `json:"previousIssuer"`
+
+	// AcceptUntil is the time after which the PreviousIssuer is no longer served. It should be far enough in the future
+	// for the sessions which were started with the previous issuer to end, and for the users to update their
+	// kubeconfigs to the new Issuer.
+	AcceptUntil metav1.Time `json:"acceptUntil"`
+}
+
+// FederationDomainSecrets holds information about this OIDC Provider's secrets.
+type FederationDomainSecrets struct {
+	// JWKS holds the name of the corev1.Secret in which this OIDC Provider's signing/verification keys are
+	// stored. If it is empty, then the signing/verification keys are either unknown or they don't
+	// exist.
+	// +optional
+	JWKS corev1.LocalObjectReference `json:"jwks,omitempty"`
+
+	// TokenSigningKey holds the name of the corev1.Secret in which this OIDC Provider's key for
+	// signing tokens is stored.
+	// +optional
+	TokenSigningKey corev1.LocalObjectReference `json:"tokenSigningKey,omitempty"`
+
+	// StateSigningKey holds the name of the corev1.Secret in which this OIDC Provider's key for
+	// signing state parameters is stored.
+	// +optional
+	StateSigningKey corev1.LocalObjectReference `json:"stateSigningKey,omitempty"`
+
+	// StateSigningKey holds the name of the corev1.Secret in which this OIDC Provider's key for
+	// encrypting state parameters is stored.
+	// +optional
+	StateEncryptionKey corev1.LocalObjectReference `json:"stateEncryptionKey,omitempty"`
+}
+
+// FederationDomainStatus is a struct that describes the actual state of an OIDC Provider.
+type FederationDomainStatus struct {
+	// Status holds an enum that describes the state of this OIDC Provider. Note that this Status can
+	// represent success or failure.
+	// +optional
+	Status FederationDomainStatusCondition `json:"status,omitempty"`
+
+	// Message provides human-readable details about the Status.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastUpdateTime holds the time at which the Status was last updated. It is a pointer to get
+	// around some undesirable behavior with respect to the empty metav1.Time value (see
+	// https://github.com/kubernetes/kubernetes/issues/86811).
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Secrets contains information about this OIDC Provider's secrets.
+	// +optional
+	Secrets FederationDomainSecrets `json:"secrets,omitempty"`
+
+	// Conditions represent the observations of a FederationDomain's current state, e.g. whether its TLS serving
+	// certificate is valid for its issuer host and alias hosts, and whether the certificate has expired.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// FederationDomain describes the configuration of an OIDC provider.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:categories=pinniped
+// +kubebuilder:printcolumn:name="Issuer",type=string,JSONPath=`.spec.issuer`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:subresource:status
+type FederationDomain struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec of the OIDC provider.
+	Spec FederationDomainSpec `json:"spec"`
+
+	// Status of the OIDC provider.
+	Status FederationDomainStatus `json:"status,omitempty"`
+}
+
+// List of FederationDomain objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type FederationDomainList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []FederationDomain `json:"items"`
+}
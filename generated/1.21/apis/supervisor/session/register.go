@@ -1,14 +1,14 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
-package identity
+package session
 
 import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-const GroupName = "identity.concierge.pinniped.dev"
+const GroupName = "session.supervisor.pinniped.dev"
 
 // SchemeGroupVersion is group version used to register these objects.
 var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: runtime.APIVersionInternal}
@@ -31,8 +31,10 @@ var (
 // Adds the list of known types to the given scheme.
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
-		&WhoAmIRequest{},
-		&WhoAmIRequestList{},
+		&DownstreamSession{},
+		&DownstreamSessionList{},
+		&SecondFactor{},
+		&SecondFactorList{},
 	)
 	return nil
 }
@@ -0,0 +1,53 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// JWTAuthenticatorSpecApplyConfiguration represents an declarative configuration of the JWTAuthenticatorSpec type for use
+// with apply.
+type JWTAuthenticatorSpecApplyConfiguration struct {
+	Issuer   *string                           `json:"issuer,omitempty"`
+	Audience *string                           `json:"audience,omitempty"`
+	Claims   *JWTTokenClaimsApplyConfiguration `json:"claims,omitempty"`
+	TLS      *TLSSpecApplyConfiguration        `json:"tls,omitempty"`
+}
+
+// JWTAuthenticatorSpecApplyConfiguration constructs an declarative configuration of the JWTAuthenticatorSpec type for use with
+// apply.
+func JWTAuthenticatorSpec() *JWTAuthenticatorSpecApplyConfiguration {
+	return &JWTAuthenticatorSpecApplyConfiguration{}
+}
+
+// WithIssuer sets the Issuer field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Issuer field is set to the value of the last call.
+func (b *JWTAuthenticatorSpecApplyConfiguration) WithIssuer(value string) *JWTAuthenticatorSpecApplyConfiguration {
+	b.Issuer = &value
+	return b
+}
+
+// WithAudience sets the Audience field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Audience field is set to the value of the last call.
+func (b *JWTAuthenticatorSpecApplyConfiguration) WithAudience(value string) *JWTAuthenticatorSpecApplyConfiguration {
+	b.Audience = &value
+	return b
+}
+
+// WithClaims sets the Claims field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Claims field is set to the value of the last call.
+func (b *JWTAuthenticatorSpecApplyConfiguration) WithClaims(value *JWTTokenClaimsApplyConfiguration) *JWTAuthenticatorSpecApplyConfiguration {
+	b.Claims = value
+	return b
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *JWTAuthenticatorSpecApplyConfiguration) WithTLS(value *TLSSpecApplyConfiguration) *JWTAuthenticatorSpecApplyConfiguration {
+	b.TLS = value
+	return b
+}
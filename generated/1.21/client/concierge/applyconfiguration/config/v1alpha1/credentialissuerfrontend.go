@@ -0,0 +1,48 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.21/apis/concierge/config/v1alpha1"
+)
+
+// CredentialIssuerFrontendApplyConfiguration represents an declarative configuration of the CredentialIssuerFrontend type for use
+// with apply.
+type CredentialIssuerFrontendApplyConfiguration struct {
+	Type                          *v1alpha1.FrontendType                           `json:"type,omitempty"`
+	TokenCredentialRequestAPIInfo *TokenCredentialRequestAPIInfoApplyConfiguration `json:"tokenCredentialRequestInfo,omitempty"`
+	ImpersonationProxyInfo        *ImpersonationProxyInfoApplyConfiguration        `json:"impersonationProxyInfo,omitempty"`
+}
+
+// CredentialIssuerFrontendApplyConfiguration constructs an declarative configuration of the CredentialIssuerFrontend type for use with
+// apply.
+func CredentialIssuerFrontend() *CredentialIssuerFrontendApplyConfiguration {
+	return &CredentialIssuerFrontendApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *CredentialIssuerFrontendApplyConfiguration) WithType(value v1alpha1.FrontendType) *CredentialIssuerFrontendApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithTokenCredentialRequestAPIInfo sets the TokenCredentialRequestAPIInfo field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TokenCredentialRequestAPIInfo field is set to the value of the last call.
+func (b *CredentialIssuerFrontendApplyConfiguration) WithTokenCredentialRequestAPIInfo(value *TokenCredentialRequestAPIInfoApplyConfiguration) *CredentialIssuerFrontendApplyConfiguration {
+	b.TokenCredentialRequestAPIInfo = value
+	return b
+}
+
+// WithImpersonationProxyInfo sets the ImpersonationProxyInfo field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ImpersonationProxyInfo field is set to the value of the last call.
+func (b *CredentialIssuerFrontendApplyConfiguration) WithImpersonationProxyInfo(value *ImpersonationProxyInfoApplyConfiguration) *CredentialIssuerFrontendApplyConfiguration {
+	b.ImpersonationProxyInfo = value
+	return b
+}
@@ -0,0 +1,76 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.21/apis/concierge/config/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CredentialIssuerStrategyApplyConfiguration represents an declarative configuration of the CredentialIssuerStrategy type for use
+// with apply.
+type CredentialIssuerStrategyApplyConfiguration struct {
+	Type           *v1alpha1.StrategyType                      `json:"type,omitempty"`
+	Status         *v1alpha1.StrategyStatus                    `json:"status,omitempty"`
+	Reason         *v1alpha1.StrategyReason                    `json:"reason,omitempty"`
+	Message        *string                                     `json:"message,omitempty"`
+	LastUpdateTime *v1.Time                                    `json:"lastUpdateTime,omitempty"`
+	Frontend       *CredentialIssuerFrontendApplyConfiguration `json:"frontend,omitempty"`
+}
+
+// CredentialIssuerStrategyApplyConfiguration constructs an declarative configuration of the CredentialIssuerStrategy type for use with
+// apply.
+func CredentialIssuerStrategy() *CredentialIssuerStrategyApplyConfiguration {
+	return &CredentialIssuerStrategyApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *CredentialIssuerStrategyApplyConfiguration) WithType(value v1alpha1.StrategyType) *CredentialIssuerStrategyApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithStatus sets the Status field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Status field is set to the value of the last call.
+func (b *CredentialIssuerStrategyApplyConfiguration) WithStatus(value v1alpha1.StrategyStatus) *CredentialIssuerStrategyApplyConfiguration {
+	b.Status = &value
+	return b
+}
+
+// WithReason sets the Reason field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Reason field is set to the value of the last call.
+func (b *CredentialIssuerStrategyApplyConfiguration) WithReason(value v1alpha1.StrategyReason) *CredentialIssuerStrategyApplyConfiguration {
+	b.Reason = &value
+	return b
+}
+
+// WithMessage sets the Message field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Message field is set to the value of the last call.
+func (b *CredentialIssuerStrategyApplyConfiguration) WithMessage(value string) *CredentialIssuerStrategyApplyConfiguration {
+	b.Message = &value
+	return b
+}
+
+// WithLastUpdateTime sets the LastUpdateTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastUpdateTime field is set to the value of the last call.
+func (b *CredentialIssuerStrategyApplyConfiguration) WithLastUpdateTime(value v1.Time) *CredentialIssuerStrategyApplyConfiguration {
+	b.LastUpdateTime = &value
+	return b
+}
+
+// WithFrontend sets the Frontend field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Frontend field is set to the value of the last call.
+func (b *CredentialIssuerStrategyApplyConfiguration) WithFrontend(value *CredentialIssuerFrontendApplyConfiguration) *CredentialIssuerStrategyApplyConfiguration {
+	b.Frontend = value
+	return b
+}
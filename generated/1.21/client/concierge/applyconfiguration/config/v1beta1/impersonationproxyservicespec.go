@@ -0,0 +1,54 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "go.pinniped.dev/generated/1.21/apis/concierge/config/v1beta1"
+)
+
+// ImpersonationProxyServiceSpecApplyConfiguration represents an declarative configuration of the ImpersonationProxyServiceSpec type for use
+// with apply.
+type ImpersonationProxyServiceSpecApplyConfiguration struct {
+	Type           *v1beta1.ImpersonationProxyServiceType `json:"type,omitempty"`
+	LoadBalancerIP *string                                `json:"loadBalancerIP,omitempty"`
+	Annotations    map[string]string                      `json:"annotations,omitempty"`
+}
+
+// ImpersonationProxyServiceSpecApplyConfiguration constructs an declarative configuration of the ImpersonationProxyServiceSpec type for use with
+// apply.
+func ImpersonationProxyServiceSpec() *ImpersonationProxyServiceSpecApplyConfiguration {
+	return &ImpersonationProxyServiceSpecApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *ImpersonationProxyServiceSpecApplyConfiguration) WithType(value v1beta1.ImpersonationProxyServiceType) *ImpersonationProxyServiceSpecApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithLoadBalancerIP sets the LoadBalancerIP field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LoadBalancerIP field is set to the value of the last call.
+func (b *ImpersonationProxyServiceSpecApplyConfiguration) WithLoadBalancerIP(value string) *ImpersonationProxyServiceSpecApplyConfiguration {
+	b.LoadBalancerIP = &value
+	return b
+}
+
+// WithAnnotations puts the entries into the Annotations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Annotations field,
+// overwriting an existing map entries in Annotations field with the same key.
+func (b *ImpersonationProxyServiceSpecApplyConfiguration) WithAnnotations(entries map[string]string) *ImpersonationProxyServiceSpecApplyConfiguration {
+	if b.Annotations == nil && len(entries) > 0 {
+		b.Annotations = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.Annotations[k] = v
+	}
+	return b
+}
@@ -0,0 +1,112 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.21/apis/concierge/authentication/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.21/apis/concierge/authentication/v1beta1"
+	configv1alpha1 "go.pinniped.dev/generated/1.21/apis/concierge/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.21/apis/concierge/config/v1beta1"
+	authenticationv1alpha1 "go.pinniped.dev/generated/1.21/client/concierge/applyconfiguration/authentication/v1alpha1"
+	authenticationv1beta1 "go.pinniped.dev/generated/1.21/client/concierge/applyconfiguration/authentication/v1beta1"
+	applyconfigurationconfigv1alpha1 "go.pinniped.dev/generated/1.21/client/concierge/applyconfiguration/config/v1alpha1"
+	applyconfigurationconfigv1beta1 "go.pinniped.dev/generated/1.21/client/concierge/applyconfiguration/config/v1beta1"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=authentication.concierge.pinniped.dev, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("JWTAuthenticator"):
+		return &authenticationv1alpha1.JWTAuthenticatorApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("JWTAuthenticatorSpec"):
+		return &authenticationv1alpha1.JWTAuthenticatorSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("JWTAuthenticatorStatus"):
+		return &authenticationv1alpha1.JWTAuthenticatorStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("JWTTokenClaims"):
+		return &authenticationv1alpha1.JWTTokenClaimsApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("TLSSpec"):
+		return &authenticationv1alpha1.TLSSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WebhookAuthenticator"):
+		return &authenticationv1alpha1.WebhookAuthenticatorApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WebhookAuthenticatorServiceAccountBot"):
+		return &authenticationv1alpha1.WebhookAuthenticatorServiceAccountBotApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WebhookAuthenticatorServiceAccountTokens"):
+		return &authenticationv1alpha1.WebhookAuthenticatorServiceAccountTokensApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WebhookAuthenticatorSpec"):
+		return &authenticationv1alpha1.WebhookAuthenticatorSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WebhookAuthenticatorStatus"):
+		return &authenticationv1alpha1.WebhookAuthenticatorStatusApplyConfiguration{}
+
+		// Group=authentication.concierge.pinniped.dev, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithKind("JWTAuthenticator"):
+		return &authenticationv1beta1.JWTAuthenticatorApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("JWTAuthenticatorSpec"):
+		return &authenticationv1beta1.JWTAuthenticatorSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("JWTAuthenticatorStatus"):
+		return &authenticationv1beta1.JWTAuthenticatorStatusApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("JWTTokenClaims"):
+		return &authenticationv1beta1.JWTTokenClaimsApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("TLSSpec"):
+		return &authenticationv1beta1.TLSSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("WebhookAuthenticator"):
+		return &authenticationv1beta1.WebhookAuthenticatorApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("WebhookAuthenticatorServiceAccountBot"):
+		return &authenticationv1beta1.WebhookAuthenticatorServiceAccountBotApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("WebhookAuthenticatorServiceAccountTokens"):
+		return &authenticationv1beta1.WebhookAuthenticatorServiceAccountTokensApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("WebhookAuthenticatorSpec"):
+		return &authenticationv1beta1.WebhookAuthenticatorSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("WebhookAuthenticatorStatus"):
+		return &authenticationv1beta1.WebhookAuthenticatorStatusApplyConfiguration{}
+
+		// Group=config.concierge.pinniped.dev, Version=v1alpha1
+	case configv1alpha1.SchemeGroupVersion.WithKind("CredentialIssuer"):
+		return &applyconfigurationconfigv1alpha1.CredentialIssuerApplyConfiguration{}
+	case configv1alpha1.SchemeGroupVersion.WithKind("CredentialIssuerFrontend"):
+		return &applyconfigurationconfigv1alpha1.CredentialIssuerFrontendApplyConfiguration{}
+	case configv1alpha1.SchemeGroupVersion.WithKind("CredentialIssuerKubeConfigInfo"):
+		return &applyconfigurationconfigv1alpha1.CredentialIssuerKubeConfigInfoApplyConfiguration{}
+	case configv1alpha1.SchemeGroupVersion.WithKind("CredentialIssuerSpec"):
+		return &applyconfigurationconfigv1alpha1.CredentialIssuerSpecApplyConfiguration{}
+	case configv1alpha1.SchemeGroupVersion.WithKind("CredentialIssuerStatus"):
+		return &applyconfigurationconfigv1alpha1.CredentialIssuerStatusApplyConfiguration{}
+	case configv1alpha1.SchemeGroupVersion.WithKind("CredentialIssuerStrategy"):
+		return &applyconfigurationconfigv1alpha1.CredentialIssuerStrategyApplyConfiguration{}
+	case configv1alpha1.SchemeGroupVersion.WithKind("ImpersonationProxyInfo"):
+		return &applyconfigurationconfigv1alpha1.ImpersonationProxyInfoApplyConfiguration{}
+	case configv1alpha1.SchemeGroupVersion.WithKind("ImpersonationProxyServiceSpec"):
+		return &applyconfigurationconfigv1alpha1.ImpersonationProxyServiceSpecApplyConfiguration{}
+	case configv1alpha1.SchemeGroupVersion.WithKind("ImpersonationProxySpec"):
+		return &applyconfigurationconfigv1alpha1.ImpersonationProxySpecApplyConfiguration{}
+	case configv1alpha1.SchemeGroupVersion.WithKind("TokenCredentialRequestAPIInfo"):
+		return &applyconfigurationconfigv1alpha1.TokenCredentialRequestAPIInfoApplyConfiguration{}
+
+		// Group=config.concierge.pinniped.dev, Version=v1beta1
+	case configv1beta1.SchemeGroupVersion.WithKind("CredentialIssuer"):
+		return &applyconfigurationconfigv1beta1.CredentialIssuerApplyConfiguration{}
+	case configv1beta1.SchemeGroupVersion.WithKind("CredentialIssuerFrontend"):
+		return &applyconfigurationconfigv1beta1.CredentialIssuerFrontendApplyConfiguration{}
+	case configv1beta1.SchemeGroupVersion.WithKind("CredentialIssuerSpec"):
+		return &applyconfigurationconfigv1beta1.CredentialIssuerSpecApplyConfiguration{}
+	case configv1beta1.SchemeGroupVersion.WithKind("CredentialIssuerStatus"):
+		return &applyconfigurationconfigv1beta1.CredentialIssuerStatusApplyConfiguration{}
+	case configv1beta1.SchemeGroupVersion.WithKind("CredentialIssuerStrategy"):
+		return &applyconfigurationconfigv1beta1.CredentialIssuerStrategyApplyConfiguration{}
+	case configv1beta1.SchemeGroupVersion.WithKind("ImpersonationProxyInfo"):
+		return &applyconfigurationconfigv1beta1.ImpersonationProxyInfoApplyConfiguration{}
+	case configv1beta1.SchemeGroupVersion.WithKind("ImpersonationProxyServiceSpec"):
+		return &applyconfigurationconfigv1beta1.ImpersonationProxyServiceSpecApplyConfiguration{}
+	case configv1beta1.SchemeGroupVersion.WithKind("ImpersonationProxySpec"):
+		return &applyconfigurationconfigv1beta1.ImpersonationProxySpecApplyConfiguration{}
+	case configv1beta1.SchemeGroupVersion.WithKind("TokenCredentialRequestAPIInfo"):
+		return &applyconfigurationconfigv1beta1.TokenCredentialRequestAPIInfoApplyConfiguration{}
+
+	}
+	return nil
+}
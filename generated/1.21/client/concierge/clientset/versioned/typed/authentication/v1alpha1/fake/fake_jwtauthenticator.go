@@ -7,8 +7,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.21/apis/concierge/authentication/v1alpha1"
+	authenticationv1alpha1 "go.pinniped.dev/generated/1.21/client/concierge/applyconfiguration/authentication/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -118,3 +121,46 @@ func (c *FakeJWTAuthenticators) Patch(ctx context.Context, name string, pt types
 	}
 	return obj.(*v1alpha1.JWTAuthenticator), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied jWTAuthenticator.
+func (c *FakeJWTAuthenticators) Apply(ctx context.Context, jWTAuthenticator *authenticationv1alpha1.JWTAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.JWTAuthenticator, err error) {
+	if jWTAuthenticator == nil {
+		return nil, fmt.Errorf("jWTAuthenticator provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(jWTAuthenticator)
+	if err != nil {
+		return nil, err
+	}
+	name := jWTAuthenticator.Name
+	if name == nil {
+		return nil, fmt.Errorf("jWTAuthenticator.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(jwtauthenticatorsResource, *name, types.ApplyPatchType, data), &v1alpha1.JWTAuthenticator{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.JWTAuthenticator), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeJWTAuthenticators) ApplyStatus(ctx context.Context, jWTAuthenticator *authenticationv1alpha1.JWTAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.JWTAuthenticator, err error) {
+	if jWTAuthenticator == nil {
+		return nil, fmt.Errorf("jWTAuthenticator provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(jWTAuthenticator)
+	if err != nil {
+		return nil, err
+	}
+	name := jWTAuthenticator.Name
+	if name == nil {
+		return nil, fmt.Errorf("jWTAuthenticator.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(jwtauthenticatorsResource, *name, types.ApplyPatchType, data, "status"), &v1alpha1.JWTAuthenticator{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.JWTAuthenticator), err
+}
@@ -7,9 +7,12 @@ package v1alpha1
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 	"time"
 
 	v1alpha1 "go.pinniped.dev/generated/1.21/apis/concierge/authentication/v1alpha1"
+	authenticationv1alpha1 "go.pinniped.dev/generated/1.21/client/concierge/applyconfiguration/authentication/v1alpha1"
 	scheme "go.pinniped.dev/generated/1.21/client/concierge/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -34,6 +37,8 @@ type JWTAuthenticatorInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.JWTAuthenticatorList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.JWTAuthenticator, err error)
+	Apply(ctx context.Context, jWTAuthenticator *authenticationv1alpha1.JWTAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.JWTAuthenticator, err error)
+	ApplyStatus(ctx context.Context, jWTAuthenticator *authenticationv1alpha1.JWTAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.JWTAuthenticator, err error)
 	JWTAuthenticatorExpansion
 }
 
@@ -169,3 +174,57 @@ func (c *jWTAuthenticators) Patch(ctx context.Context, name string, pt types.Pat
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied jWTAuthenticator.
+func (c *jWTAuthenticators) Apply(ctx context.Context, jWTAuthenticator *authenticationv1alpha1.JWTAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.JWTAuthenticator, err error) {
+	if jWTAuthenticator == nil {
+		return nil, fmt.Errorf("jWTAuthenticator provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(jWTAuthenticator)
+	if err != nil {
+		return nil, err
+	}
+	name := jWTAuthenticator.Name
+	if name == nil {
+		return nil, fmt.Errorf("jWTAuthenticator.Name must be provided to Apply")
+	}
+	result = &v1alpha1.JWTAuthenticator{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("jwtauthenticators").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *jWTAuthenticators) ApplyStatus(ctx context.Context, jWTAuthenticator *authenticationv1alpha1.JWTAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.JWTAuthenticator, err error) {
+	if jWTAuthenticator == nil {
+		return nil, fmt.Errorf("jWTAuthenticator provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(jWTAuthenticator)
+	if err != nil {
+		return nil, err
+	}
+
+	name := jWTAuthenticator.Name
+	if name == nil {
+		return nil, fmt.Errorf("jWTAuthenticator.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.JWTAuthenticator{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("jwtauthenticators").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
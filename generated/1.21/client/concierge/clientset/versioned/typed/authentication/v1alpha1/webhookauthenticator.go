@@ -7,9 +7,12 @@ package v1alpha1
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 	"time"
 
 	v1alpha1 "go.pinniped.dev/generated/1.21/apis/concierge/authentication/v1alpha1"
+	authenticationv1alpha1 "go.pinniped.dev/generated/1.21/client/concierge/applyconfiguration/authentication/v1alpha1"
 	scheme "go.pinniped.dev/generated/1.21/client/concierge/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -34,6 +37,8 @@ type WebhookAuthenticatorInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.WebhookAuthenticatorList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WebhookAuthenticator, err error)
+	Apply(ctx context.Context, webhookAuthenticator *authenticationv1alpha1.WebhookAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WebhookAuthenticator, err error)
+	ApplyStatus(ctx context.Context, webhookAuthenticator *authenticationv1alpha1.WebhookAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WebhookAuthenticator, err error)
 	WebhookAuthenticatorExpansion
 }
 
@@ -169,3 +174,57 @@ func (c *webhookAuthenticators) Patch(ctx context.Context, name string, pt types
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied webhookAuthenticator.
+func (c *webhookAuthenticators) Apply(ctx context.Context, webhookAuthenticator *authenticationv1alpha1.WebhookAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
+	if webhookAuthenticator == nil {
+		return nil, fmt.Errorf("webhookAuthenticator provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(webhookAuthenticator)
+	if err != nil {
+		return nil, err
+	}
+	name := webhookAuthenticator.Name
+	if name == nil {
+		return nil, fmt.Errorf("webhookAuthenticator.Name must be provided to Apply")
+	}
+	result = &v1alpha1.WebhookAuthenticator{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("webhookauthenticators").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *webhookAuthenticators) ApplyStatus(ctx context.Context, webhookAuthenticator *authenticationv1alpha1.WebhookAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
+	if webhookAuthenticator == nil {
+		return nil, fmt.Errorf("webhookAuthenticator provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(webhookAuthenticator)
+	if err != nil {
+		return nil, err
+	}
+
+	name := webhookAuthenticator.Name
+	if name == nil {
+		return nil, fmt.Errorf("webhookAuthenticator.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.WebhookAuthenticator{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("webhookauthenticators").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
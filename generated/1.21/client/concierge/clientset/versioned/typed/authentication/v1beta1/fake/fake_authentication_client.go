@@ -0,0 +1,31 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "go.pinniped.dev/generated/1.21/client/concierge/clientset/versioned/typed/authentication/v1beta1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeAuthenticationV1beta1 struct {
+	*testing.Fake
+}
+
+func (c *FakeAuthenticationV1beta1) JWTAuthenticators() v1beta1.JWTAuthenticatorInterface {
+	return &FakeJWTAuthenticators{c}
+}
+
+func (c *FakeAuthenticationV1beta1) WebhookAuthenticators() v1beta1.WebhookAuthenticatorInterface {
+	return &FakeWebhookAuthenticators{c}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeAuthenticationV1beta1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
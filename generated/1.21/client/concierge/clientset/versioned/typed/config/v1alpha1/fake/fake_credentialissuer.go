@@ -7,8 +7,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.21/apis/concierge/config/v1alpha1"
+	configv1alpha1 "go.pinniped.dev/generated/1.21/client/concierge/applyconfiguration/config/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -118,3 +121,46 @@ func (c *FakeCredentialIssuers) Patch(ctx context.Context, name string, pt types
 	}
 	return obj.(*v1alpha1.CredentialIssuer), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied credentialIssuer.
+func (c *FakeCredentialIssuers) Apply(ctx context.Context, credentialIssuer *configv1alpha1.CredentialIssuerApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.CredentialIssuer, err error) {
+	if credentialIssuer == nil {
+		return nil, fmt.Errorf("credentialIssuer provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(credentialIssuer)
+	if err != nil {
+		return nil, err
+	}
+	name := credentialIssuer.Name
+	if name == nil {
+		return nil, fmt.Errorf("credentialIssuer.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(credentialissuersResource, *name, types.ApplyPatchType, data), &v1alpha1.CredentialIssuer{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.CredentialIssuer), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeCredentialIssuers) ApplyStatus(ctx context.Context, credentialIssuer *configv1alpha1.CredentialIssuerApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.CredentialIssuer, err error) {
+	if credentialIssuer == nil {
+		return nil, fmt.Errorf("credentialIssuer provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(credentialIssuer)
+	if err != nil {
+		return nil, err
+	}
+	name := credentialIssuer.Name
+	if name == nil {
+		return nil, fmt.Errorf("credentialIssuer.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(credentialissuersResource, *name, types.ApplyPatchType, data, "status"), &v1alpha1.CredentialIssuer{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.CredentialIssuer), err
+}
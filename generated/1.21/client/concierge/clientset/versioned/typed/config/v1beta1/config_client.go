@@ -3,30 +3,30 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha1
+package v1beta1
 
 import (
-	v1alpha1 "go.pinniped.dev/generated/1.17/apis/concierge/login/v1alpha1"
-	"go.pinniped.dev/generated/1.17/client/concierge/clientset/versioned/scheme"
+	v1beta1 "go.pinniped.dev/generated/1.21/apis/concierge/config/v1beta1"
+	"go.pinniped.dev/generated/1.21/client/concierge/clientset/versioned/scheme"
 	rest "k8s.io/client-go/rest"
 )
 
-type LoginV1alpha1Interface interface {
+type ConfigV1beta1Interface interface {
 	RESTClient() rest.Interface
-	TokenCredentialRequestsGetter
+	CredentialIssuersGetter
 }
 
-// LoginV1alpha1Client is used to interact with features provided by the login.concierge.pinniped.dev group.
-type LoginV1alpha1Client struct {
+// ConfigV1beta1Client is used to interact with features provided by the config.concierge.pinniped.dev group.
+type ConfigV1beta1Client struct {
 	restClient rest.Interface
 }
 
-func (c *LoginV1alpha1Client) TokenCredentialRequests() TokenCredentialRequestInterface {
-	return newTokenCredentialRequests(c)
+func (c *ConfigV1beta1Client) CredentialIssuers() CredentialIssuerInterface {
+	return newCredentialIssuers(c)
 }
 
-// NewForConfig creates a new LoginV1alpha1Client for the given config.
-func NewForConfig(c *rest.Config) (*LoginV1alpha1Client, error) {
+// NewForConfig creates a new ConfigV1beta1Client for the given config.
+func NewForConfig(c *rest.Config) (*ConfigV1beta1Client, error) {
 	config := *c
 	if err := setConfigDefaults(&config); err != nil {
 		return nil, err
@@ -35,12 +35,12 @@ func NewForConfig(c *rest.Config) (*LoginV1alpha1Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &LoginV1alpha1Client{client}, nil
+	return &ConfigV1beta1Client{client}, nil
 }
 
-// NewForConfigOrDie creates a new LoginV1alpha1Client for the given config and
+// NewForConfigOrDie creates a new ConfigV1beta1Client for the given config and
 // panics if there is an error in the config.
-func NewForConfigOrDie(c *rest.Config) *LoginV1alpha1Client {
+func NewForConfigOrDie(c *rest.Config) *ConfigV1beta1Client {
 	client, err := NewForConfig(c)
 	if err != nil {
 		panic(err)
@@ -48,13 +48,13 @@ func NewForConfigOrDie(c *rest.Config) *LoginV1alpha1Client {
 	return client
 }
 
-// New creates a new LoginV1alpha1Client for the given RESTClient.
-func New(c rest.Interface) *LoginV1alpha1Client {
-	return &LoginV1alpha1Client{c}
+// New creates a new ConfigV1beta1Client for the given RESTClient.
+func New(c rest.Interface) *ConfigV1beta1Client {
+	return &ConfigV1beta1Client{c}
 }
 
 func setConfigDefaults(config *rest.Config) error {
-	gv := v1alpha1.SchemeGroupVersion
+	gv := v1beta1.SchemeGroupVersion
 	config.GroupVersion = &gv
 	config.APIPath = "/apis"
 	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
@@ -68,7 +68,7 @@ func setConfigDefaults(config *rest.Config) error {
 
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
-func (c *LoginV1alpha1Client) RESTClient() rest.Interface {
+func (c *ConfigV1beta1Client) RESTClient() rest.Interface {
 	if c == nil {
 		return nil
 	}
@@ -0,0 +1,51 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OIDCClientStatusApplyConfiguration represents an declarative configuration of the OIDCClientStatus type for use
+// with apply.
+type OIDCClientStatusApplyConfiguration struct {
+	Phase              *v1alpha1.OIDCClientPhase `json:"phase,omitempty"`
+	Conditions         []v1.Condition            `json:"conditions,omitempty"`
+	TotalClientSecrets *int32                    `json:"totalClientSecrets,omitempty"`
+}
+
+// OIDCClientStatusApplyConfiguration constructs an declarative configuration of the OIDCClientStatus type for use with
+// apply.
+func OIDCClientStatus() *OIDCClientStatusApplyConfiguration {
+	return &OIDCClientStatusApplyConfiguration{}
+}
+
+// WithPhase sets the Phase field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Phase field is set to the value of the last call.
+func (b *OIDCClientStatusApplyConfiguration) WithPhase(value v1alpha1.OIDCClientPhase) *OIDCClientStatusApplyConfiguration {
+	b.Phase = &value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *OIDCClientStatusApplyConfiguration) WithConditions(values ...v1.Condition) *OIDCClientStatusApplyConfiguration {
+	for i := range values {
+		b.Conditions = append(b.Conditions, values[i])
+	}
+	return b
+}
+
+// WithTotalClientSecrets sets the TotalClientSecrets field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TotalClientSecrets field is set to the value of the last call.
+func (b *OIDCClientStatusApplyConfiguration) WithTotalClientSecrets(value int32) *OIDCClientStatusApplyConfiguration {
+	b.TotalClientSecrets = &value
+	return b
+}
@@ -0,0 +1,39 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1alpha1"
+)
+
+// OIDCClientTokenProfileApplyConfiguration represents an declarative configuration of the OIDCClientTokenProfile type for use
+// with apply.
+type OIDCClientTokenProfileApplyConfiguration struct {
+	CloudProvider *v1alpha1.OIDCClientCloudProvider `json:"cloudProvider,omitempty"`
+	SubjectFormat *v1alpha1.OIDCClientSubjectFormat `json:"subjectFormat,omitempty"`
+}
+
+// OIDCClientTokenProfileApplyConfiguration constructs an declarative configuration of the OIDCClientTokenProfile type for use with
+// apply.
+func OIDCClientTokenProfile() *OIDCClientTokenProfileApplyConfiguration {
+	return &OIDCClientTokenProfileApplyConfiguration{}
+}
+
+// WithCloudProvider sets the CloudProvider field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CloudProvider field is set to the value of the last call.
+func (b *OIDCClientTokenProfileApplyConfiguration) WithCloudProvider(value v1alpha1.OIDCClientCloudProvider) *OIDCClientTokenProfileApplyConfiguration {
+	b.CloudProvider = &value
+	return b
+}
+
+// WithSubjectFormat sets the SubjectFormat field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SubjectFormat field is set to the value of the last call.
+func (b *OIDCClientTokenProfileApplyConfiguration) WithSubjectFormat(value v1alpha1.OIDCClientSubjectFormat) *OIDCClientTokenProfileApplyConfiguration {
+	b.SubjectFormat = &value
+	return b
+}
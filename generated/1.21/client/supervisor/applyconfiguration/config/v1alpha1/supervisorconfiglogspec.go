@@ -0,0 +1,45 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1alpha1"
+)
+
+// SupervisorConfigLogSpecApplyConfiguration represents an declarative configuration of the SupervisorConfigLogSpec type for use
+// with apply.
+type SupervisorConfigLogSpecApplyConfiguration struct {
+	Level      *v1alpha1.SupervisorConfigLogLevel           `json:"level,omitempty"`
+	Components map[string]v1alpha1.SupervisorConfigLogLevel `json:"components,omitempty"`
+}
+
+// SupervisorConfigLogSpecApplyConfiguration constructs an declarative configuration of the SupervisorConfigLogSpec type for use with
+// apply.
+func SupervisorConfigLogSpec() *SupervisorConfigLogSpecApplyConfiguration {
+	return &SupervisorConfigLogSpecApplyConfiguration{}
+}
+
+// WithLevel sets the Level field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Level field is set to the value of the last call.
+func (b *SupervisorConfigLogSpecApplyConfiguration) WithLevel(value v1alpha1.SupervisorConfigLogLevel) *SupervisorConfigLogSpecApplyConfiguration {
+	b.Level = &value
+	return b
+}
+
+// WithComponents puts the entries into the Components field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Components field,
+// overwriting an existing map entries in Components field with the same key.
+func (b *SupervisorConfigLogSpecApplyConfiguration) WithComponents(entries map[string]v1alpha1.SupervisorConfigLogLevel) *SupervisorConfigLogSpecApplyConfiguration {
+	if b.Components == nil && len(entries) > 0 {
+		b.Components = make(map[string]v1alpha1.SupervisorConfigLogLevel, len(entries))
+	}
+	for k, v := range entries {
+		b.Components[k] = v
+	}
+	return b
+}
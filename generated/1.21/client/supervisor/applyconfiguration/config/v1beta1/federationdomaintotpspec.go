@@ -0,0 +1,30 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1beta1"
+)
+
+// FederationDomainTOTPSpecApplyConfiguration represents an declarative configuration of the FederationDomainTOTPSpec type for use
+// with apply.
+type FederationDomainTOTPSpecApplyConfiguration struct {
+	Enforcement *v1beta1.FederationDomainTOTPEnforcement `json:"enforcement,omitempty"`
+}
+
+// FederationDomainTOTPSpecApplyConfiguration constructs an declarative configuration of the FederationDomainTOTPSpec type for use with
+// apply.
+func FederationDomainTOTPSpec() *FederationDomainTOTPSpecApplyConfiguration {
+	return &FederationDomainTOTPSpecApplyConfiguration{}
+}
+
+// WithEnforcement sets the Enforcement field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Enforcement field is set to the value of the last call.
+func (b *FederationDomainTOTPSpecApplyConfiguration) WithEnforcement(value v1beta1.FederationDomainTOTPEnforcement) *FederationDomainTOTPSpecApplyConfiguration {
+	b.Enforcement = &value
+	return b
+}
@@ -0,0 +1,62 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// OIDCIdentityProviderSpecApplyConfiguration represents an declarative configuration of the OIDCIdentityProviderSpec type for use
+// with apply.
+type OIDCIdentityProviderSpecApplyConfiguration struct {
+	Issuer              *string                                    `json:"issuer,omitempty"`
+	TLS                 *TLSSpecApplyConfiguration                 `json:"tls,omitempty"`
+	AuthorizationConfig *OIDCAuthorizationConfigApplyConfiguration `json:"authorizationConfig,omitempty"`
+	Claims              *OIDCClaimsApplyConfiguration              `json:"claims,omitempty"`
+	Client              *OIDCClientApplyConfiguration              `json:"client,omitempty"`
+}
+
+// OIDCIdentityProviderSpecApplyConfiguration constructs an declarative configuration of the OIDCIdentityProviderSpec type for use with
+// apply.
+func OIDCIdentityProviderSpec() *OIDCIdentityProviderSpecApplyConfiguration {
+	return &OIDCIdentityProviderSpecApplyConfiguration{}
+}
+
+// WithIssuer sets the Issuer field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Issuer field is set to the value of the last call.
+func (b *OIDCIdentityProviderSpecApplyConfiguration) WithIssuer(value string) *OIDCIdentityProviderSpecApplyConfiguration {
+	b.Issuer = &value
+	return b
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *OIDCIdentityProviderSpecApplyConfiguration) WithTLS(value *TLSSpecApplyConfiguration) *OIDCIdentityProviderSpecApplyConfiguration {
+	b.TLS = value
+	return b
+}
+
+// WithAuthorizationConfig sets the AuthorizationConfig field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AuthorizationConfig field is set to the value of the last call.
+func (b *OIDCIdentityProviderSpecApplyConfiguration) WithAuthorizationConfig(value *OIDCAuthorizationConfigApplyConfiguration) *OIDCIdentityProviderSpecApplyConfiguration {
+	b.AuthorizationConfig = value
+	return b
+}
+
+// WithClaims sets the Claims field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Claims field is set to the value of the last call.
+func (b *OIDCIdentityProviderSpecApplyConfiguration) WithClaims(value *OIDCClaimsApplyConfiguration) *OIDCIdentityProviderSpecApplyConfiguration {
+	b.Claims = value
+	return b
+}
+
+// WithClient sets the Client field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Client field is set to the value of the last call.
+func (b *OIDCIdentityProviderSpecApplyConfiguration) WithClient(value *OIDCClientApplyConfiguration) *OIDCIdentityProviderSpecApplyConfiguration {
+	b.Client = value
+	return b
+}
@@ -0,0 +1,216 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1beta1"
+	idpv1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/idp/v1alpha1"
+	idpv1beta1 "go.pinniped.dev/generated/1.21/apis/supervisor/idp/v1beta1"
+	configv1alpha1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/config/v1beta1"
+	applyconfigurationidpv1alpha1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/idp/v1alpha1"
+	applyconfigurationidpv1beta1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/idp/v1beta1"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=config.supervisor.pinniped.dev, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomain"):
+		return &configv1alpha1.FederationDomainApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomainIssuerMigration"):
+		return &configv1alpha1.FederationDomainIssuerMigrationApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomainLoginPolicy"):
+		return &configv1alpha1.FederationDomainLoginPolicyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomainLoginTimeWindow"):
+		return &configv1alpha1.FederationDomainLoginTimeWindowApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomainSecrets"):
+		return &configv1alpha1.FederationDomainSecretsApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomainSpec"):
+		return &configv1alpha1.FederationDomainSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomainStatus"):
+		return &configv1alpha1.FederationDomainStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomainTLSSpec"):
+		return &configv1alpha1.FederationDomainTLSSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomainTOTPSpec"):
+		return &configv1alpha1.FederationDomainTOTPSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FederationDomainWebAuthnSpec"):
+		return &configv1alpha1.FederationDomainWebAuthnSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OIDCClient"):
+		return &configv1alpha1.OIDCClientApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OIDCClientCredentials"):
+		return &configv1alpha1.OIDCClientCredentialsApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OIDCClientGroupsClaim"):
+		return &configv1alpha1.OIDCClientGroupsClaimApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OIDCClientSecretPolicy"):
+		return &configv1alpha1.OIDCClientSecretPolicyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OIDCClientSpec"):
+		return &configv1alpha1.OIDCClientSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OIDCClientStatus"):
+		return &configv1alpha1.OIDCClientStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OIDCClientTokenProfile"):
+		return &configv1alpha1.OIDCClientTokenProfileApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SupervisorConfig"):
+		return &configv1alpha1.SupervisorConfigApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SupervisorConfigEndpoint"):
+		return &configv1alpha1.SupervisorConfigEndpointApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SupervisorConfigEndpoints"):
+		return &configv1alpha1.SupervisorConfigEndpointsApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SupervisorConfigLogSpec"):
+		return &configv1alpha1.SupervisorConfigLogSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SupervisorConfigSpec"):
+		return &configv1alpha1.SupervisorConfigSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SupervisorConfigStatus"):
+		return &configv1alpha1.SupervisorConfigStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SupervisorConfigTLSSpec"):
+		return &configv1alpha1.SupervisorConfigTLSSpecApplyConfiguration{}
+
+		// Group=config.supervisor.pinniped.dev, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomain"):
+		return &configv1beta1.FederationDomainApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomainIssuerMigration"):
+		return &configv1beta1.FederationDomainIssuerMigrationApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomainLoginPolicy"):
+		return &configv1beta1.FederationDomainLoginPolicyApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomainLoginTimeWindow"):
+		return &configv1beta1.FederationDomainLoginTimeWindowApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomainSecrets"):
+		return &configv1beta1.FederationDomainSecretsApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomainSpec"):
+		return &configv1beta1.FederationDomainSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomainStatus"):
+		return &configv1beta1.FederationDomainStatusApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomainTLSSpec"):
+		return &configv1beta1.FederationDomainTLSSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomainTOTPSpec"):
+		return &configv1beta1.FederationDomainTOTPSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FederationDomainWebAuthnSpec"):
+		return &configv1beta1.FederationDomainWebAuthnSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("OIDCClient"):
+		return &configv1beta1.OIDCClientApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("OIDCClientCredentials"):
+		return &configv1beta1.OIDCClientCredentialsApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("OIDCClientGroupsClaim"):
+		return &configv1beta1.OIDCClientGroupsClaimApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("OIDCClientSecretPolicy"):
+		return &configv1beta1.OIDCClientSecretPolicyApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("OIDCClientSpec"):
+		return &configv1beta1.OIDCClientSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("OIDCClientStatus"):
+		return &configv1beta1.OIDCClientStatusApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("OIDCClientTokenProfile"):
+		return &configv1beta1.OIDCClientTokenProfileApplyConfiguration{}
+
+		// Group=idp.supervisor.pinniped.dev, Version=v1alpha1
+	case idpv1alpha1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProvider"):
+		return &applyconfigurationidpv1alpha1.ActiveDirectoryIdentityProviderApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderBind"):
+		return &applyconfigurationidpv1alpha1.ActiveDirectoryIdentityProviderBindApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderGroupSearch"):
+		return &applyconfigurationidpv1alpha1.ActiveDirectoryIdentityProviderGroupSearchApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderGroupSearchAttributes"):
+		return &applyconfigurationidpv1alpha1.ActiveDirectoryIdentityProviderGroupSearchAttributesApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderSpec"):
+		return &applyconfigurationidpv1alpha1.ActiveDirectoryIdentityProviderSpecApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderStatus"):
+		return &applyconfigurationidpv1alpha1.ActiveDirectoryIdentityProviderStatusApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderUserSearch"):
+		return &applyconfigurationidpv1alpha1.ActiveDirectoryIdentityProviderUserSearchApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderUserSearchAttributes"):
+		return &applyconfigurationidpv1alpha1.ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("GroupTransformation"):
+		return &applyconfigurationidpv1alpha1.GroupTransformationApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("LDAPIdentityProvider"):
+		return &applyconfigurationidpv1alpha1.LDAPIdentityProviderApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("LDAPIdentityProviderBind"):
+		return &applyconfigurationidpv1alpha1.LDAPIdentityProviderBindApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("LDAPIdentityProviderGroupSearch"):
+		return &applyconfigurationidpv1alpha1.LDAPIdentityProviderGroupSearchApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("LDAPIdentityProviderGroupSearchAttributes"):
+		return &applyconfigurationidpv1alpha1.LDAPIdentityProviderGroupSearchAttributesApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("LDAPIdentityProviderSpec"):
+		return &applyconfigurationidpv1alpha1.LDAPIdentityProviderSpecApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("LDAPIdentityProviderStatus"):
+		return &applyconfigurationidpv1alpha1.LDAPIdentityProviderStatusApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("LDAPIdentityProviderUserSearch"):
+		return &applyconfigurationidpv1alpha1.LDAPIdentityProviderUserSearchApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("LDAPIdentityProviderUserSearchAttributes"):
+		return &applyconfigurationidpv1alpha1.LDAPIdentityProviderUserSearchAttributesApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("OIDCAuthorizationConfig"):
+		return &applyconfigurationidpv1alpha1.OIDCAuthorizationConfigApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("OIDCClaims"):
+		return &applyconfigurationidpv1alpha1.OIDCClaimsApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("OIDCClient"):
+		return &applyconfigurationidpv1alpha1.OIDCClientApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("OIDCIdentityProvider"):
+		return &applyconfigurationidpv1alpha1.OIDCIdentityProviderApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("OIDCIdentityProviderSpec"):
+		return &applyconfigurationidpv1alpha1.OIDCIdentityProviderSpecApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("OIDCIdentityProviderStatus"):
+		return &applyconfigurationidpv1alpha1.OIDCIdentityProviderStatusApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("Parameter"):
+		return &applyconfigurationidpv1alpha1.ParameterApplyConfiguration{}
+	case idpv1alpha1.SchemeGroupVersion.WithKind("TLSSpec"):
+		return &applyconfigurationidpv1alpha1.TLSSpecApplyConfiguration{}
+
+		// Group=idp.supervisor.pinniped.dev, Version=v1beta1
+	case idpv1beta1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProvider"):
+		return &applyconfigurationidpv1beta1.ActiveDirectoryIdentityProviderApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderBind"):
+		return &applyconfigurationidpv1beta1.ActiveDirectoryIdentityProviderBindApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderGroupSearch"):
+		return &applyconfigurationidpv1beta1.ActiveDirectoryIdentityProviderGroupSearchApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderGroupSearchAttributes"):
+		return &applyconfigurationidpv1beta1.ActiveDirectoryIdentityProviderGroupSearchAttributesApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderSpec"):
+		return &applyconfigurationidpv1beta1.ActiveDirectoryIdentityProviderSpecApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderStatus"):
+		return &applyconfigurationidpv1beta1.ActiveDirectoryIdentityProviderStatusApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderUserSearch"):
+		return &applyconfigurationidpv1beta1.ActiveDirectoryIdentityProviderUserSearchApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProviderUserSearchAttributes"):
+		return &applyconfigurationidpv1beta1.ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("GroupTransformation"):
+		return &applyconfigurationidpv1beta1.GroupTransformationApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("LDAPIdentityProvider"):
+		return &applyconfigurationidpv1beta1.LDAPIdentityProviderApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("LDAPIdentityProviderBind"):
+		return &applyconfigurationidpv1beta1.LDAPIdentityProviderBindApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("LDAPIdentityProviderGroupSearch"):
+		return &applyconfigurationidpv1beta1.LDAPIdentityProviderGroupSearchApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("LDAPIdentityProviderGroupSearchAttributes"):
+		return &applyconfigurationidpv1beta1.LDAPIdentityProviderGroupSearchAttributesApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("LDAPIdentityProviderSpec"):
+		return &applyconfigurationidpv1beta1.LDAPIdentityProviderSpecApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("LDAPIdentityProviderStatus"):
+		return &applyconfigurationidpv1beta1.LDAPIdentityProviderStatusApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("LDAPIdentityProviderUserSearch"):
+		return &applyconfigurationidpv1beta1.LDAPIdentityProviderUserSearchApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("LDAPIdentityProviderUserSearchAttributes"):
+		return &applyconfigurationidpv1beta1.LDAPIdentityProviderUserSearchAttributesApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("OIDCAuthorizationConfig"):
+		return &applyconfigurationidpv1beta1.OIDCAuthorizationConfigApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("OIDCClaims"):
+		return &applyconfigurationidpv1beta1.OIDCClaimsApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("OIDCClient"):
+		return &applyconfigurationidpv1beta1.OIDCClientApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("OIDCIdentityProvider"):
+		return &applyconfigurationidpv1beta1.OIDCIdentityProviderApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("OIDCIdentityProviderSpec"):
+		return &applyconfigurationidpv1beta1.OIDCIdentityProviderSpecApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("OIDCIdentityProviderStatus"):
+		return &applyconfigurationidpv1beta1.OIDCIdentityProviderStatusApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("Parameter"):
+		return &applyconfigurationidpv1beta1.ParameterApplyConfiguration{}
+	case idpv1beta1.SchemeGroupVersion.WithKind("TLSSpec"):
+		return &applyconfigurationidpv1beta1.TLSSpecApplyConfiguration{}
+
+	}
+	return nil
+}
@@ -7,8 +7,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1alpha1"
+	configv1alpha1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/config/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -127,3 +130,48 @@ func (c *FakeFederationDomains) Patch(ctx context.Context, name string, pt types
 	}
 	return obj.(*v1alpha1.FederationDomain), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied federationDomain.
+func (c *FakeFederationDomains) Apply(ctx context.Context, federationDomain *configv1alpha1.FederationDomainApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.FederationDomain, err error) {
+	if federationDomain == nil {
+		return nil, fmt.Errorf("federationDomain provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(federationDomain)
+	if err != nil {
+		return nil, err
+	}
+	name := federationDomain.Name
+	if name == nil {
+		return nil, fmt.Errorf("federationDomain.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(federationdomainsResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha1.FederationDomain{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.FederationDomain), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeFederationDomains) ApplyStatus(ctx context.Context, federationDomain *configv1alpha1.FederationDomainApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.FederationDomain, err error) {
+	if federationDomain == nil {
+		return nil, fmt.Errorf("federationDomain provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(federationDomain)
+	if err != nil {
+		return nil, err
+	}
+	name := federationDomain.Name
+	if name == nil {
+		return nil, fmt.Errorf("federationDomain.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(federationdomainsResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1alpha1.FederationDomain{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.FederationDomain), err
+}
@@ -7,8 +7,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1alpha1"
+	configv1alpha1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/config/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -127,3 +130,48 @@ func (c *FakeOIDCClients) Patch(ctx context.Context, name string, pt types.Patch
 	}
 	return obj.(*v1alpha1.OIDCClient), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied oIDCClient.
+func (c *FakeOIDCClients) Apply(ctx context.Context, oIDCClient *configv1alpha1.OIDCClientApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCClient, err error) {
+	if oIDCClient == nil {
+		return nil, fmt.Errorf("oIDCClient provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(oIDCClient)
+	if err != nil {
+		return nil, err
+	}
+	name := oIDCClient.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCClient.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(oidcclientsResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha1.OIDCClient{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.OIDCClient), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeOIDCClients) ApplyStatus(ctx context.Context, oIDCClient *configv1alpha1.OIDCClientApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCClient, err error) {
+	if oIDCClient == nil {
+		return nil, fmt.Errorf("oIDCClient provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(oIDCClient)
+	if err != nil {
+		return nil, err
+	}
+	name := oIDCClient.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCClient.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(oidcclientsResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1alpha1.OIDCClient{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.OIDCClient), err
+}
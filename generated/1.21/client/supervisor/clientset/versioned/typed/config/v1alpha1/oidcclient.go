@@ -7,9 +7,12 @@ package v1alpha1
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 	"time"
 
 	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1alpha1"
+	configv1alpha1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/config/v1alpha1"
 	scheme "go.pinniped.dev/generated/1.21/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -34,6 +37,8 @@ type OIDCClientInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.OIDCClientList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.OIDCClient, err error)
+	Apply(ctx context.Context, oIDCClient *configv1alpha1.OIDCClientApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCClient, err error)
+	ApplyStatus(ctx context.Context, oIDCClient *configv1alpha1.OIDCClientApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCClient, err error)
 	OIDCClientExpansion
 }
 
@@ -180,3 +185,59 @@ func (c *oIDCClients) Patch(ctx context.Context, name string, pt types.PatchType
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied oIDCClient.
+func (c *oIDCClients) Apply(ctx context.Context, oIDCClient *configv1alpha1.OIDCClientApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCClient, err error) {
+	if oIDCClient == nil {
+		return nil, fmt.Errorf("oIDCClient provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(oIDCClient)
+	if err != nil {
+		return nil, err
+	}
+	name := oIDCClient.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCClient.Name must be provided to Apply")
+	}
+	result = &v1alpha1.OIDCClient{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("oidcclients").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *oIDCClients) ApplyStatus(ctx context.Context, oIDCClient *configv1alpha1.OIDCClientApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCClient, err error) {
+	if oIDCClient == nil {
+		return nil, fmt.Errorf("oIDCClient provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(oIDCClient)
+	if err != nil {
+		return nil, err
+	}
+
+	name := oIDCClient.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCClient.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.OIDCClient{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("oidcclients").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -7,8 +7,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/idp/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/idp/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -127,3 +130,48 @@ func (c *FakeActiveDirectoryIdentityProviders) Patch(ctx context.Context, name s
 	}
 	return obj.(*v1alpha1.ActiveDirectoryIdentityProvider), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied activeDirectoryIdentityProvider.
+func (c *FakeActiveDirectoryIdentityProviders) Apply(ctx context.Context, activeDirectoryIdentityProvider *idpv1alpha1.ActiveDirectoryIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+	if activeDirectoryIdentityProvider == nil {
+		return nil, fmt.Errorf("activeDirectoryIdentityProvider provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(activeDirectoryIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := activeDirectoryIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("activeDirectoryIdentityProvider.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(activedirectoryidentityprovidersResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha1.ActiveDirectoryIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ActiveDirectoryIdentityProvider), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeActiveDirectoryIdentityProviders) ApplyStatus(ctx context.Context, activeDirectoryIdentityProvider *idpv1alpha1.ActiveDirectoryIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+	if activeDirectoryIdentityProvider == nil {
+		return nil, fmt.Errorf("activeDirectoryIdentityProvider provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(activeDirectoryIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := activeDirectoryIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("activeDirectoryIdentityProvider.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(activedirectoryidentityprovidersResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1alpha1.ActiveDirectoryIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ActiveDirectoryIdentityProvider), err
+}
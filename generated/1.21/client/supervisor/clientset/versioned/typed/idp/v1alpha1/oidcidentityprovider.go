@@ -7,9 +7,12 @@ package v1alpha1
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 	"time"
 
 	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/idp/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/idp/v1alpha1"
 	scheme "go.pinniped.dev/generated/1.21/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -34,6 +37,8 @@ type OIDCIdentityProviderInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.OIDCIdentityProviderList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.OIDCIdentityProvider, err error)
+	Apply(ctx context.Context, oIDCIdentityProvider *idpv1alpha1.OIDCIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCIdentityProvider, err error)
+	ApplyStatus(ctx context.Context, oIDCIdentityProvider *idpv1alpha1.OIDCIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCIdentityProvider, err error)
 	OIDCIdentityProviderExpansion
 }
 
@@ -180,3 +185,59 @@ func (c *oIDCIdentityProviders) Patch(ctx context.Context, name string, pt types
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied oIDCIdentityProvider.
+func (c *oIDCIdentityProviders) Apply(ctx context.Context, oIDCIdentityProvider *idpv1alpha1.OIDCIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
+	if oIDCIdentityProvider == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(oIDCIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := oIDCIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider.Name must be provided to Apply")
+	}
+	result = &v1alpha1.OIDCIdentityProvider{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("oidcidentityproviders").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *oIDCIdentityProviders) ApplyStatus(ctx context.Context, oIDCIdentityProvider *idpv1alpha1.OIDCIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
+	if oIDCIdentityProvider == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(oIDCIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	name := oIDCIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.OIDCIdentityProvider{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("oidcidentityproviders").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,177 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+
+	v1beta1 "go.pinniped.dev/generated/1.21/apis/supervisor/idp/v1beta1"
+	idpv1beta1 "go.pinniped.dev/generated/1.21/client/supervisor/applyconfiguration/idp/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeOIDCIdentityProviders implements OIDCIdentityProviderInterface
+type FakeOIDCIdentityProviders struct {
+	Fake *FakeIDPV1beta1
+	ns   string
+}
+
+var oidcidentityprovidersResource = schema.GroupVersionResource{Group: "idp.supervisor.pinniped.dev", Version: "v1beta1", Resource: "oidcidentityproviders"}
+
+var oidcidentityprovidersKind = schema.GroupVersionKind{Group: "idp.supervisor.pinniped.dev", Version: "v1beta1", Kind: "OIDCIdentityProvider"}
+
+// Get takes name of the oIDCIdentityProvider, and returns the corresponding oIDCIdentityProvider object, and an error if there is any.
+func (c *FakeOIDCIdentityProviders) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(oidcidentityprovidersResource, c.ns, name), &v1beta1.OIDCIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.OIDCIdentityProvider), err
+}
+
+// List takes label and field selectors, and returns the list of OIDCIdentityProviders that match those selectors.
+func (c *FakeOIDCIdentityProviders) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.OIDCIdentityProviderList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(oidcidentityprovidersResource, oidcidentityprovidersKind, c.ns, opts), &v1beta1.OIDCIdentityProviderList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.OIDCIdentityProviderList{ListMeta: obj.(*v1beta1.OIDCIdentityProviderList).ListMeta}
+	for _, item := range obj.(*v1beta1.OIDCIdentityProviderList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested oIDCIdentityProviders.
+func (c *FakeOIDCIdentityProviders) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(oidcidentityprovidersResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a oIDCIdentityProvider and creates it.  Returns the server's representation of the oIDCIdentityProvider, and an error, if there is any.
+func (c *FakeOIDCIdentityProviders) Create(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.CreateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(oidcidentityprovidersResource, c.ns, oIDCIdentityProvider), &v1beta1.OIDCIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.OIDCIdentityProvider), err
+}
+
+// Update takes the representation of a oIDCIdentityProvider and updates it. Returns the server's representation of the oIDCIdentityProvider, and an error, if there is any.
+func (c *FakeOIDCIdentityProviders) Update(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(oidcidentityprovidersResource, c.ns, oIDCIdentityProvider), &v1beta1.OIDCIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.OIDCIdentityProvider), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeOIDCIdentityProviders) UpdateStatus(ctx context.Context, oIDCIdentityProvider *v1beta1.OIDCIdentityProvider, opts v1.UpdateOptions) (*v1beta1.OIDCIdentityProvider, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(oidcidentityprovidersResource, "status", c.ns, oIDCIdentityProvider), &v1beta1.OIDCIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.OIDCIdentityProvider), err
+}
+
+// Delete takes name of the oIDCIdentityProvider and deletes it. Returns an error if one occurs.
+func (c *FakeOIDCIdentityProviders) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(oidcidentityprovidersResource, c.ns, name), &v1beta1.OIDCIdentityProvider{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeOIDCIdentityProviders) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(oidcidentityprovidersResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.OIDCIdentityProviderList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched oIDCIdentityProvider.
+func (c *FakeOIDCIdentityProviders) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.OIDCIdentityProvider, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(oidcidentityprovidersResource, c.ns, name, pt, data, subresources...), &v1beta1.OIDCIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.OIDCIdentityProvider), err
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied oIDCIdentityProvider.
+func (c *FakeOIDCIdentityProviders) Apply(ctx context.Context, oIDCIdentityProvider *idpv1beta1.OIDCIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	if oIDCIdentityProvider == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(oIDCIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := oIDCIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(oidcidentityprovidersResource, c.ns, *name, types.ApplyPatchType, data), &v1beta1.OIDCIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.OIDCIdentityProvider), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeOIDCIdentityProviders) ApplyStatus(ctx context.Context, oIDCIdentityProvider *idpv1beta1.OIDCIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.OIDCIdentityProvider, err error) {
+	if oIDCIdentityProvider == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(oIDCIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := oIDCIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(oidcidentityprovidersResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1beta1.OIDCIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.OIDCIdentityProvider), err
+}
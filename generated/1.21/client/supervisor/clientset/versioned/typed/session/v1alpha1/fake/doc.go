@@ -3,5 +3,5 @@
 
 // Code generated by client-gen. DO NOT EDIT.
 
-// This package has the automatically generated fake clientset.
+// Package fake has the automatically generated clients.
 package fake
@@ -0,0 +1,75 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeSecondFactors implements SecondFactorInterface
+type FakeSecondFactors struct {
+	Fake *FakeSessionV1alpha1
+	ns   string
+}
+
+var secondfactorsResource = schema.GroupVersionResource{Group: "session.supervisor.pinniped.dev", Version: "v1alpha1", Resource: "secondfactors"}
+
+var secondfactorsKind = schema.GroupVersionKind{Group: "session.supervisor.pinniped.dev", Version: "v1alpha1", Kind: "SecondFactor"}
+
+// Get takes name of the secondFactor, and returns the corresponding secondFactor object, and an error if there is any.
+func (c *FakeSecondFactors) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.SecondFactor, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(secondfactorsResource, c.ns, name), &v1alpha1.SecondFactor{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SecondFactor), err
+}
+
+// List takes label and field selectors, and returns the list of SecondFactors that match those selectors.
+func (c *FakeSecondFactors) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.SecondFactorList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(secondfactorsResource, secondfactorsKind, c.ns, opts), &v1alpha1.SecondFactorList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.SecondFactorList{ListMeta: obj.(*v1alpha1.SecondFactorList).ListMeta}
+	for _, item := range obj.(*v1alpha1.SecondFactorList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Delete takes name of the secondFactor and deletes it. Returns an error if one occurs.
+func (c *FakeSecondFactors) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(secondfactorsResource, c.ns, name), &v1alpha1.SecondFactor{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeSecondFactors) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(secondfactorsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.SecondFactorList{})
+	return err
+}
@@ -0,0 +1,55 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.21/apis/supervisor/config/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SupervisorConfigLister helps list SupervisorConfigs.
+// All objects returned here must be treated as read-only.
+type SupervisorConfigLister interface {
+	// List lists all SupervisorConfigs in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.SupervisorConfig, err error)
+	// Get retrieves the SupervisorConfig from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.SupervisorConfig, error)
+	SupervisorConfigListerExpansion
+}
+
+// supervisorConfigLister implements the SupervisorConfigLister interface.
+type supervisorConfigLister struct {
+	indexer cache.Indexer
+}
+
+// NewSupervisorConfigLister returns a new SupervisorConfigLister.
+func NewSupervisorConfigLister(indexer cache.Indexer) SupervisorConfigLister {
+	return &supervisorConfigLister{indexer: indexer}
+}
+
+// List lists all SupervisorConfigs in the indexer.
+func (s *supervisorConfigLister) List(selector labels.Selector) (ret []*v1alpha1.SupervisorConfig, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.SupervisorConfig))
+	})
+	return ret, err
+}
+
+// Get retrieves the SupervisorConfig from the index for a given name.
+func (s *supervisorConfigLister) Get(name string) (*v1alpha1.SupervisorConfig, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("supervisorconfig"), name)
+	}
+	return obj.(*v1alpha1.SupervisorConfig), nil
+}
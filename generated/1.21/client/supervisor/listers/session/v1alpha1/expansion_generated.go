@@ -0,0 +1,22 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// DownstreamSessionListerExpansion allows custom methods to be added to
+// DownstreamSessionLister.
+type DownstreamSessionListerExpansion interface{}
+
+// DownstreamSessionNamespaceListerExpansion allows custom methods to be added to
+// DownstreamSessionNamespaceLister.
+type DownstreamSessionNamespaceListerExpansion interface{}
+
+// SecondFactorListerExpansion allows custom methods to be added to
+// SecondFactorLister.
+type SecondFactorListerExpansion interface{}
+
+// SecondFactorNamespaceListerExpansion allows custom methods to be added to
+// SecondFactorNamespaceLister.
+type SecondFactorNamespaceListerExpansion interface{}
@@ -18,10 +18,17 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
+		"go.pinniped.dev/generated/1.21/apis/supervisor/clientsecret/v1alpha1.OIDCClientSecret":              schema_apis_supervisor_clientsecret_v1alpha1_OIDCClientSecret(ref),
 		"go.pinniped.dev/generated/1.21/apis/supervisor/clientsecret/v1alpha1.OIDCClientSecretRequest":       schema_apis_supervisor_clientsecret_v1alpha1_OIDCClientSecretRequest(ref),
 		"go.pinniped.dev/generated/1.21/apis/supervisor/clientsecret/v1alpha1.OIDCClientSecretRequestList":   schema_apis_supervisor_clientsecret_v1alpha1_OIDCClientSecretRequestList(ref),
 		"go.pinniped.dev/generated/1.21/apis/supervisor/clientsecret/v1alpha1.OIDCClientSecretRequestSpec":   schema_apis_supervisor_clientsecret_v1alpha1_OIDCClientSecretRequestSpec(ref),
 		"go.pinniped.dev/generated/1.21/apis/supervisor/clientsecret/v1alpha1.OIDCClientSecretRequestStatus": schema_apis_supervisor_clientsecret_v1alpha1_OIDCClientSecretRequestStatus(ref),
+		"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.DownstreamSession":                  schema_apis_supervisor_session_v1alpha1_DownstreamSession(ref),
+		"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.DownstreamSessionList":              schema_apis_supervisor_session_v1alpha1_DownstreamSessionList(ref),
+		"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.DownstreamSessionStatus":            schema_apis_supervisor_session_v1alpha1_DownstreamSessionStatus(ref),
+		"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.SecondFactor":                       schema_apis_supervisor_session_v1alpha1_SecondFactor(ref),
+		"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.SecondFactorList":                   schema_apis_supervisor_session_v1alpha1_SecondFactorList(ref),
+		"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.SecondFactorStatus":                 schema_apis_supervisor_session_v1alpha1_SecondFactorStatus(ref),
 		"k8s.io/apimachinery/pkg/apis/meta/v1.APIGroup":                                                      schema_pkg_apis_meta_v1_APIGroup(ref),
 		"k8s.io/apimachinery/pkg/apis/meta/v1.APIGroupList":                                                  schema_pkg_apis_meta_v1_APIGroupList(ref),
 		"k8s.io/apimachinery/pkg/apis/meta/v1.APIResource":                                                   schema_pkg_apis_meta_v1_APIResource(ref),
@@ -77,6 +84,48 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	}
 }
 
+func schema_apis_supervisor_clientsecret_v1alpha1_OIDCClientSecret(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OIDCClientSecret describes one of the client secrets associated with an OIDCClient.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"id": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ID identifies the client secret, e.g. to revoke it using spec.revokeSecrets.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"creationTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CreationTimestamp is when the client secret was generated. It is not set for client secrets which were generated before the Supervisor started to keep track of it.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastUsedTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastUsedTimestamp is approximately when the client secret was last used to authenticate the client. It is updated at most once per hour, and is not set when the client secret has not been used since the Supervisor started to keep track of it.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"expirationTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpirationTimestamp is when the client secret expires, according to spec.expiresIn of the OIDCClientSecretRequest which generated it and spec.clientSecretPolicy.maxAge of the OIDCClient. It is not set when the client secret does not expire. Expired client secrets can no longer be used to authenticate the client.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"id"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_apis_supervisor_clientsecret_v1alpha1_OIDCClientSecretRequest(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -198,9 +247,37 @@ func schema_apis_supervisor_clientsecret_v1alpha1_OIDCClientSecretRequestSpec(re
 							Format:      "",
 						},
 					},
+					"revokeSecrets": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Revoke the client secrets with these IDs, as listed in status.secrets of a previous OIDCClientSecretRequest, associated with the OIDCClient referenced by the metadata.name field.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"expiresIn": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpiresIn is how long the new client secret can be used, e.g. \"720h\" for 30 days. It may only be set when generateNewSecret is true. When not set, the new client secret does not expire, unless the OIDCClient has a spec.clientSecretPolicy.maxAge.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -226,10 +303,319 @@ func schema_apis_supervisor_clientsecret_v1alpha1_OIDCClientSecretRequestStatus(
 							Format:      "int32",
 						},
 					},
+					"secrets": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "The client secrets associated with the OIDCClient referenced by the metadata.name field, ordered from newest to oldest. The client secrets themselves are never included.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("go.pinniped.dev/generated/1.21/apis/supervisor/clientsecret/v1alpha1.OIDCClientSecret"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"totalClientSecrets"},
 			},
 		},
+		Dependencies: []string{
+			"go.pinniped.dev/generated/1.21/apis/supervisor/clientsecret/v1alpha1.OIDCClientSecret"},
+	}
+}
+
+func schema_apis_supervisor_session_v1alpha1_DownstreamSession(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DownstreamSession describes an active session which was started when a user logged in to a downstream client through the Supervisor. Deleting a DownstreamSession revokes all tokens which were issued during that session.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.DownstreamSessionStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.DownstreamSessionStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_apis_supervisor_session_v1alpha1_DownstreamSessionList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DownstreamSessionList is a list of DownstreamSession objects.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Items is a list of DownstreamSession.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.DownstreamSession"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.DownstreamSession", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_apis_supervisor_session_v1alpha1_DownstreamSessionStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Status of the DownstreamSession.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"username": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Username is the downstream username of the user who started the session.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"identityProviderName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IdentityProviderName is the name of the identity provider resource which was used to start the session.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"identityProviderType": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IdentityProviderType is the type of the identity provider resource which was used to start the session, e.g. \"oidc\", \"ldap\", or \"activedirectory\".",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"clientID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClientID is the ID of the downstream OIDC client which started the session.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"authenticationTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AuthenticationTime is the time at which the user originally logged in to start the session.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastRefreshTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastRefreshTime is the time at which the session's tokens were most recently refreshed. It will be equal to the authenticationTime until the session is refreshed for the first time.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"username", "identityProviderName", "identityProviderType", "clientID"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_apis_supervisor_session_v1alpha1_SecondFactor(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SecondFactor describes the second factors which a user has enrolled to log in through the Supervisor, i.e. their WebAuthn credentials and their TOTP secret. Deleting a SecondFactor resets all second factors of the user, so they must enroll new ones during their next login.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.SecondFactorStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.SecondFactorStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_apis_supervisor_session_v1alpha1_SecondFactorList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SecondFactorList is a list of SecondFactor objects.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Items is a list of SecondFactor.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.SecondFactor"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"go.pinniped.dev/generated/1.21/apis/supervisor/session/v1alpha1.SecondFactor", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_apis_supervisor_session_v1alpha1_SecondFactorStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Status of the SecondFactor.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"subject": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Subject is the downstream subject of the user.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"username": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Username is the downstream username of the user when they most recently enrolled a second factor.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"webAuthnCredentials": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WebAuthnCredentials is the number of WebAuthn credentials which the user has registered.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"totpEnrolled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TOTPEnrolled is true when the user has enrolled a TOTP secret.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"subject", "username"},
+			},
+		},
 	}
 }
 
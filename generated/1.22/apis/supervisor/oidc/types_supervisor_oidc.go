@@ -32,6 +32,10 @@ const (
 	// IDTokenClaimAuthorizedParty is name of the authorized party claim defined by the OIDC spec.
 	IDTokenClaimAuthorizedParty = "azp"
 
+	// IDTokenClaimActor is name of the actor claim defined by RFC8693, which identifies the party who is acting on
+	// behalf of the subject of a token which was issued by a token exchange with an actor token.
+	IDTokenClaimActor = "act"
+
 	// IDTokenClaimUsername is the name of a custom claim in the downstream ID token whose value will contain the user's
 	// username which was mapped from the upstream identity provider.
 	IDTokenClaimUsername = "username"
@@ -40,6 +44,11 @@ const (
 	// group names which were mapped from the upstream identity provider.
 	IDTokenClaimGroups = "groups"
 
+	// IDTokenClaimGroupsOverflow is the name of a custom claim in the downstream ID token which is set to true when
+	// the groups claim does not contain all the user's group names, either because the OIDCClient limits the number of
+	// groups, or because the user's group names exceed the maximum size of the groups of a session.
+	IDTokenClaimGroupsOverflow = "groupsOverflow"
+
 	// IDTokenClaimAdditionalClaims is the top level claim used to hold additional claims in the downstream ID
 	// token, if any claims are present.
 	IDTokenClaimAdditionalClaims = "additionalClaims"
@@ -53,6 +62,10 @@ const (
 	// GrantTypeTokenExchange is the name of a custom grant type for RFC8693 token exchanges.
 	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange" //nolint:gosec // this is not a credential
 
+	// GrantTypeClientCredentials is the name of the grant type for clients which get tokens for themselves, defined by
+	// the OAuth 2.0 spec.
+	GrantTypeClientCredentials = "client_credentials"
+
 	// ScopeOpenID is name of the openid scope defined by the OIDC spec.
 	ScopeOpenID = "openid"
 
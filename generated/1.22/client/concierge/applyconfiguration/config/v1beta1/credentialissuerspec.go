@@ -0,0 +1,26 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// CredentialIssuerSpecApplyConfiguration represents an declarative configuration of the CredentialIssuerSpec type for use
+// with apply.
+type CredentialIssuerSpecApplyConfiguration struct {
+	ImpersonationProxy *ImpersonationProxySpecApplyConfiguration `json:"impersonationProxy,omitempty"`
+}
+
+// CredentialIssuerSpecApplyConfiguration constructs an declarative configuration of the CredentialIssuerSpec type for use with
+// apply.
+func CredentialIssuerSpec() *CredentialIssuerSpecApplyConfiguration {
+	return &CredentialIssuerSpecApplyConfiguration{}
+}
+
+// WithImpersonationProxy sets the ImpersonationProxy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ImpersonationProxy field is set to the value of the last call.
+func (b *CredentialIssuerSpecApplyConfiguration) WithImpersonationProxy(value *ImpersonationProxySpecApplyConfiguration) *CredentialIssuerSpecApplyConfiguration {
+	b.ImpersonationProxy = value
+	return b
+}
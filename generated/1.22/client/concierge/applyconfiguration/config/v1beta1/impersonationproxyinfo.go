@@ -0,0 +1,35 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// ImpersonationProxyInfoApplyConfiguration represents an declarative configuration of the ImpersonationProxyInfo type for use
+// with apply.
+type ImpersonationProxyInfoApplyConfiguration struct {
+	Endpoint                 *string `json:"endpoint,omitempty"`
+	CertificateAuthorityData *string `json:"certificateAuthorityData,omitempty"`
+}
+
+// ImpersonationProxyInfoApplyConfiguration constructs an declarative configuration of the ImpersonationProxyInfo type for use with
+// apply.
+func ImpersonationProxyInfo() *ImpersonationProxyInfoApplyConfiguration {
+	return &ImpersonationProxyInfoApplyConfiguration{}
+}
+
+// WithEndpoint sets the Endpoint field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Endpoint field is set to the value of the last call.
+func (b *ImpersonationProxyInfoApplyConfiguration) WithEndpoint(value string) *ImpersonationProxyInfoApplyConfiguration {
+	b.Endpoint = &value
+	return b
+}
+
+// WithCertificateAuthorityData sets the CertificateAuthorityData field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CertificateAuthorityData field is set to the value of the last call.
+func (b *ImpersonationProxyInfoApplyConfiguration) WithCertificateAuthorityData(value string) *ImpersonationProxyInfoApplyConfiguration {
+	b.CertificateAuthorityData = &value
+	return b
+}
@@ -0,0 +1,48 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "go.pinniped.dev/generated/1.22/apis/concierge/config/v1beta1"
+)
+
+// ImpersonationProxySpecApplyConfiguration represents an declarative configuration of the ImpersonationProxySpec type for use
+// with apply.
+type ImpersonationProxySpecApplyConfiguration struct {
+	Mode             *v1beta1.ImpersonationProxyMode                  `json:"mode,omitempty"`
+	Service          *ImpersonationProxyServiceSpecApplyConfiguration `json:"service,omitempty"`
+	ExternalEndpoint *string                                          `json:"externalEndpoint,omitempty"`
+}
+
+// ImpersonationProxySpecApplyConfiguration constructs an declarative configuration of the ImpersonationProxySpec type for use with
+// apply.
+func ImpersonationProxySpec() *ImpersonationProxySpecApplyConfiguration {
+	return &ImpersonationProxySpecApplyConfiguration{}
+}
+
+// WithMode sets the Mode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Mode field is set to the value of the last call.
+func (b *ImpersonationProxySpecApplyConfiguration) WithMode(value v1beta1.ImpersonationProxyMode) *ImpersonationProxySpecApplyConfiguration {
+	b.Mode = &value
+	return b
+}
+
+// WithService sets the Service field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Service field is set to the value of the last call.
+func (b *ImpersonationProxySpecApplyConfiguration) WithService(value *ImpersonationProxyServiceSpecApplyConfiguration) *ImpersonationProxySpecApplyConfiguration {
+	b.Service = value
+	return b
+}
+
+// WithExternalEndpoint sets the ExternalEndpoint field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ExternalEndpoint field is set to the value of the last call.
+func (b *ImpersonationProxySpecApplyConfiguration) WithExternalEndpoint(value string) *ImpersonationProxySpecApplyConfiguration {
+	b.ExternalEndpoint = &value
+	return b
+}
@@ -7,8 +7,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.22/apis/concierge/authentication/v1alpha1"
+	authenticationv1alpha1 "go.pinniped.dev/generated/1.22/client/concierge/applyconfiguration/authentication/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -118,3 +121,46 @@ func (c *FakeWebhookAuthenticators) Patch(ctx context.Context, name string, pt t
 	}
 	return obj.(*v1alpha1.WebhookAuthenticator), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied webhookAuthenticator.
+func (c *FakeWebhookAuthenticators) Apply(ctx context.Context, webhookAuthenticator *authenticationv1alpha1.WebhookAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
+	if webhookAuthenticator == nil {
+		return nil, fmt.Errorf("webhookAuthenticator provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(webhookAuthenticator)
+	if err != nil {
+		return nil, err
+	}
+	name := webhookAuthenticator.Name
+	if name == nil {
+		return nil, fmt.Errorf("webhookAuthenticator.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(webhookauthenticatorsResource, *name, types.ApplyPatchType, data), &v1alpha1.WebhookAuthenticator{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WebhookAuthenticator), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeWebhookAuthenticators) ApplyStatus(ctx context.Context, webhookAuthenticator *authenticationv1alpha1.WebhookAuthenticatorApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WebhookAuthenticator, err error) {
+	if webhookAuthenticator == nil {
+		return nil, fmt.Errorf("webhookAuthenticator provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(webhookAuthenticator)
+	if err != nil {
+		return nil, err
+	}
+	name := webhookAuthenticator.Name
+	if name == nil {
+		return nil, fmt.Errorf("webhookAuthenticator.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(webhookauthenticatorsResource, *name, types.ApplyPatchType, data, "status"), &v1alpha1.WebhookAuthenticator{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WebhookAuthenticator), err
+}
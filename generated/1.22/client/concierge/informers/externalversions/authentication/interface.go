@@ -7,6 +7,7 @@ package authentication
 
 import (
 	v1alpha1 "go.pinniped.dev/generated/1.22/client/concierge/informers/externalversions/authentication/v1alpha1"
+	v1beta1 "go.pinniped.dev/generated/1.22/client/concierge/informers/externalversions/authentication/v1beta1"
 	internalinterfaces "go.pinniped.dev/generated/1.22/client/concierge/informers/externalversions/internalinterfaces"
 )
 
@@ -14,6 +15,8 @@ import (
 type Interface interface {
 	// V1alpha1 provides access to shared informers for resources in V1alpha1.
 	V1alpha1() v1alpha1.Interface
+	// V1beta1 provides access to shared informers for resources in V1beta1.
+	V1beta1() v1beta1.Interface
 }
 
 type group struct {
@@ -31,3 +34,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (g *group) V1alpha1() v1alpha1.Interface {
 	return v1alpha1.New(g.factory, g.namespace, g.tweakListOptions)
 }
+
+// V1beta1 returns a new v1beta1.Interface.
+func (g *group) V1beta1() v1beta1.Interface {
+	return v1beta1.New(g.factory, g.namespace, g.tweakListOptions)
+}
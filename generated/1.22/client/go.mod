@@ -5,9 +5,11 @@ go 1.13
 
 require (
 	go.pinniped.dev/generated/1.22/apis v0.0.0
+	k8s.io/api v0.22.17
 	k8s.io/apimachinery v0.22.17
 	k8s.io/client-go v0.22.17
 	k8s.io/kube-openapi v0.0.0-20211109043538-20434351676c
+	sigs.k8s.io/structured-merge-diff/v4 v4.2.1
 )
 
 replace go.pinniped.dev/generated/1.22/apis => ../apis
@@ -0,0 +1,59 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.22/apis/supervisor/config/v1alpha1"
+)
+
+// FederationDomainLoginTimeWindowApplyConfiguration represents an declarative configuration of the FederationDomainLoginTimeWindow type for use
+// with apply.
+type FederationDomainLoginTimeWindowApplyConfiguration struct {
+	Days     []v1alpha1.FederationDomainLoginWeekday `json:"days,omitempty"`
+	Start    *string                                 `json:"start,omitempty"`
+	End      *string                                 `json:"end,omitempty"`
+	TimeZone *string                                 `json:"timeZone,omitempty"`
+}
+
+// FederationDomainLoginTimeWindowApplyConfiguration constructs an declarative configuration of the FederationDomainLoginTimeWindow type for use with
+// apply.
+func FederationDomainLoginTimeWindow() *FederationDomainLoginTimeWindowApplyConfiguration {
+	return &FederationDomainLoginTimeWindowApplyConfiguration{}
+}
+
+// WithDays adds the given value to the Days field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Days field.
+func (b *FederationDomainLoginTimeWindowApplyConfiguration) WithDays(values ...v1alpha1.FederationDomainLoginWeekday) *FederationDomainLoginTimeWindowApplyConfiguration {
+	for i := range values {
+		b.Days = append(b.Days, values[i])
+	}
+	return b
+}
+
+// WithStart sets the Start field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Start field is set to the value of the last call.
+func (b *FederationDomainLoginTimeWindowApplyConfiguration) WithStart(value string) *FederationDomainLoginTimeWindowApplyConfiguration {
+	b.Start = &value
+	return b
+}
+
+// WithEnd sets the End field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the End field is set to the value of the last call.
+func (b *FederationDomainLoginTimeWindowApplyConfiguration) WithEnd(value string) *FederationDomainLoginTimeWindowApplyConfiguration {
+	b.End = &value
+	return b
+}
+
+// WithTimeZone sets the TimeZone field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TimeZone field is set to the value of the last call.
+func (b *FederationDomainLoginTimeWindowApplyConfiguration) WithTimeZone(value string) *FederationDomainLoginTimeWindowApplyConfiguration {
+	b.TimeZone = &value
+	return b
+}
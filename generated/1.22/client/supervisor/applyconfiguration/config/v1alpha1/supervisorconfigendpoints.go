@@ -0,0 +1,35 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// SupervisorConfigEndpointsApplyConfiguration represents an declarative configuration of the SupervisorConfigEndpoints type for use
+// with apply.
+type SupervisorConfigEndpointsApplyConfiguration struct {
+	HTTPS *SupervisorConfigEndpointApplyConfiguration `json:"https,omitempty"`
+	HTTP  *SupervisorConfigEndpointApplyConfiguration `json:"http,omitempty"`
+}
+
+// SupervisorConfigEndpointsApplyConfiguration constructs an declarative configuration of the SupervisorConfigEndpoints type for use with
+// apply.
+func SupervisorConfigEndpoints() *SupervisorConfigEndpointsApplyConfiguration {
+	return &SupervisorConfigEndpointsApplyConfiguration{}
+}
+
+// WithHTTPS sets the HTTPS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the HTTPS field is set to the value of the last call.
+func (b *SupervisorConfigEndpointsApplyConfiguration) WithHTTPS(value *SupervisorConfigEndpointApplyConfiguration) *SupervisorConfigEndpointsApplyConfiguration {
+	b.HTTPS = value
+	return b
+}
+
+// WithHTTP sets the HTTP field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the HTTP field is set to the value of the last call.
+func (b *SupervisorConfigEndpointsApplyConfiguration) WithHTTP(value *SupervisorConfigEndpointApplyConfiguration) *SupervisorConfigEndpointsApplyConfiguration {
+	b.HTTP = value
+	return b
+}
@@ -0,0 +1,39 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FederationDomainIssuerMigrationApplyConfiguration represents an declarative configuration of the FederationDomainIssuerMigration type for use
+// with apply.
+type FederationDomainIssuerMigrationApplyConfiguration struct {
+	PreviousIssuer *string  `json:"previousIssuer,omitempty"`
+	AcceptUntil    *v1.Time `json:"acceptUntil,omitempty"`
+}
+
+// FederationDomainIssuerMigrationApplyConfiguration constructs an declarative configuration of the FederationDomainIssuerMigration type for use with
+// apply.
+func FederationDomainIssuerMigration() *FederationDomainIssuerMigrationApplyConfiguration {
+	return &FederationDomainIssuerMigrationApplyConfiguration{}
+}
+
+// WithPreviousIssuer sets the PreviousIssuer field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PreviousIssuer field is set to the value of the last call.
+func (b *FederationDomainIssuerMigrationApplyConfiguration) WithPreviousIssuer(value string) *FederationDomainIssuerMigrationApplyConfiguration {
+	b.PreviousIssuer = &value
+	return b
+}
+
+// WithAcceptUntil sets the AcceptUntil field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AcceptUntil field is set to the value of the last call.
+func (b *FederationDomainIssuerMigrationApplyConfiguration) WithAcceptUntil(value v1.Time) *FederationDomainIssuerMigrationApplyConfiguration {
+	b.AcceptUntil = &value
+	return b
+}
@@ -0,0 +1,57 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// FederationDomainSecretsApplyConfiguration represents an declarative configuration of the FederationDomainSecrets type for use
+// with apply.
+type FederationDomainSecretsApplyConfiguration struct {
+	JWKS               *v1.LocalObjectReference `json:"jwks,omitempty"`
+	TokenSigningKey    *v1.LocalObjectReference `json:"tokenSigningKey,omitempty"`
+	StateSigningKey    *v1.LocalObjectReference `json:"stateSigningKey,omitempty"`
+	StateEncryptionKey *v1.LocalObjectReference `json:"stateEncryptionKey,omitempty"`
+}
+
+// FederationDomainSecretsApplyConfiguration constructs an declarative configuration of the FederationDomainSecrets type for use with
+// apply.
+func FederationDomainSecrets() *FederationDomainSecretsApplyConfiguration {
+	return &FederationDomainSecretsApplyConfiguration{}
+}
+
+// WithJWKS sets the JWKS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the JWKS field is set to the value of the last call.
+func (b *FederationDomainSecretsApplyConfiguration) WithJWKS(value v1.LocalObjectReference) *FederationDomainSecretsApplyConfiguration {
+	b.JWKS = &value
+	return b
+}
+
+// WithTokenSigningKey sets the TokenSigningKey field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TokenSigningKey field is set to the value of the last call.
+func (b *FederationDomainSecretsApplyConfiguration) WithTokenSigningKey(value v1.LocalObjectReference) *FederationDomainSecretsApplyConfiguration {
+	b.TokenSigningKey = &value
+	return b
+}
+
+// WithStateSigningKey sets the StateSigningKey field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the StateSigningKey field is set to the value of the last call.
+func (b *FederationDomainSecretsApplyConfiguration) WithStateSigningKey(value v1.LocalObjectReference) *FederationDomainSecretsApplyConfiguration {
+	b.StateSigningKey = &value
+	return b
+}
+
+// WithStateEncryptionKey sets the StateEncryptionKey field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the StateEncryptionKey field is set to the value of the last call.
+func (b *FederationDomainSecretsApplyConfiguration) WithStateEncryptionKey(value v1.LocalObjectReference) *FederationDomainSecretsApplyConfiguration {
+	b.StateEncryptionKey = &value
+	return b
+}
@@ -0,0 +1,69 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "go.pinniped.dev/generated/1.22/apis/supervisor/config/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FederationDomainStatusApplyConfiguration represents an declarative configuration of the FederationDomainStatus type for use
+// with apply.
+type FederationDomainStatusApplyConfiguration struct {
+	Status         *v1beta1.FederationDomainStatusCondition   `json:"status,omitempty"`
+	Message        *string                                    `json:"message,omitempty"`
+	LastUpdateTime *v1.Time                                   `json:"lastUpdateTime,omitempty"`
+	Secrets        *FederationDomainSecretsApplyConfiguration `json:"secrets,omitempty"`
+	Conditions     []v1.Condition                             `json:"conditions,omitempty"`
+}
+
+// FederationDomainStatusApplyConfiguration constructs an declarative configuration of the FederationDomainStatus type for use with
+// apply.
+func FederationDomainStatus() *FederationDomainStatusApplyConfiguration {
+	return &FederationDomainStatusApplyConfiguration{}
+}
+
+// WithStatus sets the Status field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Status field is set to the value of the last call.
+func (b *FederationDomainStatusApplyConfiguration) WithStatus(value v1beta1.FederationDomainStatusCondition) *FederationDomainStatusApplyConfiguration {
+	b.Status = &value
+	return b
+}
+
+// WithMessage sets the Message field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Message field is set to the value of the last call.
+func (b *FederationDomainStatusApplyConfiguration) WithMessage(value string) *FederationDomainStatusApplyConfiguration {
+	b.Message = &value
+	return b
+}
+
+// WithLastUpdateTime sets the LastUpdateTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastUpdateTime field is set to the value of the last call.
+func (b *FederationDomainStatusApplyConfiguration) WithLastUpdateTime(value v1.Time) *FederationDomainStatusApplyConfiguration {
+	b.LastUpdateTime = &value
+	return b
+}
+
+// WithSecrets sets the Secrets field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Secrets field is set to the value of the last call.
+func (b *FederationDomainStatusApplyConfiguration) WithSecrets(value *FederationDomainSecretsApplyConfiguration) *FederationDomainStatusApplyConfiguration {
+	b.Secrets = value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *FederationDomainStatusApplyConfiguration) WithConditions(values ...v1.Condition) *FederationDomainStatusApplyConfiguration {
+	for i := range values {
+		b.Conditions = append(b.Conditions, values[i])
+	}
+	return b
+}
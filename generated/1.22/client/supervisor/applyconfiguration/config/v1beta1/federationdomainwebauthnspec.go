@@ -0,0 +1,30 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "go.pinniped.dev/generated/1.22/apis/supervisor/config/v1beta1"
+)
+
+// FederationDomainWebAuthnSpecApplyConfiguration represents an declarative configuration of the FederationDomainWebAuthnSpec type for use
+// with apply.
+type FederationDomainWebAuthnSpecApplyConfiguration struct {
+	Enforcement *v1beta1.FederationDomainWebAuthnEnforcement `json:"enforcement,omitempty"`
+}
+
+// FederationDomainWebAuthnSpecApplyConfiguration constructs an declarative configuration of the FederationDomainWebAuthnSpec type for use with
+// apply.
+func FederationDomainWebAuthnSpec() *FederationDomainWebAuthnSpecApplyConfiguration {
+	return &FederationDomainWebAuthnSpecApplyConfiguration{}
+}
+
+// WithEnforcement sets the Enforcement field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Enforcement field is set to the value of the last call.
+func (b *FederationDomainWebAuthnSpecApplyConfiguration) WithEnforcement(value v1beta1.FederationDomainWebAuthnEnforcement) *FederationDomainWebAuthnSpecApplyConfiguration {
+	b.Enforcement = &value
+	return b
+}
@@ -0,0 +1,37 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// OIDCClientGroupsClaimApplyConfiguration represents an declarative configuration of the OIDCClientGroupsClaim type for use
+// with apply.
+type OIDCClientGroupsClaimApplyConfiguration struct {
+	AllowedPatterns []string `json:"allowedPatterns,omitempty"`
+	MaxGroups       *int32   `json:"maxGroups,omitempty"`
+}
+
+// OIDCClientGroupsClaimApplyConfiguration constructs an declarative configuration of the OIDCClientGroupsClaim type for use with
+// apply.
+func OIDCClientGroupsClaim() *OIDCClientGroupsClaimApplyConfiguration {
+	return &OIDCClientGroupsClaimApplyConfiguration{}
+}
+
+// WithAllowedPatterns adds the given value to the AllowedPatterns field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedPatterns field.
+func (b *OIDCClientGroupsClaimApplyConfiguration) WithAllowedPatterns(values ...string) *OIDCClientGroupsClaimApplyConfiguration {
+	for i := range values {
+		b.AllowedPatterns = append(b.AllowedPatterns, values[i])
+	}
+	return b
+}
+
+// WithMaxGroups sets the MaxGroups field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxGroups field is set to the value of the last call.
+func (b *OIDCClientGroupsClaimApplyConfiguration) WithMaxGroups(value int32) *OIDCClientGroupsClaimApplyConfiguration {
+	b.MaxGroups = &value
+	return b
+}
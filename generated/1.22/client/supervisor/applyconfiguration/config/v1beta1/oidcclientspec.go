@@ -0,0 +1,101 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "go.pinniped.dev/generated/1.22/apis/supervisor/config/v1beta1"
+)
+
+// OIDCClientSpecApplyConfiguration represents an declarative configuration of the OIDCClientSpec type for use
+// with apply.
+type OIDCClientSpecApplyConfiguration struct {
+	AllowedRedirectURIs []v1beta1.RedirectURI                     `json:"allowedRedirectURIs,omitempty"`
+	AllowedGrantTypes   []v1beta1.GrantType                       `json:"allowedGrantTypes,omitempty"`
+	AllowedScopes       []v1beta1.Scope                           `json:"allowedScopes,omitempty"`
+	RequiredGroups      []string                                  `json:"requiredGroups,omitempty"`
+	GroupsClaim         *OIDCClientGroupsClaimApplyConfiguration  `json:"groupsClaim,omitempty"`
+	ClientCredentials   *OIDCClientCredentialsApplyConfiguration  `json:"clientCredentials,omitempty"`
+	ClientSecretPolicy  *OIDCClientSecretPolicyApplyConfiguration `json:"clientSecretPolicy,omitempty"`
+	TokenProfile        *OIDCClientTokenProfileApplyConfiguration `json:"tokenProfile,omitempty"`
+}
+
+// OIDCClientSpecApplyConfiguration constructs an declarative configuration of the OIDCClientSpec type for use with
+// apply.
+func OIDCClientSpec() *OIDCClientSpecApplyConfiguration {
+	return &OIDCClientSpecApplyConfiguration{}
+}
+
+// WithAllowedRedirectURIs adds the given value to the AllowedRedirectURIs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedRedirectURIs field.
+func (b *OIDCClientSpecApplyConfiguration) WithAllowedRedirectURIs(values ...v1beta1.RedirectURI) *OIDCClientSpecApplyConfiguration {
+	for i := range values {
+		b.AllowedRedirectURIs = append(b.AllowedRedirectURIs, values[i])
+	}
+	return b
+}
+
+// WithAllowedGrantTypes adds the given value to the AllowedGrantTypes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedGrantTypes field.
+func (b *OIDCClientSpecApplyConfiguration) WithAllowedGrantTypes(values ...v1beta1.GrantType) *OIDCClientSpecApplyConfiguration {
+	for i := range values {
+		b.AllowedGrantTypes = append(b.AllowedGrantTypes, values[i])
+	}
+	return b
+}
+
+// WithAllowedScopes adds the given value to the AllowedScopes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedScopes field.
+func (b *OIDCClientSpecApplyConfiguration) WithAllowedScopes(values ...v1beta1.Scope) *OIDCClientSpecApplyConfiguration {
+	for i := range values {
+		b.AllowedScopes = append(b.AllowedScopes, values[i])
+	}
+	return b
+}
+
+// WithRequiredGroups adds the given value to the RequiredGroups field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the RequiredGroups field.
+func (b *OIDCClientSpecApplyConfiguration) WithRequiredGroups(values ...string) *OIDCClientSpecApplyConfiguration {
+	for i := range values {
+		b.RequiredGroups = append(b.RequiredGroups, values[i])
+	}
+	return b
+}
+
+// WithGroupsClaim sets the GroupsClaim field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GroupsClaim field is set to the value of the last call.
+func (b *OIDCClientSpecApplyConfiguration) WithGroupsClaim(value *OIDCClientGroupsClaimApplyConfiguration) *OIDCClientSpecApplyConfiguration {
+	b.GroupsClaim = value
+	return b
+}
+
+// WithClientCredentials sets the ClientCredentials field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ClientCredentials field is set to the value of the last call.
+func (b *OIDCClientSpecApplyConfiguration) WithClientCredentials(value *OIDCClientCredentialsApplyConfiguration) *OIDCClientSpecApplyConfiguration {
+	b.ClientCredentials = value
+	return b
+}
+
+// WithClientSecretPolicy sets the ClientSecretPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ClientSecretPolicy field is set to the value of the last call.
+func (b *OIDCClientSpecApplyConfiguration) WithClientSecretPolicy(value *OIDCClientSecretPolicyApplyConfiguration) *OIDCClientSpecApplyConfiguration {
+	b.ClientSecretPolicy = value
+	return b
+}
+
+// WithTokenProfile sets the TokenProfile field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TokenProfile field is set to the value of the last call.
+func (b *OIDCClientSpecApplyConfiguration) WithTokenProfile(value *OIDCClientTokenProfileApplyConfiguration) *OIDCClientSpecApplyConfiguration {
+	b.TokenProfile = value
+	return b
+}
@@ -0,0 +1,35 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// LDAPIdentityProviderUserSearchAttributesApplyConfiguration represents an declarative configuration of the LDAPIdentityProviderUserSearchAttributes type for use
+// with apply.
+type LDAPIdentityProviderUserSearchAttributesApplyConfiguration struct {
+	Username *string `json:"username,omitempty"`
+	UID      *string `json:"uid,omitempty"`
+}
+
+// LDAPIdentityProviderUserSearchAttributesApplyConfiguration constructs an declarative configuration of the LDAPIdentityProviderUserSearchAttributes type for use with
+// apply.
+func LDAPIdentityProviderUserSearchAttributes() *LDAPIdentityProviderUserSearchAttributesApplyConfiguration {
+	return &LDAPIdentityProviderUserSearchAttributesApplyConfiguration{}
+}
+
+// WithUsername sets the Username field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Username field is set to the value of the last call.
+func (b *LDAPIdentityProviderUserSearchAttributesApplyConfiguration) WithUsername(value string) *LDAPIdentityProviderUserSearchAttributesApplyConfiguration {
+	b.Username = &value
+	return b
+}
+
+// WithUID sets the UID field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UID field is set to the value of the last call.
+func (b *LDAPIdentityProviderUserSearchAttributesApplyConfiguration) WithUID(value string) *LDAPIdentityProviderUserSearchAttributesApplyConfiguration {
+	b.UID = &value
+	return b
+}
@@ -0,0 +1,26 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// TLSSpecApplyConfiguration represents an declarative configuration of the TLSSpec type for use
+// with apply.
+type TLSSpecApplyConfiguration struct {
+	CertificateAuthorityData *string `json:"certificateAuthorityData,omitempty"`
+}
+
+// TLSSpecApplyConfiguration constructs an declarative configuration of the TLSSpec type for use with
+// apply.
+func TLSSpec() *TLSSpecApplyConfiguration {
+	return &TLSSpecApplyConfiguration{}
+}
+
+// WithCertificateAuthorityData sets the CertificateAuthorityData field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CertificateAuthorityData field is set to the value of the last call.
+func (b *TLSSpecApplyConfiguration) WithCertificateAuthorityData(value string) *TLSSpecApplyConfiguration {
+	b.CertificateAuthorityData = &value
+	return b
+}
@@ -10,7 +10,10 @@ import (
 
 	clientsecretv1alpha1 "go.pinniped.dev/generated/1.22/client/supervisor/clientset/versioned/typed/clientsecret/v1alpha1"
 	configv1alpha1 "go.pinniped.dev/generated/1.22/client/supervisor/clientset/versioned/typed/config/v1alpha1"
+	configv1beta1 "go.pinniped.dev/generated/1.22/client/supervisor/clientset/versioned/typed/config/v1beta1"
 	idpv1alpha1 "go.pinniped.dev/generated/1.22/client/supervisor/clientset/versioned/typed/idp/v1alpha1"
+	idpv1beta1 "go.pinniped.dev/generated/1.22/client/supervisor/clientset/versioned/typed/idp/v1beta1"
+	sessionv1alpha1 "go.pinniped.dev/generated/1.22/client/supervisor/clientset/versioned/typed/session/v1alpha1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -20,7 +23,10 @@ type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	ClientsecretV1alpha1() clientsecretv1alpha1.ClientsecretV1alpha1Interface
 	ConfigV1alpha1() configv1alpha1.ConfigV1alpha1Interface
+	ConfigV1beta1() configv1beta1.ConfigV1beta1Interface
 	IDPV1alpha1() idpv1alpha1.IDPV1alpha1Interface
+	IDPV1beta1() idpv1beta1.IDPV1beta1Interface
+	SessionV1alpha1() sessionv1alpha1.SessionV1alpha1Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -29,7 +35,10 @@ type Clientset struct {
 	*discovery.DiscoveryClient
 	clientsecretV1alpha1 *clientsecretv1alpha1.ClientsecretV1alpha1Client
 	configV1alpha1       *configv1alpha1.ConfigV1alpha1Client
+	configV1beta1        *configv1beta1.ConfigV1beta1Client
 	iDPV1alpha1          *idpv1alpha1.IDPV1alpha1Client
+	iDPV1beta1           *idpv1beta1.IDPV1beta1Client
+	sessionV1alpha1      *sessionv1alpha1.SessionV1alpha1Client
 }
 
 // ClientsecretV1alpha1 retrieves the ClientsecretV1alpha1Client
@@ -42,11 +51,26 @@ func (c *Clientset) ConfigV1alpha1() configv1alpha1.ConfigV1alpha1Interface {
 	return c.configV1alpha1
 }
 
+// ConfigV1beta1 retrieves the ConfigV1beta1Client
+func (c *Clientset) ConfigV1beta1() configv1beta1.ConfigV1beta1Interface {
+	return c.configV1beta1
+}
+
 // IDPV1alpha1 retrieves the IDPV1alpha1Client
 func (c *Clientset) IDPV1alpha1() idpv1alpha1.IDPV1alpha1Interface {
 	return c.iDPV1alpha1
 }
 
+// IDPV1beta1 retrieves the IDPV1beta1Client
+func (c *Clientset) IDPV1beta1() idpv1beta1.IDPV1beta1Interface {
+	return c.iDPV1beta1
+}
+
+// SessionV1alpha1 retrieves the SessionV1alpha1Client
+func (c *Clientset) SessionV1alpha1() sessionv1alpha1.SessionV1alpha1Interface {
+	return c.sessionV1alpha1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -76,10 +100,22 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.configV1beta1, err = configv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.iDPV1alpha1, err = idpv1alpha1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
 	}
+	cs.iDPV1beta1, err = idpv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.sessionV1alpha1, err = sessionv1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -94,7 +130,10 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.clientsecretV1alpha1 = clientsecretv1alpha1.NewForConfigOrDie(c)
 	cs.configV1alpha1 = configv1alpha1.NewForConfigOrDie(c)
+	cs.configV1beta1 = configv1beta1.NewForConfigOrDie(c)
 	cs.iDPV1alpha1 = idpv1alpha1.NewForConfigOrDie(c)
+	cs.iDPV1beta1 = idpv1beta1.NewForConfigOrDie(c)
+	cs.sessionV1alpha1 = sessionv1alpha1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -105,7 +144,10 @@ func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.clientsecretV1alpha1 = clientsecretv1alpha1.New(c)
 	cs.configV1alpha1 = configv1alpha1.New(c)
+	cs.configV1beta1 = configv1beta1.New(c)
 	cs.iDPV1alpha1 = idpv1alpha1.New(c)
+	cs.iDPV1beta1 = idpv1beta1.New(c)
+	cs.sessionV1alpha1 = sessionv1alpha1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -8,3 +8,5 @@ package v1alpha1
 type FederationDomainExpansion interface{}
 
 type OIDCClientExpansion interface{}
+
+type SupervisorConfigExpansion interface{}
@@ -0,0 +1,243 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+	"time"
+
+	v1beta1 "go.pinniped.dev/generated/1.22/apis/supervisor/config/v1beta1"
+	configv1beta1 "go.pinniped.dev/generated/1.22/client/supervisor/applyconfiguration/config/v1beta1"
+	scheme "go.pinniped.dev/generated/1.22/client/supervisor/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// FederationDomainsGetter has a method to return a FederationDomainInterface.
+// A group's client should implement this interface.
+type FederationDomainsGetter interface {
+	FederationDomains(namespace string) FederationDomainInterface
+}
+
+// FederationDomainInterface has methods to work with FederationDomain resources.
+type FederationDomainInterface interface {
+	Create(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.CreateOptions) (*v1beta1.FederationDomain, error)
+	Update(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (*v1beta1.FederationDomain, error)
+	UpdateStatus(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (*v1beta1.FederationDomain, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.FederationDomain, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.FederationDomainList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.FederationDomain, err error)
+	Apply(ctx context.Context, federationDomain *configv1beta1.FederationDomainApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.FederationDomain, err error)
+	ApplyStatus(ctx context.Context, federationDomain *configv1beta1.FederationDomainApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.FederationDomain, err error)
+	FederationDomainExpansion
+}
+
+// federationDomains implements FederationDomainInterface
+type federationDomains struct {
+	client rest.Interface
+	ns     string
+}
+
+// newFederationDomains returns a FederationDomains
+func newFederationDomains(c *ConfigV1beta1Client, namespace string) *federationDomains {
+	return &federationDomains{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the federationDomain, and returns the corresponding federationDomain object, and an error if there is any.
+func (c *federationDomains) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("federationdomains").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of FederationDomains that match those selectors.
+func (c *federationDomains) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.FederationDomainList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.FederationDomainList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("federationdomains").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested federationDomains.
+func (c *federationDomains) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("federationdomains").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a federationDomain and creates it.  Returns the server's representation of the federationDomain, and an error, if there is any.
+func (c *federationDomains) Create(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.CreateOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("federationdomains").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(federationDomain).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a federationDomain and updates it. Returns the server's representation of the federationDomain, and an error, if there is any.
+func (c *federationDomains) Update(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("federationdomains").
+		Name(federationDomain.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(federationDomain).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *federationDomains) UpdateStatus(ctx context.Context, federationDomain *v1beta1.FederationDomain, opts v1.UpdateOptions) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("federationdomains").
+		Name(federationDomain.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(federationDomain).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the federationDomain and deletes it. Returns an error if one occurs.
+func (c *federationDomains) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("federationdomains").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *federationDomains) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("federationdomains").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched federationDomain.
+func (c *federationDomains) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.FederationDomain, err error) {
+	result = &v1beta1.FederationDomain{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("federationdomains").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied federationDomain.
+func (c *federationDomains) Apply(ctx context.Context, federationDomain *configv1beta1.FederationDomainApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.FederationDomain, err error) {
+	if federationDomain == nil {
+		return nil, fmt.Errorf("federationDomain provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(federationDomain)
+	if err != nil {
+		return nil, err
+	}
+	name := federationDomain.Name
+	if name == nil {
+		return nil, fmt.Errorf("federationDomain.Name must be provided to Apply")
+	}
+	result = &v1beta1.FederationDomain{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("federationdomains").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *federationDomains) ApplyStatus(ctx context.Context, federationDomain *configv1beta1.FederationDomainApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.FederationDomain, err error) {
+	if federationDomain == nil {
+		return nil, fmt.Errorf("federationDomain provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(federationDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	name := federationDomain.Name
+	if name == nil {
+		return nil, fmt.Errorf("federationDomain.Name must be provided to Apply")
+	}
+
+	result = &v1beta1.FederationDomain{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("federationdomains").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -7,9 +7,12 @@ package v1alpha1
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 	"time"
 
 	v1alpha1 "go.pinniped.dev/generated/1.22/apis/supervisor/idp/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/1.22/client/supervisor/applyconfiguration/idp/v1alpha1"
 	scheme "go.pinniped.dev/generated/1.22/client/supervisor/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -34,6 +37,8 @@ type ActiveDirectoryIdentityProviderInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.ActiveDirectoryIdentityProviderList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error)
+	Apply(ctx context.Context, activeDirectoryIdentityProvider *idpv1alpha1.ActiveDirectoryIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error)
+	ApplyStatus(ctx context.Context, activeDirectoryIdentityProvider *idpv1alpha1.ActiveDirectoryIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error)
 	ActiveDirectoryIdentityProviderExpansion
 }
 
@@ -180,3 +185,59 @@ func (c *activeDirectoryIdentityProviders) Patch(ctx context.Context, name strin
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied activeDirectoryIdentityProvider.
+func (c *activeDirectoryIdentityProviders) Apply(ctx context.Context, activeDirectoryIdentityProvider *idpv1alpha1.ActiveDirectoryIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+	if activeDirectoryIdentityProvider == nil {
+		return nil, fmt.Errorf("activeDirectoryIdentityProvider provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(activeDirectoryIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := activeDirectoryIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("activeDirectoryIdentityProvider.Name must be provided to Apply")
+	}
+	result = &v1alpha1.ActiveDirectoryIdentityProvider{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("activedirectoryidentityproviders").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *activeDirectoryIdentityProviders) ApplyStatus(ctx context.Context, activeDirectoryIdentityProvider *idpv1alpha1.ActiveDirectoryIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.ActiveDirectoryIdentityProvider, err error) {
+	if activeDirectoryIdentityProvider == nil {
+		return nil, fmt.Errorf("activeDirectoryIdentityProvider provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(activeDirectoryIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	name := activeDirectoryIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("activeDirectoryIdentityProvider.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.ActiveDirectoryIdentityProvider{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("activedirectoryidentityproviders").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
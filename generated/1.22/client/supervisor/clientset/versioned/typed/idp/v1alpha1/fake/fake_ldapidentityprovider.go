@@ -7,8 +7,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.22/apis/supervisor/idp/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/1.22/client/supervisor/applyconfiguration/idp/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -127,3 +130,48 @@ func (c *FakeLDAPIdentityProviders) Patch(ctx context.Context, name string, pt t
 	}
 	return obj.(*v1alpha1.LDAPIdentityProvider), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied lDAPIdentityProvider.
+func (c *FakeLDAPIdentityProviders) Apply(ctx context.Context, lDAPIdentityProvider *idpv1alpha1.LDAPIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
+	if lDAPIdentityProvider == nil {
+		return nil, fmt.Errorf("lDAPIdentityProvider provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(lDAPIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := lDAPIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("lDAPIdentityProvider.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(ldapidentityprovidersResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha1.LDAPIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.LDAPIdentityProvider), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeLDAPIdentityProviders) ApplyStatus(ctx context.Context, lDAPIdentityProvider *idpv1alpha1.LDAPIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.LDAPIdentityProvider, err error) {
+	if lDAPIdentityProvider == nil {
+		return nil, fmt.Errorf("lDAPIdentityProvider provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(lDAPIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := lDAPIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("lDAPIdentityProvider.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(ldapidentityprovidersResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1alpha1.LDAPIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.LDAPIdentityProvider), err
+}
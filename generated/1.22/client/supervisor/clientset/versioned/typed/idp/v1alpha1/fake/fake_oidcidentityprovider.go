@@ -7,8 +7,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1alpha1 "go.pinniped.dev/generated/1.22/apis/supervisor/idp/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/1.22/client/supervisor/applyconfiguration/idp/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -127,3 +130,48 @@ func (c *FakeOIDCIdentityProviders) Patch(ctx context.Context, name string, pt t
 	}
 	return obj.(*v1alpha1.OIDCIdentityProvider), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied oIDCIdentityProvider.
+func (c *FakeOIDCIdentityProviders) Apply(ctx context.Context, oIDCIdentityProvider *idpv1alpha1.OIDCIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
+	if oIDCIdentityProvider == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(oIDCIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := oIDCIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(oidcidentityprovidersResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha1.OIDCIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.OIDCIdentityProvider), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeOIDCIdentityProviders) ApplyStatus(ctx context.Context, oIDCIdentityProvider *idpv1alpha1.OIDCIdentityProviderApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.OIDCIdentityProvider, err error) {
+	if oIDCIdentityProvider == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(oIDCIdentityProvider)
+	if err != nil {
+		return nil, err
+	}
+	name := oIDCIdentityProvider.Name
+	if name == nil {
+		return nil, fmt.Errorf("oIDCIdentityProvider.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(oidcidentityprovidersResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1alpha1.OIDCIdentityProvider{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.OIDCIdentityProvider), err
+}
@@ -0,0 +1,86 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "go.pinniped.dev/generated/1.22/apis/supervisor/idp/v1beta1"
+	"go.pinniped.dev/generated/1.22/client/supervisor/clientset/versioned/scheme"
+	rest "k8s.io/client-go/rest"
+)
+
+type IDPV1beta1Interface interface {
+	RESTClient() rest.Interface
+	ActiveDirectoryIdentityProvidersGetter
+	LDAPIdentityProvidersGetter
+	OIDCIdentityProvidersGetter
+}
+
+// IDPV1beta1Client is used to interact with features provided by the idp.supervisor.pinniped.dev group.
+type IDPV1beta1Client struct {
+	restClient rest.Interface
+}
+
+func (c *IDPV1beta1Client) ActiveDirectoryIdentityProviders(namespace string) ActiveDirectoryIdentityProviderInterface {
+	return newActiveDirectoryIdentityProviders(c, namespace)
+}
+
+func (c *IDPV1beta1Client) LDAPIdentityProviders(namespace string) LDAPIdentityProviderInterface {
+	return newLDAPIdentityProviders(c, namespace)
+}
+
+func (c *IDPV1beta1Client) OIDCIdentityProviders(namespace string) OIDCIdentityProviderInterface {
+	return newOIDCIdentityProviders(c, namespace)
+}
+
+// NewForConfig creates a new IDPV1beta1Client for the given config.
+func NewForConfig(c *rest.Config) (*IDPV1beta1Client, error) {
+	config := *c
+	if err := setConfigDefaults(&config); err != nil {
+		return nil, err
+	}
+	client, err := rest.RESTClientFor(&config)
+	if err != nil {
+		return nil, err
+	}
+	return &IDPV1beta1Client{client}, nil
+}
+
+// NewForConfigOrDie creates a new IDPV1beta1Client for the given config and
+// panics if there is an error in the config.
+func NewForConfigOrDie(c *rest.Config) *IDPV1beta1Client {
+	client, err := NewForConfig(c)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// New creates a new IDPV1beta1Client for the given RESTClient.
+func New(c rest.Interface) *IDPV1beta1Client {
+	return &IDPV1beta1Client{c}
+}
+
+func setConfigDefaults(config *rest.Config) error {
+	gv := v1beta1.SchemeGroupVersion
+	config.GroupVersion = &gv
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+
+	if config.UserAgent == "" {
+		config.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	return nil
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *IDPV1beta1Client) RESTClient() rest.Interface {
+	if c == nil {
+		return nil
+	}
+	return c.restClient
+}
@@ -9,6 +9,7 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -60,6 +61,11 @@ func (in *ActiveDirectoryIdentityProviderBind) DeepCopy() *ActiveDirectoryIdenti
 func (in *ActiveDirectoryIdentityProviderGroupSearch) DeepCopyInto(out *ActiveDirectoryIdentityProviderGroupSearch) {
 	*out = *in
 	out.Attributes = in.Attributes
+	if in.Transformations != nil {
+		in, out := &in.Transformations, &out.Transformations
+		*out = make([]GroupTransformation, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -132,7 +138,7 @@ func (in *ActiveDirectoryIdentityProviderSpec) DeepCopyInto(out *ActiveDirectory
 	}
 	out.Bind = in.Bind
 	out.UserSearch = in.UserSearch
-	out.GroupSearch = in.GroupSearch
+	in.GroupSearch.DeepCopyInto(&out.GroupSearch)
 	return
 }
 
@@ -151,7 +157,7 @@ func (in *ActiveDirectoryIdentityProviderStatus) DeepCopyInto(out *ActiveDirecto
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -203,18 +209,17 @@ func (in *ActiveDirectoryIdentityProviderUserSearchAttributes) DeepCopy() *Activ
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Condition) DeepCopyInto(out *Condition) {
+func (in *GroupTransformation) DeepCopyInto(out *GroupTransformation) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
-func (in *Condition) DeepCopy() *Condition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupTransformation.
+func (in *GroupTransformation) DeepCopy() *GroupTransformation {
 	if in == nil {
 		return nil
 	}
-	out := new(Condition)
+	out := new(GroupTransformation)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -267,6 +272,11 @@ func (in *LDAPIdentityProviderBind) DeepCopy() *LDAPIdentityProviderBind {
 func (in *LDAPIdentityProviderGroupSearch) DeepCopyInto(out *LDAPIdentityProviderGroupSearch) {
 	*out = *in
 	out.Attributes = in.Attributes
+	if in.Transformations != nil {
+		in, out := &in.Transformations, &out.Transformations
+		*out = make([]GroupTransformation, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -339,7 +349,7 @@ func (in *LDAPIdentityProviderSpec) DeepCopyInto(out *LDAPIdentityProviderSpec)
 	}
 	out.Bind = in.Bind
 	out.UserSearch = in.UserSearch
-	out.GroupSearch = in.GroupSearch
+	in.GroupSearch.DeepCopyInto(&out.GroupSearch)
 	return
 }
 
@@ -358,7 +368,7 @@ func (in *LDAPIdentityProviderStatus) DeepCopyInto(out *LDAPIdentityProviderStat
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -564,7 +574,7 @@ func (in *OIDCIdentityProviderStatus) DeepCopyInto(out *OIDCIdentityProviderStat
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
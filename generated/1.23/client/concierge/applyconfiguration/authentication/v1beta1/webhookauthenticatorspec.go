@@ -0,0 +1,44 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// WebhookAuthenticatorSpecApplyConfiguration represents an declarative configuration of the WebhookAuthenticatorSpec type for use
+// with apply.
+type WebhookAuthenticatorSpecApplyConfiguration struct {
+	Endpoint             *string                                                     `json:"endpoint,omitempty"`
+	TLS                  *TLSSpecApplyConfiguration                                  `json:"tls,omitempty"`
+	ServiceAccountTokens *WebhookAuthenticatorServiceAccountTokensApplyConfiguration `json:"serviceAccountTokens,omitempty"`
+}
+
+// WebhookAuthenticatorSpecApplyConfiguration constructs an declarative configuration of the WebhookAuthenticatorSpec type for use with
+// apply.
+func WebhookAuthenticatorSpec() *WebhookAuthenticatorSpecApplyConfiguration {
+	return &WebhookAuthenticatorSpecApplyConfiguration{}
+}
+
+// WithEndpoint sets the Endpoint field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Endpoint field is set to the value of the last call.
+func (b *WebhookAuthenticatorSpecApplyConfiguration) WithEndpoint(value string) *WebhookAuthenticatorSpecApplyConfiguration {
+	b.Endpoint = &value
+	return b
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *WebhookAuthenticatorSpecApplyConfiguration) WithTLS(value *TLSSpecApplyConfiguration) *WebhookAuthenticatorSpecApplyConfiguration {
+	b.TLS = value
+	return b
+}
+
+// WithServiceAccountTokens sets the ServiceAccountTokens field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ServiceAccountTokens field is set to the value of the last call.
+func (b *WebhookAuthenticatorSpecApplyConfiguration) WithServiceAccountTokens(value *WebhookAuthenticatorServiceAccountTokensApplyConfiguration) *WebhookAuthenticatorSpecApplyConfiguration {
+	b.ServiceAccountTokens = value
+	return b
+}
@@ -7,9 +7,12 @@ package v1alpha1
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 	"time"
 
 	v1alpha1 "go.pinniped.dev/generated/1.23/apis/concierge/config/v1alpha1"
+	configv1alpha1 "go.pinniped.dev/generated/1.23/client/concierge/applyconfiguration/config/v1alpha1"
 	scheme "go.pinniped.dev/generated/1.23/client/concierge/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -34,6 +37,8 @@ type CredentialIssuerInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.CredentialIssuerList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.CredentialIssuer, err error)
+	Apply(ctx context.Context, credentialIssuer *configv1alpha1.CredentialIssuerApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.CredentialIssuer, err error)
+	ApplyStatus(ctx context.Context, credentialIssuer *configv1alpha1.CredentialIssuerApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.CredentialIssuer, err error)
 	CredentialIssuerExpansion
 }
 
@@ -169,3 +174,57 @@ func (c *credentialIssuers) Patch(ctx context.Context, name string, pt types.Pat
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied credentialIssuer.
+func (c *credentialIssuers) Apply(ctx context.Context, credentialIssuer *configv1alpha1.CredentialIssuerApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.CredentialIssuer, err error) {
+	if credentialIssuer == nil {
+		return nil, fmt.Errorf("credentialIssuer provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(credentialIssuer)
+	if err != nil {
+		return nil, err
+	}
+	name := credentialIssuer.Name
+	if name == nil {
+		return nil, fmt.Errorf("credentialIssuer.Name must be provided to Apply")
+	}
+	result = &v1alpha1.CredentialIssuer{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("credentialissuers").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *credentialIssuers) ApplyStatus(ctx context.Context, credentialIssuer *configv1alpha1.CredentialIssuerApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.CredentialIssuer, err error) {
+	if credentialIssuer == nil {
+		return nil, fmt.Errorf("credentialIssuer provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(credentialIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	name := credentialIssuer.Name
+	if name == nil {
+		return nil, fmt.Errorf("credentialIssuer.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.CredentialIssuer{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("credentialissuers").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
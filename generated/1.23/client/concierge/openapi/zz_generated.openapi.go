@@ -499,8 +499,17 @@ func schema_apis_concierge_identity_v1alpha1_WhoAmIRequestSpec(ref common.Refere
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Spec is always empty for a WhoAmIRequest.",
+				Description: "Spec is the optional configuration of a WhoAmIRequest.",
 				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"includeImpersonation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When true, the status also reports the original user of a request which impersonated another user through the impersonation proxy of the Concierge, e.g. to debug nested impersonation.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
 			},
 		},
 	}
@@ -520,12 +529,18 @@ func schema_apis_concierge_identity_v1alpha1_WhoAmIRequestStatus(ref common.Refe
 							Ref:         ref("go.pinniped.dev/generated/1.23/apis/concierge/identity/v1alpha1.KubernetesUserInfo"),
 						},
 					},
+					"originalUser": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The user who made the request, before the impersonation which it asked for was applied. Only set when spec.includeImpersonation is true and the request impersonated another user through the impersonation proxy of the Concierge. The Kubernetes API server does not share the original user of requests which impersonate directly on the Kubernetes API server, so only the impersonated user is reported for them.",
+							Ref:         ref("go.pinniped.dev/generated/1.23/apis/concierge/identity/v1alpha1.UserInfo"),
+						},
+					},
 				},
 				Required: []string{"kubernetesUserInfo"},
 			},
 		},
 		Dependencies: []string{
-			"go.pinniped.dev/generated/1.23/apis/concierge/identity/v1alpha1.KubernetesUserInfo"},
+			"go.pinniped.dev/generated/1.23/apis/concierge/identity/v1alpha1.KubernetesUserInfo", "go.pinniped.dev/generated/1.23/apis/concierge/identity/v1alpha1.UserInfo"},
 	}
 }
 
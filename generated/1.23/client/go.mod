@@ -5,9 +5,11 @@ go 1.13
 
 require (
 	go.pinniped.dev/generated/1.23/apis v0.0.0
+	k8s.io/api v0.23.15
 	k8s.io/apimachinery v0.23.15
 	k8s.io/client-go v0.23.15
 	k8s.io/kube-openapi v0.0.0-20211115234752-e816edb12b65
+	sigs.k8s.io/structured-merge-diff/v4 v4.2.3
 )
 
 replace go.pinniped.dev/generated/1.23/apis => ../apis
@@ -0,0 +1,26 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// FederationDomainTLSSpecApplyConfiguration represents an declarative configuration of the FederationDomainTLSSpec type for use
+// with apply.
+type FederationDomainTLSSpecApplyConfiguration struct {
+	SecretName *string `json:"secretName,omitempty"`
+}
+
+// FederationDomainTLSSpecApplyConfiguration constructs an declarative configuration of the FederationDomainTLSSpec type for use with
+// apply.
+func FederationDomainTLSSpec() *FederationDomainTLSSpecApplyConfiguration {
+	return &FederationDomainTLSSpecApplyConfiguration{}
+}
+
+// WithSecretName sets the SecretName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SecretName field is set to the value of the last call.
+func (b *FederationDomainTLSSpecApplyConfiguration) WithSecretName(value string) *FederationDomainTLSSpecApplyConfiguration {
+	b.SecretName = &value
+	return b
+}
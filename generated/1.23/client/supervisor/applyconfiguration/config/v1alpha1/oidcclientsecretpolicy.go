@@ -0,0 +1,39 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OIDCClientSecretPolicyApplyConfiguration represents an declarative configuration of the OIDCClientSecretPolicy type for use
+// with apply.
+type OIDCClientSecretPolicyApplyConfiguration struct {
+	MaxAge     *v1.Duration `json:"maxAge,omitempty"`
+	MaxSecrets *int32       `json:"maxSecrets,omitempty"`
+}
+
+// OIDCClientSecretPolicyApplyConfiguration constructs an declarative configuration of the OIDCClientSecretPolicy type for use with
+// apply.
+func OIDCClientSecretPolicy() *OIDCClientSecretPolicyApplyConfiguration {
+	return &OIDCClientSecretPolicyApplyConfiguration{}
+}
+
+// WithMaxAge sets the MaxAge field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxAge field is set to the value of the last call.
+func (b *OIDCClientSecretPolicyApplyConfiguration) WithMaxAge(value v1.Duration) *OIDCClientSecretPolicyApplyConfiguration {
+	b.MaxAge = &value
+	return b
+}
+
+// WithMaxSecrets sets the MaxSecrets field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxSecrets field is set to the value of the last call.
+func (b *OIDCClientSecretPolicyApplyConfiguration) WithMaxSecrets(value int32) *OIDCClientSecretPolicyApplyConfiguration {
+	b.MaxSecrets = &value
+	return b
+}
@@ -0,0 +1,35 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration represents an declarative configuration of the ActiveDirectoryIdentityProviderUserSearchAttributes type for use
+// with apply.
+type ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration struct {
+	Username *string `json:"username,omitempty"`
+	UID      *string `json:"uid,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration constructs an declarative configuration of the ActiveDirectoryIdentityProviderUserSearchAttributes type for use with
+// apply.
+func ActiveDirectoryIdentityProviderUserSearchAttributes() *ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration {
+	return &ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration{}
+}
+
+// WithUsername sets the Username field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Username field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration) WithUsername(value string) *ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration {
+	b.Username = &value
+	return b
+}
+
+// WithUID sets the UID field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UID field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration) WithUID(value string) *ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration {
+	b.UID = &value
+	return b
+}
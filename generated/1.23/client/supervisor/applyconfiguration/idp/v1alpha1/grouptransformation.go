@@ -0,0 +1,57 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.23/apis/supervisor/idp/v1alpha1"
+)
+
+// GroupTransformationApplyConfiguration represents an declarative configuration of the GroupTransformation type for use
+// with apply.
+type GroupTransformationApplyConfiguration struct {
+	Type        *v1alpha1.GroupTransformationType `json:"type,omitempty"`
+	Pattern     *string                           `json:"pattern,omitempty"`
+	Replacement *string                           `json:"replacement,omitempty"`
+	Expression  *string                           `json:"expression,omitempty"`
+}
+
+// GroupTransformationApplyConfiguration constructs an declarative configuration of the GroupTransformation type for use with
+// apply.
+func GroupTransformation() *GroupTransformationApplyConfiguration {
+	return &GroupTransformationApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *GroupTransformationApplyConfiguration) WithType(value v1alpha1.GroupTransformationType) *GroupTransformationApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithPattern sets the Pattern field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Pattern field is set to the value of the last call.
+func (b *GroupTransformationApplyConfiguration) WithPattern(value string) *GroupTransformationApplyConfiguration {
+	b.Pattern = &value
+	return b
+}
+
+// WithReplacement sets the Replacement field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Replacement field is set to the value of the last call.
+func (b *GroupTransformationApplyConfiguration) WithReplacement(value string) *GroupTransformationApplyConfiguration {
+	b.Replacement = &value
+	return b
+}
+
+// WithExpression sets the Expression field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Expression field is set to the value of the last call.
+func (b *GroupTransformationApplyConfiguration) WithExpression(value string) *GroupTransformationApplyConfiguration {
+	b.Expression = &value
+	return b
+}
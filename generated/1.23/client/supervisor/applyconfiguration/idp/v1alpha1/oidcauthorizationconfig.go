@@ -0,0 +1,51 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// OIDCAuthorizationConfigApplyConfiguration represents an declarative configuration of the OIDCAuthorizationConfig type for use
+// with apply.
+type OIDCAuthorizationConfigApplyConfiguration struct {
+	AdditionalScopes              []string                      `json:"additionalScopes,omitempty"`
+	AdditionalAuthorizeParameters []ParameterApplyConfiguration `json:"additionalAuthorizeParameters,omitempty"`
+	AllowPasswordGrant            *bool                         `json:"allowPasswordGrant,omitempty"`
+}
+
+// OIDCAuthorizationConfigApplyConfiguration constructs an declarative configuration of the OIDCAuthorizationConfig type for use with
+// apply.
+func OIDCAuthorizationConfig() *OIDCAuthorizationConfigApplyConfiguration {
+	return &OIDCAuthorizationConfigApplyConfiguration{}
+}
+
+// WithAdditionalScopes adds the given value to the AdditionalScopes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AdditionalScopes field.
+func (b *OIDCAuthorizationConfigApplyConfiguration) WithAdditionalScopes(values ...string) *OIDCAuthorizationConfigApplyConfiguration {
+	for i := range values {
+		b.AdditionalScopes = append(b.AdditionalScopes, values[i])
+	}
+	return b
+}
+
+// WithAdditionalAuthorizeParameters adds the given value to the AdditionalAuthorizeParameters field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AdditionalAuthorizeParameters field.
+func (b *OIDCAuthorizationConfigApplyConfiguration) WithAdditionalAuthorizeParameters(values ...*ParameterApplyConfiguration) *OIDCAuthorizationConfigApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithAdditionalAuthorizeParameters")
+		}
+		b.AdditionalAuthorizeParameters = append(b.AdditionalAuthorizeParameters, *values[i])
+	}
+	return b
+}
+
+// WithAllowPasswordGrant sets the AllowPasswordGrant field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AllowPasswordGrant field is set to the value of the last call.
+func (b *OIDCAuthorizationConfigApplyConfiguration) WithAllowPasswordGrant(value bool) *OIDCAuthorizationConfigApplyConfiguration {
+	b.AllowPasswordGrant = &value
+	return b
+}
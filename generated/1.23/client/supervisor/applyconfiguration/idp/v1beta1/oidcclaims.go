@@ -0,0 +1,50 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// OIDCClaimsApplyConfiguration represents an declarative configuration of the OIDCClaims type for use
+// with apply.
+type OIDCClaimsApplyConfiguration struct {
+	Groups                  *string           `json:"groups,omitempty"`
+	Username                *string           `json:"username,omitempty"`
+	AdditionalClaimMappings map[string]string `json:"additionalClaimMappings,omitempty"`
+}
+
+// OIDCClaimsApplyConfiguration constructs an declarative configuration of the OIDCClaims type for use with
+// apply.
+func OIDCClaims() *OIDCClaimsApplyConfiguration {
+	return &OIDCClaimsApplyConfiguration{}
+}
+
+// WithGroups sets the Groups field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Groups field is set to the value of the last call.
+func (b *OIDCClaimsApplyConfiguration) WithGroups(value string) *OIDCClaimsApplyConfiguration {
+	b.Groups = &value
+	return b
+}
+
+// WithUsername sets the Username field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Username field is set to the value of the last call.
+func (b *OIDCClaimsApplyConfiguration) WithUsername(value string) *OIDCClaimsApplyConfiguration {
+	b.Username = &value
+	return b
+}
+
+// WithAdditionalClaimMappings puts the entries into the AdditionalClaimMappings field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the AdditionalClaimMappings field,
+// overwriting an existing map entries in AdditionalClaimMappings field with the same key.
+func (b *OIDCClaimsApplyConfiguration) WithAdditionalClaimMappings(entries map[string]string) *OIDCClaimsApplyConfiguration {
+	if b.AdditionalClaimMappings == nil && len(entries) > 0 {
+		b.AdditionalClaimMappings = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.AdditionalClaimMappings[k] = v
+	}
+	return b
+}
@@ -17,6 +17,7 @@ type ConfigV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	FederationDomainsGetter
 	OIDCClientsGetter
+	SupervisorConfigsGetter
 }
 
 // ConfigV1alpha1Client is used to interact with features provided by the config.supervisor.pinniped.dev group.
@@ -32,6 +33,10 @@ func (c *ConfigV1alpha1Client) OIDCClients(namespace string) OIDCClientInterface
 	return newOIDCClients(c, namespace)
 }
 
+func (c *ConfigV1alpha1Client) SupervisorConfigs() SupervisorConfigInterface {
+	return newSupervisorConfigs(c)
+}
+
 // NewForConfig creates a new ConfigV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
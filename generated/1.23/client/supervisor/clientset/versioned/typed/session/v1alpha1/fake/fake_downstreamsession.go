@@ -0,0 +1,75 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "go.pinniped.dev/generated/1.23/apis/supervisor/session/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDownstreamSessions implements DownstreamSessionInterface
+type FakeDownstreamSessions struct {
+	Fake *FakeSessionV1alpha1
+	ns   string
+}
+
+var downstreamsessionsResource = schema.GroupVersionResource{Group: "session.supervisor.pinniped.dev", Version: "v1alpha1", Resource: "downstreamsessions"}
+
+var downstreamsessionsKind = schema.GroupVersionKind{Group: "session.supervisor.pinniped.dev", Version: "v1alpha1", Kind: "DownstreamSession"}
+
+// Get takes name of the downstreamSession, and returns the corresponding downstreamSession object, and an error if there is any.
+func (c *FakeDownstreamSessions) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.DownstreamSession, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(downstreamsessionsResource, c.ns, name), &v1alpha1.DownstreamSession{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DownstreamSession), err
+}
+
+// List takes label and field selectors, and returns the list of DownstreamSessions that match those selectors.
+func (c *FakeDownstreamSessions) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.DownstreamSessionList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(downstreamsessionsResource, downstreamsessionsKind, c.ns, opts), &v1alpha1.DownstreamSessionList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.DownstreamSessionList{ListMeta: obj.(*v1alpha1.DownstreamSessionList).ListMeta}
+	for _, item := range obj.(*v1alpha1.DownstreamSessionList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Delete takes name of the downstreamSession and deletes it. Returns an error if one occurs.
+func (c *FakeDownstreamSessions) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(downstreamsessionsResource, c.ns, name, opts), &v1alpha1.DownstreamSession{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDownstreamSessions) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(downstreamsessionsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.DownstreamSessionList{})
+	return err
+}
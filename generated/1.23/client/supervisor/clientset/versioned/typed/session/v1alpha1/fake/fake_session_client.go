@@ -0,0 +1,31 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.23/client/supervisor/clientset/versioned/typed/session/v1alpha1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeSessionV1alpha1 struct {
+	*testing.Fake
+}
+
+func (c *FakeSessionV1alpha1) DownstreamSessions(namespace string) v1alpha1.DownstreamSessionInterface {
+	return &FakeDownstreamSessions{c, namespace}
+}
+
+func (c *FakeSessionV1alpha1) SecondFactors(namespace string) v1alpha1.SecondFactorInterface {
+	return &FakeSecondFactors{c, namespace}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeSessionV1alpha1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
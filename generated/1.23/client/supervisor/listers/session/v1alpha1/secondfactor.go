@@ -0,0 +1,86 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.23/apis/supervisor/session/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SecondFactorLister helps list SecondFactors.
+// All objects returned here must be treated as read-only.
+type SecondFactorLister interface {
+	// List lists all SecondFactors in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.SecondFactor, err error)
+	// SecondFactors returns an object that can list and get SecondFactors.
+	SecondFactors(namespace string) SecondFactorNamespaceLister
+	SecondFactorListerExpansion
+}
+
+// secondFactorLister implements the SecondFactorLister interface.
+type secondFactorLister struct {
+	indexer cache.Indexer
+}
+
+// NewSecondFactorLister returns a new SecondFactorLister.
+func NewSecondFactorLister(indexer cache.Indexer) SecondFactorLister {
+	return &secondFactorLister{indexer: indexer}
+}
+
+// List lists all SecondFactors in the indexer.
+func (s *secondFactorLister) List(selector labels.Selector) (ret []*v1alpha1.SecondFactor, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.SecondFactor))
+	})
+	return ret, err
+}
+
+// SecondFactors returns an object that can list and get SecondFactors.
+func (s *secondFactorLister) SecondFactors(namespace string) SecondFactorNamespaceLister {
+	return secondFactorNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// SecondFactorNamespaceLister helps list and get SecondFactors.
+// All objects returned here must be treated as read-only.
+type SecondFactorNamespaceLister interface {
+	// List lists all SecondFactors in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.SecondFactor, err error)
+	// Get retrieves the SecondFactor from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.SecondFactor, error)
+	SecondFactorNamespaceListerExpansion
+}
+
+// secondFactorNamespaceLister implements the SecondFactorNamespaceLister
+// interface.
+type secondFactorNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all SecondFactors in the indexer for a given namespace.
+func (s secondFactorNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.SecondFactor, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.SecondFactor))
+	})
+	return ret, err
+}
+
+// Get retrieves the SecondFactor from the indexer for a given namespace and name.
+func (s secondFactorNamespaceLister) Get(name string) (*v1alpha1.SecondFactor, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("secondfactor"), name)
+	}
+	return obj.(*v1alpha1.SecondFactor), nil
+}
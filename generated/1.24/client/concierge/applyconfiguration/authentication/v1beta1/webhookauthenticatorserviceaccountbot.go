@@ -0,0 +1,55 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// WebhookAuthenticatorServiceAccountBotApplyConfiguration represents an declarative configuration of the WebhookAuthenticatorServiceAccountBot type for use
+// with apply.
+type WebhookAuthenticatorServiceAccountBotApplyConfiguration struct {
+	Namespace *string  `json:"namespace,omitempty"`
+	Name      *string  `json:"name,omitempty"`
+	Username  *string  `json:"username,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+}
+
+// WebhookAuthenticatorServiceAccountBotApplyConfiguration constructs an declarative configuration of the WebhookAuthenticatorServiceAccountBot type for use with
+// apply.
+func WebhookAuthenticatorServiceAccountBot() *WebhookAuthenticatorServiceAccountBotApplyConfiguration {
+	return &WebhookAuthenticatorServiceAccountBotApplyConfiguration{}
+}
+
+// WithNamespace sets the Namespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Namespace field is set to the value of the last call.
+func (b *WebhookAuthenticatorServiceAccountBotApplyConfiguration) WithNamespace(value string) *WebhookAuthenticatorServiceAccountBotApplyConfiguration {
+	b.Namespace = &value
+	return b
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *WebhookAuthenticatorServiceAccountBotApplyConfiguration) WithName(value string) *WebhookAuthenticatorServiceAccountBotApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithUsername sets the Username field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Username field is set to the value of the last call.
+func (b *WebhookAuthenticatorServiceAccountBotApplyConfiguration) WithUsername(value string) *WebhookAuthenticatorServiceAccountBotApplyConfiguration {
+	b.Username = &value
+	return b
+}
+
+// WithGroups adds the given value to the Groups field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Groups field.
+func (b *WebhookAuthenticatorServiceAccountBotApplyConfiguration) WithGroups(values ...string) *WebhookAuthenticatorServiceAccountBotApplyConfiguration {
+	for i := range values {
+		b.Groups = append(b.Groups, values[i])
+	}
+	return b
+}
@@ -5,9 +5,11 @@ go 1.13
 
 require (
 	go.pinniped.dev/generated/1.24/apis v0.0.0
+	k8s.io/api v0.24.9
 	k8s.io/apimachinery v0.24.9
 	k8s.io/client-go v0.24.9
 	k8s.io/kube-openapi v0.0.0-20220328201542-3ee0da9b0b42
+	sigs.k8s.io/structured-merge-diff/v4 v4.2.1
 )
 
 replace go.pinniped.dev/generated/1.24/apis => ../apis
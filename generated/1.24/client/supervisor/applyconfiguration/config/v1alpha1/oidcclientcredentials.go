@@ -0,0 +1,39 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// OIDCClientCredentialsApplyConfiguration represents an declarative configuration of the OIDCClientCredentials type for use
+// with apply.
+type OIDCClientCredentialsApplyConfiguration struct {
+	AllowedAudiences []string `json:"allowedAudiences,omitempty"`
+	AllowedScopes    []string `json:"allowedScopes,omitempty"`
+}
+
+// OIDCClientCredentialsApplyConfiguration constructs an declarative configuration of the OIDCClientCredentials type for use with
+// apply.
+func OIDCClientCredentials() *OIDCClientCredentialsApplyConfiguration {
+	return &OIDCClientCredentialsApplyConfiguration{}
+}
+
+// WithAllowedAudiences adds the given value to the AllowedAudiences field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedAudiences field.
+func (b *OIDCClientCredentialsApplyConfiguration) WithAllowedAudiences(values ...string) *OIDCClientCredentialsApplyConfiguration {
+	for i := range values {
+		b.AllowedAudiences = append(b.AllowedAudiences, values[i])
+	}
+	return b
+}
+
+// WithAllowedScopes adds the given value to the AllowedScopes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedScopes field.
+func (b *OIDCClientCredentialsApplyConfiguration) WithAllowedScopes(values ...string) *OIDCClientCredentialsApplyConfiguration {
+	for i := range values {
+		b.AllowedScopes = append(b.AllowedScopes, values[i])
+	}
+	return b
+}
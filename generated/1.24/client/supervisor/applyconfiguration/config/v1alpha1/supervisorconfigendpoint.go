@@ -0,0 +1,39 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "go.pinniped.dev/generated/1.24/apis/supervisor/config/v1alpha1"
+)
+
+// SupervisorConfigEndpointApplyConfiguration represents an declarative configuration of the SupervisorConfigEndpoint type for use
+// with apply.
+type SupervisorConfigEndpointApplyConfiguration struct {
+	Network *v1alpha1.SupervisorConfigEndpointNetwork `json:"network,omitempty"`
+	Address *string                                   `json:"address,omitempty"`
+}
+
+// SupervisorConfigEndpointApplyConfiguration constructs an declarative configuration of the SupervisorConfigEndpoint type for use with
+// apply.
+func SupervisorConfigEndpoint() *SupervisorConfigEndpointApplyConfiguration {
+	return &SupervisorConfigEndpointApplyConfiguration{}
+}
+
+// WithNetwork sets the Network field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Network field is set to the value of the last call.
+func (b *SupervisorConfigEndpointApplyConfiguration) WithNetwork(value v1alpha1.SupervisorConfigEndpointNetwork) *SupervisorConfigEndpointApplyConfiguration {
+	b.Network = &value
+	return b
+}
+
+// WithAddress sets the Address field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Address field is set to the value of the last call.
+func (b *SupervisorConfigEndpointApplyConfiguration) WithAddress(value string) *SupervisorConfigEndpointApplyConfiguration {
+	b.Address = &value
+	return b
+}
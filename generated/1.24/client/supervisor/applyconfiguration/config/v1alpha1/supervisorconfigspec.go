@@ -0,0 +1,44 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// SupervisorConfigSpecApplyConfiguration represents an declarative configuration of the SupervisorConfigSpec type for use
+// with apply.
+type SupervisorConfigSpecApplyConfiguration struct {
+	Log       *SupervisorConfigLogSpecApplyConfiguration   `json:"log,omitempty"`
+	TLS       *SupervisorConfigTLSSpecApplyConfiguration   `json:"tls,omitempty"`
+	Endpoints *SupervisorConfigEndpointsApplyConfiguration `json:"endpoints,omitempty"`
+}
+
+// SupervisorConfigSpecApplyConfiguration constructs an declarative configuration of the SupervisorConfigSpec type for use with
+// apply.
+func SupervisorConfigSpec() *SupervisorConfigSpecApplyConfiguration {
+	return &SupervisorConfigSpecApplyConfiguration{}
+}
+
+// WithLog sets the Log field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Log field is set to the value of the last call.
+func (b *SupervisorConfigSpecApplyConfiguration) WithLog(value *SupervisorConfigLogSpecApplyConfiguration) *SupervisorConfigSpecApplyConfiguration {
+	b.Log = value
+	return b
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *SupervisorConfigSpecApplyConfiguration) WithTLS(value *SupervisorConfigTLSSpecApplyConfiguration) *SupervisorConfigSpecApplyConfiguration {
+	b.TLS = value
+	return b
+}
+
+// WithEndpoints sets the Endpoints field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Endpoints field is set to the value of the last call.
+func (b *SupervisorConfigSpecApplyConfiguration) WithEndpoints(value *SupervisorConfigEndpointsApplyConfiguration) *SupervisorConfigSpecApplyConfiguration {
+	b.Endpoints = value
+	return b
+}
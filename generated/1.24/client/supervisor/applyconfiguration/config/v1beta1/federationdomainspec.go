@@ -0,0 +1,93 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// FederationDomainSpecApplyConfiguration represents an declarative configuration of the FederationDomainSpec type for use
+// with apply.
+type FederationDomainSpecApplyConfiguration struct {
+	Issuer             *string                                            `json:"issuer,omitempty"`
+	AliasHosts         []string                                           `json:"aliasHosts,omitempty"`
+	IssuerMigration    *FederationDomainIssuerMigrationApplyConfiguration `json:"issuerMigration,omitempty"`
+	AllowedCORSOrigins []string                                           `json:"allowedCORSOrigins,omitempty"`
+	TLS                *FederationDomainTLSSpecApplyConfiguration         `json:"tls,omitempty"`
+	WebAuthn           *FederationDomainWebAuthnSpecApplyConfiguration    `json:"webAuthn,omitempty"`
+	TOTP               *FederationDomainTOTPSpecApplyConfiguration        `json:"totp,omitempty"`
+	LoginPolicy        *FederationDomainLoginPolicyApplyConfiguration     `json:"loginPolicy,omitempty"`
+}
+
+// FederationDomainSpecApplyConfiguration constructs an declarative configuration of the FederationDomainSpec type for use with
+// apply.
+func FederationDomainSpec() *FederationDomainSpecApplyConfiguration {
+	return &FederationDomainSpecApplyConfiguration{}
+}
+
+// WithIssuer sets the Issuer field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Issuer field is set to the value of the last call.
+func (b *FederationDomainSpecApplyConfiguration) WithIssuer(value string) *FederationDomainSpecApplyConfiguration {
+	b.Issuer = &value
+	return b
+}
+
+// WithAliasHosts adds the given value to the AliasHosts field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AliasHosts field.
+func (b *FederationDomainSpecApplyConfiguration) WithAliasHosts(values ...string) *FederationDomainSpecApplyConfiguration {
+	for i := range values {
+		b.AliasHosts = append(b.AliasHosts, values[i])
+	}
+	return b
+}
+
+// WithIssuerMigration sets the IssuerMigration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IssuerMigration field is set to the value of the last call.
+func (b *FederationDomainSpecApplyConfiguration) WithIssuerMigration(value *FederationDomainIssuerMigrationApplyConfiguration) *FederationDomainSpecApplyConfiguration {
+	b.IssuerMigration = value
+	return b
+}
+
+// WithAllowedCORSOrigins adds the given value to the AllowedCORSOrigins field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedCORSOrigins field.
+func (b *FederationDomainSpecApplyConfiguration) WithAllowedCORSOrigins(values ...string) *FederationDomainSpecApplyConfiguration {
+	for i := range values {
+		b.AllowedCORSOrigins = append(b.AllowedCORSOrigins, values[i])
+	}
+	return b
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *FederationDomainSpecApplyConfiguration) WithTLS(value *FederationDomainTLSSpecApplyConfiguration) *FederationDomainSpecApplyConfiguration {
+	b.TLS = value
+	return b
+}
+
+// WithWebAuthn sets the WebAuthn field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WebAuthn field is set to the value of the last call.
+func (b *FederationDomainSpecApplyConfiguration) WithWebAuthn(value *FederationDomainWebAuthnSpecApplyConfiguration) *FederationDomainSpecApplyConfiguration {
+	b.WebAuthn = value
+	return b
+}
+
+// WithTOTP sets the TOTP field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TOTP field is set to the value of the last call.
+func (b *FederationDomainSpecApplyConfiguration) WithTOTP(value *FederationDomainTOTPSpecApplyConfiguration) *FederationDomainSpecApplyConfiguration {
+	b.TOTP = value
+	return b
+}
+
+// WithLoginPolicy sets the LoginPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LoginPolicy field is set to the value of the last call.
+func (b *FederationDomainSpecApplyConfiguration) WithLoginPolicy(value *FederationDomainLoginPolicyApplyConfiguration) *FederationDomainSpecApplyConfiguration {
+	b.LoginPolicy = value
+	return b
+}
@@ -0,0 +1,26 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ActiveDirectoryIdentityProviderGroupSearchAttributesApplyConfiguration represents an declarative configuration of the ActiveDirectoryIdentityProviderGroupSearchAttributes type for use
+// with apply.
+type ActiveDirectoryIdentityProviderGroupSearchAttributesApplyConfiguration struct {
+	GroupName *string `json:"groupName,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderGroupSearchAttributesApplyConfiguration constructs an declarative configuration of the ActiveDirectoryIdentityProviderGroupSearchAttributes type for use with
+// apply.
+func ActiveDirectoryIdentityProviderGroupSearchAttributes() *ActiveDirectoryIdentityProviderGroupSearchAttributesApplyConfiguration {
+	return &ActiveDirectoryIdentityProviderGroupSearchAttributesApplyConfiguration{}
+}
+
+// WithGroupName sets the GroupName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GroupName field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderGroupSearchAttributesApplyConfiguration) WithGroupName(value string) *ActiveDirectoryIdentityProviderGroupSearchAttributesApplyConfiguration {
+	b.GroupName = &value
+	return b
+}
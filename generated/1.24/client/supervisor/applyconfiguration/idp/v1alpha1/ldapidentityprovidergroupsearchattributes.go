@@ -0,0 +1,26 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// LDAPIdentityProviderGroupSearchAttributesApplyConfiguration represents an declarative configuration of the LDAPIdentityProviderGroupSearchAttributes type for use
+// with apply.
+type LDAPIdentityProviderGroupSearchAttributesApplyConfiguration struct {
+	GroupName *string `json:"groupName,omitempty"`
+}
+
+// LDAPIdentityProviderGroupSearchAttributesApplyConfiguration constructs an declarative configuration of the LDAPIdentityProviderGroupSearchAttributes type for use with
+// apply.
+func LDAPIdentityProviderGroupSearchAttributes() *LDAPIdentityProviderGroupSearchAttributesApplyConfiguration {
+	return &LDAPIdentityProviderGroupSearchAttributesApplyConfiguration{}
+}
+
+// WithGroupName sets the GroupName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GroupName field is set to the value of the last call.
+func (b *LDAPIdentityProviderGroupSearchAttributesApplyConfiguration) WithGroupName(value string) *LDAPIdentityProviderGroupSearchAttributesApplyConfiguration {
+	b.GroupName = &value
+	return b
+}
@@ -23,6 +23,10 @@ func (c *FakeConfigV1alpha1) OIDCClients(namespace string) v1alpha1.OIDCClientIn
 	return &FakeOIDCClients{c, namespace}
 }
 
+func (c *FakeConfigV1alpha1) SupervisorConfigs() v1alpha1.SupervisorConfigInterface {
+	return &FakeSupervisorConfigs{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeConfigV1alpha1) RESTClient() rest.Interface {
@@ -15,6 +15,8 @@ type Interface interface {
 	FederationDomains() FederationDomainInformer
 	// OIDCClients returns a OIDCClientInformer.
 	OIDCClients() OIDCClientInformer
+	// SupervisorConfigs returns a SupervisorConfigInformer.
+	SupervisorConfigs() SupervisorConfigInformer
 }
 
 type version struct {
@@ -37,3 +39,8 @@ func (v *version) FederationDomains() FederationDomainInformer {
 func (v *version) OIDCClients() OIDCClientInformer {
 	return &oIDCClientInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// SupervisorConfigs returns a SupervisorConfigInformer.
+func (v *version) SupervisorConfigs() SupervisorConfigInformer {
+	return &supervisorConfigInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -171,6 +171,11 @@ func (in *WhoAmIRequestSpec) DeepCopy() *WhoAmIRequestSpec {
 func (in *WhoAmIRequestStatus) DeepCopyInto(out *WhoAmIRequestStatus) {
 	*out = *in
 	in.KubernetesUserInfo.DeepCopyInto(&out.KubernetesUserInfo)
+	if in.OriginalUser != nil {
+		in, out := &in.OriginalUser, &out.OriginalUser
+		*out = new(UserInfo)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -0,0 +1,49 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// WebhookAuthenticatorServiceAccountTokensApplyConfiguration represents an declarative configuration of the WebhookAuthenticatorServiceAccountTokens type for use
+// with apply.
+type WebhookAuthenticatorServiceAccountTokensApplyConfiguration struct {
+	Audience              *string                                                   `json:"audience,omitempty"`
+	CredentialsSecretName *string                                                   `json:"credentialsSecretName,omitempty"`
+	Bots                  []WebhookAuthenticatorServiceAccountBotApplyConfiguration `json:"bots,omitempty"`
+}
+
+// WebhookAuthenticatorServiceAccountTokensApplyConfiguration constructs an declarative configuration of the WebhookAuthenticatorServiceAccountTokens type for use with
+// apply.
+func WebhookAuthenticatorServiceAccountTokens() *WebhookAuthenticatorServiceAccountTokensApplyConfiguration {
+	return &WebhookAuthenticatorServiceAccountTokensApplyConfiguration{}
+}
+
+// WithAudience sets the Audience field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Audience field is set to the value of the last call.
+func (b *WebhookAuthenticatorServiceAccountTokensApplyConfiguration) WithAudience(value string) *WebhookAuthenticatorServiceAccountTokensApplyConfiguration {
+	b.Audience = &value
+	return b
+}
+
+// WithCredentialsSecretName sets the CredentialsSecretName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CredentialsSecretName field is set to the value of the last call.
+func (b *WebhookAuthenticatorServiceAccountTokensApplyConfiguration) WithCredentialsSecretName(value string) *WebhookAuthenticatorServiceAccountTokensApplyConfiguration {
+	b.CredentialsSecretName = &value
+	return b
+}
+
+// WithBots adds the given value to the Bots field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Bots field.
+func (b *WebhookAuthenticatorServiceAccountTokensApplyConfiguration) WithBots(values ...*WebhookAuthenticatorServiceAccountBotApplyConfiguration) *WebhookAuthenticatorServiceAccountTokensApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithBots")
+		}
+		b.Bots = append(b.Bots, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,35 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// TokenCredentialRequestAPIInfoApplyConfiguration represents an declarative configuration of the TokenCredentialRequestAPIInfo type for use
+// with apply.
+type TokenCredentialRequestAPIInfoApplyConfiguration struct {
+	Server                   *string `json:"server,omitempty"`
+	CertificateAuthorityData *string `json:"certificateAuthorityData,omitempty"`
+}
+
+// TokenCredentialRequestAPIInfoApplyConfiguration constructs an declarative configuration of the TokenCredentialRequestAPIInfo type for use with
+// apply.
+func TokenCredentialRequestAPIInfo() *TokenCredentialRequestAPIInfoApplyConfiguration {
+	return &TokenCredentialRequestAPIInfoApplyConfiguration{}
+}
+
+// WithServer sets the Server field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Server field is set to the value of the last call.
+func (b *TokenCredentialRequestAPIInfoApplyConfiguration) WithServer(value string) *TokenCredentialRequestAPIInfoApplyConfiguration {
+	b.Server = &value
+	return b
+}
+
+// WithCertificateAuthorityData sets the CertificateAuthorityData field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CertificateAuthorityData field is set to the value of the last call.
+func (b *TokenCredentialRequestAPIInfoApplyConfiguration) WithCertificateAuthorityData(value string) *TokenCredentialRequestAPIInfoApplyConfiguration {
+	b.CertificateAuthorityData = &value
+	return b
+}
@@ -0,0 +1,31 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// CredentialIssuerStatusApplyConfiguration represents an declarative configuration of the CredentialIssuerStatus type for use
+// with apply.
+type CredentialIssuerStatusApplyConfiguration struct {
+	Strategies []CredentialIssuerStrategyApplyConfiguration `json:"strategies,omitempty"`
+}
+
+// CredentialIssuerStatusApplyConfiguration constructs an declarative configuration of the CredentialIssuerStatus type for use with
+// apply.
+func CredentialIssuerStatus() *CredentialIssuerStatusApplyConfiguration {
+	return &CredentialIssuerStatusApplyConfiguration{}
+}
+
+// WithStrategies adds the given value to the Strategies field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Strategies field.
+func (b *CredentialIssuerStatusApplyConfiguration) WithStrategies(values ...*CredentialIssuerStrategyApplyConfiguration) *CredentialIssuerStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithStrategies")
+		}
+		b.Strategies = append(b.Strategies, *values[i])
+	}
+	return b
+}
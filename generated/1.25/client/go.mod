@@ -5,9 +5,11 @@ go 1.13
 
 require (
 	go.pinniped.dev/generated/1.25/apis v0.0.0
+	k8s.io/api v0.25.5
 	k8s.io/apimachinery v0.25.5
 	k8s.io/client-go v0.25.5
 	k8s.io/kube-openapi v0.0.0-20220803162953-67bda5d908f1
+	sigs.k8s.io/structured-merge-diff/v4 v4.2.3
 )
 
 replace go.pinniped.dev/generated/1.25/apis => ../apis
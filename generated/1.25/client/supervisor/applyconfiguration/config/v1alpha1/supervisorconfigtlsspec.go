@@ -0,0 +1,48 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// SupervisorConfigTLSSpecApplyConfiguration represents an declarative configuration of the SupervisorConfigTLSSpec type for use
+// with apply.
+type SupervisorConfigTLSSpecApplyConfiguration struct {
+	MinVersion       *string  `json:"minVersion,omitempty"`
+	CipherSuites     []string `json:"cipherSuites,omitempty"`
+	CurvePreferences []string `json:"curvePreferences,omitempty"`
+}
+
+// SupervisorConfigTLSSpecApplyConfiguration constructs an declarative configuration of the SupervisorConfigTLSSpec type for use with
+// apply.
+func SupervisorConfigTLSSpec() *SupervisorConfigTLSSpecApplyConfiguration {
+	return &SupervisorConfigTLSSpecApplyConfiguration{}
+}
+
+// WithMinVersion sets the MinVersion field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MinVersion field is set to the value of the last call.
+func (b *SupervisorConfigTLSSpecApplyConfiguration) WithMinVersion(value string) *SupervisorConfigTLSSpecApplyConfiguration {
+	b.MinVersion = &value
+	return b
+}
+
+// WithCipherSuites adds the given value to the CipherSuites field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the CipherSuites field.
+func (b *SupervisorConfigTLSSpecApplyConfiguration) WithCipherSuites(values ...string) *SupervisorConfigTLSSpecApplyConfiguration {
+	for i := range values {
+		b.CipherSuites = append(b.CipherSuites, values[i])
+	}
+	return b
+}
+
+// WithCurvePreferences adds the given value to the CurvePreferences field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the CurvePreferences field.
+func (b *SupervisorConfigTLSSpecApplyConfiguration) WithCurvePreferences(values ...string) *SupervisorConfigTLSSpecApplyConfiguration {
+	for i := range values {
+		b.CurvePreferences = append(b.CurvePreferences, values[i])
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// LDAPIdentityProviderSpecApplyConfiguration represents an declarative configuration of the LDAPIdentityProviderSpec type for use
+// with apply.
+type LDAPIdentityProviderSpecApplyConfiguration struct {
+	Host        *string                                            `json:"host,omitempty"`
+	TLS         *TLSSpecApplyConfiguration                         `json:"tls,omitempty"`
+	Bind        *LDAPIdentityProviderBindApplyConfiguration        `json:"bind,omitempty"`
+	UserSearch  *LDAPIdentityProviderUserSearchApplyConfiguration  `json:"userSearch,omitempty"`
+	GroupSearch *LDAPIdentityProviderGroupSearchApplyConfiguration `json:"groupSearch,omitempty"`
+}
+
+// LDAPIdentityProviderSpecApplyConfiguration constructs an declarative configuration of the LDAPIdentityProviderSpec type for use with
+// apply.
+func LDAPIdentityProviderSpec() *LDAPIdentityProviderSpecApplyConfiguration {
+	return &LDAPIdentityProviderSpecApplyConfiguration{}
+}
+
+// WithHost sets the Host field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Host field is set to the value of the last call.
+func (b *LDAPIdentityProviderSpecApplyConfiguration) WithHost(value string) *LDAPIdentityProviderSpecApplyConfiguration {
+	b.Host = &value
+	return b
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *LDAPIdentityProviderSpecApplyConfiguration) WithTLS(value *TLSSpecApplyConfiguration) *LDAPIdentityProviderSpecApplyConfiguration {
+	b.TLS = value
+	return b
+}
+
+// WithBind sets the Bind field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Bind field is set to the value of the last call.
+func (b *LDAPIdentityProviderSpecApplyConfiguration) WithBind(value *LDAPIdentityProviderBindApplyConfiguration) *LDAPIdentityProviderSpecApplyConfiguration {
+	b.Bind = value
+	return b
+}
+
+// WithUserSearch sets the UserSearch field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UserSearch field is set to the value of the last call.
+func (b *LDAPIdentityProviderSpecApplyConfiguration) WithUserSearch(value *LDAPIdentityProviderUserSearchApplyConfiguration) *LDAPIdentityProviderSpecApplyConfiguration {
+	b.UserSearch = value
+	return b
+}
+
+// WithGroupSearch sets the GroupSearch field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GroupSearch field is set to the value of the last call.
+func (b *LDAPIdentityProviderSpecApplyConfiguration) WithGroupSearch(value *LDAPIdentityProviderGroupSearchApplyConfiguration) *LDAPIdentityProviderSpecApplyConfiguration {
+	b.GroupSearch = value
+	return b
+}
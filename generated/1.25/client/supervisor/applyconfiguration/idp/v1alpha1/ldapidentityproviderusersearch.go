@@ -0,0 +1,44 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// LDAPIdentityProviderUserSearchApplyConfiguration represents an declarative configuration of the LDAPIdentityProviderUserSearch type for use
+// with apply.
+type LDAPIdentityProviderUserSearchApplyConfiguration struct {
+	Base       *string                                                     `json:"base,omitempty"`
+	Filter     *string                                                     `json:"filter,omitempty"`
+	Attributes *LDAPIdentityProviderUserSearchAttributesApplyConfiguration `json:"attributes,omitempty"`
+}
+
+// LDAPIdentityProviderUserSearchApplyConfiguration constructs an declarative configuration of the LDAPIdentityProviderUserSearch type for use with
+// apply.
+func LDAPIdentityProviderUserSearch() *LDAPIdentityProviderUserSearchApplyConfiguration {
+	return &LDAPIdentityProviderUserSearchApplyConfiguration{}
+}
+
+// WithBase sets the Base field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Base field is set to the value of the last call.
+func (b *LDAPIdentityProviderUserSearchApplyConfiguration) WithBase(value string) *LDAPIdentityProviderUserSearchApplyConfiguration {
+	b.Base = &value
+	return b
+}
+
+// WithFilter sets the Filter field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Filter field is set to the value of the last call.
+func (b *LDAPIdentityProviderUserSearchApplyConfiguration) WithFilter(value string) *LDAPIdentityProviderUserSearchApplyConfiguration {
+	b.Filter = &value
+	return b
+}
+
+// WithAttributes sets the Attributes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Attributes field is set to the value of the last call.
+func (b *LDAPIdentityProviderUserSearchApplyConfiguration) WithAttributes(value *LDAPIdentityProviderUserSearchAttributesApplyConfiguration) *LDAPIdentityProviderUserSearchApplyConfiguration {
+	b.Attributes = value
+	return b
+}
@@ -0,0 +1,22 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+// FederationDomainListerExpansion allows custom methods to be added to
+// FederationDomainLister.
+type FederationDomainListerExpansion interface{}
+
+// FederationDomainNamespaceListerExpansion allows custom methods to be added to
+// FederationDomainNamespaceLister.
+type FederationDomainNamespaceListerExpansion interface{}
+
+// OIDCClientListerExpansion allows custom methods to be added to
+// OIDCClientLister.
+type OIDCClientListerExpansion interface{}
+
+// OIDCClientNamespaceListerExpansion allows custom methods to be added to
+// OIDCClientNamespaceLister.
+type OIDCClientNamespaceListerExpansion interface{}
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -31,6 +31,18 @@ type OIDCClientSecretRequestSpec struct {
 	// Revoke the old client secrets associated with the OIDCClient referenced by the metadata.name field.
 	// +optional
 	RevokeOldSecrets bool `json:"revokeOldSecrets"`
+
+	// Revoke the client secrets with these IDs, as listed in status.secrets of a previous OIDCClientSecretRequest,
+	// associated with the OIDCClient referenced by the metadata.name field.
+	// +optional
+	// +listType=set
+	RevokeSecrets []string `json:"revokeSecrets,omitempty"`
+
+	// ExpiresIn is how long the new client secret can be used, e.g. "720h" for 30 days. It may only be set when
+	// generateNewSecret is true. When not set, the new client secret does not expire, unless the OIDCClient has a
+	// spec.clientSecretPolicy.maxAge.
+	// +optional
+	ExpiresIn *metav1.Duration `json:"expiresIn,omitempty"`
 }
 
 // Status of the OIDCClientSecretRequest.
@@ -40,6 +52,36 @@ type OIDCClientSecretRequestStatus struct {
 
 	// The total number of client secrets associated with the OIDCClient referenced by the metadata.name field.
 	TotalClientSecrets int `json:"totalClientSecrets"`
+
+	// The client secrets associated with the OIDCClient referenced by the metadata.name field, ordered from newest
+	// to oldest. The client secrets themselves are never included.
+	// +optional
+	// +listType=atomic
+	Secrets []OIDCClientSecret `json:"secrets,omitempty"`
+}
+
+// OIDCClientSecret describes one of the client secrets associated with an OIDCClient.
+type OIDCClientSecret struct {
+	// ID identifies the client secret, e.g. to revoke it using spec.revokeSecrets.
+	ID string `json:"id"`
+
+	// CreationTimestamp is when the client secret was generated. It is not set for client secrets which were
+	// generated before the Supervisor started to keep track of it.
+	// +optional
+	CreationTimestamp *metav1.Time `json:"creationTimestamp,omitempty"`
+
+	// LastUsedTimestamp is approximately when the client secret was last used to authenticate the client.
+	// It is updated at most once per hour, and is not set when the client secret has not been used since the
+	// Supervisor started to keep track of it.
+	// +optional
+	LastUsedTimestamp *metav1.Time `json:"lastUsedTimestamp,omitempty"`
+
+	// ExpirationTimestamp is when the client secret expires, according to spec.expiresIn of the
+	// OIDCClientSecretRequest which generated it and spec.clientSecretPolicy.maxAge of the OIDCClient.
+	// It is not set when the client secret does not expire. Expired client secrets can no longer be used to
+	// authenticate the client.
+	// +optional
+	ExpirationTimestamp *metav1.Time `json:"expirationTimestamp,omitempty"`
 }
 
 // OIDCClientSecretRequestList is a list of OIDCClientSecretRequest objects.
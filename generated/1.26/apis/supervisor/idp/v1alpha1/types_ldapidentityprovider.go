@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -32,7 +32,7 @@ type LDAPIdentityProviderStatus struct {
 	// +patchStrategy=merge
 	// +listType=map
 	// +listMapKey=type
-	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 type LDAPIdentityProviderBind struct {
@@ -144,6 +144,15 @@ type LDAPIdentityProviderGroupSearch struct {
 	// release notes before upgrading to ensure that the meaning of this field has
 	// not changed.
 	SkipGroupRefresh bool `json:"skipGroupRefresh,omitempty"`
+
+	// Transformations is an optional ordered list of rules which transform the group names which were found by the
+	// group search, before they become the groups of the user in the Supervisor's sessions and in the ID tokens which
+	// it issues. E.g. they can strip the distinguished names of groups down to their common names, map group names
+	// to canonical names, and drop groups which are not relevant to Kubernetes clusters. Each rule is applied to the
+	// result of the previous rule. Duplicate group names which result from the transformations are removed.
+	// The transformations are applied again whenever the user's groups are refreshed.
+	// +optional
+	Transformations []GroupTransformation `json:"transformations,omitempty"`
 }
 
 // Spec for configuring an LDAP identity provider.
@@ -175,6 +184,7 @@ type LDAPIdentityProviderSpec struct {
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 type LDAPIdentityProvider struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
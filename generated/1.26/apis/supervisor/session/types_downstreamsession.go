@@ -0,0 +1,54 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DownstreamSession describes an active session which was started when a user logged in to a downstream client
+// through the Supervisor. Deleting a DownstreamSession revokes all tokens which were issued during that session.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DownstreamSession struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta // metadata.name is the session ID
+
+	// +optional
+	Status DownstreamSessionStatus
+}
+
+// Status of the DownstreamSession.
+type DownstreamSessionStatus struct {
+	// Username is the downstream username of the user who started the session.
+	Username string
+
+	// IdentityProviderName is the name of the identity provider resource which was used to start the session.
+	IdentityProviderName string
+
+	// IdentityProviderType is the type of the identity provider resource which was used to start the session,
+	// e.g. "oidc", "ldap", or "activedirectory".
+	IdentityProviderType string
+
+	// ClientID is the ID of the downstream OIDC client which started the session.
+	ClientID string
+
+	// AuthenticationTime is the time at which the user originally logged in to start the session.
+	// +optional
+	AuthenticationTime metav1.Time
+
+	// LastRefreshTime is the time at which the session's tokens were most recently refreshed.
+	// It will be equal to the authenticationTime until the session is refreshed for the first time.
+	// +optional
+	LastRefreshTime metav1.Time
+}
+
+// DownstreamSessionList is a list of DownstreamSession objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DownstreamSessionList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	// Items is a list of DownstreamSession.
+	Items []DownstreamSession
+}
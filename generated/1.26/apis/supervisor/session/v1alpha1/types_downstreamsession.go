@@ -0,0 +1,56 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DownstreamSession describes an active session which was started when a user logged in to a downstream client
+// through the Supervisor. Deleting a DownstreamSession revokes all tokens which were issued during that session.
+// +genclient
+// +genclient:onlyVerbs=get,list,delete,deleteCollection
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DownstreamSession struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"` // metadata.name is the session ID
+
+	// +optional
+	Status DownstreamSessionStatus `json:"status"`
+}
+
+// Status of the DownstreamSession.
+type DownstreamSessionStatus struct {
+	// Username is the downstream username of the user who started the session.
+	Username string `json:"username"`
+
+	// IdentityProviderName is the name of the identity provider resource which was used to start the session.
+	IdentityProviderName string `json:"identityProviderName"`
+
+	// IdentityProviderType is the type of the identity provider resource which was used to start the session,
+	// e.g. "oidc", "ldap", or "activedirectory".
+	IdentityProviderType string `json:"identityProviderType"`
+
+	// ClientID is the ID of the downstream OIDC client which started the session.
+	ClientID string `json:"clientID"`
+
+	// AuthenticationTime is the time at which the user originally logged in to start the session.
+	// +optional
+	AuthenticationTime metav1.Time `json:"authenticationTime,omitempty"`
+
+	// LastRefreshTime is the time at which the session's tokens were most recently refreshed.
+	// It will be equal to the authenticationTime until the session is refreshed for the first time.
+	// +optional
+	LastRefreshTime metav1.Time `json:"lastRefreshTime,omitempty"`
+}
+
+// DownstreamSessionList is a list of DownstreamSession objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type DownstreamSessionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of DownstreamSession.
+	Items []DownstreamSession `json:"items"`
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecondFactor describes the second factors which a user has enrolled to log in through the Supervisor, i.e. their
+// WebAuthn credentials and their TOTP secret. Deleting a SecondFactor resets all second factors of the user, so they
+// must enroll new ones during their next login.
+// +genclient
+// +genclient:onlyVerbs=get,list,delete,deleteCollection
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SecondFactor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"` // metadata.name is derived from the downstream subject of the user
+
+	// +optional
+	Status SecondFactorStatus `json:"status"`
+}
+
+// Status of the SecondFactor.
+type SecondFactorStatus struct {
+	// Subject is the downstream subject of the user.
+	Subject string `json:"subject"`
+
+	// Username is the downstream username of the user when they most recently enrolled a second factor.
+	Username string `json:"username"`
+
+	// WebAuthnCredentials is the number of WebAuthn credentials which the user has registered.
+	// +optional
+	WebAuthnCredentials int32 `json:"webAuthnCredentials,omitempty"`
+
+	// TOTPEnrolled is true when the user has enrolled a TOTP secret.
+	// +optional
+	TOTPEnrolled bool `json:"totpEnrolled,omitempty"`
+}
+
+// SecondFactorList is a list of SecondFactor objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SecondFactorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of SecondFactor.
+	Items []SecondFactor `json:"items"`
+}
@@ -0,0 +1,35 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// JWTTokenClaimsApplyConfiguration represents an declarative configuration of the JWTTokenClaims type for use
+// with apply.
+type JWTTokenClaimsApplyConfiguration struct {
+	Groups   *string `json:"groups,omitempty"`
+	Username *string `json:"username,omitempty"`
+}
+
+// JWTTokenClaimsApplyConfiguration constructs an declarative configuration of the JWTTokenClaims type for use with
+// apply.
+func JWTTokenClaims() *JWTTokenClaimsApplyConfiguration {
+	return &JWTTokenClaimsApplyConfiguration{}
+}
+
+// WithGroups sets the Groups field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Groups field is set to the value of the last call.
+func (b *JWTTokenClaimsApplyConfiguration) WithGroups(value string) *JWTTokenClaimsApplyConfiguration {
+	b.Groups = &value
+	return b
+}
+
+// WithUsername sets the Username field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Username field is set to the value of the last call.
+func (b *JWTTokenClaimsApplyConfiguration) WithUsername(value string) *JWTTokenClaimsApplyConfiguration {
+	b.Username = &value
+	return b
+}
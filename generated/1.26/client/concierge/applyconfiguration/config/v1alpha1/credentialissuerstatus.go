@@ -0,0 +1,40 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// CredentialIssuerStatusApplyConfiguration represents an declarative configuration of the CredentialIssuerStatus type for use
+// with apply.
+type CredentialIssuerStatusApplyConfiguration struct {
+	Strategies     []CredentialIssuerStrategyApplyConfiguration      `json:"strategies,omitempty"`
+	KubeConfigInfo *CredentialIssuerKubeConfigInfoApplyConfiguration `json:"kubeConfigInfo,omitempty"`
+}
+
+// CredentialIssuerStatusApplyConfiguration constructs an declarative configuration of the CredentialIssuerStatus type for use with
+// apply.
+func CredentialIssuerStatus() *CredentialIssuerStatusApplyConfiguration {
+	return &CredentialIssuerStatusApplyConfiguration{}
+}
+
+// WithStrategies adds the given value to the Strategies field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Strategies field.
+func (b *CredentialIssuerStatusApplyConfiguration) WithStrategies(values ...*CredentialIssuerStrategyApplyConfiguration) *CredentialIssuerStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithStrategies")
+		}
+		b.Strategies = append(b.Strategies, *values[i])
+	}
+	return b
+}
+
+// WithKubeConfigInfo sets the KubeConfigInfo field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the KubeConfigInfo field is set to the value of the last call.
+func (b *CredentialIssuerStatusApplyConfiguration) WithKubeConfigInfo(value *CredentialIssuerKubeConfigInfoApplyConfiguration) *CredentialIssuerStatusApplyConfiguration {
+	b.KubeConfigInfo = value
+	return b
+}
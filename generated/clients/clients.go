@@ -0,0 +1,61 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clients is a thin facade over the clients which are generated for each supported Kubernetes minor version,
+// so that Go programs can use the Concierge and Supervisor APIs without importing a version-specific package.
+//
+// The generated clients of the newest supported Kubernetes version are used by default. Programs which must use the
+// generated clients of an older version select it at build time with a build tag, e.g.:
+//
+//	go build -tags pinniped_kube_1_24 ./...
+//
+// Only the generated code which is compiled into the program changes with the build tag. The version of the
+// Kubernetes client libraries is still chosen by the go.mod file of the program. The APIs use metav1.Condition, which
+// was added in Kubernetes 1.19, so no clients are generated for older versions.
+package clients
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+)
+
+// Clientset holds the clients of the Concierge and Supervisor APIs.
+type Clientset struct {
+	Concierge  ConciergeInterface
+	Supervisor SupervisorInterface
+}
+
+// NewForConfig returns the clients of the Concierge and Supervisor APIs for the given config.
+func NewForConfig(config *rest.Config) (*Clientset, error) {
+	concierge, err := newConciergeForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Concierge client: %w", err)
+	}
+	supervisor, err := newSupervisorForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Supervisor client: %w", err)
+	}
+	return &Clientset{Concierge: concierge, Supervisor: supervisor}, nil
+}
+
+// NewConciergeInformerFactory returns a shared informer factory for the Concierge APIs.
+func NewConciergeInformerFactory(client ConciergeInterface, defaultResync time.Duration) ConciergeInformerFactory {
+	return newConciergeInformerFactory(client, defaultResync)
+}
+
+// NewSupervisorInformerFactory returns a shared informer factory for the Supervisor APIs.
+func NewSupervisorInformerFactory(client SupervisorInterface, defaultResync time.Duration) SupervisorInformerFactory {
+	return newSupervisorInformerFactory(client, defaultResync)
+}
+
+// AddToScheme adds the types of the Concierge and Supervisor APIs to the scheme, e.g. so that a controller-runtime
+// client can use them.
+func AddToScheme(scheme *runtime.Scheme) error {
+	if err := conciergeAddToScheme(scheme); err != nil {
+		return err
+	}
+	return supervisorAddToScheme(scheme)
+}
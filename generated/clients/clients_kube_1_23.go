@@ -0,0 +1,59 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build pinniped_kube_1_23
+// +build pinniped_kube_1_23
+
+package clients
+
+import (
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	conciergeclientset "go.pinniped.dev/generated/1.23/client/concierge/clientset/versioned"
+	conciergescheme "go.pinniped.dev/generated/1.23/client/concierge/clientset/versioned/scheme"
+	conciergeinformers "go.pinniped.dev/generated/1.23/client/concierge/informers/externalversions"
+	supervisorclientset "go.pinniped.dev/generated/1.23/client/supervisor/clientset/versioned"
+	supervisorscheme "go.pinniped.dev/generated/1.23/client/supervisor/clientset/versioned/scheme"
+	supervisorinformers "go.pinniped.dev/generated/1.23/client/supervisor/informers/externalversions"
+)
+
+// KubeMinorVersion is the Kubernetes minor version of the generated clients which are compiled into the program.
+const KubeMinorVersion = "1.23"
+
+type (
+	// ConciergeInterface is the client of the Concierge APIs.
+	ConciergeInterface = conciergeclientset.Interface
+
+	// SupervisorInterface is the client of the Supervisor APIs.
+	SupervisorInterface = supervisorclientset.Interface
+
+	// ConciergeInformerFactory is the shared informer factory of the Concierge APIs.
+	ConciergeInformerFactory = conciergeinformers.SharedInformerFactory
+
+	// SupervisorInformerFactory is the shared informer factory of the Supervisor APIs.
+	SupervisorInformerFactory = supervisorinformers.SharedInformerFactory
+)
+
+//nolint:gochecknoglobals
+var (
+	conciergeAddToScheme  = conciergescheme.AddToScheme
+	supervisorAddToScheme = supervisorscheme.AddToScheme
+)
+
+func newConciergeForConfig(config *rest.Config) (ConciergeInterface, error) {
+	return conciergeclientset.NewForConfig(config)
+}
+
+func newSupervisorForConfig(config *rest.Config) (SupervisorInterface, error) {
+	return supervisorclientset.NewForConfig(config)
+}
+
+func newConciergeInformerFactory(client ConciergeInterface, defaultResync time.Duration) ConciergeInformerFactory {
+	return conciergeinformers.NewSharedInformerFactory(client, defaultResync)
+}
+
+func newSupervisorInformerFactory(client SupervisorInterface, defaultResync time.Duration) SupervisorInformerFactory {
+	return supervisorinformers.NewSharedInformerFactory(client, defaultResync)
+}
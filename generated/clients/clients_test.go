@@ -0,0 +1,65 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clients
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+func TestKubeMinorVersion(t *testing.T) {
+	require.Regexp(t, `^1\.\d+$`, KubeMinorVersion)
+}
+
+func TestNewForConfig(t *testing.T) {
+	clientset, err := NewForConfig(&rest.Config{Host: "https://pinniped.example.com"})
+	require.NoError(t, err)
+	require.NotNil(t, clientset.Concierge)
+	require.NotNil(t, clientset.Supervisor)
+	require.NotNil(t, clientset.Concierge.AuthenticationV1beta1())
+	require.NotNil(t, clientset.Concierge.ConfigV1beta1())
+	require.NotNil(t, clientset.Supervisor.ConfigV1alpha1())
+	require.NotNil(t, clientset.Supervisor.ConfigV1beta1())
+	require.NotNil(t, clientset.Supervisor.IDPV1beta1())
+	require.NotNil(t, clientset.Supervisor.SessionV1alpha1())
+
+	require.NotNil(t, NewConciergeInformerFactory(clientset.Concierge, time.Minute))
+	require.NotNil(t, NewSupervisorInformerFactory(clientset.Supervisor, time.Minute))
+}
+
+func TestNewForConfigError(t *testing.T) {
+	// A QPS without a burst is rejected by the generated clientsets.
+	_, err := NewForConfig(&rest.Config{Host: "https://pinniped.example.com", QPS: 1})
+	require.EqualError(t, err, "could not create Concierge client: burst is required to be greater than 0 when RateLimiter is not set and QPS is set to greater than 0")
+}
+
+func TestAddToScheme(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, AddToScheme(scheme))
+
+	for _, group := range []string{
+		"authentication.concierge.pinniped.dev",
+		"config.concierge.pinniped.dev",
+		"login.concierge.pinniped.dev",
+		"config.supervisor.pinniped.dev",
+		"idp.supervisor.pinniped.dev",
+		"clientsecret.supervisor.pinniped.dev",
+	} {
+		require.True(t, scheme.IsGroupRegistered(group), "group %s should be registered", group)
+	}
+
+	for _, groupVersion := range []schema.GroupVersion{
+		{Group: "authentication.concierge.pinniped.dev", Version: "v1beta1"},
+		{Group: "config.concierge.pinniped.dev", Version: "v1beta1"},
+		{Group: "config.supervisor.pinniped.dev", Version: "v1beta1"},
+		{Group: "idp.supervisor.pinniped.dev", Version: "v1beta1"},
+	} {
+		require.True(t, scheme.IsVersionRegistered(groupVersion), "version %s should be registered", groupVersion)
+	}
+}
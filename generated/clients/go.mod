@@ -0,0 +1,36 @@
+module go.pinniped.dev/generated/clients
+
+go 1.13
+
+require (
+	github.com/stretchr/testify v1.8.0
+	go.pinniped.dev/generated/1.19/client v0.0.0
+	go.pinniped.dev/generated/1.20/client v0.0.0
+	go.pinniped.dev/generated/1.21/client v0.0.0
+	go.pinniped.dev/generated/1.22/client v0.0.0
+	go.pinniped.dev/generated/1.23/client v0.0.0
+	go.pinniped.dev/generated/1.24/client v0.0.0
+	go.pinniped.dev/generated/1.25/client v0.0.0
+	go.pinniped.dev/generated/1.26/client v0.0.0
+	k8s.io/apimachinery v0.26.0
+	k8s.io/client-go v0.26.0
+)
+
+replace (
+	go.pinniped.dev/generated/1.19/apis => ../1.19/apis
+	go.pinniped.dev/generated/1.19/client => ../1.19/client
+	go.pinniped.dev/generated/1.20/apis => ../1.20/apis
+	go.pinniped.dev/generated/1.20/client => ../1.20/client
+	go.pinniped.dev/generated/1.21/apis => ../1.21/apis
+	go.pinniped.dev/generated/1.21/client => ../1.21/client
+	go.pinniped.dev/generated/1.22/apis => ../1.22/apis
+	go.pinniped.dev/generated/1.22/client => ../1.22/client
+	go.pinniped.dev/generated/1.23/apis => ../1.23/apis
+	go.pinniped.dev/generated/1.23/client => ../1.23/client
+	go.pinniped.dev/generated/1.24/apis => ../1.24/apis
+	go.pinniped.dev/generated/1.24/client => ../1.24/client
+	go.pinniped.dev/generated/1.25/apis => ../1.25/apis
+	go.pinniped.dev/generated/1.25/client => ../1.25/client
+	go.pinniped.dev/generated/1.26/apis => ../1.26/apis
+	go.pinniped.dev/generated/1.26/client => ../1.26/client
+)
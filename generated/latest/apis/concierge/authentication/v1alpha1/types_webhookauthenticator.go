@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package v1alpha1
@@ -12,7 +12,7 @@ type WebhookAuthenticatorStatus struct {
 	// +patchStrategy=merge
 	// +listType=map
 	// +listMapKey=type
-	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // Spec for configuring a webhook authenticator.
@@ -25,6 +25,50 @@ type WebhookAuthenticatorSpec struct {
 	// TLS configuration.
 	// +optional
 	TLS *TLSSpec `json:"tls,omitempty"`
+
+	// Configuration for accepting projected service account tokens of another cluster, e.g. the tokens of CI bots,
+	// and for mapping them to bot identities. When this is set, the endpoint must be the URL of the TokenReview API of
+	// the Kubernetes API server which issued the tokens, e.g. https://example.com/apis/authentication.k8s.io/v1/tokenreviews.
+	// +optional
+	ServiceAccountTokens *WebhookAuthenticatorServiceAccountTokens `json:"serviceAccountTokens,omitempty"`
+}
+
+// Configuration for validating projected service account tokens with the TokenReview API of another cluster.
+type WebhookAuthenticatorServiceAccountTokens struct {
+	// Audience which the tokens must be bound to, e.g. "ci.pinniped.example.com". This audience is requested in each
+	// TokenReview, so tokens which were issued for other audiences, such as the default audience of the Kubernetes API
+	// server, are rejected.
+	// +kubebuilder:validation:MinLength=1
+	Audience string `json:"audience"`
+
+	// Name of a Secret in the namespace of the Concierge whose "token" key holds a bearer token which is allowed to
+	// create TokenReviews on the cluster of the endpoint.
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecretName string `json:"credentialsSecretName"`
+
+	// Service accounts whose tokens are accepted, and the identities which they are given. The tokens of all other
+	// service accounts are rejected.
+	// +kubebuilder:validation:MinItems=1
+	Bots []WebhookAuthenticatorServiceAccountBot `json:"bots"`
+}
+
+// A service account whose tokens are accepted, and the identity which it is given.
+type WebhookAuthenticatorServiceAccountBot struct {
+	// Namespace of the service account.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name of the service account.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Username which is given to the service account.
+	// +kubebuilder:validation:MinLength=1
+	Username string `json:"username"`
+
+	// Groups which are given to the service account.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
 }
 
 // WebhookAuthenticator describes the configuration of a webhook authenticator.
@@ -35,6 +79,7 @@ type WebhookAuthenticatorSpec struct {
 // +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.spec.endpoint`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 type WebhookAuthenticator struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
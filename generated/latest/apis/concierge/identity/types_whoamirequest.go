@@ -17,10 +17,13 @@ type WhoAmIRequest struct {
 	Status WhoAmIRequestStatus
 }
 
-// Spec is always empty for a WhoAmIRequest.
+// Spec is the optional configuration of a WhoAmIRequest.
 type WhoAmIRequestSpec struct {
-	// empty for now but we may add some config here in the future
-	// any such config must be safe in the context of an unauthenticated user
+	// Any configuration here must be safe in the context of an unauthenticated user.
+
+	// When true, the status also reports the original user of a request which impersonated another user
+	// through the impersonation proxy of the Concierge, e.g. to debug nested impersonation.
+	IncludeImpersonation bool
 }
 
 // Status is set by the server in the response to a WhoAmIRequest.
@@ -28,6 +31,12 @@ type WhoAmIRequestStatus struct {
 	// The current authenticated user, exactly as Kubernetes understands it.
 	KubernetesUserInfo KubernetesUserInfo
 
+	// The user who made the request, before the impersonation which it asked for was applied. Only set when
+	// spec.includeImpersonation is true and the request impersonated another user through the impersonation proxy
+	// of the Concierge. The Kubernetes API server does not share the original user of requests which impersonate
+	// directly on the Kubernetes API server, so only the impersonated user is reported for them.
+	OriginalUser *UserInfo
+
 	// We may add concierge specific information here in the future.
 }
 
@@ -193,6 +193,7 @@ func Convert_identity_WhoAmIRequestList_To_v1alpha1_WhoAmIRequestList(in *identi
 }
 
 func autoConvert_v1alpha1_WhoAmIRequestSpec_To_identity_WhoAmIRequestSpec(in *WhoAmIRequestSpec, out *identity.WhoAmIRequestSpec, s conversion.Scope) error {
+	out.IncludeImpersonation = in.IncludeImpersonation
 	return nil
 }
 
@@ -202,6 +203,7 @@ func Convert_v1alpha1_WhoAmIRequestSpec_To_identity_WhoAmIRequestSpec(in *WhoAmI
 }
 
 func autoConvert_identity_WhoAmIRequestSpec_To_v1alpha1_WhoAmIRequestSpec(in *identity.WhoAmIRequestSpec, out *WhoAmIRequestSpec, s conversion.Scope) error {
+	out.IncludeImpersonation = in.IncludeImpersonation
 	return nil
 }
 
@@ -214,6 +216,7 @@ func autoConvert_v1alpha1_WhoAmIRequestStatus_To_identity_WhoAmIRequestStatus(in
 	if err := Convert_v1alpha1_KubernetesUserInfo_To_identity_KubernetesUserInfo(&in.KubernetesUserInfo, &out.KubernetesUserInfo, s); err != nil {
 		return err
 	}
+	out.OriginalUser = (*identity.UserInfo)(unsafe.Pointer(in.OriginalUser))
 	return nil
 }
 
@@ -226,6 +229,7 @@ func autoConvert_identity_WhoAmIRequestStatus_To_v1alpha1_WhoAmIRequestStatus(in
 	if err := Convert_identity_KubernetesUserInfo_To_v1alpha1_KubernetesUserInfo(&in.KubernetesUserInfo, &out.KubernetesUserInfo, s); err != nil {
 		return err
 	}
+	out.OriginalUser = (*UserInfo)(unsafe.Pointer(in.OriginalUser))
 	return nil
 }
 
@@ -0,0 +1,158 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// SupervisorConfigLogLevel is the verbosity of the logs of the Supervisor.
+//
+// +kubebuilder:validation:Enum=info;debug;trace;all
+type SupervisorConfigLogLevel string
+
+// SupervisorConfigEndpointNetwork is the kind of socket on which an endpoint of the Supervisor listens.
+//
+// +kubebuilder:validation:Enum=tcp;unix;disabled
+type SupervisorConfigEndpointNetwork string
+
+const (
+	// EndpointNetworkTCP listens on a TCP address, e.g. ":8443".
+	EndpointNetworkTCP = SupervisorConfigEndpointNetwork("tcp")
+
+	// EndpointNetworkUnix listens on a Unix domain socket, e.g. "/pinniped_socket/socketfile.sock".
+	EndpointNetworkUnix = SupervisorConfigEndpointNetwork("unix")
+
+	// EndpointNetworkDisabled does not listen at all.
+	EndpointNetworkDisabled = SupervisorConfigEndpointNetwork("disabled")
+)
+
+// SupervisorConfigLogSpec configures the logs of the Supervisor.
+type SupervisorConfigLogSpec struct {
+	// Level is the verbosity of the logs. When not set, only warnings and errors are logged.
+	//
+	// +optional
+	Level SupervisorConfigLogLevel `json:"level,omitempty"`
+
+	// Components overrides Level for the logs of named loggers, e.g. {"upstream-oidc": "trace"}. A component
+	// applies to the logger with that name and to all loggers whose names start with it, and the longest matching
+	// component wins.
+	//
+	// +optional
+	Components map[string]SupervisorConfigLogLevel `json:"components,omitempty"`
+}
+
+// SupervisorConfigTLSSpec configures the TLS settings of the HTTPS endpoints of the Supervisor.
+type SupervisorConfigTLSSpec struct {
+	// MinVersion is the minimum TLS version. When not set, the default of the Supervisor is used.
+	//
+	// +kubebuilder:validation:Enum=TLS1.2;TLS1.3
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+
+	// CipherSuites are the names of the allowed TLS 1.2 cipher suites, e.g.
+	// TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256. TLS 1.3 cipher suites are not configurable. When empty, the
+	// defaults of the Supervisor are used.
+	//
+	// +listType=set
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+
+	// CurvePreferences are the names of the elliptic curves used in ECDHE handshakes in preference order, i.e.
+	// X25519, CurveP256, CurveP384 or CurveP521. When empty, the defaults of the Supervisor are used.
+	//
+	// +listType=atomic
+	// +optional
+	CurvePreferences []string `json:"curvePreferences,omitempty"`
+}
+
+// SupervisorConfigEndpoint configures the socket on which an endpoint of the Supervisor listens.
+type SupervisorConfigEndpoint struct {
+	// Network is the kind of socket, i.e. tcp, unix or disabled.
+	Network SupervisorConfigEndpointNetwork `json:"network"`
+
+	// Address is the address of the socket, e.g. ":8443" for tcp or "/pinniped_socket/socketfile.sock" for unix.
+	// It must not be set when Network is disabled.
+	//
+	// +optional
+	Address string `json:"address,omitempty"`
+}
+
+// SupervisorConfigEndpoints configures the sockets on which the Supervisor serves its FederationDomains.
+type SupervisorConfigEndpoints struct {
+	// HTTPS configures the endpoint which serves HTTPS requests. When not set, the endpoint of the static
+	// configuration of the Supervisor is used.
+	//
+	// +optional
+	HTTPS *SupervisorConfigEndpoint `json:"https,omitempty"`
+
+	// HTTP configures the endpoint which serves plain HTTP requests. Like in the static configuration of the
+	// Supervisor, it may only listen on a Unix domain socket or on a loopback TCP address. When not set, the
+	// endpoint of the static configuration of the Supervisor is used.
+	//
+	// +optional
+	HTTP *SupervisorConfigEndpoint `json:"http,omitempty"`
+}
+
+// SupervisorConfigSpec configures the settings of the Supervisor which can be changed while it is running. Each
+// setting which is not set keeps the value from the static configuration of the Supervisor.
+type SupervisorConfigSpec struct {
+	// Log configures the logs of the Supervisor.
+	//
+	// +optional
+	Log *SupervisorConfigLogSpec `json:"log,omitempty"`
+
+	// TLS configures the TLS settings of the HTTPS endpoints of the Supervisor.
+	//
+	// +optional
+	TLS *SupervisorConfigTLSSpec `json:"tls,omitempty"`
+
+	// Endpoints configures the sockets on which the Supervisor serves its FederationDomains. Changing them makes
+	// the Supervisor start listening on the new sockets and stop listening on the old ones.
+	//
+	// +optional
+	Endpoints *SupervisorConfigEndpoints `json:"endpoints,omitempty"`
+}
+
+// SupervisorConfigStatus describes whether the Supervisor applied its SupervisorConfig.
+type SupervisorConfigStatus struct {
+	// Conditions represent the observations of a SupervisorConfig's current state, i.e. whether its settings are
+	// valid and were applied.
+	//
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// SupervisorConfig configures the settings of the Supervisor which can be changed while it is running, e.g. its
+// log levels, TLS settings and endpoints. The Supervisor only watches the SupervisorConfig which is named by its
+// static configuration.
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:categories=pinniped,scope=Cluster
+// +kubebuilder:printcolumn:name="Log Level",type=string,JSONPath=`.spec.log.level`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type == "Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:subresource:status
+type SupervisorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec of the SupervisorConfig.
+	Spec SupervisorConfigSpec `json:"spec"`
+
+	// Status of the SupervisorConfig.
+	Status SupervisorConfigStatus `json:"status,omitempty"`
+}
+
+// List of SupervisorConfig objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SupervisorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []SupervisorConfig `json:"items"`
+}
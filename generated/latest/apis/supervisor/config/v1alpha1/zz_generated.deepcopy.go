@@ -9,26 +9,10 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Condition) DeepCopyInto(out *Condition) {
-	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
-func (in *Condition) DeepCopy() *Condition {
-	if in == nil {
-		return nil
-	}
-	out := new(Condition)
-	in.DeepCopyInto(out)
-	return out
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FederationDomain) DeepCopyInto(out *FederationDomain) {
 	*out = *in
@@ -57,6 +41,23 @@ func (in *FederationDomain) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationDomainIssuerMigration) DeepCopyInto(out *FederationDomainIssuerMigration) {
+	*out = *in
+	in.AcceptUntil.DeepCopyInto(&out.AcceptUntil)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationDomainIssuerMigration.
+func (in *FederationDomainIssuerMigration) DeepCopy() *FederationDomainIssuerMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationDomainIssuerMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FederationDomainList) DeepCopyInto(out *FederationDomainList) {
 	*out = *in
@@ -90,6 +91,65 @@ func (in *FederationDomainList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationDomainLoginPolicy) DeepCopyInto(out *FederationDomainLoginPolicy) {
+	*out = *in
+	if in.AllowedEmailDomains != nil {
+		in, out := &in.AllowedEmailDomains, &out.AllowedEmailDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedGroups != nil {
+		in, out := &in.AllowedGroups, &out.AllowedGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedSourceCIDRs != nil {
+		in, out := &in.AllowedSourceCIDRs, &out.AllowedSourceCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeWindows != nil {
+		in, out := &in.TimeWindows, &out.TimeWindows
+		*out = make([]FederationDomainLoginTimeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationDomainLoginPolicy.
+func (in *FederationDomainLoginPolicy) DeepCopy() *FederationDomainLoginPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationDomainLoginPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationDomainLoginTimeWindow) DeepCopyInto(out *FederationDomainLoginTimeWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]FederationDomainLoginWeekday, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationDomainLoginTimeWindow.
+func (in *FederationDomainLoginTimeWindow) DeepCopy() *FederationDomainLoginTimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationDomainLoginTimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FederationDomainSecrets) DeepCopyInto(out *FederationDomainSecrets) {
 	*out = *in
@@ -113,11 +173,41 @@ func (in *FederationDomainSecrets) DeepCopy() *FederationDomainSecrets {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FederationDomainSpec) DeepCopyInto(out *FederationDomainSpec) {
 	*out = *in
+	if in.AliasHosts != nil {
+		in, out := &in.AliasHosts, &out.AliasHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IssuerMigration != nil {
+		in, out := &in.IssuerMigration, &out.IssuerMigration
+		*out = new(FederationDomainIssuerMigration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedCORSOrigins != nil {
+		in, out := &in.AllowedCORSOrigins, &out.AllowedCORSOrigins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(FederationDomainTLSSpec)
 		**out = **in
 	}
+	if in.WebAuthn != nil {
+		in, out := &in.WebAuthn, &out.WebAuthn
+		*out = new(FederationDomainWebAuthnSpec)
+		**out = **in
+	}
+	if in.TOTP != nil {
+		in, out := &in.TOTP, &out.TOTP
+		*out = new(FederationDomainTOTPSpec)
+		**out = **in
+	}
+	if in.LoginPolicy != nil {
+		in, out := &in.LoginPolicy, &out.LoginPolicy
+		*out = new(FederationDomainLoginPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -139,6 +229,13 @@ func (in *FederationDomainStatus) DeepCopyInto(out *FederationDomainStatus) {
 		*out = (*in).DeepCopy()
 	}
 	out.Secrets = in.Secrets
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -168,6 +265,38 @@ func (in *FederationDomainTLSSpec) DeepCopy() *FederationDomainTLSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationDomainTOTPSpec) DeepCopyInto(out *FederationDomainTOTPSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationDomainTOTPSpec.
+func (in *FederationDomainTOTPSpec) DeepCopy() *FederationDomainTOTPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationDomainTOTPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationDomainWebAuthnSpec) DeepCopyInto(out *FederationDomainWebAuthnSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationDomainWebAuthnSpec.
+func (in *FederationDomainWebAuthnSpec) DeepCopy() *FederationDomainWebAuthnSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationDomainWebAuthnSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OIDCClient) DeepCopyInto(out *OIDCClient) {
 	*out = *in
@@ -196,6 +325,53 @@ func (in *OIDCClient) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCClientCredentials) DeepCopyInto(out *OIDCClientCredentials) {
+	*out = *in
+	if in.AllowedAudiences != nil {
+		in, out := &in.AllowedAudiences, &out.AllowedAudiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedScopes != nil {
+		in, out := &in.AllowedScopes, &out.AllowedScopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCClientCredentials.
+func (in *OIDCClientCredentials) DeepCopy() *OIDCClientCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCClientCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCClientGroupsClaim) DeepCopyInto(out *OIDCClientGroupsClaim) {
+	*out = *in
+	if in.AllowedPatterns != nil {
+		in, out := &in.AllowedPatterns, &out.AllowedPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCClientGroupsClaim.
+func (in *OIDCClientGroupsClaim) DeepCopy() *OIDCClientGroupsClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCClientGroupsClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OIDCClientList) DeepCopyInto(out *OIDCClientList) {
 	*out = *in
@@ -229,6 +405,27 @@ func (in *OIDCClientList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCClientSecretPolicy) DeepCopyInto(out *OIDCClientSecretPolicy) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCClientSecretPolicy.
+func (in *OIDCClientSecretPolicy) DeepCopy() *OIDCClientSecretPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCClientSecretPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OIDCClientSpec) DeepCopyInto(out *OIDCClientSpec) {
 	*out = *in
@@ -247,6 +444,31 @@ func (in *OIDCClientSpec) DeepCopyInto(out *OIDCClientSpec) {
 		*out = make([]Scope, len(*in))
 		copy(*out, *in)
 	}
+	if in.RequiredGroups != nil {
+		in, out := &in.RequiredGroups, &out.RequiredGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GroupsClaim != nil {
+		in, out := &in.GroupsClaim, &out.GroupsClaim
+		*out = new(OIDCClientGroupsClaim)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCredentials != nil {
+		in, out := &in.ClientCredentials, &out.ClientCredentials
+		*out = new(OIDCClientCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientSecretPolicy != nil {
+		in, out := &in.ClientSecretPolicy, &out.ClientSecretPolicy
+		*out = new(OIDCClientSecretPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TokenProfile != nil {
+		in, out := &in.TokenProfile, &out.TokenProfile
+		*out = new(OIDCClientTokenProfile)
+		**out = **in
+	}
 	return
 }
 
@@ -265,7 +487,7 @@ func (in *OIDCClientStatus) DeepCopyInto(out *OIDCClientStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -282,3 +504,225 @@ func (in *OIDCClientStatus) DeepCopy() *OIDCClientStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCClientTokenProfile) DeepCopyInto(out *OIDCClientTokenProfile) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCClientTokenProfile.
+func (in *OIDCClientTokenProfile) DeepCopy() *OIDCClientTokenProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCClientTokenProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorConfig) DeepCopyInto(out *SupervisorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorConfig.
+func (in *SupervisorConfig) DeepCopy() *SupervisorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SupervisorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorConfigEndpoint) DeepCopyInto(out *SupervisorConfigEndpoint) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorConfigEndpoint.
+func (in *SupervisorConfigEndpoint) DeepCopy() *SupervisorConfigEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorConfigEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorConfigEndpoints) DeepCopyInto(out *SupervisorConfigEndpoints) {
+	*out = *in
+	if in.HTTPS != nil {
+		in, out := &in.HTTPS, &out.HTTPS
+		*out = new(SupervisorConfigEndpoint)
+		**out = **in
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(SupervisorConfigEndpoint)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorConfigEndpoints.
+func (in *SupervisorConfigEndpoints) DeepCopy() *SupervisorConfigEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorConfigEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorConfigList) DeepCopyInto(out *SupervisorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SupervisorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorConfigList.
+func (in *SupervisorConfigList) DeepCopy() *SupervisorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SupervisorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorConfigLogSpec) DeepCopyInto(out *SupervisorConfigLogSpec) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[string]SupervisorConfigLogLevel, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorConfigLogSpec.
+func (in *SupervisorConfigLogSpec) DeepCopy() *SupervisorConfigLogSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorConfigLogSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorConfigSpec) DeepCopyInto(out *SupervisorConfigSpec) {
+	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = new(SupervisorConfigLogSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(SupervisorConfigTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(SupervisorConfigEndpoints)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorConfigSpec.
+func (in *SupervisorConfigSpec) DeepCopy() *SupervisorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorConfigStatus) DeepCopyInto(out *SupervisorConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorConfigStatus.
+func (in *SupervisorConfigStatus) DeepCopy() *SupervisorConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorConfigTLSSpec) DeepCopyInto(out *SupervisorConfigTLSSpec) {
+	*out = *in
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CurvePreferences != nil {
+		in, out := &in.CurvePreferences, &out.CurvePreferences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorConfigTLSSpec.
+func (in *SupervisorConfigTLSSpec) DeepCopy() *SupervisorConfigTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorConfigTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+// GroupTransformationType enumerates the kinds of rules which can transform the group names of users.
+//
+// +kubebuilder:validation:Enum=StripDN;Replace;Drop;Expression
+type GroupTransformationType string
+
+const (
+	// GroupTransformationTypeStripDN replaces each group name which is a distinguished name by the value of its
+	// first relative distinguished name, e.g. "cn=admins,ou=groups,dc=example,dc=com" becomes "admins".
+	GroupTransformationTypeStripDN = GroupTransformationType("StripDN")
+
+	// GroupTransformationTypeReplace replaces each group name which matches the pattern by the replacement.
+	GroupTransformationTypeReplace = GroupTransformationType("Replace")
+
+	// GroupTransformationTypeDrop removes each group name which matches the pattern.
+	GroupTransformationTypeDrop = GroupTransformationType("Drop")
+
+	// GroupTransformationTypeExpression replaces each group name by the result of the CEL expression.
+	GroupTransformationTypeExpression = GroupTransformationType("Expression")
+)
+
+// GroupTransformation is a rule which transforms the names of the groups of users which were found by the group
+// search of an identity provider.
+type GroupTransformation struct {
+	// Type is the kind of this rule. Allowed values are "StripDN", "Replace", "Drop" and "Expression".
+	//
+	// "StripDN" replaces each group name which is a distinguished name by the value of its first relative
+	// distinguished name, e.g. "cn=admins,ou=groups,dc=example,dc=com" becomes "admins". Group names which are not
+	// distinguished names are not changed. "Replace" replaces each group name which matches Pattern by Replacement.
+	// "Drop" removes each group name which matches Pattern. "Expression" replaces each group name by the result of
+	// the CEL expression in Expression.
+	Type GroupTransformationType `json:"type"`
+
+	// Pattern is a regular expression in the RE2 syntax which is used by Go, e.g. "k8s-(.*)". The pattern must match
+	// the whole group name. Required when Type is "Replace" or "Drop".
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+
+	// Replacement is the new group name of the group names which match Pattern. It may refer to the submatches of
+	// Pattern as "$1" or "${name}", e.g. "$1". When the replacement results in an empty group name, the group is
+	// removed. Only used when Type is "Replace".
+	// +optional
+	Replacement string `json:"replacement,omitempty"`
+
+	// Expression is an expression in the Common Expression Language (CEL), see https://github.com/google/cel-spec.
+	// The group name is available to the expression as the string variable "group", and the expression must result
+	// in a string, e.g. 'group.startsWith("k8s-") ? group.substring(4) : group'. The result becomes the new group
+	// name, and an empty result removes the group. Required when Type is "Expression".
+	// +optional
+	Expression string `json:"expression,omitempty"`
+}
@@ -0,0 +1,32 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WebhookAuthenticatorStatusApplyConfiguration represents an declarative configuration of the WebhookAuthenticatorStatus type for use
+// with apply.
+type WebhookAuthenticatorStatusApplyConfiguration struct {
+	Conditions []v1.Condition `json:"conditions,omitempty"`
+}
+
+// WebhookAuthenticatorStatusApplyConfiguration constructs an declarative configuration of the WebhookAuthenticatorStatus type for use with
+// apply.
+func WebhookAuthenticatorStatus() *WebhookAuthenticatorStatusApplyConfiguration {
+	return &WebhookAuthenticatorStatusApplyConfiguration{}
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *WebhookAuthenticatorStatusApplyConfiguration) WithConditions(values ...v1.Condition) *WebhookAuthenticatorStatusApplyConfiguration {
+	for i := range values {
+		b.Conditions = append(b.Conditions, values[i])
+	}
+	return b
+}
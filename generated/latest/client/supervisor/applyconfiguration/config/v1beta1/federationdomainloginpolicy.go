@@ -0,0 +1,64 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// FederationDomainLoginPolicyApplyConfiguration represents an declarative configuration of the FederationDomainLoginPolicy type for use
+// with apply.
+type FederationDomainLoginPolicyApplyConfiguration struct {
+	AllowedEmailDomains []string                                            `json:"allowedEmailDomains,omitempty"`
+	AllowedGroups       []string                                            `json:"allowedGroups,omitempty"`
+	AllowedSourceCIDRs  []string                                            `json:"allowedSourceCIDRs,omitempty"`
+	TimeWindows         []FederationDomainLoginTimeWindowApplyConfiguration `json:"timeWindows,omitempty"`
+}
+
+// FederationDomainLoginPolicyApplyConfiguration constructs an declarative configuration of the FederationDomainLoginPolicy type for use with
+// apply.
+func FederationDomainLoginPolicy() *FederationDomainLoginPolicyApplyConfiguration {
+	return &FederationDomainLoginPolicyApplyConfiguration{}
+}
+
+// WithAllowedEmailDomains adds the given value to the AllowedEmailDomains field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedEmailDomains field.
+func (b *FederationDomainLoginPolicyApplyConfiguration) WithAllowedEmailDomains(values ...string) *FederationDomainLoginPolicyApplyConfiguration {
+	for i := range values {
+		b.AllowedEmailDomains = append(b.AllowedEmailDomains, values[i])
+	}
+	return b
+}
+
+// WithAllowedGroups adds the given value to the AllowedGroups field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedGroups field.
+func (b *FederationDomainLoginPolicyApplyConfiguration) WithAllowedGroups(values ...string) *FederationDomainLoginPolicyApplyConfiguration {
+	for i := range values {
+		b.AllowedGroups = append(b.AllowedGroups, values[i])
+	}
+	return b
+}
+
+// WithAllowedSourceCIDRs adds the given value to the AllowedSourceCIDRs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedSourceCIDRs field.
+func (b *FederationDomainLoginPolicyApplyConfiguration) WithAllowedSourceCIDRs(values ...string) *FederationDomainLoginPolicyApplyConfiguration {
+	for i := range values {
+		b.AllowedSourceCIDRs = append(b.AllowedSourceCIDRs, values[i])
+	}
+	return b
+}
+
+// WithTimeWindows adds the given value to the TimeWindows field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the TimeWindows field.
+func (b *FederationDomainLoginPolicyApplyConfiguration) WithTimeWindows(values ...*FederationDomainLoginTimeWindowApplyConfiguration) *FederationDomainLoginPolicyApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithTimeWindows")
+		}
+		b.TimeWindows = append(b.TimeWindows, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ActiveDirectoryIdentityProviderSpecApplyConfiguration represents an declarative configuration of the ActiveDirectoryIdentityProviderSpec type for use
+// with apply.
+type ActiveDirectoryIdentityProviderSpecApplyConfiguration struct {
+	Host        *string                                                       `json:"host,omitempty"`
+	TLS         *TLSSpecApplyConfiguration                                    `json:"tls,omitempty"`
+	Bind        *ActiveDirectoryIdentityProviderBindApplyConfiguration        `json:"bind,omitempty"`
+	UserSearch  *ActiveDirectoryIdentityProviderUserSearchApplyConfiguration  `json:"userSearch,omitempty"`
+	GroupSearch *ActiveDirectoryIdentityProviderGroupSearchApplyConfiguration `json:"groupSearch,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderSpecApplyConfiguration constructs an declarative configuration of the ActiveDirectoryIdentityProviderSpec type for use with
+// apply.
+func ActiveDirectoryIdentityProviderSpec() *ActiveDirectoryIdentityProviderSpecApplyConfiguration {
+	return &ActiveDirectoryIdentityProviderSpecApplyConfiguration{}
+}
+
+// WithHost sets the Host field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Host field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderSpecApplyConfiguration) WithHost(value string) *ActiveDirectoryIdentityProviderSpecApplyConfiguration {
+	b.Host = &value
+	return b
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderSpecApplyConfiguration) WithTLS(value *TLSSpecApplyConfiguration) *ActiveDirectoryIdentityProviderSpecApplyConfiguration {
+	b.TLS = value
+	return b
+}
+
+// WithBind sets the Bind field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Bind field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderSpecApplyConfiguration) WithBind(value *ActiveDirectoryIdentityProviderBindApplyConfiguration) *ActiveDirectoryIdentityProviderSpecApplyConfiguration {
+	b.Bind = value
+	return b
+}
+
+// WithUserSearch sets the UserSearch field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UserSearch field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderSpecApplyConfiguration) WithUserSearch(value *ActiveDirectoryIdentityProviderUserSearchApplyConfiguration) *ActiveDirectoryIdentityProviderSpecApplyConfiguration {
+	b.UserSearch = value
+	return b
+}
+
+// WithGroupSearch sets the GroupSearch field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GroupSearch field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderSpecApplyConfiguration) WithGroupSearch(value *ActiveDirectoryIdentityProviderGroupSearchApplyConfiguration) *ActiveDirectoryIdentityProviderSpecApplyConfiguration {
+	b.GroupSearch = value
+	return b
+}
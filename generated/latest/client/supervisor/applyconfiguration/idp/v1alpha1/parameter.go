@@ -0,0 +1,35 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ParameterApplyConfiguration represents an declarative configuration of the Parameter type for use
+// with apply.
+type ParameterApplyConfiguration struct {
+	Name  *string `json:"name,omitempty"`
+	Value *string `json:"value,omitempty"`
+}
+
+// ParameterApplyConfiguration constructs an declarative configuration of the Parameter type for use with
+// apply.
+func Parameter() *ParameterApplyConfiguration {
+	return &ParameterApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *ParameterApplyConfiguration) WithName(value string) *ParameterApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithValue sets the Value field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Value field is set to the value of the last call.
+func (b *ParameterApplyConfiguration) WithValue(value string) *ParameterApplyConfiguration {
+	b.Value = &value
+	return b
+}
@@ -0,0 +1,26 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// ActiveDirectoryIdentityProviderBindApplyConfiguration represents an declarative configuration of the ActiveDirectoryIdentityProviderBind type for use
+// with apply.
+type ActiveDirectoryIdentityProviderBindApplyConfiguration struct {
+	SecretName *string `json:"secretName,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderBindApplyConfiguration constructs an declarative configuration of the ActiveDirectoryIdentityProviderBind type for use with
+// apply.
+func ActiveDirectoryIdentityProviderBind() *ActiveDirectoryIdentityProviderBindApplyConfiguration {
+	return &ActiveDirectoryIdentityProviderBindApplyConfiguration{}
+}
+
+// WithSecretName sets the SecretName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SecretName field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderBindApplyConfiguration) WithSecretName(value string) *ActiveDirectoryIdentityProviderBindApplyConfiguration {
+	b.SecretName = &value
+	return b
+}
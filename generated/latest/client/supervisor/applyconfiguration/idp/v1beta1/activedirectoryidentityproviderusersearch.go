@@ -0,0 +1,44 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// ActiveDirectoryIdentityProviderUserSearchApplyConfiguration represents an declarative configuration of the ActiveDirectoryIdentityProviderUserSearch type for use
+// with apply.
+type ActiveDirectoryIdentityProviderUserSearchApplyConfiguration struct {
+	Base       *string                                                                `json:"base,omitempty"`
+	Filter     *string                                                                `json:"filter,omitempty"`
+	Attributes *ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration `json:"attributes,omitempty"`
+}
+
+// ActiveDirectoryIdentityProviderUserSearchApplyConfiguration constructs an declarative configuration of the ActiveDirectoryIdentityProviderUserSearch type for use with
+// apply.
+func ActiveDirectoryIdentityProviderUserSearch() *ActiveDirectoryIdentityProviderUserSearchApplyConfiguration {
+	return &ActiveDirectoryIdentityProviderUserSearchApplyConfiguration{}
+}
+
+// WithBase sets the Base field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Base field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderUserSearchApplyConfiguration) WithBase(value string) *ActiveDirectoryIdentityProviderUserSearchApplyConfiguration {
+	b.Base = &value
+	return b
+}
+
+// WithFilter sets the Filter field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Filter field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderUserSearchApplyConfiguration) WithFilter(value string) *ActiveDirectoryIdentityProviderUserSearchApplyConfiguration {
+	b.Filter = &value
+	return b
+}
+
+// WithAttributes sets the Attributes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Attributes field is set to the value of the last call.
+func (b *ActiveDirectoryIdentityProviderUserSearchApplyConfiguration) WithAttributes(value *ActiveDirectoryIdentityProviderUserSearchAttributesApplyConfiguration) *ActiveDirectoryIdentityProviderUserSearchApplyConfiguration {
+	b.Attributes = value
+	return b
+}
@@ -0,0 +1,67 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// LDAPIdentityProviderGroupSearchApplyConfiguration represents an declarative configuration of the LDAPIdentityProviderGroupSearch type for use
+// with apply.
+type LDAPIdentityProviderGroupSearchApplyConfiguration struct {
+	Base             *string                                                      `json:"base,omitempty"`
+	Filter           *string                                                      `json:"filter,omitempty"`
+	Attributes       *LDAPIdentityProviderGroupSearchAttributesApplyConfiguration `json:"attributes,omitempty"`
+	SkipGroupRefresh *bool                                                        `json:"skipGroupRefresh,omitempty"`
+	Transformations  []GroupTransformationApplyConfiguration                      `json:"transformations,omitempty"`
+}
+
+// LDAPIdentityProviderGroupSearchApplyConfiguration constructs an declarative configuration of the LDAPIdentityProviderGroupSearch type for use with
+// apply.
+func LDAPIdentityProviderGroupSearch() *LDAPIdentityProviderGroupSearchApplyConfiguration {
+	return &LDAPIdentityProviderGroupSearchApplyConfiguration{}
+}
+
+// WithBase sets the Base field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Base field is set to the value of the last call.
+func (b *LDAPIdentityProviderGroupSearchApplyConfiguration) WithBase(value string) *LDAPIdentityProviderGroupSearchApplyConfiguration {
+	b.Base = &value
+	return b
+}
+
+// WithFilter sets the Filter field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Filter field is set to the value of the last call.
+func (b *LDAPIdentityProviderGroupSearchApplyConfiguration) WithFilter(value string) *LDAPIdentityProviderGroupSearchApplyConfiguration {
+	b.Filter = &value
+	return b
+}
+
+// WithAttributes sets the Attributes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Attributes field is set to the value of the last call.
+func (b *LDAPIdentityProviderGroupSearchApplyConfiguration) WithAttributes(value *LDAPIdentityProviderGroupSearchAttributesApplyConfiguration) *LDAPIdentityProviderGroupSearchApplyConfiguration {
+	b.Attributes = value
+	return b
+}
+
+// WithSkipGroupRefresh sets the SkipGroupRefresh field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SkipGroupRefresh field is set to the value of the last call.
+func (b *LDAPIdentityProviderGroupSearchApplyConfiguration) WithSkipGroupRefresh(value bool) *LDAPIdentityProviderGroupSearchApplyConfiguration {
+	b.SkipGroupRefresh = &value
+	return b
+}
+
+// WithTransformations adds the given value to the Transformations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Transformations field.
+func (b *LDAPIdentityProviderGroupSearchApplyConfiguration) WithTransformations(values ...*GroupTransformationApplyConfiguration) *LDAPIdentityProviderGroupSearchApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithTransformations")
+		}
+		b.Transformations = append(b.Transformations, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,26 @@
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+// OIDCClientApplyConfiguration represents an declarative configuration of the OIDCClient type for use
+// with apply.
+type OIDCClientApplyConfiguration struct {
+	SecretName *string `json:"secretName,omitempty"`
+}
+
+// OIDCClientApplyConfiguration constructs an declarative configuration of the OIDCClient type for use with
+// apply.
+func OIDCClient() *OIDCClientApplyConfiguration {
+	return &OIDCClientApplyConfiguration{}
+}
+
+// WithSecretName sets the SecretName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SecretName field is set to the value of the last call.
+func (b *OIDCClientApplyConfiguration) WithSecretName(value string) *OIDCClientApplyConfiguration {
+	b.SecretName = &value
+	return b
+}
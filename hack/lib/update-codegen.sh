@@ -1,6 +1,6 @@
 #!/usr/bin/env bash
 
-# Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+# Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 # SPDX-License-Identifier: Apache-2.0
 set -euo pipefail
 ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")/../.." && pwd)"
@@ -92,6 +92,13 @@ EOF
 echo "running go mod download in ${OUTPUT_DIR}/apis/go.mod to generate a go.sum file..."
 (cd "${OUTPUT_DIR}/apis" && go mod download all 2>&1 | sed "s|^|go-mod-download > |")
 
+# The informer factories generated for Kubernetes 1.26 and newer redeclare InformerFor from an embedded
+# interface, and overlapping interface methods need at least Go 1.14.
+CLIENT_GO_DIRECTIVE="1.13"
+if [[ "$(echo "${KUBE_MINOR_VERSION}" | cut -d"." -f2)" -ge 26 ]]; then
+    CLIENT_GO_DIRECTIVE="1.14"
+fi
+
 # Make the generated client code its own Go module.
 echo "generating ${OUTPUT_DIR}/client/go.mod..."
 mkdir client
@@ -101,7 +108,7 @@ cat << EOF > "./client/go.mod"
 // This go.mod file is generated by ./hack/codegen.sh.
 module ${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/client
 
-go 1.13
+go ${CLIENT_GO_DIRECTIVE}
 
 require (
     k8s.io/api ${KUBE_MODULE_VERSION}
@@ -124,7 +131,7 @@ echo "generating API-related code for our public API groups..."
         "deepcopy" \
         "${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/apis" \
         "${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/apis" \
-        "supervisor/config:v1alpha1 supervisor/idp:v1alpha1 supervisor/clientsecret:v1alpha1 concierge/config:v1alpha1 concierge/authentication:v1alpha1 concierge/login:v1alpha1 concierge/identity:v1alpha1" \
+        "supervisor/config:v1alpha1,v1beta1 supervisor/idp:v1alpha1,v1beta1 supervisor/clientsecret:v1alpha1 supervisor/session:v1alpha1 concierge/config:v1alpha1,v1beta1 concierge/authentication:v1alpha1,v1beta1 concierge/login:v1alpha1 concierge/identity:v1alpha1" \
         --go-header-file "${ROOT}/hack/boilerplate.go.txt" -v "$debug_level" 2>&1 | sed "s|^|gen-api > |"
 )
 
@@ -150,7 +157,7 @@ echo "generating API-related code for our internal API groups..."
         "${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/client/supervisor" \
         "${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/apis" \
         "${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/apis" \
-        "supervisor/clientsecret:v1alpha1" \
+        "supervisor/clientsecret:v1alpha1 supervisor/session:v1alpha1" \
         --go-header-file "${ROOT}/hack/boilerplate.go.txt" -v "$debug_level" 2>&1 | sed "s|^|gen-supervisor-int-api > |"
 )
 
@@ -158,23 +165,78 @@ echo "generating API-related code for our internal API groups..."
 echo "tidying ${OUTPUT_DIR}/apis/go.mod..."
 (cd apis && go mod tidy 2>&1 | sed "s|^|go-mod-tidy > |")
 
-# Generate client code for our public API groups
+# Generate client code for our public API groups.
+# Starting with Kubernetes 1.21, also generate apply configurations and Apply() methods on the clientsets, so that
+# controllers and other clients can use server-side apply with our CRDs. The generate-groups.sh script does not
+# know about applyconfiguration-gen, so run it directly and pass its output package to client-gen only.
+KUBE_MINOR_VERSION_NUMBER="$(echo "${KUBE_MINOR_VERSION}" | cut -d"." -f2)"
+if [[ "${KUBE_MINOR_VERSION_NUMBER}" -ge 21 ]]; then
+    (cd "${GOPATH}/src/k8s.io/code-generator" && go install ./cmd/applyconfiguration-gen)
+fi
+
+function generate_clients() {
+    local app="$1"
+    local groups_with_versions="$2"
+    local output_pkg="${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/client/${app}"
+    local apis_pkg="${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/apis"
+
+    if [[ "${KUBE_MINOR_VERSION_NUMBER}" -lt 21 ]]; then
+        bash "${GOPATH}/src/k8s.io/code-generator/generate-groups.sh" \
+            "client,lister,informer" \
+            "${output_pkg}" \
+            "${apis_pkg}" \
+            "${groups_with_versions}" \
+            --go-header-file "${ROOT}/hack/boilerplate.go.txt" -v "$debug_level" 2>&1 | sed "s|^|gen-${app}-client > |"
+        return
+    fi
+
+    local input_dirs=()
+    for group in ${groups_with_versions}; do
+        local versions="${group##*:}"
+        for version in ${versions//,/ }; do
+            input_dirs+=("${apis_pkg}/${group%%:*}/${version}")
+        done
+    done
+    "$(go env GOPATH)/bin/applyconfiguration-gen" \
+        --input-dirs "$(IFS=,; echo "${input_dirs[*]}")" \
+        --output-package "${output_pkg}/applyconfiguration" \
+        --go-header-file "${ROOT}/hack/boilerplate.go.txt" -v "$debug_level" 2>&1 | sed "s|^|gen-${app}-applyconfiguration > |"
+    if [[ "${KUBE_MINOR_VERSION_NUMBER}" -lt 26 ]]; then
+        # Before Kubernetes 1.26, applyconfiguration-gen does not know that OwnerReference has an apply configuration in
+        # client-go, so it generates WithOwnerReferences() methods that do not compile against the embedded
+        # ObjectMetaApplyConfiguration. Its --external-applyconfigurations flag cannot parse package paths containing
+        # dots, so rewrite those methods to match what newer versions of applyconfiguration-gen generate.
+        # Also remove the WithSelfLink() and WithClusterName() methods generated before Kubernetes 1.24, because the
+        # ObjectMetaApplyConfiguration of newer versions of client-go does not have those fields, and the facade in
+        # ./generated/clients compiles the clients of every supported version against the same version of client-go.
+        find "./${app}/applyconfiguration" -name '*.go' -print0 | xargs -0 perl -0pi -e '
+            s/WithOwnerReferences\(values \.\.\.metav1\.OwnerReference\)/WithOwnerReferences(values ...*v1.OwnerReferenceApplyConfiguration)/g;
+            s/(\tfor i := range values \{\n)(\t\tb\.OwnerReferences = append\(b\.OwnerReferences, )values\[i\]\)/$1\t\tif values[i] == nil {\n\t\t\tpanic("nil value passed to WithOwnerReferences")\n\t\t}\n$2*values[i])/g;
+            s/\/\/ With(SelfLink|ClusterName) sets .*?\n}\n\n//gs'
+    fi
+    bash "${GOPATH}/src/k8s.io/code-generator/generate-groups.sh" \
+        "client" \
+        "${output_pkg}" \
+        "${apis_pkg}" \
+        "${groups_with_versions}" \
+        --apply-configuration-package "${output_pkg}/applyconfiguration" \
+        --go-header-file "${ROOT}/hack/boilerplate.go.txt" -v "$debug_level" 2>&1 | sed "s|^|gen-${app}-client > |"
+    bash "${GOPATH}/src/k8s.io/code-generator/generate-groups.sh" \
+        "lister,informer" \
+        "${output_pkg}" \
+        "${apis_pkg}" \
+        "${groups_with_versions}" \
+        --go-header-file "${ROOT}/hack/boilerplate.go.txt" -v "$debug_level" 2>&1 | sed "s|^|gen-${app}-client > |"
+}
+
 echo "generating client code for our public API groups..."
 (cd client &&
-    bash "${GOPATH}/src/k8s.io/code-generator/generate-groups.sh" \
-        "client,lister,informer" \
-        "${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/client/concierge" \
-        "${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/apis" \
-        "concierge/config:v1alpha1 concierge/authentication:v1alpha1 concierge/login:v1alpha1 concierge/identity:v1alpha1" \
-        --go-header-file "${ROOT}/hack/boilerplate.go.txt" -v "$debug_level" 2>&1 | sed "s|^|gen-concierge-client > |"
+    generate_clients concierge \
+        "concierge/config:v1alpha1,v1beta1 concierge/authentication:v1alpha1,v1beta1 concierge/login:v1alpha1 concierge/identity:v1alpha1"
 )
 (cd client &&
-    bash "${GOPATH}/src/k8s.io/code-generator/generate-groups.sh" \
-        "client,lister,informer" \
-        "${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/client/supervisor" \
-        "${BASE_PKG}/generated/${KUBE_MINOR_VERSION}/apis" \
-        "supervisor/config:v1alpha1 supervisor/idp:v1alpha1 supervisor/clientsecret:v1alpha1" \
-        --go-header-file "${ROOT}/hack/boilerplate.go.txt" -v "$debug_level" 2>&1 | sed "s|^|gen-supervisor-client > |"
+    generate_clients supervisor \
+        "supervisor/config:v1alpha1,v1beta1 supervisor/idp:v1alpha1,v1beta1 supervisor/clientsecret:v1alpha1 supervisor/session:v1alpha1"
 )
 
 # Tidy up the .../client module
@@ -190,10 +252,11 @@ crd-ref-docs \
     --templates-dir="${ROOT}/hack/lib/docs/templates" \
     --output-path="${ROOT}/generated/${KUBE_MINOR_VERSION}/README.adoc"
 
-# Generate CRD YAML
+# Generate CRD YAML. All versions of an API group must be passed to controller-gen together so that each CRD
+# gets all of its versions.
 (cd apis &&
-    controller-gen paths=./supervisor/config/v1alpha1 crd output:crd:artifacts:config=../crds &&
-    controller-gen paths=./supervisor/idp/v1alpha1 crd output:crd:artifacts:config=../crds &&
-    controller-gen paths=./concierge/config/v1alpha1 crd output:crd:artifacts:config=../crds &&
-    controller-gen paths=./concierge/authentication/v1alpha1 crd output:crd:artifacts:config=../crds
+    controller-gen paths=./supervisor/config/... crd output:crd:artifacts:config=../crds &&
+    controller-gen paths=./supervisor/idp/... crd output:crd:artifacts:config=../crds &&
+    controller-gen paths=./concierge/config/... crd output:crd:artifacts:config=../crds &&
+    controller-gen paths=./concierge/authentication/... crd output:crd:artifacts:config=../crds
 )
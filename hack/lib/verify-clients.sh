@@ -0,0 +1,22 @@
+#!/usr/bin/env bash
+
+# Copyright 2023 the Pinniped contributors. All Rights Reserved.
+# SPDX-License-Identifier: Apache-2.0
+set -euo pipefail
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")/../.." && pwd)"
+KUBE_VERSIONS=("$@")
+
+# Build and test the facade over the generated clients once with its default build tags and once with the
+# build tag of each supported Kubernetes version, so that a facade which does not compile cannot be merged.
+cd "${ROOT}/generated/clients"
+
+echo "verifying the facade over the generated clients with the default build tags..."
+go vet ./...
+go test -count 1 ./...
+
+for kubeVersion in "${KUBE_VERSIONS[@]}"; do
+    tag="pinniped_kube_$(echo "${kubeVersion}" | cut -d"." -f1-2 | tr '.' '_')"
+    echo "verifying the facade over the generated clients with -tags ${tag}..."
+    go vet -tags "${tag}" ./...
+    go test -count 1 -tags "${tag}" ./...
+done
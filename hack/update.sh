@@ -1,6 +1,6 @@
 #!/usr/bin/env bash
 
-# Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+# Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 # SPDX-License-Identifier: Apache-2.0
 
 set -euo pipefail
@@ -35,3 +35,7 @@ cp "$ROOT"/generated/"$LATEST_MINOR_VERSION"/crds/*.concierge.*.yaml "$ROOT/depl
 
 # Tidy.
 "$ROOT/hack/module.sh" tidy
+
+# Tidy the facade over the generated clients, which requires the generated clients of each supported version.
+# When the supported versions change, also change the clients_kube_*.go files of the facade.
+(cd "$ROOT/generated/clients" && go mod tidy)
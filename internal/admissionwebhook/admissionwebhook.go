@@ -0,0 +1,128 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package admissionwebhook serves a validating admission webhook, which the Kubernetes API server calls to validate
+// Pinniped resources when they are created or updated. This rejects invalid resources at apply time, instead of
+// only reporting them later in their status conditions.
+package admissionwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// Path is the path at which the webhook is served by the aggregated API server. The ValidatingWebhookConfiguration
+// must use this path.
+const Path = "/validate-pinniped-resources"
+
+// maxRequestBodySize is the maximum size of an AdmissionReview, which is a bit more than twice the maximum size of an
+// object in etcd, because it may hold both the old and the new object.
+const maxRequestBodySize = 7 * 1024 * 1024
+
+// Validator validates the object of an admission request.
+type Validator interface {
+	// Validate returns an error which describes why the object of the request is invalid, or nil when it is valid.
+	Validate(ctx context.Context, request *admissionv1.AdmissionRequest) error
+}
+
+// ValidatorFunc is a func which implements Validator.
+type ValidatorFunc func(ctx context.Context, request *admissionv1.AdmissionRequest) error
+
+func (f ValidatorFunc) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) error {
+	return f(ctx, request)
+}
+
+type handler struct {
+	validators map[schema.GroupResource]Validator
+}
+
+// NewHandler returns a handler which serves the admission reviews of the Kubernetes API server. The created and
+// updated objects are validated by the validator of their resource. Objects of other resources, subresources such as
+// the status, and other operations such as deletes are always allowed.
+func NewHandler(validators map[schema.GroupResource]Validator) http.Handler {
+	return &handler{validators: validators}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || contentType != "application/json" {
+		http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize+1))
+	if err != nil {
+		http.Error(w, "could not read request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestBodySize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "request body must be an AdmissionReview with a request", http.StatusBadRequest)
+		return
+	}
+
+	response := &admissionv1.AdmissionResponse{UID: review.Request.UID, Allowed: true}
+	if err := h.validate(r.Context(), review.Request); err != nil {
+		plog.DebugErr("admission webhook rejected a request", err,
+			"resource", review.Request.Resource.String(),
+			"namespace", review.Request.Namespace,
+			"name", review.Request.Name,
+			"operation", review.Request.Operation,
+		)
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusUnprocessableEntity,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: err.Error(),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+		Response: response,
+	}); err != nil {
+		plog.Error("could not write admission review response", err)
+	}
+}
+
+func (h *handler) validate(ctx context.Context, request *admissionv1.AdmissionRequest) error {
+	if request.SubResource != "" ||
+		(request.Operation != admissionv1.Create && request.Operation != admissionv1.Update) {
+		return nil
+	}
+
+	validator, ok := h.validators[schema.GroupResource{Group: request.Resource.Group, Resource: request.Resource.Resource}]
+	if !ok {
+		return nil
+	}
+	return validator.Validate(ctx, request)
+}
+
+// DecodeObject decodes the object of the admission request into the given object.
+func DecodeObject(request *admissionv1.AdmissionRequest, into interface{}) error {
+	if err := json.Unmarshal(request.Object.Raw, into); err != nil {
+		return fmt.Errorf("could not decode %s: %w", request.Resource.Resource, err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package admissionwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestHandler(t *testing.T) {
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	handler := NewHandler(map[schema.GroupResource]Validator{
+		widgets.GroupResource(): ValidatorFunc(func(ctx context.Context, request *admissionv1.AdmissionRequest) error {
+			var widget struct {
+				Spec struct {
+					Size int `json:"size"`
+				} `json:"spec"`
+			}
+			if err := DecodeObject(request, &widget); err != nil {
+				return err
+			}
+			if widget.Spec.Size > 10 {
+				return errors.New("spec.size must not be greater than 10")
+			}
+			return nil
+		}),
+	})
+
+	newReview := func(resource schema.GroupVersionResource, subResource string, operation admissionv1.Operation, object string) string {
+		review, err := json.Marshal(&admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request: &admissionv1.AdmissionRequest{
+				UID:         "some-uid",
+				Resource:    metav1.GroupVersionResource(resource),
+				SubResource: subResource,
+				Operation:   operation,
+				Object:      runtime.RawExtension{Raw: []byte(object)},
+			},
+		})
+		require.NoError(t, err)
+		return string(review)
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		contentType  string
+		body         string
+		wantStatus   int
+		wantBody     string
+		wantResponse *admissionv1.AdmissionResponse
+	}{
+		{
+			name:       "valid object",
+			body:       newReview(widgets, "", admissionv1.Create, `{"spec":{"size":5}}`),
+			wantStatus: http.StatusOK,
+			wantResponse: &admissionv1.AdmissionResponse{
+				UID:     "some-uid",
+				Allowed: true,
+			},
+		},
+		{
+			name:       "invalid object",
+			body:       newReview(widgets, "", admissionv1.Update, `{"spec":{"size":11}}`),
+			wantStatus: http.StatusOK,
+			wantResponse: &admissionv1.AdmissionResponse{
+				UID:     "some-uid",
+				Allowed: false,
+				Result: &metav1.Status{
+					Status:  metav1.StatusFailure,
+					Code:    http.StatusUnprocessableEntity,
+					Reason:  metav1.StatusReasonInvalid,
+					Message: "spec.size must not be greater than 10",
+				},
+			},
+		},
+		{
+			name:       "object which cannot be decoded",
+			body:       newReview(widgets, "", admissionv1.Create, `{"spec":{"size":"big"}}`),
+			wantStatus: http.StatusOK,
+			wantResponse: &admissionv1.AdmissionResponse{
+				UID:     "some-uid",
+				Allowed: false,
+				Result: &metav1.Status{
+					Status:  metav1.StatusFailure,
+					Code:    http.StatusUnprocessableEntity,
+					Reason:  metav1.StatusReasonInvalid,
+					Message: "could not decode widgets: json: cannot unmarshal string into Go struct field .spec.size of type int",
+				},
+			},
+		},
+		{
+			name:       "status subresources are not validated",
+			body:       newReview(widgets, "status", admissionv1.Update, `{"spec":{"size":11}}`),
+			wantStatus: http.StatusOK,
+			wantResponse: &admissionv1.AdmissionResponse{
+				UID:     "some-uid",
+				Allowed: true,
+			},
+		},
+		{
+			name:       "deletes are not validated",
+			body:       newReview(widgets, "", admissionv1.Delete, `{"spec":{"size":11}}`),
+			wantStatus: http.StatusOK,
+			wantResponse: &admissionv1.AdmissionResponse{
+				UID:     "some-uid",
+				Allowed: true,
+			},
+		},
+		{
+			name:       "other resources are not validated",
+			body:       newReview(schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "gadgets"}, "", admissionv1.Create, `{"spec":{"size":11}}`),
+			wantStatus: http.StatusOK,
+			wantResponse: &admissionv1.AdmissionResponse{
+				UID:     "some-uid",
+				Allowed: true,
+			},
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed\n",
+		},
+		{
+			name:        "wrong content type",
+			contentType: "text/plain",
+			body:        newReview(widgets, "", admissionv1.Create, `{}`),
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantBody:    "content type must be application/json\n",
+		},
+		{
+			name:       "body which is not an admission review",
+			body:       `{"kind":"AdmissionReview"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "request body must be an AdmissionReview with a request\n",
+		},
+		{
+			name:       "body which is too large",
+			body:       strings.Repeat(" ", maxRequestBodySize+1),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   "request body too large\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+
+			req := httptest.NewRequest(method, Path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", contentType)
+			rsp := httptest.NewRecorder()
+			handler.ServeHTTP(rsp, req)
+
+			require.Equal(t, tt.wantStatus, rsp.Code)
+			if tt.wantResponse == nil {
+				require.Equal(t, tt.wantBody, rsp.Body.String())
+				return
+			}
+
+			require.Equal(t, "application/json", rsp.Header().Get("Content-Type"))
+			var review admissionv1.AdmissionReview
+			require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &review))
+			require.Equal(t, "admission.k8s.io/v1", review.APIVersion)
+			require.Equal(t, "AdmissionReview", review.Kind)
+			require.Equal(t, tt.wantResponse, review.Response)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package auditlog emits structured audit events about the lifecycle of the Supervisor's downstream sessions,
+// e.g. logins, token issuance, refreshes, and revocations, in a format which is suitable for ingestion by a SIEM.
+package auditlog
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// EventType describes what happened during an audit event.
+type EventType string
+
+const (
+	// EventAuthorizeStarted is emitted when a valid authorize request starts a login.
+	EventAuthorizeStarted EventType = "AuthorizeStarted"
+
+	// EventUpstreamAuthenticationSucceeded is emitted when the upstream identity provider has authenticated the
+	// user, e.g. at the callback endpoint, and a downstream session has been started.
+	EventUpstreamAuthenticationSucceeded EventType = "UpstreamAuthenticationSucceeded"
+
+	// EventUpstreamAuthenticationFailed is emitted when the upstream identity provider did not authenticate the
+	// user, or when the result of the upstream authentication could not be used to start a downstream session.
+	EventUpstreamAuthenticationFailed EventType = "UpstreamAuthenticationFailed"
+
+	// EventLoginPolicyDenied is emitted when the login policy of the FederationDomain denies the login of a user who
+	// was authenticated by the upstream identity provider.
+	EventLoginPolicyDenied EventType = "LoginPolicyDenied"
+
+	// EventClientAccessDenied is emitted when a user who was authenticated by the upstream identity provider is not a
+	// member of any of the groups which are required by the OIDCClient of the authorization request.
+	EventClientAccessDenied EventType = "ClientAccessDenied"
+
+	// EventUserLockedOut is emitted when a failed password login to an upstream LDAP or Active Directory identity
+	// provider causes the username to be temporarily locked out, in addition to the EventUpstreamAuthenticationFailed
+	// event of the failed login.
+	EventUserLockedOut EventType = "UserLockedOut"
+
+	// EventTokensIssued is emitted when the token endpoint issues tokens for any grant type other than refresh.
+	EventTokensIssued EventType = "TokensIssued"
+
+	// EventSessionRefreshed is emitted when the token endpoint issues tokens for a refresh grant.
+	EventSessionRefreshed EventType = "SessionRefreshed"
+
+	// EventTokenRequestFailed is emitted when the token endpoint rejects a request, including failed refreshes.
+	EventTokenRequestFailed EventType = "TokenRequestFailed"
+
+	// EventSessionRevoked is emitted when an admin revokes a session using the DownstreamSession API.
+	EventSessionRevoked EventType = "SessionRevoked"
+
+	// EventSecondFactorReset is emitted when an admin resets the second factors of a user using the SecondFactor API.
+	EventSecondFactorReset EventType = "SecondFactorReset"
+
+	// EventTokenRevoked is emitted when a client revokes one of its tokens at the revocation endpoint, e.g. when a
+	// user logs out using the CLI. RFC 7009 does not allow the endpoint to reveal whether the token was still valid,
+	// so the event is emitted even when there was nothing left to revoke.
+	EventTokenRevoked EventType = "TokenRevoked"
+
+	// EventTokenReplayDetected is emitted when the token endpoint receives an authorization code which was already
+	// redeemed or a refresh token which was already rotated. This can mean that the token was stolen, so all tokens of
+	// the session are revoked. Unlike other failed token requests, the session of the replayed token is known.
+	EventTokenReplayDetected EventType = "TokenReplayDetected"
+)
+
+// Kind is the value of the kind field of every audit event. It distinguishes audit events from the other lines
+// of the Supervisor's log when both are written to stdout.
+const Kind = "PinnipedSupervisorAuditEvent"
+
+// Event is a single audit event. Fields which are not known at the time of the event are omitted. The SourceIP is the
+// address of the client which made the request, as reported by a trusted proxy when there is one. The SessionID
+// is the same as the name of the session in the DownstreamSession API. It is not known during refreshes, so
+// refreshes should be correlated with their session using the client, identity provider, and username. The Actor is
+// the subject of the actor token of a token exchange in which a client acted on behalf of the user.
+type Event struct {
+	Kind             string    `json:"kind"`
+	Time             time.Time `json:"time"`
+	Type             EventType `json:"type"`
+	Issuer           string    `json:"issuer,omitempty"`
+	SourceIP         string    `json:"sourceIP,omitempty"`
+	SessionID        string    `json:"sessionID,omitempty"`
+	ClientID         string    `json:"clientID,omitempty"`
+	IdentityProvider string    `json:"identityProvider,omitempty"`
+	Username         string    `json:"username,omitempty"`
+	Actor            string    `json:"actor,omitempty"`
+	GrantType        string    `json:"grantType,omitempty"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// Logger emits audit events. Implementations must be safe for concurrent use and must not block the caller
+// for long, since events are emitted while handling login requests.
+type Logger interface {
+	Emit(event Event)
+}
+
+// Nop returns a Logger which discards all events. It is used when audit logging is not configured.
+func Nop() Logger {
+	return nopLogger{}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Emit(Event) {}
+
+// Tee returns a Logger which emits each event to all the given Loggers.
+func Tee(loggers ...Logger) Logger {
+	return teeLogger(loggers)
+}
+
+type teeLogger []Logger
+
+func (t teeLogger) Emit(event Event) {
+	for _, logger := range t {
+		logger.Emit(event)
+	}
+}
+
+// WithIssuer returns a Logger which sets the issuer of each event to the given FederationDomain issuer before
+// emitting it to logger. Events from requests to the alias hosts of a FederationDomain use its primary issuer.
+func WithIssuer(logger Logger, issuer string) Logger {
+	return &issuerLogger{logger: logger, issuer: issuer}
+}
+
+type issuerLogger struct {
+	logger Logger
+	issuer string
+}
+
+func (i *issuerLogger) Emit(event Event) {
+	event.Issuer = i.issuer
+	i.logger.Emit(event)
+}
+
+// WithSourceIP returns a Logger which sets the source IP of each event to the given address of the client which made
+// the current request before emitting it to logger. Nothing is set when sourceIP is empty.
+func WithSourceIP(logger Logger, sourceIP string) Logger {
+	if sourceIP == "" {
+		return logger
+	}
+	return &sourceIPLogger{logger: logger, sourceIP: sourceIP}
+}
+
+type sourceIPLogger struct {
+	logger   Logger
+	sourceIP string
+}
+
+func (s *sourceIPLogger) Emit(event Event) {
+	event.SourceIP = s.sourceIP
+	s.logger.Emit(event)
+}
+
+// NewJSONLogger returns a Logger which writes each event to w as a single line of JSON, e.g. to os.Stdout.
+func NewJSONLogger(w io.Writer) Logger {
+	return &jsonLogger{w: w}
+}
+
+type jsonLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (j *jsonLogger) Emit(event Event) {
+	data, err := marshal(event)
+	if err != nil {
+		plog.Error("failed to encode audit event", err, "type", event.Type)
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.w.Write(append(data, '\n')); err != nil {
+		plog.Error("failed to write audit event", err, "type", event.Type)
+	}
+}
+
+// marshal fills in the kind and, when it was not already set, the time of the event, and encodes it as JSON.
+func marshal(event Event) ([]byte, error) {
+	event.Kind = Kind
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	event.Time = event.Time.UTC()
+	return json.Marshal(event)
+}
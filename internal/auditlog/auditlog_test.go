@@ -0,0 +1,85 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package auditlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/here"
+)
+
+type recordingLogger struct {
+	events []Event
+}
+
+func (r *recordingLogger) Emit(event Event) {
+	r.events = append(r.events, event)
+}
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	subject := NewJSONLogger(&buf)
+
+	eventTime := time.Date(2023, 3, 4, 5, 6, 7, 0, time.FixedZone("some-zone", -2*60*60))
+	subject.Emit(Event{
+		Time:             eventTime,
+		Type:             EventTokensIssued,
+		Issuer:           "https://issuer.example.com",
+		SessionID:        "some-session-id",
+		ClientID:         "pinniped-cli",
+		IdentityProvider: "some-idp",
+		Username:         "some-username",
+		GrantType:        "authorization_code",
+	})
+	subject.Emit(Event{
+		Time:  eventTime,
+		Type:  EventTokenRequestFailed,
+		Error: "invalid_grant",
+	})
+
+	require.Equal(t, here.Doc(`
+		{"kind":"PinnipedSupervisorAuditEvent","time":"2023-03-04T07:06:07Z","type":"TokensIssued","issuer":"https://issuer.example.com","sessionID":"some-session-id","clientID":"pinniped-cli","identityProvider":"some-idp","username":"some-username","grantType":"authorization_code"}
+		{"kind":"PinnipedSupervisorAuditEvent","time":"2023-03-04T07:06:07Z","type":"TokenRequestFailed","error":"invalid_grant"}
+	`), buf.String())
+}
+
+func TestJSONLoggerSetsTime(t *testing.T) {
+	var buf bytes.Buffer
+	before := time.Now().UTC().Truncate(time.Second)
+
+	NewJSONLogger(&buf).Emit(Event{Type: EventAuthorizeStarted})
+
+	require.Regexp(t, `^{"kind":"PinnipedSupervisorAuditEvent","time":"[^"]+Z","type":"AuthorizeStarted"}\n$`, buf.String())
+	var actual Event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+	require.False(t, actual.Time.Before(before))
+}
+
+func TestTeeAndWithIssuer(t *testing.T) {
+	first, second := &recordingLogger{}, &recordingLogger{}
+
+	subject := WithIssuer(Tee(first, Nop(), second), "https://issuer.example.com")
+	subject.Emit(Event{Type: EventSessionRevoked, Issuer: "https://some-other-issuer.example.com", SessionID: "some-session-id"})
+
+	want := []Event{{Type: EventSessionRevoked, Issuer: "https://issuer.example.com", SessionID: "some-session-id"}}
+	require.Equal(t, want, first.events)
+	require.Equal(t, want, second.events)
+}
+
+func TestWithSourceIP(t *testing.T) {
+	recorder := &recordingLogger{}
+
+	WithSourceIP(recorder, "203.0.113.1").Emit(Event{Type: EventTokensIssued})
+	WithSourceIP(recorder, "").Emit(Event{Type: EventTokensIssued})
+
+	require.Equal(t, []Event{
+		{Type: EventTokensIssued, SourceIP: "203.0.113.1"},
+		{Type: EventTokensIssued},
+	}, recorder.events)
+}
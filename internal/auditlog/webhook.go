@@ -0,0 +1,96 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package auditlog
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	// webhookQueueSize is the number of events which may be waiting to be sent to the webhook. Any further
+	// events are dropped until the webhook catches up.
+	webhookQueueSize = 1000
+
+	// webhookTimeout limits how long the webhook may take to respond to each event.
+	webhookTimeout = 10 * time.Second
+)
+
+// NewWebhookLogger returns a Logger which POSTs each event as a JSON document to the given URL. Events are sent
+// one at a time by a background goroutine which runs until ctx is cancelled, so a slow webhook does not slow
+// down logins. Events are dropped, with a warning in the Supervisor's log, when too many events are waiting to
+// be sent or when the webhook does not respond with a 2xx status. rootCAs may be nil to use the host's root CAs.
+func NewWebhookLogger(ctx context.Context, url string, rootCAs *x509.CertPool) Logger {
+	client := phttp.Default(rootCAs)
+	client.Timeout = webhookTimeout
+
+	w := &webhookLogger{
+		url:    url,
+		client: client,
+		queue:  make(chan []byte, webhookQueueSize),
+	}
+	go w.run(ctx)
+	return w
+}
+
+type webhookLogger struct {
+	url    string
+	client *http.Client
+	queue  chan []byte
+}
+
+func (w *webhookLogger) Emit(event Event) {
+	data, err := marshal(event)
+	if err != nil {
+		plog.Error("failed to encode audit event", err, "type", event.Type)
+		return
+	}
+
+	select {
+	case w.queue <- data:
+	default:
+		plog.Warning("dropped audit event because too many events are waiting to be sent to the audit webhook", "type", event.Type)
+	}
+}
+
+func (w *webhookLogger) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data := <-w.queue:
+			if err := w.send(ctx, data); err != nil {
+				plog.WarningErr("failed to send audit event to the audit webhook", err)
+			}
+		}
+	}
+}
+
+func (w *webhookLogger) send(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rsp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, rsp.Body)
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %q", rsp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package auditlog
+
+import (
+	"context"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/testutil/tlsserver"
+)
+
+func TestWebhookLogger(t *testing.T) {
+	received := make(chan string, 10)
+	server := tlsserver.TLSTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received <- string(body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}), nil)
+
+	rootCAs := x509.NewCertPool()
+	require.True(t, rootCAs.AppendCertsFromPEM(tlsserver.TLSTestServerCA(server)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	eventTime := time.Date(2023, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	// A failure to send one event does not stop later events from being sent.
+	failing := NewWebhookLogger(ctx, server.URL+"/fail", rootCAs)
+	failing.Emit(Event{Time: eventTime, Type: EventAuthorizeStarted})
+	failing.Emit(Event{Time: eventTime, Type: EventSessionRevoked, SessionID: "some-session-id"})
+
+	require.Equal(t, `{"kind":"PinnipedSupervisorAuditEvent","time":"2023-03-04T05:06:07Z","type":"AuthorizeStarted"}`, receive(t, received))
+	require.Equal(t, `{"kind":"PinnipedSupervisorAuditEvent","time":"2023-03-04T05:06:07Z","type":"SessionRevoked","sessionID":"some-session-id"}`, receive(t, received))
+}
+
+func TestWebhookLoggerDropsEventsWhenQueueIsFull(t *testing.T) {
+	// Without a running sender, nothing is ever removed from the queue.
+	subject := &webhookLogger{queue: make(chan []byte, webhookQueueSize)}
+	for i := 0; i < webhookQueueSize+10; i++ {
+		subject.Emit(Event{Type: EventAuthorizeStarted})
+	}
+	require.Len(t, subject.queue, webhookQueueSize)
+}
+
+func receive(t *testing.T, received <-chan string) string {
+	t.Helper()
+
+	select {
+	case body := <-received:
+		return body
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for the webhook to receive an event")
+		return ""
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cachecrypter encrypts the contents of the CLI's cache files using a key which is kept in the OS keychain
+// (macOS Keychain, Windows Credential Manager, or a Secret Service provider such as GNOME Keyring on Linux), or using
+// a key which is derived from a passphrase on platforms which have no keychain.
+//
+// The cache files themselves stay on disk, so that they can keep using file locking and can grow beyond the size limits
+// of some keychains, but the tokens and credentials within them are no longer readable without access to the key.
+package cachecrypter
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// keychainService and keychainUser identify the cache encryption key in the OS keychain.
+	keychainService = "pinniped"
+	keychainUser    = "cache-encryption-key"
+
+	// keySize is the size of the AES-256 key used to encrypt the cache files.
+	keySize = 32
+
+	// saltSize is the size of the random salt used when deriving a key from a passphrase.
+	saltSize = 16
+
+	// scryptN, scryptR, and scryptP are the scrypt parameters used when deriving a key from a passphrase.
+	// See https://pkg.go.dev/golang.org/x/crypto/scrypt#Key.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+//nolint:gochecknoglobals
+var (
+	// encryptedPrefix marks the contents of a cache file which was encrypted using the key from the OS keychain, to
+	// distinguish them from the plaintext YAML which was written by older versions of the CLI or when no key was available.
+	encryptedPrefix = []byte("pinniped-encrypted-v1:")
+
+	// passphraseEncryptedPrefix marks the contents of a cache file which was encrypted using a key derived from a passphrase.
+	passphraseEncryptedPrefix = []byte("pinniped-passphrase-encrypted-v1:")
+)
+
+// Keyring stores secrets in an OS keychain.
+type Keyring interface {
+	Get(service, user string) (string, error)
+	Set(service, user, secret string) error
+}
+
+type osKeyring struct{}
+
+func (osKeyring) Get(service, user string) (string, error) { return keyring.Get(service, user) }
+func (osKeyring) Set(service, user, secret string) error   { return keyring.Set(service, user, secret) }
+
+// OSKeyring returns a Keyring which uses the keychain of the current operating system.
+func OSKeyring() Keyring {
+	return osKeyring{}
+}
+
+// Crypter encrypts and decrypts cache file contents.
+type Crypter struct {
+	aead cipher.AEAD
+
+	// passphrase is only set for a Crypter which was created by NewFromPassphrase. In that case, aead is derived from
+	// the passphrase and salt, and is recalculated whenever a file with a different salt is decrypted.
+	passphrase []byte
+	salt       []byte
+}
+
+// New returns a Crypter which uses the cache encryption key stored in the given Keyring, creating the key if it does
+// not exist yet. It returns an error when the keychain is not available, in which case the caller should fall back to
+// using plaintext cache files.
+func New(kr Keyring) (*Crypter, error) {
+	encodedKey, err := kr.Get(keychainService, keychainUser)
+	if errors.Is(err, keyring.ErrNotFound) {
+		encodedKey, err = createKey(kr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get cache encryption key from OS keychain: %w", err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("invalid cache encryption key in OS keychain")
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Crypter{aead: aead}, nil
+}
+
+// NewFromPassphrase returns a Crypter which uses a key derived from the given passphrase, for platforms which have no
+// OS keychain. The passphrase may be chosen by the user, or may be the contents of a secret which is protected by the
+// OS, such as a file which is only readable by the user.
+func NewFromPassphrase(passphrase []byte) (*Crypter, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("cache encryption passphrase must not be empty")
+	}
+	return &Crypter{passphrase: passphrase}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey sets the Crypter's salt and its key derived from that salt. This is relatively expensive, so it is only
+// done when the salt changes, and a Crypter keeps using the salt of the file which it last read when writing.
+func (c *Crypter) deriveKey(salt []byte) error {
+	if c.aead != nil && bytes.Equal(salt, c.salt) {
+		return nil
+	}
+	key, err := scrypt.Key(c.passphrase, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return fmt.Errorf("could not derive key from passphrase: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	c.salt, c.aead = append([]byte(nil), salt...), aead
+	return nil
+}
+
+// createKey generates a new random key and stores it in the keychain. It reads the key back afterwards, so that
+// concurrent CLI processes which race to create the key will agree on the winner.
+func createKey(kr Keyring) (string, error) {
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	if err := kr.Set(keychainService, keychainUser, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return "", err
+	}
+	return kr.Get(keychainService, keychainUser)
+}
+
+// Encrypt returns the encrypted form of plaintext, suitable for writing to a cache file.
+func (c *Crypter) Encrypt(plaintext []byte) ([]byte, error) {
+	prefix, header := encryptedPrefix, []byte(nil)
+	if c.passphrase != nil {
+		salt := c.salt
+		if salt == nil {
+			salt = make([]byte, saltSize)
+			if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.deriveKey(salt); err != nil {
+			return nil, err
+		}
+		prefix, header = passphraseEncryptedPrefix, salt
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := c.aead.Seal(append(append([]byte(nil), header...), nonce...), nonce, plaintext, nil)
+
+	encoded := make([]byte, len(prefix)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(encoded, prefix)
+	base64.StdEncoding.Encode(encoded[len(prefix):], sealed)
+	return append(encoded, '\n'), nil
+}
+
+// Decrypt returns the plaintext form of data which was read from a cache file. Data which was never encrypted is
+// returned as-is, so that existing plaintext cache files can still be read, and will be encrypted on their next write.
+func (c *Crypter) Decrypt(data []byte) ([]byte, error) {
+	prefix := encryptedPrefix
+	if c.passphrase != nil {
+		prefix = passphraseEncryptedPrefix
+	}
+	if !bytes.HasPrefix(data, prefix) {
+		if bytes.HasPrefix(data, encryptedPrefix) || bytes.HasPrefix(data, passphraseEncryptedPrefix) {
+			return nil, fmt.Errorf("data was encrypted using a different kind of key")
+		}
+		return data, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data[len(prefix):])))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode encrypted data: %w", err)
+	}
+	if c.passphrase != nil {
+		if len(sealed) < saltSize {
+			return nil, fmt.Errorf("encrypted data is too short")
+		}
+		if err := c.deriveKey(sealed[:saltSize]); err != nil {
+			return nil, err
+		}
+		sealed = sealed[saltSize:]
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("encrypted data is too short")
+	}
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt data: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cachecrypter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+type fakeKeyring struct {
+	getErr  error
+	setErr  error
+	secrets map[string]string
+}
+
+func (k *fakeKeyring) Get(service, user string) (string, error) {
+	if k.getErr != nil {
+		return "", k.getErr
+	}
+	secret, ok := k.secrets[service+"/"+user]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k *fakeKeyring) Set(service, user, secret string) error {
+	if k.setErr != nil {
+		return k.setErr
+	}
+	if k.secrets == nil {
+		k.secrets = map[string]string{}
+	}
+	k.secrets[service+"/"+user] = secret
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	validKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, keySize))
+
+	tests := []struct {
+		name        string
+		keyring     *fakeKeyring
+		wantErr     string
+		wantSecrets map[string]string
+	}{
+		{
+			name:    "keychain not available",
+			keyring: &fakeKeyring{getErr: fmt.Errorf("some keychain error")},
+			wantErr: "could not get cache encryption key from OS keychain: some keychain error",
+		},
+		{
+			name:    "key not found and cannot be created",
+			keyring: &fakeKeyring{setErr: fmt.Errorf("some keychain error")},
+			wantErr: "could not get cache encryption key from OS keychain: some keychain error",
+		},
+		{
+			name:    "invalid key",
+			keyring: &fakeKeyring{secrets: map[string]string{"pinniped/cache-encryption-key": "too-short"}},
+			wantErr: "invalid cache encryption key in OS keychain",
+		},
+		{
+			name:        "existing key",
+			keyring:     &fakeKeyring{secrets: map[string]string{"pinniped/cache-encryption-key": validKey}},
+			wantSecrets: map[string]string{"pinniped/cache-encryption-key": validKey},
+		},
+		{
+			name:    "key is created",
+			keyring: &fakeKeyring{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := New(tt.keyring)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, c)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			require.Len(t, tt.keyring.secrets, 1)
+			if tt.wantSecrets != nil {
+				require.Equal(t, tt.wantSecrets, tt.keyring.secrets)
+			}
+		})
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+	kr := &fakeKeyring{}
+	c, err := New(kr)
+	require.NoError(t, err)
+
+	plaintext := []byte("apiVersion: config.supervisor.pinniped.dev/v1alpha1\nkind: SessionCache\n")
+
+	encrypted, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(encrypted, []byte("pinniped-encrypted-v1:")))
+	require.NotContains(t, string(encrypted), "SessionCache")
+
+	// Encrypting the same data twice uses a different nonce each time.
+	encryptedAgain, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	require.NotEqual(t, encrypted, encryptedAgain)
+
+	decrypted, err := c.Decrypt(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// Another Crypter which uses the same keychain can decrypt the data.
+	c2, err := New(kr)
+	require.NoError(t, err)
+	decrypted, err = c2.Decrypt(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// Data which was never encrypted is returned as-is.
+	decrypted, err = c.Decrypt(plaintext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// A Crypter with a different key cannot decrypt the data.
+	other, err := New(&fakeKeyring{})
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+	require.EqualError(t, err, "could not decrypt data: cipher: message authentication failed")
+
+	// Data which is not valid base64 or is too short is rejected.
+	_, err = c.Decrypt([]byte("pinniped-encrypted-v1:%%%"))
+	require.EqualError(t, err, "could not decode encrypted data: illegal base64 data at input byte 0")
+	_, err = c.Decrypt([]byte("pinniped-encrypted-v1:AAAA"))
+	require.EqualError(t, err, "encrypted data is too short")
+}
+
+func TestNewFromPassphrase(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewFromPassphrase(nil)
+	require.EqualError(t, err, "cache encryption passphrase must not be empty")
+
+	c, err := NewFromPassphrase([]byte("some passphrase"))
+	require.NoError(t, err)
+
+	plaintext := []byte("apiVersion: config.supervisor.pinniped.dev/v1alpha1\nkind: SessionCache\n")
+
+	encrypted, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(encrypted, []byte("pinniped-passphrase-encrypted-v1:")))
+	require.NotContains(t, string(encrypted), "SessionCache")
+
+	// The same Crypter keeps using the same salt, so that it only needs to derive its key once.
+	salt := c.salt
+	encryptedAgain, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	require.NotEqual(t, encrypted, encryptedAgain)
+	require.Equal(t, salt, c.salt)
+
+	// Another Crypter with the same passphrase can decrypt the data, and adopts its salt.
+	c2, err := NewFromPassphrase([]byte("some passphrase"))
+	require.NoError(t, err)
+	decrypted, err := c2.Decrypt(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+	require.Equal(t, salt, c2.salt)
+
+	// Data which was never encrypted is returned as-is.
+	decrypted, err = c2.Decrypt(plaintext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// A Crypter with a different passphrase cannot decrypt the data.
+	other, err := NewFromPassphrase([]byte("some other passphrase"))
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+	require.EqualError(t, err, "could not decrypt data: cipher: message authentication failed")
+
+	// Data which was encrypted using the key from the OS keychain cannot be decrypted using a passphrase, and vice versa.
+	keychainCrypter, err := New(&fakeKeyring{})
+	require.NoError(t, err)
+	keychainEncrypted, err := keychainCrypter.Encrypt(plaintext)
+	require.NoError(t, err)
+	_, err = c.Decrypt(keychainEncrypted)
+	require.EqualError(t, err, "data was encrypted using a different kind of key")
+	_, err = keychainCrypter.Decrypt(encrypted)
+	require.EqualError(t, err, "data was encrypted using a different kind of key")
+
+	// Data which is too short to contain a salt is rejected.
+	_, err = c.Decrypt([]byte("pinniped-passphrase-encrypted-v1:AAAA"))
+	require.EqualError(t, err, "encrypted data is too short")
+}
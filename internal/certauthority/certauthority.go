@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package certauthority implements a simple x509 certificate authority suitable for use in an aggregated API service.
@@ -8,8 +8,8 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/ecdsa"
-	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
@@ -50,10 +50,14 @@ type CA struct {
 	// signer is the private key for the current CA.
 	signer crypto.Signer
 
-	// privateKey is the same private key represented by signer, but in a format which allows export.
-	// It is only set by New, not by Load, since Load can handle various types of PrivateKey but New
-	// only needs to create keys of type ecdsa.PrivateKey.
-	privateKey *ecdsa.PrivateKey
+	// privateKey is the same private key represented by signer, but only set by New, not by Load,
+	// since only the keys which were generated by New need to be exported. It is also not set when
+	// the CA signs with an external signer, see WithSigner.
+	privateKey crypto.Signer
+
+	// keyAlgorithm is the algorithm of the private keys of the issued certificates, and of the CA itself when it
+	// was created by New. The zero value means DefaultKeyAlgorithm.
+	keyAlgorithm KeyAlgorithm
 
 	// env is our reference to the outside world (clocks and random number generation).
 	env env
@@ -73,49 +77,108 @@ func secureEnv() env {
 // ErrInvalidCACertificate is returned when the contents of the loaded CA certificate do not meet our assumptions.
 const ErrInvalidCACertificate = constable.Error("invalid CA certificate")
 
+// Option configures a CA which is created by New or Load.
+type Option func(*CA)
+
+// WithKeyAlgorithm sets the algorithm of the private keys which are generated by the CA.
+func WithKeyAlgorithm(keyAlgorithm KeyAlgorithm) Option {
+	return func(c *CA) {
+		c.keyAlgorithm = keyAlgorithm
+	}
+}
+
+// WithSigner makes the CA sign with the given signer instead of a private key of its own, e.g. a key which is held
+// by an HSM or a cloud KMS and which can only be used via an external signer plugin. New self-signs the CA certificate
+// with the signer instead of generating a private key, and Load ignores its private key argument and only checks that
+// the certificate belongs to the signer. The private key of such a CA can never be exported by PrivateKeyToPEM.
+func WithSigner(signer crypto.Signer) Option {
+	return func(c *CA) {
+		c.signer = signer
+	}
+}
+
 // Load a certificate authority from an existing certificate and private key (in PEM format).
-func Load(certPEM string, keyPEM string) (*CA, error) {
-	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
-	if err != nil {
-		return nil, fmt.Errorf("could not load CA: %w", err)
+// The algorithm of the private key of the loaded CA does not need to match the key algorithm option.
+func Load(certPEM string, keyPEM string, opts ...Option) (*CA, error) {
+	ca := &CA{env: secureEnv()}
+	for _, opt := range opts {
+		opt(ca)
 	}
-	if certCount := len(cert.Certificate); certCount != 1 {
+
+	var certs [][]byte
+	if ca.signer != nil {
+		certs = certificatesFromPEM([]byte(certPEM))
+	} else {
+		cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("could not load CA: %w", err)
+		}
+		certs = cert.Certificate
+		ca.signer = cert.PrivateKey.(crypto.Signer)
+	}
+
+	if certCount := len(certs); certCount != 1 {
 		return nil, fmt.Errorf("%w: expected a single certificate, found %d certificates", ErrInvalidCACertificate, certCount)
 	}
-	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
+	x509Cert, err := x509.ParseCertificate(certs[0])
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse key pair as x509 cert: %w", err)
 	}
 	if !x509Cert.IsCA {
 		return nil, fmt.Errorf("%w: passed in key pair is not a CA", ErrInvalidCACertificate)
 	}
-	return &CA{
-		caCertBytes: cert.Certificate[0],
-		signer:      cert.PrivateKey.(crypto.Signer),
-		env:         secureEnv(),
-	}, nil
+	if !publicKeysEqual(x509Cert.PublicKey, ca.signer.Public()) {
+		return nil, fmt.Errorf("%w: certificate does not match the public key of the signer", ErrInvalidCACertificate)
+	}
+
+	ca.caCertBytes = certs[0]
+	return ca, nil
+}
+
+func certificatesFromPEM(certPEM []byte) [][]byte {
+	var certs [][]byte
+	for {
+		var block *pem.Block
+		block, certPEM = pem.Decode(certPEM)
+		if block == nil {
+			return certs
+		}
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, block.Bytes)
+		}
+	}
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
 }
 
 // New generates a fresh certificate authority with the given Common Name and TTL.
-func New(commonName string, ttl time.Duration) (*CA, error) {
-	return newInternal(commonName, ttl, secureEnv())
+func New(commonName string, ttl time.Duration, opts ...Option) (*CA, error) {
+	return newInternal(commonName, ttl, secureEnv(), opts...)
 }
 
 // newInternal is the internal guts of New, broken out for easier testing.
-func newInternal(commonName string, ttl time.Duration, env env) (*CA, error) {
+func newInternal(commonName string, ttl time.Duration, env env, opts ...Option) (*CA, error) {
 	ca := CA{env: env}
+	for _, opt := range opts {
+		opt(&ca)
+	}
 	// Generate a random serial for the CA
 	serialNumber, err := randomSerial(env.serialRNG)
 	if err != nil {
 		return nil, fmt.Errorf("could not generate CA serial: %w", err)
 	}
 
-	// Generate a new P256 keypair.
-	ca.privateKey, err = ecdsa.GenerateKey(elliptic.P256(), env.keygenRNG)
-	if err != nil {
-		return nil, fmt.Errorf("could not generate CA private key: %w", err)
+	// Generate a new keypair, unless the CA signs with an external signer.
+	if ca.signer == nil {
+		ca.privateKey, err = ca.keyAlgorithm.generateKey(env.keygenRNG)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate CA private key: %w", err)
+		}
+		ca.signer = ca.privateKey
 	}
-	ca.signer = ca.privateKey
 
 	// Make a CA certificate valid for some ttl and backdated by some amount.
 	now := env.clock()
@@ -135,7 +198,7 @@ func newInternal(commonName string, ttl time.Duration, env env) (*CA, error) {
 	}
 
 	// Self-sign the CA to get the DER certificate.
-	caCertBytes, err := x509.CreateCertificate(env.signingRNG, &caTemplate, &caTemplate, &ca.privateKey.PublicKey, ca.privateKey)
+	caCertBytes, err := x509.CreateCertificate(env.signingRNG, &caTemplate, &caTemplate, ca.signer.Public(), ca.signer)
 	if err != nil {
 		return nil, fmt.Errorf("could not issue CA certificate: %w", err)
 	}
@@ -153,11 +216,18 @@ func (c *CA) PrivateKeyToPEM() ([]byte, error) {
 	if c.privateKey == nil {
 		return nil, fmt.Errorf("no private key data (did you try to use this after Load?)")
 	}
-	derKey, err := x509.MarshalECPrivateKey(c.privateKey)
-	if err != nil {
-		return nil, err
+	switch privateKey := c.privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		derKey, err := x509.MarshalECPrivateKey(privateKey)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: derKey}), nil
+	case *rsa.PrivateKey:
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", c.privateKey)
 	}
-	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: derKey}), nil
 }
 
 // Pool returns the current CA signing bundle as a *x509.CertPool.
@@ -198,8 +268,8 @@ func (c *CA) issueCert(extKeyUsage x509.ExtKeyUsage, subject pkix.Name, dnsNames
 		return nil, fmt.Errorf("could not generate serial number for certificate: %w", err)
 	}
 
-	// Generate a new P256 keypair.
-	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), c.env.keygenRNG)
+	// Generate a new keypair.
+	privateKey, err := c.keyAlgorithm.generateKey(c.env.keygenRNG)
 	if err != nil {
 		return nil, fmt.Errorf("could not generate private key: %w", err)
 	}
@@ -227,7 +297,7 @@ func (c *CA) issueCert(extKeyUsage x509.ExtKeyUsage, subject pkix.Name, dnsNames
 		DNSNames:              dnsNames,
 		IPAddresses:           ips,
 	}
-	certBytes, err := x509.CreateCertificate(rand.Reader, &template, caCert, &privateKey.PublicKey, c.signer)
+	certBytes, err := x509.CreateCertificate(rand.Reader, &template, caCert, privateKey.Public(), c.signer)
 	if err != nil {
 		return nil, fmt.Errorf("could not sign certificate: %w", err)
 	}
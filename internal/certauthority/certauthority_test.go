@@ -5,8 +5,12 @@ package certauthority
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"net"
@@ -178,6 +182,48 @@ func TestNewInternal(t *testing.T) {
 	}
 }
 
+func TestWithSigner(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	ca, err := New("Test CA", time.Hour, WithSigner(signer))
+	require.NoError(t, err)
+	require.Nil(t, ca.privateKey)
+	_, err = ca.PrivateKeyToPEM()
+	require.EqualError(t, err, "no private key data (did you try to use this after Load?)")
+
+	caCert, err := x509.ParseCertificate(ca.caCertBytes)
+	require.NoError(t, err)
+	require.Equal(t, &signer.PublicKey, caCert.PublicKey)
+	require.NoError(t, caCert.CheckSignatureFrom(caCert))
+
+	// the private key argument is ignored when loading a CA which signs with an external signer
+	reloaded, err := Load(string(ca.Bundle()), "", WithSigner(signer))
+	require.NoError(t, err)
+	certPEM, keyPEM, err := reloaded.IssueClientCertPEM("test-user", nil, time.Hour)
+	require.NoError(t, err)
+	_, err = tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	clientCert, err := x509.ParseCertificate(mustDecodePEM(t, certPEM))
+	require.NoError(t, err)
+	require.NoError(t, clientCert.CheckSignatureFrom(caCert))
+
+	otherSigner, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = Load(string(ca.Bundle()), "", WithSigner(otherSigner))
+	require.EqualError(t, err, "invalid CA certificate: certificate does not match the public key of the signer")
+
+	_, err = Load("", "", WithSigner(signer))
+	require.EqualError(t, err, "invalid CA certificate: expected a single certificate, found 0 certificates")
+}
+
+func mustDecodePEM(t *testing.T, certPEM []byte) []byte {
+	t.Helper()
+	block, _ := pem.Decode(certPEM)
+	require.NotNil(t, block)
+	return block.Bytes
+}
+
 func TestBundle(t *testing.T) {
 	ca := CA{caCertBytes: []byte{1, 2, 3, 4, 5, 6, 7, 8}}
 	certPEM := ca.Bundle()
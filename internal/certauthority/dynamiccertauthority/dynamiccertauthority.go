@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package dynamiccertauthority implements a x509 certificate authority capable of issuing
@@ -17,13 +17,17 @@ import (
 // ca is a type capable of issuing certificates.
 type ca struct {
 	provider dynamiccertificates.CertKeyContentProvider
+	opts     []certauthority.Option
 }
 
 // New creates a ClientCertIssuer, ready to issue certs whenever
 // the given CertKeyContentProvider has a keypair to provide.
-func New(provider dynamiccertificates.CertKeyContentProvider) issuer.ClientCertIssuer {
+// The options are used to load the CA, e.g. to choose the key algorithm of the issued certs,
+// or to sign with an external signer when the provider only provides the CA cert.
+func New(provider dynamiccertificates.CertKeyContentProvider, opts ...certauthority.Option) issuer.ClientCertIssuer {
 	return &ca{
 		provider: provider,
+		opts:     opts,
 	}
 }
 
@@ -38,7 +42,7 @@ func (c *ca) IssueClientCertPEM(username string, groups []string, ttl time.Durat
 	// in the future we could split dynamiccert.Private into two interfaces (Private and PrivateRead)
 	// and have this code take PrivateRead as input.  We would then add ourselves as a listener to
 	// the PrivateRead.  This would allow us to only reload the CA contents when they actually change.
-	ca, err := certauthority.Load(string(caCrtPEM), string(caKeyPEM))
+	ca, err := certauthority.Load(string(caCrtPEM), string(caKeyPEM), c.opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1,14 +1,18 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package dynamiccertauthority
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"go.pinniped.dev/internal/certauthority"
 	"go.pinniped.dev/internal/dynamiccert"
 	"go.pinniped.dev/internal/issuer"
 	"go.pinniped.dev/internal/testutil"
@@ -116,6 +120,29 @@ func TestCAIssuePEM(t *testing.T) {
 	}
 }
 
+func TestCAIssuePEMWithSigner(t *testing.T) {
+	t.Parallel()
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	provider := dynamiccert.NewCAWithSigner(t.Name(), signer)
+	ca := New(provider, certauthority.WithSigner(signer))
+
+	_, _, err = ca.IssueClientCertPEM("some-username", nil, time.Hour)
+	require.EqualError(t, err, "invalid CA certificate: expected a single certificate, found 0 certificates")
+
+	signerCA, err := certauthority.New("some-ca", time.Hour, certauthority.WithSigner(signer))
+	require.NoError(t, err)
+
+	crtPEM, keyPEM, err := issuePEM(provider, ca, signerCA.Bundle(), nil)
+	require.NoError(t, err)
+
+	crtAssertions := testutil.ValidateClientCertificate(t, string(signerCA.Bundle()), string(crtPEM))
+	crtAssertions.RequireCommonName("some-username")
+	crtAssertions.RequireMatchesPrivateKey(string(keyPEM))
+}
+
 func issuePEM(provider dynamiccert.Provider, ca issuer.ClientCertIssuer, caCrt, caKey []byte) ([]byte, []byte, error) {
 	// if setting fails, look at that error
 	if caCrt != nil || caKey != nil {
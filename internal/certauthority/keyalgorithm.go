@@ -0,0 +1,59 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package certauthority
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"io"
+)
+
+// KeyAlgorithm is the algorithm and size of the private keys which are generated by a CA.
+type KeyAlgorithm string
+
+const (
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ECDSA-P256"
+	KeyAlgorithmECDSAP384 KeyAlgorithm = "ECDSA-P384"
+	KeyAlgorithmRSA2048   KeyAlgorithm = "RSA-2048"
+	KeyAlgorithmRSA4096   KeyAlgorithm = "RSA-4096"
+
+	DefaultKeyAlgorithm = KeyAlgorithmECDSAP256
+)
+
+// Validate returns an error when the key algorithm is not supported. The empty string means DefaultKeyAlgorithm.
+func (a KeyAlgorithm) Validate() error {
+	switch a {
+	case "", KeyAlgorithmECDSAP256, KeyAlgorithmECDSAP384, KeyAlgorithmRSA2048, KeyAlgorithmRSA4096:
+		return nil
+	default:
+		return fmt.Errorf("unsupported key algorithm %q, must be one of %s, %s, %s or %s",
+			string(a), KeyAlgorithmECDSAP256, KeyAlgorithmECDSAP384, KeyAlgorithmRSA2048, KeyAlgorithmRSA4096)
+	}
+}
+
+func (a KeyAlgorithm) generateKey(rng io.Reader) (crypto.Signer, error) {
+	var (
+		key crypto.Signer
+		err error
+	)
+	switch a {
+	case "", KeyAlgorithmECDSAP256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rng)
+	case KeyAlgorithmECDSAP384:
+		key, err = ecdsa.GenerateKey(elliptic.P384(), rng)
+	case KeyAlgorithmRSA2048:
+		key, err = rsa.GenerateKey(rng, 2048)
+	case KeyAlgorithmRSA4096:
+		key, err = rsa.GenerateKey(rng, 4096)
+	default:
+		return nil, a.Validate()
+	}
+	if err != nil {
+		return nil, err // do not return a non-nil interface which holds a nil key
+	}
+	return key, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package certauthority
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		keyAlgorithm KeyAlgorithm
+		wantBits     int
+	}{
+		{keyAlgorithm: "", wantBits: 256},
+		{keyAlgorithm: KeyAlgorithmECDSAP256, wantBits: 256},
+		{keyAlgorithm: KeyAlgorithmECDSAP384, wantBits: 384},
+		{keyAlgorithm: KeyAlgorithmRSA2048, wantBits: 2048},
+		{keyAlgorithm: KeyAlgorithmRSA4096, wantBits: 4096},
+	} {
+		tt := tt
+		t.Run(string(tt.keyAlgorithm), func(t *testing.T) {
+			t.Parallel()
+
+			require.NoError(t, tt.keyAlgorithm.Validate())
+
+			ca, err := New("Test CA", time.Hour, WithKeyAlgorithm(tt.keyAlgorithm))
+			require.NoError(t, err)
+
+			caCert, err := x509.ParseCertificate(ca.caCertBytes)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBits, publicKeyBits(t, caCert.PublicKey))
+
+			// the exported key of the CA can be loaded again, and the loaded CA keeps using the key algorithm
+			keyPEM, err := ca.PrivateKeyToPEM()
+			require.NoError(t, err)
+			_, err = tls.X509KeyPair(ca.Bundle(), keyPEM)
+			require.NoError(t, err)
+			reloaded, err := Load(string(ca.Bundle()), string(keyPEM), WithKeyAlgorithm(tt.keyAlgorithm))
+			require.NoError(t, err)
+
+			certPEM, certKeyPEM, err := reloaded.IssueServerCertPEM(nil, []net.IP{net.ParseIP("127.0.0.1")}, time.Hour)
+			require.NoError(t, err)
+			validateServerCert(t, ca.Bundle(), certPEM, certKeyPEM, nil, []net.IP{net.ParseIP("127.0.0.1")}, time.Hour)
+
+			cert, err := tls.X509KeyPair(certPEM, certKeyPEM)
+			require.NoError(t, err)
+			leaf, err := x509.ParseCertificate(cert.Certificate[0])
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBits, publicKeyBits(t, leaf.PublicKey))
+		})
+	}
+}
+
+func TestKeyAlgorithmValidate(t *testing.T) {
+	t.Parallel()
+
+	err := KeyAlgorithm("ED25519").Validate()
+	require.EqualError(t, err, `unsupported key algorithm "ED25519", must be one of ECDSA-P256, ECDSA-P384, RSA-2048 or RSA-4096`)
+
+	_, err = New("Test CA", time.Hour, WithKeyAlgorithm("ED25519"))
+	require.EqualError(t, err, `could not generate CA private key: unsupported key algorithm "ED25519", must be one of ECDSA-P256, ECDSA-P384, RSA-2048 or RSA-4096`)
+}
+
+func publicKeyBits(t *testing.T, publicKey interface{}) int {
+	t.Helper()
+
+	switch k := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return k.Curve.Params().BitSize
+	case *rsa.PublicKey:
+		return k.N.BitLen()
+	default:
+		t.Fatalf("unexpected public key type %T", publicKey)
+		return 0
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package apiserver
@@ -6,6 +6,7 @@ package apiserver
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -15,12 +16,15 @@ import (
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/client-go/pkg/version"
+	"k8s.io/client-go/tools/events"
 
 	"go.pinniped.dev/internal/controllerinit"
+	"go.pinniped.dev/internal/crdconversion"
 	"go.pinniped.dev/internal/issuer"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/registry/credentialrequest"
 	"go.pinniped.dev/internal/registry/whoamirequest"
+	"go.pinniped.dev/internal/tokenreviewwebhook"
 )
 
 type Config struct {
@@ -36,6 +40,18 @@ type ExtraConfig struct {
 	NegotiatedSerializer          runtime.NegotiatedSerializer
 	LoginConciergeGroupVersion    schema.GroupVersion
 	IdentityConciergeGroupVersion schema.GroupVersion
+
+	// EventRecorder records the Events of notable outcomes of TokenCredentialRequests, e.g. of credentials which are
+	// issued for users in one of the PrivilegedGroups.
+	EventRecorder    events.EventRecorder
+	PrivilegedGroups []string
+
+	// ConversionWebhook serves the conversion webhook of the CustomResourceDefinitions at crdconversion.Path, unless
+	// it is nil.
+	ConversionWebhook http.Handler
+
+	// TokenReviewWebhook serves the TokenReview webhook under tokenreviewwebhook.PathPrefix, unless it is nil.
+	TokenReviewWebhook http.Handler
 }
 
 type PinnipedServer struct {
@@ -80,7 +96,13 @@ func (c completedConfig) New() (*PinnipedServer, error) {
 	for _, f := range []func() (schema.GroupVersionResource, rest.Storage){
 		func() (schema.GroupVersionResource, rest.Storage) {
 			tokenCredReqGVR := c.ExtraConfig.LoginConciergeGroupVersion.WithResource("tokencredentialrequests")
-			tokenCredStorage := credentialrequest.NewREST(c.ExtraConfig.Authenticator, c.ExtraConfig.Issuer, tokenCredReqGVR.GroupResource())
+			tokenCredStorage := credentialrequest.NewREST(
+				c.ExtraConfig.Authenticator,
+				c.ExtraConfig.Issuer,
+				tokenCredReqGVR.GroupResource(),
+				c.ExtraConfig.EventRecorder,
+				c.ExtraConfig.PrivilegedGroups,
+			)
 			return tokenCredReqGVR, tokenCredStorage
 		},
 		func() (schema.GroupVersionResource, rest.Storage) {
@@ -107,6 +129,14 @@ func (c completedConfig) New() (*PinnipedServer, error) {
 		return nil, fmt.Errorf("could not install API groups: %w", err)
 	}
 
+	if c.ExtraConfig.ConversionWebhook != nil {
+		s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(crdconversion.Path, c.ExtraConfig.ConversionWebhook)
+	}
+
+	if c.ExtraConfig.TokenReviewWebhook != nil {
+		s.GenericAPIServer.Handler.NonGoRestfulMux.HandlePrefix(tokenreviewwebhook.PathPrefix, c.ExtraConfig.TokenReviewWebhook)
+	}
+
 	shutdown := &sync.WaitGroup{}
 	s.GenericAPIServer.AddPostStartHookOrDie("start-controllers",
 		func(postStartContext genericapiserver.PostStartHookContext) error {
@@ -47,6 +47,7 @@ import (
 	genericoptions "k8s.io/apiserver/pkg/server/options"
 	auditfake "k8s.io/apiserver/plugin/pkg/audit/fake"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/transport"
 
 	"go.pinniped.dev/internal/constable"
@@ -55,9 +56,13 @@ import (
 	"go.pinniped.dev/internal/httputil/securityheader"
 	"go.pinniped.dev/internal/kubeclient"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/pmetrics"
 	"go.pinniped.dev/internal/valuelesscontext"
 )
 
+// logger is named so that the log level of the impersonation proxy can be set separately via log.components.
+var logger = plog.New().WithName("impersonation-proxy") //nolint:gochecknoglobals
+
 // FactoryFunc is a function which can create an impersonator server.
 // It returns a function which will start the impersonator server.
 // That start function takes a stopCh which can be used to stop the server.
@@ -69,23 +74,49 @@ type FactoryFunc func(
 	impersonationProxySignerCA dynamiccert.Public,
 ) (func(stopCh <-chan struct{}) error, error)
 
-func New(
-	port int,
-	dynamicCertProvider dynamiccert.Private,
-	impersonationProxySignerCA dynamiccert.Public,
-) (func(stopCh <-chan struct{}) error, error) {
-	return newInternal(port, dynamicCertProvider, impersonationProxySignerCA, kubeclient.Secure, nil, nil, nil)
+// NewFactory returns a FactoryFunc which creates impersonator servers that use the TLS config of the given ConfigFunc,
+// whose transports to the Kubernetes API server are tuned by the given TransportSpec, and which treat privileged users
+// as configured by the given PrivilegedIdentitiesSpec. When the given targetClusterKubeconfig is not empty, the
+// Concierge runs outside of its target cluster, and the impersonator servers use the credentials of that kubeconfig
+// file for everything, including reverse proxying, since the impersonation proxy service account token of the target
+// cluster is not mounted into the pod.
+func NewFactory(tlsConfigFunc ptls.ConfigFunc, transportSpec TransportSpec, privilegedIdentities PrivilegedIdentitiesSpec, targetClusterKubeconfig string) FactoryFunc {
+	return func(
+		port int,
+		dynamicCertProvider dynamiccert.Private,
+		impersonationProxySignerCA dynamiccert.Public,
+	) (func(stopCh <-chan struct{}) error, error) {
+		if targetClusterKubeconfig == "" {
+			return newInternal(port, dynamicCertProvider, impersonationProxySignerCA, tlsConfigFunc, transportSpec, privilegedIdentities, kubeclient.Secure, nil, nil, nil)
+		}
+
+		targetClusterConfig, err := clientcmd.BuildConfigFromFlags("", targetClusterKubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("could not load kubeconfig of target cluster: %w", err)
+		}
+		useTargetClusterKubeconfig := func(recommendedOptions *genericoptions.RecommendedOptions) {
+			recommendedOptions.Authentication.RemoteKubeConfigFile = targetClusterKubeconfig
+			recommendedOptions.Authorization.RemoteKubeConfigFile = targetClusterKubeconfig
+		}
+		return newInternal(port, dynamicCertProvider, impersonationProxySignerCA, tlsConfigFunc, transportSpec, privilegedIdentities,
+			kubeclient.SecureFor(targetClusterConfig), []kubeclient.Option{kubeclient.WithConfig(targetClusterConfig)}, useTargetClusterKubeconfig, nil)
+	}
 }
 
 func newInternal( //nolint:funlen // yeah, it's kind of long.
 	port int,
 	dynamicCertProvider dynamiccert.Private,
 	impersonationProxySignerCA dynamiccert.Public,
-	restConfigFunc ptls.RestConfigFunc, // for unit testing, should always be kubeclient.Secure in production
-	clientOpts []kubeclient.Option, // for unit testing, should always be nil in production
-	recOpts func(*genericoptions.RecommendedOptions), // for unit testing, should always be nil in production
+	tlsConfigFunc ptls.ConfigFunc,
+	transportSpec TransportSpec,
+	privilegedIdentities PrivilegedIdentitiesSpec,
+	restConfigFunc ptls.RestConfigFunc, // for unit testing and for a remote target cluster, otherwise kubeclient.Secure in production
+	clientOpts []kubeclient.Option, // for unit testing and for a remote target cluster, otherwise nil in production
+	recOpts func(*genericoptions.RecommendedOptions), // for unit testing and for a remote target cluster, otherwise nil in production
 	recConfig func(*genericapiserver.RecommendedConfig), // for unit testing, should always be nil in production
 ) (func(stopCh <-chan struct{}) error, error) {
+	registerMetrics()
+
 	var listener net.Listener
 
 	constructServer := func() (func(stopCh <-chan struct{}) error, error) {
@@ -107,8 +138,8 @@ func newInternal( //nolint:funlen // yeah, it's kind of long.
 
 		// secure TLS for connections coming from external clients and going to the Kube API server
 		// this is best effort because not all options provide the right hooks to override TLS config
-		// since any client could connect to the impersonation proxy, this uses the default TLS config
-		if err := ptls.DefaultRecommendedOptions(recommendedOptions, restConfigFunc); err != nil {
+		// since any client could connect to the impersonation proxy, this uses the default TLS config unless the TLS profile says otherwise
+		if err := ptls.RecommendedOptions(recommendedOptions, tlsConfigFunc, restConfigFunc); err != nil {
 			return nil, fmt.Errorf("failed to secure recommended options: %w", err)
 		}
 
@@ -174,7 +205,7 @@ func newInternal( //nolint:funlen // yeah, it's kind of long.
 
 		// Assume proto config is safe because transport level configs do not use rest.ContentConfig.
 		// Thus if we are interacting with actual APIs, they should be using pre-built clients.
-		impersonationProxyFunc, err := newImpersonationReverseProxyFunc(rest.CopyConfig(kubeClientForProxy.ProtoConfig))
+		impersonationProxyFunc, err := newImpersonationReverseProxyFunc(rest.CopyConfig(kubeClientForProxy.ProtoConfig), transportSpec, privilegedIdentities)
 		if err != nil {
 			return nil, err
 		}
@@ -192,6 +223,7 @@ func newInternal( //nolint:funlen // yeah, it's kind of long.
 				defer impersonationProxyCompleted.ServeHTTP(w, r)
 				impersonationProxy.ServeHTTP(w, r)
 			}))
+			handler = withRequestDurationMetric(handler)
 			handler = filterlatency.TrackStarted(handler, c.TracerProvider, "impersonationproxy")
 
 			// The standard Kube handler chain (authn, authz, impersonation, audit, etc).
@@ -220,7 +252,7 @@ func newInternal( //nolint:funlen // yeah, it's kind of long.
 		if err != nil {
 			return nil, fmt.Errorf("could not detect if anonymous authentication is enabled: %w", err)
 		}
-		plog.Debug("anonymous authentication probed", "anonymousAuthEnabled", anonymousAuthEnabled)
+		logger.Debug("anonymous authentication probed", "anonymousAuthEnabled", anonymousAuthEnabled)
 
 		// if we ever start unioning a TCR bearer token authenticator with serverConfig.Authenticator
 		// then we will need to update the related assumption in tokenPassthroughRoundTripper
@@ -341,7 +373,7 @@ func newInternal( //nolint:funlen // yeah, it's kind of long.
 }
 
 func getReverseProxyClient(clientOpts []kubeclient.Option) (*kubeclient.Client, error) {
-	// just use the overrides given during unit tests
+	// just use the overrides given during unit tests or for a remote target cluster
 	if len(clientOpts) != 0 {
 		return kubeclient.New(clientOpts...)
 	}
@@ -437,9 +469,10 @@ func withBearerTokenPreservation(delegate http.Handler) http.Handler {
 			return nil, false, nil
 		})).AuthenticateRequest(r)
 
-		// smuggle the token through the context.  this does mean that we need to avoid logging the context.
+		// smuggle the token through the context.  the token is wrapped in a plog.Secret so that it is redacted
+		// if the context is ever logged or printed, and plog refuses to serialize contexts as a second safeguard.
 		if len(reqToken) != 0 {
-			ctx := context.WithValue(r.Context(), tokenKey, reqToken)
+			ctx := context.WithValue(r.Context(), tokenKey, plog.NewSecret(reqToken))
 			r = r.WithContext(ctx)
 		}
 
@@ -447,9 +480,21 @@ func withBearerTokenPreservation(delegate http.Handler) http.Handler {
 	})
 }
 
+// withRequestDurationMetric observes the durations of the proxied requests, labeled by their verb, which is known once
+// the standard Kube handler chain has parsed the request info.
+func withRequestDurationMetric(delegate http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verb := "unknown"
+		if requestInfo, ok := genericapirequest.RequestInfoFrom(r.Context()); ok {
+			verb = requestInfo.Verb
+		}
+		pmetrics.InstrumentHandler(delegate, requestDurationMetric, verb).ServeHTTP(w, r)
+	})
+}
+
 func tokenFrom(ctx context.Context) string {
-	token, _ := ctx.Value(tokenKey).(string)
-	return token
+	token, _ := ctx.Value(tokenKey).(plog.Secret)
+	return token.Reveal()
 }
 
 // contextKey type is unexported to prevent collisions.
@@ -457,26 +502,26 @@ type contextKey int
 
 const tokenKey contextKey = iota
 
-func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapiserver.Config) http.Handler, error) {
+func newImpersonationReverseProxyFunc(restConfig *rest.Config, transportSpec TransportSpec, privilegedIdentities PrivilegedIdentitiesSpec) (func(*genericapiserver.Config) http.Handler, error) {
 	serverURL, err := url.Parse(restConfig.Host)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse host URL from in-cluster config: %w", err)
 	}
 
-	http1RoundTripper, err := getTransportForProtocol(restConfig, "http/1.1")
+	http1RoundTripper, err := getTransportForProtocol(restConfig, "http/1.1", transportSpec)
 	if err != nil {
 		return nil, fmt.Errorf("could not get http/1.1 round tripper: %w", err)
 	}
-	http1RoundTripperAnonymous, err := getTransportForProtocol(kubeclient.SecureAnonymousClientConfig(restConfig), "http/1.1")
+	http1RoundTripperAnonymous, err := getTransportForProtocol(kubeclient.SecureAnonymousClientConfig(restConfig), "http/1.1", transportSpec)
 	if err != nil {
 		return nil, fmt.Errorf("could not get http/1.1 anonymous round tripper: %w", err)
 	}
 
-	http2RoundTripper, err := getTransportForProtocol(restConfig, "h2")
+	http2RoundTripper, err := getTransportForProtocol(restConfig, "h2", transportSpec)
 	if err != nil {
 		return nil, fmt.Errorf("could not get http/2.0 round tripper: %w", err)
 	}
-	http2RoundTripperAnonymous, err := getTransportForProtocol(kubeclient.SecureAnonymousClientConfig(restConfig), "h2")
+	http2RoundTripperAnonymous, err := getTransportForProtocol(kubeclient.SecureAnonymousClientConfig(restConfig), "h2", transportSpec)
 	if err != nil {
 		return nil, fmt.Errorf("could not get http/2.0 anonymous round tripper: %w", err)
 	}
@@ -484,7 +529,7 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 	return func(c *genericapiserver.Config) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if len(r.Header.Values("Authorization")) != 0 {
-				plog.Warning("aggregated API server logic did not delete authorization header but it is always supposed to do so",
+				logger.Warning("aggregated API server logic did not delete authorization header but it is always supposed to do so",
 					"url", r.URL.String(),
 					"method", r.Method,
 				)
@@ -493,7 +538,7 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 			}
 
 			if err := ensureNoImpersonationHeaders(r); err != nil {
-				plog.Error("unknown impersonation header seen",
+				logger.Error("unknown impersonation header seen",
 					err,
 					"url", r.URL.String(),
 					"method", r.Method,
@@ -504,7 +549,7 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 
 			userInfo, ok := request.UserFrom(r.Context())
 			if !ok {
-				plog.Warning("aggregated API server logic did not set user info but it is always supposed to do so",
+				logger.Warning("aggregated API server logic did not set user info but it is always supposed to do so",
 					"url", r.URL.String(),
 					"method", r.Method,
 				)
@@ -514,7 +559,7 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 
 			ae := audit.AuditEventFrom(r.Context())
 			if ae == nil {
-				plog.Warning("aggregated API server logic did not set audit event but it is always supposed to do so",
+				logger.Warning("aggregated API server logic did not set audit event but it is always supposed to do so",
 					"url", r.URL.String(),
 					"method", r.Method,
 				)
@@ -522,6 +567,29 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 				return
 			}
 
+			if err := privilegedIdentities.checkNestedImpersonation(userInfo, ae); err != nil {
+				logger.Warning("rejecting nested impersonation of a system identity",
+					"url", r.URL.String(),
+					"method", r.Method,
+					"originalUsername", ae.User.Username,
+					"username", userInfo.GetName(),
+					"groups", userInfo.GetGroups(),
+				)
+				newStatusErrResponse(w, r, c.Serializer, err)
+				return
+			}
+
+			// privileged requests are logged at the default log level so that they can be alerted on
+			if privilegedGroups := privilegedIdentities.privilegedGroupsOf(userInfo); len(privilegedGroups) > 0 {
+				logger.Warning("impersonation proxy servicing request for a privileged user",
+					"url", r.URL.String(),
+					"method", r.Method,
+					"originalUsername", ae.User.Username,
+					"username", userInfo.GetName(),
+					"privilegedGroups", privilegedGroups,
+				)
+			}
+
 			// grab the request's bearer token if present.  this is optional and does not fail the request if missing.
 			token := tokenFrom(r.Context())
 
@@ -535,7 +603,7 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 
 			rt, err := getTransportForUser(r.Context(), userInfo, baseRT, baseRTAnonymous, ae, token, c.Authentication.Authenticator)
 			if err != nil {
-				plog.WarningErr("rejecting request as we cannot act as the current user", err,
+				logger.WarningErr("rejecting request as we cannot act as the current user", err,
 					"url", r.URL.String(),
 					"method", r.Method,
 					"isUpgradeRequest", isUpgradeRequest,
@@ -544,12 +612,12 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 				return
 			}
 
-			plog.Debug("impersonation proxy servicing request",
+			logger.Debug("impersonation proxy servicing request",
 				"url", r.URL.String(),
 				"method", r.Method,
 				"isUpgradeRequest", isUpgradeRequest,
 			)
-			plog.Trace("impersonation proxy servicing request was for user",
+			logger.Trace("impersonation proxy servicing request was for user",
 				"url", r.URL.String(),
 				"method", r.Method,
 				"isUpgradeRequest", isUpgradeRequest,
@@ -558,7 +626,7 @@ func newImpersonationReverseProxyFunc(restConfig *rest.Config) (func(*genericapi
 
 			// The proxy library used below will panic when the client disconnects abruptly, so in order to
 			// assure that this log message is always printed at the end of this func, it must be deferred.
-			defer plog.Debug("impersonation proxy finished servicing request",
+			defer logger.Debug("impersonation proxy finished servicing request",
 				"url", r.URL.String(),
 				"method", r.Method,
 				"isUpgradeRequest", isUpgradeRequest,
@@ -696,7 +764,7 @@ func tokenPassthroughRoundTripper(ctx context.Context, delegateAnonymous http.Ro
 	// if the user who made the request and the token do not match, we cannot go any further at this point
 	if !apiequality.Semantic.DeepEqual(ae.User, tokenUser) {
 		// this info leak seems fine for trace level logs
-		plog.Trace("failed to passthrough token due to user mismatch",
+		logger.Trace("failed to passthrough token due to user mismatch",
 			"original-username", ae.User.Username,
 			"original-uid", ae.User.UID,
 			"token-username", tokenUser.Username,
@@ -800,19 +868,19 @@ func newStatusErrResponse(w http.ResponseWriter, r *http.Request, s runtime.Nego
 	responsewriters.ErrorNegotiated(err, s, gv, w, r)
 }
 
-func getTransportForProtocol(restConfig *rest.Config, protocol string) (http.RoundTripper, error) {
+func getTransportForProtocol(restConfig *rest.Config, protocol string, transportSpec TransportSpec) (http.RoundTripper, error) {
 	transportConfig, err := restConfig.TransportConfig()
 	if err != nil {
 		return nil, fmt.Errorf("could not get in-cluster transport config: %w", err)
 	}
 	transportConfig.TLS.NextProtos = []string{protocol}
 
-	rt, err := transport.New(transportConfig)
+	rt, err := transportSpec.newTransport(transportConfig)
 	if err != nil {
 		return nil, fmt.Errorf("could not build transport: %w", err)
 	}
 
-	// For clients that support http2, transport.New calls http2.ConfigureTransports,
+	// For clients that support http2, newTransport calls http2.ConfigureTransports,
 	// which configures with both h2 and http/1.1,
 	// even when you explicitly only ask for h2.
 	// Override that change.
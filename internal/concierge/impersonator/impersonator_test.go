@@ -4,6 +4,7 @@
 package impersonator
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -54,6 +55,7 @@ import (
 	"go.pinniped.dev/internal/here"
 	"go.pinniped.dev/internal/httputil/roundtripper"
 	"go.pinniped.dev/internal/kubeclient"
+	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/testutil/tlsserver"
 )
 
@@ -93,6 +95,7 @@ func TestImpersonator(t *testing.T) {
 		kubeAPIServerStatusCode            int
 		kubeAPIServerHealthz               http.Handler
 		anonymousAuthDisabled              bool
+		privilegedIdentities               PrivilegedIdentitiesSpec
 		wantKubeAPIServerRequestHeaders    http.Header
 		wantError                          string
 		wantConstructionError              string
@@ -470,6 +473,113 @@ func TestImpersonator(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:                               "nested impersonation by admin users cannot impersonate system users by default",
+			clientCert:                         newClientCert(t, ca, "test-admin", []string{"system:masters", "test-group2"}),
+			clientImpersonateUser:              rest.ImpersonationConfig{UserName: "system:kube-controller-manager"},
+			kubeAPIServerClientBearerTokenFile: "required-to-be-set",
+			wantError: `users "system:kube-controller-manager" is forbidden: ` +
+				`user "test-admin" cannot impersonate system users through the impersonation proxy`,
+			wantAuthorizerAttributes: []authorizer.AttributesRecord{
+				{
+					User: &user.DefaultInfo{Name: "test-admin", UID: "", Groups: []string{"test-group2", "system:masters", "system:authenticated"}, Extra: nil},
+					Verb: "impersonate", Namespace: "", APIGroup: "", APIVersion: "", Resource: "users", Subresource: "", Name: "system:kube-controller-manager", ResourceRequest: true, Path: "",
+				},
+				{
+					User: &user.DefaultInfo{Name: "system:kube-controller-manager", UID: "", Groups: []string{"system:authenticated"}, Extra: map[string][]string{}},
+					Verb: "list", Namespace: "", APIGroup: "", APIVersion: "v1", Resource: "namespaces", Subresource: "", Name: "", ResourceRequest: true, Path: "/api/v1/namespaces",
+				},
+			},
+		},
+		{
+			name:       "nested impersonation by admin users cannot impersonate system groups by default",
+			clientCert: newClientCert(t, ca, "test-admin", []string{"system:masters", "test-group2"}),
+			clientImpersonateUser: rest.ImpersonationConfig{
+				UserName: "fire",
+				Groups:   []string{"elements", "system:masters"},
+			},
+			kubeAPIServerClientBearerTokenFile: "required-to-be-set",
+			wantError: `groups "system:masters" is forbidden: ` +
+				`user "test-admin" cannot impersonate system groups through the impersonation proxy`,
+			wantAuthorizerAttributes: []authorizer.AttributesRecord{
+				{
+					User: &user.DefaultInfo{Name: "test-admin", UID: "", Groups: []string{"test-group2", "system:masters", "system:authenticated"}, Extra: nil},
+					Verb: "impersonate", Namespace: "", APIGroup: "", APIVersion: "", Resource: "users", Subresource: "", Name: "fire", ResourceRequest: true, Path: "",
+				},
+				{
+					User: &user.DefaultInfo{Name: "test-admin", UID: "", Groups: []string{"test-group2", "system:masters", "system:authenticated"}, Extra: nil},
+					Verb: "impersonate", Namespace: "", APIGroup: "", APIVersion: "", Resource: "groups", Subresource: "", Name: "elements", ResourceRequest: true, Path: "",
+				},
+				{
+					User: &user.DefaultInfo{Name: "test-admin", UID: "", Groups: []string{"test-group2", "system:masters", "system:authenticated"}, Extra: nil},
+					Verb: "impersonate", Namespace: "", APIGroup: "", APIVersion: "", Resource: "groups", Subresource: "", Name: "system:masters", ResourceRequest: true, Path: "",
+				},
+				{
+					User: &user.DefaultInfo{Name: "fire", UID: "", Groups: []string{"elements", "system:masters", "system:authenticated"}, Extra: map[string][]string{}},
+					Verb: "list", Namespace: "", APIGroup: "", APIVersion: "v1", Resource: "namespaces", Subresource: "", Name: "", ResourceRequest: true, Path: "/api/v1/namespaces",
+				},
+			},
+		},
+		{
+			name:       "nested impersonation by admin users can impersonate system identities when allowed",
+			clientCert: newClientCert(t, ca, "test-admin", []string{"system:masters", "test-group2"}),
+			clientImpersonateUser: rest.ImpersonationConfig{
+				UserName: "system:kube-controller-manager",
+				Groups:   []string{"system:masters"},
+			},
+			privilegedIdentities:               PrivilegedIdentitiesSpec{AllowImpersonatingSystemIdentities: true},
+			kubeAPIServerClientBearerTokenFile: "required-to-be-set",
+			wantKubeAPIServerRequestHeaders: http.Header{
+				"Impersonate-User":  {"system:kube-controller-manager"},
+				"Impersonate-Group": {"system:masters", "system:authenticated"},
+				"Impersonate-Extra-Original-User-Info.impersonation-Proxy.concierge.pinniped.dev": {`{"username":"test-admin","groups":["test-group2","system:masters","system:authenticated"]}`},
+				"Authorization":   {"Bearer some-service-account-token"},
+				"User-Agent":      {"test-agent"},
+				"Accept":          {"application/vnd.kubernetes.protobuf,application/json"},
+				"Accept-Encoding": {"gzip"},
+				"X-Forwarded-For": {"127.0.0.1"},
+			},
+			wantAuthorizerAttributes: []authorizer.AttributesRecord{
+				{
+					User: &user.DefaultInfo{Name: "test-admin", UID: "", Groups: []string{"test-group2", "system:masters", "system:authenticated"}, Extra: nil},
+					Verb: "impersonate", Namespace: "", APIGroup: "", APIVersion: "", Resource: "users", Subresource: "", Name: "system:kube-controller-manager", ResourceRequest: true, Path: "",
+				},
+				{
+					User: &user.DefaultInfo{Name: "test-admin", UID: "", Groups: []string{"test-group2", "system:masters", "system:authenticated"}, Extra: nil},
+					Verb: "impersonate", Namespace: "", APIGroup: "", APIVersion: "", Resource: "groups", Subresource: "", Name: "system:masters", ResourceRequest: true, Path: "",
+				},
+				{
+					User: &user.DefaultInfo{Name: "system:kube-controller-manager", UID: "", Groups: []string{"system:masters", "system:authenticated"}, Extra: map[string][]string{}},
+					Verb: "list", Namespace: "", APIGroup: "", APIVersion: "v1", Resource: "namespaces", Subresource: "", Name: "", ResourceRequest: true, Path: "/api/v1/namespaces",
+				},
+			},
+		},
+		{
+			name:                               "nested impersonation by admin users can impersonate service accounts by default",
+			clientCert:                         newClientCert(t, ca, "test-admin", []string{"system:masters", "test-group2"}),
+			clientImpersonateUser:              rest.ImpersonationConfig{UserName: "system:serviceaccount:some-namespace:some-service-account"},
+			kubeAPIServerClientBearerTokenFile: "required-to-be-set",
+			wantKubeAPIServerRequestHeaders: http.Header{
+				"Impersonate-User":  {"system:serviceaccount:some-namespace:some-service-account"},
+				"Impersonate-Group": {"system:serviceaccounts", "system:serviceaccounts:some-namespace", "system:authenticated"},
+				"Impersonate-Extra-Original-User-Info.impersonation-Proxy.concierge.pinniped.dev": {`{"username":"test-admin","groups":["test-group2","system:masters","system:authenticated"]}`},
+				"Authorization":   {"Bearer some-service-account-token"},
+				"User-Agent":      {"test-agent"},
+				"Accept":          {"application/vnd.kubernetes.protobuf,application/json"},
+				"Accept-Encoding": {"gzip"},
+				"X-Forwarded-For": {"127.0.0.1"},
+			},
+			wantAuthorizerAttributes: []authorizer.AttributesRecord{
+				{
+					User: &user.DefaultInfo{Name: "test-admin", UID: "", Groups: []string{"test-group2", "system:masters", "system:authenticated"}, Extra: nil},
+					Verb: "impersonate", Namespace: "some-namespace", APIGroup: "", APIVersion: "", Resource: "serviceaccounts", Subresource: "", Name: "some-service-account", ResourceRequest: true, Path: "",
+				},
+				{
+					User: &user.DefaultInfo{Name: "system:serviceaccount:some-namespace:some-service-account", UID: "", Groups: []string{"system:serviceaccounts", "system:serviceaccounts:some-namespace", "system:authenticated"}, Extra: map[string][]string{}},
+					Verb: "list", Namespace: "", APIGroup: "", APIVersion: "v1", Resource: "namespaces", Subresource: "", Name: "", ResourceRequest: true, Path: "/api/v1/namespaces",
+				},
+			},
+		},
 		{
 			name:                  "nested impersonation by admin users cannot impersonate UID",
 			clientCert:            newClientCert(t, ca, "test-admin", []string{"system:masters", "test-group2"}),
@@ -873,7 +983,7 @@ func TestImpersonator(t *testing.T) {
 			}
 
 			// Create an impersonator.  Use an invalid port number to make sure our listener override works.
-			runner, constructionErr := newInternal(-1000, certKeyContent, caContent, restConfigFunc, clientOpts, recOpts, recConfig)
+			runner, constructionErr := newInternal(-1000, certKeyContent, caContent, ptls.Default, TransportSpec{}, tt.privilegedIdentities, restConfigFunc, clientOpts, recOpts, recConfig)
 			if len(tt.wantConstructionError) > 0 {
 				require.EqualError(t, constructionErr, tt.wantConstructionError)
 				require.Nil(t, runner)
@@ -1076,6 +1186,7 @@ func TestImpersonatorHTTPHandler(t *testing.T) {
 		authenticator                   authenticator.Request
 		wantHTTPBody                    string
 		wantHTTPStatus                  int
+		privilegedIdentities            PrivilegedIdentitiesSpec
 		wantKubeAPIServerRequestHeaders http.Header
 		kubeAPIServerStatusCode         int
 	}{
@@ -1631,6 +1742,48 @@ func TestImpersonatorHTTPHandler(t *testing.T) {
 			wantHTTPBody:   "successful proxied response",
 			wantHTTPStatus: http.StatusOK,
 		},
+		{
+			name: "nested impersonation of a system user is rejected",
+			request: newRequest(t, map[string][]string{
+				"User-Agent": {"test-user-agent"},
+			}, &user.DefaultInfo{
+				Name:   "system:kube-scheduler",
+				Groups: []string{"system:authenticated"},
+			},
+				&auditinternal.Event{
+					User: authenticationv1.UserInfo{
+						Username: "panda",
+						UID:      "0x001",
+						Groups:   []string{"bears", "friends"},
+					},
+					ImpersonatedUser: &authenticationv1.UserInfo{},
+				},
+				"",
+			),
+			wantHTTPBody:   `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":"users \"system:kube-scheduler\" is forbidden: user \"panda\" cannot impersonate system users through the impersonation proxy","reason":"Forbidden","details":{"name":"system:kube-scheduler","kind":"users"},"code":403}` + "\n",
+			wantHTTPStatus: http.StatusForbidden,
+		},
+		{
+			name: "nested impersonation of a system group is rejected",
+			request: newRequest(t, map[string][]string{
+				"User-Agent": {"test-user-agent"},
+			}, &user.DefaultInfo{
+				Name:   testUser,
+				Groups: []string{"system:nodes", "system:authenticated"},
+			},
+				&auditinternal.Event{
+					User: authenticationv1.UserInfo{
+						Username: "panda",
+						UID:      "0x001",
+						Groups:   []string{"bears", "friends"},
+					},
+					ImpersonatedUser: &authenticationv1.UserInfo{},
+				},
+				"",
+			),
+			wantHTTPBody:   `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":"groups \"system:nodes\" is forbidden: user \"panda\" cannot impersonate system groups through the impersonation proxy","reason":"Forbidden","details":{"name":"system:nodes","kind":"groups"},"code":403}` + "\n",
+			wantHTTPStatus: http.StatusForbidden,
+		},
 		{
 			name: "authenticated user with nested impersonation",
 			request: newRequest(t, map[string][]string{
@@ -1861,7 +2014,7 @@ func TestImpersonatorHTTPHandler(t *testing.T) {
 				if err != nil {
 					return nil, err
 				}
-				return newImpersonationReverseProxyFunc(rest.CopyConfig(kubeClientForProxy.ProtoConfig))
+				return newImpersonationReverseProxyFunc(rest.CopyConfig(kubeClientForProxy.ProtoConfig), TransportSpec{}, tt.privilegedIdentities)
 			}()
 
 			if tt.wantCreationErr != "" {
@@ -1950,7 +2103,7 @@ func newRequest(t *testing.T, h http.Header, userInfo user.Info, event *auditint
 	ctx = authenticator.WithAudiences(ctx, authenticator.Audiences{"must-be-ignored"})
 
 	if len(token) != 0 {
-		ctx = context.WithValue(ctx, tokenKey, token)
+		ctx = context.WithValue(ctx, tokenKey, plog.NewSecret(token))
 	}
 
 	var cancel context.CancelFunc
@@ -2086,6 +2239,16 @@ func Test_withBearerTokenPreservation(t *testing.T) {
 
 				require.Equal(t, tt.want, tokenFrom(outputReq.Context()))
 
+				// the token must never leak when the context is printed or logged
+				if len(tt.want) != 0 {
+					for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+						require.NotContains(t, fmt.Sprintf(format, outputReq.Context()), tt.want)
+					}
+					var log bytes.Buffer
+					plog.TestLogger(t, &log).Always("logging a context by mistake", "ctx", outputReq.Context(), "req", outputReq)
+					require.NotContains(t, log.String(), tt.want)
+				}
+
 				if len(tt.want) == 0 {
 					require.True(t, inputReq == outputReq, "expect req to passed through when no token expected")
 				}
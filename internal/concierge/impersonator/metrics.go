@@ -0,0 +1,35 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package impersonator
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"go.pinniped.dev/internal/pmetrics"
+)
+
+// This metric is labeled by the verb of the request, so that the durations of long-running requests, such as watches,
+// can be told apart from the others.
+var (
+	requestDurationMetric = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemImpersonationProxy,
+		Name:           "request_duration_seconds",
+		Help:           "Duration of the requests proxied by the impersonation proxy by verb and HTTP status code.",
+		Buckets:        metrics.DefBuckets,
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"verb", "code"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		pmetrics.ApplyLatencyBuckets(requestDurationMetric.HistogramOpts)
+		legacyregistry.MustRegister(requestDurationMetric)
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package impersonator
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+// systemPrefix is the prefix of the users and groups which Kubernetes reserves for its own components.
+const systemPrefix = "system:"
+
+// PrivilegedIdentitiesSpec configures how the impersonation proxy treats requests which act as privileged users.
+// All fields are optional.
+type PrivilegedIdentitiesSpec struct {
+	// PrivilegedGroups are the groups whose members are privileged. A warning is logged for every request through
+	// the impersonation proxy which acts as a member of one of these groups, and an Event is recorded for every
+	// credential which is issued by a TokenCredentialRequest for a member, so that they can be alerted on.
+	// Defaults to system:masters.
+	PrivilegedGroups []string `json:"privilegedGroups,omitempty"`
+
+	// AllowImpersonatingSystemIdentities allows clients of the impersonation proxy to use nested impersonation to act
+	// as users or groups with the system: prefix, other than service accounts. By default these requests are rejected,
+	// even when the client is authorized to impersonate, because these identities are reserved for Kubernetes itself.
+	AllowImpersonatingSystemIdentities bool `json:"allowImpersonatingSystemIdentities,omitempty"`
+}
+
+// Validate validates the privileged identities configuration.
+func (s PrivilegedIdentitiesSpec) Validate() error {
+	for _, group := range s.PrivilegedGroups {
+		if len(group) == 0 {
+			return constable.Error("privilegedGroups must not contain empty group names")
+		}
+	}
+	return nil
+}
+
+// Groups returns the privileged groups, i.e. PrivilegedGroups or its default.
+func (s PrivilegedIdentitiesSpec) Groups() []string {
+	if len(s.PrivilegedGroups) == 0 {
+		return []string{user.SystemPrivilegedGroup}
+	}
+	return s.PrivilegedGroups
+}
+
+// privilegedGroupsOf returns the groups of the user which are privileged.
+func (s PrivilegedIdentitiesSpec) privilegedGroupsOf(userInfo user.Info) []string {
+	privilegedGroups := s.Groups()
+
+	var groups []string
+	for _, group := range userInfo.GetGroups() {
+		for _, privilegedGroup := range privilegedGroups {
+			if group == privilegedGroup {
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// checkNestedImpersonation returns an error when the client used nested impersonation to act as a user or group which
+// is reserved for Kubernetes itself, unless that is allowed. Service accounts may always be impersonated, and so may
+// the system:authenticated group, since Kubernetes adds it to every impersonated user.
+func (s PrivilegedIdentitiesSpec) checkNestedImpersonation(userInfo user.Info, ae *auditinternal.Event) *apierrors.StatusError {
+	if ae.ImpersonatedUser == nil || s.AllowImpersonatingSystemIdentities {
+		return nil
+	}
+
+	if username := userInfo.GetName(); isReservedSystemIdentity(username, serviceaccount.ServiceAccountUsernamePrefix) {
+		return apierrors.NewForbidden(schema.GroupResource{Resource: "users"}, username,
+			fmt.Errorf("user %q cannot impersonate system users through the impersonation proxy", ae.User.Username))
+	}
+
+	for _, group := range userInfo.GetGroups() {
+		if group == user.AllAuthenticated || group == serviceaccount.AllServiceAccountsGroup {
+			continue
+		}
+		if isReservedSystemIdentity(group, serviceaccount.ServiceAccountGroupPrefix) {
+			return apierrors.NewForbidden(schema.GroupResource{Resource: "groups"}, group,
+				fmt.Errorf("user %q cannot impersonate system groups through the impersonation proxy", ae.User.Username))
+		}
+	}
+
+	return nil
+}
+
+// isReservedSystemIdentity returns true when the name has the system: prefix, but not the given service account prefix.
+func isReservedSystemIdentity(name, serviceAccountPrefix string) bool {
+	return strings.HasPrefix(name, systemPrefix) && !strings.HasPrefix(name, serviceAccountPrefix)
+}
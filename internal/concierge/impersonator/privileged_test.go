@@ -0,0 +1,55 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package impersonator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+func TestPrivilegedIdentitiesSpecValidate(t *testing.T) {
+	require.NoError(t, PrivilegedIdentitiesSpec{}.Validate())
+	require.NoError(t, PrivilegedIdentitiesSpec{PrivilegedGroups: []string{"admins"}}.Validate())
+	require.EqualError(t, PrivilegedIdentitiesSpec{PrivilegedGroups: []string{"admins", ""}}.Validate(),
+		"privilegedGroups must not contain empty group names")
+}
+
+func TestPrivilegedIdentitiesSpecGroups(t *testing.T) {
+	require.Equal(t, []string{"system:masters"}, PrivilegedIdentitiesSpec{}.Groups())
+	require.Equal(t, []string{"admins"}, PrivilegedIdentitiesSpec{PrivilegedGroups: []string{"admins"}}.Groups())
+}
+
+func TestPrivilegedGroupsOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   PrivilegedIdentitiesSpec
+		groups []string
+		want   []string
+	}{
+		{
+			name:   "defaults to system:masters",
+			groups: []string{"a", "system:masters", "system:authenticated"},
+			want:   []string{"system:masters"},
+		},
+		{
+			name:   "no privileged groups",
+			groups: []string{"a", "system:authenticated"},
+			want:   nil,
+		},
+		{
+			name:   "configured groups replace the default",
+			spec:   PrivilegedIdentitiesSpec{PrivilegedGroups: []string{"admins", "cluster-admins"}},
+			groups: []string{"system:masters", "cluster-admins", "a", "admins"},
+			want:   []string{"cluster-admins", "admins"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.spec.privilegedGroupsOf(&user.DefaultInfo{Name: "some-user", Groups: tt.groups}))
+		})
+	}
+}
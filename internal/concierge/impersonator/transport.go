@@ -0,0 +1,153 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package impersonator
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/transport"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+// These defaults match the transports which client-go builds for itself.
+const (
+	defaultMaxIdleConnsPerHost = 25
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultHTTP2PingInterval   = 30 * time.Second
+	defaultHTTP2PingTimeout    = 15 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// TransportSpec tunes the connection pools of the transports which the impersonation proxy uses to send requests
+// to the Kubernetes API server. On clusters with many clients of the impersonation proxy, raising the idle
+// connection limits avoids opening a new connection for most requests. All fields are optional.
+type TransportSpec struct {
+	// MaxIdleConns is the maximum number of idle connections of each transport. Defaults to 0, i.e. no limit.
+	MaxIdleConns *int `json:"maxIdleConns,omitempty"`
+
+	// MaxIdleConnsPerHost is the maximum number of idle connections of each transport to the Kubernetes API server.
+	// Defaults to 25.
+	MaxIdleConnsPerHost *int `json:"maxIdleConnsPerHost,omitempty"`
+
+	// IdleConnTimeoutSeconds is how long an idle connection is kept open before it is closed. Defaults to 90.
+	IdleConnTimeoutSeconds *int64 `json:"idleConnTimeoutSeconds,omitempty"`
+
+	// HTTP2PingIntervalSeconds is how long an HTTP/2 connection may receive no frames before a ping is sent on it.
+	// The pings keep long-running requests, e.g. watches, alive through load balancers which close idle connections,
+	// and close broken connections. It is also the interval of the TCP keepalives of all connections, which keep
+	// upgraded connections, e.g. of exec, alive. Defaults to 30. It should be below the idle timeout of any load
+	// balancer between the impersonation proxy and the Kubernetes API server.
+	HTTP2PingIntervalSeconds *int64 `json:"http2PingIntervalSeconds,omitempty"`
+}
+
+// Validate validates the transport tuning.
+func (s TransportSpec) Validate() error {
+	if s.MaxIdleConns != nil && *s.MaxIdleConns < 0 {
+		return constable.Error("maxIdleConns must not be negative")
+	}
+	if s.MaxIdleConnsPerHost != nil && *s.MaxIdleConnsPerHost <= 0 {
+		return constable.Error("maxIdleConnsPerHost must be positive")
+	}
+	if s.IdleConnTimeoutSeconds != nil && *s.IdleConnTimeoutSeconds <= 0 {
+		return constable.Error("idleConnTimeoutSeconds must be positive")
+	}
+	if s.HTTP2PingIntervalSeconds != nil && *s.HTTP2PingIntervalSeconds <= 0 {
+		return constable.Error("http2PingIntervalSeconds must be positive")
+	}
+	return nil
+}
+
+func (s TransportSpec) maxIdleConnsPerHost() int {
+	if s.MaxIdleConnsPerHost == nil {
+		return defaultMaxIdleConnsPerHost
+	}
+	return *s.MaxIdleConnsPerHost
+}
+
+func (s TransportSpec) idleConnTimeout() time.Duration {
+	if s.IdleConnTimeoutSeconds == nil {
+		return defaultIdleConnTimeout
+	}
+	return time.Duration(*s.IdleConnTimeoutSeconds) * time.Second
+}
+
+func (s TransportSpec) http2PingInterval() time.Duration {
+	if s.HTTP2PingIntervalSeconds == nil {
+		return defaultHTTP2PingInterval
+	}
+	return time.Duration(*s.HTTP2PingIntervalSeconds) * time.Second
+}
+
+// newTransport is like transport.New, except that the connection pool and the keepalives of the transport are
+// tuned by the spec. Unlike transport.New, it never returns a cached transport.
+func (s TransportSpec) newTransport(transportConfig *transport.Config) (http.RoundTripper, error) {
+	if transportConfig.Transport != nil {
+		return nil, constable.Error("a custom transport cannot be tuned")
+	}
+
+	tlsConfig, err := transport.TLSConfigFor(transportConfig)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		return nil, constable.Error("transport config has no TLS settings")
+	}
+
+	dial := (&net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: s.http2PingInterval(), // keeps upgraded connections alive, which do not use http/2.0 pings
+	}).DialContext
+	if transportConfig.DialHolder != nil {
+		dial = transportConfig.DialHolder.Dial
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if transportConfig.Proxy != nil {
+		proxy = transportConfig.Proxy
+	}
+
+	httpTransport := utilnet.SetOldTransportDefaults(&http.Transport{
+		Proxy:               proxy,
+		DialContext:         dial,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: defaultTLSHandshakeTimeout,
+		DisableCompression:  transportConfig.DisableCompression,
+		MaxIdleConnsPerHost: s.maxIdleConnsPerHost(),
+		IdleConnTimeout:     s.idleConnTimeout(),
+	})
+	if s.MaxIdleConns != nil {
+		httpTransport.MaxIdleConns = *s.MaxIdleConns
+	}
+
+	if allowsHTTP2(tlsConfig.NextProtos) {
+		http2Transport, err := http2.ConfigureTransports(httpTransport)
+		if err != nil {
+			return nil, fmt.Errorf("could not configure http/2.0: %w", err)
+		}
+		// The pings detect and close broken connections, and keep idle connections alive.
+		http2Transport.ReadIdleTimeout = s.http2PingInterval()
+		http2Transport.PingTimeout = defaultHTTP2PingTimeout
+	}
+
+	return transport.HTTPWrappersForConfig(transportConfig, httpTransport)
+}
+
+func allowsHTTP2(nextProtos []string) bool {
+	if len(nextProtos) == 0 {
+		return true
+	}
+	for _, protocol := range nextProtos {
+		if protocol == http2.NextProtoTLS {
+			return true
+		}
+	}
+	return false
+}
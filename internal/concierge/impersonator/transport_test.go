@@ -0,0 +1,70 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package impersonator
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/transport"
+	"k8s.io/utils/pointer"
+)
+
+func TestTransportSpec(t *testing.T) {
+	require.NoError(t, TransportSpec{}.Validate())
+	require.NoError(t, TransportSpec{
+		MaxIdleConns:             pointer.Int(0),
+		MaxIdleConnsPerHost:      pointer.Int(1),
+		IdleConnTimeoutSeconds:   pointer.Int64(1),
+		HTTP2PingIntervalSeconds: pointer.Int64(1),
+	}.Validate())
+	require.EqualError(t, TransportSpec{MaxIdleConns: pointer.Int(-1)}.Validate(), "maxIdleConns must not be negative")
+	require.EqualError(t, TransportSpec{MaxIdleConnsPerHost: pointer.Int(0)}.Validate(), "maxIdleConnsPerHost must be positive")
+	require.EqualError(t, TransportSpec{IdleConnTimeoutSeconds: pointer.Int64(0)}.Validate(), "idleConnTimeoutSeconds must be positive")
+	require.EqualError(t, TransportSpec{HTTP2PingIntervalSeconds: pointer.Int64(-5)}.Validate(), "http2PingIntervalSeconds must be positive")
+
+	newTransport := func(t *testing.T, spec TransportSpec, protocol string) *http.Transport {
+		t.Helper()
+
+		rt, err := spec.newTransport(&transport.Config{
+			TLS: transport.TLSConfig{ServerName: "kubernetes.default.svc", NextProtos: []string{protocol}},
+		})
+		require.NoError(t, err)
+		require.IsType(t, &http.Transport{}, rt)
+		return rt.(*http.Transport)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		rt := newTransport(t, TransportSpec{}, "http/1.1")
+		require.Zero(t, rt.MaxIdleConns)
+		require.Equal(t, 25, rt.MaxIdleConnsPerHost)
+		require.Equal(t, 90*time.Second, rt.IdleConnTimeout)
+		require.Empty(t, rt.TLSNextProto)
+	})
+
+	t.Run("tuned", func(t *testing.T) {
+		rt := newTransport(t, TransportSpec{
+			MaxIdleConns:             pointer.Int(200),
+			MaxIdleConnsPerHost:      pointer.Int(100),
+			IdleConnTimeoutSeconds:   pointer.Int64(300),
+			HTTP2PingIntervalSeconds: pointer.Int64(20),
+		}, "h2")
+		require.Equal(t, 200, rt.MaxIdleConns)
+		require.Equal(t, 100, rt.MaxIdleConnsPerHost)
+		require.Equal(t, 300*time.Second, rt.IdleConnTimeout)
+		require.Contains(t, rt.TLSNextProto, "h2")
+	})
+
+	t.Run("custom transports are not tuned", func(t *testing.T) {
+		_, err := TransportSpec{}.newTransport(&transport.Config{Transport: http.DefaultTransport})
+		require.EqualError(t, err, "a custom transport cannot be tuned")
+	})
+
+	t.Run("transports without TLS settings are rejected", func(t *testing.T) {
+		_, err := TransportSpec{}.newTransport(&transport.Config{})
+		require.EqualError(t, err, "transport config has no TLS settings")
+	})
+}
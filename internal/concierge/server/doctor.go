@@ -0,0 +1,137 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	authenticationv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	"go.pinniped.dev/internal/config/concierge"
+	"go.pinniped.dev/internal/doctor"
+	"go.pinniped.dev/internal/downward"
+	"go.pinniped.dev/internal/groupsuffix"
+	"go.pinniped.dev/internal/kubeclient"
+)
+
+// runDoctor checks whether the Concierge can run with its configuration in its cluster, and writes a readiness report
+// to stdout. It returns an error when the Concierge is not ready, so that the exit code can be checked.
+func (a *App) runDoctor(ctx context.Context, stdout io.Writer) error {
+	report := doctor.NewReport("pinniped-concierge")
+	doctorChecks(ctx, report, a.configPath, a.downwardAPIPath)
+
+	if err := report.Write(stdout); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	if !report.Ready {
+		return errors.New("not ready, see the failed checks in the report")
+	}
+	return nil
+}
+
+func doctorChecks(ctx context.Context, report *doctor.Report, configPath, downwardAPIPath string) {
+	cfg, err := concierge.ReloadFromPath(configPath)
+	report.Add(doctor.FromError("configuration", err, "loaded "+configPath))
+
+	podInfo, err := downward.Load(downwardAPIPath)
+	report.Add(doctor.FromError("pod metadata", err, "loaded "+downwardAPIPath))
+
+	if cfg == nil || podInfo == nil {
+		return // the remaining checks depend on the configuration and the namespace
+	}
+
+	targetClusterConfig, err := cfg.TargetCluster.RESTConfig()
+	if cfg.TargetCluster.IsRemote() {
+		report.Add(doctor.FromError("target cluster", err, "loaded "+cfg.TargetCluster.Kubeconfig))
+	}
+	if err != nil {
+		return
+	}
+
+	client, err := kubeclient.New(
+		kubeclient.WithConfig(targetClusterConfig),
+		kubeclient.WithMiddleware(groupsuffix.New(*cfg.APIGroupSuffix)),
+		kubeclient.WithRateLimit(cfg.KubeClient),
+	)
+	report.Add(doctor.FromError("kubernetes client", err, "created"))
+	if err != nil {
+		return
+	}
+
+	authenticationGroup, _ := groupsuffix.Replace(authenticationv1alpha1.GroupName, *cfg.APIGroupSuffix)
+	configGroup, _ := groupsuffix.Replace(configv1alpha1.GroupName, *cfg.APIGroupSuffix)
+	loginGroupData, identityGroupData := groupsuffix.ConciergeAggregatedGroups(*cfg.APIGroupSuffix)
+	namespace := cfg.TargetCluster.NamespaceOrDefault(podInfo.Namespace)
+
+	report.Run(ctx,
+		doctor.APIServer(client.Kubernetes.Discovery()),
+		doctor.APIGroups(client.Kubernetes.Discovery(), authenticationGroup, configGroup),
+		doctor.APIService(client.Aggregation, loginGroupData.APIServiceName()),
+		doctor.APIService(client.Aggregation, identityGroupData.APIServiceName()),
+		doctor.Permissions(client.Kubernetes, conciergePermissions(namespace, authenticationGroup, configGroup)),
+		doctor.ConfigMap(client.Kubernetes, "kube-system", "extension-apiserver-authentication"),
+		// These Secrets are created by the controllers of the Concierge once it runs.
+		doctor.Secret(client.Kubernetes, namespace, cfg.NamesConfig.ServingCertificateSecret, false),
+		doctor.Secret(client.Kubernetes, namespace, cfg.NamesConfig.ImpersonationCACertificateSecret, false),
+		doctor.Secret(client.Kubernetes, namespace, cfg.NamesConfig.ImpersonationSignerSecret, false),
+	)
+	if cfg.NamesConfig.ConfigMap != "" {
+		report.Run(ctx, doctor.ConfigMap(client.Kubernetes, namespace, cfg.NamesConfig.ConfigMap))
+	}
+
+	// The authenticators are cluster-scoped, so they are only checked when they can be listed.
+	jwtAuthenticators, err := client.PinnipedConcierge.AuthenticationV1alpha1().JWTAuthenticators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		report.Add(doctor.FromError("JWTAuthenticators", fmt.Errorf("cannot list JWTAuthenticators: %w", err), ""))
+	} else {
+		for _, jwtAuthenticator := range jwtAuthenticators.Items {
+			report.Run(ctx, doctor.Reachable("JWTAuthenticator "+jwtAuthenticator.Name, jwtAuthenticator.Spec.Issuer, 443))
+		}
+	}
+
+	webhookAuthenticators, err := client.PinnipedConcierge.AuthenticationV1alpha1().WebhookAuthenticators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		report.Add(doctor.FromError("WebhookAuthenticators", fmt.Errorf("cannot list WebhookAuthenticators: %w", err), ""))
+	} else {
+		for _, webhookAuthenticator := range webhookAuthenticators.Items {
+			report.Run(ctx, doctor.Reachable("WebhookAuthenticator "+webhookAuthenticator.Name, webhookAuthenticator.Spec.Endpoint, 443))
+		}
+	}
+}
+
+// conciergePermissions returns the permissions which the Concierge needs, which are granted by the RBAC resources of
+// its deployment.
+func conciergePermissions(namespace, authenticationGroup, configGroup string) []authorizationv1.ResourceAttributes {
+	var permissions []authorizationv1.ResourceAttributes
+	for _, rule := range [][]authorizationv1.ResourceAttributes{
+		doctor.Rule("", "", "namespaces", "get", "list", "watch"),
+		doctor.Rule("", "apiregistration.k8s.io", "apiservices", "get", "list", "update", "watch"),
+		doctor.Rule("", "", "nodes", "list"),
+		doctor.Rule("", configGroup, "credentialissuers", "get", "list", "watch", "create"),
+		doctor.Rule("", configGroup, "credentialissuers/status", "update"),
+		doctor.Rule("", authenticationGroup, "jwtauthenticators", "get", "list", "watch"),
+		doctor.Rule("", authenticationGroup, "webhookauthenticators", "get", "list", "watch"),
+		doctor.Rule("", "events.k8s.io", "events", "create"),
+		doctor.Rule("", "authentication.k8s.io", "tokenreviews", "create"),
+		doctor.Rule("", "authorization.k8s.io", "subjectaccessreviews", "create"),
+		doctor.Rule(namespace, "", "secrets", "create", "get", "list", "update", "watch", "delete"),
+		doctor.Rule(namespace, "", "services", "create", "get", "list", "update", "watch", "delete"),
+		doctor.Rule(namespace, "", "pods", "get", "list", "watch", "delete"),
+		doctor.Rule(namespace, "", "pods/exec", "create"),
+		doctor.Rule(namespace, "", "configmaps", "get", "list", "watch"),
+		doctor.Rule(namespace, "apps", "deployments", "create", "get", "list", "update", "watch", "delete"),
+		doctor.Rule(namespace, "apps", "replicasets", "get"),
+		doctor.Rule(namespace, "coordination.k8s.io", "leases", "create", "get", "update"),
+		doctor.Rule("kube-system", "", "pods", "get", "list", "watch"),
+	} {
+		permissions = append(permissions, rule...)
+	}
+	return permissions
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package server is the command line entry point for pinniped-concierge.
@@ -6,8 +6,11 @@ package server
 
 import (
 	"context"
+	"crypto"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"time"
 
@@ -21,23 +24,31 @@ import (
 	genericoptions "k8s.io/apiserver/pkg/server/options"
 	"k8s.io/client-go/pkg/version"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
 
 	conciergeopenapi "go.pinniped.dev/generated/latest/client/concierge/openapi"
+	"go.pinniped.dev/internal/certauthority"
 	"go.pinniped.dev/internal/certauthority/dynamiccertauthority"
 	"go.pinniped.dev/internal/concierge/apiserver"
 	conciergescheme "go.pinniped.dev/internal/concierge/scheme"
 	"go.pinniped.dev/internal/config/concierge"
+	"go.pinniped.dev/internal/configreload"
 	"go.pinniped.dev/internal/controller/authenticator/authncache"
 	"go.pinniped.dev/internal/controllerinit"
 	"go.pinniped.dev/internal/controllermanager"
+	"go.pinniped.dev/internal/crdconversion"
+	"go.pinniped.dev/internal/crypto/fips"
 	"go.pinniped.dev/internal/crypto/ptls"
 	"go.pinniped.dev/internal/downward"
 	"go.pinniped.dev/internal/dynamiccert"
 	"go.pinniped.dev/internal/here"
 	"go.pinniped.dev/internal/issuer"
 	"go.pinniped.dev/internal/kubeclient"
+	"go.pinniped.dev/internal/metricsendpoint"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/profiling"
 	"go.pinniped.dev/internal/registry/credentialrequest"
+	"go.pinniped.dev/internal/tokenreviewwebhook"
 )
 
 // App is an object that represents the pinniped-concierge application.
@@ -73,6 +84,20 @@ func (a *App) addServerCommand(ctx context.Context, args []string, stdout, stder
 		Args: cobra.NoArgs,
 	}
 
+	cmd.AddCommand(&cobra.Command{
+		Use:   "doctor",
+		Short: "Check the installation and print a readiness report",
+		Long: here.Doc(`
+			doctor checks whether pinniped-concierge can run with its configuration
+			in its cluster, e.g. whether its RBAC permissions, APIs and Secrets are
+			in place and its authenticators can be reached, and prints a readiness
+			report. It exits with an error when a check failed.`),
+		RunE:         func(cmd *cobra.Command, args []string) error { return a.runDoctor(ctx, cmd.OutOrStdout()) },
+		Args:         cobra.NoArgs,
+		SilenceUsage: true, // the report explains what failed
+	})
+	cmd.CompletionOptions.DisableDefaultCmd = true
+
 	cmd.SetArgs(args)
 	cmd.SetOut(stdout)
 	cmd.SetErr(stderr)
@@ -83,7 +108,7 @@ func (a *App) addServerCommand(ctx context.Context, args []string, stdout, stder
 
 // Define the app's commandline flags.
 func addCommandlineFlagsToCommand(cmd *cobra.Command, app *App) {
-	cmd.Flags().StringVarP(
+	cmd.PersistentFlags().StringVarP(
 		&app.configPath,
 		"config",
 		"c",
@@ -91,7 +116,7 @@ func addCommandlineFlagsToCommand(cmd *cobra.Command, app *App) {
 		"path to configuration file",
 	)
 
-	cmd.Flags().StringVar(
+	cmd.PersistentFlags().StringVar(
 		&app.downwardAPIPath,
 		"downward-api-path",
 		"/etc/podinfo",
@@ -107,6 +132,9 @@ func (a *App) runServer(ctx context.Context) error {
 		return fmt.Errorf("could not load config: %w", err)
 	}
 
+	// The latency buckets apply to the metrics which are registered afterwards.
+	cfg.Metrics.ConfigureLatencyBuckets()
+
 	// Discover in which namespace we are installed.
 	podInfo, err := downward.Load(a.downwardAPIPath)
 	if err != nil {
@@ -130,6 +158,40 @@ func (a *App) runServer(ctx context.Context) error {
 	// This cert provider will be used to provide the impersonation proxy signing key to the
 	// cert issuer used to issue certs to Pinniped clients wishing to login.
 	impersonationProxySigningCertProvider := dynamiccert.NewCA("impersonation-proxy-signing-cert")
+	impersonationProxySigningCAOpts := []certauthority.Option{certauthority.WithKeyAlgorithm(cfg.Certificates.KeyAlgorithm)}
+
+	// When the impersonation proxy signing key is held by an external signer plugin, the provider
+	// only provides the CA cert, and the cert issuer signs with the plugin.
+	var impersonationProxySigner crypto.Signer
+	if cfg.ExternalSigners.ImpersonationSigner != nil {
+		signer, err := cfg.ExternalSigners.ImpersonationSigner.NewSigner(ctx)
+		if err != nil {
+			return fmt.Errorf("could not connect to the external impersonation signer: %w", err)
+		}
+		defer func() { _ = signer.Close() }()
+
+		impersonationProxySigner = signer
+		impersonationProxySigningCertProvider = dynamiccert.NewCAWithSigner("impersonation-proxy-signing-cert", signer)
+		impersonationProxySigningCAOpts = append(impersonationProxySigningCAOpts, certauthority.WithSigner(signer))
+	}
+
+	// The TLS profile of the config applies to the aggregated API server, whose only client is the Kube API server,
+	// and to the impersonation proxy, which any client could connect to. Each starts from its own defaults.
+	aggregatedAPIServerTLSConfigFunc, err := cfg.TLS.ConfigFunc(ptls.Secure)
+	if err != nil {
+		return fmt.Errorf("could not configure TLS: %w", err)
+	}
+	impersonationProxyTLSConfigFunc, err := cfg.TLS.ConfigFunc(ptls.Default)
+	if err != nil {
+		return fmt.Errorf("could not configure TLS: %w", err)
+	}
+
+	if err := fips.Check(cfg.EnforceFIPS, map[string]*tls.Config{
+		"aggregated API server": aggregatedAPIServerTLSConfigFunc(nil),
+		"impersonation proxy":   impersonationProxyTLSConfigFunc(nil),
+	}); err != nil {
+		return err
+	}
 
 	// Get the "real" name of the login concierge API group (i.e., the API group name with the
 	// injected suffix).
@@ -139,18 +201,27 @@ func (a *App) runServer(ctx context.Context) error {
 	// post start hook of the aggregated API server.
 	buildControllers, err := controllermanager.PrepareControllers(
 		&controllermanager.Config{
-			ServerInstallationInfo:           podInfo,
-			APIGroupSuffix:                   *cfg.APIGroupSuffix,
-			NamesConfig:                      &cfg.NamesConfig,
-			Labels:                           cfg.Labels,
-			KubeCertAgentConfig:              &cfg.KubeCertAgentConfig,
-			DiscoveryURLOverride:             cfg.DiscoveryInfo.URL,
-			DynamicServingCertProvider:       dynamicServingCertProvider,
-			DynamicSigningCertProvider:       dynamicSigningCertProvider,
-			ImpersonationSigningCertProvider: impersonationProxySigningCertProvider,
-			ServingCertDuration:              time.Duration(*cfg.APIConfig.ServingCertificateConfig.DurationSeconds) * time.Second,
-			ServingCertRenewBefore:           time.Duration(*cfg.APIConfig.ServingCertificateConfig.RenewBeforeSeconds) * time.Second,
-			AuthenticatorCache:               authenticators,
+			ServerInstallationInfo:                 podInfo,
+			APIGroupSuffix:                         *cfg.APIGroupSuffix,
+			NamesConfig:                            &cfg.NamesConfig,
+			Labels:                                 cfg.Labels,
+			Log:                                    cfg.Log,
+			ControllerTuning:                       cfg.Controllers,
+			KubeClientRateLimit:                    cfg.KubeClient,
+			KubeCertAgentConfig:                    &cfg.KubeCertAgentConfig,
+			DiscoveryURLOverride:                   cfg.DiscoveryInfo.URL,
+			DynamicServingCertProvider:             dynamicServingCertProvider,
+			DynamicSigningCertProvider:             dynamicSigningCertProvider,
+			ImpersonationSigningCertProvider:       impersonationProxySigningCertProvider,
+			ImpersonationSigner:                    impersonationProxySigner,
+			ImpersonationProxyTLSConfigFunc:        impersonationProxyTLSConfigFunc,
+			ImpersonationProxyTransport:            cfg.ImpersonationProxyTransport,
+			ImpersonationProxyPrivilegedIdentities: cfg.ImpersonationProxyPrivilegedIdentities,
+			ServingCertDuration:                    time.Duration(*cfg.APIConfig.ServingCertificateConfig.DurationSeconds) * time.Second,
+			ServingCertRenewBefore:                 time.Duration(*cfg.APIConfig.ServingCertificateConfig.RenewBeforeSeconds) * time.Second,
+			Certificates:                           &cfg.Certificates,
+			AuthenticatorCache:                     authenticators,
+			TargetCluster:                          &cfg.TargetCluster,
 			// This port should be safe to cast because the config reader already validated it.
 			ImpersonationProxyServerPort: int(*cfg.ImpersonationProxyServerPort),
 		},
@@ -160,10 +231,40 @@ func (a *App) runServer(ctx context.Context) error {
 	}
 
 	certIssuer := issuer.ClientCertIssuers{
-		dynamiccertauthority.New(dynamicSigningCertProvider),            // attempt to use the real Kube CA if possible
-		dynamiccertauthority.New(impersonationProxySigningCertProvider), // fallback to our internal CA if we need to
+		dynamiccertauthority.New(dynamicSigningCertProvider, certauthority.WithKeyAlgorithm(cfg.Certificates.KeyAlgorithm)), // attempt to use the real Kube CA if possible
+		dynamiccertauthority.New(impersonationProxySigningCertProvider, impersonationProxySigningCAOpts...),                 // fallback to our internal CA if we need to
+	}
+
+	// The conversion webhook of the CustomResourceDefinitions is served by the aggregated API server.
+	converters, err := crdconversion.NewConverters(*cfg.APIGroupSuffix)
+	if err != nil {
+		return fmt.Errorf("could not configure conversion webhook: %w", err)
 	}
 
+	// The TokenReview webhook is optional, because most clusters authenticate with the TokenCredentialRequest API
+	// or the impersonation proxy instead.
+	var tokenReviewWebhook http.Handler
+	if cfg.TokenReviewWebhook.Enabled {
+		tokenReviewWebhook = tokenreviewwebhook.NewHandler(authenticators)
+	}
+
+	// When the Concierge runs outside of its target cluster, all of its clients for the target cluster use the
+	// kubeconfig of the target cluster instead of the in-cluster config.
+	targetClusterConfig, err := cfg.TargetCluster.RESTConfig()
+	if err != nil {
+		return err
+	}
+
+	// Every pod serves TokenCredentialRequests, so their Events are recorded with a client which is not subject to
+	// the leader election of the controllers.
+	eventsClient, err := kubeclient.New(kubeclient.WithConfig(targetClusterConfig), kubeclient.WithRateLimit(cfg.KubeClient))
+	if err != nil {
+		return fmt.Errorf("could not create client for events: %w", err)
+	}
+	eventBroadcaster := events.NewEventBroadcasterAdapter(eventsClient.Kubernetes)
+	eventBroadcaster.StartRecordingToSink(ctx.Done())
+	defer eventBroadcaster.Shutdown()
+
 	// Get the aggregated API server config.
 	aggregatedAPIServerConfig, err := getAggregatedAPIServerConfig(
 		dynamicServingCertProvider,
@@ -172,9 +273,18 @@ func (a *App) runServer(ctx context.Context) error {
 		buildControllers,
 		*cfg.APIGroupSuffix,
 		*cfg.AggregatedAPIServerPort,
+		aggregatedAPIServerTLSConfigFunc,
 		scheme,
 		loginGV,
 		identityGV,
+		crdconversion.NewHandler(converters),
+		tokenReviewWebhook,
+		eventBroadcaster.NewRecorder("pinniped-concierge"),
+		cfg.ImpersonationProxyPrivilegedIdentities.Groups(),
+		cfg.Profiling,
+		cfg.Metrics,
+		cfg.TargetCluster.Kubeconfig,
+		targetClusterConfig,
 	)
 	if err != nil {
 		return fmt.Errorf("could not configure aggregated API server: %w", err)
@@ -186,6 +296,27 @@ func (a *App) runServer(ctx context.Context) error {
 		return fmt.Errorf("could not create aggregated API server: %w", err)
 	}
 
+	// Warn about certs which are about to expire, e.g. because a controller keeps failing to rotate them.
+	go dynamiccert.RunExpiryWarnings(ctx, dynamiccert.ExpiryWarningInterval)
+
+	if err := cfg.Profiling.ServeLoopback(ctx); err != nil {
+		return err
+	}
+
+	if err := cfg.Metrics.ServeCleartext(ctx); err != nil {
+		return err
+	}
+
+	// Apply the settings which can be changed while running when the config is reloaded, i.e. the log levels.
+	// The TLS settings of the aggregated API server and of the impersonation proxy are only applied by restarting the pods.
+	go configreload.Run(ctx, a.configPath, configreload.PollInterval, func() error {
+		reloaded, err := concierge.ReloadFromPath(a.configPath)
+		if err != nil {
+			return err
+		}
+		return plog.SetLogLevelGlobally(reloaded.Log.Level, reloaded.Log.Components)
+	})
+
 	// Run the server. Its post-start hook will start the controllers.
 	return server.GenericAPIServer.PrepareRun().Run(ctx.Done())
 }
@@ -198,8 +329,17 @@ func getAggregatedAPIServerConfig(
 	buildControllers controllerinit.RunnerBuilder,
 	apiGroupSuffix string,
 	aggregatedAPIServerPort int64,
+	tlsConfigFunc ptls.ConfigFunc,
 	scheme *runtime.Scheme,
 	loginConciergeGroupVersion, identityConciergeGroupVersion schema.GroupVersion,
+	conversionWebhook http.Handler,
+	tokenReviewWebhook http.Handler,
+	eventRecorder events.EventRecorder,
+	privilegedGroups []string,
+	profilingSpec profiling.Spec,
+	metricsSpec metricsendpoint.Spec,
+	targetClusterKubeconfig string,
+	targetClusterConfig *rest.Config,
 ) (*apiserver.Config, error) {
 	codecs := serializer.NewCodecFactory(scheme)
 
@@ -216,13 +356,35 @@ func getAggregatedAPIServerConfig(
 	// This port is configurable. It should be safe to cast because the config reader already validated it.
 	recommendedOptions.SecureServing.BindPort = int(aggregatedAPIServerPort)
 
+	// The Kube API server does not authenticate when it calls the conversion webhook, so allow anonymous requests.
+	if conversionWebhook != nil {
+		recommendedOptions.Authorization.WithAlwaysAllowPaths(crdconversion.Path)
+	}
+
+	// The TokenReview webhook does not require authorization, just like the TokenCredentialRequest API.
+	if tokenReviewWebhook != nil {
+		recommendedOptions.Authorization.WithAlwaysAllowPaths(tokenreviewwebhook.PathPrefix + "*")
+	}
+
 	// secure TLS for connections coming from and going to the Kube API server
 	// this is best effort because not all options provide the right hooks to override TLS config
-	// since our only client is the Kube API server, this uses the most secure TLS config
-	if err := ptls.SecureRecommendedOptions(recommendedOptions, kubeclient.Secure); err != nil {
+	// since our only client is the Kube API server, this uses the most secure TLS config unless the TLS profile says otherwise
+	if err := ptls.RecommendedOptions(recommendedOptions, tlsConfigFunc, kubeclient.SecureFor(targetClusterConfig)); err != nil {
 		return nil, fmt.Errorf("failed to secure recommended options: %w", err)
 	}
 
+	// The delegated authentication and authorization use the kubeconfig of the target cluster when the Concierge runs
+	// outside of it. This must be set after securing the options, which expects the in-cluster config, but its TLS
+	// config still applies since the RestConfigFunc above uses the same kubeconfig.
+	recommendedOptions.Authentication.RemoteKubeConfigFile = targetClusterKubeconfig
+	recommendedOptions.Authorization.RemoteKubeConfigFile = targetClusterKubeconfig
+
+	// The pprof endpoints are only served when they are enabled by the config.
+	profilingSpec.ApplyTo(recommendedOptions)
+
+	// The metrics endpoint is served to unauthenticated clients only when the config allows it.
+	metricsSpec.ApplyTo(recommendedOptions)
+
 	serverConfig := genericapiserver.NewRecommendedConfig(codecs)
 	// Add the generated openapi docs to the server config. Publishing openapi docs allows
 	// `kubectl explain` to work for the Concierge's aggregated API resources.
@@ -251,6 +413,10 @@ func getAggregatedAPIServerConfig(
 			NegotiatedSerializer:          codecs,
 			LoginConciergeGroupVersion:    loginConciergeGroupVersion,
 			IdentityConciergeGroupVersion: identityConciergeGroupVersion,
+			ConversionWebhook:             conversionWebhook,
+			TokenReviewWebhook:            tokenReviewWebhook,
+			EventRecorder:                 eventRecorder,
+			PrivilegedGroups:              privilegedGroups,
 		},
 	}
 	return apiServerConfig, nil
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package server
@@ -21,11 +21,18 @@ authenticating to the Kubernetes API.
 
 Usage:
   pinniped-concierge [flags]
+  pinniped-concierge [command]
+
+Available Commands:
+  doctor      Check the installation and print a readiness report
+  help        Help about any command
 
 Flags:
   -c, --config string              path to configuration file (default "pinniped.yaml")
       --downward-api-path string   path to Downward API volume mount (default "/etc/podinfo")
   -h, --help                       help for pinniped-concierge
+
+Use "pinniped-concierge [command] --help" for more information about a command.
 `
 
 func TestCommand(t *testing.T) {
@@ -65,6 +72,33 @@ func TestCommand(t *testing.T) {
 			},
 			wantErr: `unknown command "tuna" for "pinniped-concierge"`,
 		},
+		{
+			name: "DoctorReportsFailedChecks",
+			args: []string{
+				"doctor",
+				"--config", "does/not/exist/config.yaml",
+				"--downward-api-path", "does/not/exist/podinfo",
+			},
+			wantErr: "not ready, see the failed checks in the report",
+			wantStdout: `
+{
+  "component": "pinniped-concierge",
+  "ready": false,
+  "results": [
+    {
+      "check": "configuration",
+      "status": "Fail",
+      "message": "read file: open does/not/exist/config.yaml: no such file or directory"
+    },
+    {
+      "check": "pod metadata",
+      "status": "Fail",
+      "message": "could not load namespace: open does/not/exist/podinfo/namespace: no such file or directory"
+    }
+  ]
+}
+`,
+		},
 	}
 	for _, test := range tests {
 		test := test
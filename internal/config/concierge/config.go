@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package concierge contains functionality to load/store Config's from/to
@@ -11,10 +11,12 @@ import (
 	"os"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/yaml"
 
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/groupsuffix"
 	"go.pinniped.dev/internal/plog"
 )
@@ -43,6 +45,21 @@ const (
 // This function will decode that base64-encoded data to PEM bytes to be stored
 // in the Config.
 func FromPath(ctx context.Context, path string) (*Config, error) {
+	config, err := ReloadFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := plog.ValidateAndSetLogLevelAndFormatGlobally(ctx, config.Log); err != nil {
+		return nil, fmt.Errorf("validate log level: %w", err)
+	}
+
+	return config, nil
+}
+
+// ReloadFromPath loads a Config like FromPath, but without applying its log settings globally, so that it can be
+// used to reload the Config while running.
+func ReloadFromPath(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
@@ -58,11 +75,24 @@ func FromPath(ctx context.Context, path string) (*Config, error) {
 	maybeSetImpersonationProxyServerPortDefaults(&config.ImpersonationProxyServerPort)
 	maybeSetAPIGroupSuffixDefault(&config.APIGroupSuffix)
 	maybeSetKubeCertAgentDefaults(&config.KubeCertAgentConfig)
+	maybeSetCertificatesDefaults(&config.Certificates)
 
 	if err := validateAPI(&config.APIConfig); err != nil {
 		return nil, fmt.Errorf("validate api: %w", err)
 	}
 
+	if err := validateCertificates(&config.Certificates); err != nil {
+		return nil, fmt.Errorf("validate certificates: %w", err)
+	}
+
+	if err := validateExternalSigners(&config.ExternalSigners); err != nil {
+		return nil, fmt.Errorf("validate externalSigners: %w", err)
+	}
+
+	if err := config.TLS.Validate(); err != nil {
+		return nil, fmt.Errorf("validate tls: %w", err)
+	}
+
 	if err := validateAPIGroupSuffix(*config.APIGroupSuffix); err != nil {
 		return nil, fmt.Errorf("validate apiGroupSuffix: %w", err)
 	}
@@ -75,15 +105,43 @@ func FromPath(ctx context.Context, path string) (*Config, error) {
 		return nil, fmt.Errorf("validate impersonationProxyServerPort: %w", err)
 	}
 
+	if err := config.ImpersonationProxyTransport.Validate(); err != nil {
+		return nil, fmt.Errorf("validate impersonationProxyTransport: %w", err)
+	}
+
+	if err := config.ImpersonationProxyPrivilegedIdentities.Validate(); err != nil {
+		return nil, fmt.Errorf("validate impersonationProxyPrivilegedIdentities: %w", err)
+	}
+
 	if err := validateNames(&config.NamesConfig); err != nil {
 		return nil, fmt.Errorf("validate names: %w", err)
 	}
 
 	plog.MaybeSetDeprecatedLogLevel(config.LogLevel, &config.Log)
-	if err := plog.ValidateAndSetLogLevelAndFormatGlobally(ctx, config.Log); err != nil {
+	if err := plog.ValidateLogSpec(config.Log); err != nil {
 		return nil, fmt.Errorf("validate log level: %w", err)
 	}
 
+	if err := controllerlib.ValidateTuning(config.Controllers); err != nil {
+		return nil, fmt.Errorf("validate controllers: %w", err)
+	}
+
+	if err := config.KubeClient.Validate(); err != nil {
+		return nil, fmt.Errorf("validate kubeClient: %w", err)
+	}
+
+	if err := config.Profiling.Validate(); err != nil {
+		return nil, fmt.Errorf("validate profiling: %w", err)
+	}
+
+	if err := config.Metrics.Validate(); err != nil {
+		return nil, fmt.Errorf("validate metrics: %w", err)
+	}
+
+	if err := validateTargetCluster(&config.TargetCluster); err != nil {
+		return nil, fmt.Errorf("validate targetCluster: %w", err)
+	}
+
 	if config.Labels == nil {
 		config.Labels = make(map[string]string)
 	}
@@ -101,6 +159,16 @@ func maybeSetAPIDefaults(apiConfig *APIConfigSpec) {
 	}
 }
 
+func maybeSetCertificatesDefaults(certificates *CertificatesSpec) {
+	if certificates.ImpersonationSigner.DurationSeconds == nil {
+		certificates.ImpersonationSigner.DurationSeconds = pointer.Int64(aboutAYear)
+	}
+
+	if certificates.ImpersonationSigner.RenewBeforeSeconds == nil {
+		certificates.ImpersonationSigner.RenewBeforeSeconds = pointer.Int64(about9Months)
+	}
+}
+
 func maybeSetAPIGroupSuffixDefault(apiGroupSuffix **string) {
 	if *apiGroupSuffix == nil {
 		*apiGroupSuffix = pointer.String(groupsuffix.PinnipedDefaultSuffix)
@@ -179,6 +247,46 @@ func validateAPI(apiConfig *APIConfigSpec) error {
 	return nil
 }
 
+func validateCertificates(certificates *CertificatesSpec) error {
+	if err := certificates.KeyAlgorithm.Validate(); err != nil {
+		return fmt.Errorf("keyAlgorithm: %w", err)
+	}
+
+	signer := certificates.ImpersonationSigner
+	if *signer.DurationSeconds < *signer.RenewBeforeSeconds {
+		return constable.Error("impersonationSigner.durationSeconds cannot be smaller than impersonationSigner.renewBeforeSeconds")
+	}
+
+	if *signer.RenewBeforeSeconds <= 0 {
+		return constable.Error("impersonationSigner.renewBeforeSeconds must be positive")
+	}
+
+	return nil
+}
+
+func validateExternalSigners(externalSigners *ExternalSignersSpec) error {
+	if externalSigners.ImpersonationSigner != nil {
+		if err := externalSigners.ImpersonationSigner.Validate(); err != nil {
+			return fmt.Errorf("impersonationSigner: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func validateTargetCluster(targetCluster *TargetClusterSpec) error {
+	if targetCluster.Namespace == "" {
+		return nil
+	}
+	if targetCluster.Kubeconfig == "" {
+		return constable.Error("namespace may only be set along with kubeconfig")
+	}
+	if errs := validation.IsDNS1123Label(targetCluster.Namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", targetCluster.Namespace, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
 func validateAPIGroupSuffix(apiGroupSuffix string) error {
 	return groupsuffix.Validate(apiGroupSuffix)
 }
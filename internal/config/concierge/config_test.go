@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package concierge
@@ -11,7 +11,13 @@ import (
 	"github.com/stretchr/testify/require"
 	"k8s.io/utils/pointer"
 
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/concierge/impersonator"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/externalsigner"
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/kubeclient"
 	"go.pinniped.dev/internal/plog"
 )
 
@@ -47,6 +53,7 @@ func TestFromPath(t *testing.T) {
 				  impersonationSignerSecret: impersonationSignerSecret-value
 				  impersonationSignerSecret: impersonationSignerSecret-value
 				  agentServiceAccount: agentServiceAccount-value
+				  configMap: configMap-value
 				  extraName: extraName-value
 				labels:
 				  myLabelKey1: myLabelValue1
@@ -56,6 +63,21 @@ func TestFromPath(t *testing.T) {
 				  image: kube-cert-agent-image
 				  imagePullSecrets: [kube-cert-agent-image-pull-secret]
 				logLevel: debug
+				externalSigners:
+				  impersonationSigner:
+				    endpoint: unix:///var/run/pinniped-signer/socket
+				    keyID: some-key
+				    timeoutSeconds: 5
+				tls:
+				  minVersion: TLS1.2
+				  cipherSuites: [TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384]
+				  curvePreferences: [CurveP384]
+				enforceFIPS: true
+				tokenReviewWebhook:
+				  enabled: true
+				targetCluster:
+				  kubeconfig: /etc/target-cluster/kubeconfig
+				  namespace: pinniped-concierge
 			`),
 			wantConfig: &Config{
 				DiscoveryInfo: DiscoveryInfoSpec{
@@ -80,6 +102,7 @@ func TestFromPath(t *testing.T) {
 					ImpersonationCACertificateSecret:  "impersonationCACertificateSecret-value",
 					ImpersonationSignerSecret:         "impersonationSignerSecret-value",
 					AgentServiceAccount:               "agentServiceAccount-value",
+					ConfigMap:                         "configMap-value",
 				},
 				Labels: map[string]string{
 					"myLabelKey1": "myLabelValue1",
@@ -94,6 +117,32 @@ func TestFromPath(t *testing.T) {
 				Log: plog.LogSpec{
 					Level: plog.LevelDebug,
 				},
+				Certificates: CertificatesSpec{
+					ImpersonationSigner: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(60 * 60 * 24 * 30 * 9), // about 9 months
+					},
+				},
+				ExternalSigners: ExternalSignersSpec{
+					ImpersonationSigner: &externalsigner.Spec{
+						Endpoint:       "unix:///var/run/pinniped-signer/socket",
+						KeyID:          "some-key",
+						TimeoutSeconds: pointer.Int64(5),
+					},
+				},
+				TLS: ptls.ProfileSpec{
+					MinVersion:       "TLS1.2",
+					CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"},
+					CurvePreferences: []string{"CurveP384"},
+				},
+				EnforceFIPS: true,
+				TokenReviewWebhook: TokenReviewWebhookSpec{
+					Enabled: true,
+				},
+				TargetCluster: TargetClusterSpec{
+					Kubeconfig: "/etc/target-cluster/kubeconfig",
+					Namespace:  "pinniped-concierge",
+				},
 			},
 		},
 		{
@@ -132,6 +181,28 @@ func TestFromPath(t *testing.T) {
 				log:
 				  level: all
 				  format: json
+				controllers:
+				  some-controller:
+				    rateLimiter:
+				      baseDelayMilliseconds: 100
+				      maxDelaySeconds: 60
+				    resyncPeriodSeconds: 600
+				kubeClient:
+				  qps: 25.5
+				  burst: 50
+				certificates:
+				  keyAlgorithm: RSA-4096
+				  impersonationSigner:
+				    durationSeconds: 7200
+				    renewBeforeSeconds: 3600
+				impersonationProxyTransport:
+				  maxIdleConns: 200
+				  maxIdleConnsPerHost: 100
+				  idleConnTimeoutSeconds: 300
+				  http2PingIntervalSeconds: 20
+				impersonationProxyPrivilegedIdentities:
+				  privilegedGroups: [system:masters, cluster-admins]
+				  allowImpersonatingSystemIdentities: true
 			`),
 			wantConfig: &Config{
 				DiscoveryInfo: DiscoveryInfoSpec{
@@ -170,6 +241,36 @@ func TestFromPath(t *testing.T) {
 					Level:  plog.LevelAll,
 					Format: plog.FormatJSON,
 				},
+				Controllers: map[string]controllerlib.TuningSpec{
+					"some-controller": {
+						RateLimiter: &controllerlib.RateLimiterSpec{
+							BaseDelayMilliseconds: pointer.Int64(100),
+							MaxDelaySeconds:       pointer.Int64(60),
+						},
+						ResyncPeriodSeconds: pointer.Int64(600),
+					},
+				},
+				KubeClient: kubeclient.RateLimitSpec{
+					QPS:   pointer.Float32(25.5),
+					Burst: pointer.Int(50),
+				},
+				ImpersonationProxyTransport: impersonator.TransportSpec{
+					MaxIdleConns:             pointer.Int(200),
+					MaxIdleConnsPerHost:      pointer.Int(100),
+					IdleConnTimeoutSeconds:   pointer.Int64(300),
+					HTTP2PingIntervalSeconds: pointer.Int64(20),
+				},
+				ImpersonationProxyPrivilegedIdentities: impersonator.PrivilegedIdentitiesSpec{
+					PrivilegedGroups:                   []string{"system:masters", "cluster-admins"},
+					AllowImpersonatingSystemIdentities: true,
+				},
+				Certificates: CertificatesSpec{
+					KeyAlgorithm: certauthority.KeyAlgorithmRSA4096,
+					ImpersonationSigner: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(7200),
+						RenewBeforeSeconds: pointer.Int64(3600),
+					},
+				},
 			},
 		},
 		{
@@ -248,6 +349,12 @@ func TestFromPath(t *testing.T) {
 					Level:  plog.LevelDebug,
 					Format: plog.FormatJSON,
 				},
+				Certificates: CertificatesSpec{
+					ImpersonationSigner: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(60 * 60 * 24 * 30 * 9), // about 9 months
+					},
+				},
 			},
 		},
 		{
@@ -268,7 +375,259 @@ func TestFromPath(t *testing.T) {
 				  level: all
 				  format: snorlax
 			`),
-			wantError: "decode yaml: error unmarshaling JSON: while decoding JSON: invalid log format, valid choices are the empty string, json and text",
+			wantError: "decode yaml: error unmarshaling JSON: while decoding JSON: invalid log format, valid choices are the empty string, json, kubernetes-json and text",
+		},
+		{
+			name: "invalid controller tuning",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				controllers:
+				  some-controller:
+				    resyncPeriodSeconds: 0
+			`),
+			wantError: `validate controllers: controller "some-controller": resyncPeriodSeconds must be positive`,
+		},
+		{
+			name: "invalid kube client rate limit",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				kubeClient:
+				  qps: 0
+			`),
+			wantError: "validate kubeClient: qps must be positive",
+		},
+		{
+			name: "invalid profiling",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				profiling:
+				  loopbackPort: 6060
+			`),
+			wantError: "validate profiling: loopbackPort requires enabled to be true",
+		},
+		{
+			name: "invalid metrics",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				metrics:
+				  cleartextPort: 80
+			`),
+			wantError: "validate metrics: cleartextPort must be between 1024 and 65535",
+		},
+		{
+			name: "invalid metrics latency buckets",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				metrics:
+				  latencyBuckets: [1, 0.5]
+			`),
+			wantError: "validate metrics: invalid latencyBuckets: latency bucket 1 must be greater than the previous bucket",
+		},
+		{
+			name: "target cluster namespace without kubeconfig",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				targetCluster:
+				  namespace: pinniped-concierge
+			`),
+			wantError: "validate targetCluster: namespace may only be set along with kubeconfig",
+		},
+		{
+			name: "invalid target cluster namespace",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				targetCluster:
+				  kubeconfig: /etc/target-cluster/kubeconfig
+				  namespace: Not_A_Namespace
+			`),
+			wantError: `validate targetCluster: invalid namespace "Not_A_Namespace": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+		{
+			name: "invalid impersonation proxy transport",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				impersonationProxyTransport:
+				  http2PingIntervalSeconds: -1
+			`),
+			wantError: "validate impersonationProxyTransport: http2PingIntervalSeconds must be positive",
+		},
+		{
+			name: "invalid impersonation proxy privileged identities",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				impersonationProxyPrivilegedIdentities:
+				  privilegedGroups: [""]
+			`),
+			wantError: "validate impersonationProxyPrivilegedIdentities: privilegedGroups must not contain empty group names",
+		},
+		{
+			name: "invalid certificates key algorithm",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				certificates:
+				  keyAlgorithm: DSA-1024
+			`),
+			wantError: `validate certificates: keyAlgorithm: unsupported key algorithm "DSA-1024", must be one of ECDSA-P256, ECDSA-P384, RSA-2048 or RSA-4096`,
+		},
+		{
+			name: "invalid certificates impersonation signer lifetime",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				certificates:
+				  impersonationSigner:
+				    durationSeconds: 3600
+			`),
+			wantError: "validate certificates: impersonationSigner.durationSeconds cannot be smaller than impersonationSigner.renewBeforeSeconds",
+		},
+		{
+			name: "invalid external impersonation signer",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				externalSigners:
+				  impersonationSigner:
+				    endpoint: https://kms.example.com
+				    keyID: some-key
+			`),
+			wantError: "validate externalSigners: impersonationSigner: endpoint must be a unix domain socket, e.g. unix:///var/run/pinniped-signer/socket",
+		},
+		{
+			name: "invalid tls profile",
+			yaml: here.Doc(`
+				---
+				names:
+				  servingCertificateSecret: pinniped-concierge-api-tls-serving-certificate
+				  credentialIssuer: pinniped-config
+				  apiService: pinniped-api
+				  impersonationLoadBalancerService: impersonationLoadBalancerService-value
+				  impersonationClusterIPService: impersonationClusterIPService-value
+				  impersonationTLSCertificateSecret: impersonationTLSCertificateSecret-value
+				  impersonationCACertificateSecret: impersonationCACertificateSecret-value
+				  impersonationSignerSecret: impersonationSignerSecret-value
+				  agentServiceAccount: agentServiceAccount-value
+				tls:
+				  minVersion: TLS1.1
+			`),
+			wantError: `validate tls: minVersion "TLS1.1" is not supported, must be TLS1.2 or TLS1.3`,
 		},
 		{
 			name: "When only the required fields are present, causes other fields to be defaulted",
@@ -314,6 +673,12 @@ func TestFromPath(t *testing.T) {
 					NamePrefix: pointer.String("pinniped-kube-cert-agent-"),
 					Image:      pointer.String("debian:latest"),
 				},
+				Certificates: CertificatesSpec{
+					ImpersonationSigner: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(60 * 60 * 24 * 30 * 9), // about 9 months
+					},
+				},
 			},
 		},
 		{
@@ -0,0 +1,38 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package concierge
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// IsRemote returns whether the Concierge runs outside of its target cluster.
+func (s *TargetClusterSpec) IsRemote() bool {
+	return s.Kubeconfig != ""
+}
+
+// RESTConfig returns the client config of the target cluster, or nil when the Concierge runs in its target cluster
+// and should use its in-cluster config.
+func (s *TargetClusterSpec) RESTConfig() (*rest.Config, error) {
+	if !s.IsRemote() {
+		return nil, nil
+	}
+	config, err := clientcmd.BuildConfigFromFlags("", s.Kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not load kubeconfig of target cluster: %w", err)
+	}
+	return config, nil
+}
+
+// NamespaceOrDefault returns the namespace of the Concierge's resources in the target cluster, which defaults to the
+// given namespace of the Concierge's pod.
+func (s *TargetClusterSpec) NamespaceOrDefault(podNamespace string) string {
+	if s.Namespace != "" {
+		return s.Namespace
+	}
+	return podNamespace
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package concierge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/here"
+)
+
+func TestTargetClusterSpec(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(here.Doc(`
+		apiVersion: v1
+		kind: Config
+		clusters:
+		- name: spoke
+		  cluster:
+		    server: https://spoke.example.com
+		contexts:
+		- name: spoke
+		  context:
+		    cluster: spoke
+		    user: concierge
+		current-context: spoke
+		users:
+		- name: concierge
+		  user:
+		    token: some-token
+	`)), 0600))
+
+	t.Run("in cluster", func(t *testing.T) {
+		spec := &TargetClusterSpec{}
+		require.False(t, spec.IsRemote())
+		config, err := spec.RESTConfig()
+		require.NoError(t, err)
+		require.Nil(t, config)
+		require.Equal(t, "pod-namespace", spec.NamespaceOrDefault("pod-namespace"))
+	})
+
+	t.Run("remote with the namespace of the pod", func(t *testing.T) {
+		spec := &TargetClusterSpec{Kubeconfig: kubeconfig}
+		require.True(t, spec.IsRemote())
+		config, err := spec.RESTConfig()
+		require.NoError(t, err)
+		require.Equal(t, "https://spoke.example.com", config.Host)
+		require.Equal(t, "some-token", config.BearerToken)
+		require.Equal(t, "pod-namespace", spec.NamespaceOrDefault("pod-namespace"))
+	})
+
+	t.Run("remote with another namespace", func(t *testing.T) {
+		spec := &TargetClusterSpec{Kubeconfig: kubeconfig, Namespace: "pinniped-concierge"}
+		require.Equal(t, "pinniped-concierge", spec.NamespaceOrDefault("pod-namespace"))
+	})
+
+	t.Run("missing kubeconfig", func(t *testing.T) {
+		spec := &TargetClusterSpec{Kubeconfig: filepath.Join(t.TempDir(), "does-not-exist")}
+		config, err := spec.RESTConfig()
+		require.ErrorContains(t, err, "could not load kubeconfig of target cluster: ")
+		require.Nil(t, config)
+	})
+}
@@ -1,9 +1,19 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package concierge
 
-import "go.pinniped.dev/internal/plog"
+import (
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/concierge/impersonator"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/externalsigner"
+	"go.pinniped.dev/internal/kubeclient"
+	"go.pinniped.dev/internal/metricsendpoint"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/profiling"
+)
 
 // Config contains knobs to setup an instance of the Pinniped Concierge.
 type Config struct {
@@ -18,6 +28,36 @@ type Config struct {
 	// Deprecated: use log.level instead
 	LogLevel *plog.LogLevel `json:"logLevel"`
 	Log      plog.LogSpec   `json:"log"`
+	// Controllers tunes the rate limiters, resync periods and quiet periods of the controllers, keyed by controller name.
+	Controllers map[string]controllerlib.TuningSpec `json:"controllers,omitempty"`
+	// KubeClient configures the client-side rate limiting of the requests of the controllers to the Kubernetes API.
+	KubeClient kubeclient.RateLimitSpec `json:"kubeClient,omitempty"`
+	// Profiling optionally serves the pprof endpoints, e.g. to capture CPU and heap profiles during performance incidents.
+	Profiling profiling.Spec `json:"profiling,omitempty"`
+	// Metrics configures how the Prometheus metrics are served.
+	Metrics metricsendpoint.Spec `json:"metrics,omitempty"`
+	// ImpersonationProxyTransport tunes the connection pools and keepalives of the connections of the impersonation
+	// proxy to the Kubernetes API server.
+	ImpersonationProxyTransport impersonator.TransportSpec `json:"impersonationProxyTransport,omitempty"`
+	// ImpersonationProxyPrivilegedIdentities configures which groups of users of the impersonation proxy are privileged,
+	// and whether nested impersonation of system users and groups is allowed.
+	ImpersonationProxyPrivilegedIdentities impersonator.PrivilegedIdentitiesSpec `json:"impersonationProxyPrivilegedIdentities,omitempty"`
+	// Certificates configures the key algorithm and the validity of the certificates which the Concierge generates.
+	Certificates CertificatesSpec `json:"certificates,omitempty"`
+	// ExternalSigners configures keys of external signer plugins, e.g. keys in an HSM or a cloud KMS, which are used
+	// instead of keys which are stored in Secrets.
+	ExternalSigners ExternalSignersSpec `json:"externalSigners,omitempty"`
+	// TLS configures the TLS settings of the aggregated API server and the impersonation proxy.
+	TLS ptls.ProfileSpec `json:"tls,omitempty"`
+	// EnforceFIPS makes the Concierge refuse to start unless its crypto module runs in FIPS mode and its servers
+	// only serve FIPS-approved TLS settings.
+	EnforceFIPS bool `json:"enforceFIPS,omitempty"`
+	// TokenReviewWebhook optionally serves a TokenReview webhook, so that the Kubernetes API server can authenticate
+	// tokens with the authenticators of the Concierge.
+	TokenReviewWebhook TokenReviewWebhookSpec `json:"tokenReviewWebhook,omitempty"`
+	// TargetCluster optionally runs the Concierge outside of the cluster whose users it authenticates, e.g. in a hub
+	// cluster which serves spoke clusters on which the Concierge cannot be installed.
+	TargetCluster TargetClusterSpec `json:"targetCluster,omitempty"`
 }
 
 // DiscoveryInfoSpec contains configuration knobs specific to
@@ -45,6 +85,10 @@ type NamesConfigSpec struct {
 	ImpersonationCACertificateSecret  string `json:"impersonationCACertificateSecret"`
 	ImpersonationSignerSecret         string `json:"impersonationSignerSecret"`
 	AgentServiceAccount               string `json:"agentServiceAccount"`
+
+	// ConfigMap is the name of the ConfigMap in the Concierge's namespace which holds this configuration. When it is
+	// set, changes to log.level in that ConfigMap are applied while the Concierge is running.
+	ConfigMap string `json:"configMap,omitempty"`
 }
 
 // ServingCertificateConfigSpec contains the configuration knobs for the API's
@@ -65,6 +109,46 @@ type ServingCertificateConfigSpec struct {
 	RenewBeforeSeconds *int64 `json:"renewBeforeSeconds,omitempty"`
 }
 
+// CertificatesSpec configures the CAs and certificates which the Concierge generates for itself.
+type CertificatesSpec struct {
+	// KeyAlgorithm is the algorithm of the private keys of the generated CAs and certificates, i.e. of the API
+	// serving certificate, the impersonation proxy's certificates, and the client certificates which are issued
+	// by the TokenCredentialRequest API. It is one of ECDSA-P256 (the default), ECDSA-P384, RSA-2048 or RSA-4096.
+	// Keys which already exist are replaced only when their certificates are rotated.
+	KeyAlgorithm certauthority.KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+
+	// ImpersonationSigner configures the validity of the CA which signs the client certificates of the
+	// impersonation proxy. By default, it is issued for 1 year and rotated after 9 months. The previous CA remains
+	// trusted until the next rotation, so that rotating it does not break the client certificates which it signed.
+	ImpersonationSigner CertificateLifetimeSpec `json:"impersonationSigner,omitempty"`
+}
+
+// CertificateLifetimeSpec configures the validity of a generated certificate which is rotated before it expires.
+type CertificateLifetimeSpec struct {
+	// DurationSeconds is the validity period, in seconds, of the certificate.
+	DurationSeconds *int64 `json:"durationSeconds,omitempty"`
+
+	// RenewBeforeSeconds is the period of time, in seconds, after the issuance of the certificate at which
+	// it is rotated. This must be less than DurationSeconds.
+	RenewBeforeSeconds *int64 `json:"renewBeforeSeconds,omitempty"`
+}
+
+// ExternalSignersSpec configures which keys of the Concierge are held by external signer plugins.
+type ExternalSignersSpec struct {
+	// ImpersonationSigner is the key of the CA which signs the client certificates of the impersonation proxy.
+	// When it is set, the impersonation signer Secret only holds the CA certificate, which is signed by this key.
+	ImpersonationSigner *externalsigner.Spec `json:"impersonationSigner,omitempty"`
+}
+
+// TokenReviewWebhookSpec configures the TokenReview webhook of the Concierge.
+type TokenReviewWebhookSpec struct {
+	// Enabled serves a TokenReview webhook on the aggregated API server for each JWTAuthenticator and
+	// WebhookAuthenticator, which the Kubernetes API server can call through its
+	// --authentication-token-webhook-config-file. The webhook does not require authentication, just like
+	// the TokenCredentialRequest API.
+	Enabled bool `json:"enabled,omitempty"`
+}
+
 type KubeCertAgentSpec struct {
 	// NamePrefix is the prefix of the name of the kube-cert-agent pods. For example, if this field is
 	// set to "some-prefix-", then the name of the pods will look like "some-prefix-blah". The default
@@ -80,3 +164,13 @@ type KubeCertAgentSpec struct {
 	// ImagePullSecrets on the kube-cert-agent pods.
 	ImagePullSecrets []string
 }
+
+// TargetClusterSpec configures the cluster whose users the Concierge authenticates.
+type TargetClusterSpec struct {
+	// Kubeconfig is the path of a kubeconfig file with the credentials of the Concierge for the target cluster.
+	// When empty, the Concierge runs in the target cluster and uses the credentials of its service account.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Namespace is the namespace of the Concierge's resources in the target cluster. It defaults to the namespace
+	// of the Concierge's pod and may only be set along with Kubeconfig.
+	Namespace string `json:"namespace,omitempty"`
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package supervisor contains functionality to load/store Config's from/to
@@ -7,8 +7,11 @@ package supervisor
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"strings"
 
@@ -16,6 +19,8 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/endpointaddr"
 	"go.pinniped.dev/internal/groupsuffix"
 	"go.pinniped.dev/internal/plog"
 )
@@ -25,17 +30,57 @@ const (
 	NetworkUnix     = "unix"
 	NetworkTCP      = "tcp"
 
+	SessionStorageTypeKubernetes = "kubernetes"
+	SessionStorageTypeRedis      = "redis"
+
+	PasswordLockoutStorageMemory     = "memory"
+	PasswordLockoutStorageKubernetes = "kubernetes"
+
 	// Use 10250 because it happens to be the same port on which the Kubelet listens, so some cluster types
 	// are more permissive with servers that run on this port. For example, GKE private clusters do not
 	// allow traffic from the control plane to most ports, but do allow traffic to port 10250. This allows
 	// the Concierge to work without additional configuration on these types of clusters.
 	aggregatedAPIServerPortDefault = 10250
+
+	passwordLockoutMaxFailedAttemptsDefault      = 10
+	passwordLockoutLockoutDurationSecondsDefault = 10 * 60
+
+	sessionGarbageCollectionIntervalSecondsDefault  = 30
+	sessionGarbageCollectionBatchSizeDefault        = 0 // no limit
+	sessionGarbageCollectionDeletesPerSecondDefault = 0 // no limit
+
+	TracingEndpointPortDefault       = 4317 // the standard port of OTLP gRPC collectors
+	tracingSamplingPercentageDefault = 100
+
+	aggregatedAPIServingCertificateDurationSecondsDefault    = 60 * 60 * 24 * 365    // about a year
+	aggregatedAPIServingCertificateRenewBeforeSecondsDefault = 60 * 60 * 24 * 30 * 9 // about 9 months
+
+	keyRotationPeriodSecondsDefault = 60 * 60 * 24 * 30 // about a month
+
+	// The sum of these defaults is less than the default terminationGracePeriodSeconds of pods, i.e. 30 seconds.
+	ShutdownDrainDelaySecondsDefault = 5
+	ShutdownTimeoutSecondsDefault    = 20
 )
 
 // FromPath loads an Config from a provided local file path, inserts any
 // defaults (from the Config documentation), and verifies that the config is
 // valid (Config documentation).
 func FromPath(ctx context.Context, path string) (*Config, error) {
+	config, err := ReloadFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := plog.ValidateAndSetLogLevelAndFormatGlobally(ctx, config.Log); err != nil {
+		return nil, fmt.Errorf("validate log level: %w", err)
+	}
+
+	return config, nil
+}
+
+// ReloadFromPath loads a Config like FromPath, but without applying its log settings globally, so that it can be
+// used to reload the Config while running.
+func ReloadFromPath(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
@@ -66,8 +111,20 @@ func FromPath(ctx context.Context, path string) (*Config, error) {
 		return nil, fmt.Errorf("validate names: %w", err)
 	}
 
+	maybeSetCertificatesDefaults(&config.Certificates)
+
+	if err := validateCertificates(config.Certificates); err != nil {
+		return nil, fmt.Errorf("validate certificates: %w", err)
+	}
+
+	maybeSetKeyRotationDefaults(&config.KeyRotation)
+
+	if err := validateKeyRotation(config.KeyRotation); err != nil {
+		return nil, fmt.Errorf("validate keyRotation: %w", err)
+	}
+
 	plog.MaybeSetDeprecatedLogLevel(config.LogLevel, &config.Log)
-	if err := plog.ValidateAndSetLogLevelAndFormatGlobally(ctx, config.Log); err != nil {
+	if err := plog.ValidateLogSpec(config.Log); err != nil {
 		return nil, fmt.Errorf("validate log level: %w", err)
 	}
 
@@ -90,12 +147,88 @@ func FromPath(ctx context.Context, path string) (*Config, error) {
 	if err := validateEndpoint(*config.Endpoints.HTTP); err != nil {
 		return nil, fmt.Errorf("validate http endpoint: %w", err)
 	}
-	if err := validateAdditionalHTTPEndpointRequirements(*config.Endpoints.HTTP, config.AllowExternalHTTP); err != nil {
-		return nil, fmt.Errorf("validate http endpoint: %w", err)
+	for _, listener := range config.Endpoints.HTTP.Listeners() {
+		if err := validateAdditionalHTTPEndpointRequirements(listener, config.AllowExternalHTTP); err != nil {
+			return nil, fmt.Errorf("validate http endpoint: %w", err)
+		}
 	}
 	if err := validateAtLeastOneEnabledEndpoint(*config.Endpoints.HTTPS, *config.Endpoints.HTTP); err != nil {
 		return nil, fmt.Errorf("validate endpoints: %w", err)
 	}
+	if err := validateUniqueListeners(*config.Endpoints.HTTPS, *config.Endpoints.HTTP); err != nil {
+		return nil, fmt.Errorf("validate endpoints: %w", err)
+	}
+
+	if config.PasswordLockout != nil {
+		maybeSetPasswordLockoutDefaults(config.PasswordLockout)
+		if err := validatePasswordLockout(*config.PasswordLockout); err != nil {
+			return nil, fmt.Errorf("validate passwordLockout: %w", err)
+		}
+	}
+
+	if config.SessionStorage != nil {
+		maybeSetSessionStorageDefaults(config.SessionStorage)
+		if err := validateSessionStorage(*config.SessionStorage); err != nil {
+			return nil, fmt.Errorf("validate sessionStorage: %w", err)
+		}
+	}
+
+	if config.SessionGarbageCollection != nil {
+		maybeSetSessionGarbageCollectionDefaults(config.SessionGarbageCollection)
+		if err := validateSessionGarbageCollection(*config.SessionGarbageCollection); err != nil {
+			return nil, fmt.Errorf("validate sessionGarbageCollection: %w", err)
+		}
+	}
+
+	if config.AuditLog != nil {
+		if err := validateAuditLog(*config.AuditLog); err != nil {
+			return nil, fmt.Errorf("validate auditLog: %w", err)
+		}
+	}
+
+	if config.Tracing != nil {
+		maybeSetTracingDefaults(config.Tracing)
+		if err := validateTracing(*config.Tracing); err != nil {
+			return nil, fmt.Errorf("validate tracing: %w", err)
+		}
+	}
+
+	if config.Shutdown != nil {
+		maybeSetShutdownDefaults(config.Shutdown)
+		if err := validateShutdown(*config.Shutdown); err != nil {
+			return nil, fmt.Errorf("validate shutdown: %w", err)
+		}
+	}
+
+	if config.TLS != nil {
+		if err := validateTLS(*config.TLS); err != nil {
+			return nil, fmt.Errorf("validate tls: %w", err)
+		}
+	}
+
+	if err := validateTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("validate trustedProxies: %w", err)
+	}
+
+	if err := controllerlib.ValidateTuning(config.Controllers); err != nil {
+		return nil, fmt.Errorf("validate controllers: %w", err)
+	}
+
+	if err := config.KubeClient.Validate(); err != nil {
+		return nil, fmt.Errorf("validate kubeClient: %w", err)
+	}
+
+	if err := config.Profiling.Validate(); err != nil {
+		return nil, fmt.Errorf("validate profiling: %w", err)
+	}
+
+	if err := config.Metrics.Validate(); err != nil {
+		return nil, fmt.Errorf("validate metrics: %w", err)
+	}
+
+	if err := validateExternalSigners(config.ExternalSigners); err != nil {
+		return nil, fmt.Errorf("validate externalSigners: %w", err)
+	}
 
 	return &config, nil
 }
@@ -123,6 +256,251 @@ func maybeSetAggregatedAPIServerPortDefaults(port **int64) {
 	}
 }
 
+func maybeSetPasswordLockoutDefaults(passwordLockout *PasswordLockout) {
+	if passwordLockout.MaxFailedAttempts == nil {
+		passwordLockout.MaxFailedAttempts = pointer.Int64(passwordLockoutMaxFailedAttemptsDefault)
+	}
+	if passwordLockout.LockoutDurationSeconds == nil {
+		passwordLockout.LockoutDurationSeconds = pointer.Int64(passwordLockoutLockoutDurationSecondsDefault)
+	}
+	if passwordLockout.Storage == "" {
+		passwordLockout.Storage = PasswordLockoutStorageMemory
+	}
+}
+
+func validatePasswordLockout(passwordLockout PasswordLockout) error {
+	if *passwordLockout.MaxFailedAttempts < 1 {
+		return constable.Error("maxFailedAttempts must be at least 1")
+	}
+	if *passwordLockout.LockoutDurationSeconds < 1 {
+		return constable.Error("lockoutDurationSeconds must be at least 1")
+	}
+	switch passwordLockout.Storage {
+	case PasswordLockoutStorageMemory, PasswordLockoutStorageKubernetes:
+		return nil
+	default:
+		return fmt.Errorf("unknown storage %q", passwordLockout.Storage)
+	}
+}
+
+func maybeSetSessionStorageDefaults(sessionStorage *SessionStorage) {
+	if sessionStorage.Type == "" {
+		sessionStorage.Type = SessionStorageTypeKubernetes
+	}
+}
+
+func validateSessionStorage(sessionStorage SessionStorage) error {
+	if err := validateSessionEncryption(sessionStorage.Encryption); err != nil {
+		return fmt.Errorf("encryption: %w", err)
+	}
+
+	switch sessionStorage.Type {
+	case SessionStorageTypeKubernetes:
+		if sessionStorage.Redis != nil {
+			return fmt.Errorf("redis must not be configured when type is %q", sessionStorage.Type)
+		}
+		return nil
+	case SessionStorageTypeRedis:
+		if sessionStorage.Kubernetes != nil {
+			return fmt.Errorf("kubernetes must not be configured when type is %q", sessionStorage.Type)
+		}
+		return validateRedisSessionStorage(sessionStorage.Redis)
+	default:
+		return fmt.Errorf("unknown type %q", sessionStorage.Type)
+	}
+}
+
+func validateAuditLog(auditLog AuditLog) error {
+	if !auditLog.Stdout && auditLog.Webhook == nil {
+		return constable.Error("at least one of stdout or webhook must be configured")
+	}
+	if auditLog.Webhook == nil {
+		return nil
+	}
+	webhookURL, err := url.Parse(auditLog.Webhook.URL)
+	if err != nil || webhookURL.Scheme != "https" || webhookURL.Host == "" {
+		return constable.Error("webhook.url must be an https URL")
+	}
+	if auditLog.Webhook.CertificateAuthorityData != "" {
+		caBundle, err := base64.StdEncoding.DecodeString(auditLog.Webhook.CertificateAuthorityData)
+		if err != nil {
+			return fmt.Errorf("webhook.certificateAuthorityData is not valid base64: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(caBundle) {
+			return constable.Error("webhook.certificateAuthorityData does not contain any valid PEM certificates")
+		}
+	}
+	return nil
+}
+
+func maybeSetShutdownDefaults(shutdown *Shutdown) {
+	if shutdown.DrainDelaySeconds == nil {
+		shutdown.DrainDelaySeconds = pointer.Int64(ShutdownDrainDelaySecondsDefault)
+	}
+	if shutdown.TimeoutSeconds == nil {
+		shutdown.TimeoutSeconds = pointer.Int64(ShutdownTimeoutSecondsDefault)
+	}
+}
+
+func validateShutdown(shutdown Shutdown) error {
+	if *shutdown.DrainDelaySeconds < 0 {
+		return constable.Error("drainDelaySeconds must not be negative")
+	}
+	if *shutdown.TimeoutSeconds < 1 {
+		return constable.Error("timeoutSeconds must be at least 1")
+	}
+	return nil
+}
+
+func maybeSetCertificatesDefaults(certificates *CertificatesSpec) {
+	if certificates.AggregatedAPIServing.DurationSeconds == nil {
+		certificates.AggregatedAPIServing.DurationSeconds = pointer.Int64(aggregatedAPIServingCertificateDurationSecondsDefault)
+	}
+	if certificates.AggregatedAPIServing.RenewBeforeSeconds == nil {
+		certificates.AggregatedAPIServing.RenewBeforeSeconds = pointer.Int64(aggregatedAPIServingCertificateRenewBeforeSecondsDefault)
+	}
+}
+
+func validateCertificates(certificates CertificatesSpec) error {
+	if err := certificates.KeyAlgorithm.Validate(); err != nil {
+		return fmt.Errorf("keyAlgorithm: %w", err)
+	}
+	serving := certificates.AggregatedAPIServing
+	if *serving.DurationSeconds < *serving.RenewBeforeSeconds {
+		return constable.Error("aggregatedAPIServing.durationSeconds cannot be smaller than aggregatedAPIServing.renewBeforeSeconds")
+	}
+	if *serving.RenewBeforeSeconds <= 0 {
+		return constable.Error("aggregatedAPIServing.renewBeforeSeconds must be positive")
+	}
+	return nil
+}
+
+func maybeSetKeyRotationDefaults(keyRotation *KeyRotationSpec) {
+	if keyRotation.PeriodSeconds == nil {
+		keyRotation.PeriodSeconds = pointer.Int64(keyRotationPeriodSecondsDefault)
+	}
+}
+
+func validateKeyRotation(keyRotation KeyRotationSpec) error {
+	if *keyRotation.PeriodSeconds < 60*60 {
+		return constable.Error("periodSeconds must be at least 3600")
+	}
+	return nil
+}
+
+func validateExternalSigners(externalSigners ExternalSignersSpec) error {
+	if externalSigners.IDTokenSigner != nil {
+		if err := externalSigners.IDTokenSigner.Validate(); err != nil {
+			return fmt.Errorf("idTokenSigner: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateTLS(tls TLS) error {
+	if f := tls.DefaultCertificateFiles; f != nil && (f.CertificateFile == "" || f.KeyFile == "") {
+		return constable.Error("defaultCertificateFiles.certificateFile and defaultCertificateFiles.keyFile must both be set")
+	}
+	return tls.ProfileSpec.Validate()
+}
+
+func validateTrustedProxies(trustedProxies []string) error {
+	for _, cidr := range trustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("%q is not a valid CIDR: %w", cidr, err)
+		}
+	}
+	return nil
+}
+
+func maybeSetTracingDefaults(tracing *Tracing) {
+	if tracing.SamplingPercentage == nil {
+		tracing.SamplingPercentage = pointer.Int64(tracingSamplingPercentageDefault)
+	}
+}
+
+func validateTracing(tracing Tracing) error {
+	if tracing.Endpoint == "" {
+		return constable.Error("endpoint must be set")
+	}
+	if _, err := endpointaddr.Parse(tracing.Endpoint, TracingEndpointPortDefault); err != nil {
+		return fmt.Errorf("endpoint is not a valid host or host:port: %w", err)
+	}
+	if tracing.Insecure && tracing.CertificateAuthorityData != "" {
+		return constable.Error("certificateAuthorityData must not be set when insecure is true")
+	}
+	if tracing.CertificateAuthorityData != "" {
+		caBundle, err := base64.StdEncoding.DecodeString(tracing.CertificateAuthorityData)
+		if err != nil {
+			return fmt.Errorf("certificateAuthorityData is not valid base64: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(caBundle) {
+			return constable.Error("certificateAuthorityData does not contain any valid PEM certificates")
+		}
+	}
+	if *tracing.SamplingPercentage < 0 || *tracing.SamplingPercentage > 100 {
+		return constable.Error("samplingPercentage must be between 0 and 100")
+	}
+	return nil
+}
+
+func validateSessionEncryption(encryption *SessionEncryption) error {
+	if encryption == nil || encryption.ExternalKeyEncryptionKey == nil {
+		return nil
+	}
+	if !encryption.Enabled {
+		return constable.Error("externalKeyEncryptionKey requires enabled to be true")
+	}
+	if err := encryption.ExternalKeyEncryptionKey.Validate(); err != nil {
+		return fmt.Errorf("externalKeyEncryptionKey: %w", err)
+	}
+	return nil
+}
+
+func validateRedisSessionStorage(redis *RedisSessionStorageConfig) error {
+	if redis == nil || redis.Address == "" {
+		return constable.Error("redis.address must be set when type is \"redis\"")
+	}
+	if redis.Database < 0 {
+		return constable.Error("redis.database must not be negative")
+	}
+	if redis.TLS != nil && redis.TLS.CertificateAuthorityData != "" {
+		caBundle, err := base64.StdEncoding.DecodeString(redis.TLS.CertificateAuthorityData)
+		if err != nil {
+			return fmt.Errorf("redis.tls.certificateAuthorityData is not valid base64: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(caBundle) {
+			return constable.Error("redis.tls.certificateAuthorityData does not contain any valid PEM certificates")
+		}
+	}
+	return nil
+}
+
+func maybeSetSessionGarbageCollectionDefaults(gc *SessionGarbageCollection) {
+	if gc.IntervalSeconds == nil {
+		gc.IntervalSeconds = pointer.Int64(sessionGarbageCollectionIntervalSecondsDefault)
+	}
+	if gc.BatchSize == nil {
+		gc.BatchSize = pointer.Int64(sessionGarbageCollectionBatchSizeDefault)
+	}
+	if gc.DeletesPerSecond == nil {
+		gc.DeletesPerSecond = pointer.Int64(sessionGarbageCollectionDeletesPerSecondDefault)
+	}
+}
+
+func validateSessionGarbageCollection(gc SessionGarbageCollection) error {
+	if *gc.IntervalSeconds < 1 {
+		return constable.Error("intervalSeconds must be at least 1")
+	}
+	if *gc.BatchSize < 0 {
+		return constable.Error("batchSize must not be negative")
+	}
+	if *gc.DeletesPerSecond < 0 {
+		return constable.Error("deletesPerSecond must not be negative")
+	}
+	return nil
+}
+
 func validateNames(names *NamesConfigSpec) error {
 	missingNames := []string{}
 	if names.DefaultTLSCertificateSecret == "" {
@@ -135,6 +513,27 @@ func validateNames(names *NamesConfigSpec) error {
 }
 
 func validateEndpoint(endpoint Endpoint) error {
+	if err := validateNetworkAndAddress(endpoint); err != nil {
+		return err
+	}
+	if endpoint.Network == NetworkDisabled && len(endpoint.Additional) != 0 {
+		return constable.Error("additional sockets set when disabled, should be empty")
+	}
+	for i, additional := range endpoint.Additional {
+		if additional.Network == NetworkDisabled {
+			return fmt.Errorf("additional[%d]: network must be %q or %q", i, NetworkTCP, NetworkUnix)
+		}
+		if len(additional.Additional) != 0 {
+			return fmt.Errorf("additional[%d]: additional sockets may not have their own additional sockets", i)
+		}
+		if err := validateNetworkAndAddress(additional); err != nil {
+			return fmt.Errorf("additional[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateNetworkAndAddress(endpoint Endpoint) error {
 	switch n := endpoint.Network; n {
 	case NetworkTCP, NetworkUnix:
 		if len(endpoint.Address) == 0 {
@@ -178,6 +577,23 @@ func validateAtLeastOneEnabledEndpoint(endpoints ...Endpoint) error {
 	return constable.Error("all endpoints are disabled")
 }
 
+// validateUniqueListeners makes sure that no two listeners use the same socket, since the Supervisor removes
+// any existing Unix domain socket before listening on it.
+func validateUniqueListeners(endpoints ...Endpoint) error {
+	type socket struct{ network, address string }
+	seen := map[socket]bool{}
+	for _, endpoint := range endpoints {
+		for _, listener := range endpoint.Listeners() {
+			key := socket{network: listener.Network, address: listener.Address}
+			if seen[key] {
+				return fmt.Errorf("address %q for %q network is used by more than one listener", listener.Address, listener.Network)
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
 // For tcp networks, the address can be in several formats: host:port, host:, and :port.
 // See address description in https://pkg.go.dev/net#Listen and https://pkg.go.dev/net#Dial.
 // The host may be a literal IP address, or a host name that can be resolved to IP addresses,
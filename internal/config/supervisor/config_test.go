@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisor
@@ -12,7 +12,13 @@ import (
 	"github.com/stretchr/testify/require"
 	"k8s.io/utils/pointer"
 
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/externalsigner"
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/kubeclient"
+	"go.pinniped.dev/internal/metricsendpoint"
 	"go.pinniped.dev/internal/plog"
 )
 
@@ -33,6 +39,8 @@ func TestFromPath(t *testing.T) {
 				  myLabelKey2: myLabelValue2
 				names:
 				  defaultTLSCertificateSecret: my-secret-name
+				  configMap: my-config-map
+				  supervisorConfig: my-supervisor-config
 				endpoints:
 				  https:
 				    network: unix
@@ -43,6 +51,25 @@ func TestFromPath(t *testing.T) {
 				insecureAcceptExternalUnencryptedHttpRequests: false
 				logLevel: trace
 				aggregatedAPIServerPort: 12345
+				certificates:
+				  keyAlgorithm: ECDSA-P384
+				  aggregatedAPIServing:
+				    durationSeconds: 7200
+				    renewBeforeSeconds: 3600
+				keyRotation:
+				  periodSeconds: 86400
+				externalSigners:
+				  idTokenSigner:
+				    endpoint: unix:///var/run/pinniped-signer/socket
+				    keyID: some-key
+				tls:
+				  minVersion: TLS1.3
+				  curvePreferences: [CurveP256, X25519]
+				metrics:
+				  allowUnauthenticated: true
+				  cleartextPort: 9090
+				  latencyBuckets: [0.1, 1, 10]
+				enforceFIPS: true
 			`),
 			wantConfig: &Config{
 				APIGroupSuffix: pointer.String("some.suffix.com"),
@@ -52,6 +79,8 @@ func TestFromPath(t *testing.T) {
 				},
 				NamesConfig: NamesConfigSpec{
 					DefaultTLSCertificateSecret: "my-secret-name",
+					ConfigMap:                   "my-config-map",
+					SupervisorConfig:            "my-supervisor-config",
 				},
 				Endpoints: &Endpoints{
 					HTTPS: &Endpoint{
@@ -69,6 +98,34 @@ func TestFromPath(t *testing.T) {
 					Level: plog.LevelTrace,
 				},
 				AggregatedAPIServerPort: pointer.Int64(12345),
+				Certificates: CertificatesSpec{
+					KeyAlgorithm: certauthority.KeyAlgorithmECDSAP384,
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(7200),
+						RenewBeforeSeconds: pointer.Int64(3600),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(86400),
+				},
+				ExternalSigners: ExternalSignersSpec{
+					IDTokenSigner: &externalsigner.Spec{
+						Endpoint: "unix:///var/run/pinniped-signer/socket",
+						KeyID:    "some-key",
+					},
+				},
+				TLS: &TLS{
+					ProfileSpec: ptls.ProfileSpec{
+						MinVersion:       "TLS1.3",
+						CurvePreferences: []string{"CurveP256", "X25519"},
+					},
+				},
+				Metrics: metricsendpoint.Spec{
+					AllowUnauthenticated: true,
+					CleartextPort:        pointer.Int64(9090),
+					LatencyBuckets:       []float64{0.1, 1, 10},
+				},
+				EnforceFIPS: true,
 			},
 		},
 		{
@@ -119,6 +176,15 @@ func TestFromPath(t *testing.T) {
 					Format: plog.FormatText,
 				},
 				AggregatedAPIServerPort: pointer.Int64(12345),
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
 			},
 		},
 		{
@@ -170,6 +236,57 @@ func TestFromPath(t *testing.T) {
 					Format: plog.FormatText,
 				},
 				AggregatedAPIServerPort: pointer.Int64(10250),
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "Happy with kubernetes-json log format",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				log:
+				  level: debug
+				  format: kubernetes-json
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AllowExternalHTTP: false,
+				Log: plog.LogSpec{
+					Level:  plog.LevelDebug,
+					Format: plog.FormatKubernetesJSON,
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
 			},
 		},
 		{
@@ -182,7 +299,134 @@ func TestFromPath(t *testing.T) {
 				  level: info
 				  format: cli
 			`),
-			wantError: "decode yaml: error unmarshaling JSON: while decoding JSON: invalid log format, valid choices are the empty string, json and text",
+			wantError: "decode yaml: error unmarshaling JSON: while decoding JSON: invalid log format, valid choices are the empty string, json, kubernetes-json and text",
+		},
+		{
+			name: "Happy with controller tuning",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				controllers:
+				  some-controller:
+				    rateLimiter:
+				      maxDelaySeconds: 30
+				  other-controller:
+				    resyncPeriodSeconds: 3600
+				kubeClient:
+				  burst: 100
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AllowExternalHTTP:       false,
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				Controllers: map[string]controllerlib.TuningSpec{
+					"some-controller": {
+						RateLimiter: &controllerlib.RateLimiterSpec{
+							MaxDelaySeconds: pointer.Int64(30),
+						},
+					},
+					"other-controller": {
+						ResyncPeriodSeconds: pointer.Int64(3600),
+					},
+				},
+				KubeClient: kubeclient.RateLimitSpec{
+					Burst: pointer.Int(100),
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "invalid controller tuning",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				controllers:
+				  some-controller:
+				    rateLimiter:
+				      baseDelayMilliseconds: 60000
+				      maxDelaySeconds: 30
+			`),
+			wantError: `validate controllers: controller "some-controller": rateLimiter.baseDelayMilliseconds cannot be longer than rateLimiter.maxDelaySeconds`,
+		},
+		{
+			name: "invalid kube client rate limit",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				kubeClient:
+				  burst: -1
+			`),
+			wantError: "validate kubeClient: burst must be positive",
+		},
+		{
+			name: "invalid profiling",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				profiling:
+				  enabled: true
+				  loopbackPort: 80
+			`),
+			wantError: "validate profiling: loopbackPort must be between 1024 and 65535",
+		},
+		{
+			name: "invalid metrics",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				metrics:
+				  cleartextPort: 65536
+			`),
+			wantError: "validate metrics: cleartextPort must be between 1024 and 65535",
+		},
+		{
+			name: "invalid external ID token signer",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				externalSigners:
+				  idTokenSigner:
+				    endpoint: unix:///var/run/pinniped-signer/socket
+			`),
+			wantError: "validate externalSigners: idTokenSigner: keyID must not be empty",
+		},
+		{
+			name: "invalid tls profile",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tls:
+				  cipherSuites: [TLS_RSA_WITH_RC4_128_SHA]
+			`),
+			wantError: `validate tls: cipher suite "TLS_RSA_WITH_RC4_128_SHA" is insecure`,
 		},
 		{
 			name: "When only the required fields are present, causes other fields to be defaulted",
@@ -208,6 +452,15 @@ func TestFromPath(t *testing.T) {
 				},
 				AllowExternalHTTP:       false,
 				AggregatedAPIServerPort: pointer.Int64(10250),
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
 			},
 		},
 		{
@@ -339,6 +592,15 @@ func TestFromPath(t *testing.T) {
 				},
 				AllowExternalHTTP:       true,
 				AggregatedAPIServerPort: pointer.Int64(10250),
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
 			},
 		},
 		{
@@ -371,6 +633,15 @@ func TestFromPath(t *testing.T) {
 				},
 				AllowExternalHTTP:       true,
 				AggregatedAPIServerPort: pointer.Int64(10250),
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
 			},
 		},
 		{
@@ -410,6 +681,160 @@ func TestFromPath(t *testing.T) {
 			`),
 			wantError: `validate https endpoint: address must be set with "unix" network`,
 		},
+		{
+			name: "endpoints with additional sockets",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpoints:
+				  https:
+				    network: tcp
+				    address: :8443
+				    additional:
+				    - network: unix
+				      address: /pinniped_socket/https.sock
+				  http:
+				    network: unix
+				    address: /pinniped_socket/http.sock
+				    additional:
+				    - network: tcp
+				      address: 127.0.0.1:8080
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+						Additional: []Endpoint{
+							{Network: "unix", Address: "/pinniped_socket/https.sock"},
+						},
+					},
+					HTTP: &Endpoint{
+						Network: "unix",
+						Address: "/pinniped_socket/http.sock",
+						Additional: []Endpoint{
+							{Network: "tcp", Address: "127.0.0.1:8080"},
+						},
+					},
+				},
+				AllowExternalHTTP:       false,
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "endpoint disabled with additional sockets",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpoints:
+				  http:
+				    network: disabled
+				    additional:
+				    - network: unix
+				      address: /pinniped_socket/http.sock
+			`),
+			wantError: `validate http endpoint: additional sockets set when disabled, should be empty`,
+		},
+		{
+			name: "endpoint with disabled additional socket",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpoints:
+				  https:
+				    network: tcp
+				    address: :8443
+				    additional:
+				    - network: disabled
+			`),
+			wantError: `validate https endpoint: additional[0]: network must be "tcp" or "unix"`,
+		},
+		{
+			name: "endpoint with nested additional sockets",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpoints:
+				  https:
+				    network: tcp
+				    address: :8443
+				    additional:
+				    - network: unix
+				      address: /pinniped_socket/https.sock
+				      additional:
+				      - network: unix
+				        address: /pinniped_socket/other.sock
+			`),
+			wantError: `validate https endpoint: additional[0]: additional sockets may not have their own additional sockets`,
+		},
+		{
+			name: "endpoint with additional unix socket with empty address",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpoints:
+				  https:
+				    network: tcp
+				    address: :8443
+				    additional:
+				    - network: unix
+			`),
+			wantError: `validate https endpoint: additional[0]: address must be set with "unix" network`,
+		},
+		{
+			name: "http endpoint with additional tcp socket which binds to more than only loopback interfaces",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpoints:
+				  http:
+				    network: unix
+				    address: /pinniped_socket/http.sock
+				    additional:
+				    - network: tcp
+				      address: :8080
+			`),
+			wantError: `validate http endpoint: http listener address ":8080" for "tcp" network may only bind to loopback interfaces`,
+		},
+		{
+			name: "endpoints which listen on the same socket",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				endpoints:
+				  https:
+				    network: tcp
+				    address: :8443
+				    additional:
+				    - network: unix
+				      address: /pinniped_socket/socketfile.sock
+				  http:
+				    network: unix
+				    address: /pinniped_socket/socketfile.sock
+			`),
+			wantError: `validate endpoints: address "/pinniped_socket/socketfile.sock" for "unix" network is used by more than one listener`,
+		},
 		{
 			name: "Missing defaultTLSCertificateSecret name",
 			yaml: here.Doc(`
@@ -427,6 +852,881 @@ func TestFromPath(t *testing.T) {
 			`),
 			wantError: "validate apiGroupSuffix: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
 		},
+		{
+			name: "passwordLockout with defaults",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				passwordLockout: {}
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				PasswordLockout: &PasswordLockout{
+					MaxFailedAttempts:      pointer.Int64(10),
+					LockoutDurationSeconds: pointer.Int64(600),
+					Storage:                "memory",
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "passwordLockout with custom values",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				passwordLockout:
+				  maxFailedAttempts: 3
+				  lockoutDurationSeconds: 60
+				  storage: kubernetes
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				PasswordLockout: &PasswordLockout{
+					MaxFailedAttempts:      pointer.Int64(3),
+					LockoutDurationSeconds: pointer.Int64(60),
+					Storage:                "kubernetes",
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "passwordLockout with invalid maxFailedAttempts",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				passwordLockout:
+				  maxFailedAttempts: 0
+			`),
+			wantError: "validate passwordLockout: maxFailedAttempts must be at least 1",
+		},
+		{
+			name: "passwordLockout with invalid lockoutDurationSeconds",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				passwordLockout:
+				  lockoutDurationSeconds: -1
+			`),
+			wantError: "validate passwordLockout: lockoutDurationSeconds must be at least 1",
+		},
+		{
+			name: "passwordLockout with invalid storage",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				passwordLockout:
+				  storage: redis
+			`),
+			wantError: `validate passwordLockout: unknown storage "redis"`,
+		},
+		{
+			name: "sessionStorage with default type",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage: {}
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				SessionStorage:          &SessionStorage{Type: "kubernetes"},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "sessionStorage with redis",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  type: redis
+				  redis:
+				    address: redis.example.com:6379
+				    database: 2
+				    secretName: my-redis-credentials
+				    tls: {}
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				SessionStorage: &SessionStorage{
+					Type: "redis",
+					Redis: &RedisSessionStorageConfig{
+						Address:    "redis.example.com:6379",
+						Database:   2,
+						SecretName: "my-redis-credentials",
+						TLS:        &RedisTLSConfig{},
+					},
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "sessionStorage with cached reads",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  kubernetes:
+				    cachedReads: true
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				SessionStorage: &SessionStorage{
+					Type:       "kubernetes",
+					Kubernetes: &KubernetesSessionStorageConfig{CachedReads: true},
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "sessionStorage with encryption",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  encryption:
+				    enabled: true
+				    externalKeyEncryptionKey:
+				      endpoint: unix:///var/run/pinniped-signer/socket
+				      keyID: some-kek
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				SessionStorage: &SessionStorage{
+					Type: "kubernetes",
+					Encryption: &SessionEncryption{
+						Enabled: true,
+						ExternalKeyEncryptionKey: &externalsigner.Spec{
+							Endpoint: "unix:///var/run/pinniped-signer/socket",
+							KeyID:    "some-kek",
+						},
+					},
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "sessionStorage with external key encryption key but encryption disabled",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  encryption:
+				    externalKeyEncryptionKey:
+				      endpoint: unix:///var/run/pinniped-signer/socket
+				      keyID: some-kek
+			`),
+			wantError: `validate sessionStorage: encryption: externalKeyEncryptionKey requires enabled to be true`,
+		},
+		{
+			name: "sessionStorage with invalid external key encryption key",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  encryption:
+				    enabled: true
+				    externalKeyEncryptionKey:
+				      endpoint: unix:///var/run/pinniped-signer/socket
+			`),
+			wantError: `validate sessionStorage: encryption: externalKeyEncryptionKey: keyID must not be empty`,
+		},
+		{
+			name: "sessionStorage with unknown type",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  type: etcd
+			`),
+			wantError: `validate sessionStorage: unknown type "etcd"`,
+		},
+		{
+			name: "sessionStorage with redis config for kubernetes type",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  type: kubernetes
+				  redis:
+				    address: redis.example.com:6379
+			`),
+			wantError: `validate sessionStorage: redis must not be configured when type is "kubernetes"`,
+		},
+		{
+			name: "sessionStorage with kubernetes config for redis type",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  type: redis
+				  kubernetes:
+				    cachedReads: true
+				  redis:
+				    address: redis.example.com:6379
+			`),
+			wantError: `validate sessionStorage: kubernetes must not be configured when type is "redis"`,
+		},
+		{
+			name: "sessionStorage with redis type but no address",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  type: redis
+			`),
+			wantError: `validate sessionStorage: redis.address must be set when type is "redis"`,
+		},
+		{
+			name: "sessionStorage with negative redis database",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  type: redis
+				  redis:
+				    address: redis.example.com:6379
+				    database: -1
+			`),
+			wantError: "validate sessionStorage: redis.database must not be negative",
+		},
+		{
+			name: "sessionStorage with invalid redis CA bundle",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionStorage:
+				  type: redis
+				  redis:
+				    address: redis.example.com:6379
+				    tls:
+				      certificateAuthorityData: bm90IGEgY2VydGlmaWNhdGU=
+			`),
+			wantError: "validate sessionStorage: redis.tls.certificateAuthorityData does not contain any valid PEM certificates",
+		},
+		{
+			name: "sessionGarbageCollection with defaults",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionGarbageCollection: {}
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				SessionGarbageCollection: &SessionGarbageCollection{
+					IntervalSeconds:  pointer.Int64(30),
+					BatchSize:        pointer.Int64(0),
+					DeletesPerSecond: pointer.Int64(0),
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "sessionGarbageCollection with custom values",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionGarbageCollection:
+				  intervalSeconds: 120
+				  batchSize: 500
+				  deletesPerSecond: 20
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				SessionGarbageCollection: &SessionGarbageCollection{
+					IntervalSeconds:  pointer.Int64(120),
+					BatchSize:        pointer.Int64(500),
+					DeletesPerSecond: pointer.Int64(20),
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "sessionGarbageCollection with invalid intervalSeconds",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionGarbageCollection:
+				  intervalSeconds: 0
+			`),
+			wantError: "validate sessionGarbageCollection: intervalSeconds must be at least 1",
+		},
+		{
+			name: "sessionGarbageCollection with invalid batchSize",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionGarbageCollection:
+				  batchSize: -1
+			`),
+			wantError: "validate sessionGarbageCollection: batchSize must not be negative",
+		},
+		{
+			name: "sessionGarbageCollection with invalid deletesPerSecond",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				sessionGarbageCollection:
+				  deletesPerSecond: -1
+			`),
+			wantError: "validate sessionGarbageCollection: deletesPerSecond must not be negative",
+		},
+		{
+			name: "auditLog with stdout and webhook",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				auditLog:
+				  stdout: true
+				  webhook:
+				    url: https://siem.example.com/events
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				AuditLog: &AuditLog{
+					Stdout: true,
+					Webhook: &AuditLogWebhook{
+						URL: "https://siem.example.com/events",
+					},
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "auditLog without any destination",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				auditLog: {}
+			`),
+			wantError: "validate auditLog: at least one of stdout or webhook must be configured",
+		},
+		{
+			name: "auditLog with a webhook url which is not https",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				auditLog:
+				  webhook:
+				    url: http://siem.example.com/events
+			`),
+			wantError: "validate auditLog: webhook.url must be an https URL",
+		},
+		{
+			name: "auditLog with invalid webhook certificateAuthorityData",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				auditLog:
+				  webhook:
+				    url: https://siem.example.com/events
+				    certificateAuthorityData: bm90IGEgY2VydGlmaWNhdGU=
+			`),
+			wantError: "validate auditLog: webhook.certificateAuthorityData does not contain any valid PEM certificates",
+		},
+		{
+			name: "tracing with defaults",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tracing:
+				  endpoint: otel-collector.example.com
+				  insecure: true
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				Tracing: &Tracing{
+					Endpoint:           "otel-collector.example.com",
+					Insecure:           true,
+					SamplingPercentage: pointer.Int64(100),
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "tracing without endpoint",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tracing:
+				  samplingPercentage: 10
+			`),
+			wantError: "validate tracing: endpoint must be set",
+		},
+		{
+			name: "tracing with insecure and certificateAuthorityData",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tracing:
+				  endpoint: otel-collector.example.com:4317
+				  insecure: true
+				  certificateAuthorityData: bm90IGEgY2VydGlmaWNhdGU=
+			`),
+			wantError: "validate tracing: certificateAuthorityData must not be set when insecure is true",
+		},
+		{
+			name: "tracing with samplingPercentage too large",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tracing:
+				  endpoint: otel-collector.example.com:4317
+				  samplingPercentage: 101
+			`),
+			wantError: "validate tracing: samplingPercentage must be between 0 and 100",
+		},
+		{
+			name: "shutdown with defaults",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				shutdown:
+				  drainDelaySeconds: 0
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				Shutdown: &Shutdown{
+					DrainDelaySeconds: pointer.Int64(0),
+					TimeoutSeconds:    pointer.Int64(20),
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "shutdown with negative drainDelaySeconds",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				shutdown:
+				  drainDelaySeconds: -1
+			`),
+			wantError: "validate shutdown: drainDelaySeconds must not be negative",
+		},
+		{
+			name: "shutdown with zero timeoutSeconds",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				shutdown:
+				  timeoutSeconds: 0
+			`),
+			wantError: "validate shutdown: timeoutSeconds must be at least 1",
+		},
+		{
+			name: "tls with defaultCertificateFiles",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tls:
+				  defaultCertificateFiles:
+				    certificateFile: /etc/tls/tls.crt
+				    keyFile: /etc/tls/tls.key
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				TLS: &TLS{
+					DefaultCertificateFiles: &CertificateFiles{
+						CertificateFile: "/etc/tls/tls.crt",
+						KeyFile:         "/etc/tls/tls.key",
+					},
+				},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "tls with defaultCertificateFiles without keyFile",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				tls:
+				  defaultCertificateFiles:
+				    certificateFile: /etc/tls/tls.crt
+			`),
+			wantError: "validate tls: defaultCertificateFiles.certificateFile and defaultCertificateFiles.keyFile must both be set",
+		},
+		{
+			name: "certificates with invalid keyAlgorithm",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				certificates:
+				  keyAlgorithm: RSA-1024
+			`),
+			wantError: `validate certificates: keyAlgorithm: unsupported key algorithm "RSA-1024", must be one of ECDSA-P256, ECDSA-P384, RSA-2048 or RSA-4096`,
+		},
+		{
+			name: "certificates with renewBeforeSeconds larger than durationSeconds",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				certificates:
+				  aggregatedAPIServing:
+				    durationSeconds: 3600
+				    renewBeforeSeconds: 7200
+			`),
+			wantError: "validate certificates: aggregatedAPIServing.durationSeconds cannot be smaller than aggregatedAPIServing.renewBeforeSeconds",
+		},
+		{
+			name: "keyRotation with a short periodSeconds",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				keyRotation:
+				  periodSeconds: 60
+			`),
+			wantError: "validate keyRotation: periodSeconds must be at least 3600",
+		},
+		{
+			name: "trustedProxies",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				trustedProxies:
+				- 10.0.0.0/8
+				- fd00::/8
+			`),
+			wantConfig: &Config{
+				APIGroupSuffix: pointer.String("pinniped.dev"),
+				Labels:         map[string]string{},
+				NamesConfig: NamesConfigSpec{
+					DefaultTLSCertificateSecret: "my-secret-name",
+				},
+				Endpoints: &Endpoints{
+					HTTPS: &Endpoint{
+						Network: "tcp",
+						Address: ":8443",
+					},
+					HTTP: &Endpoint{
+						Network: "disabled",
+					},
+				},
+				AggregatedAPIServerPort: pointer.Int64(10250),
+				TrustedProxies:          []string{"10.0.0.0/8", "fd00::/8"},
+				Certificates: CertificatesSpec{
+					AggregatedAPIServing: CertificateLifetimeSpec{
+						DurationSeconds:    pointer.Int64(31536000),
+						RenewBeforeSeconds: pointer.Int64(23328000),
+					},
+				},
+				KeyRotation: KeyRotationSpec{
+					PeriodSeconds: pointer.Int64(2592000),
+				},
+			},
+		},
+		{
+			name: "trustedProxies with an IP instead of a CIDR",
+			yaml: here.Doc(`
+				---
+				names:
+				  defaultTLSCertificateSecret: my-secret-name
+				trustedProxies:
+				- 10.1.2.3
+			`),
+			wantError: `validate trustedProxies: "10.1.2.3" is not a valid CIDR: invalid CIDR address: 10.1.2.3`,
+		},
 		{
 			name: "AggregatedAPIServerPortDefault too small",
 			yaml: here.Doc(`
@@ -0,0 +1,137 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisor
+
+import (
+	"fmt"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/plog"
+)
+
+// ValidateSupervisorConfig checks the settings of a SupervisorConfig on their own, i.e. without the static
+// configuration whose settings they override. Unlike in the static configuration, the HTTP endpoint of a
+// SupervisorConfig may never listen on non-loopback interfaces.
+func ValidateSupervisorConfig(spec configv1alpha1.SupervisorConfigSpec) error {
+	if spec.Log != nil {
+		if err := plog.ValidateLogSpec(logSpecFromSupervisorConfig(plog.LogSpec{}, *spec.Log)); err != nil {
+			return fmt.Errorf("validate log: %w", err)
+		}
+	}
+
+	if spec.TLS != nil {
+		if err := profileSpecFromSupervisorConfig(ptls.ProfileSpec{}, *spec.TLS).Validate(); err != nil {
+			return fmt.Errorf("validate tls: %w", err)
+		}
+	}
+
+	if e := spec.Endpoints; e != nil {
+		if e.HTTPS != nil {
+			if err := validateEndpoint(endpointFromSupervisorConfig(*e.HTTPS)); err != nil {
+				return fmt.Errorf("validate https endpoint: %w", err)
+			}
+		}
+		if e.HTTP != nil {
+			http := endpointFromSupervisorConfig(*e.HTTP)
+			if err := validateEndpoint(http); err != nil {
+				return fmt.Errorf("validate http endpoint: %w", err)
+			}
+			if err := validateAdditionalHTTPEndpointRequirements(http, false); err != nil {
+				return fmt.Errorf("validate http endpoint: %w", err)
+			}
+		}
+		if e.HTTPS != nil && e.HTTP != nil {
+			https, http := endpointFromSupervisorConfig(*e.HTTPS), endpointFromSupervisorConfig(*e.HTTP)
+			if err := validateAtLeastOneEnabledEndpoint(https, http); err != nil {
+				return fmt.Errorf("validate endpoints: %w", err)
+			}
+			if err := validateUniqueListeners(https, http); err != nil {
+				return fmt.Errorf("validate endpoints: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// WithSupervisorConfig returns a copy of the Config whose log levels, TLS profile and endpoints are overridden by the
+// settings of a SupervisorConfig. An endpoint of the SupervisorConfig replaces the whole endpoint of the Config,
+// including its additional sockets. It returns an error when the settings or the resulting endpoints are invalid.
+func (c *Config) WithSupervisorConfig(spec configv1alpha1.SupervisorConfigSpec) (*Config, error) {
+	if err := ValidateSupervisorConfig(spec); err != nil {
+		return nil, err
+	}
+
+	config := *c
+
+	if spec.Log != nil {
+		config.Log = logSpecFromSupervisorConfig(c.Log, *spec.Log)
+	}
+
+	if spec.TLS != nil {
+		tls := TLS{}
+		if c.TLS != nil {
+			tls = *c.TLS
+		}
+		tls.ProfileSpec = profileSpecFromSupervisorConfig(tls.ProfileSpec, *spec.TLS)
+		if err := validateTLS(tls); err != nil {
+			return nil, fmt.Errorf("validate tls: %w", err)
+		}
+		config.TLS = &tls
+	}
+
+	if e := spec.Endpoints; e != nil {
+		endpoints := *c.Endpoints
+		if e.HTTPS != nil {
+			https := endpointFromSupervisorConfig(*e.HTTPS)
+			endpoints.HTTPS = &https
+		}
+		if e.HTTP != nil {
+			http := endpointFromSupervisorConfig(*e.HTTP)
+			endpoints.HTTP = &http
+		}
+		if err := validateAtLeastOneEnabledEndpoint(*endpoints.HTTPS, *endpoints.HTTP); err != nil {
+			return nil, fmt.Errorf("validate endpoints: %w", err)
+		}
+		if err := validateUniqueListeners(*endpoints.HTTPS, *endpoints.HTTP); err != nil {
+			return nil, fmt.Errorf("validate endpoints: %w", err)
+		}
+		config.Endpoints = &endpoints
+	}
+
+	return &config, nil
+}
+
+// logSpecFromSupervisorConfig overrides the level and the components of a LogSpec. The format cannot be changed
+// while running, so it is kept.
+func logSpecFromSupervisorConfig(log plog.LogSpec, spec configv1alpha1.SupervisorConfigLogSpec) plog.LogSpec {
+	log.Level = plog.LogLevel(spec.Level)
+	log.Components = nil
+	for component, level := range spec.Components {
+		if log.Components == nil {
+			log.Components = make(map[string]plog.LogLevel, len(spec.Components))
+		}
+		log.Components[component] = plog.LogLevel(level)
+	}
+	return log
+}
+
+// profileSpecFromSupervisorConfig overrides the settings of a ProfileSpec which are set in the SupervisorConfig.
+func profileSpecFromSupervisorConfig(profile ptls.ProfileSpec, spec configv1alpha1.SupervisorConfigTLSSpec) ptls.ProfileSpec {
+	if spec.MinVersion != "" {
+		profile.MinVersion = spec.MinVersion
+	}
+	if len(spec.CipherSuites) != 0 {
+		profile.CipherSuites = spec.CipherSuites
+	}
+	if len(spec.CurvePreferences) != 0 {
+		profile.CurvePreferences = spec.CurvePreferences
+	}
+	return profile
+}
+
+func endpointFromSupervisorConfig(spec configv1alpha1.SupervisorConfigEndpoint) Endpoint {
+	return Endpoint{Network: string(spec.Network), Address: spec.Address}
+}
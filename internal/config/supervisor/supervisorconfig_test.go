@@ -0,0 +1,232 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/plog"
+)
+
+func TestWithSupervisorConfig(t *testing.T) {
+	static := &Config{
+		Log: plog.LogSpec{
+			Level:      plog.LevelDebug,
+			Components: map[string]plog.LogLevel{"upstream-oidc": plog.LevelTrace},
+			Format:     plog.FormatText,
+		},
+		TLS: &TLS{
+			ProfileSpec: ptls.ProfileSpec{
+				MinVersion:       "TLS1.2",
+				CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+				CurvePreferences: []string{"X25519"},
+			},
+			DefaultCertificateFiles: &CertificateFiles{CertificateFile: "tls.crt", KeyFile: "tls.key"},
+		},
+		Endpoints: &Endpoints{
+			HTTPS: &Endpoint{
+				Network:    NetworkTCP,
+				Address:    ":8443",
+				Additional: []Endpoint{{Network: NetworkUnix, Address: "/pinniped_socket/https.sock"}},
+			},
+			HTTP: &Endpoint{Network: NetworkDisabled},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		spec       configv1alpha1.SupervisorConfigSpec
+		wantConfig func(c *Config)
+		wantError  string
+	}{
+		{
+			name:       "empty spec keeps the static configuration",
+			spec:       configv1alpha1.SupervisorConfigSpec{},
+			wantConfig: func(c *Config) {},
+		},
+		{
+			name: "log levels are overridden and the format is kept",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Log: &configv1alpha1.SupervisorConfigLogSpec{
+					Level:      "trace",
+					Components: map[string]configv1alpha1.SupervisorConfigLogLevel{"federation-domain": "all"},
+				},
+			},
+			wantConfig: func(c *Config) {
+				c.Log = plog.LogSpec{
+					Level:      plog.LevelTrace,
+					Components: map[string]plog.LogLevel{"federation-domain": plog.LevelAll},
+					Format:     plog.FormatText,
+				}
+			},
+		},
+		{
+			name: "tls settings which are set are overridden and the certificate files are kept",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				TLS: &configv1alpha1.SupervisorConfigTLSSpec{
+					CurvePreferences: []string{"CurveP384", "CurveP256"},
+				},
+			},
+			wantConfig: func(c *Config) {
+				c.TLS = &TLS{
+					ProfileSpec: ptls.ProfileSpec{
+						MinVersion:       "TLS1.2",
+						CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+						CurvePreferences: []string{"CurveP384", "CurveP256"},
+					},
+					DefaultCertificateFiles: &CertificateFiles{CertificateFile: "tls.crt", KeyFile: "tls.key"},
+				}
+			},
+		},
+		{
+			name: "endpoints which are set replace the whole endpoint",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Endpoints: &configv1alpha1.SupervisorConfigEndpoints{
+					HTTPS: &configv1alpha1.SupervisorConfigEndpoint{Network: "tcp", Address: ":9443"},
+					HTTP:  &configv1alpha1.SupervisorConfigEndpoint{Network: "unix", Address: "/pinniped_socket/http.sock"},
+				},
+			},
+			wantConfig: func(c *Config) {
+				c.Endpoints = &Endpoints{
+					HTTPS: &Endpoint{Network: NetworkTCP, Address: ":9443"},
+					HTTP:  &Endpoint{Network: NetworkUnix, Address: "/pinniped_socket/http.sock"},
+				}
+			},
+		},
+		{
+			name: "invalid log level",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Log: &configv1alpha1.SupervisorConfigLogSpec{Level: "loud"},
+			},
+			wantError: "validate log: invalid log level, valid choices are the empty string, info, debug, trace and all",
+		},
+		{
+			name: "tls settings which conflict with the static configuration",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				TLS: &configv1alpha1.SupervisorConfigTLSSpec{MinVersion: "TLS1.3"},
+			},
+			wantError: "validate tls: cipherSuites cannot be configured when minVersion is TLS1.3",
+		},
+		{
+			name: "http endpoint on a non-loopback interface",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Endpoints: &configv1alpha1.SupervisorConfigEndpoints{
+					HTTP: &configv1alpha1.SupervisorConfigEndpoint{Network: "tcp", Address: ":8080"},
+				},
+			},
+			wantError: `validate http endpoint: http listener address ":8080" for "tcp" network may only bind to loopback interfaces`,
+		},
+		{
+			name: "disabling the only enabled endpoint",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Endpoints: &configv1alpha1.SupervisorConfigEndpoints{
+					HTTPS: &configv1alpha1.SupervisorConfigEndpoint{Network: "disabled"},
+				},
+			},
+			wantError: "validate endpoints: all endpoints are disabled",
+		},
+		{
+			name: "endpoint which uses the socket of an additional socket of the static configuration",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Endpoints: &configv1alpha1.SupervisorConfigEndpoints{
+					HTTP: &configv1alpha1.SupervisorConfigEndpoint{Network: "unix", Address: "/pinniped_socket/https.sock"},
+				},
+			},
+			wantError: `validate endpoints: address "/pinniped_socket/https.sock" for "unix" network is used by more than one listener`,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := static.WithSupervisorConfig(tt.spec)
+
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				require.Nil(t, config)
+				return
+			}
+
+			require.NoError(t, err)
+			want := *static
+			tt.wantConfig(&want)
+			require.Equal(t, &want, config)
+		})
+	}
+}
+
+func TestValidateSupervisorConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      configv1alpha1.SupervisorConfigSpec
+		wantError string
+	}{
+		{
+			name: "valid",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Log: &configv1alpha1.SupervisorConfigLogSpec{Level: "debug"},
+				TLS: &configv1alpha1.SupervisorConfigTLSSpec{MinVersion: "TLS1.3"},
+				Endpoints: &configv1alpha1.SupervisorConfigEndpoints{
+					HTTPS: &configv1alpha1.SupervisorConfigEndpoint{Network: "tcp", Address: ":8443"},
+					HTTP:  &configv1alpha1.SupervisorConfigEndpoint{Network: "tcp", Address: "127.0.0.1:8080"},
+				},
+			},
+		},
+		{
+			name: "invalid component log level",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Log: &configv1alpha1.SupervisorConfigLogSpec{
+					Components: map[string]configv1alpha1.SupervisorConfigLogLevel{"upstream-oidc": "loud"},
+				},
+			},
+			wantError: `validate log: log level of component "upstream-oidc": invalid log level, valid choices are the empty string, info, debug, trace and all`,
+		},
+		{
+			name: "unsupported curve",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				TLS: &configv1alpha1.SupervisorConfigTLSSpec{CurvePreferences: []string{"CurveP224"}},
+			},
+			wantError: `validate tls: curve "CurveP224" is not supported, must be one of X25519, CurveP256, CurveP384 or CurveP521`,
+		},
+		{
+			name: "https endpoint without an address",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Endpoints: &configv1alpha1.SupervisorConfigEndpoints{
+					HTTPS: &configv1alpha1.SupervisorConfigEndpoint{Network: "unix"},
+				},
+			},
+			wantError: `validate https endpoint: address must be set with "unix" network`,
+		},
+		{
+			name: "both endpoints on the same socket",
+			spec: configv1alpha1.SupervisorConfigSpec{
+				Endpoints: &configv1alpha1.SupervisorConfigEndpoints{
+					HTTPS: &configv1alpha1.SupervisorConfigEndpoint{Network: "tcp", Address: "127.0.0.1:8443"},
+					HTTP:  &configv1alpha1.SupervisorConfigEndpoint{Network: "tcp", Address: "127.0.0.1:8443"},
+				},
+			},
+			wantError: `validate endpoints: address "127.0.0.1:8443" for "tcp" network is used by more than one listener`,
+		},
+	}
+	for _, test := range tests {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateSupervisorConfig(tt.spec)
+
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
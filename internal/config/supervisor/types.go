@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisor
@@ -6,7 +6,14 @@ package supervisor
 import (
 	"errors"
 
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/crypto/ptls"
+	"go.pinniped.dev/internal/externalsigner"
+	"go.pinniped.dev/internal/kubeclient"
+	"go.pinniped.dev/internal/metricsendpoint"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/profiling"
 )
 
 // Config contains knobs to setup an instance of the Pinniped Supervisor.
@@ -20,12 +27,230 @@ type Config struct {
 	Endpoints               *Endpoints         `json:"endpoints"`
 	AllowExternalHTTP       stringOrBoolAsBool `json:"insecureAcceptExternalUnencryptedHttpRequests"`
 	AggregatedAPIServerPort *int64             `json:"aggregatedAPIServerPort"`
+	PasswordLockout         *PasswordLockout   `json:"passwordLockout,omitempty"`
+	SessionStorage          *SessionStorage    `json:"sessionStorage,omitempty"`
+
+	SessionGarbageCollection *SessionGarbageCollection `json:"sessionGarbageCollection,omitempty"`
+	AuditLog                 *AuditLog                 `json:"auditLog,omitempty"`
+	Tracing                  *Tracing                  `json:"tracing,omitempty"`
+	Shutdown                 *Shutdown                 `json:"shutdown,omitempty"`
+	TLS                      *TLS                      `json:"tls,omitempty"`
+
+	// TrustedProxies are the CIDRs of the proxies in front of the Supervisor, e.g. its Ingress, whose X-Forwarded-For
+	// and X-Forwarded-Proto headers are honored when determining the IP address and protocol of a client.
+	TrustedProxies []string `json:"trustedProxies,omitempty"`
+
+	// Controllers tunes the rate limiters, resync periods and quiet periods of the controllers, keyed by controller name.
+	Controllers map[string]controllerlib.TuningSpec `json:"controllers,omitempty"`
+
+	// KubeClient configures the client-side rate limiting of the requests of the controllers to the Kubernetes API.
+	KubeClient kubeclient.RateLimitSpec `json:"kubeClient,omitempty"`
+	// Profiling optionally serves the pprof endpoints, e.g. to capture CPU and heap profiles during performance incidents.
+	Profiling profiling.Spec `json:"profiling,omitempty"`
+	// Metrics configures how the Prometheus metrics are served.
+	Metrics metricsendpoint.Spec `json:"metrics,omitempty"`
+
+	// Certificates configures the key algorithm and the validity of the certificates which the Supervisor generates.
+	Certificates CertificatesSpec `json:"certificates,omitempty"`
+
+	// KeyRotation configures the rotation of the symmetric keys which sign the CSRF cookies of the Supervisor and
+	// the state parameters of the logins to upstream identity providers.
+	KeyRotation KeyRotationSpec `json:"keyRotation,omitempty"`
+
+	// ExternalSigners configures keys of external signer plugins, e.g. keys in an HSM or a cloud KMS, which are used
+	// instead of keys which are stored in Secrets.
+	ExternalSigners ExternalSignersSpec `json:"externalSigners,omitempty"`
+
+	// EnforceFIPS makes the Supervisor refuse to start unless its crypto module runs in FIPS mode and its servers
+	// only serve FIPS-approved TLS settings.
+	EnforceFIPS bool `json:"enforceFIPS,omitempty"`
+}
+
+// ExternalSignersSpec configures which keys of the Supervisor are held by external signer plugins.
+type ExternalSignersSpec struct {
+	// IDTokenSigner is the key which signs the ID tokens of all FederationDomains. It must be an ECDSA P-256 key.
+	// When it is set, the keys in the JWKS Secrets of the FederationDomains are not used.
+	IDTokenSigner *externalsigner.Spec `json:"idTokenSigner,omitempty"`
+}
+
+// CertificatesSpec configures the CAs and certificates which the Supervisor generates for itself.
+type CertificatesSpec struct {
+	// KeyAlgorithm is the algorithm of the private keys of the generated CAs and certificates, i.e. of the serving
+	// certificate of the aggregated API and of the bootstrap certificate of the HTTPS endpoints. It is one of
+	// ECDSA-P256 (the default), ECDSA-P384, RSA-2048 or RSA-4096. Keys which already exist are replaced only when
+	// their certificates are rotated.
+	KeyAlgorithm certauthority.KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+
+	// AggregatedAPIServing configures the validity of the serving certificate of the aggregated API and of its CA.
+	// By default, they are issued for 1 year and rotated after about 9 months.
+	AggregatedAPIServing CertificateLifetimeSpec `json:"aggregatedAPIServing,omitempty"`
+}
+
+// KeyRotationSpec configures the rotation of the symmetric keys which the Supervisor generates for itself.
+type KeyRotationSpec struct {
+	// PeriodSeconds is how long a key is used before it is rotated. Values which were signed by the previous key
+	// are still accepted until they expire, i.e. for 7 days for CSRF cookies and for 90 minutes for state parameters,
+	// and a key is not rotated again while the previous key is still accepted, so a period shorter than 7 days
+	// rotates the CSRF cookie signing key every 7 days. By default, keys are rotated every 30 days.
+	PeriodSeconds *int64 `json:"periodSeconds,omitempty"`
+}
+
+// CertificateLifetimeSpec configures the validity of a generated certificate which is rotated before it expires.
+type CertificateLifetimeSpec struct {
+	// DurationSeconds is the validity period, in seconds, of the certificate.
+	DurationSeconds *int64 `json:"durationSeconds,omitempty"`
+
+	// RenewBeforeSeconds is the period of time, in seconds, after the issuance of the certificate at which
+	// it is rotated. This must be less than DurationSeconds.
+	RenewBeforeSeconds *int64 `json:"renewBeforeSeconds,omitempty"`
 }
 
 // NamesConfigSpec configures the names of some Kubernetes resources for the Supervisor.
 type NamesConfigSpec struct {
 	DefaultTLSCertificateSecret string `json:"defaultTLSCertificateSecret"`
 	APIService                  string `json:"apiService"`
+
+	// ConfigMap is the name of the ConfigMap in the Supervisor's namespace which holds this configuration. When it is
+	// set, changes to log.level in that ConfigMap are applied while the Supervisor is running.
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// SupervisorConfig is the name of the cluster-scoped SupervisorConfig which overrides the log levels, TLS
+	// settings and endpoints of this configuration. When it is set, changes to that SupervisorConfig are applied
+	// while the Supervisor is running, and the log level in the ConfigMap is no longer watched.
+	SupervisorConfig string `json:"supervisorConfig,omitempty"`
+
+	// ValidatingWebhookConfiguration is the name of the ValidatingWebhookConfiguration which calls the Supervisor's
+	// validating admission webhook. When it is set, the aggregated API server serves the webhook, and the CA bundle of
+	// the ValidatingWebhookConfiguration is kept up to date with the CA of its serving certificate.
+	ValidatingWebhookConfiguration string `json:"validatingWebhookConfiguration,omitempty"`
+}
+
+// PasswordLockout configures temporary lockouts of usernames after repeated failed password logins
+// to upstream LDAP and Active Directory identity providers. Lockouts are disabled when this is not configured.
+type PasswordLockout struct {
+	MaxFailedAttempts      *int64 `json:"maxFailedAttempts,omitempty"`
+	LockoutDurationSeconds *int64 `json:"lockoutDurationSeconds,omitempty"`
+	// Storage is where the failed attempts are tracked, either "memory" of each Supervisor pod (the default)
+	// or "kubernetes" Secrets, which are shared by all Supervisor pods and survive restarts.
+	Storage string `json:"storage,omitempty"`
+}
+
+// SessionGarbageCollection tunes the garbage collector which deletes expired session storage Secrets.
+type SessionGarbageCollection struct {
+	// IntervalSeconds is the minimum number of seconds between garbage collection sweeps.
+	IntervalSeconds *int64 `json:"intervalSeconds,omitempty"`
+	// BatchSize is the maximum number of Secrets deleted per sweep. Zero means no limit.
+	BatchSize *int64 `json:"batchSize,omitempty"`
+	// DeletesPerSecond limits the rate of delete requests to the Kubernetes API. Zero means no limit.
+	DeletesPerSecond *int64 `json:"deletesPerSecond,omitempty"`
+}
+
+// AuditLog configures where the audit events of the authentication lifecycle of downstream sessions are emitted.
+// Audit events are disabled when this is not configured.
+type AuditLog struct {
+	// Stdout writes each audit event as a line of JSON to the Supervisor's stdout.
+	Stdout  bool             `json:"stdout,omitempty"`
+	Webhook *AuditLogWebhook `json:"webhook,omitempty"`
+}
+
+// AuditLogWebhook configures an HTTPS endpoint to which each audit event is POSTed as a JSON document.
+type AuditLogWebhook struct {
+	URL string `json:"url"`
+	// CertificateAuthorityData is an optional base64 encoded PEM bundle used to verify the webhook's certificate.
+	// The host's root CAs are used when it is not configured.
+	CertificateAuthorityData string `json:"certificateAuthorityData,omitempty"`
+}
+
+// Shutdown tunes how the Supervisor stops serving requests when it is asked to stop, e.g. during a rolling update.
+type Shutdown struct {
+	// DrainDelaySeconds is how long the Supervisor keeps accepting new requests after it is asked to stop, while its
+	// /readyz endpoint fails, so that Services and load balancers stop sending it new requests before it stops listening.
+	DrainDelaySeconds *int64 `json:"drainDelaySeconds,omitempty"`
+	// TimeoutSeconds is how long in-flight requests may take to finish after the Supervisor stops listening.
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// TLS configures where the Supervisor loads the certificates which it serves on its HTTPS endpoints, and the
+// TLS settings of the HTTPS endpoints and the aggregated API server.
+type TLS struct {
+	ptls.ProfileSpec
+
+	// DefaultCertificateFiles loads the default TLS serving certificate from PEM files, e.g. a Secret which is
+	// mounted by cert-manager or a file which is written by a SPIFFE sidecar, instead of from the Secret named
+	// by names.defaultTLSCertificateSecret. The files are reloaded whenever they change.
+	DefaultCertificateFiles *CertificateFiles `json:"defaultCertificateFiles,omitempty"`
+}
+
+// CertificateFiles are the paths of the PEM files of a certificate and its private key.
+type CertificateFiles struct {
+	CertificateFile string `json:"certificateFile"`
+	KeyFile         string `json:"keyFile"`
+}
+
+// Tracing configures the export of OpenTelemetry traces of the login flows to an OTLP gRPC collector.
+// Tracing is disabled when this is not configured.
+type Tracing struct {
+	// Endpoint is the host of the collector, with an optional port which defaults to 4317.
+	Endpoint string `json:"endpoint"`
+	// Insecure disables TLS for the connection to the collector.
+	Insecure bool `json:"insecure,omitempty"`
+	// CertificateAuthorityData is an optional base64 encoded PEM bundle used to verify the collector's certificate.
+	// The host's root CAs are used when it is not configured.
+	CertificateAuthorityData string `json:"certificateAuthorityData,omitempty"`
+	// SamplingPercentage is the percentage of requests which are traced, unless the client which made the request
+	// has already decided whether its trace is sampled.
+	SamplingPercentage *int64 `json:"samplingPercentage,omitempty"`
+}
+
+// SessionStorage configures where the Supervisor stores the sessions of downstream clients, i.e. their
+// authorization codes, PKCE and OIDC sessions, access tokens, and refresh tokens. Sessions are stored in
+// Kubernetes Secrets when this is not configured.
+type SessionStorage struct {
+	Type       string                          `json:"type"`
+	Kubernetes *KubernetesSessionStorageConfig `json:"kubernetes,omitempty"`
+	Redis      *RedisSessionStorageConfig      `json:"redis,omitempty"`
+	Encryption *SessionEncryption              `json:"encryption,omitempty"`
+}
+
+// SessionEncryption configures the envelope encryption of the sessions, which keeps the upstream refresh tokens of
+// the sessions unusable to anyone who can only read the session storage, or etcd. The sessions are encrypted with a
+// data encryption key which the Supervisor generates and stores in a Secret, wrapped by a key encryption key.
+// Sessions which were stored before encryption was enabled can still be read, but sessions which were stored while
+// it was enabled cannot be read anymore once it is disabled.
+type SessionEncryption struct {
+	Enabled bool `json:"enabled"`
+
+	// ExternalKeyEncryptionKey is a key of an external signer plugin which wraps the data encryption key, e.g. a key
+	// of a cloud KMS, whose rotation is handled by the plugin. By default, the key encryption key is generated by the
+	// Supervisor in another Secret and rotated every keyRotation.periodSeconds. Changing the external key makes all
+	// existing sessions unreadable, since the plugin is only asked to decrypt with the configured key.
+	ExternalKeyEncryptionKey *externalsigner.Spec `json:"externalKeyEncryptionKey,omitempty"`
+}
+
+// KubernetesSessionStorageConfig tunes the session storage when the session storage type is "kubernetes".
+type KubernetesSessionStorageConfig struct {
+	// CachedReads makes the Supervisor read sessions from its informer cache of the Secrets in its namespace instead
+	// of from the Kubernetes API server, falling back to the Kubernetes API server for sessions which are not in the
+	// cache yet. This reduces the load on the Kubernetes API server during heavy token refresh load, but a session
+	// which was just changed by another Supervisor pod may be read out of date, which makes the request fail.
+	CachedReads bool `json:"cachedReads,omitempty"`
+}
+
+// RedisSessionStorageConfig configures the Redis server which is used when the session storage type is "redis".
+type RedisSessionStorageConfig struct {
+	Address  string `json:"address"`
+	Database int    `json:"database"`
+	// SecretName is the name of an optional Secret in the Supervisor's namespace which contains the "username"
+	// and/or "password" keys used to authenticate to Redis.
+	SecretName string          `json:"secretName,omitempty"`
+	TLS        *RedisTLSConfig `json:"tls,omitempty"`
+}
+
+// RedisTLSConfig enables TLS for connections to Redis.
+type RedisTLSConfig struct {
+	// CertificateAuthorityData is an optional base64 encoded PEM bundle used to verify the Redis server's certificate.
+	// The host's root CAs are used when it is not configured.
+	CertificateAuthorityData string `json:"certificateAuthorityData,omitempty"`
 }
 
 type Endpoints struct {
@@ -36,6 +261,17 @@ type Endpoints struct {
 type Endpoint struct {
 	Network string `json:"network"`
 	Address string `json:"address"`
+	// Additional are other sockets on which the same listener also serves requests, e.g. a Unix domain socket
+	// for a sidecar proxy in addition to a TCP port. They may not be disabled or have their own additional sockets.
+	Additional []Endpoint `json:"additional,omitempty"`
+}
+
+// Listeners returns the network and address of each socket on which the endpoint serves requests.
+func (e *Endpoint) Listeners() []Endpoint {
+	if e.Network == NetworkDisabled {
+		return nil
+	}
+	return append([]Endpoint{{Network: e.Network, Address: e.Address}}, e.Additional...)
 }
 
 type stringOrBoolAsBool bool
@@ -0,0 +1,58 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package configreload reloads the configuration file of the Concierge or the Supervisor while it is running, so that
+// the settings which can be changed without restarting its pods are applied while its listeners and in-flight
+// requests are kept intact.
+package configreload
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// PollInterval is how often the configuration file is checked for changes. The kubelet updates the files of mounted
+// ConfigMaps about a minute after the ConfigMap changes, so checking more often would not apply changes much sooner.
+const PollInterval = 30 * time.Second
+
+// Run calls reload whenever the process receives SIGHUP, and whenever the contents of the configuration file at path
+// change, e.g. because the kubelet updated the mounted ConfigMap, until ctx is done. It blocks. When reload fails, the
+// error is logged and the settings which were applied before are kept.
+func Run(ctx context.Context, path string, pollInterval time.Duration, reload func() error) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastContents, _ := os.ReadFile(path) // the config was already loaded from this file when the server started
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			plog.Info("reloading configuration after SIGHUP", "path", path)
+		case <-ticker.C:
+			contents, err := os.ReadFile(path)
+			if err != nil || bytes.Equal(contents, lastContents) {
+				continue // a missing file is reported when the configuration is reloaded for any other reason
+			}
+			plog.Info("reloading configuration after it changed", "path", path)
+		}
+
+		lastContents, _ = os.ReadFile(path)
+		if err := reload(); err != nil {
+			plog.WarningErr("could not reload configuration, keeping the previous settings", err, "path", path)
+			continue
+		}
+		plog.Info("reloaded configuration", "path", path)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package configreload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pinniped.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("some: config"), 0600))
+
+	var reloads atomic.Int32
+	var reloadFails atomic.Bool
+	reloadFails.Store(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, path, 10*time.Millisecond, func() error {
+			reloads.Add(1)
+			if reloadFails.Load() {
+				return errors.New("some reload error")
+			}
+			return nil
+		})
+	}()
+
+	// the unchanged file is not reloaded
+	time.Sleep(100 * time.Millisecond)
+	require.Zero(t, reloads.Load())
+
+	// a failed reload is retried only once the file changes again
+	require.NoError(t, os.WriteFile(path, []byte("some: changed config"), 0600))
+	require.Eventually(t, func() bool { return reloads.Load() == 1 }, 10*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int32(1), reloads.Load())
+
+	reloadFails.Store(false)
+	require.NoError(t, os.WriteFile(path, []byte("some: other config"), 0600))
+	require.Eventually(t, func() bool { return reloads.Load() == 2 }, 10*time.Second, 10*time.Millisecond)
+
+	// SIGHUP reloads the unchanged file
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool { return reloads.Load() == 3 }, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+}
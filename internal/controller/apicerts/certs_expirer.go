@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package apicerts
@@ -130,20 +130,30 @@ func (c *certsExpirerController) Sync(ctx controllerlib.Context) error {
 // getCertBounds returns the NotBefore and NotAfter fields of the TLS
 // certificate in the provided secret, or an error.
 func (c *certsExpirerController) getCertBounds(secret *corev1.Secret) (time.Time, time.Time, error) {
-	certPEM := secret.Data[c.secretKey]
+	cert, err := getCert(secret, c.secretKey)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return cert.NotBefore, cert.NotAfter, nil
+}
+
+// getCert returns the first certificate in the provided secret at the provided key, or an error.
+func getCert(secret *corev1.Secret, secretKey string) (*x509.Certificate, error) {
+	certPEM := secret.Data[secretKey]
 	if certPEM == nil {
-		return time.Time{}, time.Time{}, constable.Error("failed to find certificate")
+		return nil, constable.Error("failed to find certificate")
 	}
 
 	certBlock, _ := pem.Decode(certPEM)
 	if certBlock == nil {
-		return time.Time{}, time.Time{}, constable.Error("failed to decode certificate PEM")
+		return nil, constable.Error("failed to decode certificate PEM")
 	}
 
 	cert, err := x509.ParseCertificate(certBlock.Bytes)
 	if err != nil {
-		return time.Time{}, time.Time{}, fmt.Errorf("failed to parse certificate: %w", err)
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
 	}
 
-	return cert.NotBefore, cert.NotAfter, nil
+	return cert, nil
 }
@@ -1,9 +1,10 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package apicerts
 
 import (
+	"crypto"
 	"fmt"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 const (
 	CACertificateSecretKey           = "caCertificate"
 	CACertificatePrivateKeySecretKey = "caCertificatePrivateKey"
+	PreviousCACertificateSecretKey   = "previousCACertificate"
 	tlsPrivateKeySecretKey           = "tlsPrivateKey"
 	TLSCertificateChainSecretKey     = "tlsCertificateChain"
 )
@@ -37,6 +39,13 @@ type certsManagerController struct {
 	// certificate that this controller will use when issuing the certificates.
 	certDuration time.Duration
 
+	// keyAlgorithm is the algorithm of the private keys of both the serving certificate and its CA certificate.
+	keyAlgorithm certauthority.KeyAlgorithm
+
+	// caSigner is optional. When it is set, the CA certificate is signed by it, e.g. by a key which is held by an
+	// external signer plugin, instead of by a newly generated private key, and the Secret does not contain a CA private key.
+	caSigner crypto.Signer
+
 	generatedCACommonName                 string
 	serviceNameForGeneratedCertCommonName string
 }
@@ -50,6 +59,8 @@ func NewCertsManagerController(
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
 	withInitialEvent pinnipedcontroller.WithInitialEventOptionFunc,
 	certDuration time.Duration,
+	keyAlgorithm certauthority.KeyAlgorithm,
+	caSigner crypto.Signer,
 	generatedCACommonName string,
 	serviceNameForGeneratedCertCommonName string,
 ) controllerlib.Controller {
@@ -63,6 +74,8 @@ func NewCertsManagerController(
 				k8sClient:                             k8sClient,
 				secretInformer:                        secretInformer,
 				certDuration:                          certDuration,
+				keyAlgorithm:                          keyAlgorithm,
+				caSigner:                              caSigner,
 				generatedCACommonName:                 generatedCACommonName,
 				serviceNameForGeneratedCertCommonName: serviceNameForGeneratedCertCommonName,
 			},
@@ -93,14 +106,13 @@ func (c *certsManagerController) Sync(ctx controllerlib.Context) error {
 	}
 
 	// Create a CA.
-	ca, err := certauthority.New(c.generatedCACommonName, c.certDuration)
-	if err != nil {
-		return fmt.Errorf("could not initialize CA: %w", err)
+	caOpts := []certauthority.Option{certauthority.WithKeyAlgorithm(c.keyAlgorithm)}
+	if c.caSigner != nil {
+		caOpts = append(caOpts, certauthority.WithSigner(c.caSigner))
 	}
-
-	caPrivateKeyPEM, err := ca.PrivateKeyToPEM()
+	ca, err := certauthority.New(c.generatedCACommonName, c.certDuration, caOpts...)
 	if err != nil {
-		return fmt.Errorf("could not get CA private key: %w", err)
+		return fmt.Errorf("could not initialize CA: %w", err)
 	}
 
 	secret := corev1.Secret{
@@ -111,11 +123,19 @@ func (c *certsManagerController) Sync(ctx controllerlib.Context) error {
 			Labels:    c.certsSecretLabels,
 		},
 		StringData: map[string]string{
-			CACertificateSecretKey:           string(ca.Bundle()),
-			CACertificatePrivateKeySecretKey: string(caPrivateKeyPEM),
+			CACertificateSecretKey: string(ca.Bundle()),
 		},
 	}
 
+	// The private key of an external signer never leaves the signer.
+	if c.caSigner == nil {
+		caPrivateKeyPEM, err := ca.PrivateKeyToPEM()
+		if err != nil {
+			return fmt.Errorf("could not get CA private key: %w", err)
+		}
+		secret.StringData[CACertificatePrivateKeySecretKey] = string(caPrivateKeyPEM)
+	}
+
 	// Using the CA from above, create a TLS server cert if we have service name.
 	if len(c.serviceNameForGeneratedCertCommonName) != 0 {
 		serviceEndpoint := c.serviceNameForGeneratedCertCommonName + "." + c.namespace + ".svc"
@@ -1,10 +1,17 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package apicerts
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"testing"
 	"time"
@@ -20,6 +27,7 @@ import (
 	kubernetesfake "k8s.io/client-go/kubernetes/fake"
 	coretesting "k8s.io/client-go/testing"
 
+	"go.pinniped.dev/internal/certauthority"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/testutil"
 )
@@ -48,6 +56,8 @@ func TestManagerControllerOptions(t *testing.T) {
 				observableWithInformerOption.WithInformer,
 				observableWithInitialEventOption.WithInitialEvent,
 				0,
+				"",
+				nil,
 				"Pinniped CA",
 				"ignored",
 			)
@@ -129,6 +139,8 @@ func TestManagerControllerSync(t *testing.T) {
 		var cancelContext context.Context
 		var cancelContextCancelFunc context.CancelFunc
 		var syncContext *controllerlib.Context
+		var keyAlgorithm certauthority.KeyAlgorithm
+		var caSigner crypto.Signer
 
 		// Defer starting the informers until the last possible moment so that the
 		// nested Before's can keep adding things to the informer caches.
@@ -146,6 +158,8 @@ func TestManagerControllerSync(t *testing.T) {
 				controllerlib.WithInformer,
 				controllerlib.WithInitialEvent,
 				certDuration,
+				keyAlgorithm,
+				caSigner,
 				"Pinniped CA",
 				serviceName,
 			)
@@ -173,6 +187,8 @@ func TestManagerControllerSync(t *testing.T) {
 			kubeInformerClient = kubernetesfake.NewSimpleClientset()
 			kubeInformers = kubeinformers.NewSharedInformerFactory(kubeInformerClient, 0)
 			kubeAPIClient = kubernetesfake.NewSimpleClientset()
+			keyAlgorithm = ""
+			caSigner = nil
 		})
 
 		it.After(func() {
@@ -229,6 +245,54 @@ func TestManagerControllerSync(t *testing.T) {
 				validCert.RequireMatchesPrivateKey(actualPrivateKey)
 			})
 
+			it("creates the serving cert Secret with the configured key algorithm", func() {
+				keyAlgorithm = certauthority.KeyAlgorithmRSA2048
+				startInformersAndController(defaultServiceName)
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.Len(kubeAPIClient.Actions(), 1)
+				actualSecret := kubeAPIClient.Actions()[0].(coretesting.CreateActionImpl).GetObject().(*corev1.Secret)
+
+				for _, key := range []string{"caCertificatePrivateKey", "tlsPrivateKey"} {
+					block, _ := pem.Decode([]byte(actualSecret.StringData[key]))
+					r.NotNil(block, key)
+					privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+					if err != nil {
+						privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+					}
+					r.NoError(err, key)
+					r.IsType(&rsa.PrivateKey{}, privateKey, key)
+					r.Equal(2048, privateKey.(*rsa.PrivateKey).N.BitLen(), key)
+				}
+
+				validCert := testutil.ValidateServerCertificate(t, actualSecret.StringData["caCertificate"], actualSecret.StringData["tlsCertificateChain"])
+				validCert.RequireMatchesPrivateKey(actualSecret.StringData["tlsPrivateKey"])
+			})
+
+			it("creates the CA with the configured signer and does not store a CA private key", func() {
+				signer, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+				r.NoError(err)
+				caSigner = signer
+				startInformersAndController("")
+				err = controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.Len(kubeAPIClient.Actions(), 1)
+				actualSecret := kubeAPIClient.Actions()[0].(coretesting.CreateActionImpl).GetObject().(*corev1.Secret)
+				r.Len(actualSecret.StringData, 1)
+
+				actualCACert := actualSecret.StringData["caCertificate"]
+				validCACert := testutil.ValidateServerCertificate(t, actualCACert, actualCACert)
+				validCACert.RequireLifetime(time.Now(), time.Now().Add(certDuration), 6*time.Minute)
+
+				block, _ := pem.Decode([]byte(actualCACert))
+				r.NotNil(block)
+				caCert, err := x509.ParseCertificate(block.Bytes)
+				r.NoError(err)
+				r.Equal(&signer.PublicKey, caCert.PublicKey)
+			})
+
 			it("creates the CA but not service when the service name is empty", func() {
 				startInformersAndController("")
 				err := controllerlib.TestSync(t, subject, *syncContext)
@@ -0,0 +1,158 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apicerts
+
+import (
+	"crypto"
+	"fmt"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/internal/certauthority"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+type caRotatorController struct {
+	namespace               string
+	certsSecretResourceName string
+	k8sClient               kubernetes.Interface
+	secretInformer          corev1informers.SecretInformer
+
+	// renewBefore is the amount of time after the CA's issuance where
+	// this controller will rotate it.
+	renewBefore time.Duration
+
+	// certDuration, keyAlgorithm, caSigner and generatedCACommonName are used to create the new CA, just like
+	// certsManagerController creates the original CA.
+	certDuration          time.Duration
+	keyAlgorithm          certauthority.KeyAlgorithm
+	caSigner              crypto.Signer
+	generatedCACommonName string
+
+	clock  clock.Clock
+	logger plog.Logger
+}
+
+// NewCARotatorController returns a controllerlib.Controller that will replace the CA in a secret which was
+// created by a certsManagerController once the CA gets older than renewBefore, or expires. Unlike
+// NewCertsExpirerController, it does not delete the secret. Instead, it updates the secret in place and keeps
+// the previous CA certificate in the secret at PreviousCACertificateSecretKey until the next rotation, so that the
+// certificates which were signed by the previous CA can still be trusted until they expire.
+func NewCARotatorController(
+	namespace string,
+	certsSecretResourceName string,
+	k8sClient kubernetes.Interface,
+	secretInformer corev1informers.SecretInformer,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+	renewBefore time.Duration,
+	certDuration time.Duration,
+	keyAlgorithm certauthority.KeyAlgorithm,
+	caSigner crypto.Signer,
+	generatedCACommonName string,
+	clock clock.Clock,
+	logger plog.Logger,
+) controllerlib.Controller {
+	const name = "ca-rotator-controller"
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: name,
+			Syncer: &caRotatorController{
+				namespace:               namespace,
+				certsSecretResourceName: certsSecretResourceName,
+				k8sClient:               k8sClient,
+				secretInformer:          secretInformer,
+				renewBefore:             renewBefore,
+				certDuration:            certDuration,
+				keyAlgorithm:            keyAlgorithm,
+				caSigner:                caSigner,
+				generatedCACommonName:   generatedCACommonName,
+				clock:                   clock,
+				logger:                  logger.WithName(name),
+			},
+		},
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.NameAndNamespaceExactMatchFilterFactory(certsSecretResourceName, namespace),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controller.Syncer.Sync.
+func (c *caRotatorController) Sync(ctx controllerlib.Context) error {
+	secret, err := c.secretInformer.Lister().Secrets(c.namespace).Get(c.certsSecretResourceName)
+	notFound := k8serrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return fmt.Errorf("failed to get %s/%s secret: %w", c.namespace, c.certsSecretResourceName, err)
+	}
+	if notFound {
+		c.logger.Debug("secret does not exist yet or was deleted",
+			"namespace", c.namespace,
+			"name", c.certsSecretResourceName,
+		)
+		return nil
+	}
+
+	caCert, err := getCert(secret, CACertificateSecretKey)
+	if err != nil {
+		return fmt.Errorf("failed to get CA certificate from secret %q with key %q: %w", secret.Name, CACertificateSecretKey, err)
+	}
+
+	now := c.clock.Now()
+	rotateAt := caCert.NotBefore.Add(c.renewBefore)
+	if now.Before(rotateAt) && now.Before(caCert.NotAfter) {
+		// Check again when it is time to rotate the CA, since nothing else would cause this controller to sync then.
+		ctx.Queue.AddAfter(ctx.Key, rotateAt.Sub(now))
+		return nil
+	}
+
+	caOpts := []certauthority.Option{certauthority.WithKeyAlgorithm(c.keyAlgorithm)}
+	if c.caSigner != nil {
+		caOpts = append(caOpts, certauthority.WithSigner(c.caSigner))
+	}
+	ca, err := certauthority.New(c.generatedCACommonName, c.certDuration, caOpts...)
+	if err != nil {
+		return fmt.Errorf("could not initialize CA: %w", err)
+	}
+
+	updatedSecret := secret.DeepCopy()
+	updatedSecret.Data[CACertificateSecretKey] = ca.Bundle()
+
+	// The private key of an external signer never leaves the signer.
+	if c.caSigner == nil {
+		caPrivateKeyPEM, err := ca.PrivateKeyToPEM()
+		if err != nil {
+			return fmt.Errorf("could not get CA private key: %w", err)
+		}
+		updatedSecret.Data[CACertificatePrivateKeySecretKey] = caPrivateKeyPEM
+	}
+
+	// Keep trusting the previous CA, unless it has already expired.
+	if now.Before(caCert.NotAfter) {
+		updatedSecret.Data[PreviousCACertificateSecretKey] = secret.Data[CACertificateSecretKey]
+	} else {
+		delete(updatedSecret.Data, PreviousCACertificateSecretKey)
+	}
+
+	// The update fails when the secret was changed in the meantime, e.g. by another rotation,
+	// so that the previous CA is never lost. Return the error to try again.
+	if _, err := c.k8sClient.CoreV1().Secrets(c.namespace).Update(ctx.Context, updatedSecret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("could not update secret: %w", err)
+	}
+
+	c.logger.Info("rotated CA",
+		"namespace", c.namespace,
+		"name", c.certsSecretResourceName,
+		"previousNotBefore", caCert.NotBefore.String(),
+		"previousNotAfter", caCert.NotAfter.String(),
+	)
+	return nil
+}
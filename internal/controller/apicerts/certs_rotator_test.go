@@ -0,0 +1,231 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apicerts
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+func TestCARotatorControllerSync(t *testing.T) {
+	t.Parallel()
+
+	const (
+		namespace   = "some-namespace"
+		secretName  = "some-secret"
+		renewBefore = 9 * time.Hour
+		duration    = 12 * time.Hour
+	)
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	newCASecret := func(t *testing.T, caOpts ...certauthority.Option) (*corev1.Secret, *x509.Certificate) {
+		t.Helper()
+
+		ca, err := certauthority.New("some-ca", duration, caOpts...)
+		require.NoError(t, err)
+		caCert, err := getCert(&corev1.Secret{Data: map[string][]byte{"ca": ca.Bundle()}}, "ca")
+		require.NoError(t, err)
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace, Labels: map[string]string{"some": "label"}},
+			Data: map[string][]byte{
+				CACertificateSecretKey:         ca.Bundle(),
+				PreviousCACertificateSecretKey: []byte("some-older-ca"),
+			},
+		}
+		if len(caOpts) == 0 {
+			caPrivateKeyPEM, err := ca.PrivateKeyToPEM()
+			require.NoError(t, err)
+			secret.Data[CACertificatePrivateKeySecretKey] = caPrivateKeyPEM
+		}
+		return secret, caCert
+	}
+
+	tests := []struct {
+		name             string
+		caSigner         crypto.Signer
+		noSecret         bool
+		invalidSecret    bool
+		age              func(caCert *x509.Certificate) time.Duration
+		updateErr        error
+		wantError        string
+		wantRequeueAfter time.Duration
+		wantRotated      bool
+		wantPrevious     bool
+	}{
+		{
+			name:     "secret does not exist",
+			noSecret: true,
+		},
+		{
+			name:          "secret does not contain a CA",
+			invalidSecret: true,
+			wantError:     `failed to get CA certificate from secret "some-secret" with key "caCertificate": failed to find certificate`,
+		},
+		{
+			name:             "CA is not old enough to rotate",
+			age:              func(_ *x509.Certificate) time.Duration { return renewBefore - time.Hour },
+			wantRequeueAfter: time.Hour,
+		},
+		{
+			name:         "CA is old enough to rotate",
+			age:          func(_ *x509.Certificate) time.Duration { return renewBefore },
+			wantRotated:  true,
+			wantPrevious: true,
+		},
+		{
+			name:         "CA is old enough to rotate and is signed by an external signer",
+			caSigner:     signer,
+			age:          func(_ *x509.Certificate) time.Duration { return renewBefore + time.Minute },
+			wantRotated:  true,
+			wantPrevious: true,
+		},
+		{
+			name: "CA has expired",
+			age: func(caCert *x509.Certificate) time.Duration {
+				return caCert.NotAfter.Sub(caCert.NotBefore) + time.Second
+			},
+			wantRotated: true,
+		},
+		{
+			name:      "update fails",
+			age:       func(_ *x509.Certificate) time.Duration { return renewBefore },
+			updateErr: errors.New("some update error"),
+			wantError: "could not update secret: some update error",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var caOpts []certauthority.Option
+			if tt.caSigner != nil {
+				caOpts = append(caOpts, certauthority.WithSigner(tt.caSigner))
+			}
+			secret, caCert := newCASecret(t, caOpts...)
+			if tt.invalidSecret {
+				delete(secret.Data, CACertificateSecretKey)
+			}
+
+			var objects []runtime.Object
+			if !tt.noSecret {
+				objects = append(objects, secret)
+			}
+			kubeAPIClient := kubernetesfake.NewSimpleClientset(objects...)
+			if tt.updateErr != nil {
+				kubeAPIClient.PrependReactor("update", "secrets", func(_ kubetesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.updateErr
+				})
+			}
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(objects...), 0)
+
+			now := caCert.NotBefore
+			if tt.age != nil {
+				now = now.Add(tt.age(caCert))
+			}
+
+			subject := NewCARotatorController(
+				namespace,
+				secretName,
+				kubeAPIClient,
+				kubeInformers.Core().V1().Secrets(),
+				controllerlib.WithInformer,
+				renewBefore,
+				duration,
+				certauthority.KeyAlgorithmECDSAP256,
+				tt.caSigner,
+				"some-ca",
+				clocktesting.NewFakeClock(now),
+				plog.TestLogger(t, io.Discard),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			queue := &fakeAddAfterQueue{t: t}
+			err := controllerlib.TestSync(t, subject, controllerlib.Context{Context: ctx, Name: subject.Name(), Queue: queue})
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRequeueAfter, queue.duration)
+
+			if !tt.wantRotated {
+				for _, action := range kubeAPIClient.Actions() {
+					require.NotEqual(t, "update", action.GetVerb())
+				}
+				return
+			}
+
+			actual, err := kubeAPIClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, secret.Labels, actual.Labels)
+
+			newCACert, err := getCert(actual, CACertificateSecretKey)
+			require.NoError(t, err)
+			require.NotEqual(t, caCert.Raw, newCACert.Raw)
+			require.True(t, newCACert.IsCA)
+			require.Equal(t, "some-ca", newCACert.Subject.CommonName)
+
+			if tt.caSigner != nil {
+				require.NotContains(t, actual.Data, CACertificatePrivateKeySecretKey)
+				_, err = certauthority.Load(string(actual.Data[CACertificateSecretKey]), "", certauthority.WithSigner(tt.caSigner))
+			} else {
+				require.NotEqual(t, secret.Data[CACertificatePrivateKeySecretKey], actual.Data[CACertificatePrivateKeySecretKey])
+				_, err = certauthority.Load(string(actual.Data[CACertificateSecretKey]), string(actual.Data[CACertificatePrivateKeySecretKey]))
+			}
+			require.NoError(t, err)
+
+			if tt.wantPrevious {
+				require.Equal(t, secret.Data[CACertificateSecretKey], actual.Data[PreviousCACertificateSecretKey])
+			} else {
+				require.NotContains(t, actual.Data, PreviousCACertificateSecretKey)
+			}
+		})
+	}
+}
+
+type fakeAddAfterQueue struct {
+	t *testing.T
+
+	duration time.Duration
+
+	controllerlib.Queue // panic if any other methods called
+}
+
+func (q *fakeAddAfterQueue) AddAfter(_ controllerlib.Key, duration time.Duration) {
+	q.t.Helper()
+
+	require.Zero(q.t, q.duration, "AddAfter should only be called once")
+
+	q.duration = duration
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apicerts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+type crdConversionUpdaterController struct {
+	namespace               string
+	certsSecretResourceName string
+	apiExtensionsClient     apiextensionsclient.Interface
+	secretInformer          corev1informers.SecretInformer
+	crdName                 string
+}
+
+// NewCRDConversionUpdaterController returns a controller which keeps the CA bundle of the conversion webhook of the
+// CustomResourceDefinition up to date with the CA of the aggregated API serving certificate, which also serves the
+// conversion webhook.
+func NewCRDConversionUpdaterController(
+	namespace string,
+	certsSecretResourceName string,
+	crdName string,
+	apiExtensionsClient apiextensionsclient.Interface,
+	secretInformer corev1informers.SecretInformer,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "crd-conversion-updater-controller",
+			Syncer: &crdConversionUpdaterController{
+				namespace:               namespace,
+				certsSecretResourceName: certsSecretResourceName,
+				apiExtensionsClient:     apiExtensionsClient,
+				secretInformer:          secretInformer,
+				crdName:                 crdName,
+			},
+		},
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.NameAndNamespaceExactMatchFilterFactory(certsSecretResourceName, namespace),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+func (c *crdConversionUpdaterController) Sync(ctx controllerlib.Context) error {
+	// Try to get the secret from the informer cache.
+	certSecret, err := c.secretInformer.Lister().Secrets(c.namespace).Get(c.certsSecretResourceName)
+	notFound := k8serrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return fmt.Errorf("failed to get %s/%s secret: %w", c.namespace, c.certsSecretResourceName, err)
+	}
+	if notFound {
+		// The secret does not exist yet, so nothing to do.
+		plog.Info("crdConversionUpdaterController Sync found that the secret does not exist yet or was deleted")
+		return nil
+	}
+
+	// Update the CustomResourceDefinition to give its conversion webhook the new CA bundle.
+	if err := UpdateCRDConversionWebhook(ctx.Context, c.apiExtensionsClient, c.crdName, c.namespace, certSecret.Data[CACertificateSecretKey]); err != nil {
+		return fmt.Errorf("could not update the custom resource definition: %w", err)
+	}
+
+	plog.Debug("crdConversionUpdaterController Sync complete")
+	return nil
+}
+
+// UpdateCRDConversionWebhook updates the CA bundle of the conversion webhook of the CustomResourceDefinition when the
+// webhook calls a Service in the given namespace.
+func UpdateCRDConversionWebhook(ctx context.Context, apiExtensionsClient apiextensionsclient.Interface, crdName, serviceNamespace string, caBundle []byte) error {
+	crds := apiExtensionsClient.ApiextensionsV1().CustomResourceDefinitions()
+
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fetchedCRD, err := crds.Get(ctx, crdName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("could not get existing version of custom resource definition: %w", err)
+		}
+
+		conversion := fetchedCRD.Spec.Conversion
+		if conversion == nil || conversion.Strategy != apiextensionsv1.WebhookConverter ||
+			conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil ||
+			conversion.Webhook.ClientConfig.Service == nil || conversion.Webhook.ClientConfig.Service.Namespace != serviceNamespace {
+			// we do not own this conversion webhook so do not attempt to mutate it
+			return nil
+		}
+
+		if bytes.Equal(conversion.Webhook.ClientConfig.CABundle, caBundle) {
+			// Already has the same value, perhaps because another process already updated the object, so no need to update.
+			return nil
+		}
+
+		// Update just the field we care about.
+		conversion.Webhook.ClientConfig.CABundle = caBundle
+
+		_, updateErr := crds.Update(ctx, fetchedCRD, metav1.UpdateOptions{})
+		return updateErr
+	}); err != nil {
+		return fmt.Errorf("could not update custom resource definition: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apicerts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/controllerlib"
+)
+
+func TestCRDConversionUpdaterControllerSync(t *testing.T) {
+	const (
+		installedInNamespace    = "some-namespace"
+		certsSecretResourceName = "some-resource-name"
+		crdName                 = "widgets.example.com"
+	)
+
+	servingCertSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: certsSecretResourceName, Namespace: installedInNamespace},
+		Data:       map[string][]byte{"caCertificate": []byte("fake CA cert")},
+	}
+
+	newCRD := func(serviceNamespace string, caBundle []byte) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: crdName},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Conversion: &apiextensionsv1.CustomResourceConversion{
+					Strategy: apiextensionsv1.WebhookConverter,
+					Webhook: &apiextensionsv1.WebhookConversion{
+						ClientConfig: &apiextensionsv1.WebhookClientConfig{
+							Service:  &apiextensionsv1.ServiceReference{Namespace: serviceNamespace, Name: "some-service"},
+							CABundle: caBundle,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		secrets        []runtime.Object
+		crds           []runtime.Object
+		wantErr        string
+		wantUpdate     bool
+		wantCABundle   []byte
+		wantNoKubeCall bool
+	}{
+		{
+			name:           "there is no serving cert Secret yet",
+			crds:           []runtime.Object{newCRD(installedInNamespace, nil)},
+			wantNoKubeCall: true,
+		},
+		{
+			name:         "the conversion webhook has no CA bundle yet",
+			secrets:      []runtime.Object{servingCertSecret},
+			crds:         []runtime.Object{newCRD(installedInNamespace, nil)},
+			wantUpdate:   true,
+			wantCABundle: []byte("fake CA cert"),
+		},
+		{
+			name:         "the conversion webhook has an old CA bundle",
+			secrets:      []runtime.Object{servingCertSecret},
+			crds:         []runtime.Object{newCRD(installedInNamespace, []byte("old CA cert"))},
+			wantUpdate:   true,
+			wantCABundle: []byte("fake CA cert"),
+		},
+		{
+			name:         "the conversion webhook already has the CA bundle",
+			secrets:      []runtime.Object{servingCertSecret},
+			crds:         []runtime.Object{newCRD(installedInNamespace, []byte("fake CA cert"))},
+			wantCABundle: []byte("fake CA cert"),
+		},
+		{
+			name:         "the conversion webhook calls a service in another namespace",
+			secrets:      []runtime.Object{servingCertSecret},
+			crds:         []runtime.Object{newCRD("other-namespace", []byte("other CA cert"))},
+			wantCABundle: []byte("other CA cert"),
+		},
+		{
+			name:    "the custom resource definition does not exist",
+			secrets: []runtime.Object{servingCertSecret},
+			wantErr: `could not update the custom resource definition: could not update custom resource definition: ` +
+				`could not get existing version of custom resource definition: ` +
+				`customresourcedefinitions.apiextensions.k8s.io "widgets.example.com" not found`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(tt.secrets...), 0)
+			apiExtensionsClient := apiextensionsfake.NewSimpleClientset(tt.crds...)
+
+			subject := NewCRDConversionUpdaterController(
+				installedInNamespace,
+				certsSecretResourceName,
+				crdName,
+				apiExtensionsClient,
+				kubeInformers.Core().V1().Secrets(),
+				controllerlib.WithInformer,
+			)
+
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			err := controllerlib.TestSync(t, subject, controllerlib.Context{
+				Context: ctx,
+				Name:    subject.Name(),
+				Key:     controllerlib.Key{Namespace: installedInNamespace, Name: certsSecretResourceName},
+			})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantNoKubeCall {
+				require.Empty(t, apiExtensionsClient.Actions())
+				return
+			}
+
+			updated := false
+			for _, action := range apiExtensionsClient.Actions() {
+				updated = updated || action.GetVerb() == "update"
+			}
+			require.Equal(t, tt.wantUpdate, updated)
+
+			crd, err := apiExtensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, crdName, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCABundle, crd.Spec.Conversion.Webhook.ClientConfig.CABundle)
+		})
+	}
+}
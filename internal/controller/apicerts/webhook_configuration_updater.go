@@ -0,0 +1,116 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apicerts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+type webhookConfigurationUpdaterController struct {
+	namespace                string
+	certsSecretResourceName  string
+	kubeClient               kubernetes.Interface
+	secretInformer           corev1informers.SecretInformer
+	webhookConfigurationName string
+}
+
+// NewWebhookConfigurationUpdaterController returns a controller which keeps the CA bundle of the webhooks of the
+// ValidatingWebhookConfiguration up to date with the CA of the aggregated API serving certificate, which also serves
+// the admission webhook.
+func NewWebhookConfigurationUpdaterController(
+	namespace string,
+	certsSecretResourceName string,
+	webhookConfigurationName string,
+	kubeClient kubernetes.Interface,
+	secretInformer corev1informers.SecretInformer,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "webhook-configuration-updater-controller",
+			Syncer: &webhookConfigurationUpdaterController{
+				namespace:                namespace,
+				certsSecretResourceName:  certsSecretResourceName,
+				kubeClient:               kubeClient,
+				secretInformer:           secretInformer,
+				webhookConfigurationName: webhookConfigurationName,
+			},
+		},
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.NameAndNamespaceExactMatchFilterFactory(certsSecretResourceName, namespace),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+func (c *webhookConfigurationUpdaterController) Sync(ctx controllerlib.Context) error {
+	// Try to get the secret from the informer cache.
+	certSecret, err := c.secretInformer.Lister().Secrets(c.namespace).Get(c.certsSecretResourceName)
+	notFound := k8serrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return fmt.Errorf("failed to get %s/%s secret: %w", c.namespace, c.certsSecretResourceName, err)
+	}
+	if notFound {
+		// The secret does not exist yet, so nothing to do.
+		plog.Info("webhookConfigurationUpdaterController Sync found that the secret does not exist yet or was deleted")
+		return nil
+	}
+
+	// Update the ValidatingWebhookConfiguration to give it the new CA bundle.
+	if err := UpdateValidatingWebhookConfiguration(ctx.Context, c.kubeClient, c.webhookConfigurationName, c.namespace, certSecret.Data[CACertificateSecretKey]); err != nil {
+		return fmt.Errorf("could not update the validating webhook configuration: %w", err)
+	}
+
+	plog.Debug("webhookConfigurationUpdaterController Sync complete")
+	return nil
+}
+
+// UpdateValidatingWebhookConfiguration updates the CA bundle of the webhooks of the ValidatingWebhookConfiguration
+// which call a Service in the given namespace.
+func UpdateValidatingWebhookConfiguration(ctx context.Context, kubeClient kubernetes.Interface, webhookConfigurationName, serviceNamespace string, caBundle []byte) error {
+	webhookConfigurations := kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fetchedWebhookConfiguration, err := webhookConfigurations.Get(ctx, webhookConfigurationName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("could not get existing version of validating webhook configuration: %w", err)
+		}
+
+		changed := false
+		for i := range fetchedWebhookConfiguration.Webhooks {
+			clientConfig := &fetchedWebhookConfiguration.Webhooks[i].ClientConfig
+			if clientConfig.Service == nil || clientConfig.Service.Namespace != serviceNamespace {
+				// we do not own this webhook so do not attempt to mutate it
+				continue
+			}
+			if !bytes.Equal(clientConfig.CABundle, caBundle) {
+				clientConfig.CABundle = caBundle
+				changed = true
+			}
+		}
+		if !changed {
+			// Already has the same value, perhaps because another process already updated the object, so no need to update.
+			return nil
+		}
+
+		_, updateErr := webhookConfigurations.Update(ctx, fetchedWebhookConfiguration, metav1.UpdateOptions{})
+		return updateErr
+	}); err != nil {
+		return fmt.Errorf("could not update validating webhook configuration: %w", err)
+	}
+	return nil
+}
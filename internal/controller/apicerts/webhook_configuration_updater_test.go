@@ -0,0 +1,149 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apicerts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/controllerlib"
+)
+
+func TestWebhookConfigurationUpdaterControllerSync(t *testing.T) {
+	const (
+		installedInNamespace     = "some-namespace"
+		certsSecretResourceName  = "some-resource-name"
+		webhookConfigurationName = "some-webhook-configuration"
+	)
+
+	servingCertSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: certsSecretResourceName, Namespace: installedInNamespace},
+		Data:       map[string][]byte{"caCertificate": []byte("fake CA cert")},
+	}
+
+	newWebhookConfiguration := func(caBundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+		return &admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: webhookConfigurationName},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{
+					Name: "our-webhook",
+					ClientConfig: admissionregistrationv1.WebhookClientConfig{
+						Service:  &admissionregistrationv1.ServiceReference{Namespace: installedInNamespace, Name: "some-service"},
+						CABundle: caBundle,
+					},
+				},
+				{
+					Name: "someone-elses-webhook",
+					ClientConfig: admissionregistrationv1.WebhookClientConfig{
+						Service:  &admissionregistrationv1.ServiceReference{Namespace: "other-namespace", Name: "other-service"},
+						CABundle: []byte("other CA cert"),
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		secrets        []runtime.Object
+		kubeObjects    []runtime.Object
+		wantErr        string
+		wantUpdate     bool
+		wantCABundles  [][]byte
+		wantNoKubeCall bool
+	}{
+		{
+			name:           "there is no serving cert Secret yet",
+			kubeObjects:    []runtime.Object{newWebhookConfiguration(nil)},
+			wantNoKubeCall: true,
+		},
+		{
+			name:          "the webhook configuration has no CA bundle yet",
+			secrets:       []runtime.Object{servingCertSecret},
+			kubeObjects:   []runtime.Object{newWebhookConfiguration(nil)},
+			wantUpdate:    true,
+			wantCABundles: [][]byte{[]byte("fake CA cert"), []byte("other CA cert")},
+		},
+		{
+			name:          "the webhook configuration has an old CA bundle",
+			secrets:       []runtime.Object{servingCertSecret},
+			kubeObjects:   []runtime.Object{newWebhookConfiguration([]byte("old CA cert"))},
+			wantUpdate:    true,
+			wantCABundles: [][]byte{[]byte("fake CA cert"), []byte("other CA cert")},
+		},
+		{
+			name:          "the webhook configuration already has the CA bundle",
+			secrets:       []runtime.Object{servingCertSecret},
+			kubeObjects:   []runtime.Object{newWebhookConfiguration([]byte("fake CA cert"))},
+			wantCABundles: [][]byte{[]byte("fake CA cert"), []byte("other CA cert")},
+		},
+		{
+			name:    "the webhook configuration does not exist",
+			secrets: []runtime.Object{servingCertSecret},
+			wantErr: `could not update the validating webhook configuration: could not update validating webhook configuration: ` +
+				`could not get existing version of validating webhook configuration: ` +
+				`validatingwebhookconfigurations.admissionregistration.k8s.io "some-webhook-configuration" not found`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(tt.secrets...), 0)
+			kubeClient := kubernetesfake.NewSimpleClientset(tt.kubeObjects...)
+
+			subject := NewWebhookConfigurationUpdaterController(
+				installedInNamespace,
+				certsSecretResourceName,
+				webhookConfigurationName,
+				kubeClient,
+				kubeInformers.Core().V1().Secrets(),
+				controllerlib.WithInformer,
+			)
+
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			err := controllerlib.TestSync(t, subject, controllerlib.Context{
+				Context: ctx,
+				Name:    subject.Name(),
+				Key:     controllerlib.Key{Namespace: installedInNamespace, Name: certsSecretResourceName},
+			})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantNoKubeCall {
+				require.Empty(t, kubeClient.Actions())
+				return
+			}
+
+			updated := false
+			for _, action := range kubeClient.Actions() {
+				updated = updated || action.GetVerb() == "update"
+			}
+			require.Equal(t, tt.wantUpdate, updated)
+
+			webhookConfiguration, err := kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, webhookConfigurationName, metav1.GetOptions{})
+			require.NoError(t, err)
+			var caBundles [][]byte
+			for _, webhook := range webhookConfiguration.Webhooks {
+				caBundles = append(caBundles, webhook.ClientConfig.CABundle)
+			}
+			require.Equal(t, tt.wantCABundles, caBundles)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhookcachefiller
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	k8sauthv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+)
+
+// credentialsSecretTokenKey is the key of the bearer token in the credentials Secret of a WebhookAuthenticator.
+// It is the same key as in Secrets of type kubernetes.io/service-account-token.
+const credentialsSecretTokenKey = "token"
+
+// serviceAccountTokenAuthenticator validates projected service account tokens of another cluster using the
+// TokenReview API of that cluster, and maps the service accounts of the configured bots to their identities.
+type serviceAccountTokenAuthenticator struct {
+	tokenReview authenticator.Token
+	audiences   authenticator.Audiences
+	bots        map[string]*user.DefaultInfo // keyed by the username of the service account
+}
+
+var _ authenticator.Token = (*serviceAccountTokenAuthenticator)(nil)
+
+func (c *controller) newServiceAccountTokenAuthenticator(
+	ctx context.Context,
+	spec *auth1alpha1.WebhookAuthenticatorSpec,
+) (*serviceAccountTokenAuthenticator, error) {
+	// The credentials are not one of the Concierge's own Secrets, so they are not in its informer caches.
+	// They are read again every time that the WebhookAuthenticator is synced, which picks up rotated credentials.
+	secretName := spec.ServiceAccountTokens.CredentialsSecretName
+	secret, err := c.client.CoreV1().Secrets(c.namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials secret %s/%s: %w", c.namespace, secretName, err)
+	}
+	token := secret.Data[credentialsSecretTokenKey]
+	if len(token) == 0 {
+		return nil, fmt.Errorf("credentials secret %s/%s has no %q key", c.namespace, secretName, credentialsSecretTokenKey)
+	}
+
+	return newServiceAccountTokenAuthenticator(spec, string(token), os.CreateTemp, clientcmd.WriteToFile)
+}
+
+func newServiceAccountTokenAuthenticator(
+	spec *auth1alpha1.WebhookAuthenticatorSpec,
+	token string,
+	tempfileFunc func(string, string) (*os.File, error),
+	marshalFunc func(clientcmdapi.Config, string) error,
+) (*serviceAccountTokenAuthenticator, error) {
+	bots := make(map[string]*user.DefaultInfo, len(spec.ServiceAccountTokens.Bots))
+	for i, bot := range spec.ServiceAccountTokens.Bots {
+		username := serviceaccount.MakeUsername(bot.Namespace, bot.Name)
+		if _, ok := bots[username]; ok {
+			return nil, fmt.Errorf("bots[%d] has the same service account as another bot: %s/%s", i, bot.Namespace, bot.Name)
+		}
+		bots[username] = &user.DefaultInfo{Name: bot.Username, Groups: bot.Groups}
+	}
+
+	// Service account tokens are always validated by the Kubernetes API server, so we can use v1.
+	// There are no implicit audiences, so tokens are only accepted when the TokenReview confirms the audience.
+	tokenReview, err := newTokenReviewWebhook(spec, &clientcmdapi.AuthInfo{Token: token}, k8sauthv1.SchemeGroupVersion.Version, nil, tempfileFunc, marshalFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &serviceAccountTokenAuthenticator{
+		tokenReview: tokenReview,
+		audiences:   authenticator.Audiences{spec.ServiceAccountTokens.Audience},
+		bots:        bots,
+	}, nil
+}
+
+func (a *serviceAccountTokenAuthenticator) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+	// Ask the TokenReview API to check that the token is bound to our audience, instead of any audiences of the request.
+	response, authenticated, err := a.tokenReview.AuthenticateToken(authenticator.WithAudiences(ctx, a.audiences), token)
+	if err != nil || !authenticated {
+		return nil, false, err
+	}
+
+	bot, ok := a.bots[response.User.GetName()]
+	if !ok {
+		// The token is valid, but it does not belong to the service account of any bot.
+		return nil, false, nil
+	}
+
+	return &authenticator.Response{
+		User: &user.DefaultInfo{
+			Name:   bot.Name,
+			Groups: append([]string(nil), bot.Groups...),
+		},
+	}, true, nil
+}
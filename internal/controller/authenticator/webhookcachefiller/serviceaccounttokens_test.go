@@ -0,0 +1,180 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhookcachefiller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	k8sauthv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/tools/clientcmd"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func serviceAccountTokensSpec(credentialsSecretName string) *auth1alpha1.WebhookAuthenticatorServiceAccountTokens {
+	return &auth1alpha1.WebhookAuthenticatorServiceAccountTokens{
+		Audience:              "ci.pinniped.example.com",
+		CredentialsSecretName: credentialsSecretName,
+		Bots: []auth1alpha1.WebhookAuthenticatorServiceAccountBot{
+			{Namespace: "ci", Name: "deployer", Username: "ci-deployer", Groups: []string{"ci-bots", "deployers"}},
+			{Namespace: "ci", Name: "tester", Username: "ci-tester"},
+		},
+	}
+}
+
+func TestServiceAccountTokenAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	// A fake TokenReview API which only knows the tokens of some service accounts, and which returns
+	// the requested audiences only when they are "ci.pinniped.example.com".
+	serviceAccountsByToken := map[string]string{
+		"deployer-token": "system:serviceaccount:ci:deployer",
+		"tester-token":   "system:serviceaccount:ci:tester",
+		"other-token":    "system:serviceaccount:ci:other",
+	}
+	caBundle, url := testutil.TLSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/apis/authentication.k8s.io/v1/tokenreviews", r.URL.Path)
+		require.Equal(t, "Bearer some-credentials-token", r.Header.Get("Authorization"))
+
+		var review k8sauthv1.TokenReview
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+		require.Equal(t, []string{"ci.pinniped.example.com"}, review.Spec.Audiences)
+
+		if username, ok := serviceAccountsByToken[review.Spec.Token]; ok {
+			review.Status = k8sauthv1.TokenReviewStatus{
+				Authenticated: true,
+				User: k8sauthv1.UserInfo{
+					Username: username,
+					UID:      "some-uid",
+					Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:ci", "system:authenticated"},
+					Extra:    map[string]k8sauthv1.ExtraValue{"authentication.kubernetes.io/pod-name": {"some-pod"}},
+				},
+				Audiences: review.Spec.Audiences,
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(&review))
+	})
+
+	spec := &auth1alpha1.WebhookAuthenticatorSpec{
+		Endpoint:             url + "/apis/authentication.k8s.io/v1/tokenreviews",
+		TLS:                  &auth1alpha1.TLSSpec{CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte(caBundle))},
+		ServiceAccountTokens: serviceAccountTokensSpec("some-secret"),
+	}
+	subject, err := newServiceAccountTokenAuthenticator(spec, "some-credentials-token", os.CreateTemp, clientcmd.WriteToFile)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		token    string
+		wantUser user.Info
+	}{
+		{
+			name:     "token of a bot with groups",
+			ctx:      context.Background(),
+			token:    "deployer-token",
+			wantUser: &user.DefaultInfo{Name: "ci-deployer", Groups: []string{"ci-bots", "deployers"}},
+		},
+		{
+			name:     "token of a bot without groups",
+			ctx:      context.Background(),
+			token:    "tester-token",
+			wantUser: &user.DefaultInfo{Name: "ci-tester"},
+		},
+		{
+			name:     "audiences of the request are replaced by the configured audience",
+			ctx:      authenticator.WithAudiences(context.Background(), authenticator.Audiences{"some-other-audience"}),
+			token:    "deployer-token",
+			wantUser: &user.DefaultInfo{Name: "ci-deployer", Groups: []string{"ci-bots", "deployers"}},
+		},
+		{
+			name:  "token of a service account which is not a bot",
+			ctx:   context.Background(),
+			token: "other-token",
+		},
+		{
+			name:  "invalid token",
+			ctx:   context.Background(),
+			token: "invalid-token",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, authenticated, err := subject.AuthenticateToken(tt.ctx, tt.token)
+			require.NoError(t, err)
+			if tt.wantUser == nil {
+				require.False(t, authenticated)
+				require.Nil(t, resp)
+				return
+			}
+			require.True(t, authenticated)
+			require.Equal(t, tt.wantUser, resp.User)
+		})
+	}
+}
+
+func TestServiceAccountTokenAuthenticatorWrongAudience(t *testing.T) {
+	t.Parallel()
+
+	// A TokenReview API which validates the token, but does not confirm the requested audience.
+	caBundle, url := testutil.TLSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var review k8sauthv1.TokenReview
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+		review.Status = k8sauthv1.TokenReviewStatus{
+			Authenticated: true,
+			User:          k8sauthv1.UserInfo{Username: "system:serviceaccount:ci:deployer"},
+			Audiences:     []string{"https://kubernetes.default.svc"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(&review))
+	})
+
+	subject, err := newServiceAccountTokenAuthenticator(&auth1alpha1.WebhookAuthenticatorSpec{
+		Endpoint:             url,
+		TLS:                  &auth1alpha1.TLSSpec{CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte(caBundle))},
+		ServiceAccountTokens: serviceAccountTokensSpec("some-secret"),
+	}, "some-credentials-token", os.CreateTemp, clientcmd.WriteToFile)
+	require.NoError(t, err)
+
+	resp, authenticated, err := subject.AuthenticateToken(context.Background(), "deployer-token")
+	require.NoError(t, err)
+	require.False(t, authenticated)
+	require.Nil(t, resp)
+}
+
+func TestNewServiceAccountTokenAuthenticator(t *testing.T) {
+	t.Run("duplicate bots", func(t *testing.T) {
+		tokens := serviceAccountTokensSpec("some-secret")
+		tokens.Bots = append(tokens.Bots, auth1alpha1.WebhookAuthenticatorServiceAccountBot{Namespace: "ci", Name: "deployer", Username: "admin"})
+		res, err := newServiceAccountTokenAuthenticator(&auth1alpha1.WebhookAuthenticatorSpec{
+			Endpoint:             "https://example.com",
+			ServiceAccountTokens: tokens,
+		}, "some-credentials-token", os.CreateTemp, clientcmd.WriteToFile)
+		require.Nil(t, res)
+		require.EqualError(t, err, "bots[2] has the same service account as another bot: ci/deployer")
+	})
+
+	t.Run("invalid TLS configuration", func(t *testing.T) {
+		res, err := newServiceAccountTokenAuthenticator(&auth1alpha1.WebhookAuthenticatorSpec{
+			Endpoint:             "https://example.com",
+			TLS:                  &auth1alpha1.TLSSpec{CertificateAuthorityData: "invalid-base64"},
+			ServiceAccountTokens: serviceAccountTokensSpec("some-secret"),
+		}, "some-credentials-token", os.CreateTemp, clientcmd.WriteToFile)
+		require.Nil(t, res)
+		require.EqualError(t, err, "invalid TLS configuration: illegal base64 data at input byte 7")
+	})
+}
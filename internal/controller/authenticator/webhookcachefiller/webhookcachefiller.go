@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package webhookcachefiller implements a controller for filling an authncache.Cache with each added/updated WebhookAuthenticator.
@@ -15,6 +15,7 @@ import (
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	webhookutil "k8s.io/apiserver/pkg/util/webhook"
 	"k8s.io/apiserver/plugin/pkg/authenticator/token/webhook"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2"
@@ -28,14 +29,24 @@ import (
 )
 
 // New instantiates a new controllerlib.Controller which will populate the provided authncache.Cache.
-func New(cache *authncache.Cache, webhooks authinformers.WebhookAuthenticatorInformer, log logr.Logger) controllerlib.Controller {
+// The credentials of WebhookAuthenticators which validate service account tokens are read from Secrets
+// in the provided namespace.
+func New(
+	cache *authncache.Cache,
+	webhooks authinformers.WebhookAuthenticatorInformer,
+	client kubernetes.Interface,
+	namespace string,
+	log logr.Logger,
+) controllerlib.Controller {
 	return controllerlib.New(
 		controllerlib.Config{
 			Name: "webhookcachefiller-controller",
 			Syncer: &controller{
-				cache:    cache,
-				webhooks: webhooks,
-				log:      log.WithName("webhookcachefiller-controller"),
+				cache:     cache,
+				webhooks:  webhooks,
+				client:    client,
+				namespace: namespace,
+				log:       log.WithName("webhookcachefiller-controller"),
 			},
 		},
 		controllerlib.WithInformer(
@@ -47,9 +58,11 @@ func New(cache *authncache.Cache, webhooks authinformers.WebhookAuthenticatorInf
 }
 
 type controller struct {
-	cache    *authncache.Cache
-	webhooks authinformers.WebhookAuthenticatorInformer
-	log      logr.Logger
+	cache     *authncache.Cache
+	webhooks  authinformers.WebhookAuthenticatorInformer
+	client    kubernetes.Interface
+	namespace string
+	log       logr.Logger
 }
 
 // Sync implements controllerlib.Syncer.
@@ -63,7 +76,12 @@ func (c *controller) Sync(ctx controllerlib.Context) error {
 		return fmt.Errorf("failed to get WebhookAuthenticator %s/%s: %w", ctx.Key.Namespace, ctx.Key.Name, err)
 	}
 
-	webhookAuthenticator, err := newWebhookAuthenticator(&obj.Spec, os.CreateTemp, clientcmd.WriteToFile)
+	var webhookAuthenticator authncache.Value
+	if obj.Spec.ServiceAccountTokens != nil {
+		webhookAuthenticator, err = c.newServiceAccountTokenAuthenticator(ctx.Context, &obj.Spec)
+	} else {
+		webhookAuthenticator, err = newWebhookAuthenticator(&obj.Spec, os.CreateTemp, clientcmd.WriteToFile)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to build webhook config: %w", err)
 	}
@@ -83,6 +101,28 @@ func newWebhookAuthenticator(
 	spec *auth1alpha1.WebhookAuthenticatorSpec,
 	tempfileFunc func(string, string) (*os.File, error),
 	marshalFunc func(clientcmdapi.Config, string) error,
+) (*webhook.WebhookTokenAuthenticator, error) {
+	// We use v1beta1 instead of v1 since v1beta1 is more prevalent in our desired
+	// integration points.
+	version := k8sauthv1beta1.SchemeGroupVersion.Version
+
+	// At the current time, we don't provide any audiences because we simply don't
+	// have any requirements to do so. This can be changed in the future as
+	// requirements change.
+	var implicitAuds authenticator.Audiences
+
+	return newTokenReviewWebhook(spec, nil, version, implicitAuds, tempfileFunc, marshalFunc)
+}
+
+// newTokenReviewWebhook creates a webhook which sends TokenReviews of the provided version to the endpoint
+// of the provided spec, authenticating with the provided credentials when they are not nil.
+func newTokenReviewWebhook(
+	spec *auth1alpha1.WebhookAuthenticatorSpec,
+	authInfo *clientcmdapi.AuthInfo,
+	version string,
+	implicitAuds authenticator.Audiences,
+	tempfileFunc func(string, string) (*os.File, error),
+	marshalFunc func(clientcmdapi.Config, string) error,
 ) (*webhook.WebhookTokenAuthenticator, error) {
 	temp, err := tempfileFunc("", "pinniped-webhook-kubeconfig-*")
 	if err != nil {
@@ -100,20 +140,16 @@ func newWebhookAuthenticator(
 	kubeconfig.Clusters["anonymous-cluster"] = cluster
 	kubeconfig.Contexts["anonymous"] = &clientcmdapi.Context{Cluster: "anonymous-cluster"}
 	kubeconfig.CurrentContext = "anonymous"
+	if authInfo != nil {
+		// The endpoint requires credentials, e.g. to create TokenReviews on another cluster.
+		kubeconfig.AuthInfos["credentials"] = authInfo
+		kubeconfig.Contexts["anonymous"].AuthInfo = "credentials"
+	}
 
 	if err := marshalFunc(*kubeconfig, temp.Name()); err != nil {
 		return nil, fmt.Errorf("unable to marshal kubeconfig: %w", err)
 	}
 
-	// We use v1beta1 instead of v1 since v1beta1 is more prevalent in our desired
-	// integration points.
-	version := k8sauthv1beta1.SchemeGroupVersion.Version
-
-	// At the current time, we don't provide any audiences because we simply don't
-	// have any requirements to do so. This can be changed in the future as
-	// requirements change.
-	var implicitAuds authenticator.Audiences
-
 	// We set this to nil because we would only need this to support some of the
 	// custom proxy stuff used by the API server.
 	var customDial net.DialFunc
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package webhookcachefiller
@@ -13,8 +13,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
@@ -34,6 +36,7 @@ func TestController(t *testing.T) {
 		name             string
 		syncKey          controllerlib.Key
 		webhooks         []runtime.Object
+		secrets          []runtime.Object
 		wantErr          string
 		wantLogs         []string
 		wantCacheEntries int
@@ -79,6 +82,69 @@ func TestController(t *testing.T) {
 			},
 			wantCacheEntries: 1,
 		},
+		{
+			name:    "service account tokens with a missing credentials secret",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint:             "https://example.com/apis/authentication.k8s.io/v1/tokenreviews",
+						ServiceAccountTokens: serviceAccountTokensSpec("test-credentials"),
+					},
+				},
+			},
+			wantErr: `failed to build webhook config: failed to get credentials secret concierge/test-credentials: secrets "test-credentials" not found`,
+		},
+		{
+			name:    "service account tokens with a credentials secret which has no token",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint:             "https://example.com/apis/authentication.k8s.io/v1/tokenreviews",
+						ServiceAccountTokens: serviceAccountTokensSpec("test-credentials"),
+					},
+				},
+			},
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-credentials", Namespace: "concierge"},
+					Data:       map[string][]byte{"ca.crt": []byte("some-ca")},
+				},
+			},
+			wantErr: `failed to build webhook config: credentials secret concierge/test-credentials has no "token" key`,
+		},
+		{
+			name:    "valid service account tokens",
+			syncKey: controllerlib.Key{Name: "test-name"},
+			webhooks: []runtime.Object{
+				&auth1alpha1.WebhookAuthenticator{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-name",
+					},
+					Spec: auth1alpha1.WebhookAuthenticatorSpec{
+						Endpoint:             "https://example.com/apis/authentication.k8s.io/v1/tokenreviews",
+						ServiceAccountTokens: serviceAccountTokensSpec("test-credentials"),
+					},
+				},
+			},
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-credentials", Namespace: "concierge"},
+					Data:       map[string][]byte{"token": []byte("some-token")},
+				},
+			},
+			wantLogs: []string{
+				`webhookcachefiller-controller "level"=0 "msg"="added new webhook authenticator" "endpoint"="https://example.com/apis/authentication.k8s.io/v1/tokenreviews" "webhook"={"name":"test-name"}`,
+			},
+			wantCacheEntries: 1,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -90,7 +156,9 @@ func TestController(t *testing.T) {
 			cache := authncache.New()
 			testLog := testlogger.NewLegacy(t) //nolint:staticcheck  // old test with lots of log statements
 
-			controller := New(cache, informers.Authentication().V1alpha1().WebhookAuthenticators(), testLog.Logger)
+			kubeClient := kubefake.NewSimpleClientset(tt.secrets...)
+
+			controller := New(cache, informers.Authentication().V1alpha1().WebhookAuthenticators(), kubeClient, "concierge", testLog.Logger)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
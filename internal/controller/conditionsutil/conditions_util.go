@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package conditionsutil
@@ -6,25 +6,27 @@ package conditionsutil
 import (
 	"sort"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/events"
 
-	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
-	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	"go.pinniped.dev/internal/plog"
 )
 
-// MergeIDPConditions merges conditions into conditionsToUpdate. If returns true if it merged any error conditions.
-func MergeIDPConditions(conditions []*idpv1alpha1.Condition, observedGeneration int64, conditionsToUpdate *[]idpv1alpha1.Condition, log plog.MinLogger) bool {
+// MergeConditions merges conditions into conditionsToUpdate. It returns true if it merged any error conditions.
+func MergeConditions(conditions []*metav1.Condition, observedGeneration int64, conditionsToUpdate *[]metav1.Condition, log plog.MinLogger) bool {
 	hadErrorCondition := false
 	for i := range conditions {
 		cond := conditions[i].DeepCopy()
-		cond.LastTransitionTime = v1.Now()
+		cond.LastTransitionTime = metav1.Now()
 		cond.ObservedGeneration = observedGeneration
-		if mergeIDPCondition(conditionsToUpdate, cond) {
+		if mergeCondition(conditionsToUpdate, cond) {
 			log.Info("updated condition", "type", cond.Type, "status", cond.Status, "reason", cond.Reason, "message", cond.Message)
 		}
-		if cond.Status == idpv1alpha1.ConditionFalse {
+		if cond.Status == metav1.ConditionFalse {
 			hadErrorCondition = true
 		}
 	}
@@ -34,17 +36,11 @@ func MergeIDPConditions(conditions []*idpv1alpha1.Condition, observedGeneration
 	return hadErrorCondition
 }
 
-// mergeIDPCondition merges a new idpv1alpha1.Condition into a slice of existing conditions. It returns true
+// mergeCondition merges a new metav1.Condition into a slice of existing conditions. It returns true
 // if the condition has meaningfully changed.
-func mergeIDPCondition(existing *[]idpv1alpha1.Condition, new *idpv1alpha1.Condition) bool {
+func mergeCondition(existing *[]metav1.Condition, new *metav1.Condition) bool {
 	// Find any existing condition with a matching type.
-	var old *idpv1alpha1.Condition
-	for i := range *existing {
-		if (*existing)[i].Type == new.Type {
-			old = &(*existing)[i]
-			continue
-		}
-	}
+	old := meta.FindStatusCondition(*existing, new.Type)
 
 	// If there is no existing condition of this type, append this one and we're done.
 	if old == nil {
@@ -68,56 +64,46 @@ func mergeIDPCondition(existing *[]idpv1alpha1.Condition, new *idpv1alpha1.Condi
 	return false
 }
 
-// MergeConfigConditions merges conditions into conditionsToUpdate. If returns true if it merged any error conditions.
-func MergeConfigConditions(conditions []*configv1alpha1.Condition, observedGeneration int64, conditionsToUpdate *[]configv1alpha1.Condition, log plog.MinLogger) bool {
-	hadErrorCondition := false
-	for i := range conditions {
-		cond := conditions[i].DeepCopy()
-		cond.LastTransitionTime = v1.Now()
-		cond.ObservedGeneration = observedGeneration
-		if mergeConfigCondition(conditionsToUpdate, cond) {
-			log.Info("updated condition", "type", cond.Type, "status", cond.Status, "reason", cond.Reason, "message", cond.Message)
-		}
-		if cond.Status == configv1alpha1.ConditionFalse {
-			hadErrorCondition = true
-		}
+// maxEventNoteLength is the maximum length of the note of an Event which the Kubernetes API server accepts.
+const maxEventNoteLength = 1024
+
+// ObjectReference returns a reference to the object for recording Events about it. The events recorders can only
+// find the kind of the types in the client-go scheme, so the kind of Pinniped types must be given explicitly.
+func ObjectReference(object metav1.Object, gvk schema.GroupVersionKind) *corev1.ObjectReference {
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return &corev1.ObjectReference{
+		APIVersion:      apiVersion,
+		Kind:            kind,
+		Namespace:       object.GetNamespace(),
+		Name:            object.GetName(),
+		UID:             object.GetUID(),
+		ResourceVersion: object.GetResourceVersion(),
 	}
-	sort.SliceStable(*conditionsToUpdate, func(i, j int) bool {
-		return (*conditionsToUpdate)[i].Type < (*conditionsToUpdate)[j].Type
-	})
-	return hadErrorCondition
 }
 
-// mergeConfigCondition merges a new idpv1alpha1.Condition into a slice of existing conditions. It returns true
-// if the condition has meaningfully changed.
-func mergeConfigCondition(existing *[]configv1alpha1.Condition, new *configv1alpha1.Condition) bool {
-	// Find any existing condition with a matching type.
-	var old *configv1alpha1.Condition
-	for i := range *existing {
-		if (*existing)[i].Type == new.Type {
-			old = &(*existing)[i]
+// RecordConditionFailures records a Warning Event regarding the object for each condition which changed to False,
+// so that the failures show up in kubectl describe without reading the logs of the pods.
+func RecordConditionFailures(recorder events.EventRecorder, regarding *corev1.ObjectReference, oldConditions, newConditions []metav1.Condition) {
+	for _, cond := range newConditions {
+		if cond.Status != metav1.ConditionFalse {
 			continue
 		}
+		if old := meta.FindStatusCondition(oldConditions, cond.Type); old != nil && old.Status == metav1.ConditionFalse {
+			continue
+		}
+		recordConditionFailure(recorder, regarding, cond.Type, cond.Reason, cond.Message)
 	}
+}
 
-	// If there is no existing condition of this type, append this one and we're done.
-	if old == nil {
-		*existing = append(*existing, *new)
-		return true
-	}
-
-	// Set the LastTransitionTime depending on whether the status has changed.
-	new = new.DeepCopy()
-	if old.Status == new.Status {
-		new.LastTransitionTime = old.LastTransitionTime
-	}
+func recordConditionFailure(recorder events.EventRecorder, regarding *corev1.ObjectReference, conditionType, reason, message string) {
+	RecordStatusWarning(recorder, regarding, reason, conditionType+" is False: "+message)
+}
 
-	// If anything has actually changed, update the entry and return true.
-	if !equality.Semantic.DeepEqual(old, new) {
-		*old = *new
-		return true
+// RecordStatusWarning records a Warning Event regarding the object for a failure which was written to its status.
+// Notes which are too long for the Kubernetes API server are truncated.
+func RecordStatusWarning(recorder events.EventRecorder, regarding *corev1.ObjectReference, reason, note string) {
+	if len(note) > maxEventNoteLength {
+		note = note[:maxEventNoteLength-len("...")] + "..."
 	}
-
-	// Otherwise the entry is already up to date.
-	return false
+	recorder.Eventf(regarding, nil, corev1.EventTypeWarning, reason, "UpdateStatus", "%s", note)
 }
@@ -0,0 +1,196 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package conditionsutil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestObjectReference(t *testing.T) {
+	upstream := &idpv1alpha1.OIDCIdentityProvider{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "some-namespace", Name: "some-name", UID: "some-uid", ResourceVersion: "42",
+	}}
+	require.Equal(t, &corev1.ObjectReference{
+		APIVersion:      "idp.supervisor.pinniped.dev/v1alpha1",
+		Kind:            "OIDCIdentityProvider",
+		Namespace:       "some-namespace",
+		Name:            "some-name",
+		UID:             "some-uid",
+		ResourceVersion: "42",
+	}, ObjectReference(upstream, idpv1alpha1.SchemeGroupVersion.WithKind("OIDCIdentityProvider")))
+}
+
+func TestMergeConditions(t *testing.T) {
+	const observedGeneration = 42
+	now := metav1.NewTime(time.Date(2099, 1, 2, 3, 4, 5, 0, time.UTC))
+	oldTime := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	tests := []struct {
+		name                  string
+		existing              []metav1.Condition
+		conditions            []*metav1.Condition
+		want                  []metav1.Condition
+		wantHadErrorCondition bool
+		wantLogUpdates        int
+	}{
+		{
+			name: "new conditions are added and sorted by type",
+			conditions: []*metav1.Condition{
+				{Type: "TypeB", Status: metav1.ConditionTrue, Reason: "Success", Message: "b"},
+				{Type: "TypeA", Status: metav1.ConditionUnknown, Reason: "Unknown", Message: "a"},
+			},
+			want: []metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionUnknown, Reason: "Unknown", Message: "a", ObservedGeneration: observedGeneration, LastTransitionTime: now},
+				{Type: "TypeB", Status: metav1.ConditionTrue, Reason: "Success", Message: "b", ObservedGeneration: observedGeneration, LastTransitionTime: now},
+			},
+			wantLogUpdates: 2,
+		},
+		{
+			name: "unchanged conditions keep their last transition time",
+			existing: []metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionTrue, Reason: "Success", Message: "a", ObservedGeneration: observedGeneration, LastTransitionTime: oldTime},
+			},
+			conditions: []*metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionTrue, Reason: "Success", Message: "a"},
+			},
+			want: []metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionTrue, Reason: "Success", Message: "a", ObservedGeneration: observedGeneration, LastTransitionTime: oldTime},
+			},
+		},
+		{
+			name: "conditions with the same status but a new message or generation keep their last transition time",
+			existing: []metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionFalse, Reason: "SomeReason", Message: "old", ObservedGeneration: 1, LastTransitionTime: oldTime},
+			},
+			conditions: []*metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionFalse, Reason: "OtherReason", Message: "new"},
+			},
+			want: []metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionFalse, Reason: "OtherReason", Message: "new", ObservedGeneration: observedGeneration, LastTransitionTime: oldTime},
+			},
+			wantHadErrorCondition: true,
+			wantLogUpdates:        1,
+		},
+		{
+			name: "conditions with a new status get a new last transition time",
+			existing: []metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionTrue, Reason: "Success", Message: "a", ObservedGeneration: observedGeneration, LastTransitionTime: oldTime},
+				{Type: "TypeB", Status: metav1.ConditionTrue, Reason: "Success", Message: "b", ObservedGeneration: observedGeneration, LastTransitionTime: oldTime},
+			},
+			conditions: []*metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionFalse, Reason: "SomeReason", Message: "a"},
+			},
+			want: []metav1.Condition{
+				{Type: "TypeA", Status: metav1.ConditionFalse, Reason: "SomeReason", Message: "a", ObservedGeneration: observedGeneration, LastTransitionTime: now},
+				{Type: "TypeB", Status: metav1.ConditionTrue, Reason: "Success", Message: "b", ObservedGeneration: observedGeneration, LastTransitionTime: oldTime},
+			},
+			wantHadErrorCondition: true,
+			wantLogUpdates:        1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var log bytes.Buffer
+			conditions := tt.existing
+			hadErrorCondition := MergeConditions(tt.conditions, observedGeneration, &conditions, plog.TestLogger(t, &log))
+
+			require.Equal(t, tt.wantHadErrorCondition, hadErrorCondition)
+			require.Equal(t, tt.want, testutil.NormalizeConditionTimes(conditions, now))
+			require.Equal(t, tt.wantLogUpdates, strings.Count(log.String(), `"message":"updated condition"`))
+		})
+	}
+}
+
+func TestRecordConditionFailures(t *testing.T) {
+	regarding := &corev1.ObjectReference{Kind: "OIDCIdentityProvider", Namespace: "some-namespace", Name: "some-name"}
+
+	tests := []struct {
+		name          string
+		oldConditions []metav1.Condition
+		newConditions []metav1.Condition
+		wantEvents    []string
+	}{
+		{
+			name: "no conditions are False",
+			newConditions: []metav1.Condition{
+				{Type: "ClientCredentialsValid", Status: metav1.ConditionTrue, Reason: "Success", Message: "loaded client credentials"},
+			},
+		},
+		{
+			name: "new conditions which are False",
+			newConditions: []metav1.Condition{
+				{Type: "ClientCredentialsValid", Status: metav1.ConditionFalse, Reason: "SecretNotFound", Message: "secret not found"},
+				{Type: "OIDCDiscoverySucceeded", Status: metav1.ConditionUnknown, Reason: "Unknown", Message: "unknown"},
+			},
+			wantEvents: []string{"Warning SecretNotFound ClientCredentialsValid is False: secret not found"},
+		},
+		{
+			name: "conditions which changed to False",
+			oldConditions: []metav1.Condition{
+				{Type: "ClientCredentialsValid", Status: metav1.ConditionTrue, Reason: "Success", Message: "loaded client credentials"},
+				{Type: "OIDCDiscoverySucceeded", Status: metav1.ConditionUnknown, Reason: "Unknown", Message: "unknown"},
+			},
+			newConditions: []metav1.Condition{
+				{Type: "ClientCredentialsValid", Status: metav1.ConditionFalse, Reason: "SecretNotFound", Message: "secret not found"},
+				{Type: "OIDCDiscoverySucceeded", Status: metav1.ConditionFalse, Reason: "Unreachable", Message: "could not connect"},
+			},
+			wantEvents: []string{
+				"Warning SecretNotFound ClientCredentialsValid is False: secret not found",
+				"Warning Unreachable OIDCDiscoverySucceeded is False: could not connect",
+			},
+		},
+		{
+			name: "conditions which were already False",
+			oldConditions: []metav1.Condition{
+				{Type: "ClientCredentialsValid", Status: metav1.ConditionFalse, Reason: "SecretNotFound", Message: "secret not found"},
+			},
+			newConditions: []metav1.Condition{
+				{Type: "ClientCredentialsValid", Status: metav1.ConditionFalse, Reason: "SecretWrongType", Message: "wrong type"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := events.NewFakeRecorder(10)
+			RecordConditionFailures(recorder, regarding, tt.oldConditions, tt.newConditions)
+			require.Equal(t, tt.wantEvents, drainEvents(recorder))
+		})
+	}
+}
+
+func TestRecordStatusWarningTruncatesLongNotes(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	RecordStatusWarning(recorder, &corev1.ObjectReference{}, "SomeReason", strings.Repeat("a", 2000))
+
+	got := drainEvents(recorder)
+	require.Len(t, got, 1)
+	wantPrefix := "Warning SomeReason "
+	require.Equal(t, wantPrefix+strings.Repeat("a", maxEventNoteLength-3)+"...", got[0])
+}
+
+func drainEvents(recorder *events.FakeRecorder) []string {
+	var got []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			got = append(got, event)
+		default:
+			return got
+		}
+	}
+}
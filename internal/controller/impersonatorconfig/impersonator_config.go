@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package impersonatorconfig
@@ -79,6 +79,7 @@ type impersonatorConfigController struct {
 	clock                            clock.Clock
 	impersonationSigningCertProvider dynamiccert.Provider
 	impersonatorFunc                 impersonator.FactoryFunc
+	keyAlgorithm                     certauthority.KeyAlgorithm
 
 	hasControlPlaneNodes              *bool
 	serverStopCh                      chan struct{}
@@ -107,6 +108,7 @@ func NewImpersonatorConfigController(
 	impersonatorFunc impersonator.FactoryFunc,
 	impersonationSignerSecretName string,
 	impersonationSigningCertProvider dynamiccert.Provider,
+	keyAlgorithm certauthority.KeyAlgorithm,
 	log logr.Logger,
 ) controllerlib.Controller {
 	secretNames := sets.NewString(tlsSecretName, caSecretName, impersonationSignerSecretName)
@@ -131,6 +133,7 @@ func NewImpersonatorConfigController(
 				labels:                            labels,
 				clock:                             clock,
 				impersonationSigningCertProvider:  impersonationSigningCertProvider,
+				keyAlgorithm:                      keyAlgorithm,
 				impersonatorFunc:                  impersonatorFunc,
 				tlsServingCertDynamicCertProvider: dynamiccert.NewServingCert("impersonation-proxy-serving-cert"),
 				infoLog:                           log.V(plog.KlogLevelInfo),
@@ -191,6 +194,7 @@ func (c *impersonatorConfigController) Sync(syncCtx controllerlib.Context) error
 	err = utilerrors.NewAggregate([]error{err, issuerconfig.Update(
 		syncCtx.Context,
 		c.pinnipedAPIClient,
+		syncCtx.Recorder,
 		credIssuer,
 		*strategy,
 	)})
@@ -789,7 +793,7 @@ func (c *impersonatorConfigController) ensureCASecretIsCreated(ctx context.Conte
 	} else {
 		crtBytes := caSecret.Data[caCrtKey]
 		keyBytes := caSecret.Data[caKeyKey]
-		impersonationCA, err = certauthority.Load(string(crtBytes), string(keyBytes))
+		impersonationCA, err = certauthority.Load(string(crtBytes), string(keyBytes), certauthority.WithKeyAlgorithm(c.keyAlgorithm))
 	}
 	if err != nil {
 		return nil, err
@@ -799,7 +803,7 @@ func (c *impersonatorConfigController) ensureCASecretIsCreated(ctx context.Conte
 }
 
 func (c *impersonatorConfigController) createCASecret(ctx context.Context) (*certauthority.CA, error) {
-	impersonationCA, err := certauthority.New(caCommonName, approximatelyOneHundredYears)
+	impersonationCA, err := certauthority.New(caCommonName, approximatelyOneHundredYears, certauthority.WithKeyAlgorithm(c.keyAlgorithm))
 	if err != nil {
 		return nil, fmt.Errorf("could not create impersonation CA: %w", err)
 	}
@@ -1005,6 +1009,9 @@ func (c *impersonatorConfigController) loadSignerCA() error {
 		return fmt.Errorf("could not set the impersonator's credential signing secret: %w", err)
 	}
 
+	// Keep trusting the client certs which were signed by the previous CA after the CA was rotated.
+	c.impersonationSigningCertProvider.SetAdditionalCABundleContent(signingCertSecret.Data[apicerts.PreviousCACertificateSecretKey])
+
 	c.infoLog.Info("loading credential signing certificate for impersonation proxy",
 		"certPEM", string(certPEM),
 		"secret", klog.KObj(signingCertSecret),
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package impersonatorconfig
@@ -92,6 +92,7 @@ func TestImpersonatorConfigControllerOptions(t *testing.T) {
 				nil,
 				caSignerName,
 				nil,
+				"",
 				plog.Logr(), //nolint:staticcheck  // old test with no log assertions
 			)
 			credIssuerInformerFilter = observableWithInformerOption.GetFilterForInformer(credIssuerInformer)
@@ -563,6 +564,7 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 				impersonatorFunc,
 				caSignerName,
 				signingCertProvider,
+				"",
 				plog.Logr(), //nolint:staticcheck  // old test with no log assertions
 			)
 			controllerlib.TestWrap(t, subject, func(syncer controllerlib.Syncer) controllerlib.Syncer {
@@ -3847,6 +3849,45 @@ func TestImpersonatorConfigControllerSync(t *testing.T) {
 				})
 			})
 
+			when("the signer CA is rotated", func() {
+				const fakeHostname = "foo.example.com"
+				it.Before(func() {
+					addSecretToTrackers(signingCASecret, kubeInformerClient)
+				})
+
+				it("loads the new CA and keeps trusting the previous CA", func() {
+					startInformersAndController()
+					r.NoError(runControllerSync())
+					r.Len(kubeAPIClient.Actions(), 3)
+					requireNodesListed(kubeAPIClient.Actions()[0])
+					ca := requireCASecretWasCreated(kubeAPIClient.Actions()[1])
+					requireTLSSecretWasCreated(kubeAPIClient.Actions()[2], ca)
+					requireCredentialIssuer(newSuccessStrategy(fakeHostname, ca))
+					requireSigningCertProviderHasLoadedCerts(signingCACertPEM, signingCAKeyPEM)
+					r.Equal(string(signingCACertPEM), string(signingCertProvider.CurrentCABundleContent()))
+
+					// Simulate the informer cache's background update from its watch.
+					addObjectFromCreateActionToInformerAndWait(kubeAPIClient.Actions()[1], kubeInformers.Core().V1().Secrets())
+					addObjectFromCreateActionToInformerAndWait(kubeAPIClient.Actions()[2], kubeInformers.Core().V1().Secrets())
+
+					// Now rotate the signer CA, like the CA rotator controller would.
+					newSigningCA := newCA()
+					newSigningCAKeyPEM, err := newSigningCA.PrivateKeyToPEM()
+					r.NoError(err)
+					deleteSecretFromTracker(caSignerName, kubeInformerClient)
+					waitForObjectToBeDeletedFromInformer(caSignerName, kubeInformers.Core().V1().Secrets())
+					updatedSigner := newSigningKeySecret(caSignerName, newSigningCA.Bundle(), newSigningCAKeyPEM)
+					updatedSigner.Data[apicerts.PreviousCACertificateSecretKey] = signingCACertPEM
+					addSecretToTrackers(updatedSigner, kubeInformerClient)
+					waitForObjectToAppearInInformer(updatedSigner, kubeInformers.Core().V1().Secrets())
+
+					r.NoError(runControllerSync())
+					requireCredentialIssuer(newSuccessStrategy(fakeHostname, ca))
+					requireSigningCertProviderHasLoadedCerts(newSigningCA.Bundle(), newSigningCAKeyPEM)
+					r.Equal(string(newSigningCA.Bundle())+string(signingCACertPEM), string(signingCertProvider.CurrentCABundleContent()))
+				})
+			})
+
 			when("the cert goes from being valid to being invalid", func() {
 				const fakeHostname = "foo.example.com"
 				it.Before(func() {
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package issuerconfig contains helpers for updating CredentialIssuer status entries.
@@ -11,13 +11,22 @@ import (
 
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
 
 	"go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
 	"go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
+	"go.pinniped.dev/internal/controller/conditionsutil"
 )
 
-// Update a strategy on an existing CredentialIssuer, merging into any existing strategy entries.
-func Update(ctx context.Context, client versioned.Interface, issuer *v1alpha1.CredentialIssuer, strategy v1alpha1.CredentialIssuerStrategy) error {
+// Update a strategy on an existing CredentialIssuer, merging into any existing strategy entries. When the strategy
+// changes to an error, a Warning Event regarding the CredentialIssuer is recorded.
+func Update(
+	ctx context.Context,
+	client versioned.Interface,
+	recorder events.EventRecorder,
+	issuer *v1alpha1.CredentialIssuer,
+	strategy v1alpha1.CredentialIssuerStrategy,
+) error {
 	// Update the existing object to merge in the new strategy.
 	updated := issuer.DeepCopy()
 	mergeStrategy(&updated.Status, strategy)
@@ -30,9 +39,24 @@ func Update(ctx context.Context, client versioned.Interface, issuer *v1alpha1.Cr
 	if _, err := client.ConfigV1alpha1().CredentialIssuers().UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
 		return fmt.Errorf("failed to update CredentialIssuer status: %w", err)
 	}
+
+	if strategy.Status == v1alpha1.ErrorStrategyStatus && !hadErrorStrategy(&issuer.Status, strategy.Type) {
+		conditionsutil.RecordStatusWarning(recorder,
+			conditionsutil.ObjectReference(issuer, v1alpha1.SchemeGroupVersion.WithKind("CredentialIssuer")),
+			string(strategy.Reason), fmt.Sprintf("%s strategy failed: %s", strategy.Type, strategy.Message))
+	}
 	return nil
 }
 
+func hadErrorStrategy(status *v1alpha1.CredentialIssuerStatus, strategyType v1alpha1.StrategyType) bool {
+	for i := range status.Strategies {
+		if status.Strategies[i].Type == strategyType {
+			return status.Strategies[i].Status == v1alpha1.ErrorStrategyStatus
+		}
+	}
+	return false
+}
+
 func mergeStrategy(configToUpdate *v1alpha1.CredentialIssuerStatus, strategy v1alpha1.CredentialIssuerStrategy) {
 	var existing *v1alpha1.CredentialIssuerStrategy
 	for i := range configToUpdate.Strategies {
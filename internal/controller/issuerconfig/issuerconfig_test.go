@@ -1,9 +1,10 @@
-// Copyright 2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package issuerconfig
 
 import (
+	"context"
 	"math/rand"
 	"sort"
 	"testing"
@@ -13,10 +14,44 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
 
 	"go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned/fake"
 )
 
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	issuer := &v1alpha1.CredentialIssuer{ObjectMeta: metav1.ObjectMeta{Name: "some-issuer"}}
+	client := pinnipedfake.NewSimpleClientset(issuer)
+	recorder := events.NewFakeRecorder(10)
+
+	update := func(t *testing.T, status v1alpha1.StrategyStatus, reason v1alpha1.StrategyReason, message string) {
+		t.Helper()
+		issuer, err := client.ConfigV1alpha1().CredentialIssuers().Get(ctx, "some-issuer", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NoError(t, Update(ctx, client, recorder, issuer, v1alpha1.CredentialIssuerStrategy{
+			Type:    v1alpha1.KubeClusterSigningCertificateStrategyType,
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		}))
+	}
+
+	// A strategy which changes to an error records an Event.
+	update(t, v1alpha1.ErrorStrategyStatus, v1alpha1.CouldNotFetchKeyStrategyReason, "some error")
+	require.Equal(t, "Warning CouldNotFetchKey KubeClusterSigningCertificate strategy failed: some error", <-recorder.Events)
+
+	// A strategy which was already an error does not record another Event.
+	update(t, v1alpha1.ErrorStrategyStatus, v1alpha1.CouldNotFetchKeyStrategyReason, "some other error")
+	update(t, v1alpha1.SuccessStrategyStatus, v1alpha1.FetchedKeyStrategyReason, "key was fetched successfully")
+	require.Empty(t, recorder.Events)
+
+	update(t, v1alpha1.ErrorStrategyStatus, v1alpha1.CouldNotGetClusterInfoStrategyReason, "some error")
+	require.Equal(t, "Warning CouldNotGetClusterInfo KubeClusterSigningCertificate strategy failed: some error", <-recorder.Events)
+	require.Empty(t, recorder.Events)
+}
+
 func TestMergeStrategy(t *testing.T) {
 	t1 := metav1.Now()
 	t2 := metav1.NewTime(metav1.Now().Add(-1 * time.Hour))
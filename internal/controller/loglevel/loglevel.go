@@ -0,0 +1,131 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package loglevel implements a controller which applies changes to the log level in the configuration of the
+// Concierge or the Supervisor while it is running, so that debug logs can be turned on without restarting its pods.
+package loglevel
+
+import (
+	"fmt"
+	"reflect"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"sigs.k8s.io/yaml"
+
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+// ConfigMapKey is the key of the configuration file in the ConfigMap.
+const ConfigMapKey = "pinniped.yaml"
+
+// levels are the parts of a plog.LogSpec which can be changed while running.
+type levels struct {
+	level      plog.LogLevel
+	components map[string]plog.LogLevel
+}
+
+func (l levels) equal(other levels) bool {
+	return l.level == other.level && reflect.DeepEqual(l.components, other.components)
+}
+
+type logLevelController struct {
+	namespace         string
+	configMapName     string
+	initialLevels     levels
+	currentLevels     levels
+	configMapInformer corev1informers.ConfigMapInformer
+	setLogLevel       func(plog.LogLevel, map[string]plog.LogLevel) error
+}
+
+// NewLogLevelController returns a controller which sets the global log level and the log levels of components to the
+// ones in the configuration file in the ConfigMap. When the ConfigMap or the configuration file does not exist, it goes
+// back to the initial levels of the log config, which was configured when the server was started.
+func NewLogLevelController(
+	namespace string,
+	configMapName string,
+	initialLog plog.LogSpec,
+	configMapInformer corev1informers.ConfigMapInformer,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return newLogLevelController(namespace, configMapName, initialLog, configMapInformer, withInformer, plog.SetLogLevelGlobally)
+}
+
+func newLogLevelController(
+	namespace string,
+	configMapName string,
+	initialLog plog.LogSpec,
+	configMapInformer corev1informers.ConfigMapInformer,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+	setLogLevel func(plog.LogLevel, map[string]plog.LogLevel) error,
+) controllerlib.Controller {
+	initialLevels := levels{level: initialLog.Level, components: initialLog.Components}
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "log-level-controller",
+			Syncer: &logLevelController{
+				namespace:         namespace,
+				configMapName:     configMapName,
+				initialLevels:     initialLevels,
+				currentLevels:     initialLevels,
+				configMapInformer: configMapInformer,
+				setLogLevel:       setLogLevel,
+			},
+		},
+		withInformer(
+			configMapInformer,
+			pinnipedcontroller.NameAndNamespaceExactMatchFilterFactory(configMapName, namespace),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+func (c *logLevelController) Sync(_ controllerlib.Context) error {
+	wantLevels := c.initialLevels
+	configMap, err := c.configMapInformer.Lister().ConfigMaps(c.namespace).Get(c.configMapName)
+	notFound := k8serrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return fmt.Errorf("failed to get %s/%s configmap: %w", c.namespace, c.configMapName, err)
+	}
+	if !notFound {
+		if data, ok := configMap.Data[ConfigMapKey]; ok {
+			wantLevels, err = levelsFromConfig([]byte(data))
+			if err != nil {
+				return fmt.Errorf("could not read log level from %s/%s configmap: %w", c.namespace, c.configMapName, err)
+			}
+		}
+	}
+
+	if wantLevels.equal(c.currentLevels) {
+		return nil
+	}
+	if err := c.setLogLevel(wantLevels.level, wantLevels.components); err != nil {
+		return fmt.Errorf("could not set log level from %s/%s configmap: %w", c.namespace, c.configMapName, err)
+	}
+	plog.Info("logLevelController Sync changed the log level",
+		"level", wantLevels.level,
+		"previousLevel", c.currentLevels.level,
+		"components", wantLevels.components,
+		"previousComponents", c.currentLevels.components,
+	)
+	c.currentLevels = wantLevels
+	return nil
+}
+
+// levelsFromConfig returns the log levels of a configuration file. Like the configuration parsers of the Concierge and
+// the Supervisor, the deprecated logLevel takes precedence over log.level.
+func levelsFromConfig(data []byte) (levels, error) {
+	var config struct {
+		LogLevel *plog.LogLevel `json:"logLevel"`
+		Log      plog.LogSpec   `json:"log"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return levels{}, fmt.Errorf("decode yaml: %w", err)
+	}
+	if config.LogLevel != nil {
+		config.Log.Level = *config.LogLevel
+	}
+	return levels{level: config.Log.Level, components: config.Log.Components}, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package loglevel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/testutil"
+)
+
+const (
+	testNamespace     = "some-namespace"
+	testConfigMapName = "some-config"
+)
+
+func TestLogLevelControllerFilter(t *testing.T) {
+	t.Parallel()
+
+	configMapInformer := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(), 0).Core().V1().ConfigMaps()
+	withInformer := testutil.NewObservableWithInformerOption()
+	_ = NewLogLevelController(testNamespace, testConfigMapName, plog.LogSpec{}, configMapInformer, withInformer.WithInformer)
+	filter := withInformer.GetFilterForInformer(configMapInformer)
+
+	target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: testConfigMapName, Namespace: testNamespace}}
+	wrongName := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "wrong-name", Namespace: testNamespace}}
+	wrongNamespace := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: testConfigMapName, Namespace: "wrong-namespace"}}
+
+	require.True(t, filter.Add(target))
+	require.True(t, filter.Update(wrongName, target))
+	require.True(t, filter.Update(target, wrongName))
+	require.True(t, filter.Delete(target))
+	for _, unrelated := range []*corev1.ConfigMap{wrongName, wrongNamespace} {
+		require.False(t, filter.Add(unrelated))
+		require.False(t, filter.Update(wrongName, unrelated))
+		require.False(t, filter.Delete(unrelated))
+	}
+}
+
+func TestLogLevelControllerSync(t *testing.T) {
+	t.Parallel()
+
+	configMap := func(data map[string]string) runtime.Object {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: testConfigMapName, Namespace: testNamespace}, Data: data}
+	}
+
+	tests := []struct {
+		name          string
+		initialLog    plog.LogSpec
+		configMap     runtime.Object
+		setLogLevel   error
+		wantErr       string
+		wantSetLevels []levels
+	}{
+		{
+			name:       "configmap does not exist",
+			initialLog: plog.LogSpec{Level: plog.LevelInfo},
+		},
+		{
+			name:       "configmap has no configuration file",
+			initialLog: plog.LogSpec{Level: plog.LevelInfo},
+			configMap:  configMap(map[string]string{"other-key": "log: {level: debug}"}),
+		},
+		{
+			name:       "log level is unchanged",
+			initialLog: plog.LogSpec{Level: plog.LevelInfo},
+			configMap:  configMap(map[string]string{ConfigMapKey: "log:\n  level: info\n"}),
+		},
+		{
+			name:          "log level is changed",
+			initialLog:    plog.LogSpec{Level: plog.LevelInfo},
+			configMap:     configMap(map[string]string{ConfigMapKey: "apiGroupSuffix: pinniped.dev\nlog:\n  level: debug\n"}),
+			wantSetLevels: []levels{{level: plog.LevelDebug}},
+		},
+		{
+			name:          "log level is removed",
+			initialLog:    plog.LogSpec{Level: plog.LevelInfo},
+			configMap:     configMap(map[string]string{ConfigMapKey: "apiGroupSuffix: pinniped.dev\n"}),
+			wantSetLevels: []levels{{level: plog.LevelWarning}},
+		},
+		{
+			name:       "component log levels are changed",
+			initialLog: plog.LogSpec{Level: plog.LevelInfo, Components: map[string]plog.LogLevel{"impersonation-proxy": plog.LevelDebug}},
+			configMap:  configMap(map[string]string{ConfigMapKey: "log:\n  level: info\n  components:\n    impersonation-proxy: trace\n"}),
+			wantSetLevels: []levels{{
+				level:      plog.LevelInfo,
+				components: map[string]plog.LogLevel{"impersonation-proxy": plog.LevelTrace},
+			}},
+		},
+		{
+			name:       "component log levels are unchanged",
+			initialLog: plog.LogSpec{Level: plog.LevelInfo, Components: map[string]plog.LogLevel{"impersonation-proxy": plog.LevelDebug}},
+			configMap:  configMap(map[string]string{ConfigMapKey: "log:\n  level: info\n  components:\n    impersonation-proxy: debug\n"}),
+		},
+		{
+			name:          "component log levels are removed",
+			initialLog:    plog.LogSpec{Level: plog.LevelInfo, Components: map[string]plog.LogLevel{"impersonation-proxy": plog.LevelDebug}},
+			configMap:     configMap(map[string]string{ConfigMapKey: "log:\n  level: info\n"}),
+			wantSetLevels: []levels{{level: plog.LevelInfo}},
+		},
+		{
+			name:          "deprecated log level takes precedence",
+			initialLog:    plog.LogSpec{Level: plog.LevelInfo},
+			configMap:     configMap(map[string]string{ConfigMapKey: "logLevel: trace\nlog:\n  level: debug\n"}),
+			wantSetLevels: []levels{{level: plog.LevelTrace}},
+		},
+		{
+			name:       "configuration file is invalid",
+			initialLog: plog.LogSpec{Level: plog.LevelInfo},
+			configMap:  configMap(map[string]string{ConfigMapKey: "log: [this is not valid"}),
+			wantErr:    "could not read log level from some-namespace/some-config configmap: decode yaml: error converting YAML to JSON: yaml: line 1: did not find expected ',' or ']'",
+		},
+		{
+			name:          "log level is invalid",
+			initialLog:    plog.LogSpec{Level: plog.LevelInfo},
+			configMap:     configMap(map[string]string{ConfigMapKey: "log:\n  level: panda\n"}),
+			setLogLevel:   fmt.Errorf("some set error"),
+			wantErr:       "could not set log level from some-namespace/some-config configmap: some set error",
+			wantSetLevels: []levels{{level: "panda"}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var objects []runtime.Object
+			if tt.configMap != nil {
+				objects = append(objects, tt.configMap)
+			}
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(objects...), 0)
+			var gotSetLevels []levels
+			controller := newLogLevelController(
+				testNamespace,
+				testConfigMapName,
+				tt.initialLog,
+				kubeInformers.Core().V1().ConfigMaps(),
+				controllerlib.WithInformer,
+				func(level plog.LogLevel, components map[string]plog.LogLevel) error {
+					gotSetLevels = append(gotSetLevels, levels{level: level, components: components})
+					return tt.setLogLevel
+				},
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, controller)
+
+			syncCtx := controllerlib.Context{Context: ctx, Key: controllerlib.Key{Namespace: testNamespace, Name: testConfigMapName}}
+			err := controllerlib.TestSync(t, controller, syncCtx)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantSetLevels, gotSetLevels)
+
+			// Syncing again does not set the same log level again, unless it could not be set.
+			_ = controllerlib.TestSync(t, controller, syncCtx)
+			if tt.setLogLevel == nil {
+				require.Equal(t, tt.wantSetLevels, gotSetLevels)
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package activedirectoryupstreamwatcher implements a controller which watches ActiveDirectoryIdentityProviders.
@@ -120,14 +120,14 @@ func (s *activeDirectoryUpstreamGenericLDAPSpec) GroupSearch() upstreamwatchers.
 	return &activeDirectoryUpstreamGenericLDAPGroupSearch{s.activeDirectoryIdentityProvider.Spec.GroupSearch}
 }
 
-func (s *activeDirectoryUpstreamGenericLDAPSpec) DetectAndSetSearchBase(ctx context.Context, config *upstreamldap.ProviderConfig) *v1alpha1.Condition {
+func (s *activeDirectoryUpstreamGenericLDAPSpec) DetectAndSetSearchBase(ctx context.Context, config *upstreamldap.ProviderConfig) *metav1.Condition {
 	config.GroupSearch.Base = s.activeDirectoryIdentityProvider.Spec.GroupSearch.Base
 	config.UserSearch.Base = s.activeDirectoryIdentityProvider.Spec.UserSearch.Base
 	if config.GroupSearch.Base != "" && config.UserSearch.Base != "" {
 		// Both were already set in spec so just return; no need to query the RootDSE
-		return &v1alpha1.Condition{
+		return &metav1.Condition{
 			Type:    upstreamwatchers.TypeSearchBaseFound,
-			Status:  v1alpha1.ConditionTrue,
+			Status:  metav1.ConditionTrue,
 			Reason:  upstreamwatchers.ReasonUsingConfigurationFromSpec,
 			Message: "Using search base from ActiveDirectoryIdentityProvider config.",
 		}
@@ -138,9 +138,9 @@ func (s *activeDirectoryUpstreamGenericLDAPSpec) DetectAndSetSearchBase(ctx cont
 	// https://ldapwiki.com/wiki/DefaultNamingContext
 	defaultNamingContext, err := ldapProvider.SearchForDefaultNamingContext(ctx)
 	if err != nil {
-		return &v1alpha1.Condition{
+		return &metav1.Condition{
 			Type:    upstreamwatchers.TypeSearchBaseFound,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  upstreamwatchers.ReasonErrorFetchingSearchBase,
 			Message: fmt.Sprintf(`Error finding search base: %s`, err.Error()),
 		}
@@ -151,9 +151,9 @@ func (s *activeDirectoryUpstreamGenericLDAPSpec) DetectAndSetSearchBase(ctx cont
 	if config.GroupSearch.Base == "" {
 		config.GroupSearch.Base = defaultNamingContext
 	}
-	return &v1alpha1.Condition{
+	return &metav1.Condition{
 		Type:    upstreamwatchers.TypeSearchBaseFound,
-		Status:  v1alpha1.ConditionTrue,
+		Status:  metav1.ConditionTrue,
 		Reason:  upstreamwatchers.ReasonSuccess,
 		Message: "Successfully fetched defaultNamingContext to use as default search base from RootDSE.",
 	}
@@ -210,11 +210,15 @@ func (g *activeDirectoryUpstreamGenericLDAPGroupSearch) GroupNameAttribute() str
 	return g.groupSearch.Attributes.GroupName
 }
 
+func (g *activeDirectoryUpstreamGenericLDAPGroupSearch) Transformations() []v1alpha1.GroupTransformation {
+	return g.groupSearch.Transformations
+}
+
 type activeDirectoryUpstreamGenericLDAPStatus struct {
 	activeDirectoryIdentityProvider v1alpha1.ActiveDirectoryIdentityProvider
 }
 
-func (s *activeDirectoryUpstreamGenericLDAPStatus) Conditions() []v1alpha1.Condition {
+func (s *activeDirectoryUpstreamGenericLDAPStatus) Conditions() []metav1.Condition {
 	return s.activeDirectoryIdentityProvider.Status.Conditions
 }
 
@@ -296,7 +300,7 @@ func (c *activeDirectoryWatcherController) Sync(ctx controllerlib.Context) error
 	requeue := false
 	validatedUpstreams := make([]provider.UpstreamLDAPIdentityProviderI, 0, len(actualUpstreams))
 	for _, upstream := range actualUpstreams {
-		valid, requestedRequeue := c.validateUpstream(ctx.Context, upstream)
+		valid, requestedRequeue := c.validateUpstream(ctx, upstream)
 		if valid != nil {
 			validatedUpstreams = append(validatedUpstreams, valid)
 		}
@@ -313,7 +317,7 @@ func (c *activeDirectoryWatcherController) Sync(ctx controllerlib.Context) error
 	return nil
 }
 
-func (c *activeDirectoryWatcherController) validateUpstream(ctx context.Context, upstream *v1alpha1.ActiveDirectoryIdentityProvider) (p provider.UpstreamLDAPIdentityProviderI, requeue bool) {
+func (c *activeDirectoryWatcherController) validateUpstream(ctx controllerlib.Context, upstream *v1alpha1.ActiveDirectoryIdentityProvider) (p provider.UpstreamLDAPIdentityProviderI, requeue bool) {
 	spec := upstream.Spec
 
 	adUpstreamImpl := &activeDirectoryUpstreamGenericLDAPImpl{activeDirectoryIdentityProvider: *upstream}
@@ -351,18 +355,18 @@ func (c *activeDirectoryWatcherController) validateUpstream(ctx context.Context,
 		}
 	}
 
-	conditions := upstreamwatchers.ValidateGenericLDAP(ctx, adUpstreamImpl, c.secretInformer, c.validatedSettingsCache, config)
+	conditions := upstreamwatchers.ValidateGenericLDAP(ctx.Context, adUpstreamImpl, c.secretInformer, c.validatedSettingsCache, config)
 
 	c.updateStatus(ctx, upstream, conditions.Conditions())
 
 	return upstreamwatchers.EvaluateConditions(conditions, config)
 }
 
-func (c *activeDirectoryWatcherController) updateStatus(ctx context.Context, upstream *v1alpha1.ActiveDirectoryIdentityProvider, conditions []*v1alpha1.Condition) {
+func (c *activeDirectoryWatcherController) updateStatus(ctx controllerlib.Context, upstream *v1alpha1.ActiveDirectoryIdentityProvider, conditions []*metav1.Condition) {
 	log := plog.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
 	updated := upstream.DeepCopy()
 
-	hadErrorCondition := conditionsutil.MergeIDPConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
+	hadErrorCondition := conditionsutil.MergeConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
 
 	updated.Status.Phase = v1alpha1.ActiveDirectoryPhaseReady
 	if hadErrorCondition {
@@ -376,10 +380,15 @@ func (c *activeDirectoryWatcherController) updateStatus(ctx context.Context, ups
 	_, err := c.client.
 		IDPV1alpha1().
 		ActiveDirectoryIdentityProviders(upstream.Namespace).
-		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+		UpdateStatus(ctx.Context, updated, metav1.UpdateOptions{})
 	if err != nil {
 		log.Error("failed to update status", err)
+		return
 	}
+
+	conditionsutil.RecordConditionFailures(ctx.Recorder,
+		conditionsutil.ObjectReference(upstream, v1alpha1.SchemeGroupVersion.WithKind("ActiveDirectoryIdentityProvider")),
+		upstream.Status.Conditions, updated.Status.Conditions)
 }
 
 func microsoftUUIDFromBinaryAttr(attributeName string) func(entry *ldap.Entry) (string, error) {
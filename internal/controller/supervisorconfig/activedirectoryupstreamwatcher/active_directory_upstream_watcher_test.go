@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package activedirectoryupstreamwatcher
@@ -29,6 +29,7 @@ import (
 	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/grouptransform"
 	"go.pinniped.dev/internal/mocks/mockldapconn"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/testutil"
@@ -234,8 +235,8 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 	providerConfigForValidUpstreamWithStartTLS := &copyOfProviderConfigForValidUpstreamWithTLS
 	providerConfigForValidUpstreamWithStartTLS.ConnectionProtocol = upstreamldap.StartTLS
 
-	bindSecretValidTrueCondition := func(gen int64) v1alpha1.Condition {
-		return v1alpha1.Condition{
+	bindSecretValidTrueCondition := func(gen int64) metav1.Condition {
+		return metav1.Condition{
 			Type:               "BindSecretValid",
 			Status:             "True",
 			LastTransitionTime: now,
@@ -244,8 +245,8 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			ObservedGeneration: gen,
 		}
 	}
-	activeDirectoryConnectionValidTrueCondition := func(gen int64, secretVersion string) v1alpha1.Condition {
-		return v1alpha1.Condition{
+	activeDirectoryConnectionValidTrueCondition := func(gen int64, secretVersion string) metav1.Condition {
+		return metav1.Condition{
 			Type:               "LDAPConnectionValid",
 			Status:             "True",
 			LastTransitionTime: now,
@@ -256,21 +257,31 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			ObservedGeneration: gen,
 		}
 	}
-	activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration := func(secretVersion string) v1alpha1.Condition {
+	activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration := func(secretVersion string) metav1.Condition {
 		c := activeDirectoryConnectionValidTrueCondition(0, secretVersion)
 		c.LastTransitionTime = metav1.Time{}
 		return c
 	}
-	condPtr := func(c v1alpha1.Condition) *v1alpha1.Condition {
+	condPtr := func(c metav1.Condition) *metav1.Condition {
 		return &c
 	}
-	withoutTime := func(c v1alpha1.Condition) v1alpha1.Condition {
+	withoutTime := func(c metav1.Condition) metav1.Condition {
 		c = *c.DeepCopy()
 		c.LastTransitionTime = metav1.Time{}
 		return c
 	}
-	tlsConfigurationValidLoadedTrueCondition := func(gen int64) v1alpha1.Condition {
-		return v1alpha1.Condition{
+	groupTransformationsValidTrueCondition := func(gen int64) metav1.Condition {
+		return metav1.Condition{
+			Type:               "GroupTransformationsValid",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "Success",
+			Message:            "loaded group transformations",
+			ObservedGeneration: gen,
+		}
+	}
+	tlsConfigurationValidLoadedTrueCondition := func(gen int64) metav1.Condition {
+		return metav1.Condition{
 			Type:               "TLSConfigurationValid",
 			Status:             "True",
 			LastTransitionTime: now,
@@ -280,8 +291,8 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	searchBaseFoundInRootDSECondition := func(gen int64) v1alpha1.Condition {
-		return v1alpha1.Condition{
+	searchBaseFoundInRootDSECondition := func(gen int64) metav1.Condition {
+		return metav1.Condition{
 			Type:               "SearchBaseFound",
 			Status:             "True",
 			LastTransitionTime: now,
@@ -291,8 +302,8 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	searchBaseFoundInConfigCondition := func(gen int64) v1alpha1.Condition {
-		return v1alpha1.Condition{
+	searchBaseFoundInConfigCondition := func(gen int64) metav1.Condition {
+		return metav1.Condition{
 			Type:               "SearchBaseFound",
 			Status:             "True",
 			LastTransitionTime: now,
@@ -302,8 +313,8 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	searchBaseFoundErrorCondition := func(gen int64, message string) v1alpha1.Condition {
-		return v1alpha1.Condition{
+	searchBaseFoundErrorCondition := func(gen int64, message string) metav1.Condition {
+		return metav1.Condition{
 			Type:               "SearchBaseFound",
 			Status:             "False",
 			LastTransitionTime: now,
@@ -313,9 +324,10 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	allConditionsTrue := func(gen int64, secretVersion string) []v1alpha1.Condition {
-		return []v1alpha1.Condition{
+	allConditionsTrue := func(gen int64, secretVersion string) []metav1.Condition {
+		return []metav1.Condition{
 			bindSecretValidTrueCondition(gen),
+			groupTransformationsValidTrueCondition(gen),
 			activeDirectoryConnectionValidTrueCondition(gen, secretVersion),
 			searchBaseFoundInConfigCondition(gen),
 			tlsConfigurationValidLoadedTrueCondition(gen),
@@ -411,7 +423,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						{
 							Type:               "BindSecretValid",
 							Status:             "False",
@@ -420,6 +432,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`secret "%s" not found`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						groupTransformationsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
 					},
 				},
@@ -439,7 +452,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						{
 							Type:               "BindSecretValid",
 							Status:             "False",
@@ -448,6 +461,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`referenced Secret "%s" has wrong type "some-other-type" (should be "kubernetes.io/basic-auth")`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						groupTransformationsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
 					},
 				},
@@ -466,7 +480,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						{
 							Type:               "BindSecretValid",
 							Status:             "False",
@@ -475,6 +489,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`referenced Secret "%s" is missing required keys ["username" "password"]`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						groupTransformationsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
 					},
 				},
@@ -492,8 +507,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "False",
@@ -518,8 +534,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "False",
@@ -575,8 +592,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundInConfigCondition(1234),
 						{
@@ -644,8 +662,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundInConfigCondition(1234),
 						{
@@ -716,8 +735,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "True",
@@ -739,7 +759,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				UserSearchBase:            testUserSearchBase,
 				GroupSearchBase:           testGroupSearchBase,
 				IDPSpecGeneration:         1234,
-				ConnectionValidCondition: &v1alpha1.Condition{
+				ConnectionValidCondition: &metav1.Condition{
 					Type:   "LDAPConnectionValid",
 					Status: "True",
 					Reason: "Success",
@@ -796,8 +816,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -892,7 +913,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "other-upstream", Generation: 42, UID: "other-uid"},
 					Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 						Phase: "Error",
-						Conditions: []v1alpha1.Condition{
+						Conditions: []metav1.Condition{
 							{
 								Type:               "BindSecretValid",
 								Status:             "False",
@@ -901,6 +922,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 								Message:            fmt.Sprintf(`secret "%s" not found`, "non-existent-secret"),
 								ObservedGeneration: 42,
 							},
+							groupTransformationsValidTrueCondition(42),
 							tlsConfigurationValidLoadedTrueCondition(42),
 						},
 					},
@@ -941,8 +963,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -1008,8 +1031,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -1044,8 +1068,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -1067,7 +1092,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection was already validated using TLS for the current resource generation and secret version, then do not validate it again and keep using TLS",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 					searchBaseFoundInConfigCondition(1234),
 				}
@@ -1109,7 +1134,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			// validatedsettings cache invalid.
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 				}
 				upstream.Spec.UserSearch.Base = ""
@@ -1156,8 +1181,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundInRootDSECondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1178,7 +1204,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection was already validated using TLS, and the search base was found, load TLS and search base info into the cache",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 					searchBaseFoundInRootDSECondition(1234),
 				}
@@ -1228,8 +1254,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundInRootDSECondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1250,7 +1277,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection was already validated using StartTLS for the current resource generation and secret version, then do not validate it again and keep using StartTLS",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 					searchBaseFoundInConfigCondition(1234),
 				}
@@ -1290,7 +1317,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection was validated for an older resource generation, then try to validate it again",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
 				upstream.Generation = 1234 // current generation
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					activeDirectoryConnectionValidTrueCondition(1233, "4242"), // older spec generation!
 				}
 			})},
@@ -1331,7 +1358,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection condition failed to update previously, then write the cached condition from the previous connection validation",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
 				upstream.Generation = 1234 // current generation
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					activeDirectoryConnectionValidTrueCondition(1234, "4200"), // old version of the condition, as if the previous update of conditions had failed
 				}
 			})},
@@ -1371,7 +1398,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection validation previously failed for this resource generation, then try to validate it again",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					{
 						Type:               "LDAPConnectionValid",
 						Status:             "False", // failure!
@@ -1410,7 +1437,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection was already validated for this resource generation but the bind secret has changed, then try to validate it again",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					activeDirectoryConnectionValidTrueCondition(1234, "4241"), // same spec generation, old secret version
 				}
 			})},
@@ -1553,8 +1580,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundInRootDSECondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1616,8 +1644,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundInRootDSECondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1679,8 +1708,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundInRootDSECondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1715,8 +1745,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundErrorCondition(1234, "Error finding search base: error querying RootDSE for defaultNamingContext: some error"),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1751,8 +1782,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundErrorCondition(1234, "Error finding search base: error querying RootDSE for defaultNamingContext: empty search base DN found"),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1793,8 +1825,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundErrorCondition(1234, "Error finding search base: error querying RootDSE for defaultNamingContext: expected to find 1 entry but found 2"),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1822,8 +1855,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundErrorCondition(1234, "Error finding search base: error querying RootDSE for defaultNamingContext: expected to find 1 entry but found 0"),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1836,7 +1870,7 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when search base was previously found but the bind secret has changed",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					searchBaseFoundInRootDSECondition(1234),
 				}
 				upstream.Spec.UserSearch.Attributes = v1alpha1.ActiveDirectoryIdentityProviderUserSearchAttributes{}
@@ -1890,8 +1924,9 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: testResourceUID, Generation: 1234},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundInRootDSECondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
@@ -1952,8 +1987,82 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
+						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
+						searchBaseFoundInConfigCondition(1234),
+						{
+							Type:               "TLSConfigurationValid",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message:            "loaded TLS configuration",
+							ObservedGeneration: 1234,
+						},
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(activeDirectoryConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+				SearchBaseFoundCondition:  condPtr(withoutTime(searchBaseFoundInConfigCondition(0))),
+			}},
+		},
+		{
+			name: "valid group transformations are loaded",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.ActiveDirectoryIdentityProvider) {
+				upstream.Spec.GroupSearch.Transformations = []v1alpha1.GroupTransformation{
+					{Type: v1alpha1.GroupTransformationTypeStripDN},
+					{Type: v1alpha1.GroupTransformationTypeDrop, Pattern: "Domain Users"},
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+						Transformations:    make(grouptransform.Transformations, 2),
+					},
+					UIDAttributeParsingOverrides: map[string]func(*ldap.Entry) (string, error){"objectGUID": microsoftUUIDFromBinaryAttr("objectGUID")},
+					RefreshAttributeChecks: map[string]func(*ldap.Entry, provider.RefreshAttributes) error{
+						"pwdLastSet":                         upstreamldap.AttributeUnchangedSinceLogin("pwdLastSet"),
+						"userAccountControl":                 validUserAccountControl,
+						"msDS-User-Account-Control-Computed": validComputedUserAccountControl,
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.ActiveDirectoryIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.ActiveDirectoryIdentityProviderStatus{
+					Phase: "Ready",
+					Conditions: []metav1.Condition{
+						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						activeDirectoryConnectionValidTrueCondition(1234, "4242"),
 						searchBaseFoundInConfigCondition(1234),
 						{
@@ -2091,6 +2200,11 @@ func TestActiveDirectoryUpstreamWatcherControllerSync(t *testing.T) {
 					require.Equal(t, reflect.ValueOf(v).Pointer(), reflect.ValueOf(actualRefreshAttributeChecks[k]).Pointer())
 				}
 
+				// Compiled group transformations are functions too, so only compare how many there are.
+				require.Len(t, actualConfig.GroupSearch.Transformations, len(copyOfExpectedValueForResultingCache.GroupSearch.Transformations))
+				copyOfExpectedValueForResultingCache.GroupSearch.Transformations = nil
+				actualConfig.GroupSearch.Transformations = nil
+
 				require.Equal(t, copyOfExpectedValueForResultingCache, actualConfig)
 			}
 
@@ -0,0 +1,272 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/utils/clock"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
+	configinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/config/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/conditionsutil"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	typeTLSCertificateValid = "TLSCertificateValid"
+
+	reasonSuccess                 = "Success"
+	reasonDefaultCertificate      = "DefaultCertificate"
+	reasonSecretNotFound          = "SecretNotFound"
+	reasonInvalidCertificate      = "InvalidCertificate"
+	reasonCertificateNotYetValid  = "CertificateNotYetValid"
+	reasonCertificateExpired      = "CertificateExpired"
+	reasonCertificateExpiringSoon = "CertificateExpiringSoon"
+	reasonHostnameMismatch        = "HostnameMismatch"
+
+	// certificateExpiringSoonThreshold is how long before its expiration a certificate is reported as expiring soon.
+	// Tools like cert-manager renew certificates well before this, so a certificate this close to its expiration
+	// probably means that its renewal has failed.
+	certificateExpiringSoonThreshold = 7 * 24 * time.Hour
+)
+
+type federationDomainTLSStatusController struct {
+	client                   pinnipedclientset.Interface
+	clock                    clock.Clock
+	secretInformer           corev1informers.SecretInformer
+	federationDomainInformer configinformers.FederationDomainInformer
+}
+
+// NewFederationDomainTLSStatusController creates a controllerlib.Controller that watches FederationDomains and
+// their TLS Secrets, and updates the TLSCertificateValid condition of each FederationDomain. The certificates
+// themselves are loaded by the controller returned by NewTLSCertObserverController.
+func NewFederationDomainTLSStatusController(
+	client pinnipedclientset.Interface,
+	clock clock.Clock,
+	secretInformer corev1informers.SecretInformer,
+	federationDomainInformer configinformers.FederationDomainInformer,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "federation-domain-tls-status-controller",
+			Syncer: &federationDomainTLSStatusController{
+				client:                   client,
+				clock:                    clock,
+				secretInformer:           secretInformer,
+				federationDomainInformer: federationDomainInformer,
+			},
+		},
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.MatchAnySecretOfTypeFilter(corev1.SecretTypeTLS, pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+		withInformer(
+			federationDomainInformer,
+			pinnipedcontroller.MatchAnythingFilter(pinnipedcontroller.SingletonQueue()),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *federationDomainTLSStatusController) Sync(ctx controllerlib.Context) error {
+	federationDomains, err := c.federationDomainInformer.Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list FederationDomains: %w", err)
+	}
+
+	now := c.clock.Now()
+
+	// The condition of a certificate changes with time, e.g. when it expires, so remember the next time at which
+	// any of the conditions will change without any Secret or FederationDomain changing.
+	var nextTransition time.Time
+
+	var errs []error
+	for _, federationDomain := range federationDomains {
+		condition, transition, err := c.tlsCertificateCondition(federationDomain, now)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !transition.IsZero() && (nextTransition.IsZero() || transition.Before(nextTransition)) {
+			nextTransition = transition
+		}
+
+		if err := c.updateStatus(ctx, federationDomain, condition); err != nil {
+			errs = append(errs, fmt.Errorf("could not update status of FederationDomain %s/%s: %w",
+				federationDomain.Namespace, federationDomain.Name, err))
+		}
+	}
+
+	if !nextTransition.IsZero() {
+		ctx.Queue.AddAfter(ctx.Key, nextTransition.Sub(now))
+	}
+
+	return errors.NewAggregate(errs)
+}
+
+// tlsCertificateCondition returns the TLSCertificateValid condition of the FederationDomain at the given time,
+// and the time at which the condition will change by itself, or a zero time when it will not.
+func (c *federationDomainTLSStatusController) tlsCertificateCondition(
+	federationDomain *configv1alpha1.FederationDomain,
+	now time.Time,
+) (*metav1.Condition, time.Time, error) {
+	if federationDomain.Spec.TLS == nil || federationDomain.Spec.TLS.SecretName == "" {
+		return &metav1.Condition{
+			Type:    typeTLSCertificateValid,
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonDefaultCertificate,
+			Message: "no TLS secretName is configured, so the default TLS certificate will be used",
+		}, time.Time{}, nil
+	}
+	secretName := federationDomain.Spec.TLS.SecretName
+
+	secret, err := c.secretInformer.Lister().Secrets(federationDomain.Namespace).Get(secretName)
+	if k8serrors.IsNotFound(err) {
+		return &metav1.Condition{
+			Type:    typeTLSCertificateValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonSecretNotFound,
+			Message: fmt.Sprintf("secret %q not found", secretName),
+		}, time.Time{}, nil
+	}
+	if err != nil {
+		// Anything other than a NotFound error is unexpected when reading from an informer.
+		return nil, time.Time{}, fmt.Errorf("failed to get %s/%s secret: %w", federationDomain.Namespace, secretName, err)
+	}
+
+	leaf, err := leafCertificateFromSecret(secret)
+	if err != nil {
+		return &metav1.Condition{
+			Type:    typeTLSCertificateValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonInvalidCertificate,
+			Message: fmt.Sprintf("secret %q does not contain a valid TLS certificate and private key: %s", secretName, err.Error()),
+		}, time.Time{}, nil
+	}
+
+	if now.Before(leaf.NotBefore) {
+		return &metav1.Condition{
+			Type:    typeTLSCertificateValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonCertificateNotYetValid,
+			Message: fmt.Sprintf("the certificate in secret %q is not valid before %s", secretName, formatTime(leaf.NotBefore)),
+		}, leaf.NotBefore, nil
+	}
+
+	if !now.Before(leaf.NotAfter) {
+		return &metav1.Condition{
+			Type:    typeTLSCertificateValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonCertificateExpired,
+			Message: fmt.Sprintf("the certificate in secret %q expired at %s", secretName, formatTime(leaf.NotAfter)),
+		}, time.Time{}, nil
+	}
+
+	if hostname := findHostnameNotValidForCertificate(federationDomain, leaf); hostname != "" {
+		return &metav1.Condition{
+			Type:    typeTLSCertificateValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonHostnameMismatch,
+			Message: fmt.Sprintf("the certificate in secret %q is not valid for hostname %q", secretName, hostname),
+		}, leaf.NotAfter, nil
+	}
+
+	expiringSoon := leaf.NotAfter.Add(-certificateExpiringSoonThreshold)
+	if !now.Before(expiringSoon) {
+		return &metav1.Condition{
+			Type:    typeTLSCertificateValid,
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonCertificateExpiringSoon,
+			Message: fmt.Sprintf("the certificate in secret %q expires soon at %s", secretName, formatTime(leaf.NotAfter)),
+		}, leaf.NotAfter, nil
+	}
+
+	return &metav1.Condition{
+		Type:    typeTLSCertificateValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonSuccess,
+		Message: fmt.Sprintf("the certificate in secret %q is valid until %s", secretName, formatTime(leaf.NotAfter)),
+	}, expiringSoon, nil
+}
+
+func (c *federationDomainTLSStatusController) updateStatus(
+	ctx controllerlib.Context,
+	federationDomain *configv1alpha1.FederationDomain,
+	condition *metav1.Condition,
+) error {
+	updated := federationDomain.DeepCopy()
+
+	_ = conditionsutil.MergeConditions(
+		[]*metav1.Condition{condition},
+		federationDomain.Generation,
+		&updated.Status.Conditions,
+		plog.WithValues("namespace", federationDomain.Namespace, "name", federationDomain.Name),
+	)
+
+	if equality.Semantic.DeepEqual(federationDomain, updated) {
+		return nil
+	}
+
+	_, err := c.client.ConfigV1alpha1().FederationDomains(federationDomain.Namespace).UpdateStatus(ctx.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	conditionsutil.RecordConditionFailures(ctx.Recorder,
+		conditionsutil.ObjectReference(federationDomain, configv1alpha1.SchemeGroupVersion.WithKind("FederationDomain")),
+		federationDomain.Status.Conditions, updated.Status.Conditions)
+	return nil
+}
+
+func leafCertificateFromSecret(secret *corev1.Secret) (*x509.Certificate, error) {
+	certificate, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(certificate.Certificate[0])
+}
+
+// findHostnameNotValidForCertificate returns the first hostname of the issuer and alias hosts of the
+// FederationDomain for which the certificate is not valid, or an empty string when there are none.
+// IP addresses are skipped because SNI does not work for them, so the certificate is not used for them.
+func findHostnameNotValidForCertificate(federationDomain *configv1alpha1.FederationDomain, leaf *x509.Certificate) string {
+	issuerURL, err := url.Parse(federationDomain.Spec.Issuer)
+	if err != nil {
+		return "" // Invalid issuer URLs are reported in the status by the FederationDomainWatcherController.
+	}
+	for _, u := range issuerURLsForAllHosts(issuerURL, federationDomain.Spec.AliasHosts) {
+		hostname := lowercaseHostWithoutPort(u)
+		if net.ParseIP(hostname) != nil {
+			continue
+		}
+		if err := leaf.VerifyHostname(hostname); err != nil {
+			return hostname
+		}
+	}
+	return ""
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,226 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
+	"go.pinniped.dev/internal/certauthority"
+	"go.pinniped.dev/internal/controllerlib"
+)
+
+func TestFederationDomainTLSStatusControllerSync(t *testing.T) {
+	t.Parallel()
+
+	const (
+		namespace  = "some-namespace"
+		secretName = "some-tls-secret"
+	)
+
+	ca, err := certauthority.New("Test CA", time.Hour)
+	require.NoError(t, err)
+	certPEM, keyPEM, err := ca.IssueServerCertPEM([]string{"issuer.example.com", "alias.example.com"}, nil, 30*24*time.Hour)
+	require.NoError(t, err)
+	block, _ := pem.Decode(certPEM)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	tlsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+	}
+	invalidTLSSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("not a cert"), corev1.TLSPrivateKeyKey: keyPEM},
+	}
+
+	federationDomain := func(tls *configv1alpha1.FederationDomainTLSSpec, aliasHosts ...string) *configv1alpha1.FederationDomain {
+		return &configv1alpha1.FederationDomain{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-federation-domain", Namespace: namespace, Generation: 42},
+			Spec: configv1alpha1.FederationDomainSpec{
+				Issuer:     "https://issuer.example.com:8443/some/path",
+				AliasHosts: aliasHosts,
+				TLS:        tls,
+			},
+		}
+	}
+	withSecret := &configv1alpha1.FederationDomainTLSSpec{SecretName: secretName}
+
+	condition := func(status metav1.ConditionStatus, reason, message string) metav1.Condition {
+		return metav1.Condition{
+			Type:               "TLSCertificateValid",
+			Status:             status,
+			ObservedGeneration: 42,
+			Reason:             reason,
+			Message:            message,
+		}
+	}
+
+	notAfter := leaf.NotAfter.UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name             string
+		federationDomain *configv1alpha1.FederationDomain
+		secrets          []runtime.Object
+		now              time.Time
+		wantCondition    metav1.Condition
+		wantRequeueAfter time.Duration
+	}{
+		{
+			name:             "no TLS secretName is configured",
+			federationDomain: federationDomain(nil),
+			now:              leaf.NotBefore,
+			wantCondition: condition(metav1.ConditionTrue, "DefaultCertificate",
+				"no TLS secretName is configured, so the default TLS certificate will be used"),
+		},
+		{
+			name:             "the TLS secret does not exist",
+			federationDomain: federationDomain(withSecret),
+			now:              leaf.NotBefore,
+			wantCondition:    condition(metav1.ConditionFalse, "SecretNotFound", `secret "some-tls-secret" not found`),
+		},
+		{
+			name:             "the TLS secret does not contain a valid certificate",
+			federationDomain: federationDomain(withSecret),
+			secrets:          []runtime.Object{invalidTLSSecret},
+			now:              leaf.NotBefore,
+			wantCondition: condition(metav1.ConditionFalse, "InvalidCertificate",
+				`secret "some-tls-secret" does not contain a valid TLS certificate and private key: tls: failed to find any PEM data in certificate input`),
+		},
+		{
+			name:             "the certificate is valid for the issuer and alias hosts, ignoring alias IP addresses",
+			federationDomain: federationDomain(withSecret, "ALIAS.example.com:1234", "127.0.0.1"),
+			secrets:          []runtime.Object{tlsSecret},
+			now:              leaf.NotBefore,
+			wantCondition: condition(metav1.ConditionTrue, "Success",
+				`the certificate in secret "some-tls-secret" is valid until `+notAfter),
+			wantRequeueAfter: leaf.NotAfter.Add(-7 * 24 * time.Hour).Sub(leaf.NotBefore),
+		},
+		{
+			name:             "the certificate is not valid for an alias host",
+			federationDomain: federationDomain(withSecret, "alias.example.com", "other.example.com"),
+			secrets:          []runtime.Object{tlsSecret},
+			now:              leaf.NotBefore,
+			wantCondition: condition(metav1.ConditionFalse, "HostnameMismatch",
+				`the certificate in secret "some-tls-secret" is not valid for hostname "other.example.com"`),
+			wantRequeueAfter: leaf.NotAfter.Sub(leaf.NotBefore),
+		},
+		{
+			name:             "the certificate expires soon",
+			federationDomain: federationDomain(withSecret),
+			secrets:          []runtime.Object{tlsSecret},
+			now:              leaf.NotAfter.Add(-time.Hour),
+			wantCondition: condition(metav1.ConditionTrue, "CertificateExpiringSoon",
+				`the certificate in secret "some-tls-secret" expires soon at `+notAfter),
+			wantRequeueAfter: time.Hour,
+		},
+		{
+			name:             "the certificate has expired",
+			federationDomain: federationDomain(withSecret),
+			secrets:          []runtime.Object{tlsSecret},
+			now:              leaf.NotAfter,
+			wantCondition: condition(metav1.ConditionFalse, "CertificateExpired",
+				`the certificate in secret "some-tls-secret" expired at `+notAfter),
+		},
+		{
+			name:             "the certificate is not valid yet",
+			federationDomain: federationDomain(withSecret),
+			secrets:          []runtime.Object{tlsSecret},
+			now:              leaf.NotBefore.Add(-time.Minute),
+			wantCondition: condition(metav1.ConditionFalse, "CertificateNotYetValid",
+				`the certificate in secret "some-tls-secret" is not valid before `+leaf.NotBefore.UTC().Format(time.RFC3339)),
+			wantRequeueAfter: time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pinnipedAPIClient := pinnipedfake.NewSimpleClientset(tt.federationDomain)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(pinnipedfake.NewSimpleClientset(tt.federationDomain), 0)
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(tt.secrets...), 0)
+
+			subject := NewFederationDomainTLSStatusController(
+				pinnipedAPIClient,
+				clocktesting.NewFakeClock(tt.now),
+				kubeInformers.Core().V1().Secrets(),
+				pinnipedInformers.Config().V1alpha1().FederationDomains(),
+				controllerlib.WithInformer,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedInformers.Start(ctx.Done())
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			queue := &fakeAddAfterQueue{t: t}
+			require.NoError(t, controllerlib.TestSync(t, subject, controllerlib.Context{Context: ctx, Name: subject.Name(), Queue: queue}))
+
+			require.Equal(t, tt.wantRequeueAfter != 0, queue.called)
+			require.Equal(t, tt.wantRequeueAfter, queue.duration)
+
+			actual, err := pinnipedAPIClient.ConfigV1alpha1().FederationDomains(namespace).Get(ctx, tt.federationDomain.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Len(t, actual.Status.Conditions, 1)
+			require.NotZero(t, actual.Status.Conditions[0].LastTransitionTime)
+			actual.Status.Conditions[0].LastTransitionTime = metav1.Time{}
+			require.Equal(t, tt.wantCondition, actual.Status.Conditions[0])
+
+			// Syncing again without any changes should not update the FederationDomain again.
+			pinnipedAPIClient.ClearActions()
+			require.NoError(t, pinnipedInformers.Config().V1alpha1().FederationDomains().Informer().GetIndexer().Update(actualWithCondition(t, actual, tt.wantCondition)))
+			require.NoError(t, controllerlib.TestSync(t, subject, controllerlib.Context{Context: ctx, Name: subject.Name(), Queue: &fakeAddAfterQueue{t: t}}))
+			for _, action := range pinnipedAPIClient.Actions() {
+				require.NotEqual(t, "update", action.GetVerb())
+			}
+		})
+	}
+}
+
+func actualWithCondition(t *testing.T, federationDomain *configv1alpha1.FederationDomain, condition metav1.Condition) *configv1alpha1.FederationDomain {
+	t.Helper()
+
+	updated := federationDomain.DeepCopy()
+	condition.LastTransitionTime = metav1.Now()
+	updated.Status.Conditions = []metav1.Condition{condition}
+	return updated
+}
+
+type fakeAddAfterQueue struct {
+	t *testing.T
+
+	called   bool
+	duration time.Duration
+
+	controllerlib.Queue // panic if any other methods called
+}
+
+func (q *fakeAddAfterQueue) AddAfter(_ controllerlib.Key, duration time.Duration) {
+	q.t.Helper()
+
+	require.False(q.t, q.called, "AddAfter should only be called once")
+
+	q.called = true
+	q.duration = duration
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -21,6 +22,7 @@ import (
 	configinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/config/v1alpha1"
 	pinnipedcontroller "go.pinniped.dev/internal/controller"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
 )
@@ -73,6 +75,12 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 		return err
 	}
 
+	now := c.clock.Now()
+
+	// Remember the earliest end of the grace period of an issuer migration, because the previous issuer
+	// must stop being served at that time without any FederationDomain changing.
+	var nextMigrationEnd time.Time
+
 	// Make a map of issuer strings -> count of how many times we saw that issuer string.
 	// This will help us complain when there are duplicate issuer strings.
 	// Also make a helper function for forming keys into this map.
@@ -95,15 +103,26 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 			continue // Skip url parse errors because they will be validated again below.
 		}
 
-		issuerCounts[issuerURLToIssuerKey(issuerURL)]++
+		// Each alias host of a FederationDomain serves its issuer too, and so does its previous issuer during an
+		// issuer migration, so they must not conflict with other FederationDomains either. Only count each issuer
+		// once per FederationDomain, because alias hosts or previous issuers which are the same as the issuer will be
+		// reported as invalid below instead.
+		seenIssuerKeys := make(map[string]bool)
+		issuerURLs := issuerURLsForAllHosts(issuerURL, federationDomain.Spec.AliasHosts)
+		for _, u := range withPreviousIssuerURL(issuerURLs, activeIssuerMigration(federationDomain, now)) {
+			if !seenIssuerKeys[issuerURLToIssuerKey(u)] {
+				seenIssuerKeys[issuerURLToIssuerKey(u)] = true
+				issuerCounts[issuerURLToIssuerKey(u)]++
+			}
 
-		setOfSecretNames := uniqueSecretNamesPerIssuerAddress[issuerURLToHostnameKey(issuerURL)]
-		if setOfSecretNames == nil {
-			setOfSecretNames = make(map[string]bool)
-			uniqueSecretNamesPerIssuerAddress[issuerURLToHostnameKey(issuerURL)] = setOfSecretNames
-		}
-		if federationDomain.Spec.TLS != nil {
-			setOfSecretNames[federationDomain.Spec.TLS.SecretName] = true
+			setOfSecretNames := uniqueSecretNamesPerIssuerAddress[issuerURLToHostnameKey(u)]
+			if setOfSecretNames == nil {
+				setOfSecretNames = make(map[string]bool)
+				uniqueSecretNamesPerIssuerAddress[issuerURLToHostnameKey(u)] = setOfSecretNames
+			}
+			if federationDomain.Spec.TLS != nil {
+				setOfSecretNames[federationDomain.Spec.TLS.SecretName] = true
+			}
 		}
 	}
 
@@ -111,39 +130,58 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 
 	federationDomainIssuers := make([]*provider.FederationDomainIssuer, 0)
 	for _, federationDomain := range federationDomains {
-		issuerURL, urlParseErr := url.Parse(federationDomain.Spec.Issuer)
-
+		var issuerURLs []*url.URL
 		// Skip url parse errors because they will be validated below.
-		if urlParseErr == nil {
-			if issuerCount := issuerCounts[issuerURLToIssuerKey(issuerURL)]; issuerCount > 1 {
-				if err := c.updateStatus(
-					ctx.Context,
-					federationDomain.Namespace,
-					federationDomain.Name,
-					configv1alpha1.DuplicateFederationDomainStatusCondition,
-					"Duplicate issuer: "+federationDomain.Spec.Issuer,
-				); err != nil {
-					errs = append(errs, fmt.Errorf("could not update status: %w", err))
-				}
-				continue
+		if issuerURL, urlParseErr := url.Parse(federationDomain.Spec.Issuer); urlParseErr == nil {
+			issuerURLs = issuerURLsForAllHosts(issuerURL, federationDomain.Spec.AliasHosts)
+		}
+		issuerMigration := activeIssuerMigration(federationDomain, now)
+		issuerURLs = withPreviousIssuerURL(issuerURLs, issuerMigration)
+
+		if duplicateIssuer := findIssuerWithCountAbove1(issuerURLs, issuerCounts, issuerURLToIssuerKey); duplicateIssuer != "" {
+			if err := c.updateStatus(
+				ctx.Context,
+				federationDomain.Namespace,
+				federationDomain.Name,
+				configv1alpha1.DuplicateFederationDomainStatusCondition,
+				"Duplicate issuer: "+duplicateIssuer,
+			); err != nil {
+				errs = append(errs, fmt.Errorf("could not update status: %w", err))
 			}
+			continue
 		}
 
-		// Skip url parse errors because they will be validated below.
-		if urlParseErr == nil && len(uniqueSecretNamesPerIssuerAddress[issuerURLToHostnameKey(issuerURL)]) > 1 {
+		if hostname := findHostnameWithMultipleSecretNames(issuerURLs, uniqueSecretNamesPerIssuerAddress, issuerURLToHostnameKey); hostname != "" {
 			if err := c.updateStatus(
 				ctx.Context,
 				federationDomain.Namespace,
 				federationDomain.Name,
 				configv1alpha1.SameIssuerHostMustUseSameSecretFederationDomainStatusCondition,
-				"Issuers with the same DNS hostname (address not including port) must use the same secretName: "+issuerURLToHostnameKey(issuerURL),
+				"Issuers with the same DNS hostname (address not including port) must use the same secretName: "+hostname,
 			); err != nil {
 				errs = append(errs, fmt.Errorf("could not update status: %w", err))
 			}
 			continue
 		}
 
-		federationDomainIssuer, err := provider.NewFederationDomainIssuer(federationDomain.Spec.Issuer) // This validates the Issuer URL.
+		// This validates the Issuer URL, the alias hosts, the previous issuer, the allowed CORS origins, the second factor
+		// enforcements, and the login policy.
+		federationDomainIssuer, err := provider.NewFederationDomainIssuerWithAliasHosts(federationDomain.Spec.Issuer, federationDomain.Spec.AliasHosts)
+		if err == nil && issuerMigration != nil {
+			err = federationDomainIssuer.SetPreviousIssuer(issuerMigration.PreviousIssuer)
+		}
+		if err == nil {
+			err = federationDomainIssuer.SetAllowedCORSOrigins(federationDomain.Spec.AllowedCORSOrigins)
+		}
+		if err == nil && federationDomain.Spec.WebAuthn != nil {
+			err = federationDomainIssuer.SetWebAuthnEnforcement(provider.WebAuthnEnforcement(federationDomain.Spec.WebAuthn.Enforcement))
+		}
+		if err == nil && federationDomain.Spec.TOTP != nil {
+			err = federationDomainIssuer.SetTOTPEnforcement(provider.TOTPEnforcement(federationDomain.Spec.TOTP.Enforcement))
+		}
+		if err == nil {
+			err = federationDomainIssuer.SetLoginPolicy(loginpolicy.ConfigFromSpec(federationDomain.Spec.LoginPolicy))
+		}
 		if err != nil {
 			if err := c.updateStatus(
 				ctx.Context,
@@ -169,13 +207,77 @@ func (c *federationDomainWatcherController) Sync(ctx controllerlib.Context) erro
 		}
 
 		federationDomainIssuers = append(federationDomainIssuers, federationDomainIssuer)
+
+		if issuerMigration != nil && (nextMigrationEnd.IsZero() || issuerMigration.AcceptUntil.Time.Before(nextMigrationEnd)) {
+			nextMigrationEnd = issuerMigration.AcceptUntil.Time
+		}
 	}
 
 	c.providerSetter.SetProviders(federationDomainIssuers...)
 
+	if !nextMigrationEnd.IsZero() {
+		ctx.Queue.AddAfter(ctx.Key, nextMigrationEnd.Sub(now))
+	}
+
 	return errors.NewAggregate(errs)
 }
 
+// issuerURLsForAllHosts returns the issuer URL followed by the issuer URL as seen from each of the alias hosts.
+func issuerURLsForAllHosts(issuerURL *url.URL, aliasHosts []string) []*url.URL {
+	issuerURLs := []*url.URL{issuerURL}
+	for _, aliasHost := range aliasHosts {
+		aliasURL := *issuerURL
+		aliasURL.Host = aliasHost
+		issuerURLs = append(issuerURLs, &aliasURL)
+	}
+	return issuerURLs
+}
+
+// activeIssuerMigration returns the issuer migration of the FederationDomain while its previous issuer is still
+// accepted at the given time, or nil otherwise.
+func activeIssuerMigration(federationDomain *configv1alpha1.FederationDomain, now time.Time) *configv1alpha1.FederationDomainIssuerMigration {
+	issuerMigration := federationDomain.Spec.IssuerMigration
+	if issuerMigration == nil || !now.Before(issuerMigration.AcceptUntil.Time) {
+		return nil
+	}
+	return issuerMigration
+}
+
+// withPreviousIssuerURL returns the issuer URLs followed by the URL of the previous issuer of the issuer migration,
+// if any. Previous issuers which are not URLs are skipped, because they are reported as invalid elsewhere.
+func withPreviousIssuerURL(issuerURLs []*url.URL, issuerMigration *configv1alpha1.FederationDomainIssuerMigration) []*url.URL {
+	if issuerMigration == nil {
+		return issuerURLs
+	}
+	previousIssuerURL, err := url.Parse(issuerMigration.PreviousIssuer)
+	if err != nil {
+		return issuerURLs
+	}
+	return append(issuerURLs, previousIssuerURL)
+}
+
+// findIssuerWithCountAbove1 returns the first of the issuer URLs which is used by more than one FederationDomain,
+// or an empty string when there are none.
+func findIssuerWithCountAbove1(issuerURLs []*url.URL, issuerCounts map[string]int, issuerURLToIssuerKey func(*url.URL) string) string {
+	for _, u := range issuerURLs {
+		if issuerCounts[issuerURLToIssuerKey(u)] > 1 {
+			return u.String()
+		}
+	}
+	return ""
+}
+
+// findHostnameWithMultipleSecretNames returns the first hostname of the issuer URLs which is used with more than one
+// TLS secretName, or an empty string when there are none.
+func findHostnameWithMultipleSecretNames(issuerURLs []*url.URL, uniqueSecretNamesPerIssuerAddress map[string]map[string]bool, issuerURLToHostnameKey func(*url.URL) string) string {
+	for _, u := range issuerURLs {
+		if len(uniqueSecretNamesPerIssuerAddress[issuerURLToHostnameKey(u)]) > 1 {
+			return issuerURLToHostnameKey(u)
+		}
+	}
+	return ""
+}
+
 func (c *federationDomainWatcherController) updateStatus(
 	ctx context.Context,
 	namespace, name string,
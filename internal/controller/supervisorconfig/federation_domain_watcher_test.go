@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -16,7 +16,6 @@ import (
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 	"github.com/stretchr/testify/require"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -28,6 +27,7 @@ import (
 	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/testutil"
 )
@@ -398,18 +398,7 @@ func TestSync(t *testing.T) {
 
 			when("there is a conflict while updating an FederationDomain", func() {
 				it.Before(func() {
-					once := sync.Once{}
-					pinnipedAPIClient.PrependReactor(
-						"update",
-						"federationdomains",
-						func(_ coretesting.Action) (bool, runtime.Object, error) {
-							var err error
-							once.Do(func() {
-								err = k8serrors.NewConflict(schema.GroupResource{}, "", nil)
-							})
-							return true, nil, err
-						},
-					)
+					testutil.AddUpdateStatusConflictReactor(&pinnipedAPIClient.Fake, federationDomainGVR.GroupResource(), 1)
 				})
 
 				it("retries updating the FederationDomain", func() {
@@ -1014,6 +1003,355 @@ func TestSync(t *testing.T) {
 			})
 		})
 
+		when("there are FederationDomains with alias hosts in the informer", func() {
+			var federationDomainWithAliasHost *v1alpha1.FederationDomain
+
+			addFederationDomain := func(federationDomain *v1alpha1.FederationDomain) {
+				r.NoError(pinnipedAPIClient.Tracker().Add(federationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(federationDomain))
+			}
+
+			requireStatus := func(name string, wantStatus v1alpha1.FederationDomainStatusCondition, wantMessage string) {
+				federationDomain, err := pinnipedAPIClient.ConfigV1alpha1().FederationDomains(namespace).Get(context.Background(), name, metav1.GetOptions{})
+				r.NoError(err)
+				r.Equal(wantStatus, federationDomain.Status.Status)
+				r.Equal(wantMessage, federationDomain.Status.Message)
+			}
+
+			it.Before(func() {
+				federationDomainWithAliasHost = &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-alias", Namespace: namespace},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer:     "https://issuer.com/a",
+						AliasHosts: []string{"alias.example.com:8443"},
+					},
+				}
+				addFederationDomain(federationDomainWithAliasHost)
+
+				// The alias host of this one is the same as the issuer of the next one.
+				addFederationDomain(&v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-duplicate-alias", Namespace: namespace},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer:     "https://duplicate-alias-issuer.com/b",
+						AliasHosts: []string{"DUPLICATE.example.com"},
+					},
+				})
+				addFederationDomain(&v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "duplicate-of-alias", Namespace: namespace},
+					Spec:       v1alpha1.FederationDomainSpec{Issuer: "https://duplicate.example.com/b"},
+				})
+
+				// The alias host of this one has the same hostname as the issuer of the next one, but a different secretName.
+				addFederationDomain(&v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-alias-secret", Namespace: namespace},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer:     "https://alias-secret-issuer.com/c",
+						AliasHosts: []string{"shared-hostname.example.com"},
+						TLS:        &v1alpha1.FederationDomainTLSSpec{SecretName: "secret1"},
+					},
+				})
+				addFederationDomain(&v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-other-secret", Namespace: namespace},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://shared-hostname.example.com:1234/d",
+						TLS:    &v1alpha1.FederationDomainTLSSpec{SecretName: "secret2"},
+					},
+				})
+
+				addFederationDomain(&v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-invalid-alias", Namespace: namespace},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer:     "https://invalid-alias.com/e",
+						AliasHosts: []string{"invalid-alias.example.com/path"},
+					},
+				})
+			})
+
+			it("calls the ProvidersSetter with only the valid provider, including its alias hosts", func() {
+				startInformersAndController()
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				wantProvider, err := provider.NewFederationDomainIssuerWithAliasHosts(
+					federationDomainWithAliasHost.Spec.Issuer, federationDomainWithAliasHost.Spec.AliasHosts)
+				r.NoError(err)
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Equal([]*provider.FederationDomainIssuer{wantProvider}, providersSetter.FederationDomainsReceived)
+			})
+
+			it("updates the statuses", func() {
+				startInformersAndController()
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				requireStatus("with-alias", v1alpha1.SuccessFederationDomainStatusCondition,
+					"Provider successfully created")
+				requireStatus("with-duplicate-alias", v1alpha1.DuplicateFederationDomainStatusCondition,
+					"Duplicate issuer: https://DUPLICATE.example.com/b")
+				requireStatus("duplicate-of-alias", v1alpha1.DuplicateFederationDomainStatusCondition,
+					"Duplicate issuer: https://duplicate.example.com/b")
+				requireStatus("with-alias-secret", v1alpha1.SameIssuerHostMustUseSameSecretFederationDomainStatusCondition,
+					"Issuers with the same DNS hostname (address not including port) must use the same secretName: shared-hostname.example.com")
+				requireStatus("with-other-secret", v1alpha1.SameIssuerHostMustUseSameSecretFederationDomainStatusCondition,
+					"Issuers with the same DNS hostname (address not including port) must use the same secretName: shared-hostname.example.com")
+				requireStatus("with-invalid-alias", v1alpha1.InvalidFederationDomainStatusCondition,
+					`Invalid: alias host "invalid-alias.example.com/path" must be a hostname or IP address with an optional port`)
+			})
+		})
+
+		when("there are FederationDomains with issuer migrations in the informer", func() {
+			var queue *fakeAddAfterQueue
+
+			addFederationDomain := func(name, issuer, previousIssuer string, acceptUntil time.Time) {
+				federationDomain := &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+					Spec:       v1alpha1.FederationDomainSpec{Issuer: issuer},
+				}
+				if previousIssuer != "" {
+					federationDomain.Spec.IssuerMigration = &v1alpha1.FederationDomainIssuerMigration{
+						PreviousIssuer: previousIssuer,
+						AcceptUntil:    metav1.NewTime(acceptUntil),
+					}
+				}
+				r.NoError(pinnipedAPIClient.Tracker().Add(federationDomain))
+				r.NoError(federationDomainInformerClient.Tracker().Add(federationDomain))
+			}
+
+			requireStatus := func(name string, wantStatus v1alpha1.FederationDomainStatusCondition, wantMessage string) {
+				federationDomain, err := pinnipedAPIClient.ConfigV1alpha1().FederationDomains(namespace).Get(context.Background(), name, metav1.GetOptions{})
+				r.NoError(err)
+				r.Equal(wantStatus, federationDomain.Status.Status)
+				r.Equal(wantMessage, federationDomain.Status.Message)
+			}
+
+			it.Before(func() {
+				addFederationDomain("migrating", "https://new.example.com/a", "https://old.example.com/a", frozenNow.Add(2*time.Hour))
+				addFederationDomain("migrating-later", "https://new.example.com/b", "https://old.example.com/b", frozenNow.Add(5*time.Hour))
+
+				// The grace period of this one has ended, so its previous issuer may be used by the next one.
+				addFederationDomain("migrated", "https://new.example.com/c", "https://old.example.com/c", frozenNow.Add(-time.Hour))
+				addFederationDomain("reusing-expired-previous-issuer", "https://old.example.com/c", "", time.Time{})
+
+				// The previous issuer of this one is the same as the issuer of the next one.
+				addFederationDomain("with-duplicate-previous-issuer", "https://new.example.com/d", "https://taken.example.com/d", frozenNow.Add(time.Hour))
+				addFederationDomain("duplicate-of-previous-issuer", "https://taken.example.com/d", "", time.Time{})
+
+				addFederationDomain("with-invalid-previous-issuer", "https://new.example.com/e", "https://NEW.example.com/e", frozenNow.Add(time.Hour))
+			})
+
+			it("calls the ProvidersSetter with the valid providers, including the previous issuers which are still accepted", func() {
+				startInformersAndController()
+				queue = &fakeAddAfterQueue{t: t}
+				syncContext.Queue = queue
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				migrating, err := provider.NewFederationDomainIssuer("https://new.example.com/a")
+				r.NoError(err)
+				r.NoError(migrating.SetPreviousIssuer("https://old.example.com/a"))
+				migratingLater, err := provider.NewFederationDomainIssuer("https://new.example.com/b")
+				r.NoError(err)
+				r.NoError(migratingLater.SetPreviousIssuer("https://old.example.com/b"))
+				migrated, err := provider.NewFederationDomainIssuer("https://new.example.com/c")
+				r.NoError(err)
+				reusing, err := provider.NewFederationDomainIssuer("https://old.example.com/c")
+				r.NoError(err)
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.ElementsMatch([]*provider.FederationDomainIssuer{migrating, migratingLater, migrated, reusing}, providersSetter.FederationDomainsReceived)
+
+				// The controller syncs again when the earliest grace period ends, to stop serving that previous issuer.
+				r.True(queue.called)
+				r.Equal(2*time.Hour, queue.duration)
+			})
+
+			it("updates the statuses", func() {
+				startInformersAndController()
+				syncContext.Queue = &fakeAddAfterQueue{t: t}
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				requireStatus("migrating", v1alpha1.SuccessFederationDomainStatusCondition, "Provider successfully created")
+				requireStatus("migrating-later", v1alpha1.SuccessFederationDomainStatusCondition, "Provider successfully created")
+				requireStatus("migrated", v1alpha1.SuccessFederationDomainStatusCondition, "Provider successfully created")
+				requireStatus("reusing-expired-previous-issuer", v1alpha1.SuccessFederationDomainStatusCondition, "Provider successfully created")
+				requireStatus("with-duplicate-previous-issuer", v1alpha1.DuplicateFederationDomainStatusCondition,
+					"Duplicate issuer: https://taken.example.com/d")
+				requireStatus("duplicate-of-previous-issuer", v1alpha1.DuplicateFederationDomainStatusCondition,
+					"Duplicate issuer: https://taken.example.com/d")
+				requireStatus("with-invalid-previous-issuer", v1alpha1.InvalidFederationDomainStatusCondition,
+					`Invalid: previous issuer "https://NEW.example.com/e" must not be the same as the issuer or the issuer of an alias host`)
+			})
+		})
+
+		when("there are FederationDomains with allowed CORS origins in the informer", func() {
+			it.Before(func() {
+				for _, federationDomain := range []*v1alpha1.FederationDomain{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "with-cors", Namespace: namespace},
+						Spec: v1alpha1.FederationDomainSpec{
+							Issuer:             "https://issuer.com/a",
+							AllowedCORSOrigins: []string{"https://app.example.com"},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "with-invalid-cors", Namespace: namespace},
+						Spec: v1alpha1.FederationDomainSpec{
+							Issuer:             "https://issuer.com/b",
+							AllowedCORSOrigins: []string{"*"},
+						},
+					},
+				} {
+					r.NoError(pinnipedAPIClient.Tracker().Add(federationDomain))
+					r.NoError(federationDomainInformerClient.Tracker().Add(federationDomain))
+				}
+			})
+
+			it("calls the ProvidersSetter with only the valid provider, including its allowed CORS origins", func() {
+				startInformersAndController()
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				wantProvider, err := provider.NewFederationDomainIssuer("https://issuer.com/a")
+				r.NoError(err)
+				r.NoError(wantProvider.SetAllowedCORSOrigins([]string{"https://app.example.com"}))
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Equal([]*provider.FederationDomainIssuer{wantProvider}, providersSetter.FederationDomainsReceived)
+
+				federationDomain, err := pinnipedAPIClient.ConfigV1alpha1().FederationDomains(namespace).Get(context.Background(), "with-invalid-cors", metav1.GetOptions{})
+				r.NoError(err)
+				r.Equal(v1alpha1.InvalidFederationDomainStatusCondition, federationDomain.Status.Status)
+				r.Equal(`Invalid: allowed CORS origin "*" must be a scheme and host with an optional port, e.g. https://app.example.com`,
+					federationDomain.Status.Message)
+			})
+		})
+
+		when("there are FederationDomains with WebAuthn enforcements in the informer", func() {
+			it.Before(func() {
+				for _, federationDomain := range []*v1alpha1.FederationDomain{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "with-webauthn", Namespace: namespace},
+						Spec: v1alpha1.FederationDomainSpec{
+							Issuer:   "https://issuer.com/a",
+							WebAuthn: &v1alpha1.FederationDomainWebAuthnSpec{Enforcement: v1alpha1.FederationDomainWebAuthnEnforcementRequired},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "with-invalid-webauthn", Namespace: namespace},
+						Spec: v1alpha1.FederationDomainSpec{
+							Issuer:   "https://issuer.com/b",
+							WebAuthn: &v1alpha1.FederationDomainWebAuthnSpec{Enforcement: "Sometimes"},
+						},
+					},
+				} {
+					r.NoError(pinnipedAPIClient.Tracker().Add(federationDomain))
+					r.NoError(federationDomainInformerClient.Tracker().Add(federationDomain))
+				}
+			})
+
+			it("calls the ProvidersSetter with only the valid provider, including its WebAuthn enforcement", func() {
+				startInformersAndController()
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				wantProvider, err := provider.NewFederationDomainIssuer("https://issuer.com/a")
+				r.NoError(err)
+				r.NoError(wantProvider.SetWebAuthnEnforcement(provider.WebAuthnEnforcementRequired))
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Equal([]*provider.FederationDomainIssuer{wantProvider}, providersSetter.FederationDomainsReceived)
+
+				federationDomain, err := pinnipedAPIClient.ConfigV1alpha1().FederationDomains(namespace).Get(context.Background(), "with-invalid-webauthn", metav1.GetOptions{})
+				r.NoError(err)
+				r.Equal(v1alpha1.InvalidFederationDomainStatusCondition, federationDomain.Status.Status)
+				r.Equal(`Invalid: WebAuthn enforcement "Sometimes" must be "IfEnrolled" or "Required"`,
+					federationDomain.Status.Message)
+			})
+		})
+
+		when("there are FederationDomains with TOTP enforcements in the informer", func() {
+			it.Before(func() {
+				for _, federationDomain := range []*v1alpha1.FederationDomain{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "with-totp", Namespace: namespace},
+						Spec: v1alpha1.FederationDomainSpec{
+							Issuer: "https://issuer.com/a",
+							TOTP:   &v1alpha1.FederationDomainTOTPSpec{Enforcement: v1alpha1.FederationDomainTOTPEnforcementIfEnrolled},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "with-totp-and-webauthn", Namespace: namespace},
+						Spec: v1alpha1.FederationDomainSpec{
+							Issuer:   "https://issuer.com/b",
+							WebAuthn: &v1alpha1.FederationDomainWebAuthnSpec{Enforcement: v1alpha1.FederationDomainWebAuthnEnforcementRequired},
+							TOTP:     &v1alpha1.FederationDomainTOTPSpec{Enforcement: v1alpha1.FederationDomainTOTPEnforcementRequired},
+						},
+					},
+				} {
+					r.NoError(pinnipedAPIClient.Tracker().Add(federationDomain))
+					r.NoError(federationDomainInformerClient.Tracker().Add(federationDomain))
+				}
+			})
+
+			it("calls the ProvidersSetter with only the valid provider, including its TOTP enforcement", func() {
+				startInformersAndController()
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				wantProvider, err := provider.NewFederationDomainIssuer("https://issuer.com/a")
+				r.NoError(err)
+				r.NoError(wantProvider.SetTOTPEnforcement(provider.TOTPEnforcementIfEnrolled))
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Equal([]*provider.FederationDomainIssuer{wantProvider}, providersSetter.FederationDomainsReceived)
+
+				federationDomain, err := pinnipedAPIClient.ConfigV1alpha1().FederationDomains(namespace).Get(context.Background(), "with-totp-and-webauthn", metav1.GetOptions{})
+				r.NoError(err)
+				r.Equal(v1alpha1.InvalidFederationDomainStatusCondition, federationDomain.Status.Status)
+				r.Equal("Invalid: TOTP enforcement cannot be combined with WebAuthn enforcement",
+					federationDomain.Status.Message)
+			})
+		})
+
+		when("there are FederationDomains with login policies in the informer", func() {
+			it.Before(func() {
+				for _, federationDomain := range []*v1alpha1.FederationDomain{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "with-login-policy", Namespace: namespace},
+						Spec: v1alpha1.FederationDomainSpec{
+							Issuer:      "https://issuer.com/a",
+							LoginPolicy: &v1alpha1.FederationDomainLoginPolicy{AllowedGroups: []string{"admins"}},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "with-invalid-login-policy", Namespace: namespace},
+						Spec: v1alpha1.FederationDomainSpec{
+							Issuer: "https://issuer.com/b",
+							LoginPolicy: &v1alpha1.FederationDomainLoginPolicy{
+								TimeWindows: []v1alpha1.FederationDomainLoginTimeWindow{{Start: "08:00", End: "18:00", TimeZone: "Mars/Olympus_Mons"}},
+							},
+						},
+					},
+				} {
+					r.NoError(pinnipedAPIClient.Tracker().Add(federationDomain))
+					r.NoError(federationDomainInformerClient.Tracker().Add(federationDomain))
+				}
+			})
+
+			it("calls the ProvidersSetter with only the valid provider, including its login policy", func() {
+				startInformersAndController()
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				wantProvider, err := provider.NewFederationDomainIssuer("https://issuer.com/a")
+				r.NoError(err)
+				r.NoError(wantProvider.SetLoginPolicy(loginpolicy.Config{AllowedGroups: []string{"admins"}}))
+
+				r.True(providersSetter.SetProvidersWasCalled)
+				r.Equal([]*provider.FederationDomainIssuer{wantProvider}, providersSetter.FederationDomainsReceived)
+
+				federationDomain, err := pinnipedAPIClient.ConfigV1alpha1().FederationDomains(namespace).Get(context.Background(), "with-invalid-login-policy", metav1.GetOptions{})
+				r.NoError(err)
+				r.Equal(v1alpha1.InvalidFederationDomainStatusCondition, federationDomain.Status.Status)
+				r.Equal(`Invalid: invalid login policy: time window 0 is invalid: time zone "Mars/Olympus_Mons" is unknown`,
+					federationDomain.Status.Message)
+			})
+		})
+
 		when("there are no FederationDomains in the informer", func() {
 			it("keeps waiting for one", func() {
 				startInformersAndController()
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package generator
@@ -177,7 +177,7 @@ func TestFederationDomainControllerFilterSecret(t *testing.T) {
 				map[string]string{},
 				rand.Reader,
 				SecretUsageTokenSigningKey,
-				func(cacheKey string, cacheValue []byte) {},
+				func(cacheKey string, cacheValue, previousCacheValue []byte) {},
 			)
 
 			secretInformer := kubeinformers.NewSharedInformerFactory(
@@ -240,7 +240,7 @@ func TestNewFederationDomainSecretsControllerFilterFederationDomain(t *testing.T
 				map[string]string{},
 				rand.Reader,
 				SecretUsageTokenSigningKey,
-				func(cacheKey string, cacheValue []byte) {},
+				func(cacheKey string, cacheValue, previousCacheValue []byte) {},
 			)
 
 			secretInformer := kubeinformers.NewSharedInformerFactory(
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package generator
@@ -30,6 +30,16 @@ const (
 	// SupervisorCSRFSigningKeySecretType for the Secret storing the CSRF signing key.
 	SupervisorCSRFSigningKeySecretType corev1.SecretType = "secrets.pinniped.dev/supervisor-csrf-signing-key"
 
+	// SupervisorTOTPEncryptionKeySecretType for the Secret storing the key which encrypts the TOTP secrets of users.
+	SupervisorTOTPEncryptionKeySecretType corev1.SecretType = "secrets.pinniped.dev/supervisor-totp-encryption-key"
+
+	// SupervisorSessionKeyEncryptionKeySecretType for the Secret storing the generated key which wraps the key which
+	// encrypts the sessions.
+	SupervisorSessionKeyEncryptionKeySecretType corev1.SecretType = "secrets.pinniped.dev/supervisor-session-key-encryption-key"
+
+	// SupervisorSessionEncryptionKeySecretType for the Secret storing the wrapped key which encrypts the sessions.
+	SupervisorSessionEncryptionKeySecretType corev1.SecretType = "secrets.pinniped.dev/supervisor-session-encryption-key"
+
 	// FederationDomainTokenSigningKeyType for the Secret storing the FederationDomain token signing key.
 	FederationDomainTokenSigningKeyType corev1.SecretType = "secrets.pinniped.dev/federation-domain-token-signing-key"
 
@@ -44,6 +54,10 @@ const (
 	// symmetricSecretDataKey is the corev1.Secret.Data key for the symmetric key value generated by this helper.
 	symmetricSecretDataKey = "key"
 
+	// previousSymmetricSecretDataKey is the corev1.Secret.Data key for the previous symmetric key value, which is kept
+	// by the symmetricKeyRotatorController after it rotates the key.
+	previousSymmetricSecretDataKey = "previousKey"
+
 	// symmetricKeySize is the default length, in bytes, of generated keys. It is set to 32 since this
 	// seems like reasonable entropy for our keys, and a 32-byte key will allow for AES-256
 	// to be used in our codecs (see dynamiccodec.Codec).
@@ -61,13 +75,14 @@ const (
 )
 
 // New returns a SecretHelper that has been parameterized with common symmetric secret generation
-// knobs.
+// knobs. The updateCacheFunc is called with the current key of the active secret, and with its previous
+// key after it was rotated, or nil.
 func NewSymmetricSecretHelper(
 	namePrefix string,
 	labels map[string]string,
 	rand io.Reader,
 	secretUsage SecretUsage,
-	updateCacheFunc func(cacheKey string, cacheValue []byte),
+	updateCacheFunc func(cacheKey string, cacheValue, previousCacheValue []byte),
 ) SecretHelper {
 	return &symmetricSecretHelper{
 		namePrefix:      namePrefix,
@@ -83,7 +98,7 @@ type symmetricSecretHelper struct {
 	labels          map[string]string
 	rand            io.Reader
 	secretUsage     SecretUsage
-	updateCacheFunc func(cacheKey string, cacheValue []byte)
+	updateCacheFunc func(cacheKey string, cacheValue, previousCacheValue []byte)
 }
 
 func (s *symmetricSecretHelper) NamePrefix() string { return s.namePrefix }
@@ -141,7 +156,7 @@ func (s *symmetricSecretHelper) ObserveActiveSecretAndUpdateParentFederationDoma
 	federationDomain *configv1alpha1.FederationDomain,
 	secret *corev1.Secret,
 ) *configv1alpha1.FederationDomain {
-	s.updateCacheFunc(federationDomain.Spec.Issuer, secret.Data[symmetricSecretDataKey])
+	s.updateCacheFunc(federationDomain.Spec.Issuer, secret.Data[symmetricSecretDataKey], secret.Data[previousSymmetricSecretDataKey])
 
 	switch s.secretUsage {
 	case SecretUsageTokenSigningKey:
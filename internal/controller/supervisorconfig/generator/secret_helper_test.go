@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package generator
@@ -62,16 +62,17 @@ func TestSymmetricSecretHelper(t *testing.T) {
 			}
 			randSource := strings.NewReader(keyWith32Bytes)
 			var federationDomainIssuerValue string
-			var symmetricKeyValue []byte
+			var symmetricKeyValue, previousSymmetricKeyValue []byte
 			h := NewSymmetricSecretHelper(
 				"some-name-prefix-",
 				labels,
 				randSource,
 				test.secretUsage,
-				func(federationDomainIssuer string, symmetricKey []byte) {
+				func(federationDomainIssuer string, symmetricKey, previousSymmetricKey []byte) {
 					require.True(t, federationDomainIssuer == "" && symmetricKeyValue == nil, "expected notify func not to have been called yet")
 					federationDomainIssuerValue = federationDomainIssuer
 					symmetricKeyValue = symmetricKey
+					previousSymmetricKeyValue = previousSymmetricKey
 				},
 			)
 
@@ -104,10 +105,15 @@ func TestSymmetricSecretHelper(t *testing.T) {
 
 			require.True(t, h.IsValid(parent, child))
 
+			// A rotated secret is still valid, and its previous key is observed too.
+			child.Data["previousKey"] = []byte("some-previous-key")
+			require.True(t, h.IsValid(parent, child))
+
 			h.ObserveActiveSecretAndUpdateParentFederationDomain(parent, child)
 			require.Equal(t, parent.Spec.Issuer, federationDomainIssuerValue)
 			require.Equal(t, child.Name, test.wantSetFederationDomainField(parent))
 			require.Equal(t, child.Data["key"], symmetricKeyValue)
+			require.Equal(t, []byte("some-previous-key"), previousSymmetricKeyValue)
 
 			require.True(t, h.Handles(child))
 			wrongTypedChild := child.DeepCopy()
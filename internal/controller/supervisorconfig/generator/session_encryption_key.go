@@ -0,0 +1,174 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"fmt"
+	"io"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/sessionencryption"
+)
+
+const (
+	// wrappedKeySecretDataKey is the corev1.Secret.Data key for the wrapped session encryption key.
+	wrappedKeySecretDataKey = "wrappedKey"
+
+	// keyEncryptionKeyIDSecretDataKey is the corev1.Secret.Data key for the ID of the key which wrapped the key.
+	keyEncryptionKeyIDSecretDataKey = "keyEncryptionKeyID"
+)
+
+type sessionEncryptionKeyController struct {
+	name           string
+	namespace      string
+	labels         map[string]string
+	kubeClient     kubernetes.Interface
+	secretInformer corev1informers.SecretInformer
+	kek            sessionencryption.KeyEncryptionKey
+	keyring        *sessionencryption.Keyring
+	rand           io.Reader
+}
+
+// NewSessionEncryptionKeyController returns a controllerlib.Controller which ensures that the Secret whose name is the
+// name of the owner followed by "-session-encryption-key" holds a session encryption key, wrapped by the given key
+// encryption key, and which sets the unwrapped key in the keyring. The key is generated once, and rewrapped whenever
+// the current version of the key encryption key changes, e.g. after a rotation. It is never rotated itself, because
+// the existing sessions could not be decrypted anymore.
+func NewSessionEncryptionKeyController(
+	owner *appsv1.Deployment,
+	labels map[string]string,
+	kubeClient kubernetes.Interface,
+	secretInformer corev1informers.SecretInformer,
+	kek sessionencryption.KeyEncryptionKey,
+	keyring *sessionencryption.Keyring,
+	rand io.Reader,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+	initialEventFunc pinnipedcontroller.WithInitialEventOptionFunc,
+) controllerlib.Controller {
+	key := controllerlib.Key{Namespace: owner.Namespace, Name: owner.Name + "-session-encryption-key"}
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: key.Name + "-generator",
+			Syncer: &sessionEncryptionKeyController{
+				name:           key.Name,
+				namespace:      key.Namespace,
+				labels:         labels,
+				kubeClient:     kubeClient,
+				secretInformer: secretInformer,
+				kek:            kek,
+				keyring:        keyring,
+				rand:           rand,
+			},
+		},
+		// A change of the generated key encryption key may require the session encryption key to be rewrapped.
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.SimpleFilter(func(obj metav1.Object) bool {
+				secret, ok := obj.(*corev1.Secret)
+				return ok && (secret.Type == SupervisorSessionEncryptionKeySecretType ||
+					secret.Type == SupervisorSessionKeyEncryptionKeySecretType)
+			}, func(_ metav1.Object) controllerlib.Key {
+				return key
+			}),
+			controllerlib.InformerOption{},
+		),
+		initialEventFunc(key),
+	)
+}
+
+// Sync implements controllerlib.Syncer.Sync().
+func (c *sessionEncryptionKeyController) Sync(ctx controllerlib.Context) error {
+	secret, err := c.secretInformer.Lister().Secrets(c.namespace).Get(c.name)
+	if k8serrors.IsNotFound(err) {
+		return c.generate(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", c.namespace, c.name, err)
+	}
+
+	if secret.Type != SupervisorSessionEncryptionKeySecretType {
+		return fmt.Errorf("secret %s/%s has type %s instead of %s", c.namespace, c.name, secret.Type, SupervisorSessionEncryptionKeySecretType)
+	}
+
+	// The key is never regenerated automatically, because that would make all existing sessions unreadable. When the
+	// key encryption key is lost, the Secret can be deleted to generate a new key.
+	kekID := string(secret.Data[keyEncryptionKeyIDSecretDataKey])
+	dek, err := c.kek.Unwrap(ctx.Context, kekID, secret.Data[wrappedKeySecretDataKey])
+	if err != nil {
+		return fmt.Errorf("failed to unwrap session encryption key of secret %s/%s: %w", c.namespace, c.name, err)
+	}
+	if err := c.keyring.SetDataEncryptionKey(dek); err != nil {
+		return err
+	}
+
+	currentKEKID, err := c.kek.CurrentID()
+	if err != nil {
+		return err
+	}
+	if currentKEKID == kekID {
+		plog.Debug("session encryption key is up to date", "secret", klog.KObj(secret))
+		return nil
+	}
+
+	wrapped, wrappedKEKID, err := c.kek.Wrap(ctx.Context, dek)
+	if err != nil {
+		return fmt.Errorf("failed to rewrap session encryption key: %w", err)
+	}
+
+	// The update fails when the secret was changed in the meantime, e.g. by another pod. Return the error to try again.
+	updatedSecret := secret.DeepCopy()
+	updatedSecret.Data = secretDataForWrappedKey(wrapped, wrappedKEKID)
+	if _, err := c.kubeClient.CoreV1().Secrets(c.namespace).Update(ctx.Context, updatedSecret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update secret %s/%s: %w", c.namespace, c.name, err)
+	}
+
+	plog.Info("rewrapped session encryption key", "secret", klog.KObj(secret), "keyEncryptionKeyID", wrappedKEKID)
+	return nil
+}
+
+func (c *sessionEncryptionKeyController) generate(ctx controllerlib.Context) error {
+	dek := make([]byte, sessionencryption.KeySize)
+	if _, err := io.ReadFull(c.rand, dek); err != nil {
+		return fmt.Errorf("failed to generate session encryption key: %w", err)
+	}
+
+	wrapped, kekID, err := c.kek.Wrap(ctx.Context, dek)
+	if err != nil {
+		return fmt.Errorf("failed to wrap session encryption key: %w", err)
+	}
+
+	// The create fails when another pod created the secret in the meantime. Return the error to use its key instead.
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: c.namespace,
+			Labels:    c.labels,
+		},
+		Type: SupervisorSessionEncryptionKeySecretType,
+		Data: secretDataForWrappedKey(wrapped, kekID),
+	}
+	if _, err := c.kubeClient.CoreV1().Secrets(c.namespace).Create(ctx.Context, secret, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create secret %s/%s: %w", c.namespace, c.name, err)
+	}
+
+	plog.Info("generated session encryption key", "secret", klog.KObj(secret), "keyEncryptionKeyID", kekID)
+	return c.keyring.SetDataEncryptionKey(dek)
+}
+
+func secretDataForWrappedKey(wrapped []byte, kekID string) map[string][]byte {
+	return map[string][]byte{
+		wrappedKeySecretDataKey:         wrapped,
+		keyEncryptionKeyIDSecretDataKey: []byte(kekID),
+	}
+}
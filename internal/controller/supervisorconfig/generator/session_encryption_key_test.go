@@ -0,0 +1,267 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/sessionencryption"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestSessionEncryptionKeyControllerFilter(t *testing.T) {
+	owner := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "some-owner", Namespace: "some-namespace"}}
+	secretInformer := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(), 0).Core().V1().Secrets()
+	withInformer := testutil.NewObservableWithInformerOption()
+	_ = NewSessionEncryptionKeyController(
+		owner,
+		nil, // labels, not needed
+		nil, // kubeClient, not needed
+		secretInformer,
+		nil, // kek, not needed
+		nil, // keyring, not needed
+		nil, // rand, not needed
+		withInformer.WithInformer,
+		controllerlib.WithInitialEvent,
+	)
+
+	filter := withInformer.GetFilterForInformer(secretInformer)
+	dek := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "some-dek", Namespace: "some-namespace"}, Type: SupervisorSessionEncryptionKeySecretType}
+	kek := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "some-kek", Namespace: "some-namespace"}, Type: SupervisorSessionKeyEncryptionKeySecretType}
+	other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "some-other", Namespace: "some-namespace"}, Type: SupervisorCSRFSigningKeySecretType}
+	require.True(t, filter.Add(dek))
+	require.True(t, filter.Add(kek))
+	require.False(t, filter.Add(other))
+	require.True(t, filter.Update(other, kek))
+	require.True(t, filter.Delete(dek))
+	wantKey := controllerlib.Key{Namespace: "some-namespace", Name: "some-owner-session-encryption-key"}
+	require.Equal(t, wantKey, filter.Parent(kek))
+	require.Equal(t, wantKey, filter.Parent(other))
+}
+
+func TestSessionEncryptionKeyControllerSync(t *testing.T) {
+	const (
+		namespace  = "some-namespace"
+		secretName = "some-owner-session-encryption-key"
+	)
+
+	var (
+		dek         = []byte("some-neato-32-byte-generated-dek")
+		currentKEK  = []byte("some-neato-32-byte-generated-kek")
+		previousKEK = []byte("some-funio-32-byte-generated-kek")
+		labels      = map[string]string{"some-label": "some-value"}
+	)
+
+	wrap := func(t *testing.T, kek []byte) map[string][]byte {
+		t.Helper()
+
+		k := sessionencryption.NewLocalKeyEncryptionKey()
+		k.Set(kek, nil)
+		wrapped, id, err := k.Wrap(context.Background(), dek)
+		require.NoError(t, err)
+		return secretDataForWrappedKey(wrapped, id)
+	}
+	newSecret := func(secretType corev1.SecretType, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace, Labels: labels},
+			Type:       secretType,
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		secret      func(t *testing.T) *corev1.Secret
+		currentKEK  []byte
+		previousKEK []byte
+		rand        string
+		createErr   error
+		updateErr   error
+		wantError   string
+		wantCreate  bool
+		wantUpdate  bool
+	}{
+		{
+			name:       "secret does not exist",
+			currentKEK: currentKEK,
+			rand:       string(dek),
+			wantCreate: true,
+		},
+		{
+			name:       "secret does not exist and generating the key fails",
+			currentKEK: currentKEK,
+			rand:       "too short",
+			wantError:  "failed to generate session encryption key: unexpected EOF",
+		},
+		{
+			name:      "secret does not exist and the key encryption key is not available yet",
+			rand:      string(dek),
+			wantError: "failed to wrap session encryption key: session key encryption key is not available yet",
+		},
+		{
+			name:       "secret does not exist and creating the secret fails",
+			currentKEK: currentKEK,
+			rand:       string(dek),
+			createErr:  errors.New("some create error"),
+			wantError:  "failed to create secret some-namespace/some-owner-session-encryption-key: some create error",
+		},
+		{
+			name: "secret has the wrong type",
+			secret: func(t *testing.T) *corev1.Secret {
+				return newSecret(SupervisorCSRFSigningKeySecretType, wrap(t, currentKEK))
+			},
+			currentKEK: currentKEK,
+			wantError: "secret some-namespace/some-owner-session-encryption-key has type secrets.pinniped.dev/supervisor-csrf-signing-key " +
+				"instead of secrets.pinniped.dev/supervisor-session-encryption-key",
+		},
+		{
+			name: "key is wrapped by the current key encryption key",
+			secret: func(t *testing.T) *corev1.Secret {
+				return newSecret(SupervisorSessionEncryptionKeySecretType, wrap(t, currentKEK))
+			},
+			currentKEK:  currentKEK,
+			previousKEK: previousKEK,
+		},
+		{
+			name: "key is wrapped by the previous key encryption key",
+			secret: func(t *testing.T) *corev1.Secret {
+				return newSecret(SupervisorSessionEncryptionKeySecretType, wrap(t, previousKEK))
+			},
+			currentKEK:  currentKEK,
+			previousKEK: previousKEK,
+			wantUpdate:  true,
+		},
+		{
+			name: "key is wrapped by the previous key encryption key and updating the secret fails",
+			secret: func(t *testing.T) *corev1.Secret {
+				return newSecret(SupervisorSessionEncryptionKeySecretType, wrap(t, previousKEK))
+			},
+			currentKEK:  currentKEK,
+			previousKEK: previousKEK,
+			updateErr:   errors.New("some update error"),
+			wantError:   "failed to update secret some-namespace/some-owner-session-encryption-key: some update error",
+		},
+		{
+			name: "key is wrapped by an unknown key encryption key",
+			secret: func(t *testing.T) *corev1.Secret {
+				return newSecret(SupervisorSessionEncryptionKeySecretType, wrap(t, previousKEK))
+			},
+			currentKEK: currentKEK,
+			wantError: "failed to unwrap session encryption key of secret some-namespace/some-owner-session-encryption-key: " +
+				"session encryption key was wrapped by an unknown key encryption key",
+		},
+		{
+			name: "key was tampered with",
+			secret: func(t *testing.T) *corev1.Secret {
+				data := wrap(t, currentKEK)
+				data[wrappedKeySecretDataKey][0] ^= 0xff
+				return newSecret(SupervisorSessionEncryptionKeySecretType, data)
+			},
+			currentKEK: currentKEK,
+			wantError: "failed to unwrap session encryption key of secret some-namespace/some-owner-session-encryption-key: " +
+				"session could not be decrypted",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var objects []runtime.Object
+			if tt.secret != nil {
+				objects = append(objects, tt.secret(t))
+			}
+			kubeAPIClient := kubernetesfake.NewSimpleClientset(objects...)
+			if tt.createErr != nil {
+				kubeAPIClient.PrependReactor("create", "secrets", func(_ kubetesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.createErr
+				})
+			}
+			if tt.updateErr != nil {
+				kubeAPIClient.PrependReactor("update", "secrets", func(_ kubetesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.updateErr
+				})
+			}
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(objects...), 0)
+
+			kek := sessionencryption.NewLocalKeyEncryptionKey()
+			kek.Set(tt.currentKEK, tt.previousKEK)
+			keyring := sessionencryption.NewKeyring()
+
+			subject := NewSessionEncryptionKeyController(
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "some-owner", Namespace: namespace}},
+				labels,
+				kubeAPIClient,
+				kubeInformers.Core().V1().Secrets(),
+				kek,
+				keyring,
+				strings.NewReader(tt.rand),
+				controllerlib.WithInformer,
+				controllerlib.WithInitialEvent,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			err := controllerlib.TestSync(t, subject, controllerlib.Context{
+				Context: ctx,
+				Name:    subject.Name(),
+				Key:     controllerlib.Key{Namespace: namespace, Name: secretName},
+			})
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+
+			// The keyring encrypts with the session encryption key.
+			expectedKeyring := sessionencryption.NewKeyring()
+			require.NoError(t, expectedKeyring.SetDataEncryptionKey(dek))
+			ciphertext, err := keyring.Encrypt([]byte("some-session"), []byte("some-name"))
+			require.NoError(t, err)
+			plaintext, err := expectedKeyring.Decrypt(ciphertext, []byte("some-name"))
+			require.NoError(t, err)
+			require.Equal(t, []byte("some-session"), plaintext)
+
+			verbs := sets.NewString()
+			for _, action := range kubeAPIClient.Actions() {
+				verbs.Insert(action.GetVerb())
+			}
+			require.Equal(t, tt.wantCreate, verbs.Has("create"))
+			require.Equal(t, tt.wantUpdate, verbs.Has("update"))
+			if !tt.wantCreate && !tt.wantUpdate {
+				return
+			}
+
+			// The stored key is wrapped by the current key encryption key.
+			actual, err := kubeAPIClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, SupervisorSessionEncryptionKeySecretType, actual.Type)
+			require.Equal(t, labels, actual.Labels)
+			currentID, err := kek.CurrentID()
+			require.NoError(t, err)
+			require.Equal(t, currentID, string(actual.Data[keyEncryptionKeyIDSecretDataKey]))
+			unwrapped, err := kek.Unwrap(ctx, currentID, actual.Data[wrappedKeySecretDataKey])
+			require.NoError(t, err)
+			require.Equal(t, dek, unwrapped)
+		})
+	}
+}
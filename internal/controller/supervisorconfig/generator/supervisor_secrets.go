@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package generator provides a supervisorSecretsController that can ensure existence of a generated secret.
@@ -30,16 +30,21 @@ type supervisorSecretsController struct {
 	labels         map[string]string
 	kubeClient     kubernetes.Interface
 	secretInformer corev1informers.SecretInformer
-	setCacheFunc   func(secret []byte)
+	secretType     corev1.SecretType
+	setCacheFunc   func(secret, previousSecret []byte)
 }
 
-// NewSupervisorSecretsController instantiates a new controllerlib.Controller which will ensure existence of a generated secret.
+// NewSupervisorSecretsController instantiates a new controllerlib.Controller which will ensure existence of a generated secret
+// of the given type, whose name is the name of the owner followed by the secretNameSuffix.
+// The setCacheFunc is called with the current key of the secret, and with its previous key after it was rotated, or nil.
 func NewSupervisorSecretsController(
 	owner *appsv1.Deployment,
 	labels map[string]string,
 	kubeClient kubernetes.Interface,
 	secretInformer corev1informers.SecretInformer,
-	setCacheFunc func(secret []byte),
+	secretType corev1.SecretType,
+	secretNameSuffix string,
+	setCacheFunc func(secret, previousSecret []byte),
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
 	initialEventFunc pinnipedcontroller.WithInitialEventOptionFunc,
 ) controllerlib.Controller {
@@ -47,10 +52,11 @@ func NewSupervisorSecretsController(
 		labels:         labels,
 		kubeClient:     kubeClient,
 		secretInformer: secretInformer,
+		secretType:     secretType,
 		setCacheFunc:   setCacheFunc,
 	}
 	return controllerlib.New(
-		controllerlib.Config{Name: owner.Name + "-secret-generator", Syncer: &c},
+		controllerlib.Config{Name: owner.Name + secretNameSuffix + "-generator", Syncer: &c},
 		withInformer(
 			secretInformer,
 			pinnipedcontroller.SimpleFilter(func(obj metav1.Object) bool {
@@ -58,7 +64,7 @@ func NewSupervisorSecretsController(
 				if !ok {
 					return false
 				}
-				if secret.Type != SupervisorCSRFSigningKeySecretType {
+				if secret.Type != secretType {
 					return false
 				}
 				return true
@@ -67,7 +73,7 @@ func NewSupervisorSecretsController(
 		),
 		initialEventFunc(controllerlib.Key{
 			Namespace: owner.Namespace,
-			Name:      owner.Name + "-key",
+			Name:      owner.Name + secretNameSuffix,
 		}),
 	)
 }
@@ -80,14 +86,14 @@ func (c *supervisorSecretsController) Sync(ctx controllerlib.Context) error {
 		return fmt.Errorf("failed to list secret %s/%s: %w", ctx.Key.Namespace, ctx.Key.Name, err)
 	}
 
-	secretNeedsUpdate := isNotFound || !isValid(secret, c.labels)
+	secretNeedsUpdate := isNotFound || !isValid(secret, c.secretType, c.labels)
 	if !secretNeedsUpdate {
 		plog.Debug("secret is up to date", "secret", klog.KObj(secret))
-		c.setCacheFunc(secret.Data[symmetricSecretDataKey])
+		c.setCacheFunc(secret.Data[symmetricSecretDataKey], secret.Data[previousSymmetricSecretDataKey])
 		return nil
 	}
 
-	newSecret, err := generateSecret(ctx.Key.Namespace, ctx.Key.Name, c.labels, secretDataFunc)
+	newSecret, err := generateSecret(ctx.Key.Namespace, ctx.Key.Name, c.secretType, c.labels, secretDataFunc)
 	if err != nil {
 		return fmt.Errorf("failed to generate secret: %w", err)
 	}
@@ -101,7 +107,7 @@ func (c *supervisorSecretsController) Sync(ctx controllerlib.Context) error {
 		return fmt.Errorf("failed to create/update secret %s/%s: %w", newSecret.Namespace, newSecret.Name, err)
 	}
 
-	c.setCacheFunc(newSecret.Data[symmetricSecretDataKey])
+	c.setCacheFunc(newSecret.Data[symmetricSecretDataKey], newSecret.Data[previousSymmetricSecretDataKey])
 
 	return nil
 }
@@ -127,7 +133,7 @@ func (c *supervisorSecretsController) updateSecret(ctx context.Context, newSecre
 			return nil
 		}
 
-		if isValid(currentSecret, c.labels) {
+		if isValid(currentSecret, c.secretType, c.labels) {
 			*newSecret = currentSecret
 			return nil
 		}
@@ -151,8 +157,8 @@ func generateSymmetricKey() ([]byte, error) {
 	return b, nil
 }
 
-func isValid(secret *corev1.Secret, labels map[string]string) bool {
-	if secret.Type != SupervisorCSRFSigningKeySecretType {
+func isValid(secret *corev1.Secret, secretType corev1.SecretType, labels map[string]string) bool {
+	if secret.Type != secretType {
 		return false
 	}
 
@@ -184,7 +190,7 @@ func secretDataFunc() (map[string][]byte, error) {
 	}, nil
 }
 
-func generateSecret(namespace, name string, labels map[string]string, secretDataFunc func() (map[string][]byte, error)) (*corev1.Secret, error) {
+func generateSecret(namespace, name string, secretType corev1.SecretType, labels map[string]string, secretDataFunc func() (map[string][]byte, error)) (*corev1.Secret, error) {
 	secretData, err := secretDataFunc()
 	if err != nil {
 		return nil, err
@@ -196,7 +202,7 @@ func generateSecret(namespace, name string, labels map[string]string, secretData
 			Namespace: namespace,
 			Labels:    labels,
 		},
-		Type: SupervisorCSRFSigningKeySecretType,
+		Type: secretType,
 		Data: secretData,
 	}, nil
 }
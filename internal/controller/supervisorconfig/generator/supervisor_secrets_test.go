@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package generator
@@ -114,6 +114,8 @@ func TestSupervisorSecretsControllerFilterSecret(t *testing.T) {
 				labels,
 				nil, // kubeClient, not needed
 				secretInformer,
+				SupervisorCSRFSigningKeySecretType,
+				"-key",
 				nil, // setCache, not needed
 				withInformer.WithInformer,
 				testutil.NewObservableWithInitialEventOption().WithInitialEvent,
@@ -140,6 +142,8 @@ func TestSupervisorSecretsControllerInitialEvent(t *testing.T) {
 		nil,
 		nil, // kubeClient, not needed
 		secretInformer,
+		SupervisorCSRFSigningKeySecretType,
+		"-key",
 		nil, // setCache, not needed
 		testutil.NewObservableWithInformerOption().WithInformer,
 		initialEventOption.WithInitialEvent,
@@ -197,13 +201,14 @@ func TestSupervisorSecretsControllerSync(t *testing.T) {
 	once := sync.Once{}
 
 	tests := []struct {
-		name               string
-		storedSecret       func(**corev1.Secret)
-		generateKey        func() ([]byte, error)
-		apiClient          func(*testing.T, *kubernetesfake.Clientset)
-		wantError          string
-		wantActions        []kubetesting.Action
-		wantCallbackSecret []byte
+		name                       string
+		storedSecret               func(**corev1.Secret)
+		generateKey                func() ([]byte, error)
+		apiClient                  func(*testing.T, *kubernetesfake.Clientset)
+		wantError                  string
+		wantActions                []kubetesting.Action
+		wantCallbackSecret         []byte
+		wantCallbackPreviousSecret []byte
 	}{
 		{
 			name: "when the secrets does not exist, it gets generated",
@@ -219,6 +224,14 @@ func TestSupervisorSecretsControllerSync(t *testing.T) {
 			name:               "when a valid secret exists, nothing happens",
 			wantCallbackSecret: generatedSymmetricKey,
 		},
+		{
+			name: "when a valid secret with a previous key exists, nothing happens",
+			storedSecret: func(secret **corev1.Secret) {
+				(*secret).Data["previousKey"] = otherGeneratedSymmetricKey
+			},
+			wantCallbackSecret:         generatedSymmetricKey,
+			wantCallbackPreviousSecret: otherGeneratedSymmetricKey,
+		},
 		{
 			name: "secret gets updated when the type is wrong",
 			storedSecret: func(secret **corev1.Secret) {
@@ -440,15 +453,18 @@ func TestSupervisorSecretsControllerSync(t *testing.T) {
 			informers := kubeinformers.NewSharedInformerFactory(informerClient, 0)
 			secrets := informers.Core().V1().Secrets()
 
-			var callbackSecret []byte
+			var callbackSecret, callbackPreviousSecret []byte
 			c := NewSupervisorSecretsController(
 				owner,
 				labels,
 				apiClient,
 				secrets,
-				func(secret []byte) {
+				SupervisorCSRFSigningKeySecretType,
+				"-key",
+				func(secret, previousSecret []byte) {
 					require.Nil(t, callbackSecret, "callback was called twice")
 					callbackSecret = secret
+					callbackPreviousSecret = previousSecret
 				},
 				testutil.NewObservableWithInformerOption().WithInformer,
 				testutil.NewObservableWithInitialEventOption().WithInitialEvent,
@@ -477,6 +493,7 @@ func TestSupervisorSecretsControllerSync(t *testing.T) {
 			require.Equal(t, test.wantActions, apiClient.Actions())
 
 			require.Equal(t, test.wantCallbackSecret, callbackSecret)
+			require.Equal(t, test.wantCallbackPreviousSecret, callbackPreviousSecret)
 		})
 	}
 }
@@ -0,0 +1,152 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+// KeyRotatedAtAnnotation is the annotation on a Secret with a symmetric key which records when the key was last
+// rotated, in RFC 3339 format. The creation time of the Secret is used when the key was never rotated.
+const KeyRotatedAtAnnotation = "secrets.pinniped.dev/key-rotated-at"
+
+type symmetricKeyRotatorController struct {
+	kubeClient     kubernetes.Interface
+	secretInformer corev1informers.SecretInformer
+
+	// rotationPeriod is how long a key is used before it is rotated.
+	rotationPeriod time.Duration
+
+	// previousKeyLifespan is how long the previous key is kept after a rotation, i.e. how long the values which
+	// were signed by it remain valid. It should be the lifespan of the values which are signed by the key.
+	previousKeyLifespan time.Duration
+
+	clock clock.Clock
+	rand  io.Reader
+}
+
+// NewSymmetricKeyRotatorController returns a controllerlib.Controller which periodically rotates the symmetric keys
+// of the Secrets which are handled by the given filter, i.e. of Secrets which are generated by a
+// supervisorSecretsController or by a federationDomainSecretsController. The previous key is kept in the Secret for
+// previousKeyLifespan, so that values which were signed by it can still be verified until they expire, which bounds
+// the usefulness of a leaked key. A key is not rotated again before the previous key is forgotten, so keys are rotated
+// at most once per previousKeyLifespan even when rotationPeriod is shorter.
+func NewSymmetricKeyRotatorController(
+	name string,
+	handles func(metav1.Object) bool,
+	kubeClient kubernetes.Interface,
+	secretInformer corev1informers.SecretInformer,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+	rotationPeriod time.Duration,
+	previousKeyLifespan time.Duration,
+	clock clock.Clock,
+	rand io.Reader,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: name,
+			Syncer: &symmetricKeyRotatorController{
+				kubeClient:          kubeClient,
+				secretInformer:      secretInformer,
+				rotationPeriod:      rotationPeriod,
+				previousKeyLifespan: previousKeyLifespan,
+				clock:               clock,
+				rand:                rand,
+			},
+		},
+		withInformer(
+			secretInformer,
+			pinnipedcontroller.SimpleFilter(handles, nil),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.Sync().
+func (c *symmetricKeyRotatorController) Sync(ctx controllerlib.Context) error {
+	secret, err := c.secretInformer.Lister().Secrets(ctx.Key.Namespace).Get(ctx.Key.Name)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", ctx.Key.Namespace, ctx.Key.Name, err)
+	}
+
+	// The controller which generates the key is responsible for replacing invalid keys.
+	if len(secret.Data[symmetricSecretDataKey]) != symmetricKeySize {
+		return nil
+	}
+
+	now := c.clock.Now()
+	rotatedAt := keyRotatedAt(secret)
+	rotateAt := rotatedAt.Add(c.rotationPeriod)
+	_, hasPreviousKey := secret.Data[previousSymmetricSecretDataKey]
+	forgetPreviousKeyAt := rotatedAt.Add(c.previousKeyLifespan)
+
+	// Never rotate while the previous key is still accepted, since that would replace the previous key and break
+	// the values which it signed before they expire. When the rotation period is shorter than the lifespan of the
+	// previous key, the key is therefore only rotated once the previous key is forgotten.
+	if hasPreviousKey && rotateAt.Before(forgetPreviousKeyAt) {
+		rotateAt = forgetPreviousKeyAt
+	}
+
+	updatedSecret := secret.DeepCopy()
+	var message string
+	switch {
+	case !now.Before(rotateAt):
+		key := make([]byte, symmetricKeySize)
+		if _, err := io.ReadFull(c.rand, key); err != nil {
+			return fmt.Errorf("failed to generate key: %w", err)
+		}
+		updatedSecret.Data[previousSymmetricSecretDataKey] = secret.Data[symmetricSecretDataKey]
+		updatedSecret.Data[symmetricSecretDataKey] = key
+		if updatedSecret.Annotations == nil {
+			updatedSecret.Annotations = map[string]string{}
+		}
+		updatedSecret.Annotations[KeyRotatedAtAnnotation] = now.UTC().Format(time.RFC3339)
+		message = "rotated symmetric key"
+	case hasPreviousKey && !now.Before(forgetPreviousKeyAt):
+		delete(updatedSecret.Data, previousSymmetricSecretDataKey)
+		message = "removed previous symmetric key"
+	default:
+		// Check again at the next transition, since nothing else would cause this controller to sync then.
+		nextTransition := rotateAt
+		if hasPreviousKey && forgetPreviousKeyAt.Before(nextTransition) {
+			nextTransition = forgetPreviousKeyAt
+		}
+		ctx.Queue.AddAfter(ctx.Key, nextTransition.Sub(now))
+		return nil
+	}
+
+	// The update fails when the secret was changed in the meantime, e.g. by a rotation in another pod,
+	// so that a key is never lost. Return the error to try again.
+	if _, err := c.kubeClient.CoreV1().Secrets(secret.Namespace).Update(ctx.Context, updatedSecret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	plog.Info(message, "secret", klog.KObj(secret))
+	return nil
+}
+
+// keyRotatedAt returns when the key of the secret was last rotated.
+func keyRotatedAt(secret *corev1.Secret) time.Time {
+	if rotatedAt, err := time.Parse(time.RFC3339, secret.Annotations[KeyRotatedAtAnnotation]); err == nil {
+		return rotatedAt
+	}
+	return secret.CreationTimestamp.Time
+}
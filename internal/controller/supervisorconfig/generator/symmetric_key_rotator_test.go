@@ -0,0 +1,274 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestSymmetricKeyRotatorControllerFilter(t *testing.T) {
+	secretInformer := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(), 0).Core().V1().Secrets()
+	withInformer := testutil.NewObservableWithInformerOption()
+	_ = NewSymmetricKeyRotatorController(
+		"some-name",
+		func(obj metav1.Object) bool { return obj.GetName() == "handled" },
+		nil, // kubeClient, not needed
+		secretInformer,
+		withInformer.WithInformer,
+		0, // rotationPeriod, not needed
+		0, // previousKeyLifespan, not needed
+		nil,
+		nil,
+	)
+
+	filter := withInformer.GetFilterForInformer(secretInformer)
+	handled := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "handled", Namespace: "some-namespace"}}
+	unhandled := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unhandled", Namespace: "some-namespace"}}
+	require.True(t, filter.Add(handled))
+	require.False(t, filter.Add(unhandled))
+	require.True(t, filter.Update(unhandled, handled))
+	require.True(t, filter.Delete(handled))
+	require.Equal(t, controllerlib.Key{Namespace: "some-namespace", Name: "handled"}, filter.Parent(handled))
+}
+
+func TestSymmetricKeyRotatorControllerSync(t *testing.T) {
+	const (
+		namespace           = "some-namespace"
+		secretName          = "some-secret"
+		rotationPeriod      = 30 * 24 * time.Hour
+		previousKeyLifespan = 7 * 24 * time.Hour
+	)
+
+	var (
+		currentKey  = []byte("some-neato-32-byte-generated-key")
+		previousKey = []byte("some-funio-32-byte-generated-key")
+		newKey      = []byte("some-newer-32-byte-generated-key")
+
+		createdAt = time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC)
+		rotatedAt = createdAt.Add(60 * 24 * time.Hour)
+	)
+
+	newSecret := func(data map[string][]byte, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              secretName,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(createdAt),
+				Annotations:       annotations,
+				Labels:            map[string]string{"some-label": "some-value"},
+			},
+			Type: SupervisorCSRFSigningKeySecretType,
+			Data: data,
+		}
+	}
+	rotatedAtAnnotation := map[string]string{KeyRotatedAtAnnotation: rotatedAt.Format(time.RFC3339)}
+
+	tests := []struct {
+		name             string
+		secret           *corev1.Secret
+		rotationPeriod   time.Duration
+		now              time.Time
+		rand             string
+		updateErr        error
+		wantError        string
+		wantRequeueAfter time.Duration
+		wantSecret       *corev1.Secret
+	}{
+		{
+			name: "secret does not exist",
+			now:  createdAt,
+		},
+		{
+			name:   "secret has an invalid key",
+			secret: newSecret(map[string][]byte{"key": []byte("too short")}, nil),
+			now:    createdAt.Add(rotationPeriod),
+		},
+		{
+			name:             "key which was never rotated is not old enough to rotate",
+			secret:           newSecret(map[string][]byte{"key": currentKey}, nil),
+			now:              createdAt.Add(rotationPeriod - time.Hour),
+			wantRequeueAfter: time.Hour,
+		},
+		{
+			name:   "key which was never rotated is old enough to rotate",
+			secret: newSecret(map[string][]byte{"key": currentKey}, nil),
+			now:    createdAt.Add(rotationPeriod),
+			rand:   string(newKey),
+			wantSecret: newSecret(
+				map[string][]byte{"key": newKey, "previousKey": currentKey},
+				map[string]string{KeyRotatedAtAnnotation: createdAt.Add(rotationPeriod).Format(time.RFC3339)},
+			),
+		},
+		{
+			name:             "rotated key is not old enough to rotate, and the previous key is still accepted",
+			secret:           newSecret(map[string][]byte{"key": currentKey, "previousKey": previousKey}, rotatedAtAnnotation),
+			now:              rotatedAt.Add(time.Hour),
+			wantRequeueAfter: previousKeyLifespan - time.Hour,
+		},
+		{
+			name:       "rotated key is not old enough to rotate, and the previous key is no longer accepted",
+			secret:     newSecret(map[string][]byte{"key": currentKey, "previousKey": previousKey}, rotatedAtAnnotation),
+			now:        rotatedAt.Add(previousKeyLifespan),
+			wantSecret: newSecret(map[string][]byte{"key": currentKey}, rotatedAtAnnotation),
+		},
+		{
+			name:             "rotated key without a previous key is not old enough to rotate",
+			secret:           newSecret(map[string][]byte{"key": currentKey}, rotatedAtAnnotation),
+			now:              rotatedAt.Add(previousKeyLifespan),
+			wantRequeueAfter: rotationPeriod - previousKeyLifespan,
+		},
+		{
+			name:   "rotated key is old enough to rotate",
+			secret: newSecret(map[string][]byte{"key": currentKey, "previousKey": previousKey}, rotatedAtAnnotation),
+			now:    rotatedAt.Add(rotationPeriod + time.Minute),
+			rand:   string(newKey),
+			wantSecret: newSecret(
+				map[string][]byte{"key": newKey, "previousKey": currentKey},
+				map[string]string{KeyRotatedAtAnnotation: rotatedAt.Add(rotationPeriod + time.Minute).Format(time.RFC3339)},
+			),
+		},
+		{
+			name:             "rotation period is shorter than the lifespan of the previous key, and the previous key is still accepted",
+			secret:           newSecret(map[string][]byte{"key": currentKey, "previousKey": previousKey}, rotatedAtAnnotation),
+			rotationPeriod:   time.Hour,
+			now:              rotatedAt.Add(2 * time.Hour),
+			wantRequeueAfter: previousKeyLifespan - 2*time.Hour,
+		},
+		{
+			name:           "rotation period is shorter than the lifespan of the previous key, and the previous key is no longer accepted",
+			secret:         newSecret(map[string][]byte{"key": currentKey, "previousKey": previousKey}, rotatedAtAnnotation),
+			rotationPeriod: time.Hour,
+			now:            rotatedAt.Add(previousKeyLifespan),
+			rand:           string(newKey),
+			wantSecret: newSecret(
+				map[string][]byte{"key": newKey, "previousKey": currentKey},
+				map[string]string{KeyRotatedAtAnnotation: rotatedAt.Add(previousKeyLifespan).Format(time.RFC3339)},
+			),
+		},
+		{
+			name:           "rotation period is shorter than the lifespan of the previous key, and there is no previous key",
+			secret:         newSecret(map[string][]byte{"key": currentKey}, rotatedAtAnnotation),
+			rotationPeriod: time.Hour,
+			now:            rotatedAt.Add(time.Hour),
+			rand:           string(newKey),
+			wantSecret: newSecret(
+				map[string][]byte{"key": newKey, "previousKey": currentKey},
+				map[string]string{KeyRotatedAtAnnotation: rotatedAt.Add(time.Hour).Format(time.RFC3339)},
+			),
+		},
+		{
+			name:      "generating the key fails",
+			secret:    newSecret(map[string][]byte{"key": currentKey}, nil),
+			now:       createdAt.Add(rotationPeriod),
+			rand:      "too short",
+			wantError: "failed to generate key: unexpected EOF",
+		},
+		{
+			name:      "updating the secret fails",
+			secret:    newSecret(map[string][]byte{"key": currentKey}, nil),
+			now:       createdAt.Add(rotationPeriod),
+			rand:      string(newKey),
+			updateErr: errors.New("some update error"),
+			wantError: "failed to update secret some-namespace/some-secret: some update error",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var objects []runtime.Object
+			if tt.secret != nil {
+				objects = append(objects, tt.secret)
+			}
+			kubeAPIClient := kubernetesfake.NewSimpleClientset(objects...)
+			if tt.updateErr != nil {
+				kubeAPIClient.PrependReactor("update", "secrets", func(_ kubetesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.updateErr
+				})
+			}
+			kubeInformers := kubeinformers.NewSharedInformerFactory(kubernetesfake.NewSimpleClientset(objects...), 0)
+
+			period := rotationPeriod
+			if tt.rotationPeriod != 0 {
+				period = tt.rotationPeriod
+			}
+
+			subject := NewSymmetricKeyRotatorController(
+				"some-rotator",
+				func(obj metav1.Object) bool { return true },
+				kubeAPIClient,
+				kubeInformers.Core().V1().Secrets(),
+				controllerlib.WithInformer,
+				period,
+				previousKeyLifespan,
+				clocktesting.NewFakeClock(tt.now),
+				strings.NewReader(tt.rand),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			kubeInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			queue := &fakeAddAfterQueue{t: t}
+			err := controllerlib.TestSync(t, subject, controllerlib.Context{
+				Context: ctx,
+				Name:    subject.Name(),
+				Key:     controllerlib.Key{Namespace: namespace, Name: secretName},
+				Queue:   queue,
+			})
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRequeueAfter, queue.duration)
+
+			if tt.wantSecret == nil {
+				for _, action := range kubeAPIClient.Actions() {
+					require.NotEqual(t, "update", action.GetVerb())
+				}
+				return
+			}
+
+			actual, err := kubeAPIClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSecret, actual)
+		})
+	}
+}
+
+type fakeAddAfterQueue struct {
+	t *testing.T
+
+	duration time.Duration
+
+	controllerlib.Queue // panic if any other methods called
+}
+
+func (q *fakeAddAfterQueue) AddAfter(_ controllerlib.Key, duration time.Duration) {
+	q.t.Helper()
+
+	require.Zero(q.t, q.duration, "AddAfter should only be called once")
+
+	q.duration = duration
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"fmt"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/cryptosigner"
+)
+
+// NewExternalJWK returns the active JWK of all FederationDomains when their ID tokens are signed by the given
+// signer, e.g. by a key which is held by an external signer plugin, instead of by the keys in their JWKS Secrets.
+// Its Key is a jose.OpaqueSigner. The signer must have an ECDSA P-256 key, since ID tokens are signed with ES256.
+func NewExternalJWK(signer crypto.Signer) (*jose.JSONWebKey, error) {
+	publicKey, ok := signer.Public().(*ecdsa.PublicKey)
+	if !ok || publicKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("ID token signing key must be an ECDSA P-256 key, but got %T", signer.Public())
+	}
+
+	// derive the key ID from the key, so that clients do not confuse it with a previous key
+	thumbprint, err := (&jose.JSONWebKey{Key: publicKey}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute thumbprint of ID token signing key: %w", err)
+	}
+
+	return &jose.JSONWebKey{
+		Key:       cryptosigner.Opaque(signer),
+		KeyID:     "pinniped-supervisor-external-key-" + base64.RawURLEncoding.EncodeToString(thumbprint)[:16],
+		Algorithm: string(jose.ES256),
+		Use:       "sig",
+	}, nil
+}
+
+// publicExternalJWKS returns the JWKS which publishes the public key of an active JWK from NewExternalJWK.
+func publicExternalJWKS(externalJWK *jose.JSONWebKey) *jose.JSONWebKeySet {
+	return &jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{{
+			Key:       externalJWK.Key.(jose.OpaqueSigner).Public().Key,
+			KeyID:     externalJWK.KeyID,
+			Algorithm: externalJWK.Algorithm,
+			Use:       externalJWK.Use,
+		}},
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -14,11 +14,13 @@ import (
 	"go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/config/v1alpha1"
 	pinnipedcontroller "go.pinniped.dev/internal/controller"
 	"go.pinniped.dev/internal/controllerlib"
+	oidcprovider "go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
 )
 
 type jwksObserverController struct {
 	issuerToJWKSSetter       IssuerToJWKSMapSetter
+	externalJWK              *jose.JSONWebKey
 	federationDomainInformer v1alpha1.FederationDomainInformer
 	secretInformer           corev1informers.SecretInformer
 }
@@ -35,8 +37,11 @@ type IssuerToJWKSMapSetter interface {
 // This controller assumes that the informers passed to it are already scoped down to the
 // appropriate namespace. It also assumes that the IssuerToJWKSMapSetter passed to it has an
 // underlying implementation which is thread-safe.
+// The externalJWK is optional. When it is set, it is the active JWK of every FederationDomain,
+// see NewExternalJWK, and the keys in the JWKS Secrets are ignored.
 func NewJWKSObserverController(
 	issuerToJWKSSetter IssuerToJWKSMapSetter,
+	externalJWK *jose.JSONWebKey,
 	secretInformer corev1informers.SecretInformer,
 	federationDomainInformer v1alpha1.FederationDomainInformer,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
@@ -46,6 +51,7 @@ func NewJWKSObserverController(
 			Name: "jwks-observer-controller",
 			Syncer: &jwksObserverController{
 				issuerToJWKSSetter:       issuerToJWKSSetter,
+				externalJWK:              externalJWK,
 				federationDomainInformer: federationDomainInformer,
 				secretInformer:           secretInformer,
 			},
@@ -74,31 +80,39 @@ func (c *jwksObserverController) Sync(ctx controllerlib.Context) error {
 	// can cause the map to need to be updated.
 	issuerToJWKSMap := map[string]*jose.JSONWebKeySet{}
 	issuerToActiveJWKMap := map[string]*jose.JSONWebKey{}
+	previousIssuerToIssuer := map[string]string{}
 
 	for _, provider := range allProviders {
-		secretRef := provider.Status.Secrets.JWKS
-		jwksSecret, err := c.secretInformer.Lister().Secrets(ns).Get(secretRef.Name)
-		if err != nil {
-			plog.Debug("jwksObserverController Sync could not find JWKS secret", "namespace", ns, "secretName", secretRef.Name)
+		jwks, activeJWK, ok := c.jwksForFederationDomain(ns, provider.Status.Secrets.JWKS.Name)
+		if !ok {
 			continue
 		}
 
-		jwksFromSecret := jose.JSONWebKeySet{}
-		err = json.Unmarshal(jwksSecret.Data[jwksKey], &jwksFromSecret)
-		if err != nil {
-			plog.Debug("jwksObserverController Sync found a JWKS secret with Data in an unexpected format", "namespace", ns, "secretName", secretRef.Name)
-			continue
+		issuers := []string{provider.Spec.Issuer}
+		// Tokens which are issued via an alias host are signed by the same keys, but using the alias host's issuer.
+		if federationDomainIssuer, err := oidcprovider.NewFederationDomainIssuerWithAliasHosts(provider.Spec.Issuer, provider.Spec.AliasHosts); err == nil {
+			issuers = append(issuers, federationDomainIssuer.AliasIssuers()...)
 		}
 
-		activeJWKFromSecret := jose.JSONWebKey{}
-		err = json.Unmarshal(jwksSecret.Data[activeJWKKey], &activeJWKFromSecret)
-		if err != nil {
-			plog.Debug("jwksObserverController Sync found an active JWK secret with Data in an unexpected format", "namespace", ns, "secretName", secretRef.Name)
-			continue
+		for _, issuer := range issuers {
+			issuerToJWKSMap[issuer] = jwks
+			issuerToActiveJWKMap[issuer] = activeJWK
 		}
 
-		issuerToJWKSMap[provider.Spec.Issuer] = &jwksFromSecret
-		issuerToActiveJWKMap[provider.Spec.Issuer] = &activeJWKFromSecret
+		if provider.Spec.IssuerMigration != nil {
+			previousIssuerToIssuer[provider.Spec.IssuerMigration.PreviousIssuer] = provider.Spec.Issuer
+		}
+	}
+
+	// Tokens which are issued via the previous issuer of an issuer migration are signed by the same keys too. The
+	// previous issuer never replaces the keys of another FederationDomain's issuer, because its grace period may have
+	// ended, after which the same issuer may be used by another FederationDomain.
+	for previousIssuer, issuer := range previousIssuerToIssuer {
+		if _, ok := issuerToJWKSMap[previousIssuer]; ok {
+			continue
+		}
+		issuerToJWKSMap[previousIssuer] = issuerToJWKSMap[issuer]
+		issuerToActiveJWKMap[previousIssuer] = issuerToActiveJWKMap[issuer]
 	}
 
 	plog.Debug(
@@ -112,3 +126,31 @@ func (c *jwksObserverController) Sync(ctx controllerlib.Context) error {
 
 	return nil
 }
+
+func (c *jwksObserverController) jwksForFederationDomain(ns, secretName string) (*jose.JSONWebKeySet, *jose.JSONWebKey, bool) {
+	if c.externalJWK != nil {
+		return publicExternalJWKS(c.externalJWK), c.externalJWK, true
+	}
+
+	jwksSecret, err := c.secretInformer.Lister().Secrets(ns).Get(secretName)
+	if err != nil {
+		plog.Debug("jwksObserverController Sync could not find JWKS secret", "namespace", ns, "secretName", secretName)
+		return nil, nil, false
+	}
+
+	jwksFromSecret := jose.JSONWebKeySet{}
+	err = json.Unmarshal(jwksSecret.Data[jwksKey], &jwksFromSecret)
+	if err != nil {
+		plog.Debug("jwksObserverController Sync found a JWKS secret with Data in an unexpected format", "namespace", ns, "secretName", secretName)
+		return nil, nil, false
+	}
+
+	activeJWKFromSecret := jose.JSONWebKey{}
+	err = json.Unmarshal(jwksSecret.Data[activeJWKKey], &activeJWKFromSecret)
+	if err != nil {
+		plog.Debug("jwksObserverController Sync found an active JWK secret with Data in an unexpected format", "namespace", ns, "secretName", secretName)
+		return nil, nil, false
+	}
+
+	return &jwksFromSecret, &activeJWKFromSecret, true
+}
@@ -1,10 +1,14 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"encoding/json"
 	"testing"
 
@@ -39,6 +43,7 @@ func TestJWKSObserverControllerInformerFilters(t *testing.T) {
 			secretsInformer := kubeinformers.NewSharedInformerFactory(nil, 0).Core().V1().Secrets()
 			federationDomainInformer := pinnipedinformers.NewSharedInformerFactory(nil, 0).Config().V1alpha1().FederationDomains()
 			_ = NewJWKSObserverController(
+				nil,
 				nil,
 				secretsInformer,
 				federationDomainInformer,
@@ -133,6 +138,7 @@ func TestJWKSObserverControllerSync(t *testing.T) {
 			cancelContextCancelFunc context.CancelFunc
 			syncContext             *controllerlib.Context
 			issuerToJWKSSetter      *fakeIssuerToJWKSMapSetter
+			externalJWK             *jose.JSONWebKey
 		)
 
 		// Defer starting the informers until the last possible moment so that the
@@ -141,6 +147,7 @@ func TestJWKSObserverControllerSync(t *testing.T) {
 			// Set this at the last second to allow for injection of server override.
 			subject = NewJWKSObserverController(
 				issuerToJWKSSetter,
+				externalJWK,
 				kubeInformers.Core().V1().Secrets(),
 				pinnipedInformers.Config().V1alpha1().FederationDomains(),
 				controllerlib.WithInformer,
@@ -172,6 +179,7 @@ func TestJWKSObserverControllerSync(t *testing.T) {
 			pinnipedInformerClient = pinnipedfake.NewSimpleClientset()
 			pinnipedInformers = pinnipedinformers.NewSharedInformerFactory(pinnipedInformerClient, 0)
 			issuerToJWKSSetter = &fakeIssuerToJWKSMapSetter{}
+			externalJWK = nil
 
 			unrelatedSecret := &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
@@ -261,7 +269,12 @@ func TestJWKSObserverControllerSync(t *testing.T) {
 						Name:      "good-secret-federationdomain1",
 						Namespace: installedInNamespace,
 					},
-					Spec: v1alpha1.FederationDomainSpec{Issuer: "https://issuer-with-good-secret1.com"},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer: "https://issuer-with-good-secret1.com",
+						IssuerMigration: &v1alpha1.FederationDomainIssuerMigration{
+							PreviousIssuer: "https://previous-issuer-with-good-secret1.com",
+						},
+					},
 					Status: v1alpha1.FederationDomainStatus{
 						Secrets: v1alpha1.FederationDomainSecrets{
 							JWKS: corev1.LocalObjectReference{Name: "good-jwks-secret-name1"},
@@ -273,7 +286,14 @@ func TestJWKSObserverControllerSync(t *testing.T) {
 						Name:      "good-secret-federationdomain2",
 						Namespace: installedInNamespace,
 					},
-					Spec: v1alpha1.FederationDomainSpec{Issuer: "https://issuer-with-good-secret2.com"},
+					Spec: v1alpha1.FederationDomainSpec{
+						Issuer:     "https://issuer-with-good-secret2.com",
+						AliasHosts: []string{"alias-with-good-secret2.com:8443"},
+						// This previous issuer is now used by another FederationDomain.
+						IssuerMigration: &v1alpha1.FederationDomainIssuerMigration{
+							PreviousIssuer: "https://issuer-with-good-secret1.com",
+						},
+					},
 					Status: v1alpha1.FederationDomainStatus{
 						Secrets: v1alpha1.FederationDomainSecrets{
 							JWKS: corev1.LocalObjectReference{Name: "good-jwks-secret-name2"},
@@ -366,15 +386,85 @@ func TestJWKSObserverControllerSync(t *testing.T) {
 				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
 
 				r.True(issuerToJWKSSetter.setIssuerToJWKSMapWasCalled)
-				r.Len(issuerToJWKSSetter.issuerToJWKSMapReceived, 2)
-				r.Len(issuerToJWKSSetter.issuerToActiveJWKMapReceived, 2)
+				r.Len(issuerToJWKSSetter.issuerToJWKSMapReceived, 4)
+				r.Len(issuerToJWKSSetter.issuerToActiveJWKMapReceived, 4)
 
 				// the actual JWK should match the one from the test fixture that was put into the secret
 				requireJWKSJSON(expectedJWK1, issuerToJWKSSetter.issuerToJWKSMapReceived["https://issuer-with-good-secret1.com"])
 				requireJWKJSON(expectedJWK1, issuerToJWKSSetter.issuerToActiveJWKMapReceived["https://issuer-with-good-secret1.com"])
 				requireJWKSJSON(expectedJWK2, issuerToJWKSSetter.issuerToJWKSMapReceived["https://issuer-with-good-secret2.com"])
 				requireJWKJSON(expectedJWK2, issuerToJWKSSetter.issuerToActiveJWKMapReceived["https://issuer-with-good-secret2.com"])
+
+				// alias hosts use the same keys as the issuer
+				requireJWKSJSON(expectedJWK2, issuerToJWKSSetter.issuerToJWKSMapReceived["https://alias-with-good-secret2.com:8443"])
+				requireJWKJSON(expectedJWK2, issuerToJWKSSetter.issuerToActiveJWKMapReceived["https://alias-with-good-secret2.com:8443"])
+
+				// previous issuers use the same keys as the issuer, unless they are used by the issuer of another FederationDomain
+				requireJWKSJSON(expectedJWK1, issuerToJWKSSetter.issuerToJWKSMapReceived["https://previous-issuer-with-good-secret1.com"])
+				requireJWKJSON(expectedJWK1, issuerToJWKSSetter.issuerToActiveJWKMapReceived["https://previous-issuer-with-good-secret1.com"])
+			})
+		})
+
+		when("the ID tokens are signed by an external signer", func() {
+			var signer *ecdsa.PrivateKey
+
+			it.Before(func() {
+				var err error
+				signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				r.NoError(err)
+				externalJWK, err = NewExternalJWK(signer)
+				r.NoError(err)
+
+				federationDomainWithoutSecret := &v1alpha1.FederationDomain{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "no-secret-federationdomain",
+						Namespace: installedInNamespace,
+					},
+					Spec: v1alpha1.FederationDomainSpec{Issuer: "https://no-secret-issuer.com"},
+				}
+				r.NoError(pinnipedInformerClient.Tracker().Add(federationDomainWithoutSecret))
+			})
+
+			it("uses the external key for every issuer and only publishes its public key", func() {
+				startInformersAndController()
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				r.True(issuerToJWKSSetter.setIssuerToJWKSMapWasCalled)
+				r.Len(issuerToJWKSSetter.issuerToJWKSMapReceived, 1)
+				r.Same(externalJWK, issuerToJWKSSetter.issuerToActiveJWKMapReceived["https://no-secret-issuer.com"])
+
+				jwks := issuerToJWKSSetter.issuerToJWKSMapReceived["https://no-secret-issuer.com"]
+				r.Len(jwks.Keys, 1)
+				r.True(jwks.Keys[0].IsPublic())
+				r.Equal(&signer.PublicKey, jwks.Keys[0].Key)
+				r.Equal(externalJWK.KeyID, jwks.Keys[0].KeyID)
+				r.Equal("ES256", jwks.Keys[0].Algorithm)
+				r.Equal("sig", jwks.Keys[0].Use)
 			})
 		})
 	}, spec.Parallel(), spec.Report(report.Terminal{}))
 }
+
+func TestNewExternalJWK(t *testing.T) {
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, err = NewExternalJWK(p384Key)
+	require.EqualError(t, err, "ID token signing key must be an ECDSA P-256 key, but got *ecdsa.PublicKey")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = NewExternalJWK(rsaKey)
+	require.EqualError(t, err, "ID token signing key must be an ECDSA P-256 key, but got *rsa.PublicKey")
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := NewExternalJWK(p256Key)
+	require.NoError(t, err)
+	require.Regexp(t, "^pinniped-supervisor-external-key-[A-Za-z0-9_-]{16}$", jwk.KeyID)
+	require.Implements(t, (*jose.OpaqueSigner)(nil), jwk.Key)
+
+	// the key ID only depends on the key
+	again, err := NewExternalJWK(p256Key)
+	require.NoError(t, err)
+	require.Equal(t, jwk.KeyID, again.KeyID)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
 
 	"gopkg.in/square/go-jose.v2"
 	corev1 "k8s.io/api/core/v1"
@@ -56,6 +57,67 @@ func generateECKey(r io.Reader) (interface{}, error) {
 	return ecdsa.GenerateKey(elliptic.P256(), r)
 }
 
+// keyPool hands out signing keys which were generated ahead of time in the background, so that new
+// FederationDomains do not wait for key generation. It starts generating keys when it is first primed, and
+// refills itself in the background whenever a key is taken.
+type keyPool struct {
+	keys chan interface{}
+
+	lock    sync.Mutex
+	filling bool
+}
+
+func newKeyPool(size int) *keyPool {
+	return &keyPool{keys: make(chan interface{}, size)}
+}
+
+// get returns a key from the pool, or generates one when the pool is empty.
+func (p *keyPool) get() (interface{}, error) {
+	defer p.prime()
+
+	select {
+	case key := <-p.keys:
+		return key, nil
+	default:
+		return generateKey(rand.Reader)
+	}
+}
+
+// prime fills the pool in the background, unless it is already being filled.
+func (p *keyPool) prime() {
+	if cap(p.keys) == 0 {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.filling {
+		return
+	}
+	p.filling = true
+
+	go func() {
+		defer func() {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			p.filling = false
+		}()
+
+		for len(p.keys) < cap(p.keys) {
+			key, err := generateKey(rand.Reader)
+			if err != nil {
+				plog.Debug("cannot generate key in the background", "err", err)
+				return
+			}
+			select {
+			case p.keys <- key:
+			default:
+				return // the pool is full
+			}
+		}
+	}()
+}
+
 // jwkController holds the fields necessary for the JWKS controller to communicate with FederationDomains and
 // secrets, both via a cache and via the API.
 type jwksWriterController struct {
@@ -64,16 +126,19 @@ type jwksWriterController struct {
 	kubeClient               kubernetes.Interface
 	federationDomainInformer configinformers.FederationDomainInformer
 	secretInformer           corev1informers.SecretInformer
+	keys                     *keyPool
 }
 
 // NewJWKSWriterController returns a controllerlib.Controller that ensures a FederationDomain has a corresponding
-// Secret that contains a valid active JWK and JWKS.
+// Secret that contains a valid active JWK and JWKS. Up to primedKeys keys are generated ahead of time in the
+// background once the first FederationDomain is synced, so that each new FederationDomain gets a key right away.
 func NewJWKSWriterController(
 	jwksSecretLabels map[string]string,
 	kubeClient kubernetes.Interface,
 	pinnipedClient pinnipedclientset.Interface,
 	secretInformer corev1informers.SecretInformer,
 	federationDomainInformer configinformers.FederationDomainInformer,
+	primedKeys int,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
 ) controllerlib.Controller {
 	isSecretToSync := func(obj metav1.Object) bool {
@@ -89,6 +154,7 @@ func NewJWKSWriterController(
 				pinnipedClient:           pinnipedClient,
 				secretInformer:           secretInformer,
 				federationDomainInformer: federationDomainInformer,
+				keys:                     newKeyPool(primedKeys),
 			},
 		},
 		// We want to be notified when a FederationDomain's secret gets updated or deleted. When this happens, we
@@ -109,6 +175,8 @@ func NewJWKSWriterController(
 
 // Sync implements controllerlib.Syncer.
 func (c *jwksWriterController) Sync(ctx controllerlib.Context) error {
+	c.keys.prime()
+
 	federationDomain, err := c.federationDomainInformer.Lister().FederationDomains(ctx.Key.Namespace).Get(ctx.Key.Name)
 	notFound := k8serrors.IsNotFound(err)
 	if err != nil && !notFound {
@@ -198,9 +266,9 @@ func (c *jwksWriterController) generateSecret(federationDomain *configv1alpha1.F
 	// this FederationDomain should sign and verify ID tokens (e.g., hardcoded token secret, gRPC
 	// connection to KMS, etc).
 	//
-	// For now, we just generate an new RSA keypair and put that in the secret.
+	// For now, we just take a new EC keypair from the pool and put that in the secret.
 
-	key, err := generateKey(rand.Reader)
+	key, err := c.keys.get()
 	if err != nil {
 		return nil, fmt.Errorf("cannot generate key: %w", err)
 	}
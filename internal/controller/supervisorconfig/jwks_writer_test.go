@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -11,7 +11,9 @@ import (
 	"errors"
 	"io"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -186,6 +188,7 @@ func TestJWKSWriterControllerFilterSecret(t *testing.T) {
 				nil, // pinnipedClient, not needed
 				secretInformer,
 				federationDomainInformer,
+				0, // primedKeys, not needed
 				withInformer.WithInformer,
 			)
 
@@ -240,6 +243,7 @@ func TestJWKSWriterControllerFilterFederationDomain(t *testing.T) {
 				nil, // pinnipedClient, not needed
 				secretInformer,
 				federationDomainInformer,
+				0, // primedKeys, not needed
 				withInformer.WithInformer,
 			)
 
@@ -714,6 +718,7 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 				pinnipedAPIClient,
 				kubeInformers.Core().V1().Secrets(),
 				pinnipedInformers.Config().V1alpha1().FederationDomains(),
+				0, // generate keys on demand, since the tests count the generated keys
 				controllerlib.WithInformer,
 			)
 
@@ -744,6 +749,60 @@ func TestJWKSWriterControllerSync(t *testing.T) {
 	}
 }
 
+func TestKeyPool(t *testing.T) {
+	// We shouldn't run this test in parallel since it messes with a global function (generateKey).
+	var lock sync.Mutex
+	generateKeyCount := 0
+	generateKey = func(r io.Reader) (interface{}, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		generateKeyCount++
+		return generateECKey(r)
+	}
+	generatedKeys := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return generateKeyCount
+	}
+	isFilling := func(p *keyPool) bool {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		return p.filling
+	}
+
+	t.Run("without primed keys", func(t *testing.T) {
+		p := newKeyPool(0)
+		p.prime()
+
+		key, err := p.get()
+		require.NoError(t, err)
+		require.NotNil(t, key)
+		require.False(t, isFilling(p))
+		require.Equal(t, 1, generatedKeys())
+	})
+
+	t.Run("with primed keys", func(t *testing.T) {
+		generateKeyCount = 0
+		p := newKeyPool(3)
+		p.prime()
+		p.prime() // only one goroutine fills the pool
+		require.Eventually(t, func() bool { return len(p.keys) == 3 && !isFilling(p) }, time.Minute, 10*time.Millisecond)
+		require.Equal(t, 3, generatedKeys())
+
+		keys := map[interface{}]bool{}
+		for i := 0; i < 3; i++ {
+			key, err := p.get()
+			require.NoError(t, err)
+			keys[key] = true
+		}
+		require.Len(t, keys, 3)
+
+		// the pool refills itself after keys are taken
+		require.Eventually(t, func() bool { return len(p.keys) == 3 && !isFilling(p) }, time.Minute, 10*time.Millisecond)
+		require.GreaterOrEqual(t, generatedKeys(), 6)
+	})
+}
+
 func readJWKJSON(t *testing.T, path string) []byte {
 	t.Helper()
 
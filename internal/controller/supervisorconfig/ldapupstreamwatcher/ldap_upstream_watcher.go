@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package ldapupstreamwatcher implements a controller which watches LDAPIdentityProviders.
@@ -77,7 +77,7 @@ func (s *ldapUpstreamGenericLDAPSpec) GroupSearch() upstreamwatchers.UpstreamGen
 	return &ldapUpstreamGenericLDAPGroupSearch{s.ldapIdentityProvider.Spec.GroupSearch}
 }
 
-func (s *ldapUpstreamGenericLDAPSpec) DetectAndSetSearchBase(_ context.Context, config *upstreamldap.ProviderConfig) *v1alpha1.Condition {
+func (s *ldapUpstreamGenericLDAPSpec) DetectAndSetSearchBase(_ context.Context, config *upstreamldap.ProviderConfig) *metav1.Condition {
 	config.GroupSearch.Base = s.ldapIdentityProvider.Spec.GroupSearch.Base
 	config.UserSearch.Base = s.ldapIdentityProvider.Spec.UserSearch.Base
 	return nil
@@ -119,11 +119,15 @@ func (g *ldapUpstreamGenericLDAPGroupSearch) GroupNameAttribute() string {
 	return g.groupSearch.Attributes.GroupName
 }
 
+func (g *ldapUpstreamGenericLDAPGroupSearch) Transformations() []v1alpha1.GroupTransformation {
+	return g.groupSearch.Transformations
+}
+
 type ldapUpstreamGenericLDAPStatus struct {
 	ldapIdentityProvider v1alpha1.LDAPIdentityProvider
 }
 
-func (s *ldapUpstreamGenericLDAPStatus) Conditions() []v1alpha1.Condition {
+func (s *ldapUpstreamGenericLDAPStatus) Conditions() []metav1.Condition {
 	return s.ldapIdentityProvider.Status.Conditions
 }
 
@@ -205,7 +209,7 @@ func (c *ldapWatcherController) Sync(ctx controllerlib.Context) error {
 	requeue := false
 	validatedUpstreams := make([]provider.UpstreamLDAPIdentityProviderI, 0, len(actualUpstreams))
 	for _, upstream := range actualUpstreams {
-		valid, requestedRequeue := c.validateUpstream(ctx.Context, upstream)
+		valid, requestedRequeue := c.validateUpstream(ctx, upstream)
 		if valid != nil {
 			validatedUpstreams = append(validatedUpstreams, valid)
 		}
@@ -222,7 +226,7 @@ func (c *ldapWatcherController) Sync(ctx controllerlib.Context) error {
 	return nil
 }
 
-func (c *ldapWatcherController) validateUpstream(ctx context.Context, upstream *v1alpha1.LDAPIdentityProvider) (p provider.UpstreamLDAPIdentityProviderI, requeue bool) {
+func (c *ldapWatcherController) validateUpstream(ctx controllerlib.Context, upstream *v1alpha1.LDAPIdentityProvider) (p provider.UpstreamLDAPIdentityProviderI, requeue bool) {
 	spec := upstream.Spec
 
 	config := &upstreamldap.ProviderConfig{
@@ -244,18 +248,18 @@ func (c *ldapWatcherController) validateUpstream(ctx context.Context, upstream *
 		Dialer: c.ldapDialer,
 	}
 
-	conditions := upstreamwatchers.ValidateGenericLDAP(ctx, &ldapUpstreamGenericLDAPImpl{*upstream}, c.secretInformer, c.validatedSettingsCache, config)
+	conditions := upstreamwatchers.ValidateGenericLDAP(ctx.Context, &ldapUpstreamGenericLDAPImpl{*upstream}, c.secretInformer, c.validatedSettingsCache, config)
 
 	c.updateStatus(ctx, upstream, conditions.Conditions())
 
 	return upstreamwatchers.EvaluateConditions(conditions, config)
 }
 
-func (c *ldapWatcherController) updateStatus(ctx context.Context, upstream *v1alpha1.LDAPIdentityProvider, conditions []*v1alpha1.Condition) {
+func (c *ldapWatcherController) updateStatus(ctx controllerlib.Context, upstream *v1alpha1.LDAPIdentityProvider, conditions []*metav1.Condition) {
 	log := plog.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
 	updated := upstream.DeepCopy()
 
-	hadErrorCondition := conditionsutil.MergeIDPConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
+	hadErrorCondition := conditionsutil.MergeConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
 
 	updated.Status.Phase = v1alpha1.LDAPPhaseReady
 	if hadErrorCondition {
@@ -269,8 +273,13 @@ func (c *ldapWatcherController) updateStatus(ctx context.Context, upstream *v1al
 	_, err := c.client.
 		IDPV1alpha1().
 		LDAPIdentityProviders(upstream.Namespace).
-		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+		UpdateStatus(ctx.Context, updated, metav1.UpdateOptions{})
 	if err != nil {
 		log.Error("failed to update status", err)
+		return
 	}
+
+	conditionsutil.RecordConditionFailures(ctx.Recorder,
+		conditionsutil.ObjectReference(upstream, v1alpha1.SchemeGroupVersion.WithKind("LDAPIdentityProvider")),
+		upstream.Status.Conditions, updated.Status.Conditions)
 }
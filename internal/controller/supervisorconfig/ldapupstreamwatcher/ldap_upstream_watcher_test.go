@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package ldapupstreamwatcher
@@ -28,6 +28,7 @@ import (
 	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
 	"go.pinniped.dev/internal/controllerlib"
 	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/grouptransform"
 	"go.pinniped.dev/internal/mocks/mockldapconn"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/testutil"
@@ -232,8 +233,8 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 	providerConfigForValidUpstreamWithStartTLS := &copyOfProviderConfigForValidUpstreamWithTLS
 	providerConfigForValidUpstreamWithStartTLS.ConnectionProtocol = upstreamldap.StartTLS
 
-	bindSecretValidTrueCondition := func(gen int64) v1alpha1.Condition {
-		return v1alpha1.Condition{
+	bindSecretValidTrueCondition := func(gen int64) metav1.Condition {
+		return metav1.Condition{
 			Type:               "BindSecretValid",
 			Status:             "True",
 			LastTransitionTime: now,
@@ -242,8 +243,8 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			ObservedGeneration: gen,
 		}
 	}
-	ldapConnectionValidTrueCondition := func(gen int64, secretVersion string) v1alpha1.Condition {
-		return v1alpha1.Condition{
+	ldapConnectionValidTrueCondition := func(gen int64, secretVersion string) metav1.Condition {
+		return metav1.Condition{
 			Type:               "LDAPConnectionValid",
 			Status:             "True",
 			LastTransitionTime: now,
@@ -254,16 +255,26 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			ObservedGeneration: gen,
 		}
 	}
-	ldapConnectionValidTrueConditionWithoutTimeOrGeneration := func(secretVersion string) v1alpha1.Condition {
+	ldapConnectionValidTrueConditionWithoutTimeOrGeneration := func(secretVersion string) metav1.Condition {
 		c := ldapConnectionValidTrueCondition(0, secretVersion)
 		c.LastTransitionTime = metav1.Time{}
 		return c
 	}
-	condPtr := func(c v1alpha1.Condition) *v1alpha1.Condition {
+	condPtr := func(c metav1.Condition) *metav1.Condition {
 		return &c
 	}
-	tlsConfigurationValidLoadedTrueCondition := func(gen int64) v1alpha1.Condition {
-		return v1alpha1.Condition{
+	groupTransformationsValidTrueCondition := func(gen int64) metav1.Condition {
+		return metav1.Condition{
+			Type:               "GroupTransformationsValid",
+			Status:             "True",
+			LastTransitionTime: now,
+			Reason:             "Success",
+			Message:            "loaded group transformations",
+			ObservedGeneration: gen,
+		}
+	}
+	tlsConfigurationValidLoadedTrueCondition := func(gen int64) metav1.Condition {
+		return metav1.Condition{
 			Type:               "TLSConfigurationValid",
 			Status:             "True",
 			LastTransitionTime: now,
@@ -272,9 +283,10 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			ObservedGeneration: gen,
 		}
 	}
-	allConditionsTrue := func(gen int64, secretVersion string) []v1alpha1.Condition {
-		return []v1alpha1.Condition{
+	allConditionsTrue := func(gen int64, secretVersion string) []metav1.Condition {
+		return []metav1.Condition{
 			bindSecretValidTrueCondition(gen),
+			groupTransformationsValidTrueCondition(gen),
 			ldapConnectionValidTrueCondition(gen, secretVersion),
 			tlsConfigurationValidLoadedTrueCondition(gen),
 		}
@@ -340,7 +352,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						{
 							Type:               "BindSecretValid",
 							Status:             "False",
@@ -349,6 +361,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`secret "%s" not found`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						groupTransformationsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
 					},
 				},
@@ -368,7 +381,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						{
 							Type:               "BindSecretValid",
 							Status:             "False",
@@ -377,6 +390,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`referenced Secret "%s" has wrong type "some-other-type" (should be "kubernetes.io/basic-auth")`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						groupTransformationsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
 					},
 				},
@@ -395,7 +409,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						{
 							Type:               "BindSecretValid",
 							Status:             "False",
@@ -404,6 +418,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 							Message:            fmt.Sprintf(`referenced Secret "%s" is missing required keys ["username" "password"]`, testSecretName),
 							ObservedGeneration: 1234,
 						},
+						groupTransformationsValidTrueCondition(1234),
 						tlsConfigurationValidLoadedTrueCondition(1234),
 					},
 				},
@@ -421,8 +436,9 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "False",
@@ -447,8 +463,9 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "TLSConfigurationValid",
 							Status:             "False",
@@ -498,8 +515,9 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						ldapConnectionValidTrueCondition(1234, "4242"),
 						{
 							Type:               "TLSConfigurationValid",
@@ -562,8 +580,9 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "True",
@@ -584,7 +603,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				UserSearchBase:            testUserSearchBase,
 				GroupSearchBase:           testGroupSearchBase,
 				IDPSpecGeneration:         1234,
-				ConnectionValidCondition: &v1alpha1.Condition{
+				ConnectionValidCondition: &metav1.Condition{
 					Type:   "LDAPConnectionValid",
 					Status: "True",
 					Reason: "Success",
@@ -634,8 +653,9 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -722,7 +742,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "other-upstream", Generation: 42, UID: "other-uid"},
 					Status: v1alpha1.LDAPIdentityProviderStatus{
 						Phase: "Error",
-						Conditions: []v1alpha1.Condition{
+						Conditions: []metav1.Condition{
 							{
 								Type:               "BindSecretValid",
 								Status:             "False",
@@ -731,6 +751,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 								Message:            fmt.Sprintf(`secret "%s" not found`, "non-existent-secret"),
 								ObservedGeneration: 42,
 							},
+							groupTransformationsValidTrueCondition(42),
 							tlsConfigurationValidLoadedTrueCondition(42),
 						},
 					},
@@ -768,8 +789,9 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Error",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						{
 							Type:               "LDAPConnectionValid",
 							Status:             "False",
@@ -790,7 +812,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection was already validated using TLS for the current resource generation and secret version, then do not validate it again and keep using TLS",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					ldapConnectionValidTrueCondition(1234, "4242"),
 				}
 			})},
@@ -827,7 +849,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection was already validated using StartTLS for the current resource generation and secret version, then do not validate it again and keep using StartTLS",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					ldapConnectionValidTrueCondition(1234, "4242"),
 				}
 			})},
@@ -864,7 +886,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection was validated for an older resource generation, then try to validate it again",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
 				upstream.Generation = 1234 // current generation
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					ldapConnectionValidTrueCondition(1233, "4242"), // older spec generation!
 				}
 			})},
@@ -902,7 +924,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection condition failed to update previously, then write the cached condition from the previous connection validation",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
 				upstream.Generation = 1234 // current generation
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					ldapConnectionValidTrueCondition(1234, "4200"), // old version of the condition, as if the previous update of conditions had failed
 				}
 			})},
@@ -940,7 +962,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection validation previously failed for this resource generation, then try to validate it again",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					{
 						Type:               "LDAPConnectionValid",
 						Status:             "False", // failure!
@@ -979,7 +1001,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			// this shouldn't happen, but if it does, just throw it out and try again.
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					{
 						Type:               "LDAPConnectionValid",
 						Status:             "False", // failure!
@@ -1021,7 +1043,7 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 			name: "when the LDAP server connection was already validated for this resource generation but the bind secret has changed, then try to validate it again",
 			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
 				upstream.Generation = 1234
-				upstream.Status.Conditions = []v1alpha1.Condition{
+				upstream.Status.Conditions = []metav1.Condition{
 					ldapConnectionValidTrueCondition(1234, "4241"), // same spec generation, old secret version
 				}
 			})},
@@ -1092,8 +1114,9 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
 				Status: v1alpha1.LDAPIdentityProviderStatus{
 					Phase: "Ready",
-					Conditions: []v1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						bindSecretValidTrueCondition(1234),
+						groupTransformationsValidTrueCondition(1234),
 						ldapConnectionValidTrueCondition(1234, "4242"),
 						{
 							Type:               "TLSConfigurationValid",
@@ -1115,6 +1138,107 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
 			}},
 		},
+		{
+			name: "valid group transformations are loaded",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.GroupSearch.Transformations = []v1alpha1.GroupTransformation{
+					{Type: v1alpha1.GroupTransformationTypeStripDN},
+					{Type: v1alpha1.GroupTransformationTypeReplace, Pattern: "k8s-(.*)", Replacement: "$1"},
+					{Type: v1alpha1.GroupTransformationTypeDrop, Pattern: "domain users"},
+					{Type: v1alpha1.GroupTransformationTypeExpression, Expression: "group.lowerAscii()"},
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantResultingCache: []*upstreamldap.ProviderConfig{
+				{
+					Name:               testName,
+					ResourceUID:        testResourceUID,
+					Host:               testHost,
+					ConnectionProtocol: upstreamldap.TLS,
+					CABundle:           testCABundle,
+					BindUsername:       testBindUsername,
+					BindPassword:       testBindPassword,
+					UserSearch: upstreamldap.UserSearchConfig{
+						Base:              testUserSearchBase,
+						Filter:            testUserSearchFilter,
+						UsernameAttribute: testUsernameAttrName,
+						UIDAttribute:      testUIDAttrName,
+					},
+					GroupSearch: upstreamldap.GroupSearchConfig{
+						Base:               testGroupSearchBase,
+						Filter:             testGroupSearchFilter,
+						GroupNameAttribute: testGroupNameAttrName,
+						Transformations:    make(grouptransform.Transformations, 4),
+					},
+				},
+			},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase:      "Ready",
+					Conditions: allConditionsTrue(1234, "4242"),
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
+		{
+			name: "invalid group transformations result in an error status and the upstream is not added to the cache",
+			inputUpstreams: []runtime.Object{editedValidUpstream(func(upstream *v1alpha1.LDAPIdentityProvider) {
+				upstream.Spec.GroupSearch.Transformations = []v1alpha1.GroupTransformation{
+					{Type: v1alpha1.GroupTransformationTypeStripDN},
+					{Type: v1alpha1.GroupTransformationTypeReplace, Pattern: "k8s-(.*", Replacement: "$1"},
+					{Type: v1alpha1.GroupTransformationTypeExpression, Expression: "size(group)"},
+				}
+			})},
+			inputSecrets: []runtime.Object{validBindUserSecret("4242")},
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				// Should perform a test dial and bind.
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantErr:            controllerlib.ErrSyntheticRequeue.Error(),
+			wantResultingCache: []*upstreamldap.ProviderConfig{},
+			wantResultingUpstreams: []v1alpha1.LDAPIdentityProvider{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testResourceUID},
+				Status: v1alpha1.LDAPIdentityProviderStatus{
+					Phase: "Error",
+					Conditions: []metav1.Condition{
+						bindSecretValidTrueCondition(1234),
+						{
+							Type:               "GroupTransformationsValid",
+							Status:             "False",
+							LastTransitionTime: now,
+							Reason:             "InvalidGroupTransformations",
+							Message: "groupSearch.transformations is invalid: [transformations[1]: pattern is not a valid regular expression: " +
+								"error parsing regexp: missing closing ): `k8s-(.*`, transformations[2]: expression must result in a string, but results in int]",
+							ObservedGeneration: 1234,
+						},
+						ldapConnectionValidTrueCondition(1234, "4242"),
+						tlsConfigurationValidLoadedTrueCondition(1234),
+					},
+				},
+			}},
+			wantValidatedSettings: map[string]upstreamwatchers.ValidatedSettings{testName: {
+				BindSecretResourceVersion: "4242",
+				LDAPConnectionProtocol:    upstreamldap.TLS,
+				UserSearchBase:            testUserSearchBase,
+				GroupSearchBase:           testGroupSearchBase,
+				IDPSpecGeneration:         1234,
+				ConnectionValidCondition:  condPtr(ldapConnectionValidTrueConditionWithoutTimeOrGeneration("4242")),
+			}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1193,7 +1317,12 @@ func TestLDAPUpstreamWatcherControllerSync(t *testing.T) {
 				// The dialer that was passed in to the controller's constructor should always have been
 				// passed through to the provider.
 				copyOfExpectedValueForResultingCache.Dialer = dialer
-				require.Equal(t, copyOfExpectedValueForResultingCache, actualIDP.GetConfig())
+				// Compiled group transformations are functions, which cannot be compared, so only compare how many there are.
+				actualConfig := actualIDP.GetConfig()
+				require.Len(t, actualConfig.GroupSearch.Transformations, len(copyOfExpectedValueForResultingCache.GroupSearch.Transformations))
+				actualConfig.GroupSearch.Transformations = nil
+				copyOfExpectedValueForResultingCache.GroupSearch.Transformations = nil
+				require.Equal(t, copyOfExpectedValueForResultingCache, actualConfig)
 			}
 
 			actualUpstreams, err := fakePinnipedClient.IDPV1alpha1().LDAPIdentityProviders(testNamespace).List(ctx, metav1.ListOptions{})
@@ -1,10 +1,9 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclientwatcher
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
@@ -108,9 +107,9 @@ func (c *oidcClientWatcherController) Sync(ctx controllerlib.Context) error {
 			secret = nil
 		}
 
-		_, conditions, clientSecrets := oidcclientvalidator.Validate(oidcClient, secret, oidcclientvalidator.DefaultMinBcryptCost)
+		valid, conditions, clientSecrets := oidcclientvalidator.Validate(oidcClient, secret, oidcclientvalidator.DefaultMinBcryptCost)
 
-		if err := c.updateStatus(ctx.Context, oidcClient, conditions, len(clientSecrets)); err != nil {
+		if err := c.updateStatus(ctx, oidcClient, valid, conditions, len(clientSecrets)); err != nil {
 			return fmt.Errorf("cannot update OIDCClient '%s/%s': %w", oidcClient.Namespace, oidcClient.Name, err)
 		}
 
@@ -126,17 +125,20 @@ func (c *oidcClientWatcherController) Sync(ctx controllerlib.Context) error {
 }
 
 func (c *oidcClientWatcherController) updateStatus(
-	ctx context.Context,
+	ctx controllerlib.Context,
 	upstream *v1alpha1.OIDCClient,
-	conditions []*v1alpha1.Condition,
+	valid bool,
+	conditions []*metav1.Condition,
 	totalClientSecrets int,
 ) error {
 	updated := upstream.DeepCopy()
 
-	hadErrorCondition := conditionsutil.MergeConfigConditions(conditions, upstream.Generation, &updated.Status.Conditions, plog.New())
+	// Some conditions are only warnings (e.g. client secrets which will expire soon), so decide the phase based on
+	// the overall validity of the OIDCClient rather than on whether any condition was false.
+	_ = conditionsutil.MergeConditions(conditions, upstream.Generation, &updated.Status.Conditions, plog.New())
 
 	updated.Status.Phase = v1alpha1.PhaseReady
-	if hadErrorCondition {
+	if !valid {
 		updated.Status.Phase = v1alpha1.PhaseError
 	}
 
@@ -149,6 +151,13 @@ func (c *oidcClientWatcherController) updateStatus(
 	_, err := c.pinnipedClient.
 		ConfigV1alpha1().
 		OIDCClients(upstream.Namespace).
-		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
-	return err
+		UpdateStatus(ctx.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	conditionsutil.RecordConditionFailures(ctx.Recorder,
+		conditionsutil.ObjectReference(upstream, v1alpha1.SchemeGroupVersion.WithKind("OIDCClient")),
+		upstream.Status.Conditions, updated.Status.Conditions)
+	return nil
 }
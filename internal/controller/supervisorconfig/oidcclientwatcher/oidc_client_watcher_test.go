@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclientwatcher
@@ -20,6 +20,7 @@ import (
 	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
 	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/oidcclientsecretstorage"
 	"go.pinniped.dev/internal/testutil"
 )
 
@@ -169,8 +170,8 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 	now := metav1.NewTime(time.Now().UTC())
 	earlier := metav1.NewTime(now.Add(-1 * time.Hour).UTC())
 
-	happyAllowedGrantTypesCondition := func(time metav1.Time, observedGeneration int64) configv1alpha1.Condition {
-		return configv1alpha1.Condition{
+	happyAllowedGrantTypesCondition := func(time metav1.Time, observedGeneration int64) metav1.Condition {
+		return metav1.Condition{
 			Type:               "AllowedGrantTypesValid",
 			Status:             "True",
 			LastTransitionTime: time,
@@ -180,8 +181,8 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	sadAllowedGrantTypesCondition := func(time metav1.Time, observedGeneration int64, message string) configv1alpha1.Condition {
-		return configv1alpha1.Condition{
+	sadAllowedGrantTypesCondition := func(time metav1.Time, observedGeneration int64, message string) metav1.Condition {
+		return metav1.Condition{
 			Type:               "AllowedGrantTypesValid",
 			Status:             "False",
 			LastTransitionTime: time,
@@ -191,8 +192,8 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	happyClientSecretsCondition := func(howMany int, time metav1.Time, observedGeneration int64) configv1alpha1.Condition {
-		return configv1alpha1.Condition{
+	happyClientSecretsCondition := func(howMany int, time metav1.Time, observedGeneration int64) metav1.Condition {
+		return metav1.Condition{
 			Type:               "ClientSecretExists",
 			Status:             "True",
 			LastTransitionTime: time,
@@ -202,8 +203,8 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	sadNoClientSecretsCondition := func(time metav1.Time, observedGeneration int64, message string) configv1alpha1.Condition {
-		return configv1alpha1.Condition{
+	sadNoClientSecretsCondition := func(time metav1.Time, observedGeneration int64, message string) metav1.Condition {
+		return metav1.Condition{
 			Type:               "ClientSecretExists",
 			Status:             "False",
 			LastTransitionTime: time,
@@ -213,8 +214,8 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	sadInvalidClientSecretsCondition := func(time metav1.Time, observedGeneration int64, message string) configv1alpha1.Condition {
-		return configv1alpha1.Condition{
+	sadInvalidClientSecretsCondition := func(time metav1.Time, observedGeneration int64, message string) metav1.Condition {
+		return metav1.Condition{
 			Type:               "ClientSecretExists",
 			Status:             "False",
 			LastTransitionTime: time,
@@ -224,8 +225,30 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	happyAllowedScopesCondition := func(time metav1.Time, observedGeneration int64) configv1alpha1.Condition {
-		return configv1alpha1.Condition{
+	happyClientSecretsNotExpiringCondition := func(time metav1.Time, observedGeneration int64) metav1.Condition {
+		return metav1.Condition{
+			Type:               "ClientSecretsNotExpiring",
+			Status:             "True",
+			LastTransitionTime: time,
+			Reason:             "Success",
+			Message:            "no client secrets will expire within 168h0m0s",
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	sadClientSecretsExpiringCondition := func(time metav1.Time, observedGeneration int64, message string) metav1.Condition {
+		return metav1.Condition{
+			Type:               "ClientSecretsNotExpiring",
+			Status:             "False",
+			LastTransitionTime: time,
+			Reason:             "ClientSecretExpiringSoon",
+			Message:            message,
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	happyAllowedScopesCondition := func(time metav1.Time, observedGeneration int64) metav1.Condition {
+		return metav1.Condition{
 			Type:               "AllowedScopesValid",
 			Status:             "True",
 			LastTransitionTime: time,
@@ -235,8 +258,8 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 		}
 	}
 
-	sadAllowedScopesCondition := func(time metav1.Time, observedGeneration int64, message string) configv1alpha1.Condition {
-		return configv1alpha1.Condition{
+	sadAllowedScopesCondition := func(time metav1.Time, observedGeneration int64, message string) metav1.Condition {
+		return metav1.Condition{
 			Type:               "AllowedScopesValid",
 			Status:             "False",
 			LastTransitionTime: time,
@@ -246,6 +269,28 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 		}
 	}
 
+	happyGroupsClaimCondition := func(time metav1.Time, observedGeneration int64) metav1.Condition {
+		return metav1.Condition{
+			Type:               "GroupsClaimValid",
+			Status:             "True",
+			LastTransitionTime: time,
+			Reason:             "Success",
+			Message:            `"groupsClaim" is valid`,
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	sadGroupsClaimCondition := func(time metav1.Time, observedGeneration int64, message string) metav1.Condition {
+		return metav1.Condition{
+			Type:               "GroupsClaimValid",
+			Status:             "False",
+			LastTransitionTime: time,
+			Reason:             "InvalidPattern",
+			Message:            message,
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
 	tests := []struct {
 		name                     string
 		inputObjects             []runtime.Object
@@ -292,10 +337,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 					Status: configv1alpha1.OIDCClientStatus{
 						Phase: "Ready",
-						Conditions: []configv1alpha1.Condition{
+						Conditions: []metav1.Condition{
 							happyAllowedGrantTypesCondition(now, 1234),
 							happyAllowedScopesCondition(now, 1234),
 							happyClientSecretsCondition(1, now, 1234),
+							happyClientSecretsNotExpiringCondition(now, 1234),
+							happyGroupsClaimCondition(now, 1234),
 						},
 						TotalClientSecrets: 1,
 					},
@@ -317,15 +364,123 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(2, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 2,
 				},
 			}},
 		},
+		{
+			name: "client secrets which will expire soon cause a warning but the OIDCClient is still ready",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+				},
+			}},
+			inputSecrets: []runtime.Object{
+				testutil.OIDCClientSecretStorageSecretForUIDWithExpirations(t, testNamespace, testUID,
+					[]string{testutil.HashedPassword1AtSupervisorMinCost, testutil.HashedPassword2AtSupervisorMinCost},
+					map[string]time.Time{
+						testutil.HashedPassword1AtSupervisorMinCost: now.Add(24 * time.Hour),
+						testutil.HashedPassword2AtSupervisorMinCost: now.Add(30 * 24 * time.Hour),
+					}),
+			},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Ready",
+					Conditions: []metav1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientSecretsCondition(2, now, 1234),
+						sadClientSecretsExpiringCondition(now, 1234,
+							"1 client secret(s) will expire within 168h0m0s: "+oidcclientsecretstorage.SecretID(testutil.HashedPassword1AtSupervisorMinCost)),
+						happyGroupsClaimCondition(now, 1234),
+					},
+					TotalClientSecrets: 2,
+				},
+			}},
+		},
+		{
+			name: "expired client secrets are ignored",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+				},
+			}},
+			inputSecrets: []runtime.Object{
+				testutil.OIDCClientSecretStorageSecretForUIDWithExpirations(t, testNamespace, testUID,
+					[]string{testutil.HashedPassword1AtSupervisorMinCost, testutil.HashedPassword2AtSupervisorMinCost},
+					map[string]time.Time{
+						testutil.HashedPassword1AtSupervisorMinCost: earlier.Time,
+					}),
+			},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Ready",
+					Conditions: []metav1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						{
+							Type:               "ClientSecretExists",
+							Status:             "True",
+							LastTransitionTime: now,
+							Reason:             "Success",
+							Message:            "1 client secret(s) found (1 expired client secret(s) ignored)",
+							ObservedGeneration: 1234,
+						},
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "client secret storage exists but all of the client secrets have expired",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+				},
+			}},
+			inputSecrets: []runtime.Object{
+				testutil.OIDCClientSecretStorageSecretForUIDWithExpirations(t, testNamespace, testUID,
+					[]string{testutil.HashedPassword1AtSupervisorMinCost, testutil.HashedPassword2AtSupervisorMinCost},
+					map[string]time.Time{
+						testutil.HashedPassword1AtSupervisorMinCost: earlier.Time,
+						testutil.HashedPassword2AtSupervisorMinCost: earlier.Time,
+					}),
+			},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Error",
+					Conditions: []metav1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						sadNoClientSecretsCondition(now, 1234, "no client secret found (all 2 stored client secrets have expired)"),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
+					},
+					TotalClientSecrets: 0,
+				},
+			}},
+		},
 		{
 			name: "an already validated OIDCClient does not have its conditions updated when everything is still valid",
 			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
@@ -336,10 +491,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(earlier, 1234),
 						happyAllowedScopesCondition(earlier, 1234),
 						happyClientSecretsCondition(1, earlier, 1234),
+						happyClientSecretsNotExpiringCondition(earlier, 1234),
+						happyGroupsClaimCondition(earlier, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -350,10 +507,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(earlier, 1234),
 						happyAllowedScopesCondition(earlier, 1234),
 						happyClientSecretsCondition(1, earlier, 1234),
+						happyClientSecretsNotExpiringCondition(earlier, 1234),
+						happyGroupsClaimCondition(earlier, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -370,10 +529,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						sadAllowedGrantTypesCondition(now, 1234, `"authorization_code" must always be included in "allowedGrantTypes"`),
 						sadAllowedScopesCondition(now, 1234, `"openid" must always be included in "allowedScopes"`),
 						sadNoClientSecretsCondition(now, 1234, "no client secret found (no Secret storage found)"),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 				},
 			}},
@@ -393,10 +554,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						sadNoClientSecretsCondition(now, 1234, "error reading client secret storage: OIDC client secret storage data has wrong version: OIDC client secret storage has version wrong-version instead of 1"),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 				},
 			}},
@@ -416,10 +579,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						sadNoClientSecretsCondition(now, 1234, "no client secret found (empty list in storage)"),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 0,
 				},
@@ -443,13 +608,15 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						sadInvalidClientSecretsCondition(now, 1234,
 							"3 stored client secrets found, but some were invalid, so none will be used: "+
 								"hashed client secret at index 1: bcrypt cost 11 is below the required minimum of 12; "+
 								"hashed client secret at index 2: crypto/bcrypt: hashedSecret too short to be a bcrypted password"),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 0,
 				},
@@ -477,10 +644,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "client.oauth.pinniped.dev-test1", Generation: 1234, UID: "uid1"},
 					Status: configv1alpha1.OIDCClientStatus{
 						Phase: "Ready",
-						Conditions: []configv1alpha1.Condition{
+						Conditions: []metav1.Condition{
 							happyAllowedGrantTypesCondition(now, 1234),
 							happyAllowedScopesCondition(now, 1234),
 							happyClientSecretsCondition(1, now, 1234),
+							happyClientSecretsNotExpiringCondition(now, 1234),
+							happyGroupsClaimCondition(now, 1234),
 						},
 						TotalClientSecrets: 1,
 					},
@@ -489,10 +658,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "client.oauth.pinniped.dev-test2", Generation: 4567, UID: "uid2"},
 					Status: configv1alpha1.OIDCClientStatus{
 						Phase: "Error",
-						Conditions: []configv1alpha1.Condition{
+						Conditions: []metav1.Condition{
 							sadAllowedGrantTypesCondition(now, 4567, `"authorization_code" must always be included in "allowedGrantTypes"`),
 							sadAllowedScopesCondition(now, 4567, `"openid" must always be included in "allowedScopes"`),
 							sadNoClientSecretsCondition(now, 4567, "no client secret found (no Secret storage found)"),
+							happyClientSecretsNotExpiringCondition(now, 4567),
+							happyGroupsClaimCondition(now, 4567),
 						},
 						TotalClientSecrets: 0,
 					},
@@ -510,10 +681,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				// was invalid on previous run of controller which observed an old generation at an earlier time
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						sadAllowedGrantTypesCondition(earlier, 1234, `"authorization_code" must always be included in "allowedGrantTypes"`),
 						sadAllowedScopesCondition(earlier, 1234, `"openid" must always be included in "allowedScopes"`),
 						happyClientSecretsCondition(1, earlier, 1234),
+						happyClientSecretsNotExpiringCondition(earlier, 1234),
+						happyGroupsClaimCondition(earlier, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -525,10 +698,101 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				// status was updated to reflect the current generation at the current time
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 4567),
 						happyAllowedScopesCondition(now, 4567),
 						happyClientSecretsCondition(1, earlier, 4567), // was already validated earlier
+						happyClientSecretsNotExpiringCondition(earlier, 4567),
+						happyGroupsClaimCondition(earlier, 4567),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "groupsClaim allowedPatterns must be valid regular expressions",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+					GroupsClaim: &configv1alpha1.OIDCClientGroupsClaim{
+						AllowedPatterns: []string{"eng-.*", "(ops", "sales["},
+					},
+				},
+			}},
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantAPIActions: 1, // one update
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Error",
+					Conditions: []metav1.Condition{
+						happyAllowedGrantTypesCondition(now, 1234),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						sadGroupsClaimCondition(now, 1234,
+							"allowedPatterns[1] is not a valid regular expression: error parsing regexp: missing closing ): `(ops`; "+
+								"allowedPatterns[2] is not a valid regular expression: error parsing regexp: missing closing ]: `[`"),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "clientCredentials must be configured when client_credentials is included in allowedGrantTypes",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code", "client_credentials"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+				},
+			}},
+			wantAPIActions: 1, // one update
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Error",
+					Conditions: []metav1.Condition{
+						sadAllowedGrantTypesCondition(now, 1234, `"clientCredentials.allowedAudiences" must list at least one audience when "client_credentials" is included in "allowedGrantTypes"`),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
+					},
+					TotalClientSecrets: 1,
+				},
+			}},
+		},
+		{
+			name: "client_credentials must be included in allowedGrantTypes when clientCredentials is configured, and reserved audiences are not allowed",
+			inputObjects: []runtime.Object{&configv1alpha1.OIDCClient{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Spec: configv1alpha1.OIDCClientSpec{
+					AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+					AllowedScopes:     []configv1alpha1.Scope{"openid"},
+					ClientCredentials: &configv1alpha1.OIDCClientCredentials{
+						AllowedAudiences: []string{"some-gateway", "pinniped-cli", "foo.pinniped.dev"},
+					},
+				},
+			}},
+			wantAPIActions: 1, // one update
+			inputSecrets:   []runtime.Object{testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID, []string{testutil.HashedPassword1AtSupervisorMinCost})},
+			wantResultingOIDCClients: []configv1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+				Status: configv1alpha1.OIDCClientStatus{
+					Phase: "Error",
+					Conditions: []metav1.Condition{
+						sadAllowedGrantTypesCondition(now, 1234,
+							`"client_credentials" must be included in "allowedGrantTypes" when "clientCredentials" is configured; `+
+								`"clientCredentials.allowedAudiences" cannot contain the reserved audience "pinniped-cli"; `+
+								`"clientCredentials.allowedAudiences" cannot contain the reserved audience "foo.pinniped.dev"`),
+						happyAllowedScopesCondition(now, 1234),
+						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -549,10 +813,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						sadAllowedGrantTypesCondition(now, 1234, `"refresh_token" must be included in "allowedGrantTypes" when "offline_access" is included in "allowedScopes"`),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -573,7 +839,7 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						sadAllowedGrantTypesCondition(now, 1234,
 							`"authorization_code" must always be included in "allowedGrantTypes"; `+
 								`"urn:ietf:params:oauth:grant-type:token-exchange" must be included in "allowedGrantTypes" when "pinniped:request-audience" is included in "allowedScopes"`),
@@ -582,6 +848,8 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 								`"offline_access" must be included in "allowedScopes" when "refresh_token" is included in "allowedGrantTypes"; `+
 								`"username" and "groups" must be included in "allowedScopes" when "pinniped:request-audience" is included in "allowedScopes"`),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -602,7 +870,7 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						sadAllowedGrantTypesCondition(now, 1234,
 							`"authorization_code" must always be included in "allowedGrantTypes"; `+
 								`"refresh_token" must be included in "allowedGrantTypes" when "offline_access" is included in "allowedScopes"`),
@@ -610,6 +878,8 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 							`"openid" must always be included in "allowedScopes"; `+
 								`"pinniped:request-audience" must be included in "allowedScopes" when "urn:ietf:params:oauth:grant-type:token-exchange" is included in "allowedGrantTypes"`),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -630,10 +900,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						sadAllowedGrantTypesCondition(now, 1234, `"urn:ietf:params:oauth:grant-type:token-exchange" must be included in "allowedGrantTypes" when "pinniped:request-audience" is included in "allowedScopes"`),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -654,10 +926,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"offline_access" must be included in "allowedScopes" when "refresh_token" is included in "allowedGrantTypes"`),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -678,10 +952,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"username" and "groups" must be included in "allowedScopes" when "pinniped:request-audience" is included in "allowedScopes"`),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -702,10 +978,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"username" and "groups" must be included in "allowedScopes" when "pinniped:request-audience" is included in "allowedScopes"`),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -726,10 +1004,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"username" and "groups" must be included in "allowedScopes" when "pinniped:request-audience" is included in "allowedScopes"`),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -750,10 +1030,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Error",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						sadAllowedScopesCondition(now, 1234, `"pinniped:request-audience" must be included in "allowedScopes" when "urn:ietf:params:oauth:grant-type:token-exchange" is included in "allowedGrantTypes"`),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -774,10 +1056,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -798,10 +1082,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -822,10 +1108,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -846,10 +1134,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -870,10 +1160,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -894,10 +1186,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -918,10 +1212,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -942,10 +1238,12 @@ func TestOIDCClientWatcherControllerSync(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
 				Status: configv1alpha1.OIDCClientStatus{
 					Phase: "Ready",
-					Conditions: []configv1alpha1.Condition{
+					Conditions: []metav1.Condition{
 						happyAllowedGrantTypesCondition(now, 1234),
 						happyAllowedScopesCondition(now, 1234),
 						happyClientSecretsCondition(1, now, 1234),
+						happyClientSecretsNotExpiringCondition(now, 1234),
+						happyGroupsClaimCondition(now, 1234),
 					},
 					TotalClientSecrets: 1,
 				},
@@ -52,6 +52,7 @@ const (
 
 	// Constants related to the OIDC provider discovery cache. These do not affect the cache of JWKS.
 	oidcValidatorCacheTTL = 15 * time.Minute
+	oidcKeySetCacheTTL    = 24 * time.Hour
 
 	// Constants related to conditions.
 	typeClientCredentialsValid             = "ClientCredentialsValid" //nolint:gosec // this is not a credential
@@ -95,7 +96,10 @@ type UpstreamOIDCIdentityProviderICache interface {
 }
 
 // lruValidatorCache caches the *oidc.Provider associated with a particular issuer/TLS configuration.
-type lruValidatorCache struct{ cache *cache.Expiring }
+type lruValidatorCache struct {
+	cache   *cache.Expiring
+	keySets *cache.Expiring
+}
 
 type lruValidatorCacheEntry struct {
 	provider *coreosoidc.Provider
@@ -114,6 +118,23 @@ func (c *lruValidatorCache) putProvider(spec *v1alpha1.OIDCIdentityProviderSpec,
 	c.cache.Set(c.cacheKey(spec), &lruValidatorCacheEntry{provider: provider, client: client}, oidcValidatorCacheTTL)
 }
 
+// getKeySet returns the KeySet for the JWKS URL of a provider with the given issuer/TLS configuration. KeySets are
+// cached for longer than providers, so that the keys of a provider do not need to be fetched again when it is
+// discovered again.
+func (c *lruValidatorCache) getKeySet(spec *v1alpha1.OIDCIdentityProviderSpec, jwksURL string, client *http.Client) *upstreamoidc.KeySet {
+	key := struct {
+		provider interface{}
+		jwksURL  string
+	}{provider: c.cacheKey(spec), jwksURL: jwksURL}
+
+	keySet, ok := c.keySets.Get(key)
+	if !ok {
+		keySet = upstreamoidc.NewKeySet(client, jwksURL)
+	}
+	c.keySets.Set(key, keySet, oidcKeySetCacheTTL)
+	return keySet.(*upstreamoidc.KeySet)
+}
+
 func (c *lruValidatorCache) cacheKey(spec *v1alpha1.OIDCIdentityProviderSpec) interface{} {
 	var key struct{ issuer, caBundle string }
 	key.issuer = spec.Issuer
@@ -132,6 +153,7 @@ type oidcWatcherController struct {
 	validatorCache               interface {
 		getProvider(*v1alpha1.OIDCIdentityProviderSpec) (*coreosoidc.Provider, *http.Client)
 		putProvider(*v1alpha1.OIDCIdentityProviderSpec, *coreosoidc.Provider, *http.Client)
+		getKeySet(spec *v1alpha1.OIDCIdentityProviderSpec, jwksURL string, client *http.Client) *upstreamoidc.KeySet
 	}
 }
 
@@ -150,7 +172,7 @@ func New(
 		client:                       client,
 		oidcIdentityProviderInformer: oidcIdentityProviderInformer,
 		secretInformer:               secretInformer,
-		validatorCache:               &lruValidatorCache{cache: cache.NewExpiring()},
+		validatorCache:               &lruValidatorCache{cache: cache.NewExpiring(), keySets: cache.NewExpiring()},
 	}
 	return controllerlib.New(
 		controllerlib.Config{Name: oidcControllerName, Syncer: &c},
@@ -219,33 +241,33 @@ func (c *oidcWatcherController) validateUpstream(ctx controllerlib.Context, upst
 		ResourceUID:              upstream.UID,
 	}
 
-	conditions := []*v1alpha1.Condition{
+	conditions := []*metav1.Condition{
 		c.validateSecret(upstream, &result),
 		c.validateIssuer(ctx.Context, upstream, &result),
 	}
 	if len(rejectedAuthcodeAuthorizeParameters) > 0 {
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:   typeAdditionalAuthorizeParametersValid,
-			Status: v1alpha1.ConditionFalse,
+			Status: metav1.ConditionFalse,
 			Reason: reasonDisallowedParameterName,
 			Message: fmt.Sprintf("the following additionalAuthorizeParameters are not allowed: %s",
 				strings.Join(rejectedAuthcodeAuthorizeParameters, ",")),
 		})
 	} else {
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:    typeAdditionalAuthorizeParametersValid,
-			Status:  v1alpha1.ConditionTrue,
+			Status:  metav1.ConditionTrue,
 			Reason:  upstreamwatchers.ReasonSuccess,
 			Message: allParamNamesAllowedMsg,
 		})
 	}
 
-	c.updateStatus(ctx.Context, upstream, conditions)
+	c.updateStatus(ctx, upstream, conditions)
 
 	valid := true
 	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
 	for _, condition := range conditions {
-		if condition.Status == v1alpha1.ConditionFalse {
+		if condition.Status == metav1.ConditionFalse {
 			valid = false
 			log.WithValues(
 				"type", condition.Type,
@@ -261,15 +283,15 @@ func (c *oidcWatcherController) validateUpstream(ctx controllerlib.Context, upst
 }
 
 // validateSecret validates the .spec.client.secretName field and returns the appropriate ClientCredentialsValid condition.
-func (c *oidcWatcherController) validateSecret(upstream *v1alpha1.OIDCIdentityProvider, result *upstreamoidc.ProviderConfig) *v1alpha1.Condition {
+func (c *oidcWatcherController) validateSecret(upstream *v1alpha1.OIDCIdentityProvider, result *upstreamoidc.ProviderConfig) *metav1.Condition {
 	secretName := upstream.Spec.Client.SecretName
 
 	// Fetch the Secret from informer cache.
 	secret, err := c.secretInformer.Lister().Secrets(upstream.Namespace).Get(secretName)
 	if err != nil {
-		return &v1alpha1.Condition{
+		return &metav1.Condition{
 			Type:    typeClientCredentialsValid,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  upstreamwatchers.ReasonNotFound,
 			Message: err.Error(),
 		}
@@ -277,9 +299,9 @@ func (c *oidcWatcherController) validateSecret(upstream *v1alpha1.OIDCIdentityPr
 
 	// Validate the secret .type field.
 	if secret.Type != oidcClientSecretType {
-		return &v1alpha1.Condition{
+		return &metav1.Condition{
 			Type:    typeClientCredentialsValid,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  upstreamwatchers.ReasonWrongType,
 			Message: fmt.Sprintf("referenced Secret %q has wrong type %q (should be %q)", secretName, secret.Type, oidcClientSecretType),
 		}
@@ -289,9 +311,9 @@ func (c *oidcWatcherController) validateSecret(upstream *v1alpha1.OIDCIdentityPr
 	clientID := secret.Data[clientIDDataKey]
 	clientSecret := secret.Data[clientSecretDataKey]
 	if len(clientID) == 0 || len(clientSecret) == 0 {
-		return &v1alpha1.Condition{
+		return &metav1.Condition{
 			Type:    typeClientCredentialsValid,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  upstreamwatchers.ReasonMissingKeys,
 			Message: fmt.Sprintf("referenced Secret %q is missing required keys %q", secretName, []string{clientIDDataKey, clientSecretDataKey}),
 		}
@@ -300,16 +322,16 @@ func (c *oidcWatcherController) validateSecret(upstream *v1alpha1.OIDCIdentityPr
 	// If everything is valid, update the result and set the condition to true.
 	result.Config.ClientID = string(clientID)
 	result.Config.ClientSecret = string(clientSecret)
-	return &v1alpha1.Condition{
+	return &metav1.Condition{
 		Type:    typeClientCredentialsValid,
-		Status:  v1alpha1.ConditionTrue,
+		Status:  metav1.ConditionTrue,
 		Reason:  upstreamwatchers.ReasonSuccess,
 		Message: "loaded client credentials",
 	}
 }
 
 // validateIssuer validates the .spec.issuer field, performs OIDC discovery, and returns the appropriate OIDCDiscoverySucceeded condition.
-func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1alpha1.OIDCIdentityProvider, result *upstreamoidc.ProviderConfig) *v1alpha1.Condition {
+func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1alpha1.OIDCIdentityProvider, result *upstreamoidc.ProviderConfig) *metav1.Condition {
 	// Get the provider and HTTP Client from cache if possible.
 	discoveredProvider, httpClient := c.validatorCache.getProvider(&upstream.Spec)
 
@@ -318,9 +340,9 @@ func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1
 		var err error
 		httpClient, err = getClient(upstream)
 		if err != nil {
-			return &v1alpha1.Condition{
+			return &metav1.Condition{
 				Type:    typeOIDCDiscoverySucceeded,
-				Status:  v1alpha1.ConditionFalse,
+				Status:  metav1.ConditionFalse,
 				Reason:  upstreamwatchers.ReasonInvalidTLSConfig,
 				Message: err.Error(),
 			}
@@ -338,9 +360,9 @@ func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1
 				"name", upstream.Name,
 				"issuer", upstream.Spec.Issuer,
 			).Error(err, "failed to perform OIDC discovery")
-			return &v1alpha1.Condition{
+			return &metav1.Condition{
 				Type:    typeOIDCDiscoverySucceeded,
-				Status:  v1alpha1.ConditionFalse,
+				Status:  metav1.ConditionFalse,
 				Reason:  reasonUnreachable,
 				Message: fmt.Sprintf("failed to perform OIDC discovery against %q:\n%s", upstream.Spec.Issuer, truncateMostLongErr(err)),
 			}
@@ -354,12 +376,13 @@ func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1
 	var additionalDiscoveryClaims struct {
 		// "revocation_endpoint" is specified by https://datatracker.ietf.org/doc/html/rfc8414#section-2
 		RevocationEndpoint string `json:"revocation_endpoint"`
+		JWKSURL            string `json:"jwks_uri"`
 	}
 	if err := discoveredProvider.Claims(&additionalDiscoveryClaims); err != nil {
 		// This shouldn't actually happen because the above call to NewProvider() would have already returned this error.
-		return &v1alpha1.Condition{
+		return &metav1.Condition{
 			Type:    typeOIDCDiscoverySucceeded,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  reasonInvalidResponse,
 			Message: fmt.Sprintf("failed to unmarshal OIDC discovery response from %q:\n%s", upstream.Spec.Issuer, truncateMostLongErr(err)),
 		}
@@ -396,23 +419,38 @@ func (c *oidcWatcherController) validateIssuer(ctx context.Context, upstream *v1
 		return tokenURLCondition
 	}
 
+	// Validate ID tokens with keys which are refreshed in the background, instead of fetching the keys during logins.
+	keySetProvider, err := upstreamoidc.NewKeySetProvider(
+		discoveredProvider,
+		c.validatorCache.getKeySet(&upstream.Spec, additionalDiscoveryClaims.JWKSURL, httpClient),
+	)
+	if err != nil {
+		// This shouldn't actually happen because the above call to Claims() would have already returned this error.
+		return &metav1.Condition{
+			Type:    typeOIDCDiscoverySucceeded,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonInvalidResponse,
+			Message: fmt.Sprintf("failed to unmarshal OIDC discovery response from %q:\n%s", upstream.Spec.Issuer, truncateMostLongErr(err)),
+		}
+	}
+
 	// If everything is valid, update the result and set the condition to true.
 	result.Config.Endpoint = discoveredProvider.Endpoint()
-	result.Provider = discoveredProvider
+	result.Provider = keySetProvider
 	result.Client = httpClient
-	return &v1alpha1.Condition{
+	return &metav1.Condition{
 		Type:    typeOIDCDiscoverySucceeded,
-		Status:  v1alpha1.ConditionTrue,
+		Status:  metav1.ConditionTrue,
 		Reason:  upstreamwatchers.ReasonSuccess,
 		Message: "discovered issuer configuration",
 	}
 }
 
-func (c *oidcWatcherController) updateStatus(ctx context.Context, upstream *v1alpha1.OIDCIdentityProvider, conditions []*v1alpha1.Condition) {
+func (c *oidcWatcherController) updateStatus(ctx controllerlib.Context, upstream *v1alpha1.OIDCIdentityProvider, conditions []*metav1.Condition) {
 	log := c.log.WithValues("namespace", upstream.Namespace, "name", upstream.Name)
 	updated := upstream.DeepCopy()
 
-	hadErrorCondition := conditionsutil.MergeIDPConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
+	hadErrorCondition := conditionsutil.MergeConditions(conditions, upstream.Generation, &updated.Status.Conditions, log)
 
 	updated.Status.Phase = v1alpha1.PhaseReady
 	if hadErrorCondition {
@@ -426,10 +464,15 @@ func (c *oidcWatcherController) updateStatus(ctx context.Context, upstream *v1al
 	_, err := c.client.
 		IDPV1alpha1().
 		OIDCIdentityProviders(upstream.Namespace).
-		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+		UpdateStatus(ctx.Context, updated, metav1.UpdateOptions{})
 	if err != nil {
 		log.Error(err, "failed to update status")
+		return
 	}
+
+	conditionsutil.RecordConditionFailures(ctx.Recorder,
+		conditionsutil.ObjectReference(upstream, v1alpha1.SchemeGroupVersion.WithKind("OIDCIdentityProvider")),
+		upstream.Status.Conditions, updated.Status.Conditions)
 }
 
 func getClient(upstream *v1alpha1.OIDCIdentityProvider) (*http.Client, error) {
@@ -485,28 +528,28 @@ func truncateMostLongErr(err error) string {
 	return msg[:max] + fmt.Sprintf(" [truncated %d chars]", len(msg)-max)
 }
 
-func validateHTTPSURL(maybeHTTPSURL, endpointType, reason string) (*url.URL, *v1alpha1.Condition) {
+func validateHTTPSURL(maybeHTTPSURL, endpointType, reason string) (*url.URL, *metav1.Condition) {
 	parsedURL, err := url.Parse(maybeHTTPSURL)
 	if err != nil {
-		return nil, &v1alpha1.Condition{
+		return nil, &metav1.Condition{
 			Type:    typeOIDCDiscoverySucceeded,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  reason,
 			Message: fmt.Sprintf("failed to parse %s URL: %v", endpointType, truncateMostLongErr(err)),
 		}
 	}
 	if parsedURL.Scheme != "https" {
-		return nil, &v1alpha1.Condition{
+		return nil, &metav1.Condition{
 			Type:    typeOIDCDiscoverySucceeded,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  reason,
 			Message: fmt.Sprintf(`%s URL '%s' must have "https" scheme, not %q`, endpointType, maybeHTTPSURL, parsedURL.Scheme),
 		}
 	}
 	if len(parsedURL.Query()) != 0 || parsedURL.Fragment != "" {
-		return nil, &v1alpha1.Condition{
+		return nil, &metav1.Condition{
 			Type:    typeOIDCDiscoverySucceeded,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  reason,
 			Message: fmt.Sprintf(`%s URL '%s' cannot contain query or fragment component`, endpointType, maybeHTTPSURL),
 		}
@@ -0,0 +1,138 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	pinnipedclientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned"
+	configinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/config/v1alpha1"
+	pinnipedcontroller "go.pinniped.dev/internal/controller"
+	"go.pinniped.dev/internal/controller/conditionsutil"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	typeReady = "Ready"
+
+	reasonNotApplied = "NotApplied"
+)
+
+// SupervisorConfigApplier applies the settings of a SupervisorConfig to the running Supervisor.
+type SupervisorConfigApplier interface {
+	// ApplySupervisorConfig overrides the static configuration with the settings of the SupervisorConfig, or goes
+	// back to the static configuration when it is nil. When it returns an error, the previous settings are kept.
+	ApplySupervisorConfig(spec *configv1alpha1.SupervisorConfigSpec) error
+}
+
+type supervisorConfigWatcherController struct {
+	name                     string
+	applier                  SupervisorConfigApplier
+	client                   pinnipedclientset.Interface
+	supervisorConfigInformer configinformers.SupervisorConfigInformer
+}
+
+// NewSupervisorConfigWatcherController creates a controllerlib.Controller that watches the SupervisorConfig with the
+// given name, applies its settings to the running Supervisor, and updates its Ready condition. When the
+// SupervisorConfig does not exist, the static configuration of the Supervisor is applied.
+func NewSupervisorConfigWatcherController(
+	name string,
+	applier SupervisorConfigApplier,
+	client pinnipedclientset.Interface,
+	supervisorConfigInformer configinformers.SupervisorConfigInformer,
+	withInformer pinnipedcontroller.WithInformerOptionFunc,
+) controllerlib.Controller {
+	return controllerlib.New(
+		controllerlib.Config{
+			Name: "supervisor-config-watcher-controller",
+			Syncer: &supervisorConfigWatcherController{
+				name:                     name,
+				applier:                  applier,
+				client:                   client,
+				supervisorConfigInformer: supervisorConfigInformer,
+			},
+		},
+		withInformer(
+			supervisorConfigInformer,
+			pinnipedcontroller.NameAndNamespaceExactMatchFilterFactory(name, ""),
+			controllerlib.InformerOption{},
+		),
+	)
+}
+
+// Sync implements controllerlib.Syncer.
+func (c *supervisorConfigWatcherController) Sync(ctx controllerlib.Context) error {
+	supervisorConfig, err := c.supervisorConfigInformer.Lister().Get(c.name)
+	if k8serrors.IsNotFound(err) {
+		if err := c.applier.ApplySupervisorConfig(nil); err != nil {
+			return fmt.Errorf("could not apply the static configuration: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s supervisorconfig: %w", c.name, err)
+	}
+
+	applyErr := c.applier.ApplySupervisorConfig(&supervisorConfig.Spec)
+
+	condition := &metav1.Condition{
+		Type:    typeReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonSuccess,
+		Message: "the settings of the SupervisorConfig were applied",
+	}
+	if applyErr != nil {
+		condition = &metav1.Condition{
+			Type:    typeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonNotApplied,
+			Message: fmt.Sprintf("the settings of the SupervisorConfig were not applied, the previous settings are still used: %s", applyErr.Error()),
+		}
+	}
+
+	if err := c.updateStatus(ctx, supervisorConfig, condition); err != nil {
+		return fmt.Errorf("could not update status of SupervisorConfig %s: %w", supervisorConfig.Name, err)
+	}
+
+	if applyErr != nil {
+		// Retry, since some errors are temporary, e.g. when a port is still used by a process which is exiting.
+		return fmt.Errorf("could not apply SupervisorConfig %s: %w", supervisorConfig.Name, applyErr)
+	}
+	return nil
+}
+
+func (c *supervisorConfigWatcherController) updateStatus(
+	ctx controllerlib.Context,
+	supervisorConfig *configv1alpha1.SupervisorConfig,
+	condition *metav1.Condition,
+) error {
+	updated := supervisorConfig.DeepCopy()
+
+	_ = conditionsutil.MergeConditions(
+		[]*metav1.Condition{condition},
+		supervisorConfig.Generation,
+		&updated.Status.Conditions,
+		plog.WithValues("name", supervisorConfig.Name),
+	)
+
+	if equality.Semantic.DeepEqual(supervisorConfig, updated) {
+		return nil
+	}
+
+	_, err := c.client.ConfigV1alpha1().SupervisorConfigs().UpdateStatus(ctx.Context, updated, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	conditionsutil.RecordConditionFailures(ctx.Recorder,
+		conditionsutil.ObjectReference(supervisorConfig, configv1alpha1.SchemeGroupVersion.WithKind("SupervisorConfig")),
+		supervisorConfig.Status.Conditions, updated.Status.Conditions)
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisorconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	pinnipedfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
+	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestSupervisorConfigWatcherControllerFilter(t *testing.T) {
+	t.Parallel()
+
+	pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(pinnipedfake.NewSimpleClientset(), 0)
+	supervisorConfigInformer := pinnipedInformers.Config().V1alpha1().SupervisorConfigs()
+	observableWithInformerOption := testutil.NewObservableWithInformerOption()
+
+	_ = NewSupervisorConfigWatcherController(
+		"some-name",
+		&fakeSupervisorConfigApplier{},
+		nil,
+		supervisorConfigInformer,
+		observableWithInformerOption.WithInformer,
+	)
+
+	filter := observableWithInformerOption.GetFilterForInformer(supervisorConfigInformer)
+	matching := &configv1alpha1.SupervisorConfig{ObjectMeta: metav1.ObjectMeta{Name: "some-name"}}
+	other := &configv1alpha1.SupervisorConfig{ObjectMeta: metav1.ObjectMeta{Name: "other-name"}}
+
+	require.True(t, filter.Add(matching))
+	require.True(t, filter.Update(other, matching))
+	require.True(t, filter.Delete(matching))
+	require.False(t, filter.Add(other))
+	require.False(t, filter.Update(other, other))
+	require.False(t, filter.Delete(other))
+}
+
+func TestSupervisorConfigWatcherControllerSync(t *testing.T) {
+	t.Parallel()
+
+	const name = "some-name"
+
+	spec := configv1alpha1.SupervisorConfigSpec{
+		Log: &configv1alpha1.SupervisorConfigLogSpec{Level: "debug"},
+	}
+	supervisorConfig := func(conditions ...metav1.Condition) *configv1alpha1.SupervisorConfig {
+		return &configv1alpha1.SupervisorConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Generation: 42},
+			Spec:       spec,
+			Status:     configv1alpha1.SupervisorConfigStatus{Conditions: conditions},
+		}
+	}
+	condition := func(status metav1.ConditionStatus, reason, message string) metav1.Condition {
+		return metav1.Condition{
+			Type:               "Ready",
+			Status:             status,
+			ObservedGeneration: 42,
+			Reason:             reason,
+			Message:            message,
+		}
+	}
+	readyCondition := condition(metav1.ConditionTrue, "Success", "the settings of the SupervisorConfig were applied")
+
+	tests := []struct {
+		name             string
+		supervisorConfig *configv1alpha1.SupervisorConfig
+		applyErr         error
+		wantApplied      []*configv1alpha1.SupervisorConfigSpec
+		wantCondition    *metav1.Condition
+		wantUpdate       bool
+		wantErr          string
+	}{
+		{
+			name:        "the SupervisorConfig does not exist",
+			wantApplied: []*configv1alpha1.SupervisorConfigSpec{nil},
+		},
+		{
+			name:             "the settings are applied",
+			supervisorConfig: supervisorConfig(),
+			wantApplied:      []*configv1alpha1.SupervisorConfigSpec{&spec},
+			wantCondition:    &readyCondition,
+			wantUpdate:       true,
+		},
+		{
+			name:             "the settings are applied and the status is already up to date",
+			supervisorConfig: supervisorConfig(readyCondition),
+			wantApplied:      []*configv1alpha1.SupervisorConfigSpec{&spec},
+			wantCondition:    &readyCondition,
+		},
+		{
+			name:             "the settings cannot be applied",
+			supervisorConfig: supervisorConfig(readyCondition),
+			applyErr:         errors.New("some apply error"),
+			wantApplied:      []*configv1alpha1.SupervisorConfigSpec{&spec},
+			wantCondition: func() *metav1.Condition {
+				c := condition(metav1.ConditionFalse, "NotApplied",
+					"the settings of the SupervisorConfig were not applied, the previous settings are still used: some apply error")
+				return &c
+			}(),
+			wantUpdate: true,
+			wantErr:    "could not apply SupervisorConfig some-name: some apply error",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var objects []runtime.Object
+			if tt.supervisorConfig != nil {
+				objects = append(objects, tt.supervisorConfig)
+			}
+			pinnipedAPIClient := pinnipedfake.NewSimpleClientset(objects...)
+			pinnipedInformers := pinnipedinformers.NewSharedInformerFactory(pinnipedfake.NewSimpleClientset(objects...), 0)
+			applier := &fakeSupervisorConfigApplier{err: tt.applyErr}
+
+			subject := NewSupervisorConfigWatcherController(
+				name,
+				applier,
+				pinnipedAPIClient,
+				pinnipedInformers.Config().V1alpha1().SupervisorConfigs(),
+				controllerlib.WithInformer,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pinnipedInformers.Start(ctx.Done())
+			controllerlib.TestRunSynchronously(t, subject)
+
+			err := controllerlib.TestSync(t, subject, controllerlib.Context{Context: ctx, Name: subject.Name()})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tt.wantApplied, applier.applied)
+
+			var updated bool
+			for _, action := range pinnipedAPIClient.Actions() {
+				if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+					updated = true
+				}
+			}
+			require.Equal(t, tt.wantUpdate, updated)
+
+			if tt.wantCondition == nil {
+				return
+			}
+			actual, err := pinnipedAPIClient.ConfigV1alpha1().SupervisorConfigs().Get(ctx, name, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Len(t, actual.Status.Conditions, 1)
+			actual.Status.Conditions[0].LastTransitionTime = metav1.Time{}
+			require.Equal(t, *tt.wantCondition, actual.Status.Conditions[0])
+		})
+	}
+}
+
+type fakeSupervisorConfigApplier struct {
+	err     error
+	applied []*configv1alpha1.SupervisorConfigSpec
+}
+
+func (f *fakeSupervisorConfigApplier) ApplySupervisorConfig(spec *configv1alpha1.SupervisorConfigSpec) error {
+	f.applied = append(f.applied, spec)
+	return f.err
+}
@@ -1,10 +1,11 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/url"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions/config/v1alpha1"
 	pinnipedcontroller "go.pinniped.dev/internal/controller"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/dynamiccert"
 	"go.pinniped.dev/internal/plog"
 )
 
@@ -24,6 +26,9 @@ type tlsCertObserverController struct {
 	defaultTLSCertificateSecretName string
 	federationDomainInformer        v1alpha1.FederationDomainInformer
 	secretInformer                  corev1informers.SecretInformer
+
+	// expiryNames are the names under which the expiries of the certs were recorded by the previous sync.
+	expiryNames map[string]struct{}
 }
 
 type IssuerTLSCertSetter interface {
@@ -71,6 +76,8 @@ func (c *tlsCertObserverController) Sync(ctx controllerlib.Context) error {
 	// Rebuild the whole map on any change to any Secret or FederationDomain, because either can have changes that
 	// can cause the map to need to be updated.
 	issuerHostToTLSCertMap := map[string]*tls.Certificate{}
+	secretNameToTLSCertMap := map[string]*tls.Certificate{}
+	previousIssuerHostToTLSCertMap := map[string]*tls.Certificate{}
 
 	for _, provider := range allProviders {
 		secretName := ""
@@ -86,23 +93,66 @@ func (c *tlsCertObserverController) Sync(ctx controllerlib.Context) error {
 		if err != nil {
 			continue
 		}
+		secretNameToTLSCertMap[secretName] = certFromSecret
 		// Lowercase the host part of the URL because hostnames should be treated as case-insensitive.
-		issuerHostToTLSCertMap[lowercaseHostWithoutPort(issuerURL)] = certFromSecret
+		// The alias hosts of the FederationDomain use the same certificate.
+		for _, u := range issuerURLsForAllHosts(issuerURL, provider.Spec.AliasHosts) {
+			issuerHostToTLSCertMap[lowercaseHostWithoutPort(u)] = certFromSecret
+		}
+		for _, u := range withPreviousIssuerURL(nil, provider.Spec.IssuerMigration) {
+			previousIssuerHostToTLSCertMap[lowercaseHostWithoutPort(u)] = certFromSecret
+		}
+	}
+
+	// The host of the previous issuer of an issuer migration uses the same certificate too, unless the host is also
+	// used by the issuer of a FederationDomain, which may happen after the grace period of the migration has ended.
+	for host, cert := range previousIssuerHostToTLSCertMap {
+		if _, ok := issuerHostToTLSCertMap[host]; !ok {
+			issuerHostToTLSCertMap[host] = cert
+		}
 	}
 
 	plog.Debug("tlsCertObserverController Sync updated the TLS cert cache", "issuerHostCount", len(issuerHostToTLSCertMap))
 	c.issuerTLSCertSetter.SetIssuerHostToTLSCertMap(issuerHostToTLSCertMap)
 
-	defaultCert, err := c.certFromSecret(ns, c.defaultTLSCertificateSecretName)
-	if err != nil {
-		c.issuerTLSCertSetter.SetDefaultTLSCert(nil)
-	} else {
-		c.issuerTLSCertSetter.SetDefaultTLSCert(defaultCert)
+	// the default cert is not loaded from a Secret when it is loaded from files instead
+	if c.defaultTLSCertificateSecretName != "" {
+		defaultCert, err := c.certFromSecret(ns, c.defaultTLSCertificateSecretName)
+		if err != nil {
+			c.issuerTLSCertSetter.SetDefaultTLSCert(nil)
+		} else {
+			c.issuerTLSCertSetter.SetDefaultTLSCert(defaultCert)
+			secretNameToTLSCertMap[c.defaultTLSCertificateSecretName] = defaultCert
+		}
 	}
 
+	c.recordExpiries(secretNameToTLSCertMap)
+
 	return nil
 }
 
+// recordExpiries exports the expiry of each cert which is currently served, and stops exporting the expiry of
+// the certs which are no longer served.
+func (c *tlsCertObserverController) recordExpiries(secretNameToTLSCertMap map[string]*tls.Certificate) {
+	expiryNames := map[string]struct{}{}
+	for secretName, cert := range secretNameToTLSCertMap {
+		x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil { // should not happen because tls.X509KeyPair already parsed it
+			continue
+		}
+		name := "supervisor-tls-cert/" + secretName
+		dynamiccert.RecordExpiry(name, x509Cert)
+		expiryNames[name] = struct{}{}
+	}
+
+	for name := range c.expiryNames {
+		if _, ok := expiryNames[name]; !ok {
+			dynamiccert.ForgetExpiry(name)
+		}
+	}
+	c.expiryNames = expiryNames
+}
+
 func (c *tlsCertObserverController) certFromSecret(ns string, secretName string) (*tls.Certificate, error) {
 	tlsSecret, err := c.secretInformer.Lister().Secrets(ns).Get(secretName)
 	if err != nil {
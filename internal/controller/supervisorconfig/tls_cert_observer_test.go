@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorconfig
@@ -139,6 +139,7 @@ func TestTLSCertObserverControllerSync(t *testing.T) {
 			cancelContextCancelFunc context.CancelFunc
 			syncContext             *controllerlib.Context
 			issuerTLSCertSetter     *fakeIssuerTLSCertSetter
+			defaultSecretName       string
 		)
 
 		// Defer starting the informers until the last possible moment so that the
@@ -147,7 +148,7 @@ func TestTLSCertObserverControllerSync(t *testing.T) {
 			// Set this at the last second to allow for injection of server override.
 			subject = NewTLSCertObserverController(
 				issuerTLSCertSetter,
-				defaultTLSSecretName,
+				defaultSecretName,
 				kubeInformers.Core().V1().Secrets(),
 				pinnipedInformers.Config().V1alpha1().FederationDomains(),
 				controllerlib.WithInformer,
@@ -185,6 +186,7 @@ func TestTLSCertObserverControllerSync(t *testing.T) {
 			pinnipedInformerClient = pinnipedfake.NewSimpleClientset()
 			pinnipedInformers = pinnipedinformers.NewSharedInformerFactory(pinnipedInformerClient, 0)
 			issuerTLSCertSetter = &fakeIssuerTLSCertSetter{}
+			defaultSecretName = defaultTLSSecretName
 
 			unrelatedSecret := &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
@@ -212,6 +214,21 @@ func TestTLSCertObserverControllerSync(t *testing.T) {
 			})
 		})
 
+		when("there is no default TLS cert secret name because the default TLS cert is loaded from files", func() {
+			it.Before(func() {
+				defaultSecretName = ""
+			})
+
+			it("does not change the issuerTLSCertSetter's default certificate", func() {
+				startInformersAndController()
+				err := controllerlib.TestSync(t, subject, *syncContext)
+				r.NoError(err)
+
+				r.True(issuerTLSCertSetter.setIssuerHostToTLSCertMapWasCalled)
+				r.False(issuerTLSCertSetter.setDefaultTLSCertWasCalled)
+			})
+		})
+
 		when("there are FederationDomains where some have corresponding TLS Secrets and some don't", func() {
 			var (
 				expectedCertificate1, expectedCertificate2 tls.Certificate
@@ -263,9 +280,13 @@ func TestTLSCertObserverControllerSync(t *testing.T) {
 						Namespace: installedInNamespace,
 					},
 					// Issuer hostname should be treated in a case-insensitive way and SNI ignores port numbers. Test without a port number.
+					// The host of the previous issuer should use the same cert.
 					Spec: v1alpha1.FederationDomainSpec{
 						Issuer: "https://www.iSSuer-wiTh-goOd-secRet1.cOm/path",
-						TLS:    &v1alpha1.FederationDomainTLSSpec{SecretName: "good-tls-secret-name1"},
+						IssuerMigration: &v1alpha1.FederationDomainIssuerMigration{
+							PreviousIssuer: "https://www.PREVIOUS-issuer-with-good-secret1.com/path",
+						},
+						TLS: &v1alpha1.FederationDomainTLSSpec{SecretName: "good-tls-secret-name1"},
 					},
 				}
 				federationDomainWithGoodSecret2 := &v1alpha1.FederationDomain{
@@ -274,9 +295,15 @@ func TestTLSCertObserverControllerSync(t *testing.T) {
 						Namespace: installedInNamespace,
 					},
 					// Issuer hostname should be treated in a case-insensitive way and SNI ignores port numbers. Test with a port number.
+					// Alias hosts should use the same cert. Previous issuers should not use the same cert when their host is
+					// used by the issuer of another FederationDomain.
 					Spec: v1alpha1.FederationDomainSpec{
-						Issuer: "https://www.issUEr-WIth-gOOd-seCret2.com:1234/path",
-						TLS:    &v1alpha1.FederationDomainTLSSpec{SecretName: "good-tls-secret-name2"},
+						Issuer:     "https://www.issUEr-WIth-gOOd-seCret2.com:1234/path",
+						AliasHosts: []string{"www.ALIAS-with-good-secret2.com:8443"},
+						IssuerMigration: &v1alpha1.FederationDomainIssuerMigration{
+							PreviousIssuer: "https://www.issuer-with-good-secret1.com/old-path",
+						},
+						TLS: &v1alpha1.FederationDomainTLSSpec{SecretName: "good-tls-secret-name2"},
 					},
 				}
 				federationDomainWithIPv6Issuer := &v1alpha1.FederationDomain{
@@ -334,7 +361,7 @@ func TestTLSCertObserverControllerSync(t *testing.T) {
 				r.Nil(issuerTLSCertSetter.setDefaultTLSCertReceived)
 
 				r.True(issuerTLSCertSetter.setIssuerHostToTLSCertMapWasCalled)
-				r.Len(issuerTLSCertSetter.issuerHostToTLSCertMapReceived, 3)
+				r.Len(issuerTLSCertSetter.issuerHostToTLSCertMapReceived, 5)
 
 				// They keys in the map should be lower case and should not include the port numbers, because
 				// TLS SNI says that SNI hostnames must be DNS names (not ports) and must be case insensitive.
@@ -346,6 +373,12 @@ func TestTLSCertObserverControllerSync(t *testing.T) {
 				actualCertificate2 := issuerTLSCertSetter.issuerHostToTLSCertMapReceived["www.issuer-with-good-secret2.com"]
 				r.NotNil(actualCertificate2)
 				r.Equal(expectedCertificate2, *actualCertificate2)
+				actualAliasCertificate2 := issuerTLSCertSetter.issuerHostToTLSCertMapReceived["www.alias-with-good-secret2.com"]
+				r.NotNil(actualAliasCertificate2)
+				r.Equal(expectedCertificate2, *actualAliasCertificate2)
+				actualPreviousIssuerCertificate1 := issuerTLSCertSetter.issuerHostToTLSCertMapReceived["www.previous-issuer-with-good-secret1.com"]
+				r.NotNil(actualPreviousIssuerCertificate1)
+				r.Equal(expectedCertificate1, *actualPreviousIssuerCertificate1)
 
 				actualCertificate3 := issuerTLSCertSetter.issuerHostToTLSCertMapReceived["2001:db8::1"]
 				r.NotNil(actualCertificate3)
@@ -382,7 +415,7 @@ func TestTLSCertObserverControllerSync(t *testing.T) {
 					r.Equal(expectedDefaultCertificate, *actualDefaultCertificate)
 
 					r.True(issuerTLSCertSetter.setIssuerHostToTLSCertMapWasCalled)
-					r.Len(issuerTLSCertSetter.issuerHostToTLSCertMapReceived, 3)
+					r.Len(issuerTLSCertSetter.issuerHostToTLSCertMapReceived, 5)
 				})
 			})
 		})
@@ -0,0 +1,49 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamwatchers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousBindCredentials(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	cache := &ValidatedSettingsCache{
+		ValidatedSettingsByName: map[string]ValidatedSettings{},
+		clock:                   func() time.Time { return now },
+	}
+
+	requirePreviousBindCredentials := func(resourceVersion, username, password, wantUsername, wantPassword string) {
+		t.Helper()
+		gotUsername, gotPassword := cache.PreviousBindCredentials("some-upstream", resourceVersion, username, password)
+		require.Equal(t, wantUsername, gotUsername)
+		require.Equal(t, wantPassword, gotPassword)
+	}
+
+	// There are no previous credentials when the bind secret is seen for the first time.
+	requirePreviousBindCredentials("1", "some-username", "some-password", "", "")
+	requirePreviousBindCredentials("1", "some-username", "some-password", "", "")
+
+	// After the bind secret is changed, the previous credentials are returned during the grace period.
+	now = now.Add(time.Hour)
+	requirePreviousBindCredentials("2", "some-username", "some-new-password", "some-username", "some-password")
+	now = now.Add(BindSecretRotationGracePeriod)
+	requirePreviousBindCredentials("2", "some-username", "some-new-password", "some-username", "some-password")
+
+	// But not after the grace period.
+	now = now.Add(time.Second)
+	requirePreviousBindCredentials("2", "some-username", "some-new-password", "", "")
+
+	// Changes to the bind secret which do not change the credentials do not start another grace period.
+	requirePreviousBindCredentials("3", "some-username", "some-new-password", "", "")
+
+	// The credentials of other upstreams are tracked separately.
+	otherUsername, otherPassword := cache.PreviousBindCredentials("some-other-upstream", "4", "some-other-username", "some-other-password")
+	require.Empty(t, otherUsername)
+	require.Empty(t, otherPassword)
+	requirePreviousBindCredentials("5", "some-new-username", "some-newer-password", "some-username", "some-new-password")
+}
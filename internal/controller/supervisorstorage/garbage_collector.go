@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorstorage
@@ -7,6 +7,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -14,6 +16,9 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/utils/clock"
 	clocktesting "k8s.io/utils/clock/testing"
 
@@ -26,18 +31,73 @@ import (
 	"go.pinniped.dev/internal/fositestorage/openidconnect"
 	"go.pinniped.dev/internal/fositestorage/pkce"
 	"go.pinniped.dev/internal/fositestorage/refreshtoken"
+	"go.pinniped.dev/internal/oidc/lockout"
+	"go.pinniped.dev/internal/oidc/login"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/pmetrics"
 	"go.pinniped.dev/internal/psession"
 )
 
-const minimumRepeatInterval = 30 * time.Second
+const defaultMinimumRepeatInterval = 30 * time.Second
+
+var (
+	deletedSecretsMetric = metrics.NewCounter(&metrics.CounterOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemSupervisorStorageGarbageCollector,
+		Name:           "deleted_secrets_total",
+		Help:           "Number of expired Secrets deleted by the storage garbage collector.",
+		StabilityLevel: metrics.ALPHA,
+	})
+	pendingExpiredSecretsMetric = metrics.NewGauge(&metrics.GaugeOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemSupervisorStorageGarbageCollector,
+		Name:           "pending_expired_secrets",
+		Help:           "Number of expired Secrets which still existed at the end of the most recent storage garbage collection sweep.",
+		StabilityLevel: metrics.ALPHA,
+	})
+	sweepDurationMetric = metrics.NewHistogram(&metrics.HistogramOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemSupervisorStorageGarbageCollector,
+		Name:           "sweep_duration_seconds",
+		Help:           "Latency of storage garbage collection sweeps in seconds.",
+		Buckets:        metrics.ExponentialBuckets(0.01, 4, 8),
+		StabilityLevel: metrics.ALPHA,
+	})
+	activeSessionsMetric = metrics.NewGauge(&metrics.GaugeOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemSupervisorStorageGarbageCollector,
+		Name:           "active_sessions",
+		Help:           "Number of unexpired downstream sessions, counted by their refresh token storage Secrets, at the time of the most recent storage garbage collection sweep.",
+		StabilityLevel: metrics.ALPHA,
+	})
+
+	registerMetricsOnce sync.Once
+)
+
+// GarbageCollectorConfig tunes the garbage collector. The zero value gives the default behavior.
+type GarbageCollectorConfig struct {
+	// MinimumRepeatInterval is the minimum amount of time between sweeps. Defaults to 30 seconds.
+	MinimumRepeatInterval time.Duration
+	// BatchSize is the maximum number of expired Secrets to delete per sweep. When there are more, the rest are
+	// deleted by the following sweeps. Zero means no limit.
+	BatchSize int
+	// DeletesPerSecond limits the rate of delete calls to the Kubernetes API. Zero means no limit.
+	DeletesPerSecond float32
+	// SessionEncrypter decrypts the sessions when they are encrypted, so that their upstream tokens can be revoked.
+	// Nil means that the sessions are not encrypted.
+	SessionEncrypter crud.Encrypter
+}
 
 type garbageCollectorController struct {
 	idpCache              UpstreamOIDCIdentityProviderICache
 	secretInformer        corev1informers.SecretInformer
 	kubeClient            kubernetes.Interface
 	clock                 clock.Clock
+	minimumRepeatInterval time.Duration
+	batchSize             int
+	deleteRateLimiter     flowcontrol.RateLimiter // nil means that deletes are not rate limited
+	sessionEncrypter      crud.Encrypter          // nil means that the sessions are not encrypted
 	timeOfMostRecentSweep time.Time
 }
 
@@ -51,8 +111,24 @@ func GarbageCollectorController(
 	clock clock.Clock,
 	kubeClient kubernetes.Interface,
 	secretInformer corev1informers.SecretInformer,
+	config GarbageCollectorConfig,
 	withInformer pinnipedcontroller.WithInformerOptionFunc,
 ) controllerlib.Controller {
+	registerMetricsOnce.Do(func() {
+		pmetrics.ApplyLatencyBuckets(sweepDurationMetric.HistogramOpts)
+		legacyregistry.MustRegister(deletedSecretsMetric, pendingExpiredSecretsMetric, sweepDurationMetric, activeSessionsMetric)
+	})
+
+	minimumRepeatInterval := config.MinimumRepeatInterval
+	if minimumRepeatInterval == 0 {
+		minimumRepeatInterval = defaultMinimumRepeatInterval
+	}
+
+	var deleteRateLimiter flowcontrol.RateLimiter
+	if config.DeletesPerSecond > 0 {
+		deleteRateLimiter = flowcontrol.NewTokenBucketRateLimiterWithClock(config.DeletesPerSecond, 1, clock)
+	}
+
 	isSecretWithGCAnnotation := func(obj metav1.Object) bool {
 		secret, ok := obj.(*v1.Secret)
 		if !ok {
@@ -65,10 +141,14 @@ func GarbageCollectorController(
 		controllerlib.Config{
 			Name: "garbage-collector-controller",
 			Syncer: &garbageCollectorController{
-				idpCache:       idpCache,
-				secretInformer: secretInformer,
-				kubeClient:     kubeClient,
-				clock:          clock,
+				idpCache:              idpCache,
+				secretInformer:        secretInformer,
+				kubeClient:            kubeClient,
+				clock:                 clock,
+				minimumRepeatInterval: minimumRepeatInterval,
+				batchSize:             config.BatchSize,
+				deleteRateLimiter:     deleteRateLimiter,
+				sessionEncrypter:      config.SessionEncrypter,
 			},
 		},
 		withInformer(
@@ -94,19 +174,22 @@ func (c *garbageCollectorController) Sync(ctx controllerlib.Context) error {
 	// controller too chatty, so it rate limits itself to a more reasonable interval.
 	// Note that even during a period when no secrets are changing, it will still run
 	// at the informer's full-resync interval (as long as there are some secrets).
-	if since := frozenClock.Since(c.timeOfMostRecentSweep); since < minimumRepeatInterval {
-		ctx.Queue.AddAfter(ctx.Key, minimumRepeatInterval-since)
+	if since := frozenClock.Since(c.timeOfMostRecentSweep); since < c.minimumRepeatInterval {
+		ctx.Queue.AddAfter(ctx.Key, c.minimumRepeatInterval-since)
 		return nil
 	}
 
 	plog.Info("starting storage garbage collection sweep")
 	c.timeOfMostRecentSweep = frozenClock.Now()
+	defer func() { sweepDurationMetric.Observe(c.clock.Since(frozenClock.Now()).Seconds()) }()
 
 	listOfSecrets, err := c.secretInformer.Lister().List(labels.Everything())
 	if err != nil {
 		return err
 	}
 
+	var expiredSecrets []expiredSecret
+	activeSessions := 0
 	for i := range listOfSecrets {
 		secret := listOfSecrets[i]
 
@@ -125,9 +208,34 @@ func (c *garbageCollectorController) Sync(ctx controllerlib.Context) error {
 
 		if !garbageCollectAfterTime.Before(frozenClock.Now()) {
 			// Secret is not old enough yet, so skip deletion.
+			if secret.Labels[crud.SecretLabelKey] == refreshtoken.TypeLabelValue && !c.isRotatedRefreshToken(secret) {
+				activeSessions++
+			}
 			continue
 		}
 
+		expiredSecrets = append(expiredSecrets, expiredSecret{secret: secret, garbageCollectAfterTime: garbageCollectAfterTime})
+	}
+
+	// When the batch size limits how many Secrets are deleted by this sweep, delete the oldest ones first.
+	sort.SliceStable(expiredSecrets, func(i, j int) bool {
+		return expiredSecrets[i].garbageCollectAfterTime.Before(expiredSecrets[j].garbageCollectAfterTime)
+	})
+
+	pending := 0
+	deleteAttempts := 0
+	for i, expired := range expiredSecrets {
+		if c.batchSize > 0 && deleteAttempts >= c.batchSize {
+			pending += len(expiredSecrets) - i
+			plog.Info("storage garbage collector reached its batch size, so the remaining expired resources will be deleted by a later sweep",
+				"batchSize", c.batchSize, "remaining", len(expiredSecrets)-i)
+			// Make sure that there is another sweep soon, even if no Secrets are changed in the meantime.
+			ctx.Queue.AddAfter(ctx.Key, c.minimumRepeatInterval)
+			break
+		}
+
+		secret := expired.secret
+
 		// The Secret has expired. Check if it is a downstream session storage Secret, which may require extra processing.
 		storageType, isSessionStorage := secret.Labels[crud.SecretLabelKey]
 		if isSessionStorage {
@@ -142,15 +250,21 @@ func (c *garbageCollectorController) Sync(ctx controllerlib.Context) error {
 				// session Secrets by too much time, since the garbage collector is the only thing that is
 				// cleaning them out of etcd storage.
 				fourHoursAgo := frozenClock.Now().Add(-4 * time.Hour)
-				nowIsLessThanFourHoursBeyondSecretGCTime := garbageCollectAfterTime.After(fourHoursAgo)
+				nowIsLessThanFourHoursBeyondSecretGCTime := expired.garbageCollectAfterTime.After(fourHoursAgo)
 				if errors.As(revokeErr, &provider.RetryableRevocationError{}) && nowIsLessThanFourHoursBeyondSecretGCTime {
 					// Hasn't been very long since secret expired, so skip deletion to try revocation again later.
 					plog.Trace("garbage collector keeping Secret to retry upstream OIDC token revocation later", logKV(secret)...)
+					pending++
 					continue
 				}
 			}
 		}
 
+		if c.deleteRateLimiter != nil {
+			c.deleteRateLimiter.Accept()
+		}
+		deleteAttempts++
+
 		// Garbage collect the Secret.
 		err = c.kubeClient.CoreV1().Secrets(secret.Namespace).Delete(ctx.Context, secret.Name, metav1.DeleteOptions{
 			Preconditions: &metav1.Preconditions{
@@ -160,14 +274,24 @@ func (c *garbageCollectorController) Sync(ctx controllerlib.Context) error {
 		})
 		if err != nil {
 			plog.WarningErr("failed to garbage collect resource", err, logKV(secret)...)
+			pending++
 			continue
 		}
+		deletedSecretsMetric.Inc()
 		plog.Info("storage garbage collector deleted resource", logKV(secret)...)
 	}
 
+	pendingExpiredSecretsMetric.Set(float64(pending))
+	activeSessionsMetric.Set(float64(activeSessions))
+
 	return nil
 }
 
+type expiredSecret struct {
+	secret                  *v1.Secret
+	garbageCollectAfterTime time.Time
+}
+
 func (c *garbageCollectorController) maybeRevokeUpstreamOIDCToken(ctx context.Context, storageType string, secret *v1.Secret) error {
 	// All downstream session storage types hold upstream tokens when the upstream IDP is an OIDC provider.
 	// However, some of them will be outdated because they are not updated by fosite after creation.
@@ -181,7 +305,7 @@ func (c *garbageCollectorController) maybeRevokeUpstreamOIDCToken(ctx context.Co
 	// upstream access token more than once.
 	switch storageType {
 	case authorizationcode.TypeLabelValue:
-		authorizeCodeSession, err := authorizationcode.ReadFromSecret(secret)
+		authorizeCodeSession, err := authorizationcode.ReadFromSecret(secret, c.sessionEncrypter)
 		if err != nil {
 			return err
 		}
@@ -198,7 +322,7 @@ func (c *garbageCollectorController) maybeRevokeUpstreamOIDCToken(ctx context.Co
 		// If it was granted, then the latest upstream token should be found in the refresh token storage instead.
 		// If it was not granted, then the user could not possibly have performed a downstream refresh, so the
 		// access token storage has the latest version of the upstream token.
-		accessTokenSession, err := accesstoken.ReadFromSecret(secret)
+		accessTokenSession, err := accesstoken.ReadFromSecret(secret, c.sessionEncrypter)
 		if err != nil {
 			return err
 		}
@@ -209,13 +333,18 @@ func (c *garbageCollectorController) maybeRevokeUpstreamOIDCToken(ctx context.Co
 		return c.tryRevokeUpstreamOIDCToken(ctx, pinnipedSession.Custom, secret)
 
 	case refreshtoken.TypeLabelValue:
-		// For refresh token storage, always revoke its upstream token. This refresh token storage could be
-		// the result of the initial downstream authcode exchange, or it could be the result of a downstream
-		// refresh. Either way, it always contains the latest upstream token when it exists.
-		refreshTokenSession, err := refreshtoken.ReadFromSecret(secret)
+		// For refresh token storage, revoke its upstream token unless the downstream refresh token was already
+		// rotated. This refresh token storage could be the result of the initial downstream authcode exchange, or
+		// it could be the result of a downstream refresh. Either way, when it was not rotated it contains the
+		// latest upstream token when it exists. A rotated one is only kept to detect replays, and its upstream
+		// token may still be in use by the newer refresh token storage of the same session.
+		refreshTokenSession, err := refreshtoken.ReadFromSecret(secret, c.sessionEncrypter)
 		if err != nil {
 			return err
 		}
+		if refreshTokenSession.Rotated {
+			return nil
+		}
 		return c.tryRevokeUpstreamOIDCToken(ctx, refreshTokenSession.Request.Session.(*psession.PinnipedSession).Custom, secret)
 
 	case pkce.TypeLabelValue:
@@ -231,6 +360,14 @@ func (c *garbageCollectorController) maybeRevokeUpstreamOIDCToken(ctx context.Co
 		// be revoked by one of the other cases above.
 		return nil
 
+	case login.WebAuthnLoginTypeLabelValue, login.TOTPLoginTypeLabelValue:
+		// Logins which are waiting for the WebAuthn or TOTP second factor do not hold any upstream tokens.
+		return nil
+
+	case lockout.TypeLabelValue:
+		// The failed password logins of a username do not hold any upstream tokens.
+		return nil
+
 	default:
 		// There are no other storage types, so this should never happen in practice.
 		return errors.New("garbage collector saw invalid label on Secret when trying to determine if upstream revocation was needed")
@@ -287,3 +424,10 @@ func logKV(secret *v1.Secret) []interface{} {
 		"storageTypeLabelValue", secret.Labels[crud.SecretLabelKey],
 	}
 }
+
+// isRotatedRefreshToken returns true when the Secret is the storage of a downstream refresh token which was already
+// exchanged for a new one. These are only kept to detect replays, so they do not count as active sessions.
+func (c *garbageCollectorController) isRotatedRefreshToken(secret *v1.Secret) bool {
+	refreshTokenSession, err := refreshtoken.ReadFromSecret(secret, c.sessionEncrypter)
+	return err == nil && refreshTokenSession.Rotated
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package supervisorstorage
@@ -16,11 +16,15 @@ import (
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	kubeinformers "k8s.io/client-go/informers"
 	kubernetesfake "k8s.io/client-go/kubernetes/fake"
 	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/component-base/metrics/legacyregistry"
+	metricstestutil "k8s.io/component-base/metrics/testutil"
 	"k8s.io/utils/clock"
 	clocktesting "k8s.io/utils/clock/testing"
 
@@ -52,6 +56,7 @@ func TestGarbageCollectorControllerInformerFilters(t *testing.T) {
 				clock.RealClock{},
 				nil,
 				secretsInformer,
+				GarbageCollectorConfig{},
 				observableWithInformerOption.WithInformer, // make it possible to observe the behavior of the Filters
 			)
 			secretsInformerFilter = observableWithInformerOption.GetFilterForInformer(secretsInformer)
@@ -133,6 +138,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 			syncContext             *controllerlib.Context
 			fakeClock               *clocktesting.FakeClock
 			frozenNow               time.Time
+			gcConfig                GarbageCollectorConfig
 		)
 
 		// Defer starting the informers until the last possible moment so that the
@@ -144,6 +150,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 				fakeClock,
 				kubeClient,
 				kubeInformers.Core().V1().Secrets(),
+				gcConfig,
 				controllerlib.WithInformer,
 			)
 
@@ -173,6 +180,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 			kubeInformers = kubeinformers.NewSharedInformerFactory(kubeInformerClient, 0)
 			frozenNow = time.Now().UTC()
 			fakeClock = clocktesting.NewFakeClock(frozenNow)
+			gcConfig = GarbageCollectorConfig{}
 
 			unrelatedSecret := &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
@@ -302,7 +310,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + authorizationcode.TypeLabelValue,
 				}
-				_, err = authorizationcode.ReadFromSecret(activeOIDCAuthcodeSessionSecret)
+				_, err = authorizationcode.ReadFromSecret(activeOIDCAuthcodeSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid authcode secret")
 				r.NoError(kubeInformerClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
@@ -347,7 +355,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + authorizationcode.TypeLabelValue,
 				}
-				_, err = authorizationcode.ReadFromSecret(inactiveOIDCAuthcodeSessionSecret)
+				_, err = authorizationcode.ReadFromSecret(inactiveOIDCAuthcodeSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid authcode secret")
 				r.NoError(kubeInformerClient.Tracker().Add(inactiveOIDCAuthcodeSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(inactiveOIDCAuthcodeSessionSecret))
@@ -426,7 +434,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + authorizationcode.TypeLabelValue,
 				}
-				_, err = authorizationcode.ReadFromSecret(activeOIDCAuthcodeSessionSecret)
+				_, err = authorizationcode.ReadFromSecret(activeOIDCAuthcodeSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid authcode secret")
 				r.NoError(kubeInformerClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
@@ -471,7 +479,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + authorizationcode.TypeLabelValue,
 				}
-				_, err = authorizationcode.ReadFromSecret(inactiveOIDCAuthcodeSessionSecret)
+				_, err = authorizationcode.ReadFromSecret(inactiveOIDCAuthcodeSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid authcode secret")
 				r.NoError(kubeInformerClient.Tracker().Add(inactiveOIDCAuthcodeSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(inactiveOIDCAuthcodeSessionSecret))
@@ -619,7 +627,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + authorizationcode.TypeLabelValue,
 				}
-				_, err = authorizationcode.ReadFromSecret(wrongProviderNameOIDCAuthcodeSessionSecret)
+				_, err = authorizationcode.ReadFromSecret(wrongProviderNameOIDCAuthcodeSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid authcode secret")
 				r.NoError(kubeInformerClient.Tracker().Add(wrongProviderNameOIDCAuthcodeSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(wrongProviderNameOIDCAuthcodeSessionSecret))
@@ -690,7 +698,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + authorizationcode.TypeLabelValue,
 				}
-				_, err = authorizationcode.ReadFromSecret(wrongProviderNameOIDCAuthcodeSessionSecret)
+				_, err = authorizationcode.ReadFromSecret(wrongProviderNameOIDCAuthcodeSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid authcode secret")
 				r.NoError(kubeInformerClient.Tracker().Add(wrongProviderNameOIDCAuthcodeSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(wrongProviderNameOIDCAuthcodeSessionSecret))
@@ -762,7 +770,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + authorizationcode.TypeLabelValue,
 				}
-				_, err = authorizationcode.ReadFromSecret(activeOIDCAuthcodeSessionSecret)
+				_, err = authorizationcode.ReadFromSecret(activeOIDCAuthcodeSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid authcode secret")
 				r.NoError(kubeInformerClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
@@ -867,7 +875,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + authorizationcode.TypeLabelValue,
 				}
-				_, err = authorizationcode.ReadFromSecret(activeOIDCAuthcodeSessionSecret)
+				_, err = authorizationcode.ReadFromSecret(activeOIDCAuthcodeSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid authcode secret")
 				r.NoError(kubeInformerClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(activeOIDCAuthcodeSessionSecret))
@@ -945,7 +953,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + accesstoken.TypeLabelValue,
 				}
-				_, err = accesstoken.ReadFromSecret(offlineAccessGrantedOIDCAccessTokenSessionSecret)
+				_, err = accesstoken.ReadFromSecret(offlineAccessGrantedOIDCAccessTokenSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid accesstoken secret")
 				r.NoError(kubeInformerClient.Tracker().Add(offlineAccessGrantedOIDCAccessTokenSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(offlineAccessGrantedOIDCAccessTokenSessionSecret))
@@ -990,7 +998,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + accesstoken.TypeLabelValue,
 				}
-				_, err = accesstoken.ReadFromSecret(offlineAccessNotGrantedOIDCAccessTokenSessionSecret)
+				_, err = accesstoken.ReadFromSecret(offlineAccessNotGrantedOIDCAccessTokenSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid accesstoken secret")
 				r.NoError(kubeInformerClient.Tracker().Add(offlineAccessNotGrantedOIDCAccessTokenSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(offlineAccessNotGrantedOIDCAccessTokenSessionSecret))
@@ -1069,7 +1077,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + accesstoken.TypeLabelValue,
 				}
-				_, err = accesstoken.ReadFromSecret(offlineAccessGrantedOIDCAccessTokenSessionSecret)
+				_, err = accesstoken.ReadFromSecret(offlineAccessGrantedOIDCAccessTokenSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid accesstoken secret")
 				r.NoError(kubeInformerClient.Tracker().Add(offlineAccessGrantedOIDCAccessTokenSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(offlineAccessGrantedOIDCAccessTokenSessionSecret))
@@ -1114,7 +1122,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + accesstoken.TypeLabelValue,
 				}
-				_, err = accesstoken.ReadFromSecret(offlineAccessNotGrantedOIDCAccessTokenSessionSecret)
+				_, err = accesstoken.ReadFromSecret(offlineAccessNotGrantedOIDCAccessTokenSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid accesstoken secret")
 				r.NoError(kubeInformerClient.Tracker().Add(offlineAccessNotGrantedOIDCAccessTokenSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(offlineAccessNotGrantedOIDCAccessTokenSessionSecret))
@@ -1192,7 +1200,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + refreshtoken.TypeLabelValue,
 				}
-				_, err = refreshtoken.ReadFromSecret(oidcRefreshSessionSecret)
+				_, err = refreshtoken.ReadFromSecret(oidcRefreshSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid refresh token secret")
 				r.NoError(kubeInformerClient.Tracker().Add(oidcRefreshSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(oidcRefreshSessionSecret))
@@ -1228,6 +1236,76 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 			})
 		})
 
+		when("there are valid, expired refresh secrets which were already rotated", func() {
+			it.Before(func() {
+				rotatedRefreshSession := &refreshtoken.Session{
+					Version: "4",
+					Rotated: true,
+					Request: &fosite.Request{
+						ID:     "request-id-1",
+						Client: &clientregistry.Client{},
+						Session: &psession.PinnipedSession{
+							Custom: &psession.CustomSessionData{
+								Username:     "should be ignored by garbage collector",
+								ProviderUID:  "upstream-oidc-provider-uid",
+								ProviderName: "upstream-oidc-provider-name",
+								ProviderType: psession.ProviderTypeOIDC,
+								OIDC: &psession.OIDCSessionData{
+									UpstreamRefreshToken: "fake-upstream-refresh-token",
+								},
+							},
+						},
+					},
+				}
+				rotatedRefreshSessionJSON, err := json.Marshal(rotatedRefreshSession)
+				r.NoError(err)
+				rotatedRefreshSessionSecret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "rotatedRefreshSession",
+						Namespace:       installedInNamespace,
+						UID:             "uid-123",
+						ResourceVersion: "rv-123",
+						Annotations: map[string]string{
+							"storage.pinniped.dev/garbage-collect-after": frozenNow.Add(-time.Second).Format(time.RFC3339),
+						},
+						Labels: map[string]string{
+							"storage.pinniped.dev/type": refreshtoken.TypeLabelValue,
+						},
+					},
+					Data: map[string][]byte{
+						"pinniped-storage-data":    rotatedRefreshSessionJSON,
+						"pinniped-storage-version": []byte("1"),
+					},
+					Type: "storage.pinniped.dev/" + refreshtoken.TypeLabelValue,
+				}
+				_, err = refreshtoken.ReadFromSecret(rotatedRefreshSessionSecret, nil)
+				r.NoError(err, "the test author accidentally formed an invalid refresh token secret")
+				r.NoError(kubeInformerClient.Tracker().Add(rotatedRefreshSessionSecret))
+				r.NoError(kubeClient.Tracker().Add(rotatedRefreshSessionSecret))
+			})
+
+			it("should delete the secrets without revoking their upstream tokens, which may still be used by a newer refresh token", func() {
+				happyOIDCUpstream := oidctestutil.NewTestUpstreamOIDCIdentityProviderBuilder().
+					WithName("upstream-oidc-provider-name").
+					WithResourceUID("upstream-oidc-provider-uid").
+					WithRevokeTokenError(nil)
+				idpListerBuilder := oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyOIDCUpstream.Build())
+
+				startInformersAndController(idpListerBuilder.Build())
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				idpListerBuilder.RequireExactlyZeroCallsToRevokeToken(t)
+
+				// The secret is deleted.
+				r.ElementsMatch(
+					[]kubetesting.Action{
+						kubetesting.NewDeleteActionWithOptions(secretsGVR, installedInNamespace, "rotatedRefreshSession", testutil.NewPreconditions("uid-123", "rv-123")),
+					},
+					kubeClient.Actions(),
+				)
+			})
+		})
+
 		when("there are valid, expired refresh secrets which contain upstream access tokens", func() {
 			it.Before(func() {
 				oidcRefreshSession := &refreshtoken.Session{
@@ -1269,7 +1347,7 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 					},
 					Type: "storage.pinniped.dev/" + refreshtoken.TypeLabelValue,
 				}
-				_, err = refreshtoken.ReadFromSecret(oidcRefreshSessionSecret)
+				_, err = refreshtoken.ReadFromSecret(oidcRefreshSessionSecret, nil)
 				r.NoError(err, "the test author accidentally formed an invalid refresh token secret")
 				r.NoError(kubeInformerClient.Tracker().Add(oidcRefreshSessionSecret))
 				r.NoError(kubeClient.Tracker().Add(oidcRefreshSessionSecret))
@@ -1460,9 +1538,209 @@ func TestGarbageCollectorControllerSync(t *testing.T) {
 				r.ElementsMatch([]string{"erroring secret", "some other unrelated secret"}, []string{list.Items[0].Name, list.Items[1].Name})
 			})
 		})
+
+		when("there are more expired secrets than the configured batch size", func() {
+			it.Before(func() {
+				gcConfig = GarbageCollectorConfig{MinimumRepeatInterval: 10 * time.Second, BatchSize: 2}
+
+				for i, name := range []string{"newest expired secret", "oldest expired secret", "middle expired secret"} {
+					expiredAgo := time.Duration(i+1) * time.Minute
+					if name == "newest expired secret" {
+						expiredAgo = time.Second
+					}
+					secret := &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:            name,
+							Namespace:       installedInNamespace,
+							UID:             types.UID("uid-" + name),
+							ResourceVersion: "rv-" + name,
+							Annotations: map[string]string{
+								"storage.pinniped.dev/garbage-collect-after": frozenNow.Add(-expiredAgo).Format(time.RFC3339),
+							},
+						},
+					}
+					r.NoError(kubeInformerClient.Tracker().Add(secret))
+					r.NoError(kubeClient.Tracker().Add(secret))
+				}
+			})
+
+			it("deletes the oldest ones first and requeues itself to delete the rest during a later sweep", func() {
+				startInformersAndController(nil)
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				r.Equal(
+					[]kubetesting.Action{
+						kubetesting.NewDeleteActionWithOptions(secretsGVR, installedInNamespace, "middle expired secret", testutil.NewPreconditions("uid-middle expired secret", "rv-middle expired secret")),
+						kubetesting.NewDeleteActionWithOptions(secretsGVR, installedInNamespace, "oldest expired secret", testutil.NewPreconditions("uid-oldest expired secret", "rv-oldest expired secret")),
+					},
+					kubeClient.Actions(),
+				)
+				r.True(syncContext.Queue.(*testQueue).called)
+				r.Equal(10*time.Second, syncContext.Queue.(*testQueue).duration)
+
+				syncContext.Queue = &testQueue{t: t} // reset the queue for the next sync
+				kubeClient.ClearActions()
+
+				// The informer cache still has the deleted secrets because they were only deleted using the other client.
+				r.NoError(kubeInformerClient.Tracker().Delete(secretsGVR, installedInNamespace, "middle expired secret"))
+				r.NoError(kubeInformerClient.Tracker().Delete(secretsGVR, installedInNamespace, "oldest expired secret"))
+				r.Eventually(func() bool {
+					list, err := kubeInformers.Core().V1().Secrets().Lister().List(labels.Everything())
+					return err == nil && len(list) == 2
+				}, 10*time.Second, 10*time.Millisecond)
+
+				fakeClock.Step(10 * time.Second)
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				r.Equal(
+					[]kubetesting.Action{
+						kubetesting.NewDeleteActionWithOptions(secretsGVR, installedInNamespace, "newest expired secret", testutil.NewPreconditions("uid-newest expired secret", "rv-newest expired secret")),
+					},
+					kubeClient.Actions(),
+				)
+				r.False(syncContext.Queue.(*testQueue).called)
+			})
+		})
+
+		when("deletes are rate limited", func() {
+			it.Before(func() {
+				gcConfig = GarbageCollectorConfig{DeletesPerSecond: 2}
+
+				for _, name := range []string{"expired secret 1", "expired secret 2", "expired secret 3"} {
+					secret := &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      name,
+							Namespace: installedInNamespace,
+							Annotations: map[string]string{
+								"storage.pinniped.dev/garbage-collect-after": frozenNow.Add(-time.Second).Format(time.RFC3339),
+							},
+						},
+					}
+					r.NoError(kubeInformerClient.Tracker().Add(secret))
+					r.NoError(kubeClient.Tracker().Add(secret))
+				}
+			})
+
+			it("waits between delete calls", func() {
+				startInformersAndController(nil)
+				r.NoError(controllerlib.TestSync(t, subject, *syncContext))
+
+				r.Len(kubeClient.Actions(), 3)
+				// The first delete is allowed immediately, and then each of the others waits half a second.
+				r.Equal(frozenNow.Add(time.Second), fakeClock.Now())
+			})
+		})
 	}, spec.Parallel(), spec.Report(report.Terminal{}))
 }
 
+// This test is not run in parallel with the others because the metrics are global.
+func TestGarbageCollectorControllerMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	frozenNow := time.Now().UTC()
+	kubeInformerClient := kubernetesfake.NewSimpleClientset()
+	kubeClient := kubernetesfake.NewSimpleClientset()
+	kubeInformers := kubeinformers.NewSharedInformerFactory(kubeInformerClient, 0)
+
+	for _, name := range []string{"expired secret", "erroring secret"} {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "some-namespace",
+				Annotations: map[string]string{
+					"storage.pinniped.dev/garbage-collect-after": frozenNow.Add(-time.Second).Format(time.RFC3339),
+				},
+			},
+		}
+		require.NoError(t, kubeInformerClient.Tracker().Add(secret))
+		require.NoError(t, kubeClient.Tracker().Add(secret))
+	}
+	for name, storageType := range map[string]string{"active refresh token": "refresh-token", "active access token": "access-token"} {
+		require.NoError(t, kubeInformerClient.Tracker().Add(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "some-namespace",
+				Labels:    map[string]string{"storage.pinniped.dev/type": storageType},
+				Annotations: map[string]string{
+					"storage.pinniped.dev/garbage-collect-after": frozenNow.Add(time.Hour).Format(time.RFC3339),
+				},
+			},
+		}))
+	}
+	// A refresh token which was already rotated does not count as an active session.
+	rotatedRefreshSessionJSON, err := json.Marshal(&refreshtoken.Session{
+		Version: "4",
+		Rotated: true,
+		Request: &fosite.Request{
+			ID:      "request-id-1",
+			Client:  &clientregistry.Client{},
+			Session: &psession.PinnipedSession{Custom: &psession.CustomSessionData{}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, kubeInformerClient.Tracker().Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rotated refresh token",
+			Namespace: "some-namespace",
+			Labels:    map[string]string{"storage.pinniped.dev/type": "refresh-token"},
+			Annotations: map[string]string{
+				"storage.pinniped.dev/garbage-collect-after": frozenNow.Add(time.Hour).Format(time.RFC3339),
+			},
+		},
+		Data: map[string][]byte{
+			"pinniped-storage-data":    rotatedRefreshSessionJSON,
+			"pinniped-storage-version": []byte("1"),
+		},
+		Type: "storage.pinniped.dev/refresh-token",
+	}))
+	kubeClient.PrependReactor("delete", "secrets", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		if action.(kubetesting.DeleteActionImpl).Name == "erroring secret" {
+			return true, nil, errors.New("delete failed: some delete error")
+		}
+		return false, nil, nil
+	})
+
+	subject := GarbageCollectorController(
+		nil,
+		clocktesting.NewFakeClock(frozenNow),
+		kubeClient,
+		kubeInformers.Core().V1().Secrets(),
+		GarbageCollectorConfig{},
+		controllerlib.WithInformer,
+	)
+	kubeInformers.Start(ctx.Done())
+	controllerlib.TestRunSynchronously(t, subject)
+
+	deletedBefore, err := metricstestutil.GetCounterMetricValue(deletedSecretsMetric)
+	require.NoError(t, err)
+	sweepsBefore := getSweepCount(t)
+
+	require.NoError(t, controllerlib.TestSync(t, subject, controllerlib.Context{Context: ctx, Name: subject.Name(), Queue: &testQueue{t: t}}))
+
+	deletedAfter, err := metricstestutil.GetCounterMetricValue(deletedSecretsMetric)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), deletedAfter-deletedBefore)
+
+	pending, err := metricstestutil.GetGaugeMetricValue(pendingExpiredSecretsMetric)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), pending)
+
+	activeSessions, err := metricstestutil.GetGaugeMetricValue(activeSessionsMetric)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), activeSessions)
+
+	require.Equal(t, uint64(1), getSweepCount(t)-sweepsBefore)
+}
+
+func getSweepCount(t *testing.T) uint64 {
+	t.Helper()
+
+	vec, err := metricstestutil.GetHistogramVecFromGatherer(legacyregistry.DefaultGatherer, "pinniped_supervisor_storage_garbage_collector_sweep_duration_seconds", nil)
+	require.NoError(t, err)
+	return vec.GetAggregatedSampleCount()
+}
+
 type testQueue struct {
 	t *testing.T
 
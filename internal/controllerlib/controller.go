@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package controllerlib
@@ -43,6 +43,13 @@ type Controller interface {
 	// The wrapping must be done after New is called and before Run is called.
 	wrap(wrapper SyncWrapperFunc)
 
+	// tune applies the tuning from the configuration. It is called by the Manager before Run.
+	tune(spec TuningSpec)
+
+	// useDefaultRecorder replaces the default recorder, which only logs events, unless the controller was created
+	// with its own recorder. It is called by the Manager before Run.
+	useDefaultRecorder(recorder events.EventRecorder)
+
 	// These are called by the Run() method but also need to be called by Test* functions sometimes.
 	waitForCacheSyncWithTimeout() bool
 	invokeAllRunOpts()
@@ -56,6 +63,8 @@ type Config struct {
 }
 
 func New(config Config, opts ...Option) Controller {
+	registerMetrics()
+
 	c := &controller{
 		config: config,
 	}
@@ -78,6 +87,8 @@ type controller struct {
 	queueWrapper Queue
 	maxRetries   int
 	recorder     events.EventRecorder
+	resyncPeriod time.Duration // zero means the resync period of the informers
+	quietPeriod  time.Duration // zero means that informer events are queued immediately
 
 	run     bool
 	runOpts []Option
@@ -150,6 +161,12 @@ func (c *controller) wrap(wrapper SyncWrapperFunc) {
 	}))
 }
 
+func (c *controller) useDefaultRecorder(recorder events.EventRecorder) {
+	if _, isDefault := c.recorder.(klogRecorder); isDefault {
+		c.recorder = recorder
+	}
+}
+
 func (c *controller) waitForCacheSyncWithTimeout() bool {
 	// prevent us from blocking forever due to a broken informer
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
@@ -160,6 +177,12 @@ func (c *controller) waitForCacheSyncWithTimeout() bool {
 
 func (c *controller) add(filter Filter, object metav1.Object) {
 	key := filter.Parent(object)
+	if c.quietPeriod > 0 {
+		// The workqueue only keeps the earliest of the pending delays of a key and it does not queue a key twice,
+		// so all the events for this key until the quiet period is over are coalesced into a single sync.
+		c.queueWrapper.AddAfter(key, c.quietPeriod)
+		return
+	}
 	c.queueWrapper.Add(key)
 }
 
@@ -193,7 +216,13 @@ func (c *controller) processNextWorkItem(ctx context.Context) {
 		Recorder: c.recorder,
 	}
 
+	start := time.Now()
 	err := c.sync(syncCtx)
+	syncDurationMetric.WithLabelValues(c.Name()).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, ErrSyntheticRequeue) {
+		syncErrorsMetric.WithLabelValues(c.Name()).Inc()
+	}
+
 	c.handleKey(key, err)
 }
 
@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package controllerlib
@@ -7,12 +7,19 @@ import (
 	"context"
 	"sync"
 
+	"k8s.io/client-go/tools/events"
+
 	"go.pinniped.dev/internal/plog"
 )
 
 type Manager interface {
 	Start(ctx context.Context)
 	WithController(controller Controller, workers int) Manager
+	// WithTuning sets the tuning of the controllers with the given names. It is applied when the manager starts.
+	WithTuning(tuning map[string]TuningSpec) Manager
+	// WithEventBroadcaster makes the controllers record their events to Kubernetes through the broadcaster, instead of
+	// only logging them. The broadcaster records while the manager runs, and is shut down when the manager stops.
+	WithEventBroadcaster(broadcaster events.EventBroadcasterAdapter, component string) Manager
 }
 
 func NewManager() Manager {
@@ -27,6 +34,10 @@ type runnableController struct {
 
 type controllerManager struct {
 	controllers []runnableController
+	tuning      map[string]TuningSpec
+
+	eventBroadcaster events.EventBroadcasterAdapter
+	eventComponent   string
 }
 
 var _ Manager = &controllerManager{}
@@ -39,11 +50,36 @@ func (c *controllerManager) WithController(controller Controller, workers int) M
 	return c
 }
 
+func (c *controllerManager) WithTuning(tuning map[string]TuningSpec) Manager {
+	c.tuning = tuning
+	return c
+}
+
+func (c *controllerManager) WithEventBroadcaster(broadcaster events.EventBroadcasterAdapter, component string) Manager {
+	c.eventBroadcaster = broadcaster
+	c.eventComponent = component
+	return c
+}
+
 // Start will run all managed controllers and block until all controllers shutdown.
 // When the context passed is cancelled, all controllers are signalled to shutdown.
 func (c *controllerManager) Start(ctx context.Context) {
 	var wg sync.WaitGroup
 	wg.Add(len(c.controllers))
+	for i := range c.controllers {
+		if spec, ok := c.tuning[c.controllers[i].controller.Name()]; ok {
+			plog.Debug("tuning controller", "controller", c.controllers[i].controller.Name())
+			c.controllers[i].controller.tune(spec)
+		}
+	}
+	if c.eventBroadcaster != nil {
+		c.eventBroadcaster.StartRecordingToSink(ctx.Done())
+		defer c.eventBroadcaster.Shutdown()
+		recorder := c.eventBroadcaster.NewRecorder(c.eventComponent)
+		for i := range c.controllers {
+			c.controllers[i].controller.useDefaultRecorder(recorder)
+		}
+	}
 	for i := range c.controllers {
 		idx := i
 		go func() {
@@ -0,0 +1,45 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllerlib
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"go.pinniped.dev/internal/pmetrics"
+
+	// The queue of each controller is named after the controller, so this makes the standard workqueue metrics, such
+	// as workqueue_depth and workqueue_adds_total, available for each controller via their name label.
+	_ "k8s.io/component-base/metrics/prometheus/workqueue"
+)
+
+// These metrics are labeled by the name of the controller, so that hot-looping controllers are easy to spot.
+var (
+	syncDurationMetric = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemController,
+		Name:           "sync_duration_seconds",
+		Help:           "Duration of the syncs of each controller.",
+		Buckets:        metrics.ExponentialBuckets(0.001, 4, 10),
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller"})
+	syncErrorsMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemController,
+		Name:           "sync_errors_total",
+		Help:           "Number of syncs of each controller which returned an error, not counting synthetic requeues.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"controller"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		pmetrics.ApplyLatencyBuckets(syncDurationMetric.HistogramOpts)
+		legacyregistry.MustRegister(syncDurationMetric, syncErrorsMetric)
+	})
+}
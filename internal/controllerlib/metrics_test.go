@@ -0,0 +1,62 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllerlib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/component-base/metrics/legacyregistry"
+	metricstestutil "k8s.io/component-base/metrics/testutil"
+)
+
+func TestControllerMetrics(t *testing.T) {
+	name := "test-metrics-controller-" + rand.String(8) // the metrics are global, so avoid collisions with other runs
+
+	var syncErrs []error
+	c := New(Config{
+		Name: name,
+		Syncer: SyncFunc(func(ctx Context) error {
+			err := syncErrs[0]
+			syncErrs = syncErrs[1:]
+			return err
+		}),
+	}, WithRateLimiter(workqueue.NewItemExponentialFailureRateLimiter(time.Hour, time.Hour))).(*controller) // no retries during the test
+
+	// a successful sync, a failed sync and a synthetic requeue
+	syncErrs = []error{nil, errors.New("some error"), ErrSyntheticRequeue}
+	for range syncErrs {
+		c.queue.Add(Key{Name: "some-key"})
+		c.processNextWorkItem(context.Background())
+	}
+
+	syncs, err := metricstestutil.GetHistogramMetricCount(syncDurationMetric.WithLabelValues(name))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), syncs)
+
+	syncErrors, err := metricstestutil.GetCounterMetricValue(syncErrorsMetric.WithLabelValues(name))
+	require.NoError(t, err)
+	require.Equal(t, float64(1), syncErrors, "synthetic requeues are not errors")
+
+	// the queue metrics are labeled with the name of the controller too
+	families, err := legacyregistry.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	var adds float64
+	for _, family := range families {
+		if family.GetName() != "workqueue_adds_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metricstestutil.LabelsMatch(metric, map[string]string{"name": name}) {
+				adds = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	require.Equal(t, float64(3), adds)
+}
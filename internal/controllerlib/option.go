@@ -6,6 +6,7 @@ package controllerlib
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,6 +39,15 @@ func WithRateLimiter(limiter workqueue.RateLimiter) Option {
 	}
 }
 
+// WithQuietPeriod delays the keys queued by informer events by the quiet period. All the events for a key which
+// arrive during that time result in a single sync, which keeps bursts of updates, e.g. of Secrets on a busy
+// cluster, from causing many redundant syncs. Keys queued by other means, such as WithInitialEvent, are not delayed.
+func WithQuietPeriod(quietPeriod time.Duration) Option {
+	return func(c *controller) {
+		c.quietPeriod = quietPeriod
+	}
+}
+
 func WithRecorder(recorder events.EventRecorder) Option {
 	return func(c *controller) {
 		c.recorder = recorder
@@ -59,7 +69,7 @@ func WithInformer(getter InformerGetter, filter Filter, opt InformerOption) Opti
 			return
 		}
 
-		_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		_, err := addEventHandler(informer, c.resyncPeriod, cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
 				object := metaOrDie(obj)
 				if filter.Add(object) {
@@ -120,6 +130,13 @@ func WithInformer(getter InformerGetter, filter Filter, opt InformerOption) Opti
 	})
 }
 
+func addEventHandler(informer cache.SharedIndexInformer, resyncPeriod time.Duration, handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	if resyncPeriod == 0 {
+		return informer.AddEventHandler(handler)
+	}
+	return informer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+}
+
 // toRunOpt guarantees that an Option only runs once on the first call to Run (and not New), even if a controller is stopped and restarted.
 func toRunOpt(opt Option) Option {
 	return toOnceOpt(toNaiveRunOpt(opt))
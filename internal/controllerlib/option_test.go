@@ -1,11 +1,14 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package controllerlib
 
 import (
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -23,3 +26,25 @@ func TestInformerCalled(t *testing.T) {
 		t.Error("expected InformerGetter.Informer() to be called")
 	}
 }
+
+func TestWithQuietPeriod(t *testing.T) {
+	c := New(Config{Name: "some-controller"}, WithQuietPeriod(100*time.Millisecond)).(*controller)
+	defer c.queue.ShutDown()
+
+	filter := FilterFuncs{}
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		c.add(filter, &metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-name"})
+	}
+	c.add(filter, &metav1.ObjectMeta{Namespace: "some-namespace", Name: "other-name"})
+	require.Zero(t, c.queue.Len(), "keys should not be queued during the quiet period")
+
+	first, _ := c.queue.Get()
+	second, _ := c.queue.Get()
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	require.ElementsMatch(t, []interface{}{
+		Key{Namespace: "some-namespace", Name: "some-name"},
+		Key{Namespace: "some-namespace", Name: "other-name"},
+	}, []interface{}{first, second})
+	require.Zero(t, c.queue.Len(), "the events for each key should be coalesced")
+}
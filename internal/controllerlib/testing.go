@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package controllerlib
@@ -9,6 +9,9 @@ import (
 
 func TestSync(t *testing.T, controller Controller, ctx Context) error {
 	t.Helper() // force testing import to discourage external use
+	if ctx.Recorder == nil {
+		ctx.Recorder = klogRecorder{}
+	}
 	return controller.sync(ctx)
 }
 
@@ -0,0 +1,121 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllerlib
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+const (
+	// These match workqueue.DefaultControllerRateLimiter.
+	defaultRateLimiterBaseDelay = 5 * time.Millisecond
+	defaultRateLimiterMaxDelay  = 1000 * time.Second
+)
+
+// TuningSpec configures how hard a controller reconciles. It comes from the configuration of the Concierge and the
+// Supervisor, keyed by controller name, so that large clusters can reduce the reconcile pressure of busy controllers.
+type TuningSpec struct {
+	// RateLimiter configures how quickly the keys of failed syncs are retried.
+	RateLimiter *RateLimiterSpec `json:"rateLimiter,omitempty"`
+	// ResyncPeriodSeconds is how often the controller is told about all the objects of its informers even though they
+	// did not change. It cannot be shorter than the resync period of the informers, which is used when it is unset.
+	ResyncPeriodSeconds *int64 `json:"resyncPeriodSeconds,omitempty"`
+	// QuietPeriodMilliseconds is how long the controller waits after an informer event for a key before it syncs
+	// that key, so that a burst of events results in a single sync. Zero disables the quiet period. When it is unset,
+	// the quiet period chosen by the controller, which is usually zero, is used.
+	QuietPeriodMilliseconds *int64 `json:"quietPeriodMilliseconds,omitempty"`
+}
+
+// RateLimiterSpec configures the per-key exponential backoff of the workqueue of a controller.
+type RateLimiterSpec struct {
+	// BaseDelayMilliseconds is the delay before the first retry. Defaults to 5.
+	BaseDelayMilliseconds *int64 `json:"baseDelayMilliseconds,omitempty"`
+	// MaxDelaySeconds is the upper bound of the delay between retries. Defaults to 1000.
+	MaxDelaySeconds *int64 `json:"maxDelaySeconds,omitempty"`
+}
+
+// ValidateTuning validates the tuning of each controller.
+func ValidateTuning(tuning map[string]TuningSpec) error {
+	names := make([]string, 0, len(tuning))
+	for name := range tuning {
+		names = append(names, name)
+	}
+	sort.Strings(names) // make the error deterministic
+
+	for _, name := range names {
+		if err := tuning[name].validate(); err != nil {
+			return fmt.Errorf("controller %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (s TuningSpec) validate() error {
+	if s.ResyncPeriodSeconds != nil && *s.ResyncPeriodSeconds <= 0 {
+		return constable.Error("resyncPeriodSeconds must be positive")
+	}
+	if s.QuietPeriodMilliseconds != nil && *s.QuietPeriodMilliseconds < 0 {
+		return constable.Error("quietPeriodMilliseconds cannot be negative")
+	}
+	if s.RateLimiter == nil {
+		return nil
+	}
+	if s.RateLimiter.BaseDelayMilliseconds != nil && *s.RateLimiter.BaseDelayMilliseconds <= 0 {
+		return constable.Error("rateLimiter.baseDelayMilliseconds must be positive")
+	}
+	if s.RateLimiter.MaxDelaySeconds != nil && *s.RateLimiter.MaxDelaySeconds <= 0 {
+		return constable.Error("rateLimiter.maxDelaySeconds must be positive")
+	}
+	if s.RateLimiter.baseDelay() > s.RateLimiter.maxDelay() {
+		return constable.Error("rateLimiter.baseDelayMilliseconds cannot be longer than rateLimiter.maxDelaySeconds")
+	}
+	return nil
+}
+
+func (s *RateLimiterSpec) baseDelay() time.Duration {
+	if s.BaseDelayMilliseconds == nil {
+		return defaultRateLimiterBaseDelay
+	}
+	return time.Duration(*s.BaseDelayMilliseconds) * time.Millisecond
+}
+
+func (s *RateLimiterSpec) maxDelay() time.Duration {
+	if s.MaxDelaySeconds == nil {
+		return defaultRateLimiterMaxDelay
+	}
+	return time.Duration(*s.MaxDelaySeconds) * time.Second
+}
+
+// rateLimiter is like workqueue.DefaultControllerRateLimiter, but with the configured per-key backoff.
+func (s *RateLimiterSpec) rateLimiter() workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(s.baseDelay(), s.maxDelay()),
+		// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+// tune applies the tuning to a controller. It must be called before the controller runs.
+func (c *controller) tune(spec TuningSpec) {
+	if c.run {
+		panic(die(fmt.Sprintf("%s: cannot tune a controller which has already run", c.Name())))
+	}
+	if spec.RateLimiter != nil {
+		c.queue.ShutDown() // nothing has been added to the queue yet, so it is safe to replace
+		WithRateLimiter(spec.RateLimiter.rateLimiter())(c)
+	}
+	if spec.ResyncPeriodSeconds != nil {
+		c.resyncPeriod = time.Duration(*spec.ResyncPeriodSeconds) * time.Second
+	}
+	if spec.QuietPeriodMilliseconds != nil {
+		WithQuietPeriod(time.Duration(*spec.QuietPeriodMilliseconds) * time.Millisecond)(c)
+	}
+}
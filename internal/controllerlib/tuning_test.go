@@ -0,0 +1,164 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package controllerlib
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateTuning(t *testing.T) {
+	tests := []struct {
+		name    string
+		tuning  map[string]TuningSpec
+		wantErr string
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "empty specs",
+			tuning: map[string]TuningSpec{
+				"some-controller":  {},
+				"other-controller": {RateLimiter: &RateLimiterSpec{}},
+			},
+		},
+		{
+			name: "fully filled out",
+			tuning: map[string]TuningSpec{
+				"some-controller": {
+					RateLimiter: &RateLimiterSpec{
+						BaseDelayMilliseconds: pointer.Int64(1000),
+						MaxDelaySeconds:       pointer.Int64(1),
+					},
+					ResyncPeriodSeconds:     pointer.Int64(60),
+					QuietPeriodMilliseconds: pointer.Int64(0),
+				},
+			},
+		},
+		{
+			name: "zero resync period",
+			tuning: map[string]TuningSpec{
+				"some-controller": {ResyncPeriodSeconds: pointer.Int64(0)},
+			},
+			wantErr: `controller "some-controller": resyncPeriodSeconds must be positive`,
+		},
+		{
+			name: "negative quiet period",
+			tuning: map[string]TuningSpec{
+				"some-controller": {QuietPeriodMilliseconds: pointer.Int64(-1)},
+			},
+			wantErr: `controller "some-controller": quietPeriodMilliseconds cannot be negative`,
+		},
+		{
+			name: "negative base delay",
+			tuning: map[string]TuningSpec{
+				"some-controller": {RateLimiter: &RateLimiterSpec{BaseDelayMilliseconds: pointer.Int64(-1)}},
+			},
+			wantErr: `controller "some-controller": rateLimiter.baseDelayMilliseconds must be positive`,
+		},
+		{
+			name: "zero max delay",
+			tuning: map[string]TuningSpec{
+				"some-controller": {RateLimiter: &RateLimiterSpec{MaxDelaySeconds: pointer.Int64(0)}},
+			},
+			wantErr: `controller "some-controller": rateLimiter.maxDelaySeconds must be positive`,
+		},
+		{
+			name: "base delay longer than the default max delay",
+			tuning: map[string]TuningSpec{
+				"some-controller": {RateLimiter: &RateLimiterSpec{BaseDelayMilliseconds: pointer.Int64(1000*1000 + 1)}},
+			},
+			wantErr: `controller "some-controller": rateLimiter.baseDelayMilliseconds cannot be longer than rateLimiter.maxDelaySeconds`,
+		},
+		{
+			name: "the first invalid controller by name is reported",
+			tuning: map[string]TuningSpec{
+				"b-controller": {ResyncPeriodSeconds: pointer.Int64(-1)},
+				"a-controller": {RateLimiter: &RateLimiterSpec{MaxDelaySeconds: pointer.Int64(-1)}},
+				"c-controller": {ResyncPeriodSeconds: pointer.Int64(-1)},
+			},
+			wantErr: `controller "a-controller": rateLimiter.maxDelaySeconds must be positive`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateTuning(tt.tuning)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRateLimiterSpec(t *testing.T) {
+	defaults := (&RateLimiterSpec{}).rateLimiter()
+	require.Equal(t, 5*time.Millisecond, defaults.When("some-key"))
+	require.Equal(t, 10*time.Millisecond, defaults.When("some-key"))
+
+	tuned := (&RateLimiterSpec{
+		BaseDelayMilliseconds: pointer.Int64(300),
+		MaxDelaySeconds:       pointer.Int64(1),
+	}).rateLimiter()
+	require.Equal(t, 300*time.Millisecond, tuned.When("some-key"))
+	require.Equal(t, 600*time.Millisecond, tuned.When("some-key"))
+	require.Equal(t, time.Second, tuned.When("some-key"))
+	require.Equal(t, time.Second, tuned.When("some-key"))
+	require.Equal(t, 300*time.Millisecond, tuned.When("other-key"))
+}
+
+func TestTune(t *testing.T) {
+	c := New(Config{Name: "some-controller"}).(*controller)
+	originalQueue := c.queue
+
+	c.tune(TuningSpec{})
+	require.Same(t, originalQueue, c.queue)
+	require.Zero(t, c.resyncPeriod)
+	require.Zero(t, c.quietPeriod)
+
+	c.tune(TuningSpec{
+		RateLimiter:             &RateLimiterSpec{MaxDelaySeconds: pointer.Int64(1)},
+		ResyncPeriodSeconds:     pointer.Int64(42),
+		QuietPeriodMilliseconds: pointer.Int64(250),
+	})
+	require.NotSame(t, originalQueue, c.queue)
+	require.True(t, originalQueue.ShuttingDown())
+	require.False(t, c.queue.ShuttingDown())
+	require.Equal(t, 42*time.Second, c.resyncPeriod)
+	require.Equal(t, 250*time.Millisecond, c.quietPeriod)
+
+	c.run = true
+	require.PanicsWithValue(t, die("some-controller: cannot tune a controller which has already run"), func() {
+		c.tune(TuningSpec{})
+	})
+}
+
+func TestManagerWithTuning(t *testing.T) {
+	tuned := New(Config{Name: "tuned-controller", Syncer: SyncFunc(func(Context) error { return nil })}).(*controller)
+	untuned := New(Config{Name: "untuned-controller", Syncer: SyncFunc(func(Context) error { return nil })}).(*controller)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // the controllers stop right away
+
+	NewManager().
+		WithTuning(map[string]TuningSpec{
+			"tuned-controller":   {ResyncPeriodSeconds: pointer.Int64(42)},
+			"missing-controller": {ResyncPeriodSeconds: pointer.Int64(7)},
+		}).
+		WithController(tuned, 1).
+		WithController(untuned, 1).
+		Start(ctx)
+
+	require.Equal(t, 42*time.Second, tuned.resyncPeriod)
+	require.Zero(t, untuned.resyncPeriod)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package controllermanager provides an entrypoint into running all of the controllers that run as
@@ -6,13 +6,19 @@
 package controllermanager
 
 import (
+	"crypto"
 	"fmt"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	k8sinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/utils/clock"
 
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
 	pinnipedclientset "go.pinniped.dev/generated/latest/client/concierge/clientset/versioned"
 	pinnipedinformers "go.pinniped.dev/generated/latest/client/concierge/informers/externalversions"
 	"go.pinniped.dev/internal/apiserviceref"
@@ -25,8 +31,10 @@ import (
 	"go.pinniped.dev/internal/controller/authenticator/webhookcachefiller"
 	"go.pinniped.dev/internal/controller/impersonatorconfig"
 	"go.pinniped.dev/internal/controller/kubecertagent"
+	"go.pinniped.dev/internal/controller/loglevel"
 	"go.pinniped.dev/internal/controllerinit"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/crypto/ptls"
 	"go.pinniped.dev/internal/deploymentref"
 	"go.pinniped.dev/internal/downward"
 	"go.pinniped.dev/internal/dynamiccert"
@@ -39,6 +47,10 @@ import (
 const (
 	singletonWorker       = 1
 	defaultResyncInterval = 3 * time.Minute
+
+	// appLabelKey is the key of the label which the YAML manifests apply to all Concierge resources, including
+	// the Secrets which are created by the controllers.
+	appLabelKey = "app"
 )
 
 // Config holds all the input parameters to the set of controllers run as a part of Pinniped.
@@ -81,6 +93,21 @@ type Config struct {
 	// (Note that the impersonation proxy also accepts client certs signed by the Kube API server's cert.)
 	ImpersonationSigningCertProvider dynamiccert.Provider
 
+	// ImpersonationSigner is optional. When it is set, the impersonation proxy's signer CA is signed by it,
+	// e.g. by a key which is held by an external signer plugin, instead of by a private key which is stored
+	// in the impersonation signer Secret.
+	ImpersonationSigner crypto.Signer
+
+	// ImpersonationProxyTLSConfigFunc returns the TLS config of the impersonation proxy, i.e. the TLS profile of the
+	// Pinniped config API (see api.Config) applied on top of ptls.Default.
+	ImpersonationProxyTLSConfigFunc ptls.ConfigFunc
+
+	// ImpersonationProxyTransport tunes the transports of the impersonation proxy to the Kubernetes API server.
+	ImpersonationProxyTransport impersonator.TransportSpec
+
+	// ImpersonationProxyPrivilegedIdentities configures how the impersonation proxy treats privileged users.
+	ImpersonationProxyPrivilegedIdentities impersonator.PrivilegedIdentitiesSpec
+
 	// ServingCertDuration is the validity period, in seconds, of the API serving certificate.
 	ServingCertDuration time.Duration
 
@@ -89,43 +116,89 @@ type Config struct {
 	// certificate.
 	ServingCertRenewBefore time.Duration
 
+	// Certificates comes from the Pinniped config API (see api.Config). It configures the key algorithm of the
+	// generated certificates and the validity of the impersonation proxy's signer CA.
+	Certificates *concierge.CertificatesSpec
+
 	// AuthenticatorCache is a cache of authenticators shared amongst various authenticated-related controllers.
 	AuthenticatorCache *authncache.Cache
 
 	// Labels are labels that should be added to any resources created by the controllers.
 	Labels map[string]string
+
+	// Log is the log config that the server was started with. Its log levels are restored when they are
+	// removed from the ConfigMap named by NamesConfig.ConfigMap.
+	Log plog.LogSpec
+
+	// ControllerTuning tunes the rate limiters, resync periods and quiet periods of the controllers, keyed by controller name.
+	ControllerTuning map[string]controllerlib.TuningSpec
+
+	// KubeClientRateLimit configures the client-side rate limiting of the requests of the controllers.
+	KubeClientRateLimit kubeclient.RateLimitSpec
+
+	// TargetCluster comes from the Pinniped config API (see api.Config). When it has a kubeconfig, the Concierge
+	// runs outside of its target cluster, and the controllers manage the resources of that cluster instead.
+	TargetCluster *concierge.TargetClusterSpec
 }
 
 // PrepareControllers prepares the controllers and their informers and returns a function that will start them when called.
 func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nolint:funlen // Eh, fair, it is a really long function...but it is wiring the world...so...
 	loginConciergeGroupData, identityConciergeGroupData := groupsuffix.ConciergeAggregatedGroups(c.APIGroupSuffix)
 
+	configConciergeGroup, ok := groupsuffix.Replace(configv1alpha1.GroupName, c.APIGroupSuffix)
+	if !ok {
+		return nil, fmt.Errorf("cannot make api group from %s/%s", configv1alpha1.GroupName, c.APIGroupSuffix)
+	}
+
+	// The deployment is always looked up in the cluster in which the pod runs, even when it is not the target cluster.
 	dref, deployment, _, err := deploymentref.New(c.ServerInstallationInfo)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create deployment ref: %w", err)
 	}
 
-	apiServiceRef, err := apiserviceref.New(loginConciergeGroupData.APIServiceName())
+	targetClusterConfig, err := c.TargetCluster.RESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	namespace := c.TargetCluster.NamespaceOrDefault(c.ServerInstallationInfo.Namespace)
+
+	apiServiceRef, err := apiserviceref.New(loginConciergeGroupData.APIServiceName(), kubeclient.WithConfig(targetClusterConfig))
 	if err != nil {
 		return nil, fmt.Errorf("cannot create API service ref: %w", err)
 	}
 
+	var ownerRefs []kubeclient.Option
+	if !c.TargetCluster.IsRemote() {
+		// first try to use the deployment as an owner ref (for namespace scoped resources)
+		ownerRefs = append(ownerRefs, dref)
+	}
+	// fallback to our API service (for everything else we create), which is the only owner in a remote target cluster,
+	// because the deployment is not in that cluster
+	ownerRefs = append(ownerRefs, apiServiceRef)
+
+	// The leases of the leader election are in the namespace of the target cluster.
+	leaderElectionPodInfo := *c.ServerInstallationInfo
+	leaderElectionPodInfo.Namespace = namespace
+
 	client, leaderElector, err := leaderelection.New(
-		c.ServerInstallationInfo,
+		&leaderElectionPodInfo,
 		deployment,
-		dref,          // first try to use the deployment as an owner ref (for namespace scoped resources)
-		apiServiceRef, // fallback to our API service (for everything else we create)
-		kubeclient.WithMiddleware(groupsuffix.New(c.APIGroupSuffix)),
+		append(ownerRefs,
+			kubeclient.WithConfig(targetClusterConfig),
+			kubeclient.WithMiddleware(groupsuffix.New(c.APIGroupSuffix)),
+			kubeclient.WithRetry(kubeclient.DefaultRetryBackoff), // keep brief API server blips from flapping conditions
+			kubeclient.WithRateLimit(c.KubeClientRateLimit),
+		)...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("could not create clients for the controllers: %w", err)
 	}
 
 	// Create informers. Don't forget to make sure they get started in the function returned below.
-	informers := createInformers(c.ServerInstallationInfo.Namespace, client.Kubernetes, client.PinnipedConcierge)
+	informers := createInformers(namespace, c.Labels, client.Kubernetes, client.PinnipedConcierge)
 
 	agentConfig := kubecertagent.AgentConfig{
-		Namespace:                 c.ServerInstallationInfo.Namespace,
+		Namespace:                 namespace,
 		ServiceAccountName:        c.NamesConfig.AgentServiceAccount,
 		ContainerImage:            *c.KubeCertAgentConfig.Image,
 		NamePrefix:                *c.KubeCertAgentConfig.NamePrefix,
@@ -138,18 +211,22 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 	// Create controller manager.
 	controllerManager := controllerlib.
 		NewManager().
+		WithTuning(c.ControllerTuning).
+		WithEventBroadcaster(events.NewEventBroadcasterAdapter(client.Kubernetes), "pinniped-concierge").
 
 		// API certs controllers are responsible for managing the TLS certificates used to serve Pinniped's API.
 		WithController(
 			apicerts.NewCertsManagerController(
-				c.ServerInstallationInfo.Namespace,
+				namespace,
 				c.NamesConfig.ServingCertificateSecret,
 				c.Labels,
 				client.Kubernetes,
-				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceSecretsK8s.Core().V1().Secrets(),
 				controllerlib.WithInformer,
 				controllerlib.WithInitialEvent,
 				c.ServingCertDuration,
+				c.Certificates.KeyAlgorithm,
+				nil, // the CA of the API serving cert always has its own private key
 				"Pinniped Aggregation CA",
 				c.NamesConfig.APIService,
 			),
@@ -157,42 +234,53 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 		).
 		WithController(
 			apicerts.NewAPIServiceUpdaterController(
-				c.ServerInstallationInfo.Namespace,
+				namespace,
 				c.NamesConfig.ServingCertificateSecret,
 				loginConciergeGroupData.APIServiceName(),
 				client.Aggregation,
-				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceSecretsK8s.Core().V1().Secrets(),
 				controllerlib.WithInformer,
 			),
 			singletonWorker,
 		).
 		WithController(
 			apicerts.NewAPIServiceUpdaterController(
-				c.ServerInstallationInfo.Namespace,
+				namespace,
 				c.NamesConfig.ServingCertificateSecret,
 				identityConciergeGroupData.APIServiceName(),
 				client.Aggregation,
-				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceSecretsK8s.Core().V1().Secrets(),
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		).
+		WithController(
+			apicerts.NewCRDConversionUpdaterController(
+				namespace,
+				c.NamesConfig.ServingCertificateSecret,
+				"credentialissuers."+configConciergeGroup, // the only CRD with a conversion webhook
+				client.APIExtensions,
+				informers.installationNamespaceSecretsK8s.Core().V1().Secrets(),
 				controllerlib.WithInformer,
 			),
 			singletonWorker,
 		).
 		WithController(
 			apicerts.NewCertsObserverController(
-				c.ServerInstallationInfo.Namespace,
+				namespace,
 				c.NamesConfig.ServingCertificateSecret,
 				c.DynamicServingCertProvider,
-				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceSecretsK8s.Core().V1().Secrets(),
 				controllerlib.WithInformer,
 			),
 			singletonWorker,
 		).
 		WithController(
 			apicerts.NewCertsExpirerController(
-				c.ServerInstallationInfo.Namespace,
+				namespace,
 				c.NamesConfig.ServingCertificateSecret,
 				client.Kubernetes,
-				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceSecretsK8s.Core().V1().Secrets(),
 				controllerlib.WithInformer,
 				c.ServingCertRenewBefore,
 				apicerts.TLSCertificateChainSecretKey,
@@ -232,6 +320,8 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 			webhookcachefiller.New(
 				c.AuthenticatorCache,
 				informers.pinniped.Authentication().V1alpha1().WebhookAuthenticators(),
+				client.Kubernetes,
+				namespace,
 				plog.Logr(), //nolint:staticcheck  // old controller with lots of log statements
 			),
 			singletonWorker,
@@ -257,13 +347,13 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 		// The impersonator configuration controller dynamically configures the impersonation proxy feature.
 		WithController(
 			impersonatorconfig.NewImpersonatorConfigController(
-				c.ServerInstallationInfo.Namespace,
+				namespace,
 				c.NamesConfig.CredentialIssuer,
 				client.Kubernetes,
 				client.PinnipedConcierge,
 				informers.pinniped.Config().V1alpha1().CredentialIssuers(),
 				informers.installationNamespaceK8s.Core().V1().Services(),
-				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceSecretsK8s.Core().V1().Secrets(),
 				controllerlib.WithInformer,
 				c.ImpersonationProxyServerPort,
 				c.NamesConfig.ImpersonationLoadBalancerService,
@@ -272,60 +362,90 @@ func PrepareControllers(c *Config) (controllerinit.RunnerBuilder, error) { //nol
 				c.NamesConfig.ImpersonationCACertificateSecret,
 				c.Labels,
 				clock.RealClock{},
-				impersonator.New,
+				impersonator.NewFactory(c.ImpersonationProxyTLSConfigFunc, c.ImpersonationProxyTransport, c.ImpersonationProxyPrivilegedIdentities, c.TargetCluster.Kubeconfig),
 				c.NamesConfig.ImpersonationSignerSecret,
 				c.ImpersonationSigningCertProvider,
+				c.Certificates.KeyAlgorithm,
 				plog.Logr(), //nolint:staticcheck  // old controller with lots of log statements
 			),
 			singletonWorker,
 		).
 		WithController(
 			apicerts.NewCertsManagerController(
-				c.ServerInstallationInfo.Namespace,
+				namespace,
 				c.NamesConfig.ImpersonationSignerSecret,
 				c.Labels,
 				client.Kubernetes,
-				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceSecretsK8s.Core().V1().Secrets(),
 				controllerlib.WithInformer,
 				controllerlib.WithInitialEvent,
-				365*24*time.Hour, // 1 year hard coded value
+				time.Duration(*c.Certificates.ImpersonationSigner.DurationSeconds)*time.Second,
+				c.Certificates.KeyAlgorithm,
+				c.ImpersonationSigner,
 				"Pinniped Impersonation Proxy Signer CA",
 				"", // optional, means do not give me a serving cert
 			),
 			singletonWorker,
 		).
+		// The signer CA is rotated in place, so that the client certs which were signed by the previous CA
+		// remain trusted by the impersonation proxy until they expire.
 		WithController(
-			apicerts.NewCertsExpirerController(
-				c.ServerInstallationInfo.Namespace,
+			apicerts.NewCARotatorController(
+				namespace,
 				c.NamesConfig.ImpersonationSignerSecret,
 				client.Kubernetes,
-				informers.installationNamespaceK8s.Core().V1().Secrets(),
+				informers.installationNamespaceSecretsK8s.Core().V1().Secrets(),
 				controllerlib.WithInformer,
-				365*24*time.Hour-time.Hour, // 1 year minus 1 hour hard coded value (i.e. wait until the last moment to break the signer)
-				apicerts.CACertificateSecretKey,
+				time.Duration(*c.Certificates.ImpersonationSigner.RenewBeforeSeconds)*time.Second,
+				time.Duration(*c.Certificates.ImpersonationSigner.DurationSeconds)*time.Second,
+				c.Certificates.KeyAlgorithm,
+				c.ImpersonationSigner,
+				"Pinniped Impersonation Proxy Signer CA",
+				clock.RealClock{},
 				plog.New(),
 			),
 			singletonWorker,
 		)
 
+	// The log level controller is only needed when the ConfigMap which holds our configuration is known.
+	if c.NamesConfig.ConfigMap != "" {
+		controllerManager = controllerManager.WithController(
+			loglevel.NewLogLevelController(
+				namespace,
+				c.NamesConfig.ConfigMap,
+				c.Log,
+				informers.installationNamespaceK8s.Core().V1().ConfigMaps(),
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		)
+	}
+
 	return controllerinit.Prepare(controllerManager.Start, leaderElector,
 		informers.kubePublicNamespaceK8s,
 		informers.kubeSystemNamespaceK8s,
 		informers.installationNamespaceK8s,
+		informers.installationNamespaceSecretsK8s,
 		informers.pinniped,
 	), nil
 }
 
 type informers struct {
-	kubePublicNamespaceK8s   k8sinformers.SharedInformerFactory
-	kubeSystemNamespaceK8s   k8sinformers.SharedInformerFactory
-	installationNamespaceK8s k8sinformers.SharedInformerFactory
-	pinniped                 pinnipedinformers.SharedInformerFactory
+	kubePublicNamespaceK8s          k8sinformers.SharedInformerFactory
+	kubeSystemNamespaceK8s          k8sinformers.SharedInformerFactory
+	installationNamespaceK8s        k8sinformers.SharedInformerFactory
+	installationNamespaceSecretsK8s k8sinformers.SharedInformerFactory
+	pinniped                        pinnipedinformers.SharedInformerFactory
 }
 
 // Create the informers that will be used by the controllers.
+//
+// The Secret informer only caches the Secrets which were created by the Concierge, selected by their app label,
+// instead of every Secret in the installation namespace. The kube-public informer only caches the well-known
+// cluster-info ConfigMap. This keeps the memory usage of the Concierge small on clusters with many Secrets.
 func createInformers(
 	serverInstallationNamespace string,
+	secretLabels map[string]string,
 	k8sClient kubernetes.Interface,
 	pinnipedClient pinnipedclientset.Interface,
 ) *informers {
@@ -334,6 +454,9 @@ func createInformers(
 			k8sClient,
 			defaultResyncInterval,
 			k8sinformers.WithNamespace(kubecertagent.ClusterInfoNamespace),
+			k8sinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", kubecertagent.ClusterInfoName).String()
+			}),
 		),
 		kubeSystemNamespaceK8s: k8sinformers.NewSharedInformerFactoryWithOptions(
 			k8sClient,
@@ -345,9 +468,28 @@ func createInformers(
 			defaultResyncInterval,
 			k8sinformers.WithNamespace(serverInstallationNamespace),
 		),
+		installationNamespaceSecretsK8s: k8sinformers.NewSharedInformerFactoryWithOptions(
+			k8sClient,
+			defaultResyncInterval,
+			k8sinformers.WithNamespace(serverInstallationNamespace),
+			k8sinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.LabelSelector = ownedSecretsSelector(secretLabels).String()
+			}),
+		),
 		pinniped: pinnipedinformers.NewSharedInformerFactoryWithOptions(
 			pinnipedClient,
 			defaultResyncInterval,
 		),
 	}
 }
+
+// ownedSecretsSelector selects the Secrets which were created by the Concierge. All of them have the app label of
+// the Concierge. Other labels are not used, since they can be changed in the configuration and then would not match
+// the Secrets which were created before the change. When there is no app label, all Secrets are selected.
+func ownedSecretsSelector(secretLabels map[string]string) labels.Selector {
+	appName, ok := secretLabels[appLabelKey]
+	if !ok {
+		return labels.Everything()
+	}
+	return labels.SelectorFromSet(labels.Set{appLabelKey: appName})
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crdconversion
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	conciergeconfigv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	conciergeconfigv1beta1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1beta1"
+	"go.pinniped.dev/internal/groupsuffix"
+)
+
+// NewConverters returns the converters of the Concierge's custom resources in the API groups with the given suffix.
+//
+// Only the kinds whose versions have different schemas need a converter. The CustomResourceDefinitions of the other
+// kinds use the None conversion strategy, which only changes the apiVersion of the objects.
+func NewConverters(apiGroupSuffix string) (map[schema.GroupKind]Converter, error) {
+	configGroup, ok := groupsuffix.Replace(conciergeconfigv1alpha1.GroupName, apiGroupSuffix)
+	if !ok {
+		return nil, fmt.Errorf("cannot make api group from %s/%s", conciergeconfigv1alpha1.GroupName, apiGroupSuffix)
+	}
+
+	return map[schema.GroupKind]Converter{
+		{Group: configGroup, Kind: "CredentialIssuer"}: ConverterFunc(convertCredentialIssuer),
+	}, nil
+}
+
+// convertCredentialIssuer converts CredentialIssuers between v1alpha1 and v1beta1.
+//
+// The deprecated status.kubeConfigInfo field of v1alpha1 was dropped from v1beta1. The Concierge always sets it to a
+// copy of the TokenCredentialRequest API frontend of the strategies, so it is computed again from the strategies
+// when converting back to v1alpha1.
+func convertCredentialIssuer(object *unstructured.Unstructured, toVersion string) error {
+	switch toVersion {
+	case conciergeconfigv1beta1.SchemeGroupVersion.Version:
+		unstructured.RemoveNestedField(object.Object, "status", "kubeConfigInfo")
+		return nil
+
+	case conciergeconfigv1alpha1.SchemeGroupVersion.Version:
+		rawStatus, found, err := unstructured.NestedMap(object.Object, "status")
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+
+		var status conciergeconfigv1beta1.CredentialIssuerStatus
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawStatus, &status); err != nil {
+			return fmt.Errorf("could not decode status: %w", err)
+		}
+
+		for _, strategy := range status.Strategies {
+			if strategy.Frontend == nil ||
+				strategy.Frontend.Type != conciergeconfigv1beta1.TokenCredentialRequestAPIFrontendType ||
+				strategy.Frontend.TokenCredentialRequestAPIInfo == nil {
+				continue
+			}
+			return unstructured.SetNestedField(object.Object, map[string]interface{}{
+				"server":                   strategy.Frontend.TokenCredentialRequestAPIInfo.Server,
+				"certificateAuthorityData": strategy.Frontend.TokenCredentialRequestAPIInfo.CertificateAuthorityData,
+			}, "status", "kubeConfigInfo")
+		}
+		unstructured.RemoveNestedField(object.Object, "status", "kubeConfigInfo")
+		return nil
+
+	default:
+		return fmt.Errorf("unknown version %q", toVersion)
+	}
+}
@@ -0,0 +1,398 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crdconversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	authenticationv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	authenticationv1beta1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1beta1"
+	conciergeconfigv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+	conciergeconfigv1beta1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1beta1"
+	supervisorconfigv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	supervisorconfigv1beta1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1beta1"
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	idpv1beta1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1beta1"
+)
+
+func TestNewConverters(t *testing.T) {
+	converters, err := NewConverters("pinniped.example.com")
+	require.NoError(t, err)
+	require.Len(t, converters, 1)
+	require.Contains(t, converters, schema.GroupKind{Group: "config.concierge.pinniped.example.com", Kind: "CredentialIssuer"})
+}
+
+func TestConvertCredentialIssuer(t *testing.T) {
+	const (
+		v1alpha1WithKubeConfigInfo = `{
+			"apiVersion": "config.concierge.pinniped.dev/v1alpha1",
+			"kind": "CredentialIssuer",
+			"metadata": {"name": "some-credential-issuer"},
+			"spec": {"impersonationProxy": {"mode": "auto", "service": {"type": "LoadBalancer"}}},
+			"status": {
+				"kubeConfigInfo": {"server": "https://some-server", "certificateAuthorityData": "some-ca-data"},
+				"strategies": [
+					{
+						"type": "KubeClusterSigningCertificate",
+						"status": "Success",
+						"reason": "FetchedKey",
+						"message": "some-message",
+						"lastUpdateTime": "2023-01-02T03:04:05Z",
+						"frontend": {
+							"type": "TokenCredentialRequestAPI",
+							"tokenCredentialRequestInfo": {"server": "https://some-server", "certificateAuthorityData": "some-ca-data"}
+						}
+					},
+					{
+						"type": "ImpersonationProxy",
+						"status": "Error",
+						"reason": "ErrorDuringSetup",
+						"message": "some-other-message",
+						"lastUpdateTime": "2023-01-02T03:04:05Z"
+					}
+				]
+			}
+		}`
+		v1beta1WithTokenCredentialRequestAPI = `{
+			"apiVersion": "config.concierge.pinniped.dev/v1beta1",
+			"kind": "CredentialIssuer",
+			"metadata": {"name": "some-credential-issuer"},
+			"spec": {"impersonationProxy": {"mode": "auto", "service": {"type": "LoadBalancer"}}},
+			"status": {
+				"strategies": [
+					{
+						"type": "KubeClusterSigningCertificate",
+						"status": "Success",
+						"reason": "FetchedKey",
+						"message": "some-message",
+						"lastUpdateTime": "2023-01-02T03:04:05Z",
+						"frontend": {
+							"type": "TokenCredentialRequestAPI",
+							"tokenCredentialRequestInfo": {"server": "https://some-server", "certificateAuthorityData": "some-ca-data"}
+						}
+					},
+					{
+						"type": "ImpersonationProxy",
+						"status": "Error",
+						"reason": "ErrorDuringSetup",
+						"message": "some-other-message",
+						"lastUpdateTime": "2023-01-02T03:04:05Z"
+					}
+				]
+			}
+		}`
+		v1alpha1WithoutTokenCredentialRequestAPI = `{
+			"apiVersion": "config.concierge.pinniped.dev/v1alpha1",
+			"kind": "CredentialIssuer",
+			"metadata": {"name": "some-credential-issuer"},
+			"status": {
+				"strategies": [
+					{
+						"type": "ImpersonationProxy",
+						"status": "Success",
+						"reason": "Listening",
+						"message": "some-message",
+						"lastUpdateTime": "2023-01-02T03:04:05Z",
+						"frontend": {
+							"type": "ImpersonationProxy",
+							"impersonationProxyInfo": {"endpoint": "https://some-endpoint", "certificateAuthorityData": "some-ca-data"}
+						}
+					}
+				]
+			}
+		}`
+		v1beta1WithoutTokenCredentialRequestAPI = `{
+			"apiVersion": "config.concierge.pinniped.dev/v1beta1",
+			"kind": "CredentialIssuer",
+			"metadata": {"name": "some-credential-issuer"},
+			"status": {
+				"strategies": [
+					{
+						"type": "ImpersonationProxy",
+						"status": "Success",
+						"reason": "Listening",
+						"message": "some-message",
+						"lastUpdateTime": "2023-01-02T03:04:05Z",
+						"frontend": {
+							"type": "ImpersonationProxy",
+							"impersonationProxyInfo": {"endpoint": "https://some-endpoint", "certificateAuthorityData": "some-ca-data"}
+						}
+					}
+				]
+			}
+		}`
+	)
+
+	tests := []struct {
+		name              string
+		object            string
+		desiredAPIVersion string
+		wantObject        string
+		wantErr           string
+	}{
+		{
+			name:              "v1alpha1 to v1beta1 drops the deprecated kubeConfigInfo",
+			object:            v1alpha1WithKubeConfigInfo,
+			desiredAPIVersion: "config.concierge.pinniped.dev/v1beta1",
+			wantObject:        v1beta1WithTokenCredentialRequestAPI,
+		},
+		{
+			name:              "v1beta1 to v1alpha1 sets kubeConfigInfo from the TokenCredentialRequestAPI frontend",
+			object:            v1beta1WithTokenCredentialRequestAPI,
+			desiredAPIVersion: "config.concierge.pinniped.dev/v1alpha1",
+			wantObject:        v1alpha1WithKubeConfigInfo,
+		},
+		{
+			name:              "v1alpha1 to v1beta1 without a TokenCredentialRequestAPI frontend",
+			object:            v1alpha1WithoutTokenCredentialRequestAPI,
+			desiredAPIVersion: "config.concierge.pinniped.dev/v1beta1",
+			wantObject:        v1beta1WithoutTokenCredentialRequestAPI,
+		},
+		{
+			name:              "v1beta1 to v1alpha1 without a TokenCredentialRequestAPI frontend",
+			object:            v1beta1WithoutTokenCredentialRequestAPI,
+			desiredAPIVersion: "config.concierge.pinniped.dev/v1alpha1",
+			wantObject:        v1alpha1WithoutTokenCredentialRequestAPI,
+		},
+		{
+			name:              "v1beta1 to v1alpha1 without a status",
+			object:            `{"apiVersion": "config.concierge.pinniped.dev/v1beta1", "kind": "CredentialIssuer", "metadata": {"name": "a"}}`,
+			desiredAPIVersion: "config.concierge.pinniped.dev/v1alpha1",
+			wantObject:        `{"apiVersion": "config.concierge.pinniped.dev/v1alpha1", "kind": "CredentialIssuer", "metadata": {"name": "a"}}`,
+		},
+		{
+			name:              "v1beta1 to v1alpha1 with an invalid status",
+			object:            `{"apiVersion": "config.concierge.pinniped.dev/v1beta1", "kind": "CredentialIssuer", "metadata": {"name": "a"}, "status": {"strategies": "a"}}`,
+			desiredAPIVersion: "config.concierge.pinniped.dev/v1alpha1",
+			wantErr: `could not convert CredentialIssuer "a" from config.concierge.pinniped.dev/v1beta1 to config.concierge.pinniped.dev/v1alpha1: ` +
+				`could not decode status: cannot restore slice from string`,
+		},
+		{
+			name:              "unknown version",
+			object:            v1alpha1WithKubeConfigInfo,
+			desiredAPIVersion: "config.concierge.pinniped.dev/v2",
+			wantErr: `could not convert CredentialIssuer "some-credential-issuer" from config.concierge.pinniped.dev/v1alpha1 to config.concierge.pinniped.dev/v2: ` +
+				`unknown version "v2"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			convertedObjects, err := convertWithHandler(t, tt.desiredAPIVersion, []byte(tt.object))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, convertedObjects, 1)
+			require.JSONEq(t, tt.wantObject, string(convertedObjects[0]))
+		})
+	}
+}
+
+// TestRoundTrip checks that random objects survive a conversion to the other version and back without losing any
+// data. The kinds whose versions have the same schema are converted by only changing their apiVersion, like the None
+// conversion strategy of their CustomResourceDefinitions.
+func TestRoundTrip(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		authenticationv1alpha1.AddToScheme, authenticationv1beta1.AddToScheme,
+		conciergeconfigv1alpha1.AddToScheme, conciergeconfigv1beta1.AddToScheme,
+		supervisorconfigv1alpha1.AddToScheme, supervisorconfigv1beta1.AddToScheme,
+		idpv1alpha1.AddToScheme, idpv1beta1.AddToScheme,
+	} {
+		require.NoError(t, addToScheme(scheme))
+	}
+
+	// deterministic fuzzing of the objects
+	f := fuzzer.FuzzerFor(metafuzzer.Funcs, rand.NewSource(1), serializer.NewCodecFactory(scheme))
+
+	tests := []struct {
+		v1alpha1Object runtime.Object
+		v1beta1Object  runtime.Object
+		convert        func(t *testing.T, desiredAPIVersion string, object []byte) []byte
+		normalize      func(v1alpha1Object runtime.Object)
+	}{
+		{
+			v1alpha1Object: &authenticationv1alpha1.JWTAuthenticator{},
+			v1beta1Object:  &authenticationv1beta1.JWTAuthenticator{},
+		},
+		{
+			v1alpha1Object: &authenticationv1alpha1.WebhookAuthenticator{},
+			v1beta1Object:  &authenticationv1beta1.WebhookAuthenticator{},
+		},
+		{
+			v1alpha1Object: &conciergeconfigv1alpha1.CredentialIssuer{},
+			v1beta1Object:  &conciergeconfigv1beta1.CredentialIssuer{},
+			convert: func(t *testing.T, desiredAPIVersion string, object []byte) []byte {
+				convertedObjects, err := convertWithHandler(t, desiredAPIVersion, object)
+				require.NoError(t, err)
+				require.Len(t, convertedObjects, 1)
+				return convertedObjects[0]
+			},
+			normalize: func(v1alpha1Object runtime.Object) {
+				// The Concierge always mirrors the TokenCredentialRequestAPI frontend into kubeConfigInfo.
+				status := &v1alpha1Object.(*conciergeconfigv1alpha1.CredentialIssuer).Status
+				status.KubeConfigInfo = nil
+				for _, strategy := range status.Strategies {
+					if strategy.Frontend != nil && strategy.Frontend.TokenCredentialRequestAPIInfo != nil &&
+						strategy.Frontend.Type == conciergeconfigv1alpha1.TokenCredentialRequestAPIFrontendType {
+						status.KubeConfigInfo = &conciergeconfigv1alpha1.CredentialIssuerKubeConfigInfo{
+							Server:                   strategy.Frontend.TokenCredentialRequestAPIInfo.Server,
+							CertificateAuthorityData: strategy.Frontend.TokenCredentialRequestAPIInfo.CertificateAuthorityData,
+						}
+						break
+					}
+				}
+			},
+		},
+		{
+			v1alpha1Object: &supervisorconfigv1alpha1.FederationDomain{},
+			v1beta1Object:  &supervisorconfigv1beta1.FederationDomain{},
+		},
+		{
+			v1alpha1Object: &supervisorconfigv1alpha1.OIDCClient{},
+			v1beta1Object:  &supervisorconfigv1beta1.OIDCClient{},
+		},
+		{
+			v1alpha1Object: &idpv1alpha1.OIDCIdentityProvider{},
+			v1beta1Object:  &idpv1beta1.OIDCIdentityProvider{},
+		},
+		{
+			v1alpha1Object: &idpv1alpha1.LDAPIdentityProvider{},
+			v1beta1Object:  &idpv1beta1.LDAPIdentityProvider{},
+		},
+		{
+			v1alpha1Object: &idpv1alpha1.ActiveDirectoryIdentityProvider{},
+			v1beta1Object:  &idpv1beta1.ActiveDirectoryIdentityProvider{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		kind := reflect.TypeOf(tt.v1alpha1Object).Elem().Name()
+		t.Run(kind, func(t *testing.T) {
+			convert := tt.convert
+			if convert == nil {
+				convert = func(t *testing.T, desiredAPIVersion string, object []byte) []byte {
+					var u map[string]interface{}
+					decoder := json.NewDecoder(bytes.NewReader(object))
+					decoder.UseNumber()
+					require.NoError(t, decoder.Decode(&u))
+					u["apiVersion"] = desiredAPIVersion
+					converted, err := json.Marshal(u)
+					require.NoError(t, err)
+					return converted
+				}
+			}
+
+			// roundTrip converts a random object of one version to the other version and back, decoding it strictly
+			// into the Go type of each version along the way.
+			roundTrip := func(t *testing.T, original, other runtime.Object, normalize func(runtime.Object)) {
+				for i := 0; i < 100; i++ {
+					object := original.DeepCopyObject()
+					f.Fuzz(object)
+					if normalize != nil {
+						normalize(object)
+					}
+
+					originalGVK := gvkFor(t, scheme, original)
+					otherGVK := gvkFor(t, scheme, other)
+					object.GetObjectKind().SetGroupVersionKind(originalGVK)
+					originalEncoded, err := json.Marshal(object)
+					require.NoError(t, err)
+
+					converted := other.DeepCopyObject()
+					strictDecode(t, convert(t, otherGVK.GroupVersion().String(), originalEncoded), converted)
+					require.Equal(t, otherGVK, converted.GetObjectKind().GroupVersionKind())
+					convertedEncoded, err := json.Marshal(converted)
+					require.NoError(t, err)
+
+					roundTripped := original.DeepCopyObject()
+					strictDecode(t, convert(t, originalGVK.GroupVersion().String(), convertedEncoded), roundTripped)
+					roundTrippedEncoded, err := json.Marshal(roundTripped)
+					require.NoError(t, err)
+					require.Equal(t, string(originalEncoded), string(roundTrippedEncoded))
+				}
+			}
+
+			t.Run("v1alpha1 to v1beta1 and back", func(t *testing.T) {
+				roundTrip(t, tt.v1alpha1Object, tt.v1beta1Object, tt.normalize)
+			})
+			t.Run("v1beta1 to v1alpha1 and back", func(t *testing.T) {
+				roundTrip(t, tt.v1beta1Object, tt.v1alpha1Object, nil)
+			})
+		})
+	}
+}
+
+// convertWithHandler sends a ConversionReview with the given object to the handler of the Concierge's converters.
+func convertWithHandler(t *testing.T, desiredAPIVersion string, object []byte) ([][]byte, error) {
+	t.Helper()
+
+	converters, err := NewConverters("pinniped.dev")
+	require.NoError(t, err)
+
+	body, err := json.Marshal(&apiextensionsv1.ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+		Request: &apiextensionsv1.ConversionRequest{
+			UID:               "some-uid",
+			DesiredAPIVersion: desiredAPIVersion,
+			Objects:           []runtime.RawExtension{{Raw: object}},
+		},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rsp := httptest.NewRecorder()
+	NewHandler(converters).ServeHTTP(rsp, req)
+	require.Equal(t, http.StatusOK, rsp.Code)
+
+	var review apiextensionsv1.ConversionReview
+	require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &review))
+	require.NotNil(t, review.Response)
+	if review.Response.Result.Status != metav1.StatusSuccess {
+		return nil, errors.New(review.Response.Result.Message)
+	}
+
+	var convertedObjects [][]byte
+	for _, convertedObject := range review.Response.ConvertedObjects {
+		convertedObjects = append(convertedObjects, convertedObject.Raw)
+	}
+	return convertedObjects, nil
+}
+
+func gvkFor(t *testing.T, scheme *runtime.Scheme, object runtime.Object) schema.GroupVersionKind {
+	t.Helper()
+
+	gvks, _, err := scheme.ObjectKinds(object)
+	require.NoError(t, err)
+	require.Len(t, gvks, 1)
+	return gvks[0]
+}
+
+// strictDecode decodes the JSON into the object, failing on any field which the Go type of the object does not have.
+func strictDecode(t *testing.T, data []byte, into runtime.Object) {
+	t.Helper()
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	require.NoError(t, decoder.Decode(into), string(data))
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package crdconversion serves a conversion webhook, which the Kubernetes API server calls to convert Pinniped
+// custom resources between the versions of their API groups when the schemas of those versions are different.
+package crdconversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// Path is the path at which the webhook is served by the aggregated API server. The conversion webhook config of
+// the CustomResourceDefinitions must use this path.
+const Path = "/convert-pinniped-resources"
+
+// maxRequestBodySize is the maximum size of a ConversionReview. Lists are converted in a single review, so this is
+// much larger than the maximum size of an object in etcd.
+const maxRequestBodySize = 32 * 1024 * 1024
+
+// Converter converts objects of a kind between the versions of its API group.
+type Converter interface {
+	// Convert converts the object in place from the version of its apiVersion to the given version. The caller
+	// updates the apiVersion of the object afterwards.
+	Convert(object *unstructured.Unstructured, toVersion string) error
+}
+
+// ConverterFunc is a func which implements Converter.
+type ConverterFunc func(object *unstructured.Unstructured, toVersion string) error
+
+func (f ConverterFunc) Convert(object *unstructured.Unstructured, toVersion string) error {
+	return f(object, toVersion)
+}
+
+type handler struct {
+	converters map[schema.GroupKind]Converter
+}
+
+// NewHandler returns a handler which serves the conversion reviews of the Kubernetes API server. The objects are
+// converted by the converter of their kind. A review fails as a whole when any of its objects cannot be converted.
+func NewHandler(converters map[schema.GroupKind]Converter) http.Handler {
+	return &handler{converters: converters}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || contentType != "application/json" {
+		http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize+1))
+	if err != nil {
+		http.Error(w, "could not read request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestBodySize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var review apiextensionsv1.ConversionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "request body must be a ConversionReview with a request", http.StatusBadRequest)
+		return
+	}
+
+	response := &apiextensionsv1.ConversionResponse{UID: review.Request.UID}
+	convertedObjects, err := h.convert(review.Request)
+	if err != nil {
+		plog.DebugErr("conversion webhook could not convert a request", err,
+			"desiredAPIVersion", review.Request.DesiredAPIVersion,
+			"objects", len(review.Request.Objects),
+		)
+		response.Result = metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+		}
+	} else {
+		response.ConvertedObjects = convertedObjects
+		response.Result = metav1.Status{Status: metav1.StatusSuccess}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&apiextensionsv1.ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: apiextensionsv1.SchemeGroupVersion.String(), Kind: "ConversionReview"},
+		Response: response,
+	}); err != nil {
+		plog.Error("could not write conversion review response", err)
+	}
+}
+
+func (h *handler) convert(request *apiextensionsv1.ConversionRequest) ([]runtime.RawExtension, error) {
+	desiredGroupVersion, err := schema.ParseGroupVersion(request.DesiredAPIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid desiredAPIVersion: %w", err)
+	}
+
+	convertedObjects := make([]runtime.RawExtension, 0, len(request.Objects))
+	for _, rawObject := range request.Objects {
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(rawObject.Raw); err != nil {
+			return nil, fmt.Errorf("could not decode object: %w", err)
+		}
+
+		gvk := object.GroupVersionKind()
+		if gvk.Group != desiredGroupVersion.Group {
+			return nil, fmt.Errorf("cannot convert %s %q from %s to %s", gvk.Kind, object.GetName(), gvk.GroupVersion(), desiredGroupVersion)
+		}
+
+		if gvk.Version != desiredGroupVersion.Version {
+			converter, ok := h.converters[gvk.GroupKind()]
+			if !ok {
+				return nil, fmt.Errorf("no conversion for %s", gvk.GroupKind())
+			}
+			if err := converter.Convert(object, desiredGroupVersion.Version); err != nil {
+				return nil, fmt.Errorf("could not convert %s %q from %s to %s: %w", gvk.Kind, object.GetName(), gvk.GroupVersion(), desiredGroupVersion, err)
+			}
+			object.SetAPIVersion(desiredGroupVersion.String())
+		}
+
+		convertedObject, err := object.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("could not encode object: %w", err)
+		}
+		convertedObjects = append(convertedObjects, runtime.RawExtension{Raw: convertedObject})
+	}
+	return convertedObjects, nil
+}
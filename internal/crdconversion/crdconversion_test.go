@@ -0,0 +1,205 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crdconversion
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestHandler(t *testing.T) {
+	handler := NewHandler(map[schema.GroupKind]Converter{
+		{Group: "example.com", Kind: "Widget"}: ConverterFunc(func(object *unstructured.Unstructured, toVersion string) error {
+			switch toVersion {
+			case "v2":
+				size, _, _ := unstructured.NestedInt64(object.Object, "spec", "size")
+				if size > 10 {
+					return errors.New("spec.size must not be greater than 10")
+				}
+				unstructured.RemoveNestedField(object.Object, "spec", "size")
+				return unstructured.SetNestedField(object.Object, size*100, "spec", "sizeInCentimeters")
+			case "v1":
+				size, _, _ := unstructured.NestedInt64(object.Object, "spec", "sizeInCentimeters")
+				unstructured.RemoveNestedField(object.Object, "spec", "sizeInCentimeters")
+				return unstructured.SetNestedField(object.Object, size/100, "spec", "size")
+			default:
+				return errors.New("unknown version")
+			}
+		}),
+	})
+
+	newReview := func(desiredAPIVersion string, objects ...string) string {
+		request := &apiextensionsv1.ConversionRequest{UID: "some-uid", DesiredAPIVersion: desiredAPIVersion}
+		for _, object := range objects {
+			request.Objects = append(request.Objects, runtime.RawExtension{Raw: []byte(object)})
+		}
+		review, err := json.Marshal(&apiextensionsv1.ConversionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+			Request:  request,
+		})
+		require.NoError(t, err)
+		return string(review)
+	}
+
+	failure := func(message string) *apiextensionsv1.ConversionResponse {
+		return &apiextensionsv1.ConversionResponse{
+			UID:    "some-uid",
+			Result: metav1.Status{Status: metav1.StatusFailure, Message: message},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		contentType  string
+		body         string
+		wantStatus   int
+		wantBody     string
+		wantResponse *apiextensionsv1.ConversionResponse
+		wantObjects  []string
+	}{
+		{
+			name: "objects which need conversion",
+			body: newReview("example.com/v2",
+				`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"a"},"spec":{"size":5}}`,
+				`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"b"},"spec":{"size":7}}`,
+			),
+			wantStatus: http.StatusOK,
+			wantObjects: []string{
+				`{"apiVersion":"example.com/v2","kind":"Widget","metadata":{"name":"a"},"spec":{"sizeInCentimeters":500}}`,
+				`{"apiVersion":"example.com/v2","kind":"Widget","metadata":{"name":"b"},"spec":{"sizeInCentimeters":700}}`,
+			},
+		},
+		{
+			name: "objects which already have the desired version",
+			body: newReview("example.com/v1",
+				`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"a"},"spec":{"size":5}}`,
+				`{"apiVersion":"example.com/v2","kind":"Widget","metadata":{"name":"b"},"spec":{"sizeInCentimeters":700}}`,
+			),
+			wantStatus: http.StatusOK,
+			wantObjects: []string{
+				`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"a"},"spec":{"size":5}}`,
+				`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"b"},"spec":{"size":7}}`,
+			},
+		},
+		{
+			name:        "no objects",
+			body:        newReview("example.com/v1"),
+			wantStatus:  http.StatusOK,
+			wantObjects: []string{},
+		},
+		{
+			name: "object which cannot be converted",
+			body: newReview("example.com/v2",
+				`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"a"},"spec":{"size":5}}`,
+				`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"b"},"spec":{"size":11}}`,
+			),
+			wantStatus:   http.StatusOK,
+			wantResponse: failure(`could not convert Widget "b" from example.com/v1 to example.com/v2: spec.size must not be greater than 10`),
+		},
+		{
+			name:         "object of a kind without a converter",
+			body:         newReview("example.com/v2", `{"apiVersion":"example.com/v1","kind":"Gadget","metadata":{"name":"a"}}`),
+			wantStatus:   http.StatusOK,
+			wantResponse: failure("no conversion for Gadget.example.com"),
+		},
+		{
+			name:         "object of another group",
+			body:         newReview("example.com/v2", `{"apiVersion":"other.example.com/v1","kind":"Widget","metadata":{"name":"a"}}`),
+			wantStatus:   http.StatusOK,
+			wantResponse: failure(`cannot convert Widget "a" from other.example.com/v1 to example.com/v2`),
+		},
+		{
+			name:         "object which cannot be decoded",
+			body:         newReview("example.com/v2", `{"metadata":{"name":"a"}}`),
+			wantStatus:   http.StatusOK,
+			wantResponse: failure("could not decode object: Object 'Kind' is missing in '{\"metadata\":{\"name\":\"a\"}}'"),
+		},
+		{
+			name:         "invalid desired API version",
+			body:         newReview("example.com/v1/extra"),
+			wantStatus:   http.StatusOK,
+			wantResponse: failure("invalid desiredAPIVersion: unexpected GroupVersion string: example.com/v1/extra"),
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed\n",
+		},
+		{
+			name:        "wrong content type",
+			contentType: "text/plain",
+			body:        newReview("example.com/v1"),
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantBody:    "content type must be application/json\n",
+		},
+		{
+			name:       "body which is not a conversion review",
+			body:       `{"kind":"ConversionReview"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "request body must be a ConversionReview with a request\n",
+		},
+		{
+			name:       "body which is too large",
+			body:       strings.Repeat(" ", maxRequestBodySize+1),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   "request body too large\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+
+			req := httptest.NewRequest(method, Path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", contentType)
+			rsp := httptest.NewRecorder()
+			handler.ServeHTTP(rsp, req)
+
+			require.Equal(t, tt.wantStatus, rsp.Code)
+			if tt.wantResponse == nil && tt.wantObjects == nil {
+				require.Equal(t, tt.wantBody, rsp.Body.String())
+				return
+			}
+
+			require.Equal(t, "application/json", rsp.Header().Get("Content-Type"))
+			var review apiextensionsv1.ConversionReview
+			require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &review))
+			require.Equal(t, "apiextensions.k8s.io/v1", review.APIVersion)
+			require.Equal(t, "ConversionReview", review.Kind)
+			require.NotNil(t, review.Response)
+
+			if tt.wantResponse != nil {
+				require.Equal(t, tt.wantResponse, review.Response)
+				return
+			}
+
+			require.Equal(t, "some-uid", string(review.Response.UID))
+			require.Equal(t, metav1.Status{Status: metav1.StatusSuccess}, review.Response.Result)
+			require.Len(t, review.Response.ConvertedObjects, len(tt.wantObjects))
+			for i, wantObject := range tt.wantObjects {
+				require.JSONEq(t, wantObject, string(review.Response.ConvertedObjects[i].Raw))
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crud
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+const (
+	// compressionThreshold is the size of the serialized data of an item above which it is compressed. Most items are
+	// small enough to be stored as they are, which keeps them readable with kubectl, but the sessions of users with
+	// thousands of groups would otherwise exceed the size limit of Secrets.
+	compressionThreshold = 4 * 1024
+
+	// maxDecompressedSize is the largest size of the serialized data of an item which will be decompressed. The JSON
+	// of sessions compresses by a factor of about 10 to 20, so this leaves plenty of room for real items while refusing
+	// to exhaust the memory of the process on a decompression bomb.
+	maxDecompressedSize = 32 * MaxSecretSizeBytes
+
+	// encodingGzip is the encoding of data which was compressed with gzip.
+	encodingGzip = "gzip"
+)
+
+// maybeCompress compresses the data when it is larger than compressionThreshold, and returns its encoding, which is
+// empty when the data was not compressed.
+func maybeCompress(data []byte) ([]byte, string, error) {
+	if len(data) <= compressionThreshold {
+		return data, "", nil
+	}
+	if len(data) > maxDecompressedSize {
+		// it could not be decompressed again
+		return nil, "", fmt.Errorf("failed to compress data: data is larger than %d bytes", maxDecompressedSize)
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, "", fmt.Errorf("failed to compress data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to compress data: %w", err)
+	}
+	return buf.Bytes(), encodingGzip, nil
+}
+
+// decompress reverses maybeCompress for the given encoding.
+func decompress(data []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return data, nil
+	case encodingGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress data: %w", err)
+		}
+		decompressed, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress data: %w", err)
+		}
+		if len(decompressed) > maxDecompressedSize {
+			return nil, fmt.Errorf("failed to decompress data: data is larger than %d bytes", maxDecompressedSize)
+		}
+		return decompressed, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrSecretEncodingMismatch, encoding)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crud
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCompression(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test-ns"
+
+	type testJSON struct {
+		Groups []string
+	}
+
+	// thousands of groups, like the sessions of some Active Directory users
+	largeData := &testJSON{}
+	for i := 0; i < 25000; i++ {
+		largeData.Groups = append(largeData.Groups, "CN=some-group-"+strings.Repeat("x", i%10)+",OU=Groups,DC=example,DC=com")
+	}
+	largeJSON, err := json.Marshal(largeData)
+	require.NoError(t, err)
+	require.Greater(t, len(largeJSON), 1024*1024, "the test data should exceed the size limit of Secrets")
+
+	client := fake.NewSimpleClientset()
+	secrets := client.CoreV1().Secrets(namespace)
+	storage := NewSecretsBackend(secrets).New("compressed", time.Now, 0)
+
+	// small items are stored as they are
+	_, err = storage.Create(ctx, "some-small-signature", &testJSON{Groups: []string{"some-group"}}, nil, nil)
+	require.NoError(t, err)
+	secret, err := secrets.Get(ctx, storage.GetName("some-small-signature"), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, `{"Groups":["some-group"]}`, string(secret.Data[secretDataKey]))
+	require.NotContains(t, secret.Data, secretEncodingKey)
+
+	// large items are compressed
+	rv, err := storage.Create(ctx, "some-large-signature", largeData, nil, nil)
+	require.NoError(t, err)
+	secret, err = secrets.Get(ctx, storage.GetName("some-large-signature"), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "gzip", string(secret.Data[secretEncodingKey]))
+	require.Less(t, len(secret.Data[secretDataKey]), len(largeJSON)/10)
+
+	data := &testJSON{}
+	_, err = storage.Get(ctx, "some-large-signature", data)
+	require.NoError(t, err)
+	require.Equal(t, largeData, data)
+
+	data = &testJSON{}
+	require.NoError(t, FromSecret("compressed", secret, data))
+	require.Equal(t, largeData, data)
+
+	// updates which make an item small store it as it is again
+	_, err = storage.Update(ctx, "some-large-signature", rv, &testJSON{Groups: []string{"some-group"}})
+	require.NoError(t, err)
+	secret, err = secrets.Get(ctx, storage.GetName("some-large-signature"), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotContains(t, secret.Data, secretEncodingKey)
+
+	// large items are compressed before they are encrypted
+	encryptedStorage := NewEncryptedBackend(NewSecretsBackend(secrets), &fakeEncrypter{}).New("compressed", time.Now, 0)
+	_, err = encryptedStorage.Create(ctx, "some-encrypted-signature", largeData, nil, nil)
+	require.NoError(t, err)
+	secret, err = secrets.Get(ctx, storage.GetName("some-encrypted-signature"), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Less(t, len(secret.Data[secretDataKey]), len(largeJSON)/10)
+
+	data = &testJSON{}
+	_, err = encryptedStorage.Get(ctx, "some-encrypted-signature", data)
+	require.NoError(t, err)
+	require.Equal(t, largeData, data)
+
+	// data with an unknown encoding cannot be read
+	secret.Data[secretEncodingKey] = []byte("some-encoding")
+	require.EqualError(t,
+		FromSecret("compressed", secret, &testJSON{}),
+		`failed to decode compressed: secret storage data has unknown encoding: "some-encoding"`,
+	)
+	secret.Data[secretEncodingKey] = []byte("gzip")
+	secret.Data[secretDataKey] = []byte("not gzip")
+	require.EqualError(t,
+		FromSecret("compressed", secret, &testJSON{}),
+		"failed to decode compressed: failed to decompress data: unexpected EOF",
+	)
+}
+
+func TestDecompressionLimit(t *testing.T) {
+	gzipped := func(t *testing.T, size int) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(make([]byte, size))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	// data at the limit can be decompressed
+	decompressed, err := decompress(gzipped(t, maxDecompressedSize), encodingGzip)
+	require.NoError(t, err)
+	require.Len(t, decompressed, maxDecompressedSize)
+
+	// a decompression bomb, which is tiny but decompresses to more than the limit, is rejected
+	bomb := gzipped(t, maxDecompressedSize+1)
+	require.Less(t, len(bomb), 1024*1024, "the decompression bomb should fit into a Secret")
+	_, err = decompress(bomb, encodingGzip)
+	require.EqualError(t, err, "failed to decompress data: data is larger than 33554432 bytes")
+
+	// data which could not be decompressed again is not compressed
+	_, _, err = maybeCompress(make([]byte, maxDecompressedSize+1))
+	require.EqualError(t, err, "failed to compress data: data is larger than 33554432 bytes")
+}
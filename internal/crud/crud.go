@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package crud
@@ -14,9 +14,11 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 
 	"go.pinniped.dev/internal/constable"
 )
@@ -28,15 +30,23 @@ const (
 	SecretLifetimeAnnotationKey        = "storage.pinniped.dev/garbage-collect-after"
 	SecretLifetimeAnnotationDateFormat = time.RFC3339
 
+	// MaxSecretSizeBytes is the maximum total size of the data of a Secret, which is enforced by Kubernetes.
+	MaxSecretSizeBytes = 1024 * 1024
+
 	secretNameFormat = "pinniped-storage-%s-%s"
 	secretTypeFormat = "storage.pinniped.dev/%s"
 	secretVersion    = "1"
 	secretDataKey    = "pinniped-storage-data"
 	secretVersionKey = "pinniped-storage-version"
 
-	ErrSecretTypeMismatch    = constable.Error("secret storage data has incorrect type")
-	ErrSecretLabelMismatch   = constable.Error("secret storage data has incorrect label")
-	ErrSecretVersionMismatch = constable.Error("secret storage data has incorrect version")
+	// secretEncodingKey holds the encoding of the data of Secrets whose data was compressed. Secrets without it hold
+	// the serialized data as it is.
+	secretEncodingKey = "pinniped-storage-encoding"
+
+	ErrSecretTypeMismatch     = constable.Error("secret storage data has incorrect type")
+	ErrSecretLabelMismatch    = constable.Error("secret storage data has incorrect label")
+	ErrSecretVersionMismatch  = constable.Error("secret storage data has incorrect version")
+	ErrSecretEncodingMismatch = constable.Error("secret storage data has unknown encoding")
 )
 
 type Storage interface {
@@ -50,6 +60,38 @@ type Storage interface {
 
 type JSON interface{} // document that we need valid JSON types
 
+// Backend creates a Storage for each type of resource. The default backend keeps each item in its own
+// Kubernetes Secret, but the Supervisor may be configured to keep its session storage somewhere else.
+type Backend interface {
+	New(resource string, clock func() time.Time, lifetime time.Duration) Storage
+}
+
+// NewSecretsBackend returns a Backend which stores each item as a Secret using the given client.
+func NewSecretsBackend(secrets corev1client.SecretInterface) Backend {
+	return &secretsBackend{secrets: secrets}
+}
+
+// NewCachedSecretsBackend is like NewSecretsBackend, except that reads are served by the given lister, e.g. of a
+// Secret informer, instead of by the Kubernetes API server. The lister must list the Secrets in the namespace of
+// the client. Items which are not in the cache yet, e.g. because they were created moments ago by another pod, are
+// read from the Kubernetes API server. A cached item may be a little out of date, but every write is still checked
+// against the resource version of the item, so a stale read makes a write fail with a conflict instead of losing
+// another write.
+func NewCachedSecretsBackend(secrets corev1client.SecretInterface, lister corev1listers.SecretNamespaceLister) Backend {
+	return &secretsBackend{secrets: secrets, lister: lister}
+}
+
+type secretsBackend struct {
+	secrets corev1client.SecretInterface
+	lister  corev1listers.SecretNamespaceLister
+}
+
+func (b *secretsBackend) New(resource string, clock func() time.Time, lifetime time.Duration) Storage {
+	storage := New(resource, b.secrets, clock, lifetime).(*secretsStorage)
+	storage.lister = b.lister
+	return storage
+}
+
 func New(resource string, secrets corev1client.SecretInterface, clock func() time.Time, lifetime time.Duration) Storage {
 	return &secretsStorage{
 		resource:   resource,
@@ -64,6 +106,7 @@ type secretsStorage struct {
 	resource   string
 	secretType corev1.SecretType
 	secrets    corev1client.SecretInterface
+	lister     corev1listers.SecretNamespaceLister // optional, reads are served by the Kubernetes API server when nil
 	clock      func() time.Time
 	lifetime   time.Duration
 }
@@ -81,7 +124,7 @@ func (s *secretsStorage) Create(ctx context.Context, signature string, data JSON
 }
 
 func (s *secretsStorage) Get(ctx context.Context, signature string, data JSON) (string, error) {
-	secret, err := s.secrets.Get(ctx, s.GetName(signature), metav1.GetOptions{})
+	secret, err := s.getSecret(ctx, s.GetName(signature))
 	if err != nil {
 		return "", fmt.Errorf("failed to get %s for signature %s: %w", s.resource, signature, err)
 	}
@@ -101,7 +144,7 @@ func (s *secretsStorage) Update(ctx context.Context, signature, resourceVersion
 		return "", err
 	}
 
-	oldSecret, err := s.secrets.Get(ctx, secret.Name, metav1.GetOptions{})
+	oldSecret, err := s.getSecret(ctx, secret.Name)
 	if err != nil {
 		return "", fmt.Errorf("failed to get %s for signature %s: %w", s.resource, signature, err)
 	}
@@ -125,6 +168,8 @@ func (s *secretsStorage) Delete(ctx context.Context, signature string) error {
 	return nil
 }
 
+// DeleteByLabel always lists the Secrets on the Kubernetes API server, even when there is a cache, because it is used
+// to revoke sessions and must not miss Secrets which were just created, e.g. by a refresh on another pod.
 func (s *secretsStorage) DeleteByLabel(ctx context.Context, labelName string, labelValue string) error {
 	list, err := s.secrets.List(ctx, metav1.ListOptions{
 		LabelSelector: labels.Set{
@@ -148,13 +193,32 @@ func (s *secretsStorage) DeleteByLabel(ctx context.Context, labelName string, la
 	return nil
 }
 
+// getSecret reads the Secret from the cache when there is one, falling back to the Kubernetes API server when the
+// Secret is not in the cache.
+func (s *secretsStorage) getSecret(ctx context.Context, name string) (*corev1.Secret, error) {
+	if s.lister != nil {
+		secret, err := s.lister.Get(name)
+		if err == nil {
+			return secret.DeepCopy(), nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return s.secrets.Get(ctx, name, metav1.GetOptions{})
+}
+
 // FromSecret is similar to Get, but for when you already have a Secret in hand, e.g. from an informer.
 // It validates and unmarshals the Secret. The data parameter is filled in as the result.
 func FromSecret(resource string, secret *corev1.Secret, data JSON) error {
 	if err := validateSecret(resource, secret); err != nil {
 		return err
 	}
-	if err := json.Unmarshal(secret.Data[secretDataKey], data); err != nil {
+	buf, err := decompress(secret.Data[secretDataKey], string(secret.Data[secretEncodingKey]))
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", resource, err)
+	}
+	if err := json.Unmarshal(buf, data); err != nil {
 		return fmt.Errorf("failed to decode %s: %w", resource, err)
 	}
 	return nil
@@ -182,11 +246,16 @@ func validateSecret(resource string, secret *corev1.Secret) error {
 var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
 
 func (s *secretsStorage) GetName(signature string) string {
+	return NameForSignature(s.resource, signature)
+}
+
+// NameForSignature returns the name under which the item with the given signature is stored for the given resource.
+func NameForSignature(resource, signature string) string {
 	// try to decode base64 signatures to prevent double encoding of binary data
 	signatureBytes := maybeBase64Decode(signature)
 	// lower case base32 encoding insures that our secret name is valid per ValidateSecretName in k/k
 	signatureAsValidName := strings.ToLower(b32.EncodeToString(signatureBytes))
-	return fmt.Sprintf(secretNameFormat, s.resource, signatureAsValidName)
+	return fmt.Sprintf(secretNameFormat, resource, signatureAsValidName)
 }
 
 func (s *secretsStorage) toSecret(signature, resourceVersion string, data JSON, additionalLabels map[string]string, ownerReferences []metav1.OwnerReference) (*corev1.Secret, error) {
@@ -194,6 +263,10 @@ func (s *secretsStorage) toSecret(signature, resourceVersion string, data JSON,
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode secret data for %s: %w", s.GetName(signature), err)
 	}
+	buf, encoding, err := maybeCompress(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode secret data for %s: %w", s.GetName(signature), err)
+	}
 
 	labelsToAdd := make(map[string]string, len(additionalLabels)+1)
 	for labelName, labelValue := range additionalLabels {
@@ -208,6 +281,14 @@ func (s *secretsStorage) toSecret(signature, resourceVersion string, data JSON,
 		}
 	}
 
+	secretData := map[string][]byte{
+		secretDataKey:    buf,
+		secretVersionKey: []byte(secretVersion),
+	}
+	if encoding != "" {
+		secretData[secretEncodingKey] = []byte(encoding)
+	}
+
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            s.GetName(signature),
@@ -216,10 +297,7 @@ func (s *secretsStorage) toSecret(signature, resourceVersion string, data JSON,
 			Annotations:     annotations,
 			OwnerReferences: ownerReferences,
 		},
-		Data: map[string][]byte{
-			secretDataKey:    buf,
-			secretVersionKey: []byte(secretVersion),
-		},
+		Data: secretData,
 		Type: s.secretType,
 	}, nil
 }
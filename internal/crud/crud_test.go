@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package crud
@@ -21,7 +21,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	coretesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 	clocktesting "k8s.io/utils/clock/testing"
 )
 
@@ -1537,3 +1539,59 @@ func TestFromSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestCachedSecretsBackend(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test-ns"
+
+	type testJSON struct {
+		Data string
+	}
+
+	client := fake.NewSimpleClientset()
+	secrets := client.CoreV1().Secrets(namespace)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lister := corev1listers.NewSecretLister(indexer).Secrets(namespace)
+
+	storage := NewCachedSecretsBackend(secrets, lister).New("cached", time.Now, 0)
+
+	rv, err := storage.Create(ctx, "some-signature", &testJSON{Data: "created"}, nil, nil)
+	require.NoError(t, err)
+	client.ClearActions()
+
+	// the Secret is not in the cache yet, so it is read from the API server
+	var data testJSON
+	gotRV, err := storage.Get(ctx, "some-signature", &data)
+	require.NoError(t, err)
+	require.Equal(t, rv, gotRV)
+	require.Equal(t, testJSON{Data: "created"}, data)
+	require.Len(t, client.Actions(), 1)
+	require.True(t, client.Actions()[0].Matches("get", "secrets"))
+
+	// once the Secret is in the cache, it is read from the cache
+	secret, err := secrets.Get(ctx, storage.GetName("some-signature"), metav1.GetOptions{})
+	require.NoError(t, err)
+	secret.Data[secretDataKey] = []byte(`{"Data":"cached"}`)
+	require.NoError(t, indexer.Add(secret))
+	client.ClearActions()
+
+	gotRV, err = storage.Get(ctx, "some-signature", &data)
+	require.NoError(t, err)
+	require.Equal(t, rv, gotRV)
+	require.Equal(t, testJSON{Data: "cached"}, data)
+	require.Empty(t, client.Actions())
+
+	// updates only write to the API server
+	_, err = storage.Update(ctx, "some-signature", rv, &testJSON{Data: "updated"})
+	require.NoError(t, err)
+	require.Len(t, client.Actions(), 1)
+	require.True(t, client.Actions()[0].Matches("update", "secrets"))
+
+	// deletes by label always list the Secrets on the API server
+	client.ClearActions()
+	require.NoError(t, indexer.Delete(secret))
+	require.NoError(t, storage.DeleteByLabel(ctx, SecretLabelKey, "cached"))
+	require.Len(t, client.Actions(), 2)
+	require.True(t, client.Actions()[0].Matches("list", "secrets"))
+	require.True(t, client.Actions()[1].Matches("delete", "secrets"))
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+const ErrNoDecrypter = constable.Error("stored data is encrypted, but encryption is not enabled")
+
+// Encrypter encrypts the data of the stored items, e.g. with envelope encryption. The associated data is the name of
+// the item, so that the encrypted data of an item cannot be copied to another item.
+type Encrypter interface {
+	Encrypt(plaintext, associatedData []byte) ([]byte, error)
+	Decrypt(ciphertext, associatedData []byte) ([]byte, error)
+}
+
+// encryptedData is what is stored instead of the data of an item when the data is encrypted. Large data is compressed
+// before it is encrypted, since encrypted data cannot be compressed anymore.
+type encryptedData struct {
+	EncryptedData []byte `json:"pinnipedEncryptedData"`
+	Encoding      string `json:"pinnipedEncoding,omitempty"`
+}
+
+// NewEncryptedBackend returns a Backend whose items are encrypted with the given Encrypter before they are stored
+// by the given Backend. Items which were stored unencrypted, i.e. before encryption was enabled, can still be read.
+func NewEncryptedBackend(backend Backend, encrypter Encrypter) Backend {
+	return &encryptedBackend{backend: backend, encrypter: encrypter}
+}
+
+type encryptedBackend struct {
+	backend   Backend
+	encrypter Encrypter
+}
+
+func (b *encryptedBackend) New(resource string, clock func() time.Time, lifetime time.Duration) Storage {
+	return &encryptedStorage{
+		Storage:   b.backend.New(resource, clock, lifetime),
+		resource:  resource,
+		encrypter: b.encrypter,
+	}
+}
+
+// encryptedStorage only changes how the data is read and written. Everything else, e.g. deletions, is delegated.
+type encryptedStorage struct {
+	Storage
+	resource  string
+	encrypter Encrypter
+}
+
+func (s *encryptedStorage) Create(ctx context.Context, signature string, data JSON, additionalLabels map[string]string, ownerReferences []metav1.OwnerReference) (string, error) {
+	encrypted, err := s.encrypt(signature, data)
+	if err != nil {
+		return "", err
+	}
+	return s.Storage.Create(ctx, signature, encrypted, additionalLabels, ownerReferences)
+}
+
+func (s *encryptedStorage) Get(ctx context.Context, signature string, data JSON) (string, error) {
+	var raw json.RawMessage
+	resourceVersion, err := s.Storage.Get(ctx, signature, &raw)
+	if err != nil {
+		return "", err
+	}
+	if err := decode(s.resource, s.encrypter, s.GetName(signature), raw, data); err != nil {
+		return "", fmt.Errorf("error during get for signature %s: %w", signature, err)
+	}
+	return resourceVersion, nil
+}
+
+func (s *encryptedStorage) Update(ctx context.Context, signature, resourceVersion string, data JSON) (string, error) {
+	encrypted, err := s.encrypt(signature, data)
+	if err != nil {
+		return "", err
+	}
+	return s.Storage.Update(ctx, signature, resourceVersion, encrypted)
+}
+
+func (s *encryptedStorage) encrypt(signature string, data JSON) (*encryptedData, error) {
+	name := s.GetName(signature)
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode secret data for %s: %w", name, err)
+	}
+	buf, encoding, err := maybeCompress(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode secret data for %s: %w", name, err)
+	}
+	ciphertext, err := s.encrypter.Encrypt(buf, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret data for %s: %w", name, err)
+	}
+	return &encryptedData{EncryptedData: ciphertext, Encoding: encoding}, nil
+}
+
+// FromEncryptedSecret is like FromSecret, but also reads Secrets whose data was encrypted by a Backend which was
+// returned by NewEncryptedBackend. The encrypter may be nil when encryption is not enabled.
+func FromEncryptedSecret(resource string, secret *corev1.Secret, data JSON, encrypter Encrypter) error {
+	var raw json.RawMessage
+	if err := FromSecret(resource, secret, &raw); err != nil {
+		return err
+	}
+	return decode(resource, encrypter, secret.Name, raw, data)
+}
+
+// decode unmarshals the raw data of the item with the given name, decrypting it first when it is encrypted.
+func decode(resource string, encrypter Encrypter, name string, raw json.RawMessage, data JSON) error {
+	encrypted := &encryptedData{}
+	if err := json.Unmarshal(raw, encrypted); err == nil && encrypted.EncryptedData != nil {
+		if encrypter == nil {
+			return ErrNoDecrypter
+		}
+		plaintext, err := encrypter.Decrypt(encrypted.EncryptedData, []byte(name))
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", resource, err)
+		}
+		if raw, err = decompress(plaintext, encrypted.Encoding); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", resource, err)
+		}
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", resource, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package crud
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeEncrypter "encrypts" by prefixing the plaintext with the associated data, so that the tests can tell which
+// associated data was used.
+type fakeEncrypter struct {
+	encryptErr error
+}
+
+func (e *fakeEncrypter) Encrypt(plaintext, associatedData []byte) ([]byte, error) {
+	if e.encryptErr != nil {
+		return nil, e.encryptErr
+	}
+	return append(append(append([]byte(nil), associatedData...), '|'), plaintext...), nil
+}
+
+func (e *fakeEncrypter) Decrypt(ciphertext, associatedData []byte) ([]byte, error) {
+	prefix := append(append([]byte(nil), associatedData...), '|')
+	if !bytes.HasPrefix(ciphertext, prefix) {
+		return nil, errors.New("some decryption error")
+	}
+	return ciphertext[len(prefix):], nil
+}
+
+func TestEncryptedBackend(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test-ns"
+
+	type testJSON struct {
+		Data string
+	}
+
+	client := fake.NewSimpleClientset()
+	secrets := client.CoreV1().Secrets(namespace)
+	plaintextStorage := NewSecretsBackend(secrets).New("encrypted", time.Now, 0)
+	storage := NewEncryptedBackend(NewSecretsBackend(secrets), &fakeEncrypter{}).New("encrypted", time.Now, 0)
+
+	// items are encrypted with their name as the associated data
+	rv, err := storage.Create(ctx, "some-signature", &testJSON{Data: "created"}, nil, nil)
+	require.NoError(t, err)
+	name := storage.GetName("some-signature")
+	secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{"pinnipedEncryptedData":"`+base64.StdEncoding.EncodeToString([]byte(name+`|{"Data":"created"}`))+`"}`,
+		string(secret.Data[secretDataKey]),
+	)
+
+	var data testJSON
+	gotRV, err := storage.Get(ctx, "some-signature", &data)
+	require.NoError(t, err)
+	require.Equal(t, rv, gotRV)
+	require.Equal(t, testJSON{Data: "created"}, data)
+
+	// encrypted items can be read from Secrets
+	data = testJSON{}
+	require.NoError(t, FromEncryptedSecret("encrypted", secret, &data, &fakeEncrypter{}))
+	require.Equal(t, testJSON{Data: "created"}, data)
+	require.ErrorIs(t, FromEncryptedSecret("encrypted", secret, &data, nil), ErrNoDecrypter)
+
+	// but not with the data of another item
+	secret.Name = "some-other-name"
+	require.EqualError(t,
+		FromEncryptedSecret("encrypted", secret, &data, &fakeEncrypter{}),
+		"failed to decrypt encrypted: some decryption error",
+	)
+
+	// updates are encrypted too
+	_, err = storage.Update(ctx, "some-signature", rv, &testJSON{Data: "updated"})
+	require.NoError(t, err)
+	_, err = storage.Get(ctx, "some-signature", &data)
+	require.NoError(t, err)
+	require.Equal(t, testJSON{Data: "updated"}, data)
+
+	// items which were stored before encryption was enabled can still be read
+	_, err = plaintextStorage.Create(ctx, "some-plaintext-signature", &testJSON{Data: "plaintext"}, nil, nil)
+	require.NoError(t, err)
+	_, err = storage.Get(ctx, "some-plaintext-signature", &data)
+	require.NoError(t, err)
+	require.Equal(t, testJSON{Data: "plaintext"}, data)
+
+	// deletes are delegated
+	require.NoError(t, storage.Delete(ctx, "some-plaintext-signature"))
+	_, err = secrets.Get(ctx, storage.GetName("some-plaintext-signature"), metav1.GetOptions{})
+	require.Error(t, err)
+
+	// encryption errors are returned before anything is stored
+	client.ClearActions()
+	failingStorage := NewEncryptedBackend(NewSecretsBackend(secrets), &fakeEncrypter{encryptErr: errors.New("some encryption error")}).New("encrypted", time.Now, 0)
+	_, err = failingStorage.Create(ctx, "some-new-signature", &testJSON{Data: "created"}, nil, nil)
+	require.EqualError(t, err, "failed to encrypt secret data for "+failingStorage.GetName("some-new-signature")+": some encryption error")
+	require.Empty(t, client.Actions())
+}
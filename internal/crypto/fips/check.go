@@ -0,0 +1,85 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fips
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// Check logs which crypto module is used and whether it runs in FIPS mode, and verifies that the TLS configs of the
+// servers, keyed by server name, only use FIPS-approved settings. When enforce is true, it returns an error which
+// lists every problem, so that the server refuses to start. Otherwise, the problems are only logged as warnings.
+func Check(enforce bool, serverTLSConfigs map[string]*tls.Config) error {
+	active := Active()
+	plog.Info("crypto module", "module", Module, "fipsMode", active, "enforceFIPS", enforce)
+
+	problems := checkTLSConfigs(serverTLSConfigs)
+	if !active {
+		problems = append([]string{fmt.Sprintf("crypto module %s is not running in FIPS mode", Module)}, problems...)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if enforce {
+		return fmt.Errorf("FIPS mode is enforced, but %s", strings.Join(problems, ", "))
+	}
+
+	for _, problem := range problems {
+		plog.Warning("not FIPS compliant", "problem", problem)
+	}
+	return nil
+}
+
+func checkTLSConfigs(serverTLSConfigs map[string]*tls.Config) []string {
+	names := make([]string, 0, len(serverTLSConfigs))
+	for name := range serverTLSConfigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	approved := approvedCipherSuites()
+
+	var problems []string
+	for _, name := range names {
+		c := serverTLSConfigs[name]
+
+		// the TLS 1.3 cipher suites are not configurable in Go, and some of them are not approved
+		if c.MaxVersion == 0 || c.MaxVersion > tls.VersionTLS12 {
+			problems = append(problems, fmt.Sprintf("%s would serve TLS 1.3, whose cipher suites are not configurable", name))
+		}
+
+		if c.MinVersion < tls.VersionTLS13 {
+			for _, id := range c.CipherSuites {
+				if !approved[id] {
+					problems = append(problems, fmt.Sprintf("%s would serve cipher suite %s", name, tls.CipherSuiteName(id)))
+				}
+			}
+		}
+
+		for _, curve := range c.CurvePreferences {
+			if curve == tls.X25519 {
+				problems = append(problems, fmt.Sprintf("%s would serve curve %s", name, curve))
+			}
+		}
+	}
+	return problems
+}
+
+func approvedCipherSuites() map[uint16]bool {
+	return map[uint16]bool{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: true,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   true,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: true,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   true,
+		tls.TLS_RSA_WITH_AES_128_GCM_SHA256:         true,
+		tls.TLS_RSA_WITH_AES_256_GCM_SHA384:         true,
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !fips_strict
+// +build !fips_strict
+
+package fips
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	approved := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_AES_256_GCM_SHA384},
+	}
+	notApproved := &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CipherSuites:     []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256},
+		CurvePreferences: []tls.CurveID{tls.CurveP256, tls.X25519},
+	}
+
+	require.Empty(t, checkTLSConfigs(map[string]*tls.Config{"some-server": approved}))
+	require.Equal(t, []string{
+		"other-server would serve TLS 1.3, whose cipher suites are not configurable",
+		"other-server would serve cipher suite TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+		"other-server would serve curve X25519",
+	}, checkTLSConfigs(map[string]*tls.Config{"some-server": approved, "other-server": notApproved}))
+
+	// the Go standard library never runs in FIPS mode
+	require.NoError(t, Check(false, map[string]*tls.Config{"some-server": approved}))
+	require.EqualError(t, Check(true, map[string]*tls.Config{"some-server": approved}),
+		"FIPS mode is enforced, but crypto module Go standard library is not running in FIPS mode")
+	require.EqualError(t, Check(true, map[string]*tls.Config{"other-server": notApproved}),
+		"FIPS mode is enforced, but crypto module Go standard library is not running in FIPS mode, "+
+			"other-server would serve TLS 1.3, whose cipher suites are not configurable, "+
+			"other-server would serve cipher suite TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256, "+
+			"other-server would serve curve X25519")
+}
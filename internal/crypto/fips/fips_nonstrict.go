@@ -0,0 +1,18 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !fips_strict
+// +build !fips_strict
+
+package fips
+
+// Enabled is true when Pinniped is compiled with fips_strict.
+const Enabled = false
+
+// Module is the name of the crypto module which Pinniped is compiled with.
+const Module = "Go standard library"
+
+// Active returns true when the crypto module runs in FIPS mode, which the Go standard library never does.
+func Active() bool {
+	return false
+}
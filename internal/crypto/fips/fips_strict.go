@@ -7,6 +7,19 @@
 package fips
 
 import (
-	"C"                     // explicitly import cgo so that runtime/cgo gets linked into the kube-cert-agent
+	"C" // explicitly import cgo so that runtime/cgo gets linked into the kube-cert-agent
+	"crypto/boring"
 	_ "crypto/tls/fipsonly" // restricts all TLS configuration to FIPS-approved settings.
 )
+
+// Enabled is true when Pinniped is compiled with fips_strict.
+const Enabled = true
+
+// Module is the name of the crypto module which Pinniped is compiled with.
+const Module = "BoringCrypto"
+
+// Active returns true when the crypto module runs in FIPS mode, i.e. when BoringCrypto is actually used
+// rather than the Go standard library, e.g. because Pinniped was not compiled with GOEXPERIMENT=boringcrypto.
+func Active() bool {
+	return boring.Enabled()
+}
@@ -12,19 +12,17 @@ package ptls
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 
-	"k8s.io/apiserver/pkg/server/options"
-
 	// Cause fipsonly tls mode with this side effect import.
 	_ "go.pinniped.dev/internal/crypto/fips"
 	"go.pinniped.dev/internal/plog"
 )
 
 // Always use TLS 1.2 for FIPs
-const secureServingOptionsMinTLSVersion = "VersionTLS12"
 const SecureTLSConfigMinTLSVersion = tls.VersionTLS12
 
 func init() {
@@ -75,6 +73,28 @@ func DefaultLDAP(rootCAs *x509.CertPool) *tls.Config {
 	return Default(rootCAs)
 }
 
-func secureServing(opts *options.SecureServingOptionsWithLoopback) {
-	defaultServing(opts)
+// validateProfile checks that a TLS profile only uses FIPS-approved settings, since any other settings would
+// cause all handshakes to fail in fips only mode.
+func validateProfile(p *profile) error {
+	if p.minVersion > SecureTLSConfigMinTLSVersion {
+		return fmt.Errorf("minVersion TLS1.3 is not supported in fips only mode")
+	}
+
+	approved := map[uint16]bool{}
+	for _, id := range Default(nil).CipherSuites {
+		approved[id] = true
+	}
+	for _, id := range p.cipherSuites {
+		if !approved[id] {
+			return fmt.Errorf("cipher suite %q is not supported in fips only mode", tls.CipherSuiteName(id))
+		}
+	}
+
+	for _, curve := range p.curvePreferences {
+		if curve == tls.X25519 {
+			return fmt.Errorf("curve %q is not supported in fips only mode", curve)
+		}
+	}
+
+	return nil
 }
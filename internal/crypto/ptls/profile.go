@@ -0,0 +1,144 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ptls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// ProfileSpec configures the TLS settings of all servers of a Pinniped component, i.e. the aggregated API servers,
+// the impersonation proxy and the HTTPS listeners of the Supervisor. Each field which is not set keeps the defaults
+// of each server, which e.g. allow TLS 1.2 for the impersonation proxy since any client may connect to it, but
+// require TLS 1.3 for the aggregated API servers since their only client is the Kubernetes API server.
+type ProfileSpec struct {
+	// MinVersion is the minimum TLS version, either TLS1.2 or TLS1.3.
+	MinVersion string `json:"minVersion,omitempty"`
+
+	// CipherSuites are the names of the allowed TLS 1.2 cipher suites, e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	// as listed by tls.CipherSuites. TLS 1.3 cipher suites are not configurable.
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+
+	// CurvePreferences are the names of the elliptic curves used in ECDHE handshakes in preference order, i.e.
+	// X25519, CurveP256, CurveP384 or CurveP521. Only the HTTPS listeners of the Supervisor support this setting,
+	// since the servers which are built on k8s.io/apiserver, i.e. the aggregated API servers and the impersonation
+	// proxy, always use the defaults of Go.
+	CurvePreferences []string `json:"curvePreferences,omitempty"`
+}
+
+type profile struct {
+	minVersion       uint16
+	cipherSuites     []uint16
+	curvePreferences []tls.CurveID
+}
+
+// Validate checks that the profile only uses known TLS versions, cipher suites and curves.
+func (s ProfileSpec) Validate() error {
+	_, err := s.parse()
+	return err
+}
+
+// ConfigFunc returns a ConfigFunc which returns the TLS configs of the given defaults with the settings of the
+// profile applied to them.
+func (s ProfileSpec) ConfigFunc(defaults ConfigFunc) (ConfigFunc, error) {
+	p, err := s.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(rootCAs *x509.CertPool) *tls.Config {
+		c := defaults(rootCAs)
+		defaultMinVersion := c.MinVersion
+
+		if p.minVersion != 0 {
+			c.MinVersion = p.minVersion
+		}
+
+		switch {
+		case len(p.cipherSuites) != 0:
+			c.CipherSuites = p.cipherSuites
+		case c.MinVersion < tls.VersionTLS13 && defaultMinVersion == tls.VersionTLS13:
+			// the defaults only list TLS 1.3 cipher suites, which cannot be used with older versions
+			c.CipherSuites = Default(nil).CipherSuites
+		}
+
+		if len(p.curvePreferences) != 0 {
+			c.CurvePreferences = p.curvePreferences
+		}
+
+		return c
+	}, nil
+}
+
+func (s ProfileSpec) parse() (*profile, error) {
+	p := &profile{}
+
+	switch s.MinVersion {
+	case "":
+	case "TLS1.2":
+		p.minVersion = tls.VersionTLS12
+	case "TLS1.3":
+		p.minVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("minVersion %q is not supported, must be TLS1.2 or TLS1.3", s.MinVersion)
+	}
+
+	if len(s.CipherSuites) != 0 && p.minVersion == tls.VersionTLS13 {
+		return nil, fmt.Errorf("cipherSuites cannot be configured when minVersion is TLS1.3")
+	}
+
+	for _, name := range s.CipherSuites {
+		id, err := cipherSuiteByName(name)
+		if err != nil {
+			return nil, err
+		}
+		p.cipherSuites = append(p.cipherSuites, id)
+	}
+
+	for _, name := range s.CurvePreferences {
+		id, err := curveByName(name)
+		if err != nil {
+			return nil, err
+		}
+		p.curvePreferences = append(p.curvePreferences, id)
+	}
+
+	if err := validateProfile(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func cipherSuiteByName(name string) (uint16, error) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name != name {
+			continue
+		}
+		for _, version := range suite.SupportedVersions {
+			if version == tls.VersionTLS13 {
+				return 0, fmt.Errorf("cipher suite %q is a TLS 1.3 cipher suite, which cannot be configured", name)
+			}
+		}
+		return suite.ID, nil
+	}
+
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.Name == name {
+			return 0, fmt.Errorf("cipher suite %q is insecure", name)
+		}
+	}
+
+	return 0, fmt.Errorf("cipher suite %q is not supported", name)
+}
+
+func curveByName(name string) (tls.CurveID, error) {
+	for _, curve := range []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521} {
+		if curve.String() == name {
+			return curve, nil
+		}
+	}
+	return 0, fmt.Errorf("curve %q is not supported, must be one of X25519, CurveP256, CurveP384 or CurveP521", name)
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !fips_strict
+// +build !fips_strict
+
+package ptls
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/server/options"
+)
+
+func TestProfileSpec(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		spec     ProfileSpec
+		defaults ConfigFunc
+		want     func(c *tls.Config)
+		wantErr  string
+	}{
+		{
+			name:     "empty profile keeps the defaults",
+			defaults: Secure,
+			want:     func(c *tls.Config) {},
+		},
+		{
+			name:     "min version",
+			spec:     ProfileSpec{MinVersion: "TLS1.3"},
+			defaults: Default,
+			want: func(c *tls.Config) {
+				c.MinVersion = tls.VersionTLS13
+			},
+		},
+		{
+			name:     "lower min version than the defaults uses the default TLS 1.2 cipher suites",
+			spec:     ProfileSpec{MinVersion: "TLS1.2"},
+			defaults: Secure,
+			want: func(c *tls.Config) {
+				c.MinVersion = tls.VersionTLS12
+				c.CipherSuites = Default(nil).CipherSuites
+			},
+		},
+		{
+			name: "cipher suites and curves",
+			spec: ProfileSpec{
+				MinVersion:       "TLS1.2",
+				CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+				CurvePreferences: []string{"CurveP384", "CurveP256"},
+			},
+			defaults: Secure,
+			want: func(c *tls.Config) {
+				c.MinVersion = tls.VersionTLS12
+				c.CipherSuites = []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}
+				c.CurvePreferences = []tls.CurveID{tls.CurveP384, tls.CurveP256}
+			},
+		},
+		{
+			name:    "unknown min version",
+			spec:    ProfileSpec{MinVersion: "TLS1.1"},
+			wantErr: `minVersion "TLS1.1" is not supported, must be TLS1.2 or TLS1.3`,
+		},
+		{
+			name:    "cipher suites with TLS 1.3",
+			spec:    ProfileSpec{MinVersion: "TLS1.3", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}},
+			wantErr: "cipherSuites cannot be configured when minVersion is TLS1.3",
+		},
+		{
+			name:    "TLS 1.3 cipher suite",
+			spec:    ProfileSpec{CipherSuites: []string{"TLS_AES_128_GCM_SHA256"}},
+			wantErr: `cipher suite "TLS_AES_128_GCM_SHA256" is a TLS 1.3 cipher suite, which cannot be configured`,
+		},
+		{
+			name:    "insecure cipher suite",
+			spec:    ProfileSpec{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			wantErr: `cipher suite "TLS_RSA_WITH_RC4_128_SHA" is insecure`,
+		},
+		{
+			name:    "unknown cipher suite",
+			spec:    ProfileSpec{CipherSuites: []string{"TLS_PANDA"}},
+			wantErr: `cipher suite "TLS_PANDA" is not supported`,
+		},
+		{
+			name:    "unknown curve",
+			spec:    ProfileSpec{CurvePreferences: []string{"P-256"}},
+			wantErr: `curve "P-256" is not supported, must be one of X25519, CurveP256, CurveP384 or CurveP521`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			configFunc, err := tt.spec.ConfigFunc(tt.defaults)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.EqualError(t, tt.spec.Validate(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, tt.spec.Validate())
+
+			want := tt.defaults(nil)
+			tt.want(want)
+			require.Equal(t, want, configFunc(nil))
+		})
+	}
+}
+
+func TestProfileServing(t *testing.T) {
+	t.Parallel()
+
+	configFunc, err := ProfileSpec{
+		MinVersion:   "TLS1.2",
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"},
+	}.ConfigFunc(Secure)
+	require.NoError(t, err)
+
+	opts := &options.SecureServingOptionsWithLoopback{SecureServingOptions: &options.SecureServingOptions{}}
+	serving(opts, configFunc)
+	require.Equal(t, options.SecureServingOptionsWithLoopback{
+		SecureServingOptions: &options.SecureServingOptions{
+			CipherSuites:  []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"},
+			MinTLSVersion: "VersionTLS12",
+		},
+	}, *opts)
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package ptls
@@ -19,13 +19,6 @@ import (
 	"k8s.io/client-go/transport"
 )
 
-// TODO decide if we need to expose the four TLS levels (secure, default, default-ldap, legacy) as config.
-
-// defaultServingOptionsMinTLSVersion is the minimum tls version in the format
-// expected by SecureServingOptions.MinTLSVersion from
-// k8s.io/apiserver/pkg/server/options.
-const defaultServingOptionsMinTLSVersion = "VersionTLS12"
-
 type ConfigFunc func(*x509.CertPool) *tls.Config
 
 func Legacy(rootCAs *x509.CertPool) *tls.Config {
@@ -61,25 +54,30 @@ func Merge(tlsConfigFunc ConfigFunc, tlsConfig *tls.Config) {
 // RestConfigFunc allows this package to not depend on the kubeclient package.
 type RestConfigFunc func(*rest.Config) (kubernetes.Interface, *rest.Config, error)
 
-func DefaultRecommendedOptions(opts *options.RecommendedOptions, f RestConfigFunc) error {
-	defaultServing(opts.SecureServing)
+// RecommendedOptions configures the serving options of an aggregated API server to use the TLS version and
+// cipher suites of the given ConfigFunc, and its clients to use the TLS config of the given RestConfigFunc.
+func RecommendedOptions(opts *options.RecommendedOptions, tlsConfigFunc ConfigFunc, f RestConfigFunc) error {
+	serving(opts.SecureServing, tlsConfigFunc)
 	return secureClient(opts, f)
 }
 
-func SecureRecommendedOptions(opts *options.RecommendedOptions, f RestConfigFunc) error {
-	secureServing(opts.SecureServing)
-	return secureClient(opts, f)
-}
+func serving(opts *options.SecureServingOptionsWithLoopback, tlsConfigFunc ConfigFunc) {
+	c := tlsConfigFunc(nil)
+
+	// the version names are in the format expected by SecureServingOptions.MinTLSVersion
+	if c.MinVersion == tls.VersionTLS13 {
+		opts.MinTLSVersion = "VersionTLS13"
+		opts.CipherSuites = nil // TLS 1.3 cipher suites are not configurable
+		return
+	}
 
-func defaultServing(opts *options.SecureServingOptionsWithLoopback) {
-	c := Default(nil)
 	cipherSuites := make([]string, 0, len(c.CipherSuites))
 	for _, id := range c.CipherSuites {
 		cipherSuites = append(cipherSuites, tls.CipherSuiteName(id))
 	}
 	opts.CipherSuites = cipherSuites
 
-	opts.MinTLSVersion = defaultServingOptionsMinTLSVersion
+	opts.MinTLSVersion = "VersionTLS12"
 }
 
 func secureClient(opts *options.RecommendedOptions, f RestConfigFunc) error {
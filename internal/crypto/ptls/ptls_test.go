@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package ptls
@@ -15,7 +15,7 @@ func TestDefaultServing(t *testing.T) {
 	t.Parallel()
 
 	opts := &options.SecureServingOptionsWithLoopback{SecureServingOptions: &options.SecureServingOptions{}}
-	defaultServing(opts)
+	serving(opts, Default)
 	require.Equal(t, options.SecureServingOptionsWithLoopback{
 		SecureServingOptions: &options.SecureServingOptions{
 			CipherSuites: []string{
@@ -35,7 +35,7 @@ func TestSecureServing(t *testing.T) {
 	t.Parallel()
 
 	opts := &options.SecureServingOptionsWithLoopback{SecureServingOptions: &options.SecureServingOptions{}}
-	secureServing(opts)
+	serving(opts, Secure)
 	require.Equal(t, options.SecureServingOptionsWithLoopback{
 		SecureServingOptions: &options.SecureServingOptions{
 			MinTLSVersion: "VersionTLS13",
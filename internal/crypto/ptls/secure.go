@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 //go:build !fips_strict
@@ -9,15 +9,8 @@ package ptls
 import (
 	"crypto/tls"
 	"crypto/x509"
-
-	"k8s.io/apiserver/pkg/server/options"
 )
 
-// secureServingOptionsMinTLSVersion is the minimum tls version in the format
-// expected by SecureServingOptions.MinTLSVersion from
-// k8s.io/apiserver/pkg/server/options.
-const secureServingOptionsMinTLSVersion = "VersionTLS13"
-
 // SecureTLSConfigMinTLSVersion is the minimum tls version in the format expected
 // by tls.Config.
 const SecureTLSConfigMinTLSVersion = tls.VersionTLS13
@@ -44,8 +37,3 @@ func Secure(rootCAs *x509.CertPool) *tls.Config {
 	}
 	return c
 }
-
-func secureServing(opts *options.SecureServingOptionsWithLoopback) {
-	opts.MinTLSVersion = secureServingOptionsMinTLSVersion
-	opts.CipherSuites = nil
-}
@@ -0,0 +1,284 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package doctor checks whether the Concierge or the Supervisor can run in its cluster, e.g. whether its RBAC
+// permissions, APIs and required Secrets and ConfigMaps are in place, and prints a readiness report which helps to
+// debug installations.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+	aggregatorclient "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
+
+	"go.pinniped.dev/internal/endpointaddr"
+)
+
+// dialTimeout is how long a Reachable check waits for a TCP connection.
+const dialTimeout = 5 * time.Second
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	// StatusPass means that the check found no problem.
+	StatusPass Status = "Pass"
+	// StatusWarn means that the check found something which may need attention, but which does not prevent the
+	// component from running, e.g. a Secret which the component creates itself once it runs.
+	StatusWarn Status = "Warn"
+	// StatusFail means that the check found a problem which prevents the component from running correctly.
+	StatusFail Status = "Fail"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Check checks one aspect of the installation.
+type Check func(ctx context.Context) Result
+
+// Report is the readiness report of a component.
+type Report struct {
+	Component string   `json:"component"`
+	Ready     bool     `json:"ready"`
+	Results   []Result `json:"results"`
+}
+
+// NewReport returns an empty report, which is ready until a failed result is added.
+func NewReport(component string) *Report {
+	return &Report{Component: component, Ready: true, Results: []Result{}}
+}
+
+// Add adds results to the report.
+func (r *Report) Add(results ...Result) {
+	for _, result := range results {
+		if result.Status == StatusFail {
+			r.Ready = false
+		}
+		r.Results = append(r.Results, result)
+	}
+}
+
+// Run runs the checks in order and adds their results to the report.
+func (r *Report) Run(ctx context.Context, checks ...Check) {
+	for _, check := range checks {
+		r.Add(check(ctx))
+	}
+}
+
+// Write writes the report to w as indented JSON.
+func (r *Report) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// FromError returns a failed result with the message of err, or a passed result with message when err is nil.
+func FromError(check string, err error, message string) Result {
+	if err != nil {
+		return Result{Check: check, Status: StatusFail, Message: err.Error()}
+	}
+	return Result{Check: check, Status: StatusPass, Message: message}
+}
+
+// APIServer checks that the Kubernetes API server can be reached.
+func APIServer(client discovery.ServerVersionInterface) Check {
+	return func(ctx context.Context) Result {
+		info, err := client.ServerVersion()
+		if err != nil {
+			return FromError("kubernetes API server", fmt.Errorf("cannot reach the Kubernetes API server: %w", err), "")
+		}
+		return FromError("kubernetes API server", nil, "reached Kubernetes "+info.GitVersion)
+	}
+}
+
+// APIGroups checks that the Kubernetes API server serves the API groups, e.g. because their CRDs are installed.
+func APIGroups(client discovery.ServerGroupsInterface, groups ...string) Check {
+	return func(ctx context.Context) Result {
+		const check = "API groups"
+
+		served, err := client.ServerGroups()
+		if err != nil {
+			return FromError(check, fmt.Errorf("cannot discover API groups: %w", err), "")
+		}
+
+		servedNames := sets.NewString()
+		for _, group := range served.Groups {
+			servedNames.Insert(group.Name)
+		}
+
+		if missing := sets.NewString(groups...).Difference(servedNames); missing.Len() > 0 {
+			return FromError(check, fmt.Errorf("API groups are not served, are their CRDs installed? %v", missing.List()), "")
+		}
+		return FromError(check, nil, fmt.Sprintf("served %v", sets.NewString(groups...).List()))
+	}
+}
+
+// APIService checks that the APIService of an aggregated API exists and warns when it is unavailable, e.g. because
+// the component is not running yet.
+func APIService(client aggregatorclient.Interface, name string) Check {
+	return func(ctx context.Context) Result {
+		check := "APIService " + name
+
+		apiService, err := client.ApiregistrationV1().APIServices().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return FromError(check, fmt.Errorf("cannot get APIService: %w", err), "")
+		}
+
+		for _, cond := range apiService.Status.Conditions {
+			if cond.Type == apiregistrationv1.Available && cond.Status != apiregistrationv1.ConditionTrue {
+				return Result{Check: check, Status: StatusWarn, Message: fmt.Sprintf("not available: %s: %s", cond.Reason, cond.Message)}
+			}
+		}
+		return FromError(check, nil, "exists")
+	}
+}
+
+// Permissions checks that the component is authorized for all of the attributes.
+func Permissions(client kubernetes.Interface, attributes []authorizationv1.ResourceAttributes) Check {
+	return func(ctx context.Context) Result {
+		const check = "RBAC permissions"
+
+		var denied []string
+		for _, attrs := range attributes {
+			attrs := attrs
+			review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx,
+				&authorizationv1.SelfSubjectAccessReview{Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs}},
+				metav1.CreateOptions{},
+			)
+			if err != nil {
+				return FromError(check, fmt.Errorf("cannot review access: %w", err), "")
+			}
+			if !review.Status.Allowed {
+				denied = append(denied, describe(attrs))
+			}
+		}
+
+		if len(denied) > 0 {
+			return FromError(check, fmt.Errorf("not authorized to %s", strings.Join(denied, "; ")), "")
+		}
+		return FromError(check, nil, fmt.Sprintf("authorized for all %d required permissions", len(attributes)))
+	}
+}
+
+// Rule returns the attributes of each of the verbs on a resource, which may have a subresource, e.g. pods/exec,
+// like a rule of an RBAC Role. The namespace is empty for cluster-scoped resources.
+func Rule(namespace, group, resource string, verbs ...string) []authorizationv1.ResourceAttributes {
+	resource, subresource, _ := strings.Cut(resource, "/")
+
+	attributes := make([]authorizationv1.ResourceAttributes, 0, len(verbs))
+	for _, verb := range verbs {
+		attributes = append(attributes, authorizationv1.ResourceAttributes{
+			Namespace:   namespace,
+			Verb:        verb,
+			Group:       group,
+			Resource:    resource,
+			Subresource: subresource,
+		})
+	}
+	return attributes
+}
+
+// Secret checks that the Secret exists. When it is missing, the check fails when the Secret is required, and
+// otherwise only warns, e.g. because the component creates the Secret itself.
+func Secret(client kubernetes.Interface, namespace, name string, required bool) Check {
+	return exists("Secret "+namespace+"/"+name, required, func(ctx context.Context) error {
+		_, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+}
+
+// ConfigMap checks that the ConfigMap exists.
+func ConfigMap(client kubernetes.Interface, namespace, name string) Check {
+	return exists("ConfigMap "+namespace+"/"+name, true, func(ctx context.Context) error {
+		_, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+}
+
+func exists(check string, required bool, get func(ctx context.Context) error) Check {
+	return func(ctx context.Context) Result {
+		err := get(ctx)
+		switch {
+		case err == nil:
+			return FromError(check, nil, "exists")
+		case apierrors.IsNotFound(err) && !required:
+			return Result{Check: check, Status: StatusWarn, Message: "does not exist"}
+		default:
+			return FromError(check, err, "")
+		}
+	}
+}
+
+// Reachable checks that a TCP connection can be opened to the endpoint, which is either a URL or a host with an
+// optional port, which defaults to defaultPort.
+func Reachable(check, endpoint string, defaultPort uint16) Check {
+	return func(ctx context.Context) Result {
+		address, err := dialAddress(endpoint, defaultPort)
+		if err != nil {
+			return FromError(check, fmt.Errorf("invalid endpoint %q: %w", endpoint, err), "")
+		}
+
+		dialer := net.Dialer{Timeout: dialTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return FromError(check, fmt.Errorf("cannot connect to %s: %w", address, err), "")
+		}
+		_ = conn.Close()
+
+		return FromError(check, nil, "connected to "+address)
+	}
+}
+
+func dialAddress(endpoint string, defaultPort uint16) (string, error) {
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", err
+		}
+		endpoint = u.Host
+		if u.Scheme == "http" {
+			defaultPort = 80
+		}
+	}
+
+	hostPort, err := endpointaddr.Parse(endpoint, defaultPort)
+	if err != nil {
+		return "", err
+	}
+	return hostPort.Endpoint(), nil
+}
+
+func describe(attrs authorizationv1.ResourceAttributes) string {
+	resource := attrs.Resource
+	if attrs.Group != "" {
+		resource += "." + attrs.Group
+	}
+	if attrs.Subresource != "" {
+		resource += "/" + attrs.Subresource
+	}
+	if attrs.Namespace != "" {
+		return fmt.Sprintf("%s %s in namespace %s", attrs.Verb, resource, attrs.Namespace)
+	}
+	return fmt.Sprintf("%s %s", attrs.Verb, resource)
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+	aggregatorfake "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset/fake"
+)
+
+func TestReport(t *testing.T) {
+	report := NewReport("some-component")
+	report.Run(context.Background(),
+		func(ctx context.Context) Result { return FromError("first", nil, "fine") },
+		func(ctx context.Context) Result { return Result{Check: "second", Status: StatusWarn, Message: "hmm"} },
+	)
+	require.True(t, report.Ready)
+
+	report.Add(FromError("third", errors.New("some error"), "fine"))
+	require.False(t, report.Ready)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+	require.JSONEq(t, `{
+		"component": "some-component",
+		"ready": false,
+		"results": [
+			{"check": "first", "status": "Pass", "message": "fine"},
+			{"check": "second", "status": "Warn", "message": "hmm"},
+			{"check": "third", "status": "Fail", "message": "some error"}
+		]
+	}`, buf.String())
+}
+
+func TestAPIServerAndGroups(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	fakeDiscovery := client.Discovery().(*fakediscovery.FakeDiscovery)
+	fakeDiscovery.FakedServerVersion = &version.Info{GitVersion: "v1.26.1"}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "a.example.com/v1"},
+		{GroupVersion: "b.example.com/v1"},
+	}
+
+	ctx := context.Background()
+	require.Equal(t,
+		Result{Check: "kubernetes API server", Status: StatusPass, Message: "reached Kubernetes v1.26.1"},
+		APIServer(fakeDiscovery)(ctx),
+	)
+	require.Equal(t,
+		Result{Check: "API groups", Status: StatusPass, Message: "served [a.example.com b.example.com]"},
+		APIGroups(fakeDiscovery, "b.example.com", "a.example.com")(ctx),
+	)
+	require.Equal(t,
+		Result{Check: "API groups", Status: StatusFail, Message: "API groups are not served, are their CRDs installed? [c.example.com]"},
+		APIGroups(fakeDiscovery, "a.example.com", "c.example.com")(ctx),
+	)
+}
+
+func TestAPIService(t *testing.T) {
+	client := aggregatorfake.NewSimpleClientset(
+		&apiregistrationv1.APIService{ObjectMeta: metav1.ObjectMeta{Name: "v1.available.example.com"}, Status: apiregistrationv1.APIServiceStatus{
+			Conditions: []apiregistrationv1.APIServiceCondition{{Type: apiregistrationv1.Available, Status: apiregistrationv1.ConditionTrue}},
+		}},
+		&apiregistrationv1.APIService{ObjectMeta: metav1.ObjectMeta{Name: "v1.unavailable.example.com"}, Status: apiregistrationv1.APIServiceStatus{
+			Conditions: []apiregistrationv1.APIServiceCondition{{Type: apiregistrationv1.Available, Status: apiregistrationv1.ConditionFalse, Reason: "MissingEndpoints", Message: "no endpoints"}},
+		}},
+	)
+
+	ctx := context.Background()
+	require.Equal(t, StatusPass, APIService(client, "v1.available.example.com")(ctx).Status)
+	require.Equal(t,
+		Result{Check: "APIService v1.unavailable.example.com", Status: StatusWarn, Message: "not available: MissingEndpoints: no endpoints"},
+		APIService(client, "v1.unavailable.example.com")(ctx),
+	)
+	require.Equal(t, StatusFail, APIService(client, "v1.missing.example.com")(ctx).Status)
+}
+
+func TestPermissions(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action coretesting.Action) (bool, runtime.Object, error) {
+		review := action.(coretesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Verb != "delete"
+		return true, review, nil
+	})
+
+	ctx := context.Background()
+	require.Equal(t,
+		Result{Check: "RBAC permissions", Status: StatusPass, Message: "authorized for all 3 required permissions"},
+		Permissions(client, append(Rule("some-namespace", "", "secrets", "get", "list"), Rule("", "apps", "deployments", "watch")...))(ctx),
+	)
+	require.Equal(t,
+		Result{Check: "RBAC permissions", Status: StatusFail, Message: "not authorized to delete secrets in namespace some-namespace; delete widgets.example.com/status"},
+		Permissions(client, append(Rule("some-namespace", "", "secrets", "get", "delete"), Rule("", "example.com", "widgets/status", "delete")...))(ctx),
+	)
+}
+
+func TestSecretAndConfigMap(t *testing.T) {
+	client := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-secret"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-configmap"}},
+	)
+
+	ctx := context.Background()
+	require.Equal(t,
+		Result{Check: "Secret some-namespace/some-secret", Status: StatusPass, Message: "exists"},
+		Secret(client, "some-namespace", "some-secret", true)(ctx),
+	)
+	require.Equal(t,
+		Result{Check: "Secret some-namespace/other-secret", Status: StatusWarn, Message: "does not exist"},
+		Secret(client, "some-namespace", "other-secret", false)(ctx),
+	)
+	require.Equal(t,
+		Result{Check: "Secret some-namespace/other-secret", Status: StatusFail, Message: `secrets "other-secret" not found`},
+		Secret(client, "some-namespace", "other-secret", true)(ctx),
+	)
+	require.Equal(t, StatusPass, ConfigMap(client, "some-namespace", "some-configmap")(ctx).Status)
+	require.Equal(t, StatusFail, ConfigMap(client, "some-namespace", "other-configmap")(ctx).Status)
+}
+
+func TestReachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	address := l.Addr().String()
+
+	ctx := context.Background()
+	require.Equal(t,
+		Result{Check: "some idp", Status: StatusPass, Message: "connected to " + address},
+		Reachable("some idp", address, 636)(ctx),
+	)
+	require.Equal(t,
+		Result{Check: "some idp", Status: StatusPass, Message: "connected to " + address},
+		Reachable("some idp", "https://"+address+"/some/path", 443)(ctx),
+	)
+	require.Equal(t,
+		Result{Check: "some idp", Status: StatusFail, Message: `invalid endpoint "https://bad host": parse "https://bad host": invalid character " " in host name`},
+		Reachable("some idp", "https://bad host", 443)(ctx),
+	)
+
+	require.NoError(t, l.Close())
+	result := Reachable("some idp", address, 636)(ctx)
+	require.Equal(t, StatusFail, result.Status)
+	require.Contains(t, result.Message, "cannot connect to "+address)
+}
+
+func TestDialAddress(t *testing.T) {
+	for _, tt := range []struct {
+		endpoint    string
+		defaultPort uint16
+		want        string
+	}{
+		{endpoint: "ldap.example.com", defaultPort: 636, want: "ldap.example.com:636"},
+		{endpoint: "ldap.example.com:1636", defaultPort: 636, want: "ldap.example.com:1636"},
+		{endpoint: "https://issuer.example.com/path", defaultPort: 443, want: "issuer.example.com:443"},
+		{endpoint: "http://issuer.example.com/path", defaultPort: 443, want: "issuer.example.com:80"},
+		{endpoint: "https://issuer.example.com:8443/path", defaultPort: 443, want: "issuer.example.com:8443"},
+	} {
+		got, err := dialAddress(tt.endpoint, tt.defaultPort)
+		require.NoError(t, err, tt.endpoint)
+		require.Equal(t, tt.want, got, tt.endpoint)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package dynamiccert
+
+import (
+	"context"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/pmetrics"
+)
+
+const (
+	errExpiringSoon = constable.Error("certificate is about to expire")
+	errExpired      = constable.Error("certificate has expired")
+)
+
+// ExpiryWarningInterval is how often RunExpiryWarnings checks the expiry of the certificates.
+const ExpiryWarningInterval = time.Hour
+
+var expiryMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{ //nolint:gochecknoglobals
+	Namespace:      pmetrics.Namespace,
+	Name:           "certificate_expiry_timestamp_seconds",
+	Help:           "Unix time at which the certificate which is currently served or used for signing expires, by certificate name.",
+	StabilityLevel: metrics.ALPHA,
+}, []string{"name"})
+
+// expiryLevel is how close a certificate is to its expiry. Each level is more severe than the previous one,
+// so a message is logged whenever a certificate reaches a new level, e.g. because its rotation keeps failing.
+type expiryLevel int
+
+const (
+	expiryLevelNone expiryLevel = iota
+	expiryLevelMonth
+	expiryLevelWeek
+	expiryLevelDay
+	expiryLevelExpired
+)
+
+func expiryLevelAt(now, notAfter time.Time) expiryLevel {
+	switch remaining := notAfter.Sub(now); {
+	case remaining <= 0:
+		return expiryLevelExpired
+	case remaining <= 24*time.Hour:
+		return expiryLevelDay
+	case remaining <= 7*24*time.Hour:
+		return expiryLevelWeek
+	case remaining <= 30*24*time.Hour:
+		return expiryLevelMonth
+	default:
+		return expiryLevelNone
+	}
+}
+
+type expiry struct {
+	notAfter time.Time
+	logged   expiryLevel
+}
+
+type expiryTracker struct {
+	mutex    sync.Mutex
+	expiries map[string]*expiry
+}
+
+var (
+	expiries            = &expiryTracker{expiries: map[string]*expiry{}} //nolint:gochecknoglobals
+	registerMetricsOnce sync.Once                                        //nolint:gochecknoglobals
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(expiryMetric)
+	})
+}
+
+// RecordExpiry exports the expiry of the named certificate as a metric and includes it in the warnings which
+// are logged by RunExpiryWarnings. The providers of this package record their own certificates, so this only
+// needs to be called for certificates which are held elsewhere, e.g. the TLS certificates of FederationDomains.
+func RecordExpiry(name string, cert *x509.Certificate) {
+	registerMetrics()
+
+	expiryMetric.WithLabelValues(name).Set(float64(cert.NotAfter.Unix()))
+
+	expiries.mutex.Lock()
+	defer expiries.mutex.Unlock()
+
+	if e, ok := expiries.expiries[name]; ok && e.notAfter.Equal(cert.NotAfter) {
+		return // keep remembering which warning was already logged for this certificate
+	}
+	expiries.expiries[name] = &expiry{notAfter: cert.NotAfter}
+}
+
+// ForgetExpiry stops exporting and warning about the expiry of the named certificate.
+func ForgetExpiry(name string) {
+	expiryMetric.DeleteLabelValues(name)
+
+	expiries.mutex.Lock()
+	defer expiries.mutex.Unlock()
+
+	delete(expiries.expiries, name)
+}
+
+// RunExpiryWarnings logs increasingly severe messages when a recorded certificate gets closer to its expiry than
+// 30 days, 7 days and 1 day, and on every check once it has expired, until the context is canceled.
+func RunExpiryWarnings(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expiries.logWarnings(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *expiryTracker) logWarnings(now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for name, e := range t.expiries {
+		level := expiryLevelAt(now, e.notAfter)
+		if level <= e.logged && level != expiryLevelExpired {
+			continue
+		}
+		e.logged = level
+
+		keysAndValues := []interface{}{"name", name, "notAfter", e.notAfter, "remaining", e.notAfter.Sub(now).Round(time.Minute).String()}
+		switch level {
+		case expiryLevelMonth:
+			plog.Info("certificate will expire within 30 days, check that it is being rotated", keysAndValues...)
+		case expiryLevelWeek:
+			plog.Warning("certificate will expire within 7 days, check that it is being rotated", keysAndValues...)
+		case expiryLevelDay:
+			plog.Error("certificate will expire within 1 day, check that it is being rotated", errExpiringSoon, keysAndValues...)
+		case expiryLevelExpired:
+			plog.Error("certificate has expired, check that it is being rotated", errExpired, keysAndValues...)
+		case expiryLevelNone:
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package dynamiccert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/rand"
+	metricstestutil "k8s.io/component-base/metrics/testutil"
+
+	"go.pinniped.dev/internal/certauthority"
+)
+
+func TestExpiryLevelAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	for _, tt := range []struct {
+		remaining time.Duration
+		want      expiryLevel
+	}{
+		{remaining: 60 * 24 * time.Hour, want: expiryLevelNone},
+		{remaining: 30 * 24 * time.Hour, want: expiryLevelMonth},
+		{remaining: 7 * 24 * time.Hour, want: expiryLevelWeek},
+		{remaining: 2 * time.Hour, want: expiryLevelDay},
+		{remaining: 0, want: expiryLevelExpired},
+		{remaining: -time.Hour, want: expiryLevelExpired},
+	} {
+		require.Equal(t, tt.want, expiryLevelAt(now, now.Add(tt.remaining)), tt.remaining.String())
+	}
+}
+
+func TestExpiryTrackerLogsEachLevelOnce(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	e := &expiry{notAfter: now.Add(40 * 24 * time.Hour)}
+	tracker := &expiryTracker{expiries: map[string]*expiry{"some-cert": e}}
+
+	tracker.logWarnings(now)
+	require.Equal(t, expiryLevelNone, e.logged)
+
+	tracker.logWarnings(now.Add(12 * 24 * time.Hour))
+	require.Equal(t, expiryLevelMonth, e.logged)
+
+	tracker.logWarnings(now.Add(39*24*time.Hour + time.Minute))
+	require.Equal(t, expiryLevelDay, e.logged)
+
+	tracker.logWarnings(now.Add(41 * 24 * time.Hour))
+	require.Equal(t, expiryLevelExpired, e.logged)
+}
+
+func TestProviderRecordsExpiry(t *testing.T) {
+	// the metrics are global, so use a name which is unique to this run to avoid collisions with other tests
+	name := "test-ca-" + rand.String(8)
+	p := NewCA(name)
+
+	ca, err := certauthority.New("ca", time.Hour)
+	require.NoError(t, err)
+	caKey, err := ca.PrivateKeyToPEM()
+	require.NoError(t, err)
+	require.NoError(t, p.SetCertKeyContent(ca.Bundle(), caKey))
+
+	got, err := metricstestutil.GetGaugeMetricValue(expiryMetric.WithLabelValues(name))
+	require.NoError(t, err)
+	require.InDelta(t, float64(time.Now().Add(time.Hour).Unix()), got, 60)
+
+	expiries.mutex.Lock()
+	require.Contains(t, expiries.expiries, name)
+	expiries.mutex.Unlock()
+
+	p.UnsetCertKeyContent()
+
+	expiries.mutex.Lock()
+	require.NotContains(t, expiries.expiries, name)
+	expiries.mutex.Unlock()
+}
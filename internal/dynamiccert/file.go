@@ -0,0 +1,200 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package dynamiccert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// filePollInterval is how often the files are stat'ed in case a change was missed by the watcher,
+// e.g. because the watch could not be established or the directory was replaced.
+const filePollInterval = time.Minute
+
+var _ Provider = &fileProvider{}
+
+type fileProvider struct {
+	*provider
+
+	// these fields are constant after struct initialization and thus do not need locking
+	certFile     string
+	keyFile      string
+	pollInterval time.Duration
+
+	// loadMutex serializes loads and guards all the fields below it
+	loadMutex sync.Mutex
+	lastStats [2]fileStat
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewServingCertFromFiles returns a Private whose key pair is loaded from the given PEM files, e.g. a
+// Secret which is mounted into the pod by cert-manager or a file written by a SPIFFE sidecar.
+// It can only hold key pairs that have IsCA=false.
+// RunOnce must be called to load the files and Run must be called to keep watching them for changes.
+func NewServingCertFromFiles(name, certFile, keyFile string) Private {
+	return struct {
+		Private
+	}{
+		Private: newFileProvider(name, certFile, keyFile, false),
+	}
+}
+
+// NewCAFromFiles returns a Provider whose key pair is loaded from the given PEM files.
+// It can only hold key pairs that have IsCA=true.
+// RunOnce must be called to load the files and Run must be called to keep watching them for changes.
+func NewCAFromFiles(name, certFile, keyFile string) Provider {
+	return newFileProvider(name, certFile, keyFile, true)
+}
+
+func newFileProvider(name, certFile, keyFile string, isCA bool) *fileProvider {
+	return &fileProvider{
+		provider:     &provider{name: name, isCA: isCA},
+		certFile:     certFile,
+		keyFile:      keyFile,
+		pollInterval: filePollInterval,
+	}
+}
+
+// RunOnce loads the key pair from the files, and returns an error when they do not contain a valid key pair.
+func (p *fileProvider) RunOnce(_ context.Context) error {
+	p.loadMutex.Lock()
+	defer p.loadMutex.Unlock()
+
+	return p.load()
+}
+
+// Run watches the files and reloads the key pair whenever they change, until the context is canceled.
+// Invalid content is logged and ignored, so the previous key pair keeps being served until the files
+// are fixed, e.g. while only one of the cert and key files has been rewritten.
+func (p *fileProvider) Run(ctx context.Context, _ int) {
+	events, closeWatcher := p.watch()
+	defer closeWatcher()
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-events:
+			p.reload()
+		case <-ticker.C:
+			if p.changed() {
+				p.reload()
+			}
+		}
+	}
+}
+
+// watch watches the directories of the files rather than the files themselves, because mounted Secrets and
+// ConfigMaps are updated by atomically swapping a symlink, which a watch on the old file would never see.
+// The returned channel is nil when the watch could not be established, in which case only polling is used.
+func (p *fileProvider) watch() (<-chan fsnotify.Event, func()) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		plog.WarningErr("could not watch certificate files, falling back to polling", err, "name", p.name)
+		return nil, func() {}
+	}
+
+	for _, dir := range p.dirs() {
+		if err := watcher.Add(dir); err != nil {
+			plog.WarningErr("could not watch certificate directory, falling back to polling", err, "name", p.name, "dir", dir)
+		}
+	}
+
+	go func() {
+		for err := range watcher.Errors {
+			plog.WarningErr("error while watching certificate files", err, "name", p.name)
+		}
+	}()
+
+	return watcher.Events, func() { _ = watcher.Close() }
+}
+
+func (p *fileProvider) dirs() []string {
+	certDir, keyDir := filepath.Dir(p.certFile), filepath.Dir(p.keyFile)
+	if certDir == keyDir {
+		return []string{certDir}
+	}
+	return []string{certDir, keyDir}
+}
+
+func (p *fileProvider) reload() {
+	p.loadMutex.Lock()
+	defer p.loadMutex.Unlock()
+
+	if err := p.load(); err != nil {
+		plog.WarningErr("could not reload certificate files, continuing to use the previous key pair", err, "name", p.name)
+	}
+}
+
+// load must be called while holding loadMutex.
+func (p *fileProvider) load() error {
+	stats, err := p.stat()
+	if err != nil {
+		return err
+	}
+
+	certPEM, err := os.ReadFile(p.certFile)
+	if err != nil {
+		return fmt.Errorf("%s: could not read cert file: %w", p.name, err)
+	}
+	keyPEM, err := os.ReadFile(p.keyFile)
+	if err != nil {
+		return fmt.Errorf("%s: could not read key file: %w", p.name, err)
+	}
+
+	p.lastStats = stats
+
+	currentCertPEM, currentKeyPEM := p.CurrentCertKeyContent()
+	if bytes.Equal(certPEM, currentCertPEM) && bytes.Equal(keyPEM, currentKeyPEM) {
+		return nil // avoid waking up the listeners when nothing changed
+	}
+
+	if err := p.SetCertKeyContent(certPEM, keyPEM); err != nil {
+		return err
+	}
+
+	plog.Info("loaded certificate files", "name", p.name, "certFile", p.certFile, "keyFile", p.keyFile)
+	return nil
+}
+
+func (p *fileProvider) changed() bool {
+	p.loadMutex.Lock()
+	defer p.loadMutex.Unlock()
+
+	stats, err := p.stat()
+	if err != nil {
+		plog.WarningErr("could not stat certificate files", err, "name", p.name)
+		return false
+	}
+
+	return stats != p.lastStats
+}
+
+func (p *fileProvider) stat() ([2]fileStat, error) {
+	var stats [2]fileStat
+	for i, file := range []string{p.certFile, p.keyFile} {
+		info, err := os.Stat(file) // follows symlinks, so a swapped Secret mount changes the result
+		if err != nil {
+			return stats, fmt.Errorf("%s: could not stat file: %w", p.name, err)
+		}
+		stats[i] = fileStat{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stats, nil
+}
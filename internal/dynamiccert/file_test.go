@@ -0,0 +1,134 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package dynamiccert
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/certauthority"
+)
+
+type countingListener struct{ count chan struct{} }
+
+func (l *countingListener) Enqueue() { l.count <- struct{}{} }
+
+func TestServingCertFromFiles(t *testing.T) {
+	t.Parallel()
+
+	ca, err := certauthority.New("ca", time.Hour)
+	require.NoError(t, err)
+
+	issue := func(ip string) ([]byte, []byte) {
+		t.Helper()
+		certPEM, keyPEM, err := ca.IssueServerCertPEM(nil, []net.IP{net.ParseIP(ip)}, time.Hour)
+		require.NoError(t, err)
+		return certPEM, keyPEM
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	write := func(certPEM, keyPEM []byte) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+		require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	}
+
+	p := NewServingCertFromFiles("test-serving-cert", certFile, keyFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.EqualError(t, p.RunOnce(ctx), "test-serving-cert: could not stat file: stat "+certFile+": no such file or directory")
+
+	certPEM1, keyPEM1 := issue("127.0.0.1")
+	write(certPEM1, keyPEM1)
+	require.NoError(t, p.RunOnce(ctx))
+	gotCert, gotKey := p.CurrentCertKeyContent()
+	require.Equal(t, certPEM1, gotCert)
+	require.Equal(t, keyPEM1, gotKey)
+
+	listener := &countingListener{count: make(chan struct{}, 10)}
+	p.AddListener(listener)
+
+	go p.Run(ctx, 1)
+
+	// invalid content is ignored and the previous key pair is kept
+	write([]byte("not a cert"), keyPEM1)
+	require.Never(t, func() bool { return len(listener.count) != 0 }, time.Second, 10*time.Millisecond)
+	gotCert, _ = p.CurrentCertKeyContent()
+	require.Equal(t, certPEM1, gotCert)
+
+	certPEM2, keyPEM2 := issue("127.0.0.2")
+	write(certPEM2, keyPEM2)
+	require.Eventually(t, func() bool {
+		gotCert, gotKey := p.CurrentCertKeyContent()
+		return string(gotCert) == string(certPEM2) && string(gotKey) == string(keyPEM2)
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Len(t, listener.count, 1)
+}
+
+func TestServingCertFromFilesPolling(t *testing.T) {
+	t.Parallel()
+
+	ca, err := certauthority.New("ca", time.Hour)
+	require.NoError(t, err)
+
+	certPEM, keyPEM, err := ca.IssueServerCertPEM(nil, []net.IP{net.ParseIP("127.0.0.1")}, time.Hour)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	// watch a directory which does not contain the files, so that only polling can notice the change
+	p := newFileProvider("test-serving-cert", certFile, keyFile, false)
+	p.certFile, p.keyFile = filepath.Join(dir, "missing", "tls.crt"), filepath.Join(dir, "missing", "tls.key")
+	p.pollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go p.Run(ctx, 1)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "missing"), 0o700))
+	require.NoError(t, os.Rename(certFile, p.certFile))
+	require.NoError(t, os.Rename(keyFile, p.keyFile))
+
+	require.Eventually(t, func() bool {
+		gotCert, _ := p.CurrentCertKeyContent()
+		return string(gotCert) == string(certPEM)
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestCAFromFilesRejectsServingCert(t *testing.T) {
+	t.Parallel()
+
+	ca, err := certauthority.New("ca", time.Hour)
+	require.NoError(t, err)
+
+	certPEM, keyPEM, err := ca.IssueServerCertPEM(nil, []net.IP{net.ParseIP("127.0.0.1")}, time.Hour)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	p := NewCAFromFiles("test-ca", certFile, keyFile)
+	require.EqualError(t, p.RunOnce(context.Background()), "test-ca: attempt to set x509 cert with unexpected IsCA=false")
+
+	caKeyPEM, err := ca.PrivateKeyToPEM()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, ca.Bundle(), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, caKeyPEM, 0o600))
+	require.NoError(t, p.RunOnce(context.Background()))
+	require.Equal(t, ca.Bundle(), p.CurrentCABundleContent())
+}
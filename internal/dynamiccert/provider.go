@@ -1,12 +1,15 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package dynamiccert
 
 import (
+	"bytes"
 	"context"
+	"crypto"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"sync"
 
@@ -18,6 +21,11 @@ import (
 type Provider interface {
 	Private
 	Public
+
+	// SetAdditionalCABundleContent sets CA certificates which are trusted in addition to the current CA, i.e. which
+	// are included in CurrentCABundleContent, e.g. the previous CA after it was rotated. It is cleared by
+	// UnsetCertKeyContent.
+	SetAdditionalCABundleContent(caBundlePEM []byte)
 }
 
 type Private interface {
@@ -43,14 +51,16 @@ var _ Provider = &provider{}
 
 type provider struct {
 	// these fields are constant after struct initialization and thus do not need locking
-	name string
-	isCA bool
+	name   string
+	isCA   bool
+	signer crypto.Signer
 
 	// mutex guards all the fields below it
-	mutex     sync.RWMutex
-	certPEM   []byte
-	keyPEM    []byte
-	listeners []dynamiccertificates.Listener
+	mutex                 sync.RWMutex
+	certPEM               []byte
+	keyPEM                []byte
+	additionalCABundlePEM []byte
+	listeners             []dynamiccertificates.Listener
 }
 
 // NewServingCert returns a Private that is go routine safe.
@@ -69,6 +79,14 @@ func NewCA(name string) Provider {
 	return &provider{name: name, isCA: true}
 }
 
+// NewCAWithSigner returns a Provider that is go routine safe.
+// It can only hold CA certificates which belong to the given signer, e.g. a key which is held by an external signer
+// plugin, and thus it does not need their private key. SetCertKeyContent ignores its keyPEM argument, and
+// CurrentCertKeyContent always returns a nil key.
+func NewCAWithSigner(name string, signer crypto.Signer) Provider {
+	return &provider{name: name, isCA: true, signer: signer}
+}
+
 func (p *provider) Name() string {
 	return p.name
 }
@@ -81,34 +99,87 @@ func (p *provider) CurrentCertKeyContent() (cert []byte, key []byte) {
 }
 
 func (p *provider) SetCertKeyContent(certPEM, keyPEM []byte) error {
+	x509Cert, err := p.parseCertKeyContent(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+
+	// confirm that we are not trying to use a CA as a serving cert and vice versa
+	if p.isCA != x509Cert.IsCA {
+		return fmt.Errorf("%s: attempt to set x509 cert with unexpected IsCA=%v", p.name, x509Cert.IsCA)
+	}
+
+	if p.signer != nil {
+		keyPEM = nil
+	}
+
+	p.setCertKeyContent(certPEM, keyPEM)
+	RecordExpiry(p.name, x509Cert)
+
+	return nil
+}
+
+func (p *provider) parseCertKeyContent(certPEM, keyPEM []byte) (*x509.Certificate, error) {
+	if p.signer != nil {
+		return p.parseCertContent(certPEM)
+	}
+
 	// always make sure that we have valid PEM data, otherwise
 	// dynamiccertificates.NewUnionCAContentProvider.VerifyOptions will panic
 	cert, err := tls.X509KeyPair(certPEM, keyPEM)
 	if err != nil {
-		return fmt.Errorf("%s: attempt to set invalid key pair: %w", p.name, err)
+		return nil, fmt.Errorf("%s: attempt to set invalid key pair: %w", p.name, err)
 	}
 
 	// these checks should always pass if tls.X509KeyPair did not error
 	if len(cert.Certificate) == 0 {
-		return fmt.Errorf("%s: key pair has empty cert slice", p.name)
+		return nil, fmt.Errorf("%s: key pair has empty cert slice", p.name)
 	}
 	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
 	if err != nil {
-		return fmt.Errorf("%s: failed to parse key pair as x509 cert: %w", p.name, err)
+		return nil, fmt.Errorf("%s: failed to parse key pair as x509 cert: %w", p.name, err)
 	}
 
-	// confirm that we are not trying to use a CA as a serving cert and vice versa
-	if p.isCA != x509Cert.IsCA {
-		return fmt.Errorf("%s: attempt to set x509 cert with unexpected IsCA=%v", p.name, x509Cert.IsCA)
+	return x509Cert, nil
+}
+
+func (p *provider) parseCertContent(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s: attempt to set invalid cert: failed to find any PEM certificate", p.name)
+	}
+	x509Cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse x509 cert: %w", p.name, err)
 	}
 
-	p.setCertKeyContent(certPEM, keyPEM)
+	publicKey, ok := x509Cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(p.signer.Public()) {
+		return nil, fmt.Errorf("%s: attempt to set x509 cert which does not belong to the signer", p.name)
+	}
 
-	return nil
+	return x509Cert, nil
 }
 
 func (p *provider) UnsetCertKeyContent() {
+	p.SetAdditionalCABundleContent(nil)
 	p.setCertKeyContent(nil, nil)
+	ForgetExpiry(p.name)
+}
+
+func (p *provider) SetAdditionalCABundleContent(caBundlePEM []byte) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if bytes.Equal(p.additionalCABundlePEM, caBundlePEM) {
+		return
+	}
+
+	p.additionalCABundlePEM = caBundlePEM
+
+	for _, listener := range p.listeners {
+		listener.Enqueue()
+	}
 }
 
 func (p *provider) setCertKeyContent(certPEM, keyPEM []byte) {
@@ -129,8 +200,20 @@ func (p *provider) CurrentCABundleContent() []byte {
 		panic("*provider from NewServingCert was cast into wrong CA interface")
 	}
 
-	ca, _ := p.CurrentCertKeyContent()
-	return ca
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	// the additional CAs are only trusted while there is a current CA
+	if len(p.certPEM) == 0 || len(p.additionalCABundlePEM) == 0 {
+		return p.certPEM
+	}
+
+	bundle := make([]byte, 0, len(p.certPEM)+len(p.additionalCABundlePEM)+1)
+	bundle = append(bundle, p.certPEM...)
+	if !bytes.HasSuffix(bundle, []byte("\n")) {
+		bundle = append(bundle, '\n')
+	}
+	return append(bundle, p.additionalCABundlePEM...)
 }
 
 func (p *provider) VerifyOptions() (x509.VerifyOptions, bool) {
@@ -4,6 +4,9 @@
 package dynamiccert
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
 	"net"
@@ -242,6 +245,64 @@ func TestNewServingCert(t *testing.T) {
 	require.False(t, ok3, "NewServingCert must not implement Provider")
 }
 
+func TestNewCAWithSigner(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ca, err := certauthority.New("ca", time.Hour, certauthority.WithSigner(signer))
+	require.NoError(t, err)
+
+	p := NewCAWithSigner("test-ca-with-signer", signer)
+	require.NoError(t, p.SetCertKeyContent(ca.Bundle(), nil))
+	require.Equal(t, ca.Bundle(), p.CurrentCABundleContent())
+	gotCert, gotKey := p.CurrentCertKeyContent()
+	require.Equal(t, ca.Bundle(), gotCert)
+	require.Nil(t, gotKey)
+
+	otherCA, err := certauthority.New("other-ca", time.Hour)
+	require.NoError(t, err)
+	otherKey, err := otherCA.PrivateKeyToPEM()
+	require.NoError(t, err)
+	require.EqualError(t, p.SetCertKeyContent(otherCA.Bundle(), otherKey), "test-ca-with-signer: attempt to set x509 cert which does not belong to the signer")
+	require.EqualError(t, p.SetCertKeyContent([]byte("not a cert"), nil), "test-ca-with-signer: attempt to set invalid cert: failed to find any PEM certificate")
+	require.Equal(t, ca.Bundle(), p.CurrentCABundleContent())
+
+	p.UnsetCertKeyContent()
+}
+
+func TestAdditionalCABundleContent(t *testing.T) {
+	ca, err := certauthority.New("ca", time.Hour)
+	require.NoError(t, err)
+	caKey, err := ca.PrivateKeyToPEM()
+	require.NoError(t, err)
+
+	previousCA, err := certauthority.New("previous-ca", time.Hour)
+	require.NoError(t, err)
+
+	p := NewCA("test-ca")
+
+	// the additional CAs are not trusted without a current CA
+	p.SetAdditionalCABundleContent(previousCA.Bundle())
+	require.Nil(t, p.CurrentCABundleContent())
+
+	require.NoError(t, p.SetCertKeyContent(ca.Bundle(), caKey))
+	require.Equal(t, append(ca.Bundle(), previousCA.Bundle()...), p.CurrentCABundleContent())
+	gotCert, gotKey := p.CurrentCertKeyContent()
+	require.Equal(t, ca.Bundle(), gotCert)
+	require.Equal(t, caKey, gotKey)
+
+	p.SetAdditionalCABundleContent(nil)
+	require.Equal(t, ca.Bundle(), p.CurrentCABundleContent())
+
+	p.SetAdditionalCABundleContent(previousCA.Bundle())
+	p.UnsetCertKeyContent()
+	require.Nil(t, p.CurrentCABundleContent())
+	require.NoError(t, p.SetCertKeyContent(ca.Bundle(), caKey))
+	require.Equal(t, ca.Bundle(), p.CurrentCABundleContent())
+
+	p.UnsetCertKeyContent()
+}
+
 type fakeT struct{}
 
 func (fakeT) Errorf(string, ...interface{}) {}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package execcredcache
@@ -48,8 +48,9 @@ type (
 	}
 )
 
-// readCache loads a credCache from a path on disk. If the requested path does not exist, it returns an empty cache.
-func readCache(path string) (*credCache, error) {
+// readCache loads a credCache from a path on disk, decrypting it with the crypter if one is provided. If the requested
+// path does not exist, it returns an empty cache.
+func readCache(path string, crypter Crypter) (*credCache, error) {
 	cacheYAML, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -60,6 +61,13 @@ func readCache(path string) (*credCache, error) {
 		return nil, fmt.Errorf("could not read cache file: %w", err)
 	}
 
+	// Decrypt the file, if it should be encrypted.
+	if crypter != nil {
+		if cacheYAML, err = crypter.Decrypt(cacheYAML); err != nil {
+			return nil, fmt.Errorf("could not decrypt cache file: %w", err)
+		}
+	}
+
 	// If we read the file successfully, unmarshal it from YAML.
 	var cache credCache
 	if err := yaml.Unmarshal(cacheYAML, &cache); err != nil {
@@ -81,10 +89,13 @@ func emptyCache() *credCache {
 	}
 }
 
-// writeTo writes the cache to the specified file path.
-func (c *credCache) writeTo(path string) error {
+// writeTo writes the cache to the specified file path, encrypting it with the crypter if one is provided.
+func (c *credCache) writeTo(path string, crypter Crypter) error {
 	// Marshal the cache back to YAML and save it to the file.
 	cacheYAML, err := yaml.Marshal(c)
+	if err == nil && crypter != nil {
+		cacheYAML, err = crypter.Encrypt(cacheYAML)
+	}
 	if err == nil {
 		err = os.WriteFile(path, cacheYAML, 0600)
 	}
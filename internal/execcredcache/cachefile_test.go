@@ -75,7 +75,7 @@ func TestReadCache(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got, err := readCache(tt.path)
+			got, err := readCache(tt.path, nil)
 			if tt.wantErr != "" {
 				require.EqualError(t, err, tt.wantErr)
 				require.Nil(t, got)
@@ -102,13 +102,13 @@ func TestWriteTo(t *testing.T) {
 		t.Parallel()
 		tmp := testutil.TempDir(t) + "/credentials.yaml"
 		require.NoError(t, os.Mkdir(tmp, 0700))
-		err := validCache.writeTo(tmp)
+		err := validCache.writeTo(tmp, nil)
 		require.EqualError(t, err, "open "+tmp+": is a directory")
 	})
 
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
-		require.NoError(t, validCache.writeTo(testutil.TempDir(t)+"/credentials.yaml"))
+		require.NoError(t, validCache.writeTo(testutil.TempDir(t)+"/credentials.yaml", nil))
 	})
 }
 
@@ -1,4 +1,4 @@
-// Copyright 2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package execcredcache implements a cache for Kubernetes ExecCredential data.
@@ -28,16 +28,34 @@ const (
 	defaultFileLockRetryInterval = 10 * time.Millisecond
 )
 
+// Crypter encrypts and decrypts the contents of the cache file.
+type Crypter interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(data []byte) ([]byte, error)
+}
+
+// Option configures a cache in New().
+type Option func(*Cache)
+
+// WithCrypter is an Option that specifies a Crypter which will be used to encrypt the cache file. By default, the
+// cache file is not encrypted. Existing unencrypted cache files will be encrypted when next written.
+func WithCrypter(crypter Crypter) Option {
+	return func(c *Cache) {
+		c.crypter = crypter
+	}
+}
+
 type Cache struct {
 	path        string
+	crypter     Crypter
 	errReporter func(error)
 	trylockFunc func() error
 	unlockFunc  func() error
 }
 
-func New(path string) *Cache {
+func New(path string, options ...Option) *Cache {
 	lock := flock.New(path + ".lock")
-	return &Cache{
+	c := Cache{
 		path: path,
 		trylockFunc: func() error {
 			ctx, cancel := context.WithTimeout(context.Background(), defaultFileLockTimeout)
@@ -48,6 +66,10 @@ func New(path string) *Cache {
 		unlockFunc:  lock.Unlock,
 		errReporter: func(_ error) {},
 	}
+	for _, opt := range options {
+		opt(&c)
+	}
+	return &c
 }
 
 func (c *Cache) Get(key interface{}) *clientauthenticationv1beta1.ExecCredential {
@@ -111,6 +133,25 @@ func (c *Cache) Put(key interface{}, cred *clientauthenticationv1beta1.ExecCrede
 	})
 }
 
+// Delete removes the entry for the key from the cache, if there is one.
+func (c *Cache) Delete(key interface{}) {
+	// If the cache file does not exist, exit immediately with no error log
+	if _, err := os.Stat(c.path); errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	cacheKey := jsonSHA256Hex(key)
+	c.withCache(func(cache *credCache) {
+		entries := make([]entry, 0, len(cache.Entries))
+		for i := range cache.Entries {
+			if cache.Entries[i].Key != cacheKey {
+				entries = append(entries, cache.Entries[i])
+			}
+		}
+		cache.Entries = entries
+	})
+}
+
 func jsonSHA256Hex(key interface{}) string {
 	hash := sha256.New()
 	if err := json.NewEncoder(hash).Encode(key); err != nil {
@@ -136,7 +177,7 @@ func (c *Cache) withCache(transact func(*credCache)) {
 	}()
 
 	// Try to read the existing cache.
-	cache, err := readCache(c.path)
+	cache, err := readCache(c.path, c.crypter)
 	if err != nil {
 		// If that fails, fall back to resetting to a blank slate.
 		c.errReporter(fmt.Errorf("failed to read cache, resetting: %w", err))
@@ -153,7 +194,7 @@ func (c *Cache) withCache(transact func(*credCache)) {
 	cache = cache.normalized()
 
 	// Marshal the cache back to YAML and save it to the file.
-	if err := cache.writeTo(c.path); err != nil {
+	if err := cache.writeTo(c.path, c.crypter); err != nil {
 		c.errReporter(fmt.Errorf("could not write cache: %w", err))
 	}
 }
@@ -1,9 +1,10 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package execcredcache
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -102,7 +103,7 @@ func TestGet(t *testing.T) {
 						ExpirationTimestamp: &oneHourFromNow,
 					},
 				}}
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key:        testKey{K1: "v1", K2: "v2"},
 			wantErrors: []string{},
@@ -121,7 +122,7 @@ func TestGet(t *testing.T) {
 						ExpirationTimestamp: &oneMinuteAgo,
 					},
 				}}
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key:        testKey{K1: "v1", K2: "v2"},
 			wantErrors: []string{},
@@ -140,7 +141,7 @@ func TestGet(t *testing.T) {
 						ExpirationTimestamp: &oneHourFromNow,
 					},
 				}}
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key:        testKey{K1: "v1", K2: "v2"},
 			wantErrors: []string{},
@@ -156,7 +157,7 @@ func TestGet(t *testing.T) {
 				},
 			},
 			wantTestFile: func(t *testing.T, tmp string) {
-				cache, err := readCache(tmp)
+				cache, err := readCache(tmp, nil)
 				require.NoError(t, err)
 				require.Len(t, cache.Entries, 1)
 				require.Less(t, time.Since(cache.Entries[0].LastUsedTimestamp.Time).Nanoseconds(), (5 * time.Second).Nanoseconds())
@@ -243,7 +244,7 @@ func TestPutToken(t *testing.T) {
 					},
 				}
 				require.NoError(t, os.MkdirAll(filepath.Dir(tmp), 0700))
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key: testKey{K1: "v1", K2: "v2"},
 			cred: &clientauthenticationv1beta1.ExecCredential{
@@ -257,7 +258,7 @@ func TestPutToken(t *testing.T) {
 				},
 			},
 			wantTestFile: func(t *testing.T, tmp string) {
-				cache, err := readCache(tmp)
+				cache, err := readCache(tmp, nil)
 				require.NoError(t, err)
 				require.Len(t, cache.Entries, 1)
 				require.Less(t, time.Since(cache.Entries[0].LastUsedTimestamp.Time).Nanoseconds(), (5 * time.Second).Nanoseconds())
@@ -283,7 +284,7 @@ func TestPutToken(t *testing.T) {
 					},
 				}
 				require.NoError(t, os.MkdirAll(filepath.Dir(tmp), 0700))
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key: testKey{K1: "v1", K2: "v2"},
 			cred: &clientauthenticationv1beta1.ExecCredential{
@@ -297,7 +298,7 @@ func TestPutToken(t *testing.T) {
 				},
 			},
 			wantTestFile: func(t *testing.T, tmp string) {
-				cache, err := readCache(tmp)
+				cache, err := readCache(tmp, nil)
 				require.NoError(t, err)
 				require.Len(t, cache.Entries, 2)
 				require.Less(t, time.Since(cache.Entries[1].LastUsedTimestamp.Time).Nanoseconds(), (5 * time.Second).Nanoseconds())
@@ -359,6 +360,106 @@ func TestHashing(t *testing.T) {
 	require.Panics(t, func() { jsonSHA256Hex(&unmarshalable{}) })
 }
 
+func TestDelete(t *testing.T) {
+	t.Parallel()
+	tmp := testutil.TempDir(t) + "/credentials.yaml"
+	type testKey struct{ K1, K2 string }
+	cred := &clientauthenticationv1beta1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ExecCredential",
+			APIVersion: "client.authentication.k8s.io/v1beta1",
+		},
+		Status: &clientauthenticationv1beta1.ExecCredentialStatus{
+			Token:               "test-token",
+			ExpirationTimestamp: timePtr(time.Now().Add(1 * time.Hour).Round(1 * time.Second)),
+		},
+	}
+
+	// Deleting from a cache file which does not exist does nothing.
+	errors := errorCollector{t: t}
+	c := New(tmp)
+	c.errReporter = errors.report
+	c.Delete(testKey{K1: "v1"})
+	require.NoFileExists(t, tmp)
+
+	c.Put(testKey{K1: "v1"}, cred)
+	c.Put(testKey{K1: "v2"}, cred)
+	require.NotNil(t, c.Get(testKey{K1: "v1"}))
+
+	c.Delete(testKey{K1: "v1"})
+	require.Nil(t, c.Get(testKey{K1: "v1"}))
+	require.NotNil(t, c.Get(testKey{K1: "v2"}))
+
+	// Deleting a key which is not in the cache does nothing.
+	c.Delete(testKey{K1: "v3"})
+	require.NotNil(t, c.Get(testKey{K1: "v2"}))
+	errors.require([]string{})
+}
+
+func TestWithCrypter(t *testing.T) {
+	t.Parallel()
+	tmp := testutil.TempDir(t) + "/credentials.yaml"
+	type testKey struct{ K1, K2 string }
+	key := testKey{K1: "v1", K2: "v2"}
+	cred := &clientauthenticationv1beta1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ExecCredential",
+			APIVersion: "client.authentication.k8s.io/v1beta1",
+		},
+		Status: &clientauthenticationv1beta1.ExecCredentialStatus{
+			Token:               "test-token",
+			ExpirationTimestamp: timePtr(time.Now().Add(1 * time.Hour).Round(1 * time.Second)),
+		},
+	}
+
+	// Start with an unencrypted cache file, as written by a cache without a Crypter.
+	New(tmp).Put(key, cred)
+	fileContents, err := os.ReadFile(tmp)
+	require.NoError(t, err)
+	require.Contains(t, string(fileContents), "test-token")
+
+	// A cache with a Crypter can read the unencrypted file, and encrypts it when writing it back.
+	errors := errorCollector{t: t}
+	c := New(tmp, WithCrypter(&fakeCrypter{}))
+	c.errReporter = errors.report
+	require.Equal(t, cred.Status.Token, c.Get(key).Status.Token)
+	errors.require([]string{})
+	fileContents, err = os.ReadFile(tmp)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(fileContents), "encrypted:"))
+	require.NotContains(t, string(fileContents), "test-token")
+
+	// The encrypted file can be read back by a cache with the same Crypter.
+	require.Equal(t, cred.Status.Token, c.Get(key).Status.Token)
+	errors.require([]string{})
+
+	// A Crypter which fails to decrypt causes the cache to be reset.
+	errors = errorCollector{t: t}
+	c = New(tmp, WithCrypter(&fakeCrypter{decryptErr: fmt.Errorf("some decrypt error")}))
+	c.errReporter = errors.report
+	require.Nil(t, c.Get(key))
+	errors.require([]string{"failed to read cache, resetting: could not decrypt cache file: some decrypt error"})
+}
+
+// fakeCrypter is a Crypter which only base64 encodes, to make its output easy to recognize.
+type fakeCrypter struct {
+	decryptErr error
+}
+
+func (*fakeCrypter) Encrypt(plaintext []byte) ([]byte, error) {
+	return []byte("encrypted:" + base64.StdEncoding.EncodeToString(plaintext)), nil
+}
+
+func (c *fakeCrypter) Decrypt(data []byte) ([]byte, error) {
+	if c.decryptErr != nil {
+		return nil, c.decryptErr
+	}
+	if !strings.HasPrefix(string(data), "encrypted:") {
+		return data, nil
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimPrefix(string(data), "encrypted:"))
+}
+
 type errorCollector struct {
 	t   *testing.T
 	saw []error
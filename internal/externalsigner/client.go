@@ -0,0 +1,122 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package externalsigner
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+const (
+	unixScheme = "unix://"
+
+	ErrInvalidEndpoint = constable.Error("endpoint must be a unix domain socket, e.g. unix:///var/run/pinniped-signer/socket")
+)
+
+var _ crypto.Signer = &Signer{}
+
+// Signer is a crypto.Signer which signs with a key of an external signer plugin.
+type Signer struct {
+	// these fields are constant after struct initialization and thus do not need locking
+	conn      *grpc.ClientConn
+	endpoint  string
+	keyID     string
+	timeout   time.Duration
+	publicKey crypto.PublicKey
+}
+
+// New connects to the external signer plugin which listens on the given unix domain socket endpoint, e.g.
+// unix:///var/run/pinniped-signer/socket, and returns a Signer for the key with the given ID. The public key is
+// fetched once, so New fails when the plugin is not available yet. Each request to the plugin times out after the
+// given timeout.
+func New(ctx context.Context, endpoint, keyID string, timeout time.Duration) (*Signer, error) {
+	if err := validateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	conn, err := dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Signer{conn: conn, endpoint: endpoint, keyID: keyID, timeout: timeout}
+
+	s.publicKey, err = s.fetchPublicKey(ctx)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func dial(endpoint string) (*grpc.ClientConn, error) {
+	conn, err := grpc.Dial(endpoint,
+		// the socket is only reachable from within the pod, like the sockets of Kubernetes KMS plugins
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("external signer %s: could not connect: %w", endpoint, err)
+	}
+	return conn, nil
+}
+
+func (s *Signer) fetchPublicKey(ctx context.Context) (crypto.PublicKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	resp := &PublicKeyResponse{}
+	if err := s.conn.Invoke(ctx, publicKeyMethod, &PublicKeyRequest{KeyID: s.keyID}, resp); err != nil {
+		return nil, fmt.Errorf("external signer %s: could not get public key of key %q: %w", s.endpoint, s.keyID, err)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(resp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("external signer %s: could not parse public key of key %q: %w", s.endpoint, s.keyID, err)
+	}
+
+	return publicKey, nil
+}
+
+// Public returns the public key of the key of the plugin.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign asks the plugin to sign the digest. The rand argument is ignored, since the plugin uses its own source
+// of randomness.
+func (s *Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	req := &SignRequest{KeyID: s.keyID, Digest: digest, Hash: opts.HashFunc().String()}
+	if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+		saltLength := pssOpts.SaltLength
+		req.PSSSaltLength = &saltLength
+	}
+
+	// crypto.Signer does not take a context, so each request can only be bounded by the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	resp := &SignResponse{}
+	if err := s.conn.Invoke(ctx, signMethod, req, resp); err != nil {
+		return nil, fmt.Errorf("external signer %s: could not sign with key %q: %w", s.endpoint, s.keyID, err)
+	}
+
+	return resp.Signature, nil
+}
+
+// Close closes the connection to the plugin.
+func (s *Signer) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package externalsigner implements a crypto.Signer whose private key is held by an external signer plugin, e.g.
+// a plugin which signs with a key in an HSM or a cloud KMS, so that the key never needs to be stored in a Secret.
+// It also implements a KeyWrapper which encrypts small secrets, e.g. other keys, with a symmetric key of the plugin.
+//
+// The plugin is a gRPC server which listens on a unix domain socket, usually in a sidecar container which shares
+// the socket with Pinniped via an emptyDir volume. It serves the pinniped.externalsigner.v1alpha1.Signer service,
+// whose messages are encoded as JSON (i.e. using the application/grpc+json content type) rather than protobuf:
+//
+//	service Signer {
+//	  // PublicKey returns the PKIX, ASN.1 DER encoded public key of the key with the given ID.
+//	  rpc PublicKey(PublicKeyRequest) returns (PublicKeyResponse);
+//	  // Sign signs the digest with the key with the given ID. ECDSA signatures are ASN.1 DER encoded,
+//	  // RSA signatures use PKCS #1 v1.5 unless the request asks for PSS.
+//	  rpc Sign(SignRequest) returns (SignResponse);
+//	  // Encrypt encrypts the plaintext with the key with the given ID. Only plugins which hold symmetric keys,
+//	  // e.g. for envelope encryption, need to implement it.
+//	  rpc Encrypt(EncryptRequest) returns (EncryptResponse);
+//	  // Decrypt decrypts a ciphertext which was returned by Encrypt for the key with the given ID.
+//	  rpc Decrypt(DecryptRequest) returns (DecryptResponse);
+//	}
+//
+// Plugins which are written in Go can use RegisterServer to serve a Backend, and optionally an EncryptionBackend.
+package externalsigner
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	serviceName = "pinniped.externalsigner.v1alpha1.Signer"
+
+	publicKeyMethod = "/" + serviceName + "/PublicKey"
+	signMethod      = "/" + serviceName + "/Sign"
+	encryptMethod   = "/" + serviceName + "/Encrypt"
+	decryptMethod   = "/" + serviceName + "/Decrypt"
+)
+
+// PublicKeyRequest is the request of the PublicKey method.
+type PublicKeyRequest struct {
+	KeyID string `json:"keyID"`
+}
+
+// PublicKeyResponse is the response of the PublicKey method.
+type PublicKeyResponse struct {
+	// PublicKey is the PKIX, ASN.1 DER encoded public key.
+	PublicKey []byte `json:"publicKey"`
+}
+
+// SignRequest is the request of the Sign method.
+type SignRequest struct {
+	KeyID string `json:"keyID"`
+
+	// Digest is the digest of the message, which was hashed with Hash.
+	Digest []byte `json:"digest"`
+
+	// Hash is the name of the hash function, e.g. SHA-256, as returned by crypto.Hash.String.
+	Hash string `json:"hash"`
+
+	// PSSSaltLength is only set when an RSA-PSS signature is requested. See rsa.PSSOptions.
+	PSSSaltLength *int `json:"pssSaltLength,omitempty"`
+}
+
+// SignResponse is the response of the Sign method.
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// EncryptRequest is the request of the Encrypt method.
+type EncryptRequest struct {
+	KeyID     string `json:"keyID"`
+	Plaintext []byte `json:"plaintext"`
+}
+
+// EncryptResponse is the response of the Encrypt method.
+type EncryptResponse struct {
+	// Ciphertext is opaque to Pinniped. It may include e.g. the version of the key which encrypted the plaintext.
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// DecryptRequest is the request of the Decrypt method.
+type DecryptRequest struct {
+	KeyID      string `json:"keyID"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// DecryptResponse is the response of the Decrypt method.
+type DecryptResponse struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+// jsonCodec encodes the messages of the Signer service, so that the service does not depend on generated protobuf code.
+type jsonCodec struct{}
+
+var _ encoding.Codec = jsonCodec{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+func (jsonCodec) Name() string { return "json" }
+
+func serviceDesc() *grpc.ServiceDesc {
+	return &grpc.ServiceDesc{
+		ServiceName: serviceName,
+		HandlerType: (*Backend)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "PublicKey", Handler: publicKeyHandler},
+			{MethodName: "Sign", Handler: signHandler},
+			{MethodName: "Encrypt", Handler: encryptHandler},
+			{MethodName: "Decrypt", Handler: decryptHandler},
+		},
+		Streams: []grpc.StreamDesc{},
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package externalsigner
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/utils/pointer"
+
+	"go.pinniped.dev/internal/certauthority"
+)
+
+type fakeBackend struct {
+	keys map[string]crypto.Signer
+}
+
+func (b *fakeBackend) PublicKey(_ context.Context, keyID string) (crypto.PublicKey, error) {
+	key, ok := b.keys[keyID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown key %q", keyID)
+	}
+	return key.Public(), nil
+}
+
+func (b *fakeBackend) Sign(_ context.Context, keyID string, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	key, ok := b.keys[keyID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown key %q", keyID)
+	}
+	return key.Sign(rand.Reader, digest, opts)
+}
+
+// fakeEncryptionBackend also holds symmetric keys, which "encrypt" by prefixing the plaintext with the key.
+type fakeEncryptionBackend struct {
+	fakeBackend
+	symmetricKeys map[string][]byte
+}
+
+func (b *fakeEncryptionBackend) Encrypt(_ context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	key, ok := b.symmetricKeys[keyID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown key %q", keyID)
+	}
+	return append(append([]byte{}, key...), plaintext...), nil
+}
+
+func (b *fakeEncryptionBackend) Decrypt(_ context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	key, ok := b.symmetricKeys[keyID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown key %q", keyID)
+	}
+	if !bytes.HasPrefix(ciphertext, key) {
+		return nil, status.Error(codes.InvalidArgument, "invalid ciphertext")
+	}
+	return ciphertext[len(key):], nil
+}
+
+func startPlugin(t *testing.T, keys map[string]crypto.Signer) string {
+	t.Helper()
+
+	return startPluginWithBackend(t, &fakeBackend{keys: keys})
+}
+
+func startPluginWithBackend(t *testing.T, backend Backend) string {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "socket")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := grpc.NewServer(ServerOptions()...)
+	RegisterServer(server, backend)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return "unix://" + socket
+}
+
+func TestSigner(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	endpoint := startPlugin(t, map[string]crypto.Signer{"ec": ecKey, "rsa": rsaKey})
+	ctx := context.Background()
+	digest := sha256.Sum256([]byte("some message"))
+
+	t.Run("ecdsa", func(t *testing.T) {
+		signer, err := New(ctx, endpoint, "ec", time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, signer.Close()) })
+
+		require.Equal(t, &ecKey.PublicKey, signer.Public())
+
+		signature, err := signer.Sign(nil, digest[:], crypto.SHA256)
+		require.NoError(t, err)
+		require.True(t, ecdsa.VerifyASN1(&ecKey.PublicKey, digest[:], signature))
+	})
+
+	t.Run("rsa", func(t *testing.T) {
+		signer, err := New(ctx, endpoint, "rsa", time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, signer.Close()) })
+
+		require.Equal(t, &rsaKey.PublicKey, signer.Public())
+
+		signature, err := signer.Sign(nil, digest[:], crypto.SHA256)
+		require.NoError(t, err)
+		require.NoError(t, rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest[:], signature))
+
+		pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+		signature, err = signer.Sign(nil, digest[:], pssOpts)
+		require.NoError(t, err)
+		require.NoError(t, rsa.VerifyPSS(&rsaKey.PublicKey, crypto.SHA256, digest[:], signature, pssOpts))
+	})
+
+	t.Run("certificate authority", func(t *testing.T) {
+		signer, err := New(ctx, endpoint, "ec", time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, signer.Close()) })
+
+		ca, err := certauthority.New("test CA", time.Hour, certauthority.WithSigner(signer))
+		require.NoError(t, err)
+
+		cert, err := ca.IssueClientCert("test-user", nil, time.Hour)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		_, err = leaf.Verify(x509.VerifyOptions{Roots: ca.Pool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+		require.NoError(t, err)
+	})
+
+	t.Run("unsupported hash", func(t *testing.T) {
+		signer, err := New(ctx, endpoint, "ec", time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, signer.Close()) })
+
+		_, err = signer.Sign(nil, digest[:20], crypto.SHA1)
+		require.EqualError(t, err, `external signer `+endpoint+`: could not sign with key "ec": rpc error: code = InvalidArgument desc = unsupported hash "SHA-1"`)
+
+		_, err = signer.Sign(nil, digest[:20], crypto.SHA256)
+		require.EqualError(t, err, `external signer `+endpoint+`: could not sign with key "ec": rpc error: code = InvalidArgument desc = digest has length 20, but SHA-256 digests have length 32`)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := New(ctx, endpoint, "missing", time.Minute)
+		require.EqualError(t, err, `external signer `+endpoint+`: could not get public key of key "missing": rpc error: code = NotFound desc = unknown key "missing"`)
+	})
+
+	t.Run("plugin is not running", func(t *testing.T) {
+		missing := "unix://" + filepath.Join(t.TempDir(), "socket")
+		_, err := New(ctx, missing, "ec", time.Minute)
+		require.ErrorContains(t, err, `external signer `+missing+`: could not get public key of key "ec": rpc error: code = Unavailable`)
+	})
+
+	t.Run("invalid endpoint", func(t *testing.T) {
+		for _, endpoint := range []string{"", "unix://", "localhost:1234", "dns:///signer:1234"} {
+			_, err := New(ctx, endpoint, "ec", time.Minute)
+			require.ErrorIs(t, err, ErrInvalidEndpoint, endpoint)
+		}
+	})
+}
+
+func TestKeyWrapper(t *testing.T) {
+	ctx := context.Background()
+	endpoint := startPluginWithBackend(t, &fakeEncryptionBackend{symmetricKeys: map[string][]byte{"kek": []byte("some-kek")}})
+
+	t.Run("encrypt and decrypt", func(t *testing.T) {
+		wrapper, err := NewKeyWrapper(endpoint, "kek", time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, wrapper.Close()) })
+		require.Equal(t, "kek", wrapper.KeyID())
+
+		ciphertext, err := wrapper.Encrypt(ctx, []byte("some-dek"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("some-keksome-dek"), ciphertext)
+
+		plaintext, err := wrapper.Decrypt(ctx, ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, []byte("some-dek"), plaintext)
+
+		_, err = wrapper.Decrypt(ctx, []byte("tampered"))
+		require.EqualError(t, err, `external signer `+endpoint+`: could not decrypt with key "kek": rpc error: code = InvalidArgument desc = invalid ciphertext`)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		wrapper, err := NewKeyWrapper(endpoint, "missing", time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, wrapper.Close()) })
+
+		_, err = wrapper.Encrypt(ctx, []byte("some-dek"))
+		require.EqualError(t, err, `external signer `+endpoint+`: could not encrypt with key "missing": rpc error: code = NotFound desc = unknown key "missing"`)
+	})
+
+	t.Run("plugin without encryption", func(t *testing.T) {
+		signerOnly := startPlugin(t, nil)
+		wrapper, err := NewKeyWrapper(signerOnly, "kek", time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, wrapper.Close()) })
+
+		_, err = wrapper.Encrypt(ctx, []byte("some-dek"))
+		require.EqualError(t, err, `external signer `+signerOnly+`: could not encrypt with key "kek": rpc error: code = Unimplemented desc = this plugin does not support encryption`)
+		_, err = wrapper.Decrypt(ctx, []byte("some-ciphertext"))
+		require.EqualError(t, err, `external signer `+signerOnly+`: could not decrypt with key "kek": rpc error: code = Unimplemented desc = this plugin does not support encryption`)
+	})
+
+	t.Run("invalid endpoint", func(t *testing.T) {
+		_, err := NewKeyWrapper("localhost:1234", "kek", time.Minute)
+		require.ErrorIs(t, err, ErrInvalidEndpoint)
+	})
+}
+
+func TestSpec(t *testing.T) {
+	require.NoError(t, Spec{Endpoint: "unix:///some/socket", KeyID: "some-key"}.Validate())
+	require.NoError(t, Spec{Endpoint: "unix:///some/socket", KeyID: "some-key", TimeoutSeconds: pointer.Int64(1)}.Validate())
+	require.ErrorIs(t, Spec{Endpoint: "localhost:1234", KeyID: "some-key"}.Validate(), ErrInvalidEndpoint)
+	require.EqualError(t, Spec{Endpoint: "unix:///some/socket"}.Validate(), "keyID must not be empty")
+	require.EqualError(t, Spec{Endpoint: "unix:///some/socket", KeyID: "some-key", TimeoutSeconds: pointer.Int64(0)}.Validate(), "timeoutSeconds must be positive")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	endpoint := startPlugin(t, map[string]crypto.Signer{"some-key": key})
+
+	signer, err := Spec{Endpoint: endpoint, KeyID: "some-key"}.NewSigner(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, signer.Close()) })
+	require.Equal(t, defaultTimeout, signer.timeout)
+	require.Equal(t, &key.PublicKey, signer.Public())
+
+	wrapper, err := Spec{Endpoint: endpoint, KeyID: "some-key", TimeoutSeconds: pointer.Int64(3)}.NewKeyWrapper()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, wrapper.Close()) })
+	require.Equal(t, 3*time.Second, wrapper.timeout)
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package externalsigner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// KeyWrapper encrypts small secrets, e.g. data encryption keys, with a symmetric key of an external signer plugin.
+type KeyWrapper struct {
+	// these fields are constant after struct initialization and thus do not need locking
+	conn     *grpc.ClientConn
+	endpoint string
+	keyID    string
+	timeout  time.Duration
+}
+
+// NewKeyWrapper connects to the external signer plugin which listens on the given unix domain socket endpoint and
+// returns a KeyWrapper for the key with the given ID. Unlike New, it does not need the plugin to be available yet.
+// Each request to the plugin times out after the given timeout.
+func NewKeyWrapper(endpoint, keyID string, timeout time.Duration) (*KeyWrapper, error) {
+	if err := validateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	conn, err := dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyWrapper{conn: conn, endpoint: endpoint, keyID: keyID, timeout: timeout}, nil
+}
+
+// KeyID returns the ID of the key of the plugin.
+func (w *KeyWrapper) KeyID() string {
+	return w.keyID
+}
+
+// Encrypt asks the plugin to encrypt the plaintext.
+func (w *KeyWrapper) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	resp := &EncryptResponse{}
+	if err := w.conn.Invoke(ctx, encryptMethod, &EncryptRequest{KeyID: w.keyID, Plaintext: plaintext}, resp); err != nil {
+		return nil, fmt.Errorf("external signer %s: could not encrypt with key %q: %w", w.endpoint, w.keyID, err)
+	}
+
+	return resp.Ciphertext, nil
+}
+
+// Decrypt asks the plugin to decrypt a ciphertext which was returned by Encrypt.
+func (w *KeyWrapper) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	resp := &DecryptResponse{}
+	if err := w.conn.Invoke(ctx, decryptMethod, &DecryptRequest{KeyID: w.keyID, Ciphertext: ciphertext}, resp); err != nil {
+		return nil, fmt.Errorf("external signer %s: could not decrypt with key %q: %w", w.endpoint, w.keyID, err)
+	}
+
+	return resp.Plaintext, nil
+}
+
+// Close closes the connection to the plugin.
+func (w *KeyWrapper) Close() error {
+	return w.conn.Close()
+}
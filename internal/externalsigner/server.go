@@ -0,0 +1,176 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package externalsigner
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Backend is implemented by external signer plugins which are written in Go, e.g. by wrapping the client of a
+// cloud KMS. Errors which are gRPC status errors, e.g. a codes.NotFound error for an unknown key ID, are returned
+// to Pinniped as they are.
+type Backend interface {
+	PublicKey(ctx context.Context, keyID string) (crypto.PublicKey, error)
+	Sign(ctx context.Context, keyID string, digest []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// EncryptionBackend is optionally implemented by the Backends of plugins which also hold symmetric keys, e.g. the
+// key encryption key of the envelope encryption of the sessions of the Supervisor. The Encrypt and Decrypt methods
+// of plugins whose Backend does not implement it fail with codes.Unimplemented.
+type EncryptionBackend interface {
+	Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// ServerOptions returns the options which the gRPC server of a plugin must be created with, so that it
+// understands the JSON encoded messages of the Signer service.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.ForceServerCodec(jsonCodec{})}
+}
+
+// RegisterServer registers the Signer service of the backend with the gRPC server, which must have been
+// created with ServerOptions.
+func RegisterServer(server *grpc.Server, backend Backend) {
+	server.RegisterService(serviceDesc(), backend)
+}
+
+func publicKeyHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) { //nolint:revive // the signature is defined by grpc
+	req := &PublicKeyRequest{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return publicKey(ctx, srv.(Backend), req.(*PublicKeyRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: publicKeyMethod}, handler)
+}
+
+func signHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) { //nolint:revive // the signature is defined by grpc
+	req := &SignRequest{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return sign(ctx, srv.(Backend), req.(*SignRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: signMethod}, handler)
+}
+
+func encryptHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) { //nolint:revive // the signature is defined by grpc
+	req := &EncryptRequest{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return encrypt(ctx, srv.(Backend), req.(*EncryptRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: encryptMethod}, handler)
+}
+
+func decryptHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) { //nolint:revive // the signature is defined by grpc
+	req := &DecryptRequest{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return decrypt(ctx, srv.(Backend), req.(*DecryptRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: decryptMethod}, handler)
+}
+
+func publicKey(ctx context.Context, backend Backend, req *PublicKeyRequest) (*PublicKeyResponse, error) {
+	key, err := backend.PublicKey(ctx, req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not marshal public key: %v", err)
+	}
+
+	return &PublicKeyResponse{PublicKey: der}, nil
+}
+
+func sign(ctx context.Context, backend Backend, req *SignRequest) (*SignResponse, error) {
+	hash, ok := hashByName(req.Hash)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported hash %q", req.Hash)
+	}
+	if len(req.Digest) != hash.Size() {
+		return nil, status.Errorf(codes.InvalidArgument, "digest has length %d, but %s digests have length %d", len(req.Digest), req.Hash, hash.Size())
+	}
+
+	var opts crypto.SignerOpts = hash
+	if req.PSSSaltLength != nil {
+		opts = &rsa.PSSOptions{SaltLength: *req.PSSSaltLength, Hash: hash}
+	}
+
+	signature, err := backend.Sign(ctx, req.KeyID, req.Digest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignResponse{Signature: signature}, nil
+}
+
+func hashByName(name string) (crypto.Hash, bool) {
+	for _, hash := range []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		if hash.String() == name {
+			return hash, true
+		}
+	}
+	return 0, false
+}
+
+func encrypt(ctx context.Context, backend Backend, req *EncryptRequest) (*EncryptResponse, error) {
+	encryptionBackend, ok := backend.(EncryptionBackend)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "this plugin does not support encryption")
+	}
+
+	ciphertext, err := encryptionBackend.Encrypt(ctx, req.KeyID, req.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptResponse{Ciphertext: ciphertext}, nil
+}
+
+func decrypt(ctx context.Context, backend Backend, req *DecryptRequest) (*DecryptResponse, error) {
+	encryptionBackend, ok := backend.(EncryptionBackend)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "this plugin does not support encryption")
+	}
+
+	plaintext, err := encryptionBackend.Decrypt(ctx, req.KeyID, req.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecryptResponse{Plaintext: plaintext}, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package externalsigner
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+// defaultTimeout is how long a request to the plugin may take when the Spec does not configure a timeout.
+const defaultTimeout = 10 * time.Second
+
+// Spec configures a key of an external signer plugin.
+type Spec struct {
+	// Endpoint is the unix domain socket on which the plugin listens, e.g. unix:///var/run/pinniped-signer/socket.
+	Endpoint string `json:"endpoint"`
+
+	// KeyID identifies the key to the plugin, e.g. the resource name of a cloud KMS key or the label of an HSM key.
+	KeyID string `json:"keyID"`
+
+	// TimeoutSeconds is how long a request to the plugin may take. It defaults to 10 seconds.
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+}
+
+func (s Spec) Validate() error {
+	if err := validateEndpoint(s.Endpoint); err != nil {
+		return err
+	}
+	if s.KeyID == "" {
+		return constable.Error("keyID must not be empty")
+	}
+	if s.TimeoutSeconds != nil && *s.TimeoutSeconds <= 0 {
+		return constable.Error("timeoutSeconds must be positive")
+	}
+	return nil
+}
+
+// NewSigner connects to the plugin, see New.
+func (s Spec) NewSigner(ctx context.Context) (*Signer, error) {
+	return New(ctx, s.Endpoint, s.KeyID, s.timeout())
+}
+
+// NewKeyWrapper connects to the plugin, see NewKeyWrapper.
+func (s Spec) NewKeyWrapper() (*KeyWrapper, error) {
+	return NewKeyWrapper(s.Endpoint, s.KeyID, s.timeout())
+}
+
+func (s Spec) timeout() time.Duration {
+	if s.TimeoutSeconds != nil {
+		return time.Duration(*s.TimeoutSeconds) * time.Second
+	}
+	return defaultTimeout
+}
+
+func validateEndpoint(endpoint string) error {
+	if !strings.HasPrefix(endpoint, unixScheme) || len(endpoint) == len(unixScheme) {
+		return ErrInvalidEndpoint
+	}
+	return nil
+}
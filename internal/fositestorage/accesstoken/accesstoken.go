@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package accesstoken
@@ -54,10 +54,16 @@ func New(secrets corev1client.SecretInterface, clock func() time.Time, sessionSt
 	return &accessTokenStorage{storage: crud.New(TypeLabelValue, secrets, clock, sessionStorageLifetime)}
 }
 
-// ReadFromSecret reads the contents of a Secret as a Session.
-func ReadFromSecret(secret *v1.Secret) (*Session, error) {
+// NewWithBackend is like New, but keeps the sessions in the given storage backend instead of always using Secrets.
+func NewWithBackend(backend crud.Backend, clock func() time.Time, sessionStorageLifetime time.Duration) RevocationStorage {
+	return &accessTokenStorage{storage: backend.New(TypeLabelValue, clock, sessionStorageLifetime)}
+}
+
+// ReadFromSecret reads the contents of a Secret as a Session. The encrypter decrypts the Secret when the sessions are
+// encrypted, and may be nil otherwise.
+func ReadFromSecret(secret *v1.Secret, encrypter crud.Encrypter) (*Session, error) {
 	session := newValidEmptyAccessTokenSession()
-	err := crud.FromSecret(TypeLabelValue, secret, session)
+	err := crud.FromEncryptedSecret(TypeLabelValue, secret, session, encrypter)
 	if err != nil {
 		return nil, err
 	}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package authorizationcode
@@ -140,9 +140,13 @@ func TestAuthorizationCodeStorage(t *testing.T) {
 	require.Equal(t, wantActions, client.Actions())
 
 	// Doing a Get on an invalidated session should still return the session, but also return an error.
-	invalidatedRequest, err := storage.GetAuthorizeCodeSession(ctx, "fancy-signature", nil)
+	replayCtx, recorder := fositestorage.WithReplayRecorder(ctx)
+	invalidatedRequest, err := storage.GetAuthorizeCodeSession(replayCtx, "fancy-signature", nil)
 	require.EqualError(t, err, "authorization code session for fancy-signature has already been used: Authorization code has ben invalidated")
 	require.Equal(t, "abcd-1", invalidatedRequest.GetID())
+
+	// The reuse of the authorization code should have been recorded as a replay.
+	require.Equal(t, []fositestorage.Replay{{TokenType: fosite.AuthorizeCode, Request: invalidatedRequest}}, recorder.Replays())
 }
 
 func TestGetNotFound(t *testing.T) {
@@ -511,7 +515,7 @@ func TestReadFromSecret(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			session, err := ReadFromSecret(tt.secret)
+			session, err := ReadFromSecret(tt.secret, nil)
 			if tt.wantErr == "" {
 				require.NoError(t, err)
 				require.Equal(t, tt.wantSession, session)
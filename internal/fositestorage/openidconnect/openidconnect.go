@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package openidconnect
@@ -50,6 +50,11 @@ func New(secrets corev1client.SecretInterface, clock func() time.Time, sessionSt
 	return &openIDConnectRequestStorage{storage: crud.New(TypeLabelValue, secrets, clock, sessionStorageLifetime)}
 }
 
+// NewWithBackend is like New, but keeps the sessions in the given storage backend instead of always using Secrets.
+func NewWithBackend(backend crud.Backend, clock func() time.Time, sessionStorageLifetime time.Duration) openid.OpenIDConnectRequestStorage {
+	return &openIDConnectRequestStorage{storage: backend.New(TypeLabelValue, clock, sessionStorageLifetime)}
+}
+
 func (a *openIDConnectRequestStorage) CreateOpenIDConnectSession(ctx context.Context, authcode string, requester fosite.Requester) error {
 	signature, err := getSignature(authcode)
 	if err != nil {
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package pkce
@@ -48,6 +48,11 @@ func New(secrets corev1client.SecretInterface, clock func() time.Time, sessionSt
 	return &pkceStorage{storage: crud.New(TypeLabelValue, secrets, clock, sessionStorageLifetime)}
 }
 
+// NewWithBackend is like New, but keeps the sessions in the given storage backend instead of always using Secrets.
+func NewWithBackend(backend crud.Backend, clock func() time.Time, sessionStorageLifetime time.Duration) pkce.PKCERequestStorage {
+	return &pkceStorage{storage: backend.New(TypeLabelValue, clock, sessionStorageLifetime)}
+}
+
 func (a *pkceStorage) CreatePKCERequestSession(ctx context.Context, signature string, requester fosite.Requester) error {
 	request, err := fositestorage.ValidateAndExtractAuthorizeRequest(requester)
 	if err != nil {
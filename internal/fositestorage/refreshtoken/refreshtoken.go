@@ -1,10 +1,11 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package refreshtoken
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"time"
 
@@ -49,16 +50,25 @@ type refreshTokenStorage struct {
 type Session struct {
 	Request *fosite.Request `json:"request"`
 	Version string          `json:"version"`
+	// Rotated is true when the refresh token was already exchanged for a new refresh token. The session is kept until
+	// it expires so that a replay of the old refresh token can be detected instead of looking like an unknown token.
+	Rotated bool `json:"rotated,omitempty"`
 }
 
 func New(secrets corev1client.SecretInterface, clock func() time.Time, sessionStorageLifetime time.Duration) RevocationStorage {
 	return &refreshTokenStorage{storage: crud.New(TypeLabelValue, secrets, clock, sessionStorageLifetime)}
 }
 
-// ReadFromSecret reads the contents of a Secret as a Session.
-func ReadFromSecret(secret *v1.Secret) (*Session, error) {
+// NewWithBackend is like New, but keeps the sessions in the given storage backend instead of always using Secrets.
+func NewWithBackend(backend crud.Backend, clock func() time.Time, sessionStorageLifetime time.Duration) RevocationStorage {
+	return &refreshTokenStorage{storage: backend.New(TypeLabelValue, clock, sessionStorageLifetime)}
+}
+
+// ReadFromSecret reads the contents of a Secret as a Session. The encrypter decrypts the Secret when the sessions are
+// encrypted, and may be nil otherwise.
+func ReadFromSecret(secret *v1.Secret, encrypter crud.Encrypter) (*Session, error) {
 	session := newValidEmptyRefreshTokenSession()
-	err := crud.FromSecret(TypeLabelValue, secret, session)
+	err := crud.FromEncryptedSecret(TypeLabelValue, secret, session, encrypter)
 	if err != nil {
 		return nil, err
 	}
@@ -76,9 +86,23 @@ func (a *refreshTokenStorage) RevokeRefreshToken(ctx context.Context, requestID
 	return a.storage.DeleteByLabel(ctx, fositestorage.StorageRequestIDLabelName, requestID)
 }
 
-func (a *refreshTokenStorage) RevokeRefreshTokenMaybeGracePeriod(ctx context.Context, requestID string, signature string) error {
-	// We don't support a grace period, so always call the regular RevokeRefreshToken().
-	return a.RevokeRefreshToken(ctx, requestID)
+func (a *refreshTokenStorage) RevokeRefreshTokenMaybeGracePeriod(ctx context.Context, _ string, signature string) error {
+	// We don't support a grace period. Instead of deleting the old refresh token, mark it as rotated so that
+	// any later use of it is detected as a replay. Fosite will then revoke all tokens of the session.
+	session, rv, err := a.getSession(ctx, signature)
+	if err != nil {
+		return err
+	}
+
+	session.Rotated = true
+	if _, err := a.storage.Update(ctx, signature, rv, session); err != nil {
+		if errors.IsConflict(err) {
+			return &errSerializationFailureWithCause{cause: err}
+		}
+		return err
+	}
+
+	return nil
 }
 
 func (a *refreshTokenStorage) CreateRefreshTokenSession(ctx context.Context, signature string, requester fosite.Requester) error {
@@ -100,10 +124,15 @@ func (a *refreshTokenStorage) CreateRefreshTokenSession(ctx context.Context, sig
 func (a *refreshTokenStorage) GetRefreshTokenSession(ctx context.Context, signature string, _ fosite.Session) (fosite.Requester, error) {
 	session, _, err := a.getSession(ctx, signature)
 
-	if err != nil {
+	// we need to always pass both the request and error back
+	if session == nil {
 		return nil, err
 	}
 
+	if stderrors.Is(err, fosite.ErrInactiveToken) {
+		fositestorage.RecordReplay(ctx, fosite.RefreshToken, session.Request)
+	}
+
 	return session.Request, err
 }
 
@@ -132,6 +161,11 @@ func (a *refreshTokenStorage) getSession(ctx context.Context, signature string)
 		return nil, "", fmt.Errorf("malformed refresh token session for %s: %w", signature, ErrInvalidRefreshTokenRequestData)
 	}
 
+	// we must return the session in this case to allow fosite to revoke the associated tokens
+	if session.Rotated {
+		return session, rv, fmt.Errorf("refresh token session for %s has already been used: %w", signature, fosite.ErrInactiveToken)
+	}
+
 	return session, rv, nil
 }
 
@@ -143,3 +177,25 @@ func newValidEmptyRefreshTokenSession() *Session {
 		},
 	}
 }
+
+var _ interface {
+	Is(error) bool
+	Unwrap() error
+	error
+} = &errSerializationFailureWithCause{}
+
+type errSerializationFailureWithCause struct {
+	cause error
+}
+
+func (e *errSerializationFailureWithCause) Is(err error) bool {
+	return stderrors.Is(fosite.ErrSerializationFailure, err)
+}
+
+func (e *errSerializationFailureWithCause) Unwrap() error {
+	return e.cause
+}
+
+func (e *errSerializationFailureWithCause) Error() string {
+	return fmt.Sprintf("%s: %s", fosite.ErrSerializationFailure, e.cause)
+}
@@ -1,10 +1,11 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package refreshtoken
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"testing"
 	"time"
@@ -15,13 +16,16 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/fake"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	coretesting "k8s.io/client-go/testing"
 	clocktesting "k8s.io/utils/clock/testing"
 
+	"go.pinniped.dev/internal/fositestorage"
 	"go.pinniped.dev/internal/oidc/clientregistry"
 	"go.pinniped.dev/internal/psession"
 	"go.pinniped.dev/internal/testutil"
@@ -183,10 +187,27 @@ func TestRefreshTokenStorageRevokeRefreshTokenMaybeGracePeriod(t *testing.T) {
 			},
 			Type: "storage.pinniped.dev/refresh-token",
 		}),
-		coretesting.NewListAction(secretsGVR, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"}, namespace, metav1.ListOptions{
-			LabelSelector: "storage.pinniped.dev/type=refresh-token,storage.pinniped.dev/request-id=abcd-1",
+		coretesting.NewGetAction(secretsGVR, namespace, "pinniped-storage-refresh-token-pwu5zs7lekbhnln2w4"),
+		coretesting.NewGetAction(secretsGVR, namespace, "pinniped-storage-refresh-token-pwu5zs7lekbhnln2w4"),
+		coretesting.NewUpdateAction(secretsGVR, namespace, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "pinniped-storage-refresh-token-pwu5zs7lekbhnln2w4",
+				ResourceVersion: "",
+				Labels: map[string]string{
+					"storage.pinniped.dev/type":       "refresh-token",
+					"storage.pinniped.dev/request-id": "abcd-1",
+				},
+				Annotations: map[string]string{
+					"storage.pinniped.dev/garbage-collect-after": fakeNowPlusLifetimeAsString,
+				},
+			},
+			Data: map[string][]byte{
+				"pinniped-storage-data":    []byte(`{"request":{"id":"abcd-1","requestedAt":"0001-01-01T00:00:00Z","client":{"id":"pinny","redirect_uris":null,"grant_types":null,"response_types":null,"scopes":null,"audience":null,"public":true,"jwks_uri":"where","jwks":null,"token_endpoint_auth_method":"something","request_uris":null,"request_object_signing_alg":"","token_endpoint_auth_signing_alg":""},"scopes":null,"grantedScopes":null,"form":{"key":["val"]},"session":{"fosite":{"id_token_claims":null,"headers":null,"expires_at":null,"username":"snorlax","subject":"panda"},"custom":{"username":"fake-username","providerUID":"fake-provider-uid","providerName":"fake-provider-name","providerType":"fake-provider-type","warnings":null,"oidc":{"upstreamRefreshToken":"fake-upstream-refresh-token","upstreamAccessToken":"","upstreamSubject":"some-subject","upstreamIssuer":"some-issuer"}}},"requestedAudience":null,"grantedAudience":null},"version":"4","rotated":true}`),
+				"pinniped-storage-version": []byte("1"),
+			},
+			Type: "storage.pinniped.dev/refresh-token",
 		}),
-		coretesting.NewDeleteAction(secretsGVR, namespace, "pinniped-storage-refresh-token-pwu5zs7lekbhnln2w4"),
+		coretesting.NewGetAction(secretsGVR, namespace, "pinniped-storage-refresh-token-pwu5zs7lekbhnln2w4"),
 	}
 
 	ctx, client, _, storage := makeTestSubject()
@@ -210,15 +231,56 @@ func TestRefreshTokenStorageRevokeRefreshTokenMaybeGracePeriod(t *testing.T) {
 	err := storage.CreateRefreshTokenSession(ctx, "fancy-signature", request)
 	require.NoError(t, err)
 
-	// Revoke the request ID of the session that we just created. We don't support grace periods, so this
-	// should work exactly like the regular RevokeRefreshToken() function.
+	// Rotate the refresh token that we just created. We don't support grace periods, so this should
+	// immediately mark the refresh token as already used instead of deleting it.
 	err = storage.RevokeRefreshTokenMaybeGracePeriod(ctx, "abcd-1", "fancy-signature")
 	require.NoError(t, err)
 
+	// Doing a Get on a rotated session should still return the session, but also return an error.
+	replayCtx, recorder := fositestorage.WithReplayRecorder(ctx)
+	rotatedRequest, err := storage.GetRefreshTokenSession(replayCtx, "fancy-signature", nil)
+	require.EqualError(t, err, "refresh token session for fancy-signature has already been used: token_inactive")
+	require.True(t, errors.Is(err, fosite.ErrInactiveToken))
+	require.Equal(t, "abcd-1", rotatedRequest.GetID())
+
+	// The reuse of the refresh token should have been recorded as a replay.
+	require.Equal(t, []fositestorage.Replay{{TokenType: fosite.RefreshToken, Request: rotatedRequest}}, recorder.Replays())
+
 	testutil.LogActualJSONFromCreateAction(t, client, 0) // makes it easier to update expected values when needed
+	testutil.LogActualJSONFromUpdateAction(t, client, 3) // makes it easier to update expected values when needed
 	require.Equal(t, wantActions, client.Actions())
 }
 
+func TestRevokeRefreshTokenMaybeGracePeriodWhenNotFound(t *testing.T) {
+	ctx, _, _, storage := makeTestSubject()
+
+	notFoundErr := storage.RevokeRefreshTokenMaybeGracePeriod(ctx, "abcd-1", "non-existent-signature")
+	require.EqualError(t, notFoundErr, "not_found")
+	require.True(t, errors.Is(notFoundErr, fosite.ErrNotFound))
+}
+
+func TestRevokeRefreshTokenMaybeGracePeriodWhenConflictOnUpdateHappens(t *testing.T) {
+	ctx, client, _, storage := makeTestSubject()
+
+	client.PrependReactor("update", "secrets", func(_ coretesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(schema.GroupResource{
+			Group:    "",
+			Resource: "secrets",
+		}, "some-secret-name", fmt.Errorf("there was a conflict"))
+	})
+
+	request := &fosite.Request{
+		ID:      "some-request-id",
+		Client:  &clientregistry.Client{},
+		Session: testutil.NewFakePinnipedSession(),
+	}
+	err := storage.CreateRefreshTokenSession(ctx, "fancy-signature", request)
+	require.NoError(t, err)
+	err = storage.RevokeRefreshTokenMaybeGracePeriod(ctx, "some-request-id", "fancy-signature")
+	require.EqualError(t, err, `The request could not be completed due to concurrent access: failed to update refresh-token for signature fancy-signature at resource version : Operation cannot be fulfilled on secrets "some-secret-name": there was a conflict`)
+	require.True(t, errors.Is(err, fosite.ErrSerializationFailure))
+}
+
 func TestGetNotFound(t *testing.T) {
 	ctx, _, _, storage := makeTestSubject()
 
@@ -443,7 +505,7 @@ func TestReadFromSecret(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			session, err := ReadFromSecret(tt.secret)
+			session, err := ReadFromSecret(tt.secret, nil)
 			if tt.wantErr == "" {
 				require.NoError(t, err)
 				require.Equal(t, tt.wantSession, session)
@@ -0,0 +1,53 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fositestorage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ory/fosite"
+)
+
+// Replay describes a token which was used again after it had already been exchanged, e.g. an authorization code which
+// was already redeemed or a refresh token which was already rotated. Request is the stored request of the token, so its
+// ID is the ID of the session which the token belongs to.
+type Replay struct {
+	TokenType fosite.TokenType
+	Request   fosite.Requester
+}
+
+// ReplayRecorder collects the replays which the storage detects while it handles a request.
+type ReplayRecorder struct {
+	lock    sync.Mutex
+	replays []Replay
+}
+
+// Replays returns the replays which were recorded so far.
+func (r *ReplayRecorder) Replays() []Replay {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Replay(nil), r.replays...)
+}
+
+type replayRecorderKey struct{}
+
+// WithReplayRecorder returns a context which makes the storage record any replays which it detects while it is called
+// with the context, and the recorder which collects them. fosite only reports replays as errors, so this is how the
+// token endpoint learns which session a replayed token belonged to.
+func WithReplayRecorder(ctx context.Context) (context.Context, *ReplayRecorder) {
+	recorder := &ReplayRecorder{}
+	return context.WithValue(ctx, replayRecorderKey{}, recorder), recorder
+}
+
+// RecordReplay records a replay with the recorder of the context. It does nothing when the context has no recorder.
+func RecordReplay(ctx context.Context, tokenType fosite.TokenType, request fosite.Requester) {
+	recorder, ok := ctx.Value(replayRecorderKey{}).(*ReplayRecorder)
+	if !ok || request == nil {
+		return
+	}
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	recorder.replays = append(recorder.replays, Replay{TokenType: tokenType, Request: request})
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package grouptransform transforms the names of the groups of users from upstream identity providers, before they
+// become the groups of the users in the Supervisor's sessions and tokens.
+package grouptransform
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+)
+
+const (
+	// groupVariableName is the name of the variable which holds the group name in CEL expressions.
+	groupVariableName = "group"
+
+	// expressionCostLimit limits the runtime cost of each evaluation of a CEL expression, so that an expensive
+	// expression cannot slow down the logins of users who are members of many groups.
+	expressionCostLimit = 100_000
+)
+
+// Transformations is an ordered list of compiled group transformation rules. The nil value does not change any groups.
+type Transformations []transformation
+
+// transformation transforms one group name. An empty result means that the group should be removed.
+type transformation func(group string) (string, error)
+
+// Compile compiles the group transformation rules of the spec of an identity provider. The returned error describes
+// all the invalid rules.
+func Compile(rules []v1alpha1.GroupTransformation) (Transformations, error) {
+	var errs []error
+	var transformations Transformations
+	for i, rule := range rules {
+		t, err := compile(rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("transformations[%d]: %w", i, err))
+			continue
+		}
+		transformations = append(transformations, t)
+	}
+	if err := errors.NewAggregate(errs); err != nil {
+		return nil, err
+	}
+	return transformations, nil
+}
+
+// Apply transforms the given group names in order. Groups which are removed by a rule are not passed to the
+// following rules, and duplicate group names which result from the rules are removed.
+func (t Transformations) Apply(groups []string) ([]string, error) {
+	if len(t) == 0 {
+		return groups, nil
+	}
+
+	seen := sets.NewString()
+	transformedGroups := make([]string, 0, len(groups))
+	for _, group := range groups {
+		transformedGroup, err := t.applyToGroup(group)
+		if err != nil {
+			return nil, fmt.Errorf("could not transform group %q: %w", group, err)
+		}
+		if transformedGroup == "" || seen.Has(transformedGroup) {
+			continue
+		}
+		seen.Insert(transformedGroup)
+		transformedGroups = append(transformedGroups, transformedGroup)
+	}
+	return transformedGroups, nil
+}
+
+func (t Transformations) applyToGroup(group string) (string, error) {
+	var err error
+	for _, transform := range t {
+		group, err = transform(group)
+		if err != nil {
+			return "", err
+		}
+		if group == "" {
+			break
+		}
+	}
+	return group, nil
+}
+
+func compile(rule v1alpha1.GroupTransformation) (transformation, error) {
+	switch rule.Type {
+	case v1alpha1.GroupTransformationTypeStripDN:
+		return stripDN, nil
+
+	case v1alpha1.GroupTransformationTypeReplace:
+		pattern, err := compilePattern(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		return func(group string) (string, error) {
+			match := pattern.FindStringSubmatchIndex(group)
+			if match == nil {
+				return group, nil
+			}
+			return string(pattern.ExpandString(nil, rule.Replacement, group, match)), nil
+		}, nil
+
+	case v1alpha1.GroupTransformationTypeDrop:
+		pattern, err := compilePattern(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		return func(group string) (string, error) {
+			if pattern.MatchString(group) {
+				return "", nil
+			}
+			return group, nil
+		}, nil
+
+	case v1alpha1.GroupTransformationTypeExpression:
+		return compileExpression(rule.Expression)
+
+	default:
+		return nil, fmt.Errorf("unsupported type %q", rule.Type)
+	}
+}
+
+// stripDN replaces a group name which is a distinguished name by the value of its first relative distinguished name.
+func stripDN(group string) (string, error) {
+	dn, err := ldap.ParseDN(group)
+	if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+		return group, nil // not a distinguished name
+	}
+	return dn.RDNs[0].Attributes[0].Value, nil
+}
+
+// compilePattern compiles a pattern which must match whole group names.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	// Compile the pattern as written first, so that the error message does not show how it gets anchored.
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, fmt.Errorf("pattern is not a valid regular expression: %w", err)
+	}
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+func compileExpression(expression string) (transformation, error) {
+	if expression == "" {
+		return nil, fmt.Errorf("expression is required")
+	}
+
+	env, err := cel.NewEnv(cel.Variable(groupVariableName, cel.StringType), ext.Strings())
+	if err != nil {
+		return nil, fmt.Errorf("could not create CEL environment: %w", err)
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("expression is not a valid CEL expression: %w", issues.Err())
+	}
+	if !cel.StringType.IsAssignableType(ast.OutputType()) {
+		return nil, fmt.Errorf("expression must result in a string, but results in %s", ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(expressionCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("could not compile expression: %w", err)
+	}
+
+	return func(group string) (string, error) {
+		result, _, err := program.Eval(map[string]interface{}{groupVariableName: group})
+		if err != nil {
+			return "", fmt.Errorf("could not evaluate expression: %w", err)
+		}
+		transformedGroup, ok := result.Value().(string)
+		if !ok {
+			return "", fmt.Errorf("expression must result in a string, but resulted in %s", result.Type().TypeName())
+		}
+		return transformedGroup, nil
+	}, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grouptransform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+)
+
+func TestTransformations(t *testing.T) {
+	tests := []struct {
+		name            string
+		rules           []v1alpha1.GroupTransformation
+		groups          []string
+		wantGroups      []string
+		wantCompileErr  string
+		wantApplyErr    string
+		wantNilCompiled bool
+	}{
+		{
+			name:            "no rules do not change the groups",
+			groups:          []string{"b", "a", "a", ""},
+			wantGroups:      []string{"b", "a", "a", ""},
+			wantNilCompiled: true,
+		},
+		{
+			name:       "strip DN",
+			rules:      []v1alpha1.GroupTransformation{{Type: v1alpha1.GroupTransformationTypeStripDN}},
+			groups:     []string{"cn=admins,ou=groups,dc=example,dc=com", `CN=Domain\2c Admins,DC=example,DC=com`, "not a DN"},
+			wantGroups: []string{"admins", "Domain, Admins", "not a DN"},
+		},
+		{
+			name: "replace with submatches",
+			rules: []v1alpha1.GroupTransformation{
+				{Type: v1alpha1.GroupTransformationTypeReplace, Pattern: "k8s-(.*)", Replacement: "$1"},
+				{Type: v1alpha1.GroupTransformationTypeReplace, Pattern: "(?P<team>.*)-team", Replacement: "team:${team}"},
+			},
+			groups:     []string{"k8s-admins", "web-team", "k8s-db-team", "not-k8s-admins"},
+			wantGroups: []string{"admins", "team:web", "team:db", "not-k8s-admins"},
+		},
+		{
+			name: "replace with an empty replacement removes the group",
+			rules: []v1alpha1.GroupTransformation{
+				{Type: v1alpha1.GroupTransformationTypeReplace, Pattern: "ignored-.*", Replacement: ""},
+			},
+			groups:     []string{"ignored-group", "group"},
+			wantGroups: []string{"group"},
+		},
+		{
+			name: "drop",
+			rules: []v1alpha1.GroupTransformation{
+				{Type: v1alpha1.GroupTransformationTypeDrop, Pattern: "Domain Users|.*-legacy"},
+			},
+			groups:     []string{"Domain Users", "Domain Users Extra", "app-legacy", "app"},
+			wantGroups: []string{"Domain Users Extra", "app"},
+		},
+		{
+			name: "expression",
+			rules: []v1alpha1.GroupTransformation{
+				{Type: v1alpha1.GroupTransformationTypeExpression, Expression: `group.startsWith("k8s-") ? group.substring(4).upperAscii() : ""`},
+			},
+			groups:     []string{"k8s-admins", "other"},
+			wantGroups: []string{"ADMINS"},
+		},
+		{
+			name: "rules are applied in order and duplicate results are removed",
+			rules: []v1alpha1.GroupTransformation{
+				{Type: v1alpha1.GroupTransformationTypeStripDN},
+				{Type: v1alpha1.GroupTransformationTypeDrop, Pattern: "Domain Users"},
+				{Type: v1alpha1.GroupTransformationTypeExpression, Expression: "group.lowerAscii()"},
+			},
+			groups: []string{
+				"CN=Admins,OU=Groups,DC=example,DC=com",
+				"CN=Domain Users,CN=Users,DC=example,DC=com",
+				"cn=admins,ou=other,dc=example,dc=com",
+				"Developers",
+			},
+			wantGroups: []string{"admins", "developers"},
+		},
+		{
+			name: "invalid rules",
+			rules: []v1alpha1.GroupTransformation{
+				{Type: v1alpha1.GroupTransformationTypeStripDN},
+				{Type: v1alpha1.GroupTransformationTypeReplace},
+				{Type: v1alpha1.GroupTransformationTypeDrop, Pattern: "("},
+				{Type: v1alpha1.GroupTransformationTypeExpression},
+				{Type: v1alpha1.GroupTransformationTypeExpression, Expression: "group +"},
+				{Type: v1alpha1.GroupTransformationTypeExpression, Expression: "size(group)"},
+				{Type: "Other"},
+			},
+			wantCompileErr: "[" +
+				"transformations[1]: pattern is required, " +
+				"transformations[2]: pattern is not a valid regular expression: error parsing regexp: missing closing ): `(`, " +
+				"transformations[3]: expression is required, " +
+				"transformations[4]: expression is not a valid CEL expression: ERROR: <input>:1:8: Syntax error: mismatched input '<EOF>' expecting {'[', '{', '(', '.', '-', '!', 'true', 'false', 'null', NUM_FLOAT, NUM_INT, NUM_UINT, STRING, BYTES, IDENTIFIER}\n" +
+				" | group +\n" +
+				" | .......^, " +
+				"transformations[5]: expression must result in a string, but results in int, " +
+				`transformations[6]: unsupported type "Other"` +
+				"]",
+		},
+		{
+			name: "expression which fails to evaluate",
+			rules: []v1alpha1.GroupTransformation{
+				{Type: v1alpha1.GroupTransformationTypeExpression, Expression: "group.substring(10)"},
+			},
+			groups:       []string{"admins"},
+			wantApplyErr: `could not transform group "admins": could not evaluate expression: index out of range: 10`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			transformations, err := Compile(tt.rules)
+			if tt.wantCompileErr != "" {
+				require.EqualError(t, err, tt.wantCompileErr)
+				require.Nil(t, transformations)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNilCompiled {
+				require.Nil(t, transformations)
+			}
+
+			groups, err := transformations.Apply(tt.groups)
+			if tt.wantApplyErr != "" {
+				require.EqualError(t, err, tt.wantApplyErr)
+				require.Nil(t, groups)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantGroups, groups)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clientip implements an HTTP middleware which determines the IP address and protocol used by the client
+// which made a request, honoring the X-Forwarded-For and X-Forwarded-Proto headers only when the request was sent
+// by a trusted proxy, e.g. the Ingress in front of the Supervisor.
+package clientip
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Info describes the client which made a request.
+type Info struct {
+	// IP is the address of the client, or empty when it is not known, e.g. for requests over a Unix domain socket.
+	IP string
+	// Proto is the protocol used by the client, either "http" or "https", or empty when it is not known, e.g. for
+	// plain HTTP requests from a service mesh sidecar which is not a trusted proxy and may have terminated TLS itself.
+	Proto string
+}
+
+type contextKey struct{}
+
+// Wrap the provided http.Handler so that the Info of each request can be retrieved from its context using
+// FromContext. The X-Forwarded-For and X-Forwarded-Proto headers are ignored unless the request was sent from one of
+// the trustedProxies. Proxies are only trusted when they connect over TCP.
+func Wrap(wrapped http.Handler, trustedProxies []*net.IPNet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := resolve(r, trustedProxies)
+		wrapped.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, info)))
+	})
+}
+
+// FromContext returns the Info of the request whose context is ctx, or an empty Info when the request was not
+// handled by Wrap.
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
+
+func resolve(r *http.Request, trustedProxies []*net.IPNet) Info {
+	info := Info{IP: remoteIP(r.RemoteAddr)}
+	if r.TLS != nil {
+		info.Proto = "https"
+	}
+
+	if !isTrusted(info.IP, trustedProxies) {
+		return info
+	}
+
+	// Each proxy appends the address of its own client, so walk the list from the nearest proxy to the farthest
+	// and stop at the first address which is not another trusted proxy. Only that address can be believed.
+	forwardedFor := splitHeader(r.Header.Values("X-Forwarded-For"))
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		ip := net.ParseIP(forwardedFor[i])
+		if ip == nil {
+			break // a malformed entry means that nothing further along the list can be believed either
+		}
+		info.IP = ip.String()
+		if !isTrusted(info.IP, trustedProxies) {
+			break
+		}
+	}
+
+	// The nearest proxy decides which protocol was used by its client. Without this header, a plain HTTP request
+	// from a trusted proxy still says nothing about whether the client used TLS.
+	if forwardedProto := splitHeader(r.Header.Values("X-Forwarded-Proto")); len(forwardedProto) != 0 {
+		switch proto := strings.ToLower(forwardedProto[len(forwardedProto)-1]); proto {
+		case "http", "https":
+			info.Proto = proto
+		}
+	}
+
+	return info
+}
+
+// remoteIP returns the IP of a request's RemoteAddr, or an empty string when it is not an IP address.
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+func isTrusted(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, trusted := range trustedProxies {
+		if trusted.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitHeader returns the comma separated elements of all values of a header, in order.
+func splitHeader(values []string) []string {
+	var elements []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			if element = strings.TrimSpace(element); element != "" {
+				elements = append(elements, element)
+			}
+		}
+	}
+	return elements
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clientip
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	trustedProxies := []*net.IPNet{mustParseCIDR(t, "10.0.0.0/8"), mustParseCIDR(t, "fd00::/8")}
+
+	for _, tt := range []struct {
+		name           string
+		remoteAddr     string
+		tls            bool
+		headers        http.Header
+		trustedProxies []*net.IPNet
+		wantInfo       Info
+	}{
+		{
+			name:           "direct request",
+			remoteAddr:     "203.0.113.1:12345",
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "203.0.113.1"},
+		},
+		{
+			name:           "direct request over TLS",
+			remoteAddr:     "203.0.113.1:12345",
+			tls:            true,
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "203.0.113.1", Proto: "https"},
+		},
+		{
+			name:           "request over a Unix domain socket",
+			remoteAddr:     "@",
+			headers:        http.Header{"X-Forwarded-For": {"203.0.113.1"}},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: ""},
+		},
+		{
+			name:       "forwarded headers from an untrusted client are ignored",
+			remoteAddr: "203.0.113.1:12345",
+			tls:        true,
+			headers: http.Header{
+				"X-Forwarded-For":   {"198.51.100.1"},
+				"X-Forwarded-Proto": {"http"},
+			},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "203.0.113.1", Proto: "https"},
+		},
+		{
+			name:       "forwarded headers are ignored when no proxies are trusted",
+			remoteAddr: "10.1.2.3:12345",
+			headers: http.Header{
+				"X-Forwarded-For":   {"198.51.100.1"},
+				"X-Forwarded-Proto": {"https"},
+			},
+			wantInfo: Info{IP: "10.1.2.3"},
+		},
+		{
+			name:       "forwarded headers from a trusted proxy",
+			remoteAddr: "10.1.2.3:12345",
+			headers: http.Header{
+				"X-Forwarded-For":   {"198.51.100.1"},
+				"X-Forwarded-Proto": {"https"},
+			},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "198.51.100.1", Proto: "https"},
+		},
+		{
+			name:       "forwarded headers from a trusted IPv6 proxy",
+			remoteAddr: "[fd00::1]:12345",
+			headers: http.Header{
+				"X-Forwarded-For": {"2001:db8::1"},
+			},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "2001:db8::1"},
+		},
+		{
+			name:       "addresses spoofed by the client before the trusted proxies are ignored",
+			remoteAddr: "10.1.2.3:12345",
+			headers: http.Header{
+				"X-Forwarded-For": {"192.0.2.1, 198.51.100.1", "10.4.5.6"},
+			},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "198.51.100.1"},
+		},
+		{
+			name:       "all forwarded addresses are trusted proxies",
+			remoteAddr: "10.1.2.3:12345",
+			headers: http.Header{
+				"X-Forwarded-For": {"10.7.8.9, 10.4.5.6"},
+			},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "10.7.8.9"},
+		},
+		{
+			name:       "malformed forwarded address",
+			remoteAddr: "10.1.2.3:12345",
+			headers: http.Header{
+				"X-Forwarded-For": {"198.51.100.1, not-an-ip, 10.4.5.6"},
+			},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "10.4.5.6"},
+		},
+		{
+			name:       "the nearest proxy decides the protocol",
+			remoteAddr: "10.1.2.3:12345",
+			headers: http.Header{
+				"X-Forwarded-Proto": {"http, HTTPS"},
+			},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "10.1.2.3", Proto: "https"},
+		},
+		{
+			name:       "plain HTTP forwarded by a trusted proxy",
+			remoteAddr: "10.1.2.3:12345",
+			headers: http.Header{
+				"X-Forwarded-Proto": {"http"},
+			},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "10.1.2.3", Proto: "http"},
+		},
+		{
+			name:       "unknown forwarded protocol",
+			remoteAddr: "10.1.2.3:12345",
+			tls:        true,
+			headers: http.Header{
+				"X-Forwarded-Proto": {"gopher"},
+			},
+			trustedProxies: trustedProxies,
+			wantInfo:       Info{IP: "10.1.2.3", Proto: "https"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var gotInfo *Info
+			handler := Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				info := FromContext(r.Context())
+				gotInfo = &info
+			}), tt.trustedProxies)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header = tt.headers
+			if !tt.tls {
+				req.TLS = nil
+			} else {
+				req.TLS = &tls.ConnectionState{}
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.NotNil(t, gotInfo)
+			require.Equal(t, tt.wantInfo, *gotInfo)
+		})
+	}
+}
+
+func TestFromContextWithoutWrap(t *testing.T) {
+	require.Equal(t, Info{}, FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()))
+}
+
+func mustParseCIDR(t *testing.T, cidr string) *net.IPNet {
+	t.Helper()
+	_, ipNet, err := net.ParseCIDR(cidr)
+	require.NoError(t, err)
+	return ipNet
+}
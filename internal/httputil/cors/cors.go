@@ -0,0 +1,49 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cors implements an HTTP middleware which allows browser-based applications served from other origins,
+// e.g. single-page apps, to make cross-origin requests to an endpoint.
+package cors
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Wrap the provided http.Handler so that cross-origin requests using the given methods are allowed from each of the
+// allowedOrigins, e.g. "https://app.example.com". Credentials such as cookies are never allowed. When there are no
+// allowedOrigins, the provided http.Handler is returned unchanged.
+func Wrap(wrapped http.Handler, allowedOrigins []string, allowedMethods ...string) http.Handler {
+	if len(allowedOrigins) == 0 {
+		return wrapped
+	}
+
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.ToLower(origin)] = true
+	}
+	methods := strings.Join(allowedMethods, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The response depends on the Origin header, so caches must not share it between origins.
+		w.Header().Add("Vary", "Origin")
+
+		origin := r.Header.Get("Origin")
+		if origin == "" || !allowed[strings.ToLower(origin)] {
+			wrapped.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		// Answer preflight requests without calling the wrapped handler.
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		wrapped.ServeHTTP(w, r)
+	})
+}
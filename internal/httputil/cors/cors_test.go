@@ -0,0 +1,125 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	allowedOrigins := []string{"https://app.example.com", "http://localhost:3000"}
+
+	for _, tt := range []struct {
+		name           string
+		allowedOrigins []string
+		method         string
+		headers        http.Header
+		wantStatus     int
+		wantHeaders    http.Header
+		wantWrapped    bool
+	}{
+		{
+			name:           "no allowed origins",
+			allowedOrigins: nil,
+			method:         http.MethodGet,
+			headers:        http.Header{"Origin": {"https://app.example.com"}},
+			wantStatus:     http.StatusOK,
+			wantHeaders:    http.Header{},
+			wantWrapped:    true,
+		},
+		{
+			name:           "same-origin request without an Origin header",
+			allowedOrigins: allowedOrigins,
+			method:         http.MethodGet,
+			wantStatus:     http.StatusOK,
+			wantHeaders:    http.Header{"Vary": {"Origin"}},
+			wantWrapped:    true,
+		},
+		{
+			name:           "request from an allowed origin",
+			allowedOrigins: allowedOrigins,
+			method:         http.MethodPost,
+			headers:        http.Header{"Origin": {"https://app.example.com"}},
+			wantStatus:     http.StatusOK,
+			wantHeaders: http.Header{
+				"Vary":                        {"Origin"},
+				"Access-Control-Allow-Origin": {"https://app.example.com"},
+			},
+			wantWrapped: true,
+		},
+		{
+			name:           "request from an allowed origin with different case",
+			allowedOrigins: allowedOrigins,
+			method:         http.MethodGet,
+			headers:        http.Header{"Origin": {"http://LOCALHOST:3000"}},
+			wantStatus:     http.StatusOK,
+			wantHeaders: http.Header{
+				"Vary":                        {"Origin"},
+				"Access-Control-Allow-Origin": {"http://LOCALHOST:3000"},
+			},
+			wantWrapped: true,
+		},
+		{
+			name:           "request from another origin",
+			allowedOrigins: allowedOrigins,
+			method:         http.MethodPost,
+			headers:        http.Header{"Origin": {"https://evil.example.com"}},
+			wantStatus:     http.StatusOK,
+			wantHeaders:    http.Header{"Vary": {"Origin"}},
+			wantWrapped:    true,
+		},
+		{
+			name:           "preflight request from an allowed origin",
+			allowedOrigins: allowedOrigins,
+			method:         http.MethodOptions,
+			headers: http.Header{
+				"Origin":                        {"https://app.example.com"},
+				"Access-Control-Request-Method": {"POST"},
+			},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: http.Header{
+				"Vary":                         {"Origin"},
+				"Access-Control-Allow-Origin":  {"https://app.example.com"},
+				"Access-Control-Allow-Methods": {"GET, POST"},
+				"Access-Control-Allow-Headers": {"Authorization, Content-Type"},
+			},
+		},
+		{
+			name:           "preflight request from another origin",
+			allowedOrigins: allowedOrigins,
+			method:         http.MethodOptions,
+			headers: http.Header{
+				"Origin":                        {"https://evil.example.com"},
+				"Access-Control-Request-Method": {"POST"},
+			},
+			wantStatus:  http.StatusOK,
+			wantHeaders: http.Header{"Vary": {"Origin"}},
+			wantWrapped: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			wrappedCalled := false
+			handler := Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wrappedCalled = true
+			}), tt.allowedOrigins, http.MethodGet, http.MethodPost)
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			req.Header = tt.headers
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+			rsp := httptest.NewRecorder()
+			handler.ServeHTTP(rsp, req)
+
+			require.Equal(t, tt.wantStatus, rsp.Code)
+			require.Equal(t, tt.wantHeaders, rsp.Header())
+			require.Equal(t, tt.wantWrapped, wrappedCalled)
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package kubeclient
@@ -11,6 +11,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextensionsclientscheme "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/client-go/kubernetes"
@@ -29,6 +31,7 @@ import (
 type Client struct {
 	Kubernetes         kubernetes.Interface
 	Aggregation        aggregatorclient.Interface
+	APIExtensions      apiextensionsclient.Interface
 	PinnipedConcierge  pinnipedconciergeclientset.Interface
 	PinnipedSupervisor pinnipedsupervisorclientset.Interface
 
@@ -55,6 +58,7 @@ func New(opts ...Option) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not create secure client config: %w", err)
 	}
+	c.rateLimit.apply(secureKubeConfig)
 
 	// explicitly use json when talking to CRD APIs
 	jsonKubeConfig := createJSONKubeConfig(secureKubeConfig)
@@ -63,21 +67,27 @@ func New(opts ...Option) (*Client, error) {
 	protoKubeConfig := createProtoKubeConfig(secureKubeConfig)
 
 	// Connect to the core Kubernetes API.
-	k8sClient, err := kubernetes.NewForConfig(configWithWrapper(protoKubeConfig, kubescheme.Scheme, kubescheme.Codecs, c.middlewares, c.transportWrapper))
+	k8sClient, err := kubernetes.NewForConfig(configWithWrapper(protoKubeConfig, kubescheme.Scheme, kubescheme.Codecs, c.middlewares, c.transportWrapper, c.retryBackoff))
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize Kubernetes client: %w", err)
 	}
 
 	// Connect to the Kubernetes aggregation API.
-	aggregatorClient, err := aggregatorclient.NewForConfig(configWithWrapper(protoKubeConfig, aggregatorclientscheme.Scheme, aggregatorclientscheme.Codecs, c.middlewares, c.transportWrapper))
+	aggregatorClient, err := aggregatorclient.NewForConfig(configWithWrapper(protoKubeConfig, aggregatorclientscheme.Scheme, aggregatorclientscheme.Codecs, c.middlewares, c.transportWrapper, c.retryBackoff))
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize aggregation client: %w", err)
 	}
 
+	// Connect to the Kubernetes API extensions API, e.g. to manage our CustomResourceDefinitions.
+	apiExtensionsClient, err := apiextensionsclient.NewForConfig(configWithWrapper(protoKubeConfig, apiextensionsclientscheme.Scheme, apiextensionsclientscheme.Codecs, c.middlewares, c.transportWrapper, c.retryBackoff))
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize API extensions client: %w", err)
+	}
+
 	// Connect to the pinniped concierge API.
 	// We cannot use protobuf encoding here because we are using CRDs
 	// (for which protobuf encoding is not yet supported).
-	pinnipedConciergeClient, err := pinnipedconciergeclientset.NewForConfig(configWithWrapper(jsonKubeConfig, pinnipedconciergeclientsetscheme.Scheme, pinnipedconciergeclientsetscheme.Codecs, c.middlewares, c.transportWrapper))
+	pinnipedConciergeClient, err := pinnipedconciergeclientset.NewForConfig(configWithWrapper(jsonKubeConfig, pinnipedconciergeclientsetscheme.Scheme, pinnipedconciergeclientsetscheme.Codecs, c.middlewares, c.transportWrapper, c.retryBackoff))
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize pinniped client: %w", err)
 	}
@@ -85,7 +95,7 @@ func New(opts ...Option) (*Client, error) {
 	// Connect to the pinniped supervisor API.
 	// We cannot use protobuf encoding here because we are using CRDs
 	// (for which protobuf encoding is not yet supported).
-	pinnipedSupervisorClient, err := pinnipedsupervisorclientset.NewForConfig(configWithWrapper(jsonKubeConfig, pinnipedsupervisorclientsetscheme.Scheme, pinnipedsupervisorclientsetscheme.Codecs, c.middlewares, c.transportWrapper))
+	pinnipedSupervisorClient, err := pinnipedsupervisorclientset.NewForConfig(configWithWrapper(jsonKubeConfig, pinnipedsupervisorclientsetscheme.Scheme, pinnipedsupervisorclientsetscheme.Codecs, c.middlewares, c.transportWrapper, c.retryBackoff))
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize pinniped client: %w", err)
 	}
@@ -93,6 +103,7 @@ func New(opts ...Option) (*Client, error) {
 	return &Client{
 		Kubernetes:         k8sClient,
 		Aggregation:        aggregatorClient,
+		APIExtensions:      apiExtensionsClient,
 		PinnipedConcierge:  pinnipedConciergeClient,
 		PinnipedSupervisor: pinnipedSupervisorClient,
 
@@ -231,3 +242,15 @@ func Secure(config *restclient.Config) (kubernetes.Interface, *restclient.Config
 	}
 	return secureClient.Kubernetes, secureClient.ProtoConfig, nil
 }
+
+// SecureFor returns a ptls.RestConfigFunc which is like Secure, except that it uses the given config instead of the
+// in-cluster config when it is called with a nil config, e.g. when the Concierge runs outside of its target cluster.
+// A nil defaultConfig makes it behave exactly like Secure.
+func SecureFor(defaultConfig *restclient.Config) ptls.RestConfigFunc {
+	return func(config *restclient.Config) (kubernetes.Interface, *restclient.Config, error) {
+		if config == nil {
+			config = defaultConfig
+		}
+		return Secure(config)
+	}
+}
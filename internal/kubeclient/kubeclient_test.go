@@ -1009,6 +1009,10 @@ func testUnwrap(t *testing.T, client *Client, serverSubjects [][]byte) {
 			name: "api registration v1",
 			rt:   extractTransport(client.Aggregation.ApiregistrationV1()),
 		},
+		{
+			name: "api extensions v1",
+			rt:   extractTransport(client.APIExtensions.ApiextensionsV1()),
+		},
 		{
 			name: "concierge login",
 			rt:   extractTransport(client.PinnipedConcierge.LoginV1alpha1()),
@@ -1132,3 +1136,18 @@ func makeClient(t *testing.T, restConfig *rest.Config, f func(*rest.Config)) *Cl
 
 	return client
 }
+
+func TestSecureFor(t *testing.T) {
+	defaultConfig := &rest.Config{Host: "https://target-cluster.example.com", BearerToken: "some-token"}
+	otherConfig := &rest.Config{Host: "https://loopback.example.com"}
+
+	_, config, err := SecureFor(defaultConfig)(nil)
+	require.NoError(t, err)
+	require.Equal(t, "https://target-cluster.example.com", config.Host)
+	require.Equal(t, "some-token", config.BearerToken)
+
+	_, config, err = SecureFor(defaultConfig)(otherConfig)
+	require.NoError(t, err)
+	require.Equal(t, "https://loopback.example.com", config.Host)
+	require.Empty(t, config.BearerToken)
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeclient
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	// This registers the standard client-go metrics, such as rest_client_rate_limiter_duration_seconds, which shows
+	// how long requests wait for the client-side rate limiter, i.e. if the QPS and burst of the client are too low.
+	_ "k8s.io/component-base/metrics/prometheus/restclient"
+
+	"go.pinniped.dev/internal/httputil/roundtripper"
+	"go.pinniped.dev/internal/pmetrics"
+)
+
+// requestsMetric is more detailed than the standard rest_client_requests_total, which does not know the resource.
+var requestsMetric = metrics.NewCounterVec(&metrics.CounterOpts{ //nolint:gochecknoglobals
+	Namespace:      pmetrics.Namespace,
+	Subsystem:      pmetrics.SubsystemKubeClient,
+	Name:           "requests_total",
+	Help:           "Number of requests to the Kubernetes API by verb, resource and status code. Each retry is counted.",
+	StabilityLevel: metrics.ALPHA,
+}, []string{"verb", "resource", "code"})
+
+var registerMetricsOnce sync.Once //nolint:gochecknoglobals
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(requestsMetric)
+	})
+}
+
+func configWithMetrics(config *restclient.Config, resolver genericapirequest.RequestInfoResolver, hostURL, apiPathPrefix string) *restclient.Config {
+	registerMetrics()
+
+	cc := restclient.CopyConfig(config)
+	cc.Wrap(newMetricsWrapper(resolver, hostURL, apiPathPrefix))
+	return cc
+}
+
+func newMetricsWrapper(resolver genericapirequest.RequestInfoResolver, hostURL, apiPathPrefix string) transport.WrapperFunc {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return roundtripper.WrapFunc(rt, func(req *http.Request) (*http.Response, error) {
+			verb, resource := "unknown", ""
+			if reqInfo, err := resolver.NewRequestInfo(reqWithoutPrefix(req, hostURL, apiPathPrefix)); err == nil {
+				verb = reqInfo.Verb
+				if reqInfo.IsResourceRequest {
+					resource = reqInfo.Resource
+					if len(reqInfo.APIGroup) != 0 {
+						resource += "." + reqInfo.APIGroup
+					}
+					if len(reqInfo.Subresource) != 0 {
+						resource += "/" + reqInfo.Subresource
+					}
+				}
+			}
+
+			resp, err := rt.RoundTrip(req)
+
+			code := "<error>"
+			if err == nil {
+				code = strconv.Itoa(resp.StatusCode)
+			}
+			requestsMetric.WithLabelValues(verb, resource, code).Inc()
+
+			return resp, err
+		})
+	}
+}
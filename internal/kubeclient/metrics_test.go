@@ -0,0 +1,68 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apiserver/pkg/server"
+	metricstestutil "k8s.io/component-base/metrics/testutil"
+
+	"go.pinniped.dev/internal/httputil/roundtripper"
+)
+
+func TestMetricsWrapper(t *testing.T) {
+	registerMetrics()
+
+	// the metrics are global, so use a group which is unique to this run to avoid collisions with other tests
+	group := rand.String(8) + ".pinniped.dev"
+
+	var respErr error
+	rt := roundtripper.Func(func(req *http.Request) (*http.Response, error) {
+		if respErr != nil {
+			return nil, respErr
+		}
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+
+	resolver := server.NewRequestInfoResolver(server.NewConfig(serializer.CodecFactory{}))
+	wrapped := newMetricsWrapper(resolver, "https://example.com", "/some/prefix")(rt)
+
+	send := func(method, path string) {
+		req, err := http.NewRequestWithContext(context.Background(), method, "https://example.com/some/prefix"+path, nil)
+		require.NoError(t, err)
+		resp, err := wrapped.RoundTrip(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}
+
+	send(http.MethodGet, "/apis/"+group+"/v1/namespaces/some-namespace/widgets/some-name")
+	send(http.MethodGet, "/apis/"+group+"/v1/widgets")
+	send(http.MethodPut, "/apis/"+group+"/v1/namespaces/some-namespace/widgets/some-name/status")
+	respErr = errors.New("some error")
+	send(http.MethodGet, "/apis/"+group+"/v1/widgets")
+
+	for _, tt := range []struct {
+		verb, resource, code string
+		want                 float64
+	}{
+		{verb: "get", resource: "widgets." + group, code: "404", want: 1},
+		{verb: "list", resource: "widgets." + group, code: "404", want: 1},
+		{verb: "update", resource: "widgets." + group + "/status", code: "404", want: 1},
+		{verb: "list", resource: "widgets." + group, code: "<error>", want: 1},
+	} {
+		got, err := metricstestutil.GetCounterMetricValue(requestsMetric.WithLabelValues(tt.verb, tt.resource, tt.code))
+		require.NoError(t, err)
+		require.Equal(t, tt.want, got, "%s %s %s", tt.verb, tt.resource, tt.code)
+	}
+}
@@ -1,9 +1,10 @@
-// Copyright 2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package kubeclient
 
 import (
+	"k8s.io/apimachinery/pkg/util/wait"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/transport"
 )
@@ -14,6 +15,8 @@ type clientConfig struct {
 	config           *restclient.Config
 	middlewares      []Middleware
 	transportWrapper transport.WrapperFunc
+	retryBackoff     *wait.Backoff
+	rateLimit        RateLimitSpec
 }
 
 func WithConfig(config *restclient.Config) Option {
@@ -40,3 +43,20 @@ func WithTransportWrapper(wrapper transport.WrapperFunc) Option {
 		c.transportWrapper = wrapper
 	}
 }
+
+// WithRetry retries the requests which fail with a transient error, such as a 429, a 5xx or a reset connection,
+// using the given backoff. A Retry-After header from the server is honored when it is within the cap of the backoff.
+// Only reads and the writes which are preconditioned on a resourceVersion are retried after errors which may have
+// happened after the server processed the request.
+func WithRetry(backoff wait.Backoff) Option {
+	return func(c *clientConfig) {
+		c.retryBackoff = &backoff
+	}
+}
+
+// WithRateLimit overrides the client-side rate limiting of the requests to the Kubernetes API.
+func WithRateLimit(rateLimit RateLimitSpec) Option {
+	return func(c *clientConfig) {
+		c.rateLimit = rateLimit
+	}
+}
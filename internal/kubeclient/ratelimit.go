@@ -0,0 +1,39 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeclient
+
+import (
+	restclient "k8s.io/client-go/rest"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+// RateLimitSpec configures the client-side rate limiting of the requests to the Kubernetes API. When the
+// rest_client_rate_limiter_duration_seconds metric shows that requests wait for the rate limiter, these can be raised.
+type RateLimitSpec struct {
+	// QPS is the sustained number of requests per second. Defaults to the client-go default of 5.
+	QPS *float32 `json:"qps,omitempty"`
+	// Burst is the number of requests which can be sent at once. Defaults to the client-go default of 10.
+	Burst *int `json:"burst,omitempty"`
+}
+
+// Validate validates the rate limit.
+func (s RateLimitSpec) Validate() error {
+	if s.QPS != nil && *s.QPS <= 0 {
+		return constable.Error("qps must be positive")
+	}
+	if s.Burst != nil && *s.Burst <= 0 {
+		return constable.Error("burst must be positive")
+	}
+	return nil
+}
+
+func (s RateLimitSpec) apply(config *restclient.Config) {
+	if s.QPS != nil {
+		config.QPS = *s.QPS
+	}
+	if s.Burst != nil {
+		config.Burst = *s.Burst
+	}
+}
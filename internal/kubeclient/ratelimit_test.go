@@ -0,0 +1,26 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
+)
+
+func TestRateLimitSpec(t *testing.T) {
+	require.NoError(t, RateLimitSpec{}.Validate())
+	require.NoError(t, RateLimitSpec{QPS: pointer.Float32(0.5), Burst: pointer.Int(1)}.Validate())
+	require.EqualError(t, RateLimitSpec{QPS: pointer.Float32(-1)}.Validate(), "qps must be positive")
+	require.EqualError(t, RateLimitSpec{Burst: pointer.Int(0)}.Validate(), "burst must be positive")
+
+	config := &restclient.Config{QPS: 5, Burst: 10}
+	RateLimitSpec{}.apply(config)
+	require.Equal(t, &restclient.Config{QPS: 5, Burst: 10}, config)
+
+	RateLimitSpec{QPS: pointer.Float32(50), Burst: pointer.Int(100)}.apply(config)
+	require.Equal(t, &restclient.Config{QPS: 50, Burst: 100}, config)
+}
@@ -0,0 +1,231 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+
+	"go.pinniped.dev/internal/httputil/roundtripper"
+	"go.pinniped.dev/internal/plog"
+)
+
+// DefaultRetryBackoff retries a request up to four times over about three seconds.
+//
+//nolint:gochecknoglobals
+var DefaultRetryBackoff = wait.Backoff{
+	Duration: 200 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    4,
+	Cap:      5 * time.Second,
+}
+
+func configWithRetry(config *restclient.Config, negotiatedSerializer runtime.NegotiatedSerializer, backoff *wait.Backoff) *restclient.Config {
+	if backoff == nil {
+		return config
+	}
+
+	cc := restclient.CopyConfig(config)
+	cc.Wrap(newRetryWrapper(*backoff, negotiatedSerializer))
+	return cc
+}
+
+func newRetryWrapper(backoff wait.Backoff, negotiatedSerializer runtime.NegotiatedSerializer) transport.WrapperFunc {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return roundtripper.WrapFunc(rt, func(req *http.Request) (*http.Response, error) {
+			return retryRoundTrip(rt, req, backoff, negotiatedSerializer)
+		})
+	}
+}
+
+func retryRoundTrip(rt http.RoundTripper, req *http.Request, backoff wait.Backoff, negotiatedSerializer runtime.NegotiatedSerializer) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	idempotent := isIdempotent(req, body, negotiatedSerializer)
+
+	for {
+		attempt := req.Clone(req.Context())
+		if body != nil {
+			attempt.Body = io.NopCloser(bytes.NewReader(body))
+			attempt.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+		}
+
+		resp, err := rt.RoundTrip(attempt)
+
+		retry, retryAfter := isTransient(resp, err, idempotent)
+		if !retry || backoff.Steps < 1 {
+			return resp, err
+		}
+
+		delay := backoff.Step()
+		if retryAfter > delay {
+			if backoff.Cap > 0 && retryAfter > backoff.Cap {
+				return resp, err // the server wants us to wait longer than we are willing to
+			}
+			delay = retryAfter
+		}
+
+		plog.Debug("retrying kube API request after transient error",
+			"method", req.Method,
+			"url", req.URL.String(),
+			"status", statusOf(resp),
+			"err", err,
+			"delay", delay,
+		)
+
+		if resp != nil {
+			// drain a bit of the body so that the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer func() { _ = req.Body.Close() }()
+
+	return io.ReadAll(req.Body)
+}
+
+// isTransient decides if a request should be retried based on its result. Requests which the API server did not
+// process, i.e. those which were refused or rate limited, are always retried. Other failures are only retried for
+// idempotent requests since the API server may have processed them before the failure.
+func isTransient(resp *http.Response, err error, idempotent bool) (bool, time.Duration) {
+	if err != nil {
+		if utilnet.IsConnectionRefused(err) {
+			return true, 0
+		}
+		return idempotent && (utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)), 0
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true, retryAfter(resp)
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent, retryAfter(resp)
+	default:
+		return false, 0
+	}
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if len(value) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+// isIdempotent decides if a request can be safely sent more than once. Reads are always safe to repeat. Writes are
+// only safe to repeat when they are preconditioned on a resourceVersion, since a repeated write then fails with a
+// conflict instead of overwriting a change made in between.
+func isIdempotent(req *http.Request, body []byte, negotiatedSerializer runtime.NegotiatedSerializer) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return len(resourceVersionPrecondition(req, body, negotiatedSerializer)) != 0
+	default:
+		return false // creates are never safe to repeat
+	}
+}
+
+func resourceVersionPrecondition(req *http.Request, body []byte, negotiatedSerializer runtime.NegotiatedSerializer) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+
+	// handles objects, delete options and merge patches, i.e. all the JSON bodies which can hold a resourceVersion
+	if strings.HasSuffix(mediaType, "json") {
+		var partial struct {
+			Metadata struct {
+				ResourceVersion string `json:"resourceVersion"`
+			} `json:"metadata"`
+			Preconditions struct {
+				ResourceVersion *string `json:"resourceVersion"`
+			} `json:"preconditions"`
+		}
+		if err := json.Unmarshal(body, &partial); err != nil {
+			return "" // e.g. a JSON patch, which is a list
+		}
+		if partial.Preconditions.ResourceVersion != nil {
+			return *partial.Preconditions.ResourceVersion
+		}
+		return partial.Metadata.ResourceVersion
+	}
+
+	info, ok := runtime.SerializerInfoForMediaType(negotiatedSerializer.SupportedMediaTypes(), mediaType)
+	if !ok {
+		return ""
+	}
+	obj, _, err := info.Serializer.Decode(body, nil, nil)
+	if err != nil {
+		return ""
+	}
+
+	if deleteOptions, ok := obj.(*metav1.DeleteOptions); ok {
+		if deleteOptions.Preconditions == nil || deleteOptions.Preconditions.ResourceVersion == nil {
+			return ""
+		}
+		return *deleteOptions.Preconditions.ResourceVersion
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return accessor.GetResourceVersion()
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
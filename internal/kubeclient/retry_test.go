@@ -0,0 +1,240 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+
+	"go.pinniped.dev/internal/httputil/roundtripper"
+)
+
+func TestRetryRoundTrip(t *testing.T) {
+	status := func(code int, headers ...string) func() (*http.Response, error) {
+		return func() (*http.Response, error) {
+			resp := &http.Response{StatusCode: code, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("some body"))}
+			for i := 0; i < len(headers); i += 2 {
+				resp.Header.Set(headers[i], headers[i+1])
+			}
+			return resp, nil
+		}
+	}
+	failure := func(err error) func() (*http.Response, error) {
+		return func() (*http.Response, error) {
+			return nil, err
+		}
+	}
+
+	protoConfigMap := func(resourceVersion string) []byte {
+		info, ok := runtime.SerializerInfoForMediaType(kubescheme.Codecs.SupportedMediaTypes(), runtime.ContentTypeProtobuf)
+		require.True(t, ok)
+		encoder := kubescheme.Codecs.EncoderForVersion(info.Serializer, corev1.SchemeGroupVersion)
+		data, err := runtime.Encode(encoder, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-name", ResourceVersion: resourceVersion}})
+		require.NoError(t, err)
+		return data
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        []byte
+		responses   []func() (*http.Response, error)
+		wantStatus  int
+		wantErr     string
+		wantCalls   int
+	}{
+		{
+			name:       "success",
+			method:     http.MethodGet,
+			responses:  []func() (*http.Response, error){status(http.StatusOK)},
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name:       "non-transient errors are not retried",
+			method:     http.MethodGet,
+			responses:  []func() (*http.Response, error){status(http.StatusNotFound)},
+			wantStatus: http.StatusNotFound,
+			wantCalls:  1,
+		},
+		{
+			name:   "get retried on 5xx and connection reset",
+			method: http.MethodGet,
+			responses: []func() (*http.Response, error){
+				status(http.StatusServiceUnavailable),
+				failure(syscall.ECONNRESET),
+				status(http.StatusGatewayTimeout),
+				status(http.StatusOK),
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  4,
+		},
+		{
+			name:   "gives up when out of steps",
+			method: http.MethodGet,
+			responses: []func() (*http.Response, error){
+				status(http.StatusInternalServerError),
+				status(http.StatusInternalServerError),
+				status(http.StatusInternalServerError),
+				status(http.StatusInternalServerError),
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  4,
+		},
+		{
+			name:        "create retried on 429 and connection refused",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        []byte(`{"metadata":{"name":"some-name"}}`),
+			responses: []func() (*http.Response, error){
+				status(http.StatusTooManyRequests, "Retry-After", "0"),
+				failure(syscall.ECONNREFUSED),
+				status(http.StatusCreated),
+			},
+			wantStatus: http.StatusCreated,
+			wantCalls:  3,
+		},
+		{
+			name:        "create not retried on 5xx",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        []byte(`{"metadata":{"name":"some-name"}}`),
+			responses:   []func() (*http.Response, error){status(http.StatusServiceUnavailable)},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantCalls:   1,
+		},
+		{
+			name:        "update without resource version not retried on connection reset",
+			method:      http.MethodPut,
+			contentType: "application/json",
+			body:        []byte(`{"metadata":{"name":"some-name"}}`),
+			responses:   []func() (*http.Response, error){failure(syscall.ECONNRESET)},
+			wantErr:     "connection reset by peer",
+			wantCalls:   1,
+		},
+		{
+			name:        "json update with resource version retried on 5xx",
+			method:      http.MethodPut,
+			contentType: "application/json",
+			body:        []byte(`{"metadata":{"name":"some-name","resourceVersion":"42"}}`),
+			responses:   []func() (*http.Response, error){status(http.StatusBadGateway), status(http.StatusOK)},
+			wantStatus:  http.StatusOK,
+			wantCalls:   2,
+		},
+		{
+			name:        "protobuf update with resource version retried on 5xx",
+			method:      http.MethodPut,
+			contentType: runtime.ContentTypeProtobuf,
+			body:        protoConfigMap("42"),
+			responses:   []func() (*http.Response, error){status(http.StatusBadGateway), status(http.StatusOK)},
+			wantStatus:  http.StatusOK,
+			wantCalls:   2,
+		},
+		{
+			name:        "protobuf update without resource version not retried on 5xx",
+			method:      http.MethodPut,
+			contentType: runtime.ContentTypeProtobuf,
+			body:        protoConfigMap(""),
+			responses:   []func() (*http.Response, error){status(http.StatusBadGateway)},
+			wantStatus:  http.StatusBadGateway,
+			wantCalls:   1,
+		},
+		{
+			name:        "delete with resource version precondition retried on 5xx",
+			method:      http.MethodDelete,
+			contentType: "application/json",
+			body:        []byte(`{"preconditions":{"resourceVersion":"42"}}`),
+			responses:   []func() (*http.Response, error){status(http.StatusServiceUnavailable), status(http.StatusOK)},
+			wantStatus:  http.StatusOK,
+			wantCalls:   2,
+		},
+		{
+			name:        "json patch not retried on 5xx",
+			method:      http.MethodPatch,
+			contentType: "application/json-patch+json",
+			body:        []byte(`[{"op":"add","path":"/metadata/resourceVersion","value":"42"}]`),
+			responses:   []func() (*http.Response, error){status(http.StatusServiceUnavailable)},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantCalls:   1,
+		},
+		{
+			name:   "retry after longer than the cap is not honored",
+			method: http.MethodGet,
+			responses: []func() (*http.Response, error){
+				status(http.StatusTooManyRequests, "Retry-After", "3600"),
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			rt := roundtripper.Func(func(req *http.Request) (*http.Response, error) {
+				if tt.body != nil {
+					body, err := io.ReadAll(req.Body)
+					require.NoError(t, err)
+					require.Equal(t, tt.body, body, "every attempt should send the whole body")
+				}
+				calls++
+				return tt.responses[calls-1]()
+			})
+
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, "https://example.com/api/v1/configmaps", bytes.NewReader(tt.body))
+			require.NoError(t, err)
+			if len(tt.contentType) != 0 {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			backoff := wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3, Cap: time.Minute}
+			resp, err := newRetryWrapper(backoff, kubescheme.Codecs)(rt).RoundTrip(req)
+
+			if len(tt.wantErr) != 0 {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantStatus, resp.StatusCode)
+			}
+			require.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestRetryRoundTripHonorsRetryAfterAndContext(t *testing.T) {
+	rt := roundtripper.Func(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"10"}},
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/api/v1/configmaps", nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	backoff := wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3, Cap: time.Minute}
+	_, err = newRetryWrapper(backoff, kubescheme.Codecs)(rt).RoundTrip(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 10*time.Second, "should stop waiting for the Retry-After when the context is done")
+}
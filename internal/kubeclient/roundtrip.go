@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package kubeclient
@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/wait"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/server"
 	restclient "k8s.io/client-go/rest"
@@ -24,14 +25,20 @@ import (
 	"go.pinniped.dev/internal/plog"
 )
 
-func configWithWrapper(config *restclient.Config, scheme *runtime.Scheme, negotiatedSerializer runtime.NegotiatedSerializer, middlewares []Middleware, wrapper transport.WrapperFunc) *restclient.Config {
+func configWithWrapper(config *restclient.Config, scheme *runtime.Scheme, negotiatedSerializer runtime.NegotiatedSerializer, middlewares []Middleware, wrapper transport.WrapperFunc, retryBackoff *wait.Backoff) *restclient.Config {
 	hostURL, apiPathPrefix, err := getHostAndAPIPathPrefix(config)
 	if err != nil {
 		plog.DebugErr("invalid rest config", err)
 		return config // invalid input config, will fail existing client-go validation
 	}
 
-	// no need for any wrapping when we have no middleware to inject
+	resolver := server.NewRequestInfoResolver(server.NewConfig(serializer.CodecFactory{}))
+
+	// metrics and retries happen below the middleware so that each attempt sends the same mutated request
+	config = configWithMetrics(config, resolver, hostURL, apiPathPrefix)
+	config = configWithRetry(config, negotiatedSerializer, retryBackoff)
+
+	// no need for any more wrapping when we have no middleware to inject
 	if len(middlewares) == 0 {
 		return config
 	}
@@ -42,8 +49,6 @@ func configWithWrapper(config *restclient.Config, scheme *runtime.Scheme, negoti
 	}
 	regSerializer := info.Serializer // should perform no conversion
 
-	resolver := server.NewRequestInfoResolver(server.NewConfig(serializer.CodecFactory{}))
-
 	schemeRestMapperFunc := schemeRestMapper(scheme)
 
 	f := newWrapper(hostURL, apiPathPrefix, config, resolver, regSerializer, negotiatedSerializer, schemeRestMapperFunc, middlewares)
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package localuserauthenticator provides a authentication webhook program.
@@ -33,6 +33,7 @@ import (
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"go.pinniped.dev/internal/certauthority"
 	"go.pinniped.dev/internal/constable"
 	"go.pinniped.dev/internal/controller/apicerts"
 	"go.pinniped.dev/internal/controllerlib"
@@ -304,6 +305,8 @@ func startControllers(
 				controllerlib.WithInformer,
 				controllerlib.WithInitialEvent,
 				aVeryLongTime,
+				certauthority.DefaultKeyAlgorithm,
+				nil,
 				"local-user-authenticator CA",
 				serviceName,
 			),
@@ -0,0 +1,104 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metricsendpoint configures how the Prometheus metrics of the Concierge and the Supervisor are served, so that
+// both can be scraped the same way.
+package metricsendpoint
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/pmetrics"
+)
+
+// Path is the path of the metrics endpoint, both on the aggregated API server and on the cleartext port.
+const Path = "/metrics"
+
+// Spec configures the metrics endpoint. The metrics are always served under /metrics on the aggregated API server,
+// behind its TLS serving certificate.
+type Spec struct {
+	// AllowUnauthenticated serves the metrics on the aggregated API server to all clients. By default, its clients
+	// must be authenticated by the Kubernetes API server with a TokenReview, and authorized for the non-resource URL
+	// /metrics with a SubjectAccessReview.
+	AllowUnauthenticated bool `json:"allowUnauthenticated,omitempty"`
+
+	// CleartextPort additionally serves the metrics without TLS and without authentication on this port of all
+	// interfaces of the pod, e.g. for scraping through a service mesh which adds its own mutual TLS.
+	CleartextPort *int64 `json:"cleartextPort,omitempty"`
+
+	// LatencyBuckets are the upper bounds, in seconds, of the buckets of all latency histograms, e.g. of the durations
+	// of the requests to the endpoints and of the syncs of the controllers. By default, each histogram has its own
+	// buckets. Changes take effect when the pod restarts.
+	LatencyBuckets []float64 `json:"latencyBuckets,omitempty"`
+}
+
+// Validate validates the metrics configuration.
+func (s Spec) Validate() error {
+	if s.CleartextPort != nil && (*s.CleartextPort < 1024 || *s.CleartextPort > 65535) {
+		return constable.Error("cleartextPort must be between 1024 and 65535")
+	}
+	if err := pmetrics.ValidateLatencyBuckets(s.LatencyBuckets); err != nil {
+		return fmt.Errorf("invalid latencyBuckets: %w", err)
+	}
+	return nil
+}
+
+// ConfigureLatencyBuckets configures the buckets of all latency histograms. It must be called before any metrics are
+// registered.
+func (s Spec) ConfigureLatencyBuckets() {
+	pmetrics.SetLatencyBuckets(s.LatencyBuckets)
+}
+
+// ApplyTo skips the authorization of the metrics endpoint of the aggregated API server when unauthenticated clients
+// are allowed. Requests without credentials are already authenticated as anonymous.
+func (s Spec) ApplyTo(options *genericoptions.RecommendedOptions) {
+	if s.AllowUnauthenticated {
+		options.Authorization.WithAlwaysAllowPaths(Path)
+	}
+}
+
+// ServeCleartext serves the metrics on the cleartext port until ctx is done, when the port is configured.
+func (s Spec) ServeCleartext(ctx context.Context) error {
+	if s.CleartextPort == nil {
+		return nil
+	}
+
+	l, err := net.Listen("tcp", net.JoinHostPort("", strconv.FormatInt(*s.CleartextPort, 10)))
+	if err != nil {
+		return fmt.Errorf("cannot create metrics listener: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	go func() {
+		err := server.Serve(l)
+		plog.Debug("metrics server exited", "err", err)
+	}()
+
+	plog.Info("serving metrics endpoint", "address", l.Addr().String())
+	return nil
+}
+
+func handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(Path, legacyregistry.Handler())
+	return mux
+}
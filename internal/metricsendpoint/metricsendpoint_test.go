@@ -0,0 +1,82 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metricsendpoint
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/utils/pointer"
+)
+
+func TestSpecValidate(t *testing.T) {
+	require.NoError(t, Spec{}.Validate())
+	require.NoError(t, Spec{AllowUnauthenticated: true}.Validate())
+	require.NoError(t, Spec{CleartextPort: pointer.Int64(9090)}.Validate())
+	require.EqualError(t, Spec{CleartextPort: pointer.Int64(80)}.Validate(), "cleartextPort must be between 1024 and 65535")
+	require.EqualError(t, Spec{CleartextPort: pointer.Int64(65536)}.Validate(), "cleartextPort must be between 1024 and 65535")
+	require.NoError(t, Spec{LatencyBuckets: []float64{0.1, 0.5, 1}}.Validate())
+	require.EqualError(t, Spec{LatencyBuckets: []float64{0, 1}}.Validate(), "invalid latencyBuckets: latency bucket 0 must be positive")
+	require.EqualError(t, Spec{LatencyBuckets: []float64{1, 1}}.Validate(), "invalid latencyBuckets: latency bucket 1 must be greater than the previous bucket")
+}
+
+func TestSpecApplyTo(t *testing.T) {
+	options := genericoptions.NewRecommendedOptions("", nil)
+	defaultPaths := append([]string(nil), options.Authorization.AlwaysAllowPaths...)
+	require.NotContains(t, defaultPaths, "/metrics")
+	require.False(t, options.Authentication.DisableAnonymous, "the upstream default is expected to allow anonymous requests")
+
+	Spec{}.ApplyTo(options)
+	require.Equal(t, defaultPaths, options.Authorization.AlwaysAllowPaths)
+
+	Spec{AllowUnauthenticated: true}.ApplyTo(options)
+	require.Equal(t, append(defaultPaths, "/metrics"), options.Authorization.AlwaysAllowPaths)
+}
+
+func TestServeCleartext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.NoError(t, Spec{}.ServeCleartext(ctx))
+
+	port := freePort(t)
+	require.NoError(t, Spec{CleartextPort: &port}.ServeCleartext(ctx))
+
+	resp, err := http.Get("http://127.0.0.1:" + strconv.FormatInt(port, 10) + "/metrics")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.ErrorContains(t, Spec{CleartextPort: &port}.ServeCleartext(ctx), "cannot create metrics listener")
+}
+
+func TestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "not found")
+}
+
+func freePort(t *testing.T) int64 {
+	t.Helper()
+
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+	return int64(port)
+}
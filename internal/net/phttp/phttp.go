@@ -1,11 +1,13 @@
-// Copyright 2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package phttp
 
 import (
+	"crypto/tls"
 	"crypto/x509"
 	"net/http"
+	"net/url"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/net"
@@ -17,16 +19,37 @@ import (
 )
 
 func Default(rootCAs *x509.CertPool) *http.Client {
-	return buildClient(ptls.Default, rootCAs)
+	return buildClient(ptls.Default, rootCAs, nil)
 }
 
 func Secure(rootCAs *x509.CertPool) *http.Client {
-	return buildClient(ptls.Secure, rootCAs)
+	return buildClient(ptls.Secure, rootCAs, nil)
 }
 
-func buildClient(tlsConfigFunc ptls.ConfigFunc, rootCAs *x509.CertPool) *http.Client {
+// DefaultWithProxy is like Default, but chooses the proxy for each request using the proxy func (see
+// http.Transport.Proxy). A nil proxy func chooses the proxy using the proxy environment variables, like Default.
+func DefaultWithProxy(rootCAs *x509.CertPool, proxy func(*http.Request) (*url.URL, error)) *http.Client {
+	return buildClient(ptls.Default, rootCAs, proxy)
+}
+
+// DefaultWithServerName is like DefaultWithProxy, but verifies the certificate of the server using the server name,
+// instead of the hostname of each request, when the server name is not empty (see tls.Config.ServerName).
+func DefaultWithServerName(rootCAs *x509.CertPool, serverName string, proxy func(*http.Request) (*url.URL, error)) *http.Client {
+	return buildClient(func(rootCAs *x509.CertPool) *tls.Config {
+		tlsConfig := ptls.Default(rootCAs)
+		if serverName != "" {
+			tlsConfig.ServerName = serverName
+		}
+		return tlsConfig
+	}, rootCAs, proxy)
+}
+
+func buildClient(tlsConfigFunc ptls.ConfigFunc, rootCAs *x509.CertPool, proxy func(*http.Request) (*url.URL, error)) *http.Client {
 	baseRT := defaultTransport()
 	baseRT.TLSClientConfig = tlsConfigFunc(rootCAs)
+	if proxy != nil {
+		baseRT.Proxy = proxy
+	}
 
 	return &http.Client{
 		Transport: defaultWrap(baseRT),
@@ -1,4 +1,4 @@
-// Copyright 2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package phttp
@@ -8,6 +8,8 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -106,6 +108,94 @@ func TestClient(t *testing.T) {
 	}
 }
 
+func TestDefaultWithProxy(t *testing.T) {
+	t.Parallel()
+
+	var sawRequestURI string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		assertUserAgent(t, r)
+		sawRequestURI = r.RequestURI
+	}))
+	t.Cleanup(proxyServer.Close)
+
+	proxyURL, err := url.Parse(proxyServer.URL)
+	require.NoError(t, err)
+
+	c := DefaultWithProxy(nil, func(r *http.Request) (*url.URL, error) {
+		require.Equal(t, "issuer.example.com", r.URL.Host)
+		return proxyURL, nil
+	})
+
+	tlsConfig, err := net.TLSClientConfig(c.Transport)
+	require.NoError(t, err)
+	require.Nil(t, tlsConfig.RootCAs)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://issuer.example.com/some/path", nil)
+	require.NoError(t, err)
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	// Requests to a proxy use the absolute URL as the request URI.
+	require.Equal(t, "http://issuer.example.com/some/path", sawRequestURI)
+}
+
+func TestDefaultWithServerName(t *testing.T) {
+	t.Parallel()
+
+	server := tlsserver.TLSTestServer(t, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		assertUserAgent(t, r)
+	}), nil)
+
+	rootCAs, err := cert.NewPoolFromBytes(tlsserver.TLSTestServerCA(server))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		serverName string
+		wantErr    string
+	}{
+		{
+			name:       "no server name",
+			serverName: "",
+		},
+		{
+			name:       "server name which matches the certificate",
+			serverName: "example.com",
+		},
+		{
+			name:       "server name which does not match the certificate",
+			serverName: "example.org",
+			wantErr:    "not example.org",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := DefaultWithServerName(rootCAs, tt.serverName, nil)
+
+			tlsConfig, err := net.TLSClientConfig(c.Transport)
+			require.NoError(t, err)
+			require.Equal(t, tt.serverName, tlsConfig.ServerName)
+			require.Equal(t, rootCAs, tlsConfig.RootCAs)
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := c.Do(req)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+		})
+	}
+}
+
 func assertUserAgent(t *testing.T, r *http.Request) {
 	t.Helper()
 
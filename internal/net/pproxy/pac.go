@@ -0,0 +1,421 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robertkrimen/otto"
+
+	"go.pinniped.dev/internal/crypto/ptls"
+)
+
+const (
+	// maxPACFileSize is the largest proxy auto-config file which will be loaded.
+	maxPACFileSize = 1024 * 1024
+
+	// pacFetchTimeout is how long to wait when downloading a proxy auto-config file.
+	pacFetchTimeout = 30 * time.Second
+
+	// pacCallTimeout is how long FindProxyForURL may run before it is interrupted, and pacDNSTimeout is how long
+	// each DNS lookup made by the helper functions may take.
+	pacCallTimeout = 5 * time.Second
+	pacDNSTimeout  = 5 * time.Second
+)
+
+var errPACCallTimeout = errors.New("timed out") //nolint:gochecknoglobals
+
+// pacHelpers are the standard proxy auto-config helper functions which are written in JavaScript. The others are
+// implemented in Go by newPACScript, because they need DNS, the network interfaces, or glob patterns. See
+// https://developer.mozilla.org/en-US/docs/Web/HTTP/Proxy_servers_and_tunneling/Proxy_Auto-Configuration_PAC_file.
+const pacHelpers = `
+function isPlainHostName(host) {
+	return host.indexOf('.') < 0;
+}
+
+function dnsDomainIs(host, domain) {
+	host = String(host).toLowerCase();
+	domain = String(domain).toLowerCase();
+	return host.length >= domain.length && host.substring(host.length - domain.length) === domain;
+}
+
+function localHostOrDomainIs(host, hostdom) {
+	return host === hostdom || (host.indexOf('.') < 0 && hostdom.indexOf(host + '.') === 0);
+}
+
+function isResolvable(host) {
+	return dnsResolve(host) !== null;
+}
+
+function dnsDomainLevels(host) {
+	return host.split('.').length - 1;
+}
+
+var __pacWeekdays = ['SUN', 'MON', 'TUE', 'WED', 'THU', 'FRI', 'SAT'];
+var __pacMonths = ['JAN', 'FEB', 'MAR', 'APR', 'MAY', 'JUN', 'JUL', 'AUG', 'SEP', 'OCT', 'NOV', 'DEC'];
+
+// __pacArgs returns the arguments without a trailing 'GMT', and whether there was one.
+function __pacArgs(args) {
+	args = Array.prototype.slice.call(args);
+	var gmt = args.length > 0 && args[args.length - 1] === 'GMT';
+	if (gmt) {
+		args.pop();
+	}
+	return {args: args, gmt: gmt};
+}
+
+function __pacNow(gmt) {
+	var d = new Date(__pacNowMillis());
+	return gmt ?
+		{year: d.getUTCFullYear(), month: d.getUTCMonth(), day: d.getUTCDate(), weekday: d.getUTCDay(), hour: d.getUTCHours(), minute: d.getUTCMinutes(), second: d.getUTCSeconds()} :
+		{year: d.getFullYear(), month: d.getMonth(), day: d.getDate(), weekday: d.getDay(), hour: d.getHours(), minute: d.getMinutes(), second: d.getSeconds()};
+}
+
+function __pacCompare(a, b) {
+	for (var i = 0; i < a.length; i++) {
+		if (a[i] !== b[i]) {
+			return a[i] < b[i] ? -1 : 1;
+		}
+	}
+	return 0;
+}
+
+// __pacInRange returns whether cur is between from and to, inclusively. When from is after to, the range wraps around.
+function __pacInRange(from, to, cur) {
+	if (__pacCompare(from, to) <= 0) {
+		return __pacCompare(from, cur) <= 0 && __pacCompare(cur, to) <= 0;
+	}
+	return __pacCompare(from, cur) <= 0 || __pacCompare(cur, to) <= 0;
+}
+
+function weekdayRange() {
+	var a = __pacArgs(arguments);
+	var from = __pacWeekdays.indexOf(a.args[0]);
+	var to = a.args.length > 1 ? __pacWeekdays.indexOf(a.args[1]) : from;
+	if (from < 0 || to < 0) {
+		return false;
+	}
+	return __pacInRange([from], [to], [__pacNow(a.gmt).weekday]);
+}
+
+function dateRange() {
+	var a = __pacArgs(arguments);
+	var args = a.args;
+	var kind = function(v) { return typeof v === 'string' ? 'month' : (v > 31 ? 'year' : 'day'); };
+	var value = function(v) { return typeof v === 'string' ? __pacMonths.indexOf(v) : v; };
+
+	// An even number of arguments whose halves have the same kinds is a range, otherwise it is a single date.
+	var n = args.length;
+	var isRange = n > 0 && n % 2 === 0;
+	for (var i = 0; isRange && i < n / 2; i++) {
+		isRange = kind(args[i]) === kind(args[i + n / 2]);
+	}
+	var count = isRange ? n / 2 : n;
+	if (count === 0) {
+		return false;
+	}
+
+	// Compare only the given kinds of values, from the most significant to the least significant.
+	var now = __pacNow(a.gmt);
+	var order = ['year', 'month', 'day'];
+	var tuple = function(values) {
+		var t = [];
+		for (var j = 0; j < order.length; j++) {
+			for (var k = 0; k < count; k++) {
+				if (kind(args[k]) === order[j]) {
+					t.push(values[k]);
+				}
+			}
+		}
+		return t;
+	};
+	var from = [], to = [], cur = [];
+	for (i = 0; i < count; i++) {
+		from.push(value(args[i]));
+		to.push(value(args[isRange ? i + count : i]));
+		cur.push(now[kind(args[i])]);
+	}
+	return __pacInRange(tuple(from), tuple(to), tuple(cur));
+}
+
+function timeRange() {
+	var a = __pacArgs(arguments);
+	var args = a.args;
+	var now = __pacNow(a.gmt);
+	var cur = [now.hour, now.minute, now.second];
+	switch (args.length) {
+	case 1:
+		return now.hour === args[0];
+	case 2:
+		return __pacInRange([args[0]], [args[1]], cur.slice(0, 1));
+	case 4:
+		return __pacInRange(args.slice(0, 2), args.slice(2), cur.slice(0, 2));
+	case 6:
+		return __pacInRange(args.slice(0, 3), args.slice(3), cur);
+	default:
+		return false;
+	}
+}
+`
+
+// pacScript is a loaded proxy auto-config file, which is safe for concurrent use.
+type pacScript struct {
+	mu sync.Mutex
+	vm *otto.Otto
+
+	// The calls to the outside world which are made by the helper functions, which are replaced by tests.
+	lookupIP  func(ctx context.Context, host string) ([]net.IP, error)
+	myIP      func() string
+	nowMillis func() int64
+}
+
+// loadPACScript reads the proxy auto-config file from an http, https, or file URL, or from a local path.
+func loadPACScript(ctx context.Context, location string) (*pacScript, error) {
+	source, err := readPACFile(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	return newPACScript(source, &pacScript{
+		lookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip4", host)
+		},
+		myIP:      myIPAddress,
+		nowMillis: func() int64 { return time.Now().UnixMilli() },
+	})
+}
+
+func readPACFile(ctx context.Context, location string) (string, error) {
+	var body io.ReadCloser
+	u, err := url.Parse(location)
+	switch {
+	case err == nil && (u.Scheme == "http" || u.Scheme == "https"):
+		// The proxy auto-config file is always downloaded without a proxy.
+		client := &http.Client{
+			Transport: &http.Transport{TLSClientConfig: ptls.Default(nil)},
+			Timeout:   pacFetchTimeout,
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return "", fmt.Errorf("unexpected HTTP response status %d", resp.StatusCode)
+		}
+		body = resp.Body
+	case err == nil && u.Scheme == "file":
+		if body, err = os.Open(u.Path); err != nil {
+			return "", err
+		}
+	default:
+		if body, err = os.Open(location); err != nil {
+			return "", err
+		}
+	}
+	defer func() { _ = body.Close() }()
+
+	source, err := io.ReadAll(io.LimitReader(body, maxPACFileSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(source) > maxPACFileSize {
+		return "", fmt.Errorf("file is larger than %d bytes", maxPACFileSize)
+	}
+	return string(source), nil
+}
+
+// newPACScript compiles the source of a proxy auto-config file, using the external calls of p.
+func newPACScript(source string, p *pacScript) (*pacScript, error) {
+	p.vm = otto.New()
+
+	builtins := map[string]func(call otto.FunctionCall) otto.Value{
+		"dnsResolve": func(call otto.FunctionCall) otto.Value {
+			if ip := p.resolve(call.Argument(0).String()); ip != nil {
+				return p.toValue(ip.String())
+			}
+			return otto.NullValue()
+		},
+		"myIpAddress": func(call otto.FunctionCall) otto.Value {
+			return p.toValue(p.myIP())
+		},
+		"isInNet": func(call otto.FunctionCall) otto.Value {
+			ip := p.resolve(call.Argument(0).String())
+			pattern := net.ParseIP(call.Argument(1).String()).To4()
+			mask := net.ParseIP(call.Argument(2).String()).To4()
+			if ip == nil || pattern == nil || mask == nil {
+				return otto.FalseValue()
+			}
+			return p.toValue(ip.Mask(net.IPMask(mask)).Equal(pattern.Mask(net.IPMask(mask))))
+		},
+		"shExpMatch": func(call otto.FunctionCall) otto.Value {
+			return p.toValue(shExpMatch(call.Argument(0).String(), call.Argument(1).String()))
+		},
+		"__pacNowMillis": func(call otto.FunctionCall) otto.Value {
+			return p.toValue(p.nowMillis())
+		},
+	}
+	for name, fn := range builtins {
+		if err := p.vm.Set(name, fn); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.vm.Run(pacHelpers); err != nil {
+		return nil, fmt.Errorf("could not define helper functions: %w", err)
+	}
+
+	if _, err := p.vm.Run(source); err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+	if fn, err := p.vm.Get("FindProxyForURL"); err != nil || !fn.IsFunction() {
+		return nil, fmt.Errorf("invalid script: FindProxyForURL is not defined")
+	}
+	return p, nil
+}
+
+func (p *pacScript) toValue(value interface{}) otto.Value {
+	v, err := p.vm.ToValue(value)
+	if err != nil {
+		return otto.UndefinedValue()
+	}
+	return v
+}
+
+// resolve returns the IPv4 address of the host, which may already be an IPv4 address, or nil when it has none.
+func (p *pacScript) resolve(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.To4()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pacDNSTimeout)
+	defer cancel()
+	ips, err := p.lookupIP(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4
+		}
+	}
+	return nil
+}
+
+// findProxyForURL calls the FindProxyForURL function of the script, and returns its result. Like web browsers do,
+// only the scheme and host of https URLs are given to the script.
+func (p *pacScript) findProxyForURL(u *url.URL) (result string, err error) {
+	target := u.String()
+	if u.Scheme == "https" {
+		target = (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}).String()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Interrupt the script if it runs for too long, e.g., because of an infinite loop.
+	interrupt := make(chan func(), 1)
+	p.vm.Interrupt = interrupt
+	timer := time.AfterFunc(pacCallTimeout, func() {
+		interrupt <- func() { panic(errPACCallTimeout) }
+	})
+	defer timer.Stop()
+	defer func() {
+		if caught := recover(); caught != nil {
+			if caught != errPACCallTimeout { //nolint:errorlint // this is the exact value which was given to panic
+				panic(caught)
+			}
+			err = fmt.Errorf("FindProxyForURL did not return within %s", pacCallTimeout)
+		}
+	}()
+
+	value, err := p.vm.Call("FindProxyForURL", nil, target, u.Hostname())
+	if err != nil {
+		return "", fmt.Errorf("FindProxyForURL failed: %w", err)
+	}
+	if value.IsUndefined() || value.IsNull() {
+		return "", nil
+	}
+	if !value.IsString() {
+		return "", fmt.Errorf("FindProxyForURL returned %q instead of a string", value.String())
+	}
+	return value.String(), nil
+}
+
+// proxyFromPACResult returns the first usable proxy from the result of FindProxyForURL, e.g. "PROXY host:port; DIRECT",
+// or nil when it says to connect directly.
+func proxyFromPACResult(result string) (*url.URL, error) {
+	if strings.TrimSpace(result) == "" {
+		return nil, nil
+	}
+	for _, entry := range strings.Split(result, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		var scheme string
+		switch strings.ToUpper(fields[0]) {
+		case "DIRECT":
+			return nil, nil
+		case "PROXY", "HTTP":
+			scheme = "http"
+		case "HTTPS":
+			scheme = "https"
+		case "SOCKS", "SOCKS5":
+			scheme = "socks5"
+		default:
+			continue // e.g., SOCKS4, which is not supported by Go's HTTP client
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid entry %q in result of FindProxyForURL", strings.TrimSpace(entry))
+		}
+		return &url.URL{Scheme: scheme, Host: fields[1]}, nil
+	}
+	return nil, fmt.Errorf("no usable proxy in result of FindProxyForURL: %q", result)
+}
+
+// shExpMatch returns whether str matches the shell expression, in which "*" matches any characters and "?" matches
+// exactly one character.
+func shExpMatch(str, shexp string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range shexp {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	matched, err := regexp.MatchString(b.String(), str)
+	return err == nil && matched
+}
+
+// myIPAddress returns the IPv4 address of the interface which would be used to reach the internet, or the loopback
+// address when there is none. Connecting a UDP socket does not send any packets.
+func myIPAddress() string {
+	conn, err := net.Dial("udp4", "192.0.2.1:80") // an address from TEST-NET-1 (RFC 5737)
+	if err == nil {
+		defer func() { _ = conn.Close() }()
+		if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+			return addr.IP.String()
+		}
+	}
+	return "127.0.0.1"
+}
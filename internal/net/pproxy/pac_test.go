@@ -0,0 +1,297 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pproxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestPACHelpers(t *testing.T) {
+	// Wednesday, March 15th 2023 at 14:30:45 UTC.
+	now := time.Date(2023, time.March, 15, 14, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{expression: `isPlainHostName("www")`, want: true},
+		{expression: `isPlainHostName("www.example.com")`, want: false},
+		{expression: `dnsDomainIs("www.example.com", ".example.com")`, want: true},
+		{expression: `dnsDomainIs("www.EXAMPLE.com", ".example.com")`, want: true},
+		{expression: `dnsDomainIs("www", ".example.com")`, want: false},
+		{expression: `localHostOrDomainIs("www.example.com", "www.example.com")`, want: true},
+		{expression: `localHostOrDomainIs("www", "www.example.com")`, want: true},
+		{expression: `localHostOrDomainIs("www.other.com", "www.example.com")`, want: false},
+		{expression: `localHostOrDomainIs("home", "www.example.com")`, want: false},
+		{expression: `isResolvable("www.example.com")`, want: true},
+		{expression: `isResolvable("unknown.example.com")`, want: false},
+		{expression: `dnsResolve("www.example.com") === "10.1.2.3"`, want: true},
+		{expression: `dnsResolve("unknown.example.com") === null`, want: true},
+		{expression: `myIpAddress() === "192.168.1.2"`, want: true},
+		{expression: `isInNet("www.example.com", "10.0.0.0", "255.0.0.0")`, want: true},
+		{expression: `isInNet("10.1.2.3", "10.1.3.0", "255.255.255.0")`, want: false},
+		{expression: `isInNet("unknown.example.com", "10.0.0.0", "255.0.0.0")`, want: false},
+		{expression: `dnsDomainLevels("www")`, want: false},
+		{expression: `dnsDomainLevels("www.example.com") === 2`, want: true},
+		{expression: `shExpMatch("http://www.example.com/some/path", "*/some/*")`, want: true},
+		{expression: `shExpMatch("www.example.com", "*.example.???")`, want: true},
+		{expression: `shExpMatch("www.example.org", "*.example.c?m")`, want: false},
+		{expression: `shExpMatch("wwwXexample.com", "www.example.com")`, want: false},
+		{expression: `weekdayRange("WED")`, want: true},
+		{expression: `weekdayRange("MON", "FRI")`, want: true},
+		{expression: `weekdayRange("SAT", "MON")`, want: false},
+		{expression: `weekdayRange("FRI", "WED", "GMT")`, want: true},
+		{expression: `weekdayRange("SOMEDAY")`, want: false},
+		{expression: `dateRange(15)`, want: true},
+		{expression: `dateRange(1, 14)`, want: false},
+		{expression: `dateRange("MAR")`, want: true},
+		{expression: `dateRange("NOV", "FEB")`, want: false},
+		{expression: `dateRange("DEC", "MAR")`, want: true},
+		{expression: `dateRange(2023)`, want: true},
+		{expression: `dateRange(2020, 2022)`, want: false},
+		{expression: `dateRange(15, "MAR", 2023)`, want: true},
+		{expression: `dateRange(1, "MAR", 14, "MAR")`, want: false},
+		{expression: `dateRange(1, "FEB", 2023, 1, "APR", 2023, "GMT")`, want: true},
+		{expression: `timeRange(14)`, want: true},
+		{expression: `timeRange(9, 17)`, want: true},
+		{expression: `timeRange(22, 6)`, want: false},
+		{expression: `timeRange(14, 0, 14, 30)`, want: true},
+		{expression: `timeRange(14, 31, 15, 0)`, want: false},
+		{expression: `timeRange(14, 30, 45, 14, 30, 45, "GMT")`, want: true},
+		{expression: `timeRange(1, 2, 3)`, want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expression, func(t *testing.T) {
+			// Use UTC as the local time zone too, so that the results do not depend on the time zone of the machine.
+			t.Setenv("TZ", "UTC")
+
+			script := newTestPACScript(t, fmt.Sprintf(`function FindProxyForURL(url, host) { return (%s) ? "DIRECT" : "PROXY no:1"; }`, tt.expression), now)
+			result, err := script.findProxyForURL(&url.URL{Scheme: "http", Host: "www.example.com"})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, result == "DIRECT")
+		})
+	}
+}
+
+func TestFindProxyForURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		url       string
+		wantURL   string
+		wantHost  string
+		wantProxy *url.URL
+		wantErr   string
+	}{
+		{
+			name:      "http proxy for some hosts",
+			source:    `function FindProxyForURL(url, host) { return dnsDomainIs(host, ".example.com") ? "PROXY proxy.example.com:3128; DIRECT" : "DIRECT"; }`,
+			url:       "https://issuer.example.com:8443/some/path?some=query",
+			wantURL:   "https://issuer.example.com:8443/",
+			wantHost:  "issuer.example.com",
+			wantProxy: &url.URL{Scheme: "http", Host: "proxy.example.com:3128"},
+		},
+		{
+			name:     "direct for other hosts",
+			source:   `function FindProxyForURL(url, host) { return dnsDomainIs(host, ".example.com") ? "PROXY proxy.example.com:3128; DIRECT" : "DIRECT"; }`,
+			url:      "https://issuer.other.com/some/path",
+			wantURL:  "https://issuer.other.com/",
+			wantHost: "issuer.other.com",
+		},
+		{
+			name:     "http URLs are given to the script in full",
+			source:   `function FindProxyForURL(url, host) { return "DIRECT"; }`,
+			url:      "http://issuer.example.com/some/path?some=query",
+			wantURL:  "http://issuer.example.com/some/path?some=query",
+			wantHost: "issuer.example.com",
+		},
+		{
+			name:      "https proxy",
+			source:    `function FindProxyForURL(url, host) { return "HTTPS proxy.example.com:443"; }`,
+			url:       "https://issuer.example.com",
+			wantProxy: &url.URL{Scheme: "https", Host: "proxy.example.com:443"},
+		},
+		{
+			name:      "socks proxy",
+			source:    `function FindProxyForURL(url, host) { return "SOCKS proxy.example.com:1080"; }`,
+			url:       "https://issuer.example.com",
+			wantProxy: &url.URL{Scheme: "socks5", Host: "proxy.example.com:1080"},
+		},
+		{
+			name:      "unsupported proxy types are skipped",
+			source:    `function FindProxyForURL(url, host) { return "SOCKS4 proxy.example.com:1080;   proxy proxy.example.com:3128"; }`,
+			url:       "https://issuer.example.com",
+			wantProxy: &url.URL{Scheme: "http", Host: "proxy.example.com:3128"},
+		},
+		{
+			name:   "empty result",
+			source: `function FindProxyForURL(url, host) { return ""; }`,
+			url:    "https://issuer.example.com",
+		},
+		{
+			name:   "undefined result",
+			source: `function FindProxyForURL(url, host) {}`,
+			url:    "https://issuer.example.com",
+		},
+		{
+			name:    "no usable proxy",
+			source:  `function FindProxyForURL(url, host) { return "SOCKS4 proxy.example.com:1080"; }`,
+			url:     "https://issuer.example.com",
+			wantErr: `no usable proxy in result of FindProxyForURL: "SOCKS4 proxy.example.com:1080"`,
+		},
+		{
+			name:    "invalid proxy",
+			source:  `function FindProxyForURL(url, host) { return "PROXY"; }`,
+			url:     "https://issuer.example.com",
+			wantErr: `invalid entry "PROXY" in result of FindProxyForURL`,
+		},
+		{
+			name:    "result is not a string",
+			source:  `function FindProxyForURL(url, host) { return 42; }`,
+			url:     "https://issuer.example.com",
+			wantErr: "proxy auto-config failed: FindProxyForURL returned \"42\" instead of a string",
+		},
+		{
+			name:    "script throws an error",
+			source:  `function FindProxyForURL(url, host) { throw new Error("some script error"); }`,
+			url:     "https://issuer.example.com",
+			wantErr: "proxy auto-config failed: FindProxyForURL failed: Error: some script error",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			script := newTestPACScript(t, tt.source, time.Now())
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, tt.url, nil)
+			require.NoError(t, err)
+
+			// Record the arguments given to the script.
+			_, err = script.vm.Run(`
+				var __findProxyForURL = FindProxyForURL, __gotURL, __gotHost;
+				FindProxyForURL = function(url, host) { __gotURL = url; __gotHost = host; return __findProxyForURL(url, host); };
+			`)
+			require.NoError(t, err)
+
+			lazy := &lazyPACScript{load: func(context.Context) (*pacScript, error) { return script, nil }}
+			proxy, err := lazy.proxy(req)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantProxy, proxy)
+
+			if tt.wantURL != "" {
+				gotURL, err := script.vm.Get("__gotURL")
+				require.NoError(t, err)
+				require.Equal(t, tt.wantURL, gotURL.String())
+				gotHost, err := script.vm.Get("__gotHost")
+				require.NoError(t, err)
+				require.Equal(t, tt.wantHost, gotHost.String())
+			}
+		})
+	}
+}
+
+func TestFindProxyForURLTimeout(t *testing.T) {
+	script := newTestPACScript(t, `function FindProxyForURL(url, host) { while (true) {} }`, time.Now())
+
+	start := time.Now()
+	_, err := script.findProxyForURL(&url.URL{Scheme: "https", Host: "issuer.example.com"})
+	require.EqualError(t, err, "FindProxyForURL did not return within 5s")
+	require.GreaterOrEqual(t, time.Since(start), pacCallTimeout)
+
+	// The script can still be used afterwards.
+	_, err = script.vm.Run(`FindProxyForURL = function(url, host) { return "DIRECT"; }`)
+	require.NoError(t, err)
+	result, err := script.findProxyForURL(&url.URL{Scheme: "https", Host: "issuer.example.com"})
+	require.NoError(t, err)
+	require.Equal(t, "DIRECT", result)
+}
+
+func TestNewPACScript(t *testing.T) {
+	_, err := newPACScript(`function FindProxyForURL(url, host) {`, &pacScript{})
+	require.ErrorContains(t, err, "invalid script: (anonymous): Line 1:")
+
+	_, err = newPACScript(`function SomethingElse(url, host) { return "DIRECT"; }`, &pacScript{})
+	require.EqualError(t, err, "invalid script: FindProxyForURL is not defined")
+}
+
+func TestReadPACFile(t *testing.T) {
+	const source = `function FindProxyForURL(url, host) { return "DIRECT"; }`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/proxy.pac":
+			w.Header().Set("Content-Type", "application/x-ns-proxy-autoconfig")
+			_, _ = w.Write([]byte(source))
+		case "/huge.pac":
+			_, _ = w.Write([]byte(strings.Repeat(" ", maxPACFileSize+1)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	path := filepath.Join(testutil.TempDir(t), "proxy.pac")
+	require.NoError(t, os.WriteFile(path, []byte(source), 0600))
+
+	tests := []struct {
+		name     string
+		location string
+		wantErr  string
+	}{
+		{name: "http URL", location: server.URL + "/proxy.pac"},
+		{name: "file URL", location: (&url.URL{Scheme: "file", Path: path}).String()},
+		{name: "local path", location: path},
+		{name: "http error", location: server.URL + "/not-found.pac", wantErr: "unexpected HTTP response status 404"},
+		{name: "too large", location: server.URL + "/huge.pac", wantErr: "file is larger than 1048576 bytes"},
+		{name: "missing file", location: path + ".missing", wantErr: "open " + path + ".missing: no such file or directory"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPACFile(context.Background(), tt.location)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, source, got)
+		})
+	}
+}
+
+func newTestPACScript(t *testing.T, source string, now time.Time) *pacScript {
+	t.Helper()
+
+	script, err := newPACScript(source, &pacScript{
+		lookupIP: func(_ context.Context, host string) ([]net.IP, error) {
+			if host == "www.example.com" {
+				return []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("10.1.2.3")}, nil
+			}
+			return nil, fmt.Errorf("some DNS error")
+		},
+		myIP:      func() string { return "192.168.1.2" },
+		nowMillis: func() int64 { return now.UnixMilli() },
+	})
+	require.NoError(t, err)
+	return script
+}
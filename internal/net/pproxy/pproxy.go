@@ -0,0 +1,137 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pproxy chooses the proxy which the CLI uses to reach the Supervisor and the Concierge. Besides the usual
+// proxy environment variables, it supports an explicit proxy, and proxy auto-config (PAC) files, either given
+// explicitly or configured in the proxy settings of the operating system.
+package pproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+// Direct is the value of Config.Proxy which means that no proxy should be used.
+const Direct = "direct"
+
+// Config describes how to choose a proxy. The settings are used in the order of the fields, and when none of them
+// apply, the proxy is chosen using the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment variables.
+type Config struct {
+	// Proxy is the URL of the proxy to use for all requests, or Direct.
+	Proxy string
+
+	// PACURL is the http, https, or file URL, or the local path, of a proxy auto-config file.
+	PACURL string
+
+	// LookupEnv looks up environment variables. When any of the proxy environment variables are set, the proxy
+	// auto-config file of the operating system is not used. Defaults to os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+
+	// SystemPACURL returns the URL of the proxy auto-config file of the operating system, or "" when there is none,
+	// e.g., SystemPACURL. When nil, the proxy settings of the operating system are not used.
+	SystemPACURL func() string
+
+	// Logger receives debug logs about the chosen proxy settings. Defaults to plog.New().
+	Logger plog.Logger
+}
+
+// proxyEnvVarNames are the environment variables which are used by http.ProxyFromEnvironment.
+var proxyEnvVarNames = []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} //nolint:gochecknoglobals
+
+// New returns a function which chooses the proxy for each request, for use as http.Transport.Proxy. It returns a nil
+// function when the proxy should be chosen using the proxy environment variables, which is what Go's HTTP clients
+// do by default. Proxy auto-config files are only loaded when the first request is made, so that no time is spent
+// on them when the CLI does not need to make any requests, e.g. because it has a cached credential.
+func New(config Config) (func(*http.Request) (*url.URL, error), error) {
+	if config.LookupEnv == nil {
+		config.LookupEnv = os.LookupEnv
+	}
+	if config.Logger == nil {
+		config.Logger = plog.New()
+	}
+
+	if config.Proxy != "" {
+		if strings.EqualFold(config.Proxy, Direct) {
+			return func(*http.Request) (*url.URL, error) { return nil, nil }, nil
+		}
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", config.Proxy, err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf(`invalid proxy URL %q: scheme must be "http", "https", or "socks5"`, config.Proxy)
+		}
+		if proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q: no host", config.Proxy)
+		}
+		return http.ProxyURL(proxyURL), nil
+	}
+
+	if config.PACURL != "" {
+		return (&lazyPACScript{load: func(ctx context.Context) (*pacScript, error) {
+			script, err := loadPACScript(ctx, config.PACURL)
+			if err != nil {
+				return nil, fmt.Errorf("could not load proxy auto-config file %q: %w", config.PACURL, err)
+			}
+			return script, nil
+		}}).proxy, nil
+	}
+
+	for _, name := range proxyEnvVarNames {
+		if value, ok := config.LookupEnv(name); ok && value != "" {
+			config.Logger.Debug("using proxy environment variables", "name", name)
+			return nil, nil
+		}
+	}
+
+	if config.SystemPACURL == nil {
+		return nil, nil
+	}
+	return (&lazyPACScript{load: func(ctx context.Context) (*pacScript, error) {
+		systemPACURL := config.SystemPACURL()
+		if systemPACURL == "" {
+			return nil, nil
+		}
+		script, err := loadPACScript(ctx, systemPACURL)
+		if err != nil {
+			// The settings of the operating system may be stale, e.g. for a network which the user is not connected to.
+			config.Logger.DebugErr("not using the proxy auto-config file of the operating system", err, "url", systemPACURL)
+			return nil, nil
+		}
+		config.Logger.Debug("using the proxy auto-config file of the operating system", "url", systemPACURL)
+		return script, nil
+	}}).proxy, nil
+}
+
+// lazyPACScript loads a proxy auto-config file when it is first needed. When load returns a nil script and no error,
+// no proxy is used.
+type lazyPACScript struct {
+	once   sync.Once
+	load   func(context.Context) (*pacScript, error)
+	script *pacScript
+	err    error
+}
+
+func (l *lazyPACScript) proxy(req *http.Request) (*url.URL, error) {
+	l.once.Do(func() { l.script, l.err = l.load(req.Context()) })
+	if l.err != nil {
+		return nil, l.err
+	}
+	if l.script == nil {
+		return nil, nil
+	}
+	result, err := l.script.findProxyForURL(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("proxy auto-config failed: %w", err)
+	}
+	return proxyFromPACResult(result)
+}
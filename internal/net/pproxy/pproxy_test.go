@@ -0,0 +1,203 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pproxy
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+func TestNew(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/explicit.pac":
+			_, _ = w.Write([]byte(`function FindProxyForURL(url, host) { return "PROXY explicit.example.com:3128"; }`))
+		case "/system.pac":
+			_, _ = w.Write([]byte(`function FindProxyForURL(url, host) { return "PROXY system.example.com:3128"; }`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name         string
+		config       Config
+		wantNil      bool
+		wantProxy    *url.URL
+		wantErr      string
+		wantProxyErr string
+		wantLogParts []string
+	}{
+		{
+			name:    "defaults",
+			config:  Config{},
+			wantNil: true,
+		},
+		{
+			name:      "explicit proxy",
+			config:    Config{Proxy: "http://proxy.example.com:3128", PACURL: server.URL + "/explicit.pac"},
+			wantProxy: &url.URL{Scheme: "http", Host: "proxy.example.com:3128"},
+		},
+		{
+			name:      "explicit socks proxy",
+			config:    Config{Proxy: "socks5://proxy.example.com:1080"},
+			wantProxy: &url.URL{Scheme: "socks5", Host: "proxy.example.com:1080"},
+		},
+		{
+			name:   "explicitly no proxy",
+			config: Config{Proxy: "DIRECT", LookupEnv: lookupEnv(map[string]string{"HTTPS_PROXY": "http://env.example.com:3128"})},
+		},
+		{
+			name:    "invalid proxy URL",
+			config:  Config{Proxy: "%"},
+			wantErr: `invalid proxy URL "%": parse "%": invalid URL escape "%"`,
+		},
+		{
+			name:    "unsupported proxy URL scheme",
+			config:  Config{Proxy: "ftp://proxy.example.com"},
+			wantErr: `invalid proxy URL "ftp://proxy.example.com": scheme must be "http", "https", or "socks5"`,
+		},
+		{
+			name:    "proxy URL without a host",
+			config:  Config{Proxy: "http:///some/path"},
+			wantErr: `invalid proxy URL "http:///some/path": no host`,
+		},
+		{
+			name: "explicit proxy auto-config file",
+			config: Config{
+				PACURL:       server.URL + "/explicit.pac",
+				LookupEnv:    lookupEnv(map[string]string{"HTTPS_PROXY": "http://env.example.com:3128"}),
+				SystemPACURL: func() string { return server.URL + "/system.pac" },
+			},
+			wantProxy: &url.URL{Scheme: "http", Host: "explicit.example.com:3128"},
+		},
+		{
+			name:         "explicit proxy auto-config file cannot be loaded",
+			config:       Config{PACURL: server.URL + "/missing.pac"},
+			wantProxyErr: `could not load proxy auto-config file "` + server.URL + `/missing.pac": unexpected HTTP response status 404`,
+		},
+		{
+			name: "proxy environment variables take precedence over the operating system",
+			config: Config{
+				LookupEnv:    lookupEnv(map[string]string{"https_proxy": "http://env.example.com:3128"}),
+				SystemPACURL: func() string { return server.URL + "/system.pac" },
+			},
+			wantNil:      true,
+			wantLogParts: []string{`"message":"using proxy environment variables","name":"https_proxy"`},
+		},
+		{
+			name: "proxy auto-config file of the operating system",
+			config: Config{
+				LookupEnv:    lookupEnv(map[string]string{"HTTPS_PROXY": ""}),
+				SystemPACURL: func() string { return server.URL + "/system.pac" },
+			},
+			wantProxy:    &url.URL{Scheme: "http", Host: "system.example.com:3128"},
+			wantLogParts: []string{`"message":"using the proxy auto-config file of the operating system","url":"` + server.URL + `/system.pac"`},
+		},
+		{
+			name: "no proxy auto-config file in the operating system",
+			config: Config{
+				LookupEnv:    lookupEnv(nil),
+				SystemPACURL: func() string { return "" },
+			},
+		},
+		{
+			name: "proxy auto-config file of the operating system cannot be loaded",
+			config: Config{
+				LookupEnv:    lookupEnv(nil),
+				SystemPACURL: func() string { return server.URL + "/missing.pac" },
+			},
+			wantLogParts: []string{
+				`"message":"not using the proxy auto-config file of the operating system"`,
+				`"error":"unexpected HTTP response status 404","url":"` + server.URL + `/missing.pac"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var log bytes.Buffer
+			tt.config.Logger = plog.TestLogger(t, &log)
+			if tt.config.LookupEnv == nil {
+				tt.config.LookupEnv = lookupEnv(nil)
+			}
+
+			proxy, err := New(tt.config)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, proxy)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				require.Nil(t, proxy)
+				requireLogContains(t, &log, tt.wantLogParts)
+				return
+			}
+			require.NotNil(t, proxy)
+
+			// Proxy auto-config files are loaded once, by the first request.
+			for i := 0; i < 2; i++ {
+				req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://issuer.example.com/some/path", nil)
+				require.NoError(t, err)
+				got, err := proxy(req)
+				if tt.wantProxyErr != "" {
+					require.EqualError(t, err, tt.wantProxyErr)
+					continue
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.wantProxy, got)
+			}
+			requireLogContains(t, &log, tt.wantLogParts)
+		})
+	}
+}
+
+func TestNewLoadsSystemSettingsLazily(t *testing.T) {
+	var calls int
+	proxy, err := New(Config{
+		LookupEnv: lookupEnv(nil),
+		SystemPACURL: func() string {
+			calls++
+			return ""
+		},
+		Logger: plog.TestLogger(t, &bytes.Buffer{}),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, proxy)
+	require.Zero(t, calls)
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://issuer.example.com", nil)
+		require.NoError(t, err)
+		got, err := proxy(req)
+		require.NoError(t, err)
+		require.Nil(t, got)
+	}
+	require.Equal(t, 1, calls)
+}
+
+func requireLogContains(t *testing.T, log *bytes.Buffer, parts []string) {
+	t.Helper()
+
+	for _, part := range parts {
+		require.Contains(t, log.String(), part)
+	}
+}
+
+func lookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
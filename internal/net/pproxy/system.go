@@ -0,0 +1,78 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pproxy
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// systemSettingsTimeout is how long to wait for the command which reads the proxy settings of the operating system.
+const systemSettingsTimeout = 5 * time.Second
+
+// SystemPACURL returns the URL of the proxy auto-config file which is configured in the proxy settings of the
+// operating system, or "" when there is none. It understands the settings of macOS and Windows, and the settings
+// of GNOME on other platforms.
+func SystemPACURL() string {
+	return systemPACURL(runtime.GOOS, runCommand)
+}
+
+func systemPACURL(goos string, run func(name string, args ...string) (string, error)) string {
+	switch goos {
+	case "darwin":
+		// Prints a dictionary such as "<dictionary> {\n  ProxyAutoConfigEnable : 1\n  ProxyAutoConfigURLString : ...".
+		out, err := run("scutil", "--proxy")
+		if err != nil {
+			return ""
+		}
+		settings := map[string]string{}
+		scanner := bufio.NewScanner(strings.NewReader(out))
+		for scanner.Scan() {
+			if key, value, ok := strings.Cut(scanner.Text(), " : "); ok {
+				settings[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+		if settings["ProxyAutoConfigEnable"] != "1" {
+			return ""
+		}
+		return settings["ProxyAutoConfigURLString"]
+
+	case "windows":
+		// Prints the value as a line such as "    AutoConfigURL    REG_SZ    http://...".
+		out, err := run("reg", "query", `HKCU\Software\Microsoft\Windows\CurrentVersion\Internet Settings`, "/v", "AutoConfigURL")
+		if err != nil {
+			return ""
+		}
+		scanner := bufio.NewScanner(strings.NewReader(out))
+		for scanner.Scan() {
+			if fields := strings.Fields(scanner.Text()); len(fields) == 3 && fields[0] == "AutoConfigURL" && fields[1] == "REG_SZ" {
+				return fields[2]
+			}
+		}
+		return ""
+
+	default:
+		// Prints quoted strings, such as "'auto'".
+		mode, err := run("gsettings", "get", "org.gnome.system.proxy", "mode")
+		if err != nil || strings.Trim(strings.TrimSpace(mode), "'") != "auto" {
+			return ""
+		}
+		autoConfigURL, err := run("gsettings", "get", "org.gnome.system.proxy", "autoconfig-url")
+		if err != nil {
+			return ""
+		}
+		return strings.Trim(strings.TrimSpace(autoConfigURL), "'")
+	}
+}
+
+func runCommand(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), systemSettingsTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	return string(out), err
+}
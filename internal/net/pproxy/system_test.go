@@ -0,0 +1,87 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pproxy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemPACURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		goos    string
+		outputs map[string]string
+		want    string
+	}{
+		{
+			name: "macOS with proxy auto-config enabled",
+			goos: "darwin",
+			outputs: map[string]string{
+				"scutil --proxy": "<dictionary> {\n  HTTPEnable : 0\n  ProxyAutoConfigEnable : 1\n  ProxyAutoConfigURLString : http://wpad.example.com/proxy.pac\n}\n",
+			},
+			want: "http://wpad.example.com/proxy.pac",
+		},
+		{
+			name: "macOS with proxy auto-config disabled",
+			goos: "darwin",
+			outputs: map[string]string{
+				"scutil --proxy": "<dictionary> {\n  ProxyAutoConfigEnable : 0\n  ProxyAutoConfigURLString : http://wpad.example.com/proxy.pac\n}\n",
+			},
+		},
+		{
+			name: "macOS without proxy settings",
+			goos: "darwin",
+		},
+		{
+			name: "Windows with proxy auto-config",
+			goos: "windows",
+			outputs: map[string]string{
+				`reg query HKCU\Software\Microsoft\Windows\CurrentVersion\Internet Settings /v AutoConfigURL`: "\r\nHKEY_CURRENT_USER\\Software\\Microsoft\\Windows\\CurrentVersion\\Internet Settings\r\n    AutoConfigURL    REG_SZ    http://wpad.example.com/proxy.pac\r\n\r\n",
+			},
+			want: "http://wpad.example.com/proxy.pac",
+		},
+		{
+			name: "Windows without proxy auto-config",
+			goos: "windows",
+		},
+		{
+			name: "GNOME with automatic proxy settings",
+			goos: "linux",
+			outputs: map[string]string{
+				"gsettings get org.gnome.system.proxy mode":           "'auto'\n",
+				"gsettings get org.gnome.system.proxy autoconfig-url": "'http://wpad.example.com/proxy.pac'\n",
+			},
+			want: "http://wpad.example.com/proxy.pac",
+		},
+		{
+			name: "GNOME with manual proxy settings",
+			goos: "linux",
+			outputs: map[string]string{
+				"gsettings get org.gnome.system.proxy mode":           "'manual'\n",
+				"gsettings get org.gnome.system.proxy autoconfig-url": "'http://wpad.example.com/proxy.pac'\n",
+			},
+		},
+		{
+			name: "without GNOME",
+			goos: "linux",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			run := func(name string, args ...string) (string, error) {
+				command := strings.Join(append([]string{name}, args...), " ")
+				if out, ok := tt.outputs[command]; ok {
+					return out, nil
+				}
+				return "", fmt.Errorf("some error running %q", command)
+			}
+			require.Equal(t, tt.want, systemPACURL(tt.goos, run))
+		})
+	}
+}
@@ -16,16 +16,21 @@ import (
 	"golang.org/x/oauth2"
 
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/httputil/clientip"
 	"go.pinniped.dev/internal/httputil/httperr"
 	"go.pinniped.dev/internal/httputil/securityheader"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/csrftoken"
 	"go.pinniped.dev/internal/oidc/downstreamsession"
+	"go.pinniped.dev/internal/oidc/lockout"
 	"go.pinniped.dev/internal/oidc/login"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/oidc/provider/formposthtml"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/tracing"
 	"go.pinniped.dev/pkg/oidcclient/nonce"
 	"go.pinniped.dev/pkg/oidcclient/pkce"
 )
@@ -45,8 +50,15 @@ func NewHandler(
 	generateNonce func() (nonce.Nonce, error),
 	upstreamStateEncoder oidc.Encoder,
 	cookieCodec oidc.Codec,
+	lockoutTracker *lockout.Tracker,
+	webAuthn *login.WebAuthn,
+	totp *login.TOTP,
+	loginPolicy *loginpolicy.Policy,
+	auditLogger auditlog.Logger,
 ) http.Handler {
 	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		auditLogger := auditlog.WithSourceIP(auditLogger, clientip.FromContext(r.Context()).IP)
+
 		if r.Method != http.MethodPost && r.Method != http.MethodGet {
 			// https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest
 			// Authorization Servers MUST support the use of the HTTP GET and POST methods defined in
@@ -67,10 +79,11 @@ func NewHandler(
 		}
 
 		if idpType == psession.ProviderTypeOIDC {
+			tracing.SetAttributes(r.Context(), tracing.UpstreamNameKey.String(oidcUpstream.GetName()))
 			if len(r.Header.Values(oidcapi.AuthorizeUsernameHeaderName)) > 0 ||
 				len(r.Header.Values(oidcapi.AuthorizePasswordHeaderName)) > 0 {
 				// The client set a username header, so they are trying to log in with a username/password.
-				return handleAuthRequestForOIDCUpstreamPasswordGrant(r, w, oauthHelperWithStorage, oidcUpstream)
+				return handleAuthRequestForOIDCUpstreamPasswordGrant(r, w, oauthHelperWithStorage, oidcUpstream, loginPolicy, auditLogger)
 			}
 			return handleAuthRequestForOIDCUpstreamBrowserFlow(r, w,
 				oauthHelperWithoutStorage,
@@ -79,10 +92,12 @@ func NewHandler(
 				downstreamIssuer,
 				upstreamStateEncoder,
 				cookieCodec,
+				auditLogger,
 			)
 		}
 
 		// We know it's an AD/LDAP upstream.
+		tracing.SetAttributes(r.Context(), tracing.UpstreamNameKey.String(ldapUpstream.GetName()))
 		if len(r.Header.Values(oidcapi.AuthorizeUsernameHeaderName)) > 0 ||
 			len(r.Header.Values(oidcapi.AuthorizePasswordHeaderName)) > 0 {
 			// The client set a username header, so they are trying to log in with a username/password.
@@ -90,6 +105,11 @@ func NewHandler(
 				oauthHelperWithStorage,
 				ldapUpstream,
 				idpType,
+				lockoutTracker,
+				webAuthn,
+				totp,
+				loginPolicy,
+				auditLogger,
 			)
 		}
 		return handleAuthRequestForLDAPUpstreamBrowserFlow(
@@ -104,6 +124,7 @@ func NewHandler(
 			downstreamIssuer,
 			upstreamStateEncoder,
 			cookieCodec,
+			auditLogger,
 		)
 	})
 
@@ -119,11 +140,17 @@ func handleAuthRequestForLDAPUpstreamCLIFlow(
 	oauthHelper fosite.OAuth2Provider,
 	ldapUpstream provider.UpstreamLDAPIdentityProviderI,
 	idpType psession.ProviderType,
+	lockoutTracker *lockout.Tracker,
+	webAuthn *login.WebAuthn,
+	totp *login.TOTP,
+	loginPolicy *loginpolicy.Policy,
+	auditLogger auditlog.Logger,
 ) error {
 	authorizeRequester, created := newAuthorizeRequest(r, w, oauthHelper, true)
 	if !created {
 		return nil
 	}
+	auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventAuthorizeStarted, authorizeRequester, ldapUpstream.GetName(), "", nil))
 
 	if !requireStaticClientForUsernameAndPasswordHeaders(r, w, oauthHelper, authorizeRequester) {
 		return nil
@@ -134,28 +161,108 @@ func handleAuthRequestForLDAPUpstreamCLIFlow(
 		return nil
 	}
 
+	lockedOut, err := lockoutTracker.IsLockedOut(r.Context(), ldapUpstream.GetName(), string(idpType), username)
+	if err != nil {
+		plog.WarningErr("unexpected error while checking for a password lockout", err, "upstreamName", ldapUpstream.GetName())
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+			authorizeRequester, ldapUpstream.GetName(), username, err))
+		return httperr.New(http.StatusInternalServerError, "unexpected error while checking for a password lockout")
+	}
+	if lockedOut {
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+			authorizeRequester, ldapUpstream.GetName(), username, downstreamsession.ErrLockedOut))
+		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+			fosite.ErrAccessDenied.WithHintf("Too many failed login attempts for this username. Try again later."), true)
+		return nil
+	}
+
+	span := tracing.Start(r.Context(), "upstream LDAP authentication")
 	authenticateResponse, authenticated, err := ldapUpstream.AuthenticateUser(r.Context(), username, password, authorizeRequester.GetGrantedScopes())
+	tracing.End(span, err)
 	if err != nil {
 		plog.WarningErr("unexpected error during upstream LDAP authentication", err, "upstreamName", ldapUpstream.GetName())
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+			authorizeRequester, ldapUpstream.GetName(), username, err))
 		return httperr.New(http.StatusBadGateway, "unexpected error during upstream authentication")
 	}
 	if !authenticated {
+		lockedOut := lockoutTracker.RecordFailure(r.Context(), ldapUpstream.GetName(), string(idpType), username)
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+			authorizeRequester, ldapUpstream.GetName(), username, downstreamsession.ErrUsernamePasswordNotAccepted))
+		if lockedOut {
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUserLockedOut,
+				authorizeRequester, ldapUpstream.GetName(), username, downstreamsession.ErrLockedOut))
+		}
 		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
 			fosite.ErrAccessDenied.WithHintf("Username/password not accepted by LDAP provider."), true)
 		return nil
 	}
+	lockoutTracker.RecordSuccess(r.Context(), ldapUpstream.GetName(), string(idpType), username)
 
 	subject := downstreamsession.DownstreamSubjectFromUpstreamLDAP(ldapUpstream, authenticateResponse)
 	username = authenticateResponse.User.GetName()
 	groups := authenticateResponse.User.GetGroups()
+
+	if !evaluateLoginPolicy(r, w, oauthHelper, authorizeRequester, loginPolicy, ldapUpstream.GetName(), username, groups, auditLogger) {
+		return nil
+	}
+
+	// The CLI cannot use a security key or ask for a TOTP code, so users who must verify a second factor have to log in
+	// with a browser.
+	requiresSecondFactor, err := webAuthn.RequiresSecondFactor(r.Context(), subject)
+	if err == nil && !requiresSecondFactor {
+		requiresSecondFactor, err = totp.RequiresSecondFactor(r.Context(), subject)
+	}
+	if err != nil {
+		plog.WarningErr("unexpected error while checking for second factor", err, "upstreamName", ldapUpstream.GetName())
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+			authorizeRequester, ldapUpstream.GetName(), username, err))
+		return httperr.New(http.StatusInternalServerError, "unexpected error while checking for second factor")
+	}
+	if requiresSecondFactor {
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+			authorizeRequester, ldapUpstream.GetName(), username, downstreamsession.ErrSecondFactorRequired))
+		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+			fosite.ErrAccessDenied.WithHintf("A second factor is required for this user. Log in with a browser instead."), true)
+		return nil
+	}
+
 	customSessionData := downstreamsession.MakeDownstreamLDAPOrADCustomSessionData(ldapUpstream, idpType, authenticateResponse, username)
 	openIDSession := downstreamsession.MakeDownstreamSession(subject, username, groups,
 		authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, map[string]interface{}{})
+	auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationSucceeded,
+		authorizeRequester, ldapUpstream.GetName(), username, nil))
+	tracing.SetAttributes(r.Context(), tracing.SessionIDKey.String(authorizeRequester.GetID()))
 	oidc.PerformAuthcodeRedirect(r, w, oauthHelper, authorizeRequester, openIDSession, true)
 
 	return nil
 }
 
+// evaluateLoginPolicy returns true when the login policy of the FederationDomain allows the login of the user who
+// was authenticated by the upstream identity provider. Otherwise, it writes the error response and returns false.
+func evaluateLoginPolicy(
+	r *http.Request,
+	w http.ResponseWriter,
+	oauthHelper fosite.OAuth2Provider,
+	authorizeRequester fosite.AuthorizeRequester,
+	loginPolicy *loginpolicy.Policy,
+	upstreamName string,
+	username string,
+	groups []string,
+	auditLogger auditlog.Logger,
+) bool {
+	err := loginPolicy.Evaluate(username, groups, clientip.FromContext(r.Context()).IP, time.Now())
+	if err == nil {
+		return true
+	}
+	plog.Info("login denied by policy", "upstreamName", upstreamName, "reason", err.Error())
+	auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventLoginPolicyDenied,
+		authorizeRequester, upstreamName, username, err))
+	oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+		fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), true)
+	return false
+}
+
 func handleAuthRequestForLDAPUpstreamBrowserFlow(
 	r *http.Request,
 	w http.ResponseWriter,
@@ -168,6 +275,7 @@ func handleAuthRequestForLDAPUpstreamBrowserFlow(
 	downstreamIssuer string,
 	upstreamStateEncoder oidc.Encoder,
 	cookieCodec oidc.Codec,
+	auditLogger auditlog.Logger,
 ) error {
 	authRequestState, err := handleBrowserFlowAuthRequest(
 		r,
@@ -180,6 +288,7 @@ func handleAuthRequestForLDAPUpstreamBrowserFlow(
 		idpType,
 		cookieCodec,
 		upstreamStateEncoder,
+		auditLogger,
 	)
 	if err != nil {
 		return err
@@ -189,6 +298,7 @@ func handleAuthRequestForLDAPUpstreamBrowserFlow(
 		return nil
 	}
 
+	tracing.AddEvent(r.Context(), "redirecting to login page")
 	return login.RedirectToLoginPage(r, w, downstreamIssuer, authRequestState.encodedStateParam, login.ShowNoError)
 }
 
@@ -197,11 +307,14 @@ func handleAuthRequestForOIDCUpstreamPasswordGrant(
 	w http.ResponseWriter,
 	oauthHelper fosite.OAuth2Provider,
 	oidcUpstream provider.UpstreamOIDCIdentityProviderI,
+	loginPolicy *loginpolicy.Policy,
+	auditLogger auditlog.Logger,
 ) error {
 	authorizeRequester, created := newAuthorizeRequest(r, w, oauthHelper, true)
 	if !created {
 		return nil
 	}
+	auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventAuthorizeStarted, authorizeRequester, oidcUpstream.GetName(), "", nil))
 
 	if !requireStaticClientForUsernameAndPasswordHeaders(r, w, oauthHelper, authorizeRequester) {
 		return nil
@@ -220,7 +333,9 @@ func handleAuthRequestForOIDCUpstreamPasswordGrant(
 		return nil
 	}
 
+	span := tracing.Start(r.Context(), "upstream password grant")
 	token, err := oidcUpstream.PasswordCredentialsGrantAndValidateTokens(r.Context(), username, password)
+	tracing.End(span, err)
 	if err != nil {
 		// Upstream password grant errors can be generic errors (e.g. a network failure) or can be oauth2.RetrieveError errors
 		// which represent the http response from the upstream server. These could be a 5XX or some other unexpected error,
@@ -229,13 +344,17 @@ func handleAuthRequestForOIDCUpstreamPasswordGrant(
 		// However, the exact response is undefined in the sense that there is no such thing as a password grant in
 		// the OIDC spec, so we don't try too hard to read the upstream errors in this case. (E.g. Dex departs from the
 		// spec and returns something other than an "invalid_grant" error for bad resource owner credentials.)
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+			authorizeRequester, oidcUpstream.GetName(), username, err))
 		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
 			fosite.ErrAccessDenied.WithDebug(err.Error()), true) // WithDebug hides the error from the client
 		return nil
 	}
 
-	subject, username, groups, err := downstreamsession.GetDownstreamIdentityFromUpstreamIDToken(oidcUpstream, token.IDToken.Claims)
+	subject, downstreamUsername, groups, err := downstreamsession.GetDownstreamIdentityFromUpstreamIDToken(oidcUpstream, token.IDToken.Claims)
 	if err != nil {
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+			authorizeRequester, oidcUpstream.GetName(), username, err))
 		// Return a user-friendly error for this case which is entirely within our control.
 		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
 			fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), true,
@@ -243,19 +362,28 @@ func handleAuthRequestForOIDCUpstreamPasswordGrant(
 		return nil
 	}
 
+	if !evaluateLoginPolicy(r, w, oauthHelper, authorizeRequester, loginPolicy, oidcUpstream.GetName(), downstreamUsername, groups, auditLogger) {
+		return nil
+	}
+
 	additionalClaims := downstreamsession.MapAdditionalClaimsFromUpstreamIDToken(oidcUpstream, token.IDToken.Claims)
 
-	customSessionData, err := downstreamsession.MakeDownstreamOIDCCustomSessionData(oidcUpstream, token, username)
+	customSessionData, err := downstreamsession.MakeDownstreamOIDCCustomSessionData(oidcUpstream, token, downstreamUsername)
 	if err != nil {
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+			authorizeRequester, oidcUpstream.GetName(), downstreamUsername, err))
 		oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
 			fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), true,
 		)
 		return nil
 	}
 
-	openIDSession := downstreamsession.MakeDownstreamSession(subject, username, groups,
+	openIDSession := downstreamsession.MakeDownstreamSession(subject, downstreamUsername, groups,
 		authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, additionalClaims)
 
+	auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationSucceeded,
+		authorizeRequester, oidcUpstream.GetName(), downstreamUsername, nil))
+	tracing.SetAttributes(r.Context(), tracing.SessionIDKey.String(authorizeRequester.GetID()))
 	oidc.PerformAuthcodeRedirect(r, w, oauthHelper, authorizeRequester, openIDSession, true)
 
 	return nil
@@ -272,6 +400,7 @@ func handleAuthRequestForOIDCUpstreamBrowserFlow(
 	downstreamIssuer string,
 	upstreamStateEncoder oidc.Encoder,
 	cookieCodec oidc.Codec,
+	auditLogger auditlog.Logger,
 ) error {
 	authRequestState, err := handleBrowserFlowAuthRequest(
 		r,
@@ -284,6 +413,7 @@ func handleAuthRequestForOIDCUpstreamBrowserFlow(
 		psession.ProviderTypeOIDC,
 		cookieCodec,
 		upstreamStateEncoder,
+		auditLogger,
 	)
 	if err != nil {
 		return err
@@ -312,6 +442,7 @@ func handleAuthRequestForOIDCUpstreamBrowserFlow(
 		authCodeOptions = append(authCodeOptions, oauth2.SetAuthURLParam(key, val))
 	}
 
+	tracing.AddEvent(r.Context(), "redirecting to upstream identity provider")
 	http.Redirect(w, r,
 		upstreamOAuthConfig.AuthCodeURL(
 			authRequestState.encodedStateParam,
@@ -358,6 +489,8 @@ func newAuthorizeRequest(r *http.Request, w http.ResponseWriter, oauthHelper fos
 	// an error if the client requested a scope that they are not allowed to request, so we don't need to worry about that here.
 	downstreamsession.AutoApproveScopes(authorizeRequester)
 
+	tracing.SetAttributes(r.Context(), tracing.ClientIDKey.String(authorizeRequester.GetClient().GetID()))
+
 	return authorizeRequester, true
 }
 
@@ -442,11 +575,13 @@ func handleBrowserFlowAuthRequest(
 	idpType psession.ProviderType,
 	cookieCodec oidc.Codec,
 	upstreamStateEncoder oidc.Encoder,
+	auditLogger auditlog.Logger,
 ) (*browserFlowAuthRequestState, error) {
 	authorizeRequester, created := newAuthorizeRequest(r, w, oauthHelper, false)
 	if !created {
 		return nil, nil // already wrote the error response, don't return error
 	}
+	auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventAuthorizeStarted, authorizeRequester, upstreamName, "", nil))
 
 	now := time.Now()
 	_, err := oauthHelper.NewAuthorizeResponse(r.Context(), authorizeRequester, &psession.PinnipedSession{
@@ -469,6 +604,7 @@ func handleBrowserFlowAuthRequest(
 		plog.Error("authorize generate error", err)
 		return nil, err
 	}
+	tracing.SetAttributes(r.Context(), tracing.LoginID(string(nonceValue)))
 	csrfFromCookie := readCSRFCookie(r, cookieCodec)
 	if csrfFromCookie != "" {
 		csrfValue = csrfFromCookie
@@ -22,23 +22,33 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/oauth2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/client-go/kubernetes/fake"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	kubetesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 
 	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
 	"go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
+	"go.pinniped.dev/internal/auditlog"
 	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/crud"
 	"go.pinniped.dev/internal/here"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/csrftoken"
 	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/lockout"
+	"go.pinniped.dev/internal/oidc/login"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/psession"
 	"go.pinniped.dev/internal/testutil"
 	"go.pinniped.dev/internal/testutil/oidctestutil"
+	"go.pinniped.dev/internal/totpsecretstorage"
+	"go.pinniped.dev/internal/webauthncredentialstorage"
 	"go.pinniped.dev/pkg/oidcclient/nonce"
 	"go.pinniped.dev/pkg/oidcclient/pkce"
 )
@@ -160,6 +170,24 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			"state":             happyState,
 		}
 
+		fositeAccessDeniedWithLockedOutHintErrorQuery = map[string]string{
+			"error":             "access_denied",
+			"error_description": "The resource owner or authorization server denied the request. Too many failed login attempts for this username. Try again later.",
+			"state":             happyState,
+		}
+
+		fositeAccessDeniedWithLoginPolicyHintErrorQuery = map[string]string{
+			"error":             "access_denied",
+			"error_description": "The resource owner or authorization server denied the request. Reason: login denied by policy: the user is not a member of an allowed group.",
+			"state":             happyState,
+		}
+
+		fositeAccessDeniedWithSecondFactorRequiredHintErrorQuery = map[string]string{
+			"error":             "access_denied",
+			"error_description": "The resource owner or authorization server denied the request. A second factor is required for this user. Log in with a browser instead.",
+			"state":             happyState,
+		}
+
 		fositeAccessDeniedWithMissingUsernamePasswordHintErrorQuery = map[string]string{
 			"error":             "access_denied",
 			"error_description": "The resource owner or authorization server denied the request. Missing or blank username or password.",
@@ -555,6 +583,12 @@ func TestAuthorizationEndpoint(t *testing.T) {
 		csrfCookie           string
 		customUsernameHeader *string // nil means do not send header, empty means send header with empty value
 		customPasswordHeader *string // nil means do not send header, empty means send header with empty value
+		lockoutTracker       *lockout.Tracker
+		webAuthnEnforcement  provider.WebAuthnEnforcement
+		webAuthnEnrolled     bool // whether the happy LDAP user has enrolled a WebAuthn credential
+		totpEnforcement      provider.TOTPEnforcement
+		totpEnrolled         bool // whether the happy LDAP user has enrolled a TOTP secret
+		loginPolicy          loginpolicy.Config
 
 		wantStatus                             int
 		wantContentType                        string
@@ -583,6 +617,9 @@ func TestAuthorizationEndpoint(t *testing.T) {
 		wantPasswordGrantCall             *expectedPasswordGrant
 		wantDownstreamCustomSessionData   *psession.CustomSessionData
 		wantDownstreamAdditionalClaims    map[string]interface{}
+
+		// Assertions for the audit events, which are only checked when set.
+		wantAuditEvents []auditlog.Event
 	}
 	tests := []testCase{
 		{
@@ -601,6 +638,9 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantLocationHeader:                     expectedRedirectLocationForUpstreamOIDC(expectedUpstreamStateParam(nil, "", oidcUpstreamName, "oidc"), nil),
 			wantUpstreamStateParamInLocationHeader: true,
 			wantBodyStringWithLocationInHref:       true,
+			wantAuditEvents: []auditlog.Event{
+				{Type: auditlog.EventAuthorizeStarted, ClientID: pinnipedCLIClientID, IdentityProvider: oidcUpstreamName},
+			},
 		},
 		{
 			name:                                   "OIDC upstream browser flow happy path using GET without a CSRF cookie using a dynamic client",
@@ -711,6 +751,10 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
 			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
 			wantDownstreamCustomSessionData:   expectedHappyOIDCPasswordGrantCustomSession,
+			wantAuditEvents: []auditlog.Event{
+				{Type: auditlog.EventAuthorizeStarted, ClientID: pinnipedCLIClientID, IdentityProvider: oidcPasswordGrantUpstreamName},
+				{Type: auditlog.EventUpstreamAuthenticationSucceeded, ClientID: pinnipedCLIClientID, IdentityProvider: oidcPasswordGrantUpstreamName, Username: oidcUpstreamUsername},
+			},
 		},
 		{
 			name: "OIDC upstream password grant happy path using GET with additional claim mappings",
@@ -794,6 +838,10 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
 			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
 			wantDownstreamCustomSessionData:   expectedHappyLDAPUpstreamCustomSession,
+			wantAuditEvents: []auditlog.Event{
+				{Type: auditlog.EventAuthorizeStarted, ClientID: pinnipedCLIClientID, IdentityProvider: ldapUpstreamName},
+				{Type: auditlog.EventUpstreamAuthenticationSucceeded, ClientID: pinnipedCLIClientID, IdentityProvider: ldapUpstreamName, Username: happyLDAPUsernameFromAuthenticator},
+			},
 		},
 		{
 			name:                              "ActiveDirectory cli upstream happy path using GET",
@@ -1386,6 +1434,66 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantContentType:      jsonContentType,
 			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithBadUsernamePasswordHintErrorQuery),
 			wantBodyString:       "",
+			wantAuditEvents: []auditlog.Event{
+				{Type: auditlog.EventAuthorizeStarted, ClientID: pinnipedCLIClientID, IdentityProvider: ldapUpstreamName},
+				{
+					Type:             auditlog.EventUpstreamAuthenticationFailed,
+					ClientID:         pinnipedCLIClientID,
+					IdentityProvider: ldapUpstreamName,
+					Username:         happyLDAPUsername,
+					Error:            "username/password not accepted by upstream provider",
+				},
+			},
+		},
+		{
+			name:                 "wrong upstream password for LDAP authentication which reaches the max failed attempts",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String("wrong-password"),
+			lockoutTracker:       lockout.New(lockout.Config{MaxFailedAttempts: 1}, clocktesting.NewFakeClock(time.Now())),
+			wantStatus:           http.StatusFound,
+			wantContentType:      jsonContentType,
+			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithBadUsernamePasswordHintErrorQuery),
+			wantBodyString:       "",
+			wantAuditEvents: []auditlog.Event{
+				{Type: auditlog.EventAuthorizeStarted, ClientID: pinnipedCLIClientID, IdentityProvider: ldapUpstreamName},
+				{
+					Type:             auditlog.EventUpstreamAuthenticationFailed,
+					ClientID:         pinnipedCLIClientID,
+					IdentityProvider: ldapUpstreamName,
+					Username:         happyLDAPUsername,
+					Error:            "username/password not accepted by upstream provider",
+				},
+				{
+					Type:             auditlog.EventUserLockedOut,
+					ClientID:         pinnipedCLIClientID,
+					IdentityProvider: ldapUpstreamName,
+					Username:         happyLDAPUsername,
+					Error:            "too many failed login attempts for this username",
+				},
+			},
+		},
+		{
+			name:                 "error while checking for a password lockout during LDAP authentication",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			lockoutTracker: func() *lockout.Tracker {
+				client := fake.NewSimpleClientset()
+				client.PrependReactor("get", "secrets", func(kubetesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("some get error")
+				})
+				config := lockout.Config{MaxFailedAttempts: 1}
+				fakeClock := clocktesting.NewFakeClock(time.Now())
+				return lockout.NewWithStore(config, fakeClock, lockout.NewSecretStore(client.CoreV1().Secrets("some-namespace"), fakeClock, config))
+			}(),
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: htmlContentType,
+			wantBodyString:  "Internal Server Error: unexpected error while checking for a password lockout\n",
 		},
 		{
 			name:                 "wrong upstream password for Active Directory authentication",
@@ -1399,6 +1507,183 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithBadUsernamePasswordHintErrorQuery),
 			wantBodyString:       "",
 		},
+		{
+			name:                 "correct upstream password for LDAP authentication for a locked out user",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			lockoutTracker: func() *lockout.Tracker {
+				tracker := lockout.New(lockout.Config{MaxFailedAttempts: 1}, clocktesting.NewFakeClock(time.Now()))
+				tracker.RecordFailure(context.Background(), ldapUpstreamName, "ldap", happyLDAPUsername)
+				return tracker
+			}(),
+			wantStatus:         http.StatusFound,
+			wantContentType:    jsonContentType,
+			wantLocationHeader: urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithLockedOutHintErrorQuery),
+			wantBodyString:     "",
+		},
+		{
+			name:                 "correct upstream password for LDAP authentication when a second factor is required",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			webAuthnEnforcement:  provider.WebAuthnEnforcementRequired,
+			wantStatus:           http.StatusFound,
+			wantContentType:      jsonContentType,
+			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithSecondFactorRequiredHintErrorQuery),
+			wantBodyString:       "",
+			wantAuditEvents: []auditlog.Event{
+				{Type: auditlog.EventAuthorizeStarted, ClientID: pinnipedCLIClientID, IdentityProvider: ldapUpstreamName},
+				{
+					Type:             auditlog.EventUpstreamAuthenticationFailed,
+					ClientID:         pinnipedCLIClientID,
+					IdentityProvider: ldapUpstreamName,
+					Username:         happyLDAPUsernameFromAuthenticator,
+					Error:            "second factor required, which is only supported by browser-based logins",
+				},
+			},
+		},
+		{
+			name:                 "correct upstream password for LDAP authentication when the user has enrolled a second factor",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			webAuthnEnforcement:  provider.WebAuthnEnforcementIfEnrolled,
+			webAuthnEnrolled:     true,
+			wantStatus:           http.StatusFound,
+			wantContentType:      jsonContentType,
+			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithSecondFactorRequiredHintErrorQuery),
+			wantBodyString:       "",
+		},
+		{
+			name:                              "correct upstream password for LDAP authentication when the user has not enrolled an optional second factor",
+			idps:                              oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:                            http.MethodGet,
+			path:                              happyGetRequestPath,
+			customUsernameHeader:              pointer.String(happyLDAPUsername),
+			customPasswordHeader:              pointer.String(happyLDAPPassword),
+			webAuthnEnforcement:               provider.WebAuthnEnforcementIfEnrolled,
+			wantStatus:                        http.StatusFound,
+			wantContentType:                   htmlContentType,
+			wantRedirectLocationRegexp:        happyAuthcodeDownstreamRedirectLocationRegexp,
+			wantDownstreamIDTokenSubject:      upstreamLDAPURL + "&sub=" + happyLDAPUID,
+			wantDownstreamIDTokenUsername:     happyLDAPUsernameFromAuthenticator,
+			wantDownstreamIDTokenGroups:       happyLDAPGroups,
+			wantDownstreamRequestedScopes:     happyDownstreamScopesRequested,
+			wantDownstreamRedirectURI:         downstreamRedirectURI,
+			wantDownstreamGrantedScopes:       happyDownstreamScopesGranted,
+			wantDownstreamNonce:               downstreamNonce,
+			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
+			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
+			wantDownstreamCustomSessionData:   expectedHappyLDAPUpstreamCustomSession,
+		},
+		{
+			name:                 "correct upstream password for LDAP authentication when a TOTP second factor is required",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			totpEnforcement:      provider.TOTPEnforcementRequired,
+			wantStatus:           http.StatusFound,
+			wantContentType:      jsonContentType,
+			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithSecondFactorRequiredHintErrorQuery),
+			wantBodyString:       "",
+		},
+		{
+			name:                 "correct upstream password for LDAP authentication when the user has enrolled a TOTP second factor",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			totpEnforcement:      provider.TOTPEnforcementIfEnrolled,
+			totpEnrolled:         true,
+			wantStatus:           http.StatusFound,
+			wantContentType:      jsonContentType,
+			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithSecondFactorRequiredHintErrorQuery),
+			wantBodyString:       "",
+		},
+		{
+			name:                 "correct upstream password for LDAP authentication when the login policy denies the user",
+			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:               http.MethodGet,
+			path:                 happyGetRequestPath,
+			customUsernameHeader: pointer.String(happyLDAPUsername),
+			customPasswordHeader: pointer.String(happyLDAPPassword),
+			webAuthnEnforcement:  provider.WebAuthnEnforcementRequired,
+			loginPolicy:          loginpolicy.Config{AllowedGroups: []string{"admins"}},
+			wantStatus:           http.StatusFound,
+			wantContentType:      jsonContentType,
+			wantLocationHeader:   urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithLoginPolicyHintErrorQuery),
+			wantBodyString:       "",
+			wantAuditEvents: []auditlog.Event{
+				{Type: auditlog.EventAuthorizeStarted, ClientID: pinnipedCLIClientID, IdentityProvider: ldapUpstreamName},
+				{
+					Type:             auditlog.EventLoginPolicyDenied,
+					ClientID:         pinnipedCLIClientID,
+					IdentityProvider: ldapUpstreamName,
+					Username:         happyLDAPUsernameFromAuthenticator,
+					Error:            "login denied by policy: the user is not a member of an allowed group",
+				},
+			},
+		},
+		{
+			name:                              "correct upstream password for LDAP authentication when the login policy allows the user",
+			idps:                              oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			method:                            http.MethodGet,
+			path:                              happyGetRequestPath,
+			customUsernameHeader:              pointer.String(happyLDAPUsername),
+			customPasswordHeader:              pointer.String(happyLDAPPassword),
+			loginPolicy:                       loginpolicy.Config{AllowedGroups: []string{"admins", "group2"}},
+			wantStatus:                        http.StatusFound,
+			wantContentType:                   htmlContentType,
+			wantRedirectLocationRegexp:        happyAuthcodeDownstreamRedirectLocationRegexp,
+			wantDownstreamIDTokenSubject:      upstreamLDAPURL + "&sub=" + happyLDAPUID,
+			wantDownstreamIDTokenUsername:     happyLDAPUsernameFromAuthenticator,
+			wantDownstreamIDTokenGroups:       happyLDAPGroups,
+			wantDownstreamRequestedScopes:     happyDownstreamScopesRequested,
+			wantDownstreamRedirectURI:         downstreamRedirectURI,
+			wantDownstreamGrantedScopes:       happyDownstreamScopesGranted,
+			wantDownstreamNonce:               downstreamNonce,
+			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
+			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
+			wantDownstreamCustomSessionData:   expectedHappyLDAPUpstreamCustomSession,
+		},
+		{
+			name:                  "OIDC upstream password grant when the login policy denies the user",
+			idps:                  oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(passwordGrantUpstreamOIDCIdentityProviderBuilder().Build()),
+			method:                http.MethodGet,
+			path:                  happyGetRequestPath,
+			customUsernameHeader:  pointer.String(oidcUpstreamUsername),
+			customPasswordHeader:  pointer.String(oidcUpstreamPassword),
+			loginPolicy:           loginpolicy.Config{AllowedEmailDomains: []string{"example.com"}},
+			wantPasswordGrantCall: happyUpstreamPasswordGrantMockExpectation,
+			wantStatus:            http.StatusFound,
+			wantContentType:       jsonContentType,
+			wantLocationHeader: urlWithQuery(downstreamRedirectURI, map[string]string{
+				"error":             "access_denied",
+				"error_description": "The resource owner or authorization server denied the request. Reason: login denied by policy: the email domain of the username is not allowed.",
+				"state":             happyState,
+			}),
+			wantBodyString: "",
+			wantAuditEvents: []auditlog.Event{
+				{Type: auditlog.EventAuthorizeStarted, ClientID: pinnipedCLIClientID, IdentityProvider: oidcPasswordGrantUpstreamName},
+				{
+					Type:             auditlog.EventLoginPolicyDenied,
+					ClientID:         pinnipedCLIClientID,
+					IdentityProvider: oidcPasswordGrantUpstreamName,
+					Username:         oidcUpstreamUsername,
+					Error:            "login denied by policy: the email domain of the username is not allowed",
+				},
+			},
+		},
 		{
 			name:                 "wrong upstream username for LDAP authentication",
 			idps:                 oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
@@ -3246,14 +3531,42 @@ func TestAuthorizationEndpoint(t *testing.T) {
 				require.True(t, len(idps.GetOIDCIdentityProviders()) > 0, "wantDownstreamAdditionalClaims requires at least one OIDC IDP")
 			}
 
+			// Use another client for the second factor storage, so its actions do not count as stored records.
+			secondFactorSecretsClient := fake.NewSimpleClientset().CoreV1().Secrets("some-namespace")
+			webAuthnCredentials := webauthncredentialstorage.New(secondFactorSecretsClient)
+			if test.webAuthnEnrolled {
+				require.NoError(t, webAuthnCredentials.Set(context.Background(), "", upstreamLDAPURL+"&sub="+happyLDAPUID,
+					happyLDAPUsernameFromAuthenticator, []webauthncredentialstorage.Credential{{RPID: "some-issuer.com"}}))
+			}
+			webAuthn := login.NewWebAuthn(test.webAuthnEnforcement, webAuthnCredentials, crud.NewSecretsBackend(secondFactorSecretsClient), time.Now)
+			totpSecrets := totpsecretstorage.New(secondFactorSecretsClient, func() []byte { return []byte("some-neato-32-byte-generated-key") })
+			if test.totpEnrolled {
+				require.NoError(t, totpSecrets.Set(context.Background(), "", &totpsecretstorage.TOTP{
+					Subject: upstreamLDAPURL + "&sub=" + happyLDAPUID, Secret: []byte("some-totp-secret"),
+				}))
+			}
+			totp := login.NewTOTP(test.totpEnforcement, totpSecrets, crud.NewSecretsBackend(secondFactorSecretsClient), time.Now)
+			loginPolicy, err := loginpolicy.New(test.loginPolicy)
+			require.NoError(t, err)
+
+			auditRecorder := &testutil.AuditRecorder{}
 			subject := NewHandler(
 				downstreamIssuer,
 				idps,
 				oauthHelperWithNullStorage, oauthHelperWithRealStorage,
 				test.generateCSRF, test.generatePKCE, test.generateNonce,
 				test.stateEncoder, test.cookieEncoder,
+				test.lockoutTracker,
+				webAuthn,
+				totp,
+				loginPolicy,
+				auditRecorder,
 			)
 			runOneTestCase(t, test, subject, kubeOauthStore, supervisorClient, kubeClient, secretsClient)
+
+			if test.wantAuditEvents != nil {
+				auditRecorder.RequireEventsWithRandomSessionIDs(t, test.wantAuditEvents)
+			}
 		})
 	}
 
@@ -3275,6 +3588,11 @@ func TestAuthorizationEndpoint(t *testing.T) {
 			oauthHelperWithNullStorage, oauthHelperWithRealStorage,
 			test.generateCSRF, test.generatePKCE, test.generateNonce,
 			test.stateEncoder, test.cookieEncoder,
+			nil,
+			nil,
+			nil,
+			nil,
+			auditlog.Nop(),
 		)
 
 		runOneTestCase(t, test, subject, kubeOauthStore, supervisorClient, kubeClient, secretsClient)
@@ -7,16 +7,22 @@ package callback
 import (
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/ory/fosite"
 
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/httputil/clientip"
 	"go.pinniped.dev/internal/httputil/httperr"
 	"go.pinniped.dev/internal/httputil/securityheader"
 	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/clientregistry"
 	"go.pinniped.dev/internal/oidc/downstreamsession"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/oidc/provider/formposthtml"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/tracing"
 )
 
 func NewHandler(
@@ -24,12 +30,17 @@ func NewHandler(
 	oauthHelper fosite.OAuth2Provider,
 	stateDecoder, cookieDecoder oidc.Decoder,
 	redirectURI string,
+	loginPolicy *loginpolicy.Policy,
+	auditLogger auditlog.Logger,
 ) http.Handler {
 	handler := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		auditLogger := auditlog.WithSourceIP(auditLogger, clientip.FromContext(r.Context()).IP)
+
 		state, err := validateRequest(r, stateDecoder, cookieDecoder)
 		if err != nil {
 			return err
 		}
+		tracing.SetAttributes(r.Context(), tracing.LoginID(string(state.Nonce)), tracing.UpstreamNameKey.String(state.UpstreamName))
 
 		upstreamIDPConfig := findUpstreamIDPConfig(state.UpstreamName, upstreamIDPs)
 		if upstreamIDPConfig == nil {
@@ -56,7 +67,9 @@ func NewHandler(
 		// This is instead of asking the user to approve these scopes. Note that `NewAuthorizeRequest` would have returned
 		// an error if the client requested a scope that they are not allowed to request, so we don't need to worry about that here.
 		downstreamsession.AutoApproveScopes(authorizeRequester)
+		tracing.SetAttributes(r.Context(), tracing.ClientIDKey.String(authorizeRequester.GetClient().GetID()))
 
+		span := tracing.Start(r.Context(), "upstream token exchange")
 		token, err := upstreamIDPConfig.ExchangeAuthcodeAndValidateTokens(
 			r.Context(),
 			authcode(r),
@@ -64,20 +77,41 @@ func NewHandler(
 			state.Nonce,
 			redirectURI,
 		)
+		tracing.End(span, err)
 		if err != nil {
 			plog.WarningErr("error exchanging and validating upstream tokens", err, "upstreamName", upstreamIDPConfig.GetName())
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+				authorizeRequester, upstreamIDPConfig.GetName(), "", err))
 			return httperr.New(http.StatusBadGateway, "error exchanging and validating upstream tokens")
 		}
 
 		subject, username, groups, err := downstreamsession.GetDownstreamIdentityFromUpstreamIDToken(upstreamIDPConfig, token.IDToken.Claims)
 		if err != nil {
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+				authorizeRequester, upstreamIDPConfig.GetName(), "", err))
 			return httperr.Wrap(http.StatusUnprocessableEntity, err.Error(), err)
 		}
 
+		if err := loginPolicy.Evaluate(username, groups, clientip.FromContext(r.Context()).IP, time.Now()); err != nil {
+			plog.Info("login denied by policy", "upstreamName", upstreamIDPConfig.GetName(), "reason", err.Error())
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventLoginPolicyDenied,
+				authorizeRequester, upstreamIDPConfig.GetName(), username, err))
+			return httperr.New(http.StatusForbidden, err.Error())
+		}
+		if err := clientregistry.CheckRequiredGroups(authorizeRequester.GetClient(), groups); err != nil {
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventClientAccessDenied,
+				authorizeRequester, upstreamIDPConfig.GetName(), username, err))
+			oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+				fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), false)
+			return nil
+		}
+
 		additionalClaims := downstreamsession.MapAdditionalClaimsFromUpstreamIDToken(upstreamIDPConfig, token.IDToken.Claims)
 
 		customSessionData, err := downstreamsession.MakeDownstreamOIDCCustomSessionData(upstreamIDPConfig, token, username)
 		if err != nil {
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+				authorizeRequester, upstreamIDPConfig.GetName(), username, err))
 			return httperr.Wrap(http.StatusUnprocessableEntity, err.Error(), err)
 		}
 
@@ -91,6 +125,10 @@ func NewHandler(
 			return httperr.Wrap(http.StatusInternalServerError, "error while generating and saving authcode", err)
 		}
 
+		auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationSucceeded,
+			authorizeRequester, upstreamIDPConfig.GetName(), username, nil))
+		tracing.SetAttributes(r.Context(), tracing.SessionIDKey.String(authorizeRequester.GetID()))
+
 		oauthHelper.WriteAuthorizeResponse(r.Context(), w, authorizeRequester, authorizeResponder)
 
 		return nil
@@ -21,8 +21,10 @@ import (
 
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/auditlog"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
 	"go.pinniped.dev/internal/psession"
 	"go.pinniped.dev/internal/testutil"
@@ -165,6 +167,17 @@ func TestCallbackEndpoint(t *testing.T) {
 		require.NoError(t, kubeClient.Tracker().Add(secret))
 	}
 
+	addDynamicClientWithRequiredGroupsAndSecretToKubeResources := func(requiredGroups ...string) func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+		return func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+			oidcClient, secret := testutil.FullyCapableOIDCClientAndStorageSecret(t,
+				"some-namespace", downstreamDynamicClientID, downstreamDynamicClientUID, downstreamRedirectURI,
+				[]string{testutil.HashedPassword1AtGoMinCost}, oidcclientvalidator.Validate)
+			oidcClient.Spec.RequiredGroups = requiredGroups
+			require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+			require.NoError(t, kubeClient.Tracker().Add(secret))
+		}
+	}
+
 	tests := []struct {
 		name string
 
@@ -173,11 +186,13 @@ func TestCallbackEndpoint(t *testing.T) {
 		method        string
 		path          string
 		csrfCookie    string
+		loginPolicy   loginpolicy.Config
 
 		wantStatus                        int
 		wantContentType                   string
 		wantBody                          string
 		wantRedirectLocationRegexp        string
+		wantRedirectLocationString        string
 		wantBodyFormResponseRegexp        string
 		wantDownstreamGrantedScopes       []string
 		wantDownstreamIDTokenSubject      string
@@ -192,6 +207,9 @@ func TestCallbackEndpoint(t *testing.T) {
 		wantDownstreamAdditionalClaims    map[string]interface{}
 
 		wantAuthcodeExchangeCall *expectedAuthcodeExchange
+
+		// Assertions for the audit events, which are only checked when set.
+		wantAuditEvents []auditlog.Event
 	}{
 		{
 			name:   "GET with good state and cookie and successful upstream token exchange with response_mode=form_post returns 200 with HTML+JS form",
@@ -290,6 +308,12 @@ func TestCallbackEndpoint(t *testing.T) {
 				performedByUpstreamName: happyUpstreamIDPName,
 				args:                    happyExchangeAndValidateTokensArgs,
 			},
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventUpstreamAuthenticationSucceeded,
+				ClientID:         downstreamPinnipedClientID,
+				IdentityProvider: happyUpstreamIDPName,
+				Username:         oidcUpstreamUsername,
+			}},
 		},
 		{
 			name:                              "GET with good state and cookie and successful upstream token exchange returns 303 to downstream client callback with its state and code when using dynamic client",
@@ -316,6 +340,58 @@ func TestCallbackEndpoint(t *testing.T) {
 				args:                    happyExchangeAndValidateTokensArgs,
 			},
 		},
+		{
+			name:                              "GET with good state and cookie and successful upstream token exchange returns 303 to downstream client callback with its state and code when using dynamic client which requires a group of the user",
+			idps:                              oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			kubeResources:                     addDynamicClientWithRequiredGroupsAndSecretToKubeResources("admins", oidcUpstreamGroupMembership[0]),
+			method:                            http.MethodGet,
+			path:                              newRequestPath().WithState(happyStateForDynamicClient).String(),
+			csrfCookie:                        happyCSRFCookie,
+			wantStatus:                        http.StatusSeeOther,
+			wantRedirectLocationRegexp:        happyDownstreamRedirectLocationRegexp,
+			wantBody:                          "",
+			wantDownstreamIDTokenSubject:      oidcUpstreamIssuer + "?sub=" + oidcUpstreamSubjectQueryEscaped,
+			wantDownstreamIDTokenUsername:     oidcUpstreamUsername,
+			wantDownstreamIDTokenGroups:       oidcUpstreamGroupMembership,
+			wantDownstreamRequestedScopes:     happyDownstreamScopesRequested,
+			wantDownstreamGrantedScopes:       happyDownstreamScopesGranted,
+			wantDownstreamNonce:               downstreamNonce,
+			wantDownstreamClientID:            downstreamDynamicClientID,
+			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
+			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
+			wantDownstreamCustomSessionData:   happyDownstreamCustomSessionData,
+			wantAuthcodeExchangeCall: &expectedAuthcodeExchange{
+				performedByUpstreamName: happyUpstreamIDPName,
+				args:                    happyExchangeAndValidateTokensArgs,
+			},
+		},
+		{
+			name:          "GET with good state and cookie and successful upstream token exchange returns 303 to downstream client callback with access_denied when using dynamic client which requires a group of which the user is not a member",
+			idps:          oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			kubeResources: addDynamicClientWithRequiredGroupsAndSecretToKubeResources("admins"),
+			method:        http.MethodGet,
+			path:          newRequestPath().WithState(happyStateForDynamicClient).String(),
+			csrfCookie:    happyCSRFCookie,
+			wantStatus:    http.StatusSeeOther,
+			wantRedirectLocationString: downstreamRedirectURI + "?" + url.Values{
+				"error":             {"access_denied"},
+				"error_description": {"The resource owner or authorization server denied the request. Reason: the user is not a member of a group required by the client."},
+				"state":             {happyDownstreamState},
+			}.Encode(),
+			wantContentType: htmlContentType,
+			wantBody:        "",
+			wantAuthcodeExchangeCall: &expectedAuthcodeExchange{
+				performedByUpstreamName: happyUpstreamIDPName,
+				args:                    happyExchangeAndValidateTokensArgs,
+			},
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventClientAccessDenied,
+				ClientID:         downstreamDynamicClientID,
+				IdentityProvider: happyUpstreamIDPName,
+				Username:         oidcUpstreamUsername,
+				Error:            "the user is not a member of a group required by the client",
+			}},
+		},
 		{
 			name:                              "GET with authcode exchange that returns an access token but no refresh token when there is a userinfo endpoint returns 303 to downstream client callback with its state and code",
 			idps:                              oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().WithEmptyRefreshToken().WithAccessToken(oidcUpstreamAccessToken, metav1.NewTime(time.Now().Add(9*time.Hour))).WithUserInfoURL().Build()),
@@ -1214,6 +1290,59 @@ func TestCallbackEndpoint(t *testing.T) {
 				performedByUpstreamName: happyUpstreamIDPName,
 				args:                    happyExchangeAndValidateTokensArgs,
 			},
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventUpstreamAuthenticationFailed,
+				ClientID:         downstreamPinnipedClientID,
+				IdentityProvider: happyUpstreamIDPName,
+				Error:            "some error",
+			}},
+		},
+		{
+			name:        "GET with good state and cookie when the login policy denies the user",
+			idps:        oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			method:      http.MethodGet,
+			path:        newRequestPath().WithState(happyState).String(),
+			csrfCookie:  happyCSRFCookie,
+			loginPolicy: loginpolicy.Config{AllowedGroups: []string{"admins"}},
+			wantStatus:  http.StatusForbidden,
+			wantBody:    "Forbidden: login denied by policy: the user is not a member of an allowed group\n",
+			wantAuthcodeExchangeCall: &expectedAuthcodeExchange{
+				performedByUpstreamName: happyUpstreamIDPName,
+				args:                    happyExchangeAndValidateTokensArgs,
+			},
+			wantContentType: htmlContentType,
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventLoginPolicyDenied,
+				ClientID:         downstreamPinnipedClientID,
+				IdentityProvider: happyUpstreamIDPName,
+				Username:         oidcUpstreamUsername,
+				Error:            "login denied by policy: the user is not a member of an allowed group",
+			}},
+		},
+		{
+			name:                              "GET with good state and cookie when the login policy allows the user",
+			idps:                              oidctestutil.NewUpstreamIDPListerBuilder().WithOIDC(happyUpstream().Build()),
+			method:                            http.MethodGet,
+			path:                              newRequestPath().WithState(happyState).String(),
+			csrfCookie:                        happyCSRFCookie,
+			loginPolicy:                       loginpolicy.Config{AllowedGroups: oidcUpstreamGroupMembership[1:]},
+			wantStatus:                        http.StatusSeeOther,
+			wantRedirectLocationRegexp:        happyDownstreamRedirectLocationRegexp,
+			wantBody:                          "",
+			wantDownstreamIDTokenSubject:      oidcUpstreamIssuer + "?sub=" + oidcUpstreamSubjectQueryEscaped,
+			wantDownstreamIDTokenUsername:     oidcUpstreamUsername,
+			wantDownstreamIDTokenGroups:       oidcUpstreamGroupMembership,
+			wantDownstreamRequestedScopes:     happyDownstreamScopesRequested,
+			wantDownstreamGrantedScopes:       happyDownstreamScopesGranted,
+			wantDownstreamNonce:               downstreamNonce,
+			wantDownstreamClientID:            downstreamPinnipedClientID,
+			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
+			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
+			wantDownstreamCustomSessionData:   happyDownstreamCustomSessionData,
+			wantAuthcodeExchangeCall: &expectedAuthcodeExchange{
+				performedByUpstreamName: happyUpstreamIDPName,
+				args:                    happyExchangeAndValidateTokensArgs,
+			},
 		},
 		{
 			name: "upstream ID token does not contain requested username claim",
@@ -1457,7 +1586,11 @@ func TestCallbackEndpoint(t *testing.T) {
 			jwksProviderIsUnused := jwks.NewDynamicJWKSProvider()
 			oauthHelper := oidc.FositeOauth2Helper(oauthStore, downstreamIssuer, hmacSecretFunc, jwksProviderIsUnused, timeoutsConfiguration)
 
-			subject := NewHandler(test.idps.Build(), oauthHelper, happyStateCodec, happyCookieCodec, happyUpstreamRedirectURI)
+			loginPolicy, err := loginpolicy.New(test.loginPolicy)
+			require.NoError(t, err)
+
+			auditRecorder := &testutil.AuditRecorder{}
+			subject := NewHandler(test.idps.Build(), oauthHelper, happyStateCodec, happyCookieCodec, happyUpstreamRedirectURI, loginPolicy, auditRecorder)
 			reqContext := context.WithValue(context.Background(), struct{ name string }{name: "test"}, "request-context")
 			req := httptest.NewRequest(test.method, test.path, nil).WithContext(reqContext)
 			if test.csrfCookie != "" {
@@ -1538,6 +1671,14 @@ func TestCallbackEndpoint(t *testing.T) {
 					test.wantDownstreamAdditionalClaims,
 				)
 			}
+
+			if test.wantRedirectLocationString != "" {
+				require.Equal(t, test.wantRedirectLocationString, rsp.Header().Get("Location"))
+			}
+
+			if test.wantAuditEvents != nil {
+				auditRecorder.RequireEventsWithRandomSessionIDs(t, test.wantAuditEvents)
+			}
 		})
 	}
 }
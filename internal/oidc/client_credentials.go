@@ -0,0 +1,132 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/pkg/errors"
+	"k8s.io/utils/strings/slices"
+
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/psession"
+)
+
+// clientCredentialsClaimScope is the name of the claim which lists the granted scopes of a client credentials token.
+const clientCredentialsClaimScope = "scope"
+
+func ClientCredentialsFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	return &ClientCredentialsHandler{
+		idTokenStrategy: strategy.(openid.OpenIDConnectTokenStrategy),
+		fositeConfig:    config,
+	}
+}
+
+// ClientCredentialsHandler handles the client_credentials grant of OIDCClients. Instead of an opaque access token,
+// it issues a JWT which is signed like the ID tokens of the FederationDomain, so that other services can validate it.
+// The JWT identifies the client by its sub claim and has no username claim, so it cannot be used to act as a user,
+// e.g. in a token exchange or with a JWTAuthenticator of the Concierge. Nothing is stored for these tokens.
+type ClientCredentialsHandler struct {
+	idTokenStrategy openid.OpenIDConnectTokenStrategy
+	fositeConfig    fosite.Configurator
+}
+
+var _ fosite.TokenEndpointHandler = (*ClientCredentialsHandler)(nil)
+
+func (c *ClientCredentialsHandler) HandleTokenEndpointRequest(ctx context.Context, requester fosite.AccessRequester) error {
+	if !c.CanHandleTokenEndpointRequest(ctx, requester) {
+		return errors.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	// Check that the client is allowed to perform this grant type.
+	if !requester.GetClient().GetGrantTypes().Has(oidcapi.GrantTypeClientCredentials) {
+		// This error message is trying to be similar to the analogous one in fosite's flow_authorize_code_token.go.
+		return errors.WithStack(fosite.ErrUnauthorizedClient.WithHintf(`The OAuth 2.0 Client is not allowed to use client credentials grant "%s".`, oidcapi.GrantTypeClientCredentials))
+	}
+	clientCredentials := clientregistry.ClientCredentials(requester.GetClient())
+	if clientCredentials == nil || requester.GetClient().IsPublic() {
+		// This shouldn't really happen, since the OIDCClient would not have been valid.
+		return errors.WithStack(fosite.ErrUnauthorizedClient.WithHint("The OAuth 2.0 Client is not configured for the client credentials grant."))
+	}
+
+	audience, err := clientCredentialsAudience(requester.GetRequestedAudience(), clientCredentials.AllowedAudiences)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, scope := range requester.GetRequestedScopes() {
+		if !slices.Contains(clientCredentials.AllowedScopes, scope) {
+			return errors.WithStack(fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope))
+		}
+	}
+
+	for _, scope := range requester.GetRequestedScopes() {
+		requester.GrantScope(scope)
+	}
+	requester.GrantAudience(audience)
+	return nil
+}
+
+// clientCredentialsAudience returns the one audience of a client credentials token.
+func clientCredentialsAudience(requestedAudiences []string, allowedAudiences []string) (string, error) {
+	switch len(requestedAudiences) {
+	case 0:
+		if len(allowedAudiences) != 1 {
+			return "", fosite.ErrInvalidRequest.WithHint("Missing 'audience' parameter.")
+		}
+		return allowedAudiences[0], nil
+	case 1:
+		if !slices.Contains(allowedAudiences, requestedAudiences[0]) {
+			return "", fosite.ErrInvalidRequest.WithHintf("The OAuth 2.0 Client is not allowed to request audience '%s'.", requestedAudiences[0])
+		}
+		return requestedAudiences[0], nil
+	default:
+		return "", fosite.ErrInvalidRequest.WithHint("Only one 'audience' may be requested.")
+	}
+}
+
+func (c *ClientCredentialsHandler) PopulateTokenEndpointResponse(ctx context.Context, requester fosite.AccessRequester, responder fosite.AccessResponder) error {
+	// Skip this request if it's for a different grant type.
+	if !c.CanHandleTokenEndpointRequest(ctx, requester) {
+		return errors.WithStack(fosite.ErrUnknownRequest)
+	}
+
+	clientID := requester.GetClient().GetID()
+	session := psession.NewPinnipedSession()
+	session.Fosite.Subject = clientID
+	session.Fosite.Claims.Subject = clientID
+	session.Fosite.Claims.Extra = map[string]interface{}{oidcapi.IDTokenClaimAuthorizedParty: clientID}
+	if scopes := requester.GetGrantedScopes(); len(scopes) > 0 {
+		session.Fosite.Claims.Extra[clientCredentialsClaimScope] = strings.Join(scopes, " ")
+	}
+
+	// The audience of the JWT is the ID of the client of the request which is given to the ID token strategy.
+	tokenRequester := fosite.NewAccessRequest(session)
+	tokenRequester.Client.(*fosite.DefaultClient).ID = requester.GetGrantedAudience()[0]
+
+	lifespan := c.fositeConfig.GetAccessTokenLifespan(ctx)
+	token, err := c.idTokenStrategy.GenerateIDToken(ctx, lifespan, tokenRequester)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	responder.SetAccessToken(token)
+	responder.SetTokenType("bearer")
+	responder.SetExpiresIn(lifespan)
+	if scopes := requester.GetGrantedScopes(); len(scopes) > 0 {
+		responder.SetScopes(scopes)
+	}
+	return nil
+}
+
+func (c *ClientCredentialsHandler) CanSkipClientAuth(_ context.Context, _ fosite.AccessRequester) bool {
+	return false
+}
+
+func (c *ClientCredentialsHandler) CanHandleTokenEndpointRequest(_ context.Context, requester fosite.AccessRequester) bool {
+	return requester.GetGrantTypes().ExactOne(oidcapi.GrantTypeClientCredentials)
+}
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package clientregistry defines Pinniped's OAuth2/OIDC clients.
@@ -7,17 +7,23 @@ package clientregistry
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
 	"github.com/ory/fosite"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/strings/slices"
 
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
 	supervisorclient "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
+	"go.pinniped.dev/internal/constable"
 	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
 	"go.pinniped.dev/internal/oidcclientsecretstorage"
 	"go.pinniped.dev/internal/plog"
@@ -27,6 +33,30 @@ import (
 // or a dynamic client defined by an OIDCClient CR.
 type Client struct {
 	fosite.DefaultOpenIDConnectClient
+
+	// requiredGroups are the groups of which a user must be a member of at least one to log in with this client.
+	// When empty, all users may log in with this client. They are not stored with the sessions of the client,
+	// because they are only checked during the authorization flow.
+	requiredGroups []string
+
+	// groupsClaim filters the groups claim of the ID tokens which are issued to this client, when not nil. Like
+	// requiredGroups, it is not stored with the sessions of the client, so the client must be looked up again by its
+	// ID to filter the groups claim of a session which was loaded from storage.
+	groupsClaim *groupsClaimFilter
+
+	// clientCredentials configures the client_credentials grant of this client, when not nil. It is only used by
+	// the token endpoint, which always looks up the client again, so it is not stored with the sessions either.
+	clientCredentials *configv1alpha1.OIDCClientCredentials
+
+	// tokenProfile shapes the ID tokens which are issued to this client, when not nil. Like groupsClaim, it is not
+	// stored with the sessions of the client.
+	tokenProfile *configv1alpha1.OIDCClientTokenProfile
+}
+
+// groupsClaimFilter is the compiled form of the spec.groupsClaim of an OIDCClient.
+type groupsClaimFilter struct {
+	allowedPatterns []*regexp.Regexp
+	maxGroups       int
 }
 
 // Client implements the base, OIDC, and response_mode client interfaces of Fosite.
@@ -45,11 +75,102 @@ func (c *Client) GetResponseModes() []fosite.ResponseModeType {
 	return []fosite.ResponseModeType{fosite.ResponseModeDefault, fosite.ResponseModeQuery}
 }
 
+// ErrNotInRequiredGroups is returned by CheckRequiredGroups when the user may not log in with the client.
+const ErrNotInRequiredGroups = constable.Error("the user is not a member of a group required by the client")
+
+// RequiresGroups returns whether the given client only allows the members of certain groups to log in.
+func RequiresGroups(client fosite.Client) bool {
+	c, ok := client.(*Client)
+	return ok && len(c.requiredGroups) > 0
+}
+
+// CheckRequiredGroups returns ErrNotInRequiredGroups when the given client requires the user to be a member of one
+// of its required groups, and the user with the given downstream groups is not.
+func CheckRequiredGroups(client fosite.Client, groups []string) error {
+	if !RequiresGroups(client) {
+		return nil
+	}
+	for _, group := range groups {
+		if slices.Contains(client.(*Client).requiredGroups, group) {
+			return nil
+		}
+	}
+	return ErrNotInRequiredGroups
+}
+
+// FiltersGroupsClaim returns whether the given client filters or limits the groups claim of its ID tokens.
+func FiltersGroupsClaim(client fosite.Client) bool {
+	c, ok := client.(*Client)
+	return ok && c.groupsClaim != nil
+}
+
+// FilterGroupsClaim returns the downstream groups which may be included in the groups claim of the ID tokens which
+// are issued to the given client, along with whether some groups were left out because the client limits the number
+// of groups in the claim. Clients which do not filter their groups claim get all the given groups.
+func FilterGroupsClaim(client fosite.Client, groups []string) ([]string, bool) {
+	if !FiltersGroupsClaim(client) {
+		return groups, false
+	}
+	filter := client.(*Client).groupsClaim
+
+	filtered := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if filter.allows(group) {
+			filtered = append(filtered, group)
+		}
+	}
+
+	if filter.maxGroups == 0 || len(filtered) <= filter.maxGroups {
+		return filtered, false
+	}
+	sort.Strings(filtered)
+	return filtered[:filter.maxGroups], true
+}
+
+func (f *groupsClaimFilter) allows(group string) bool {
+	if len(f.allowedPatterns) == 0 {
+		return true
+	}
+	for _, pattern := range f.allowedPatterns {
+		if pattern.MatchString(group) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientCredentials returns the configuration of the client_credentials grant of the given client, or nil when the
+// client may not use the client_credentials grant.
+func ClientCredentials(client fosite.Client) *configv1alpha1.OIDCClientCredentials {
+	c, ok := client.(*Client)
+	if !ok || !c.GetGrantTypes().Has(oidcapi.GrantTypeClientCredentials) {
+		return nil
+	}
+	return c.clientCredentials
+}
+
+// TokenProfile returns the profile which shapes the ID tokens which are issued to the given client, or nil when the
+// tokens of the client should not be shaped for any cloud provider.
+func TokenProfile(client fosite.Client) *configv1alpha1.OIDCClientTokenProfile {
+	c, ok := client.(*Client)
+	if !ok {
+		return nil
+	}
+	return c.tokenProfile
+}
+
 // ClientManager is a fosite.ClientManager with a statically-defined client and with dynamically-defined clients.
 type ClientManager struct {
 	oidcClientsClient supervisorclient.OIDCClientInterface
 	storage           *oidcclientsecretstorage.OIDCClientSecretStorage
 	minBcryptCost     int
+	nowFunc           func() time.Time
+
+	// Remembers which client owns each client secret hash returned by GetClient, so that uses of client secrets
+	// can be recorded. See SecretsHasher.
+	lock         sync.Mutex
+	secretUses   map[string]*secretUse
+	clientHashes map[types.UID][]string
 }
 
 var _ fosite.ClientManager = (*ClientManager)(nil)
@@ -63,6 +184,9 @@ func NewClientManager(
 		oidcClientsClient: oidcClientsClient,
 		storage:           storage,
 		minBcryptCost:     minBcryptCost,
+		nowFunc:           time.Now,
+		secretUses:        make(map[string]*secretUse),
+		clientHashes:      make(map[types.UID][]string),
 	}
 }
 
@@ -111,6 +235,7 @@ func (m *ClientManager) GetClient(ctx context.Context, id string) (fosite.Client
 	}
 
 	// Everything is valid, so return the client. Note that it has at least one client secret to be considered valid.
+	m.trackSecretHashes(oidcClient.Name, oidcClient.UID, clientSecrets)
 	return oidcClientCRToFositeClient(oidcClient, clientSecrets), nil
 }
 
@@ -192,7 +317,25 @@ func oidcClientCRToFositeClient(oidcClient *configv1alpha1.OIDCClient, clientSec
 			TokenEndpointAuthSigningAlgorithm: coreosoidc.RS256,
 			TokenEndpointAuthMethod:           "client_secret_basic",
 		},
+		requiredGroups:    oidcClient.Spec.RequiredGroups,
+		groupsClaim:       groupsClaimToFilter(oidcClient.Spec.GroupsClaim),
+		clientCredentials: oidcClient.Spec.ClientCredentials,
+		tokenProfile:      oidcClient.Spec.TokenProfile,
+	}
+}
+
+func groupsClaimToFilter(groupsClaim *configv1alpha1.OIDCClientGroupsClaim) *groupsClaimFilter {
+	if groupsClaim == nil || (len(groupsClaim.AllowedPatterns) == 0 && groupsClaim.MaxGroups == 0) {
+		return nil
+	}
+	filter := &groupsClaimFilter{maxGroups: int(groupsClaim.MaxGroups)}
+	for _, pattern := range groupsClaim.AllowedPatterns {
+		// The patterns of valid clients always compile, since they were checked by the validator.
+		if compiled, err := oidcclientvalidator.CompileGroupsClaimPattern(pattern); err == nil {
+			filter.allowedPatterns = append(filter.allowedPatterns, compiled)
+		}
 	}
+	return filter
 }
 
 func scopesToArguments(scopes []configv1alpha1.Scope) fosite.Arguments {
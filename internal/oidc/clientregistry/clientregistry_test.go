@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package clientregistry
@@ -188,6 +188,7 @@ func TestClientManager(t *testing.T) {
 						AllowedGrantTypes:   []configv1alpha1.GrantType{"authorization_code", "urn:ietf:params:oauth:grant-type:token-exchange", "refresh_token"},
 						AllowedScopes:       []configv1alpha1.Scope{"openid", "offline_access", "pinniped:request-audience", "username", "groups"},
 						AllowedRedirectURIs: []configv1alpha1.RedirectURI{"http://localhost:80", "https://foobar.com/callback"},
+						RequiredGroups:      []string{"admins", "developers"},
 					},
 				},
 				{
@@ -221,6 +222,7 @@ func TestClientManager(t *testing.T) {
 				require.Equal(t, "client_secret_basic", c.GetTokenEndpointAuthMethod())
 				require.Equal(t, "RS256", c.GetTokenEndpointAuthSigningAlgorithm())
 				require.Equal(t, []fosite.ResponseModeType{"", "query"}, c.GetResponseModes())
+				require.Equal(t, []string{"admins", "developers"}, c.requiredGroups)
 			},
 		},
 	}
@@ -315,3 +317,124 @@ func requireEqualsPinnipedCLI(t *testing.T, c *Client) {
 		  "token_endpoint_auth_signing_alg": "RS256"
 		}`, string(marshaled))
 }
+
+func TestCheckRequiredGroups(t *testing.T) {
+	withRequiredGroups := &Client{requiredGroups: []string{"admins", "developers"}}
+
+	require.False(t, RequiresGroups(PinnipedCLI()))
+	require.False(t, RequiresGroups(&Client{}))
+	require.False(t, RequiresGroups(&fosite.DefaultClient{}))
+	require.True(t, RequiresGroups(withRequiredGroups))
+
+	require.NoError(t, CheckRequiredGroups(PinnipedCLI(), nil))
+	require.NoError(t, CheckRequiredGroups(withRequiredGroups, []string{"users", "developers"}))
+	require.ErrorIs(t, CheckRequiredGroups(withRequiredGroups, []string{"users", "Admins"}), ErrNotInRequiredGroups)
+	require.ErrorIs(t, CheckRequiredGroups(withRequiredGroups, nil), ErrNotInRequiredGroups)
+}
+
+func TestFilterGroupsClaim(t *testing.T) {
+	groups := []string{"eng-frontend", "sales", "eng-backend", "eng-ops"}
+
+	tests := []struct {
+		name         string
+		groupsClaim  *configv1alpha1.OIDCClientGroupsClaim
+		wantFilters  bool
+		wantGroups   []string
+		wantOverflow bool
+	}{
+		{
+			name:       "no groupsClaim",
+			wantGroups: groups,
+		},
+		{
+			name:        "empty groupsClaim",
+			groupsClaim: &configv1alpha1.OIDCClientGroupsClaim{},
+			wantGroups:  groups,
+		},
+		{
+			name:        "allowed patterns must match the whole group name",
+			groupsClaim: &configv1alpha1.OIDCClientGroupsClaim{AllowedPatterns: []string{"eng-.*end", "sale"}},
+			wantFilters: true,
+			wantGroups:  []string{"eng-frontend", "eng-backend"},
+		},
+		{
+			name:        "max groups is not exceeded",
+			groupsClaim: &configv1alpha1.OIDCClientGroupsClaim{MaxGroups: 4},
+			wantFilters: true,
+			wantGroups:  groups,
+		},
+		{
+			name:         "max groups is exceeded",
+			groupsClaim:  &configv1alpha1.OIDCClientGroupsClaim{MaxGroups: 2},
+			wantFilters:  true,
+			wantGroups:   []string{"eng-backend", "eng-frontend"},
+			wantOverflow: true,
+		},
+		{
+			name:         "max groups is exceeded after filtering",
+			groupsClaim:  &configv1alpha1.OIDCClientGroupsClaim{AllowedPatterns: []string{"eng-.*"}, MaxGroups: 2},
+			wantFilters:  true,
+			wantGroups:   []string{"eng-backend", "eng-frontend"},
+			wantOverflow: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := oidcClientCRToFositeClient(&configv1alpha1.OIDCClient{
+				Spec: configv1alpha1.OIDCClientSpec{GroupsClaim: tt.groupsClaim},
+			}, nil)
+
+			require.Equal(t, tt.wantFilters, FiltersGroupsClaim(client))
+			filteredGroups, overflow := FilterGroupsClaim(client, groups)
+			require.Equal(t, tt.wantGroups, filteredGroups)
+			require.Equal(t, tt.wantOverflow, overflow)
+		})
+	}
+
+	require.False(t, FiltersGroupsClaim(PinnipedCLI()))
+	require.False(t, FiltersGroupsClaim(&fosite.DefaultClient{}))
+}
+
+func TestClientCredentials(t *testing.T) {
+	clientCredentials := &configv1alpha1.OIDCClientCredentials{AllowedAudiences: []string{"some-gateway"}}
+
+	withGrant := oidcClientCRToFositeClient(&configv1alpha1.OIDCClient{
+		Spec: configv1alpha1.OIDCClientSpec{
+			AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code", "client_credentials"},
+			ClientCredentials: clientCredentials,
+		},
+	}, nil)
+	withoutGrant := oidcClientCRToFositeClient(&configv1alpha1.OIDCClient{
+		Spec: configv1alpha1.OIDCClientSpec{
+			AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+			ClientCredentials: clientCredentials,
+		},
+	}, nil)
+
+	require.Equal(t, clientCredentials, ClientCredentials(withGrant))
+	require.Nil(t, ClientCredentials(withoutGrant))
+	require.Nil(t, ClientCredentials(PinnipedCLI()))
+	require.Nil(t, ClientCredentials(&fosite.DefaultClient{GrantTypes: []string{"client_credentials"}}))
+}
+
+func TestTokenProfile(t *testing.T) {
+	tokenProfile := &configv1alpha1.OIDCClientTokenProfile{CloudProvider: configv1alpha1.OIDCClientCloudProviderAWS}
+
+	withProfile := oidcClientCRToFositeClient(&configv1alpha1.OIDCClient{
+		Spec: configv1alpha1.OIDCClientSpec{
+			AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+			TokenProfile:      tokenProfile,
+		},
+	}, nil)
+	withoutProfile := oidcClientCRToFositeClient(&configv1alpha1.OIDCClient{
+		Spec: configv1alpha1.OIDCClientSpec{
+			AllowedGrantTypes: []configv1alpha1.GrantType{"authorization_code"},
+		},
+	}, nil)
+
+	require.Equal(t, tokenProfile, TokenProfile(withProfile))
+	require.Nil(t, TokenProfile(withoutProfile))
+	require.Nil(t, TokenProfile(PinnipedCLI()))
+	require.Nil(t, TokenProfile(&fosite.DefaultClient{}))
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clientregistry
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/fosite"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"go.pinniped.dev/internal/oidcclientsecretstorage"
+	"go.pinniped.dev/internal/plog"
+)
+
+// lastUsedUpdateInterval is the minimum time between updates of the last used timestamp of each client secret,
+// to avoid updating the client secret storage Secret upon every client authentication.
+const lastUsedUpdateInterval = time.Hour
+
+// secretUse remembers which client owns a client secret hash, and when its use was last recorded.
+type secretUse struct {
+	clientName string
+	clientUID  types.UID
+	recorded   time.Time
+}
+
+// SecretsHasher returns a fosite.Hasher which compares client secrets using the wrapped fosite.Hasher, and which
+// records when each client secret of an OIDCClient was last used in its client secret storage Secret.
+// Failures to record a use are logged, but never cause the client authentication to fail.
+func (m *ClientManager) SecretsHasher(wrapped fosite.Hasher) fosite.Hasher {
+	return &recordingHasher{Hasher: wrapped, manager: m}
+}
+
+type recordingHasher struct {
+	fosite.Hasher
+	manager *ClientManager
+}
+
+func (h *recordingHasher) Compare(ctx context.Context, hash, data []byte) error {
+	if err := h.Hasher.Compare(ctx, hash, data); err != nil {
+		return err
+	}
+	h.manager.recordSecretUse(ctx, string(hash))
+	return nil
+}
+
+// trackSecretHashes remembers that the client owns the given hashes, forgetting any of its previous hashes.
+func (m *ClientManager) trackSecretHashes(clientName string, clientUID types.UID, hashes []string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, hash := range m.clientHashes[clientUID] {
+		if !contains(hashes, hash) {
+			delete(m.secretUses, hash)
+		}
+	}
+	for _, hash := range hashes {
+		if _, ok := m.secretUses[hash]; !ok {
+			m.secretUses[hash] = &secretUse{clientName: clientName, clientUID: clientUID}
+		}
+	}
+	m.clientHashes[clientUID] = hashes
+}
+
+func (m *ClientManager) recordSecretUse(ctx context.Context, hash string) {
+	now := m.nowFunc()
+
+	m.lock.Lock()
+	use, ok := m.secretUses[hash]
+	if !ok || now.Sub(use.recorded) < lastUsedUpdateInterval {
+		// Either this is not the hash of an OIDCClient's secret, or its use was recorded recently enough.
+		m.lock.Unlock()
+		return
+	}
+	// Claim this update before releasing the lock, so concurrent authentications do not also try to make it.
+	use.recorded = now
+	clientName, clientUID := use.clientName, use.clientUID
+	m.lock.Unlock()
+
+	if err := m.storeLastUsed(ctx, clientName, clientUID, hash, now); err != nil {
+		plog.WarningErr("failed to record the last use of a client secret", err, "clientID", clientName)
+	}
+}
+
+func (m *ClientManager) storeLastUsed(ctx context.Context, clientName string, clientUID types.UID, hash string, now time.Time) error {
+	rv, hashes, metadata, err := m.storage.Get(ctx, clientUID)
+	if err != nil {
+		return err
+	}
+	if !contains(hashes, hash) {
+		return nil // the client secret was revoked in the meantime
+	}
+
+	secretMetadata := metadata[hash]
+	if lastUsed := secretMetadata.LastUsedTimestamp; lastUsed != nil && now.Sub(lastUsed.Time) < lastUsedUpdateInterval {
+		return nil // another Supervisor pod recorded a use recently enough
+	}
+	secretMetadata.LastUsedTimestamp = &v1.Time{Time: now}
+	if metadata == nil {
+		metadata = make(map[string]oidcclientsecretstorage.SecretMetadata, 1)
+	}
+	metadata[hash] = secretMetadata
+
+	return m.storage.Set(ctx, rv, clientName, clientUID, hashes, metadata)
+}
+
+func contains(hashes []string, hash string) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clientregistry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/oidcclientsecretstorage"
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestSecretsHasher(t *testing.T) {
+	ctx := context.Background()
+
+	const (
+		testName      = "client.oauth.pinniped.dev-test-name"
+		testNamespace = "test-namespace"
+		testUID       = "test-uid-123"
+	)
+
+	kubeClient := fake.NewSimpleClientset()
+	secrets := kubeClient.CoreV1().Secrets(testNamespace)
+	storageSecret := testutil.OIDCClientSecretStorageSecretForUID(t, testNamespace, testUID,
+		[]string{testutil.HashedPassword1AtGoMinCost, testutil.HashedPassword2AtGoMinCost})
+	storageSecret.ResourceVersion = "1" // updates of the storage Secret require a resource version
+	require.NoError(t, kubeClient.Tracker().Add(storageSecret))
+
+	supervisorClient := supervisorfake.NewSimpleClientset()
+	require.NoError(t, supervisorClient.Tracker().Add(&configv1alpha1.OIDCClient{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Generation: 1234, UID: testUID},
+		Spec: configv1alpha1.OIDCClientSpec{
+			AllowedGrantTypes:   []configv1alpha1.GrantType{"authorization_code"},
+			AllowedScopes:       []configv1alpha1.Scope{"openid"},
+			AllowedRedirectURIs: []configv1alpha1.RedirectURI{"http://localhost:80"},
+		},
+	}))
+
+	storage := oidcclientsecretstorage.New(secrets)
+	subject := NewClientManager(supervisorClient.ConfigV1alpha1().OIDCClients(testNamespace), storage, bcrypt.MinCost)
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	subject.nowFunc = func() time.Time { return now }
+	hasher := subject.SecretsHasher(&fosite.BCrypt{Config: &fosite.Config{}})
+
+	countUpdates := func() int {
+		updates := 0
+		for _, action := range kubeClient.Actions() {
+			if action.Matches("update", "secrets") {
+				updates++
+			}
+		}
+		return updates
+	}
+	requireLastUsed := func(want map[string]*metav1.Time) {
+		t.Helper()
+		_, _, metadata, err := storage.Get(ctx, testUID)
+		require.NoError(t, err)
+		got := map[string]*metav1.Time{}
+		for hash, m := range metadata {
+			got[hash] = m.LastUsedTimestamp
+		}
+		require.Equal(t, want, got)
+	}
+	lastUsed := func(t time.Time) *metav1.Time {
+		return &metav1.Time{Time: t.Local()} // metav1.Time unmarshals to local time
+	}
+
+	// Before the client was looked up, its hashes are not known, so nothing is recorded.
+	require.NoError(t, hasher.Compare(ctx, []byte(testutil.HashedPassword1AtGoMinCost), []byte(testutil.PlaintextPassword1)))
+	require.Zero(t, countUpdates())
+
+	_, err := subject.GetClient(ctx, testName)
+	require.NoError(t, err)
+
+	// A wrong client secret is not a use.
+	require.Error(t, hasher.Compare(ctx, []byte(testutil.HashedPassword1AtGoMinCost), []byte(testutil.PlaintextPassword2)))
+	require.Zero(t, countUpdates())
+
+	// The first use is recorded.
+	require.NoError(t, hasher.Compare(ctx, []byte(testutil.HashedPassword1AtGoMinCost), []byte(testutil.PlaintextPassword1)))
+	require.Equal(t, 1, countUpdates())
+	requireLastUsed(map[string]*metav1.Time{testutil.HashedPassword1AtGoMinCost: lastUsed(now)})
+
+	// Another use within the hour is not recorded.
+	now = now.Add(59 * time.Minute)
+	require.NoError(t, hasher.Compare(ctx, []byte(testutil.HashedPassword1AtGoMinCost), []byte(testutil.PlaintextPassword1)))
+	require.Equal(t, 1, countUpdates())
+
+	// Uses of each client secret are tracked separately.
+	require.NoError(t, hasher.Compare(ctx, []byte(testutil.HashedPassword2AtGoMinCost), []byte(testutil.PlaintextPassword2)))
+	require.Equal(t, 2, countUpdates())
+	requireLastUsed(map[string]*metav1.Time{
+		testutil.HashedPassword1AtGoMinCost: lastUsed(now.Add(-59 * time.Minute)),
+		testutil.HashedPassword2AtGoMinCost: lastUsed(now),
+	})
+
+	// A use more than an hour after the last recorded use is recorded.
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, hasher.Compare(ctx, []byte(testutil.HashedPassword1AtGoMinCost), []byte(testutil.PlaintextPassword1)))
+	require.Equal(t, 3, countUpdates())
+	requireLastUsed(map[string]*metav1.Time{
+		testutil.HashedPassword1AtGoMinCost: lastUsed(now),
+		testutil.HashedPassword2AtGoMinCost: lastUsed(now.Add(-2 * time.Minute)),
+	})
+
+	// Failing to record a use does not fail the comparison.
+	kubeClient.PrependReactor("get", "secrets", func(action coretesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("some get error")
+	})
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, hasher.Compare(ctx, []byte(testutil.HashedPassword2AtGoMinCost), []byte(testutil.PlaintextPassword2)))
+	require.Equal(t, 3, countUpdates())
+}
@@ -42,6 +42,9 @@ type Metadata struct {
 	// https://datatracker.ietf.org/doc/html/rfc8414#section-2 says, “If omitted, the authorization server does not support PKCE.”
 	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
 
+	// https://datatracker.ietf.org/doc/html/rfc8414#section-2 defines this for the endpoint of RFC 7009.
+	RevocationEndpoint string `json:"revocation_endpoint"`
+
 	// ^^^ Optional ^^^
 
 	// vvv Custom vvv
@@ -58,6 +61,7 @@ func NewHandler(issuerURL string) http.Handler {
 		AuthorizationEndpoint: issuerURL + oidc.AuthorizationEndpointPath,
 		TokenEndpoint:         issuerURL + oidc.TokenEndpointPath,
 		JWKSURI:               issuerURL + oidc.JWKSEndpointPath,
+		RevocationEndpoint:    issuerURL + oidc.RevocationEndpointPath,
 		OIDCDiscoveryResponse: v1alpha1.OIDCDiscoveryResponse{
 			SupervisorDiscovery: v1alpha1.OIDCDiscoveryResponseIDPEndpoint{
 				PinnipedIDPsEndpoint: issuerURL + oidc.PinnipedIDPsPathV1Alpha1,
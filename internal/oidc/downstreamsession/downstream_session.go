@@ -5,9 +5,11 @@
 package downstreamsession
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/ory/fosite"
@@ -17,8 +19,10 @@ import (
 	"k8s.io/utils/strings/slices"
 
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
 	"go.pinniped.dev/internal/authenticators"
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/crud"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
@@ -38,6 +42,22 @@ const (
 	requiredClaimEmptyErr              = constable.Error("required claim in upstream ID token is empty")
 	emailVerifiedClaimInvalidFormatErr = constable.Error("email_verified claim in upstream ID token has invalid format")
 	emailVerifiedClaimFalseErr         = constable.Error("email_verified claim in upstream ID token has false value")
+
+	// ErrUsernamePasswordNotAccepted and ErrLockedOut describe failed password logins in audit events.
+	ErrUsernamePasswordNotAccepted = constable.Error("username/password not accepted by upstream provider")
+	ErrLockedOut                   = constable.Error("too many failed login attempts for this username")
+
+	// ErrSecondFactorNotVerified and ErrSecondFactorRequired describe failed WebAuthn or TOTP second factors in audit
+	// events.
+	ErrSecondFactorNotVerified = constable.Error("second factor not verified")
+	ErrSecondFactorRequired    = constable.Error("second factor required, which is only supported by browser-based logins")
+
+	// MaxGroupsClaimSizeBytes is the maximum size of the JSON encoded groups of a user, which are kept in their session
+	// and included in the groups claim of their ID tokens. Each session Secret holds the groups once, so half of the
+	// size limit of a Secret leaves room for the rest of the session, even when it does not compress well. The groups
+	// of users who are members of even more groups are truncated instead of failing the login. This does not keep ID
+	// tokens small enough to be sent in request headers, which is left to the group filtering of each OIDCClient.
+	MaxGroupsClaimSizeBytes = crud.MaxSecretSizeBytes / 2
 )
 
 // MakeDownstreamSession creates a downstream OIDC session.
@@ -71,7 +91,7 @@ func MakeDownstreamSession(
 		extras[oidcapi.IDTokenClaimUsername] = username
 	}
 	if slices.Contains(grantedScopes, oidcapi.ScopeGroups) {
-		extras[oidcapi.IDTokenClaimGroups] = groups
+		SetGroupsClaim(extras, groups, username)
 	}
 	if len(additionalClaims) > 0 {
 		extras[oidcapi.IDTokenClaimAdditionalClaims] = additionalClaims
@@ -81,6 +101,59 @@ func MakeDownstreamSession(
 	return openIDSession
 }
 
+// SetGroupsClaim sets the groups claim of the given ID token claims to the given groups, truncated by LimitGroups, and
+// returns the groups which were kept. When some groups were left out, it logs a warning and sets the groupsOverflow
+// claim to true, so that the clients know that the groups claim is incomplete. Otherwise, the groupsOverflow claim is
+// removed, e.g. when the user left some groups since their groups were truncated.
+func SetGroupsClaim(extra map[string]interface{}, groups []string, username string) []string {
+	limitedGroups, overflow := LimitGroups(groups)
+	extra[oidcapi.IDTokenClaimGroups] = limitedGroups
+	if !overflow {
+		delete(extra, oidcapi.IDTokenClaimGroupsOverflow)
+		return limitedGroups
+	}
+	plog.Warning("truncated the groups of a user whose group names exceed the maximum size",
+		"username", username,
+		"groups", len(groups),
+		"keptGroups", len(limitedGroups),
+		"droppedGroups", len(groups)-len(limitedGroups),
+		"maxGroupsClaimSizeBytes", MaxGroupsClaimSizeBytes,
+	)
+	extra[oidcapi.IDTokenClaimGroupsOverflow] = true
+	return limitedGroups
+}
+
+// LimitGroups returns the given groups when their JSON encoding does not exceed MaxGroupsClaimSizeBytes. Otherwise, it
+// returns as many of the group names as fit, in alphabetical order, along with true.
+func LimitGroups(groups []string) ([]string, bool) {
+	size := len("[]")
+	for _, group := range groups {
+		size += groupClaimSize(group)
+	}
+	if size <= MaxGroupsClaimSizeBytes {
+		return groups, false
+	}
+
+	sorted := append([]string(nil), groups...)
+	sort.Strings(sorted)
+	size = len("[]")
+	for i, group := range sorted {
+		size += groupClaimSize(group)
+		if size > MaxGroupsClaimSizeBytes {
+			return sorted[:i], true
+		}
+	}
+	return sorted, false // not reached, since the size of all groups exceeds the maximum
+}
+
+// groupClaimSize returns the number of bytes which the group name adds to the JSON encoded groups claim, i.e. the
+// quoted and escaped name followed by a comma. The comma is counted for every name, which overestimates the size of
+// the claim by one byte.
+func groupClaimSize(group string) int {
+	encoded, _ := json.Marshal(group) // cannot fail for a string
+	return len(encoded) + len(",")
+}
+
 func MakeDownstreamLDAPOrADCustomSessionData(
 	ldapUpstream provider.UpstreamLDAPIdentityProviderI,
 	idpType psession.ProviderType,
@@ -198,6 +271,31 @@ func AutoApproveScopes(authorizeRequester fosite.AuthorizeRequester) {
 	}
 }
 
+// AuditEvent returns an audit event about a login to the given upstream identity provider during the given downstream
+// authorize request. The session ID is only included when the upstream authentication has succeeded, because the ID
+// of the authorize request only becomes the ID of a session at that point. err may be nil.
+func AuditEvent(
+	eventType auditlog.EventType,
+	authorizeRequester fosite.AuthorizeRequester,
+	upstreamName string,
+	username string,
+	err error,
+) auditlog.Event {
+	event := auditlog.Event{
+		Type:             eventType,
+		ClientID:         authorizeRequester.GetClient().GetID(),
+		IdentityProvider: upstreamName,
+		Username:         username,
+	}
+	if eventType == auditlog.EventUpstreamAuthenticationSucceeded {
+		event.SessionID = authorizeRequester.GetID()
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	return event
+}
+
 // GetDownstreamIdentityFromUpstreamIDToken returns the mapped subject, username, and group names, in that order.
 func GetDownstreamIdentityFromUpstreamIDToken(
 	upstreamIDPConfig provider.UpstreamOIDCIdentityProviderI,
@@ -4,10 +4,17 @@
 package downstreamsession
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/crud"
 	"go.pinniped.dev/internal/testutil/oidctestutil"
 )
 
@@ -70,3 +77,96 @@ func TestMapAdditionalClaimsFromUpstreamIDToken(t *testing.T) {
 		})
 	}
 }
+
+func TestLimitGroups(t *testing.T) {
+	// Many groups which add 64 bytes each to the claim, and one which adds 62 bytes, exactly fit into the maximum size
+	// along with the brackets of the JSON array.
+	n := (MaxGroupsClaimSizeBytes - len("[]") - 62) / 64
+	require.Zero(t, (MaxGroupsClaimSizeBytes-len("[]")-62)%64, "the test data should exactly fit into the maximum size")
+	var groups []string
+	for i := n; i > 0; i-- {
+		groups = append(groups, fmt.Sprintf("group-%05d-", i)+strings.Repeat("x", 61-len("group-00000-")))
+	}
+	groups = append(groups, "group-00000-"+strings.Repeat("x", 59-len("group-00000-")))
+	encoded, err := json.Marshal(groups)
+	require.NoError(t, err)
+	require.Len(t, encoded, MaxGroupsClaimSizeBytes-1) // one less, since there is no comma after the last group
+
+	limitedGroups, overflow := LimitGroups(groups)
+	require.False(t, overflow)
+	require.Equal(t, groups, limitedGroups, "groups which fit should be returned as they are, in their original order")
+
+	limitedGroups, overflow = LimitGroups(append(groups, "a"))
+	require.True(t, overflow)
+	require.Len(t, limitedGroups, n+1, "only the alphabetically last group should have been dropped")
+	require.Equal(t, "a", limitedGroups[0], "groups should be kept in alphabetical order")
+	require.Equal(t, groups[n], limitedGroups[1])
+	require.Equal(t, groups[1], limitedGroups[n])
+	encoded, err = json.Marshal(limitedGroups)
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(encoded), MaxGroupsClaimSizeBytes)
+
+	// Characters which are escaped in JSON count with the size of their escape sequence.
+	limitedGroups, overflow = LimitGroups([]string{strings.Repeat("<", MaxGroupsClaimSizeBytes/6)})
+	require.True(t, overflow)
+	require.Empty(t, limitedGroups)
+
+	limitedGroups, overflow = LimitGroups(nil)
+	require.False(t, overflow)
+	require.Nil(t, limitedGroups)
+}
+
+func TestSessionWithGroupsAtTheLimitFitsIntoASecret(t *testing.T) {
+	// Random group names, which do not compress well, until they exceed the maximum size.
+	var groups []string
+	size := len("[]")
+	for size <= MaxGroupsClaimSizeBytes {
+		b := make([]byte, 32)
+		_, err := rand.Read(b)
+		require.NoError(t, err)
+		groups = append(groups, "CN="+hex.EncodeToString(b)+",OU=Groups,DC=example,DC=com")
+		size += len(groups[len(groups)-1]) + len(`"",`)
+	}
+
+	session := MakeDownstreamSession("some-subject", strings.Repeat("some-username", 10), groups,
+		[]string{oidcapi.ScopeOpenID, oidcapi.ScopeUsername, oidcapi.ScopeGroups}, "some-client", nil, nil)
+	require.Equal(t, true, session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroupsOverflow])
+	keptGroups := session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups].([]string)
+	require.Greater(t, len(keptGroups), len(groups)-2, "only as many groups as needed should have been dropped")
+
+	encoded, err := json.Marshal(session)
+	require.NoError(t, err)
+	require.Greater(t, len(encoded), MaxGroupsClaimSizeBytes)
+	require.Less(t, len(encoded), crud.MaxSecretSizeBytes, "the session should fit into a Secret without compression")
+}
+
+func TestSetGroupsClaim(t *testing.T) {
+	tooManyGroups := []string{strings.Repeat("b", MaxGroupsClaimSizeBytes), "a"}
+
+	extra := map[string]interface{}{}
+	require.Equal(t, []string{"a"}, SetGroupsClaim(extra, tooManyGroups, "some-username"))
+	require.Equal(t, map[string]interface{}{
+		oidcapi.IDTokenClaimGroups:         []string{"a"},
+		oidcapi.IDTokenClaimGroupsOverflow: true,
+	}, extra)
+
+	// the overflow claim is removed once the groups fit again, e.g. after a refresh
+	require.Equal(t, []string{"b", "a"}, SetGroupsClaim(extra, []string{"b", "a"}, "some-username"))
+	require.Equal(t, map[string]interface{}{
+		oidcapi.IDTokenClaimGroups: []string{"b", "a"},
+	}, extra)
+}
+
+func TestMakeDownstreamSessionTruncatesGroups(t *testing.T) {
+	tooManyGroups := []string{strings.Repeat("b", MaxGroupsClaimSizeBytes), "a"}
+
+	session := MakeDownstreamSession("some-subject", "some-username", tooManyGroups,
+		[]string{oidcapi.ScopeOpenID, oidcapi.ScopeGroups}, "some-client", nil, nil)
+	require.Equal(t, []string{"a"}, session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups])
+	require.Equal(t, true, session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroupsOverflow])
+
+	session = MakeDownstreamSession("some-subject", "some-username", []string{"a"},
+		[]string{oidcapi.ScopeOpenID, oidcapi.ScopeGroups}, "some-client", nil, nil)
+	require.Equal(t, []string{"a"}, session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups])
+	require.NotContains(t, session.Fosite.Claims.Extra, oidcapi.IDTokenClaimGroupsOverflow)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidc
@@ -6,18 +6,33 @@ package oidc
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
 	"reflect"
 	"time"
 
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/compose"
 	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/token/jwt"
+	"gopkg.in/square/go-jose.v2"
+	josejwt "gopkg.in/square/go-jose.v2/jwt"
 
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/oidc/clientregistry"
 	"go.pinniped.dev/internal/oidc/jwks"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
 )
 
+// maxGCPSubjectLength is the maximum length of the sub claim which GCP workload identity federation allows, since
+// it is mapped to the google.subject attribute.
+const maxGCPSubjectLength = 127
+
+// idTokenClaimAudience is the name of the audience claim defined by the OIDC spec.
+const idTokenClaimAudience = "aud"
+
 // dynamicOpenIDConnectECDSAStrategy is an openid.OpenIDConnectTokenStrategy that can dynamically
 // load a signing key to issue ID tokens. We want this dynamic capability since our controllers for
 // loading FederationDomain's and signing keys run in parallel, and thus the signing key might not be
@@ -26,9 +41,16 @@ import (
 // If we ever update FederationDomain's to hold their signing key, we might not need this type, since we
 // could have an invariant that routes to an FederationDomain's endpoints are only wired up if an
 // FederationDomain has a valid signing key.
+//
+// It also filters the groups claim of the ID tokens which are issued to OIDCClients that filter their groups claim.
+// The clients are looked up again using the clients manager, because the client of a session which was loaded from
+// storage does not remember the configuration of its OIDCClient. Likewise, it shapes the ID tokens which are issued
+// for OIDCClients that have a token profile, including the tokens of their RFC8693 token exchanges and of their
+// client_credentials grant, which are found by the azp claim of the session.
 type dynamicOpenIDConnectECDSAStrategy struct {
 	fositeConfig *fosite.Config
 	jwksProvider jwks.DynamicJWKSProvider
+	clients      fosite.ClientManager
 }
 
 var _ openid.OpenIDConnectTokenStrategy = &dynamicOpenIDConnectECDSAStrategy{}
@@ -36,10 +58,12 @@ var _ openid.OpenIDConnectTokenStrategy = &dynamicOpenIDConnectECDSAStrategy{}
 func newDynamicOpenIDConnectECDSAStrategy(
 	fositeConfig *fosite.Config,
 	jwksProvider jwks.DynamicJWKSProvider,
+	clients fosite.ClientManager,
 ) *dynamicOpenIDConnectECDSAStrategy {
 	return &dynamicOpenIDConnectECDSAStrategy{
 		fositeConfig: fositeConfig,
 		jwksProvider: jwksProvider,
+		clients:      clients,
 	}
 }
 
@@ -53,8 +77,14 @@ func (s *dynamicOpenIDConnectECDSAStrategy) GenerateIDToken(
 		plog.Debug("no JWK found for issuer", "issuer", s.fositeConfig.IDTokenIssuer)
 		return "", fosite.ErrTemporarilyUnavailable.WithWrap(constable.Error("no JWK found for issuer"))
 	}
-	key, ok := activeJwk.Key.(*ecdsa.PrivateKey)
-	if !ok {
+	var key interface{}
+	switch k := activeJwk.Key.(type) {
+	case *ecdsa.PrivateKey:
+		key = k
+	case jose.OpaqueSigner:
+		// the key is held by an external signer, so let fosite sign with the JWK, which tells it the algorithm
+		key = activeJwk
+	default:
 		actualType := "nil"
 		if t := reflect.TypeOf(activeJwk.Key); t != nil {
 			actualType = t.String()
@@ -74,5 +104,202 @@ func (s *dynamicOpenIDConnectECDSAStrategy) GenerateIDToken(
 	}
 	strategy := compose.NewOpenIDConnectStrategy(keyGetter, s.fositeConfig)
 
+	requester, err := s.filterGroupsClaim(ctx, requester)
+	if err != nil {
+		return "", err
+	}
+
+	tokenProfile, err := s.tokenProfile(ctx, requester)
+	if err != nil {
+		return "", err
+	}
+	if tokenProfile != nil {
+		strategy.Signer = &tokenProfileSigner{Signer: strategy.Signer, tokenProfile: tokenProfile}
+	}
+
 	return strategy.GenerateIDToken(ctx, lifespan, requester)
 }
+
+// filterGroupsClaim returns the given requester, or a copy of it whose session has a filtered groups claim when the
+// client of the requester is an OIDCClient which filters its groups claim. The stored session is not changed, so
+// that it keeps all the groups of the user. The ID tokens of RFC8693 token exchanges are not filtered, since the
+// client of their requester is not a clientregistry.Client.
+func (s *dynamicOpenIDConnectECDSAStrategy) filterGroupsClaim(ctx context.Context, requester fosite.Requester) (fosite.Requester, error) {
+	client, ok := requester.GetClient().(*clientregistry.Client)
+	if !ok || s.clients == nil || client.GetID() == oidcapi.ClientIDPinnipedCLI {
+		return requester, nil
+	}
+	session, ok := requester.GetSession().(*psession.PinnipedSession)
+	if !ok || session.Fosite == nil || session.Fosite.Claims == nil || session.Fosite.Claims.Extra == nil {
+		return requester, nil
+	}
+	groups, ok := groupsFromClaim(session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups])
+	if !ok {
+		// The groups scope was not granted, so there is no groups claim to filter.
+		return requester, nil
+	}
+
+	currentClient, err := s.clients.GetClient(ctx, client.GetID())
+	if err != nil {
+		plog.Debug("failed to look up client to filter the groups claim", "clientID", client.GetID(), "err", err)
+		return nil, fosite.ErrServerError.WithWrap(err).WithDebug("failed to look up client to filter the groups claim")
+	}
+	if !clientregistry.FiltersGroupsClaim(currentClient) {
+		return requester, nil
+	}
+
+	filteredGroups, overflow := clientregistry.FilterGroupsClaim(currentClient, groups)
+	filteredSession := session.Clone().(*psession.PinnipedSession)
+	filteredSession.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups] = filteredGroups
+	if overflow {
+		filteredSession.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroupsOverflow] = true
+	}
+	return &requesterWithSession{Requester: requester, session: filteredSession}, nil
+}
+
+// verifyJWT verifies that a JWT was signed by one of the signing keys of the FederationDomain, that it was issued
+// by the FederationDomain for the given audience, and that it has not expired yet, and returns its claims. It is used
+// to verify the actor tokens of RFC8693 token exchanges, which are tokens which the FederationDomain issued to the
+// actor, so the audience is the ID of the client which is making the token exchange.
+func (s *dynamicOpenIDConnectECDSAStrategy) verifyJWT(token string, audience string) (map[string]interface{}, error) {
+	parsed, err := josejwt.ParseSigned(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []interface{}
+	publicJWKS, activeJwk := s.jwksProvider.GetJWKS(s.fositeConfig.IDTokenIssuer)
+	if publicJWKS != nil {
+		for i := range publicJWKS.Keys {
+			keys = append(keys, &publicJWKS.Keys[i])
+		}
+	}
+	if activeJwk != nil {
+		if signer, ok := activeJwk.Key.(jose.OpaqueSigner); ok {
+			keys = append(keys, signer.Public())
+		} else {
+			public := activeJwk.Public()
+			keys = append(keys, &public)
+		}
+	}
+
+	var rawClaims json.RawMessage
+	verified := false
+	for _, key := range keys {
+		if err := parsed.Claims(key, &rawClaims); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, constable.Error("token was not signed by a signing key of the issuer")
+	}
+
+	var standardClaims josejwt.Claims
+	if err := json.Unmarshal(rawClaims, &standardClaims); err != nil {
+		return nil, err
+	}
+	if standardClaims.Expiry == nil {
+		return nil, constable.Error("token does not expire")
+	}
+	if err := standardClaims.ValidateWithLeeway(josejwt.Expected{Issuer: s.fositeConfig.IDTokenIssuer, Audience: josejwt.Audience{audience}, Time: time.Now()}, 0); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// tokenProfile returns the token profile of the OIDCClient for which an ID token is issued, or nil when the ID token
+// should not be shaped. The client is found by the azp claim of the session, since the client of the requester is
+// the audience of the token for RFC8693 token exchanges and for the client_credentials grant.
+func (s *dynamicOpenIDConnectECDSAStrategy) tokenProfile(ctx context.Context, requester fosite.Requester) (*configv1alpha1.OIDCClientTokenProfile, error) {
+	if s.clients == nil {
+		return nil, nil
+	}
+	session, ok := requester.GetSession().(*psession.PinnipedSession)
+	if !ok || session.Fosite == nil || session.Fosite.Claims == nil {
+		return nil, nil
+	}
+	clientID, _ := session.Fosite.Claims.Extra[oidcapi.IDTokenClaimAuthorizedParty].(string)
+	if clientID == "" || clientID == oidcapi.ClientIDPinnipedCLI {
+		return nil, nil
+	}
+
+	client, err := s.clients.GetClient(ctx, clientID)
+	if err != nil {
+		plog.Debug("failed to look up client to shape the ID token", "clientID", clientID, "err", err)
+		return nil, fosite.ErrServerError.WithWrap(err).WithDebug("failed to look up client to shape the ID token")
+	}
+	return clientregistry.TokenProfile(client), nil
+}
+
+// tokenProfileSigner is a jwt.Signer which shapes the claims of the tokens which it signs according to the token
+// profile of an OIDCClient.
+type tokenProfileSigner struct {
+	jwt.Signer
+	tokenProfile *configv1alpha1.OIDCClientTokenProfile
+}
+
+func (s *tokenProfileSigner) Generate(ctx context.Context, claims jwt.MapClaims, header jwt.Mapper) (string, string, error) {
+	// All the cloud providers expect the aud claim to be a single string instead of a list of strings.
+	switch aud := claims[idTokenClaimAudience].(type) {
+	case []string:
+		if len(aud) == 1 {
+			claims[idTokenClaimAudience] = aud[0]
+		}
+	case []interface{}:
+		if len(aud) == 1 {
+			claims[idTokenClaimAudience] = aud[0]
+		}
+	}
+
+	if s.tokenProfile.SubjectFormat != configv1alpha1.OIDCClientSubjectFormatDefault {
+		if username, ok := claims[oidcapi.IDTokenClaimUsername].(string); ok && username != "" {
+			claims[oidcapi.IDTokenClaimSubject] = username
+		}
+	}
+
+	if s.tokenProfile.CloudProvider == configv1alpha1.OIDCClientCloudProviderGCP {
+		if subject, _ := claims[oidcapi.IDTokenClaimSubject].(string); len(subject) > maxGCPSubjectLength {
+			return "", "", fosite.ErrAccessDenied.WithHintf(
+				"The sub claim of the token is longer than the %d bytes which are allowed by GCP.", maxGCPSubjectLength)
+		}
+	}
+
+	return s.Signer.Generate(ctx, claims, header)
+}
+
+// groupsFromClaim returns the group names of a groups claim, which is a []string for a new session but a
+// []interface{} for a session which was loaded from storage.
+func groupsFromClaim(claim interface{}) ([]string, bool) {
+	switch c := claim.(type) {
+	case []string:
+		return c, true
+	case []interface{}:
+		groups := make([]string, 0, len(c))
+		for _, g := range c {
+			group, ok := g.(string)
+			if !ok {
+				return nil, false
+			}
+			groups = append(groups, group)
+		}
+		return groups, true
+	default:
+		return nil, false
+	}
+}
+
+// requesterWithSession is a fosite.Requester which has a different session than the requester which it wraps.
+type requesterWithSession struct {
+	fosite.Requester
+	session fosite.Session
+}
+
+func (r *requesterWithSession) GetSession() fosite.Session {
+	return r.session
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidc
@@ -11,6 +11,7 @@ import (
 	"crypto/rsa"
 	"errors"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,7 +20,10 @@ import (
 	"github.com/ory/fosite/token/jwt"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/cryptosigner"
+	josejwt "gopkg.in/square/go-jose.v2/jwt"
 
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	"go.pinniped.dev/internal/oidc/jwks"
 	"go.pinniped.dev/internal/testutil/oidctestutil"
 )
@@ -64,6 +68,24 @@ func TestDynamicOpenIDConnectECDSAStrategy(t *testing.T) {
 				Key: ecPrivateKey,
 			},
 		},
+		{
+			name:   "jwks provider contains signing key of an external signer for issuer",
+			issuer: goodIssuer,
+			jwksProvider: func(provider jwks.DynamicJWKSProvider) {
+				provider.SetIssuerToJWKSMap(
+					nil,
+					map[string]*jose.JSONWebKey{
+						goodIssuer: {
+							Key:       cryptosigner.Opaque(ecPrivateKey),
+							Algorithm: string(jose.ES256),
+						},
+					},
+				)
+			},
+			wantSigningJWK: &jose.JSONWebKey{
+				Key: ecPrivateKey,
+			},
+		},
 		{
 			name:           "jwks provider does not contain signing key for issuer",
 			issuer:         goodIssuer,
@@ -97,6 +119,7 @@ func TestDynamicOpenIDConnectECDSAStrategy(t *testing.T) {
 			s := newDynamicOpenIDConnectECDSAStrategy(
 				&fosite.Config{IDTokenIssuer: test.issuer},
 				jwksProvider,
+				nil,
 			)
 
 			requester := &fosite.Request{
@@ -134,3 +157,156 @@ func TestDynamicOpenIDConnectECDSAStrategy(t *testing.T) {
 		})
 	}
 }
+
+func TestDynamicOpenIDConnectECDSAStrategyVerifyJWT(t *testing.T) {
+	const (
+		goodIssuer   = "https://some-good-issuer.com"
+		goodAudience = "some-client-id"
+	)
+
+	activeKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	previousKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwksProvider := jwks.NewDynamicJWKSProvider()
+	jwksProvider.SetIssuerToJWKSMap(
+		map[string]*jose.JSONWebKeySet{goodIssuer: {Keys: []jose.JSONWebKey{
+			{Key: &activeKey.PublicKey, KeyID: "active", Algorithm: string(jose.ES256)},
+			{Key: &previousKey.PublicKey, KeyID: "previous", Algorithm: string(jose.ES256)},
+		}}},
+		map[string]*jose.JSONWebKey{goodIssuer: {Key: activeKey, KeyID: "active", Algorithm: string(jose.ES256)}},
+	)
+	s := newDynamicOpenIDConnectECDSAStrategy(&fosite.Config{IDTokenIssuer: goodIssuer}, jwksProvider, nil)
+
+	sign := func(key *ecdsa.PrivateKey, claims map[string]interface{}) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, nil)
+		require.NoError(t, err)
+		token, err := josejwt.Signed(signer).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+	notExpired := time.Now().Add(time.Minute).Unix()
+
+	claims, err := s.verifyJWT(sign(activeKey, map[string]interface{}{"iss": goodIssuer, "aud": goodAudience, "sub": "some-subject", "exp": notExpired}), goodAudience)
+	require.NoError(t, err)
+	require.Equal(t, "some-subject", claims["sub"])
+
+	// tokens which were signed by a previous key are still valid until they expire
+	claims, err = s.verifyJWT(sign(previousKey, map[string]interface{}{"iss": goodIssuer, "aud": goodAudience, "sub": "some-subject", "exp": notExpired}), goodAudience)
+	require.NoError(t, err)
+	require.Equal(t, "some-subject", claims["sub"])
+
+	// tokens which have other audiences besides the expected one are valid too
+	claims, err = s.verifyJWT(sign(activeKey, map[string]interface{}{"iss": goodIssuer, "aud": []string{"some-other-audience", goodAudience}, "sub": "some-subject", "exp": notExpired}), goodAudience)
+	require.NoError(t, err)
+	require.Equal(t, "some-subject", claims["sub"])
+
+	_, err = s.verifyJWT(sign(otherKey, map[string]interface{}{"iss": goodIssuer, "aud": goodAudience, "exp": notExpired}), goodAudience)
+	require.EqualError(t, err, "token was not signed by a signing key of the issuer")
+	_, err = s.verifyJWT(sign(activeKey, map[string]interface{}{"iss": "https://some-other-issuer.com", "aud": goodAudience, "exp": notExpired}), goodAudience)
+	require.EqualError(t, err, "square/go-jose/jwt: validation failed, invalid issuer claim (iss)")
+	// tokens for another audience, e.g. the tokens which token exchanges mint for workload clusters, are not valid
+	_, err = s.verifyJWT(sign(activeKey, map[string]interface{}{"iss": goodIssuer, "aud": "some-workload-cluster", "exp": notExpired}), goodAudience)
+	require.EqualError(t, err, "square/go-jose/jwt: validation failed, invalid audience claim (aud)")
+	_, err = s.verifyJWT(sign(activeKey, map[string]interface{}{"iss": goodIssuer, "exp": notExpired}), goodAudience)
+	require.EqualError(t, err, "square/go-jose/jwt: validation failed, invalid audience claim (aud)")
+	_, err = s.verifyJWT(sign(activeKey, map[string]interface{}{"iss": goodIssuer, "aud": goodAudience, "exp": time.Now().Add(-time.Minute).Unix()}), goodAudience)
+	require.EqualError(t, err, "square/go-jose/jwt: validation failed, token is expired (exp)")
+	_, err = s.verifyJWT(sign(activeKey, map[string]interface{}{"iss": goodIssuer, "aud": goodAudience}), goodAudience)
+	require.EqualError(t, err, "token does not expire")
+	_, err = s.verifyJWT("some-bogus-token", goodAudience)
+	require.Error(t, err)
+}
+
+func TestTokenProfileSigner(t *testing.T) {
+	longUsername := strings.Repeat("a", 128)
+
+	tests := []struct {
+		name          string
+		tokenProfile  *configv1alpha1.OIDCClientTokenProfile
+		claims        jwt.MapClaims
+		wantClaims    jwt.MapClaims
+		wantErrorHint string
+	}{
+		{
+			name:         "uses a single audience string and the username as the subject by default",
+			tokenProfile: &configv1alpha1.OIDCClientTokenProfile{CloudProvider: configv1alpha1.OIDCClientCloudProviderAWS},
+			claims:       jwt.MapClaims{"aud": []string{"sts.amazonaws.com"}, "sub": "some-subject", "username": "some-username"},
+			wantClaims:   jwt.MapClaims{"aud": "sts.amazonaws.com", "sub": "some-username", "username": "some-username"},
+		},
+		{
+			name: "uses the username as the subject with the Username subject format",
+			tokenProfile: &configv1alpha1.OIDCClientTokenProfile{
+				CloudProvider: configv1alpha1.OIDCClientCloudProviderAzure,
+				SubjectFormat: configv1alpha1.OIDCClientSubjectFormatUsername,
+			},
+			claims:     jwt.MapClaims{"aud": []interface{}{"api://AzureADTokenExchange"}, "sub": "some-subject", "username": "some-username"},
+			wantClaims: jwt.MapClaims{"aud": "api://AzureADTokenExchange", "sub": "some-username", "username": "some-username"},
+		},
+		{
+			name: "keeps the subject with the Default subject format",
+			tokenProfile: &configv1alpha1.OIDCClientTokenProfile{
+				CloudProvider: configv1alpha1.OIDCClientCloudProviderAWS,
+				SubjectFormat: configv1alpha1.OIDCClientSubjectFormatDefault,
+			},
+			claims:     jwt.MapClaims{"aud": []string{"sts.amazonaws.com"}, "sub": "some-subject", "username": "some-username"},
+			wantClaims: jwt.MapClaims{"aud": "sts.amazonaws.com", "sub": "some-subject", "username": "some-username"},
+		},
+		{
+			name:         "keeps the subject when there is no username claim",
+			tokenProfile: &configv1alpha1.OIDCClientTokenProfile{CloudProvider: configv1alpha1.OIDCClientCloudProviderGCP},
+			claims:       jwt.MapClaims{"aud": []string{"some-audience"}, "sub": "client.oauth.pinniped.dev-some-client"},
+			wantClaims:   jwt.MapClaims{"aud": "some-audience", "sub": "client.oauth.pinniped.dev-some-client"},
+		},
+		{
+			name:         "keeps a list of more than one audience",
+			tokenProfile: &configv1alpha1.OIDCClientTokenProfile{CloudProvider: configv1alpha1.OIDCClientCloudProviderAWS},
+			claims:       jwt.MapClaims{"aud": []string{"some-audience", "other-audience"}, "sub": "some-subject"},
+			wantClaims:   jwt.MapClaims{"aud": []string{"some-audience", "other-audience"}, "sub": "some-subject"},
+		},
+		{
+			name:          "subject which is too long for GCP",
+			tokenProfile:  &configv1alpha1.OIDCClientTokenProfile{CloudProvider: configv1alpha1.OIDCClientCloudProviderGCP},
+			claims:        jwt.MapClaims{"aud": []string{"some-audience"}, "sub": "some-subject", "username": longUsername},
+			wantErrorHint: "The sub claim of the token is longer than the 127 bytes which are allowed by GCP.",
+		},
+		{
+			name:         "subject which is long but allowed by AWS",
+			tokenProfile: &configv1alpha1.OIDCClientTokenProfile{CloudProvider: configv1alpha1.OIDCClientCloudProviderAWS},
+			claims:       jwt.MapClaims{"aud": []string{"sts.amazonaws.com"}, "sub": "some-subject", "username": longUsername},
+			wantClaims:   jwt.MapClaims{"aud": "sts.amazonaws.com", "sub": longUsername, "username": longUsername},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			inner := &recordingSigner{}
+			signer := &tokenProfileSigner{Signer: inner, tokenProfile: test.tokenProfile}
+
+			token, _, err := signer.Generate(context.Background(), test.claims, &jwt.Headers{})
+			if test.wantErrorHint != "" {
+				require.True(t, errors.Is(err, fosite.ErrAccessDenied))
+				require.Equal(t, test.wantErrorHint, err.(*fosite.RFC6749Error).HintField)
+				require.Nil(t, inner.claims)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "some-token", token)
+			require.Equal(t, test.wantClaims, inner.claims)
+		})
+	}
+}
+
+// recordingSigner is a jwt.Signer which records the claims of the token which it generates.
+type recordingSigner struct {
+	jwt.Signer
+	claims jwt.MapClaims
+}
+
+func (s *recordingSigner) Generate(_ context.Context, claims jwt.MapClaims, _ jwt.Mapper) (string, string, error) {
+	s.claims = claims
+	return "some-token", "some-signature", nil
+}
@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package dynamiccodec provides a type that can encode information using a just-in-time signing and
@@ -21,9 +21,10 @@ type KeyFunc func() []byte
 // Codec can dynamically encode and decode information by using a KeyFunc to get its keys
 // just-in-time.
 type Codec struct {
-	lifespan          time.Duration
-	signingKeyFunc    KeyFunc
-	encryptionKeyFunc KeyFunc
+	lifespan               time.Duration
+	signingKeyFunc         KeyFunc
+	previousSigningKeyFunc KeyFunc
+	encryptionKeyFunc      KeyFunc
 }
 
 // New creates a new Codec that will use the provided keyFuncs for its key source, and
@@ -40,18 +41,39 @@ func New(lifespan time.Duration, signingKeyFunc, encryptionKeyFunc KeyFunc) *Cod
 	}
 }
 
+// WithPreviousSigningKey makes the Codec also decode values which were signed by the key which is returned by the
+// provided KeyFunc, e.g. by the previous signing key after the signing key was rotated. Values are always encoded
+// with the current signing key. The KeyFunc may return nil when there is no previous signing key.
+func (c *Codec) WithPreviousSigningKey(previousSigningKeyFunc KeyFunc) *Codec {
+	c.previousSigningKeyFunc = previousSigningKeyFunc
+	return c
+}
+
 // Encode implements oidc.Encode().
 func (c *Codec) Encode(name string, value interface{}) (string, error) {
-	return c.delegate().Encode(name, value)
+	return c.delegate(c.signingKeyFunc()).Encode(name, value)
 }
 
 // Decode implements oidc.Decode().
 func (c *Codec) Decode(name string, value string, into interface{}) error {
-	return c.delegate().Decode(name, value, into)
+	err := c.delegate(c.signingKeyFunc()).Decode(name, value, into)
+	if err == nil || c.previousSigningKeyFunc == nil {
+		return err
+	}
+
+	previousSigningKey := c.previousSigningKeyFunc()
+	if len(previousSigningKey) == 0 {
+		return err
+	}
+
+	if previousErr := c.delegate(previousSigningKey).Decode(name, value, into); previousErr != nil {
+		return err // the error of the current signing key is the more interesting one
+	}
+	return nil
 }
 
-func (c *Codec) delegate() *securecookie.SecureCookie {
-	codec := securecookie.New(c.signingKeyFunc(), c.encryptionKeyFunc())
+func (c *Codec) delegate(signingKey []byte) *securecookie.SecureCookie {
+	codec := securecookie.New(signingKey, c.encryptionKeyFunc())
 	codec.MaxAge(int(c.lifespan.Seconds()))
 	codec.SetSerializer(securecookie.JSONEncoder{})
 	return codec
@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package dynamiccodec
@@ -125,3 +125,55 @@ func TestCodec(t *testing.T) {
 		})
 	}
 }
+
+func TestCodecWithPreviousSigningKey(t *testing.T) {
+	previousSigningKey := []byte("some-previous-signing-key")
+	encryptionKeyFunc := func() []byte { return []byte("16-byte-encr-key") }
+
+	encodedWithPreviousKey, err := New(time.Hour, func() []byte { return previousSigningKey }, encryptionKeyFunc).
+		Encode("some-name", "some-message")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                   string
+		previousSigningKeyFunc KeyFunc
+		wantDecoderError       string
+	}{
+		{
+			name:                   "previous signing key matches",
+			previousSigningKeyFunc: func() []byte { return previousSigningKey },
+		},
+		{
+			name:                   "previous signing key does not match",
+			previousSigningKeyFunc: func() []byte { return []byte("some-other-signing-key") },
+			wantDecoderError:       "securecookie: the value is not valid",
+		},
+		{
+			name:                   "no previous signing key",
+			previousSigningKeyFunc: func() []byte { return nil },
+			wantDecoderError:       "securecookie: the value is not valid",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			codec := New(time.Hour, func() []byte { return []byte("some-signing-key") }, encryptionKeyFunc).
+				WithPreviousSigningKey(test.previousSigningKeyFunc)
+
+			var decoded string
+			err := codec.Decode("some-name", encodedWithPreviousKey, &decoded)
+			if test.wantDecoderError != "" {
+				require.EqualError(t, err, test.wantDecoderError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "some-message", decoded)
+
+			// Values are always encoded with the current signing key.
+			encoded, err := codec.Encode("some-name", "some-other-message")
+			require.NoError(t, err)
+			err = New(time.Hour, func() []byte { return previousSigningKey }, encryptionKeyFunc).Decode("some-name", encoded, &decoded)
+			require.EqualError(t, err, "securecookie: the value is not valid")
+		})
+	}
+}
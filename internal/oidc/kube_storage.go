@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidc
@@ -14,6 +14,7 @@ import (
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	"go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
+	"go.pinniped.dev/internal/crud"
 	"go.pinniped.dev/internal/fositestorage/accesstoken"
 	"go.pinniped.dev/internal/fositestorage/authorizationcode"
 	"go.pinniped.dev/internal/fositestorage/openidconnect"
@@ -25,7 +26,7 @@ import (
 )
 
 type KubeStorage struct {
-	clientManager            fosite.ClientManager
+	clientManager            *clientregistry.ClientManager
 	authorizationCodeStorage oauth2.AuthorizeCodeStorage
 	pkceStorage              fositepkce.PKCERequestStorage
 	oidcStorage              openid.OpenIDConnectRequestStorage
@@ -40,15 +41,27 @@ func NewKubeStorage(
 	oidcClientsClient v1alpha1.OIDCClientInterface,
 	timeoutsConfiguration TimeoutsConfiguration,
 	minBcryptCost int,
+) *KubeStorage {
+	return NewKubeStorageWithSessionBackend(secrets, crud.NewSecretsBackend(secrets), oidcClientsClient, timeoutsConfiguration, minBcryptCost)
+}
+
+// NewKubeStorageWithSessionBackend is like NewKubeStorage, but keeps the sessions in the given storage backend.
+// The client secrets of OIDCClients are always stored in Secrets.
+func NewKubeStorageWithSessionBackend(
+	secrets corev1client.SecretInterface,
+	sessionStorageBackend crud.Backend,
+	oidcClientsClient v1alpha1.OIDCClientInterface,
+	timeoutsConfiguration TimeoutsConfiguration,
+	minBcryptCost int,
 ) *KubeStorage {
 	nowFunc := time.Now
 	return &KubeStorage{
 		clientManager:            clientregistry.NewClientManager(oidcClientsClient, oidcclientsecretstorage.New(secrets), minBcryptCost),
-		authorizationCodeStorage: authorizationcode.New(secrets, nowFunc, timeoutsConfiguration.AuthorizationCodeSessionStorageLifetime),
-		pkceStorage:              pkce.New(secrets, nowFunc, timeoutsConfiguration.PKCESessionStorageLifetime),
-		oidcStorage:              openidconnect.New(secrets, nowFunc, timeoutsConfiguration.OIDCSessionStorageLifetime),
-		accessTokenStorage:       accesstoken.New(secrets, nowFunc, timeoutsConfiguration.AccessTokenSessionStorageLifetime),
-		refreshTokenStorage:      refreshtoken.New(secrets, nowFunc, timeoutsConfiguration.RefreshTokenSessionStorageLifetime),
+		authorizationCodeStorage: authorizationcode.NewWithBackend(sessionStorageBackend, nowFunc, timeoutsConfiguration.AuthorizationCodeSessionStorageLifetime),
+		pkceStorage:              pkce.NewWithBackend(sessionStorageBackend, nowFunc, timeoutsConfiguration.PKCESessionStorageLifetime),
+		oidcStorage:              openidconnect.NewWithBackend(sessionStorageBackend, nowFunc, timeoutsConfiguration.OIDCSessionStorageLifetime),
+		accessTokenStorage:       accesstoken.NewWithBackend(sessionStorageBackend, nowFunc, timeoutsConfiguration.AccessTokenSessionStorageLifetime),
+		refreshTokenStorage:      refreshtoken.NewWithBackend(sessionStorageBackend, nowFunc, timeoutsConfiguration.RefreshTokenSessionStorageLifetime),
 	}
 }
 
@@ -164,11 +177,13 @@ func (k KubeStorage) RevokeAccessToken(ctx context.Context, requestID string) er
 // authcode redemption and also during refresh. Refresh tokens are only handed out when the user requested the
 // offline_access scope on the original authorization request.
 //
-// Fosite will not use the delete method. Instead, it will use the revoke method to delete them.
-// During a refresh in the token endpoint, the old refresh token is revoked just before the new refresh token is created.
-// Also, if the token endpoint receives an authcode that was already used successfully, then it revokes the refresh token
-// that was previously handed out for that authcode. If a user stops coming back to refresh their tokens, then that
-// refresh token will never be deleted.
+// During a refresh in the token endpoint, the old refresh token is marked as rotated by the maybe-grace-period revoke
+// method just before the new refresh token is created. The rotated session is not deleted, so that the token endpoint
+// can detect when the old refresh token is used again. When that happens, fosite deletes it and uses the revoke method
+// to delete all the refresh tokens of the session, including the rotated ones. Also, if the token endpoint receives an
+// authcode that was already used successfully, then it revokes the refresh tokens that were previously handed out for
+// that authcode. If a user stops coming back to refresh their tokens, then those refresh tokens will never be deleted
+// by fosite.
 //
 
 func (k KubeStorage) CreateRefreshTokenSession(ctx context.Context, signatureOfRefreshToken string, request fosite.Requester) (err error) {
@@ -195,6 +210,11 @@ func (k KubeStorage) RevokeRefreshTokenMaybeGracePeriod(ctx context.Context, req
 // OAuth client definitions:
 //
 
+// SecretsHasher returns a fosite.Hasher which records when the client secrets of OIDCClients are used.
+func (k KubeStorage) SecretsHasher(wrapped fosite.Hasher) fosite.Hasher {
+	return k.clientManager.SecretsHasher(wrapped)
+}
+
 func (k KubeStorage) GetClient(ctx context.Context, id string) (fosite.Client, error) {
 	return k.clientManager.GetClient(ctx, id)
 }
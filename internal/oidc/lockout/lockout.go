@@ -0,0 +1,172 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lockout tracks failed password logins for upstream LDAP and Active Directory identity providers,
+// and temporarily locks out usernames which have too many consecutive failed attempts.
+package lockout
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	DefaultMaxFailedAttempts = 10
+	DefaultLockoutDuration   = 10 * time.Minute
+)
+
+// Config controls the behavior of a Tracker.
+type Config struct {
+	// MaxFailedAttempts is the number of consecutive failed login attempts for a username that will cause
+	// that username to become locked out.
+	MaxFailedAttempts int
+
+	// LockoutDuration is how long a username stays locked out after reaching MaxFailedAttempts. It is also
+	// the length of time after the first of a series of failed attempts after which they are forgotten, unless
+	// they caused a lockout.
+	LockoutDuration time.Duration
+}
+
+// Tracker is a record of recent failed password logins, which is kept in a Store.
+//
+// A nil *Tracker is valid and represents a disabled lockout feature: it never locks out anyone.
+//
+// It is thread-safe.
+type Tracker struct {
+	config Config
+	clock  clock.PassiveClock
+	store  Store
+}
+
+// New returns a Tracker which enforces the given Config and keeps the failed attempts in the memory of this process.
+// Any zero values in the Config will be defaulted.
+func New(config Config, clock clock.PassiveClock) *Tracker {
+	config = withDefaults(config)
+	return NewWithStore(config, clock, newMemoryStore(clock, config.LockoutDuration))
+}
+
+// NewWithStore is like New, except that the failed attempts are kept in the given Store, e.g. a Store which
+// shares them with the other Supervisor pods.
+func NewWithStore(config Config, clock clock.PassiveClock, store Store) *Tracker {
+	return &Tracker{
+		config: withDefaults(config),
+		clock:  clock,
+		store:  store,
+	}
+}
+
+func withDefaults(config Config) Config {
+	if config.MaxFailedAttempts <= 0 {
+		config.MaxFailedAttempts = DefaultMaxFailedAttempts
+	}
+	if config.LockoutDuration <= 0 {
+		config.LockoutDuration = DefaultLockoutDuration
+	}
+	return config
+}
+
+// IsLockedOut returns true when the username is currently not allowed to attempt password logins
+// for the given upstream identity provider.
+func (t *Tracker) IsLockedOut(ctx context.Context, upstreamName, upstreamType, username string) (bool, error) {
+	if t == nil {
+		return false, nil
+	}
+
+	record, _, err := t.store.Get(ctx, keyFor(upstreamName, upstreamType, username))
+	if err != nil {
+		return false, err
+	}
+	return record != nil && t.clock.Now().Before(record.LockedUntil), nil
+}
+
+// RecordFailure notes that a password login failed for the username. Returns true when this failure caused
+// the username to become locked out. Failures to update the Store are logged, since they should not change the
+// response to the failed login.
+func (t *Tracker) RecordFailure(ctx context.Context, upstreamName, upstreamType, username string) bool {
+	if t == nil {
+		return false
+	}
+
+	k := keyFor(upstreamName, upstreamType, username)
+	var lockedOut bool
+	var record *Record
+
+	// Other Supervisor pods may record a failure for the same username at the same time, so try again on conflicts.
+	err := retry.OnError(retry.DefaultRetry, func(err error) bool { return errors.Is(err, ErrConflict) }, func() error {
+		var version string
+		var err error
+		record, version, err = t.store.Get(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		now := t.clock.Now()
+		if record == nil || record.isStale(now, t.config.LockoutDuration) {
+			if record != nil {
+				// Replace the stale record with a new one, so that a Store can garbage collect it
+				// relative to the first failure which it remembers.
+				if err := t.store.Delete(ctx, k, version); err != nil {
+					return err
+				}
+			}
+			record, version = &Record{FirstFailure: now}, ""
+		}
+
+		record.FailedAttempts++
+		lockedOut = record.FailedAttempts >= t.config.MaxFailedAttempts && !now.Before(record.LockedUntil)
+		if lockedOut {
+			record.LockedUntil = now.Add(t.config.LockoutDuration)
+		}
+		return t.store.Set(ctx, k, version, record)
+	})
+	if err != nil {
+		plog.Error("failed to record failed password login", err,
+			"upstreamName", upstreamName,
+			"upstreamType", upstreamType,
+			"username", username,
+		)
+		return false
+	}
+
+	if lockedOut {
+		plog.Warning("password logins temporarily locked for user due to too many failed attempts",
+			"upstreamName", upstreamName,
+			"upstreamType", upstreamType,
+			"username", username,
+			"failedAttempts", record.FailedAttempts,
+			"lockedUntil", record.LockedUntil.UTC().Format(time.RFC3339),
+		)
+	}
+	return lockedOut
+}
+
+// RecordSuccess forgets all previous failed attempts for the username. Failures to update the Store are logged.
+func (t *Tracker) RecordSuccess(ctx context.Context, upstreamName, upstreamType, username string) {
+	if t == nil {
+		return
+	}
+
+	if err := t.store.Delete(ctx, keyFor(upstreamName, upstreamType, username), ""); err != nil {
+		plog.Error("failed to forget failed password logins", err,
+			"upstreamName", upstreamName,
+			"upstreamType", upstreamType,
+			"username", username,
+		)
+	}
+}
+
+// keyFor normalizes the username, since LDAP and AD usernames are typically matched case-insensitively.
+func keyFor(upstreamName, upstreamType, username string) Key {
+	return Key{
+		UpstreamName: upstreamName,
+		UpstreamType: upstreamType,
+		Username:     strings.ToLower(strings.TrimSpace(username)),
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package lockout
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestNilTrackerNeverLocksOut(t *testing.T) {
+	ctx := context.Background()
+	var subject *Tracker
+	for i := 0; i < 100; i++ {
+		require.False(t, subject.RecordFailure(ctx, "idp", "ldap", "user"))
+	}
+	lockedOut, err := subject.IsLockedOut(ctx, "idp", "ldap", "user")
+	require.NoError(t, err)
+	require.False(t, lockedOut)
+	subject.RecordSuccess(ctx, "idp", "ldap", "user")
+}
+
+func TestDefaults(t *testing.T) {
+	subject := New(Config{}, clocktesting.NewFakeClock(time.Now()))
+	require.Equal(t, Config{MaxFailedAttempts: DefaultMaxFailedAttempts, LockoutDuration: DefaultLockoutDuration}, subject.config)
+}
+
+func TestTracker(t *testing.T) {
+	const (
+		idpName = "some-idp"
+		idpType = "activedirectory"
+	)
+
+	config := Config{MaxFailedAttempts: 3, LockoutDuration: 5 * time.Minute}
+
+	stores := map[string]func(clock *clocktesting.FakeClock) Store{
+		"memory": func(clock *clocktesting.FakeClock) Store {
+			return newMemoryStore(clock, config.LockoutDuration)
+		},
+		"secrets": func(clock *clocktesting.FakeClock) Store {
+			return NewSecretStore(newVersioningFakeClient().CoreV1().Secrets("some-namespace"), clock, config)
+		},
+	}
+
+	for storeName, newStore := range stores {
+		newStore := newStore
+		t.Run(storeName, func(t *testing.T) {
+			ctx := context.Background()
+
+			setup := func() (*Tracker, *clocktesting.FakeClock) {
+				fakeClock := clocktesting.NewFakeClock(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+				return NewWithStore(config, fakeClock, newStore(fakeClock)), fakeClock
+			}
+
+			isLockedOut := func(t *testing.T, subject *Tracker, upstreamName, upstreamType, username string) bool {
+				t.Helper()
+				lockedOut, err := subject.IsLockedOut(ctx, upstreamName, upstreamType, username)
+				require.NoError(t, err)
+				return lockedOut
+			}
+
+			t.Run("locks out after the max failed attempts and unlocks after the duration", func(t *testing.T) {
+				subject, fakeClock := setup()
+
+				require.False(t, subject.RecordFailure(ctx, idpName, idpType, "alice"))
+				require.False(t, subject.RecordFailure(ctx, idpName, idpType, "alice"))
+				require.False(t, isLockedOut(t, subject, idpName, idpType, "alice"))
+				require.True(t, subject.RecordFailure(ctx, idpName, idpType, "alice"))
+				require.True(t, isLockedOut(t, subject, idpName, idpType, "alice"))
+
+				// More failures while locked do not extend the lockout.
+				fakeClock.Step(4 * time.Minute)
+				require.False(t, subject.RecordFailure(ctx, idpName, idpType, "alice"))
+				require.True(t, isLockedOut(t, subject, idpName, idpType, "alice"))
+
+				fakeClock.Step(time.Minute)
+				require.False(t, isLockedOut(t, subject, idpName, idpType, "alice"))
+
+				// The failures which caused the lockout are forgotten when it ends.
+				require.False(t, subject.RecordFailure(ctx, idpName, idpType, "alice"))
+				require.False(t, isLockedOut(t, subject, idpName, idpType, "alice"))
+			})
+
+			t.Run("usernames are compared case-insensitively", func(t *testing.T) {
+				subject, _ := setup()
+
+				subject.RecordFailure(ctx, idpName, idpType, "Alice")
+				subject.RecordFailure(ctx, idpName, idpType, "alice")
+				subject.RecordFailure(ctx, idpName, idpType, " ALICE ")
+				require.True(t, isLockedOut(t, subject, idpName, idpType, "aLiCe"))
+			})
+
+			t.Run("failures are tracked separately per upstream and per user", func(t *testing.T) {
+				subject, _ := setup()
+
+				for i := 0; i < 3; i++ {
+					subject.RecordFailure(ctx, idpName, idpType, "alice")
+				}
+				require.True(t, isLockedOut(t, subject, idpName, idpType, "alice"))
+				require.False(t, isLockedOut(t, subject, idpName, idpType, "bob"))
+				require.False(t, isLockedOut(t, subject, "other-idp", idpType, "alice"))
+				require.False(t, isLockedOut(t, subject, idpName, "ldap", "alice"))
+			})
+
+			t.Run("success resets the failure count", func(t *testing.T) {
+				subject, _ := setup()
+
+				subject.RecordFailure(ctx, idpName, idpType, "alice")
+				subject.RecordFailure(ctx, idpName, idpType, "alice")
+				subject.RecordSuccess(ctx, idpName, idpType, "alice")
+				require.False(t, subject.RecordFailure(ctx, idpName, idpType, "alice"))
+				require.False(t, isLockedOut(t, subject, idpName, idpType, "alice"))
+			})
+
+			t.Run("failures are forgotten after the duration since the first failure", func(t *testing.T) {
+				subject, fakeClock := setup()
+
+				subject.RecordFailure(ctx, idpName, idpType, "alice")
+				fakeClock.Step(4 * time.Minute)
+				subject.RecordFailure(ctx, idpName, idpType, "alice")
+				fakeClock.Step(time.Minute)
+
+				require.False(t, subject.RecordFailure(ctx, idpName, idpType, "alice"))
+				require.False(t, subject.RecordFailure(ctx, idpName, idpType, "alice"))
+				require.False(t, isLockedOut(t, subject, idpName, idpType, "alice"))
+				require.True(t, subject.RecordFailure(ctx, idpName, idpType, "alice"))
+			})
+		})
+	}
+}
+
+func TestMemoryStorePrunesStaleEntries(t *testing.T) {
+	ctx := context.Background()
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+	subject := New(Config{MaxFailedAttempts: 3, LockoutDuration: 5 * time.Minute}, fakeClock)
+	store := subject.store.(*memoryStore)
+
+	subject.RecordFailure(ctx, "some-idp", "ldap", "alice")
+	subject.RecordFailure(ctx, "some-idp", "ldap", "alice")
+	subject.RecordFailure(ctx, "some-idp", "ldap", "bob")
+	fakeClock.Step(5 * time.Minute)
+
+	require.False(t, subject.RecordFailure(ctx, "some-idp", "ldap", "alice"))
+	require.Len(t, store.entries, 1)
+	require.Equal(t, 1, store.entries[keyFor("some-idp", "ldap", "alice")].record.FailedAttempts)
+}
+
+func TestRecordFailureRetriesConflicts(t *testing.T) {
+	ctx := context.Background()
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+	store := &conflictingStore{Store: newMemoryStore(fakeClock, time.Minute), conflicts: 2}
+	subject := NewWithStore(Config{MaxFailedAttempts: 1, LockoutDuration: time.Minute}, fakeClock, store)
+
+	require.True(t, subject.RecordFailure(ctx, "some-idp", "ldap", "alice"))
+	require.Equal(t, 3, store.sets)
+	lockedOut, err := subject.IsLockedOut(ctx, "some-idp", "ldap", "alice")
+	require.NoError(t, err)
+	require.True(t, lockedOut)
+}
+
+func TestStoreErrors(t *testing.T) {
+	ctx := context.Background()
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+	subject := NewWithStore(Config{MaxFailedAttempts: 1}, fakeClock, &failingStore{})
+
+	lockedOut, err := subject.IsLockedOut(ctx, "some-idp", "ldap", "alice")
+	require.EqualError(t, err, "some store error")
+	require.False(t, lockedOut)
+
+	// Errors while recording failures and successes are only logged.
+	require.False(t, subject.RecordFailure(ctx, "some-idp", "ldap", "alice"))
+	subject.RecordSuccess(ctx, "some-idp", "ldap", "alice")
+}
+
+// conflictingStore returns ErrConflict for the first few calls to Set, as if other pods changed the records.
+type conflictingStore struct {
+	Store
+	conflicts int
+	sets      int
+}
+
+func (s *conflictingStore) Set(ctx context.Context, key Key, version string, record *Record) error {
+	s.sets++
+	if s.sets <= s.conflicts {
+		return ErrConflict
+	}
+	return s.Store.Set(ctx, key, version, record)
+}
+
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, Key) (*Record, string, error) {
+	return nil, "", errors.New("some store error")
+}
+
+func (failingStore) Set(context.Context, Key, string, *Record) error {
+	return errors.New("some store error")
+}
+
+func (failingStore) Delete(context.Context, Key, string) error {
+	return errors.New("some store error")
+}
+
+// newVersioningFakeClient returns a fake client which assigns resource versions to created and updated objects,
+// which the fake client does not do by itself.
+func newVersioningFakeClient() *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	var version int64
+	assignVersion := func(action kubetesting.Action) (bool, runtime.Object, error) {
+		obj := action.(interface{ GetObject() runtime.Object }).GetObject()
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return true, nil, err
+		}
+		accessor.SetResourceVersion(strconv.FormatInt(atomic.AddInt64(&version, 1), 10))
+		return false, nil, nil
+	}
+	client.PrependReactor("create", "*", assignVersion)
+	client.PrependReactor("update", "*", assignVersion)
+	return client
+}
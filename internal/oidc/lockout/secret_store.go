@@ -0,0 +1,171 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package lockout
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/crud"
+)
+
+const (
+	TypeLabelValue = "password-lockout"
+
+	ErrLockoutStorageVersion = constable.Error("failed password login storage data has wrong version")
+
+	// Version 1 was the initial release of the storage of failed password logins.
+	lockoutStorageVersion = "1"
+
+	// maxStoredRecords limits how many Secrets may hold records at once, since anyone can attempt logins with
+	// any number of made up usernames. Usernames beyond this limit are tracked in the memory of each pod instead.
+	maxStoredRecords = 1000
+
+	// memoryVersionPrefix marks the versions of the records which are kept in memory instead of in Secrets.
+	memoryVersionPrefix = "memory-"
+)
+
+// secretStore keeps each record in its own Secret, so that all Supervisor pods share the failed attempts and they
+// survive restarts. The Secrets are deleted by the garbage collector of the session storage once their records
+// have become stale.
+type secretStore struct {
+	storage    crud.Storage
+	secrets    corev1client.SecretInterface
+	overflow   *memoryStore // records which did not fit into Secrets because of maxRecords
+	maxRecords int
+}
+
+// storedRecord defines the format of a record when stored in a Secret as a JSON string value.
+type storedRecord struct {
+	Key    Key    `json:"key"`
+	Record Record `json:"record"`
+	// The format version. Take care when updating. We cannot simply bump the storage version and drop/ignore old data.
+	// Updating this would require some form of migration of existing stored data.
+	Version string `json:"version"`
+}
+
+// NewSecretStore returns a Store which keeps the records of a Tracker with the given Config in Secrets using the
+// given client.
+func NewSecretStore(secrets corev1client.SecretInterface, clock clock.PassiveClock, config Config) Store {
+	// A record is created at the first failure. Its failures are counted for at most LockoutDuration, and a lockout
+	// which they cause lasts for another LockoutDuration, after which the record is stale and may be deleted.
+	config = withDefaults(config)
+	lifetime := 2 * config.LockoutDuration
+	return &secretStore{
+		storage:    crud.New(TypeLabelValue, secrets, clock.Now, lifetime),
+		secrets:    secrets,
+		overflow:   newMemoryStore(clock, config.LockoutDuration),
+		maxRecords: maxStoredRecords,
+	}
+}
+
+func (s *secretStore) Get(ctx context.Context, key Key) (*Record, string, error) {
+	stored := &storedRecord{}
+	rv, err := s.storage.Get(ctx, keyToSignature(key), stored)
+	if apierrors.IsNotFound(err) {
+		return s.getOverflow(ctx, key)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get failed password logins: %w", err)
+	}
+	if stored.Version != lockoutStorageVersion {
+		return nil, "", fmt.Errorf("%w: failed password login storage has version %s instead of %s",
+			ErrLockoutStorageVersion, stored.Version, lockoutStorageVersion)
+	}
+	return &stored.Record, rv, nil
+}
+
+func (s *secretStore) Set(ctx context.Context, key Key, version string, record *Record) error {
+	stored := &storedRecord{Key: key, Record: *record, Version: lockoutStorageVersion}
+	signature := keyToSignature(key)
+
+	if strings.HasPrefix(version, memoryVersionPrefix) {
+		return s.overflow.Set(ctx, key, strings.TrimPrefix(version, memoryVersionPrefix), record)
+	}
+
+	if mustBeCreate := len(version) == 0; mustBeCreate {
+		full, err := s.isFull(ctx)
+		if err != nil {
+			return err
+		}
+		if full {
+			return s.overflow.Set(ctx, key, "", record)
+		}
+		if _, err := s.storage.Create(ctx, signature, stored, nil, nil); err != nil {
+			return wrapConflict("failed to create failed password logins", err)
+		}
+		return nil
+	}
+
+	if _, err := s.storage.Update(ctx, signature, version, stored); err != nil {
+		return wrapConflict("failed to update failed password logins", err)
+	}
+	return nil
+}
+
+func (s *secretStore) Delete(ctx context.Context, key Key, version string) error {
+	if strings.HasPrefix(version, memoryVersionPrefix) {
+		return s.overflow.Delete(ctx, key, strings.TrimPrefix(version, memoryVersionPrefix))
+	}
+	if version == "" {
+		_ = s.overflow.Delete(ctx, key, "") // cannot fail without a version
+	}
+
+	var preconditions *metav1.Preconditions
+	if version != "" {
+		preconditions = &metav1.Preconditions{ResourceVersion: &version}
+	}
+	err := s.secrets.Delete(ctx, s.storage.GetName(keyToSignature(key)), metav1.DeleteOptions{Preconditions: preconditions})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return wrapConflict("failed to delete failed password logins", err)
+	}
+	return nil
+}
+
+func (s *secretStore) getOverflow(ctx context.Context, key Key) (*Record, string, error) {
+	record, version, err := s.overflow.Get(ctx, key)
+	if record == nil || err != nil {
+		return record, version, err
+	}
+	return record, memoryVersionPrefix + version, nil
+}
+
+// isFull returns true when no more records may be created in Secrets. Stale records count until the garbage
+// collector deletes them, which bounds the number of Secrets even if the records are never cleaned up otherwise.
+func (s *secretStore) isFull(ctx context.Context) (bool, error) {
+	secrets, err := s.secrets.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set{crud.SecretLabelKey: TypeLabelValue}.String(),
+		Limit:         int64(s.maxRecords),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to count failed password logins: %w", err)
+	}
+	return len(secrets.Items) >= s.maxRecords, nil
+}
+
+// wrapConflict wraps the error with ErrConflict when it means that the record was changed concurrently.
+func wrapConflict(msg string, err error) error {
+	if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("%s: %w: %s", msg, ErrConflict, err.Error())
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+func keyToSignature(key Key) string {
+	// Usernames can be too long for the name of a Secret, and should not be revealed by it, so use a hash.
+	data, _ := json.Marshal(key) // cannot fail, since Key only has string fields
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
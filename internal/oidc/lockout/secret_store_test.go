@@ -0,0 +1,184 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package lockout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+const testNamespace = "some-namespace"
+
+func TestSecretStoreGetSetAndDelete(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := newVersioningFakeClient()
+	subject := NewSecretStore(client.CoreV1().Secrets(testNamespace), clocktesting.NewFakeClock(now), Config{LockoutDuration: 5 * time.Minute})
+	key := keyFor("some-idp", "ldap", "Alice")
+
+	// A username without failed attempts.
+	record, version, err := subject.Get(ctx, key)
+	require.NoError(t, err)
+	require.Empty(t, version)
+	require.Nil(t, record)
+
+	failed := &Record{FailedAttempts: 1, FirstFailure: now}
+	require.NoError(t, subject.Set(ctx, key, "", failed))
+
+	secrets, err := client.CoreV1().Secrets(testNamespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, secrets.Items, 1)
+	secret := secrets.Items[0]
+	require.Equal(t, corev1.SecretType("storage.pinniped.dev/password-lockout"), secret.Type)
+	require.Equal(t, "password-lockout", secret.Labels["storage.pinniped.dev/type"])
+	require.NotContains(t, secret.Name, "alice")
+	require.LessOrEqual(t, len(secret.Name), 253)
+	// The Secret is garbage collected once the failures and the lockout which they could cause are over.
+	require.Equal(t, map[string]string{"storage.pinniped.dev/garbage-collect-after": "2023-01-02T03:14:05Z"}, secret.Annotations)
+
+	var stored map[string]interface{}
+	require.NoError(t, json.Unmarshal(secret.Data["pinniped-storage-data"], &stored))
+	require.Equal(t, map[string]interface{}{
+		"key":     map[string]interface{}{"upstreamName": "some-idp", "upstreamType": "ldap", "username": "alice"},
+		"record":  map[string]interface{}{"failedAttempts": float64(1), "firstFailure": "2023-01-02T03:04:05Z", "lockedUntil": "0001-01-01T00:00:00Z"},
+		"version": "1",
+	}, stored)
+
+	record, version, err = subject.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, "1", version)
+	require.Equal(t, 1, record.FailedAttempts)
+	require.True(t, now.Equal(record.FirstFailure))
+
+	// Creating the record again is a conflict.
+	require.ErrorIs(t, subject.Set(ctx, key, "", failed), ErrConflict)
+
+	record.FailedAttempts = 2
+	require.NoError(t, subject.Set(ctx, key, version, record))
+	record, _, err = subject.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, 2, record.FailedAttempts)
+
+	// Another username has its own record.
+	record, _, err = subject.Get(ctx, keyFor("some-idp", "ldap", "bob"))
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	require.NoError(t, subject.Delete(ctx, key, ""))
+	record, version, err = subject.Get(ctx, key)
+	require.NoError(t, err)
+	require.Empty(t, version)
+	require.Nil(t, record)
+
+	// Deleting is idempotent.
+	require.NoError(t, subject.Delete(ctx, key, ""))
+}
+
+func TestSecretStoreConflicts(t *testing.T) {
+	ctx := context.Background()
+	key := keyFor("some-idp", "ldap", "alice")
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, "some-secret", errors.New("some conflict"))
+
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("update", "secrets", func(kubetesting.Action) (bool, runtime.Object, error) {
+		return true, nil, conflict
+	})
+	client.PrependReactor("delete", "secrets", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		if action.(kubetesting.DeleteAction).GetDeleteOptions().Preconditions == nil {
+			return true, nil, errors.New("some delete error")
+		}
+		return true, nil, conflict
+	})
+	subject := NewSecretStore(client.CoreV1().Secrets(testNamespace), clocktesting.NewFakeClock(time.Now()), Config{})
+
+	require.NoError(t, subject.Set(ctx, key, "", &Record{FailedAttempts: 1}))
+	require.ErrorIs(t, subject.Set(ctx, key, "123", &Record{FailedAttempts: 2}), ErrConflict)
+	require.ErrorIs(t, subject.Delete(ctx, key, "123"), ErrConflict)
+
+	err := subject.Delete(ctx, key, "")
+	require.EqualError(t, err, "failed to delete failed password logins: some delete error")
+	require.NotErrorIs(t, err, ErrConflict)
+}
+
+func TestSecretStoreKeepsRecordsBeyondTheLimitInMemory(t *testing.T) {
+	ctx := context.Background()
+	client := newVersioningFakeClient()
+	subject := NewSecretStore(client.CoreV1().Secrets(testNamespace), clocktesting.NewFakeClock(time.Now()), Config{}).(*secretStore)
+	subject.maxRecords = 2
+	requireSecretCount := func(want int) {
+		t.Helper()
+		secrets, err := client.CoreV1().Secrets(testNamespace).List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, secrets.Items, want)
+	}
+
+	for _, username := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, subject.Set(ctx, keyFor("some-idp", "ldap", username), "", &Record{FailedAttempts: 1}))
+	}
+	requireSecretCount(2)
+
+	// The record which did not fit into a Secret behaves like the others.
+	carol := keyFor("some-idp", "ldap", "carol")
+	record, version, err := subject.Get(ctx, carol)
+	require.NoError(t, err)
+	require.Equal(t, "memory-1", version)
+	require.Equal(t, 1, record.FailedAttempts)
+
+	require.ErrorIs(t, subject.Set(ctx, carol, "", record), ErrConflict)
+	record.FailedAttempts = 2
+	require.NoError(t, subject.Set(ctx, carol, version, record))
+	require.ErrorIs(t, subject.Set(ctx, carol, version, record), ErrConflict)
+
+	record, version, err = subject.Get(ctx, carol)
+	require.NoError(t, err)
+	require.Equal(t, "memory-2", version)
+	require.Equal(t, 2, record.FailedAttempts)
+	requireSecretCount(2)
+
+	require.ErrorIs(t, subject.Delete(ctx, carol, "memory-1"), ErrConflict)
+	require.NoError(t, subject.Delete(ctx, carol, ""))
+	record, version, err = subject.Get(ctx, carol)
+	require.NoError(t, err)
+	require.Empty(t, version)
+	require.Nil(t, record)
+
+	// Once a Secret is deleted, the next record can be stored in a Secret again.
+	require.NoError(t, subject.Delete(ctx, keyFor("some-idp", "ldap", "alice"), ""))
+	require.NoError(t, subject.Set(ctx, carol, "", &Record{FailedAttempts: 1}))
+	requireSecretCount(2)
+	_, version, err = subject.Get(ctx, carol)
+	require.NoError(t, err)
+	require.NotContains(t, version, "memory-")
+}
+
+func TestSecretStoreGetWrongVersion(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	subject := NewSecretStore(client.CoreV1().Secrets(testNamespace), clocktesting.NewFakeClock(time.Now()), Config{})
+	key := keyFor("some-idp", "ldap", "alice")
+
+	require.NoError(t, subject.Set(ctx, key, "", &Record{FailedAttempts: 1}))
+	secrets, err := client.CoreV1().Secrets(testNamespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	secret := secrets.Items[0]
+	secret.Data["pinniped-storage-data"] = []byte(`{"key":{},"record":{},"version":"not-1"}`)
+	_, err = client.CoreV1().Secrets(testNamespace).Update(ctx, &secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	_, _, err = subject.Get(ctx, key)
+	require.EqualError(t, err, "failed password login storage data has wrong version: failed password login storage has version not-1 instead of 1")
+}
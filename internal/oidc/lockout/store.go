@@ -0,0 +1,120 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package lockout
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+// ErrConflict is returned by a Store when a record was changed since it was read.
+const ErrConflict = constable.Error("failed password login record was changed concurrently")
+
+// Key identifies the failed password logins of a username to an upstream identity provider.
+type Key struct {
+	UpstreamName string `json:"upstreamName"`
+	UpstreamType string `json:"upstreamType"`
+	Username     string `json:"username"`
+}
+
+// Record is the failed password logins of a username which are remembered by a Store.
+type Record struct {
+	FailedAttempts int       `json:"failedAttempts"`
+	FirstFailure   time.Time `json:"firstFailure"`
+	LockedUntil    time.Time `json:"lockedUntil"`
+}
+
+// isStale returns true when the record is no longer locked and its failures are old enough to forget.
+func (r *Record) isStale(now time.Time, lockoutDuration time.Duration) bool {
+	return !now.Before(r.LockedUntil) && !now.Before(r.FirstFailure.Add(lockoutDuration))
+}
+
+// Store keeps the records of a Tracker. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the record of the key and its version, or a nil record when there is none.
+	Get(ctx context.Context, key Key) (*Record, string, error)
+
+	// Set creates the record of the key when version is empty, and otherwise replaces the record of the given
+	// version. It returns an error which wraps ErrConflict when the record was created or changed in the meantime.
+	Set(ctx context.Context, key Key, version string, record *Record) error
+
+	// Delete removes the record of the key. When version is not empty, it returns an error which wraps ErrConflict
+	// when the record was changed in the meantime. It is not an error when there is no record.
+	Delete(ctx context.Context, key Key, version string) error
+}
+
+// memoryStore keeps the records in the memory of this process, so each Supervisor pod tracks failures separately.
+type memoryStore struct {
+	mu              sync.Mutex
+	clock           clock.PassiveClock
+	lockoutDuration time.Duration
+	entries         map[Key]memoryEntry
+	lastVersion     int
+}
+
+type memoryEntry struct {
+	record  Record
+	version int
+}
+
+func newMemoryStore(clock clock.PassiveClock, lockoutDuration time.Duration) *memoryStore {
+	return &memoryStore{clock: clock, lockoutDuration: lockoutDuration, entries: map[Key]memoryEntry{}}
+}
+
+func (s *memoryStore) Get(_ context.Context, key Key) (*Record, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, "", nil
+	}
+	record := e.record
+	return &record, strconv.Itoa(e.version), nil
+}
+
+func (s *memoryStore) Set(_ context.Context, key Key, version string, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if ok != (version != "") || (ok && strconv.Itoa(e.version) != version) {
+		return ErrConflict
+	}
+	if !ok {
+		s.pruneStaleEntries()
+	}
+	// Versions are never reused, even for a key whose record was deleted and created again.
+	s.lastVersion++
+	s.entries[key] = memoryEntry{record: *record, version: s.lastVersion}
+	return nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key Key, version string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok && version != "" && strconv.Itoa(e.version) != version {
+		return ErrConflict
+	}
+	delete(s.entries, key)
+	return nil
+}
+
+// pruneStaleEntries keeps the map from growing without bound as unique usernames are tried.
+// Callers must hold the lock.
+func (s *memoryStore) pruneStaleEntries() {
+	now := s.clock.Now()
+	for k, e := range s.entries {
+		if e.record.isStale(now, s.lockoutDuration) {
+			delete(s.entries, k)
+		}
+	}
+}
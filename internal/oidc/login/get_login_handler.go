@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package login
@@ -13,6 +13,10 @@ import (
 const (
 	internalErrorMessage                    = "An internal error occurred. Please contact your administrator for help."
 	incorrectUsernameOrPasswordErrorMessage = "Incorrect username or password."
+	lockedOutErrorMessage                   = "Too many failed login attempts. Please try again later."
+	webAuthnErrorMessage                    = "Your security key could not be verified. Please try again."
+	totpErrorMessage                        = "Incorrect authentication code. Please try again."
+	policyDeniedErrorMessage                = "You are not allowed to log in. Please contact your administrator for help."
 )
 
 func NewGetHandler(loginPath string) HandlerFunc {
@@ -34,8 +38,17 @@ func getAlert(r *http.Request) (string, bool) {
 	errorParamValue := r.URL.Query().Get(errParamName)
 
 	message := internalErrorMessage
-	if errorParamValue == string(ShowBadUserPassErr) {
+	switch errorParamValue {
+	case string(ShowBadUserPassErr):
 		message = incorrectUsernameOrPasswordErrorMessage
+	case string(ShowLockedOutErr):
+		message = lockedOutErrorMessage
+	case string(ShowWebAuthnErr):
+		message = webAuthnErrorMessage
+	case string(ShowTOTPErr):
+		message = totpErrorMessage
+	case string(ShowPolicyDeniedErr):
+		message = policyDeniedErrorMessage
 	}
 
 	return message, errorParamValue != ""
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package login
@@ -58,6 +58,62 @@ func TestGetLogin(t *testing.T) {
 				"Incorrect username or password.",
 			),
 		},
+		{
+			name: "displays error banner when err=locked_out_error param is sent",
+			decodedState: &oidc.UpstreamStateParamData{
+				UpstreamName: testUpstreamName,
+				UpstreamType: testUpstreamType,
+			},
+			encodedState:    testEncodedState,
+			errParam:        "locked_out_error",
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBody: testutil.ExpectedLoginPageHTML(loginhtml.CSS(), testUpstreamName, testPath, testEncodedState,
+				"Too many failed login attempts. Please try again later.",
+			),
+		},
+		{
+			name: "displays error banner when err=policy_denied_error param is sent",
+			decodedState: &oidc.UpstreamStateParamData{
+				UpstreamName: testUpstreamName,
+				UpstreamType: testUpstreamType,
+			},
+			encodedState:    testEncodedState,
+			errParam:        "policy_denied_error",
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBody: testutil.ExpectedLoginPageHTML(loginhtml.CSS(), testUpstreamName, testPath, testEncodedState,
+				"You are not allowed to log in. Please contact your administrator for help.",
+			),
+		},
+		{
+			name: "displays error banner when err=webauthn_error param is sent",
+			decodedState: &oidc.UpstreamStateParamData{
+				UpstreamName: testUpstreamName,
+				UpstreamType: testUpstreamType,
+			},
+			encodedState:    testEncodedState,
+			errParam:        "webauthn_error",
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBody: testutil.ExpectedLoginPageHTML(loginhtml.CSS(), testUpstreamName, testPath, testEncodedState,
+				"Your security key could not be verified. Please try again.",
+			),
+		},
+		{
+			name: "displays error banner when err=totp_error param is sent",
+			decodedState: &oidc.UpstreamStateParamData{
+				UpstreamName: testUpstreamName,
+				UpstreamType: testUpstreamType,
+			},
+			encodedState:    testEncodedState,
+			errParam:        "totp_error",
+			wantStatus:      http.StatusOK,
+			wantContentType: htmlContentType,
+			wantBody: testutil.ExpectedLoginPageHTML(loginhtml.CSS(), testUpstreamName, testPath, testEncodedState,
+				"Incorrect authentication code. Please try again.",
+			),
+		},
 		{
 			name: "displays error banner when err=internal_error param is sent",
 			decodedState: &oidc.UpstreamStateParamData{
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package login
@@ -14,6 +14,7 @@ import (
 	"go.pinniped.dev/internal/oidc/login/loginhtml"
 	"go.pinniped.dev/internal/oidc/provider/formposthtml"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/tracing"
 )
 
 type ErrorParamValue string
@@ -24,9 +25,13 @@ const (
 	stateParamName    = "state"
 	errParamName      = "err"
 
-	ShowNoError        ErrorParamValue = ""
-	ShowInternalError  ErrorParamValue = "internal_error"
-	ShowBadUserPassErr ErrorParamValue = "login_error"
+	ShowNoError         ErrorParamValue = ""
+	ShowInternalError   ErrorParamValue = "internal_error"
+	ShowBadUserPassErr  ErrorParamValue = "login_error"
+	ShowLockedOutErr    ErrorParamValue = "locked_out_error"
+	ShowWebAuthnErr     ErrorParamValue = "webauthn_error"
+	ShowTOTPErr         ErrorParamValue = "totp_error"
+	ShowPolicyDeniedErr ErrorParamValue = "policy_denied_error"
 )
 
 // HandlerFunc is a function that can handle either a GET or POST request for the login endpoint.
@@ -69,6 +74,7 @@ func NewHandler(
 			plog.InfoErr("state or CSRF error", err)
 			return err
 		}
+		tracing.SetAttributes(r.Context(), tracing.LoginID(string(decodedState.Nonce)), tracing.UpstreamNameKey.String(decodedState.UpstreamName))
 
 		switch decodedState.UpstreamType {
 		case string(idpdiscoveryv1alpha1.IDPTypeLDAP), string(idpdiscoveryv1alpha1.IDPTypeActiveDirectory):
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package loginhtml defines HTML templates used by the Supervisor.
@@ -34,6 +34,34 @@ var (
 		`style-src '` + csp.Hash(minifiedCSS) + `'`,
 		`frame-ancestors 'none'`,
 	}, "; ")
+
+	//go:embed webauthn_form.js
+	rawWebAuthnJS      string
+	minifiedWebAuthnJS = panicOnError(minify.JS(rawWebAuthnJS))
+
+	//go:embed webauthn_form.gohtml
+	rawWebAuthnHTMLTemplate string
+
+	// The WebAuthn page shares the CSS of the login page, and adds its own inline JS.
+	parsedWebAuthnHTMLTemplate = template.Must(template.New("webauthn_form.gohtml").Funcs(template.FuncMap{
+		"minifiedCSS": func() template.CSS { return template.CSS(CSS()) },
+		"minifiedJS":  func() template.JS { return template.JS(minifiedWebAuthnJS) }, //nolint:gosec // This is 100% static input, not attacker-controlled.
+	}).Parse(rawWebAuthnHTMLTemplate))
+
+	webAuthnCSPValue = strings.Join([]string{
+		`default-src 'none'`,
+		`script-src '` + csp.Hash(minifiedWebAuthnJS) + `'`,
+		`style-src '` + csp.Hash(minifiedCSS) + `'`,
+		`frame-ancestors 'none'`,
+	}, "; ")
+
+	//go:embed totp_form.gohtml
+	rawTOTPHTMLTemplate string
+
+	// The TOTP page shares the CSS of the login page, and therefore also its CSP header value.
+	parsedTOTPHTMLTemplate = template.Must(template.New("totp_form.gohtml").Funcs(template.FuncMap{
+		"minifiedCSS": func() template.CSS { return template.CSS(CSS()) },
+	}).Parse(rawTOTPHTMLTemplate))
 )
 
 func panicOnError(s string, err error) string {
@@ -63,3 +91,44 @@ type PageData struct {
 	MinifiedCSS   template.CSS
 	PostPath      string
 }
+
+// WebAuthnContentSecurityPolicy returns the Content-Security-Policy header value to make the WebAuthnTemplate()
+// operate correctly.
+func WebAuthnContentSecurityPolicy() string { return webAuthnCSPValue }
+
+// WebAuthnTemplate returns the html/template.Template for rendering the page which asks the user to enroll or to use
+// a security key after they have logged in with their password.
+func WebAuthnTemplate() *template.Template { return parsedWebAuthnHTMLTemplate }
+
+// WebAuthnPageData represents the inputs to the WebAuthn template. The binary values are base64url-encoded.
+type WebAuthnPageData struct {
+	State         string
+	IDPName       string
+	PostPath      string
+	LoginID       string
+	Enroll        bool
+	Optional      bool
+	Challenge     string
+	RPID          string
+	UserID        string
+	UserName      string
+	CredentialIDs string // comma-separated
+}
+
+// TOTPTemplate returns the html/template.Template for rendering the page which asks the user to enroll or to enter a
+// TOTP code after they have logged in with their password. It operates correctly with the ContentSecurityPolicy().
+func TOTPTemplate() *template.Template { return parsedTOTPHTMLTemplate }
+
+// TOTPPageData represents the inputs to the TOTP template.
+type TOTPPageData struct {
+	State    string
+	IDPName  string
+	PostPath string
+	LoginID  string
+	Enroll   bool
+	Optional bool
+	// The base32-encoded secret to enroll, for users who cannot scan the QR code.
+	Secret string
+	// The QR code of the provisioning URL of the secret to enroll as an SVG image, if the URL fits in a QR code.
+	QRCode template.HTML
+}
@@ -66,3 +66,96 @@ func TestHelpers(t *testing.T) {
 	require.Equal(t, "test", panicOnError("test", nil))
 	require.PanicsWithError(t, "some error", func() { panicOnError("", fmt.Errorf("some error")) })
 }
+
+func TestWebAuthnTemplate(t *testing.T) {
+	pageInputs := &WebAuthnPageData{
+		PostPath:      "test-post-path",
+		State:         "test-encoded-state",
+		IDPName:       "test-idp-name",
+		LoginID:       "test-login-id",
+		Enroll:        true,
+		Optional:      true,
+		Challenge:     "test-challenge",
+		RPID:          "issuer.example.com",
+		UserID:        "test-user-id",
+		UserName:      "test-user-name",
+		CredentialIDs: "id1,id2",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WebAuthnTemplate().Execute(&buf, pageInputs))
+	html := buf.String()
+	require.Contains(t, html, `<style>`+testExpectedCSS+`</style>`)
+	require.Contains(t, html, `<script>`+minifiedWebAuthnJS+`</script>`)
+	require.Contains(t, html, `<h1>Log in to test-idp-name</h1>`)
+	require.Contains(t, html, `<form action="test-post-path" method="post"
+          data-enroll="true" data-challenge="test-challenge" data-rp-id="issuer.example.com"
+          data-user-id="test-user-id" data-user-name="test-user-name" data-credential-ids="id1,id2">`)
+	require.Contains(t, html, `<input type="hidden" name="state" id="state" value="test-encoded-state">`)
+	require.Contains(t, html, `<input type="hidden" name="webauthn_login" id="webauthn_login" value="test-login-id">`)
+	require.Contains(t, html, `value="Register security key"`)
+	require.Contains(t, html, `name="skip"`)
+
+	// Render again to verify an enrolled credential, which cannot be skipped.
+	pageInputs.Enroll = false
+	pageInputs.Optional = false
+	buf = bytes.Buffer{}
+	require.NoError(t, WebAuthnTemplate().Execute(&buf, pageInputs))
+	html = buf.String()
+	require.Contains(t, html, `data-enroll="false"`)
+	require.Contains(t, html, `value="Use security key"`)
+	require.NotContains(t, html, `name="skip"`)
+}
+
+func TestWebAuthnContentSecurityPolicy(t *testing.T) {
+	require.Regexp(t, `^default-src 'none'; `+
+		`script-src 'sha256-[a-zA-Z0-9+/]{43}='; `+
+		`style-src 'sha256-QC9ckaUFAdcN0Ysmu8q8iqCazYFgrJSQDJPa/przPXU='; `+
+		`frame-ancestors 'none'$`, WebAuthnContentSecurityPolicy())
+}
+
+func TestTOTPTemplate(t *testing.T) {
+	pageInputs := &TOTPPageData{
+		PostPath: "test-post-path",
+		State:    "test-encoded-state",
+		IDPName:  "test-idp-name",
+		LoginID:  "test-login-id",
+		Enroll:   true,
+		Optional: true,
+		Secret:   "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		QRCode:   `<svg id="test-qr-code"></svg>`,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, TOTPTemplate().Execute(&buf, pageInputs))
+	html := buf.String()
+	require.Contains(t, html, `<style>`+testExpectedCSS+`</style>`)
+	require.NotContains(t, html, `<script>`)
+	require.Contains(t, html, `<h1>Log in to test-idp-name</h1>`)
+	require.Contains(t, html, `<svg id="test-qr-code"></svg>`)
+	require.Contains(t, html, `<code id="secret">GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ</code>`)
+	require.Contains(t, html, `<form action="test-post-path" method="post">`)
+	require.Contains(t, html, `<input type="hidden" name="state" id="state" value="test-encoded-state">`)
+	require.Contains(t, html, `<input type="hidden" name="totp_login" id="totp_login" value="test-login-id">`)
+	require.Contains(t, html, `autocomplete="one-time-code"`)
+	require.Contains(t, html, `value="Set up and log in"`)
+	require.Contains(t, html, `name="skip"`)
+
+	// Render again without a QR code, e.g. because the provisioning URL was too long.
+	pageInputs.QRCode = ""
+	buf = bytes.Buffer{}
+	require.NoError(t, TOTPTemplate().Execute(&buf, pageInputs))
+	html = buf.String()
+	require.NotContains(t, html, `<svg`)
+	require.Contains(t, html, `<code id="secret">GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ</code>`)
+
+	// Render again to verify an enrolled secret, which cannot be skipped.
+	pageInputs.Enroll = false
+	pageInputs.Optional = false
+	buf = bytes.Buffer{}
+	require.NoError(t, TOTPTemplate().Execute(&buf, pageInputs))
+	html = buf.String()
+	require.NotContains(t, html, `id="secret"`)
+	require.Contains(t, html, `value="Log in"`)
+	require.NotContains(t, html, `name="skip"`)
+}
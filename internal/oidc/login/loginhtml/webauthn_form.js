@@ -0,0 +1,68 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+window.onload = () => {
+    const form = document.forms[0];
+    const options = form.dataset;
+
+    const fromBase64URL = (s) => Uint8Array.from(
+        atob(s.replace(/-/g, '+').replace(/_/g, '/')), c => c.charCodeAt(0));
+    const toBase64URL = (buffer) => btoa(String.fromCharCode(...new Uint8Array(buffer)))
+        .replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+
+    // The credentials which the user has already enrolled for this hostname.
+    const credentials = (options.credentialIds ? options.credentialIds.split(',') : [])
+        .map(id => ({type: 'public-key', id: fromBase64URL(id)}));
+
+    // Enroll a new credential, without attestation, so the public key can be read from the response.
+    const enroll = () => navigator.credentials.create({
+        publicKey: {
+            challenge: fromBase64URL(options.challenge),
+            rp: {id: options.rpId, name: options.rpId},
+            user: {id: fromBase64URL(options.userId), name: options.userName, displayName: options.userName},
+            pubKeyCredParams: [{type: 'public-key', alg: -7}, {type: 'public-key', alg: -257}],
+            excludeCredentials: credentials,
+            authenticatorSelection: {userVerification: 'discouraged'},
+            attestation: 'none',
+        },
+    }).then(credential => {
+        form.elements['public_key'].value = toBase64URL(credential.response.getPublicKey());
+        form.elements['public_key_algorithm'].value = credential.response.getPublicKeyAlgorithm();
+        form.elements['authenticator_data'].value = toBase64URL(credential.response.getAuthenticatorData());
+        return credential;
+    });
+
+    // Verify one of the enrolled credentials.
+    const verify = () => navigator.credentials.get({
+        publicKey: {
+            challenge: fromBase64URL(options.challenge),
+            rpId: options.rpId,
+            allowCredentials: credentials,
+            userVerification: 'discouraged',
+        },
+    }).then(credential => {
+        form.elements['signature'].value = toBase64URL(credential.response.signature);
+        form.elements['authenticator_data'].value = toBase64URL(credential.response.authenticatorData);
+        return credential;
+    });
+
+    form.onsubmit = (event) => {
+        // Skipping the optional enrollment submits the form as it is.
+        if (event.submitter && event.submitter.name === 'skip') {
+            return;
+        }
+        event.preventDefault();
+        document.getElementById('alert').hidden = true;
+
+        (options.enroll === 'true' ? enroll() : verify())
+            .then(credential => {
+                form.elements['credential_id'].value = toBase64URL(credential.rawId);
+                form.elements['client_data'].value = toBase64URL(credential.response.clientDataJSON);
+                form.submit();
+            })
+            .catch(e => {
+                console.error('security key was not used: ' + e);
+                document.getElementById('alert').hidden = false;
+            });
+    };
+};
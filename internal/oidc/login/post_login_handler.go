@@ -4,19 +4,56 @@
 package login
 
 import (
+	"errors"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/ory/fosite"
 
+	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/httputil/clientip"
 	"go.pinniped.dev/internal/httputil/httperr"
 	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/clientregistry"
 	"go.pinniped.dev/internal/oidc/downstreamsession"
+	"go.pinniped.dev/internal/oidc/lockout"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/tracing"
+	"go.pinniped.dev/internal/webauthn"
 )
 
-func NewPostHandler(issuerURL string, upstreamIDPs oidc.UpstreamIdentityProvidersLister, oauthHelper fosite.OAuth2Provider) HandlerFunc {
+func NewPostHandler(
+	issuerURL string,
+	upstreamIDPs oidc.UpstreamIdentityProvidersLister,
+	oauthHelper fosite.OAuth2Provider,
+	lockoutTracker *lockout.Tracker,
+	webAuthn *WebAuthn,
+	totp *TOTP,
+	loginPolicy *loginpolicy.Policy,
+	auditLogger auditlog.Logger,
+) HandlerFunc {
+	var relyingParty *webauthn.RelyingParty
+	if webAuthn != nil {
+		var err error
+		if relyingParty, err = webauthn.NewRelyingParty(issuerURL); err != nil {
+			// Should not happen, because the FederationDomain issuer must be an https URL.
+			plog.Error("could not use issuer as WebAuthn relying party, so the WebAuthn second factor will fail", err)
+		}
+	}
+
+	// Authenticator apps show the host of the issuer next to the TOTP codes.
+	totpIssuerName := issuerURL
+	if parsedIssuerURL, err := url.Parse(issuerURL); err == nil {
+		totpIssuerName = parsedIssuerURL.Host
+	}
+
 	return func(w http.ResponseWriter, r *http.Request, encodedState string, decodedState *oidc.UpstreamStateParamData) error {
+		auditLogger := auditlog.WithSourceIP(auditLogger, clientip.FromContext(r.Context()).IP)
+
 		// Note that the login handler prevents this handler from being called with OIDC upstreams.
 		_, ldapUpstream, idpType, err := oidc.FindUpstreamIDPByNameAndType(upstreamIDPs, decodedState.UpstreamName, decodedState.UpstreamType)
 		if err != nil {
@@ -49,6 +86,49 @@ func NewPostHandler(issuerURL string, upstreamIDPs oidc.UpstreamIdentityProvider
 		// This is instead of asking the user to approve these scopes. Note that `NewAuthorizeRequest` would have returned
 		// an error if the client requested a scope that they are not allowed to request, so we don't need to worry about that here.
 		downstreamsession.AutoApproveScopes(authorizeRequester)
+		tracing.SetAttributes(r.Context(), tracing.ClientIDKey.String(authorizeRequester.GetClient().GetID()))
+
+		// The user has already verified their password and is now responding to the WebAuthn second factor page.
+		if loginID := r.PostFormValue(webAuthnLoginParamName); loginID != "" {
+			if webAuthn == nil || relyingParty == nil {
+				return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
+			}
+			login, err := webAuthn.finishSecondFactor(r, relyingParty, loginID, decodedState)
+			if err != nil {
+				auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+					authorizeRequester, ldapUpstream.GetName(), "", err))
+				if errors.Is(err, downstreamsession.ErrSecondFactorNotVerified) {
+					plog.Info("WebAuthn second factor was not verified", "upstreamName", ldapUpstream.GetName(), "reason", err.Error())
+					return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowWebAuthnErr)
+				}
+				plog.WarningErr("unexpected error during WebAuthn second factor", err, "upstreamName", ldapUpstream.GetName())
+				return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
+			}
+			finishLogin(r, w, oauthHelper, auditLogger, authorizeRequester, ldapUpstream.GetName(),
+				login.Subject, login.Username, login.Groups, login.CustomSessionData)
+			return nil
+		}
+
+		// The user has already verified their password and is now responding to the TOTP second factor page.
+		if loginID := r.PostFormValue(totpLoginParamName); loginID != "" {
+			if totp == nil {
+				return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
+			}
+			login, err := totp.finishSecondFactor(r, loginID, decodedState)
+			if err != nil {
+				auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+					authorizeRequester, ldapUpstream.GetName(), "", err))
+				if errors.Is(err, downstreamsession.ErrSecondFactorNotVerified) {
+					plog.Info("TOTP second factor was not verified", "upstreamName", ldapUpstream.GetName(), "reason", err.Error())
+					return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowTOTPErr)
+				}
+				plog.WarningErr("unexpected error during TOTP second factor", err, "upstreamName", ldapUpstream.GetName())
+				return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
+			}
+			finishLogin(r, w, oauthHelper, auditLogger, authorizeRequester, ldapUpstream.GetName(),
+				login.Subject, login.Username, login.Groups, login.CustomSessionData)
+			return nil
+		}
 
 		// Get the username and password form params from the POST body.
 		username := r.PostFormValue(usernameParamName)
@@ -61,10 +141,28 @@ func NewPostHandler(issuerURL string, upstreamIDPs oidc.UpstreamIdentityProvider
 			return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowBadUserPassErr)
 		}
 
+		// Do not even try the upstream IDP when this username has had too many recent failed attempts.
+		lockedOut, err := lockoutTracker.IsLockedOut(r.Context(), ldapUpstream.GetName(), string(idpType), username)
+		if err != nil {
+			plog.WarningErr("unexpected error while checking for a password lockout", err, "upstreamName", ldapUpstream.GetName())
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+				authorizeRequester, ldapUpstream.GetName(), username, err))
+			return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
+		}
+		if lockedOut {
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+				authorizeRequester, ldapUpstream.GetName(), username, downstreamsession.ErrLockedOut))
+			return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowLockedOutErr)
+		}
+
 		// Attempt to authenticate the user with the upstream IDP.
-		authenticateResponse, authenticated, err := ldapUpstream.AuthenticateUser(r.Context(), username, password, authorizeRequester.GetGrantedScopes())
+		span := tracing.Start(r.Context(), "upstream LDAP authentication")
+		authenticateResponse, authenticated, err := ldapUpstream.AuthenticateUser(r.Context(), username, password, upstreamScopes(authorizeRequester))
+		tracing.End(span, err)
 		if err != nil {
 			plog.WarningErr("unexpected error during upstream LDAP authentication", err, "upstreamName", ldapUpstream.GetName())
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+				authorizeRequester, ldapUpstream.GetName(), username, err))
 			// There was some problem during authentication with the upstream, aside from bad username/password.
 			// The user may try to log in again if they'd like, so redirect back to the login page with an error.
 			return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
@@ -72,8 +170,16 @@ func NewPostHandler(issuerURL string, upstreamIDPs oidc.UpstreamIdentityProvider
 		if !authenticated {
 			// The upstream did not accept the username/password combination.
 			// The user may try to log in again if they'd like, so redirect back to the login page with an error.
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationFailed,
+				authorizeRequester, ldapUpstream.GetName(), username, downstreamsession.ErrUsernamePasswordNotAccepted))
+			if lockoutTracker.RecordFailure(r.Context(), ldapUpstream.GetName(), string(idpType), username) {
+				auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUserLockedOut,
+					authorizeRequester, ldapUpstream.GetName(), username, downstreamsession.ErrLockedOut))
+				return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowLockedOutErr)
+			}
 			return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowBadUserPassErr)
 		}
+		lockoutTracker.RecordSuccess(r.Context(), ldapUpstream.GetName(), string(idpType), username)
 
 		// We had previously interrupted the regular steps of the OIDC authcode flow to show the login page UI.
 		// Now the upstream IDP has authenticated the user, so now we're back into the regular OIDC authcode flow steps.
@@ -82,11 +188,86 @@ func NewPostHandler(issuerURL string, upstreamIDPs oidc.UpstreamIdentityProvider
 		subject := downstreamsession.DownstreamSubjectFromUpstreamLDAP(ldapUpstream, authenticateResponse)
 		username = authenticateResponse.User.GetName()
 		groups := authenticateResponse.User.GetGroups()
+		// The login policy of the FederationDomain may still deny the user, before they are asked for a second factor.
+		if err := loginPolicy.Evaluate(username, groups, clientip.FromContext(r.Context()).IP, time.Now()); err != nil {
+			plog.Info("login denied by policy", "upstreamName", ldapUpstream.GetName(), "reason", err.Error())
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventLoginPolicyDenied,
+				authorizeRequester, ldapUpstream.GetName(), username, err))
+			return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowPolicyDeniedErr)
+		}
+		if err := clientregistry.CheckRequiredGroups(authorizeRequester.GetClient(), groups); err != nil {
+			auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventClientAccessDenied,
+				authorizeRequester, ldapUpstream.GetName(), username, err))
+			oidc.WriteAuthorizeError(r, w, oauthHelper, authorizeRequester,
+				fosite.ErrAccessDenied.WithHintf("Reason: %s.", err.Error()), false)
+			return nil
+		}
 		customSessionData := downstreamsession.MakeDownstreamLDAPOrADCustomSessionData(ldapUpstream, idpType, authenticateResponse, username)
-		openIDSession := downstreamsession.MakeDownstreamSession(subject, username, groups,
-			authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, map[string]interface{}{})
-		oidc.PerformAuthcodeRedirect(r, w, oauthHelper, authorizeRequester, openIDSession, false)
+		// Users who must verify a second factor get the WebAuthn page instead of an authcode.
+		if webAuthn != nil {
+			if relyingParty == nil {
+				return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
+			}
+			err := webAuthn.startSecondFactor(w, r, relyingParty, r.URL.Path, encodedState, decodedState, &pendingLogin{
+				Subject:           subject,
+				Username:          username,
+				Groups:            groups,
+				CustomSessionData: customSessionData,
+			})
+			if err != nil {
+				plog.WarningErr("unexpected error while starting WebAuthn second factor", err, "upstreamName", ldapUpstream.GetName())
+				return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
+			}
+			return nil
+		}
+		// Only one second factor can be enabled, so otherwise users may get the TOTP page instead of an authcode.
+		if totp != nil {
+			err := totp.startSecondFactor(w, r, totpIssuerName, r.URL.Path, encodedState, decodedState, &pendingLogin{
+				Subject:           subject,
+				Username:          username,
+				Groups:            groups,
+				CustomSessionData: customSessionData,
+			})
+			if err != nil {
+				plog.WarningErr("unexpected error while starting TOTP second factor", err, "upstreamName", ldapUpstream.GetName())
+				return RedirectToLoginPage(r, w, issuerURL, encodedState, ShowInternalError)
+			}
+			return nil
+		}
 
+		finishLogin(r, w, oauthHelper, auditLogger, authorizeRequester, ldapUpstream.GetName(),
+			subject, username, groups, customSessionData)
 		return nil
 	}
 }
+
+// finishLogin responds with the downstream authcode of a user who has completed all steps of the login.
+func finishLogin(
+	r *http.Request,
+	w http.ResponseWriter,
+	oauthHelper fosite.OAuth2Provider,
+	auditLogger auditlog.Logger,
+	authorizeRequester fosite.AuthorizeRequester,
+	upstreamName string,
+	subject string,
+	username string,
+	groups []string,
+	customSessionData *psession.CustomSessionData,
+) {
+	openIDSession := downstreamsession.MakeDownstreamSession(subject, username, groups,
+		authorizeRequester.GetGrantedScopes(), authorizeRequester.GetClient().GetID(), customSessionData, map[string]interface{}{})
+	auditLogger.Emit(downstreamsession.AuditEvent(auditlog.EventUpstreamAuthenticationSucceeded,
+		authorizeRequester, upstreamName, username, nil))
+	tracing.SetAttributes(r.Context(), tracing.SessionIDKey.String(authorizeRequester.GetID()))
+	oidc.PerformAuthcodeRedirect(r, w, oauthHelper, authorizeRequester, openIDSession, false)
+}
+
+// upstreamScopes returns the scopes with which to authenticate the user with the upstream LDAP identity provider.
+// The groups of the user are always needed when the client requires them, even when the client did not request them.
+func upstreamScopes(authorizeRequester fosite.AuthorizeRequester) []string {
+	scopes := authorizeRequester.GetGrantedScopes()
+	if clientregistry.RequiresGroups(authorizeRequester.GetClient()) && !scopes.Has(oidcapi.ScopeGroups) {
+		return append([]string{oidcapi.ScopeGroups}, scopes...)
+	}
+	return scopes
+}
@@ -11,17 +11,22 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/auditlog"
 	"go.pinniped.dev/internal/authenticators"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/lockout"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
 	"go.pinniped.dev/internal/psession"
 	"go.pinniped.dev/internal/testutil"
@@ -56,13 +61,21 @@ func TestPostLoginEndpoint(t *testing.T) {
 		activeDirectoryUpstreamResourceUID = "active-directory-resource-uid"
 		upstreamLDAPURL                    = "ldaps://some-ldap-host:123?base=ou%3Dusers%2Cdc%3Dpinniped%2Cdc%3Ddev"
 
-		userParam                = "username"
-		passParam                = "password"
-		badUserPassErrParamValue = "login_error"
-		internalErrParamValue    = "internal_error"
+		userParam                 = "username"
+		passParam                 = "password"
+		badUserPassErrParamValue  = "login_error"
+		internalErrParamValue     = "internal_error"
+		lockedOutErrParamValue    = "locked_out_error"
+		policyDeniedErrParamValue = "policy_denied_error"
 	)
 
 	var (
+		fositeAccessDeniedWithRequiredGroupsHintErrorQuery = map[string]string{
+			"error":             "access_denied",
+			"error_description": "The resource owner or authorization server denied the request. Reason: the user is not a member of a group required by the client.",
+			"state":             happyDownstreamState,
+		}
+
 		fositeMissingCodeChallengeErrorQuery = map[string]string{
 			"error":             "invalid_request",
 			"error_description": "The request is missing a required parameter, includes an invalid parameter value, includes a parameter more than once, or is otherwise malformed. Clients must include a code_challenge when performing the authorize code flow, but it is missing.",
@@ -247,6 +260,17 @@ func TestPostLoginEndpoint(t *testing.T) {
 		require.NoError(t, kubeClient.Tracker().Add(secret))
 	}
 
+	addDynamicClientWithRequiredGroupsAndSecretToKubeResources := func(requiredGroups ...string) func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+		return func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+			oidcClient, secret := testutil.FullyCapableOIDCClientAndStorageSecret(t,
+				"some-namespace", downstreamDynamicClientID, downstreamDynamicClientUID, downstreamRedirectURI,
+				[]string{testutil.HashedPassword1AtGoMinCost}, oidcclientvalidator.Validate)
+			oidcClient.Spec.RequiredGroups = requiredGroups
+			require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+			require.NoError(t, kubeClient.Tracker().Add(secret))
+		}
+	}
+
 	tests := []struct {
 		name          string
 		idps          *oidctestutil.UpstreamIDPListerBuilder
@@ -255,6 +279,9 @@ func TestPostLoginEndpoint(t *testing.T) {
 		formParams    url.Values
 		reqURIQuery   url.Values
 
+		lockoutTracker *lockout.Tracker
+		loginPolicy    loginpolicy.Config
+
 		wantStatus      int
 		wantContentType string
 		wantBodyString  string
@@ -286,6 +313,9 @@ func TestPostLoginEndpoint(t *testing.T) {
 		// is stored, so it is possible with an LDAP upstream to store objects and then return an error to
 		// the client anyway (which makes the stored objects useless, but oh well).
 		wantUnnecessaryStoredRecords int
+
+		// Assertions for the audit events, which are only checked when set.
+		wantAuditEvents []auditlog.Event
 	}{
 		{
 			name: "happy LDAP login",
@@ -309,6 +339,12 @@ func TestPostLoginEndpoint(t *testing.T) {
 			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
 			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
 			wantDownstreamCustomSessionData:   expectedHappyLDAPUpstreamCustomSession,
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventUpstreamAuthenticationSucceeded,
+				ClientID:         downstreamPinnipedCLIClientID,
+				IdentityProvider: ldapUpstreamName,
+				Username:         happyLDAPUsernameFromAuthenticator,
+			}},
 		},
 		{
 			name: "happy LDAP login with dynamic client",
@@ -334,6 +370,46 @@ func TestPostLoginEndpoint(t *testing.T) {
 			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
 			wantDownstreamCustomSessionData:   expectedHappyLDAPUpstreamCustomSession,
 		},
+		{
+			name:                              "happy LDAP login with dynamic client which requires a group of the user",
+			idps:                              oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			kubeResources:                     addDynamicClientWithRequiredGroupsAndSecretToKubeResources("admins", happyLDAPGroups[1]),
+			decodedState:                      happyLDAPDecodedStateForDynamicClient,
+			formParams:                        happyUsernamePasswordFormParams,
+			wantStatus:                        http.StatusSeeOther,
+			wantContentType:                   htmlContentType,
+			wantBodyString:                    "",
+			wantRedirectLocationRegexp:        happyAuthcodeDownstreamRedirectLocationRegexp,
+			wantDownstreamIDTokenSubject:      upstreamLDAPURL + "&sub=" + happyLDAPUID,
+			wantDownstreamIDTokenUsername:     happyLDAPUsernameFromAuthenticator,
+			wantDownstreamIDTokenGroups:       happyLDAPGroups,
+			wantDownstreamRequestedScopes:     happyDownstreamScopesRequested,
+			wantDownstreamRedirectURI:         downstreamRedirectURI,
+			wantDownstreamGrantedScopes:       happyDownstreamScopesGranted,
+			wantDownstreamNonce:               downstreamNonce,
+			wantDownstreamClient:              downstreamDynamicClientID,
+			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
+			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
+			wantDownstreamCustomSessionData:   expectedHappyLDAPUpstreamCustomSession,
+		},
+		{
+			name:                       "LDAP login with dynamic client which requires a group of which the user is not a member",
+			idps:                       oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			kubeResources:              addDynamicClientWithRequiredGroupsAndSecretToKubeResources("admins"),
+			decodedState:               happyLDAPDecodedStateForDynamicClient,
+			formParams:                 happyUsernamePasswordFormParams,
+			wantStatus:                 http.StatusSeeOther,
+			wantContentType:            htmlContentType,
+			wantBodyString:             "",
+			wantRedirectLocationString: urlWithQuery(downstreamRedirectURI, fositeAccessDeniedWithRequiredGroupsHintErrorQuery),
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventClientAccessDenied,
+				ClientID:         downstreamDynamicClientID,
+				IdentityProvider: ldapUpstreamName,
+				Username:         happyLDAPUsernameFromAuthenticator,
+				Error:            "the user is not a member of a group required by the client",
+			}},
+		},
 		{
 			name: "happy AD login",
 			idps: oidctestutil.NewUpstreamIDPListerBuilder().
@@ -654,6 +730,109 @@ func TestPostLoginEndpoint(t *testing.T) {
 			wantContentType:              htmlContentType,
 			wantBodyString:               "",
 			wantRedirectToLoginPageError: badUserPassErrParamValue,
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventUpstreamAuthenticationFailed,
+				ClientID:         downstreamPinnipedCLIClientID,
+				IdentityProvider: ldapUpstreamName,
+				Username:         happyLDAPUsername,
+				Error:            "username/password not accepted by upstream provider",
+			}},
+		},
+		{
+			name:                         "correct password LDAP login which is denied by the login policy",
+			idps:                         oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			decodedState:                 happyLDAPDecodedState,
+			formParams:                   happyUsernamePasswordFormParams,
+			loginPolicy:                  loginpolicy.Config{AllowedGroups: []string{"admins"}},
+			wantStatus:                   http.StatusSeeOther,
+			wantContentType:              htmlContentType,
+			wantBodyString:               "",
+			wantRedirectToLoginPageError: policyDeniedErrParamValue,
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventLoginPolicyDenied,
+				ClientID:         downstreamPinnipedCLIClientID,
+				IdentityProvider: ldapUpstreamName,
+				Username:         happyLDAPUsernameFromAuthenticator,
+				Error:            "login denied by policy: the user is not a member of an allowed group",
+			}},
+		},
+		{
+			name:                              "correct password LDAP login which is allowed by the login policy",
+			idps:                              oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			decodedState:                      happyLDAPDecodedState,
+			formParams:                        happyUsernamePasswordFormParams,
+			loginPolicy:                       loginpolicy.Config{AllowedGroups: happyLDAPGroups[:1]},
+			wantStatus:                        http.StatusSeeOther,
+			wantContentType:                   htmlContentType,
+			wantBodyString:                    "",
+			wantRedirectLocationRegexp:        happyAuthcodeDownstreamRedirectLocationRegexp,
+			wantDownstreamIDTokenSubject:      upstreamLDAPURL + "&sub=" + happyLDAPUID,
+			wantDownstreamIDTokenUsername:     happyLDAPUsernameFromAuthenticator,
+			wantDownstreamIDTokenGroups:       happyLDAPGroups,
+			wantDownstreamRequestedScopes:     happyDownstreamScopesRequested,
+			wantDownstreamRedirectURI:         downstreamRedirectURI,
+			wantDownstreamGrantedScopes:       happyDownstreamScopesGranted,
+			wantDownstreamNonce:               downstreamNonce,
+			wantDownstreamClient:              downstreamPinnipedCLIClientID,
+			wantDownstreamPKCEChallenge:       downstreamPKCEChallenge,
+			wantDownstreamPKCEChallengeMethod: downstreamPKCEChallengeMethod,
+			wantDownstreamCustomSessionData:   expectedHappyLDAPUpstreamCustomSession,
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventUpstreamAuthenticationSucceeded,
+				ClientID:         downstreamPinnipedCLIClientID,
+				IdentityProvider: ldapUpstreamName,
+				Username:         happyLDAPUsernameFromAuthenticator,
+			}},
+		},
+		{
+			name:                         "bad password LDAP login which reaches the max failed attempts",
+			idps:                         oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			decodedState:                 happyLDAPDecodedState,
+			formParams:                   url.Values{userParam: []string{happyLDAPUsername}, passParam: []string{"wrong!"}},
+			lockoutTracker:               lockout.New(lockout.Config{MaxFailedAttempts: 1}, clocktesting.NewFakeClock(time.Now())),
+			wantStatus:                   http.StatusSeeOther,
+			wantContentType:              htmlContentType,
+			wantBodyString:               "",
+			wantRedirectToLoginPageError: lockedOutErrParamValue,
+			wantAuditEvents: []auditlog.Event{
+				{
+					Type:             auditlog.EventUpstreamAuthenticationFailed,
+					ClientID:         downstreamPinnipedCLIClientID,
+					IdentityProvider: ldapUpstreamName,
+					Username:         happyLDAPUsername,
+					Error:            "username/password not accepted by upstream provider",
+				},
+				{
+					Type:             auditlog.EventUserLockedOut,
+					ClientID:         downstreamPinnipedCLIClientID,
+					IdentityProvider: ldapUpstreamName,
+					Username:         happyLDAPUsername,
+					Error:            "too many failed login attempts for this username",
+				},
+			},
+		},
+		{
+			name:         "correct password LDAP login for a locked out user",
+			idps:         oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider),
+			decodedState: happyLDAPDecodedState,
+			formParams:   happyUsernamePasswordFormParams,
+			lockoutTracker: func() *lockout.Tracker {
+				tracker := lockout.New(lockout.Config{MaxFailedAttempts: 2}, clocktesting.NewFakeClock(time.Now()))
+				tracker.RecordFailure(context.Background(), ldapUpstreamName, ldapUpstreamType, happyLDAPUsername)
+				tracker.RecordFailure(context.Background(), ldapUpstreamName, ldapUpstreamType, happyLDAPUsername)
+				return tracker
+			}(),
+			wantStatus:                   http.StatusSeeOther,
+			wantContentType:              htmlContentType,
+			wantBodyString:               "",
+			wantRedirectToLoginPageError: lockedOutErrParamValue,
+			wantAuditEvents: []auditlog.Event{{
+				Type:             auditlog.EventUpstreamAuthenticationFailed,
+				ClientID:         downstreamPinnipedCLIClientID,
+				IdentityProvider: ldapUpstreamName,
+				Username:         happyLDAPUsername,
+				Error:            "too many failed login attempts for this username",
+			}},
 		},
 		{
 			name:                         "blank username LDAP login",
@@ -988,9 +1167,13 @@ func TestPostLoginEndpoint(t *testing.T) {
 
 			rsp := httptest.NewRecorder()
 
-			subject := NewPostHandler(downstreamIssuer, tt.idps.Build(), oauthHelper)
+			loginPolicy, err := loginpolicy.New(tt.loginPolicy)
+			require.NoError(t, err)
+
+			auditRecorder := &testutil.AuditRecorder{}
+			subject := NewPostHandler(downstreamIssuer, tt.idps.Build(), oauthHelper, tt.lockoutTracker, nil, nil, loginPolicy, auditRecorder)
 
-			err := subject(rsp, req, happyEncodedUpstreamState, tt.decodedState)
+			err = subject(rsp, req, happyEncodedUpstreamState, tt.decodedState)
 			if tt.wantErr != "" {
 				require.EqualError(t, err, tt.wantErr)
 				require.Empty(t, oidctestutil.FilterClientSecretCreateActions(kubeClient.Actions()))
@@ -1069,6 +1252,10 @@ func TestPostLoginEndpoint(t *testing.T) {
 				require.Failf(t, "test should have expected a redirect or form body",
 					"actual location was %q", actualLocation)
 			}
+
+			if tt.wantAuditEvents != nil {
+				auditRecorder.RequireEventsWithRandomSessionIDs(t, tt.wantAuditEvents)
+			}
 		})
 	}
 }
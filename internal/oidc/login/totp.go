@@ -0,0 +1,236 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package login
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/downstreamsession"
+	"go.pinniped.dev/internal/oidc/login/loginhtml"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/qrcode"
+	"go.pinniped.dev/internal/totp"
+	"go.pinniped.dev/internal/totpsecretstorage"
+)
+
+const (
+	totpLoginParamName = "totp_login"
+	codeParamName      = "code"
+
+	// TOTPLoginTypeLabelValue is the storage type of the logins which wait for the TOTP second factor.
+	TOTPLoginTypeLabelValue = "totp-login"
+
+	// totpLoginLifetime is how long a user has to enter a TOTP code after logging in with their password.
+	totpLoginLifetime = 5 * time.Minute
+
+	// Version 1 was the initial release of the TOTP second factor.
+	totpLoginStorageVersion = "1"
+)
+
+// TOTP is the TOTP second factor of the password logins of a FederationDomain. A nil *TOTP disables the second factor.
+type TOTP struct {
+	enforcement provider.TOTPEnforcement
+	secrets     *totpsecretstorage.TOTPSecretStorage
+	logins      crud.Storage
+	clock       func() time.Time
+}
+
+// NewTOTP returns the TOTP second factor with the given enforcement, or nil when the enforcement disables the second
+// factor. The enrolled secrets are kept in the secret storage, and the logins which wait for the second factor are
+// kept in the session storage.
+func NewTOTP(
+	enforcement provider.TOTPEnforcement,
+	secrets *totpsecretstorage.TOTPSecretStorage,
+	sessionStorage crud.Backend,
+	clock func() time.Time,
+) *TOTP {
+	if enforcement == provider.TOTPEnforcementNone {
+		return nil
+	}
+	return &TOTP{
+		enforcement: enforcement,
+		secrets:     secrets,
+		logins:      sessionStorage.New(TOTPLoginTypeLabelValue, clock, totpLoginLifetime),
+		clock:       clock,
+	}
+}
+
+// RequiresSecondFactor returns whether the user with the given downstream subject must enter a TOTP code after
+// logging in with their password. It may be called on a nil *TOTP.
+func (a *TOTP) RequiresSecondFactor(ctx context.Context, subject string) (bool, error) {
+	if a == nil {
+		return false, nil
+	}
+	if a.enforcement == provider.TOTPEnforcementRequired {
+		return true, nil
+	}
+	_, enrolled, err := a.secrets.Get(ctx, subject)
+	if err != nil {
+		return false, err
+	}
+	return enrolled != nil, nil
+}
+
+// startSecondFactor stores the login of a user who has verified their password, and renders the page which asks the
+// user to enter a TOTP code, or to enroll a new TOTP secret with their authenticator app. The issuer name is shown by
+// the authenticator app next to the codes.
+func (a *TOTP) startSecondFactor(
+	w http.ResponseWriter,
+	r *http.Request,
+	issuerName string,
+	postPath string,
+	encodedState string,
+	decodedState *oidc.UpstreamStateParamData,
+	login *pendingLogin,
+) error {
+	_, enrolled, err := a.secrets.Get(r.Context(), login.Subject)
+	if err != nil {
+		return err
+	}
+
+	login.Enroll = enrolled == nil
+	login.Optional = a.enforcement == provider.TOTPEnforcementIfEnrolled && login.Enroll
+
+	pageData := &loginhtml.TOTPPageData{
+		State:    encodedState,
+		IDPName:  decodedState.UpstreamName,
+		PostPath: postPath,
+		Enroll:   login.Enroll,
+		Optional: login.Optional,
+	}
+
+	// The secret to enroll is only stored with the login until the user has proven that their authenticator app
+	// generates the right codes for it.
+	if login.Enroll {
+		if login.TOTPSecret, err = totp.NewSecret(); err != nil {
+			return err
+		}
+		pageData.Secret = totp.EncodeSecret(login.TOTPSecret)
+		qrCode, err := qrcode.Encode([]byte(totp.ProvisioningURL(login.TOTPSecret, issuerName, login.Username)))
+		switch {
+		case errors.Is(err, qrcode.ErrDataTooLong):
+			// Very long usernames do not fit in a QR code, but the user can still enter the secret by hand.
+			plog.Debug("TOTP provisioning URL does not fit in a QR code", "err", err)
+		case err != nil:
+			return err
+		default:
+			pageData.QRCode = template.HTML(qrCode.SVG()) //nolint:gosec // The SVG only contains numbers generated by the encoder.
+		}
+	}
+
+	if pageData.LoginID, err = newLoginID(); err != nil {
+		return err
+	}
+	login.Nonce = string(decodedState.Nonce)
+	login.CreationTimestamp = metav1.NewTime(a.clock())
+	login.Version = totpLoginStorageVersion
+	if _, err := a.logins.Create(r.Context(), pageData.LoginID, login, nil, nil); err != nil {
+		return fmt.Errorf("could not store login: %w", err)
+	}
+
+	// The CSP header which was set for the POST request also works for this page, because it has no script.
+	return loginhtml.TOTPTemplate().Execute(w, pageData)
+}
+
+// finishSecondFactor verifies the code which the user entered on the page rendered by startSecondFactor, and returns
+// the login of the user when the user has entered a valid code, or skipped the optional enrollment. Errors which are
+// caused by the request wrap downstreamsession.ErrSecondFactorNotVerified.
+func (a *TOTP) finishSecondFactor(
+	r *http.Request,
+	loginID string,
+	decodedState *oidc.UpstreamStateParamData,
+) (*pendingLogin, error) {
+	ctx := r.Context()
+
+	login := &pendingLogin{}
+	if _, err := a.logins.Get(ctx, loginID, login); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: login not found", downstreamsession.ErrSecondFactorNotVerified)
+		}
+		return nil, fmt.Errorf("could not get login: %w", err)
+	}
+	// Each login may only be finished once, whether it succeeds or not, so a code cannot be guessed by brute force.
+	if err := a.logins.Delete(ctx, loginID); err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not delete login: %w", err)
+	}
+	switch {
+	case login.Version != totpLoginStorageVersion:
+		return nil, fmt.Errorf("%w: login has version %s instead of %s",
+			downstreamsession.ErrSecondFactorNotVerified, login.Version, totpLoginStorageVersion)
+	case login.Nonce != string(decodedState.Nonce):
+		return nil, fmt.Errorf("%w: login belongs to another authorization request", downstreamsession.ErrSecondFactorNotVerified)
+	case a.clock().After(login.CreationTimestamp.Add(totpLoginLifetime)):
+		return nil, fmt.Errorf("%w: login expired", downstreamsession.ErrSecondFactorNotVerified)
+	}
+
+	if login.Optional && r.PostFormValue(skipParamName) != "" {
+		return login, nil
+	}
+
+	var err error
+	if login.Enroll {
+		err = a.enroll(r, login)
+	} else {
+		err = a.verify(r, login)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return login, nil
+}
+
+func (a *TOTP) enroll(r *http.Request, login *pendingLogin) error {
+	now := a.clock()
+	counter, err := totp.Validate(login.TOTPSecret, r.PostFormValue(codeParamName), now, 0)
+	if err != nil {
+		return fmt.Errorf("%w: %s", downstreamsession.ErrSecondFactorNotVerified, err.Error())
+	}
+
+	// Another login of the same user may have enrolled a secret in the meantime, which this one replaces.
+	rv, _, err := a.secrets.Get(r.Context(), login.Subject)
+	if err != nil {
+		return err
+	}
+	return a.secrets.Set(r.Context(), rv, &totpsecretstorage.TOTP{
+		Subject:           login.Subject,
+		Username:          login.Username,
+		Secret:            login.TOTPSecret,
+		LastCounter:       counter,
+		CreationTimestamp: metav1.NewTime(now),
+	})
+}
+
+func (a *TOTP) verify(r *http.Request, login *pendingLogin) error {
+	rv, enrolled, err := a.secrets.Get(r.Context(), login.Subject)
+	if err != nil {
+		return err
+	}
+	if enrolled == nil {
+		// The secret of the user was reset after the login started.
+		return fmt.Errorf("%w: no TOTP secret is enrolled", downstreamsession.ErrSecondFactorNotVerified)
+	}
+
+	now := a.clock()
+	counter, err := totp.Validate(enrolled.Secret, r.PostFormValue(codeParamName), now, enrolled.LastCounter)
+	if err != nil {
+		return fmt.Errorf("%w: %s", downstreamsession.ErrSecondFactorNotVerified, err.Error())
+	}
+
+	// Remember the counter of the code, so it cannot be used again.
+	lastUsed := metav1.NewTime(now)
+	enrolled.LastCounter = counter
+	enrolled.LastUsedTimestamp = &lastUsed
+	return a.secrets.Set(r.Context(), rv, enrolled)
+}
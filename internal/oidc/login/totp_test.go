@@ -0,0 +1,348 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package login
+
+import (
+	"context"
+	"encoding/base32"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+	"go.pinniped.dev/internal/totp"
+	"go.pinniped.dev/internal/totpsecretstorage"
+)
+
+// totpPage is what the user reads from the page which asks for the TOTP code.
+type totpPage struct {
+	loginID  string
+	enroll   bool
+	optional bool
+	secret   []byte
+	qrCode   bool
+}
+
+func parseTOTPPage(t *testing.T, rsp *httptest.ResponseRecorder) *totpPage {
+	t.Helper()
+	require.Equal(t, http.StatusOK, rsp.Code)
+	body := rsp.Body.String()
+	submatches := regexp.MustCompile(`name="totp_login" id="totp_login" value="([^"]+)"`).FindStringSubmatch(body)
+	require.Len(t, submatches, 2, "no login ID in %s", body)
+	page := &totpPage{
+		loginID:  submatches[1],
+		optional: strings.Contains(body, `name="skip"`),
+		qrCode:   strings.Contains(body, `<svg xmlns="http://www.w3.org/2000/svg"`),
+	}
+	if submatches := regexp.MustCompile(`<code id="secret">([A-Z2-7]+)</code>`).FindStringSubmatch(body); submatches != nil {
+		page.enroll = true
+		var err error
+		page.secret, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(submatches[1])
+		require.NoError(t, err)
+	}
+	return page
+}
+
+func TestPostLoginEndpointWithTOTP(t *testing.T) {
+	const (
+		downstreamIssuer  = "https://my-downstream-issuer.com/path"
+		ldapUpstreamName  = "some-ldap-idp"
+		happyUsername     = "some-ldap-user"
+		happyPassword     = "some-ldap-password" //nolint:gosec
+		mappedUsername    = "some-mapped-ldap-username"
+		happyEncodedState = "fake-encoded-state-param-value"
+		totpErrorPath     = downstreamIssuer + oidc.PinnipedLoginPath + "?err=totp_error&state=" + happyEncodedState
+		happyRedirect     = `^http://127\.0\.0\.1/callback\?code=pin_ac_[^&]+&scope=openid\+username\+groups&state=8b-state$`
+	)
+
+	parsedUpstreamLDAPURL, err := url.Parse("ldaps://some-ldap-host:123?base=ou%3Dusers%2Cdc%3Dpinniped%2Cdc%3Ddev")
+	require.NoError(t, err)
+	upstreamLDAPIdentityProvider := oidctestutil.TestUpstreamLDAPIdentityProvider{
+		Name:        ldapUpstreamName,
+		ResourceUID: "ldap-resource-uid",
+		URL:         parsedUpstreamLDAPURL,
+		AuthenticateFunc: func(ctx context.Context, username, password string) (*authenticators.Response, bool, error) {
+			if username != happyUsername || password != happyPassword {
+				return nil, false, nil
+			}
+			return &authenticators.Response{
+				User: &user.DefaultInfo{Name: mappedUsername, UID: "some-ldap-uid", Groups: []string{"group1"}},
+				DN:   "cn=foo,dn=bar",
+			}, true, nil
+		},
+	}
+	happySubject := "ldaps://some-ldap-host:123?base=ou%3Dusers%2Cdc%3Dpinniped%2Cdc%3Ddev&sub=some-ldap-uid"
+
+	decodedState := &oidc.UpstreamStateParamData{
+		AuthParams: url.Values{
+			"response_type":         {"code"},
+			"scope":                 {"openid username groups"},
+			"client_id":             {"pinniped-cli"},
+			"state":                 {"8b-state"},
+			"nonce":                 {"some-nonce-value"},
+			"code_challenge":        {"some-challenge"},
+			"code_challenge_method": {"S256"},
+			"redirect_uri":          {"http://127.0.0.1/callback"},
+		}.Encode(),
+		UpstreamName:  ldapUpstreamName,
+		UpstreamType:  "ldap",
+		Nonce:         "test-nonce",
+		CSRFToken:     "test-csrf",
+		PKCECode:      "test-pkce",
+		FormatVersion: "2",
+	}
+
+	type fixture struct {
+		handler       HandlerFunc
+		secrets       *totpsecretstorage.TOTPSecretStorage
+		auditRecorder *testutil.AuditRecorder
+		now           time.Time
+	}
+	setup := func(t *testing.T, enforcement provider.TOTPEnforcement) *fixture {
+		t.Helper()
+		kubeClient := fake.NewSimpleClientset()
+		// The fake client does not assign resource versions, which the secret storage needs to update Secrets.
+		resourceVersion := 0
+		kubeClient.PrependReactor("*", "secrets", func(action coretesting.Action) (bool, runtime.Object, error) {
+			if objectAction, ok := action.(interface{ GetObject() runtime.Object }); ok {
+				resourceVersion++
+				objectAction.GetObject().(*corev1.Secret).ResourceVersion = strconv.Itoa(resourceVersion)
+			}
+			return false, nil, nil
+		})
+		secretsClient := kubeClient.CoreV1().Secrets("some-namespace")
+		oidcClientsClient := supervisorfake.NewSimpleClientset().ConfigV1alpha1().OIDCClients("some-namespace")
+		timeoutsConfiguration := oidc.DefaultOIDCTimeoutsConfiguration()
+		kubeOauthStore := oidc.NewKubeStorage(secretsClient, oidcClientsClient, timeoutsConfiguration, bcrypt.MinCost)
+		hmacSecretFunc := func() []byte { return []byte("some secret - must have at least 32 bytes") }
+		oauthHelper := oidc.FositeOauth2Helper(kubeOauthStore, downstreamIssuer, hmacSecretFunc, jwks.NewDynamicJWKSProvider(), timeoutsConfiguration)
+
+		f := &fixture{
+			secrets:       totpsecretstorage.New(secretsClient, func() []byte { return []byte("some-neato-32-byte-generated-key") }),
+			auditRecorder: &testutil.AuditRecorder{},
+			now:           time.Now(),
+		}
+		f.handler = NewPostHandler(
+			downstreamIssuer,
+			oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider).Build(),
+			oauthHelper,
+			nil,
+			nil,
+			NewTOTP(enforcement, f.secrets, crud.NewSecretsBackend(secretsClient), func() time.Time { return f.now }),
+			nil,
+			f.auditRecorder,
+		)
+		return f
+	}
+
+	post := func(t *testing.T, f *fixture, state *oidc.UpstreamStateParamData, form url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/path/login", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rsp := httptest.NewRecorder()
+		require.NoError(t, f.handler(rsp, req, happyEncodedState, state))
+		return rsp
+	}
+
+	passwordForm := url.Values{"username": {happyUsername}, "password": {happyPassword}}
+
+	codeForm := func(page *totpPage, code string) url.Values {
+		return url.Values{totpLoginParamName: {page.loginID}, codeParamName: {code}}
+	}
+
+	requireAuthcodeRedirect := func(t *testing.T, rsp *httptest.ResponseRecorder) {
+		t.Helper()
+		require.Equal(t, http.StatusSeeOther, rsp.Code)
+		require.Regexp(t, happyRedirect, rsp.Header().Get("Location"))
+	}
+
+	requireTOTPError := func(t *testing.T, rsp *httptest.ResponseRecorder) {
+		t.Helper()
+		require.Equal(t, http.StatusSeeOther, rsp.Code)
+		require.Equal(t, totpErrorPath, rsp.Header().Get("Location"))
+	}
+
+	t.Run("required second factor is enrolled and then used", func(t *testing.T) {
+		f := setup(t, provider.TOTPEnforcementRequired)
+
+		page := parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		require.True(t, page.enroll)
+		require.False(t, page.optional)
+		require.True(t, page.qrCode)
+		require.Len(t, page.secret, totp.SecretLength)
+		require.Empty(t, f.auditRecorder.Events(), "the login is not finished yet")
+
+		requireAuthcodeRedirect(t, post(t, f, decodedState, codeForm(page, totp.Code(page.secret, totp.Counter(f.now)))))
+		f.auditRecorder.RequireEventsWithRandomSessionIDs(t, []auditlog.Event{
+			{Type: auditlog.EventUpstreamAuthenticationSucceeded, ClientID: "pinniped-cli", IdentityProvider: ldapUpstreamName, Username: mappedUsername},
+		})
+
+		_, enrolled, err := f.secrets.Get(context.Background(), happySubject)
+		require.NoError(t, err)
+		require.Equal(t, page.secret, enrolled.Secret)
+		require.Equal(t, mappedUsername, enrolled.Username)
+		require.Equal(t, totp.Counter(f.now), enrolled.LastCounter)
+		require.Nil(t, enrolled.LastUsedTimestamp)
+
+		// Now the user has a secret, so the next login asks for a code.
+		page = parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		require.False(t, page.enroll)
+		require.False(t, page.optional)
+
+		// The code which was used for the enrollment cannot be used again.
+		requireTOTPError(t, post(t, f, decodedState, codeForm(page, totp.Code(enrolled.Secret, totp.Counter(f.now)))))
+		require.Equal(t, "second factor not verified: code was already used", f.auditRecorder.Events()[1].Error)
+
+		f.now = f.now.Add(totp.Period)
+		page = parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		form := codeForm(page, totp.Code(enrolled.Secret, totp.Counter(f.now)))
+		requireAuthcodeRedirect(t, post(t, f, decodedState, form))
+
+		_, enrolled, err = f.secrets.Get(context.Background(), happySubject)
+		require.NoError(t, err)
+		require.Equal(t, totp.Counter(f.now), enrolled.LastCounter)
+		require.NotNil(t, enrolled.LastUsedTimestamp)
+
+		// Each login can only be finished once.
+		requireTOTPError(t, post(t, f, decodedState, form))
+	})
+
+	t.Run("wrong code is rejected", func(t *testing.T) {
+		f := setup(t, provider.TOTPEnforcementRequired)
+
+		page := parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		requireTOTPError(t, post(t, f, decodedState, codeForm(page, "000000")))
+		f.auditRecorder.RequireEventsWithRandomSessionIDs(t, []auditlog.Event{
+			{
+				Type:             auditlog.EventUpstreamAuthenticationFailed,
+				ClientID:         "pinniped-cli",
+				IdentityProvider: ldapUpstreamName,
+				Error:            "second factor not verified: code is invalid",
+			},
+		})
+
+		// A failed enrollment does not store the secret.
+		_, enrolled, err := f.secrets.Get(context.Background(), happySubject)
+		require.NoError(t, err)
+		require.Nil(t, enrolled)
+	})
+
+	t.Run("required second factor cannot be skipped", func(t *testing.T) {
+		f := setup(t, provider.TOTPEnforcementRequired)
+
+		page := parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		requireTOTPError(t, post(t, f, decodedState, url.Values{totpLoginParamName: {page.loginID}, skipParamName: {"Skip for now"}}))
+	})
+
+	t.Run("optional second factor can be skipped until it is enrolled", func(t *testing.T) {
+		f := setup(t, provider.TOTPEnforcementIfEnrolled)
+
+		page := parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		require.True(t, page.enroll)
+		require.True(t, page.optional)
+
+		requireAuthcodeRedirect(t, post(t, f, decodedState, url.Values{totpLoginParamName: {page.loginID}, skipParamName: {"Skip for now"}}))
+		_, enrolled, err := f.secrets.Get(context.Background(), happySubject)
+		require.NoError(t, err)
+		require.Nil(t, enrolled)
+
+		page = parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		requireAuthcodeRedirect(t, post(t, f, decodedState, codeForm(page, totp.Code(page.secret, totp.Counter(f.now)))))
+
+		// Once enrolled, the second factor is no longer optional.
+		page = parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		require.False(t, page.enroll)
+		require.False(t, page.optional)
+		requireTOTPError(t, post(t, f, decodedState, url.Values{totpLoginParamName: {page.loginID}, skipParamName: {"Skip for now"}}))
+	})
+
+	t.Run("secret which was reset during the login cannot be used", func(t *testing.T) {
+		f := setup(t, provider.TOTPEnforcementRequired)
+		secret := []byte("12345678901234567890")
+		require.NoError(t, f.secrets.Set(context.Background(), "", &totpsecretstorage.TOTP{Subject: happySubject, Secret: secret}))
+
+		page := parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		require.False(t, page.enroll)
+		require.NoError(t, f.secrets.Delete(context.Background(), happySubject))
+
+		requireTOTPError(t, post(t, f, decodedState, codeForm(page, totp.Code(secret, totp.Counter(f.now)))))
+		require.Equal(t, "second factor not verified: no TOTP secret is enrolled", f.auditRecorder.Events()[0].Error)
+	})
+
+	t.Run("expired login is rejected", func(t *testing.T) {
+		f := setup(t, provider.TOTPEnforcementRequired)
+
+		page := parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+		f.now = f.now.Add(totpLoginLifetime + time.Second)
+		requireTOTPError(t, post(t, f, decodedState, codeForm(page, totp.Code(page.secret, totp.Counter(f.now)))))
+	})
+
+	t.Run("login cannot be finished by another authorization request", func(t *testing.T) {
+		f := setup(t, provider.TOTPEnforcementRequired)
+		page := parseTOTPPage(t, post(t, f, decodedState, passwordForm))
+
+		otherState := *decodedState
+		otherState.Nonce = "other-nonce"
+		requireTOTPError(t, post(t, f, &otherState, codeForm(page, totp.Code(page.secret, totp.Counter(f.now)))))
+	})
+
+	t.Run("unknown login is rejected", func(t *testing.T) {
+		f := setup(t, provider.TOTPEnforcementRequired)
+		requireTOTPError(t, post(t, f, decodedState, url.Values{totpLoginParamName: {"some-unknown-login"}}))
+	})
+
+	t.Run("second factor is not used when it is not enforced", func(t *testing.T) {
+		f := setup(t, provider.TOTPEnforcementNone)
+		requireAuthcodeRedirect(t, post(t, f, decodedState, passwordForm))
+		require.Equal(t, http.StatusSeeOther, post(t, f, decodedState, url.Values{totpLoginParamName: {"some-login"}}).Code)
+	})
+}
+
+func TestTOTPRequiresSecondFactor(t *testing.T) {
+	ctx := context.Background()
+	secretsClient := fake.NewSimpleClientset().CoreV1().Secrets("some-namespace")
+	secrets := totpsecretstorage.New(secretsClient, func() []byte { return []byte("some-neato-32-byte-generated-key") })
+	backend := crud.NewSecretsBackend(secretsClient)
+	require.NoError(t, secrets.Set(ctx, "", &totpsecretstorage.TOTP{Subject: "enrolled-subject", Secret: []byte("some-secret")}))
+
+	var disabled *TOTP
+	require.Nil(t, NewTOTP(provider.TOTPEnforcementNone, secrets, backend, time.Now))
+	requires, err := disabled.RequiresSecondFactor(ctx, "enrolled-subject")
+	require.NoError(t, err)
+	require.False(t, requires)
+
+	required := NewTOTP(provider.TOTPEnforcementRequired, secrets, backend, time.Now)
+	requires, err = required.RequiresSecondFactor(ctx, "other-subject")
+	require.NoError(t, err)
+	require.True(t, requires)
+
+	ifEnrolled := NewTOTP(provider.TOTPEnforcementIfEnrolled, secrets, backend, time.Now)
+	requires, err = ifEnrolled.RequiresSecondFactor(ctx, "enrolled-subject")
+	require.NoError(t, err)
+	require.True(t, requires)
+	requires, err = ifEnrolled.RequiresSecondFactor(ctx, "other-subject")
+	require.NoError(t, err)
+	require.False(t, requires)
+}
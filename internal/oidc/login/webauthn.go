@@ -0,0 +1,319 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package login
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/downstreamsession"
+	"go.pinniped.dev/internal/oidc/login/loginhtml"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/webauthn"
+	"go.pinniped.dev/internal/webauthncredentialstorage"
+)
+
+const (
+	webAuthnLoginParamName      = "webauthn_login"
+	credentialIDParamName       = "credential_id"
+	clientDataParamName         = "client_data"
+	authenticatorDataParamName  = "authenticator_data"
+	signatureParamName          = "signature"
+	publicKeyParamName          = "public_key"
+	publicKeyAlgorithmParamName = "public_key_algorithm"
+	skipParamName               = "skip"
+
+	// WebAuthnLoginTypeLabelValue is the storage type of the logins which wait for the WebAuthn second factor.
+	WebAuthnLoginTypeLabelValue = "webauthn-login"
+
+	// webAuthnLoginLifetime is how long a user has to use their security key after logging in with their password.
+	webAuthnLoginLifetime = 5 * time.Minute
+
+	// Version 1 was the initial release of the WebAuthn second factor.
+	webAuthnLoginStorageVersion = "1"
+)
+
+// WebAuthn is the WebAuthn second factor of the password logins of a FederationDomain. A nil *WebAuthn disables the
+// second factor.
+type WebAuthn struct {
+	enforcement provider.WebAuthnEnforcement
+	credentials *webauthncredentialstorage.WebAuthnCredentialStorage
+	logins      crud.Storage
+	clock       func() time.Time
+}
+
+// pendingLogin is the login of a user who has verified their password, but not yet their second factor.
+type pendingLogin struct {
+	// The nonce of the state param, which binds the pending login to the authorization request.
+	Nonce             string                      `json:"nonce"`
+	Subject           string                      `json:"subject"`
+	Username          string                      `json:"username"`
+	Groups            []string                    `json:"groups"`
+	CustomSessionData *psession.CustomSessionData `json:"customSessionData"`
+	// The WebAuthn challenge.
+	Challenge []byte `json:"challenge"`
+	// The TOTP secret which the user is enrolling.
+	TOTPSecret []byte `json:"totpSecret,omitempty"`
+	// Whether the user must enroll a new credential or secret instead of verifying an enrolled one.
+	Enroll bool `json:"enroll"`
+	// Whether the user may skip the enrollment.
+	Optional          bool        `json:"optional"`
+	CreationTimestamp metav1.Time `json:"created"`
+	Version           string      `json:"version"`
+}
+
+// NewWebAuthn returns the WebAuthn second factor with the given enforcement, or nil when the enforcement disables the
+// second factor. The enrolled credentials are kept in the credential storage, and the logins which wait for the
+// second factor are kept in the session storage.
+func NewWebAuthn(
+	enforcement provider.WebAuthnEnforcement,
+	credentials *webauthncredentialstorage.WebAuthnCredentialStorage,
+	sessionStorage crud.Backend,
+	clock func() time.Time,
+) *WebAuthn {
+	if enforcement == provider.WebAuthnEnforcementNone {
+		return nil
+	}
+	return &WebAuthn{
+		enforcement: enforcement,
+		credentials: credentials,
+		logins:      sessionStorage.New(WebAuthnLoginTypeLabelValue, clock, webAuthnLoginLifetime),
+		clock:       clock,
+	}
+}
+
+// RequiresSecondFactor returns whether the user with the given downstream subject must verify a WebAuthn credential
+// after logging in with their password. It may be called on a nil *WebAuthn.
+func (a *WebAuthn) RequiresSecondFactor(ctx context.Context, subject string) (bool, error) {
+	if a == nil {
+		return false, nil
+	}
+	if a.enforcement == provider.WebAuthnEnforcementRequired {
+		return true, nil
+	}
+	_, credentials, err := a.credentials.Get(ctx, subject)
+	if err != nil {
+		return false, err
+	}
+	return len(credentials) > 0, nil
+}
+
+// startSecondFactor stores the login of a user who has verified their password, and renders the page which asks the
+// user to use or to enroll a security key.
+func (a *WebAuthn) startSecondFactor(
+	w http.ResponseWriter,
+	r *http.Request,
+	rp *webauthn.RelyingParty,
+	postPath string,
+	encodedState string,
+	decodedState *oidc.UpstreamStateParamData,
+	login *pendingLogin,
+) error {
+	_, credentials, err := a.credentials.Get(r.Context(), login.Subject)
+	if err != nil {
+		return err
+	}
+
+	// Credentials are bound to a hostname, so a user who has enrolled a credential for another host of the
+	// FederationDomain must enroll another one for this host, and cannot skip that.
+	var credentialIDs []string
+	for _, credential := range credentials {
+		if credential.RPID == rp.ID {
+			credentialIDs = append(credentialIDs, base64.RawURLEncoding.EncodeToString(credential.ID))
+		}
+	}
+	login.Enroll = len(credentialIDs) == 0
+	login.Optional = a.enforcement == provider.WebAuthnEnforcementIfEnrolled && len(credentials) == 0
+
+	if login.Challenge, err = webauthn.NewChallenge(); err != nil {
+		return err
+	}
+	loginID, err := newLoginID()
+	if err != nil {
+		return err
+	}
+	login.Nonce = string(decodedState.Nonce)
+	login.CreationTimestamp = metav1.NewTime(a.clock())
+	login.Version = webAuthnLoginStorageVersion
+	if _, err := a.logins.Create(r.Context(), loginID, login, nil, nil); err != nil {
+		return fmt.Errorf("could not store login: %w", err)
+	}
+
+	// The user handle must not contain personally identifying information, so use a hash of the subject.
+	userID := sha256.Sum256([]byte(login.Subject))
+
+	// The page needs its own inline script, so it replaces the CSP header which was set for the POST request.
+	w.Header().Set("Content-Security-Policy", loginhtml.WebAuthnContentSecurityPolicy())
+	return loginhtml.WebAuthnTemplate().Execute(w, &loginhtml.WebAuthnPageData{
+		State:         encodedState,
+		IDPName:       decodedState.UpstreamName,
+		PostPath:      postPath,
+		LoginID:       loginID,
+		Enroll:        login.Enroll,
+		Optional:      login.Optional,
+		Challenge:     base64.RawURLEncoding.EncodeToString(login.Challenge),
+		RPID:          rp.ID,
+		UserID:        base64.RawURLEncoding.EncodeToString(userID[:]),
+		UserName:      login.Username,
+		CredentialIDs: strings.Join(credentialIDs, ","),
+	})
+}
+
+// finishSecondFactor verifies the response of the browser to the page rendered by startSecondFactor, and returns the
+// login of the user when the user has used an enrolled security key, enrolled a new one, or skipped the optional
+// enrollment. Errors which are caused by the request wrap downstreamsession.ErrSecondFactorNotVerified.
+func (a *WebAuthn) finishSecondFactor(
+	r *http.Request,
+	rp *webauthn.RelyingParty,
+	loginID string,
+	decodedState *oidc.UpstreamStateParamData,
+) (*pendingLogin, error) {
+	ctx := r.Context()
+
+	login := &pendingLogin{}
+	if _, err := a.logins.Get(ctx, loginID, login); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: login not found", downstreamsession.ErrSecondFactorNotVerified)
+		}
+		return nil, fmt.Errorf("could not get login: %w", err)
+	}
+	// Each login may only be finished once, whether it succeeds or not.
+	if err := a.logins.Delete(ctx, loginID); err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not delete login: %w", err)
+	}
+	switch {
+	case login.Version != webAuthnLoginStorageVersion:
+		return nil, fmt.Errorf("%w: login has version %s instead of %s",
+			downstreamsession.ErrSecondFactorNotVerified, login.Version, webAuthnLoginStorageVersion)
+	case login.Nonce != string(decodedState.Nonce):
+		return nil, fmt.Errorf("%w: login belongs to another authorization request", downstreamsession.ErrSecondFactorNotVerified)
+	case a.clock().After(login.CreationTimestamp.Add(webAuthnLoginLifetime)):
+		return nil, fmt.Errorf("%w: login expired", downstreamsession.ErrSecondFactorNotVerified)
+	}
+
+	if login.Optional && r.PostFormValue(skipParamName) != "" {
+		return login, nil
+	}
+
+	var err error
+	if login.Enroll {
+		err = a.enroll(r, rp, login)
+	} else {
+		err = a.verify(r, rp, login)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return login, nil
+}
+
+func (a *WebAuthn) enroll(r *http.Request, rp *webauthn.RelyingParty, login *pendingLogin) error {
+	registration := &webauthn.Registration{}
+	err := decodeParams(r, []param{
+		{credentialIDParamName, &registration.CredentialID},
+		{clientDataParamName, &registration.ClientDataJSON},
+		{authenticatorDataParamName, &registration.AuthenticatorData},
+		{publicKeyParamName, &registration.PublicKey},
+	})
+	if err == nil {
+		if registration.PublicKeyAlgorithm, err = strconv.Atoi(r.PostFormValue(publicKeyAlgorithmParamName)); err != nil {
+			err = fmt.Errorf("param %s is missing or not an integer", publicKeyAlgorithmParamName)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w: invalid registration: %s", downstreamsession.ErrSecondFactorNotVerified, err.Error())
+	}
+
+	credential, err := rp.VerifyRegistration(login.Challenge, registration)
+	if err != nil {
+		return fmt.Errorf("%w: %s", downstreamsession.ErrSecondFactorNotVerified, err.Error())
+	}
+
+	rv, credentials, err := a.credentials.Get(r.Context(), login.Subject)
+	if err != nil {
+		return err
+	}
+	credentials = append(credentials, webauthncredentialstorage.Credential{
+		Credential:        *credential,
+		RPID:              rp.ID,
+		CreationTimestamp: metav1.NewTime(a.clock()),
+	})
+	return a.credentials.Set(r.Context(), rv, login.Subject, login.Username, credentials)
+}
+
+func (a *WebAuthn) verify(r *http.Request, rp *webauthn.RelyingParty, login *pendingLogin) error {
+	assertion := &webauthn.Assertion{}
+	if err := decodeParams(r, []param{
+		{credentialIDParamName, &assertion.CredentialID},
+		{clientDataParamName, &assertion.ClientDataJSON},
+		{authenticatorDataParamName, &assertion.AuthenticatorData},
+		{signatureParamName, &assertion.Signature},
+	}); err != nil {
+		return fmt.Errorf("%w: invalid assertion: %s", downstreamsession.ErrSecondFactorNotVerified, err.Error())
+	}
+
+	rv, credentials, err := a.credentials.Get(r.Context(), login.Subject)
+	if err != nil {
+		return err
+	}
+
+	// Only the credentials which are bound to this host can be used.
+	var indexes []int
+	var candidates []webauthn.Credential
+	for i, credential := range credentials {
+		if credential.RPID == rp.ID {
+			indexes = append(indexes, i)
+			candidates = append(candidates, credential.Credential)
+		}
+	}
+
+	index, credential, err := rp.VerifyAssertion(login.Challenge, candidates, assertion)
+	if err != nil {
+		return fmt.Errorf("%w: %s", downstreamsession.ErrSecondFactorNotVerified, err.Error())
+	}
+
+	// Remember the signature counter to detect cloned authenticators.
+	lastUsed := metav1.NewTime(a.clock())
+	credentials[indexes[index]].Credential = *credential
+	credentials[indexes[index]].LastUsedTimestamp = &lastUsed
+	return a.credentials.Set(r.Context(), rv, login.Subject, login.Username, credentials)
+}
+
+// param is a base64url-encoded form param of the response of the browser, and where to put its decoded value.
+type param struct {
+	name  string
+	value *[]byte
+}
+
+func decodeParams(r *http.Request, params []param) error {
+	for _, p := range params {
+		decoded, err := base64.RawURLEncoding.DecodeString(r.PostFormValue(p.name))
+		if err != nil || len(decoded) == 0 {
+			return fmt.Errorf("param %s is missing or not base64url-encoded", p.name)
+		}
+		*p.value = decoded
+	}
+	return nil
+}
+
+func newLoginID() (string, error) {
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("could not generate login ID: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(id), nil
+}
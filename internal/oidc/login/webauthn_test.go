@@ -0,0 +1,414 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package login
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes/fake"
+	coretesting "k8s.io/client-go/testing"
+
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/authenticators"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/login/loginhtml"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+	"go.pinniped.dev/internal/webauthn"
+	"go.pinniped.dev/internal/webauthncredentialstorage"
+)
+
+// testAuthenticator is a software authenticator, which responds like a browser with a security key.
+type testAuthenticator struct {
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+	origin    string
+	rpID      string
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &testAuthenticator{
+		key:    key,
+		id:     []byte("some-credential-id"),
+		origin: "https://my-downstream-issuer.com",
+		rpID:   "my-downstream-issuer.com",
+	}
+}
+
+func (a *testAuthenticator) clientData(t *testing.T, typ, challenge string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]string{"type": typ, "challenge": challenge, "origin": a.origin})
+	require.NoError(t, err)
+	return data
+}
+
+func (a *testAuthenticator) authenticatorData(attested bool) []byte {
+	a.signCount++
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	if !attested {
+		data = append(data, 0x01)
+		return binary.BigEndian.AppendUint32(data, a.signCount)
+	}
+	data = append(data, 0x41)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	data = append(data, make([]byte, 16)...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.id)))
+	data = append(data, a.id...)
+	return append(data, 0xa0)
+}
+
+func (a *testAuthenticator) register(t *testing.T, page *webAuthnPage) url.Values {
+	t.Helper()
+	publicKey, err := x509.MarshalPKIXPublicKey(&a.key.PublicKey)
+	require.NoError(t, err)
+	return url.Values{
+		webAuthnLoginParamName:      {page.loginID},
+		credentialIDParamName:       {base64.RawURLEncoding.EncodeToString(a.id)},
+		clientDataParamName:         {base64.RawURLEncoding.EncodeToString(a.clientData(t, "webauthn.create", page.challenge))},
+		authenticatorDataParamName:  {base64.RawURLEncoding.EncodeToString(a.authenticatorData(true))},
+		publicKeyParamName:          {base64.RawURLEncoding.EncodeToString(publicKey)},
+		publicKeyAlgorithmParamName: {strconv.Itoa(webauthn.AlgorithmES256)},
+	}
+}
+
+func (a *testAuthenticator) assert(t *testing.T, page *webAuthnPage) url.Values {
+	t.Helper()
+	clientData := a.clientData(t, "webauthn.get", page.challenge)
+	authData := a.authenticatorData(false)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := a.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+	return url.Values{
+		webAuthnLoginParamName:     {page.loginID},
+		credentialIDParamName:      {base64.RawURLEncoding.EncodeToString(a.id)},
+		clientDataParamName:        {base64.RawURLEncoding.EncodeToString(clientData)},
+		authenticatorDataParamName: {base64.RawURLEncoding.EncodeToString(authData)},
+		signatureParamName:         {base64.RawURLEncoding.EncodeToString(signature)},
+	}
+}
+
+// webAuthnPage is what the browser reads from the page which asks for the security key.
+type webAuthnPage struct {
+	loginID       string
+	challenge     string
+	enroll        bool
+	optional      bool
+	credentialIDs string
+}
+
+func parseWebAuthnPage(t *testing.T, rsp *httptest.ResponseRecorder) *webAuthnPage {
+	t.Helper()
+	require.Equal(t, http.StatusOK, rsp.Code)
+	require.Equal(t, loginhtml.WebAuthnContentSecurityPolicy(), rsp.Header().Get("Content-Security-Policy"))
+	body := rsp.Body.String()
+	find := func(pattern string) string {
+		submatches := regexp.MustCompile(pattern).FindStringSubmatch(body)
+		require.Len(t, submatches, 2, "no match for %s in %s", pattern, body)
+		return submatches[1]
+	}
+	return &webAuthnPage{
+		loginID:       find(`name="webauthn_login" id="webauthn_login" value="([^"]+)"`),
+		challenge:     find(`data-challenge="([^"]+)"`),
+		enroll:        find(`data-enroll="([^"]+)"`) == "true",
+		optional:      strings.Contains(body, `name="skip"`),
+		credentialIDs: find(`data-credential-ids="([^"]*)"`),
+	}
+}
+
+func TestPostLoginEndpointWithWebAuthn(t *testing.T) {
+	const (
+		downstreamIssuer  = "https://my-downstream-issuer.com/path"
+		ldapUpstreamName  = "some-ldap-idp"
+		happyUsername     = "some-ldap-user"
+		happyPassword     = "some-ldap-password" //nolint:gosec
+		mappedUsername    = "some-mapped-ldap-username"
+		happyEncodedState = "fake-encoded-state-param-value"
+		webAuthnErrorPath = downstreamIssuer + oidc.PinnipedLoginPath + "?err=webauthn_error&state=" + happyEncodedState
+		happyRedirect     = `^http://127\.0\.0\.1/callback\?code=pin_ac_[^&]+&scope=openid\+username\+groups&state=8b-state$`
+	)
+
+	parsedUpstreamLDAPURL, err := url.Parse("ldaps://some-ldap-host:123?base=ou%3Dusers%2Cdc%3Dpinniped%2Cdc%3Ddev")
+	require.NoError(t, err)
+	upstreamLDAPIdentityProvider := oidctestutil.TestUpstreamLDAPIdentityProvider{
+		Name:        ldapUpstreamName,
+		ResourceUID: "ldap-resource-uid",
+		URL:         parsedUpstreamLDAPURL,
+		AuthenticateFunc: func(ctx context.Context, username, password string) (*authenticators.Response, bool, error) {
+			if username != happyUsername || password != happyPassword {
+				return nil, false, nil
+			}
+			return &authenticators.Response{
+				User: &user.DefaultInfo{Name: mappedUsername, UID: "some-ldap-uid", Groups: []string{"group1"}},
+				DN:   "cn=foo,dn=bar",
+			}, true, nil
+		},
+	}
+	happySubject := "ldaps://some-ldap-host:123?base=ou%3Dusers%2Cdc%3Dpinniped%2Cdc%3Ddev&sub=some-ldap-uid"
+
+	decodedState := &oidc.UpstreamStateParamData{
+		AuthParams: url.Values{
+			"response_type":         {"code"},
+			"scope":                 {"openid username groups"},
+			"client_id":             {"pinniped-cli"},
+			"state":                 {"8b-state"},
+			"nonce":                 {"some-nonce-value"},
+			"code_challenge":        {"some-challenge"},
+			"code_challenge_method": {"S256"},
+			"redirect_uri":          {"http://127.0.0.1/callback"},
+		}.Encode(),
+		UpstreamName:  ldapUpstreamName,
+		UpstreamType:  "ldap",
+		Nonce:         "test-nonce",
+		CSRFToken:     "test-csrf",
+		PKCECode:      "test-pkce",
+		FormatVersion: "2",
+	}
+
+	type fixture struct {
+		handler       HandlerFunc
+		credentials   *webauthncredentialstorage.WebAuthnCredentialStorage
+		auditRecorder *testutil.AuditRecorder
+	}
+	setup := func(t *testing.T, enforcement provider.WebAuthnEnforcement) *fixture {
+		t.Helper()
+		kubeClient := fake.NewSimpleClientset()
+		// The fake client does not assign resource versions, which the credential storage needs to update Secrets.
+		resourceVersion := 0
+		kubeClient.PrependReactor("*", "secrets", func(action coretesting.Action) (bool, runtime.Object, error) {
+			if objectAction, ok := action.(interface{ GetObject() runtime.Object }); ok {
+				resourceVersion++
+				objectAction.GetObject().(*corev1.Secret).ResourceVersion = strconv.Itoa(resourceVersion)
+			}
+			return false, nil, nil
+		})
+		secretsClient := kubeClient.CoreV1().Secrets("some-namespace")
+		oidcClientsClient := supervisorfake.NewSimpleClientset().ConfigV1alpha1().OIDCClients("some-namespace")
+		timeoutsConfiguration := oidc.DefaultOIDCTimeoutsConfiguration()
+		kubeOauthStore := oidc.NewKubeStorage(secretsClient, oidcClientsClient, timeoutsConfiguration, bcrypt.MinCost)
+		hmacSecretFunc := func() []byte { return []byte("some secret - must have at least 32 bytes") }
+		oauthHelper := oidc.FositeOauth2Helper(kubeOauthStore, downstreamIssuer, hmacSecretFunc, jwks.NewDynamicJWKSProvider(), timeoutsConfiguration)
+
+		credentials := webauthncredentialstorage.New(secretsClient)
+		auditRecorder := &testutil.AuditRecorder{}
+		handler := NewPostHandler(
+			downstreamIssuer,
+			oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&upstreamLDAPIdentityProvider).Build(),
+			oauthHelper,
+			nil,
+			NewWebAuthn(enforcement, credentials, crud.NewSecretsBackend(secretsClient), time.Now),
+			nil,
+			nil,
+			auditRecorder,
+		)
+		return &fixture{handler: handler, credentials: credentials, auditRecorder: auditRecorder}
+	}
+
+	post := func(t *testing.T, f *fixture, state *oidc.UpstreamStateParamData, form url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/path/login", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rsp := httptest.NewRecorder()
+		require.NoError(t, f.handler(rsp, req, happyEncodedState, state))
+		return rsp
+	}
+
+	passwordForm := url.Values{"username": {happyUsername}, "password": {happyPassword}}
+
+	requireAuthcodeRedirect := func(t *testing.T, rsp *httptest.ResponseRecorder) {
+		t.Helper()
+		require.Equal(t, http.StatusSeeOther, rsp.Code)
+		require.Regexp(t, happyRedirect, rsp.Header().Get("Location"))
+	}
+
+	requireWebAuthnError := func(t *testing.T, rsp *httptest.ResponseRecorder) {
+		t.Helper()
+		require.Equal(t, http.StatusSeeOther, rsp.Code)
+		require.Equal(t, webAuthnErrorPath, rsp.Header().Get("Location"))
+	}
+
+	t.Run("required second factor is enrolled and then used", func(t *testing.T) {
+		f := setup(t, provider.WebAuthnEnforcementRequired)
+		authenticator := newTestAuthenticator(t)
+
+		page := parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+		require.True(t, page.enroll)
+		require.False(t, page.optional)
+		require.Empty(t, page.credentialIDs)
+		require.Empty(t, f.auditRecorder.Events(), "the login is not finished yet")
+
+		requireAuthcodeRedirect(t, post(t, f, decodedState, authenticator.register(t, page)))
+		f.auditRecorder.RequireEventsWithRandomSessionIDs(t, []auditlog.Event{
+			{Type: auditlog.EventUpstreamAuthenticationSucceeded, ClientID: "pinniped-cli", IdentityProvider: ldapUpstreamName, Username: mappedUsername},
+		})
+
+		_, credentials, err := f.credentials.Get(context.Background(), happySubject)
+		require.NoError(t, err)
+		require.Len(t, credentials, 1)
+		require.Equal(t, authenticator.id, credentials[0].ID)
+		require.Equal(t, "my-downstream-issuer.com", credentials[0].RPID)
+		require.Equal(t, uint32(1), credentials[0].SignCount)
+		require.Nil(t, credentials[0].LastUsedTimestamp)
+
+		// Now the user has a credential, so the next login asks for it.
+		page = parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+		require.False(t, page.enroll)
+		require.False(t, page.optional)
+		require.Equal(t, base64.RawURLEncoding.EncodeToString(authenticator.id), page.credentialIDs)
+
+		assertion := authenticator.assert(t, page)
+		requireAuthcodeRedirect(t, post(t, f, decodedState, assertion))
+
+		_, credentials, err = f.credentials.Get(context.Background(), happySubject)
+		require.NoError(t, err)
+		require.Len(t, credentials, 1)
+		require.Equal(t, uint32(2), credentials[0].SignCount)
+		require.NotNil(t, credentials[0].LastUsedTimestamp)
+
+		// Each login can only be finished once.
+		requireWebAuthnError(t, post(t, f, decodedState, assertion))
+	})
+
+	t.Run("required second factor cannot be skipped", func(t *testing.T) {
+		f := setup(t, provider.WebAuthnEnforcementRequired)
+
+		page := parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+		requireWebAuthnError(t, post(t, f, decodedState, url.Values{webAuthnLoginParamName: {page.loginID}, skipParamName: {"Skip for now"}}))
+		f.auditRecorder.RequireEventsWithRandomSessionIDs(t, []auditlog.Event{
+			{
+				Type:             auditlog.EventUpstreamAuthenticationFailed,
+				ClientID:         "pinniped-cli",
+				IdentityProvider: ldapUpstreamName,
+				Error:            "second factor not verified: invalid registration: param credential_id is missing or not base64url-encoded",
+			},
+		})
+	})
+
+	t.Run("optional second factor can be skipped until it is enrolled", func(t *testing.T) {
+		f := setup(t, provider.WebAuthnEnforcementIfEnrolled)
+
+		page := parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+		require.True(t, page.enroll)
+		require.True(t, page.optional)
+
+		requireAuthcodeRedirect(t, post(t, f, decodedState, url.Values{webAuthnLoginParamName: {page.loginID}, skipParamName: {"Skip for now"}}))
+		_, credentials, err := f.credentials.Get(context.Background(), happySubject)
+		require.NoError(t, err)
+		require.Empty(t, credentials)
+
+		authenticator := newTestAuthenticator(t)
+		page = parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+		requireAuthcodeRedirect(t, post(t, f, decodedState, authenticator.register(t, page)))
+
+		// Once enrolled, the second factor is no longer optional.
+		page = parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+		require.False(t, page.enroll)
+		require.False(t, page.optional)
+		requireWebAuthnError(t, post(t, f, decodedState, url.Values{webAuthnLoginParamName: {page.loginID}, skipParamName: {"Skip for now"}}))
+	})
+
+	t.Run("credential which was enrolled for another hostname must be enrolled again", func(t *testing.T) {
+		f := setup(t, provider.WebAuthnEnforcementIfEnrolled)
+		require.NoError(t, f.credentials.Set(context.Background(), "", happySubject, happyUsername, []webauthncredentialstorage.Credential{
+			{Credential: webauthn.Credential{ID: []byte("other-id")}, RPID: "other-issuer.com"},
+		}))
+
+		page := parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+		require.True(t, page.enroll)
+		require.False(t, page.optional)
+		require.Empty(t, page.credentialIDs)
+	})
+
+	t.Run("invalid signature is rejected", func(t *testing.T) {
+		f := setup(t, provider.WebAuthnEnforcementRequired)
+		authenticator := newTestAuthenticator(t)
+		page := parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+		requireAuthcodeRedirect(t, post(t, f, decodedState, authenticator.register(t, page)))
+
+		page = parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+		assertion := authenticator.assert(t, page)
+		assertion.Set(signatureParamName, base64.RawURLEncoding.EncodeToString([]byte("wrong-signature")))
+		requireWebAuthnError(t, post(t, f, decodedState, assertion))
+		require.Equal(t, "second factor not verified: signature is invalid", f.auditRecorder.Events()[1].Error)
+	})
+
+	t.Run("login cannot be finished by another authorization request", func(t *testing.T) {
+		f := setup(t, provider.WebAuthnEnforcementRequired)
+		authenticator := newTestAuthenticator(t)
+		page := parseWebAuthnPage(t, post(t, f, decodedState, passwordForm))
+
+		otherState := *decodedState
+		otherState.Nonce = "other-nonce"
+		requireWebAuthnError(t, post(t, f, &otherState, authenticator.register(t, page)))
+	})
+
+	t.Run("unknown login is rejected", func(t *testing.T) {
+		f := setup(t, provider.WebAuthnEnforcementRequired)
+		requireWebAuthnError(t, post(t, f, decodedState, url.Values{webAuthnLoginParamName: {"some-unknown-login"}}))
+	})
+
+	t.Run("second factor is not used when it is not enforced", func(t *testing.T) {
+		f := setup(t, provider.WebAuthnEnforcementNone)
+		requireAuthcodeRedirect(t, post(t, f, decodedState, passwordForm))
+		require.Equal(t, http.StatusSeeOther, post(t, f, decodedState, url.Values{webAuthnLoginParamName: {"some-login"}}).Code)
+	})
+}
+
+func TestRequiresSecondFactor(t *testing.T) {
+	ctx := context.Background()
+	secretsClient := fake.NewSimpleClientset().CoreV1().Secrets("some-namespace")
+	credentials := webauthncredentialstorage.New(secretsClient)
+	backend := crud.NewSecretsBackend(secretsClient)
+	require.NoError(t, credentials.Set(ctx, "", "enrolled-subject", "enrolled-username", []webauthncredentialstorage.Credential{{RPID: "issuer.example.com"}}))
+
+	var disabled *WebAuthn
+	require.Nil(t, NewWebAuthn(provider.WebAuthnEnforcementNone, credentials, backend, time.Now))
+	requires, err := disabled.RequiresSecondFactor(ctx, "enrolled-subject")
+	require.NoError(t, err)
+	require.False(t, requires)
+
+	required := NewWebAuthn(provider.WebAuthnEnforcementRequired, credentials, backend, time.Now)
+	requires, err = required.RequiresSecondFactor(ctx, "other-subject")
+	require.NoError(t, err)
+	require.True(t, requires)
+
+	ifEnrolled := NewWebAuthn(provider.WebAuthnEnforcementIfEnrolled, credentials, backend, time.Now)
+	requires, err = ifEnrolled.RequiresSecondFactor(ctx, "enrolled-subject")
+	require.NoError(t, err)
+	require.True(t, requires)
+	requires, err = ifEnrolled.RequiresSecondFactor(ctx, "other-subject")
+	require.NoError(t, err)
+	require.False(t, requires)
+}
@@ -0,0 +1,267 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package loginpolicy evaluates the login policy of a FederationDomain, which can deny the logins of users who have
+// already authenticated with their upstream identity provider.
+package loginpolicy
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	"go.pinniped.dev/internal/constable"
+)
+
+// ErrDenied is wrapped by the errors of Evaluate, whose messages explain which restriction denied the login.
+const ErrDenied = constable.Error("login denied by policy")
+
+// Config is the configuration of a Policy. Each restriction which is left empty allows all logins.
+type Config struct {
+	// AllowedEmailDomains are the domains of the usernames which are allowed to log in.
+	AllowedEmailDomains []string
+	// AllowedGroups are the groups of which a user must be a member of at least one.
+	AllowedGroups []string
+	// AllowedSourceCIDRs are the IP address ranges from which logins are allowed.
+	AllowedSourceCIDRs []string
+	// TimeWindows are the periods of time during which logins are allowed.
+	TimeWindows []TimeWindowConfig
+}
+
+// TimeWindowConfig is the configuration of a recurring period of time during which logins are allowed.
+type TimeWindowConfig struct {
+	// Days are the names of the weekdays on which the window starts, e.g. "Monday", or empty for every day.
+	Days []string
+	// Start and End are the times of day in the format HH:MM. When End is not after Start, the window ends on the
+	// next day.
+	Start string
+	End   string
+	// TimeZone is the IANA name of the time zone of the window, or empty for UTC.
+	TimeZone string
+}
+
+// ConfigFromSpec returns the Config of the login policy in the spec of a FederationDomain, which may be nil.
+func ConfigFromSpec(spec *configv1alpha1.FederationDomainLoginPolicy) Config {
+	if spec == nil {
+		return Config{}
+	}
+	config := Config{
+		AllowedEmailDomains: spec.AllowedEmailDomains,
+		AllowedGroups:       spec.AllowedGroups,
+		AllowedSourceCIDRs:  spec.AllowedSourceCIDRs,
+	}
+	for _, window := range spec.TimeWindows {
+		days := make([]string, 0, len(window.Days))
+		for _, day := range window.Days {
+			days = append(days, string(day))
+		}
+		config.TimeWindows = append(config.TimeWindows, TimeWindowConfig{
+			Days:     days,
+			Start:    window.Start,
+			End:      window.End,
+			TimeZone: window.TimeZone,
+		})
+	}
+	return config
+}
+
+// Policy decides whether an authenticated user is allowed to log in. A nil *Policy allows all logins.
+type Policy struct {
+	allowedEmailDomains map[string]bool
+	allowedGroups       map[string]bool
+	allowedSourceCIDRs  []*net.IPNet
+	timeWindows         []timeWindow
+}
+
+type timeWindow struct {
+	// days is indexed by time.Weekday, or nil for every day.
+	days     []bool
+	start    int // minutes after midnight
+	end      int // minutes after midnight
+	location *time.Location
+}
+
+// New validates the configuration and returns its Policy, or nil when the configuration has no restrictions.
+func New(config Config) (*Policy, error) {
+	if len(config.AllowedEmailDomains) == 0 && len(config.AllowedGroups) == 0 &&
+		len(config.AllowedSourceCIDRs) == 0 && len(config.TimeWindows) == 0 {
+		return nil, nil
+	}
+
+	p := &Policy{}
+
+	if len(config.AllowedEmailDomains) > 0 {
+		p.allowedEmailDomains = make(map[string]bool, len(config.AllowedEmailDomains))
+		for _, domain := range config.AllowedEmailDomains {
+			if domain == "" || strings.Contains(domain, "@") {
+				return nil, fmt.Errorf("allowed email domain %q must be a domain name without an @, e.g. example.com", domain)
+			}
+			p.allowedEmailDomains[strings.ToLower(domain)] = true
+		}
+	}
+
+	if len(config.AllowedGroups) > 0 {
+		p.allowedGroups = make(map[string]bool, len(config.AllowedGroups))
+		for _, group := range config.AllowedGroups {
+			p.allowedGroups[group] = true
+		}
+	}
+
+	for _, cidr := range config.AllowedSourceCIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("allowed source CIDR %q must be an IP address range in CIDR notation, e.g. 10.0.0.0/8", cidr)
+		}
+		p.allowedSourceCIDRs = append(p.allowedSourceCIDRs, ipNet)
+	}
+
+	for i, windowConfig := range config.TimeWindows {
+		window, err := newTimeWindow(windowConfig)
+		if err != nil {
+			return nil, fmt.Errorf("time window %d is invalid: %w", i, err)
+		}
+		p.timeWindows = append(p.timeWindows, window)
+	}
+
+	return p, nil
+}
+
+// Evaluate returns nil when the user with the given downstream username and groups is allowed to log in from the
+// given source IP address at the given time, or an error which wraps ErrDenied otherwise.
+func (p *Policy) Evaluate(username string, groups []string, sourceIP string, now time.Time) error {
+	if p == nil {
+		return nil
+	}
+
+	if p.allowedEmailDomains != nil {
+		at := strings.LastIndex(username, "@")
+		if at < 0 || !p.allowedEmailDomains[strings.ToLower(username[at+1:])] {
+			return fmt.Errorf("%w: the email domain of the username is not allowed", ErrDenied)
+		}
+	}
+
+	if p.allowedGroups != nil && !p.isMemberOfAllowedGroup(groups) {
+		return fmt.Errorf("%w: the user is not a member of an allowed group", ErrDenied)
+	}
+
+	if p.allowedSourceCIDRs != nil && !p.isAllowedSourceIP(sourceIP) {
+		return fmt.Errorf("%w: logins are not allowed from this IP address", ErrDenied)
+	}
+
+	if p.timeWindows != nil && !p.isAllowedTime(now) {
+		return fmt.Errorf("%w: logins are not allowed at this time", ErrDenied)
+	}
+
+	return nil
+}
+
+func (p *Policy) isMemberOfAllowedGroup(groups []string) bool {
+	for _, group := range groups {
+		if p.allowedGroups[group] {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) isAllowedSourceIP(sourceIP string) bool {
+	// The IP address is unknown for requests over a Unix domain socket, which cannot be allowed.
+	ip := net.ParseIP(sourceIP)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range p.allowedSourceCIDRs {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) isAllowedTime(now time.Time) bool {
+	for _, window := range p.timeWindows {
+		if window.contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func newTimeWindow(config TimeWindowConfig) (timeWindow, error) {
+	window := timeWindow{location: time.UTC}
+
+	if len(config.Days) > 0 {
+		window.days = make([]bool, 7)
+		for _, day := range config.Days {
+			weekday, ok := parseWeekday(day)
+			if !ok {
+				return timeWindow{}, fmt.Errorf("day %q must be the name of a weekday, e.g. Monday", day)
+			}
+			window.days[weekday] = true
+		}
+	}
+
+	var err error
+	if window.start, err = parseTimeOfDay(config.Start); err != nil {
+		return timeWindow{}, fmt.Errorf("start %w", err)
+	}
+	if window.end, err = parseTimeOfDay(config.End); err != nil {
+		return timeWindow{}, fmt.Errorf("end %w", err)
+	}
+
+	if config.TimeZone != "" {
+		if window.location, err = time.LoadLocation(config.TimeZone); err != nil {
+			return timeWindow{}, fmt.Errorf("time zone %q is unknown", config.TimeZone)
+		}
+	}
+
+	return window, nil
+}
+
+func (w timeWindow) contains(now time.Time) bool {
+	now = now.In(w.location)
+	minute := now.Hour()*60 + now.Minute()
+	day := now.Weekday()
+
+	if w.start < w.end {
+		return w.startsOn(day) && minute >= w.start && minute < w.end
+	}
+
+	// The window ends on the next day, so the login may happen on the day the window started or on the day after it.
+	yesterday := (day + 6) % 7
+	return (w.startsOn(day) && minute >= w.start) || (w.startsOn(yesterday) && minute < w.end)
+}
+
+func (w timeWindow) startsOn(day time.Weekday) bool {
+	return w.days == nil || w.days[day]
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if day.String() == name {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// parseTimeOfDay parses a time in the format HH:MM and returns the number of minutes after midnight.
+func parseTimeOfDay(value string) (int, error) {
+	invalid := fmt.Errorf("%q must be a time of day in the format HH:MM, e.g. 08:00", value)
+
+	if len(value) != 5 || value[2] != ':' {
+		return 0, invalid
+	}
+	hours, err := strconv.Atoi(value[:2])
+	if err != nil || hours < 0 || hours > 23 {
+		return 0, invalid
+	}
+	minutes, err := strconv.Atoi(value[3:])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, invalid
+	}
+	return hours*60 + minutes, nil
+}
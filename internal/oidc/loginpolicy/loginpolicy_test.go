@@ -0,0 +1,276 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package loginpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantNil bool
+		wantErr string
+	}{
+		{
+			name:    "no restrictions",
+			config:  Config{},
+			wantNil: true,
+		},
+		{
+			name: "all restrictions",
+			config: Config{
+				AllowedEmailDomains: []string{"example.com"},
+				AllowedGroups:       []string{"admins"},
+				AllowedSourceCIDRs:  []string{"10.0.0.0/8", "fd00::/8"},
+				TimeWindows:         []TimeWindowConfig{{Days: []string{"Monday"}, Start: "08:00", End: "18:00", TimeZone: "Europe/Berlin"}},
+			},
+		},
+		{
+			name:    "email domain with an @",
+			config:  Config{AllowedEmailDomains: []string{"@example.com"}},
+			wantErr: `allowed email domain "@example.com" must be a domain name without an @, e.g. example.com`,
+		},
+		{
+			name:    "empty email domain",
+			config:  Config{AllowedEmailDomains: []string{""}},
+			wantErr: `allowed email domain "" must be a domain name without an @, e.g. example.com`,
+		},
+		{
+			name:    "invalid CIDR",
+			config:  Config{AllowedSourceCIDRs: []string{"10.0.0.1"}},
+			wantErr: `allowed source CIDR "10.0.0.1" must be an IP address range in CIDR notation, e.g. 10.0.0.0/8`,
+		},
+		{
+			name:    "invalid day",
+			config:  Config{TimeWindows: []TimeWindowConfig{{Days: []string{"monday"}, Start: "08:00", End: "18:00"}}},
+			wantErr: `time window 0 is invalid: day "monday" must be the name of a weekday, e.g. Monday`,
+		},
+		{
+			name:    "invalid start",
+			config:  Config{TimeWindows: []TimeWindowConfig{{Start: "8:00", End: "18:00"}}},
+			wantErr: `time window 0 is invalid: start "8:00" must be a time of day in the format HH:MM, e.g. 08:00`,
+		},
+		{
+			name: "invalid end",
+			config: Config{TimeWindows: []TimeWindowConfig{
+				{Start: "08:00", End: "18:00"},
+				{Start: "20:00", End: "24:00"},
+			}},
+			wantErr: `time window 1 is invalid: end "24:00" must be a time of day in the format HH:MM, e.g. 08:00`,
+		},
+		{
+			name:    "unknown time zone",
+			config:  Config{TimeWindows: []TimeWindowConfig{{Start: "08:00", End: "18:00", TimeZone: "Mars/Olympus_Mons"}}},
+			wantErr: `time window 0 is invalid: time zone "Mars/Olympus_Mons" is unknown`,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := New(test.config)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+				require.Nil(t, policy)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.wantNil, policy == nil)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	// A Monday.
+	monday := time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		config   Config
+		username string
+		groups   []string
+		sourceIP string
+		now      time.Time
+		wantErr  string
+	}{
+		{
+			name:     "no restrictions",
+			username: "pinny",
+			now:      monday,
+		},
+		{
+			name:     "allowed email domain",
+			config:   Config{AllowedEmailDomains: []string{"other.com", "Example.com"}},
+			username: "pinny@EXAMPLE.com",
+			now:      monday,
+		},
+		{
+			name:     "only the last @ separates the domain",
+			config:   Config{AllowedEmailDomains: []string{"example.com"}},
+			username: "pinny@example.com@evil.com",
+			now:      monday,
+			wantErr:  "login denied by policy: the email domain of the username is not allowed",
+		},
+		{
+			name:     "username without a domain",
+			config:   Config{AllowedEmailDomains: []string{"example.com"}},
+			username: "pinny",
+			now:      monday,
+			wantErr:  "login denied by policy: the email domain of the username is not allowed",
+		},
+		{
+			name:     "member of an allowed group",
+			config:   Config{AllowedGroups: []string{"admins", "developers"}},
+			username: "pinny",
+			groups:   []string{"users", "developers"},
+			now:      monday,
+		},
+		{
+			name:     "not a member of an allowed group",
+			config:   Config{AllowedGroups: []string{"admins"}},
+			username: "pinny",
+			groups:   []string{"users", "Admins"},
+			now:      monday,
+			wantErr:  "login denied by policy: the user is not a member of an allowed group",
+		},
+		{
+			name:     "allowed source IP",
+			config:   Config{AllowedSourceCIDRs: []string{"10.0.0.0/8", "fd00::/8"}},
+			username: "pinny",
+			sourceIP: "fd12::1",
+			now:      monday,
+		},
+		{
+			name:     "source IP not allowed",
+			config:   Config{AllowedSourceCIDRs: []string{"10.0.0.0/8"}},
+			username: "pinny",
+			sourceIP: "192.168.0.1",
+			now:      monday,
+			wantErr:  "login denied by policy: logins are not allowed from this IP address",
+		},
+		{
+			name:     "unknown source IP",
+			config:   Config{AllowedSourceCIDRs: []string{"0.0.0.0/0"}},
+			username: "pinny",
+			now:      monday,
+			wantErr:  "login denied by policy: logins are not allowed from this IP address",
+		},
+		{
+			name:     "during a time window",
+			config:   Config{TimeWindows: []TimeWindowConfig{{Days: []string{"Monday", "Tuesday"}, Start: "08:00", End: "12:01"}}},
+			username: "pinny",
+			now:      monday,
+		},
+		{
+			name:     "at the end of a time window",
+			config:   Config{TimeWindows: []TimeWindowConfig{{Start: "08:00", End: "12:00"}}},
+			username: "pinny",
+			now:      monday,
+			wantErr:  "login denied by policy: logins are not allowed at this time",
+		},
+		{
+			name:     "on another day",
+			config:   Config{TimeWindows: []TimeWindowConfig{{Days: []string{"Sunday"}, Start: "08:00", End: "18:00"}}},
+			username: "pinny",
+			now:      monday,
+			wantErr:  "login denied by policy: logins are not allowed at this time",
+		},
+		{
+			name: "during one of several time windows",
+			config: Config{TimeWindows: []TimeWindowConfig{
+				{Start: "00:00", End: "06:00"},
+				{Start: "11:00", End: "13:00"},
+			}},
+			username: "pinny",
+			now:      monday,
+		},
+		{
+			name:     "in another time zone",
+			config:   Config{TimeWindows: []TimeWindowConfig{{Start: "08:00", End: "12:30", TimeZone: "Europe/Berlin"}}},
+			username: "pinny",
+			now:      monday, // 13:00 in Berlin
+			wantErr:  "login denied by policy: logins are not allowed at this time",
+		},
+		{
+			name:     "overnight window on the day it starts",
+			config:   Config{TimeWindows: []TimeWindowConfig{{Days: []string{"Monday"}, Start: "22:00", End: "06:00"}}},
+			username: "pinny",
+			now:      monday.Add(11 * time.Hour), // Monday 23:00
+		},
+		{
+			name:     "overnight window on the next day",
+			config:   Config{TimeWindows: []TimeWindowConfig{{Days: []string{"Sunday"}, Start: "22:00", End: "06:00"}}},
+			username: "pinny",
+			now:      monday.Add(-7 * time.Hour), // Monday 05:00
+		},
+		{
+			name:     "overnight window which started on another day",
+			config:   Config{TimeWindows: []TimeWindowConfig{{Days: []string{"Monday"}, Start: "22:00", End: "06:00"}}},
+			username: "pinny",
+			now:      monday.Add(-7 * time.Hour), // Monday 05:00
+			wantErr:  "login denied by policy: logins are not allowed at this time",
+		},
+		{
+			name:     "whole day window",
+			config:   Config{TimeWindows: []TimeWindowConfig{{Days: []string{"Monday"}, Start: "00:00", End: "00:00"}}},
+			username: "pinny",
+			now:      monday.Add(11*time.Hour + 59*time.Minute), // Monday 23:59
+		},
+		{
+			name: "all restrictions are satisfied",
+			config: Config{
+				AllowedEmailDomains: []string{"example.com"},
+				AllowedGroups:       []string{"admins"},
+				AllowedSourceCIDRs:  []string{"10.0.0.0/8"},
+				TimeWindows:         []TimeWindowConfig{{Start: "08:00", End: "18:00"}},
+			},
+			username: "pinny@example.com",
+			groups:   []string{"admins"},
+			sourceIP: "10.1.2.3",
+			now:      monday,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := New(test.config)
+			require.NoError(t, err)
+
+			err = policy.Evaluate(test.username, test.groups, test.sourceIP, test.now)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+				require.ErrorIs(t, err, ErrDenied)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConfigFromSpec(t *testing.T) {
+	require.Equal(t, Config{}, ConfigFromSpec(nil))
+	require.Equal(t, Config{
+		AllowedEmailDomains: []string{"example.com"},
+		AllowedGroups:       []string{"admins"},
+		AllowedSourceCIDRs:  []string{"10.0.0.0/8"},
+		TimeWindows: []TimeWindowConfig{
+			{Days: []string{"Monday", "Friday"}, Start: "08:00", End: "18:00", TimeZone: "Europe/Berlin"},
+			{Days: []string{}, Start: "22:00", End: "06:00"},
+		},
+	}, ConfigFromSpec(&configv1alpha1.FederationDomainLoginPolicy{
+		AllowedEmailDomains: []string{"example.com"},
+		AllowedGroups:       []string{"admins"},
+		AllowedSourceCIDRs:  []string{"10.0.0.0/8"},
+		TimeWindows: []configv1alpha1.FederationDomainLoginTimeWindow{
+			{Days: []configv1alpha1.FederationDomainLoginWeekday{"Monday", "Friday"}, Start: "08:00", End: "18:00", TimeZone: "Europe/Berlin"},
+			{Start: "22:00", End: "06:00"},
+		},
+	}))
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package oidc contains common OIDC functionality needed by Pinniped.
@@ -34,7 +34,8 @@ import (
 const (
 	WellKnownEndpointPath     = "/.well-known/openid-configuration"
 	AuthorizationEndpointPath = "/oauth2/authorize"
-	TokenEndpointPath         = "/oauth2/token" //nolint:gosec // ignore lint warning that this is a credential
+	TokenEndpointPath         = "/oauth2/token"  //nolint:gosec // ignore lint warning that this is a credential
+	RevocationEndpointPath    = "/oauth2/revoke" //nolint:gosec // ignore lint warning that this is a credential
 	CallbackEndpointPath      = "/callback"
 	JWKSEndpointPath          = "/jwks.json"
 	PinnipedIDPsPathV1Alpha1  = "/v1alpha1/pinniped_identity_providers"
@@ -190,6 +191,11 @@ func DefaultOIDCTimeoutsConfiguration() TimeoutsConfiguration {
 	}
 }
 
+// clientSecretUseRecorder is implemented by storage which records when client secrets are used.
+type clientSecretUseRecorder interface {
+	SecretsHasher(wrapped fosite.Hasher) fosite.Hasher
+}
+
 func FositeOauth2Helper(
 	oauthStore interface{},
 	issuer string,
@@ -232,20 +238,30 @@ func FositeOauth2Helper(
 		ClientSecretsHasher: nil,
 	}
 
+	// When the storage can record the use of client secrets, let it observe each comparison of a client secret.
+	if recorder, ok := oauthStore.(clientSecretUseRecorder); ok {
+		oauthConfig.ClientSecretsHasher = recorder.SecretsHasher(&fosite.BCrypt{Config: oauthConfig})
+	}
+
+	// When the storage can look up clients, the ID token strategy uses it to filter the groups claims of the clients.
+	clients, _ := oauthStore.(fosite.ClientManager)
+
 	oAuth2Provider := compose.Compose(
 		oauthConfig,
 		oauthStore,
 		&compose.CommonStrategy{
 			// Note that Fosite requires the HMAC secret to be at least 32 bytes.
 			CoreStrategy:               newDynamicOauth2HMACStrategy(oauthConfig, hmacSecretOfLengthAtLeast32Func),
-			OpenIDConnectTokenStrategy: newDynamicOpenIDConnectECDSAStrategy(oauthConfig, jwksProvider),
+			OpenIDConnectTokenStrategy: newDynamicOpenIDConnectECDSAStrategy(oauthConfig, jwksProvider, clients),
 		},
 		compose.OAuth2AuthorizeExplicitFactory,
 		compose.OAuth2RefreshTokenGrantFactory,
 		compose.OpenIDConnectExplicitFactory,
 		compose.OpenIDConnectRefreshFactory,
 		compose.OAuth2PKCEFactory,
-		TokenExchangeFactory, // handle the "urn:ietf:params:oauth:grant-type:token-exchange" grant type
+		TokenExchangeFactory,     // handle the "urn:ietf:params:oauth:grant-type:token-exchange" grant type
+		ClientCredentialsFactory, // handle the "client_credentials" grant type
+		compose.OAuth2TokenRevocationFactory,
 	)
 
 	return oAuth2Provider
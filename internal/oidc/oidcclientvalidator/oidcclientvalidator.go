@@ -1,14 +1,17 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclientvalidator
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
@@ -18,43 +21,115 @@ import (
 const (
 	DefaultMinBcryptCost = 12
 
-	clientSecretExists     = "ClientSecretExists"
-	allowedGrantTypesValid = "AllowedGrantTypesValid"
-	allowedScopesValid     = "AllowedScopesValid"
+	// clientSecretExpirationWarningPeriod is how long before a client secret expires its OIDCClient starts to warn
+	// about it, to give the admin time to rotate it.
+	clientSecretExpirationWarningPeriod = 7 * 24 * time.Hour
+
+	clientSecretExists       = "ClientSecretExists"
+	clientSecretsNotExpiring = "ClientSecretsNotExpiring"
+	allowedGrantTypesValid   = "AllowedGrantTypesValid"
+	allowedScopesValid       = "AllowedScopesValid"
+	groupsClaimValid         = "GroupsClaimValid"
 
 	reasonSuccess                  = "Success"
 	reasonMissingRequiredValue     = "MissingRequiredValue"
 	reasonNoClientSecretFound      = "NoClientSecretFound"
 	reasonInvalidClientSecretFound = "InvalidClientSecretFound"
+	reasonClientSecretExpiringSoon = "ClientSecretExpiringSoon"
+	reasonInvalidPattern           = "InvalidPattern"
 
 	allowedGrantTypesFieldName = "allowedGrantTypes"
 	allowedScopesFieldName     = "allowedScopes"
+	groupsClaimFieldName       = "groupsClaim"
+	clientCredentialsFieldName = "clientCredentials"
 )
 
 // Validate validates the OIDCClient and its corresponding client secret storage Secret.
 // When the corresponding client secret storage Secret was not found, pass nil to this function to
 // get the validation error for that case. It returns a bool to indicate if the client is valid,
-// along with a slice of conditions containing more details, and the list of client secrets in the
-// case that the client was valid.
-func Validate(oidcClient *v1alpha1.OIDCClient, secret *v1.Secret, minBcryptCost int) (bool, []*v1alpha1.Condition, []string) {
-	conds := make([]*v1alpha1.Condition, 0, 3)
+// along with a slice of conditions containing more details, and the list of client secrets which have
+// not expired in the case that the client was valid. Client secrets which will expire soon cause a
+// warning condition, but do not make the client invalid.
+func Validate(oidcClient *v1alpha1.OIDCClient, secret *v1.Secret, minBcryptCost int) (bool, []*metav1.Condition, []string) {
+	conds := make([]*metav1.Condition, 0, 5)
 
-	conds, clientSecrets := validateSecret(secret, conds, minBcryptCost)
+	conds, clientSecrets, expiringSecretIDs := validateSecret(oidcClient, secret, conds, minBcryptCost, time.Now())
 	conds = validateAllowedGrantTypes(oidcClient, conds)
 	conds = validateAllowedScopes(oidcClient, conds)
+	conds = validateGroupsClaim(oidcClient, conds)
 
 	valid := true
 	for _, cond := range conds {
-		if cond.Status != v1alpha1.ConditionTrue {
+		if cond.Status != metav1.ConditionTrue {
 			valid = false
 			break
 		}
 	}
+
+	conds = validateSecretsExpiration(expiringSecretIDs, conds)
 	return valid, conds, clientSecrets
 }
 
+// validateSecretsExpiration warns about the client secrets with the given IDs, which will expire soon.
+func validateSecretsExpiration(expiringSecretIDs []string, conditions []*metav1.Condition) []*metav1.Condition {
+	if len(expiringSecretIDs) == 0 {
+		return append(conditions, &metav1.Condition{
+			Type:    clientSecretsNotExpiring,
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonSuccess,
+			Message: fmt.Sprintf("no client secrets will expire within %s", clientSecretExpirationWarningPeriod),
+		})
+	}
+
+	return append(conditions, &metav1.Condition{
+		Type:   clientSecretsNotExpiring,
+		Status: metav1.ConditionFalse,
+		Reason: reasonClientSecretExpiringSoon,
+		Message: fmt.Sprintf("%d client secret(s) will expire within %s: %s",
+			len(expiringSecretIDs), clientSecretExpirationWarningPeriod, strings.Join(expiringSecretIDs, ", ")),
+	})
+}
+
+// CompileGroupsClaimPattern compiles one of the spec.groupsClaim.allowedPatterns of an OIDCClient. The compiled
+// regular expression only matches group names which are matched as a whole by the pattern.
+func CompileGroupsClaimPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// validateGroupsClaim checks if groupsClaim is valid on the OIDCClient.
+func validateGroupsClaim(oidcClient *v1alpha1.OIDCClient, conditions []*metav1.Condition) []*metav1.Condition {
+	var m []string
+
+	if oidcClient.Spec.GroupsClaim != nil {
+		for i, pattern := range oidcClient.Spec.GroupsClaim.AllowedPatterns {
+			// Compile the pattern as written, so that the error message does not show how it gets anchored.
+			if _, err := regexp.Compile(pattern); err != nil {
+				m = append(m, fmt.Sprintf("allowedPatterns[%d] is not a valid regular expression: %s", i, err.Error()))
+			}
+		}
+	}
+
+	if len(m) == 0 {
+		conditions = append(conditions, &metav1.Condition{
+			Type:    groupsClaimValid,
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonSuccess,
+			Message: fmt.Sprintf("%q is valid", groupsClaimFieldName),
+		})
+	} else {
+		conditions = append(conditions, &metav1.Condition{
+			Type:    groupsClaimValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonInvalidPattern,
+			Message: strings.Join(m, "; "),
+		})
+	}
+
+	return conditions
+}
+
 // validateAllowedScopes checks if allowedScopes is valid on the OIDCClient.
-func validateAllowedScopes(oidcClient *v1alpha1.OIDCClient, conditions []*v1alpha1.Condition) []*v1alpha1.Condition {
+func validateAllowedScopes(oidcClient *v1alpha1.OIDCClient, conditions []*metav1.Condition) []*metav1.Condition {
 	m := make([]string, 0, 4)
 
 	if !allowedScopesContains(oidcClient, oidcapi.ScopeOpenID) {
@@ -75,16 +150,16 @@ func validateAllowedScopes(oidcClient *v1alpha1.OIDCClient, conditions []*v1alph
 	}
 
 	if len(m) == 0 {
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:    allowedScopesValid,
-			Status:  v1alpha1.ConditionTrue,
+			Status:  metav1.ConditionTrue,
 			Reason:  reasonSuccess,
 			Message: fmt.Sprintf("%q is valid", allowedScopesFieldName),
 		})
 	} else {
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:    allowedScopesValid,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  reasonMissingRequiredValue,
 			Message: strings.Join(m, "; "),
 		})
@@ -94,8 +169,8 @@ func validateAllowedScopes(oidcClient *v1alpha1.OIDCClient, conditions []*v1alph
 }
 
 // validateAllowedGrantTypes checks if allowedGrantTypes is valid on the OIDCClient.
-func validateAllowedGrantTypes(oidcClient *v1alpha1.OIDCClient, conditions []*v1alpha1.Condition) []*v1alpha1.Condition {
-	m := make([]string, 0, 3)
+func validateAllowedGrantTypes(oidcClient *v1alpha1.OIDCClient, conditions []*metav1.Condition) []*metav1.Condition {
+	m := make([]string, 0, 5)
 
 	if !allowedGrantTypesContains(oidcClient, oidcapi.GrantTypeAuthorizationCode) {
 		m = append(m, fmt.Sprintf("%q must always be included in %q",
@@ -109,18 +184,36 @@ func validateAllowedGrantTypes(oidcClient *v1alpha1.OIDCClient, conditions []*v1
 		m = append(m, fmt.Sprintf("%q must be included in %q when %q is included in %q",
 			oidcapi.GrantTypeTokenExchange, allowedGrantTypesFieldName, oidcapi.ScopeRequestAudience, allowedScopesFieldName))
 	}
+	if allowedGrantTypesContains(oidcClient, oidcapi.GrantTypeClientCredentials) &&
+		(oidcClient.Spec.ClientCredentials == nil || len(oidcClient.Spec.ClientCredentials.AllowedAudiences) == 0) {
+		m = append(m, fmt.Sprintf("%q must list at least one audience when %q is included in %q",
+			clientCredentialsFieldName+".allowedAudiences", oidcapi.GrantTypeClientCredentials, allowedGrantTypesFieldName))
+	}
+	if oidcClient.Spec.ClientCredentials != nil && !allowedGrantTypesContains(oidcClient, oidcapi.GrantTypeClientCredentials) {
+		m = append(m, fmt.Sprintf("%q must be included in %q when %q is configured",
+			oidcapi.GrantTypeClientCredentials, allowedGrantTypesFieldName, clientCredentialsFieldName))
+	}
+	if oidcClient.Spec.ClientCredentials != nil {
+		for _, audience := range oidcClient.Spec.ClientCredentials.AllowedAudiences {
+			// These audiences are reserved for the ID tokens of the Supervisor's clients, see the token exchange.
+			if strings.Contains(audience, ".pinniped.dev") || audience == oidcapi.ClientIDPinnipedCLI {
+				m = append(m, fmt.Sprintf("%q cannot contain the reserved audience %q",
+					clientCredentialsFieldName+".allowedAudiences", audience))
+			}
+		}
+	}
 
 	if len(m) == 0 {
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:    allowedGrantTypesValid,
-			Status:  v1alpha1.ConditionTrue,
+			Status:  metav1.ConditionTrue,
 			Reason:  reasonSuccess,
 			Message: fmt.Sprintf("%q is valid", allowedGrantTypesFieldName),
 		})
 	} else {
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:    allowedGrantTypesValid,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  reasonMissingRequiredValue,
 			Message: strings.Join(m, "; "),
 		})
@@ -129,45 +222,52 @@ func validateAllowedGrantTypes(oidcClient *v1alpha1.OIDCClient, conditions []*v1
 	return conditions
 }
 
-// validateSecret checks if the client secret storage Secret is valid and contains at least one client secret.
-// It returns the updated conditions slice along with the client secrets found in that case that it is valid.
-func validateSecret(secret *v1.Secret, conditions []*v1alpha1.Condition, minBcryptCost int) ([]*v1alpha1.Condition, []string) {
+// validateSecret checks if the client secret storage Secret is valid and contains at least one client secret which
+// has not expired. It returns the updated conditions slice along with the client secrets which have not expired in
+// the case that it is valid, and the IDs of those client secrets which will expire soon.
+func validateSecret(
+	oidcClient *v1alpha1.OIDCClient,
+	secret *v1.Secret,
+	conditions []*metav1.Condition,
+	minBcryptCost int,
+	now time.Time,
+) ([]*metav1.Condition, []string, []string) {
 	emptyList := []string{}
 
 	if secret == nil {
 		// Invalid: no storage Secret found.
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:    clientSecretExists,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  reasonNoClientSecretFound,
 			Message: "no client secret found (no Secret storage found)",
 		})
-		return conditions, emptyList
+		return conditions, emptyList, nil
 	}
 
-	storedClientSecrets, err := oidcclientsecretstorage.ReadFromSecret(secret)
+	storedClientSecrets, metadata, err := oidcclientsecretstorage.ReadFromSecret(secret)
 	if err != nil {
 		// Invalid: storage Secret exists but its data could not be parsed.
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:    clientSecretExists,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  reasonNoClientSecretFound,
 			Message: fmt.Sprintf("error reading client secret storage: %s", err.Error()),
 		})
-		return conditions, emptyList
+		return conditions, emptyList, nil
 	}
 
 	// Successfully read the stored client secrets, so check if there are any stored in the list.
 	storedClientSecretsCount := len(storedClientSecrets)
 	if storedClientSecretsCount == 0 {
 		// Invalid: no client secrets stored.
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:    clientSecretExists,
-			Status:  v1alpha1.ConditionFalse,
+			Status:  metav1.ConditionFalse,
 			Reason:  reasonNoClientSecretFound,
 			Message: "no client secret found (empty list in storage)",
 		})
-		return conditions, emptyList
+		return conditions, emptyList, nil
 	}
 
 	// Check each hashed password's format and bcrypt cost.
@@ -186,24 +286,53 @@ func validateSecret(secret *v1.Secret, conditions []*v1alpha1.Condition, minBcry
 	}
 	if len(bcryptErrs) > 0 {
 		// Invalid: some stored client secrets were not valid.
-		conditions = append(conditions, &v1alpha1.Condition{
+		conditions = append(conditions, &metav1.Condition{
 			Type:   clientSecretExists,
-			Status: v1alpha1.ConditionFalse,
+			Status: metav1.ConditionFalse,
 			Reason: reasonInvalidClientSecretFound,
 			Message: fmt.Sprintf("%d stored client secrets found, but some were invalid, so none will be used: %s",
 				storedClientSecretsCount, strings.Join(bcryptErrs, "; ")),
 		})
-		return conditions, emptyList
+		return conditions, emptyList, nil
+	}
+
+	// Expired client secrets can no longer be used, so leave them out.
+	unexpiredClientSecrets := make([]string, 0, storedClientSecretsCount)
+	var expiringSecretIDs []string
+	for _, p := range storedClientSecrets {
+		expiresAt := metadata[p].ExpiresAt(oidcClient.Spec.ClientSecretPolicy)
+		if expiresAt != nil && !now.Before(expiresAt.Time) {
+			continue
+		}
+		unexpiredClientSecrets = append(unexpiredClientSecrets, p)
+		if expiresAt != nil && expiresAt.Sub(now) < clientSecretExpirationWarningPeriod {
+			expiringSecretIDs = append(expiringSecretIDs, oidcclientsecretstorage.SecretID(p))
+		}
+	}
+	if len(unexpiredClientSecrets) == 0 {
+		// Invalid: all stored client secrets have expired.
+		conditions = append(conditions, &metav1.Condition{
+			Type:    clientSecretExists,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonNoClientSecretFound,
+			Message: fmt.Sprintf("no client secret found (all %d stored client secrets have expired)", storedClientSecretsCount),
+		})
+		return conditions, emptyList, nil
+	}
+
+	message := fmt.Sprintf("%d client secret(s) found", len(unexpiredClientSecrets))
+	if expiredCount := storedClientSecretsCount - len(unexpiredClientSecrets); expiredCount > 0 {
+		message = fmt.Sprintf("%s (%d expired client secret(s) ignored)", message, expiredCount)
 	}
 
-	// Valid: has at least one client secret stored for this OIDC client, and all stored client secrets are valid.
-	conditions = append(conditions, &v1alpha1.Condition{
+	// Valid: has at least one unexpired client secret stored for this OIDC client, and all stored client secrets are valid.
+	conditions = append(conditions, &metav1.Condition{
 		Type:    clientSecretExists,
-		Status:  v1alpha1.ConditionTrue,
+		Status:  metav1.ConditionTrue,
 		Reason:  reasonSuccess,
-		Message: fmt.Sprintf("%d client secret(s) found", storedClientSecretsCount),
+		Message: message,
 	})
-	return conditions, storedClientSecrets
+	return conditions, unexpiredClientSecrets, expiringSecretIDs
 }
 
 func allowedGrantTypesContains(haystack *v1alpha1.OIDCClient, needle string) bool {
@@ -110,8 +110,9 @@ type UpstreamLDAPIdentityProviderI interface {
 	// UserAuthenticator adds an interface method for performing user authentication against the upstream LDAP provider.
 	authenticators.UserAuthenticator
 
-	// PerformRefresh performs a refresh against the upstream LDAP identity provider
-	PerformRefresh(ctx context.Context, storedRefreshAttributes RefreshAttributes) (groups []string, err error)
+	// PerformRefresh performs a refresh against the upstream LDAP identity provider. It returns the user's current DN,
+	// which is different from the stored DN when the user was moved or renamed in the directory since they logged in.
+	PerformRefresh(ctx context.Context, storedRefreshAttributes RefreshAttributes) (groups []string, dn string, err error)
 }
 
 // RefreshAttributes contains information about the user from the original login request
@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package provider
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 )
 
 // FederationDomainIssuer represents all of the settings and state for a downstream OIDC provider
@@ -17,10 +18,54 @@ type FederationDomainIssuer struct {
 	issuer     string
 	issuerHost string
 	issuerPath string
+	aliasHosts []string
+
+	previousIssuer string
+
+	allowedCORSOrigins []string
+
+	webAuthnEnforcement WebAuthnEnforcement
+	totpEnforcement     TOTPEnforcement
+
+	loginPolicy *loginpolicy.Policy
 }
 
+// WebAuthnEnforcement determines which users must verify a WebAuthn credential after logging in with a password.
+type WebAuthnEnforcement string
+
+const (
+	// WebAuthnEnforcementNone disables the WebAuthn second factor.
+	WebAuthnEnforcementNone WebAuthnEnforcement = ""
+
+	// WebAuthnEnforcementIfEnrolled requires a WebAuthn credential from the users who have enrolled one.
+	WebAuthnEnforcementIfEnrolled WebAuthnEnforcement = "IfEnrolled"
+
+	// WebAuthnEnforcementRequired requires a WebAuthn credential from all users.
+	WebAuthnEnforcementRequired WebAuthnEnforcement = "Required"
+)
+
+// TOTPEnforcement determines which users must enter a TOTP code after logging in with a password.
+type TOTPEnforcement string
+
+const (
+	// TOTPEnforcementNone disables the TOTP second factor.
+	TOTPEnforcementNone TOTPEnforcement = ""
+
+	// TOTPEnforcementIfEnrolled requires a TOTP code from the users who have enrolled a TOTP secret.
+	TOTPEnforcementIfEnrolled TOTPEnforcement = "IfEnrolled"
+
+	// TOTPEnforcementRequired requires a TOTP code from all users.
+	TOTPEnforcementRequired TOTPEnforcement = "Required"
+)
+
 func NewFederationDomainIssuer(issuer string) (*FederationDomainIssuer, error) {
-	p := FederationDomainIssuer{issuer: issuer}
+	return NewFederationDomainIssuerWithAliasHosts(issuer, nil)
+}
+
+// NewFederationDomainIssuerWithAliasHosts is like NewFederationDomainIssuer, but the issuer will also be
+// served at the same path on each of the alias hosts.
+func NewFederationDomainIssuerWithAliasHosts(issuer string, aliasHosts []string) (*FederationDomainIssuer, error) {
+	p := FederationDomainIssuer{issuer: issuer, aliasHosts: aliasHosts}
 	err := p.validate()
 	if err != nil {
 		return nil, err
@@ -33,33 +78,61 @@ func (p *FederationDomainIssuer) validate() error {
 		return constable.Error("federation domain must have an issuer")
 	}
 
-	issuerURL, err := url.Parse(p.issuer)
+	issuerURL, err := parseIssuerURL(p.issuer)
 	if err != nil {
-		return fmt.Errorf("could not parse issuer as URL: %w", err)
+		return err
+	}
+
+	p.issuerHost = issuerURL.Host
+	p.issuerPath = issuerURL.Path
+
+	return p.validateAliasHosts()
+}
+
+func parseIssuerURL(issuer string) (*url.URL, error) {
+	issuerURL, err := url.Parse(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse issuer as URL: %w", err)
 	}
 
 	if issuerURL.Scheme != "https" {
-		return constable.Error(`issuer must have "https" scheme`)
+		return nil, constable.Error(`issuer must have "https" scheme`)
 	}
 
 	if issuerURL.User != nil {
-		return constable.Error(`issuer must not have username or password`)
+		return nil, constable.Error(`issuer must not have username or password`)
 	}
 
 	if strings.HasSuffix(issuerURL.Path, "/") {
-		return constable.Error(`issuer must not have trailing slash in path`)
+		return nil, constable.Error(`issuer must not have trailing slash in path`)
 	}
 
 	if issuerURL.RawQuery != "" {
-		return constable.Error(`issuer must not have query`)
+		return nil, constable.Error(`issuer must not have query`)
 	}
 
 	if issuerURL.Fragment != "" {
-		return constable.Error(`issuer must not have fragment`)
+		return nil, constable.Error(`issuer must not have fragment`)
 	}
 
-	p.issuerHost = issuerURL.Host
-	p.issuerPath = issuerURL.Path
+	return issuerURL, nil
+}
+
+func (p *FederationDomainIssuer) validateAliasHosts() error {
+	seenHosts := map[string]bool{strings.ToLower(p.issuerHost): true}
+
+	for _, aliasHost := range p.aliasHosts {
+		aliasURL, err := url.Parse("https://" + aliasHost)
+		if err != nil || aliasURL.Host != aliasHost || aliasURL.Hostname() == "" || aliasURL.Path != "" ||
+			aliasURL.User != nil || aliasURL.RawQuery != "" || aliasURL.Fragment != "" {
+			return fmt.Errorf("alias host %q must be a hostname or IP address with an optional port", aliasHost)
+		}
+
+		if seenHosts[strings.ToLower(aliasHost)] {
+			return fmt.Errorf("alias host %q must not be the same as the issuer host or another alias host", aliasHost)
+		}
+		seenHosts[strings.ToLower(aliasHost)] = true
+	}
 
 	return nil
 }
@@ -75,3 +148,120 @@ func (p *FederationDomainIssuer) IssuerHost() string {
 func (p *FederationDomainIssuer) IssuerPath() string {
 	return p.issuerPath
 }
+
+// SetAllowedCORSOrigins validates and sets the origins of the browser-based applications which are allowed to make
+// cross-origin requests to the discovery, JWKS, and token endpoints.
+func (p *FederationDomainIssuer) SetAllowedCORSOrigins(origins []string) error {
+	for _, origin := range origins {
+		originURL, err := url.Parse(origin)
+		if err != nil || (originURL.Scheme != "https" && originURL.Scheme != "http") || originURL.Host == "" ||
+			originURL.Path != "" || originURL.User != nil || originURL.RawQuery != "" || originURL.Fragment != "" ||
+			strings.HasSuffix(origin, "?") || strings.HasSuffix(origin, "#") {
+			return fmt.Errorf("allowed CORS origin %q must be a scheme and host with an optional port, e.g. https://app.example.com", origin)
+		}
+	}
+	p.allowedCORSOrigins = origins
+	return nil
+}
+
+// AllowedCORSOrigins returns the origins which were most recently set by SetAllowedCORSOrigins.
+func (p *FederationDomainIssuer) AllowedCORSOrigins() []string {
+	return p.allowedCORSOrigins
+}
+
+// SetWebAuthnEnforcement validates and sets which users must verify a WebAuthn credential after logging in with the
+// password of an LDAP or Active Directory identity provider.
+func (p *FederationDomainIssuer) SetWebAuthnEnforcement(enforcement WebAuthnEnforcement) error {
+	switch enforcement {
+	case WebAuthnEnforcementNone, WebAuthnEnforcementIfEnrolled, WebAuthnEnforcementRequired:
+		p.webAuthnEnforcement = enforcement
+		return nil
+	default:
+		return fmt.Errorf("WebAuthn enforcement %q must be %q or %q", enforcement, WebAuthnEnforcementIfEnrolled, WebAuthnEnforcementRequired)
+	}
+}
+
+// WebAuthnEnforcement returns the enforcement which was most recently set by SetWebAuthnEnforcement.
+func (p *FederationDomainIssuer) WebAuthnEnforcement() WebAuthnEnforcement {
+	return p.webAuthnEnforcement
+}
+
+// SetTOTPEnforcement validates and sets which users must enter a TOTP code after logging in with the password of an
+// LDAP or Active Directory identity provider. It must be called after SetWebAuthnEnforcement, because only one second
+// factor can be enabled.
+func (p *FederationDomainIssuer) SetTOTPEnforcement(enforcement TOTPEnforcement) error {
+	switch enforcement {
+	case TOTPEnforcementNone:
+	case TOTPEnforcementIfEnrolled, TOTPEnforcementRequired:
+		if p.webAuthnEnforcement != WebAuthnEnforcementNone {
+			return constable.Error("TOTP enforcement cannot be combined with WebAuthn enforcement")
+		}
+	default:
+		return fmt.Errorf("TOTP enforcement %q must be %q or %q", enforcement, TOTPEnforcementIfEnrolled, TOTPEnforcementRequired)
+	}
+	p.totpEnforcement = enforcement
+	return nil
+}
+
+// TOTPEnforcement returns the enforcement which was most recently set by SetTOTPEnforcement.
+func (p *FederationDomainIssuer) TOTPEnforcement() TOTPEnforcement {
+	return p.totpEnforcement
+}
+
+// SetLoginPolicy validates and sets the policy which decides whether users who have authenticated with their
+// upstream identity provider are allowed to log in.
+func (p *FederationDomainIssuer) SetLoginPolicy(config loginpolicy.Config) error {
+	policy, err := loginpolicy.New(config)
+	if err != nil {
+		return fmt.Errorf("invalid login policy: %w", err)
+	}
+	p.loginPolicy = policy
+	return nil
+}
+
+// LoginPolicy returns the policy which was most recently set by SetLoginPolicy, or nil when all logins are allowed.
+func (p *FederationDomainIssuer) LoginPolicy() *loginpolicy.Policy {
+	return p.loginPolicy
+}
+
+// AliasIssuers returns the issuer as seen from each of the alias hosts, i.e. the issuer with its
+// host replaced by the alias host.
+func (p *FederationDomainIssuer) AliasIssuers() []string {
+	aliasIssuers := make([]string, 0, len(p.aliasHosts))
+	for _, aliasHost := range p.aliasHosts {
+		aliasIssuers = append(aliasIssuers, "https://"+aliasHost+p.issuerPath)
+	}
+	return aliasIssuers
+}
+
+// SetPreviousIssuer validates and sets the issuer URL which was used by this FederationDomain before its issuer was
+// changed, and which is still served during the grace period of the migration to the new issuer. The previous issuer
+// must not be the same as the issuer or one of the alias issuers. An empty string means that there is no previous issuer.
+func (p *FederationDomainIssuer) SetPreviousIssuer(previousIssuer string) error {
+	if previousIssuer == "" {
+		p.previousIssuer = ""
+		return nil
+	}
+
+	previousIssuerURL, err := parseIssuerURL(previousIssuer)
+	if err != nil {
+		return fmt.Errorf("invalid previous issuer: %w", err)
+	}
+
+	previousIssuerKey := strings.ToLower(previousIssuerURL.Host) + previousIssuerURL.Path
+	for _, issuer := range append([]string{p.issuer}, p.AliasIssuers()...) {
+		issuerURL, _ := url.Parse(issuer) // the issuer and alias hosts have already been validated
+		if strings.ToLower(issuerURL.Host)+issuerURL.Path == previousIssuerKey {
+			return fmt.Errorf("previous issuer %q must not be the same as the issuer or the issuer of an alias host", previousIssuer)
+		}
+	}
+
+	p.previousIssuer = previousIssuer
+	return nil
+}
+
+// PreviousIssuer returns the previous issuer which was most recently set by SetPreviousIssuer, or an empty string
+// when there is none.
+func (p *FederationDomainIssuer) PreviousIssuer() string {
+	return p.previousIssuer
+}
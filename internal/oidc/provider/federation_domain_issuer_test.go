@@ -1,19 +1,23 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package provider
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/oidc/loginpolicy"
 )
 
 func TestFederationDomainIssuerValidations(t *testing.T) {
 	tests := []struct {
-		name      string
-		issuer    string
-		wantError string
+		name       string
+		issuer     string
+		aliasHosts []string
+		wantError  string
 	}{
 		{
 			name:      "must have an issuer",
@@ -68,15 +72,214 @@ func TestFederationDomainIssuerValidations(t *testing.T) {
 			issuer:    "https://tuna.com/",
 			wantError: `issuer must not have trailing slash in path`,
 		},
+		{
+			name:       "with alias hosts",
+			issuer:     "https://tuna.com/fish",
+			aliasHosts: []string{"tuna.example.com", "tuna.com:8443", "127.0.0.1:1234", "[::1]"},
+		},
+		{
+			name:       "alias host with scheme",
+			issuer:     "https://tuna.com",
+			aliasHosts: []string{"https://tuna.example.com"},
+			wantError:  `alias host "https://tuna.example.com" must be a hostname or IP address with an optional port`,
+		},
+		{
+			name:       "alias host with path",
+			issuer:     "https://tuna.com",
+			aliasHosts: []string{"tuna.example.com/fish"},
+			wantError:  `alias host "tuna.example.com/fish" must be a hostname or IP address with an optional port`,
+		},
+		{
+			name:       "alias host with username",
+			issuer:     "https://tuna.com",
+			aliasHosts: []string{"username@tuna.example.com"},
+			wantError:  `alias host "username@tuna.example.com" must be a hostname or IP address with an optional port`,
+		},
+		{
+			name:       "empty alias host",
+			issuer:     "https://tuna.com",
+			aliasHosts: []string{""},
+			wantError:  `alias host "" must be a hostname or IP address with an optional port`,
+		},
+		{
+			name:       "alias host with only a port",
+			issuer:     "https://tuna.com",
+			aliasHosts: []string{":8443"},
+			wantError:  `alias host ":8443" must be a hostname or IP address with an optional port`,
+		},
+		{
+			name:       "alias host which is the same as the issuer host",
+			issuer:     "https://tuna.com/fish",
+			aliasHosts: []string{"TUNA.com"},
+			wantError:  `alias host "TUNA.com" must not be the same as the issuer host or another alias host`,
+		},
+		{
+			name:       "duplicate alias hosts",
+			issuer:     "https://tuna.com",
+			aliasHosts: []string{"tuna.example.com", "tuna.example.com"},
+			wantError:  `alias host "tuna.example.com" must not be the same as the issuer host or another alias host`,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := NewFederationDomainIssuer(tt.issuer)
+			_, err := NewFederationDomainIssuerWithAliasHosts(tt.issuer, tt.aliasHosts)
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFederationDomainIssuerAliasIssuers(t *testing.T) {
+	p, err := NewFederationDomainIssuerWithAliasHosts("https://tuna.com/fish", []string{"tuna.example.com", "tuna.com:8443"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://tuna.example.com/fish", "https://tuna.com:8443/fish"}, p.AliasIssuers())
+
+	p, err = NewFederationDomainIssuer("https://tuna.com")
+	require.NoError(t, err)
+	require.Empty(t, p.AliasIssuers())
+}
+
+func TestFederationDomainIssuerSetAllowedCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name      string
+		origins   []string
+		wantError string
+	}{
+		{name: "no origins"},
+		{name: "origins with and without ports", origins: []string{"https://app.example.com", "http://localhost:3000"}},
+		{name: "wildcard", origins: []string{"*"}, wantError: `allowed CORS origin "*" must be a scheme and host with an optional port, e.g. https://app.example.com`},
+		{name: "without scheme", origins: []string{"app.example.com"}, wantError: `allowed CORS origin "app.example.com" must be a scheme and host with an optional port, e.g. https://app.example.com`},
+		{name: "with path", origins: []string{"https://app.example.com/"}, wantError: `allowed CORS origin "https://app.example.com/" must be a scheme and host with an optional port, e.g. https://app.example.com`},
+		{name: "with other scheme", origins: []string{"ftp://app.example.com"}, wantError: `allowed CORS origin "ftp://app.example.com" must be a scheme and host with an optional port, e.g. https://app.example.com`},
+		{name: "with empty query", origins: []string{"https://app.example.com?"}, wantError: `allowed CORS origin "https://app.example.com?" must be a scheme and host with an optional port, e.g. https://app.example.com`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewFederationDomainIssuer("https://tuna.com/fish")
+			require.NoError(t, err)
+
+			err = p.SetAllowedCORSOrigins(tt.origins)
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				require.Empty(t, p.AllowedCORSOrigins())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.origins, p.AllowedCORSOrigins())
+			}
+		})
+	}
+}
+
+func TestFederationDomainIssuerSetWebAuthnEnforcement(t *testing.T) {
+	tests := []struct {
+		name        string
+		enforcement WebAuthnEnforcement
+		wantError   string
+	}{
+		{name: "disabled", enforcement: WebAuthnEnforcementNone},
+		{name: "if enrolled", enforcement: WebAuthnEnforcementIfEnrolled},
+		{name: "required", enforcement: WebAuthnEnforcementRequired},
+		{name: "unknown", enforcement: "Sometimes", wantError: `WebAuthn enforcement "Sometimes" must be "IfEnrolled" or "Required"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewFederationDomainIssuer("https://tuna.com/fish")
+			require.NoError(t, err)
+
+			err = p.SetWebAuthnEnforcement(tt.enforcement)
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				require.Equal(t, WebAuthnEnforcementNone, p.WebAuthnEnforcement())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.enforcement, p.WebAuthnEnforcement())
+			}
+		})
+	}
+}
+
+func TestFederationDomainIssuerSetTOTPEnforcement(t *testing.T) {
+	tests := []struct {
+		name        string
+		webAuthn    WebAuthnEnforcement
+		enforcement TOTPEnforcement
+		wantError   string
+	}{
+		{name: "disabled", enforcement: TOTPEnforcementNone},
+		{name: "disabled with WebAuthn", webAuthn: WebAuthnEnforcementRequired, enforcement: TOTPEnforcementNone},
+		{name: "if enrolled", enforcement: TOTPEnforcementIfEnrolled},
+		{name: "required", enforcement: TOTPEnforcementRequired},
+		{name: "unknown", enforcement: "Sometimes", wantError: `TOTP enforcement "Sometimes" must be "IfEnrolled" or "Required"`},
+		{name: "with WebAuthn", webAuthn: WebAuthnEnforcementIfEnrolled, enforcement: TOTPEnforcementRequired, wantError: "TOTP enforcement cannot be combined with WebAuthn enforcement"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewFederationDomainIssuer("https://tuna.com/fish")
+			require.NoError(t, err)
+			require.NoError(t, p.SetWebAuthnEnforcement(tt.webAuthn))
+
+			err = p.SetTOTPEnforcement(tt.enforcement)
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				require.Equal(t, TOTPEnforcementNone, p.TOTPEnforcement())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.enforcement, p.TOTPEnforcement())
+			}
+		})
+	}
+}
+
+func TestFederationDomainIssuerSetLoginPolicy(t *testing.T) {
+	p, err := NewFederationDomainIssuer("https://tuna.com/fish")
+	require.NoError(t, err)
+	require.Nil(t, p.LoginPolicy())
+
+	require.NoError(t, p.SetLoginPolicy(loginpolicy.Config{AllowedGroups: []string{"admins"}}))
+	require.NotNil(t, p.LoginPolicy())
+	require.NoError(t, p.LoginPolicy().Evaluate("pinny", []string{"admins"}, "", time.Now()))
+
+	err = p.SetLoginPolicy(loginpolicy.Config{AllowedSourceCIDRs: []string{"10.0.0.1"}})
+	require.EqualError(t, err, `invalid login policy: allowed source CIDR "10.0.0.1" must be an IP address range in CIDR notation, e.g. 10.0.0.0/8`)
+
+	require.NoError(t, p.SetLoginPolicy(loginpolicy.Config{}))
+	require.Nil(t, p.LoginPolicy())
+}
+
+func TestFederationDomainIssuerSetPreviousIssuer(t *testing.T) {
+	tests := []struct {
+		name           string
+		previousIssuer string
+		wantError      string
+	}{
+		{name: "no previous issuer"},
+		{name: "previous issuer with another path", previousIssuer: "https://tuna.com/old-fish"},
+		{name: "previous issuer with another host", previousIssuer: "https://old.tuna.com/fish"},
+		{name: "previous issuer with http scheme", previousIssuer: "http://old.tuna.com/fish", wantError: `invalid previous issuer: issuer must have "https" scheme`},
+		{name: "previous issuer with trailing slash", previousIssuer: "https://old.tuna.com/fish/", wantError: "invalid previous issuer: issuer must not have trailing slash in path"},
+		{name: "same as the issuer", previousIssuer: "https://TUNA.com/fish", wantError: `previous issuer "https://TUNA.com/fish" must not be the same as the issuer or the issuer of an alias host`},
+		{name: "same as an alias issuer", previousIssuer: "https://tuna.example.com/fish", wantError: `previous issuer "https://tuna.example.com/fish" must not be the same as the issuer or the issuer of an alias host`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewFederationDomainIssuerWithAliasHosts("https://tuna.com/fish", []string{"tuna.example.com"})
+			require.NoError(t, err)
+
+			err = p.SetPreviousIssuer(tt.previousIssuer)
 			if tt.wantError != "" {
 				require.EqualError(t, err, tt.wantError)
+				require.Empty(t, p.PreviousIssuer())
 			} else {
 				require.NoError(t, err)
+				require.Equal(t, tt.previousIssuer, p.PreviousIssuer())
 			}
 		})
 	}
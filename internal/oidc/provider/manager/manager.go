@@ -1,16 +1,22 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package manager
 
 import (
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	"go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/httputil/clientip"
+	"go.pinniped.dev/internal/httputil/cors"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/auth"
 	"go.pinniped.dev/internal/oidc/callback"
@@ -19,12 +25,18 @@ import (
 	"go.pinniped.dev/internal/oidc/dynamiccodec"
 	"go.pinniped.dev/internal/oidc/idpdiscovery"
 	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/lockout"
 	"go.pinniped.dev/internal/oidc/login"
 	"go.pinniped.dev/internal/oidc/oidcclientvalidator"
 	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/oidc/revocation"
 	"go.pinniped.dev/internal/oidc/token"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/pmetrics"
 	"go.pinniped.dev/internal/secret"
+	"go.pinniped.dev/internal/totpsecretstorage"
+	"go.pinniped.dev/internal/tracing"
+	"go.pinniped.dev/internal/webauthncredentialstorage"
 	"go.pinniped.dev/pkg/oidcclient/nonce"
 	"go.pinniped.dev/pkg/oidcclient/pkce"
 )
@@ -41,21 +53,32 @@ type Manager struct {
 	upstreamIDPs        oidc.UpstreamIdentityProvidersLister // in-memory cache of upstream IDPs
 	secretCache         *secret.Cache                        // in-memory cache of cryptographic material
 	secretsClient       corev1client.SecretInterface
+	sessionStorage      crud.Backend // where the sessions of all issuers are stored
 	oidcClientsClient   v1alpha1.OIDCClientInterface
+	lockoutTracker      *lockout.Tracker // in-memory record of failed password logins, shared by all issuers
+	auditLogger         auditlog.Logger  // where the audit events of all issuers are emitted
 }
 
 // NewManager returns an empty Manager.
 // nextHandler will be invoked for any requests that could not be handled by this manager's providers.
 // dynamicJWKSProvider will be used as an in-memory cache for per-issuer JWKS data.
 // upstreamIDPs will be used as an in-memory cache of currently configured upstream IDPs.
+// sessionStorage will be used to store the sessions of all providers.
+// lockoutTracker may be nil to disable lockouts after repeated failed password logins.
+// auditLogger will be used to emit the audit events of all providers.
 func NewManager(
 	nextHandler http.Handler,
 	dynamicJWKSProvider jwks.DynamicJWKSProvider,
 	upstreamIDPs oidc.UpstreamIdentityProvidersLister,
 	secretCache *secret.Cache,
 	secretsClient corev1client.SecretInterface,
+	sessionStorage crud.Backend,
 	oidcClientsClient v1alpha1.OIDCClientInterface,
+	lockoutTracker *lockout.Tracker,
+	auditLogger auditlog.Logger,
 ) *Manager {
+	registerMetrics()
+
 	return &Manager{
 		providerHandlers:    make(map[string]http.Handler),
 		nextHandler:         nextHandler,
@@ -63,7 +86,10 @@ func NewManager(
 		upstreamIDPs:        upstreamIDPs,
 		secretCache:         secretCache,
 		secretsClient:       secretsClient,
+		sessionStorage:      sessionStorage,
 		oidcClientsClient:   oidcClientsClient,
+		lockoutTracker:      lockoutTracker,
+		auditLogger:         auditLogger,
 	}
 }
 
@@ -86,83 +112,146 @@ func (m *Manager) SetProviders(federationDomains ...*provider.FederationDomainIs
 		oidc.CSRFCookieLifespan,
 		m.secretCache.GetCSRFCookieEncoderHashKey,
 		func() []byte { return nil },
-	)
+	).WithPreviousSigningKey(m.secretCache.GetPreviousCSRFCookieEncoderHashKey)
 
 	for _, incomingProvider := range federationDomains {
-		issuer := incomingProvider.Issuer()
-		issuerHostWithPath := strings.ToLower(incomingProvider.IssuerHost()) + "/" + incomingProvider.IssuerPath()
-
-		tokenHMACKeyGetter := wrapGetter(incomingProvider.Issuer(), m.secretCache.GetTokenHMACKey)
-
-		timeoutsConfiguration := oidc.DefaultOIDCTimeoutsConfiguration()
-
-		// Use NullStorage for the authorize endpoint because we do not actually want to store anything until
-		// the upstream callback endpoint is called later.
-		oauthHelperWithNullStorage := oidc.FositeOauth2Helper(
-			oidc.NewNullStorage(m.secretsClient, m.oidcClientsClient, oidcclientvalidator.DefaultMinBcryptCost),
-			issuer,
-			tokenHMACKeyGetter,
-			nil,
-			timeoutsConfiguration,
-		)
-
-		// For all the other endpoints, make another oauth helper with exactly the same settings except use real storage.
-		oauthHelperWithKubeStorage := oidc.FositeOauth2Helper(
-			oidc.NewKubeStorage(m.secretsClient, m.oidcClientsClient, timeoutsConfiguration, oidcclientvalidator.DefaultMinBcryptCost),
-			issuer,
-			tokenHMACKeyGetter,
-			m.dynamicJWKSProvider,
-			timeoutsConfiguration,
-		)
-
-		var upstreamStateEncoder = dynamiccodec.New(
-			timeoutsConfiguration.UpstreamStateParamLifespan,
-			wrapGetter(incomingProvider.Issuer(), m.secretCache.GetStateEncoderHashKey),
-			wrapGetter(incomingProvider.Issuer(), m.secretCache.GetStateEncoderBlockKey),
-		)
-
-		m.providerHandlers[(issuerHostWithPath + oidc.WellKnownEndpointPath)] = discovery.NewHandler(issuer)
-
-		m.providerHandlers[(issuerHostWithPath + oidc.JWKSEndpointPath)] = jwks.NewHandler(issuer, m.dynamicJWKSProvider)
-
-		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedIDPsPathV1Alpha1)] = idpdiscovery.NewHandler(m.upstreamIDPs)
-
-		m.providerHandlers[(issuerHostWithPath + oidc.AuthorizationEndpointPath)] = auth.NewHandler(
-			issuer,
-			m.upstreamIDPs,
-			oauthHelperWithNullStorage,
-			oauthHelperWithKubeStorage,
-			csrftoken.Generate,
-			pkce.Generate,
-			nonce.Generate,
-			upstreamStateEncoder,
-			csrfCookieEncoder,
-		)
-
-		m.providerHandlers[(issuerHostWithPath + oidc.CallbackEndpointPath)] = callback.NewHandler(
-			m.upstreamIDPs,
-			oauthHelperWithKubeStorage,
-			upstreamStateEncoder,
-			csrfCookieEncoder,
-			issuer+oidc.CallbackEndpointPath,
-		)
-
-		m.providerHandlers[(issuerHostWithPath + oidc.TokenEndpointPath)] = token.NewHandler(
-			m.upstreamIDPs,
-			oauthHelperWithKubeStorage,
-		)
-
-		m.providerHandlers[(issuerHostWithPath + oidc.PinnipedLoginPath)] = login.NewHandler(
-			upstreamStateEncoder,
-			csrfCookieEncoder,
-			login.NewGetHandler(incomingProvider.IssuerPath()+oidc.PinnipedLoginPath),
-			login.NewPostHandler(issuer, m.upstreamIDPs, oauthHelperWithKubeStorage),
-		)
-
-		plog.Debug("oidc provider manager added or updated issuer", "issuer", issuer)
+		m.addProviderHandlers(incomingProvider, incomingProvider.Issuer(), csrfCookieEncoder)
+
+		// Requests to an alias host are handled as if the issuer had the alias host.
+		for _, aliasIssuer := range incomingProvider.AliasIssuers() {
+			m.addProviderHandlers(incomingProvider, aliasIssuer, csrfCookieEncoder)
+		}
+
+		// During an issuer migration, the previous issuer is still served, so the sessions which were started with
+		// it can still be refreshed.
+		if previousIssuer := incomingProvider.PreviousIssuer(); previousIssuer != "" {
+			m.addProviderHandlers(incomingProvider, previousIssuer, csrfCookieEncoder)
+		}
 	}
 }
 
+// Providers returns the providers which were most recently set by SetProviders.
+func (m *Manager) Providers() []*provider.FederationDomainIssuer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]*provider.FederationDomainIssuer(nil), m.providers...)
+}
+
+// addProviderHandlers adds the routes of the given provider for one of its issuer URLs, i.e. its issuer, one of its
+// alias issuers, or its previous issuer. The secrets of the provider are always looked up using its issuer, so they
+// are shared by all of its issuer URLs.
+func (m *Manager) addProviderHandlers(incomingProvider *provider.FederationDomainIssuer, issuer string, csrfCookieEncoder oidc.Codec) {
+	issuerURL, _ := url.Parse(issuer) // the issuer has already been validated
+	issuerHostWithPath := strings.ToLower(issuerURL.Host) + "/" + issuerURL.Path
+
+	tokenHMACKeyGetter := wrapGetter(incomingProvider.Issuer(), m.secretCache.GetTokenHMACKey)
+
+	timeoutsConfiguration := oidc.DefaultOIDCTimeoutsConfiguration()
+
+	// Audit events from all issuer URLs of the provider are reported with its primary issuer.
+	auditLogger := auditlog.WithIssuer(m.auditLogger, incomingProvider.Issuer())
+
+	// Use NullStorage for the authorize endpoint because we do not actually want to store anything until
+	// the upstream callback endpoint is called later.
+	oauthHelperWithNullStorage := oidc.FositeOauth2Helper(
+		oidc.NewNullStorage(m.secretsClient, m.oidcClientsClient, oidcclientvalidator.DefaultMinBcryptCost),
+		issuer,
+		tokenHMACKeyGetter,
+		nil,
+		timeoutsConfiguration,
+	)
+
+	// For all the other endpoints, make another oauth helper with exactly the same settings except use real storage.
+	oauthHelperWithKubeStorage := oidc.FositeOauth2Helper(
+		oidc.NewKubeStorageWithSessionBackend(m.secretsClient, m.sessionStorage, m.oidcClientsClient, timeoutsConfiguration, oidcclientvalidator.DefaultMinBcryptCost),
+		issuer,
+		tokenHMACKeyGetter,
+		m.dynamicJWKSProvider,
+		timeoutsConfiguration,
+	)
+
+	var upstreamStateEncoder = dynamiccodec.New(
+		timeoutsConfiguration.UpstreamStateParamLifespan,
+		wrapGetter(incomingProvider.Issuer(), m.secretCache.GetStateEncoderHashKey),
+		wrapGetter(incomingProvider.Issuer(), m.secretCache.GetStateEncoderBlockKey),
+	).WithPreviousSigningKey(wrapGetter(incomingProvider.Issuer(), m.secretCache.GetPreviousStateEncoderHashKey))
+
+	// The WebAuthn and TOTP second factors of password logins are nil unless the FederationDomain enforces them.
+	webAuthn := login.NewWebAuthn(
+		incomingProvider.WebAuthnEnforcement(),
+		webauthncredentialstorage.New(m.secretsClient),
+		m.sessionStorage,
+		time.Now,
+	)
+	totp := login.NewTOTP(
+		incomingProvider.TOTPEnforcement(),
+		totpsecretstorage.New(m.secretsClient, m.secretCache.GetTOTPEncryptionKey),
+		m.sessionStorage,
+		time.Now,
+	)
+
+	// Browser-based apps from the allowed CORS origins may call the discovery, JWKS, and token endpoints directly.
+	allowedCORSOrigins := incomingProvider.AllowedCORSOrigins()
+
+	m.providerHandlers[(issuerHostWithPath + oidc.WellKnownEndpointPath)] = instrument(cors.Wrap(
+		discovery.NewHandler(issuer), allowedCORSOrigins, http.MethodGet), "discovery")
+
+	m.providerHandlers[(issuerHostWithPath + oidc.JWKSEndpointPath)] = instrument(cors.Wrap(
+		jwks.NewHandler(issuer, m.dynamicJWKSProvider), allowedCORSOrigins, http.MethodGet), "jwks")
+
+	m.providerHandlers[(issuerHostWithPath + oidc.PinnipedIDPsPathV1Alpha1)] = instrument(idpdiscovery.NewHandler(m.upstreamIDPs), "idp_discovery")
+
+	// The endpoints of the login flows are traced.
+	m.providerHandlers[(issuerHostWithPath + oidc.AuthorizationEndpointPath)] = instrument(tracing.WrapHandler(auth.NewHandler(
+		issuer,
+		m.upstreamIDPs,
+		oauthHelperWithNullStorage,
+		oauthHelperWithKubeStorage,
+		csrftoken.Generate,
+		pkce.Generate,
+		nonce.Generate,
+		upstreamStateEncoder,
+		csrfCookieEncoder,
+		m.lockoutTracker,
+		webAuthn,
+		totp,
+		incomingProvider.LoginPolicy(),
+		auditLogger,
+	), "authorize"), "authorize")
+
+	m.providerHandlers[(issuerHostWithPath + oidc.CallbackEndpointPath)] = instrument(tracing.WrapHandler(callback.NewHandler(
+		m.upstreamIDPs,
+		oauthHelperWithKubeStorage,
+		upstreamStateEncoder,
+		csrfCookieEncoder,
+		issuer+oidc.CallbackEndpointPath,
+		incomingProvider.LoginPolicy(),
+		auditLogger,
+	), "callback"), "callback")
+
+	m.providerHandlers[(issuerHostWithPath + oidc.TokenEndpointPath)] = instrument(tracing.WrapHandler(cors.Wrap(token.NewHandler(
+		incomingProvider.Issuer(),
+		m.upstreamIDPs,
+		oauthHelperWithKubeStorage,
+		auditLogger,
+	), allowedCORSOrigins, http.MethodPost), "token"), "token")
+
+	m.providerHandlers[(issuerHostWithPath + oidc.RevocationEndpointPath)] = instrument(tracing.WrapHandler(cors.Wrap(revocation.NewHandler(
+		oauthHelperWithKubeStorage,
+		auditLogger,
+	), allowedCORSOrigins, http.MethodPost), "revocation"), "revocation")
+
+	m.providerHandlers[(issuerHostWithPath + oidc.PinnipedLoginPath)] = instrument(tracing.WrapHandler(login.NewHandler(
+		upstreamStateEncoder,
+		csrfCookieEncoder,
+		login.NewGetHandler(issuerURL.Path+oidc.PinnipedLoginPath),
+		login.NewPostHandler(issuer, m.upstreamIDPs, oauthHelperWithKubeStorage, m.lockoutTracker, webAuthn, totp, incomingProvider.LoginPolicy(), auditLogger),
+	), "login"), "login")
+
+	plog.Debug("oidc provider manager added or updated issuer", "issuer", issuer)
+}
+
 // ServeHTTP implements the http.Handler interface.
 func (m *Manager) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	requestHandler := m.findHandler(req)
@@ -177,10 +266,21 @@ func (m *Manager) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 
 	if requestHandler == nil {
 		requestHandler = m.nextHandler // couldn't find an issuer to handle the request
+	} else if clientip.FromContext(req.Context()).Proto == "http" {
+		// Issuers always have the "https" scheme, and the browser would not send back our Secure cookies over plain
+		// HTTP anyway, so refuse requests which a trusted proxy says were sent to it without TLS.
+		http.Error(resp, `Bad request (issuer requires https)`, http.StatusBadRequest)
+		return
 	}
 	requestHandler.ServeHTTP(resp, req)
 }
 
+// instrument observes the durations of the requests to the endpoint in the metrics, labeled by the name of the endpoint
+// rather than by its path, which contains the issuer.
+func instrument(handler http.Handler, endpoint string) http.Handler {
+	return pmetrics.InstrumentHandler(handler, endpointRequestDurationMetric, endpoint)
+}
+
 func (m *Manager) findHandler(req *http.Request) http.Handler {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
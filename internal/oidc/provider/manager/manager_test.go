@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package manager
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -21,7 +22,10 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 
 	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/crud"
 	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/httputil/clientip"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/discovery"
 	"go.pinniped.dev/internal/oidc/jwks"
@@ -52,6 +56,9 @@ func TestManager(t *testing.T) {
 			issuer2                      = "https://example.com/some/path/more/deeply/nested/path" // note that this is a sub-path of the other issuer url
 			issuer2DifferentCaseHostname = "https://exAmPlE.Com/some/path/more/deeply/nested/path"
 			issuer2KeyID                 = "issuer2-key"
+			issuer1AliasHost             = "alias.example.com:8443"
+			issuer1Alias                 = "https://" + issuer1AliasHost + "/some/path"
+			issuer1Previous              = "https://old.example.com/old/path"
 			upstreamIDPAuthorizationURL  = "https://test-upstream.com/auth"
 			upstreamIDPName              = "test-idp"
 			upstreamIDPType              = "oidc"
@@ -221,6 +228,21 @@ func TestManager(t *testing.T) {
 				"did not perform any kube actions during the callback request, but should have")
 		}
 
+		requireRevocationRequestToBeHandled := func(requestIssuer string) {
+			recorder := httptest.NewRecorder()
+
+			revocationRequestBody := url.Values{
+				"token":     []string{"some-token-which-was-never-issued"},
+				"client_id": []string{downstreamClientID},
+			}.Encode()
+			subject.ServeHTTP(recorder, newPostRequest(requestIssuer+oidc.RevocationEndpointPath, revocationRequestBody))
+
+			r.False(fallbackHandlerWasCalled)
+
+			// Minimal check to ensure that the right endpoint was called. Revoking an unknown token is not an error.
+			r.Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+		}
+
 		requireJWKSRequestToBeHandled := func(requestIssuer, requestURLSuffix, expectedJWKKeyID string) *jose.JSONWebKeySet {
 			recorder := httptest.NewRecorder()
 
@@ -284,7 +306,7 @@ func TestManager(t *testing.T) {
 			cache.SetStateEncoderHashKey(issuer2, []byte("some-state-encoder-hash-key-2"))
 			cache.SetStateEncoderBlockKey(issuer2, []byte("16-bytes-STATE02"))
 
-			subject = NewManager(nextHandler, dynamicJWKSProvider, idpLister, &cache, secretsClient, oidcClientsClient)
+			subject = NewManager(nextHandler, dynamicJWKSProvider, idpLister, &cache, secretsClient, crud.NewSecretsBackend(secretsClient), oidcClientsClient, nil, auditlog.Nop())
 		})
 
 		when("given no providers via SetProviders()", func() {
@@ -293,6 +315,10 @@ func TestManager(t *testing.T) {
 				subject.ServeHTTP(httptest.NewRecorder(), newGetRequest("/anything"))
 				r.True(fallbackHandlerWasCalled)
 			})
+
+			it("returns no providers", func() {
+				r.Empty(subject.Providers())
+			})
 		})
 
 		newTestJWK := func(keyID string) *jose.JSONWebKey {
@@ -383,6 +409,10 @@ func TestManager(t *testing.T) {
 			// Hostnames are case-insensitive, so test that we can handle that.
 			requireTokenRequestToBeHandled(issuer1DifferentCaseHostname, downstreamAuthCode3, issuer1JWKS, issuer1)
 			requireTokenRequestToBeHandled(issuer2DifferentCaseHostname, downstreamAuthCode4, issuer2JWKS, issuer2)
+
+			requireRevocationRequestToBeHandled(issuer1)
+			requireRevocationRequestToBeHandled(issuer2)
+			requireRevocationRequestToBeHandled(issuer2DifferentCaseHostname)
 		}
 
 		when("given some valid providers via SetProviders()", func() {
@@ -404,6 +434,13 @@ func TestManager(t *testing.T) {
 				dynamicJWKSProvider.SetIssuerToJWKSMap(jwksMap, activeJWK)
 			})
 
+			it("returns the providers", func() {
+				providers := subject.Providers()
+				r.Len(providers, 2)
+				r.Equal(issuer1, providers[0].Issuer())
+				r.Equal(issuer2, providers[1].Issuer())
+			})
+
 			it("sends all non-matching host requests to the nextHandler", func() {
 				r.False(fallbackHandlerWasCalled)
 				wrongHostURL := strings.ReplaceAll(issuer1+oidc.WellKnownEndpointPath, "example.com", "wrong-host.com")
@@ -417,6 +454,25 @@ func TestManager(t *testing.T) {
 				r.True(fallbackHandlerWasCalled)
 			})
 
+			it("refuses requests to an issuer which a trusted proxy says were sent without TLS", func() {
+				_, trustedProxy, err := net.ParseCIDR("192.0.2.0/24") // the RemoteAddr of httptest requests
+				r.NoError(err)
+				handler := clientip.Wrap(subject, []*net.IPNet{trustedProxy})
+
+				req := newGetRequest(issuer1 + oidc.WellKnownEndpointPath)
+				req.Header.Set("X-Forwarded-Proto", "http")
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+				r.Equal(http.StatusBadRequest, recorder.Code)
+				r.Contains(recorder.Body.String(), "issuer requires https")
+
+				req = newGetRequest(issuer1 + oidc.WellKnownEndpointPath)
+				req.Header.Set("X-Forwarded-Proto", "https")
+				recorder = httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+				r.Equal(http.StatusOK, recorder.Code)
+			})
+
 			it("sends requests which match the issuer prefix but do not match any of that provider's known paths to the nextHandler", func() {
 				r.False(fallbackHandlerWasCalled)
 				subject.ServeHTTP(httptest.NewRecorder(), newGetRequest(issuer1+"/unhandled-sub-path"))
@@ -451,5 +507,149 @@ func TestManager(t *testing.T) {
 				requireRoutesMatchingRequestsToAppropriateProvider()
 			})
 		})
+
+		when("given a valid provider with an alias host via SetProviders()", func() {
+			it.Before(func() {
+				p1, err := provider.NewFederationDomainIssuerWithAliasHosts(issuer1, []string{issuer1AliasHost})
+				r.NoError(err)
+				subject.SetProviders(p1)
+
+				// The JWKS observer controller stores the same keys for each of the provider's issuer URLs.
+				jwksMap := map[string]*jose.JSONWebKeySet{
+					issuer1:      {Keys: []jose.JSONWebKey{*newTestJWK(issuer1KeyID)}},
+					issuer1Alias: {Keys: []jose.JSONWebKey{*newTestJWK(issuer1KeyID)}},
+				}
+				activeJWK := map[string]*jose.JSONWebKey{
+					issuer1:      newTestJWK(issuer1KeyID),
+					issuer1Alias: newTestJWK(issuer1KeyID),
+				}
+				dynamicJWKSProvider.SetIssuerToJWKSMap(jwksMap, activeJWK)
+			})
+
+			it("serves the provider at both hosts, using the issuer URL which matches the host of the request", func() {
+				requireDiscoveryRequestToBeHandled(issuer1, "", issuer1)
+				requireDiscoveryRequestToBeHandled(issuer1Alias, "", issuer1Alias)
+				requireDiscoveryRequestToBeHandled("https://ALIAS.example.com:8443/some/path", "", issuer1Alias)
+
+				requireJWKSRequestToBeHandled(issuer1, "", issuer1KeyID)
+				issuer1AliasJWKS := requireJWKSRequestToBeHandled(issuer1Alias, "", issuer1KeyID)
+
+				authRequestParams := "?" + url.Values{
+					"response_type":         []string{"code"},
+					"scope":                 []string{"openid profile email username groups"},
+					"client_id":             []string{downstreamClientID},
+					"state":                 []string{"some-state-value-with-enough-bytes-to-exceed-min-allowed"},
+					"nonce":                 []string{"some-nonce-value-with-enough-bytes-to-exceed-min-allowed"},
+					"code_challenge":        []string{testutil.SHA256(downstreamPKCECodeVerifier)},
+					"code_challenge_method": []string{"S256"},
+					"redirect_uri":          []string{downstreamRedirectURL},
+				}.Encode()
+
+				csrfCookieValue, upstreamStateParam := requireAuthorizationRequestToBeHandled(issuer1Alias, authRequestParams, upstreamIDPAuthorizationURL)
+				callbackRequestParams := "?" + url.Values{
+					"code":  []string{"some-fake-code"},
+					"state": []string{upstreamStateParam},
+				}.Encode()
+				downstreamAuthCode := requireCallbackRequestToBeHandled(issuer1Alias, callbackRequestParams, csrfCookieValue)
+				requireTokenRequestToBeHandled(issuer1Alias, downstreamAuthCode, issuer1AliasJWKS, issuer1Alias)
+			})
+
+			it("sends requests for other hosts to the nextHandler", func() {
+				r.False(fallbackHandlerWasCalled)
+				subject.ServeHTTP(httptest.NewRecorder(), newGetRequest("https://alias.example.com/some/path"+oidc.WellKnownEndpointPath))
+				r.True(fallbackHandlerWasCalled)
+			})
+		})
+
+		when("given a valid provider with a previous issuer via SetProviders()", func() {
+			it.Before(func() {
+				p1, err := provider.NewFederationDomainIssuer(issuer1)
+				r.NoError(err)
+				r.NoError(p1.SetPreviousIssuer(issuer1Previous))
+				subject.SetProviders(p1)
+
+				// The JWKS observer controller stores the same keys for the previous issuer.
+				jwksMap := map[string]*jose.JSONWebKeySet{
+					issuer1:         {Keys: []jose.JSONWebKey{*newTestJWK(issuer1KeyID)}},
+					issuer1Previous: {Keys: []jose.JSONWebKey{*newTestJWK(issuer1KeyID)}},
+				}
+				activeJWK := map[string]*jose.JSONWebKey{
+					issuer1:         newTestJWK(issuer1KeyID),
+					issuer1Previous: newTestJWK(issuer1KeyID),
+				}
+				dynamicJWKSProvider.SetIssuerToJWKSMap(jwksMap, activeJWK)
+			})
+
+			it("serves the provider at both issuer URLs, using the issuer URL which matches the request", func() {
+				requireDiscoveryRequestToBeHandled(issuer1, "", issuer1)
+				requireDiscoveryRequestToBeHandled(issuer1Previous, "", issuer1Previous)
+
+				requireJWKSRequestToBeHandled(issuer1, "", issuer1KeyID)
+				issuer1PreviousJWKS := requireJWKSRequestToBeHandled(issuer1Previous, "", issuer1KeyID)
+
+				authRequestParams := "?" + url.Values{
+					"response_type":         []string{"code"},
+					"scope":                 []string{"openid profile email username groups"},
+					"client_id":             []string{downstreamClientID},
+					"state":                 []string{"some-state-value-with-enough-bytes-to-exceed-min-allowed"},
+					"nonce":                 []string{"some-nonce-value-with-enough-bytes-to-exceed-min-allowed"},
+					"code_challenge":        []string{testutil.SHA256(downstreamPKCECodeVerifier)},
+					"code_challenge_method": []string{"S256"},
+					"redirect_uri":          []string{downstreamRedirectURL},
+				}.Encode()
+
+				csrfCookieValue, upstreamStateParam := requireAuthorizationRequestToBeHandled(issuer1Previous, authRequestParams, upstreamIDPAuthorizationURL)
+				callbackRequestParams := "?" + url.Values{
+					"code":  []string{"some-fake-code"},
+					"state": []string{upstreamStateParam},
+				}.Encode()
+				downstreamAuthCode := requireCallbackRequestToBeHandled(issuer1Previous, callbackRequestParams, csrfCookieValue)
+				requireTokenRequestToBeHandled(issuer1Previous, downstreamAuthCode, issuer1PreviousJWKS, issuer1Previous)
+			})
+
+			it("sends requests for the path of the previous issuer at the host of the issuer to the nextHandler", func() {
+				r.False(fallbackHandlerWasCalled)
+				subject.ServeHTTP(httptest.NewRecorder(), newGetRequest("https://example.com/old/path"+oidc.WellKnownEndpointPath))
+				r.True(fallbackHandlerWasCalled)
+			})
+		})
+
+		when("given a valid provider with allowed CORS origins via SetProviders()", func() {
+			const allowedOrigin = "https://app.example.com"
+
+			it.Before(func() {
+				p1, err := provider.NewFederationDomainIssuer(issuer1)
+				r.NoError(err)
+				r.NoError(p1.SetAllowedCORSOrigins([]string{allowedOrigin}))
+				subject.SetProviders(p1)
+			})
+
+			it("allows cross-origin requests from the allowed origins to the discovery, JWKS, and token endpoints", func() {
+				for _, path := range []string{oidc.WellKnownEndpointPath, oidc.JWKSEndpointPath, oidc.TokenEndpointPath} {
+					for origin, wantAllowOrigin := range map[string]string{allowedOrigin: allowedOrigin, "https://evil.example.com": ""} {
+						preflight := httptest.NewRequest(http.MethodOptions, issuer1+path, nil)
+						preflight.Header.Set("Origin", origin)
+						preflight.Header.Set("Access-Control-Request-Method", http.MethodPost)
+						recorder := httptest.NewRecorder()
+
+						subject.ServeHTTP(recorder, preflight)
+
+						r.Equal(wantAllowOrigin, recorder.Header().Get("Access-Control-Allow-Origin"), "path %s, origin %s", path, origin)
+						r.Equal([]string{"Origin"}, recorder.Header().Values("Vary"), "path %s, origin %s", path, origin)
+					}
+				}
+			})
+
+			it("does not allow cross-origin requests to the authorize endpoint", func() {
+				preflight := httptest.NewRequest(http.MethodOptions, issuer1+oidc.AuthorizationEndpointPath, nil)
+				preflight.Header.Set("Origin", allowedOrigin)
+				preflight.Header.Set("Access-Control-Request-Method", http.MethodPost)
+				recorder := httptest.NewRecorder()
+
+				subject.ServeHTTP(recorder, preflight)
+
+				r.Empty(recorder.Header().Get("Access-Control-Allow-Origin"))
+			})
+		})
 	})
 }
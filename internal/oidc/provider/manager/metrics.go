@@ -0,0 +1,35 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package manager
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"go.pinniped.dev/internal/pmetrics"
+)
+
+// This metric is labeled by the name of the endpoint, e.g. token, rather than by the FederationDomain issuer, so that its
+// cardinality does not grow with the number of FederationDomains.
+var (
+	endpointRequestDurationMetric = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemSupervisorEndpoint,
+		Name:           "request_duration_seconds",
+		Help:           "Duration of the requests to the endpoints of the FederationDomains by endpoint and HTTP status code.",
+		Buckets:        metrics.DefBuckets,
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"endpoint", "code"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		pmetrics.ApplyLatencyBuckets(endpointRequestDurationMetric.HistogramOpts)
+		legacyregistry.MustRegister(endpointRequestDurationMetric)
+	})
+}
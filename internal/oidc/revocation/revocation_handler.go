@@ -0,0 +1,50 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package revocation provides a handler for the OAuth 2.0 token revocation endpoint (RFC 7009).
+package revocation
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/ory/fosite"
+
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/httputil/clientip"
+	"go.pinniped.dev/internal/httputil/httperr"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/plog"
+)
+
+// NewHandler returns a handler which revokes a refresh token or access token on behalf of the client to which it
+// was issued. Revoking either kind of token deletes the storage of all tokens which were issued for the same session,
+// so the session can no longer be refreshed. Per RFC 7009, revoking a token which is unknown or already revoked is
+// not an error.
+func NewHandler(oauthHelper fosite.OAuth2Provider, auditLogger auditlog.Logger) http.Handler {
+	return httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		err := oauthHelper.NewRevocationRequest(r.Context(), r)
+		if err != nil {
+			plog.Info("revocation request error", oidc.FositeErrorForLog(err)...)
+		} else {
+			auditlog.WithSourceIP(auditLogger, clientip.FromContext(r.Context()).IP).Emit(auditlog.Event{
+				Type:     auditlog.EventTokenRevoked,
+				ClientID: clientIDFromRequest(r),
+			})
+		}
+		oauthHelper.WriteRevocationResponse(r.Context(), w, err)
+		return nil
+	})
+}
+
+// clientIDFromRequest returns the ID of the client which fosite authenticated, which is either the username of the
+// basic auth header (URL encoded, as required by RFC 6749) or the client_id of a public client's form post body.
+func clientIDFromRequest(r *http.Request) string {
+	if id, _, ok := r.BasicAuth(); ok {
+		if unescaped, err := url.QueryUnescape(id); err == nil {
+			return unescaped
+		}
+		return id
+	}
+	return r.PostForm.Get("client_id")
+}
@@ -0,0 +1,255 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package revocation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/square/go-jose.v2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/fositestorage/accesstoken"
+	"go.pinniped.dev/internal/fositestorage/refreshtoken"
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/testutil"
+)
+
+const (
+	goodIssuer           = "https://some-issuer.com"
+	goodRedirectURI      = "http://127.0.0.1/callback"
+	goodPKCECodeVerifier = "some-pkce-verifier-that-must-be-at-least-43-characters-to-meet-entropy-requirements"
+	pinnipedCLIClientID  = "pinniped-cli"
+)
+
+func TestRevocationEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// makeRequest returns the revocation request, given the tokens which were issued for the session.
+		makeRequest func(refreshToken, accessToken string) *http.Request
+
+		wantStatus           int
+		wantBodyContains     string
+		wantSessionRevoked   bool
+		wantAuditEventClient string
+	}{
+		{
+			name: "revoking the refresh token revokes the session",
+			makeRequest: func(refreshToken, _ string) *http.Request {
+				return formRequest(url.Values{
+					"token":           {refreshToken},
+					"token_type_hint": {"refresh_token"},
+					"client_id":       {pinnipedCLIClientID},
+				})
+			},
+			wantStatus:           http.StatusOK,
+			wantSessionRevoked:   true,
+			wantAuditEventClient: pinnipedCLIClientID,
+		},
+		{
+			name: "revoking the refresh token without a token type hint revokes the session",
+			makeRequest: func(refreshToken, _ string) *http.Request {
+				return formRequest(url.Values{
+					"token":     {refreshToken},
+					"client_id": {pinnipedCLIClientID},
+				})
+			},
+			wantStatus:           http.StatusOK,
+			wantSessionRevoked:   true,
+			wantAuditEventClient: pinnipedCLIClientID,
+		},
+		{
+			name: "revoking the access token revokes the session",
+			makeRequest: func(_, accessToken string) *http.Request {
+				return formRequest(url.Values{
+					"token":           {accessToken},
+					"token_type_hint": {"access_token"},
+					"client_id":       {pinnipedCLIClientID},
+				})
+			},
+			wantStatus:           http.StatusOK,
+			wantSessionRevoked:   true,
+			wantAuditEventClient: pinnipedCLIClientID,
+		},
+		{
+			name: "revoking an unknown token is not an error",
+			makeRequest: func(_, _ string) *http.Request {
+				return formRequest(url.Values{
+					"token":     {"pin_rt_some-unknown-token.some-signature"},
+					"client_id": {pinnipedCLIClientID},
+				})
+			},
+			wantStatus:           http.StatusOK,
+			wantAuditEventClient: pinnipedCLIClientID,
+		},
+		{
+			name: "unknown client",
+			makeRequest: func(refreshToken, _ string) *http.Request {
+				return formRequest(url.Values{
+					"token":     {refreshToken},
+					"client_id": {"some-unknown-client"},
+				})
+			},
+			wantStatus:       http.StatusUnauthorized,
+			wantBodyContains: `"error":"invalid_client"`,
+		},
+		{
+			name: "GET request",
+			makeRequest: func(_, _ string) *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/path/shouldn't/matter", nil)
+			},
+			wantStatus:       http.StatusBadRequest,
+			wantBodyContains: `"error":"invalid_request"`,
+		},
+		{
+			name: "empty body",
+			makeRequest: func(_, _ string) *http.Request {
+				return formRequest(url.Values{})
+			},
+			wantStatus:       http.StatusBadRequest,
+			wantBodyContains: `"error":"invalid_request"`,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			secrets := fake.NewSimpleClientset().CoreV1().Secrets("some-namespace")
+			oidcClientsClient := supervisorfake.NewSimpleClientset().ConfigV1alpha1().OIDCClients("some-namespace")
+			oauthStore := oidc.NewKubeStorage(secrets, oidcClientsClient, oidc.DefaultOIDCTimeoutsConfiguration(), bcrypt.MinCost)
+			oauthHelper := oidc.FositeOauth2Helper(oauthStore, goodIssuer, func() []byte {
+				return []byte("this needs to be at least 32 characters to meet entropy requirements")
+			}, makeJWKSProvider(t), oidc.DefaultOIDCTimeoutsConfiguration())
+
+			refreshToken, accessToken := issueTokens(t, oauthHelper)
+			testutil.RequireNumberOfSecretsMatchingLabelSelector(t, secrets, labels.Set{crud.SecretLabelKey: refreshtoken.TypeLabelValue}, 1)
+			testutil.RequireNumberOfSecretsMatchingLabelSelector(t, secrets, labels.Set{crud.SecretLabelKey: accesstoken.TypeLabelValue}, 1)
+
+			auditRecorder := &testutil.AuditRecorder{}
+			rsp := httptest.NewRecorder()
+			NewHandler(oauthHelper, auditRecorder).ServeHTTP(rsp, test.makeRequest(refreshToken, accessToken))
+
+			require.Equal(t, test.wantStatus, rsp.Code, rsp.Body.String())
+			if test.wantBodyContains != "" {
+				require.Contains(t, rsp.Body.String(), test.wantBodyContains)
+			}
+
+			wantRemainingTokens := 1
+			if test.wantSessionRevoked {
+				wantRemainingTokens = 0
+			}
+			testutil.RequireNumberOfSecretsMatchingLabelSelector(t, secrets, labels.Set{crud.SecretLabelKey: refreshtoken.TypeLabelValue}, wantRemainingTokens)
+			testutil.RequireNumberOfSecretsMatchingLabelSelector(t, secrets, labels.Set{crud.SecretLabelKey: accesstoken.TypeLabelValue}, wantRemainingTokens)
+
+			if test.wantAuditEventClient != "" {
+				require.Equal(t, []auditlog.Event{{Type: auditlog.EventTokenRevoked, ClientID: test.wantAuditEventClient}}, auditRecorder.Events())
+			} else {
+				require.Empty(t, auditRecorder.Events())
+			}
+		})
+	}
+}
+
+func formRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/path/shouldn't/matter", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func makeJWKSProvider(t *testing.T) jwks.DynamicJWKSProvider {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwksProvider := jwks.NewDynamicJWKSProvider()
+	jwksProvider.SetIssuerToJWKSMap(
+		nil, // public JWKS unused
+		map[string]*jose.JSONWebKey{
+			goodIssuer: {Key: key},
+		},
+	)
+	return jwksProvider
+}
+
+// issueTokens simulates the authorize and token endpoints to store a realistic session, and returns the refresh
+// token and access token which were issued for it.
+func issueTokens(t *testing.T, oauthHelper fosite.OAuth2Provider) (string, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	authRequest := &http.Request{Form: url.Values{
+		"response_type":         {"code"},
+		"scope":                 {"openid offline_access"},
+		"client_id":             {pinnipedCLIClientID},
+		"state":                 {"some-state-value-with-enough-bytes-to-exceed-min-allowed"},
+		"nonce":                 {"some-nonce-value-with-enough-bytes-to-exceed-min-allowed"},
+		"code_challenge":        {testutil.SHA256(goodPKCECodeVerifier)},
+		"code_challenge_method": {"S256"},
+		"redirect_uri":          {goodRedirectURI},
+	}}
+	authRequester, err := oauthHelper.NewAuthorizeRequest(ctx, authRequest)
+	require.NoError(t, err)
+	authRequester.GrantScope("openid")
+	authRequester.GrantScope("offline_access")
+
+	session := &psession.PinnipedSession{
+		Fosite: &openid.DefaultSession{
+			Claims: &jwt.IDTokenClaims{
+				Subject:     "https://issuer?sub=some-subject",
+				RequestedAt: time.Now(),
+				AuthTime:    time.Now(),
+				Extra:       map[string]interface{}{"azp": pinnipedCLIClientID},
+			},
+		},
+		Custom: &psession.CustomSessionData{
+			Username:     "some-username",
+			ProviderUID:  "some-provider-uid",
+			ProviderName: "some-provider-name",
+			ProviderType: psession.ProviderTypeLDAP,
+			LDAP:         &psession.LDAPSessionData{UserDN: "some-ldap-user-dn"},
+		},
+	}
+	authResponder, err := oauthHelper.NewAuthorizeResponse(ctx, authRequester, session)
+	require.NoError(t, err)
+
+	tokenRequest := formRequest(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {authResponder.GetCode()},
+		"redirect_uri":  {goodRedirectURI},
+		"client_id":     {pinnipedCLIClientID},
+		"code_verifier": {goodPKCECodeVerifier},
+	})
+	accessRequest, err := oauthHelper.NewAccessRequest(ctx, tokenRequest, psession.NewPinnipedSession())
+	require.NoError(t, err)
+	accessResponse, err := oauthHelper.NewAccessResponse(ctx, accessRequest)
+	require.NoError(t, err)
+
+	refreshToken, _ := accessResponse.GetExtra("refresh_token").(string)
+	require.NotEmpty(t, refreshToken)
+	require.NotEmpty(t, accessResponse.GetAccessToken())
+	return refreshToken, accessResponse.GetAccessToken()
+}
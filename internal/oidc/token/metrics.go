@@ -0,0 +1,47 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package token
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"go.pinniped.dev/internal/pmetrics"
+)
+
+// These metrics are labeled by the issuer of the FederationDomain, so platform teams can see the utilization of
+// each FederationDomain. Requests to the alias hosts of a FederationDomain are counted under its primary issuer.
+var (
+	tokensIssuedMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemSupervisorFederationDomain,
+		Name:           "tokens_issued_total",
+		Help:           "Number of successful token endpoint responses by FederationDomain issuer and grant type.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"issuer", "grant_type"})
+	upstreamRefreshLastSuccessMetric = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemSupervisorFederationDomain,
+		Name:           "upstream_refresh_last_success_timestamp_seconds",
+		Help:           "Unix time of the most recent successful upstream refresh by FederationDomain issuer and identity provider.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"issuer", "identity_provider"})
+	tokenReplaysDetectedMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace:      pmetrics.Namespace,
+		Subsystem:      pmetrics.SubsystemSupervisorFederationDomain,
+		Name:           "token_replays_detected_total",
+		Help:           "Number of replayed authorization codes and refresh tokens by FederationDomain issuer and token type.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"issuer", "token_type"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(tokensIssuedMetric, upstreamRefreshLastSuccessMetric, tokenReplaysDetectedMetric)
+	})
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package token provides a handler for the OIDC token endpoint.
@@ -9,48 +9,79 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/ory/fosite"
 	errorsx "github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/oauth2"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/warning"
 	"k8s.io/utils/strings/slices"
 
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/fositestorage"
+	"go.pinniped.dev/internal/httputil/clientip"
 	"go.pinniped.dev/internal/httputil/httperr"
 	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/downstreamsession"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/tracing"
 )
 
 func NewHandler(
+	issuer string,
 	idpLister oidc.UpstreamIdentityProvidersLister,
 	oauthHelper fosite.OAuth2Provider,
+	auditLogger auditlog.Logger,
 ) http.Handler {
+	registerMetrics()
+
 	return httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		auditLogger := auditlog.WithSourceIP(auditLogger, clientip.FromContext(r.Context()).IP)
+
+		// Fosite only reports a replayed authorization code or refresh token as an error, and revokes all tokens of its
+		// session while handling the request. Record the replays to learn which sessions were affected.
+		replayCtx, replayRecorder := fositestorage.WithReplayRecorder(r.Context())
+
 		session := psession.NewPinnipedSession()
-		accessRequest, err := oauthHelper.NewAccessRequest(r.Context(), r, session)
+		accessRequest, err := oauthHelper.NewAccessRequest(replayCtx, r, session)
 		if err != nil {
 			plog.Info("token request error", oidc.FositeErrorForLog(err)...)
+			// The session was not loaded from storage, so only the grant type and client are known.
+			auditLogger.Emit(auditEvent(auditlog.EventTokenRequestFailed, accessRequest, false, err))
+			for _, replay := range replayRecorder.Replays() {
+				plog.Warning("token replay detected, revoked all tokens of the session",
+					"sessionID", replay.Request.GetID(), "tokenType", replay.TokenType)
+				auditLogger.Emit(replayAuditEvent(replay, accessRequest, err))
+				tokenReplaysDetectedMetric.WithLabelValues(issuer, string(replay.TokenType)).Inc()
+			}
 			oauthHelper.WriteAccessError(r.Context(), w, accessRequest, err)
 			return nil
 		}
 
+		tracing.SetAttributes(r.Context(), traceAttributes(accessRequest)...)
+
 		// Check if we are performing a refresh grant.
 		if accessRequest.GetGrantTypes().ExactOne(oidcapi.GrantTypeRefreshToken) {
 			// The above call to NewAccessRequest has loaded the session from storage into the accessRequest variable.
 			// The session, requested scopes, and requested audience from the original authorize request was retrieved
 			// from the Kube storage layer and added to the accessRequest. Additionally, the audience and scopes may
 			// have already been granted on the accessRequest.
+			span := tracing.Start(r.Context(), "upstream refresh")
 			err = upstreamRefresh(r.Context(), accessRequest, idpLister)
+			tracing.End(span, err)
 			if err != nil {
 				plog.Info("upstream refresh error", oidc.FositeErrorForLog(err)...)
+				auditLogger.Emit(auditEvent(auditlog.EventTokenRequestFailed, accessRequest, true, err))
 				oauthHelper.WriteAccessError(r.Context(), w, accessRequest, err)
 				return nil
 			}
+			customSessionData := accessRequest.GetSession().(*psession.PinnipedSession).Custom
+			upstreamRefreshLastSuccessMetric.WithLabelValues(issuer, customSessionData.ProviderName).SetToCurrentTime()
 		}
 
 		// When we are in the authorization code flow, check if we have any warnings that previous handlers want us
@@ -65,19 +96,98 @@ func NewHandler(
 			}
 		}
 
+		span := tracing.Start(r.Context(), "downstream token issuance")
 		accessResponse, err := oauthHelper.NewAccessResponse(r.Context(), accessRequest)
+		tracing.End(span, err)
 		if err != nil {
 			plog.Info("token response error", oidc.FositeErrorForLog(err)...)
+			auditLogger.Emit(auditEvent(auditlog.EventTokenRequestFailed, accessRequest, true, err))
 			oauthHelper.WriteAccessError(r.Context(), w, accessRequest, err)
 			return nil
 		}
 
 		oauthHelper.WriteAccessResponse(r.Context(), w, accessRequest, accessResponse)
+		tokensIssuedMetric.WithLabelValues(issuer, strings.Join(accessRequest.GetGrantTypes(), " ")).Inc()
+		if accessRequest.GetGrantTypes().ExactOne(oidcapi.GrantTypeRefreshToken) {
+			auditLogger.Emit(auditEvent(auditlog.EventSessionRefreshed, accessRequest, true, nil))
+		} else {
+			auditLogger.Emit(auditEvent(auditlog.EventTokensIssued, accessRequest, true, nil))
+		}
 
 		return nil
 	})
 }
 
+// auditEvent returns an audit event about the token request. The session is only included when it has been loaded
+// from storage, since otherwise the request does not belong to any session yet. err may be nil.
+func auditEvent(eventType auditlog.EventType, accessRequest fosite.AccessRequester, includeSession bool, err error) auditlog.Event {
+	event := auditlog.Event{Type: eventType}
+	if accessRequest != nil { // fosite returns a nil request when no handler could handle the grant type
+		event.GrantType = strings.Join(accessRequest.GetGrantTypes(), " ")
+		if client := accessRequest.GetClient(); client != nil {
+			event.ClientID = client.GetID()
+		}
+		if includeSession {
+			// During a refresh, fosite gives the request a new ID and only uses the ID of the original session when
+			// storing the new tokens, so the ID of the request is not the ID of the session.
+			if !accessRequest.GetGrantTypes().ExactOne(oidcapi.GrantTypeRefreshToken) {
+				event.SessionID = accessRequest.GetID()
+			}
+			if session, ok := accessRequest.GetSession().(*psession.PinnipedSession); ok {
+				if session.Custom != nil {
+					event.IdentityProvider = session.Custom.ProviderName
+					event.Username = session.Custom.Username
+				}
+				// The actor of a delegating token exchange is recorded along with the user on whose behalf it acted.
+				if session.Fosite != nil && session.Fosite.Claims != nil {
+					if actor, ok := session.Fosite.Claims.Extra[oidcapi.IDTokenClaimActor].(map[string]interface{}); ok {
+						event.Actor, _ = actor[oidcapi.IDTokenClaimSubject].(string)
+					}
+				}
+			}
+		}
+	}
+	if err != nil {
+		rfc6749Error := fosite.ErrorToRFC6749Error(err)
+		event.Error = rfc6749Error.ErrorField
+		if description := rfc6749Error.GetDescription(); description != "" {
+			event.Error += ": " + description
+		}
+	}
+	return event
+}
+
+// replayAuditEvent returns an audit event about a replayed token. Unlike other failed token requests, the session is
+// known, because it was loaded from storage along with the replayed token.
+func replayAuditEvent(replay fositestorage.Replay, accessRequest fosite.AccessRequester, err error) auditlog.Event {
+	event := auditEvent(auditlog.EventTokenReplayDetected, accessRequest, false, err)
+	event.SessionID = replay.Request.GetID()
+	if client := replay.Request.GetClient(); client != nil {
+		event.ClientID = client.GetID()
+	}
+	if session, ok := replay.Request.GetSession().(*psession.PinnipedSession); ok && session.Custom != nil {
+		event.IdentityProvider = session.Custom.ProviderName
+		event.Username = session.Custom.Username
+	}
+	return event
+}
+
+// traceAttributes returns the attributes which correlate the trace of the token request with the other requests of
+// its session. Like the audit events, the ID of the session is not known during a refresh.
+func traceAttributes(accessRequest fosite.AccessRequester) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		tracing.GrantTypeKey.String(strings.Join(accessRequest.GetGrantTypes(), " ")),
+		tracing.ClientIDKey.String(accessRequest.GetClient().GetID()),
+	}
+	if !accessRequest.GetGrantTypes().ExactOne(oidcapi.GrantTypeRefreshToken) {
+		attrs = append(attrs, tracing.SessionIDKey.String(accessRequest.GetID()))
+	}
+	if session, ok := accessRequest.GetSession().(*psession.PinnipedSession); ok && session.Custom != nil {
+		attrs = append(attrs, tracing.UpstreamNameKey.String(session.Custom.ProviderName))
+	}
+	return attrs
+}
+
 func errMissingUpstreamSessionInternalError() *fosite.RFC6749Error {
 	return &fosite.RFC6749Error{
 		ErrorField:       "error",
@@ -212,8 +322,8 @@ func upstreamOIDCRefresh(
 			if err != nil {
 				return err
 			}
-			warnIfGroupsChanged(ctx, oldGroups, refreshedGroups, username, clientID)
-			session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups] = refreshedGroups
+			limitedGroups := downstreamsession.SetGroupsClaim(session.Fosite.Claims.Extra, refreshedGroups, username)
+			warnIfGroupsChanged(ctx, oldGroups, limitedGroups, username, clientID)
 		}
 	}
 
@@ -353,7 +463,7 @@ func upstreamLDAPRefresh(
 		return errorsx.WithStack(errMissingUpstreamSessionInternalError())
 	}
 	// run PerformRefresh
-	groups, err := p.PerformRefresh(ctx, provider.RefreshAttributes{
+	groups, newDN, err := p.PerformRefresh(ctx, provider.RefreshAttributes{
 		Username:             username,
 		Subject:              subject,
 		DN:                   dn,
@@ -366,11 +476,19 @@ func upstreamLDAPRefresh(
 			"Upstream refresh failed.").WithTrace(err).
 			WithDebugf("provider name: %q, provider type: %q", s.ProviderName, s.ProviderType)
 	}
+	// Remember the user's new DN when they were moved or renamed in the directory, so that future refreshes find them.
+	if newDN != "" && newDN != dn {
+		if s.ProviderType == psession.ProviderTypeLDAP {
+			s.LDAP.UserDN = newDN
+		} else {
+			s.ActiveDirectory.UserDN = newDN
+		}
+	}
 	groupsScope := slices.Contains(grantedScopes, oidcapi.ScopeGroups)
 	if groupsScope {
-		warnIfGroupsChanged(ctx, oldGroups, groups, username, clientID)
 		// Replace the old value with the new value.
-		session.Fosite.Claims.Extra[oidcapi.IDTokenClaimGroups] = groups
+		limitedGroups := downstreamsession.SetGroupsClaim(session.Fosite.Claims.Extra, groups, username)
+		warnIfGroupsChanged(ctx, oldGroups, limitedGroups, username, clientID)
 	}
 
 	return nil
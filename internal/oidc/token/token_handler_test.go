@@ -38,9 +38,11 @@ import (
 	"k8s.io/apiserver/pkg/warning"
 	"k8s.io/client-go/kubernetes/fake"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	metricstestutil "k8s.io/component-base/metrics/testutil"
 
 	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+	"go.pinniped.dev/internal/auditlog"
 	"go.pinniped.dev/internal/crud"
 	"go.pinniped.dev/internal/fositestorage/accesstoken"
 	"go.pinniped.dev/internal/fositestorage/authorizationcode"
@@ -281,6 +283,8 @@ type tokenEndpointResponseExpectedValues struct {
 	wantGrantedScopes                 []string
 	wantUsername                      string
 	wantGroups                        []string
+	wantIDTokenGroups                 []string // when not nil, the groups in the ID token differ from the stored wantGroups
+	wantIDTokenGroupsOverflow         bool
 	wantUpstreamRefreshCall           *expectedUpstreamRefresh
 	wantUpstreamOIDCValidateTokenCall *expectedUpstreamValidateTokens
 	wantCustomSessionDataStored       *psession.CustomSessionData
@@ -310,6 +314,22 @@ func addFullyCapableDynamicClientAndSecretToKubeResources(t *testing.T, supervis
 	require.NoError(t, kubeClient.Tracker().Add(secret))
 }
 
+func addDynamicClientWithGroupsClaimAndSecretToKubeResources(groupsClaim *configv1alpha1.OIDCClientGroupsClaim) func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+	return func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+		oidcClient, secret := testutil.FullyCapableOIDCClientAndStorageSecret(t,
+			"some-namespace",
+			dynamicClientID,
+			dynamicClientUID,
+			goodRedirectURI,
+			[]string{testutil.HashedPassword1AtGoMinCost, testutil.HashedPassword2AtGoMinCost},
+			oidcclientvalidator.Validate,
+		)
+		oidcClient.Spec.GroupsClaim = groupsClaim
+		require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+		require.NoError(t, kubeClient.Tracker().Add(secret))
+	}
+}
+
 func modifyAuthcodeTokenRequestWithDynamicClientAuth(r *http.Request, authCode string) {
 	r.Body = happyAuthcodeRequestBody(authCode).WithClientID("").ReadCloser() // No client_id in body.
 	r.SetBasicAuth(dynamicClientID, testutil.PlaintextPassword1)              // Use basic auth header instead.
@@ -398,6 +418,53 @@ func TestTokenEndpointAuthcodeExchange(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "request is valid and tokens are issued for dynamic client which filters its groups claim",
+			kubeResources: addDynamicClientWithGroupsClaimAndSecretToKubeResources(&configv1alpha1.OIDCClientGroupsClaim{
+				AllowedPatterns: []string{`group\d+`},
+			}),
+			authcodeExchange: authcodeExchangeInputs{
+				modifyAuthRequest: func(r *http.Request) {
+					addDynamicClientIDToFormPostBody(r)
+					r.Form.Set("scope", "openid pinniped:request-audience username groups")
+				},
+				modifyTokenRequest: modifyAuthcodeTokenRequestWithDynamicClientAuth,
+				want: tokenEndpointResponseExpectedValues{
+					wantStatus:            http.StatusOK,
+					wantClientID:          dynamicClientID,
+					wantSuccessBodyFields: []string{"id_token", "access_token", "token_type", "scope", "expires_in"}, // no refresh token
+					wantRequestedScopes:   []string{"openid", "pinniped:request-audience", "username", "groups"},
+					wantGrantedScopes:     []string{"openid", "pinniped:request-audience", "username", "groups"},
+					wantUsername:          goodUsername,
+					wantGroups:            goodGroups, // the stored session keeps all the groups
+					wantIDTokenGroups:     []string{"group1"},
+				},
+			},
+		},
+		{
+			name: "request is valid and tokens are issued for dynamic client which limits its groups claim",
+			kubeResources: addDynamicClientWithGroupsClaimAndSecretToKubeResources(&configv1alpha1.OIDCClientGroupsClaim{
+				MaxGroups: 1,
+			}),
+			authcodeExchange: authcodeExchangeInputs{
+				modifyAuthRequest: func(r *http.Request) {
+					addDynamicClientIDToFormPostBody(r)
+					r.Form.Set("scope", "openid pinniped:request-audience username groups")
+				},
+				modifyTokenRequest: modifyAuthcodeTokenRequestWithDynamicClientAuth,
+				want: tokenEndpointResponseExpectedValues{
+					wantStatus:                http.StatusOK,
+					wantClientID:              dynamicClientID,
+					wantSuccessBodyFields:     []string{"id_token", "access_token", "token_type", "scope", "expires_in"}, // no refresh token
+					wantRequestedScopes:       []string{"openid", "pinniped:request-audience", "username", "groups"},
+					wantGrantedScopes:         []string{"openid", "pinniped:request-audience", "username", "groups"},
+					wantUsername:              goodUsername,
+					wantGroups:                goodGroups, // the stored session keeps all the groups
+					wantIDTokenGroups:         []string{"group1"},
+					wantIDTokenGroupsOverflow: true,
+				},
+			},
+		},
 		{
 			name:          "request is valid and tokens are issued for dynamic client with additional claims",
 			kubeResources: addFullyCapableDynamicClientAndSecretToKubeResources,
@@ -1008,10 +1075,12 @@ func TestTokenEndpointTokenExchange(t *testing.T) { // tests for grant_type "urn
 		modifyStorage        func(t *testing.T, storage *oidc.KubeStorage, secrets v1.SecretInterface, pendingRequest *http.Request)
 		requestedAudience    string
 		kubeResources        func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset)
+		actorToken           func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string
 
 		wantStatus            int
 		wantErrorType         string
 		wantErrorDescContains string
+		wantActor             map[string]interface{}
 	}{
 		{
 			name:              "happy path",
@@ -1019,6 +1088,174 @@ func TestTokenEndpointTokenExchange(t *testing.T) { // tests for grant_type "urn
 			requestedAudience: "some-workload-cluster",
 			wantStatus:        http.StatusOK,
 		},
+		{
+			name:              "happy path with an actor token",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return signActorToken(t, jwtSigningKey, map[string]interface{}{"sub": "some-service", "azp": pinnipedCLIClientID})
+			},
+			wantStatus: http.StatusOK,
+			wantActor:  map[string]interface{}{"sub": "some-service"},
+		},
+		{
+			name:              "happy path with an actor token which has an actor of its own",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return signActorToken(t, jwtSigningKey, map[string]interface{}{
+					"sub": "some-service",
+					"azp": pinnipedCLIClientID,
+					"act": map[string]interface{}{"sub": "some-other-service", "act": map[string]interface{}{"sub": "yet-another-service"}},
+				})
+			},
+			wantStatus: http.StatusOK,
+			wantActor: map[string]interface{}{
+				"sub": "some-service",
+				"act": map[string]interface{}{"sub": "some-other-service", "act": map[string]interface{}{"sub": "yet-another-service"}},
+			},
+		},
+		{
+			name:              "happy path with an actor token using dynamic client",
+			kubeResources:     addFullyCapableDynamicClientAndSecretToKubeResources,
+			authcodeExchange:  doValidAuthCodeExchangeUsingDynamicClient,
+			requestedAudience: "some-workload-cluster",
+			modifyRequestParams: func(t *testing.T, params url.Values) {
+				params.Del("client_id") // client auth for dynamic clients must be in basic auth header
+			},
+			modifyRequestHeaders: func(r *http.Request) {
+				r.SetBasicAuth(dynamicClientID, testutil.PlaintextPassword1)
+			},
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return signActorToken(t, jwtSigningKey, map[string]interface{}{"sub": dynamicClientID, "azp": dynamicClientID, "aud": dynamicClientID})
+			},
+			wantStatus: http.StatusOK,
+			wantActor:  map[string]interface{}{"sub": dynamicClientID},
+		},
+		{
+			name:              "actor token without actor_token_type",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			modifyRequestParams: func(t *testing.T, params url.Values) {
+				params.Set("actor_token", "some-actor-token")
+			},
+			wantStatus:            http.StatusBadRequest,
+			wantErrorType:         "invalid_request",
+			wantErrorDescContains: `Unsupported 'actor_token_type' parameter value, must be 'urn:ietf:params:oauth:token-type:jwt'.`,
+		},
+		{
+			name:              "wrong actor_token_type",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			modifyRequestParams: func(t *testing.T, params url.Values) {
+				params.Set("actor_token", "some-actor-token")
+				params.Set("actor_token_type", "urn:ietf:params:oauth:token-type:access_token")
+			},
+			wantStatus:            http.StatusBadRequest,
+			wantErrorType:         "invalid_request",
+			wantErrorDescContains: `Unsupported 'actor_token_type' parameter value, must be 'urn:ietf:params:oauth:token-type:jwt'.`,
+		},
+		{
+			name:              "actor_token_type without actor token",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			modifyRequestParams: func(t *testing.T, params url.Values) {
+				params.Set("actor_token_type", "urn:ietf:params:oauth:token-type:jwt")
+			},
+			wantStatus:            http.StatusBadRequest,
+			wantErrorType:         "invalid_request",
+			wantErrorDescContains: "Missing 'actor_token' parameter.",
+		},
+		{
+			name:              "bogus actor token",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return "some-bogus-value"
+			},
+			wantStatus:            http.StatusUnauthorized,
+			wantErrorType:         "request_unauthorized",
+			wantErrorDescContains: `The request could not be authorized. Invalid 'actor_token' parameter value.`,
+		},
+		{
+			name:              "actor token signed by another key",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				require.NoError(t, err)
+				return signActorToken(t, otherKey, map[string]interface{}{"sub": "some-service", "azp": pinnipedCLIClientID})
+			},
+			wantStatus:            http.StatusUnauthorized,
+			wantErrorType:         "request_unauthorized",
+			wantErrorDescContains: `The request could not be authorized. Invalid 'actor_token' parameter value.`,
+		},
+		{
+			name:              "actor token issued by another issuer",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return signActorToken(t, jwtSigningKey, map[string]interface{}{"sub": "some-service", "azp": pinnipedCLIClientID, "iss": "https://some-other-issuer.com"})
+			},
+			wantStatus:            http.StatusUnauthorized,
+			wantErrorType:         "request_unauthorized",
+			wantErrorDescContains: `The request could not be authorized. Invalid 'actor_token' parameter value.`,
+		},
+		{
+			name:              "expired actor token",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return signActorToken(t, jwtSigningKey, map[string]interface{}{"sub": "some-service", "azp": pinnipedCLIClientID, "exp": time.Now().Add(-time.Minute).Unix()})
+			},
+			wantStatus:            http.StatusUnauthorized,
+			wantErrorType:         "request_unauthorized",
+			wantErrorDescContains: `The request could not be authorized. Invalid 'actor_token' parameter value.`,
+		},
+		{
+			name:              "actor token without a subject",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return signActorToken(t, jwtSigningKey, map[string]interface{}{"azp": pinnipedCLIClientID})
+			},
+			wantStatus:            http.StatusBadRequest,
+			wantErrorType:         "invalid_request",
+			wantErrorDescContains: "The 'actor_token' parameter value does not have a subject.",
+		},
+		{
+			name:              "actor token issued to another client",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return signActorToken(t, jwtSigningKey, map[string]interface{}{"sub": "some-service", "azp": dynamicClientID})
+			},
+			wantStatus:            http.StatusBadRequest,
+			wantErrorType:         "invalid_grant",
+			wantErrorDescContains: "The 'actor_token' parameter value was not issued to the OAuth 2.0 Client of this request.",
+		},
+		{
+			name:              "actor token for another client",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return signActorToken(t, jwtSigningKey, map[string]interface{}{"sub": "some-service", "azp": pinnipedCLIClientID, "aud": dynamicClientID})
+			},
+			wantStatus:            http.StatusUnauthorized,
+			wantErrorType:         "request_unauthorized",
+			wantErrorDescContains: `The request could not be authorized. Invalid 'actor_token' parameter value.`,
+		},
+		{
+			name:              "actor token which was minted by a token exchange for a workload cluster",
+			authcodeExchange:  doValidAuthCodeExchange,
+			requestedAudience: "some-workload-cluster",
+			actorToken: func(t *testing.T, jwtSigningKey *ecdsa.PrivateKey) string {
+				return signActorToken(t, jwtSigningKey, map[string]interface{}{"sub": "some-service", "azp": pinnipedCLIClientID, "aud": "some-other-workload-cluster"})
+			},
+			wantStatus:            http.StatusUnauthorized,
+			wantErrorType:         "request_unauthorized",
+			wantErrorDescContains: `The request could not be authorized. Invalid 'actor_token' parameter value.`,
+		},
 		{
 			name: "happy path with additional claims",
 			authcodeExchange: authcodeExchangeInputs{
@@ -1576,12 +1813,16 @@ func TestTokenEndpointTokenExchange(t *testing.T) { // tests for grant_type "urn
 			t.Parallel()
 
 			// Authcode exchange doesn't use the upstream provider cache, so just pass an empty cache.
-			subject, rsp, _, _, secrets, storage := exchangeAuthcodeForTokens(t,
+			subject, rsp, _, jwtSigningKey, secrets, storage := exchangeAuthcodeForTokens(t,
 				test.authcodeExchange, oidctestutil.NewUpstreamIDPListerBuilder().Build(), test.kubeResources)
 			var parsedAuthcodeExchangeResponseBody map[string]interface{}
 			require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &parsedAuthcodeExchangeResponseBody))
 
 			request := happyTokenExchangeRequest(test.requestedAudience, parsedAuthcodeExchangeResponseBody["access_token"].(string))
+			if test.actorToken != nil {
+				request.Form.Set("actor_token", test.actorToken(t, jwtSigningKey))
+				request.Form.Set("actor_token_type", "urn:ietf:params:oauth:token-type:jwt")
+			}
 			if test.modifyStorage != nil {
 				test.modifyStorage(t, storage, secrets, request)
 			}
@@ -1656,7 +1897,15 @@ func TestTokenEndpointTokenExchange(t *testing.T) { // tests for grant_type "urn
 			if len(test.authcodeExchange.want.wantAdditionalClaims) > 0 {
 				idTokenFields = append(idTokenFields, "additionalClaims")
 			}
+			if test.wantActor != nil {
+				idTokenFields = append(idTokenFields, "act")
+			}
 			require.ElementsMatch(t, idTokenFields, getMapKeys(tokenClaims))
+			if test.wantActor != nil {
+				require.Equal(t, test.wantActor, tokenClaims["act"])
+			} else {
+				require.Nil(t, tokenClaims["act"])
+			}
 
 			// Assert that the returned token has expected claims values.
 			require.NotEmpty(t, tokenClaims["jti"])
@@ -1723,11 +1972,328 @@ func TestTokenEndpointTokenExchange(t *testing.T) { // tests for grant_type "urn
 	}
 }
 
+func TestTokenEndpointTokenExchangeAuditEventsRecordTheActor(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	supervisorClient := supervisorfake.NewSimpleClientset()
+	secrets := kubeClient.CoreV1().Secrets("some-namespace")
+	oauthStore := oidc.NewKubeStorage(secrets, supervisorClient.ConfigV1alpha1().OIDCClients("some-namespace"), oidc.DefaultOIDCTimeoutsConfiguration(), bcrypt.MinCost)
+
+	authRequest := deepCopyRequestForm(happyAuthRequest)
+	authRequest.Form.Set("scope", "openid pinniped:request-audience username groups")
+	oauthHelper, authCode, jwtSigningKey := makeHappyOauthHelper(t, authRequest, oauthStore, generateJWTSigningKeyAndJWKSProvider, &psession.CustomSessionData{
+		Username:     goodUsername,
+		ProviderUID:  "ldap-resource-uid",
+		ProviderName: "some-ldap-idp",
+		ProviderType: psession.ProviderTypeLDAP,
+		LDAP:         &psession.LDAPSessionData{UserDN: "some-ldap-user-dn"},
+	}, nil)
+
+	auditRecorder := &testutil.AuditRecorder{}
+	subject := NewHandler(goodIssuer, oidctestutil.NewUpstreamIDPListerBuilder().Build(), oauthHelper, auditRecorder)
+
+	req := httptest.NewRequest("POST", "/path/shouldn't/matter", happyAuthcodeRequestBody(authCode).ReadCloser())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp := httptest.NewRecorder()
+	subject.ServeHTTP(rsp, req)
+	require.Equal(t, http.StatusOK, rsp.Code, rsp.Body.String())
+	var parsedResponseBody map[string]interface{}
+	require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &parsedResponseBody))
+
+	request := happyTokenExchangeRequest("some-workload-cluster", parsedResponseBody["access_token"].(string))
+	request.Form.Set("actor_token", signActorToken(t, jwtSigningKey, map[string]interface{}{"sub": "some-service", "azp": pinnipedCLIClientID}))
+	request.Form.Set("actor_token_type", "urn:ietf:params:oauth:token-type:jwt")
+	req = httptest.NewRequest("POST", "/path/shouldn't/matter", body(request.Form).ReadCloser())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp = httptest.NewRecorder()
+	subject.ServeHTTP(rsp, req)
+	require.Equal(t, http.StatusOK, rsp.Code, rsp.Body.String())
+
+	// The token exchange records both the user and the actor who acted on their behalf.
+	auditEvents := auditRecorder.Events()
+	require.Len(t, auditEvents, 2)
+	require.Empty(t, auditEvents[0].Actor)
+	require.Equal(t, auditlog.EventTokensIssued, auditEvents[1].Type)
+	require.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", auditEvents[1].GrantType)
+	require.Equal(t, "some-ldap-idp", auditEvents[1].IdentityProvider)
+	require.Equal(t, goodUsername, auditEvents[1].Username)
+	require.Equal(t, "some-service", auditEvents[1].Actor)
+}
+
+func TestTokenEndpointTokenExchangeRejectsExchangedTokensAsActorTokens(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	supervisorClient := supervisorfake.NewSimpleClientset()
+	secrets := kubeClient.CoreV1().Secrets("some-namespace")
+	oauthStore := oidc.NewKubeStorage(secrets, supervisorClient.ConfigV1alpha1().OIDCClients("some-namespace"), oidc.DefaultOIDCTimeoutsConfiguration(), bcrypt.MinCost)
+
+	authRequest := deepCopyRequestForm(happyAuthRequest)
+	authRequest.Form.Set("scope", "openid pinniped:request-audience username groups")
+	oauthHelper, authCode, _ := makeHappyOauthHelper(t, authRequest, oauthStore, generateJWTSigningKeyAndJWKSProvider, nil, nil)
+	subject := NewHandler(goodIssuer, oidctestutil.NewUpstreamIDPListerBuilder().Build(), oauthHelper, &testutil.AuditRecorder{})
+
+	exchange := func(t *testing.T, form url.Values) (int, map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/path/shouldn't/matter", body(form).ReadCloser())
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rsp := httptest.NewRecorder()
+		subject.ServeHTTP(rsp, req)
+		var parsedResponseBody map[string]interface{}
+		require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &parsedResponseBody))
+		return rsp.Code, parsedResponseBody
+	}
+
+	status, authcodeResponse := exchange(t, url.Values(happyAuthcodeRequestBody(authCode)))
+	require.Equal(t, http.StatusOK, status, authcodeResponse)
+	accessToken := authcodeResponse["access_token"].(string)
+
+	// A JWT which was minted by a token exchange is signed by the FederationDomain and its azp is the client, but it
+	// is for a workload cluster, so it cannot be used to claim the identity of its subject in another token exchange.
+	status, exchangeResponse := exchange(t, happyTokenExchangeRequest("some-workload-cluster", accessToken).Form)
+	require.Equal(t, http.StatusOK, status, exchangeResponse)
+	exchangedToken := exchangeResponse["access_token"].(string)
+
+	request := happyTokenExchangeRequest("some-other-workload-cluster", accessToken)
+	request.Form.Set("actor_token", exchangedToken)
+	request.Form.Set("actor_token_type", "urn:ietf:params:oauth:token-type:jwt")
+	status, errorResponse := exchange(t, request.Form)
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Equal(t, "request_unauthorized", errorResponse["error"])
+	require.Contains(t, errorResponse["error_description"], "Invalid 'actor_token' parameter value.")
+
+	// The ID token which the client got from the authcode exchange was issued to the client for itself, so it is allowed.
+	request = happyTokenExchangeRequest("some-other-workload-cluster", accessToken)
+	request.Form.Set("actor_token", authcodeResponse["id_token"].(string))
+	request.Form.Set("actor_token_type", "urn:ietf:params:oauth:token-type:jwt")
+	status, exchangeResponse = exchange(t, request.Form)
+	require.Equal(t, http.StatusOK, status, exchangeResponse)
+}
+
 type refreshRequestInputs struct {
 	modifyTokenRequest func(tokenRequest *http.Request, refreshToken string, accessToken string)
 	want               tokenEndpointResponseExpectedValues
 }
 
+func TestTokenEndpointClientCredentials(t *testing.T) { // tests for grant_type "client_credentials"
+	addClientCredentialsClientWithTokenProfileToKubeResources := func(clientCredentials *configv1alpha1.OIDCClientCredentials, tokenProfile *configv1alpha1.OIDCClientTokenProfile) func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+		return func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+			oidcClient, secret := testutil.FullyCapableOIDCClientAndStorageSecret(t,
+				"some-namespace",
+				dynamicClientID,
+				dynamicClientUID,
+				goodRedirectURI,
+				[]string{testutil.HashedPassword1AtGoMinCost},
+				oidcclientvalidator.Validate,
+			)
+			oidcClient.Spec.AllowedGrantTypes = append(oidcClient.Spec.AllowedGrantTypes, "client_credentials")
+			oidcClient.Spec.ClientCredentials = clientCredentials
+			oidcClient.Spec.TokenProfile = tokenProfile
+			require.NoError(t, supervisorClient.Tracker().Add(oidcClient))
+			require.NoError(t, kubeClient.Tracker().Add(secret))
+		}
+	}
+	addClientCredentialsClientToKubeResources := func(clientCredentials *configv1alpha1.OIDCClientCredentials) func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset) {
+		return addClientCredentialsClientWithTokenProfileToKubeResources(clientCredentials, nil)
+	}
+
+	clientCredentials := &configv1alpha1.OIDCClientCredentials{
+		AllowedAudiences: []string{"some-gateway", "other-gateway"},
+		AllowedScopes:    []string{"gateway:read", "gateway:write"},
+	}
+
+	tests := []struct {
+		name          string
+		kubeResources func(t *testing.T, supervisorClient *supervisorfake.Clientset, kubeClient *fake.Clientset)
+		body          body
+		useBasicAuth  bool
+
+		wantStatus            int
+		wantErrorResponseBody string
+		wantAudience          string
+		wantAudienceString    bool
+		wantScope             string
+	}{
+		{
+			name:          "happy path with a requested audience and scopes",
+			kubeResources: addClientCredentialsClientToKubeResources(clientCredentials),
+			body:          body{"grant_type": {"client_credentials"}, "audience": {"other-gateway"}, "scope": {"gateway:read gateway:write"}},
+			useBasicAuth:  true,
+			wantStatus:    http.StatusOK,
+			wantAudience:  "other-gateway",
+			wantScope:     "gateway:read gateway:write",
+		},
+		{
+			name: "happy path without a requested audience when only one audience is allowed",
+			kubeResources: addClientCredentialsClientToKubeResources(&configv1alpha1.OIDCClientCredentials{
+				AllowedAudiences: []string{"some-gateway"},
+			}),
+			body:         body{"grant_type": {"client_credentials"}},
+			useBasicAuth: true,
+			wantStatus:   http.StatusOK,
+			wantAudience: "some-gateway",
+		},
+		{
+			name: "happy path with a token profile",
+			kubeResources: addClientCredentialsClientWithTokenProfileToKubeResources(
+				&configv1alpha1.OIDCClientCredentials{AllowedAudiences: []string{"sts.amazonaws.com"}},
+				&configv1alpha1.OIDCClientTokenProfile{CloudProvider: configv1alpha1.OIDCClientCloudProviderAWS},
+			),
+			body:               body{"grant_type": {"client_credentials"}, "audience": {"sts.amazonaws.com"}},
+			useBasicAuth:       true,
+			wantStatus:         http.StatusOK,
+			wantAudience:       "sts.amazonaws.com",
+			wantAudienceString: true,
+		},
+		{
+			name:          "missing audience when more than one audience is allowed",
+			kubeResources: addClientCredentialsClientToKubeResources(clientCredentials),
+			body:          body{"grant_type": {"client_credentials"}},
+			useBasicAuth:  true,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorResponseBody: here.Doc(`
+				{
+					"error":             "invalid_request",
+					"error_description": "The request is missing a required parameter, includes an invalid parameter value, includes a parameter more than once, or is otherwise malformed. Missing 'audience' parameter."
+				}
+			`),
+		},
+		{
+			name:          "audience which is not allowed",
+			kubeResources: addClientCredentialsClientToKubeResources(clientCredentials),
+			body:          body{"grant_type": {"client_credentials"}, "audience": {"some-cluster"}},
+			useBasicAuth:  true,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorResponseBody: here.Doc(`
+				{
+					"error":             "invalid_request",
+					"error_description": "The request is missing a required parameter, includes an invalid parameter value, includes a parameter more than once, or is otherwise malformed. The OAuth 2.0 Client is not allowed to request audience 'some-cluster'."
+				}
+			`),
+		},
+		{
+			name:          "more than one audience",
+			kubeResources: addClientCredentialsClientToKubeResources(clientCredentials),
+			body:          body{"grant_type": {"client_credentials"}, "audience": {"some-gateway other-gateway"}},
+			useBasicAuth:  true,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorResponseBody: here.Doc(`
+				{
+					"error":             "invalid_request",
+					"error_description": "The request is missing a required parameter, includes an invalid parameter value, includes a parameter more than once, or is otherwise malformed. Only one 'audience' may be requested."
+				}
+			`),
+		},
+		{
+			name:          "scope which is not allowed",
+			kubeResources: addClientCredentialsClientToKubeResources(clientCredentials),
+			body:          body{"grant_type": {"client_credentials"}, "audience": {"some-gateway"}, "scope": {"gateway:read openid"}},
+			useBasicAuth:  true,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorResponseBody: here.Doc(`
+				{
+					"error":             "invalid_scope",
+					"error_description": "The requested scope is invalid, unknown, or malformed. The OAuth 2.0 Client is not allowed to request scope 'openid'."
+				}
+			`),
+		},
+		{
+			name:          "dynamic client which is not allowed to use the client credentials grant",
+			kubeResources: addFullyCapableDynamicClientAndSecretToKubeResources,
+			body:          body{"grant_type": {"client_credentials"}, "audience": {"some-gateway"}},
+			useBasicAuth:  true,
+			wantStatus:    http.StatusBadRequest,
+			wantErrorResponseBody: here.Doc(`
+				{
+					"error":             "unauthorized_client",
+					"error_description": "The client is not authorized to request a token using this method. The OAuth 2.0 Client is not allowed to use client credentials grant 'client_credentials'."
+				}
+			`),
+		},
+		{
+			name:       "pinniped-cli client is not allowed to use the client credentials grant",
+			body:       body{"grant_type": {"client_credentials"}, "audience": {"some-gateway"}, "client_id": {pinnipedCLIClientID}},
+			wantStatus: http.StatusBadRequest,
+			wantErrorResponseBody: here.Doc(`
+				{
+					"error":             "unauthorized_client",
+					"error_description": "The client is not authorized to request a token using this method. The OAuth 2.0 Client is not allowed to use client credentials grant 'client_credentials'."
+				}
+			`),
+		},
+		{
+			name:                  "client authentication is required",
+			kubeResources:         addClientCredentialsClientToKubeResources(clientCredentials),
+			body:                  body{"grant_type": {"client_credentials"}, "audience": {"some-gateway"}, "client_id": {dynamicClientID}},
+			wantStatus:            http.StatusUnauthorized,
+			wantErrorResponseBody: fositeClientAuthFailedErrorBody,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			kubeClient := fake.NewSimpleClientset()
+			supervisorClient := supervisorfake.NewSimpleClientset()
+			if test.kubeResources != nil {
+				test.kubeResources(t, supervisorClient, kubeClient)
+			}
+			secrets := kubeClient.CoreV1().Secrets("some-namespace")
+			oauthStore := oidc.NewKubeStorage(secrets, supervisorClient.ConfigV1alpha1().OIDCClients("some-namespace"), oidc.DefaultOIDCTimeoutsConfiguration(), bcrypt.MinCost)
+			jwtSigningKey, jwkProvider := generateJWTSigningKeyAndJWKSProvider(t, goodIssuer)
+			oauthHelper := oidc.FositeOauth2Helper(oauthStore, goodIssuer, hmacSecretFunc, jwkProvider, oidc.DefaultOIDCTimeoutsConfiguration())
+			subject := NewHandler(goodIssuer, oidctestutil.NewUpstreamIDPListerBuilder().Build(), oauthHelper, &testutil.AuditRecorder{})
+
+			req := httptest.NewRequest("POST", "/path/shouldn't/matter", test.body.ReadCloser())
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if test.useBasicAuth {
+				req.SetBasicAuth(dynamicClientID, testutil.PlaintextPassword1)
+			}
+			rsp := httptest.NewRecorder()
+			subject.ServeHTTP(rsp, req)
+			t.Logf("response: %#v", rsp)
+			t.Logf("response body: %q", rsp.Body.String())
+
+			require.Equal(t, test.wantStatus, rsp.Code)
+			testutil.RequireEqualContentType(t, rsp.Header().Get("Content-Type"), "application/json")
+			if test.wantErrorResponseBody != "" {
+				require.JSONEq(t, test.wantErrorResponseBody, rsp.Body.String())
+				return
+			}
+
+			var parsedResponseBody map[string]interface{}
+			require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &parsedResponseBody))
+			wantBodyFields := []string{"access_token", "token_type", "expires_in"}
+			if test.wantScope != "" {
+				wantBodyFields = append(wantBodyFields, "scope")
+				require.Equal(t, test.wantScope, parsedResponseBody["scope"])
+			}
+			require.ElementsMatch(t, wantBodyFields, getMapKeys(parsedResponseBody))
+			require.Equal(t, "bearer", parsedResponseBody["token_type"])
+
+			// The access token is a JWT for the requested audience which identifies the client, but not any user.
+			token := oidctestutil.VerifyECDSAIDToken(t, goodIssuer, test.wantAudience, jwtSigningKey, parsedResponseBody["access_token"].(string))
+			var claims map[string]interface{}
+			require.NoError(t, token.Claims(&claims))
+			require.Equal(t, dynamicClientID, claims["sub"])
+			require.Equal(t, dynamicClientID, claims["azp"])
+			if test.wantAudienceString {
+				require.Equal(t, test.wantAudience, claims["aud"])
+			} else {
+				require.Equal(t, []interface{}{test.wantAudience}, claims["aud"])
+			}
+			require.NotContains(t, claims, "username")
+			require.NotContains(t, claims, "groups")
+			if test.wantScope != "" {
+				require.Equal(t, test.wantScope, claims["scope"])
+			} else {
+				require.NotContains(t, claims, "scope")
+			}
+
+			// Nothing is stored for the tokens of the client credentials grant.
+			testutil.RequireNumberOfSecretsMatchingLabelSelector(t, secrets, labels.Set{crud.SecretLabelKey: accesstoken.TypeLabelValue}, 0)
+		})
+	}
+}
+
 func TestRefreshGrant(t *testing.T) {
 	const (
 		oidcUpstreamName                  = "some-oidc-idp"
@@ -3568,6 +4134,60 @@ func TestRefreshGrant(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "upstream ldap refresh happy path when the user was moved to a new DN then the new DN is stored in the session",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{
+				Name:                 ldapUpstreamName,
+				ResourceUID:          ldapUpstreamResourceUID,
+				URL:                  ldapUpstreamURL,
+				PerformRefreshGroups: goodGroups,
+				PerformRefreshDN:     "some-moved-ldap-user-dn",
+			}),
+			authcodeExchange: happyAuthcodeExchangeInputsForLDAPUpstream,
+			refreshRequest: refreshRequestInputs{
+				want: happyRefreshTokenResponseForLDAP(
+					&psession.CustomSessionData{
+						Username:     goodUsername,
+						ProviderUID:  ldapUpstreamResourceUID,
+						ProviderName: ldapUpstreamName,
+						ProviderType: ldapUpstreamType,
+						LDAP: &psession.LDAPSessionData{
+							UserDN: "some-moved-ldap-user-dn",
+						},
+					},
+				),
+			},
+		},
+		{
+			name: "upstream active directory refresh happy path when the user was moved to a new DN then the new DN is stored in the session",
+			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithActiveDirectory(&oidctestutil.TestUpstreamLDAPIdentityProvider{
+				Name:                 activeDirectoryUpstreamName,
+				ResourceUID:          activeDirectoryUpstreamResourceUID,
+				URL:                  ldapUpstreamURL,
+				PerformRefreshGroups: goodGroups,
+				PerformRefreshDN:     "some-moved-ad-user-dn",
+			}),
+			authcodeExchange: authcodeExchangeInputs{
+				modifyAuthRequest: func(r *http.Request) { r.Form.Set("scope", "openid offline_access username groups") },
+				customSessionData: happyActiveDirectoryCustomSessionData,
+				want: happyAuthcodeExchangeTokenResponseForOpenIDAndOfflineAccess(
+					happyActiveDirectoryCustomSessionData,
+				),
+			},
+			refreshRequest: refreshRequestInputs{
+				want: happyRefreshTokenResponseForActiveDirectory(
+					&psession.CustomSessionData{
+						Username:     goodUsername,
+						ProviderUID:  activeDirectoryUpstreamResourceUID,
+						ProviderName: activeDirectoryUpstreamName,
+						ProviderType: activeDirectoryUpstreamType,
+						ActiveDirectory: &psession.ActiveDirectorySessionData{
+							UserDN: "some-moved-ad-user-dn",
+						},
+					},
+				),
+			},
+		},
 		{
 			name: "upstream ldap refresh when the LDAP session data is nil",
 			idps: oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{
@@ -4073,12 +4693,19 @@ func TestRefreshGrant(t *testing.T) {
 			// Refreshed ID tokens do not include the nonce from the original auth request
 			wantNonceValueInIDToken := false
 
+			// A successful refresh keeps the old refresh token storage, marked as rotated, to detect replays.
+			wantRotatedRefreshTokenSessions := 0
+			if test.refreshRequest.want.wantStatus == http.StatusOK {
+				wantRotatedRefreshTokenSessions = 1
+			}
+
 			requireTokenEndpointBehavior(t,
 				test.refreshRequest.want,
 				test.authcodeExchange.want.wantUsername, // the old username from the initial login
 				test.authcodeExchange.want.wantGroups,   // the old groups from the initial login
 				test.authcodeExchange.customSessionData, // the old custom session data from the initial login
 				wantNonceValueInIDToken,
+				wantRotatedRefreshTokenSessions,
 				refreshResponse,
 				authCode,
 				oauthStore,
@@ -4134,6 +4761,198 @@ func TestRefreshGrant(t *testing.T) {
 	}
 }
 
+func TestTokenEndpointMetricsAndAuditEvents(t *testing.T) {
+	t.Parallel()
+
+	// Use an issuer which is not used by any other test, since the metrics are global.
+	const (
+		metricsIssuer    = "https://metrics-issuer.example.com"
+		ldapUpstreamName = "some-ldap-idp"
+	)
+
+	kubeClient := fake.NewSimpleClientset()
+	supervisorClient := supervisorfake.NewSimpleClientset()
+	secrets := kubeClient.CoreV1().Secrets("some-namespace")
+	oauthStore := oidc.NewKubeStorage(secrets, supervisorClient.ConfigV1alpha1().OIDCClients("some-namespace"), oidc.DefaultOIDCTimeoutsConfiguration(), bcrypt.MinCost)
+
+	authRequest := deepCopyRequestForm(happyAuthRequest)
+	authRequest.Form.Set("scope", "openid offline_access username groups")
+	ldapURL, err := url.Parse("some-url")
+	require.NoError(t, err)
+	oauthHelper, authCode, _ := makeHappyOauthHelper(t, authRequest, oauthStore, generateJWTSigningKeyAndJWKSProvider, &psession.CustomSessionData{
+		Username:     goodUsername,
+		ProviderUID:  "ldap-resource-uid",
+		ProviderName: ldapUpstreamName,
+		ProviderType: psession.ProviderTypeLDAP,
+		LDAP:         &psession.LDAPSessionData{UserDN: "some-ldap-user-dn"},
+	}, nil)
+
+	auditRecorder := &testutil.AuditRecorder{}
+	subject := NewHandler(metricsIssuer, oidctestutil.NewUpstreamIDPListerBuilder().WithLDAP(&oidctestutil.TestUpstreamLDAPIdentityProvider{
+		Name:                 ldapUpstreamName,
+		ResourceUID:          "ldap-resource-uid",
+		URL:                  ldapURL,
+		PerformRefreshGroups: goodGroups,
+	}).Build(), oauthHelper, auditRecorder)
+
+	requireTokensIssued := func(grantType string, want float64) {
+		t.Helper()
+		got, err := metricstestutil.GetCounterMetricValue(tokensIssuedMetric.WithLabelValues(metricsIssuer, grantType))
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+	lastRefreshSuccess := func() float64 {
+		t.Helper()
+		got, err := metricstestutil.GetGaugeMetricValue(upstreamRefreshLastSuccessMetric.WithLabelValues(metricsIssuer, ldapUpstreamName))
+		require.NoError(t, err)
+		return got
+	}
+
+	// Failed requests are not counted.
+	req := httptest.NewRequest("POST", "/path/shouldn't/matter", happyAuthcodeRequestBody("wrong-code").ReadCloser())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp := httptest.NewRecorder()
+	subject.ServeHTTP(rsp, req)
+	require.Equal(t, http.StatusBadRequest, rsp.Code)
+	requireTokensIssued("authorization_code", 0)
+
+	req = httptest.NewRequest("POST", "/path/shouldn't/matter", happyAuthcodeRequestBody(authCode).ReadCloser())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp = httptest.NewRecorder()
+	subject.ServeHTTP(rsp, req)
+	require.Equal(t, http.StatusOK, rsp.Code, rsp.Body.String())
+	requireTokensIssued("authorization_code", 1)
+	requireTokensIssued("refresh_token", 0)
+	require.Zero(t, lastRefreshSuccess())
+
+	var parsedResponseBody map[string]interface{}
+	require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &parsedResponseBody))
+
+	beforeRefresh := time.Now()
+	req = httptest.NewRequest("POST", "/path/shouldn't/matter",
+		happyRefreshRequestBody(parsedResponseBody["refresh_token"].(string)).ReadCloser()).
+		WithContext(warning.WithWarningRecorder(context.Background(), &TestWarningRecorder{}))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp = httptest.NewRecorder()
+	subject.ServeHTTP(rsp, req)
+	require.Equal(t, http.StatusOK, rsp.Code, rsp.Body.String())
+	requireTokensIssued("authorization_code", 1)
+	requireTokensIssued("refresh_token", 1)
+	require.GreaterOrEqual(t, lastRefreshSuccess(), float64(beforeRefresh.Unix()))
+
+	var parsedRefreshResponseBody map[string]interface{}
+	require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &parsedRefreshResponseBody))
+
+	requireTokenReplaysDetected := func(tokenType fosite.TokenType, want float64) {
+		t.Helper()
+		got, err := metricstestutil.GetCounterMetricValue(tokenReplaysDetectedMetric.WithLabelValues(metricsIssuer, string(tokenType)))
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	// Replaying the refresh token which was already rotated by the refresh revokes all tokens of the session.
+	req = httptest.NewRequest("POST", "/path/shouldn't/matter",
+		happyRefreshRequestBody(parsedResponseBody["refresh_token"].(string)).ReadCloser())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp = httptest.NewRecorder()
+	subject.ServeHTTP(rsp, req)
+	require.Equal(t, http.StatusUnauthorized, rsp.Code, rsp.Body.String())
+	requireTokenReplaysDetected(fosite.RefreshToken, 1)
+	requireTokenReplaysDetected(fosite.AuthorizeCode, 0)
+	testutil.RequireNumberOfSecretsMatchingLabelSelector(t, secrets, labels.Set{crud.SecretLabelKey: refreshtoken.TypeLabelValue}, 0)
+	testutil.RequireNumberOfSecretsMatchingLabelSelector(t, secrets, labels.Set{crud.SecretLabelKey: accesstoken.TypeLabelValue}, 0)
+
+	// So the newest refresh token of the session does not work anymore, but it is not a replay.
+	req = httptest.NewRequest("POST", "/path/shouldn't/matter",
+		happyRefreshRequestBody(parsedRefreshResponseBody["refresh_token"].(string)).ReadCloser())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp = httptest.NewRecorder()
+	subject.ServeHTTP(rsp, req)
+	require.Equal(t, http.StatusBadRequest, rsp.Code, rsp.Body.String())
+	requireTokenReplaysDetected(fosite.RefreshToken, 1)
+
+	// Replaying the authcode which was already redeemed is also detected.
+	req = httptest.NewRequest("POST", "/path/shouldn't/matter", happyAuthcodeRequestBody(authCode).ReadCloser())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp = httptest.NewRecorder()
+	subject.ServeHTTP(rsp, req)
+	require.Equal(t, http.StatusBadRequest, rsp.Code, rsp.Body.String())
+	requireTokenReplaysDetected(fosite.RefreshToken, 1)
+	requireTokenReplaysDetected(fosite.AuthorizeCode, 1)
+	requireTokensIssued("authorization_code", 1)
+	requireTokensIssued("refresh_token", 1)
+
+	// The failed request does not belong to any session, and the refresh does not know the ID of its session.
+	// The replays are also failed requests, but the replayed tokens reveal the ID of their session.
+	auditEvents := auditRecorder.Events()
+	require.Len(t, auditEvents, 8)
+	// The tokens of the session were already revoked by the refresh token replay, so fosite mentions that it could not
+	// revoke them again.
+	wantReusedAuthcodeError := "invalid_grant: The provided authorization grant (e.g., authorization code, resource owner credentials) or refresh token is invalid, expired, revoked, does not match the redirection URI used in the authorization request, or was issued to another client. " +
+		"The authorization code has already been used. Additionally, an error occurred during processing the access token revocation. Additionally, an error occurred during processing the refresh token revocation."
+	sessionID := auditEvents[1].SessionID
+	require.NotEmpty(t, sessionID)
+	require.Equal(t, []auditlog.Event{
+		{
+			Type:      auditlog.EventTokenRequestFailed,
+			ClientID:  pinnipedCLIClientID,
+			GrantType: "authorization_code",
+			Error:     "invalid_grant: The provided authorization grant (e.g., authorization code, resource owner credentials) or refresh token is invalid, expired, revoked, does not match the redirection URI used in the authorization request, or was issued to another client.",
+		},
+		{
+			Type:             auditlog.EventTokensIssued,
+			SessionID:        sessionID,
+			ClientID:         pinnipedCLIClientID,
+			IdentityProvider: ldapUpstreamName,
+			Username:         goodUsername,
+			GrantType:        "authorization_code",
+		},
+		{
+			Type:             auditlog.EventSessionRefreshed,
+			ClientID:         pinnipedCLIClientID,
+			IdentityProvider: ldapUpstreamName,
+			Username:         goodUsername,
+			GrantType:        "refresh_token",
+		},
+		{
+			Type:      auditlog.EventTokenRequestFailed,
+			ClientID:  pinnipedCLIClientID,
+			GrantType: "refresh_token",
+			Error:     "token_inactive: Token is inactive because it is malformed, expired or otherwise invalid. Token validation failed.",
+		},
+		{
+			Type:             auditlog.EventTokenReplayDetected,
+			SessionID:        sessionID,
+			ClientID:         pinnipedCLIClientID,
+			IdentityProvider: ldapUpstreamName,
+			Username:         goodUsername,
+			GrantType:        "refresh_token",
+			Error:            "token_inactive: Token is inactive because it is malformed, expired or otherwise invalid. Token validation failed.",
+		},
+		{
+			Type:      auditlog.EventTokenRequestFailed,
+			ClientID:  pinnipedCLIClientID,
+			GrantType: "refresh_token",
+			Error:     "invalid_grant: The provided authorization grant (e.g., authorization code, resource owner credentials) or refresh token is invalid, expired, revoked, does not match the redirection URI used in the authorization request, or was issued to another client.",
+		},
+		{
+			Type:      auditlog.EventTokenRequestFailed,
+			ClientID:  pinnipedCLIClientID,
+			GrantType: "authorization_code",
+			Error:     wantReusedAuthcodeError,
+		},
+		{
+			Type:             auditlog.EventTokenReplayDetected,
+			SessionID:        sessionID,
+			ClientID:         pinnipedCLIClientID,
+			IdentityProvider: ldapUpstreamName,
+			Username:         goodUsername,
+			GrantType:        "authorization_code",
+			Error:            wantReusedAuthcodeError,
+		},
+	}, auditEvents)
+}
+
 func requireClaimsAreNotEqual(t *testing.T, claimName string, claimsOfTokenA map[string]interface{}, claimsOfTokenB map[string]interface{}) {
 	require.NotEmpty(t, claimsOfTokenA[claimName])
 	require.NotEmpty(t, claimsOfTokenB[claimName])
@@ -4184,7 +5003,7 @@ func exchangeAuthcodeForTokens(
 	// Note that makeHappyOauthHelper() calls simulateAuthEndpointHavingAlreadyRun() to preload the session storage.
 	oauthHelper, authCode, jwtSigningKey = makeHappyOauthHelper(t, authRequest, oauthStore, test.makeJwksSigningKeyAndProvider, test.customSessionData, test.modifySession)
 
-	subject = NewHandler(idps, oauthHelper)
+	subject = NewHandler(goodIssuer, idps, oauthHelper, auditlog.Nop())
 
 	authorizeEndpointGrantedOpenIDScope := strings.Contains(authRequest.Form.Get("scope"), "openid")
 	expectedNumberOfIDSessionsStored := 0
@@ -4218,6 +5037,7 @@ func exchangeAuthcodeForTokens(
 		test.want.wantGroups,   // the old groups from the initial login
 		test.customSessionData, // the old custom session data from the initial login
 		wantNonceValueInIDToken,
+		0, // an authcode exchange has not rotated any refresh tokens yet
 		rsp,
 		authCode,
 		oauthStore,
@@ -4236,6 +5056,7 @@ func requireTokenEndpointBehavior(
 	oldGroups []string,
 	oldCustomSessionData *psession.CustomSessionData,
 	wantNonceValueInIDToken bool,
+	wantRotatedRefreshTokenSessions int,
 	tokenEndpointResponse *httptest.ResponseRecorder,
 	authCode string,
 	oauthStore *oidc.KubeStorage,
@@ -4262,14 +5083,18 @@ func requireTokenEndpointBehavior(
 		// Performing a refresh does not update the OIDC storage, so after a refresh it should still have the old custom session data and old username and groups from the initial login.
 		requireValidOIDCStorage(t, parsedResponseBody, authCode, oauthStore, test.wantClientID, test.wantRequestedScopes, test.wantGrantedScopes, oldUsername, oldGroups, oldCustomSessionData, test.wantAdditionalClaims, requestTime)
 
-		expectedNumberOfRefreshTokenSessionsStored := 0
+		expectedNumberOfRefreshTokenSessionsStored := wantRotatedRefreshTokenSessions
 		if wantRefreshToken {
-			expectedNumberOfRefreshTokenSessionsStored = 1
+			expectedNumberOfRefreshTokenSessionsStored++
 		}
 		expectedNumberOfIDSessionsStored := 0
 		if wantIDToken {
 			expectedNumberOfIDSessionsStored = 1
-			requireValidIDToken(t, parsedResponseBody, jwtSigningKey, test.wantClientID, wantNonceValueInIDToken, test.wantUsername, test.wantGroups, test.wantAdditionalClaims, parsedResponseBody["access_token"].(string), requestTime)
+			wantIDTokenGroups := test.wantGroups
+			if test.wantIDTokenGroups != nil {
+				wantIDTokenGroups = test.wantIDTokenGroups
+			}
+			requireValidIDToken(t, parsedResponseBody, jwtSigningKey, test.wantClientID, wantNonceValueInIDToken, test.wantUsername, wantIDTokenGroups, test.wantIDTokenGroupsOverflow, test.wantAdditionalClaims, parsedResponseBody["access_token"].(string), requestTime)
 		}
 		if wantRefreshToken {
 			requireValidRefreshTokenStorage(t, parsedResponseBody, oauthStore, test.wantClientID, test.wantRequestedScopes, test.wantGrantedScopes, test.wantUsername, test.wantGroups, test.wantCustomSessionDataStored, test.wantAdditionalClaims, secrets, requestTime)
@@ -4464,6 +5289,27 @@ func simulateAuthEndpointHavingAlreadyRun(
 	return authResponder
 }
 
+// signActorToken returns a JWT which can be used as the actor token of a token exchange. The claims default to those of
+// a token which was issued by the test issuer for the pinniped-cli client and which has not expired.
+func signActorToken(t *testing.T, key *ecdsa.PrivateKey, claims map[string]interface{}) string {
+	t.Helper()
+
+	allClaims := map[string]interface{}{
+		"iss": goodIssuer,
+		"aud": pinnipedCLIClientID,
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}
+	for k, v := range claims {
+		allClaims[k] = v
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, nil)
+	require.NoError(t, err)
+	token, err := josejwt.Signed(signer).Claims(allClaims).CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
 func generateJWTSigningKeyAndJWKSProvider(t *testing.T, issuer string) (*ecdsa.PrivateKey, jwks.DynamicJWKSProvider) {
 	t.Helper()
 
@@ -4823,6 +5669,7 @@ func requireValidIDToken(
 	wantNonceValueInIDToken bool,
 	wantUsernameInIDToken string,
 	wantGroupsInIDToken []string,
+	wantGroupsOverflowInIDToken bool,
 	wantAdditionalClaims map[string]interface{},
 	actualAccessToken string,
 	requestTime time.Time,
@@ -4849,6 +5696,7 @@ func requireValidIDToken(
 		RequestedAt      int64                  `json:"rat"`
 		AuthTime         int64                  `json:"auth_time"`
 		Groups           []string               `json:"groups"`
+		GroupsOverflow   bool                   `json:"groupsOverflow"`
 		Username         string                 `json:"username"`
 		AdditionalClaims map[string]interface{} `json:"additionalClaims"`
 	}
@@ -4863,6 +5711,9 @@ func requireValidIDToken(
 	if wantGroupsInIDToken != nil {
 		idTokenFields = append(idTokenFields, "groups")
 	}
+	if wantGroupsOverflowInIDToken {
+		idTokenFields = append(idTokenFields, "groupsOverflow")
+	}
 	if len(wantAdditionalClaims) > 0 {
 		idTokenFields = append(idTokenFields, "additionalClaims")
 	}
@@ -4878,6 +5729,7 @@ func requireValidIDToken(
 	require.Equal(t, goodSubject, claims.Subject)
 	require.Equal(t, wantUsernameInIDToken, claims.Username)
 	require.Equal(t, wantGroupsInIDToken, claims.Groups)
+	require.Equal(t, wantGroupsOverflowInIDToken, claims.GroupsOverflow)
 	require.Len(t, claims.Audience, 1)
 	require.Equal(t, wantClientID, claims.Audience[0])
 	require.Equal(t, wantClientID, m["azp"])
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidc
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
 	"github.com/ory/fosite/handler/oauth2"
 	"github.com/ory/fosite/handler/openid"
 	"github.com/pkg/errors"
@@ -25,13 +26,25 @@ const (
 type stsParams struct {
 	subjectAccessToken string
 	requestedAudience  string
+	actorToken         string
+}
+
+// jwtVerifier verifies the JWTs which were issued by a FederationDomain to the given audience.
+type jwtVerifier interface {
+	verifyJWT(token string, audience string) (map[string]interface{}, error)
 }
 
 func TokenExchangeFactory(config fosite.Configurator, storage interface{}, strategy interface{}) interface{} {
+	// Actor tokens are verified by the OpenID Connect strategy which issued them.
+	var actorTokenVerifier jwtVerifier
+	if commonStrategy, ok := strategy.(*compose.CommonStrategy); ok {
+		actorTokenVerifier, _ = commonStrategy.OpenIDConnectTokenStrategy.(jwtVerifier)
+	}
 	return &TokenExchangeHandler{
 		idTokenStrategy:     strategy.(openid.OpenIDConnectTokenStrategy),
 		accessTokenStrategy: strategy.(oauth2.AccessTokenStrategy),
 		accessTokenStorage:  storage.(oauth2.AccessTokenStorage),
+		actorTokenVerifier:  actorTokenVerifier,
 		fositeConfig:        config,
 	}
 }
@@ -40,6 +53,7 @@ type TokenExchangeHandler struct {
 	idTokenStrategy     openid.OpenIDConnectTokenStrategy
 	accessTokenStrategy oauth2.AccessTokenStrategy
 	accessTokenStorage  oauth2.AccessTokenStorage
+	actorTokenVerifier  jwtVerifier
 	fositeConfig        fosite.Configurator
 }
 
@@ -95,6 +109,16 @@ func (t *TokenExchangeHandler) PopulateTokenEndpointResponse(ctx context.Context
 		return errors.WithStack(err)
 	}
 
+	// The request was made with an empty session, so give it the session of the user, e.g. for the audit log.
+	requester.SetSession(originalRequester.GetSession())
+
+	// When an actor token was sent, the client is acting on behalf of the user, so record the actor in the new JWT.
+	if params.actorToken != "" {
+		if err := t.addActorClaim(requester, originalRequester, params.actorToken); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	// Use the original authorize request information, along with the requested audience, to mint a new JWT.
 	responseToken, err := t.mintJWT(ctx, originalRequester, params.requestedAudience)
 	if err != nil {
@@ -135,6 +159,49 @@ func (t *TokenExchangeHandler) validateSession(requester fosite.Requester) error
 	return nil
 }
 
+// addActorClaim validates the actor token and adds the RFC8693 act claim to the session of the original request, from
+// which the new JWT is minted. The session is not stored again, so the act claim is only added to the new JWT. The
+// actor token must be a JWT which was issued by this FederationDomain to the client which is making the request, for
+// that client itself, e.g. the ID token of the client_credentials grant. Its audience and its authorized party must
+// both be that client. The JWTs which are minted by token exchanges are for a workload cluster, which can never be
+// the ID of a client, so they are never valid actor tokens. Otherwise, a client could impersonate another service by
+// sending a token which was minted on behalf of that service for some cluster. When the actor token has an act claim
+// of its own, it is nested into the new act claim, which records the whole chain of actors.
+func (t *TokenExchangeHandler) addActorClaim(requester fosite.AccessRequester, originalRequester fosite.Requester, actorToken string) error {
+	if t.actorTokenVerifier == nil {
+		// This shouldn't really happen.
+		return fosite.ErrServerError.WithHint("Actor tokens cannot be verified.")
+	}
+	clientID := requester.GetClient().GetID()
+	actorClaims, err := t.actorTokenVerifier.verifyJWT(actorToken, clientID)
+	if err != nil {
+		return fosite.ErrRequestUnauthorized.WithWrap(err).WithHint("Invalid 'actor_token' parameter value.")
+	}
+
+	actorSubject, _ := actorClaims[oidcapi.IDTokenClaimSubject].(string)
+	if actorSubject == "" {
+		return fosite.ErrInvalidRequest.WithHint("The 'actor_token' parameter value does not have a subject.")
+	}
+	if actorClaims[oidcapi.IDTokenClaimAuthorizedParty] != clientID {
+		return fosite.ErrInvalidGrant.WithHint("The 'actor_token' parameter value was not issued to the OAuth 2.0 Client of this request.")
+	}
+
+	pSession, ok := originalRequester.GetSession().(*psession.PinnipedSession)
+	if !ok || pSession.Fosite == nil || pSession.Fosite.Claims == nil {
+		// This shouldn't really happen.
+		return fosite.ErrServerError.WithHint("Invalid session storage.")
+	}
+	actor := map[string]interface{}{oidcapi.IDTokenClaimSubject: actorSubject}
+	if priorActor, ok := actorClaims[oidcapi.IDTokenClaimActor].(map[string]interface{}); ok {
+		actor[oidcapi.IDTokenClaimActor] = priorActor
+	}
+	if pSession.Fosite.Claims.Extra == nil {
+		pSession.Fosite.Claims.Extra = map[string]interface{}{}
+	}
+	pSession.Fosite.Claims.Extra[oidcapi.IDTokenClaimActor] = actor
+	return nil
+}
+
 func (t *TokenExchangeHandler) validateParams(params url.Values) (*stsParams, error) {
 	var result stsParams
 
@@ -156,12 +223,20 @@ func (t *TokenExchangeHandler) validateParams(params url.Values) (*stsParams, er
 		return nil, fosite.ErrInvalidRequest.WithHintf("Unsupported 'requested_token_type' parameter value, must be %q.", tokenTypeJWT)
 	}
 
+	// Validate the optional actor token, which must be sent along with its type.
+	result.actorToken = params.Get("actor_token")
+	actorTokenType := params.Get("actor_token_type")
+	if result.actorToken == "" && actorTokenType != "" {
+		return nil, fosite.ErrInvalidRequest.WithHint("Missing 'actor_token' parameter.")
+	}
+	if result.actorToken != "" && actorTokenType != tokenTypeJWT {
+		return nil, fosite.ErrInvalidRequest.WithHintf("Unsupported 'actor_token_type' parameter value, must be %q.", tokenTypeJWT)
+	}
+
 	// Validate that none of these unsupported parameters were sent. These are optional and we do not currently support them.
 	for _, param := range []string{
 		"resource",
 		"scope",
-		"actor_token",
-		"actor_token_type",
 	} {
 		if params.Get(param) != "" {
 			return nil, fosite.ErrInvalidRequest.WithHintf("Unsupported parameter %q.", param)
@@ -1,11 +1,13 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclientsecretstorage
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
@@ -38,11 +40,45 @@ type OIDCClientSecretStorage struct {
 type storedClientSecret struct {
 	// List of bcrypt hashes.
 	SecretHashes []string `json:"hashes"`
+	// Metadata about each of the hashes, keyed by hash. Hashes which were stored before metadata was introduced have
+	// no entry here, so this field can be added without bumping the format version.
+	Metadata map[string]SecretMetadata `json:"metadata,omitempty"`
 	// The format version. Take care when updating. We cannot simply bump the storage version and drop/ignore old data.
 	// Updating this would require some form of migration of existing stored data.
 	Version string `json:"version"`
 }
 
+// SecretMetadata describes one of the client secrets of an OIDCClient.
+type SecretMetadata struct {
+	// When the client secret was generated.
+	CreationTimestamp *metav1.Time `json:"created,omitempty"`
+	// When the client secret was last used to authenticate the client.
+	LastUsedTimestamp *metav1.Time `json:"lastUsed,omitempty"`
+	// When the client secret expires, as requested when it was generated.
+	ExpirationTimestamp *metav1.Time `json:"expires,omitempty"`
+}
+
+// ExpiresAt returns when the client secret expires, considering both its own expiration and the maximum age of the
+// client secrets of its OIDCClient according to the given policy, which may be nil. Returns nil when the client
+// secret never expires.
+func (m SecretMetadata) ExpiresAt(policy *configv1alpha1.OIDCClientSecretPolicy) *metav1.Time {
+	expiresAt := m.ExpirationTimestamp
+	if policy != nil && policy.MaxAge != nil && m.CreationTimestamp != nil {
+		maxAgeExpiresAt := metav1.NewTime(m.CreationTimestamp.Add(policy.MaxAge.Duration))
+		if expiresAt == nil || maxAgeExpiresAt.Before(expiresAt) {
+			expiresAt = &maxAgeExpiresAt
+		}
+	}
+	return expiresAt
+}
+
+// SecretID returns an identifier for the client secret with the given hash, which can be shared without revealing
+// anything about the client secret or its hash.
+func SecretID(hash string) string {
+	sum := sha256.Sum256([]byte(hash))
+	return hex.EncodeToString(sum[:8])
+}
+
 func New(secrets corev1client.SecretInterface) *OIDCClientSecretStorage {
 	return &OIDCClientSecretStorage{
 		storage: crud.New(TypeLabelValue, secrets, nil, 0), // can use nil clock because we are using infinite lifetime
@@ -50,34 +86,50 @@ func New(secrets corev1client.SecretInterface) *OIDCClientSecretStorage {
 	}
 }
 
-// Get returns the resourceVersion of the storage secret, the hashes within the secret, and an error.
-// When the storage secret is not found, it will simply return "", nil, nil to make it easy to pass the
+// Get returns the resourceVersion of the storage secret, the hashes within the secret, the metadata of those hashes
+// keyed by hash, and an error. Hashes without any known metadata have no entry in the metadata map.
+// When the storage secret is not found, it will simply return "", nil, nil, nil to make it easy to pass the
 // results of Get directly to Set.
-func (s *OIDCClientSecretStorage) Get(ctx context.Context, oidcClientUID types.UID) (string, []string, error) {
+func (s *OIDCClientSecretStorage) Get(ctx context.Context, oidcClientUID types.UID) (string, []string, map[string]SecretMetadata, error) {
 	clientSecret := &storedClientSecret{}
 	rv, err := s.storage.Get(ctx, uidToName(oidcClientUID), clientSecret)
 	if errors.IsNotFound(err) {
-		return "", nil, nil
+		return "", nil, nil, nil
 	}
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to get client secret for uid %s: %w", oidcClientUID, err)
+		return "", nil, nil, fmt.Errorf("failed to get client secret for uid %s: %w", oidcClientUID, err)
 	}
 	if clientSecret.Version != oidcClientSecretStorageVersion {
-		return "", nil, fmt.Errorf("%w: OIDC client secret storage has version %s instead of %s",
+		return "", nil, nil, fmt.Errorf("%w: OIDC client secret storage has version %s instead of %s",
 			ErrOIDCClientSecretStorageVersion, clientSecret.Version, oidcClientSecretStorageVersion)
 	}
-	return rv, clientSecret.SecretHashes, nil
+	return rv, clientSecret.SecretHashes, clientSecret.Metadata, nil
 }
 
 // Set will create or update the values of the storage secret associated with an OIDCClient.
 // Set takes the resourceVersion to know if we are doing a create or update and to ensure we do not edit an old version of the storage secret.
 // Set takes the oidcClientName to set up the owner reference of the storage secret to that of the OIDCClient.
 // Set takes the oidcClientUID to find the correct storage secret.
-func (s *OIDCClientSecretStorage) Set(ctx context.Context, resourceVersion, oidcClientName string, oidcClientUID types.UID, secretHashes []string) error {
+// Set takes the metadata of the hashes keyed by hash, and only stores the metadata of the given secretHashes.
+func (s *OIDCClientSecretStorage) Set(
+	ctx context.Context,
+	resourceVersion, oidcClientName string,
+	oidcClientUID types.UID,
+	secretHashes []string,
+	metadata map[string]SecretMetadata,
+) error {
 	secret := &storedClientSecret{
 		SecretHashes: secretHashes,
 		Version:      oidcClientSecretStorageVersion,
 	}
+	for _, hash := range secretHashes {
+		if m, ok := metadata[hash]; ok {
+			if secret.Metadata == nil {
+				secret.Metadata = make(map[string]SecretMetadata, len(secretHashes))
+			}
+			secret.Metadata[hash] = m
+		}
+	}
 	name := uidToName(oidcClientUID)
 
 	if mustBeCreate := len(resourceVersion) == 0; mustBeCreate {
@@ -126,16 +178,17 @@ func uidToName(oidcClientUID types.UID) string {
 	return base64.RawURLEncoding.EncodeToString([]byte(oidcClientUID))
 }
 
-// ReadFromSecret reads the contents of a Secret as a storedClientSecret and returns the associated hashes.
-func ReadFromSecret(secret *corev1.Secret) ([]string, error) {
+// ReadFromSecret reads the contents of a Secret as a storedClientSecret and returns the associated hashes, along
+// with the metadata of those hashes keyed by hash.
+func ReadFromSecret(secret *corev1.Secret) ([]string, map[string]SecretMetadata, error) {
 	clientSecret := &storedClientSecret{}
 	err := crud.FromSecret(TypeLabelValue, secret, clientSecret)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if clientSecret.Version != oidcClientSecretStorageVersion {
-		return nil, fmt.Errorf("%w: OIDC client secret storage has version %s instead of %s",
+		return nil, nil, fmt.Errorf("%w: OIDC client secret storage has version %s instead of %s",
 			ErrOIDCClientSecretStorageVersion, clientSecret.Version, oidcClientSecretStorageVersion)
 	}
-	return clientSecret.SecretHashes, nil
+	return clientSecret.SecretHashes, clientSecret.Metadata, nil
 }
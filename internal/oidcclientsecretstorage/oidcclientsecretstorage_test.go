@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclientsecretstorage
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -17,17 +18,19 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	coretesting "k8s.io/client-go/testing"
 
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	"go.pinniped.dev/internal/testutil"
 )
 
 func TestGet(t *testing.T) {
 	tests := []struct {
-		name       string
-		uid        types.UID
-		secret     *corev1.Secret
-		wantRV     string
-		wantHashes []string
-		wantErr    string
+		name         string
+		uid          types.UID
+		secret       *corev1.Secret
+		wantRV       string
+		wantHashes   []string
+		wantMetadata map[string]SecretMetadata
+		wantErr      string
 	}{
 		{
 			name: "happy path",
@@ -54,6 +57,40 @@ func TestGet(t *testing.T) {
 			wantRV:     "123",
 			wantHashes: []string{"foo", "bar"},
 		},
+		{
+			name: "happy path with metadata",
+			uid:  types.UID("some-example-uid1"),
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "pinniped-storage-oidc-client-secret-onxw2zjnmv4gc3lqnrss25ljmqyq",
+					Namespace:       "some-namespace",
+					UID:             "",
+					ResourceVersion: "123",
+					Labels: map[string]string{
+						"storage.pinniped.dev/type": "oidc-client-secret",
+					},
+				},
+				Type: "storage.pinniped.dev/oidc-client-secret",
+				Data: map[string][]byte{
+					"pinniped-storage-data": []byte(`{
+						"hashes": ["foo", "bar"],
+						"metadata": {
+							"foo": {"created": "2023-01-02T03:04:05Z", "lastUsed": "2023-02-03T04:05:06Z"}
+						},
+						"version": "1"
+					}`),
+					"pinniped-storage-version": []byte("1"),
+				},
+			},
+			wantRV:     "123",
+			wantHashes: []string{"foo", "bar"},
+			wantMetadata: map[string]SecretMetadata{
+				"foo": {
+					CreationTimestamp: timePtr(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+					LastUsedTimestamp: timePtr(time.Date(2023, 2, 3, 4, 5, 6, 0, time.UTC)),
+				},
+			},
+		},
 		{
 			name: "incorrect storage version: Data.pinniped-storage-version does not match crud storage version value",
 			uid:  types.UID("some-example-uid1"),
@@ -123,7 +160,7 @@ func TestGet(t *testing.T) {
 				require.NoError(t, kubeClient.Tracker().Add(tt.secret))
 			}
 			subject := New(kubeClient.CoreV1().Secrets("some-namespace"))
-			rv, secretHashes, err := subject.Get(context.Background(), tt.uid)
+			rv, secretHashes, metadata, err := subject.Get(context.Background(), tt.uid)
 			if tt.wantErr == "" {
 				require.NoError(t, err)
 			} else {
@@ -131,6 +168,7 @@ func TestGet(t *testing.T) {
 			}
 			require.Equal(t, tt.wantRV, rv)
 			require.Equal(t, tt.wantHashes, secretHashes)
+			require.Equal(t, tt.wantMetadata, metadata)
 		})
 	}
 }
@@ -148,6 +186,7 @@ func TestSet(t *testing.T) {
 		oidcClientName string
 		oidcClientUID  types.UID
 		hashes         []string
+		metadata       map[string]SecretMetadata
 		seedSecret     *corev1.Secret
 		addReactors    func(*fake.Clientset)
 		wantErr        string
@@ -232,6 +271,38 @@ func TestSet(t *testing.T) {
 				}),
 			},
 		},
+		{
+			name:           "happy path: new secret with metadata, which is only stored for the given hashes",
+			oidcClientName: "some-client",
+			oidcClientUID:  types.UID("some-example-uid1"),
+			hashes:         []string{"foo", "bar"},
+			metadata: map[string]SecretMetadata{
+				"foo":     {CreationTimestamp: timePtr(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))},
+				"revoked": {CreationTimestamp: timePtr(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))},
+			},
+			seedSecret: nil,
+			wantActions: []coretesting.Action{
+				coretesting.NewCreateAction(secretsGVR, namespace, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pinniped-storage-oidc-client-secret-onxw2zjnmv4gc3lqnrss25ljmqyq",
+						Labels: map[string]string{
+							"storage.pinniped.dev/type": "oidc-client-secret",
+						},
+						OwnerReferences: []metav1.OwnerReference{{
+							APIVersion: "config.supervisor.pinniped.dev/v1alpha1",
+							Kind:       "OIDCClient",
+							Name:       "some-client",
+							UID:        "some-example-uid1",
+						}},
+					},
+					Type: "storage.pinniped.dev/oidc-client-secret",
+					Data: map[string][]byte{
+						"pinniped-storage-data":    []byte(`{"hashes":["foo","bar"],"metadata":{"foo":{"created":"2023-01-02T03:04:05Z"}},"version":"1"}`),
+						"pinniped-storage-version": []byte("1"),
+					},
+				}),
+			},
+		},
 		{
 			name:           "failed to create client secret",
 			oidcClientName: "some-client",
@@ -342,6 +413,7 @@ func TestSet(t *testing.T) {
 				tt.oidcClientName,
 				tt.oidcClientUID,
 				tt.hashes,
+				tt.metadata,
 			)
 
 			if tt.wantErr == "" {
@@ -424,10 +496,11 @@ func TestGetName(t *testing.T) {
 
 func TestReadFromSecret(t *testing.T) {
 	tests := []struct {
-		name       string
-		secret     *corev1.Secret
-		wantHashes []string
-		wantErr    string
+		name         string
+		secret       *corev1.Secret
+		wantHashes   []string
+		wantMetadata map[string]SecretMetadata
+		wantErr      string
 	}{
 		{
 			name: "happy path",
@@ -447,6 +520,31 @@ func TestReadFromSecret(t *testing.T) {
 			},
 			wantHashes: []string{"first-hash", "second-hash"},
 		},
+		{
+			name: "happy path with metadata",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "pinniped-storage-oidc-client-secret-pwu5zs7lekbhnln2w4",
+					ResourceVersion: "",
+					Labels: map[string]string{
+						"storage.pinniped.dev/type": "oidc-client-secret",
+					},
+				},
+				Data: map[string][]byte{
+					"pinniped-storage-data": []byte(`{"hashes":["first-hash","second-hash"],` +
+						`"metadata":{"first-hash":{"created":"2023-01-02T03:04:05Z","expires":"2023-02-01T03:04:05Z"}},"version":"1"}`),
+					"pinniped-storage-version": []byte("1"),
+				},
+				Type: "storage.pinniped.dev/oidc-client-secret",
+			},
+			wantHashes: []string{"first-hash", "second-hash"},
+			wantMetadata: map[string]SecretMetadata{
+				"first-hash": {
+					CreationTimestamp:   timePtr(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+					ExpirationTimestamp: timePtr(time.Date(2023, 2, 1, 3, 4, 5, 0, time.UTC)),
+				},
+			},
+		},
 		{
 			name: "wrong secret type",
 			secret: &corev1.Secret{
@@ -526,14 +624,88 @@ func TestReadFromSecret(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			hashes, err := ReadFromSecret(tt.secret)
+			hashes, metadata, err := ReadFromSecret(tt.secret)
 			if tt.wantErr == "" {
 				require.NoError(t, err)
 				require.Equal(t, tt.wantHashes, hashes)
+				require.Equal(t, tt.wantMetadata, metadata)
 			} else {
 				require.EqualError(t, err, tt.wantErr)
 				require.Nil(t, hashes)
+				require.Nil(t, metadata)
 			}
 		})
 	}
 }
+
+func TestExpiresAt(t *testing.T) {
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	thirtyDays := &metav1.Duration{Duration: 30 * 24 * time.Hour}
+	ninetyDays := &metav1.Duration{Duration: 90 * 24 * time.Hour}
+
+	tests := []struct {
+		name     string
+		metadata SecretMetadata
+		policy   *configv1alpha1.OIDCClientSecretPolicy
+		want     *metav1.Time
+	}{
+		{
+			name:     "no expiration and no policy",
+			metadata: SecretMetadata{CreationTimestamp: &metav1.Time{Time: created}},
+		},
+		{
+			name:     "no expiration and a policy without a max age",
+			metadata: SecretMetadata{CreationTimestamp: &metav1.Time{Time: created}},
+			policy:   &configv1alpha1.OIDCClientSecretPolicy{MaxSecrets: 2},
+		},
+		{
+			name: "expiration without a policy",
+			metadata: SecretMetadata{
+				CreationTimestamp:   &metav1.Time{Time: created},
+				ExpirationTimestamp: &metav1.Time{Time: created.Add(thirtyDays.Duration)},
+			},
+			want: &metav1.Time{Time: created.Add(thirtyDays.Duration)},
+		},
+		{
+			name:     "max age without an expiration",
+			metadata: SecretMetadata{CreationTimestamp: &metav1.Time{Time: created}},
+			policy:   &configv1alpha1.OIDCClientSecretPolicy{MaxAge: ninetyDays},
+			want:     &metav1.Time{Time: created.Add(ninetyDays.Duration)},
+		},
+		{
+			name: "expiration before the max age",
+			metadata: SecretMetadata{
+				CreationTimestamp:   &metav1.Time{Time: created},
+				ExpirationTimestamp: &metav1.Time{Time: created.Add(thirtyDays.Duration)},
+			},
+			policy: &configv1alpha1.OIDCClientSecretPolicy{MaxAge: ninetyDays},
+			want:   &metav1.Time{Time: created.Add(thirtyDays.Duration)},
+		},
+		{
+			name: "max age before the expiration",
+			metadata: SecretMetadata{
+				CreationTimestamp:   &metav1.Time{Time: created},
+				ExpirationTimestamp: &metav1.Time{Time: created.Add(ninetyDays.Duration)},
+			},
+			policy: &configv1alpha1.OIDCClientSecretPolicy{MaxAge: thirtyDays},
+			want:   &metav1.Time{Time: created.Add(thirtyDays.Duration)},
+		},
+		{
+			name:     "max age does not apply without a creation timestamp",
+			metadata: SecretMetadata{},
+			policy:   &configv1alpha1.OIDCClientSecretPolicy{MaxAge: ninetyDays},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, tt.metadata.ExpiresAt(tt.policy))
+		})
+	}
+}
+
+func timePtr(t time.Time) *metav1.Time {
+	return &metav1.Time{Time: t.Local()} // metav1.Time unmarshals to local time
+}
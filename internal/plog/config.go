@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package plog
@@ -6,6 +6,7 @@ package plog
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -22,6 +23,8 @@ func (l *LogFormat) UnmarshalJSON(b []byte) error {
 	switch string(b) {
 	case `""`, `"json"`:
 		*l = FormatJSON
+	case `"kubernetes-json"`:
+		*l = FormatKubernetesJSON
 	case `"text"`:
 		*l = FormatText
 	// there is no "cli" case because it is not a supported option via our config
@@ -33,11 +36,13 @@ func (l *LogFormat) UnmarshalJSON(b []byte) error {
 
 const (
 	FormatJSON LogFormat = "json"
-	FormatText LogFormat = "text"
-	FormatCLI  LogFormat = "cli" // only used by the pinniped CLI and not the server components
+	// FormatKubernetesJSON is JSON which uses the key names and severity levels of Kubernetes structured logging.
+	FormatKubernetesJSON LogFormat = "kubernetes-json"
+	FormatText           LogFormat = "text"
+	FormatCLI            LogFormat = "cli" // only used by the pinniped CLI and not the server components
 
 	errInvalidLogLevel  = constable.Error("invalid log level, valid choices are the empty string, info, debug, trace and all")
-	errInvalidLogFormat = constable.Error("invalid log format, valid choices are the empty string, json and text")
+	errInvalidLogFormat = constable.Error("invalid log format, valid choices are the empty string, json, kubernetes-json and text")
 )
 
 var _ json.Unmarshaler = func() *LogFormat {
@@ -48,6 +53,10 @@ var _ json.Unmarshaler = func() *LogFormat {
 type LogSpec struct {
 	Level  LogLevel  `json:"level,omitempty"`
 	Format LogFormat `json:"format,omitempty"`
+	// Components overrides Level for the logs of named loggers, e.g. {"impersonation-proxy": "trace"}. A component
+	// applies to the logger with that name and to all loggers whose names start with it, and the longest matching
+	// component wins. Note that the deprecated text format does not support components.
+	Components map[string]LogLevel `json:"components,omitempty"`
 }
 
 func MaybeSetDeprecatedLogLevel(level *LogLevel, log *LogSpec) {
@@ -58,21 +67,17 @@ func MaybeSetDeprecatedLogLevel(level *LogLevel, log *LogSpec) {
 }
 
 func ValidateAndSetLogLevelAndFormatGlobally(ctx context.Context, spec LogSpec) error {
-	klogLevel := klogLevelForPlogLevel(spec.Level)
-	if klogLevel < 0 {
-		return errInvalidLogLevel
-	}
-
-	// set the global log levels used by our code and the kube code underneath us
-	if _, err := logs.GlogSetter(strconv.Itoa(int(klogLevel))); err != nil {
-		panic(err) // programmer error
+	if err := SetLogLevelGlobally(spec.Level, spec.Components); err != nil {
+		return err
 	}
-	globalLevel.SetLevel(zapcore.Level(-klogLevel)) // klog levels are inverted when zap handles them
+	klogLevel := klogLevelForPlogLevel(spec.Level)
 
 	var encoding string
 	switch spec.Format {
 	case "", FormatJSON:
 		encoding = "json"
+	case FormatKubernetesJSON:
+		encoding = "kubernetes-json"
 	case FormatCLI:
 		encoding = "console"
 	case FormatText:
@@ -105,3 +110,58 @@ func ValidateAndSetLogLevelAndFormatGlobally(ctx context.Context, spec LogSpec)
 
 	return nil
 }
+
+// ValidateLogSpec checks the log levels and the format of the spec without applying them, e.g. before a configuration
+// which is reloaded while running is applied.
+func ValidateLogSpec(spec LogSpec) error {
+	if klogLevelForPlogLevel(spec.Level) < 0 {
+		return errInvalidLogLevel
+	}
+	for component, componentLevel := range spec.Components {
+		if klogLevelForPlogLevel(componentLevel) < 0 {
+			return fmt.Errorf("log level of component %q: %w", component, errInvalidLogLevel)
+		}
+	}
+	switch spec.Format {
+	case "", FormatJSON, FormatKubernetesJSON, FormatCLI, FormatText:
+		return nil
+	default:
+		return errInvalidLogFormat
+	}
+}
+
+// SetLogLevelGlobally changes the global log level and the log levels of components while keeping the current loggers,
+// so that it can be called at any time, e.g. to turn on debug logs during an incident without restarting the server.
+// Note that the deprecated text format cannot log at a higher level than the one which was set by
+// ValidateAndSetLogLevelAndFormatGlobally.
+func SetLogLevelGlobally(level LogLevel, components map[string]LogLevel) error {
+	klogLevel := klogLevelForPlogLevel(level)
+	if klogLevel < 0 {
+		return errInvalidLogLevel
+	}
+
+	// the loggers need to let through the logs of the most verbose level, the components core filters the rest
+	maxKlogLevel := klogLevel
+	levels := &componentLevels{
+		base:       zapcore.Level(-klogLevel), // klog levels are inverted when zap handles them
+		components: make(map[string]zapcore.Level, len(components)),
+	}
+	for component, componentLevel := range components {
+		componentKlogLevel := klogLevelForPlogLevel(componentLevel)
+		if componentKlogLevel < 0 {
+			return fmt.Errorf("log level of component %q: %w", component, errInvalidLogLevel)
+		}
+		if componentKlogLevel > maxKlogLevel {
+			maxKlogLevel = componentKlogLevel
+		}
+		levels.components[component] = zapcore.Level(-componentKlogLevel)
+	}
+	globalComponentLevels.Store(levels)
+
+	// set the global log levels used by our code and the kube code underneath us
+	if _, err := logs.GlogSetter(strconv.Itoa(int(maxKlogLevel))); err != nil {
+		panic(err) // programmer error
+	}
+	globalLevel.SetLevel(zapcore.Level(-maxKlogLevel))
+	return nil
+}
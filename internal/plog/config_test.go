@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package plog
@@ -7,6 +7,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"runtime"
@@ -43,7 +44,7 @@ func TestFormat(t *testing.T) {
 	wd, err := os.Getwd()
 	require.NoError(t, err)
 
-	const startLogLine = 46 // make this match the current line number
+	const startLogLine = 47 // make this match the current line number
 
 	Info("hello", "happy", "day", "duration", time.Hour+time.Minute)
 	require.True(t, scanner.Scan())
@@ -104,7 +105,7 @@ func TestFormat(t *testing.T) {
   "timestamp": "2022-11-21T23:37:26.953313Z",
   "caller": "%s/config_test.go:%d$plog.TestFormat.func1",
   "message": "something happened",
-  "error": "invalid log format, valid choices are the empty string, json and text",
+  "error": "invalid log format, valid choices are the empty string, json, kubernetes-json and text",
   "an": "item"
 }`, wd, startLogLine+2+13+14+11+12), scanner.Text())
 
@@ -150,7 +151,7 @@ testing.tRunner
 	DebugErr("something happened", errInvalidLogFormat, "an", "item")
 	require.True(t, scanner.Scan())
 	require.NoError(t, scanner.Err())
-	require.Equal(t, fmt.Sprintf(nowStr+`  plog/config_test.go:%d  something happened  {"error": "invalid log format, valid choices are the empty string, json and text", "an": "item"}`,
+	require.Equal(t, fmt.Sprintf(nowStr+`  plog/config_test.go:%d  something happened  {"error": "invalid log format, valid choices are the empty string, json, kubernetes-json and text", "an": "item"}`,
 		startLogLine+2+13+14+11+12+24+28), scanner.Text())
 
 	Logr().WithName("burrito").Error(errInvalidLogLevel, "wee", "a", "b", "slightly less than a year", 363*24*time.Hour, "slightly more than 2 years", 2*367*24*time.Hour)
@@ -323,6 +324,89 @@ func TestValidateAndSetLogLevelGlobally(t *testing.T) {
 	require.Equal(t, originalLogLevel, getKlogLevel())
 }
 
+func TestSetLogLevelGlobally(t *testing.T) {
+	originalLogLevel := getKlogLevel()
+	require.GreaterOrEqual(t, int(originalLogLevel), int(klog.Level(0)), "cannot get klog level")
+	defer func() {
+		undoGlobalLogLevelChanges(t, originalLogLevel)
+	}()
+
+	require.NoError(t, SetLogLevelGlobally(LevelDebug, nil))
+	require.Equal(t, klog.Level(4), getKlogLevel())
+	require.True(t, Enabled(LevelDebug))
+	require.False(t, Enabled(LevelTrace))
+
+	require.NoError(t, SetLogLevelGlobally(LevelWarning, nil))
+	require.Equal(t, klog.Level(0), getKlogLevel())
+	require.True(t, Enabled(LevelWarning))
+	require.False(t, Enabled(LevelInfo))
+
+	// components raise the klog level so that their logs get through, but Enabled is only about the global level
+	require.NoError(t, SetLogLevelGlobally(LevelInfo, map[string]LogLevel{"impersonation-proxy": LevelTrace}))
+	require.Equal(t, klog.Level(6), getKlogLevel())
+	require.True(t, Enabled(LevelInfo))
+	require.False(t, Enabled(LevelDebug))
+
+	require.EqualError(t, SetLogLevelGlobally("panda", nil), errInvalidLogLevel.Error())
+	require.EqualError(t, SetLogLevelGlobally(LevelInfo, map[string]LogLevel{"impersonation-proxy": "panda"}),
+		`log level of component "impersonation-proxy": `+errInvalidLogLevel.Error())
+	require.Equal(t, klog.Level(6), getKlogLevel())
+}
+
+func TestComponentLevels(t *testing.T) {
+	originalLogLevel := getKlogLevel()
+	require.GreaterOrEqual(t, int(originalLogLevel), int(klog.Level(0)), "cannot get klog level")
+	defer func() {
+		undoGlobalLogLevelChanges(t, originalLogLevel)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var buf bytes.Buffer
+	ctx = TestZapOverrides(ctx, t, &buf, nil)
+
+	err := ValidateAndSetLogLevelAndFormatGlobally(ctx, LogSpec{
+		Level: LevelInfo,
+		Components: map[string]LogLevel{
+			"impersonation-proxy":        LevelTrace,
+			"impersonation-proxy.quiet":  LevelWarning,
+			"kube-cert-agent-controller": LevelDebug,
+		},
+	})
+	require.NoError(t, err)
+
+	Info("unnamed info")
+	Debug("unnamed debug")
+	WithName("impersonation-proxy").Trace("component trace")
+	WithName("impersonation-proxy").All("component all")
+	WithName("impersonation-proxy").WithName("handler").Trace("child trace")
+	WithName("impersonation-proxy").WithName("quiet").Info("quiet info")
+	WithName("impersonation-proxy").WithName("quiet").Warning("quiet warning")
+	WithName("impersonation-proxy-other").Debug("similar name debug")
+	WithName("kube-cert-agent-controller").Debug("other component debug")
+	WithName("kube-cert-agent-controller").Error("other component error", errInvalidLogLevel)
+
+	var got []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line struct {
+			Message string `json:"message"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		got = append(got, line.Message)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []string{
+		"unnamed info",
+		"component trace",
+		"child trace",
+		"quiet warning",
+		"other component debug",
+		"other component error",
+	}, got)
+}
+
 func contains(haystack []LogLevel, needle LogLevel) bool {
 	for _, hay := range haystack {
 		if hay == needle {
@@ -344,6 +428,7 @@ func undoGlobalLogLevelChanges(t *testing.T, originalLogLevel klog.Level) {
 	t.Helper()
 	_, err := logs.GlogSetter(strconv.Itoa(int(originalLogLevel)))
 	require.NoError(t, err)
+	globalComponentLevels.Store(&componentLevels{})
 }
 
 func getKlogLevel() klog.Level {
@@ -357,3 +442,76 @@ func getKlogLevel() klog.Level {
 
 	return -1
 }
+
+func TestFormatKubernetesJSON(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var buf bytes.Buffer
+
+	scanner := bufio.NewScanner(&buf)
+
+	now, err := time.Parse(time.RFC3339Nano, "2022-11-21T23:37:26.953Z")
+	require.NoError(t, err)
+	fakeClock := clocktesting.NewFakeClock(now)
+
+	ctx = TestZapOverrides(ctx, t, &buf, nil, zap.WithClock(ZapClock(fakeClock)))
+
+	err = ValidateAndSetLogLevelAndFormatGlobally(ctx, LogSpec{Level: LevelDebug, Format: FormatKubernetesJSON})
+	require.NoError(t, err)
+
+	const startLogLine = 463 // make this match the current line number
+
+	Debug("hello", "happy", "day", "duration", time.Hour+time.Minute)
+	require.True(t, scanner.Scan())
+	require.NoError(t, scanner.Err())
+	require.JSONEq(t, fmt.Sprintf(`
+{
+  "level": "info",
+  "v": 4,
+  "ts": 1669073846953,
+  "caller": "plog/config_test.go:%d",
+  "msg": "hello",
+  "happy": "day",
+  "duration": "1h1m0s"
+}`, startLogLine+2), scanner.Text())
+
+	Warning("bad stuff")
+	require.True(t, scanner.Scan())
+	require.NoError(t, scanner.Err())
+	require.JSONEq(t, fmt.Sprintf(`
+{
+  "level": "info",
+  "v": 0,
+  "ts": 1669073846953,
+  "caller": "plog/config_test.go:%d",
+  "msg": "bad stuff",
+  "warning": true
+}`, startLogLine+2+14), scanner.Text())
+
+	WithName("burrito").Error("wee", errInvalidLogLevel, "a", "b")
+	require.True(t, scanner.Scan())
+	require.NoError(t, scanner.Err())
+	require.JSONEq(t, fmt.Sprintf(`
+{
+  "level": "error",
+  "ts": 1669073846953,
+  "caller": "plog/config_test.go:%d",
+  "msg": "wee",
+  "logger": "burrito",
+  "a": "b",
+  "error": "invalid log level, valid choices are the empty string, info, debug, trace and all"
+}`, startLogLine+2+14+13), scanner.Text())
+
+	Trace("should not be logged", "for", "sure")
+	require.Empty(t, buf.String())
+}
+
+func TestValidateLogSpec(t *testing.T) {
+	require.NoError(t, ValidateLogSpec(LogSpec{}))
+	require.NoError(t, ValidateLogSpec(LogSpec{Level: LevelDebug, Format: FormatText, Components: map[string]LogLevel{"a": LevelTrace}}))
+	require.EqualError(t, ValidateLogSpec(LogSpec{Level: "panda"}), errInvalidLogLevel.Error())
+	require.EqualError(t, ValidateLogSpec(LogSpec{Components: map[string]LogLevel{"a": "panda"}}),
+		`log level of component "a": `+errInvalidLogLevel.Error())
+	require.EqualError(t, ValidateLogSpec(LogSpec{Format: "panda"}), errInvalidLogFormat.Error())
+}
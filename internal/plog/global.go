@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package plog
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/url"
 	"sync"
+	"sync/atomic"
 
 	"github.com/go-logr/logr"
 	"go.uber.org/zap"
@@ -22,6 +23,9 @@ var (
 	globalLogger logr.Logger
 	globalFlush  func()
 
+	// holds a *componentLevels, which is swapped atomically because it is read by every log call.
+	globalComponentLevels atomic.Value
+
 	// used as a temporary storage for a buffer per call of newLogr. see the init function below for more details.
 	sinkMap sync.Map
 )
@@ -30,6 +34,7 @@ var (
 func init() {
 	// make sure we always have a functional global logger
 	globalLevel = zap.NewAtomicLevelAt(0) // log at the 0 verbosity level to start with, i.e. the "always" logs
+	globalComponentLevels.Store(&componentLevels{})
 	// use json encoding to start with
 	// the context here is just used for test injection and thus can be ignored
 	log, flush, err := newLogr(context.Background(), "json", 0)
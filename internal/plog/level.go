@@ -1,9 +1,11 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package plog
 
 import (
+	"strings"
+
 	"go.uber.org/zap/zapcore"
 	"k8s.io/klog/v2"
 )
@@ -45,7 +47,31 @@ func Enabled(level LogLevel) bool {
 	l := klogLevelForPlogLevel(level)
 	// check that both our global level and the klog global level agree that the plog level is enabled
 	// klog levels are inverted when zap handles them
-	return globalLevel.Enabled(zapcore.Level(-l)) && klog.V(l).Enabled()
+	return loadComponentLevels().base.Enabled(zapcore.Level(-l)) && klog.V(l).Enabled()
+}
+
+// componentLevels holds the zap levels of the logs of unnamed loggers and of the components set via LogSpec.
+type componentLevels struct {
+	base       zapcore.Level
+	components map[string]zapcore.Level
+}
+
+func loadComponentLevels() *componentLevels {
+	return globalComponentLevels.Load().(*componentLevels)
+}
+
+// enabled returns whether a log at the given level is enabled for the logger with the given name.
+func (c *componentLevels) enabled(name string, level zapcore.Level) bool {
+	if len(c.components) == 0 {
+		return true // the level of the logger is the base level, and it has already been checked
+	}
+	minLevel, longest := c.base, -1
+	for component, componentLevel := range c.components {
+		if len(component) > longest && (name == component || strings.HasPrefix(name, component+".")) {
+			minLevel, longest = componentLevel, len(component)
+		}
+	}
+	return minLevel.Enabled(level)
 }
 
 func klogLevelForPlogLevel(plogLevel LogLevel) klog.Level {
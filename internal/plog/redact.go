@@ -0,0 +1,128 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-logr/logr"
+)
+
+const redacted = "[redacted]"
+
+// Secret is a sensitive string, such as a bearer token, which must never be logged. It redacts itself when it is
+// formatted or serialized, so it is safe to store in values which may end up in logs by accident, such as contexts.
+// The value is held behind a pointer so that it is not printed even when fmt reaches a Secret via reflection, e.g. when
+// a context is printed with %#v. Use Reveal to get the actual value.
+type Secret struct {
+	value *string
+}
+
+var (
+	_ fmt.Formatter         = Secret{}
+	_ fmt.GoStringer        = Secret{}
+	_ json.Marshaler        = Secret{}
+	_ logr.Marshaler        = Secret{}
+	_ logr.LogSink          = redactingSink{}
+	_ logr.CallDepthLogSink = redactingSink{}
+)
+
+// NewSecret returns a Secret which holds value.
+func NewSecret(value string) Secret {
+	return Secret{value: &value}
+}
+
+// Reveal returns the sensitive value, or the empty string for the zero Secret. It must not be passed to a logger.
+func (s Secret) Reveal() string {
+	if s.value == nil {
+		return ""
+	}
+	return *s.value
+}
+
+func (s Secret) String() string { return redacted }
+
+func (s Secret) GoString() string { return redacted }
+
+func (s Secret) Format(f fmt.State, _ rune) { _, _ = io.WriteString(f, redacted) }
+
+func (s Secret) MarshalJSON() ([]byte, error) { return json.Marshal(redacted) }
+
+func (s Secret) MarshalLog() interface{} { return redacted }
+
+// redactingSink refuses to serialize the values of known sensitive types. Contexts are redacted because they are used
+// to smuggle sensitive values, such as bearer tokens, between handlers, and requests are redacted because they hold
+// both a context and the headers which carry credentials.
+type redactingSink struct {
+	logr.LogSink
+}
+
+// newRedactingLogger returns a logger which redacts the values of known sensitive types and then logs via log.
+func newRedactingLogger(log logr.Logger) logr.Logger {
+	sink := log.GetSink()
+	if callDepthSink, ok := sink.(logr.CallDepthLogSink); ok {
+		sink = callDepthSink.WithCallDepth(1) // account for the extra stack frame of redactingSink
+	}
+	return logr.New(redactingSink{LogSink: sink})
+}
+
+// Init does nothing because the wrapped sink has already been initialized.
+func (s redactingSink) Init(_ logr.RuntimeInfo) {}
+
+func (s redactingSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.LogSink.Info(level, msg, redact(keysAndValues)...)
+}
+
+func (s redactingSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.LogSink.Error(err, msg, redact(keysAndValues)...)
+}
+
+func (s redactingSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return redactingSink{LogSink: s.LogSink.WithValues(redact(keysAndValues)...)}
+}
+
+func (s redactingSink) WithName(name string) logr.LogSink {
+	return redactingSink{LogSink: s.LogSink.WithName(name)}
+}
+
+func (s redactingSink) WithCallDepth(depth int) logr.LogSink {
+	if callDepthSink, ok := s.LogSink.(logr.CallDepthLogSink); ok {
+		return redactingSink{LogSink: callDepthSink.WithCallDepth(depth)}
+	}
+	return s
+}
+
+// redact returns keysAndValues with the values of known sensitive types replaced. It does not mutate its input.
+func redact(keysAndValues []interface{}) []interface{} {
+	var out []interface{}
+	for i := 1; i < len(keysAndValues); i += 2 {
+		replacement, ok := redactValue(keysAndValues[i])
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]interface{}(nil), keysAndValues...) // copy on first write
+		}
+		out[i] = replacement
+	}
+	if out == nil {
+		return keysAndValues
+	}
+	return out
+}
+
+func redactValue(value interface{}) (string, bool) {
+	switch value.(type) {
+	case Secret:
+		return redacted, true
+	case context.Context, *http.Request:
+		return fmt.Sprintf("[redacted %T]", value), true
+	default:
+		return "", false
+	}
+}
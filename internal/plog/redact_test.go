@@ -0,0 +1,74 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sensitiveValue = "some-bearer-token"
+
+type secretKey struct{}
+
+func TestSecret(t *testing.T) {
+	t.Parallel()
+
+	secret := NewSecret(sensitiveValue)
+	require.Equal(t, sensitiveValue, secret.Reveal())
+	require.Empty(t, Secret{}.Reveal())
+
+	ctx := context.WithValue(context.Background(), secretKey{}, secret)
+	for _, value := range []interface{}{secret, &secret, ctx} {
+		for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+			require.NotContains(t, fmt.Sprintf(format, value), sensitiveValue, "format %s of %T", format, value)
+		}
+	}
+	require.Equal(t, "[redacted]", fmt.Sprintf("%s", secret))
+
+	b, err := json.Marshal(map[string]interface{}{"secret": secret})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"secret": "[redacted]"}`, string(b))
+}
+
+func TestRedactingLogger(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), secretKey{}, sensitiveValue) // the worst case: an unwrapped value
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+sensitiveValue)
+
+	var log bytes.Buffer
+	logger := TestLogger(t, &log)
+
+	logger.WithValues("ctx", ctx).Info("with values", "ok", "value")
+	logger.Always("always", "ctx", ctx, "req", req, "secret", NewSecret(sensitiveValue))
+	logger.Error("error", fmt.Errorf("some error"), "req", req)
+	require.NotContains(t, log.String(), sensitiveValue)
+	require.Contains(t, log.String(), `"ctx":"[redacted *context.valueCtx]"`)
+	require.Contains(t, log.String(), `"req":"[redacted *http.Request]"`)
+	require.Contains(t, log.String(), `"secret":"[redacted]"`)
+	require.Contains(t, log.String(), `"ok":"value"`)
+}
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	keysAndValues := []interface{}{"a", "b", "ctx", ctx, "secret", NewSecret(sensitiveValue), "c"}
+
+	got := redact(keysAndValues)
+	require.Equal(t, []interface{}{"a", "b", "ctx", fmt.Sprintf("[redacted %T]", ctx), "secret", "[redacted]", "c"}, got)
+	require.Equal(t, ctx, keysAndValues[3], "input must not be mutated")
+
+	unchanged := []interface{}{"a", "b", "ctx"} // a key without a value is never redacted
+	require.Equal(t, unchanged, redact(unchanged))
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package plog
@@ -37,10 +37,14 @@ func newLogr(ctx context.Context, encoding string, klogLevel klog.Level) (logr.L
 			}
 		}
 
-		return textlogger.NewLogger(textlogger.NewConfig(textlogger.Verbosity(int(klogLevel)), textlogger.Output(w))), flush, nil
+		return newRedactingLogger(textlogger.NewLogger(textlogger.NewConfig(textlogger.Verbosity(int(klogLevel)), textlogger.Output(w)))), flush, nil
 	}
 
 	path := "stderr" // this is how zap refers to os.Stderr
+	kubernetesJSON := encoding == "kubernetes-json"
+	if kubernetesJSON {
+		encoding = "json" // only the key names and values differ from our regular JSON
+	}
 	f := func(config *zap.Config) {
 		if encoding == "console" {
 			config.EncoderConfig.LevelKey = zapcore.OmitKey
@@ -48,6 +52,9 @@ func newLogr(ctx context.Context, encoding string, klogLevel klog.Level) (logr.L
 			config.EncoderConfig.EncodeTime = humanTimeEncoder
 			config.EncoderConfig.EncodeDuration = humanDurationEncoder
 		}
+		if kubernetesJSON {
+			kubernetesEncoderConfig(&config.EncoderConfig)
+		}
 	}
 	var opts []zap.Option
 
@@ -83,11 +90,21 @@ func newLogr(ctx context.Context, encoding string, klogLevel klog.Level) (logr.L
 		}
 	}
 
+	if kubernetesJSON {
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core { return verbosityCore{Core: core} }))
+	}
+	// this must wrap all other cores so that its check is not skipped
+	opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core { return componentLevelCore{Core: core} }))
+
 	// when using the trace or all log levels, an error log will contain the full stack.
 	// this is too noisy for regular use because things like leader election conflicts
 	// result in transient errors and we do not want all of that noise in the logs.
 	// this check is performed dynamically on the global log level.
-	return newZapr(globalLevel, LevelTrace, encoding, path, f, opts...)
+	log, flush, err := newZapr(globalLevel, LevelTrace, encoding, path, f, opts...)
+	if err != nil {
+		return logr.Logger{}, nil, err
+	}
+	return newRedactingLogger(log), flush, nil
 }
 
 func newZapr(level zap.AtomicLevel, addStack zapcore.LevelEnabler, encoding, path string, f func(config *zap.Config), opts ...zap.Option) (logr.Logger, func(), error) {
@@ -166,6 +183,70 @@ func zapLevelToPlogLevel(l zapcore.Level) LogLevel {
 	}
 }
 
+// kubernetesEncoderConfig changes the key names and values of an encoder config to match the JSON format of
+// Kubernetes structured logging, i.e. {"ts":1580306777.04728,"caller":"pkg/file.go:42","msg":"...","v":0,...}.
+func kubernetesEncoderConfig(config *zapcore.EncoderConfig) {
+	config.MessageKey = "msg"
+	config.TimeKey = "ts"
+	config.EncodeTime = epochMillisTimeEncoder
+	config.EncodeCaller = zapcore.ShortCallerEncoder
+	config.EncodeLevel = kubernetesLevelEncoder
+}
+
+// kubernetesLevelEncoder only distinguishes info and error logs, as Kubernetes structured logging does.
+// The verbosity of info logs is added separately via verbosityCore.
+func kubernetesLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if l > 0 {
+		enc.AppendString(l.String())
+		return
+	}
+	enc.AppendString(zapcore.InfoLevel.String())
+}
+
+func epochMillisTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendFloat64(float64(t.UnixNano()) / float64(time.Millisecond))
+}
+
+// verbosityCore adds the klog verbosity of info logs using the "v" key, as Kubernetes structured logging does.
+type verbosityCore struct {
+	zapcore.Core
+}
+
+func (c verbosityCore) With(fields []zapcore.Field) zapcore.Core {
+	return verbosityCore{Core: c.Core.With(fields)}
+}
+
+func (c verbosityCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c verbosityCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.Level <= 0 {
+		fields = append([]zapcore.Field{zap.Int("v", int(-entry.Level))}, fields...) // klog levels are inverted when zap handles them
+	}
+	return c.Core.Write(entry, fields)
+}
+
+// componentLevelCore drops the logs of loggers which are not enabled by the log level of their component. The level
+// of the wrapped core is the most verbose of all levels, see SetLogLevelGlobally.
+type componentLevelCore struct {
+	zapcore.Core
+}
+
+func (c componentLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return componentLevelCore{Core: c.Core.With(fields)}
+}
+
+func (c componentLevelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !loadComponentLevels().enabled(entry.LoggerName, entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
 func callerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
 	enc.AppendString(caller.String() + funcEncoder(caller))
 }
@@ -0,0 +1,88 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pmetrics holds the conventions shared by the Prometheus metrics of all Pinniped components, so that
+// dashboards can be shared across installs.
+//
+// All metrics are named pinniped_<subsystem>_<name>, where the subsystem names the component or the part of the
+// component which emits the metric. All latency histograms use the configured latency buckets, if any, instead of
+// their default buckets.
+package pmetrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/felixge/httpsnoop"
+	"k8s.io/component-base/metrics"
+)
+
+// Namespace is the namespace of all Pinniped metrics.
+const Namespace = "pinniped"
+
+// The subsystems of the Pinniped metrics. Subsystems which only exist in one component are prefixed by its name.
+const (
+	SubsystemController                        = "controller"
+	SubsystemKubeClient                        = "kube_client"
+	SubsystemImpersonationProxy                = "impersonation_proxy"
+	SubsystemSupervisorEndpoint                = "supervisor_endpoint"
+	SubsystemSupervisorFederationDomain        = "supervisor_federation_domain"
+	SubsystemSupervisorStorageGarbageCollector = "supervisor_storage_garbage_collector"
+)
+
+//nolint:gochecknoglobals // the buckets are configured once at startup, before any metrics are registered
+var (
+	latencyBucketsMu sync.RWMutex
+	latencyBuckets   []float64
+)
+
+// ValidateLatencyBuckets validates configured latency buckets, which must be positive and strictly increasing.
+func ValidateLatencyBuckets(buckets []float64) error {
+	for i, b := range buckets {
+		if b <= 0 {
+			return fmt.Errorf("latency bucket %d must be positive", i)
+		}
+		if i > 0 && b <= buckets[i-1] {
+			return fmt.Errorf("latency bucket %d must be greater than the previous bucket", i)
+		}
+	}
+	return nil
+}
+
+// SetLatencyBuckets configures the buckets of all latency histograms. Empty buckets restore the default buckets of
+// each histogram. It only applies to the histograms which are registered afterwards.
+func SetLatencyBuckets(buckets []float64) {
+	latencyBucketsMu.Lock()
+	defer latencyBucketsMu.Unlock()
+
+	latencyBuckets = append([]float64(nil), buckets...)
+}
+
+// ApplyLatencyBuckets replaces the buckets of the given latency histograms by the configured latency buckets, if any.
+// It must be called before the histograms are registered.
+func ApplyLatencyBuckets(opts ...*metrics.HistogramOpts) {
+	latencyBucketsMu.RLock()
+	defer latencyBucketsMu.RUnlock()
+
+	if len(latencyBuckets) == 0 {
+		return
+	}
+	for _, o := range opts {
+		o.Buckets = append([]float64(nil), latencyBuckets...)
+	}
+}
+
+// InstrumentHandler observes the duration of each request served by handler in the latency histogram, labeled by the
+// given label values followed by the HTTP status code of the response. Long-running requests, e.g. watches, are
+// observed when they end.
+func InstrumentHandler(handler http.Handler, histogram *metrics.HistogramVec, labelValues ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := httpsnoop.CaptureMetrics(handler, w, r)
+		values := make([]string, 0, len(labelValues)+1)
+		values = append(values, labelValues...)
+		values = append(values, strconv.Itoa(m.Code))
+		histogram.WithLabelValues(values...).Observe(m.Duration.Seconds())
+	})
+}
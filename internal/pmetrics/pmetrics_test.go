@@ -0,0 +1,73 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pmetrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/component-base/metrics"
+	metricstestutil "k8s.io/component-base/metrics/testutil"
+)
+
+func TestValidateLatencyBuckets(t *testing.T) {
+	require.NoError(t, ValidateLatencyBuckets(nil))
+	require.NoError(t, ValidateLatencyBuckets([]float64{0.01, 0.1, 1, 10}))
+	require.EqualError(t, ValidateLatencyBuckets([]float64{-1}), "latency bucket 0 must be positive")
+	require.EqualError(t, ValidateLatencyBuckets([]float64{0.1, 0}), "latency bucket 1 must be positive")
+	require.EqualError(t, ValidateLatencyBuckets([]float64{0.1, 1, 1}), "latency bucket 2 must be greater than the previous bucket")
+	require.EqualError(t, ValidateLatencyBuckets([]float64{1, 0.1}), "latency bucket 1 must be greater than the previous bucket")
+}
+
+func TestApplyLatencyBuckets(t *testing.T) {
+	t.Cleanup(func() { SetLatencyBuckets(nil) })
+
+	defaults := []float64{1, 2, 3}
+	opts := &metrics.HistogramOpts{Buckets: defaults}
+
+	ApplyLatencyBuckets(opts)
+	require.Equal(t, defaults, opts.Buckets, "the defaults are kept when no buckets are configured")
+
+	configured := []float64{0.5, 5}
+	SetLatencyBuckets(configured)
+	configured[0] = 100 // the configured buckets are copied
+	other := &metrics.HistogramOpts{}
+	ApplyLatencyBuckets(opts, other)
+	require.Equal(t, []float64{0.5, 5}, opts.Buckets)
+	require.Equal(t, []float64{0.5, 5}, other.Buckets)
+
+	SetLatencyBuckets(nil)
+	opts = &metrics.HistogramOpts{Buckets: defaults}
+	ApplyLatencyBuckets(opts)
+	require.Equal(t, defaults, opts.Buckets)
+}
+
+func TestInstrumentHandler(t *testing.T) {
+	histogram := metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "test",
+		Name:      "request_duration_seconds",
+	}, []string{"endpoint", "code"})
+	metrics.NewKubeRegistry().MustRegister(histogram)
+
+	handler := InstrumentHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/teapot" {
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}), histogram, "some-endpoint")
+
+	for _, path := range []string{"/", "/", "/teapot"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	ok, err := metricstestutil.GetHistogramMetricCount(histogram.WithLabelValues("some-endpoint", "200"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), ok)
+
+	teapot, err := metricstestutil.GetHistogramMetricCount(histogram.WithLabelValues("some-endpoint", "418"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), teapot)
+}
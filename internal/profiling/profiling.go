@@ -0,0 +1,92 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package profiling serves the pprof endpoints of the Go runtime, so that operators can capture CPU and heap
+// profiles of the Concierge and the Supervisor during performance incidents.
+package profiling
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"time"
+
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/plog"
+)
+
+// Spec configures the pprof endpoints, which are disabled by default.
+type Spec struct {
+	// Enabled serves the pprof endpoints under /debug/pprof/ on the aggregated API server. Its clients must be
+	// authenticated by the Kubernetes API server, and authorized for the non-resource URL /debug/pprof/*.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// LoopbackPort additionally serves the pprof endpoints without authentication on this port of 127.0.0.1, which
+	// can only be reached from inside the pod, e.g. with kubectl port-forward. Requires Enabled.
+	LoopbackPort *int64 `json:"loopbackPort,omitempty"`
+}
+
+// Validate validates the profiling configuration.
+func (s Spec) Validate() error {
+	if s.LoopbackPort == nil {
+		return nil
+	}
+	if !s.Enabled {
+		return constable.Error("loopbackPort requires enabled to be true")
+	}
+	if *s.LoopbackPort < 1024 || *s.LoopbackPort > 65535 {
+		return constable.Error("loopbackPort must be between 1024 and 65535")
+	}
+	return nil
+}
+
+// ApplyTo enables the pprof endpoints of the aggregated API server when they are enabled.
+func (s Spec) ApplyTo(options *genericoptions.RecommendedOptions) {
+	options.Features.EnableProfiling = s.Enabled
+}
+
+// ServeLoopback serves the pprof endpoints on the loopback port until ctx is done, when the port is configured.
+func (s Spec) ServeLoopback(ctx context.Context) error {
+	if !s.Enabled || s.LoopbackPort == nil {
+		return nil
+	}
+
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.FormatInt(*s.LoopbackPort, 10)))
+	if err != nil {
+		return fmt.Errorf("cannot create profiling listener: %w", err)
+	}
+
+	// There is no write timeout, since a CPU profile or a trace is written only after its duration has passed.
+	server := &http.Server{
+		Handler:           handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	go func() {
+		err := server.Serve(l)
+		plog.Debug("profiling server exited", "err", err)
+	}()
+
+	plog.Info("serving profiling endpoints", "address", l.Addr().String())
+	return nil
+}
+
+func handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package profiling
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/utils/pointer"
+)
+
+func TestSpecValidate(t *testing.T) {
+	require.NoError(t, Spec{}.Validate())
+	require.NoError(t, Spec{Enabled: true}.Validate())
+	require.NoError(t, Spec{Enabled: true, LoopbackPort: pointer.Int64(6060)}.Validate())
+	require.EqualError(t, Spec{LoopbackPort: pointer.Int64(6060)}.Validate(), "loopbackPort requires enabled to be true")
+	require.EqualError(t, Spec{Enabled: true, LoopbackPort: pointer.Int64(80)}.Validate(), "loopbackPort must be between 1024 and 65535")
+	require.EqualError(t, Spec{Enabled: true, LoopbackPort: pointer.Int64(65536)}.Validate(), "loopbackPort must be between 1024 and 65535")
+}
+
+func TestSpecApplyTo(t *testing.T) {
+	options := genericoptions.NewRecommendedOptions("", nil)
+	require.True(t, options.Features.EnableProfiling, "the upstream default is expected to be enabled")
+
+	Spec{}.ApplyTo(options)
+	require.False(t, options.Features.EnableProfiling)
+
+	Spec{Enabled: true}.ApplyTo(options)
+	require.True(t, options.Features.EnableProfiling)
+}
+
+func TestServeLoopback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.NoError(t, Spec{}.ServeLoopback(ctx))
+
+	port := freeLoopbackPort(t)
+	require.NoError(t, Spec{Enabled: true, LoopbackPort: &port}.ServeLoopback(ctx))
+
+	resp, err := http.Get("http://127.0.0.1:" + strconv.FormatInt(port, 10) + "/debug/pprof/cmdline")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.ErrorContains(t, Spec{Enabled: true, LoopbackPort: &port}.ServeLoopback(ctx), "cannot create profiling listener")
+}
+
+func TestHandler(t *testing.T) {
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/pprof/cmdline", "/debug/pprof/symbol"} {
+		rec := httptest.NewRecorder()
+		handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code, path)
+	}
+
+	rec := httptest.NewRecorder()
+	handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "not found")
+}
+
+func freeLoopbackPort(t *testing.T) int64 {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+	return int64(port)
+}
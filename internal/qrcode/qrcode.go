@@ -0,0 +1,540 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package qrcode encodes short texts, like the provisioning URLs of TOTP secrets, as QR codes which are rendered as
+// inline SVG images, so the Supervisor's login pages do not need to load any images or scripts.
+//
+// Only the subset of ISO/IEC 18004 which is needed for that is implemented: the byte mode, the error correction
+// level M, and versions 1 through 10, i.e. texts of up to 213 bytes.
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+const (
+	ErrDataTooLong = constable.Error("data is too long for a QR code")
+
+	minVersion = 1
+	maxVersion = 10
+
+	// The mode indicator of the byte mode.
+	byteMode = 0x4
+
+	// The format bits of the error correction level M.
+	eccLevelMFormatBits = 0x0
+
+	// The number of light modules around the symbol.
+	quietZone = 4
+)
+
+//nolint:gochecknoglobals // These are constant tables of the standard, indexed by version.
+var (
+	// eccCodewordsPerBlock is the number of error correction codewords of each block at the error correction level M.
+	eccCodewordsPerBlock = [maxVersion + 1]int{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26}
+
+	// numErrorCorrectionBlocks is the number of blocks at the error correction level M.
+	numErrorCorrectionBlocks = [maxVersion + 1]int{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5}
+)
+
+// Code is a QR code, i.e. a square of dark and light modules.
+type Code struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Encode returns the QR code of the data, using the smallest version which can hold the data.
+func Encode(data []byte) (*Code, error) {
+	version := minVersion
+	for ; version <= maxVersion; version++ {
+		if 4+charCountBits(version)+8*len(data) <= numDataCodewords(version)*8 {
+			break
+		}
+	}
+	if version > maxVersion {
+		return nil, fmt.Errorf("%w: %d bytes", ErrDataTooLong, len(data))
+	}
+
+	code := newCode(version)
+	code.drawFunctionPatterns()
+	code.drawCodewords(addErrorCorrectionAndInterleave(version, encodeData(version, data)))
+
+	// Use the mask which makes the symbol easiest to read.
+	bestMask, minPenalty := -1, 0
+	for mask := 0; mask < 8; mask++ {
+		code.applyMask(mask)
+		code.drawFormatBits(mask)
+		if penalty := code.penalty(); bestMask < 0 || penalty < minPenalty {
+			bestMask, minPenalty = mask, penalty
+		}
+		code.applyMask(mask) // masks are XORed, so applying a mask again removes it
+	}
+	code.applyMask(bestMask)
+	code.drawFormatBits(bestMask)
+
+	return code, nil
+}
+
+// Size returns the number of modules in each row and column, excluding the quiet zone.
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Dark returns whether the module in the given column and row is dark.
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && x < c.size && y >= 0 && y < c.size && c.modules[y][x]
+}
+
+// SVG returns an SVG image of the QR code including its quiet zone, in which each module is one unit wide.
+// The image scales to the size of its container.
+func (c *Code) SVG() string {
+	var path strings.Builder
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+quietZone, y+quietZone)
+			}
+		}
+	}
+	dimension := c.size + 2*quietZone
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %[1]d %[1]d" shape-rendering="crispEdges">`+
+		`<rect width="%[1]d" height="%[1]d" fill="#fff"/><path d="%[2]s" fill="#000"/></svg>`, dimension, path.String())
+}
+
+func newCode(version int) *Code {
+	size := version*4 + 17
+	c := &Code{version: version, size: size, modules: make([][]bool, size), isFunction: make([][]bool, size)}
+	for i := 0; i < size; i++ {
+		c.modules[i] = make([]bool, size)
+		c.isFunction[i] = make([]bool, size)
+	}
+	return c
+}
+
+func (c *Code) setFunctionModule(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunction[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	// Timing patterns.
+	for i := 0; i < c.size; i++ {
+		c.setFunctionModule(6, i, i%2 == 0)
+		c.setFunctionModule(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns and their separators, in three corners.
+	c.drawFinderPattern(3, 3)
+	c.drawFinderPattern(c.size-4, 3)
+	c.drawFinderPattern(3, c.size-4)
+
+	// Alignment patterns, except where they would overlap the finder patterns.
+	positions := alignmentPatternPositions(c.version)
+	last := len(positions) - 1
+	for i := range positions {
+		for j := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignmentPattern(positions[i], positions[j])
+		}
+	}
+
+	// Reserve the format bits, which are drawn after the mask was chosen, and draw the version bits.
+	c.drawFormatBits(0)
+	c.drawVersionBits()
+}
+
+func (c *Code) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.size || yy < 0 || yy >= c.size {
+				continue
+			}
+			distance := maxAbs(dx, dy)
+			c.setFunctionModule(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunctionModule(x+dx, y+dy, maxAbs(dx, dy) != 1)
+		}
+	}
+}
+
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+
+	// The first copy, around the top left finder pattern.
+	for i := 0; i <= 5; i++ {
+		c.setFunctionModule(8, i, bit(bits, i))
+	}
+	c.setFunctionModule(8, 7, bit(bits, 6))
+	c.setFunctionModule(8, 8, bit(bits, 7))
+	c.setFunctionModule(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunctionModule(14-i, 8, bit(bits, i))
+	}
+
+	// The second copy, split between the other two finder patterns.
+	for i := 0; i < 8; i++ {
+		c.setFunctionModule(c.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunctionModule(8, c.size-15+i, bit(bits, i))
+	}
+	c.setFunctionModule(8, c.size-8, true) // the dark module
+}
+
+func (c *Code) drawVersionBits() {
+	if c.version < 7 {
+		return
+	}
+	bits := versionBits(c.version)
+
+	// Two copies, next to the top right and the bottom left finder patterns.
+	for i := 0; i < 18; i++ {
+		a, b := c.size-11+i%3, i/3
+		c.setFunctionModule(a, b, bit(bits, i))
+		c.setFunctionModule(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places the bits of the codewords in the zigzag order of the standard, in pairs of columns from
+// right to left, skipping the function modules.
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vertical := 0; vertical < c.size; vertical++ {
+			y := vertical
+			if upward {
+				y = c.size - 1 - vertical
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !c.isFunction[y][x] && i < len(codewords)*8 {
+					c.modules[y][x] = bit(int(codewords[i>>3]), 7-(i&7))
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if !c.isFunction[y][x] && masked(mask, x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+func masked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// penalty scores how hard the symbol would be to read, using the four rules of the standard.
+func (c *Code) penalty() int {
+	penalty := 0
+	dark := 0
+	for i := 0; i < c.size; i++ {
+		penalty += c.runPenalty(func(j int) bool { return c.modules[i][j] })
+		penalty += c.runPenalty(func(j int) bool { return c.modules[j][i] })
+		penalty += c.finderLikePenalty(func(j int) bool { return c.modules[i][j] })
+		penalty += c.finderLikePenalty(func(j int) bool { return c.modules[j][i] })
+		for j := 0; j < c.size; j++ {
+			if c.modules[i][j] {
+				dark++
+			}
+		}
+	}
+
+	// Blocks of 2x2 modules of the same color.
+	for y := 0; y < c.size-1; y++ {
+		for x := 0; x < c.size-1; x++ {
+			color := c.modules[y][x]
+			if color == c.modules[y][x+1] && color == c.modules[y+1][x] && color == c.modules[y+1][x+1] {
+				penalty += 3
+			}
+		}
+	}
+
+	// Deviation of the proportion of dark modules from 50%, in steps of 5%.
+	total := c.size * c.size
+	penalty += abs(dark*2-total) * 10 / total * 10
+
+	return penalty
+}
+
+// runPenalty scores the runs of five or more modules of the same color in a row or column.
+func (c *Code) runPenalty(module func(int) bool) int {
+	penalty := 0
+	run := 1
+	for j := 1; j <= c.size; j++ {
+		if j < c.size && module(j) == module(j-1) {
+			run++
+			continue
+		}
+		if run >= 5 {
+			penalty += 3 + run - 5
+		}
+		run = 1
+	}
+	return penalty
+}
+
+// finderLikePenalty scores the patterns in a row or column which look like the center line of a finder pattern,
+// i.e. dark, light, three dark, light, dark modules, with four light modules on either side.
+func (c *Code) finderLikePenalty(module func(int) bool) int {
+	pattern := []bool{true, false, true, true, true, false, true}
+	light := func(from, to int) bool {
+		for j := from; j < to; j++ {
+			if j >= 0 && j < c.size && module(j) {
+				return false
+			}
+		}
+		return true
+	}
+
+	penalty := 0
+	for start := 0; start+len(pattern) <= c.size; start++ {
+		matches := true
+		for k, dark := range pattern {
+			if module(start+k) != dark {
+				matches = false
+				break
+			}
+		}
+		if matches && (light(start-4, start) || light(start+len(pattern), start+len(pattern)+4)) {
+			penalty += 40
+		}
+	}
+	return penalty
+}
+
+// encodeData returns the data codewords of the data in the byte mode, padded to the capacity of the version.
+func encodeData(version int, data []byte) []byte {
+	var bits bitBuffer
+	bits.append(byteMode, 4)
+	bits.append(len(data), charCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := numDataCodewords(version) * 8
+	terminatorLength := capacity - len(bits)
+	if terminatorLength > 4 {
+		terminatorLength = 4
+	}
+	bits.append(0, terminatorLength)
+	bits.append(0, (8-len(bits)%8)%8)
+	for padByte := 0xec; len(bits) < capacity; padByte ^= 0xec ^ 0x11 {
+		bits.append(padByte, 8)
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i, b := range bits {
+		if b {
+			codewords[i>>3] |= 1 << (7 - (i & 7))
+		}
+	}
+	return codewords
+}
+
+// addErrorCorrectionAndInterleave splits the data codewords into blocks, appends the error correction codewords to
+// each block, and interleaves the codewords of all blocks.
+func addErrorCorrectionAndInterleave(version int, data []byte) []byte {
+	numBlocks := numErrorCorrectionBlocks[version]
+	eccLength := eccCodewordsPerBlock[version]
+	rawCodewords := numRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLength := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(eccLength)
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i < numBlocks; i++ {
+		dataLength := shortBlockLength - eccLength
+		if i >= numShortBlocks {
+			dataLength++
+		}
+		block := append([]byte{}, data[k:k+dataLength]...)
+		k += dataLength
+		ecc := reedSolomonRemainder(block, divisor)
+		if i < numShortBlocks {
+			block = append(block, 0) // a placeholder which is skipped while interleaving
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := 0; i <= shortBlockLength; i++ {
+		for j, block := range blocks {
+			if i != shortBlockLength-eccLength || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor returns the coefficients of the generator polynomial of the given degree, from the highest to
+// the lowest power, excluding the leading coefficient which is always one.
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords of the data.
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo the polynomial x^8 + x^4 + x^3 + x^2 + 1 of the standard.
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11d)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// formatBits returns the 15 format bits of the error correction level M and the mask, including their BCH code.
+func formatBits(mask int) int {
+	data := eccLevelMFormatBits<<3 | mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	return (data<<10 | remainder) ^ 0x5412
+}
+
+// versionBits returns the 18 version bits of the version, including their BCH code.
+func versionBits(version int) int {
+	remainder := version
+	for i := 0; i < 12; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1f25)
+	}
+	return version<<12 | remainder
+}
+
+// alignmentPatternPositions returns the ascending coordinates of the centers of the alignment patterns, which are
+// used for both rows and columns.
+func alignmentPatternPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, position := numAlign-1, version*4+17-7; i >= 1; i, position = i-1, position-step {
+		result[i] = position
+	}
+	return result
+}
+
+// numRawDataModules returns the number of modules which are not function modules, i.e. which hold the data and
+// error correction codewords, and possibly a few remainder bits.
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func numDataCodewords(version int) int {
+	return numRawDataModules(version)/8 - eccCodewordsPerBlock[version]*numErrorCorrectionBlocks[version]
+}
+
+// charCountBits returns the length of the character count of the byte mode.
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, bit(value, i))
+	}
+}
+
+func bit(value, i int) bool {
+	return (value>>i)&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func maxAbs(x, y int) int {
+	if abs(x) > abs(y) {
+		return abs(x)
+	}
+	return abs(y)
+}
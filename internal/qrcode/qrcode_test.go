@@ -0,0 +1,234 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// The data codewords of "HELLO WORLD" in version 1-M, and their error correction codewords.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	require.Equal(t,
+		[]byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23},
+		reedSolomonRemainder(data, reedSolomonDivisor(10)))
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	require.Equal(t, 0b101010000010010, formatBits(0))
+	require.Equal(t, 0b100000011001110, formatBits(5))
+	require.Equal(t, 0b000111110010010100, versionBits(7))
+	require.Equal(t, 0b001010010011010011, versionBits(10))
+}
+
+func TestVersionParameters(t *testing.T) {
+	wantDataCodewords := []int{16, 28, 44, 64, 86, 108, 124, 154, 182, 216}
+	wantAlignmentPatternPositions := [][]int{
+		nil, {6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34}, {6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+	}
+	for version := minVersion; version <= maxVersion; version++ {
+		require.Equal(t, wantDataCodewords[version-1], numDataCodewords(version), "version %d", version)
+		require.Equal(t, wantAlignmentPatternPositions[version-1], alignmentPatternPositions(version), "version %d", version)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		length      int
+		wantVersion int
+	}{
+		{length: 0, wantVersion: 1},
+		{length: 14, wantVersion: 1},
+		{length: 15, wantVersion: 2},
+		{length: 42, wantVersion: 3},
+		{length: 84, wantVersion: 5},
+		{length: 122, wantVersion: 7},
+		{length: 180, wantVersion: 9},
+		{length: 181, wantVersion: 10},
+		{length: 213, wantVersion: 10},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("%d bytes", test.length), func(t *testing.T) {
+			data := []byte(strings.Repeat("otpauth://totp/", 15)[:test.length])
+
+			code, err := Encode(data)
+			require.NoError(t, err)
+			require.Equal(t, test.wantVersion, code.version)
+			require.Equal(t, test.wantVersion*4+17, code.Size())
+
+			requireFinderPatterns(t, code)
+			require.Equal(t, data, decode(t, code))
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	code, err := Encode(bytes.Repeat([]byte("a"), 214))
+	require.ErrorIs(t, err, ErrDataTooLong)
+	require.EqualError(t, err, "data is too long for a QR code: 214 bytes")
+	require.Nil(t, code)
+}
+
+func TestSVG(t *testing.T) {
+	code, err := Encode([]byte("otpauth://totp/issuer.example.com:pinny?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"))
+	require.NoError(t, err)
+
+	svg := code.SVG()
+	dimension := code.Size() + 8
+	require.True(t, strings.HasPrefix(svg,
+		fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %[1]d %[1]d" shape-rendering="crispEdges">`+
+			`<rect width="%[1]d" height="%[1]d" fill="#fff"/><path d="M4,4h1v1h-1zM5,4h1v1h-1z`, dimension)), svg)
+	require.True(t, strings.HasSuffix(svg, `" fill="#000"/></svg>`), svg)
+
+	dark := 0
+	for y := 0; y < code.Size(); y++ {
+		for x := 0; x < code.Size(); x++ {
+			if code.Dark(x, y) {
+				dark++
+			}
+		}
+	}
+	require.Equal(t, dark, strings.Count(svg, "h1v1h-1z"))
+}
+
+func requireFinderPatterns(t *testing.T, code *Code) {
+	t.Helper()
+
+	size := code.Size()
+	for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+		for dy := -1; dy <= 7; dy++ {
+			for dx := -1; dx <= 7; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				ring := maxAbs(dx-3, dy-3)
+				require.Equal(t, ring != 2 && ring != 4, code.Dark(x, y), "module %d,%d", x, y)
+			}
+		}
+	}
+	require.True(t, code.Dark(8, size-8), "dark module")
+}
+
+// decode reads the data of a QR code in the way a reader would, without reusing the encoder.
+func decode(t *testing.T, code *Code) []byte {
+	t.Helper()
+
+	size := code.Size()
+
+	// Read both copies of the format bits.
+	var first, second int
+	for i, position := range [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}} {
+		if code.Dark(position[0], position[1]) {
+			first |= 1 << i
+		}
+	}
+	for i := 0; i < 8; i++ {
+		if code.Dark(size-1-i, 8) {
+			second |= 1 << i
+		}
+	}
+	for i := 8; i < 15; i++ {
+		if code.Dark(8, size-15+i) {
+			second |= 1 << i
+		}
+	}
+	require.Equal(t, first, second)
+	format := first ^ 0x5412
+	require.Equal(t, eccLevelMFormatBits, format>>13, "error correction level")
+	mask := (format >> 10) & 0x7
+
+	// Read the version bits.
+	if code.version >= 7 {
+		var version int
+		for i := 0; i < 18; i++ {
+			if code.Dark(size-11+i%3, i/3) {
+				version |= 1 << i
+			}
+		}
+		require.Equal(t, code.version, version>>12)
+	}
+
+	// Read the codewords in the zigzag order, skipping the function modules.
+	var bits bitBuffer
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < size; vertical++ {
+			y := vertical
+			if (right+1)&2 == 0 {
+				y = size - 1 - vertical
+			}
+			for x := right; x >= right-1; x-- {
+				if !code.isFunction[y][x] {
+					bits = append(bits, code.Dark(x, y) != masked(mask, x, y))
+				}
+			}
+		}
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i := range codewords {
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				codewords[i] |= 1 << (7 - j)
+			}
+		}
+	}
+
+	// De-interleave the blocks, of which the last ones may have one more data codeword, and check their error
+	// correction codewords.
+	numBlocks := numErrorCorrectionBlocks[code.version]
+	eccLength := eccCodewordsPerBlock[code.version]
+	numLongBlocks := numDataCodewords(code.version) % numBlocks
+	shortDataLength := numDataCodewords(code.version) / numBlocks
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortDataLength; i++ {
+		for j := range blocks {
+			if i < shortDataLength || j >= numBlocks-numLongBlocks {
+				blocks[j] = append(blocks[j], codewords[k])
+				k++
+			}
+		}
+	}
+	var data []byte
+	for i := 0; i < eccLength; i++ {
+		for j := range blocks {
+			blocks[j] = append(blocks[j], codewords[k])
+			k++
+		}
+	}
+	for _, block := range blocks {
+		dataLength := len(block) - eccLength
+		require.Equal(t, block[dataLength:], reedSolomonRemainder(block[:dataLength], reedSolomonDivisor(eccLength)))
+		data = append(data, block[:dataLength]...)
+	}
+
+	// Parse the byte mode segment.
+	readBits := func(offset, length int) int {
+		value := 0
+		for i := offset; i < offset+length; i++ {
+			value <<= 1
+			if data[i/8]&(1<<(7-i%8)) != 0 {
+				value |= 1
+			}
+		}
+		return value
+	}
+	require.Equal(t, byteMode, readBits(0, 4))
+	countLength := 8
+	if code.version >= 10 {
+		countLength = 16
+	}
+	count := readBits(4, countLength)
+	result := []byte{}
+	for i := 0; i < count; i++ {
+		result = append(result, byte(readBits(4+countLength+8*i, 8)))
+	}
+	return result
+}
@@ -0,0 +1,226 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redisstorage implements a crud.Backend which keeps the Supervisor's session storage in Redis instead
+// of in Kubernetes Secrets, for installations which have a high volume of logins.
+package redisstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"go.pinniped.dev/internal/crud"
+)
+
+const (
+	// These hash fields mirror the data keys of the Secrets which are created by crud.New.
+	dataField            = "pinniped-storage-data"
+	versionField         = "pinniped-storage-version"
+	resourceVersionField = "pinniped-storage-resource-version"
+
+	storageVersion = "1"
+
+	labelIndexKeyFormat = "pinniped-storage-%s-index-%s=%s"
+)
+
+// New returns a crud.Backend which stores each item as a Redis hash using the given client.
+//
+// Items are given a Redis TTL equal to their storage lifetime, so Redis removes them when they expire instead
+// of the Supervisor's garbage collector controller. Owner references are not supported by this backend.
+func New(client redis.UniversalClient) crud.Backend {
+	return &backend{client: client}
+}
+
+type backend struct {
+	client redis.UniversalClient
+}
+
+// New returns a crud.Storage for the given resource. The clock is not needed because Redis itself
+// keeps track of when each item expires.
+func (b *backend) New(resource string, _ func() time.Time, lifetime time.Duration) crud.Storage {
+	return &redisStorage{
+		client:        b.client,
+		resource:      resource,
+		groupResource: schema.GroupResource{Resource: resource},
+		lifetime:      lifetime,
+	}
+}
+
+type redisStorage struct {
+	client        redis.UniversalClient
+	resource      string
+	groupResource schema.GroupResource
+	lifetime      time.Duration
+}
+
+var _ crud.Storage = &redisStorage{}
+
+func (s *redisStorage) Create(ctx context.Context, signature string, data crud.JSON, additionalLabels map[string]string, _ []metav1.OwnerReference) (string, error) {
+	key := s.GetName(signature)
+
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode data for %s: %w", key, err)
+	}
+
+	const resourceVersion = "1"
+
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if exists != 0 {
+			return apierrors.NewAlreadyExists(s.groupResource, key)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, dataField, buf, versionField, storageVersion, resourceVersionField, resourceVersion)
+			s.maybeExpire(ctx, pipe, key)
+			for labelName, labelValue := range additionalLabels {
+				indexKey := s.labelIndexKey(labelName, labelValue)
+				pipe.SAdd(ctx, indexKey, key)
+				// All items of this resource type have the same lifetime, so the index outlives all of its members.
+				s.maybeExpire(ctx, pipe, indexKey)
+			}
+			return nil
+		})
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		err = apierrors.NewAlreadyExists(s.groupResource, key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s for signature %s: %w", s.resource, signature, err)
+	}
+
+	return resourceVersion, nil
+}
+
+func (s *redisStorage) Get(ctx context.Context, signature string, data crud.JSON) (string, error) {
+	key := s.GetName(signature)
+
+	fields, err := s.client.HGetAll(ctx, key).Result()
+	if err == nil && len(fields) == 0 {
+		err = apierrors.NewNotFound(s.groupResource, key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s for signature %s: %w", s.resource, signature, err)
+	}
+
+	if fields[versionField] != storageVersion {
+		return "", fmt.Errorf("error during get for signature %s: %w", signature, crud.ErrSecretVersionMismatch)
+	}
+	if err := json.Unmarshal([]byte(fields[dataField]), data); err != nil {
+		return "", fmt.Errorf("error during get for signature %s: failed to decode %s: %w", signature, s.resource, err)
+	}
+
+	return fields[resourceVersionField], nil
+}
+
+// Update takes a resourceVersion because it assumes Get has been recently called to obtain the latest resource version.
+// This is to ensure that concurrent edits are treated as conflict errors (only one will win).
+func (s *redisStorage) Update(ctx context.Context, signature, resourceVersion string, data crud.JSON) (string, error) {
+	key := s.GetName(signature)
+
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode data for %s: %w", key, err)
+	}
+
+	var newResourceVersion string
+
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		currentResourceVersion, err := tx.HGet(ctx, key, resourceVersionField).Result()
+		if errors.Is(err, redis.Nil) {
+			return apierrors.NewNotFound(s.groupResource, key)
+		}
+		if err != nil {
+			return err
+		}
+		if currentResourceVersion != resourceVersion {
+			return s.newConflictError(key)
+		}
+
+		rv, err := strconv.ParseUint(currentResourceVersion, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid resource version %q: %w", currentResourceVersion, err)
+		}
+		newResourceVersion = strconv.FormatUint(rv+1, 10)
+
+		// Setting fields of a hash does not change its TTL.
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, dataField, buf, resourceVersionField, newResourceVersion)
+			return nil
+		})
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		err = s.newConflictError(key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s for signature %s at resource version %s: %w", s.resource, signature, resourceVersion, err)
+	}
+
+	return newResourceVersion, nil
+}
+
+func (s *redisStorage) Delete(ctx context.Context, signature string) error {
+	key := s.GetName(signature)
+
+	deleted, err := s.client.Del(ctx, key).Result()
+	if err == nil && deleted == 0 {
+		err = apierrors.NewNotFound(s.groupResource, key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s for signature %s: %w", s.resource, signature, err)
+	}
+	return nil
+}
+
+func (s *redisStorage) DeleteByLabel(ctx context.Context, labelName string, labelValue string) error {
+	indexKey := s.labelIndexKey(labelName, labelValue)
+
+	keys, err := s.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return fmt.Errorf(`failed to list items for resource "%s" matching label "%s=%s": %w`, s.resource, labelName, labelValue, err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf(`failed to delete items for resource "%s" matching label "%s=%s": none found`, s.resource, labelName, labelValue)
+	}
+
+	// Some of the keys may have already been deleted or expired, which is fine.
+	if err := s.client.Del(ctx, append(keys, indexKey)...).Err(); err != nil {
+		return fmt.Errorf(`failed to delete items for resource "%s" matching label "%s=%s": %w`, s.resource, labelName, labelValue, err)
+	}
+	return nil
+}
+
+// GetName returns the Redis key of the item with the given signature, which is the same as the name that
+// the item would have if it were stored in a Secret.
+func (s *redisStorage) GetName(signature string) string {
+	return crud.NameForSignature(s.resource, signature)
+}
+
+func (s *redisStorage) labelIndexKey(labelName, labelValue string) string {
+	return fmt.Sprintf(labelIndexKeyFormat, s.resource, labelName, labelValue)
+}
+
+func (s *redisStorage) maybeExpire(ctx context.Context, pipe redis.Pipeliner, key string) {
+	if s.lifetime > 0 {
+		pipe.Expire(ctx, key, s.lifetime)
+	}
+}
+
+func (s *redisStorage) newConflictError(key string) error {
+	return apierrors.NewConflict(s.groupResource, key, errors.New("the object has been modified; please apply your changes to the latest version and try again"))
+}
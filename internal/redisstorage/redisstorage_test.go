@@ -0,0 +1,144 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package redisstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"go.pinniped.dev/internal/crud"
+)
+
+type testJSON struct {
+	Data string
+}
+
+func newTestStorage(t *testing.T, resource string, lifetime time.Duration) (crud.Storage, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { require.NoError(t, client.Close()) })
+
+	return New(client).New(resource, nil, lifetime), server
+}
+
+func TestCreateGetUpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	storage, server := newTestStorage(t, "access-tokens", 10*time.Minute)
+
+	const signature = "abcd-1"
+	key := storage.GetName(signature)
+	require.Equal(t, "pinniped-storage-access-tokens-ng3r36y", key)
+
+	rv, err := storage.Create(ctx, signature, &testJSON{Data: "create-data"}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "1", rv)
+	require.Equal(t, 10*time.Minute, server.TTL(key))
+
+	_, err = storage.Create(ctx, signature, &testJSON{Data: "create-data-again"}, nil, nil)
+	require.True(t, apierrors.IsAlreadyExists(err), "expected already exists error but got: %v", err)
+
+	var got testJSON
+	rv, err = storage.Get(ctx, signature, &got)
+	require.NoError(t, err)
+	require.Equal(t, "1", rv)
+	require.Equal(t, testJSON{Data: "create-data"}, got)
+
+	server.FastForward(time.Minute)
+
+	rv, err = storage.Update(ctx, signature, rv, &testJSON{Data: "update-data"})
+	require.NoError(t, err)
+	require.Equal(t, "2", rv)
+	require.Equal(t, 9*time.Minute, server.TTL(key), "update should not extend the lifetime")
+
+	_, err = storage.Update(ctx, signature, "1", &testJSON{Data: "stale-update-data"})
+	require.True(t, apierrors.IsConflict(err), "expected conflict error but got: %v", err)
+
+	rv, err = storage.Get(ctx, signature, &got)
+	require.NoError(t, err)
+	require.Equal(t, "2", rv)
+	require.Equal(t, testJSON{Data: "update-data"}, got)
+
+	require.NoError(t, storage.Delete(ctx, signature))
+
+	_, err = storage.Get(ctx, signature, &got)
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+	require.EqualError(t, err, `failed to get access-tokens for signature abcd-1: access-tokens "pinniped-storage-access-tokens-ng3r36y" not found`)
+
+	err = storage.Delete(ctx, signature)
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+
+	_, err = storage.Update(ctx, signature, "2", &testJSON{Data: "update-data"})
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+}
+
+func TestExpiration(t *testing.T) {
+	ctx := context.Background()
+	storage, server := newTestStorage(t, "pkce", time.Minute)
+
+	_, err := storage.Create(ctx, "sig", &testJSON{Data: "data"}, map[string]string{"label": "value"}, nil)
+	require.NoError(t, err)
+
+	server.FastForward(time.Minute)
+
+	_, err = storage.Get(ctx, "sig", &testJSON{})
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+	require.Empty(t, server.Keys(), "the label index should expire too")
+}
+
+func TestInfiniteLifetime(t *testing.T) {
+	ctx := context.Background()
+	storage, server := newTestStorage(t, "authcode", 0)
+
+	_, err := storage.Create(ctx, "sig", &testJSON{Data: "data"}, nil, nil)
+	require.NoError(t, err)
+	require.Zero(t, server.TTL(storage.GetName("sig")))
+}
+
+func TestDeleteByLabel(t *testing.T) {
+	ctx := context.Background()
+	storage, server := newTestStorage(t, "refresh-tokens", time.Hour)
+
+	_, err := storage.Create(ctx, "sig-1", &testJSON{Data: "data-1"}, map[string]string{"request-id": "request-1"}, nil)
+	require.NoError(t, err)
+	_, err = storage.Create(ctx, "sig-2", &testJSON{Data: "data-2"}, map[string]string{"request-id": "request-1"}, nil)
+	require.NoError(t, err)
+	_, err = storage.Create(ctx, "sig-3", &testJSON{Data: "data-3"}, map[string]string{"request-id": "request-2"}, nil)
+	require.NoError(t, err)
+
+	// Deleting an item directly leaves it in the label index, which is fine.
+	require.NoError(t, storage.Delete(ctx, "sig-2"))
+
+	require.NoError(t, storage.DeleteByLabel(ctx, "request-id", "request-1"))
+
+	_, err = storage.Get(ctx, "sig-1", &testJSON{})
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+	_, err = storage.Get(ctx, "sig-3", &testJSON{})
+	require.NoError(t, err)
+
+	err = storage.DeleteByLabel(ctx, "request-id", "request-1")
+	require.EqualError(t, err, `failed to delete items for resource "refresh-tokens" matching label "request-id=request-1": none found`)
+
+	require.ElementsMatch(t, []string{
+		storage.GetName("sig-3"),
+		"pinniped-storage-refresh-tokens-index-request-id=request-2",
+	}, server.Keys())
+}
+
+func TestWrongStorageVersion(t *testing.T) {
+	ctx := context.Background()
+	storage, server := newTestStorage(t, "authcode", time.Hour)
+
+	server.HSet(storage.GetName("sig"), dataField, `{"Data":"data"}`, versionField, "2", resourceVersionField, "1")
+
+	_, err := storage.Get(ctx, "sig", &testJSON{})
+	require.EqualError(t, err, "error during get for signature sig: secret storage data has incorrect version")
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package clientsecretrequest provides REST functionality for the CredentialRequest resource.
@@ -26,6 +26,7 @@ import (
 	"k8s.io/utils/trace"
 
 	clientsecretapi "go.pinniped.dev/generated/latest/apis/supervisor/clientsecret"
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
 	configv1alpha1clientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
 	"go.pinniped.dev/internal/oidcclientsecretstorage"
 )
@@ -34,6 +35,10 @@ import (
 // This value is expected to be increased over time to match CPU improvements.
 const Cost = 12
 
+// defaultMaxSecrets is how many client secrets an OIDCClient may have at the same time, unless its
+// spec.clientSecretPolicy.maxSecrets is lower.
+const defaultMaxSecrets = 5
+
 type byteHasher func(password []byte, cost int) ([]byte, error)
 type timeNowFunc func() metav1.Time
 
@@ -143,13 +148,15 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 
 	// Using the OIDCClient's UID, check to see if the storage Secret for its client secrets already exists.
 	// Note that when it does not exist, this Get() function will not return an error, and will return nil rv and hashes.
-	rv, hashes, err := r.secretStorage.Get(ctx, oidcClient.UID)
+	rv, hashes, metadata, err := r.secretStorage.Get(ctx, oidcClient.UID)
 	if err != nil {
 		traceFailureWithError(t, "secretStorage.Get", err)
 		return nil, apierrors.NewInternalError(fmt.Errorf("getting secret for client %q failed", req.Name))
 	}
 	t.Step("secretStorage.Get")
 
+	now := r.timeNowFunc()
+
 	// If requested, generate a new client secret and add it to the list.
 	var secret string
 	if req.Spec.GenerateNewSecret {
@@ -168,6 +175,15 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		t.Step("bcrypt.GenerateFromPassword")
 
 		hashes = append([]string{string(hash)}, hashes...)
+		if metadata == nil {
+			metadata = make(map[string]oidcclientsecretstorage.SecretMetadata)
+		}
+		secretMetadata := oidcclientsecretstorage.SecretMetadata{CreationTimestamp: &now}
+		if req.Spec.ExpiresIn != nil {
+			expiresAt := metav1.NewTime(now.Add(req.Spec.ExpiresIn.Duration))
+			secretMetadata.ExpirationTimestamp = &expiresAt
+		}
+		metadata[string(hash)] = secretMetadata
 	}
 
 	// If requested, remove all client secrets except for the most recent one.
@@ -176,17 +192,31 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		hashes = []string{hashes[0]}
 	}
 
+	// If requested, remove the specific client secrets identified by their IDs.
+	if len(req.Spec.RevokeSecrets) > 0 {
+		var errs field.ErrorList
+		hashes, errs = revokeSecrets(hashes, req.Spec.RevokeSecrets)
+		if len(errs) > 0 {
+			traceValidationFailure(t, errs.ToAggregate().Error())
+			return nil, apierrors.NewInvalid(kindFromContext(ctx), req.Name, errs)
+		}
+		needsRevoke = true
+	}
+
 	// If anything was requested to change...
 	if req.Spec.GenerateNewSecret || needsRevoke {
+		// Expired client secrets can never be used again, so there is no reason to keep them.
+		hashes = removeExpiredSecrets(hashes, metadata, oidcClient.Spec.ClientSecretPolicy, now)
+
 		// Each bcrypt comparison is expensive, and we do not want a large list to cause wasted CPU.
-		if len(hashes) > 5 {
+		if len(hashes) > maxSecrets(oidcClient) {
 			msg := fmt.Sprintf("OIDCClient %s has too many secrets, spec.revokeOldSecrets must be true", oidcClient.Name)
 			traceFailure(t, "secretStorage.Set", msg)
 			return nil, apierrors.NewBadRequest(msg)
 		}
 
 		// Create or update the storage Secret for client secrets.
-		if err := r.secretStorage.Set(ctx, rv, oidcClient.Name, oidcClient.UID, hashes); err != nil {
+		if err := r.secretStorage.Set(ctx, rv, oidcClient.Name, oidcClient.UID, hashes, metadata); err != nil {
 			if apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err) {
 				traceFailureWithError(t, "secretStorage.Set", err)
 				return nil, apierrors.NewConflict(qualifiedResourceFromContext(ctx), req.Name,
@@ -199,6 +229,18 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		t.Step("secretStorage.Set")
 	}
 
+	// Describe each of the remaining secrets without revealing them, so they can be revoked individually later.
+	var secrets []clientsecretapi.OIDCClientSecret
+	for _, hash := range hashes {
+		m := metadata[hash]
+		secrets = append(secrets, clientsecretapi.OIDCClientSecret{
+			ID:                  oidcclientsecretstorage.SecretID(hash),
+			CreationTimestamp:   m.CreationTimestamp,
+			LastUsedTimestamp:   m.LastUsedTimestamp,
+			ExpirationTimestamp: m.ExpiresAt(oidcClient.Spec.ClientSecretPolicy),
+		})
+	}
+
 	// Return the new secret in plaintext, if one was generated, along with the total number of secrets.
 	return &clientsecretapi.OIDCClientSecretRequest{
 		ObjectMeta: metav1.ObjectMeta{
@@ -209,10 +251,13 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		Spec: clientsecretapi.OIDCClientSecretRequestSpec{
 			GenerateNewSecret: req.Spec.GenerateNewSecret,
 			RevokeOldSecrets:  req.Spec.RevokeOldSecrets,
+			RevokeSecrets:     req.Spec.RevokeSecrets,
+			ExpiresIn:         req.Spec.ExpiresIn,
 		},
 		Status: clientsecretapi.OIDCClientSecretRequestStatus{
 			GeneratedSecret:    secret,
 			TotalClientSecrets: len(hashes),
+			Secrets:            secrets,
 		},
 	}, nil
 }
@@ -270,6 +315,21 @@ func (r *REST) validateRequest(
 		return nil, apierrors.NewInvalid(kindFromContext(ctx), clientSecretRequest.Name, errs)
 	}
 
+	if expiresIn := clientSecretRequest.Spec.ExpiresIn; expiresIn != nil {
+		var errs field.ErrorList
+		fieldPath := field.NewPath("spec", "expiresIn")
+		switch {
+		case !clientSecretRequest.Spec.GenerateNewSecret:
+			errs = append(errs, field.Invalid(fieldPath, expiresIn.Duration.String(), "may only be set when spec.generateNewSecret is true"))
+		case expiresIn.Duration <= 0:
+			errs = append(errs, field.Invalid(fieldPath, expiresIn.Duration.String(), "must be greater than zero"))
+		}
+		if len(errs) > 0 {
+			traceValidationFailure(tracer, errs.ToAggregate().Error())
+			return nil, apierrors.NewInvalid(kindFromContext(ctx), clientSecretRequest.Name, errs)
+		}
+	}
+
 	// just a sanity check, not sure how to honor a dry run on a virtual API
 	if options != nil {
 		if len(options.DryRun) != 0 {
@@ -315,6 +375,59 @@ func generateSecret(rand io.Reader) (string, error) {
 	return hex.EncodeToString(buf[:]), nil
 }
 
+// revokeSecrets returns the hashes without those identified by the given IDs, or an error for each ID which does not
+// identify any of the hashes.
+func revokeSecrets(hashes []string, ids []string) ([]string, field.ErrorList) {
+	revoke := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		revoke[id] = true
+	}
+
+	remaining := make([]string, 0, len(hashes))
+	found := make(map[string]bool, len(ids))
+	for _, hash := range hashes {
+		id := oidcclientsecretstorage.SecretID(hash)
+		if revoke[id] {
+			found[id] = true
+			continue
+		}
+		remaining = append(remaining, hash)
+	}
+
+	var errs field.ErrorList
+	for i, id := range ids {
+		if !found[id] {
+			errs = append(errs, field.NotFound(field.NewPath("spec", "revokeSecrets").Index(i), id))
+		}
+	}
+	return remaining, errs
+}
+
+// removeExpiredSecrets returns the hashes without those of the client secrets which have expired.
+func removeExpiredSecrets(
+	hashes []string,
+	metadata map[string]oidcclientsecretstorage.SecretMetadata,
+	policy *configv1alpha1.OIDCClientSecretPolicy,
+	now metav1.Time,
+) []string {
+	remaining := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		if expiresAt := metadata[hash].ExpiresAt(policy); expiresAt != nil && !now.Before(expiresAt) {
+			continue
+		}
+		remaining = append(remaining, hash)
+	}
+	return remaining
+}
+
+// maxSecrets returns how many client secrets the OIDCClient may have at the same time.
+func maxSecrets(oidcClient *configv1alpha1.OIDCClient) int {
+	if policy := oidcClient.Spec.ClientSecretPolicy; policy != nil && policy.MaxSecrets > 0 && policy.MaxSecrets < defaultMaxSecrets {
+		return int(policy.MaxSecrets)
+	}
+	return defaultMaxSecrets
+}
+
 func name(obj runtime.Object) string {
 	accessor, err := meta.Accessor(obj)
 	if err != nil {
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package clientsecretrequest
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -108,6 +109,11 @@ func TestCreate(t *testing.T) {
 	fakeNow := metav1.Now()
 	fakeTimeNowFunc := func() metav1.Time { return fakeNow }
 
+	// Timestamps read from the storage Secret are in the local time zone.
+	createdTime := metav1.NewTime(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC).Local())
+	lastUsedTime := metav1.NewTime(time.Date(2023, 2, 3, 4, 5, 6, 0, time.UTC).Local())
+	expiresTime := metav1.NewTime(time.Date(2023, 1, 3, 3, 4, 5, 0, time.UTC).Local())
+
 	tests := []struct {
 		name              string
 		args              args
@@ -695,6 +701,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: oidcclientsecretstorage.SecretID(fakeBcryptRandomBytes), CreationTimestamp: &fakeNow},
+					},
 				},
 			},
 			wantErrStatus: nil,
@@ -740,6 +749,7 @@ func TestCreate(t *testing.T) {
 							"hashed-password-1",
 							"hashed-password-2",
 						},
+						nil,
 					),
 				)
 			},
@@ -771,6 +781,11 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 3,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: oidcclientsecretstorage.SecretID(fakeBcryptRandomBytes), CreationTimestamp: &fakeNow},
+						{ID: oidcclientsecretstorage.SecretID("hashed-password-1")},
+						{ID: oidcclientsecretstorage.SecretID("hashed-password-2")},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -809,6 +824,7 @@ func TestCreate(t *testing.T) {
 							"hashed-password-1",
 							"hashed-password-2",
 						},
+						nil,
 					))
 			},
 			seedOIDCClients: []*v1alpha1.OIDCClient{{
@@ -837,6 +853,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: oidcclientsecretstorage.SecretID(fakeBcryptRandomBytes), CreationTimestamp: &fakeNow},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -875,6 +894,7 @@ func TestCreate(t *testing.T) {
 							"hashed-password-1",
 							"hashed-password-2",
 						},
+						nil,
 					))
 			},
 			seedOIDCClients: []*v1alpha1.OIDCClient{{
@@ -903,6 +923,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    "",
 					TotalClientSecrets: 1,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: oidcclientsecretstorage.SecretID("hashed-password-1")},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -942,6 +965,7 @@ func TestCreate(t *testing.T) {
 							"hashed-password-4",
 							"hashed-password-5",
 						},
+						nil,
 					))
 			},
 			seedOIDCClients: []*v1alpha1.OIDCClient{{
@@ -1008,6 +1032,7 @@ func TestCreate(t *testing.T) {
 							"hashed-password-5",
 							"hashed-password-6",
 						},
+						nil,
 					))
 			},
 			seedOIDCClients: []*v1alpha1.OIDCClient{{
@@ -1317,6 +1342,7 @@ func TestCreate(t *testing.T) {
 							"hashed-password-1",
 							"hashed-password-2",
 						},
+						nil,
 					))
 			},
 			wantHashes: &wantHashes{
@@ -1339,6 +1365,363 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    "",
 					TotalClientSecrets: 2,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: oidcclientsecretstorage.SecretID("hashed-password-1")},
+						{ID: oidcclientsecretstorage.SecretID("hashed-password-2")},
+					},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`END`,
+			},
+		},
+		{
+			name: "happy path: list the existing secrets and their metadata without changing them",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.Set(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]string{
+							"hashed-password-1",
+							"hashed-password-2",
+						},
+						map[string]oidcclientsecretstorage.SecretMetadata{
+							"hashed-password-1": {CreationTimestamp: &createdTime, LastUsedTimestamp: &lastUsedTime},
+						},
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					"hashed-password-1",
+					"hashed-password-2",
+				},
+			},
+			want: &clientsecretapi.OIDCClientSecretRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "client.oauth.pinniped.dev-some-client",
+					Namespace:         namespace,
+					CreationTimestamp: fakeNow,
+				},
+				Status: clientsecretapi.OIDCClientSecretRequestStatus{
+					TotalClientSecrets: 2,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: "a73750076dfb27a7", CreationTimestamp: &createdTime, LastUsedTimestamp: &lastUsedTime},
+						{ID: oidcclientsecretstorage.SecretID("hashed-password-2")},
+					},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`END`,
+			},
+		},
+		{
+			name: "happy path: revoke specific secrets by their IDs",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						RevokeSecrets: []string{oidcclientsecretstorage.SecretID("hashed-password-3"), oidcclientsecretstorage.SecretID("hashed-password-1")},
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.Set(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]string{
+							"hashed-password-1",
+							"hashed-password-2",
+							"hashed-password-3",
+						},
+						map[string]oidcclientsecretstorage.SecretMetadata{
+							"hashed-password-2": {CreationTimestamp: &createdTime},
+						},
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					"hashed-password-2",
+				},
+			},
+			want: &clientsecretapi.OIDCClientSecretRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "client.oauth.pinniped.dev-some-client",
+					Namespace:         namespace,
+					CreationTimestamp: fakeNow,
+				},
+				Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+					RevokeSecrets: []string{oidcclientsecretstorage.SecretID("hashed-password-3"), oidcclientsecretstorage.SecretID("hashed-password-1")},
+				},
+				Status: clientsecretapi.OIDCClientSecretRequestStatus{
+					TotalClientSecrets: 1,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: oidcclientsecretstorage.SecretID("hashed-password-2"), CreationTimestamp: &createdTime},
+					},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`secretStorage.Set`,
+				`END`,
+			},
+		},
+		{
+			name: "revoking secrets with unknown IDs is invalid",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						RevokeSecrets: []string{oidcclientsecretstorage.SecretID("hashed-password-1"), "some-unknown-id"},
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.Set(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]string{
+							"hashed-password-1",
+							"hashed-password-2",
+						},
+						nil,
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					"hashed-password-1",
+					"hashed-password-2",
+				},
+			},
+			wantErrStatus: &metav1.Status{
+				Status: metav1.StatusFailure,
+				Message: `OIDCClientSecretRequest.clientsecret.supervisor.pinniped.dev "client.oauth.pinniped.dev-some-client" ` +
+					`is invalid: spec.revokeSecrets[1]: Not found: "some-unknown-id"`,
+				Reason: metav1.StatusReasonInvalid,
+				Code:   http.StatusUnprocessableEntity,
+				Details: &metav1.StatusDetails{
+					Group: "clientsecret.supervisor.pinniped.dev",
+					Kind:  "OIDCClientSecretRequest",
+					Name:  "client.oauth.pinniped.dev-some-client",
+					Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldValueNotFound,
+						Message: `Not found: "some-unknown-id"`,
+						Field:   "spec.revokeSecrets[1]",
+					}},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`failureType:request validation,msg:spec.revokeSecrets[1]: Not found: "some-unknown-id"`,
+				`END`,
+			},
+		},
+		{
+			name: "happy path: list the existing secrets with when they expire",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+				Spec: v1alpha1.OIDCClientSpec{
+					ClientSecretPolicy: &v1alpha1.OIDCClientSecretPolicy{MaxAge: &metav1.Duration{Duration: 90 * 24 * time.Hour}},
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.Set(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]string{
+							"hashed-password-1",
+							"hashed-password-2",
+							"hashed-password-3",
+						},
+						map[string]oidcclientsecretstorage.SecretMetadata{
+							"hashed-password-1": {CreationTimestamp: &createdTime},
+							"hashed-password-2": {CreationTimestamp: &createdTime, ExpirationTimestamp: &expiresTime},
+						},
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					"hashed-password-1",
+					"hashed-password-2",
+					"hashed-password-3",
+				},
+			},
+			want: &clientsecretapi.OIDCClientSecretRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "client.oauth.pinniped.dev-some-client",
+					Namespace:         namespace,
+					CreationTimestamp: fakeNow,
+				},
+				Status: clientsecretapi.OIDCClientSecretRequestStatus{
+					TotalClientSecrets: 3,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{
+							ID:                  oidcclientsecretstorage.SecretID("hashed-password-1"),
+							CreationTimestamp:   &createdTime,
+							ExpirationTimestamp: &metav1.Time{Time: createdTime.Add(90 * 24 * time.Hour)},
+						},
+						{
+							ID:                  oidcclientsecretstorage.SecretID("hashed-password-2"),
+							CreationTimestamp:   &createdTime,
+							ExpirationTimestamp: &expiresTime,
+						},
+						{ID: oidcclientsecretstorage.SecretID("hashed-password-3")},
+					},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`END`,
+			},
+		},
+		{
+			name: "happy path: generate a new secret which expires, and remove the expired secrets",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						GenerateNewSecret: true,
+						ExpiresIn:         &metav1.Duration{Duration: 30 * 24 * time.Hour},
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+				Spec: v1alpha1.OIDCClientSpec{
+					ClientSecretPolicy: &v1alpha1.OIDCClientSecretPolicy{MaxAge: &metav1.Duration{Duration: 90 * 24 * time.Hour}},
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.Set(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]string{
+							"hashed-password-1",
+							"hashed-password-2",
+						},
+						map[string]oidcclientsecretstorage.SecretMetadata{
+							// Expired because it is older than the max age.
+							"hashed-password-1": {CreationTimestamp: &createdTime},
+						},
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					fakeBcryptRandomBytes,
+					"hashed-password-2",
+				},
+			},
+			want: &clientsecretapi.OIDCClientSecretRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "client.oauth.pinniped.dev-some-client",
+					Namespace:         namespace,
+					CreationTimestamp: fakeNow,
+				},
+				Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+					GenerateNewSecret: true,
+					ExpiresIn:         &metav1.Duration{Duration: 30 * 24 * time.Hour},
+				},
+				Status: clientsecretapi.OIDCClientSecretRequestStatus{
+					GeneratedSecret:    fakeHexEncodedRandomBytes,
+					TotalClientSecrets: 2,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{
+							ID:                  oidcclientsecretstorage.SecretID(fakeBcryptRandomBytes),
+							CreationTimestamp:   &fakeNow,
+							ExpirationTimestamp: &metav1.Time{Time: fakeNow.Add(30 * 24 * time.Hour)},
+						},
+						{ID: oidcclientsecretstorage.SecretID("hashed-password-2")},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -1346,6 +1729,143 @@ func TestCreate(t *testing.T) {
 				`"validateRequest"`,
 				`oidcClientsClient.Get`,
 				`secretStorage.Get`,
+				`generateSecret`,
+				`bcrypt.GenerateFromPassword`,
+				`secretStorage.Set`,
+				`END`,
+			},
+		},
+		{
+			name: "generating a new secret fails when the client would have more secrets than its policy allows, secret is not updated",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						GenerateNewSecret: true,
+					},
+				},
+			},
+			seedOIDCClients: []*v1alpha1.OIDCClient{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "client.oauth.pinniped.dev-some-client",
+					Namespace: namespace,
+					UID:       "12345",
+				},
+				Spec: v1alpha1.OIDCClientSpec{
+					ClientSecretPolicy: &v1alpha1.OIDCClientSecretPolicy{MaxSecrets: 2},
+				},
+			}},
+			seedHashes: func(storage *oidcclientsecretstorage.OIDCClientSecretStorage) {
+				require.NoError(t,
+					storage.Set(
+						context.Background(),
+						"",
+						"client.oauth.pinniped.dev-some-client",
+						"12345",
+						[]string{
+							"hashed-password-1",
+							"hashed-password-2",
+						},
+						nil,
+					))
+			},
+			wantHashes: &wantHashes{
+				UID: "12345",
+				hashes: []string{
+					"hashed-password-1",
+					"hashed-password-2",
+				},
+			},
+			wantErrStatus: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: `OIDCClient client.oauth.pinniped.dev-some-client has too many secrets, spec.revokeOldSecrets must be true`,
+				Reason:  metav1.StatusReasonBadRequest,
+				Code:    http.StatusBadRequest,
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`"validateRequest"`,
+				`oidcClientsClient.Get`,
+				`secretStorage.Get`,
+				`generateSecret`,
+				`bcrypt.GenerateFromPassword`,
+				`failureType:secretStorage.Set,msg:OIDCClient client.oauth.pinniped.dev-some-client has too many secrets, spec.revokeOldSecrets must be true`,
+				`END`,
+			},
+		},
+		{
+			name: "create validation: expiresIn may only be set when generating a new secret",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						ExpiresIn: &metav1.Duration{Duration: time.Hour},
+					},
+				},
+			},
+			wantErrStatus: &metav1.Status{
+				Status: metav1.StatusFailure,
+				Message: `OIDCClientSecretRequest.clientsecret.supervisor.pinniped.dev "client.oauth.pinniped.dev-some-client" ` +
+					`is invalid: spec.expiresIn: Invalid value: "1h0m0s": may only be set when spec.generateNewSecret is true`,
+				Reason: metav1.StatusReasonInvalid,
+				Code:   http.StatusUnprocessableEntity,
+				Details: &metav1.StatusDetails{
+					Group: "clientsecret.supervisor.pinniped.dev",
+					Kind:  "OIDCClientSecretRequest",
+					Name:  "client.oauth.pinniped.dev-some-client",
+					Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldValueInvalid,
+						Message: `Invalid value: "1h0m0s": may only be set when spec.generateNewSecret is true`,
+						Field:   "spec.expiresIn",
+					}},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`failureType:request validation,msg:spec.expiresIn: Invalid value: "1h0m0s": may only be set when spec.generateNewSecret is true`,
+				`END`,
+			},
+		},
+		{
+			name: "create validation: expiresIn must be positive",
+			args: args{
+				ctx: namespacedContext,
+				obj: &clientsecretapi.OIDCClientSecretRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "client.oauth.pinniped.dev-some-client",
+					},
+					Spec: clientsecretapi.OIDCClientSecretRequestSpec{
+						GenerateNewSecret: true,
+						ExpiresIn:         &metav1.Duration{Duration: -time.Hour},
+					},
+				},
+			},
+			wantErrStatus: &metav1.Status{
+				Status: metav1.StatusFailure,
+				Message: `OIDCClientSecretRequest.clientsecret.supervisor.pinniped.dev "client.oauth.pinniped.dev-some-client" ` +
+					`is invalid: spec.expiresIn: Invalid value: "-1h0m0s": must be greater than zero`,
+				Reason: metav1.StatusReasonInvalid,
+				Code:   http.StatusUnprocessableEntity,
+				Details: &metav1.StatusDetails{
+					Group: "clientsecret.supervisor.pinniped.dev",
+					Kind:  "OIDCClientSecretRequest",
+					Name:  "client.oauth.pinniped.dev-some-client",
+					Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldValueInvalid,
+						Message: `Invalid value: "-1h0m0s": must be greater than zero`,
+						Field:   "spec.expiresIn",
+					}},
+				},
+			},
+			wantLogLines: []string{
+				`"create"`,
+				`failureType:request validation,msg:spec.expiresIn: Invalid value: "-1h0m0s": must be greater than zero`,
 				`END`,
 			},
 		},
@@ -1380,6 +1900,7 @@ func TestCreate(t *testing.T) {
 						[]string{
 							"hashed-password-1",
 						},
+						nil,
 					))
 			},
 			wantHashes: &wantHashes{
@@ -1401,6 +1922,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: oidcclientsecretstorage.SecretID(fakeBcryptRandomBytes), CreationTimestamp: &fakeNow},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -1449,6 +1973,7 @@ func TestCreate(t *testing.T) {
 							"hashed-password-4",
 							"hashed-password-5",
 						},
+						nil,
 					))
 			},
 			wantHashes: &wantHashes{
@@ -1470,6 +1995,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: oidcclientsecretstorage.SecretID(fakeBcryptRandomBytes), CreationTimestamp: &fakeNow},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -1519,6 +2047,7 @@ func TestCreate(t *testing.T) {
 							"hashed-password-5",
 							"hashed-password-6",
 						},
+						nil,
 					))
 			},
 			wantHashes: &wantHashes{
@@ -1540,6 +2069,9 @@ func TestCreate(t *testing.T) {
 				Status: clientsecretapi.OIDCClientSecretRequestStatus{
 					GeneratedSecret:    fakeHexEncodedRandomBytes,
 					TotalClientSecrets: 1,
+					Secrets: []clientsecretapi.OIDCClientSecret{
+						{ID: oidcclientsecretstorage.SecretID(fakeBcryptRandomBytes), CreationTimestamp: &fakeNow},
+					},
 				},
 			},
 			wantLogLines: []string{
@@ -1635,7 +2167,7 @@ func TestCreate(t *testing.T) {
 				storeSecret, err := kubeClient.Tracker().Get(secretGVR, namespace, secretStoreName)
 				require.NoError(t, err)
 				require.IsType(t, &corev1.Secret{}, storeSecret)
-				secretHashes, err := oidcclientsecretstorage.ReadFromSecret(storeSecret.(*corev1.Secret))
+				secretHashes, _, err := oidcclientsecretstorage.ReadFromSecret(storeSecret.(*corev1.Secret))
 				require.NoError(t, err)
 				require.Equal(t, tt.wantHashes.hashes, secretHashes)
 			} else {
@@ -0,0 +1,202 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialrequest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	authenticationv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+	"go.pinniped.dev/internal/plog"
+)
+
+// Reasons of the Events which are recorded for notable outcomes of TokenCredentialRequests.
+const (
+	ReasonRepeatedAuthenticationFailures = "RepeatedAuthenticationFailures"
+	ReasonPrivilegedCredentialIssued     = "PrivilegedCredentialIssued"
+	ReasonAuthenticatorUnavailable       = "AuthenticatorUnavailable"
+)
+
+const (
+	// failureThreshold is how many failed authentications of the same user within failureWindow are reported by an
+	// Event. The count starts over after each Event, so a sustained attack is reported repeatedly.
+	failureThreshold = 5
+	failureWindow    = 5 * time.Minute
+
+	// maxTrackedUsers bounds the memory which is used to count failures, e.g. during an attack with many usernames.
+	maxTrackedUsers = 10000
+
+	// maxEventNoteLength is the maximum length of the note of an Event which the Kubernetes API server accepts.
+	maxEventNoteLength = 1024
+)
+
+// outcomeRecorder records Events and warning logs for the notable outcomes of TokenCredentialRequests, so that they
+// can be watched in a single stream of Events instead of in the logs of every pod. The Events are regarding the
+// authenticator which was named by the request. Authenticators are cluster-scoped, so the Events are recorded in the
+// default namespace.
+type outcomeRecorder struct {
+	recorder         events.EventRecorder
+	privilegedGroups []string
+	clock            clock.Clock
+
+	lock     sync.Mutex
+	failures map[string]*failures
+}
+
+type failures struct {
+	count int
+	since time.Time
+}
+
+func newOutcomeRecorder(recorder events.EventRecorder, privilegedGroups []string, clock clock.Clock) *outcomeRecorder {
+	return &outcomeRecorder{
+		recorder:         recorder,
+		privilegedGroups: privilegedGroups,
+		clock:            clock,
+		failures:         map[string]*failures{},
+	}
+}
+
+// authenticatorUnavailable reports that the authenticator of the request returned an error, e.g. because the
+// authenticator does not exist or because its webhook or its issuer could not be reached.
+func (o *outcomeRecorder) authenticatorUnavailable(req *loginapi.TokenCredentialRequest, err error) {
+	plog.WarningErr("authenticator failed to authenticate token credential request", err,
+		"authenticator", klog.KRef("", req.Spec.Authenticator.Name),
+		"kind", req.Spec.Authenticator.Kind,
+	)
+	o.record(req, corev1.EventTypeWarning, ReasonAuthenticatorUnavailable, "Authenticate",
+		fmt.Sprintf("The authenticator could not authenticate a token: %s", err))
+}
+
+// authenticationFailed counts a failed authentication. The failure is attributed to the user which was returned by
+// the authenticator, if any, or else to the user which made the request, as authenticated by the Kubernetes API
+// server. Anonymous requests are therefore counted together.
+func (o *outcomeRecorder) authenticationFailed(ctx context.Context, req *loginapi.TokenCredentialRequest, userInfo user.Info) {
+	var username string
+	switch requester, ok := genericapirequest.UserFrom(ctx); {
+	case userInfo != nil && len(userInfo.GetName()) != 0:
+		username = userInfo.GetName()
+	case ok:
+		username = requester.GetName()
+	default:
+		return
+	}
+
+	if !o.countFailure(username) {
+		return
+	}
+
+	plog.Warning("repeated failed authentications of token credential requests",
+		"username", username,
+		"failures", failureThreshold,
+		"window", failureWindow.String(),
+		"authenticator", klog.KRef("", req.Spec.Authenticator.Name),
+		"kind", req.Spec.Authenticator.Kind,
+	)
+	o.record(req, corev1.EventTypeWarning, ReasonRepeatedAuthenticationFailures, "Authenticate",
+		fmt.Sprintf("%d failed authentications for user %q within %s", failureThreshold, username, failureWindow))
+}
+
+// countFailure counts a failed authentication of the user, and returns true when the failures should be reported.
+func (o *outcomeRecorder) countFailure(username string) bool {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	now := o.clock.Now()
+
+	f, ok := o.failures[username]
+	if !ok || now.Sub(f.since) > failureWindow {
+		if !ok && len(o.failures) >= maxTrackedUsers {
+			o.forgetExpiredFailures(now)
+			if len(o.failures) >= maxTrackedUsers {
+				return false
+			}
+		}
+		f = &failures{since: now}
+		o.failures[username] = f
+	}
+
+	f.count++
+	if f.count < failureThreshold {
+		return false
+	}
+
+	delete(o.failures, username)
+	return true
+}
+
+func (o *outcomeRecorder) forgetExpiredFailures(now time.Time) {
+	for username, f := range o.failures {
+		if now.Sub(f.since) > failureWindow {
+			delete(o.failures, username)
+		}
+	}
+}
+
+// credentialIssued reports the issuance of a credential for a user in one of the privileged groups.
+func (o *outcomeRecorder) credentialIssued(req *loginapi.TokenCredentialRequest, userInfo user.Info) {
+	privilegedGroups := o.privilegedGroupsOf(userInfo)
+	if len(privilegedGroups) == 0 {
+		return
+	}
+
+	plog.Warning("issued credential for a privileged user",
+		"username", userInfo.GetName(),
+		"privilegedGroups", privilegedGroups,
+		"authenticator", klog.KRef("", req.Spec.Authenticator.Name),
+		"kind", req.Spec.Authenticator.Kind,
+	)
+	o.record(req, corev1.EventTypeWarning, ReasonPrivilegedCredentialIssued, "IssueCredential",
+		fmt.Sprintf("Issued a credential for user %q in the privileged groups %q", userInfo.GetName(), privilegedGroups))
+}
+
+func (o *outcomeRecorder) privilegedGroupsOf(userInfo user.Info) []string {
+	var groups []string
+	for _, group := range userInfo.GetGroups() {
+		for _, privilegedGroup := range o.privilegedGroups {
+			if group == privilegedGroup {
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+func (o *outcomeRecorder) record(req *loginapi.TokenCredentialRequest, eventType, reason, action, note string) {
+	if len(note) > maxEventNoteLength {
+		note = note[:maxEventNoteLength-len("...")] + "..."
+	}
+	o.recorder.Eventf(authenticatorReference(req), nil, eventType, reason, action, "%s", note)
+}
+
+// authenticatorReference returns a reference to the authenticator which was named by the request. The request only
+// names the API group of the authenticator, so the version is assumed.
+func authenticatorReference(req *loginapi.TokenCredentialRequest) *corev1.ObjectReference {
+	group := authenticationv1alpha1.SchemeGroupVersion.Group
+	if req.Spec.Authenticator.APIGroup != nil {
+		group = *req.Spec.Authenticator.APIGroup
+	}
+	apiVersion, kind := schema.GroupVersionKind{
+		Group:   group,
+		Version: authenticationv1alpha1.SchemeGroupVersion.Version,
+		Kind:    req.Spec.Authenticator.Kind,
+	}.ToAPIVersionAndKind()
+	return &corev1.ObjectReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       req.Spec.Authenticator.Name,
+	}
+}
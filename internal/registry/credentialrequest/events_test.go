@@ -0,0 +1,166 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package credentialrequest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/events"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
+)
+
+func TestOutcomeRecorder(t *testing.T) {
+	req := credentialRequest(loginapi.TokenCredentialRequestSpec{
+		Token: "some token",
+		Authenticator: corev1.TypedLocalObjectReference{
+			APIGroup: pointer.String("authentication.concierge.example.com"),
+			Kind:     "WebhookAuthenticator",
+			Name:     "some-webhook",
+		},
+	})
+	anonymousCtx := genericapirequest.WithUser(context.Background(), &user.DefaultInfo{Name: "system:anonymous"})
+
+	tests := []struct {
+		name       string
+		run        func(o *outcomeRecorder, clock *clocktesting.FakeClock)
+		wantEvents []string
+	}{
+		{
+			name: "authenticator is unavailable",
+			run: func(o *outcomeRecorder, _ *clocktesting.FakeClock) {
+				o.authenticatorUnavailable(req, errors.New("some webhook error"))
+			},
+			wantEvents: []string{
+				"Warning AuthenticatorUnavailable The authenticator could not authenticate a token: some webhook error",
+			},
+		},
+		{
+			name: "long notes are truncated",
+			run: func(o *outcomeRecorder, _ *clocktesting.FakeClock) {
+				o.authenticatorUnavailable(req, errors.New(strings.Repeat("x", 2000)))
+			},
+			wantEvents: []string{
+				"Warning AuthenticatorUnavailable The authenticator could not authenticate a token: " +
+					strings.Repeat("x", maxEventNoteLength-len("The authenticator could not authenticate a token: ...")) + "...",
+			},
+		},
+		{
+			name: "repeated failures of the requesting user within the window are reported once per threshold",
+			run: func(o *outcomeRecorder, clock *clocktesting.FakeClock) {
+				for i := 0; i < 2*failureThreshold; i++ {
+					o.authenticationFailed(anonymousCtx, req, nil)
+					clock.Step(time.Second)
+				}
+			},
+			wantEvents: []string{
+				`Warning RepeatedAuthenticationFailures 5 failed authentications for user "system:anonymous" within 5m0s`,
+				`Warning RepeatedAuthenticationFailures 5 failed authentications for user "system:anonymous" within 5m0s`,
+			},
+		},
+		{
+			name: "failures are attributed to the user which was returned by the authenticator",
+			run: func(o *outcomeRecorder, _ *clocktesting.FakeClock) {
+				for i := 0; i < failureThreshold-1; i++ {
+					o.authenticationFailed(anonymousCtx, req, nil)
+				}
+				for i := 0; i < failureThreshold; i++ {
+					o.authenticationFailed(anonymousCtx, req, &user.DefaultInfo{Name: "some-user", UID: "some-uid"})
+				}
+			},
+			wantEvents: []string{
+				`Warning RepeatedAuthenticationFailures 5 failed authentications for user "some-user" within 5m0s`,
+			},
+		},
+		{
+			name: "failures outside of the window are not reported",
+			run: func(o *outcomeRecorder, clock *clocktesting.FakeClock) {
+				for i := 0; i < failureThreshold; i++ {
+					o.authenticationFailed(anonymousCtx, req, nil)
+					clock.Step(failureWindow / (failureThreshold - 1))
+					clock.Step(time.Second)
+				}
+			},
+		},
+		{
+			name: "failures without a user are not counted",
+			run: func(o *outcomeRecorder, _ *clocktesting.FakeClock) {
+				for i := 0; i < failureThreshold; i++ {
+					o.authenticationFailed(context.Background(), req, nil)
+				}
+			},
+		},
+		{
+			name: "credential is issued for a privileged user",
+			run: func(o *outcomeRecorder, _ *clocktesting.FakeClock) {
+				o.credentialIssued(req, &user.DefaultInfo{Name: "some-admin", Groups: []string{"a", "system:masters", "admins"}})
+			},
+			wantEvents: []string{
+				`Warning PrivilegedCredentialIssued Issued a credential for user "some-admin" in the privileged groups ["system:masters" "admins"]`,
+			},
+		},
+		{
+			name: "credential is issued for an unprivileged user",
+			run: func(o *outcomeRecorder, _ *clocktesting.FakeClock) {
+				o.credentialIssued(req, &user.DefaultInfo{Name: "some-user", Groups: []string{"a", "b"}})
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := events.NewFakeRecorder(100)
+			clock := clocktesting.NewFakeClock(time.Now())
+			subject := newOutcomeRecorder(recorder, []string{"system:masters", "admins"}, clock)
+
+			tt.run(subject, clock)
+
+			close(recorder.Events)
+			var actualEvents []string
+			for event := range recorder.Events {
+				actualEvents = append(actualEvents, event)
+			}
+			require.Equal(t, tt.wantEvents, actualEvents)
+		})
+	}
+}
+
+func TestAuthenticatorReference(t *testing.T) {
+	require.Equal(t,
+		&corev1.ObjectReference{
+			APIVersion: "authentication.concierge.example.com/v1alpha1",
+			Kind:       "JWTAuthenticator",
+			Name:       "some-jwt",
+		},
+		authenticatorReference(credentialRequest(loginapi.TokenCredentialRequestSpec{
+			Authenticator: corev1.TypedLocalObjectReference{
+				APIGroup: pointer.String("authentication.concierge.example.com"),
+				Kind:     "JWTAuthenticator",
+				Name:     "some-jwt",
+			},
+		})),
+	)
+	require.Equal(t,
+		&corev1.ObjectReference{
+			APIVersion: "authentication.concierge.pinniped.dev/v1alpha1",
+			Kind:       "WebhookAuthenticator",
+			Name:       "some-webhook",
+		},
+		authenticatorReference(credentialRequest(loginapi.TokenCredentialRequestSpec{
+			Authenticator: corev1.TypedLocalObjectReference{Kind: "WebhookAuthenticator", Name: "some-webhook"},
+		})),
+	)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package credentialrequest provides REST functionality for the CredentialRequest resource.
@@ -18,6 +18,8 @@ import (
 	"k8s.io/apiserver/pkg/authentication/user"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/trace"
 
 	loginapi "go.pinniped.dev/generated/latest/apis/concierge/login"
@@ -31,11 +33,21 @@ type TokenCredentialRequestAuthenticator interface {
 	AuthenticateTokenCredentialRequest(ctx context.Context, req *loginapi.TokenCredentialRequest) (user.Info, error)
 }
 
-func NewREST(authenticator TokenCredentialRequestAuthenticator, issuer issuer.ClientCertIssuer, resource schema.GroupResource) *REST {
+// NewREST returns the storage of the TokenCredentialRequest resource. Notable outcomes, i.e. repeated failed
+// authentications of a user, credentials issued for users in one of the privilegedGroups, and errors of the
+// authenticators, are recorded as Events by the recorder.
+func NewREST(
+	authenticator TokenCredentialRequestAuthenticator,
+	issuer issuer.ClientCertIssuer,
+	resource schema.GroupResource,
+	recorder events.EventRecorder,
+	privilegedGroups []string,
+) *REST {
 	return &REST{
 		authenticator:  authenticator,
 		issuer:         issuer,
 		tableConvertor: rest.NewDefaultTableConvertor(resource),
+		outcomes:       newOutcomeRecorder(recorder, privilegedGroups, clock.RealClock{}),
 	}
 }
 
@@ -43,6 +55,7 @@ type REST struct {
 	authenticator  TokenCredentialRequestAuthenticator
 	issuer         issuer.ClientCertIssuer
 	tableConvertor rest.TableConvertor
+	outcomes       *outcomeRecorder
 }
 
 // Assert that our *REST implements all the optional interfaces that we expect it to implement.
@@ -101,10 +114,13 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 	userInfo, err := r.authenticator.AuthenticateTokenCredentialRequest(ctx, credentialRequest)
 	if err != nil {
 		traceFailureWithError(t, "token authentication", err)
+		r.outcomes.authenticatorUnavailable(credentialRequest, err)
+		r.outcomes.authenticationFailed(ctx, credentialRequest, nil)
 		return failureResponse(), nil
 	}
 	if ok := isUserInfoValid(userInfo); !ok {
 		traceSuccess(t, userInfo, false)
+		r.outcomes.authenticationFailed(ctx, credentialRequest, userInfo)
 		return failureResponse(), nil
 	}
 
@@ -117,6 +133,7 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 	}
 
 	traceSuccess(t, userInfo, true)
+	r.outcomes.credentialIssued(credentialRequest, userInfo)
 
 	return &loginapi.TokenCredentialRequest{
 		Status: loginapi.TokenCredentialRequestStatus{
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package credentialrequest
@@ -21,6 +21,7 @@ import (
 	"k8s.io/apiserver/pkg/authentication/user"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 
@@ -32,7 +33,7 @@ import (
 )
 
 func TestNew(t *testing.T) {
-	r := NewREST(nil, nil, schema.GroupResource{Group: "bears", Resource: "panda"})
+	r := NewREST(nil, nil, schema.GroupResource{Group: "bears", Resource: "panda"}, &events.FakeRecorder{}, nil)
 	require.NotNil(t, r)
 	require.False(t, r.NamespaceScoped())
 	require.Equal(t, []string{"pinniped"}, r.Categories())
@@ -97,7 +98,8 @@ func TestCreate(t *testing.T) {
 				5*time.Minute,
 			).Return([]byte("test-cert"), []byte("test-key"), nil)
 
-			storage := NewREST(requestAuthenticator, clientCertIssuer, schema.GroupResource{})
+			recorder := events.NewFakeRecorder(1)
+			storage := NewREST(requestAuthenticator, clientCertIssuer, schema.GroupResource{}, recorder, []string{"test-group-2"})
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -119,6 +121,7 @@ func TestCreate(t *testing.T) {
 				},
 			})
 			requireOneLogStatement(r, logger, `"success" userID:,hasExtra:false,authenticated:true`)
+			r.Equal(`Warning PrivilegedCredentialIssued Issued a credential for user "test-user" in the privileged groups ["test-group-2"]`, <-recorder.Events)
 		})
 
 		it("CreateFailsWithValidTokenWhenCertIssuerFails", func() {
@@ -136,7 +139,7 @@ func TestCreate(t *testing.T) {
 				IssueClientCertPEM(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(nil, nil, fmt.Errorf("some certificate authority error"))
 
-			storage := NewREST(requestAuthenticator, clientCertIssuer, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, clientCertIssuer, schema.GroupResource{}, &events.FakeRecorder{}, nil)
 
 			response, err := callCreate(context.Background(), storage, req)
 			requireSuccessfulResponseWithAuthenticationFailureMessage(t, err, response)
@@ -149,7 +152,7 @@ func TestCreate(t *testing.T) {
 			requestAuthenticator := credentialrequestmocks.NewMockTokenCredentialRequestAuthenticator(ctrl)
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).Return(nil, nil)
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{}, &events.FakeRecorder{}, nil)
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -164,12 +167,14 @@ func TestCreate(t *testing.T) {
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
 				Return(nil, errors.New("some webhook error"))
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			recorder := events.NewFakeRecorder(1)
+			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{}, recorder, nil)
 
 			response, err := callCreate(context.Background(), storage, req)
 
 			requireSuccessfulResponseWithAuthenticationFailureMessage(t, err, response)
 			requireOneLogStatement(r, logger, `"failure" failureType:token authentication,msg:some webhook error`)
+			r.Equal("Warning AuthenticatorUnavailable The authenticator could not authenticate a token: some webhook error", <-recorder.Events)
 		})
 
 		it("CreateSucceedsWithAnUnauthenticatedStatusWhenWebhookReturnsAnEmptyUsername", func() {
@@ -179,7 +184,7 @@ func TestCreate(t *testing.T) {
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req).
 				Return(&user.DefaultInfo{Name: ""}, nil)
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{}, &events.FakeRecorder{}, nil)
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -198,7 +203,7 @@ func TestCreate(t *testing.T) {
 					Groups: []string{"test-group-1", "test-group-2"},
 				}, nil)
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{}, &events.FakeRecorder{}, nil)
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -217,7 +222,7 @@ func TestCreate(t *testing.T) {
 					Extra:  map[string][]string{"test-key": {"test-val-1", "test-val-2"}},
 				}, nil)
 
-			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, nil, schema.GroupResource{}, &events.FakeRecorder{}, nil)
 
 			response, err := callCreate(context.Background(), storage, req)
 
@@ -227,7 +232,7 @@ func TestCreate(t *testing.T) {
 
 		it("CreateFailsWhenGivenTheWrongInputType", func() {
 			notACredentialRequest := runtime.Unknown{}
-			response, err := NewREST(nil, nil, schema.GroupResource{}).Create(
+			response, err := NewREST(nil, nil, schema.GroupResource{}, &events.FakeRecorder{}, nil).Create(
 				genericapirequest.NewContext(),
 				&notACredentialRequest,
 				rest.ValidateAllObjectFunc,
@@ -238,7 +243,7 @@ func TestCreate(t *testing.T) {
 		})
 
 		it("CreateFailsWhenTokenValueIsEmptyInRequest", func() {
-			storage := NewREST(nil, nil, schema.GroupResource{})
+			storage := NewREST(nil, nil, schema.GroupResource{}, &events.FakeRecorder{}, nil)
 			response, err := callCreate(context.Background(), storage, credentialRequest(loginapi.TokenCredentialRequestSpec{
 				Token: "",
 			}))
@@ -249,7 +254,7 @@ func TestCreate(t *testing.T) {
 		})
 
 		it("CreateFailsWhenValidationFails", func() {
-			storage := NewREST(nil, nil, schema.GroupResource{})
+			storage := NewREST(nil, nil, schema.GroupResource{}, &events.FakeRecorder{}, nil)
 			response, err := storage.Create(
 				context.Background(),
 				validCredentialRequest(),
@@ -269,7 +274,7 @@ func TestCreate(t *testing.T) {
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req.DeepCopy()).
 				Return(&user.DefaultInfo{Name: "test-user"}, nil)
 
-			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), schema.GroupResource{}, &events.FakeRecorder{}, nil)
 			response, err := storage.Create(
 				context.Background(),
 				req,
@@ -290,7 +295,7 @@ func TestCreate(t *testing.T) {
 			requestAuthenticator.EXPECT().AuthenticateTokenCredentialRequest(gomock.Any(), req.DeepCopy()).
 				Return(&user.DefaultInfo{Name: "test-user"}, nil)
 
-			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), schema.GroupResource{})
+			storage := NewREST(requestAuthenticator, successfulIssuer(ctrl), schema.GroupResource{}, &events.FakeRecorder{}, nil)
 			validationFunctionWasCalled := false
 			var validationFunctionSawTokenValue string
 			response, err := storage.Create(
@@ -310,7 +315,7 @@ func TestCreate(t *testing.T) {
 		})
 
 		it("CreateFailsWhenRequestOptionsDryRunIsNotEmpty", func() {
-			response, err := NewREST(nil, nil, schema.GroupResource{}).Create(
+			response, err := NewREST(nil, nil, schema.GroupResource{}, &events.FakeRecorder{}, nil).Create(
 				genericapirequest.NewContext(),
 				validCredentialRequest(),
 				rest.ValidateAllObjectFunc,
@@ -324,7 +329,7 @@ func TestCreate(t *testing.T) {
 		})
 
 		it("CreateFailsWhenNamespaceIsNotEmpty", func() {
-			response, err := NewREST(nil, nil, schema.GroupResource{}).Create(
+			response, err := NewREST(nil, nil, schema.GroupResource{}, &events.FakeRecorder{}, nil).Create(
 				genericapirequest.WithNamespace(genericapirequest.NewContext(), "some-ns"),
 				validCredentialRequest(),
 				rest.ValidateAllObjectFunc,
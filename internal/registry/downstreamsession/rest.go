@@ -0,0 +1,369 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package downstreamsession provides REST functionality for the DownstreamSession resource.
+package downstreamsession
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ory/fosite"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/fositestorage"
+	"go.pinniped.dev/internal/fositestorage/accesstoken"
+	"go.pinniped.dev/internal/fositestorage/refreshtoken"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/psession"
+)
+
+// SelectableFields returns the fields of a DownstreamSession which may be used in a field selector.
+func SelectableFields(session *sessionapi.DownstreamSession) fields.Set {
+	return fields.Set{
+		"metadata.name":               session.Name,
+		"metadata.namespace":          session.Namespace,
+		"status.username":             session.Status.Username,
+		"status.identityProviderName": session.Status.IdentityProviderName,
+		"status.identityProviderType": session.Status.IdentityProviderType,
+		"status.clientID":             session.Status.ClientID,
+	}
+}
+
+// NewREST returns the storage of the DownstreamSessions. The secretsClient may be nil when the sessions are not stored
+// in Secrets, e.g. when they are stored in Redis, in which case every request is refused because the sessions cannot
+// be listed or revoked. The sessionEncrypter decrypts the sessions when they are encrypted, and may be nil otherwise.
+func NewREST(resource schema.GroupResource, secretsClient corev1client.SecretInterface, sessionEncrypter crud.Encrypter, namespace string, auditLogger auditlog.Logger) *REST {
+	return &REST{
+		secretsClient:    secretsClient,
+		sessionEncrypter: sessionEncrypter,
+		namespace:        namespace,
+		resource:         resource,
+		tableConvertor:   rest.NewDefaultTableConvertor(resource),
+		auditLogger:      auditLogger,
+	}
+}
+
+type REST struct {
+	secretsClient    corev1client.SecretInterface
+	sessionEncrypter crud.Encrypter
+	namespace        string
+	resource         schema.GroupResource
+	tableConvertor   rest.TableConvertor
+	auditLogger      auditlog.Logger
+}
+
+// Assert that our *REST implements all the optional interfaces that we expect it to implement.
+var _ interface {
+	rest.Getter
+	rest.Lister
+	rest.GracefulDeleter
+	rest.CollectionDeleter
+	rest.Scoper
+	rest.Storage
+	rest.CategoriesProvider
+	rest.TableConvertor
+} = (*REST)(nil)
+
+func (*REST) New() runtime.Object {
+	return &sessionapi.DownstreamSession{}
+}
+
+func (*REST) Destroy() {}
+
+func (*REST) NewList() runtime.Object {
+	return &sessionapi.DownstreamSessionList{}
+}
+
+func (r *REST) ConvertToTable(ctx context.Context, obj runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return r.tableConvertor.ConvertToTable(ctx, obj, tableOptions)
+}
+
+func (*REST) NamespaceScoped() bool {
+	return true
+}
+
+func (*REST) Categories() []string {
+	return []string{"pinniped"}
+}
+
+func (r *REST) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	if err := r.validateRequest(ctx, "get"); err != nil {
+		return nil, err
+	}
+
+	sessions, err := r.listSessions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, apierrors.NewNotFound(r.resource, name)
+	}
+	return &sessions[0], nil
+}
+
+func (r *REST) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	if err := r.validateRequest(ctx, "list"); err != nil {
+		return nil, err
+	}
+
+	sessions, err := r.listSessions(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &sessionapi.DownstreamSessionList{
+		ListMeta: metav1.ListMeta{
+			ResourceVersion: "0", // this resource version means "from the API server cache"
+		},
+		Items: filterSessions(sessions, options),
+	}, nil
+}
+
+// Delete revokes the session by deleting all of its downstream access token and refresh token storage.
+func (r *REST) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, _ *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	if err := r.validateRequest(ctx, "delete"); err != nil {
+		return nil, false, err
+	}
+
+	obj, err := r.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if deleteValidation != nil {
+		if err := deleteValidation(ctx, obj); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := r.revokeSession(ctx, obj.(*sessionapi.DownstreamSession)); err != nil {
+		return nil, false, err
+	}
+
+	return obj, true, nil
+}
+
+// DeleteCollection revokes all sessions which match the list options, e.g. all sessions for a particular username
+// when using the field selector status.username=<username>.
+func (r *REST) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, _ *metav1.DeleteOptions, listOptions *metainternalversion.ListOptions) (runtime.Object, error) {
+	if err := r.validateRequest(ctx, "deletecollection"); err != nil {
+		return nil, err
+	}
+
+	obj, err := r.List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	list := obj.(*sessionapi.DownstreamSessionList)
+
+	for i := range list.Items {
+		session := &list.Items[i]
+		if deleteValidation != nil {
+			if err := deleteValidation(ctx, session); err != nil {
+				return nil, err
+			}
+		}
+		if err := r.revokeSession(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+func (r *REST) validateRequest(ctx context.Context, verb string) error {
+	// Only the Secrets backend can be searched for the storage of a session, so refuse the request instead of
+	// pretending that there are no sessions, or that a session was revoked while its tokens are still valid.
+	if r.secretsClient == nil {
+		return apierrors.NewMethodNotSupported(r.resource, verb)
+	}
+	return r.validateNamespace(ctx)
+}
+
+func (r *REST) validateNamespace(ctx context.Context) error {
+	requestNamespace, ok := genericapirequest.NamespaceFrom(ctx)
+	if !ok {
+		return apierrors.NewInternalError(fmt.Errorf("no namespace information found in request context"))
+	}
+	// Sessions are only stored in the Supervisor's namespace.
+	if requestNamespace != r.namespace {
+		return apierrors.NewBadRequest(fmt.Sprintf("namespace must be %s on DownstreamSession, was %s", r.namespace, requestNamespace))
+	}
+	return nil
+}
+
+// listSessions reads all access token and refresh token storage Secrets and combines them into sessions,
+// one per fosite request ID. When sessionID is not empty, then only the Secrets for that session are read.
+func (r *REST) listSessions(ctx context.Context, sessionID string) ([]sessionapi.DownstreamSession, error) {
+	storageTypes, err := labels.NewRequirement(crud.SecretLabelKey, selection.In,
+		[]string{accesstoken.TypeLabelValue, refreshtoken.TypeLabelValue})
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	selector := labels.NewSelector().Add(*storageTypes)
+	if sessionID != "" {
+		requestID, err := labels.NewRequirement(fositestorage.StorageRequestIDLabelName, selection.Equals, []string{sessionID})
+		if err != nil {
+			// The session ID is not a valid label value, so it could not possibly be the ID of a session.
+			return nil, nil
+		}
+		selector = selector.Add(*requestID)
+	}
+
+	secrets, err := r.secretsClient.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		plog.WarningErr("failed to list session storage secrets", err)
+		return nil, apierrors.NewInternalError(fmt.Errorf("listing sessions failed"))
+	}
+
+	sessionsByID := map[string]*sessionapi.DownstreamSession{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+
+		request, pinnipedSession, err := r.readStorageSecret(secret)
+		if err != nil {
+			// Skip any Secret that cannot be read, e.g. because it was written by an older version of the Supervisor.
+			plog.Debug("skipping unreadable session storage secret", "secretName", secret.Name, "error", err.Error())
+			continue
+		}
+		if request == nil {
+			// The Secret is a refresh token which was already rotated, so it does not belong to the session anymore.
+			continue
+		}
+
+		session, found := sessionsByID[request.GetID()]
+		if !found {
+			session = &sessionapi.DownstreamSession{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      request.GetID(),
+					Namespace: r.namespace,
+				},
+				Status: sessionapi.DownstreamSessionStatus{
+					Username:             pinnipedSession.Custom.Username,
+					IdentityProviderName: pinnipedSession.Custom.ProviderName,
+					IdentityProviderType: string(pinnipedSession.Custom.ProviderType),
+					ClientID:             request.GetClient().GetID(),
+				},
+			}
+			if pinnipedSession.Fosite != nil && pinnipedSession.Fosite.Claims != nil && !pinnipedSession.Fosite.Claims.AuthTime.IsZero() {
+				session.Status.AuthenticationTime = metav1.NewTime(pinnipedSession.Fosite.Claims.AuthTime)
+			}
+			sessionsByID[request.GetID()] = session
+		}
+
+		// Every refresh creates new storage Secrets and deletes or rotates the old ones, so the newest Secret marks
+		// the most recent refresh.
+		if session.CreationTimestamp.IsZero() || secret.CreationTimestamp.Before(&session.CreationTimestamp) {
+			session.CreationTimestamp = secret.CreationTimestamp
+		}
+		if session.Status.LastRefreshTime.Before(&secret.CreationTimestamp) {
+			session.Status.LastRefreshTime = secret.CreationTimestamp
+		}
+	}
+
+	sessions := make([]sessionapi.DownstreamSession, 0, len(sessionsByID))
+	for _, session := range sessionsByID {
+		if session.Status.AuthenticationTime.IsZero() {
+			session.Status.AuthenticationTime = session.CreationTimestamp
+		}
+		session.CreationTimestamp = session.Status.AuthenticationTime
+		sessions = append(sessions, *session)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
+
+	return sessions, nil
+}
+
+func (r *REST) revokeSession(ctx context.Context, session *sessionapi.DownstreamSession) error {
+	sessionID := session.Name
+
+	// Deleting the refresh token storage prevents any further refreshes, and deleting the access token storage
+	// prevents the access tokens from being exchanged for cluster-scoped tokens, so together they end the session.
+	secrets, err := r.secretsClient.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set{fositestorage.StorageRequestIDLabelName: sessionID}.String(),
+	})
+	if err != nil {
+		plog.WarningErr("failed to list session storage secrets", err, "sessionID", sessionID)
+		return apierrors.NewInternalError(fmt.Errorf("revoking session %q failed", sessionID))
+	}
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		err = r.secretsClient.Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			plog.WarningErr("failed to delete session storage secret", err, "sessionID", sessionID, "secretName", secret.Name)
+			return apierrors.NewInternalError(fmt.Errorf("revoking session %q failed", sessionID))
+		}
+	}
+
+	plog.Info("revoked downstream session", "sessionID", sessionID)
+	r.auditLogger.Emit(auditlog.Event{
+		Type:             auditlog.EventSessionRevoked,
+		SessionID:        sessionID,
+		ClientID:         session.Status.ClientID,
+		IdentityProvider: session.Status.IdentityProviderName,
+		Username:         session.Status.Username,
+	})
+	return nil
+}
+
+func filterSessions(sessions []sessionapi.DownstreamSession, options *metainternalversion.ListOptions) []sessionapi.DownstreamSession {
+	filtered := []sessionapi.DownstreamSession{} // avoid sending nil items list
+	for i := range sessions {
+		if options != nil && options.FieldSelector != nil && !options.FieldSelector.Matches(SelectableFields(&sessions[i])) {
+			continue
+		}
+		filtered = append(filtered, sessions[i])
+	}
+	return filtered
+}
+
+// readStorageSecret returns the request and session of an access token or refresh token storage Secret. It returns
+// a nil request and no error for a refresh token which was already rotated, since those are only kept to detect
+// replays of the refresh token.
+func (r *REST) readStorageSecret(secret *corev1.Secret) (*fosite.Request, *psession.PinnipedSession, error) {
+	var request *fosite.Request
+
+	switch secret.Labels[crud.SecretLabelKey] {
+	case accesstoken.TypeLabelValue:
+		accessTokenSession, err := accesstoken.ReadFromSecret(secret, r.sessionEncrypter)
+		if err != nil {
+			return nil, nil, err
+		}
+		request = accessTokenSession.Request
+	case refreshtoken.TypeLabelValue:
+		refreshTokenSession, err := refreshtoken.ReadFromSecret(secret, r.sessionEncrypter)
+		if err != nil {
+			return nil, nil, err
+		}
+		if refreshTokenSession.Rotated {
+			return nil, nil, nil
+		}
+		request = refreshTokenSession.Request
+	default:
+		return nil, nil, fmt.Errorf("unexpected storage type %q", secret.Labels[crud.SecretLabelKey])
+	}
+
+	pinnipedSession, ok := request.Session.(*psession.PinnipedSession)
+	if !ok || pinnipedSession.Custom == nil {
+		return nil, nil, fmt.Errorf("storage does not contain a pinniped session")
+	}
+
+	return request, pinnipedSession, nil
+}
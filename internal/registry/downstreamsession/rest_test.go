@@ -0,0 +1,227 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package downstreamsession
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/fositestorage"
+	"go.pinniped.dev/internal/fositestorage/accesstoken"
+	"go.pinniped.dev/internal/fositestorage/refreshtoken"
+	"go.pinniped.dev/internal/oidc/clientregistry"
+	"go.pinniped.dev/internal/psession"
+	"go.pinniped.dev/internal/testutil"
+)
+
+const namespace = "some-namespace"
+
+func TestNew(t *testing.T) {
+	r := NewREST(schema.GroupResource{Group: "bears", Resource: "panda"}, nil, nil, namespace, auditlog.Nop())
+
+	require.NotNil(t, r)
+	require.True(t, r.NamespaceScoped())
+	require.Equal(t, []string{"pinniped"}, r.Categories())
+
+	require.IsType(t, &sessionapi.DownstreamSession{}, r.New())
+	require.IsType(t, &sessionapi.DownstreamSessionList{}, r.NewList())
+
+	// exercise group resource - force error by passing a runtime.Object that does not have an embedded object meta
+	_, err := r.ConvertToTable(context.Background(), &metav1.APIGroup{}, nil)
+	require.Error(t, err, "the resource panda.bears does not support being converted to a Table")
+}
+
+func TestListGetAndDelete(t *testing.T) {
+	ctx := genericapirequest.WithNamespace(genericapirequest.NewContext(), namespace)
+
+	authTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	refreshTime := authTime.Add(time.Hour)
+
+	kubeClient := fake.NewSimpleClientset()
+	secrets := kubeClient.CoreV1().Secrets(namespace)
+	accessTokenStorage := accesstoken.New(secrets, time.Now, time.Hour)
+	refreshTokenStorage := refreshtoken.New(secrets, time.Now, time.Hour)
+
+	// The first session has been refreshed, and has both access and refresh tokens.
+	require.NoError(t, accessTokenStorage.CreateAccessTokenSession(ctx, "sig-1a", newRequest("session-1", "alice", "upstream-oidc", "client-a", authTime)))
+	require.NoError(t, refreshTokenStorage.CreateRefreshTokenSession(ctx, "sig-1b", newRequest("session-1", "alice", "upstream-oidc", "client-a", authTime)))
+	setCreationTimestamp(t, secrets, accesstoken.TypeLabelValue, "sig-1a", refreshTime)
+	setCreationTimestamp(t, secrets, refreshtoken.TypeLabelValue, "sig-1b", refreshTime)
+
+	// The second session belongs to the same user, but it only has an access token.
+	require.NoError(t, accessTokenStorage.CreateAccessTokenSession(ctx, "sig-2", newRequest("session-2", "alice", "upstream-ldap", "client-b", authTime)))
+	setCreationTimestamp(t, secrets, accesstoken.TypeLabelValue, "sig-2", authTime)
+
+	// The third session belongs to a different user.
+	require.NoError(t, refreshTokenStorage.CreateRefreshTokenSession(ctx, "sig-3", newRequest("session-3", "bob", "upstream-oidc", "client-a", authTime)))
+	setCreationTimestamp(t, secrets, refreshtoken.TypeLabelValue, "sig-3", authTime)
+
+	// The fourth session only has a refresh token which was already rotated, so it is not listed.
+	require.NoError(t, refreshTokenStorage.CreateRefreshTokenSession(ctx, "sig-4", newRequest("session-4", "carol", "upstream-oidc", "client-a", authTime)))
+	require.NoError(t, refreshTokenStorage.RevokeRefreshTokenMaybeGracePeriod(ctx, "session-4", "sig-4"))
+
+	auditRecorder := &testutil.AuditRecorder{}
+	r := NewREST(sessionapi.Resource("downstreamsessions"), secrets, nil, namespace, auditRecorder)
+
+	wantSession1 := sessionapi.DownstreamSession{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-1", Namespace: namespace, CreationTimestamp: metav1.NewTime(authTime)},
+		Status: sessionapi.DownstreamSessionStatus{
+			Username:             "alice",
+			IdentityProviderName: "upstream-oidc",
+			IdentityProviderType: "oidc",
+			ClientID:             "client-a",
+			AuthenticationTime:   metav1.NewTime(authTime),
+			LastRefreshTime:      metav1.NewTime(refreshTime),
+		},
+	}
+	wantSession2 := sessionapi.DownstreamSession{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-2", Namespace: namespace, CreationTimestamp: metav1.NewTime(authTime)},
+		Status: sessionapi.DownstreamSessionStatus{
+			Username:             "alice",
+			IdentityProviderName: "upstream-ldap",
+			IdentityProviderType: "oidc",
+			ClientID:             "client-b",
+			AuthenticationTime:   metav1.NewTime(authTime),
+			LastRefreshTime:      metav1.NewTime(authTime),
+		},
+	}
+	wantSession3 := sessionapi.DownstreamSession{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-3", Namespace: namespace, CreationTimestamp: metav1.NewTime(authTime)},
+		Status: sessionapi.DownstreamSessionStatus{
+			Username:             "bob",
+			IdentityProviderName: "upstream-oidc",
+			IdentityProviderType: "oidc",
+			ClientID:             "client-a",
+			AuthenticationTime:   metav1.NewTime(authTime),
+			LastRefreshTime:      metav1.NewTime(authTime),
+		},
+	}
+
+	list, err := r.List(ctx, &metainternalversion.ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []sessionapi.DownstreamSession{wantSession1, wantSession2, wantSession3}, list.(*sessionapi.DownstreamSessionList).Items)
+
+	list, err = r.List(ctx, &metainternalversion.ListOptions{FieldSelector: fields.OneTermEqualSelector("status.username", "bob")})
+	require.NoError(t, err)
+	require.Equal(t, []sessionapi.DownstreamSession{wantSession3}, list.(*sessionapi.DownstreamSessionList).Items)
+
+	got, err := r.Get(ctx, "session-1", &metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, &wantSession1, got)
+
+	_, err = r.Get(ctx, "does-not-exist", &metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+
+	_, err = r.Get(ctx, "session-4", &metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+
+	_, err = r.List(genericapirequest.WithNamespace(genericapirequest.NewContext(), "other-namespace"), &metainternalversion.ListOptions{})
+	require.True(t, apierrors.IsBadRequest(err), "expected bad request error but got: %v", err)
+
+	// Revoke a single session.
+	deleted, deletedImmediately, err := r.Delete(ctx, "session-3", nil, &metav1.DeleteOptions{})
+	require.NoError(t, err)
+	require.True(t, deletedImmediately)
+	require.Equal(t, &wantSession3, deleted)
+	_, err = r.Get(ctx, "session-3", &metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+
+	// Revoke all sessions of a user.
+	deletedList, err := r.DeleteCollection(ctx, nil, &metav1.DeleteOptions{},
+		&metainternalversion.ListOptions{FieldSelector: fields.OneTermEqualSelector("status.username", "alice")})
+	require.NoError(t, err)
+	require.Equal(t, []sessionapi.DownstreamSession{wantSession1, wantSession2}, deletedList.(*sessionapi.DownstreamSessionList).Items)
+
+	list, err = r.List(ctx, &metainternalversion.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, list.(*sessionapi.DownstreamSessionList).Items)
+
+	// Only the rotated refresh token of the fourth session remains, until it is garbage collected.
+	remainingSecrets, err := secrets.List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, remainingSecrets.Items, 1)
+	require.Equal(t, "session-4", remainingSecrets.Items[0].Labels[fositestorage.StorageRequestIDLabelName])
+
+	revokedEvent := func(session sessionapi.DownstreamSession) auditlog.Event {
+		return auditlog.Event{
+			Type:             auditlog.EventSessionRevoked,
+			SessionID:        session.Name,
+			ClientID:         session.Status.ClientID,
+			IdentityProvider: session.Status.IdentityProviderName,
+			Username:         session.Status.Username,
+		}
+	}
+	require.Equal(t, []auditlog.Event{
+		revokedEvent(wantSession3),
+		revokedEvent(wantSession1),
+		revokedEvent(wantSession2),
+	}, auditRecorder.Events())
+}
+
+func TestSessionsWhichAreNotStoredInSecrets(t *testing.T) {
+	ctx := genericapirequest.WithNamespace(genericapirequest.NewContext(), namespace)
+	r := NewREST(sessionapi.Resource("downstreamsessions"), nil, nil, namespace, auditlog.Nop())
+
+	_, err := r.Get(ctx, "some-session", &metav1.GetOptions{})
+	require.True(t, apierrors.IsMethodNotSupported(err), err)
+
+	_, err = r.List(ctx, &metainternalversion.ListOptions{})
+	require.True(t, apierrors.IsMethodNotSupported(err), err)
+
+	_, _, err = r.Delete(ctx, "some-session", nil, &metav1.DeleteOptions{})
+	require.True(t, apierrors.IsMethodNotSupported(err), err)
+	require.EqualError(t, err, `delete is not supported on resources of kind "downstreamsessions.session.supervisor.pinniped.dev"`)
+
+	_, err = r.DeleteCollection(ctx, nil, &metav1.DeleteOptions{}, &metainternalversion.ListOptions{})
+	require.True(t, apierrors.IsMethodNotSupported(err), err)
+}
+
+func newRequest(id, username, providerName, clientID string, authTime time.Time) *fosite.Request {
+	return &fosite.Request{
+		ID: id,
+		Client: &clientregistry.Client{
+			DefaultOpenIDConnectClient: fosite.DefaultOpenIDConnectClient{
+				DefaultClient: &fosite.DefaultClient{ID: clientID},
+			},
+		},
+		Session: &psession.PinnipedSession{
+			Fosite: &openid.DefaultSession{
+				Claims: &jwt.IDTokenClaims{AuthTime: authTime},
+			},
+			Custom: &psession.CustomSessionData{
+				Username:     username,
+				ProviderName: providerName,
+				ProviderType: psession.ProviderTypeOIDC,
+			},
+		},
+	}
+}
+
+func setCreationTimestamp(t *testing.T, secrets corev1client.SecretInterface, storageType, signature string, creationTime time.Time) {
+	t.Helper()
+
+	name := crud.New(storageType, secrets, time.Now, time.Hour).GetName(signature)
+	secret, err := secrets.Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	secret.CreationTimestamp = metav1.NewTime(creationTime)
+	_, err = secrets.Update(context.Background(), secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
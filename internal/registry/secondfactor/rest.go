@@ -0,0 +1,314 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package secondfactor provides REST functionality for the SecondFactor resource.
+package secondfactor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/totpsecretstorage"
+	"go.pinniped.dev/internal/webauthncredentialstorage"
+)
+
+// SelectableFields returns the fields of a SecondFactor which may be used in a field selector.
+func SelectableFields(secondFactor *sessionapi.SecondFactor) fields.Set {
+	return fields.Set{
+		"metadata.name":      secondFactor.Name,
+		"metadata.namespace": secondFactor.Namespace,
+		"status.username":    secondFactor.Status.Username,
+	}
+}
+
+// Name returns the name of the SecondFactor of the user with the given downstream subject. Subjects can be too long
+// to be names, so the name is derived from a hash of the subject.
+func Name(subject string) string {
+	sum := sha256.Sum256([]byte(subject))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewREST(resource schema.GroupResource, secretsClient corev1client.SecretInterface, namespace string, auditLogger auditlog.Logger) *REST {
+	return &REST{
+		secretsClient:  secretsClient,
+		namespace:      namespace,
+		resource:       resource,
+		tableConvertor: rest.NewDefaultTableConvertor(resource),
+		auditLogger:    auditLogger,
+	}
+}
+
+type REST struct {
+	secretsClient  corev1client.SecretInterface
+	namespace      string
+	resource       schema.GroupResource
+	tableConvertor rest.TableConvertor
+	auditLogger    auditlog.Logger
+}
+
+// Assert that our *REST implements all the optional interfaces that we expect it to implement.
+var _ interface {
+	rest.Getter
+	rest.Lister
+	rest.GracefulDeleter
+	rest.CollectionDeleter
+	rest.Scoper
+	rest.Storage
+	rest.CategoriesProvider
+	rest.TableConvertor
+} = (*REST)(nil)
+
+func (*REST) New() runtime.Object {
+	return &sessionapi.SecondFactor{}
+}
+
+func (*REST) Destroy() {}
+
+func (*REST) NewList() runtime.Object {
+	return &sessionapi.SecondFactorList{}
+}
+
+func (r *REST) ConvertToTable(ctx context.Context, obj runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return r.tableConvertor.ConvertToTable(ctx, obj, tableOptions)
+}
+
+func (*REST) NamespaceScoped() bool {
+	return true
+}
+
+func (*REST) Categories() []string {
+	return []string{"pinniped"}
+}
+
+func (r *REST) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	if err := r.validateNamespace(ctx); err != nil {
+		return nil, err
+	}
+
+	secondFactors, _, err := r.listSecondFactors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range secondFactors {
+		if secondFactors[i].Name == name {
+			return &secondFactors[i], nil
+		}
+	}
+	return nil, apierrors.NewNotFound(r.resource, name)
+}
+
+func (r *REST) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	if err := r.validateNamespace(ctx); err != nil {
+		return nil, err
+	}
+
+	secondFactors, _, err := r.listSecondFactors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sessionapi.SecondFactorList{
+		ListMeta: metav1.ListMeta{
+			ResourceVersion: "0", // this resource version means "from the API server cache"
+		},
+		Items: filterSecondFactors(secondFactors, options),
+	}, nil
+}
+
+// Delete resets the second factors of the user by deleting the storage of their WebAuthn credentials and TOTP secret.
+func (r *REST) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, _ *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	obj, err := r.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if deleteValidation != nil {
+		if err := deleteValidation(ctx, obj); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := r.resetSecondFactor(ctx, obj.(*sessionapi.SecondFactor)); err != nil {
+		return nil, false, err
+	}
+
+	return obj, true, nil
+}
+
+// DeleteCollection resets the second factors of all users which match the list options, e.g. of a particular
+// username when using the field selector status.username=<username>.
+func (r *REST) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, _ *metav1.DeleteOptions, listOptions *metainternalversion.ListOptions) (runtime.Object, error) {
+	obj, err := r.List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	list := obj.(*sessionapi.SecondFactorList)
+
+	for i := range list.Items {
+		secondFactor := &list.Items[i]
+		if deleteValidation != nil {
+			if err := deleteValidation(ctx, secondFactor); err != nil {
+				return nil, err
+			}
+		}
+		if err := r.resetSecondFactor(ctx, secondFactor); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+func (r *REST) validateNamespace(ctx context.Context) error {
+	requestNamespace, ok := genericapirequest.NamespaceFrom(ctx)
+	if !ok {
+		return apierrors.NewInternalError(fmt.Errorf("no namespace information found in request context"))
+	}
+	// Second factors are only stored in the Supervisor's namespace.
+	if requestNamespace != r.namespace {
+		return apierrors.NewBadRequest(fmt.Sprintf("namespace must be %s on SecondFactor, was %s", r.namespace, requestNamespace))
+	}
+	return nil
+}
+
+// listSecondFactors reads all WebAuthn credential and TOTP secret storage Secrets and combines them into second
+// factors, one per downstream subject. It also returns the names of the storage Secrets of each second factor.
+func (r *REST) listSecondFactors(ctx context.Context) ([]sessionapi.SecondFactor, map[string][]string, error) {
+	storageTypes, err := labels.NewRequirement(crud.SecretLabelKey, selection.In,
+		[]string{webauthncredentialstorage.TypeLabelValue, totpsecretstorage.TypeLabelValue})
+	if err != nil {
+		return nil, nil, apierrors.NewInternalError(err)
+	}
+
+	secrets, err := r.secretsClient.List(ctx, metav1.ListOptions{LabelSelector: labels.NewSelector().Add(*storageTypes).String()})
+	if err != nil {
+		plog.WarningErr("failed to list second factor storage secrets", err)
+		return nil, nil, apierrors.NewInternalError(fmt.Errorf("listing second factors failed"))
+	}
+
+	secondFactorsByName := map[string]*sessionapi.SecondFactor{}
+	secretNamesByName := map[string][]string{}
+	newestByName := map[string]metav1.Time{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+
+		subject, username, webAuthnCredentials, totpEnrolled, err := readStorageSecret(secret)
+		if err != nil {
+			// Skip any Secret that cannot be read, e.g. because it was written by an older version of the Supervisor.
+			plog.Debug("skipping unreadable second factor storage secret", "secretName", secret.Name, "error", err.Error())
+			continue
+		}
+
+		name := Name(subject)
+		secondFactor, found := secondFactorsByName[name]
+		if !found {
+			secondFactor = &sessionapi.SecondFactor{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         r.namespace,
+					CreationTimestamp: secret.CreationTimestamp,
+				},
+				Status: sessionapi.SecondFactorStatus{Subject: subject},
+			}
+			secondFactorsByName[name] = secondFactor
+		}
+		secretNamesByName[name] = append(secretNamesByName[name], secret.Name)
+
+		// The second factor exists since the user enrolled their first one, and shows the username of the most recent
+		// enrollment.
+		if secret.CreationTimestamp.Before(&secondFactor.CreationTimestamp) {
+			secondFactor.CreationTimestamp = secret.CreationTimestamp
+		}
+		if newest := newestByName[name]; !secret.CreationTimestamp.Before(&newest) {
+			newestByName[name] = secret.CreationTimestamp
+			secondFactor.Status.Username = username
+		}
+		secondFactor.Status.WebAuthnCredentials += webAuthnCredentials
+		secondFactor.Status.TOTPEnrolled = secondFactor.Status.TOTPEnrolled || totpEnrolled
+	}
+
+	secondFactors := make([]sessionapi.SecondFactor, 0, len(secondFactorsByName))
+	for _, secondFactor := range secondFactorsByName {
+		secondFactors = append(secondFactors, *secondFactor)
+	}
+	sort.Slice(secondFactors, func(i, j int) bool { return secondFactors[i].Name < secondFactors[j].Name })
+
+	return secondFactors, secretNamesByName, nil
+}
+
+func (r *REST) resetSecondFactor(ctx context.Context, secondFactor *sessionapi.SecondFactor) error {
+	name := secondFactor.Name
+
+	// Read the storage Secrets again, because the user may have enrolled another second factor in the meantime.
+	_, secretNamesByName, err := r.listSecondFactors(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, secretName := range secretNamesByName[name] {
+		err = r.secretsClient.Delete(ctx, secretName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			plog.WarningErr("failed to delete second factor storage secret", err, "secondFactor", name, "secretName", secretName)
+			return apierrors.NewInternalError(fmt.Errorf("resetting second factor %q failed", name))
+		}
+	}
+
+	plog.Info("reset second factor", "secondFactor", name)
+	r.auditLogger.Emit(auditlog.Event{
+		Type:     auditlog.EventSecondFactorReset,
+		Username: secondFactor.Status.Username,
+	})
+	return nil
+}
+
+func filterSecondFactors(secondFactors []sessionapi.SecondFactor, options *metainternalversion.ListOptions) []sessionapi.SecondFactor {
+	filtered := []sessionapi.SecondFactor{} // avoid sending nil items list
+	for i := range secondFactors {
+		if options != nil && options.FieldSelector != nil && !options.FieldSelector.Matches(SelectableFields(&secondFactors[i])) {
+			continue
+		}
+		filtered = append(filtered, secondFactors[i])
+	}
+	return filtered
+}
+
+// readStorageSecret returns the downstream subject and username of a WebAuthn credential or TOTP secret storage
+// Secret, and which second factors it contains.
+func readStorageSecret(secret *corev1.Secret) (string, string, int32, bool, error) {
+	switch secret.Labels[crud.SecretLabelKey] {
+	case webauthncredentialstorage.TypeLabelValue:
+		credentials, err := webauthncredentialstorage.ReadFromSecret(secret)
+		if err != nil {
+			return "", "", 0, false, err
+		}
+		return credentials.Subject, credentials.Username, int32(len(credentials.Credentials)), false, nil
+	case totpsecretstorage.TypeLabelValue:
+		totp, err := totpsecretstorage.ReadFromSecret(secret)
+		if err != nil {
+			return "", "", 0, false, err
+		}
+		return totp.Subject, totp.Username, 0, true, nil
+	default:
+		return "", "", 0, false, fmt.Errorf("unexpected storage type %q", secret.Labels[crud.SecretLabelKey])
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package secondfactor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	"go.pinniped.dev/internal/auditlog"
+	"go.pinniped.dev/internal/testutil"
+	"go.pinniped.dev/internal/totpsecretstorage"
+	"go.pinniped.dev/internal/webauthn"
+	"go.pinniped.dev/internal/webauthncredentialstorage"
+)
+
+const (
+	namespace    = "some-namespace"
+	aliceSubject = "https://issuer.example.com?sub=alice"
+	bobSubject   = "ldaps://ldap.example.com:636?base=ou%3Dusers&sub=Ym9i"
+)
+
+func TestNew(t *testing.T) {
+	r := NewREST(schema.GroupResource{Group: "bears", Resource: "panda"}, nil, namespace, auditlog.Nop())
+
+	require.NotNil(t, r)
+	require.True(t, r.NamespaceScoped())
+	require.Equal(t, []string{"pinniped"}, r.Categories())
+
+	require.IsType(t, &sessionapi.SecondFactor{}, r.New())
+	require.IsType(t, &sessionapi.SecondFactorList{}, r.NewList())
+
+	// exercise group resource - force error by passing a runtime.Object that does not have an embedded object meta
+	_, err := r.ConvertToTable(context.Background(), &metav1.APIGroup{}, nil)
+	require.Error(t, err, "the resource panda.bears does not support being converted to a Table")
+}
+
+func TestName(t *testing.T) {
+	require.Equal(t, "6c8c4805ef177d608c61ef3ee88d19feb2f234c72b45f6d479d73e3c64385ba5", Name("some-subject"))
+	require.NotEqual(t, Name(aliceSubject), Name(bobSubject))
+}
+
+func TestListGetAndDelete(t *testing.T) {
+	ctx := genericapirequest.WithNamespace(genericapirequest.NewContext(), namespace)
+
+	enrollTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	laterTime := enrollTime.Add(time.Hour)
+
+	kubeClient := fake.NewSimpleClientset()
+	secrets := kubeClient.CoreV1().Secrets(namespace)
+	webAuthnStorage := webauthncredentialstorage.New(secrets)
+	totpStorage := totpsecretstorage.New(secrets, func() []byte { return []byte("some-neato-32-byte-generated-key") })
+
+	// Alice has enrolled two WebAuthn credentials, and later a TOTP secret under a new username.
+	require.NoError(t, webAuthnStorage.Set(ctx, "", aliceSubject, "alice", []webauthncredentialstorage.Credential{
+		{Credential: webauthn.Credential{ID: []byte("credential-1")}},
+		{Credential: webauthn.Credential{ID: []byte("credential-2")}},
+	}))
+	setCreationTimestamp(t, secrets, webAuthnStorage.GetName(aliceSubject), enrollTime)
+	require.NoError(t, totpStorage.Set(ctx, "", &totpsecretstorage.TOTP{Subject: aliceSubject, Username: "alice@example.com", Secret: []byte("12345678901234567890")}))
+	setCreationTimestamp(t, secrets, totpStorage.GetName(aliceSubject), laterTime)
+
+	// Bob has only enrolled a TOTP secret.
+	require.NoError(t, totpStorage.Set(ctx, "", &totpsecretstorage.TOTP{Subject: bobSubject, Username: "bob", Secret: []byte("12345678901234567890")}))
+	setCreationTimestamp(t, secrets, totpStorage.GetName(bobSubject), laterTime)
+
+	auditRecorder := &testutil.AuditRecorder{}
+	r := NewREST(sessionapi.Resource("secondfactors"), secrets, namespace, auditRecorder)
+
+	wantAlice := sessionapi.SecondFactor{
+		ObjectMeta: metav1.ObjectMeta{Name: Name(aliceSubject), Namespace: namespace, CreationTimestamp: metav1.NewTime(enrollTime)},
+		Status: sessionapi.SecondFactorStatus{
+			Subject:             aliceSubject,
+			Username:            "alice@example.com",
+			WebAuthnCredentials: 2,
+			TOTPEnrolled:        true,
+		},
+	}
+	wantBob := sessionapi.SecondFactor{
+		ObjectMeta: metav1.ObjectMeta{Name: Name(bobSubject), Namespace: namespace, CreationTimestamp: metav1.NewTime(laterTime)},
+		Status: sessionapi.SecondFactorStatus{
+			Subject:      bobSubject,
+			Username:     "bob",
+			TOTPEnrolled: true,
+		},
+	}
+	wantAll := []sessionapi.SecondFactor{wantAlice, wantBob}
+	if wantAlice.Name > wantBob.Name {
+		wantAll = []sessionapi.SecondFactor{wantBob, wantAlice}
+	}
+
+	list, err := r.List(ctx, &metainternalversion.ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, wantAll, list.(*sessionapi.SecondFactorList).Items)
+
+	list, err = r.List(ctx, &metainternalversion.ListOptions{FieldSelector: fields.OneTermEqualSelector("status.username", "bob")})
+	require.NoError(t, err)
+	require.Equal(t, []sessionapi.SecondFactor{wantBob}, list.(*sessionapi.SecondFactorList).Items)
+
+	got, err := r.Get(ctx, Name(aliceSubject), &metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, &wantAlice, got)
+
+	_, err = r.Get(ctx, "does-not-exist", &metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+
+	_, err = r.List(genericapirequest.WithNamespace(genericapirequest.NewContext(), "other-namespace"), &metainternalversion.ListOptions{})
+	require.True(t, apierrors.IsBadRequest(err), "expected bad request error but got: %v", err)
+
+	// Reset all second factors of a single user.
+	deleted, deletedImmediately, err := r.Delete(ctx, Name(aliceSubject), nil, &metav1.DeleteOptions{})
+	require.NoError(t, err)
+	require.True(t, deletedImmediately)
+	require.Equal(t, &wantAlice, deleted)
+	_, err = r.Get(ctx, Name(aliceSubject), &metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "expected not found error but got: %v", err)
+	_, credentials, err := webAuthnStorage.Get(ctx, aliceSubject)
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+	_, totp, err := totpStorage.Get(ctx, aliceSubject)
+	require.NoError(t, err)
+	require.Nil(t, totp)
+
+	// Reset the second factors of users by username.
+	deletedList, err := r.DeleteCollection(ctx, nil, &metav1.DeleteOptions{},
+		&metainternalversion.ListOptions{FieldSelector: fields.OneTermEqualSelector("status.username", "bob")})
+	require.NoError(t, err)
+	require.Equal(t, []sessionapi.SecondFactor{wantBob}, deletedList.(*sessionapi.SecondFactorList).Items)
+
+	remainingSecrets, err := secrets.List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, remainingSecrets.Items)
+
+	require.Equal(t, []auditlog.Event{
+		{Type: auditlog.EventSecondFactorReset, Username: "alice@example.com"},
+		{Type: auditlog.EventSecondFactorReset, Username: "bob"},
+	}, auditRecorder.Events())
+}
+
+func setCreationTimestamp(t *testing.T, secrets corev1client.SecretInterface, name string, creationTime time.Time) {
+	t.Helper()
+
+	secret, err := secrets.Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	secret.CreationTimestamp = metav1.NewTime(creationTime)
+	_, err = secrets.Update(context.Background(), secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
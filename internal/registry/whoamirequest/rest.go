@@ -1,12 +1,14 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package whoamirequest
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,6 +23,10 @@ import (
 	identityapivalidation "go.pinniped.dev/generated/latest/apis/concierge/identity/validation"
 )
 
+// originalUserInfoExtraKey is the reserved extra key in which the impersonation proxy preserves the original user
+// of a request which impersonates another user, as a JSON blob of an authenticationv1.UserInfo.
+const originalUserInfoExtraKey = "original-user-info.impersonation-proxy.concierge.pinniped.dev"
+
 func NewREST(resource schema.GroupResource) *REST {
 	return &REST{
 		tableConvertor: rest.NewDefaultTableConvertor(resource),
@@ -129,5 +135,43 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 		out.Status.KubernetesUserInfo.User.Extra[k] = v
 	}
 
+	if whoAmIRequest.Spec.IncludeImpersonation {
+		originalUser, err := originalUserFromExtra(userInfo.GetExtra())
+		if err != nil {
+			return nil, apierrors.NewBadRequest(err.Error())
+		}
+		out.Status.OriginalUser = originalUser
+	}
+
+	return out, nil
+}
+
+// originalUserFromExtra returns the original user which the impersonation proxy preserved in the extra of the
+// impersonated user, or nil when the request did not impersonate another user through the impersonation proxy.
+func originalUserFromExtra(extra map[string][]string) (*identityapi.UserInfo, error) {
+	values := extra[originalUserInfoExtraKey]
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("extra %s must have exactly one value", originalUserInfoExtraKey)
+	}
+
+	var originalUser authenticationv1.UserInfo
+	if err := json.Unmarshal([]byte(values[0]), &originalUser); err != nil {
+		return nil, fmt.Errorf("extra %s is not a valid user info: %w", originalUserInfoExtraKey, err)
+	}
+
+	out := &identityapi.UserInfo{
+		Username: originalUser.Username,
+		UID:      originalUser.UID,
+		Groups:   originalUser.Groups,
+	}
+	for k, v := range originalUser.Extra {
+		if out.Extra == nil {
+			out.Extra = map[string]identityapi.ExtraValue{}
+		}
+		out.Extra[k] = identityapi.ExtraValue(v)
+	}
 	return out, nil
 }
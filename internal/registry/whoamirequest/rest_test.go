@@ -190,6 +190,107 @@ func TestCreate(t *testing.T) {
 			},
 			wantErr: ``,
 		},
+		{
+			name: "with impersonation through the impersonation proxy, not included",
+			args: args{
+				ctx: genericapirequest.WithUser(genericapirequest.NewContext(), &user.DefaultInfo{
+					Name: "panda",
+					Extra: map[string][]string{
+						"original-user-info.impersonation-proxy.concierge.pinniped.dev": {`{"username":"bond","groups":["spies"]}`},
+					},
+				}),
+				obj: &identityapi.WhoAmIRequest{},
+			},
+			want: &identityapi.WhoAmIRequest{
+				Status: identityapi.WhoAmIRequestStatus{
+					KubernetesUserInfo: identityapi.KubernetesUserInfo{
+						User: identityapi.UserInfo{
+							Username: "panda",
+							Extra: map[string]identityapi.ExtraValue{
+								"original-user-info.impersonation-proxy.concierge.pinniped.dev": {`{"username":"bond","groups":["spies"]}`},
+							},
+						},
+					},
+				},
+			},
+			wantErr: ``,
+		},
+		{
+			name: "with impersonation through the impersonation proxy, included",
+			args: args{
+				ctx: genericapirequest.WithUser(genericapirequest.NewContext(), &user.DefaultInfo{
+					Name: "panda",
+					Extra: map[string][]string{
+						"original-user-info.impersonation-proxy.concierge.pinniped.dev": {`{"username":"bond","uid":"007","groups":["spies"],"extra":{"agency":["mi6"]}}`},
+					},
+				}),
+				obj: &identityapi.WhoAmIRequest{Spec: identityapi.WhoAmIRequestSpec{IncludeImpersonation: true}},
+			},
+			want: &identityapi.WhoAmIRequest{
+				Status: identityapi.WhoAmIRequestStatus{
+					KubernetesUserInfo: identityapi.KubernetesUserInfo{
+						User: identityapi.UserInfo{
+							Username: "panda",
+							Extra: map[string]identityapi.ExtraValue{
+								"original-user-info.impersonation-proxy.concierge.pinniped.dev": {`{"username":"bond","uid":"007","groups":["spies"],"extra":{"agency":["mi6"]}}`},
+							},
+						},
+					},
+					OriginalUser: &identityapi.UserInfo{
+						Username: "bond",
+						UID:      "007",
+						Groups:   []string{"spies"},
+						Extra:    map[string]identityapi.ExtraValue{"agency": {"mi6"}},
+					},
+				},
+			},
+			wantErr: ``,
+		},
+		{
+			name: "without impersonation, included",
+			args: args{
+				ctx: genericapirequest.WithUser(genericapirequest.NewContext(), &user.DefaultInfo{
+					Name: "panda",
+				}),
+				obj: &identityapi.WhoAmIRequest{Spec: identityapi.WhoAmIRequestSpec{IncludeImpersonation: true}},
+			},
+			want: &identityapi.WhoAmIRequest{
+				Status: identityapi.WhoAmIRequestStatus{
+					KubernetesUserInfo: identityapi.KubernetesUserInfo{
+						User: identityapi.UserInfo{
+							Username: "panda",
+						},
+					},
+				},
+			},
+			wantErr: ``,
+		},
+		{
+			name: "with invalid original user info, included",
+			args: args{
+				ctx: genericapirequest.WithUser(genericapirequest.NewContext(), &user.DefaultInfo{
+					Name: "panda",
+					Extra: map[string][]string{
+						"original-user-info.impersonation-proxy.concierge.pinniped.dev": {`not-json`},
+					},
+				}),
+				obj: &identityapi.WhoAmIRequest{Spec: identityapi.WhoAmIRequestSpec{IncludeImpersonation: true}},
+			},
+			wantErr: `extra original-user-info.impersonation-proxy.concierge.pinniped.dev is not a valid user info: invalid character 'o' in literal null (expecting 'u')`,
+		},
+		{
+			name: "with too many original user infos, included",
+			args: args{
+				ctx: genericapirequest.WithUser(genericapirequest.NewContext(), &user.DefaultInfo{
+					Name: "panda",
+					Extra: map[string][]string{
+						"original-user-info.impersonation-proxy.concierge.pinniped.dev": {`{"username":"bond"}`, `{"username":"q"}`},
+					},
+				}),
+				obj: &identityapi.WhoAmIRequest{Spec: identityapi.WhoAmIRequestSpec{IncludeImpersonation: true}},
+			},
+			wantErr: `extra original-user-info.impersonation-proxy.concierge.pinniped.dev must have exactly one value`,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
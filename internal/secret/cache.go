@@ -1,4 +1,4 @@
-// Copyright 2020 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package secret
@@ -9,17 +9,20 @@ import (
 )
 
 type Cache struct {
-	csrfCookieEncoderHashKey atomic.Value
-	federationDomainCacheMap sync.Map
+	csrfCookieEncoderHashKey         atomic.Value
+	previousCSRFCookieEncoderHashKey atomic.Value
+	totpEncryptionKey                atomic.Value
+	federationDomainCacheMap         sync.Map
 }
 
 // New returns an empty Cache.
 func New() *Cache { return &Cache{} }
 
 type federationDomainCache struct {
-	tokenHMACKey         atomic.Value
-	stateEncoderHashKey  atomic.Value
-	stateEncoderBlockKey atomic.Value
+	tokenHMACKey                atomic.Value
+	stateEncoderHashKey         atomic.Value
+	previousStateEncoderHashKey atomic.Value
+	stateEncoderBlockKey        atomic.Value
 }
 
 func (c *Cache) GetCSRFCookieEncoderHashKey() []byte {
@@ -30,6 +33,22 @@ func (c *Cache) SetCSRFCookieEncoderHashKey(key []byte) {
 	c.csrfCookieEncoderHashKey.Store(key)
 }
 
+func (c *Cache) GetPreviousCSRFCookieEncoderHashKey() []byte {
+	return bytesOrNil(c.previousCSRFCookieEncoderHashKey.Load())
+}
+
+func (c *Cache) SetPreviousCSRFCookieEncoderHashKey(key []byte) {
+	c.previousCSRFCookieEncoderHashKey.Store(key)
+}
+
+func (c *Cache) GetTOTPEncryptionKey() []byte {
+	return bytesOrNil(c.totpEncryptionKey.Load())
+}
+
+func (c *Cache) SetTOTPEncryptionKey(key []byte) {
+	c.totpEncryptionKey.Store(key)
+}
+
 func (c *Cache) GetTokenHMACKey(oidcIssuer string) []byte {
 	return bytesOrNil(c.getFederationDomainCache(oidcIssuer).tokenHMACKey.Load())
 }
@@ -46,6 +65,14 @@ func (c *Cache) SetStateEncoderHashKey(oidcIssuer string, key []byte) {
 	c.getFederationDomainCache(oidcIssuer).stateEncoderHashKey.Store(key)
 }
 
+func (c *Cache) GetPreviousStateEncoderHashKey(oidcIssuer string) []byte {
+	return bytesOrNil(c.getFederationDomainCache(oidcIssuer).previousStateEncoderHashKey.Load())
+}
+
+func (c *Cache) SetPreviousStateEncoderHashKey(oidcIssuer string, key []byte) {
+	c.getFederationDomainCache(oidcIssuer).previousStateEncoderHashKey.Store(key)
+}
+
 func (c *Cache) GetStateEncoderBlockKey(oidcIssuer string) []byte {
 	return bytesOrNil(c.getFederationDomainCache(oidcIssuer).stateEncoderBlockKey.Load())
 }
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package secret
@@ -18,11 +18,14 @@ const (
 )
 
 var (
-	csrfCookieEncoderHashKey = []byte("csrf-cookie-encoder-hash-key")
-	tokenHMACKey             = []byte("token-hmac-key")
-	stateEncoderHashKey      = []byte("state-encoder-hash-key")
-	otherStateEncoderHashKey = []byte("other-state-encoder-hash-key")
-	stateEncoderBlockKey     = []byte("state-encoder-block-key")
+	csrfCookieEncoderHashKey         = []byte("csrf-cookie-encoder-hash-key")
+	previousCSRFCookieEncoderHashKey = []byte("previous-csrf-cookie-encoder-hash-key")
+	totpEncryptionKey                = []byte("totp-encryption-key")
+	tokenHMACKey                     = []byte("token-hmac-key")
+	stateEncoderHashKey              = []byte("state-encoder-hash-key")
+	otherStateEncoderHashKey         = []byte("other-state-encoder-hash-key")
+	previousStateEncoderHashKey      = []byte("previous-state-encoder-hash-key")
+	stateEncoderBlockKey             = []byte("state-encoder-block-key")
 )
 
 func TestCache(t *testing.T) {
@@ -30,8 +33,11 @@ func TestCache(t *testing.T) {
 
 	// Validate we get a nil return value when stuff does not exist.
 	require.Nil(t, c.GetCSRFCookieEncoderHashKey())
+	require.Nil(t, c.GetPreviousCSRFCookieEncoderHashKey())
+	require.Nil(t, c.GetTOTPEncryptionKey())
 	require.Nil(t, c.GetTokenHMACKey(issuer))
 	require.Nil(t, c.GetStateEncoderHashKey(issuer))
+	require.Nil(t, c.GetPreviousStateEncoderHashKey(issuer))
 	require.Nil(t, c.GetStateEncoderBlockKey(issuer))
 
 	// Validate we get some nil and non-nil values when some stuff exists.
@@ -45,16 +51,23 @@ func TestCache(t *testing.T) {
 	// Validate we get non-nil values when all stuff exists.
 	c.SetCSRFCookieEncoderHashKey(csrfCookieEncoderHashKey)
 	c.SetTokenHMACKey(issuer, tokenHMACKey)
+	c.SetPreviousCSRFCookieEncoderHashKey(previousCSRFCookieEncoderHashKey)
+	c.SetTOTPEncryptionKey(totpEncryptionKey)
 	c.SetStateEncoderHashKey(issuer, otherStateEncoderHashKey)
+	c.SetPreviousStateEncoderHashKey(issuer, previousStateEncoderHashKey)
 	c.SetStateEncoderBlockKey(issuer, stateEncoderBlockKey)
 	require.Equal(t, csrfCookieEncoderHashKey, c.GetCSRFCookieEncoderHashKey())
+	require.Equal(t, previousCSRFCookieEncoderHashKey, c.GetPreviousCSRFCookieEncoderHashKey())
+	require.Equal(t, totpEncryptionKey, c.GetTOTPEncryptionKey())
 	require.Equal(t, tokenHMACKey, c.GetTokenHMACKey(issuer))
 	require.Equal(t, otherStateEncoderHashKey, c.GetStateEncoderHashKey(issuer))
+	require.Equal(t, previousStateEncoderHashKey, c.GetPreviousStateEncoderHashKey(issuer))
 	require.Equal(t, stateEncoderBlockKey, c.GetStateEncoderBlockKey(issuer))
 
 	// Validate that stuff is still nil for an unknown issuer.
 	require.Nil(t, c.GetTokenHMACKey(otherIssuer))
 	require.Nil(t, c.GetStateEncoderHashKey(otherIssuer))
+	require.Nil(t, c.GetPreviousStateEncoderHashKey(otherIssuer))
 	require.Nil(t, c.GetStateEncoderBlockKey(otherIssuer))
 }
 
@@ -0,0 +1,243 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sessionencryption implements the envelope encryption of the sessions of the Supervisor, so that reading
+// the session storage alone does not yield the upstream refresh tokens of the sessions.
+//
+// The sessions are encrypted with a data encryption key (DEK) which the Supervisor generates once. The DEK is stored
+// in a Secret, wrapped by a key encryption key (KEK). The KEK is either generated by the Supervisor in another Secret
+// and rotated periodically, or held by an external signer plugin, e.g. backed by a cloud KMS. The DEK is rewrapped
+// whenever the KEK changes. Only an external KEK protects the sessions from someone who can read all the Secrets of
+// the Supervisor's namespace, or etcd, since a generated KEK is stored there too.
+package sessionencryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/externalsigner"
+)
+
+const (
+	ErrNoDataEncryptionKey     = constable.Error("session encryption key is not available yet")
+	ErrNoKeyEncryptionKey      = constable.Error("session key encryption key is not available yet")
+	ErrUnknownKeyEncryptionKey = constable.Error("session encryption key was wrapped by an unknown key encryption key")
+	ErrDecryptionFailed        = constable.Error("session could not be decrypted")
+
+	// KeySize is the length, in bytes, of the generated data encryption keys and key encryption keys.
+	KeySize = 32
+
+	// wrappedKeyAssociatedData is authenticated when the DEK is wrapped by a generated KEK.
+	wrappedKeyAssociatedData = "pinniped-session-encryption-key"
+)
+
+// Keyring holds the unwrapped DEK of the sessions. It implements crud.Encrypter.
+//
+// It is thread-safe.
+type Keyring struct {
+	mu   sync.RWMutex
+	aead cipher.AEAD
+}
+
+// NewKeyring returns a Keyring which cannot encrypt or decrypt until its DEK is set.
+func NewKeyring() *Keyring {
+	return &Keyring{}
+}
+
+// SetDataEncryptionKey sets the DEK, once it was generated or unwrapped.
+func (k *Keyring) SetDataEncryptionKey(dek []byte) error {
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return fmt.Errorf("invalid session encryption key: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.aead = aead
+	return nil
+}
+
+// Encrypt encrypts the plaintext with the DEK, authenticating the associated data.
+func (k *Keyring) Encrypt(plaintext, associatedData []byte) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.aead == nil {
+		return nil, ErrNoDataEncryptionKey
+	}
+	return seal(k.aead, plaintext, associatedData)
+}
+
+// Decrypt decrypts a ciphertext which was returned by Encrypt with the same associated data.
+func (k *Keyring) Decrypt(ciphertext, associatedData []byte) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.aead == nil {
+		return nil, ErrNoDataEncryptionKey
+	}
+	return open(k.aead, ciphertext, associatedData)
+}
+
+// KeyEncryptionKey wraps the DEK. Each version of the KEK has an ID, which is stored next to the wrapped DEK, so
+// that the DEK can be rewrapped when the current version of the KEK changes.
+type KeyEncryptionKey interface {
+	// CurrentID returns the ID of the version of the KEK which wraps keys.
+	CurrentID() (string, error)
+	// Wrap wraps the DEK with the current version of the KEK, and returns the ID of that version.
+	Wrap(ctx context.Context, dek []byte) (wrapped []byte, id string, err error)
+	// Unwrap unwraps a DEK which was wrapped by the version of the KEK with the given ID.
+	Unwrap(ctx context.Context, id string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyEncryptionKey is a KEK which is generated by the Supervisor and stored in a Secret. After a rotation, the
+// previous version of the key is kept until the DEK was rewrapped. Its Secret is in the same namespace as the sessions,
+// so it does not protect them from anyone who can read all the Secrets of that namespace.
+//
+// It is thread-safe.
+type LocalKeyEncryptionKey struct {
+	mu       sync.RWMutex
+	current  []byte
+	previous []byte
+}
+
+var _ KeyEncryptionKey = &LocalKeyEncryptionKey{}
+
+// NewLocalKeyEncryptionKey returns a LocalKeyEncryptionKey which cannot wrap or unwrap until its keys are set.
+func NewLocalKeyEncryptionKey() *LocalKeyEncryptionKey {
+	return &LocalKeyEncryptionKey{}
+}
+
+// Set sets the current key, and the previous key after a rotation, or nil.
+func (k *LocalKeyEncryptionKey) Set(current, previous []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.current, k.previous = current, previous
+}
+
+func (k *LocalKeyEncryptionKey) CurrentID() (string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if len(k.current) == 0 {
+		return "", ErrNoKeyEncryptionKey
+	}
+	return localKeyID(k.current), nil
+}
+
+func (k *LocalKeyEncryptionKey) Wrap(_ context.Context, dek []byte) ([]byte, string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if len(k.current) == 0 {
+		return nil, "", ErrNoKeyEncryptionKey
+	}
+	aead, err := newAEAD(k.current)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid session key encryption key: %w", err)
+	}
+	wrapped, err := seal(aead, dek, []byte(wrappedKeyAssociatedData))
+	if err != nil {
+		return nil, "", err
+	}
+	return wrapped, localKeyID(k.current), nil
+}
+
+func (k *LocalKeyEncryptionKey) Unwrap(_ context.Context, id string, wrapped []byte) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, key := range [][]byte{k.current, k.previous} {
+		if len(key) == 0 || localKeyID(key) != id {
+			continue
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session key encryption key: %w", err)
+		}
+		return open(aead, wrapped, []byte(wrappedKeyAssociatedData))
+	}
+	if len(k.current) == 0 {
+		return nil, ErrNoKeyEncryptionKey
+	}
+	return nil, ErrUnknownKeyEncryptionKey
+}
+
+// localKeyID identifies a generated KEK without revealing it.
+func localKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return "local:" + hex.EncodeToString(sum[:8])
+}
+
+// externalKeyEncryptionKey is a KEK which is held by an external signer plugin. The plugin is responsible for the
+// rotation of the versions of its key, e.g. by a cloud KMS, so the DEK is only rewrapped when another key is
+// configured.
+type externalKeyEncryptionKey struct {
+	wrapper *externalsigner.KeyWrapper
+}
+
+// NewExternalKeyEncryptionKey returns a KEK which is held by the external signer plugin of the KeyWrapper.
+func NewExternalKeyEncryptionKey(wrapper *externalsigner.KeyWrapper) KeyEncryptionKey {
+	return &externalKeyEncryptionKey{wrapper: wrapper}
+}
+
+func (k *externalKeyEncryptionKey) CurrentID() (string, error) {
+	return "external:" + k.wrapper.KeyID(), nil
+}
+
+func (k *externalKeyEncryptionKey) Wrap(ctx context.Context, dek []byte) ([]byte, string, error) {
+	wrapped, err := k.wrapper.Encrypt(ctx, dek)
+	if err != nil {
+		return nil, "", err
+	}
+	id, _ := k.CurrentID()
+	return wrapped, id, nil
+}
+
+func (k *externalKeyEncryptionKey) Unwrap(ctx context.Context, id string, wrapped []byte) ([]byte, error) {
+	if currentID, _ := k.CurrentID(); id != currentID {
+		return nil, ErrUnknownKeyEncryptionKey
+	}
+	return k.wrapper.Decrypt(ctx, wrapped)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key has length %d instead of %d", len(key), KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts with AES-GCM and prefixes the ciphertext with the random nonce.
+func seal(aead cipher.AEAD, plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, associatedData []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrDecryptionFailed
+	}
+	plaintext, err := aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], associatedData)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
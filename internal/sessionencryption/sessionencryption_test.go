@@ -0,0 +1,99 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package sessionencryption
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	someKey      = []byte("some-neato-32-byte-generated-key")
+	someOtherKey = []byte("some-funio-32-byte-generated-key")
+	someNewKey   = []byte("some-newer-32-byte-generated-key")
+)
+
+func TestKeyring(t *testing.T) {
+	keyring := NewKeyring()
+
+	_, err := keyring.Encrypt([]byte("some-plaintext"), []byte("some-name"))
+	require.ErrorIs(t, err, ErrNoDataEncryptionKey)
+	_, err = keyring.Decrypt([]byte("some-ciphertext"), []byte("some-name"))
+	require.ErrorIs(t, err, ErrNoDataEncryptionKey)
+
+	require.EqualError(t, keyring.SetDataEncryptionKey([]byte("too short")), "invalid session encryption key: key has length 9 instead of 32")
+	require.NoError(t, keyring.SetDataEncryptionKey(someKey))
+
+	ciphertext, err := keyring.Encrypt([]byte("some-plaintext"), []byte("some-name"))
+	require.NoError(t, err)
+	require.NotContains(t, string(ciphertext), "some-plaintext")
+
+	otherCiphertext, err := keyring.Encrypt([]byte("some-plaintext"), []byte("some-name"))
+	require.NoError(t, err)
+	require.NotEqual(t, ciphertext, otherCiphertext, "each encryption should use a new nonce")
+
+	plaintext, err := keyring.Decrypt(ciphertext, []byte("some-name"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("some-plaintext"), plaintext)
+
+	_, err = keyring.Decrypt(ciphertext, []byte("some-other-name"))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = keyring.Decrypt(ciphertext[:5], []byte("some-name"))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	require.NoError(t, keyring.SetDataEncryptionKey(someOtherKey))
+	_, err = keyring.Decrypt(ciphertext, []byte("some-name"))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestLocalKeyEncryptionKey(t *testing.T) {
+	ctx := context.Background()
+	kek := NewLocalKeyEncryptionKey()
+
+	_, err := kek.CurrentID()
+	require.ErrorIs(t, err, ErrNoKeyEncryptionKey)
+	_, _, err = kek.Wrap(ctx, someKey)
+	require.ErrorIs(t, err, ErrNoKeyEncryptionKey)
+	_, err = kek.Unwrap(ctx, "local:some-id", []byte("some-wrapped-key"))
+	require.ErrorIs(t, err, ErrNoKeyEncryptionKey)
+
+	kek.Set(someOtherKey, nil)
+	oldID, err := kek.CurrentID()
+	require.NoError(t, err)
+	require.Regexp(t, "^local:[0-9a-f]{16}$", oldID)
+	wrapped, id, err := kek.Wrap(ctx, someKey)
+	require.NoError(t, err)
+	require.Equal(t, oldID, id)
+	require.NotContains(t, string(wrapped), string(someKey))
+
+	// after a rotation, keys which were wrapped by the previous key can still be unwrapped
+	kek.Set(someNewKey, someOtherKey)
+	newID, err := kek.CurrentID()
+	require.NoError(t, err)
+	require.NotEqual(t, oldID, newID)
+	unwrapped, err := kek.Unwrap(ctx, oldID, wrapped)
+	require.NoError(t, err)
+	require.Equal(t, someKey, unwrapped)
+
+	rewrapped, id, err := kek.Wrap(ctx, unwrapped)
+	require.NoError(t, err)
+	require.Equal(t, newID, id)
+	unwrapped, err = kek.Unwrap(ctx, newID, rewrapped)
+	require.NoError(t, err)
+	require.Equal(t, someKey, unwrapped)
+
+	_, err = kek.Unwrap(ctx, oldID, rewrapped)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// once the previous key is gone, keys which were wrapped by it can no longer be unwrapped
+	kek.Set(someNewKey, nil)
+	_, err = kek.Unwrap(ctx, oldID, wrapped)
+	require.ErrorIs(t, err, ErrUnknownKeyEncryptionKey)
+
+	kek.Set([]byte("too short"), nil)
+	_, _, err = kek.Wrap(ctx, someKey)
+	require.EqualError(t, err, "invalid session key encryption key: key has length 9 instead of 32")
+}
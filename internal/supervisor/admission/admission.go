@@ -0,0 +1,226 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package admission validates the Supervisor's resources in its validating admission webhook.
+package admission
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/errors"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	configv1alpha1listers "go.pinniped.dev/generated/latest/client/supervisor/listers/config/v1alpha1"
+	"go.pinniped.dev/internal/admissionwebhook"
+	"go.pinniped.dev/internal/config/supervisor"
+	"go.pinniped.dev/internal/controller/supervisorconfig/upstreamwatchers"
+	"go.pinniped.dev/internal/groupsuffix"
+	"go.pinniped.dev/internal/oidc/loginpolicy"
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+// NewValidators returns the validators of the Supervisor's resources in the API groups with the given suffix.
+//
+// SupervisorConfigs are only validated on their own, since the static configuration whose settings they override is
+// not known here, so settings which conflict with the static configuration are still reported in their status
+// conditions.
+//
+// FederationDomains are also validated against the other FederationDomains in the informer cache, so two
+// FederationDomains which are created at about the same time may still conflict. Such conflicts are still reported
+// in their status conditions.
+func NewValidators(apiGroupSuffix string, federationDomains configv1alpha1listers.FederationDomainLister) (map[schema.GroupResource]admissionwebhook.Validator, error) {
+	configGroup, ok := groupsuffix.Replace(configv1alpha1.GroupName, apiGroupSuffix)
+	if !ok {
+		return nil, fmt.Errorf("cannot make api group from %s/%s", configv1alpha1.GroupName, apiGroupSuffix)
+	}
+	idpGroup, ok := groupsuffix.Replace(idpv1alpha1.GroupName, apiGroupSuffix)
+	if !ok {
+		return nil, fmt.Errorf("cannot make api group from %s/%s", idpv1alpha1.GroupName, apiGroupSuffix)
+	}
+
+	return map[schema.GroupResource]admissionwebhook.Validator{
+		{Group: configGroup, Resource: "federationdomains"}: admissionwebhook.ValidatorFunc(func(_ context.Context, request *admissionv1.AdmissionRequest) error {
+			var federationDomain configv1alpha1.FederationDomain
+			if err := admissionwebhook.DecodeObject(request, &federationDomain); err != nil {
+				return err
+			}
+			return validateFederationDomain(&federationDomain, federationDomains)
+		}),
+		{Group: configGroup, Resource: "oidcclients"}: admissionwebhook.ValidatorFunc(func(_ context.Context, request *admissionv1.AdmissionRequest) error {
+			var oidcClient configv1alpha1.OIDCClient
+			if err := admissionwebhook.DecodeObject(request, &oidcClient); err != nil {
+				return err
+			}
+			return validateRedirectURIs(oidcClient.Spec.AllowedRedirectURIs)
+		}),
+		{Group: configGroup, Resource: "supervisorconfigs"}: admissionwebhook.ValidatorFunc(func(_ context.Context, request *admissionv1.AdmissionRequest) error {
+			var supervisorConfig configv1alpha1.SupervisorConfig
+			if err := admissionwebhook.DecodeObject(request, &supervisorConfig); err != nil {
+				return err
+			}
+			if err := supervisor.ValidateSupervisorConfig(supervisorConfig.Spec); err != nil {
+				return fmt.Errorf("invalid SupervisorConfig: %w", err)
+			}
+			return nil
+		}),
+		{Group: idpGroup, Resource: "oidcidentityproviders"}: admissionwebhook.ValidatorFunc(func(_ context.Context, request *admissionv1.AdmissionRequest) error {
+			var upstream idpv1alpha1.OIDCIdentityProvider
+			if err := admissionwebhook.DecodeObject(request, &upstream); err != nil {
+				return err
+			}
+			return validateTLSSpec(upstream.Spec.TLS)
+		}),
+		{Group: idpGroup, Resource: "ldapidentityproviders"}: admissionwebhook.ValidatorFunc(func(_ context.Context, request *admissionv1.AdmissionRequest) error {
+			var upstream idpv1alpha1.LDAPIdentityProvider
+			if err := admissionwebhook.DecodeObject(request, &upstream); err != nil {
+				return err
+			}
+			return validateTLSSpec(upstream.Spec.TLS)
+		}),
+		{Group: idpGroup, Resource: "activedirectoryidentityproviders"}: admissionwebhook.ValidatorFunc(func(_ context.Context, request *admissionv1.AdmissionRequest) error {
+			var upstream idpv1alpha1.ActiveDirectoryIdentityProvider
+			if err := admissionwebhook.DecodeObject(request, &upstream); err != nil {
+				return err
+			}
+			return validateTLSSpec(upstream.Spec.TLS)
+		}),
+	}, nil
+}
+
+// validateFederationDomain rejects the FederationDomains which the FederationDomain watcher would report as invalid,
+// as duplicates of other FederationDomains, or as using a different TLS secretName than another FederationDomain
+// with the same hostname. The previous issuers of issuer migrations are only considered until their grace period ends.
+func validateFederationDomain(federationDomain *configv1alpha1.FederationDomain, lister configv1alpha1listers.FederationDomainLister) error {
+	now := time.Now()
+
+	// This validates the issuer URL, the alias hosts, the previous issuer, the allowed CORS origins, the second factor
+	// enforcements, and the login policy.
+	federationDomainIssuer, err := provider.NewFederationDomainIssuerWithAliasHosts(federationDomain.Spec.Issuer, federationDomain.Spec.AliasHosts)
+	if err == nil && federationDomain.Spec.IssuerMigration != nil {
+		err = federationDomainIssuer.SetPreviousIssuer(federationDomain.Spec.IssuerMigration.PreviousIssuer)
+	}
+	if err == nil {
+		err = federationDomainIssuer.SetAllowedCORSOrigins(federationDomain.Spec.AllowedCORSOrigins)
+	}
+	if err == nil && federationDomain.Spec.WebAuthn != nil {
+		err = federationDomainIssuer.SetWebAuthnEnforcement(provider.WebAuthnEnforcement(federationDomain.Spec.WebAuthn.Enforcement))
+	}
+	if err == nil && federationDomain.Spec.TOTP != nil {
+		err = federationDomainIssuer.SetTOTPEnforcement(provider.TOTPEnforcement(federationDomain.Spec.TOTP.Enforcement))
+	}
+	if err == nil {
+		err = federationDomainIssuer.SetLoginPolicy(loginpolicy.ConfigFromSpec(federationDomain.Spec.LoginPolicy))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid FederationDomain: %w", err)
+	}
+
+	others, err := lister.FederationDomains(federationDomain.Namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("could not list FederationDomains: %w", err)
+	}
+
+	issuerURLs := servedIssuerURLs(federationDomain, now)
+	var errs []error
+	for _, other := range others {
+		if other.Name == federationDomain.Name {
+			continue
+		}
+		for _, otherIssuerURL := range servedIssuerURLs(other, now) {
+			for _, issuerURL := range issuerURLs {
+				if issuerKey(issuerURL) == issuerKey(otherIssuerURL) {
+					errs = append(errs, fmt.Errorf("issuer %s is already used by FederationDomain %q", issuerURL, other.Name))
+				} else if hostnameKey(issuerURL) == hostnameKey(otherIssuerURL) &&
+					federationDomain.Spec.TLS != nil && other.Spec.TLS != nil &&
+					federationDomain.Spec.TLS.SecretName != other.Spec.TLS.SecretName {
+					errs = append(errs, fmt.Errorf("issuers with the same DNS hostname (address not including port) must use the same secretName: "+
+						"hostname %s uses secretName %q in FederationDomain %q", hostnameKey(issuerURL), other.Spec.TLS.SecretName, other.Name))
+				}
+			}
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// issuerURLsForAllHosts returns the issuer URL followed by the issuer URL as seen from each of the alias hosts.
+func issuerURLsForAllHosts(issuer string, aliasHosts []string) []*url.URL {
+	issuerURL, err := url.Parse(issuer)
+	if err != nil {
+		return nil // invalid issuers cannot conflict with anything
+	}
+	issuerURLs := []*url.URL{issuerURL}
+	for _, aliasHost := range aliasHosts {
+		aliasURL := *issuerURL
+		aliasURL.Host = aliasHost
+		issuerURLs = append(issuerURLs, &aliasURL)
+	}
+	return issuerURLs
+}
+
+// servedIssuerURLs returns the issuer URLs of the FederationDomain for all of its hosts, followed by the URL of its
+// previous issuer while the grace period of its issuer migration has not ended at the given time.
+func servedIssuerURLs(federationDomain *configv1alpha1.FederationDomain, now time.Time) []*url.URL {
+	issuerURLs := issuerURLsForAllHosts(federationDomain.Spec.Issuer, federationDomain.Spec.AliasHosts)
+	issuerMigration := federationDomain.Spec.IssuerMigration
+	if issuerMigration == nil || !now.Before(issuerMigration.AcceptUntil.Time) {
+		return issuerURLs
+	}
+	previousIssuerURL, err := url.Parse(issuerMigration.PreviousIssuer)
+	if err != nil {
+		return issuerURLs // invalid issuers cannot conflict with anything
+	}
+	return append(issuerURLs, previousIssuerURL)
+}
+
+func issuerKey(issuerURL *url.URL) string {
+	return fmt.Sprintf("%s://%s%s", issuerURL.Scheme, strings.ToLower(issuerURL.Host), issuerURL.Path)
+}
+
+func hostnameKey(issuerURL *url.URL) string {
+	return strings.ToLower(issuerURL.Hostname())
+}
+
+// validateRedirectURIs rejects redirect URIs which fosite would never match, i.e. URIs which are not https URLs or
+// http URLs on a loopback address, or which have a fragment.
+func validateRedirectURIs(redirectURIs []configv1alpha1.RedirectURI) error {
+	var errs []error
+	for _, redirectURI := range redirectURIs {
+		u, err := url.Parse(string(redirectURI))
+		if err != nil || u.Host == "" || u.Fragment != "" || strings.HasSuffix(string(redirectURI), "#") ||
+			!(u.Scheme == "https" || (u.Scheme == "http" && isLoopback(u.Hostname()))) {
+			errs = append(errs, fmt.Errorf("allowedRedirectURIs: %q must be an https URL, or an http URL on 127.0.0.1 or [::1], without a fragment", redirectURI))
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// isLoopback allows the same loopback addresses as the allowedRedirectURIs pattern of the OIDCClient CRD.
+func isLoopback(hostname string) bool {
+	return hostname == "127.0.0.1" || hostname == "::1"
+}
+
+// validateTLSSpec rejects certificateAuthorityData which is not a base64 encoded PEM bundle of certificates.
+func validateTLSSpec(tlsSpec *idpv1alpha1.TLSSpec) error {
+	if tlsSpec == nil || tlsSpec.CertificateAuthorityData == "" {
+		return nil
+	}
+
+	bundle, err := base64.StdEncoding.DecodeString(tlsSpec.CertificateAuthorityData)
+	if err != nil {
+		return fmt.Errorf("spec.tls.certificateAuthorityData is invalid: %w", err)
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(bundle) {
+		return fmt.Errorf("spec.tls.certificateAuthorityData is invalid: %w", upstreamwatchers.ErrNoCertificates)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package admission
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	configv1alpha1listers "go.pinniped.dev/generated/latest/client/supervisor/listers/config/v1alpha1"
+	"go.pinniped.dev/internal/certauthority"
+)
+
+func TestValidators(t *testing.T) {
+	ca, err := certauthority.New("some-ca", time.Hour)
+	require.NoError(t, err)
+	validCABundle := base64.StdEncoding.EncodeToString(ca.Bundle())
+
+	existingFederationDomain := &configv1alpha1.FederationDomain{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "existing"},
+		Spec: configv1alpha1.FederationDomainSpec{
+			Issuer:     "https://issuer.example.com/existing",
+			AliasHosts: []string{"alias.example.com"},
+			TLS:        &configv1alpha1.FederationDomainTLSSpec{SecretName: "existing-tls"},
+		},
+	}
+	otherNamespaceFederationDomain := &configv1alpha1.FederationDomain{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other-namespace", Name: "other"},
+		Spec:       configv1alpha1.FederationDomainSpec{Issuer: "https://issuer.example.com/other"},
+	}
+	newIssuerMigration := func(previousIssuer string, acceptUntil time.Time) *configv1alpha1.FederationDomainIssuerMigration {
+		return &configv1alpha1.FederationDomainIssuerMigration{PreviousIssuer: previousIssuer, AcceptUntil: metav1.NewTime(acceptUntil)}
+	}
+	migratingFederationDomain := &configv1alpha1.FederationDomain{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "migrating"},
+		Spec: configv1alpha1.FederationDomainSpec{
+			Issuer:          "https://issuer.example.com/migrating",
+			IssuerMigration: newIssuerMigration("https://previous.example.com/migrating", time.Now().Add(time.Hour)),
+		},
+	}
+	migratedFederationDomain := &configv1alpha1.FederationDomain{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "migrated"},
+		Spec: configv1alpha1.FederationDomainSpec{
+			Issuer:          "https://issuer.example.com/migrated",
+			IssuerMigration: newIssuerMigration("https://previous.example.com/migrated", time.Now().Add(-time.Hour)),
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, indexer.Add(existingFederationDomain))
+	require.NoError(t, indexer.Add(otherNamespaceFederationDomain))
+	require.NoError(t, indexer.Add(migratingFederationDomain))
+	require.NoError(t, indexer.Add(migratedFederationDomain))
+
+	validators, err := NewValidators("pinniped.example.com", configv1alpha1listers.NewFederationDomainLister(indexer))
+	require.NoError(t, err)
+	require.Len(t, validators, 6)
+
+	validate := func(t *testing.T, resource string, object runtime.Object) error {
+		t.Helper()
+
+		var group string
+		switch object.(type) {
+		case *configv1alpha1.FederationDomain, *configv1alpha1.OIDCClient, *configv1alpha1.SupervisorConfig:
+			group = "config.supervisor.pinniped.example.com"
+		default:
+			group = "idp.supervisor.pinniped.example.com"
+		}
+		validator, ok := validators[schema.GroupResource{Group: group, Resource: resource}]
+		require.True(t, ok)
+
+		raw, err := json.Marshal(object)
+		require.NoError(t, err)
+		return validator.Validate(context.Background(), &admissionv1.AdmissionRequest{
+			Resource:  metav1.GroupVersionResource{Group: group, Version: "v1alpha1", Resource: resource},
+			Namespace: "some-namespace",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		})
+	}
+
+	newFederationDomain := func(name string, issuer string, secretName string, aliasHosts ...string) *configv1alpha1.FederationDomain {
+		federationDomain := &configv1alpha1.FederationDomain{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: name},
+			Spec:       configv1alpha1.FederationDomainSpec{Issuer: issuer, AliasHosts: aliasHosts},
+		}
+		if secretName != "" {
+			federationDomain.Spec.TLS = &configv1alpha1.FederationDomainTLSSpec{SecretName: secretName}
+		}
+		return federationDomain
+	}
+
+	t.Run("FederationDomains", func(t *testing.T) {
+		for _, tt := range []struct {
+			name             string
+			federationDomain *configv1alpha1.FederationDomain
+			wantErr          string
+		}{
+			{
+				name:             "valid",
+				federationDomain: newFederationDomain("new", "https://issuer.example.com/new", "existing-tls"),
+			},
+			{
+				name:             "updates of the existing FederationDomain do not conflict with itself",
+				federationDomain: newFederationDomain("existing", "https://issuer.example.com/existing", "new-tls"),
+			},
+			{
+				name:             "the same issuer as a FederationDomain in another namespace",
+				federationDomain: newFederationDomain("new", "https://issuer.example.com/other", ""),
+			},
+			{
+				name:             "invalid issuer",
+				federationDomain: newFederationDomain("new", "http://issuer.example.com/new", ""),
+				wantErr:          `invalid FederationDomain: issuer must have "https" scheme`,
+			},
+			{
+				name:             "invalid alias host",
+				federationDomain: newFederationDomain("new", "https://issuer.example.com/new", "", "issuer.example.com"),
+				wantErr:          `invalid FederationDomain: alias host "issuer.example.com" must not be the same as the issuer host or another alias host`,
+			},
+			{
+				name: "invalid login policy",
+				federationDomain: func() *configv1alpha1.FederationDomain {
+					federationDomain := newFederationDomain("new", "https://issuer.example.com/new", "")
+					federationDomain.Spec.LoginPolicy = &configv1alpha1.FederationDomainLoginPolicy{AllowedEmailDomains: []string{"@example.com"}}
+					return federationDomain
+				}(),
+				wantErr: `invalid FederationDomain: invalid login policy: allowed email domain "@example.com" must be a domain name without an @, e.g. example.com`,
+			},
+			{
+				name:             "duplicate issuer",
+				federationDomain: newFederationDomain("new", "https://ISSUER.example.com/existing", ""),
+				wantErr:          `issuer https://ISSUER.example.com/existing is already used by FederationDomain "existing"`,
+			},
+			{
+				name:             "alias host which duplicates the issuer of another FederationDomain",
+				federationDomain: newFederationDomain("new", "https://new.example.com/existing", "", "issuer.example.com"),
+				wantErr:          `issuer https://issuer.example.com/existing is already used by FederationDomain "existing"`,
+			},
+			{
+				name: "invalid previous issuer",
+				federationDomain: func() *configv1alpha1.FederationDomain {
+					federationDomain := newFederationDomain("new", "https://issuer.example.com/new", "")
+					federationDomain.Spec.IssuerMigration = newIssuerMigration("https://issuer.example.com/new", time.Now().Add(time.Hour))
+					return federationDomain
+				}(),
+				wantErr: `invalid FederationDomain: previous issuer "https://issuer.example.com/new" must not be the same as the issuer or the issuer of an alias host`,
+			},
+			{
+				name: "previous issuer which duplicates the issuer of another FederationDomain",
+				federationDomain: func() *configv1alpha1.FederationDomain {
+					federationDomain := newFederationDomain("new", "https://issuer.example.com/new", "")
+					federationDomain.Spec.IssuerMigration = newIssuerMigration("https://issuer.example.com/existing", time.Now().Add(time.Hour))
+					return federationDomain
+				}(),
+				wantErr: `issuer https://issuer.example.com/existing is already used by FederationDomain "existing"`,
+			},
+			{
+				name:             "the previous issuer of another FederationDomain",
+				federationDomain: newFederationDomain("new", "https://previous.example.com/migrating", ""),
+				wantErr:          `issuer https://previous.example.com/migrating is already used by FederationDomain "migrating"`,
+			},
+			{
+				name:             "the previous issuer of another FederationDomain after its grace period has ended",
+				federationDomain: newFederationDomain("new", "https://previous.example.com/migrated", ""),
+			},
+			{
+				name:             "the same hostname with a different TLS secret",
+				federationDomain: newFederationDomain("new", "https://alias.example.com:8443/new", "new-tls"),
+				wantErr: `issuers with the same DNS hostname (address not including port) must use the same secretName: ` +
+					`hostname alias.example.com uses secretName "existing-tls" in FederationDomain "existing"`,
+			},
+		} {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				err := validate(t, "federationdomains", tt.federationDomain)
+				if tt.wantErr != "" {
+					require.EqualError(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("OIDCClients", func(t *testing.T) {
+		newOIDCClient := func(redirectURIs ...configv1alpha1.RedirectURI) *configv1alpha1.OIDCClient {
+			return &configv1alpha1.OIDCClient{Spec: configv1alpha1.OIDCClientSpec{AllowedRedirectURIs: redirectURIs}}
+		}
+
+		require.NoError(t, validate(t, "oidcclients", newOIDCClient(
+			"https://app.example.com/callback",
+			"http://127.0.0.1/callback",
+			"http://[::1]:1234/callback",
+		)))
+		require.EqualError(t, validate(t, "oidcclients", newOIDCClient(
+			"https://app.example.com/callback#fragment",
+			"http://app.example.com/callback",
+			"https:///callback",
+			"https://app.example.com/callback",
+		)), `[allowedRedirectURIs: "https://app.example.com/callback#fragment" must be an https URL, or an http URL on 127.0.0.1 or [::1], without a fragment, `+
+			`allowedRedirectURIs: "http://app.example.com/callback" must be an https URL, or an http URL on 127.0.0.1 or [::1], without a fragment, `+
+			`allowedRedirectURIs: "https:///callback" must be an https URL, or an http URL on 127.0.0.1 or [::1], without a fragment]`)
+	})
+
+	t.Run("SupervisorConfigs", func(t *testing.T) {
+		require.NoError(t, validate(t, "supervisorconfigs", &configv1alpha1.SupervisorConfig{
+			Spec: configv1alpha1.SupervisorConfigSpec{
+				Log: &configv1alpha1.SupervisorConfigLogSpec{Level: "debug"},
+				Endpoints: &configv1alpha1.SupervisorConfigEndpoints{
+					HTTP: &configv1alpha1.SupervisorConfigEndpoint{Network: "tcp", Address: "127.0.0.1:8080"},
+				},
+			},
+		}))
+		require.EqualError(t, validate(t, "supervisorconfigs", &configv1alpha1.SupervisorConfig{
+			Spec: configv1alpha1.SupervisorConfigSpec{
+				Endpoints: &configv1alpha1.SupervisorConfigEndpoints{
+					HTTP: &configv1alpha1.SupervisorConfigEndpoint{Network: "tcp", Address: ":8080"},
+				},
+			},
+		}), `invalid SupervisorConfig: validate http endpoint: http listener address ":8080" for "tcp" network may only bind to loopback interfaces`)
+	})
+
+	t.Run("identity providers", func(t *testing.T) {
+		for _, tt := range []struct {
+			name    string
+			tlsSpec *idpv1alpha1.TLSSpec
+			wantErr string
+		}{
+			{name: "no TLS spec"},
+			{name: "no CA bundle", tlsSpec: &idpv1alpha1.TLSSpec{}},
+			{name: "valid CA bundle", tlsSpec: &idpv1alpha1.TLSSpec{CertificateAuthorityData: validCABundle}},
+			{
+				name:    "CA bundle which is not base64",
+				tlsSpec: &idpv1alpha1.TLSSpec{CertificateAuthorityData: "!!!"},
+				wantErr: "spec.tls.certificateAuthorityData is invalid: illegal base64 data at input byte 0",
+			},
+			{
+				name:    "CA bundle without certificates",
+				tlsSpec: &idpv1alpha1.TLSSpec{CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte("not a cert"))},
+				wantErr: "spec.tls.certificateAuthorityData is invalid: no certificates found",
+			},
+		} {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				for resource, upstream := range map[string]runtime.Object{
+					"oidcidentityproviders":            &idpv1alpha1.OIDCIdentityProvider{Spec: idpv1alpha1.OIDCIdentityProviderSpec{TLS: tt.tlsSpec}},
+					"ldapidentityproviders":            &idpv1alpha1.LDAPIdentityProvider{Spec: idpv1alpha1.LDAPIdentityProviderSpec{TLS: tt.tlsSpec}},
+					"activedirectoryidentityproviders": &idpv1alpha1.ActiveDirectoryIdentityProvider{Spec: idpv1alpha1.ActiveDirectoryIdentityProviderSpec{TLS: tt.tlsSpec}},
+				} {
+					err := validate(t, resource, upstream)
+					if tt.wantErr != "" {
+						require.EqualError(t, err, tt.wantErr, resource)
+						continue
+					}
+					require.NoError(t, err, resource)
+				}
+			})
+		}
+	})
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package apiserver
@@ -7,6 +7,7 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"net/http"
 	"sync"
 
 	"golang.org/x/crypto/bcrypt"
@@ -20,9 +21,14 @@ import (
 	"k8s.io/client-go/pkg/version"
 
 	configv1alpha1clientset "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
+	"go.pinniped.dev/internal/admissionwebhook"
+	"go.pinniped.dev/internal/auditlog"
 	"go.pinniped.dev/internal/controllerinit"
+	"go.pinniped.dev/internal/crud"
 	"go.pinniped.dev/internal/plog"
 	"go.pinniped.dev/internal/registry/clientsecretrequest"
+	"go.pinniped.dev/internal/registry/downstreamsession"
+	"go.pinniped.dev/internal/registry/secondfactor"
 )
 
 type Config struct {
@@ -35,9 +41,16 @@ type ExtraConfig struct {
 	Scheme                             *runtime.Scheme
 	NegotiatedSerializer               runtime.NegotiatedSerializer
 	ClientSecretSupervisorGroupVersion schema.GroupVersion
+	SessionSupervisorGroupVersion      schema.GroupVersion
 	Secrets                            corev1client.SecretInterface
+	SessionSecrets                     corev1client.SecretInterface // nil means that the sessions are not stored in Secrets
+	SessionEncrypter                   crud.Encrypter               // nil means that the sessions are not encrypted
 	OIDCClients                        configv1alpha1clientset.OIDCClientInterface
 	Namespace                          string
+	AuditLogger                        auditlog.Logger
+
+	// AdmissionWebhook serves the validating admission webhook at admissionwebhook.Path, unless it is nil.
+	AdmissionWebhook http.Handler
 }
 
 type PinnipedServer struct {
@@ -78,7 +91,9 @@ func (c completedConfig) New() (*PinnipedServer, error) {
 		GenericAPIServer: genericServer,
 	}
 
-	var errs []error //nolint:prealloc
+	// Resources of the same group must be installed together, so collect the storage of each group version first.
+	var groupVersions []schema.GroupVersion
+	storageByGroupVersion := map[schema.GroupVersion]map[string]rest.Storage{}
 	for _, f := range []func() (schema.GroupVersionResource, rest.Storage){
 		func() (schema.GroupVersionResource, rest.Storage) {
 			clientSecretReqGVR := c.ExtraConfig.ClientSecretSupervisorGroupVersion.WithResource("oidcclientsecretrequests")
@@ -94,13 +109,44 @@ func (c completedConfig) New() (*PinnipedServer, error) {
 			)
 			return clientSecretReqGVR, clientSecretReqStorage
 		},
+		func() (schema.GroupVersionResource, rest.Storage) {
+			sessionGVR := c.ExtraConfig.SessionSupervisorGroupVersion.WithResource("downstreamsessions")
+			sessionStorage := downstreamsession.NewREST(
+				sessionGVR.GroupResource(),
+				c.ExtraConfig.SessionSecrets,
+				c.ExtraConfig.SessionEncrypter,
+				c.ExtraConfig.Namespace,
+				c.ExtraConfig.AuditLogger,
+			)
+			return sessionGVR, sessionStorage
+		},
+		func() (schema.GroupVersionResource, rest.Storage) {
+			secondFactorGVR := c.ExtraConfig.SessionSupervisorGroupVersion.WithResource("secondfactors")
+			secondFactorStorage := secondfactor.NewREST(
+				secondFactorGVR.GroupResource(),
+				c.ExtraConfig.Secrets,
+				c.ExtraConfig.Namespace,
+				c.ExtraConfig.AuditLogger,
+			)
+			return secondFactorGVR, secondFactorStorage
+		},
 	} {
 		gvr, storage := f()
+		gv := gvr.GroupVersion()
+		if _, ok := storageByGroupVersion[gv]; !ok {
+			groupVersions = append(groupVersions, gv)
+			storageByGroupVersion[gv] = map[string]rest.Storage{}
+		}
+		storageByGroupVersion[gv][gvr.Resource] = storage
+	}
+
+	errs := make([]error, 0, len(groupVersions))
+	for _, gv := range groupVersions {
 		errs = append(errs,
 			s.GenericAPIServer.InstallAPIGroup(
 				&genericapiserver.APIGroupInfo{
-					PrioritizedVersions:          []schema.GroupVersion{gvr.GroupVersion()},
-					VersionedResourcesStorageMap: map[string]map[string]rest.Storage{gvr.Version: {gvr.Resource: storage}},
+					PrioritizedVersions:          []schema.GroupVersion{gv},
+					VersionedResourcesStorageMap: map[string]map[string]rest.Storage{gv.Version: storageByGroupVersion[gv]},
 					OptionsExternalVersion:       &schema.GroupVersion{Version: "v1"},
 					Scheme:                       c.ExtraConfig.Scheme,
 					ParameterCodec:               metav1.ParameterCodec,
@@ -113,6 +159,10 @@ func (c completedConfig) New() (*PinnipedServer, error) {
 		return nil, fmt.Errorf("could not install API groups: %w", err)
 	}
 
+	if c.ExtraConfig.AdmissionWebhook != nil {
+		s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(admissionwebhook.Path, c.ExtraConfig.AdmissionWebhook)
+	}
+
 	shutdown := &sync.WaitGroup{}
 	s.GenericAPIServer.AddPostStartHookOrDie("start-controllers",
 		func(postStartContext genericapiserver.PostStartHookContext) error {
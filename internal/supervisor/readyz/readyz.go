@@ -0,0 +1,123 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package readyz provides the Supervisor's readiness endpoint.
+package readyz
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.pinniped.dev/internal/oidc"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/provider"
+)
+
+// ProvidersGetter returns the currently configured and valid FederationDomains.
+type ProvidersGetter interface {
+	Providers() []*provider.FederationDomainIssuer
+}
+
+// NewHandler returns a handler which reports the Supervisor as ready when at least one FederationDomain is fully
+// configured, i.e. it has a TLS certificate loaded for all of its hosts, its JWKS have been generated, and there is at
+// least one valid upstream identity provider.
+//
+// The readiness of every FederationDomain is listed in the response when the request has a "verbose" query parameter,
+// or when the Supervisor is not ready.
+//
+// The Supervisor is never ready after stopping is closed, so that it stops receiving new requests while it shuts down.
+func NewHandler(
+	providers ProvidersGetter,
+	dynamicJWKSProvider jwks.DynamicJWKSProvider,
+	dynamicTLSCertProvider provider.DynamicTLSCertProvider,
+	upstreamIDPs oidc.UpstreamIdentityProvidersLister,
+	stopping <-chan struct{},
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed (try GET)", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var report strings.Builder
+		ready := false
+
+		shuttingDown := false
+		select {
+		case <-stopping:
+			shuttingDown = true
+			report.WriteString("[-]shutdown failed: the Supervisor is shutting down\n")
+		default:
+		}
+
+		federationDomains := providers.Providers()
+		if len(federationDomains) == 0 {
+			report.WriteString("[-]federationdomains failed: no valid FederationDomains are configured\n")
+		}
+
+		for _, federationDomain := range federationDomains {
+			problems := checkFederationDomain(federationDomain, dynamicJWKSProvider, dynamicTLSCertProvider, upstreamIDPs)
+			if len(problems) == 0 {
+				ready = true
+				_, _ = fmt.Fprintf(&report, "[+]%s ok\n", federationDomain.Issuer())
+				continue
+			}
+			_, _ = fmt.Fprintf(&report, "[-]%s failed: %s\n", federationDomain.Issuer(), strings.Join(problems, ", "))
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		if !ready || shuttingDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintf(w, "%sreadyz check failed\n", report.String())
+			return
+		}
+
+		if _, verbose := r.URL.Query()["verbose"]; verbose {
+			_, _ = fmt.Fprintf(w, "%sreadyz check passed\n", report.String())
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	})
+}
+
+// checkFederationDomain returns a description of each reason that the FederationDomain is not ready.
+func checkFederationDomain(
+	federationDomain *provider.FederationDomainIssuer,
+	dynamicJWKSProvider jwks.DynamicJWKSProvider,
+	dynamicTLSCertProvider provider.DynamicTLSCertProvider,
+	upstreamIDPs oidc.UpstreamIdentityProvidersLister,
+) []string {
+	var problems []string
+
+	issuers := append([]string{federationDomain.Issuer()}, federationDomain.AliasIssuers()...)
+	if previousIssuer := federationDomain.PreviousIssuer(); previousIssuer != "" {
+		issuers = append(issuers, previousIssuer)
+	}
+	for _, issuer := range issuers {
+		issuerURL, _ := url.Parse(issuer) // the issuer has already been validated
+		hostname := strings.ToLower(issuerURL.Hostname())
+		// SNI does not work for IP addresses, so they are always served with the default certificate.
+		hasCert := net.ParseIP(hostname) == nil && dynamicTLSCertProvider.GetTLSCert(hostname) != nil
+		if !hasCert && dynamicTLSCertProvider.GetDefaultTLSCert() == nil {
+			problems = append(problems, fmt.Sprintf("no TLS certificate is loaded for host %q", hostname))
+		}
+	}
+
+	if _, activeJWK := dynamicJWKSProvider.GetJWKS(federationDomain.Issuer()); activeJWK == nil {
+		problems = append(problems, "no signing key has been generated")
+	}
+
+	if len(upstreamIDPs.GetOIDCIdentityProviders())+
+		len(upstreamIDPs.GetLDAPIdentityProviders())+
+		len(upstreamIDPs.GetActiveDirectoryIdentityProviders()) == 0 {
+		problems = append(problems, "no valid upstream identity providers are configured")
+	}
+
+	return problems
+}
@@ -0,0 +1,211 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package readyz
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+
+	"go.pinniped.dev/internal/here"
+	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/testutil/oidctestutil"
+)
+
+type fakeProvidersGetter []*provider.FederationDomainIssuer
+
+func (f fakeProvidersGetter) Providers() []*provider.FederationDomainIssuer { return f }
+
+func TestReadyz(t *testing.T) {
+	const (
+		goodIssuer   = "https://good.example.com/issuer"
+		noCertIssuer = "https://no-cert.example.com/issuer"
+		ipIssuer     = "https://127.0.0.1:8443/issuer"
+	)
+
+	newProvider := func(issuer string, aliasHosts ...string) *provider.FederationDomainIssuer {
+		p, err := provider.NewFederationDomainIssuerWithAliasHosts(issuer, aliasHosts)
+		require.NoError(t, err)
+		return p
+	}
+
+	jwksForIssuers := func(issuers ...string) jwks.DynamicJWKSProvider {
+		jwksProvider := jwks.NewDynamicJWKSProvider()
+		jwksMap := map[string]*jose.JSONWebKeySet{}
+		activeJWKMap := map[string]*jose.JSONWebKey{}
+		for _, issuer := range issuers {
+			jwksMap[issuer] = &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{KeyID: "some-key"}}}
+			activeJWKMap[issuer] = &jose.JSONWebKey{KeyID: "some-key"}
+		}
+		jwksProvider.SetIssuerToJWKSMap(jwksMap, activeJWKMap)
+		return jwksProvider
+	}
+
+	tlsCerts := func(withDefault bool, hostnames ...string) provider.DynamicTLSCertProvider {
+		tlsCertProvider := provider.NewDynamicTLSCertProvider()
+		certs := map[string]*tls.Certificate{}
+		for _, hostname := range hostnames {
+			certs[hostname] = &tls.Certificate{}
+		}
+		tlsCertProvider.SetIssuerHostToTLSCertMap(certs)
+		if withDefault {
+			tlsCertProvider.SetDefaultTLSCert(&tls.Certificate{})
+		}
+		return tlsCertProvider
+	}
+
+	withIDP := oidctestutil.NewUpstreamIDPListerBuilder().
+		WithOIDC(oidctestutil.NewTestUpstreamOIDCIdentityProviderBuilder().WithName("some-oidc-idp").Build())
+	withoutIDPs := oidctestutil.NewUpstreamIDPListerBuilder()
+
+	tests := []struct {
+		name       string
+		providers  []*provider.FederationDomainIssuer
+		jwks       jwks.DynamicJWKSProvider
+		tlsCerts   provider.DynamicTLSCertProvider
+		idps       *oidctestutil.UpstreamIDPListerBuilder
+		stopping   bool
+		method     string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "no FederationDomains",
+			jwks:       jwksForIssuers(),
+			tlsCerts:   tlsCerts(true),
+			idps:       withIDP,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: here.Doc(`
+				[-]federationdomains failed: no valid FederationDomains are configured
+				readyz check failed
+			`),
+		},
+		{
+			name:       "one ready FederationDomain",
+			providers:  []*provider.FederationDomainIssuer{newProvider(goodIssuer, "ALIAS.example.com:1234")},
+			jwks:       jwksForIssuers(goodIssuer),
+			tlsCerts:   tlsCerts(false, "good.example.com", "alias.example.com"),
+			idps:       withIDP,
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			name:       "one ready FederationDomain with verbose output",
+			providers:  []*provider.FederationDomainIssuer{newProvider(goodIssuer), newProvider(noCertIssuer)},
+			jwks:       jwksForIssuers(goodIssuer),
+			tlsCerts:   tlsCerts(false, "good.example.com"),
+			idps:       withIDP,
+			query:      "?verbose",
+			wantStatus: http.StatusOK,
+			wantBody: here.Doc(`
+				[+]https://good.example.com/issuer ok
+				[-]https://no-cert.example.com/issuer failed: no TLS certificate is loaded for host "no-cert.example.com", no signing key has been generated
+				readyz check passed
+			`),
+		},
+		{
+			name:       "FederationDomains use the default TLS certificate, including for IP addresses",
+			providers:  []*provider.FederationDomainIssuer{newProvider(noCertIssuer), newProvider(ipIssuer)},
+			jwks:       jwksForIssuers(noCertIssuer, ipIssuer),
+			tlsCerts:   tlsCerts(true, "127.0.0.1"),
+			idps:       withIDP,
+			query:      "?verbose",
+			wantStatus: http.StatusOK,
+			wantBody: here.Doc(`
+				[+]https://no-cert.example.com/issuer ok
+				[+]https://127.0.0.1:8443/issuer ok
+				readyz check passed
+			`),
+		},
+		{
+			name:       "IP address issuers do not use SNI certificates",
+			providers:  []*provider.FederationDomainIssuer{newProvider(ipIssuer)},
+			jwks:       jwksForIssuers(ipIssuer),
+			tlsCerts:   tlsCerts(false, "127.0.0.1"),
+			idps:       withIDP,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: here.Doc(`
+				[-]https://127.0.0.1:8443/issuer failed: no TLS certificate is loaded for host "127.0.0.1"
+				readyz check failed
+			`),
+		},
+		{
+			name:       "no TLS certificate for an alias host and no upstream identity providers",
+			providers:  []*provider.FederationDomainIssuer{newProvider(goodIssuer, "alias.example.com")},
+			jwks:       jwksForIssuers(goodIssuer),
+			tlsCerts:   tlsCerts(false, "good.example.com"),
+			idps:       withoutIDPs,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: here.Doc(`
+				[-]https://good.example.com/issuer failed: no TLS certificate is loaded for host "alias.example.com", no valid upstream identity providers are configured
+				readyz check failed
+			`),
+		},
+		{
+			name: "no TLS certificate for the previous issuer",
+			providers: []*provider.FederationDomainIssuer{func() *provider.FederationDomainIssuer {
+				p := newProvider(goodIssuer)
+				require.NoError(t, p.SetPreviousIssuer("https://previous.example.com/issuer"))
+				return p
+			}()},
+			jwks:       jwksForIssuers(goodIssuer),
+			tlsCerts:   tlsCerts(false, "good.example.com"),
+			idps:       withIDP,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: here.Doc(`
+				[-]https://good.example.com/issuer failed: no TLS certificate is loaded for host "previous.example.com"
+				readyz check failed
+			`),
+		},
+		{
+			name:       "shutting down",
+			providers:  []*provider.FederationDomainIssuer{newProvider(goodIssuer)},
+			jwks:       jwksForIssuers(goodIssuer),
+			tlsCerts:   tlsCerts(false, "good.example.com"),
+			idps:       withIDP,
+			stopping:   true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: here.Doc(`
+				[-]shutdown failed: the Supervisor is shutting down
+				[+]https://good.example.com/issuer ok
+				readyz check failed
+			`),
+		},
+		{
+			name:       "wrong method",
+			jwks:       jwksForIssuers(),
+			tlsCerts:   tlsCerts(true),
+			idps:       withIDP,
+			method:     http.MethodPost,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "Method not allowed (try GET)\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stopping := make(chan struct{})
+			if tt.stopping {
+				close(stopping)
+			}
+			subject := NewHandler(fakeProvidersGetter(tt.providers), tt.jwks, tt.tlsCerts, tt.idps.Build(), stopping)
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rsp := httptest.NewRecorder()
+			subject.ServeHTTP(rsp, httptest.NewRequest(method, "/readyz"+tt.query, nil))
+
+			require.Equal(t, tt.wantStatus, rsp.Code)
+			require.Equal(t, tt.wantBody, rsp.Body.String())
+		})
+	}
+}
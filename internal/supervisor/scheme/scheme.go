@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package scheme contains code to construct a proper runtime.Scheme for the Supervisor aggregated API.
@@ -9,6 +9,8 @@ import (
 
 	clientsecretapi "go.pinniped.dev/generated/latest/apis/supervisor/clientsecret"
 	clientsecretv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/clientsecret/v1alpha1"
+	sessionapi "go.pinniped.dev/generated/latest/apis/supervisor/session"
+	sessionv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/session/v1alpha1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -20,7 +22,7 @@ import (
 
 // New returns a runtime.Scheme for use by the Supervisor aggregated API running with the provided
 // apiGroupSuffix.
-func New(apiGroupSuffix string) (_ *runtime.Scheme, oauth schema.GroupVersion) {
+func New(apiGroupSuffix string) (_ *runtime.Scheme, clientSecret, session schema.GroupVersion) {
 	// standard set up of the server side scheme
 	scheme := runtime.NewScheme()
 
@@ -32,21 +34,30 @@ func New(apiGroupSuffix string) (_ *runtime.Scheme, oauth schema.GroupVersion) {
 		schemeBuilder := runtime.NewSchemeBuilder(
 			clientsecretv1alpha1.AddToScheme,
 			clientsecretapi.AddToScheme,
+			sessionv1alpha1.AddToScheme,
+			sessionapi.AddToScheme,
 		)
 		utilruntime.Must(schemeBuilder.AddToScheme(scheme))
-		return scheme, clientsecretv1alpha1.SchemeGroupVersion
+		addDownstreamSessionFieldLabelConversions(scheme, sessionv1alpha1.SchemeGroupVersion)
+		addSecondFactorFieldLabelConversions(scheme, sessionv1alpha1.SchemeGroupVersion)
+		return scheme, clientsecretv1alpha1.SchemeGroupVersion, sessionv1alpha1.SchemeGroupVersion
 	}
 
-	clientSecretSupervisorGroupData := groupsuffix.SupervisorAggregatedGroups(apiGroupSuffix)
+	clientSecretSupervisorGroupData, sessionSupervisorGroupData := groupsuffix.SupervisorAggregatedGroups(apiGroupSuffix)
 
 	addToSchemeAtNewGroup(scheme, clientsecretv1alpha1.GroupName, clientSecretSupervisorGroupData.Group, clientsecretv1alpha1.AddToScheme, clientsecretapi.AddToScheme)
+	addToSchemeAtNewGroup(scheme, sessionv1alpha1.GroupName, sessionSupervisorGroupData.Group, sessionv1alpha1.AddToScheme, sessionapi.AddToScheme)
 
 	// manually register conversions and defaulting into the correct scheme since we cannot directly call AddToScheme
 	schemeBuilder := runtime.NewSchemeBuilder(
 		clientsecretv1alpha1.RegisterConversions,
 		clientsecretv1alpha1.RegisterDefaults,
+		sessionv1alpha1.RegisterConversions,
+		sessionv1alpha1.RegisterDefaults,
 	)
 	utilruntime.Must(schemeBuilder.AddToScheme(scheme))
+	addDownstreamSessionFieldLabelConversions(scheme, schema.GroupVersion(sessionSupervisorGroupData))
+	addSecondFactorFieldLabelConversions(scheme, schema.GroupVersion(sessionSupervisorGroupData))
 
 	// we do not have any defaulting functions for *loginv1alpha1.OIDCClientSecretRequest
 	// today, but we may have some in the future.  Calling AddTypeDefaultingFunc overwrites
@@ -56,7 +67,38 @@ func New(apiGroupSuffix string) (_ *runtime.Scheme, oauth schema.GroupVersion) {
 	// defaulting func registered, but it will almost certainly panic if one is added.
 	scheme.Default((*clientsecretv1alpha1.OIDCClientSecretRequest)(nil))
 
-	return scheme, schema.GroupVersion(clientSecretSupervisorGroupData)
+	return scheme, schema.GroupVersion(clientSecretSupervisorGroupData), schema.GroupVersion(sessionSupervisorGroupData)
+}
+
+// addDownstreamSessionFieldLabelConversions allows DownstreamSessions to be listed and deleted using field selectors,
+// e.g. to revoke all sessions of a user with `kubectl delete downstreamsessions --field-selector status.username=foo`.
+func addDownstreamSessionFieldLabelConversions(scheme *runtime.Scheme, gv schema.GroupVersion) {
+	utilruntime.Must(scheme.AddFieldLabelConversionFunc(gv.WithKind("DownstreamSession"),
+		func(label, value string) (string, string, error) {
+			switch label {
+			case "metadata.name", "metadata.namespace",
+				"status.username", "status.identityProviderName", "status.identityProviderType", "status.clientID":
+				return label, value, nil
+			default:
+				return "", "", fmt.Errorf("field label not supported: %s", label)
+			}
+		},
+	))
+}
+
+// addSecondFactorFieldLabelConversions allows SecondFactors to be listed and deleted using field selectors,
+// e.g. to reset the second factors of a user with `kubectl delete secondfactors --field-selector status.username=foo`.
+func addSecondFactorFieldLabelConversions(scheme *runtime.Scheme, gv schema.GroupVersion) {
+	utilruntime.Must(scheme.AddFieldLabelConversionFunc(gv.WithKind("SecondFactor"),
+		func(label, value string) (string, string, error) {
+			switch label {
+			case "metadata.name", "metadata.namespace", "status.username":
+				return label, value, nil
+			default:
+				return "", "", fmt.Errorf("field label not supported: %s", label)
+			}
+		},
+	))
 }
 
 func addToSchemeAtNewGroup(scheme *runtime.Scheme, oldGroup, newGroup string, funcs ...func(*runtime.Scheme) error) {
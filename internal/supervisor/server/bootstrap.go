@@ -49,10 +49,10 @@ func withBootstrapPaths(handler http.Handler, paths ...string) http.Handler {
 	})
 }
 
-func getBootstrapCert() (*tls.Certificate, error) {
+func getBootstrapCert(keyAlgorithm certauthority.KeyAlgorithm) (*tls.Certificate, error) {
 	const forever = 10 * 365 * 24 * time.Hour
 
-	bootstrapCA, err := certauthority.New("pinniped-supervisor-bootstrap-ca", forever)
+	bootstrapCA, err := certauthority.New("pinniped-supervisor-bootstrap-ca", forever, certauthority.WithKeyAlgorithm(keyAlgorithm))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bootstrap CA: %w", err)
 	}
@@ -0,0 +1,160 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	"go.pinniped.dev/internal/config/supervisor"
+	"go.pinniped.dev/internal/doctor"
+	"go.pinniped.dev/internal/downward"
+	"go.pinniped.dev/internal/groupsuffix"
+	"go.pinniped.dev/internal/kubeclient"
+)
+
+const (
+	defaultLDAPSPort = 636
+	defaultRedisPort = 6379
+)
+
+// runDoctor checks whether the Supervisor can run with its configuration in its cluster, and writes a readiness
+// report to stdout. It returns an error when the Supervisor is not ready, so that the exit code can be checked.
+func runDoctor(ctx context.Context, podInfoPath, configPath string, stdout io.Writer) error {
+	report := doctor.NewReport("pinniped-supervisor")
+	doctorChecks(ctx, report, podInfoPath, configPath)
+
+	if err := report.Write(stdout); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	if !report.Ready {
+		return errors.New("not ready, see the failed checks in the report")
+	}
+	return nil
+}
+
+func doctorChecks(ctx context.Context, report *doctor.Report, podInfoPath, configPath string) {
+	podInfo, err := downward.Load(podInfoPath)
+	report.Add(doctor.FromError("pod metadata", err, "loaded "+podInfoPath))
+
+	cfg, err := supervisor.ReloadFromPath(configPath)
+	report.Add(doctor.FromError("configuration", err, "loaded "+configPath))
+
+	if cfg == nil || podInfo == nil {
+		return // the remaining checks depend on the configuration and the namespace
+	}
+
+	client, err := kubeclient.New(
+		kubeclient.WithMiddleware(groupsuffix.New(*cfg.APIGroupSuffix)),
+		kubeclient.WithRateLimit(cfg.KubeClient),
+	)
+	report.Add(doctor.FromError("kubernetes client", err, "created"))
+	if err != nil {
+		return
+	}
+
+	configGroup, _ := groupsuffix.Replace(configv1alpha1.GroupName, *cfg.APIGroupSuffix)
+	idpGroup, _ := groupsuffix.Replace(idpv1alpha1.GroupName, *cfg.APIGroupSuffix)
+	clientSecretGroupData, sessionGroupData := groupsuffix.SupervisorAggregatedGroups(*cfg.APIGroupSuffix)
+	namespace := podInfo.Namespace
+
+	report.Run(ctx,
+		doctor.APIServer(client.Kubernetes.Discovery()),
+		doctor.APIGroups(client.Kubernetes.Discovery(), configGroup, idpGroup),
+		doctor.APIService(client.Aggregation, clientSecretGroupData.APIServiceName()),
+		doctor.APIService(client.Aggregation, sessionGroupData.APIServiceName()),
+		doctor.Permissions(client.Kubernetes, supervisorPermissions(namespace, configGroup, idpGroup)),
+		doctor.ConfigMap(client.Kubernetes, "kube-system", "extension-apiserver-authentication"),
+	)
+	if cfg.NamesConfig.ConfigMap != "" {
+		report.Run(ctx, doctor.ConfigMap(client.Kubernetes, namespace, cfg.NamesConfig.ConfigMap))
+	}
+	if cfg.NamesConfig.DefaultTLSCertificateSecret != "" {
+		// Without the default TLS certificate, only the issuers which have their own TLS certificate can be served.
+		report.Run(ctx, doctor.Secret(client.Kubernetes, namespace, cfg.NamesConfig.DefaultTLSCertificateSecret, false))
+	}
+	if redis := cfg.SessionStorage.Redis; cfg.SessionStorage.Type == supervisor.SessionStorageTypeRedis && redis != nil {
+		if redis.SecretName != "" {
+			report.Run(ctx, doctor.Secret(client.Kubernetes, namespace, redis.SecretName, true))
+		}
+		report.Run(ctx, doctor.Reachable("redis session storage", redis.Address, defaultRedisPort))
+	}
+	if cfg.AuditLog != nil && cfg.AuditLog.Webhook != nil {
+		report.Run(ctx, doctor.Reachable("audit log webhook", cfg.AuditLog.Webhook.URL, 443))
+	}
+	if cfg.Tracing != nil {
+		report.Run(ctx, doctor.Reachable("tracing collector", cfg.Tracing.Endpoint, supervisor.TracingEndpointPortDefault))
+	}
+
+	idps := client.PinnipedSupervisor.IDPV1alpha1()
+
+	oidcIDPs, err := idps.OIDCIdentityProviders(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		report.Add(doctor.FromError("OIDCIdentityProviders", fmt.Errorf("cannot list OIDCIdentityProviders: %w", err), ""))
+	} else {
+		for _, idp := range oidcIDPs.Items {
+			report.Run(ctx, doctor.Reachable("OIDCIdentityProvider "+idp.Name, idp.Spec.Issuer, 443))
+		}
+	}
+
+	ldapIDPs, err := idps.LDAPIdentityProviders(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		report.Add(doctor.FromError("LDAPIdentityProviders", fmt.Errorf("cannot list LDAPIdentityProviders: %w", err), ""))
+	} else {
+		for _, idp := range ldapIDPs.Items {
+			report.Run(ctx, doctor.Reachable("LDAPIdentityProvider "+idp.Name, idp.Spec.Host, defaultLDAPSPort))
+		}
+	}
+
+	adIDPs, err := idps.ActiveDirectoryIdentityProviders(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		report.Add(doctor.FromError("ActiveDirectoryIdentityProviders", fmt.Errorf("cannot list ActiveDirectoryIdentityProviders: %w", err), ""))
+	} else {
+		for _, idp := range adIDPs.Items {
+			report.Run(ctx, doctor.Reachable("ActiveDirectoryIdentityProvider "+idp.Name, idp.Spec.Host, defaultLDAPSPort))
+		}
+	}
+}
+
+// supervisorPermissions returns the permissions which the Supervisor needs, which are granted by the RBAC resources
+// of its deployment.
+func supervisorPermissions(namespace, configGroup, idpGroup string) []authorizationv1.ResourceAttributes {
+	var permissions []authorizationv1.ResourceAttributes
+	for _, rule := range [][]authorizationv1.ResourceAttributes{
+		doctor.Rule("", "", "namespaces", "get", "list", "watch"),
+		doctor.Rule("", "apiregistration.k8s.io", "apiservices", "get", "list", "update", "watch"),
+		doctor.Rule("", "admissionregistration.k8s.io", "validatingwebhookconfigurations", "get", "list", "watch"),
+		doctor.Rule("", "authentication.k8s.io", "tokenreviews", "create"),
+		doctor.Rule("", "authorization.k8s.io", "subjectaccessreviews", "create"),
+		doctor.Rule("", configGroup, "supervisorconfigs", "get", "list", "watch"),
+		doctor.Rule("", configGroup, "supervisorconfigs/status", "update"),
+		doctor.Rule(namespace, "", "secrets", "create", "get", "list", "update", "watch", "delete"),
+		doctor.Rule(namespace, configGroup, "federationdomains", "get", "list", "watch"),
+		doctor.Rule(namespace, configGroup, "federationdomains/status", "update"),
+		doctor.Rule(namespace, configGroup, "oidcclients", "get", "list", "watch"),
+		doctor.Rule(namespace, configGroup, "oidcclients/status", "update"),
+		doctor.Rule(namespace, idpGroup, "oidcidentityproviders", "get", "list", "watch"),
+		doctor.Rule(namespace, idpGroup, "oidcidentityproviders/status", "update"),
+		doctor.Rule(namespace, idpGroup, "ldapidentityproviders", "get", "list", "watch"),
+		doctor.Rule(namespace, idpGroup, "ldapidentityproviders/status", "update"),
+		doctor.Rule(namespace, idpGroup, "activedirectoryidentityproviders", "get", "list", "watch"),
+		doctor.Rule(namespace, idpGroup, "activedirectoryidentityproviders/status", "update"),
+		doctor.Rule(namespace, "events.k8s.io", "events", "create"),
+		doctor.Rule(namespace, "", "pods", "get"),
+		doctor.Rule(namespace, "apps", "replicasets", "get"),
+		doctor.Rule(namespace, "apps", "deployments", "get"),
+		doctor.Rule(namespace, "", "configmaps", "get", "list", "watch"),
+		doctor.Rule(namespace, "coordination.k8s.io", "leases", "create", "get", "update"),
+	} {
+		permissions = append(permissions, rule...)
+	}
+	return permissions
+}
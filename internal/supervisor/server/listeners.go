@@ -0,0 +1,176 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	configv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/config/v1alpha1"
+	"go.pinniped.dev/internal/config/supervisor"
+	"go.pinniped.dev/internal/plog"
+)
+
+// listenerKey identifies a listener by whether it serves HTTPS and by its socket.
+type listenerKey struct {
+	https   bool
+	network string
+	address string
+}
+
+type runningListener struct {
+	listener net.Listener
+	cancel   context.CancelFunc
+}
+
+// listenerSet runs the HTTP and HTTPS listeners of the Supervisor, and starts and stops them when the endpoints change.
+type listenerSet struct {
+	ctx             context.Context
+	stopping        <-chan struct{}
+	shutdownTimeout time.Duration
+	shutdown        *sync.WaitGroup
+	handler         http.Handler
+	httpsConfig     *tls.Config
+	pod             *corev1.Pod
+
+	mu      sync.Mutex
+	running map[listenerKey]runningListener
+}
+
+// reconcile stops the listeners which are not in the endpoints anymore, and then starts the ones which are not running
+// yet. Listeners which are already running keep serving their connections.
+func (s *listenerSet) reconcile(endpoints *supervisor.Endpoints) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running == nil {
+		s.running = map[listenerKey]runningListener{}
+	}
+
+	var wanted []listenerKey
+	for _, e := range endpoints.HTTP.Listeners() {
+		wanted = append(wanted, listenerKey{https: false, network: e.Network, address: e.Address})
+	}
+	for _, e := range endpoints.HTTPS.Listeners() {
+		wanted = append(wanted, listenerKey{https: true, network: e.Network, address: e.Address})
+	}
+
+	isWanted := make(map[listenerKey]bool, len(wanted))
+	for _, key := range wanted {
+		isWanted[key] = true
+	}
+	for key, r := range s.running {
+		if isWanted[key] {
+			continue
+		}
+		// Close the listener right away so that its socket can be reused by the new listeners, while its
+		// connections get up to the shutdown timeout to finish their requests.
+		_ = r.listener.Close()
+		r.cancel()
+		delete(s.running, key)
+		plog.Info("supervisor listener stopped", "https", key.https, "network", key.network, "address", key.address)
+	}
+
+	for _, key := range wanted {
+		if _, ok := s.running[key]; ok {
+			continue
+		}
+		if err := s.start(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *listenerSet) start(key listenerKey) error {
+	e := supervisor.Endpoint{Network: key.network, Address: key.address}
+	protocol := "http"
+	if key.https {
+		protocol = "https"
+	}
+
+	finishSetupPerms := maybeSetupUnixPerms(&e, s.pod)
+
+	var l net.Listener
+	var err error
+	if key.https {
+		l, err = tls.Listen(e.Network, e.Address, s.httpsConfig)
+	} else {
+		l, err = net.Listen(e.Network, e.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot create %s listener with network %q and address %q: %w", protocol, e.Network, e.Address, err)
+	}
+
+	if err := finishSetupPerms(); err != nil {
+		_ = l.Close()
+		return fmt.Errorf("cannot setup %s listener permissions for network %q and address %q: %w", protocol, e.Network, e.Address, err)
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	startServer(ctx, s.stopping, s.shutdownTimeout, s.shutdown, l, s.handler)
+	s.running[key] = runningListener{listener: l, cancel: cancel}
+	plog.Debug("supervisor "+protocol+" listener started", "address", l.Addr().String())
+	return nil
+}
+
+// close closes all listeners.
+func (s *listenerSet) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.running {
+		_ = r.listener.Close()
+	}
+}
+
+// runtimeSettings applies the settings which can be changed while running, i.e. the settings of the static
+// configuration, which can be reloaded, overridden by the settings of the SupervisorConfig, if any.
+type runtimeSettings struct {
+	mu        sync.Mutex
+	static    *supervisor.Config
+	overrides *configv1alpha1.SupervisorConfigSpec
+	apply     func(*supervisor.Config) error
+}
+
+// ApplySupervisorConfig implements supervisorconfig.SupervisorConfigApplier.
+func (s *runtimeSettings) ApplySupervisorConfig(spec *configv1alpha1.SupervisorConfigSpec) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.applyLocked(s.static, spec)
+}
+
+// setStatic applies a static configuration which was loaded or reloaded, keeping the current overrides.
+func (s *runtimeSettings) setStatic(cfg *supervisor.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.applyLocked(cfg, s.overrides)
+}
+
+func (s *runtimeSettings) applyLocked(static *supervisor.Config, overrides *configv1alpha1.SupervisorConfigSpec) error {
+	cfg := static
+	if overrides != nil {
+		var err error
+		cfg, err = static.WithSupervisorConfig(*overrides)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.apply(cfg); err != nil {
+		return err
+	}
+
+	s.static, s.overrides = static, overrides
+	return nil
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package server defines the entrypoint for the Pinniped Supervisor server.
@@ -8,6 +8,8 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"net/http"
@@ -16,12 +18,16 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/joshlf/go-acl"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/square/go-jose.v2"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
@@ -33,8 +39,10 @@ import (
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/pkg/version"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
 	aggregatorclient "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
 	"k8s.io/utils/clock"
 
@@ -43,9 +51,13 @@ import (
 	"go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/typed/config/v1alpha1"
 	pinnipedinformers "go.pinniped.dev/generated/latest/client/supervisor/informers/externalversions"
 	supervisoropenapi "go.pinniped.dev/generated/latest/client/supervisor/openapi"
+	"go.pinniped.dev/internal/admissionwebhook"
 	"go.pinniped.dev/internal/apiserviceref"
+	"go.pinniped.dev/internal/auditlog"
 	"go.pinniped.dev/internal/config/supervisor"
+	"go.pinniped.dev/internal/configreload"
 	"go.pinniped.dev/internal/controller/apicerts"
+	"go.pinniped.dev/internal/controller/loglevel"
 	"go.pinniped.dev/internal/controller/supervisorconfig"
 	"go.pinniped.dev/internal/controller/supervisorconfig/activedirectoryupstreamwatcher"
 	"go.pinniped.dev/internal/controller/supervisorconfig/generator"
@@ -55,30 +67,64 @@ import (
 	"go.pinniped.dev/internal/controller/supervisorstorage"
 	"go.pinniped.dev/internal/controllerinit"
 	"go.pinniped.dev/internal/controllerlib"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/crypto/fips"
 	"go.pinniped.dev/internal/crypto/ptls"
 	"go.pinniped.dev/internal/deploymentref"
 	"go.pinniped.dev/internal/downward"
 	"go.pinniped.dev/internal/dynamiccert"
+	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/externalsigner"
 	"go.pinniped.dev/internal/groupsuffix"
+	"go.pinniped.dev/internal/httputil/clientip"
 	"go.pinniped.dev/internal/kubeclient"
 	"go.pinniped.dev/internal/leaderelection"
+	"go.pinniped.dev/internal/metricsendpoint"
+	"go.pinniped.dev/internal/oidc"
 	"go.pinniped.dev/internal/oidc/jwks"
+	"go.pinniped.dev/internal/oidc/lockout"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/oidc/provider/manager"
 	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/profiling"
+	"go.pinniped.dev/internal/redisstorage"
 	"go.pinniped.dev/internal/secret"
+	"go.pinniped.dev/internal/sessionencryption"
+	"go.pinniped.dev/internal/supervisor/admission"
 	"go.pinniped.dev/internal/supervisor/apiserver"
+	"go.pinniped.dev/internal/supervisor/readyz"
 	supervisorscheme "go.pinniped.dev/internal/supervisor/scheme"
+	"go.pinniped.dev/internal/tracing"
 )
 
 const (
 	singletonWorker       = 1
 	defaultResyncInterval = 3 * time.Minute
+
+	// jwksWriterWorkers bounds how many FederationDomains get their signing keys generated and written to
+	// Secrets concurrently, so that supervisors with many FederationDomains become ready faster.
+	jwksWriterWorkers = 8
+
+	// sessionKeyEncryptionKeyPreviousKeyLifespan is how long the previous session key encryption key remains usable
+	// after a rotation. The session encryption key is rewrapped within a resync of the informers, so this only needs
+	// to cover pods which are temporarily unable to read or update the Secrets.
+	sessionKeyEncryptionKeyPreviousKeyLifespan = 24 * time.Hour
 )
 
-func startServer(ctx context.Context, shutdown *sync.WaitGroup, l net.Listener, handler http.Handler) {
+// startServer serves requests on l until ctx is cancelled. Once stopping is closed, each connection is closed after its
+// current request, so that clients open new connections which can be routed to other pods. When ctx is cancelled,
+// either because the Supervisor is stopping or because only this listener is stopped, the listener is closed and
+// in-flight requests have up to shutdownTimeout to finish.
+func startServer(
+	ctx context.Context,
+	stopping <-chan struct{},
+	shutdownTimeout time.Duration,
+	shutdown *sync.WaitGroup,
+	l net.Listener,
+	handler http.Handler,
+) {
 	handler = genericapifilters.WithWarningRecorder(handler)
-	handler = withBootstrapPaths(handler, "/healthz") // only health checks are allowed for bootstrap connections
+	handler = withBootstrapPaths(handler, "/healthz", "/readyz") // only health checks are allowed for bootstrap connections
 
 	server := http.Server{
 		Handler:           handler,
@@ -98,11 +144,16 @@ func startServer(ctx context.Context, shutdown *sync.WaitGroup, l net.Listener,
 	go func() {
 		defer shutdown.Done()
 
-		<-ctx.Done()
+		select {
+		case <-stopping:
+			server.SetKeepAlivesEnabled(false)
+			<-ctx.Done()
+		case <-ctx.Done(): // the listener was stopped while the Supervisor keeps running
+		}
 		plog.Debug("server context cancelled", "err", ctx.Err())
 
-		// allow up to a minute grace period for active connections to return to idle
-		connectionsCtx, connectionsCancel := context.WithTimeout(context.Background(), time.Minute)
+		// allow in-flight requests to finish and active connections to return to idle
+		connectionsCtx, connectionsCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer connectionsCancel()
 
 		if err := server.Shutdown(connectionsCtx); err != nil {
@@ -111,6 +162,20 @@ func startServer(ctx context.Context, shutdown *sync.WaitGroup, l net.Listener,
 	}()
 }
 
+// delayCancel returns a context which is cancelled delay after parent is cancelled.
+func delayCancel(parent context.Context, delay time.Duration) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+
+		<-parent.Done()
+		plog.Info("draining connections before shutting down", "drainDelay", delay.String())
+		time.Sleep(delay)
+	}()
+
+	return ctx
+}
+
 func signalCtx() context.Context {
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
@@ -129,8 +194,10 @@ func signalCtx() context.Context {
 //nolint:funlen
 func prepareControllers(
 	cfg *supervisor.Config,
+	supervisorConfigApplier supervisorconfig.SupervisorConfigApplier,
 	issuerManager *manager.Manager,
 	dynamicJWKSProvider jwks.DynamicJWKSProvider,
+	externalIDTokenJWK *jose.JSONWebKey,
 	dynamicTLSCertProvider provider.DynamicTLSCertProvider,
 	dynamicUpstreamIDPProvider provider.DynamicUpstreamIDPProvider,
 	dynamicServingCertProvider dynamiccert.Private,
@@ -143,9 +210,10 @@ func prepareControllers(
 	pinnipedInformers pinnipedinformers.SharedInformerFactory,
 	leaderElector controllerinit.RunnerWrapper,
 	podInfo *downward.PodInfo,
+	sessionEncryption *sessionEncryption,
 ) controllerinit.RunnerBuilder {
 	const certificateName string = "pinniped-supervisor-api-tls-serving-certificate"
-	clientSecretSupervisorGroupData := groupsuffix.SupervisorAggregatedGroups(*cfg.APIGroupSuffix)
+	clientSecretSupervisorGroupData, sessionSupervisorGroupData := groupsuffix.SupervisorAggregatedGroups(*cfg.APIGroupSuffix)
 	federationDomainInformer := pinnipedInformers.Config().V1alpha1().FederationDomains()
 	oidcClientInformer := pinnipedInformers.Config().V1alpha1().OIDCClients()
 	secretInformer := kubeInformers.Core().V1().Secrets()
@@ -153,12 +221,15 @@ func prepareControllers(
 	// Create controller manager.
 	controllerManager := controllerlib.
 		NewManager().
+		WithTuning(cfg.Controllers).
+		WithEventBroadcaster(events.NewEventBroadcasterAdapter(kubeClient), "pinniped-supervisor").
 		WithController(
 			supervisorstorage.GarbageCollectorController(
 				dynamicUpstreamIDPProvider,
 				clock.RealClock{},
 				kubeClient,
 				secretInformer,
+				garbageCollectorConfig(cfg.SessionGarbageCollection, sessionEncryption.encrypter()),
 				controllerlib.WithInformer,
 			),
 			singletonWorker,
@@ -180,13 +251,15 @@ func prepareControllers(
 				pinnipedClient,
 				secretInformer,
 				federationDomainInformer,
+				jwksWriterWorkers,
 				controllerlib.WithInformer,
 			),
-			singletonWorker,
+			jwksWriterWorkers,
 		).
 		WithController(
 			supervisorconfig.NewJWKSObserverController(
 				dynamicJWKSProvider,
+				externalIDTokenJWK,
 				secretInformer,
 				federationDomainInformer,
 				controllerlib.WithInformer,
@@ -196,7 +269,17 @@ func prepareControllers(
 		WithController(
 			supervisorconfig.NewTLSCertObserverController(
 				dynamicTLSCertProvider,
-				cfg.NamesConfig.DefaultTLSCertificateSecret,
+				defaultTLSCertificateSecretName(cfg),
+				secretInformer,
+				federationDomainInformer,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		).
+		WithController(
+			supervisorconfig.NewFederationDomainTLSStatusController(
+				pinnipedClient,
+				clock.RealClock{},
 				secretInformer,
 				federationDomainInformer,
 				controllerlib.WithInformer,
@@ -209,9 +292,30 @@ func prepareControllers(
 				cfg.Labels,
 				kubeClient,
 				secretInformer,
-				func(secret []byte) {
+				generator.SupervisorCSRFSigningKeySecretType,
+				"-key",
+				func(secret, previousSecret []byte) {
 					plog.Debug("setting csrf cookie secret")
 					secretCache.SetCSRFCookieEncoderHashKey(secret)
+					secretCache.SetPreviousCSRFCookieEncoderHashKey(previousSecret)
+				},
+				controllerlib.WithInformer,
+				controllerlib.WithInitialEvent,
+			),
+			singletonWorker,
+		).
+		// The TOTP encryption key is never rotated, because the TOTP secrets of users could not be decrypted anymore.
+		WithController(
+			generator.NewSupervisorSecretsController(
+				supervisorDeployment,
+				cfg.Labels,
+				kubeClient,
+				secretInformer,
+				generator.SupervisorTOTPEncryptionKeySecretType,
+				"-totp-encryption-key",
+				func(secret, _ []byte) {
+					plog.Debug("setting totp encryption key")
+					secretCache.SetTOTPEncryptionKey(secret)
 				},
 				controllerlib.WithInformer,
 				controllerlib.WithInitialEvent,
@@ -225,7 +329,7 @@ func prepareControllers(
 					cfg.Labels,
 					rand.Reader,
 					generator.SecretUsageTokenSigningKey,
-					func(federationDomainIssuer string, symmetricKey []byte) {
+					func(federationDomainIssuer string, symmetricKey, _ []byte) {
 						plog.Debug("setting hmac secret", "issuer", federationDomainIssuer)
 						secretCache.SetTokenHMACKey(federationDomainIssuer, symmetricKey)
 					},
@@ -248,9 +352,10 @@ func prepareControllers(
 					cfg.Labels,
 					rand.Reader,
 					generator.SecretUsageStateSigningKey,
-					func(federationDomainIssuer string, symmetricKey []byte) {
+					func(federationDomainIssuer string, symmetricKey, previousSymmetricKey []byte) {
 						plog.Debug("setting state signature key", "issuer", federationDomainIssuer)
 						secretCache.SetStateEncoderHashKey(federationDomainIssuer, symmetricKey)
+						secretCache.SetPreviousStateEncoderHashKey(federationDomainIssuer, previousSymmetricKey)
 					},
 				),
 				func(fd *configv1alpha1.FederationDomainStatus) *corev1.LocalObjectReference {
@@ -271,7 +376,7 @@ func prepareControllers(
 					cfg.Labels,
 					rand.Reader,
 					generator.SecretUsageStateEncryptionKey,
-					func(federationDomainIssuer string, symmetricKey []byte) {
+					func(federationDomainIssuer string, symmetricKey, _ []byte) {
 						plog.Debug("setting state encryption key", "issuer", federationDomainIssuer)
 						secretCache.SetStateEncoderBlockKey(federationDomainIssuer, symmetricKey)
 					},
@@ -287,6 +392,41 @@ func prepareControllers(
 			),
 			singletonWorker,
 		).
+		// The symmetric key rotators periodically rotate the signing keys of the CSRF cookies and of the upstream
+		// state parameters, while the previous keys remain accepted until the values which they signed expire.
+		WithController(
+			generator.NewSymmetricKeyRotatorController(
+				"csrf-cookie-signing-key-rotator",
+				func(obj metav1.Object) bool {
+					secret, ok := obj.(*corev1.Secret)
+					return ok && secret.Type == generator.SupervisorCSRFSigningKeySecretType
+				},
+				kubeClient,
+				secretInformer,
+				controllerlib.WithInformer,
+				time.Duration(*cfg.KeyRotation.PeriodSeconds)*time.Second,
+				oidc.CSRFCookieLifespan,
+				clock.RealClock{},
+				rand.Reader,
+			),
+			singletonWorker,
+		).
+		WithController(
+			generator.NewSymmetricKeyRotatorController(
+				"upstream-state-signing-key-rotator",
+				func(obj metav1.Object) bool {
+					return generator.IsFederationDomainSecretOfType(obj, generator.FederationDomainStateSigningKeyType)
+				},
+				kubeClient,
+				secretInformer,
+				controllerlib.WithInformer,
+				time.Duration(*cfg.KeyRotation.PeriodSeconds)*time.Second,
+				oidc.DefaultOIDCTimeoutsConfiguration().UpstreamStateParamLifespan,
+				clock.RealClock{},
+				rand.Reader,
+			),
+			singletonWorker,
+		).
 		WithController(
 			oidcupstreamwatcher.New(
 				dynamicUpstreamIDPProvider,
@@ -324,7 +464,9 @@ func prepareControllers(
 				secretInformer,
 				controllerlib.WithInformer,
 				controllerlib.WithInitialEvent,
-				365*24*time.Hour, // about one year
+				time.Duration(*cfg.Certificates.AggregatedAPIServing.DurationSeconds)*time.Second,
+				cfg.Certificates.KeyAlgorithm,
+				nil, // the CA of the aggregated API serving cert always has its own private key
 				"Pinniped Supervisor Aggregation CA",
 				cfg.NamesConfig.APIService,
 			),
@@ -341,6 +483,17 @@ func prepareControllers(
 			),
 			singletonWorker,
 		).
+		WithController(
+			apicerts.NewAPIServiceUpdaterController(
+				podInfo.Namespace,
+				certificateName,
+				sessionSupervisorGroupData.APIServiceName(),
+				aggregatorClient,
+				secretInformer,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		).
 		WithController(
 			apicerts.NewCertsObserverController(
 				podInfo.Namespace,
@@ -358,7 +511,7 @@ func prepareControllers(
 				kubeClient,
 				secretInformer,
 				controllerlib.WithInformer,
-				9*30*24*time.Hour, // about 9 months
+				time.Duration(*cfg.Certificates.AggregatedAPIServing.RenewBeforeSeconds)*time.Second,
 				apicerts.TLSCertificateChainSecretKey,
 				plog.New(),
 			),
@@ -374,13 +527,123 @@ func prepareControllers(
 			singletonWorker,
 		)
 
+	// The webhook configuration updater controller is only needed when the admission webhook is enabled.
+	if cfg.NamesConfig.ValidatingWebhookConfiguration != "" {
+		controllerManager = controllerManager.WithController(
+			apicerts.NewWebhookConfigurationUpdaterController(
+				podInfo.Namespace,
+				certificateName,
+				cfg.NamesConfig.ValidatingWebhookConfiguration,
+				kubeClient,
+				secretInformer,
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		)
+	}
+
+	// The SupervisorConfig watcher is only needed when a SupervisorConfig is configured.
+	if cfg.NamesConfig.SupervisorConfig != "" {
+		controllerManager = controllerManager.WithController(
+			supervisorconfig.NewSupervisorConfigWatcherController(
+				cfg.NamesConfig.SupervisorConfig,
+				supervisorConfigApplier,
+				pinnipedClient,
+				pinnipedInformers.Config().V1alpha1().SupervisorConfigs(),
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		)
+	}
+
+	// The session encryption key controllers are only needed when the sessions are encrypted. The session encryption key
+	// is never rotated, because the existing sessions could not be decrypted anymore, but the generated key encryption
+	// key which wraps it is rotated, while the previous key remains accepted until the session encryption key was
+	// rewrapped.
+	if sessionEncryption != nil {
+		if localKEK := sessionEncryption.localKEK; localKEK != nil {
+			controllerManager = controllerManager.
+				WithController(
+					generator.NewSupervisorSecretsController(
+						supervisorDeployment,
+						cfg.Labels,
+						kubeClient,
+						secretInformer,
+						generator.SupervisorSessionKeyEncryptionKeySecretType,
+						"-session-key-encryption-key",
+						func(secret, previousSecret []byte) {
+							plog.Debug("setting session key encryption key")
+							localKEK.Set(secret, previousSecret)
+						},
+						controllerlib.WithInformer,
+						controllerlib.WithInitialEvent,
+					),
+					singletonWorker,
+				).
+				WithController(
+					generator.NewSymmetricKeyRotatorController(
+						"session-key-encryption-key-rotator",
+						func(obj metav1.Object) bool {
+							secret, ok := obj.(*corev1.Secret)
+							return ok && secret.Type == generator.SupervisorSessionKeyEncryptionKeySecretType
+						},
+						kubeClient,
+						secretInformer,
+						controllerlib.WithInformer,
+						time.Duration(*cfg.KeyRotation.PeriodSeconds)*time.Second,
+						sessionKeyEncryptionKeyPreviousKeyLifespan,
+						clock.RealClock{},
+						rand.Reader,
+					),
+					singletonWorker,
+				)
+		}
+		controllerManager = controllerManager.WithController(
+			generator.NewSessionEncryptionKeyController(
+				supervisorDeployment,
+				cfg.Labels,
+				kubeClient,
+				secretInformer,
+				sessionEncryption.kek,
+				sessionEncryption.keyring,
+				rand.Reader,
+				controllerlib.WithInformer,
+				controllerlib.WithInitialEvent,
+			),
+			singletonWorker,
+		)
+	}
+
+	// The log level controller is only needed when the ConfigMap which holds our configuration is known, and the log
+	// levels are not overridden by a SupervisorConfig.
+	if cfg.NamesConfig.ConfigMap != "" && cfg.NamesConfig.SupervisorConfig == "" {
+		controllerManager = controllerManager.WithController(
+			loglevel.NewLogLevelController(
+				podInfo.Namespace,
+				cfg.NamesConfig.ConfigMap,
+				cfg.Log,
+				kubeInformers.Core().V1().ConfigMaps(),
+				controllerlib.WithInformer,
+			),
+			singletonWorker,
+		)
+	}
+
 	return controllerinit.Prepare(controllerManager.Start, leaderElector, kubeInformers, pinnipedInformers)
 }
 
 //nolint:funlen
-func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervisor.Config) error {
+func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervisor.Config, configPath string) error {
+	drainDelay, shutdownTimeout := shutdownConfig(cfg.Shutdown)
+
+	// The signal ctx is cancelled as soon as the Supervisor is asked to stop. Keep everything running for the drain
+	// delay, while the /readyz endpoint fails, so that new requests stop being routed to this pod before it stops
+	// listening. Everything else uses the delayed ctx to decide when to stop.
+	stopping := ctx.Done()
+	ctx = delayCancel(ctx, drainDelay)
+
 	serverInstallationNamespace := podInfo.Namespace
-	clientSecretSupervisorGroupData := groupsuffix.SupervisorAggregatedGroups(*cfg.APIGroupSuffix)
+	clientSecretSupervisorGroupData, _ := groupsuffix.SupervisorAggregatedGroups(*cfg.APIGroupSuffix)
 
 	apiServiceRef, err := apiserviceref.New(clientSecretSupervisorGroupData.APIServiceName())
 	if err != nil {
@@ -396,6 +659,8 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		dref,
 		apiServiceRef,
 		kubeclient.WithMiddleware(groupsuffix.New(*cfg.APIGroupSuffix)),
+		kubeclient.WithRetry(kubeclient.DefaultRetryBackoff), // keep brief API server blips from flapping conditions
+		kubeclient.WithRateLimit(cfg.KubeClient),
 	}
 
 	client, leaderElector, err := leaderelection.New(
@@ -424,7 +689,8 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		pinnipedinformers.WithNamespace(serverInstallationNamespace),
 	)
 
-	// Serve the /healthz endpoint and make all other paths result in 404.
+	// Serve the /healthz and /readyz endpoints and make all other paths result in 404.
+	// The /readyz endpoint is added below, after its dependencies have been created.
 	healthMux := http.NewServeMux()
 	healthMux.Handle("/healthz", http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		_, _ = writer.Write([]byte("ok"))
@@ -437,6 +703,76 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 	dynamicUpstreamIDPProvider := provider.NewDynamicUpstreamIDPProvider()
 	secretCache := secret.Cache{}
 
+	var lockoutTracker *lockout.Tracker // nil means that lockouts are disabled
+	if l := cfg.PasswordLockout; l != nil {
+		lockoutConfig := lockout.Config{
+			MaxFailedAttempts: int(*l.MaxFailedAttempts),
+			LockoutDuration:   time.Duration(*l.LockoutDurationSeconds) * time.Second,
+		}
+		switch l.Storage {
+		case supervisor.PasswordLockoutStorageKubernetes:
+			lockoutTracker = lockout.NewWithStore(lockoutConfig, clock.RealClock{}, lockout.NewSecretStore(
+				clientWithoutLeaderElection.Kubernetes.CoreV1().Secrets(serverInstallationNamespace), // writes are allowed for non-leaders
+				clock.RealClock{},
+				lockoutConfig,
+			))
+		default:
+			lockoutTracker = lockout.New(lockoutConfig, clock.RealClock{})
+		}
+	}
+
+	sessionStorage, closeSessionStorage, err := newSessionStorageBackend(
+		ctx,
+		cfg.SessionStorage,
+		clientWithoutLeaderElection.Kubernetes.CoreV1().Secrets(serverInstallationNamespace), // writes to kube storage are allowed for non-leaders
+		kubeInformers.Core().V1().Secrets().Lister().Secrets(serverInstallationNamespace),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot create session storage: %w", err)
+	}
+	defer closeSessionStorage()
+
+	sessionEncryption, closeSessionEncryption, err := newSessionEncryption(cfg.SessionStorage)
+	if err != nil {
+		return fmt.Errorf("cannot set up session encryption: %w", err)
+	}
+	defer closeSessionEncryption()
+	if sessionEncryption != nil {
+		sessionStorage = crud.NewEncryptedBackend(sessionStorage, sessionEncryption.keyring)
+	}
+
+	auditLogger, err := newAuditLogger(ctx, cfg.AuditLog)
+	if err != nil {
+		return fmt.Errorf("cannot create audit logger: %w", err)
+	}
+
+	if err := setupDefaultTLSCertFromFiles(ctx, cfg.TLS, dynamicTLSCertProvider); err != nil {
+		return fmt.Errorf("cannot load default TLS certificate files: %w", err)
+	}
+
+	externalIDTokenJWK, closeExternalIDTokenSigner, err := newExternalIDTokenJWK(ctx, cfg.ExternalSigners.IDTokenSigner)
+	if err != nil {
+		return fmt.Errorf("cannot connect to the external ID token signer: %w", err)
+	}
+	defer closeExternalIDTokenSigner()
+
+	closeTracing, err := setupTracing(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("cannot set up tracing: %w", err)
+	}
+	defer closeTracing()
+
+	// Warn about certs which are about to expire, e.g. because a controller keeps failing to rotate them.
+	go dynamiccert.RunExpiryWarnings(ctx, dynamiccert.ExpiryWarningInterval)
+
+	if err := cfg.Profiling.ServeLoopback(ctx); err != nil {
+		return err
+	}
+
+	if err := cfg.Metrics.ServeCleartext(ctx); err != nil {
+		return err
+	}
+
 	// OIDC endpoints will be served by the oidProvidersManager, and any non-OIDC paths will fallback to the healthMux.
 	oidProvidersManager := manager.NewManager(
 		healthMux,
@@ -444,17 +780,116 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		dynamicUpstreamIDPProvider,
 		&secretCache,
 		clientWithoutLeaderElection.Kubernetes.CoreV1().Secrets(serverInstallationNamespace), // writes to kube storage are allowed for non-leaders
+		sessionStorage,
 		client.PinnipedSupervisor.ConfigV1alpha1().OIDCClients(serverInstallationNamespace),
+		lockoutTracker,
+		auditLogger,
 	)
+	healthMux.Handle("/readyz", readyz.NewHandler(
+		oidProvidersManager,
+		dynamicJWKSProvider,
+		dynamicTLSCertProvider,
+		dynamicUpstreamIDPProvider,
+		stopping,
+	))
 
-	// Get the "real" name of the client secret supervisor API group (i.e., the API group name with the
+	// Get the "real" names of the client secret and session supervisor API groups (i.e., the API group names with the
 	// injected suffix).
-	scheme, clientSecretGV := supervisorscheme.New(*cfg.APIGroupSuffix)
+	scheme, clientSecretGV, sessionGV := supervisorscheme.New(*cfg.APIGroupSuffix)
+
+	shutdown := &sync.WaitGroup{}
+
+	aggregatedAPIServerTLSConfigFunc, httpsTLSConfigFunc, err := tlsConfigFuncs(cfg)
+	if err != nil {
+		return err
+	}
+
+	// The TLS config of the HTTPS listeners is looked up for each connection, so that it can be reloaded.
+	var currentHTTPSTLSConfigFunc atomic.Value
+	currentHTTPSTLSConfigFunc.Store(httpsTLSConfigFunc)
+
+	bootstrapCert, err := getBootstrapCert(cfg.Certificates.KeyAlgorithm) // generate this in-memory once per process startup
+	if err != nil {
+		return fmt.Errorf("https listener bootstrap error: %w", err)
+	}
+
+	httpsConfig := httpsTLSConfigFunc(nil)
+	httpsConfig.GetCertificate = func(info *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		cert := dynamicTLSCertProvider.GetTLSCert(strings.ToLower(info.ServerName))
+
+		defaultCert := dynamicTLSCertProvider.GetDefaultTLSCert()
+
+		if plog.Enabled(plog.LevelTrace) { // minor CPU optimization as this is generally just noise
+			host, port, _ := net.SplitHostPort(info.Conn.LocalAddr().String()) // error is safe to ignore here
+
+			plog.Trace("GetCertificate called",
+				"info.ServerName", info.ServerName,
+				"foundSNICert", cert != nil,
+				"foundDefaultCert", defaultCert != nil,
+				"host", host,
+				"port", port,
+			)
+		}
+
+		if cert == nil {
+			cert = defaultCert
+		}
+
+		if cert == nil {
+			setIsBootstrapConn(info.Context()) // make this connection only work for bootstrap requests
+			cert = bootstrapCert
+		}
+
+		return cert, nil
+	}
+	httpsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		current := currentHTTPSTLSConfigFunc.Load().(ptls.ConfigFunc)(nil)
+		current.GetCertificate = httpsConfig.GetCertificate
+		return current, nil
+	}
+
+	// Determine the IP address and protocol of each client, as reported by the trusted proxies in front of the Supervisor.
+	supervisorHandler := &reloadableHandler{}
+	supervisorHandler.store(clientip.Wrap(oidProvidersManager, trustedProxies(cfg.TrustedProxies)))
+
+	listeners := &listenerSet{
+		ctx:             ctx,
+		stopping:        stopping,
+		shutdownTimeout: shutdownTimeout,
+		shutdown:        shutdown,
+		handler:         supervisorHandler,
+		httpsConfig:     httpsConfig,
+		pod:             supervisorPod,
+	}
+	defer listeners.close()
+
+	// The settings which can be changed while running come from the static configuration, which can be reloaded,
+	// and from the SupervisorConfig, which is watched by a controller.
+	settings := &runtimeSettings{
+		static: cfg,
+		apply: func(c *supervisor.Config) error {
+			_, appliedHTTPSTLSConfigFunc, err := tlsConfigFuncs(c)
+			if err != nil {
+				return err
+			}
+			if err := listeners.reconcile(c.Endpoints); err != nil {
+				return err
+			}
+			if err := plog.SetLogLevelGlobally(c.Log.Level, c.Log.Components); err != nil {
+				return err
+			}
+			currentHTTPSTLSConfigFunc.Store(appliedHTTPSTLSConfigFunc)
+			supervisorHandler.store(clientip.Wrap(oidProvidersManager, trustedProxies(c.TrustedProxies)))
+			return nil
+		},
+	}
 
 	buildControllersFunc := prepareControllers(
 		cfg,
+		settings,
 		oidProvidersManager,
 		dynamicJWKSProvider,
+		externalIDTokenJWK,
 		dynamicTLSCertProvider,
 		dynamicUpstreamIDPProvider,
 		dynamicServingCertProvider,
@@ -467,9 +902,18 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		pinnipedInformers,
 		leaderElector,
 		podInfo,
+		sessionEncryption,
 	)
 
-	shutdown := &sync.WaitGroup{}
+	// The admission webhook is served by the aggregated API server when it is enabled.
+	var admissionWebhook http.Handler
+	if cfg.NamesConfig.ValidatingWebhookConfiguration != "" {
+		validators, err := admission.NewValidators(*cfg.APIGroupSuffix, pinnipedInformers.Config().V1alpha1().FederationDomains().Lister())
+		if err != nil {
+			return fmt.Errorf("could not configure admission webhook: %w", err)
+		}
+		admissionWebhook = admissionwebhook.NewHandler(validators)
+	}
 
 	// Get the aggregated API server config.
 	aggregatedAPIServerConfig, err := getAggregatedAPIServerConfig(
@@ -477,11 +921,19 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		buildControllersFunc,
 		*cfg.APIGroupSuffix,
 		*cfg.AggregatedAPIServerPort,
+		aggregatedAPIServerTLSConfigFunc,
 		scheme,
 		clientSecretGV,
+		sessionGV,
 		clientWithoutLeaderElection.Kubernetes.CoreV1().Secrets(serverInstallationNamespace),
+		sessionSecrets(cfg.SessionStorage, clientWithoutLeaderElection.Kubernetes.CoreV1().Secrets(serverInstallationNamespace)),
+		sessionEncryption.encrypter(),
 		client.PinnipedSupervisor.ConfigV1alpha1().OIDCClients(serverInstallationNamespace),
 		serverInstallationNamespace,
+		auditLogger,
+		admissionWebhook,
+		cfg.Profiling,
+		cfg.Metrics,
 	)
 	if err != nil {
 		return fmt.Errorf("could not configure aggregated API server: %w", err)
@@ -493,86 +945,369 @@ func runSupervisor(ctx context.Context, podInfo *downward.PodInfo, cfg *supervis
 		return fmt.Errorf("could not create aggregated API server: %w", err)
 	}
 
-	if e := cfg.Endpoints.HTTP; e.Network != supervisor.NetworkDisabled {
-		finishSetupPerms := maybeSetupUnixPerms(e, supervisorPod)
+	// Start the listeners with the settings of the static configuration. The settings of the SupervisorConfig, if any,
+	// are applied once the controllers have started.
+	if err := settings.setStatic(cfg); err != nil {
+		return err
+	}
 
-		httpListener, err := net.Listen(e.Network, e.Address)
+	// Apply the settings which can be changed while running when the config is reloaded, i.e. the log levels, the TLS
+	// settings and the endpoints of the HTTP and HTTPS listeners, and the trusted proxies. Other settings are only
+	// applied by restarting the pods.
+	go configreload.Run(ctx, configPath, configreload.PollInterval, func() error {
+		reloaded, err := supervisor.ReloadFromPath(configPath)
 		if err != nil {
-			return fmt.Errorf("cannot create http listener with network %q and address %q: %w", e.Network, e.Address, err)
+			return err
 		}
+		return settings.setStatic(reloaded)
+	})
 
-		if err := finishSetupPerms(); err != nil {
-			return fmt.Errorf("cannot setup http listener permissions for network %q and address %q: %w", e.Network, e.Address, err)
-		}
+	plog.Debug("supervisor started")
+	defer plog.Debug("supervisor exiting")
+
+	// Run the server. Its post-start hook will start the controllers.
+	err = server.GenericAPIServer.PrepareRun().Run(ctx.Done())
+	if err != nil {
+		return err
+	}
+	shutdown.Wait()
+
+	return nil
+}
 
-		defer func() { _ = httpListener.Close() }()
-		startServer(ctx, shutdown, httpListener, oidProvidersManager)
-		plog.Debug("supervisor http listener started", "address", httpListener.Addr().String())
+// tlsConfigFuncs returns the TLS configs of the aggregated API server, whose only client is the Kube API server, and of
+// the HTTPS listeners, which any client could connect to. The TLS profile of the config applies to both, while each
+// starts from its own defaults.
+func tlsConfigFuncs(cfg *supervisor.Config) (aggregatedAPIServer ptls.ConfigFunc, https ptls.ConfigFunc, err error) {
+	var tlsProfile ptls.ProfileSpec
+	if cfg.TLS != nil {
+		tlsProfile = cfg.TLS.ProfileSpec
 	}
+	aggregatedAPIServer, err = tlsProfile.ConfigFunc(ptls.Secure)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not configure TLS: %w", err)
+	}
+	https, err = tlsProfile.ConfigFunc(ptls.Default)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not configure TLS: %w", err)
+	}
+
+	if err := fips.Check(cfg.EnforceFIPS, map[string]*tls.Config{
+		"aggregated API server": aggregatedAPIServer(nil),
+		"HTTPS listeners":       https(nil),
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return aggregatedAPIServer, https, nil
+}
+
+// reloadableHandler serves each request with the handler which was stored last, so that the handler can be replaced
+// while the listeners keep serving, e.g. when the trusted proxies are reloaded.
+type reloadableHandler struct {
+	handler atomic.Value
+}
 
-	if e := cfg.Endpoints.HTTPS; e.Network != supervisor.NetworkDisabled { //nolint:nestif
-		finishSetupPerms := maybeSetupUnixPerms(e, supervisorPod)
+func (h *reloadableHandler) store(handler http.Handler) {
+	h.handler.Store(handler)
+}
+
+func (h *reloadableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.Load().(http.Handler).ServeHTTP(w, r)
+}
+
+func trustedProxies(cidrs []string) []*net.IPNet {
+	trusted := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, _ := net.ParseCIDR(cidr) // the CIDRs have already been validated
+		trusted = append(trusted, ipNet)
+	}
+	return trusted
+}
+
+func shutdownConfig(cfg *supervisor.Shutdown) (drainDelay time.Duration, shutdownTimeout time.Duration) {
+	if cfg == nil {
+		return supervisor.ShutdownDrainDelaySecondsDefault * time.Second, supervisor.ShutdownTimeoutSecondsDefault * time.Second
+	}
+	return time.Duration(*cfg.DrainDelaySeconds) * time.Second, time.Duration(*cfg.TimeoutSeconds) * time.Second
+}
 
-		bootstrapCert, err := getBootstrapCert() // generate this in-memory once per process startup
+func garbageCollectorConfig(cfg *supervisor.SessionGarbageCollection, sessionEncrypter crud.Encrypter) supervisorstorage.GarbageCollectorConfig {
+	if cfg == nil {
+		return supervisorstorage.GarbageCollectorConfig{SessionEncrypter: sessionEncrypter} // use the defaults
+	}
+	return supervisorstorage.GarbageCollectorConfig{
+		MinimumRepeatInterval: time.Duration(*cfg.IntervalSeconds) * time.Second,
+		BatchSize:             int(*cfg.BatchSize),
+		DeletesPerSecond:      float32(*cfg.DeletesPerSecond),
+		SessionEncrypter:      sessionEncrypter,
+	}
+}
+
+// sessionEncryption holds the keys of the envelope encryption of the sessions.
+type sessionEncryption struct {
+	keyring  *sessionencryption.Keyring
+	kek      sessionencryption.KeyEncryptionKey
+	localKEK *sessionencryption.LocalKeyEncryptionKey // nil when the key encryption key is held by an external signer
+}
+
+// encrypter returns the Encrypter of the sessions, or nil when the sessions are not encrypted.
+func (e *sessionEncryption) encrypter() crud.Encrypter {
+	if e == nil {
+		return nil // avoid returning a non-nil interface which holds a nil pointer
+	}
+	return e.keyring
+}
+
+// newSessionEncryption returns the keys of the envelope encryption of the sessions, along with a func which closes
+// the connection to the external signer plugin which holds the key encryption key, if any. The keys are nil when the
+// sessions are not encrypted. They are only usable once they were loaded by the controllers.
+func newSessionEncryption(cfg *supervisor.SessionStorage) (*sessionEncryption, func(), error) {
+	if cfg == nil || cfg.Encryption == nil || !cfg.Encryption.Enabled {
+		return nil, func() {}, nil
+	}
+
+	if spec := cfg.Encryption.ExternalKeyEncryptionKey; spec != nil {
+		wrapper, err := spec.NewKeyWrapper()
 		if err != nil {
-			return fmt.Errorf("https listener bootstrap error: %w", err)
+			return nil, nil, err
 		}
+		plog.Info("encrypting sessions with a key encryption key of an external signer", "endpoint", spec.Endpoint, "keyID", spec.KeyID)
+		return &sessionEncryption{
+			keyring: sessionencryption.NewKeyring(),
+			kek:     sessionencryption.NewExternalKeyEncryptionKey(wrapper),
+		}, func() { _ = wrapper.Close() }, nil
+	}
+
+	localKEK := sessionencryption.NewLocalKeyEncryptionKey()
+	plog.Info("encrypting sessions with a generated key encryption key")
+	return &sessionEncryption{
+		keyring:  sessionencryption.NewKeyring(),
+		kek:      localKEK,
+		localKEK: localKEK,
+	}, func() {}, nil
+}
 
-		c := ptls.Default(nil)
-		c.GetCertificate = func(info *tls.ClientHelloInfo) (*tls.Certificate, error) {
-			cert := dynamicTLSCertProvider.GetTLSCert(strings.ToLower(info.ServerName))
+// sessionSecrets returns the client of the Secrets in which the sessions are stored, or nil when they are stored
+// elsewhere, e.g. in Redis.
+func sessionSecrets(cfg *supervisor.SessionStorage, secrets corev1client.SecretInterface) corev1client.SecretInterface {
+	if cfg != nil && cfg.Type == supervisor.SessionStorageTypeRedis {
+		return nil
+	}
+	return secrets
+}
+
+// newSessionStorageBackend returns the backend in which all sessions will be stored, along with a func which releases
+// any of its resources when the Supervisor exits.
+func newSessionStorageBackend(
+	ctx context.Context,
+	cfg *supervisor.SessionStorage,
+	secrets corev1client.SecretInterface,
+	secretsLister corev1listers.SecretNamespaceLister,
+) (crud.Backend, func(), error) {
+	if cfg == nil || cfg.Type != supervisor.SessionStorageTypeRedis {
+		if cfg != nil && cfg.Kubernetes != nil && cfg.Kubernetes.CachedReads {
+			return crud.NewCachedSecretsBackend(secrets, secretsLister), func() {}, nil
+		}
+		return crud.NewSecretsBackend(secrets), func() {}, nil
+	}
 
-			defaultCert := dynamicTLSCertProvider.GetDefaultTLSCert()
+	opts := &redis.Options{
+		Addr: cfg.Redis.Address,
+		DB:   cfg.Redis.Database,
+	}
 
-			if plog.Enabled(plog.LevelTrace) { // minor CPU optimization as this is generally just noise
-				host, port, _ := net.SplitHostPort(info.Conn.LocalAddr().String()) // error is safe to ignore here
+	if cfg.Redis.SecretName != "" {
+		s, err := secrets.Get(ctx, cfg.Redis.SecretName, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot get redis credentials secret %q: %w", cfg.Redis.SecretName, err)
+		}
+		opts.Username = string(s.Data["username"])
+		opts.Password = string(s.Data["password"])
+	}
 
-				plog.Trace("GetCertificate called",
-					"info.ServerName", info.ServerName,
-					"foundSNICert", cert != nil,
-					"foundDefaultCert", defaultCert != nil,
-					"host", host,
-					"port", port,
-				)
+	if t := cfg.Redis.TLS; t != nil {
+		var rootCAs *x509.CertPool // nil means use the host's root CAs
+		if t.CertificateAuthorityData != "" {
+			// The config has already been validated, so these errors should not happen.
+			caBundle, err := base64.StdEncoding.DecodeString(t.CertificateAuthorityData)
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot decode redis certificate authority data: %w", err)
 			}
-
-			if cert == nil {
-				cert = defaultCert
+			rootCAs = x509.NewCertPool()
+			if !rootCAs.AppendCertsFromPEM(caBundle) {
+				return nil, nil, fmt.Errorf("cannot parse redis certificate authority data")
 			}
+		}
+		opts.TLSConfig = ptls.Default(rootCAs)
+	}
 
-			if cert == nil {
-				setIsBootstrapConn(info.Context()) // make this connection only work for bootstrap requests
-				cert = bootstrapCert
+	client := redis.NewClient(opts)
+	plog.Info("storing sessions in redis", "address", opts.Addr, "database", opts.DB, "tls", opts.TLSConfig != nil)
+
+	return redisstorage.New(client), func() { _ = client.Close() }, nil
+}
+
+// newAuditLogger returns the Logger to which the audit events of all FederationDomains and of the DownstreamSession
+// API are emitted. The webhook, if any, stops sending events when ctx is cancelled.
+func newAuditLogger(ctx context.Context, cfg *supervisor.AuditLog) (auditlog.Logger, error) {
+	if cfg == nil {
+		return auditlog.Nop(), nil
+	}
+
+	var loggers []auditlog.Logger
+	if cfg.Stdout {
+		loggers = append(loggers, auditlog.NewJSONLogger(os.Stdout))
+	}
+	if w := cfg.Webhook; w != nil {
+		var rootCAs *x509.CertPool // nil means use the host's root CAs
+		if w.CertificateAuthorityData != "" {
+			// The config has already been validated, so these errors should not happen.
+			caBundle, err := base64.StdEncoding.DecodeString(w.CertificateAuthorityData)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode audit webhook certificate authority data: %w", err)
+			}
+			rootCAs = x509.NewCertPool()
+			if !rootCAs.AppendCertsFromPEM(caBundle) {
+				return nil, fmt.Errorf("cannot parse audit webhook certificate authority data")
 			}
+		}
+		loggers = append(loggers, auditlog.NewWebhookLogger(ctx, w.URL, rootCAs))
+	}
+
+	plog.Info("emitting audit events", "stdout", cfg.Stdout, "webhook", cfg.Webhook != nil)
+	return auditlog.Tee(loggers...), nil
+}
+
+// newExternalIDTokenJWK connects to the external signer plugin which signs the ID tokens, if any. The returned
+// function closes the connection. A nil JWK means that the ID tokens are signed by the keys in the JWKS Secrets.
+func newExternalIDTokenJWK(ctx context.Context, cfg *externalsigner.Spec) (*jose.JSONWebKey, func(), error) {
+	if cfg == nil {
+		return nil, func() {}, nil
+	}
+
+	signer, err := cfg.NewSigner(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jwk, err := supervisorconfig.NewExternalJWK(signer)
+	if err != nil {
+		_ = signer.Close()
+		return nil, nil, err
+	}
+
+	plog.Info("signing ID tokens with an external signer", "endpoint", cfg.Endpoint, "keyID", jwk.KeyID)
+	return jwk, func() { _ = signer.Close() }, nil
+}
+
+// defaultTLSCertificateSecretName returns the name of the Secret from which the default TLS cert is loaded,
+// or an empty string when it is loaded from files instead.
+func defaultTLSCertificateSecretName(cfg *supervisor.Config) string {
+	if cfg.TLS != nil && cfg.TLS.DefaultCertificateFiles != nil {
+		return ""
+	}
+	return cfg.NamesConfig.DefaultTLSCertificateSecret
+}
+
+// setupDefaultTLSCertFromFiles loads the default TLS cert from the configured files, if any, and keeps it up to date
+// as the files change until the context is canceled.
+func setupDefaultTLSCertFromFiles(ctx context.Context, cfg *supervisor.TLS, setter supervisorconfig.IssuerTLSCertSetter) error {
+	if cfg == nil || cfg.DefaultCertificateFiles == nil {
+		return nil
+	}
+
+	certProvider := dynamiccert.NewServingCertFromFiles(
+		"supervisor-default-tls-cert",
+		cfg.DefaultCertificateFiles.CertificateFile,
+		cfg.DefaultCertificateFiles.KeyFile,
+	)
 
-			return cert, nil
+	setDefaultTLSCert := func() {
+		cert, err := tls.X509KeyPair(certProvider.CurrentCertKeyContent())
+		if err != nil { // should not happen because the provider only holds valid key pairs
+			plog.Error("could not parse default TLS certificate files", err)
+			return
 		}
+		setter.SetDefaultTLSCert(&cert)
+	}
 
-		httpsListener, err := tls.Listen(e.Network, e.Address, c)
-		if err != nil {
-			return fmt.Errorf("cannot create https listener with network %q and address %q: %w", e.Network, e.Address, err)
+	if err := certProvider.RunOnce(ctx); err != nil {
+		return err
+	}
+	setDefaultTLSCert()
+
+	// listeners are called while the provider holds its lock, so only signal the goroutine which reads the new content
+	changed := make(chan struct{}, 1)
+	certProvider.AddListener(listenerFunc(func() {
+		select {
+		case changed <- struct{}{}:
+		default:
 		}
+	}))
 
-		if err := finishSetupPerms(); err != nil {
-			return fmt.Errorf("cannot setup https listener permissions for network %q and address %q: %w", e.Network, e.Address, err)
+	go certProvider.Run(ctx, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				setDefaultTLSCert()
+			}
 		}
+	}()
 
-		defer func() { _ = httpsListener.Close() }()
-		startServer(ctx, shutdown, httpsListener, oidProvidersManager)
-		plog.Debug("supervisor https listener started", "address", httpsListener.Addr().String())
+	return nil
+}
+
+type listenerFunc func()
+
+func (f listenerFunc) Enqueue() { f() }
+
+// setupTracing starts exporting the traces of the login flows when tracing is configured. The returned function
+// flushes any traces which have not been exported yet.
+func setupTracing(ctx context.Context, cfg *supervisor.Tracing) (func(), error) {
+	if cfg == nil {
+		return func() {}, nil
 	}
 
-	plog.Debug("supervisor started")
-	defer plog.Debug("supervisor exiting")
+	// The config has already been validated, so these errors should not happen.
+	endpoint, err := endpointaddr.Parse(cfg.Endpoint, supervisor.TracingEndpointPortDefault)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse tracing endpoint: %w", err)
+	}
 
-	// Run the server. Its post-start hook will start the controllers.
-	err = server.GenericAPIServer.PrepareRun().Run(ctx.Done())
+	var tlsConfig *tls.Config // nil means do not use TLS
+	if !cfg.Insecure {
+		var rootCAs *x509.CertPool // nil means use the host's root CAs
+		if cfg.CertificateAuthorityData != "" {
+			caBundle, err := base64.StdEncoding.DecodeString(cfg.CertificateAuthorityData)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode tracing certificate authority data: %w", err)
+			}
+			rootCAs = x509.NewCertPool()
+			if !rootCAs.AppendCertsFromPEM(caBundle) {
+				return nil, fmt.Errorf("cannot parse tracing certificate authority data")
+			}
+		}
+		tlsConfig = ptls.Default(rootCAs)
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, endpoint.Endpoint(), tlsConfig, float64(*cfg.SamplingPercentage)/100)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	shutdown.Wait()
 
-	return nil
+	plog.Info("exporting traces", "endpoint", endpoint.Endpoint(), "samplingPercentage", *cfg.SamplingPercentage)
+	return func() {
+		// The ctx of the Supervisor has already been cancelled by the time this runs, so use a new one.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			plog.WarningErr("failed to export remaining traces", err)
+		}
+	}, nil
 }
 
 func getAggregatedAPIServerConfig(
@@ -580,11 +1315,19 @@ func getAggregatedAPIServerConfig(
 	buildControllers controllerinit.RunnerBuilder,
 	apiGroupSuffix string,
 	aggregatedAPIServerPort int64,
+	tlsConfigFunc ptls.ConfigFunc,
 	scheme *runtime.Scheme,
 	clientSecretSupervisorGroupVersion schema.GroupVersion,
+	sessionSupervisorGroupVersion schema.GroupVersion,
 	secrets corev1client.SecretInterface,
+	sessionSecrets corev1client.SecretInterface,
+	sessionEncrypter crud.Encrypter,
 	oidcClients v1alpha1.OIDCClientInterface,
 	serverInstallationNamespace string,
+	auditLogger auditlog.Logger,
+	admissionWebhook http.Handler,
+	profilingSpec profiling.Spec,
+	metricsSpec metricsendpoint.Spec,
 ) (*apiserver.Config, error) {
 	codecs := serializer.NewCodecFactory(scheme)
 
@@ -593,7 +1336,7 @@ func getAggregatedAPIServerConfig(
 
 	recommendedOptions := genericoptions.NewRecommendedOptions(
 		defaultEtcdPathPrefix,
-		codecs.LegacyCodec(clientSecretSupervisorGroupVersion),
+		codecs.LegacyCodec(clientSecretSupervisorGroupVersion, sessionSupervisorGroupVersion),
 	)
 	recommendedOptions.Etcd = nil // turn off etcd storage because we don't need it yet
 	recommendedOptions.SecureServing.ServerCert.GeneratedCert = dynamicCertProvider
@@ -601,13 +1344,24 @@ func getAggregatedAPIServerConfig(
 	// This port is configurable. It should be safe to cast because the config reader already validated it.
 	recommendedOptions.SecureServing.BindPort = int(aggregatedAPIServerPort)
 
+	// The Kube API server does not authenticate when it calls the admission webhook, so allow anonymous requests.
+	if admissionWebhook != nil {
+		recommendedOptions.Authorization.WithAlwaysAllowPaths(admissionwebhook.Path)
+	}
+
 	// secure TLS for connections coming from and going to the Kube API server
 	// this is best effort because not all options provide the right hooks to override TLS config
-	// since our only client is the Kube API server, this uses the most secure TLS config
-	if err := ptls.SecureRecommendedOptions(recommendedOptions, kubeclient.Secure); err != nil {
+	// since our only client is the Kube API server, this uses the most secure TLS config unless the TLS profile says otherwise
+	if err := ptls.RecommendedOptions(recommendedOptions, tlsConfigFunc, kubeclient.Secure); err != nil {
 		return nil, fmt.Errorf("failed to secure recommended options: %w", err)
 	}
 
+	// The pprof endpoints are only served when they are enabled by the config.
+	profilingSpec.ApplyTo(recommendedOptions)
+
+	// The metrics endpoint is served to unauthenticated clients only when the config allows it.
+	metricsSpec.ApplyTo(recommendedOptions)
+
 	serverConfig := genericapiserver.NewRecommendedConfig(codecs)
 	// Add the generated openapi docs to the server config. Publishing openapi docs allows
 	// `kubectl explain` to work for the Supervisor's aggregated API resources.
@@ -633,9 +1387,14 @@ func getAggregatedAPIServerConfig(
 			Scheme:                             scheme,
 			NegotiatedSerializer:               codecs,
 			ClientSecretSupervisorGroupVersion: clientSecretSupervisorGroupVersion,
+			SessionSupervisorGroupVersion:      sessionSupervisorGroupVersion,
 			Secrets:                            secrets,
+			SessionSecrets:                     sessionSecrets,
+			SessionEncrypter:                   sessionEncrypter,
 			OIDCClients:                        oidcClients,
 			Namespace:                          serverInstallationNamespace,
+			AuditLogger:                        auditLogger,
+			AdmissionWebhook:                   admissionWebhook,
 		},
 	}
 	return apiServerConfig, nil
@@ -675,6 +1434,11 @@ func maybeSetupUnixPerms(endpoint *supervisor.Endpoint, pod *corev1.Pod) func()
 func main() error { // return an error instead of plog.Fatal to allow defer statements to run
 	defer plog.Setup()()
 
+	// pinniped-supervisor doctor <podinfo path> <config path> checks the installation instead of running the server.
+	if len(os.Args) == 4 && os.Args[1] == "doctor" {
+		return runDoctor(signalCtx(), os.Args[2], os.Args[3], os.Stdout)
+	}
+
 	plog.Always("Running supervisor",
 		"user-agent", rest.DefaultKubernetesUserAgent(),
 		"version", versionInfo(version.Get()),
@@ -695,7 +1459,10 @@ func main() error { // return an error instead of plog.Fatal to allow defer stat
 		return fmt.Errorf("could not load config: %w", err)
 	}
 
-	return runSupervisor(ctx, podInfo, cfg)
+	// The latency buckets apply to the metrics which are registered afterwards, so they cannot be reloaded.
+	cfg.Metrics.ConfigureLatencyBuckets()
+
+	return runSupervisor(ctx, podInfo, cfg, os.Args[2])
 }
 
 func Main() {
@@ -0,0 +1,50 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testutil
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/auditlog"
+)
+
+// AuditRecorder is an auditlog.Logger which remembers all emitted events.
+type AuditRecorder struct {
+	mu     sync.Mutex
+	events []auditlog.Event
+}
+
+func (r *AuditRecorder) Emit(event auditlog.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+// Events returns the events which have been emitted so far.
+func (r *AuditRecorder) Events() []auditlog.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]auditlog.Event(nil), r.events...)
+}
+
+// RequireEventsWithRandomSessionIDs requires that exactly the wanted events have been emitted. The session IDs of
+// downstream sessions are random, so they are only required to be non-empty in UpstreamAuthenticationSucceeded
+// events, and should not be set in the wanted events.
+func (r *AuditRecorder) RequireEventsWithRandomSessionIDs(t *testing.T, want []auditlog.Event) {
+	t.Helper()
+
+	actual := r.Events()
+	for i := range actual {
+		if actual[i].Type == auditlog.EventUpstreamAuthenticationSucceeded {
+			require.NotEmpty(t, actual[i].SessionID)
+			actual[i].SessionID = ""
+		}
+	}
+	require.Equal(t, want, actual)
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testutil
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	coretesting "k8s.io/client-go/testing"
+)
+
+// AddUpdateStatusConflictReactor makes the next count updates of the status of any object of the resource fail with
+// a conflict error, like they would when the object was changed since it was read. Pass the Fake of a generated fake
+// clientset, e.g. &pinnipedClient.Fake.
+func AddUpdateStatusConflictReactor(client *coretesting.Fake, resource schema.GroupResource, count int) {
+	var lock sync.Mutex
+	client.PrependReactor("update", resource.Resource, func(action coretesting.Action) (bool, runtime.Object, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if action.GetSubresource() != "status" || count == 0 {
+			return false, nil, nil
+		}
+		count--
+
+		name := ""
+		if object, err := meta.Accessor(action.(coretesting.UpdateAction).GetObject()); err == nil {
+			name = object.GetName()
+		}
+		return true, nil, apierrors.NewConflict(resource, name, errors.New("the object has been modified"))
+	})
+}
+
+// StatusConditionUpdates returns the status conditions of each update of the status of the named object in the
+// actions, in the order of the updates. The fake clientsets record every action, so this includes the updates which
+// failed, e.g. because of AddUpdateStatusConflictReactor. It works with any of the Pinniped APIs, since all of their
+// status conditions are metav1.Conditions.
+func StatusConditionUpdates(t *testing.T, actions []coretesting.Action, resource, namespace, name string) [][]metav1.Condition {
+	t.Helper()
+
+	var updates [][]metav1.Condition
+	for _, action := range actions {
+		updateAction, ok := action.(coretesting.UpdateAction)
+		if !ok || updateAction.GetSubresource() != "status" ||
+			updateAction.GetResource().Resource != resource || updateAction.GetNamespace() != namespace {
+			continue
+		}
+
+		object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(updateAction.GetObject())
+		require.NoError(t, err)
+		if (&unstructured.Unstructured{Object: object}).GetName() != name {
+			continue
+		}
+
+		rawConditions, _, err := unstructured.NestedSlice(object, "status", "conditions")
+		require.NoError(t, err)
+		conditions := make([]metav1.Condition, 0, len(rawConditions))
+		for _, rawCondition := range rawConditions {
+			rawCondition, ok := rawCondition.(map[string]interface{})
+			require.Truef(t, ok, "condition of %s %q is not an object", resource, name)
+			var condition metav1.Condition
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(rawCondition, &condition))
+			conditions = append(conditions, condition)
+		}
+		updates = append(updates, conditions)
+	}
+	return updates
+}
+
+// RequireConditionTransitions requires the condition of the given type to have had the given statuses, in order, in
+// the updates of the status of the named object in the actions. Updates which do not change the status of the
+// condition are not transitions, so they are ignored.
+func RequireConditionTransitions(
+	t *testing.T,
+	actions []coretesting.Action,
+	resource, namespace, name, conditionType string,
+	wantStatuses ...metav1.ConditionStatus,
+) {
+	t.Helper()
+
+	var statuses []metav1.ConditionStatus
+	for _, conditions := range StatusConditionUpdates(t, actions, resource, namespace, name) {
+		for _, condition := range conditions {
+			if condition.Type != conditionType {
+				continue
+			}
+			if len(statuses) == 0 || statuses[len(statuses)-1] != condition.Status {
+				statuses = append(statuses, condition.Status)
+			}
+		}
+	}
+	require.Equalf(t, wantStatuses, statuses, "unexpected transitions of the %s condition of %s %s/%s", conditionType, resource, namespace, name)
+}
+
+// NormalizeConditionTimes rounds down the last transition times of the conditions which changed within the last few
+// seconds to now, which makes it much easier to encode assertions about the expected timestamps.
+func NormalizeConditionTimes(conditions []metav1.Condition, now metav1.Time) []metav1.Condition {
+	normalized := make([]metav1.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		if time.Since(condition.LastTransitionTime.Time) < 5*time.Second {
+			condition.LastTransitionTime = now
+		}
+		normalized = append(normalized, condition)
+	}
+	return normalized
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	idpv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
+	supervisorfake "go.pinniped.dev/generated/latest/client/supervisor/clientset/versioned/fake"
+)
+
+func TestConditionHelpers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	upstream := &idpv1alpha1.OIDCIdentityProvider{ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "some-name"}}
+	other := &idpv1alpha1.OIDCIdentityProvider{ObjectMeta: metav1.ObjectMeta{Namespace: "some-namespace", Name: "other-name"}}
+	client := supervisorfake.NewSimpleClientset(upstream, other)
+	upstreams := client.IDPV1alpha1().OIDCIdentityProviders("some-namespace")
+
+	AddUpdateStatusConflictReactor(&client.Fake, idpv1alpha1.SchemeGroupVersion.WithResource("oidcidentityproviders").GroupResource(), 2)
+
+	updateStatus := func(t *testing.T, upstream *idpv1alpha1.OIDCIdentityProvider, status metav1.ConditionStatus) error {
+		t.Helper()
+		upstream = upstream.DeepCopy()
+		upstream.Status.Conditions = []metav1.Condition{
+			{Type: "Other", Status: metav1.ConditionTrue, Reason: "Success", LastTransitionTime: metav1.NewTime(time.Now())},
+			{Type: "Ready", Status: status, Reason: "SomeReason", LastTransitionTime: metav1.NewTime(time.Now())},
+		}
+		_, err := upstreams.UpdateStatus(ctx, upstream, metav1.UpdateOptions{})
+		return err
+	}
+
+	// The first updates of the status conflict.
+	err := updateStatus(t, upstream, metav1.ConditionUnknown)
+	require.True(t, apierrors.IsConflict(err), "expected a conflict, got %v", err)
+	require.EqualError(t, err, `Operation cannot be fulfilled on oidcidentityproviders.idp.supervisor.pinniped.dev "some-name": the object has been modified`)
+	require.True(t, apierrors.IsConflict(updateStatus(t, other, metav1.ConditionUnknown)))
+
+	// Later updates of the status succeed, and updates of other resources are not affected.
+	require.NoError(t, updateStatus(t, upstream, metav1.ConditionFalse))
+	require.NoError(t, updateStatus(t, upstream, metav1.ConditionFalse))
+	require.NoError(t, updateStatus(t, other, metav1.ConditionFalse))
+	require.NoError(t, updateStatus(t, upstream, metav1.ConditionTrue))
+
+	updates := StatusConditionUpdates(t, client.Actions(), "oidcidentityproviders", "some-namespace", "some-name")
+	require.Len(t, updates, 4)
+	now := metav1.NewTime(time.Now().Add(time.Hour))
+	require.Equal(t, []metav1.Condition{
+		{Type: "Other", Status: metav1.ConditionTrue, Reason: "Success", LastTransitionTime: now},
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "SomeReason", LastTransitionTime: now},
+	}, NormalizeConditionTimes(updates[3], now))
+
+	RequireConditionTransitions(t, client.Actions(), "oidcidentityproviders", "some-namespace", "some-name", "Ready",
+		metav1.ConditionUnknown, metav1.ConditionFalse, metav1.ConditionTrue)
+	RequireConditionTransitions(t, client.Actions(), "oidcidentityproviders", "some-namespace", "some-name", "Other",
+		metav1.ConditionTrue)
+	RequireConditionTransitions(t, client.Actions(), "oidcidentityproviders", "some-namespace", "other-name", "Ready",
+		metav1.ConditionUnknown, metav1.ConditionFalse)
+	RequireConditionTransitions(t, client.Actions(), "oidcidentityproviders", "some-namespace", "no-such-name", "Ready")
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package testutil
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -49,3 +50,19 @@ func OIDCClientSecretStorageSecretForUIDWithWrongVersion(t *testing.T, namespace
 	secret.Data["pinniped-storage-data"] = []byte(`{"version":"wrong-version","hashes":[]}`)
 	return secret
 }
+
+func OIDCClientSecretStorageSecretForUIDWithExpirations(t *testing.T, namespace string, oidcClientUID string, hashes []string, expirations map[string]time.Time) *corev1.Secret {
+	secret := OIDCClientSecretStorageSecretForUID(t, namespace, oidcClientUID, hashes)
+
+	metadata := map[string]map[string]metav1.Time{}
+	for hash, expiration := range expirations {
+		metadata[hash] = map[string]metav1.Time{"expires": metav1.NewTime(expiration)}
+	}
+	hashesJSON, err := json.Marshal(hashes)
+	require.NoError(t, err)
+	metadataJSON, err := json.Marshal(metadata)
+	require.NoError(t, err)
+
+	secret.Data["pinniped-storage-data"] = []byte(`{"version":"1","hashes":` + string(hashesJSON) + `,"metadata":` + string(metadataJSON) + `}`)
+	return secret
+}
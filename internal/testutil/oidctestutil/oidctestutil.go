@@ -105,6 +105,7 @@ type TestUpstreamLDAPIdentityProvider struct {
 	performRefreshArgs      []*PerformRefreshArgs
 	PerformRefreshErr       error
 	PerformRefreshGroups    []string
+	PerformRefreshDN        string // the DN returned by PerformRefresh, defaults to the stored DN
 }
 
 var _ provider.UpstreamLDAPIdentityProviderI = &TestUpstreamLDAPIdentityProvider{}
@@ -125,7 +126,7 @@ func (u *TestUpstreamLDAPIdentityProvider) GetURL() *url.URL {
 	return u.URL
 }
 
-func (u *TestUpstreamLDAPIdentityProvider) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, error) {
+func (u *TestUpstreamLDAPIdentityProvider) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, string, error) {
 	if u.performRefreshArgs == nil {
 		u.performRefreshArgs = make([]*PerformRefreshArgs, 0)
 	}
@@ -137,9 +138,12 @@ func (u *TestUpstreamLDAPIdentityProvider) PerformRefresh(ctx context.Context, s
 		ExpectedSubject:  storedRefreshAttributes.Subject,
 	})
 	if u.PerformRefreshErr != nil {
-		return nil, u.PerformRefreshErr
+		return nil, "", u.PerformRefreshErr
 	}
-	return u.PerformRefreshGroups, nil
+	if u.PerformRefreshDN != "" {
+		return u.PerformRefreshGroups, u.PerformRefreshDN, nil
+	}
+	return u.PerformRefreshGroups, storedRefreshAttributes.DN, nil
 }
 
 func (u *TestUpstreamLDAPIdentityProvider) PerformRefreshCallCount() int {
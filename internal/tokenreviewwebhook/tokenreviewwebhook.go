@@ -0,0 +1,167 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tokenreviewwebhook serves a TokenReview webhook, which the Kubernetes API server can call through its
+// --authentication-token-webhook-config-file to authenticate tokens with the authenticators of the Concierge. This
+// allows clusters to use Pinniped when neither the client certificates of the TokenCredentialRequest API nor the
+// impersonation proxy can be used.
+package tokenreviewwebhook
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authenticationv1beta1 "k8s.io/api/authentication/v1beta1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/klog/v2"
+
+	auth1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/authentication/v1alpha1"
+	"go.pinniped.dev/internal/controller/authenticator/authncache"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/internal/valuelesscontext"
+)
+
+// PathPrefix is the prefix of the paths at which the webhook is served by the aggregated API server. The
+// authenticator is chosen by the rest of the path, e.g. /authenticate-tokens/jwtauthenticators/my-authenticator.
+const PathPrefix = "/authenticate-tokens/"
+
+// maxRequestBodySize is the maximum size of a TokenReview, which is mostly the size of its token.
+const maxRequestBodySize = 1024 * 1024
+
+// kindsByResource maps the resources in the paths of the webhook to the kinds of the authenticators.
+//
+//nolint:gochecknoglobals
+var kindsByResource = map[string]string{
+	"jwtauthenticators":     "JWTAuthenticator",
+	"webhookauthenticators": "WebhookAuthenticator",
+}
+
+// AuthenticatorGetter gets the authenticators which are currently configured, e.g. an *authncache.Cache.
+type AuthenticatorGetter interface {
+	Get(key authncache.Key) authncache.Value
+}
+
+type handler struct {
+	authenticators AuthenticatorGetter
+}
+
+// NewHandler returns a handler which serves the TokenReviews of the Kubernetes API server with the authenticator
+// which is named by the path of the request. Both authentication.k8s.io/v1 and v1beta1 TokenReviews are supported.
+func NewHandler(authenticators AuthenticatorGetter) http.Handler {
+	return &handler{authenticators: authenticators}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key, ok := authenticatorKey(r.URL.Path)
+	if !ok {
+		http.Error(w, "path must be "+PathPrefix+"<jwtauthenticators|webhookauthenticators>/<name>", http.StatusNotFound)
+		return
+	}
+
+	if contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || contentType != "application/json" {
+		http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize+1))
+	if err != nil {
+		http.Error(w, "could not read request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestBodySize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// The v1 and v1beta1 TokenReviews have the same schema, so both are decoded as v1 and the response has the
+	// apiVersion of the request.
+	var review authenticationv1.TokenReview
+	if err := json.Unmarshal(body, &review); err != nil ||
+		review.Kind != "TokenReview" ||
+		(review.APIVersion != authenticationv1.SchemeGroupVersion.String() && review.APIVersion != authenticationv1beta1.SchemeGroupVersion.String()) {
+		http.Error(w, "request body must be an authentication.k8s.io/v1 or v1beta1 TokenReview", http.StatusBadRequest)
+		return
+	}
+
+	status := h.review(r, key, review.Spec.Token)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&authenticationv1.TokenReview{
+		TypeMeta: review.TypeMeta,
+		Status:   status,
+	}); err != nil {
+		plog.Error("could not write token review response", err)
+	}
+}
+
+func (h *handler) review(r *http.Request, key authncache.Key, token string) authenticationv1.TokenReviewStatus {
+	if len(token) == 0 {
+		return authenticationv1.TokenReviewStatus{Error: "token must be supplied"}
+	}
+
+	val := h.authenticators.Get(key)
+	if val == nil {
+		plog.Debug("token review webhook authenticator does not exist",
+			"authenticator", klog.KRef("", key.Name),
+			"kind", key.Kind,
+		)
+		return authenticationv1.TokenReviewStatus{Error: authncache.ErrNoSuchAuthenticator.Error()}
+	}
+
+	// Just like for TokenCredentialRequests, the audiences of the Kubernetes API server are not passed through to
+	// the authenticators. The Kubernetes API server assumes that the tokens are meant for it when the response does
+	// not list any audiences.
+	rsp, authenticated, err := val.AuthenticateToken(valuelesscontext.New(r.Context()), token)
+	if err != nil {
+		plog.DebugErr("token review webhook could not authenticate token", err,
+			"authenticator", klog.KRef("", key.Name),
+			"kind", key.Kind,
+		)
+		return authenticationv1.TokenReviewStatus{Error: "authentication failed"}
+	}
+	if !authenticated || rsp == nil || rsp.User == nil || len(rsp.User.GetName()) == 0 {
+		return authenticationv1.TokenReviewStatus{}
+	}
+
+	return authenticationv1.TokenReviewStatus{
+		Authenticated: true,
+		User:          userInfo(rsp.User),
+	}
+}
+
+func authenticatorKey(path string) (authncache.Key, bool) {
+	resource, name, ok := strings.Cut(strings.TrimPrefix(path, PathPrefix), "/")
+	if !ok || !strings.HasPrefix(path, PathPrefix) || len(name) == 0 || strings.Contains(name, "/") {
+		return authncache.Key{}, false
+	}
+	kind, ok := kindsByResource[resource]
+	if !ok {
+		return authncache.Key{}, false
+	}
+	return authncache.Key{APIGroup: auth1alpha1.GroupName, Kind: kind, Name: name}, true
+}
+
+func userInfo(u user.Info) authenticationv1.UserInfo {
+	var extra map[string]authenticationv1.ExtraValue
+	if len(u.GetExtra()) > 0 {
+		extra = make(map[string]authenticationv1.ExtraValue, len(u.GetExtra()))
+		for k, v := range u.GetExtra() {
+			extra[k] = v
+		}
+	}
+	return authenticationv1.UserInfo{
+		Username: u.GetName(),
+		UID:      u.GetUID(),
+		Groups:   u.GetGroups(),
+		Extra:    extra,
+	}
+}
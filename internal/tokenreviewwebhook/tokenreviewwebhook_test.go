@@ -0,0 +1,203 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package tokenreviewwebhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"go.pinniped.dev/internal/controller/authenticator/authncache"
+)
+
+func TestHandler(t *testing.T) {
+	cache := authncache.New()
+	cache.Store(
+		authncache.Key{APIGroup: "authentication.concierge.pinniped.dev", Kind: "JWTAuthenticator", Name: "some-jwt-authenticator"},
+		authenticator.TokenFunc(func(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+			// The audiences of the Kubernetes API server must not be passed through to the authenticators.
+			if _, ok := authenticator.AudiencesFrom(ctx); ok {
+				return nil, false, errors.New("unexpected audiences")
+			}
+			switch token {
+			case "good-token":
+				return &authenticator.Response{User: &user.DefaultInfo{
+					Name:   "some-user",
+					UID:    "some-uid",
+					Groups: []string{"some-group", "another-group"},
+					Extra:  map[string][]string{"some-key": {"some-value"}},
+				}}, true, nil
+			case "token-without-username":
+				return &authenticator.Response{User: &user.DefaultInfo{Groups: []string{"some-group"}}}, true, nil
+			case "broken-token":
+				return nil, false, errors.New("some internal error")
+			default:
+				return nil, false, nil
+			}
+		}),
+	)
+	cache.Store(
+		authncache.Key{APIGroup: "authentication.concierge.pinniped.dev", Kind: "WebhookAuthenticator", Name: "some-webhook-authenticator"},
+		authenticator.TokenFunc(func(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "some-webhook-user"}}, true, nil
+		}),
+	)
+	handler := NewHandler(cache)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:       "authenticated v1 token review",
+			path:       "/authenticate-tokens/jwtauthenticators/some-jwt-authenticator",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"good-token","audiences":["https://kubernetes.default.svc"]}}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"kind":"TokenReview","apiVersion":"authentication.k8s.io/v1","metadata":{"creationTimestamp":null},"spec":{},` +
+				`"status":{"authenticated":true,"user":{"username":"some-user","uid":"some-uid","groups":["some-group","another-group"],"extra":{"some-key":["some-value"]}}}}` + "\n",
+		},
+		{
+			name:       "authenticated v1beta1 token review",
+			path:       "/authenticate-tokens/webhookauthenticators/some-webhook-authenticator",
+			body:       `{"apiVersion":"authentication.k8s.io/v1beta1","kind":"TokenReview","spec":{"token":"any-token"}}`,
+			wantStatus: http.StatusOK,
+			wantBody: `{"kind":"TokenReview","apiVersion":"authentication.k8s.io/v1beta1","metadata":{"creationTimestamp":null},"spec":{},` +
+				`"status":{"authenticated":true,"user":{"username":"some-webhook-user"}}}` + "\n",
+		},
+		{
+			name:       "token which is not authenticated",
+			path:       "/authenticate-tokens/jwtauthenticators/some-jwt-authenticator",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"bad-token"}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"kind":"TokenReview","apiVersion":"authentication.k8s.io/v1","metadata":{"creationTimestamp":null},"spec":{},"status":{"user":{}}}` + "\n",
+		},
+		{
+			name:       "token of a user without a username",
+			path:       "/authenticate-tokens/jwtauthenticators/some-jwt-authenticator",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token-without-username"}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"kind":"TokenReview","apiVersion":"authentication.k8s.io/v1","metadata":{"creationTimestamp":null},"spec":{},"status":{"user":{}}}` + "\n",
+		},
+		{
+			name:       "authenticator which fails",
+			path:       "/authenticate-tokens/jwtauthenticators/some-jwt-authenticator",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"broken-token"}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"kind":"TokenReview","apiVersion":"authentication.k8s.io/v1","metadata":{"creationTimestamp":null},"spec":{},"status":{"user":{},"error":"authentication failed"}}` + "\n",
+		},
+		{
+			name:       "empty token",
+			path:       "/authenticate-tokens/jwtauthenticators/some-jwt-authenticator",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"kind":"TokenReview","apiVersion":"authentication.k8s.io/v1","metadata":{"creationTimestamp":null},"spec":{},"status":{"user":{},"error":"token must be supplied"}}` + "\n",
+		},
+		{
+			name:       "authenticator which does not exist",
+			path:       "/authenticate-tokens/jwtauthenticators/some-other-authenticator",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"good-token"}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"kind":"TokenReview","apiVersion":"authentication.k8s.io/v1","metadata":{"creationTimestamp":null},"spec":{},"status":{"user":{},"error":"no such authenticator"}}` + "\n",
+		},
+		{
+			name:       "unknown kind of authenticator",
+			path:       "/authenticate-tokens/ldapidentityproviders/some-jwt-authenticator",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"good-token"}}`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   "path must be /authenticate-tokens/<jwtauthenticators|webhookauthenticators>/<name>\n",
+		},
+		{
+			name:       "path without a name",
+			path:       "/authenticate-tokens/jwtauthenticators/",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"good-token"}}`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   "path must be /authenticate-tokens/<jwtauthenticators|webhookauthenticators>/<name>\n",
+		},
+		{
+			name:       "path with too many segments",
+			path:       "/authenticate-tokens/jwtauthenticators/some-jwt-authenticator/extra",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"good-token"}}`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   "path must be /authenticate-tokens/<jwtauthenticators|webhookauthenticators>/<name>\n",
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "method not allowed\n",
+		},
+		{
+			name:        "wrong content type",
+			contentType: "text/plain",
+			body:        `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"good-token"}}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantBody:    "content type must be application/json\n",
+		},
+		{
+			name:       "body which is not json",
+			body:       `not json`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "request body must be an authentication.k8s.io/v1 or v1beta1 TokenReview\n",
+		},
+		{
+			name:       "body which is not a token review",
+			body:       `{"apiVersion":"authentication.k8s.io/v1","kind":"SubjectAccessReview"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "request body must be an authentication.k8s.io/v1 or v1beta1 TokenReview\n",
+		},
+		{
+			name:       "token review of an unsupported version",
+			body:       `{"apiVersion":"authentication.k8s.io/v2","kind":"TokenReview","spec":{"token":"good-token"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "request body must be an authentication.k8s.io/v1 or v1beta1 TokenReview\n",
+		},
+		{
+			name:       "body which is too large",
+			body:       strings.Repeat(" ", maxRequestBodySize+1),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   "request body too large\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			path := tt.path
+			if path == "" {
+				path = "/authenticate-tokens/jwtauthenticators/some-jwt-authenticator"
+			}
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+
+			req := httptest.NewRequest(method, path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", contentType)
+			req = req.WithContext(authenticator.WithAudiences(req.Context(), authenticator.Audiences{"https://kubernetes.default.svc"}))
+			rsp := httptest.NewRecorder()
+			handler.ServeHTTP(rsp, req)
+
+			require.Equal(t, tt.wantStatus, rsp.Code)
+			require.Equal(t, tt.wantBody, rsp.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				require.Equal(t, "application/json", rsp.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package totp implements the time-based one-time passwords (TOTP) of authenticator apps, which the Supervisor
+// offers as a second factor.
+//
+// Only the parameters which are supported by practically all authenticator apps are used: a 160-bit secret,
+// HMAC-SHA1, six digits, and a period of 30 seconds.
+//
+// See https://www.rfc-editor.org/rfc/rfc6238 and https://www.rfc-editor.org/rfc/rfc4226.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // HMAC-SHA1 is the algorithm of RFC 6238 which all authenticator apps support.
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+const (
+	// SecretLength is the number of random bytes in a secret.
+	SecretLength = 20
+
+	// Digits is the number of decimal digits of a code.
+	Digits = 6
+
+	// Period is how long each code is valid.
+	Period = 30 * time.Second
+
+	// skew is the number of periods before and after the current period whose codes are also accepted, to allow
+	// for clocks which are slightly off and for users who type slowly.
+	skew = 1
+
+	ErrInvalidCode  = constable.Error("code is invalid")
+	ErrCodeReplayed = constable.Error("code was already used")
+)
+
+// NewSecret returns a random secret.
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, SecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("could not generate TOTP secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Counter returns the number of the period which contains the given time.
+func Counter(t time.Time) uint64 {
+	return uint64(t.Unix()) / uint64(Period/time.Second)
+}
+
+// Code returns the code of the given secret for the period with the given counter.
+func Code(secret []byte, counter uint64) string {
+	mac := hmac.New(sha1.New, secret)
+	_, _ = mac.Write(binary.BigEndian.AppendUint64(nil, counter))
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, see https://www.rfc-editor.org/rfc/rfc4226#section-5.3.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulus := uint32(1)
+	for i := 0; i < Digits; i++ {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%modulus)
+}
+
+// Validate checks whether the code was generated from the secret at a time close to now. To prevent replays, the
+// code must also be newer than the code which was most recently accepted for the secret, whose counter is
+// lastCounter, or zero when no code was accepted yet. It returns the counter of the accepted code, which should be
+// passed as lastCounter for the next validation.
+func Validate(secret []byte, code string, now time.Time, lastCounter uint64) (uint64, error) {
+	if len(code) != Digits {
+		return 0, ErrInvalidCode
+	}
+
+	current := Counter(now)
+	for counter := current - skew; counter <= current+skew; counter++ {
+		if subtle.ConstantTimeCompare([]byte(Code(secret, counter)), []byte(code)) != 1 {
+			continue
+		}
+		if counter <= lastCounter {
+			return 0, ErrCodeReplayed
+		}
+		return counter, nil
+	}
+	return 0, ErrInvalidCode
+}
+
+// ProvisioningURL returns the otpauth URL of the secret, which authenticator apps read from a QR code to enroll it.
+// The issuer and the account name are shown by the app to tell its codes apart.
+//
+// See https://github.com/google/google-authenticator/wiki/Key-Uri-Format.
+func ProvisioningURL(secret []byte, issuer, accountName string) string {
+	query := url.Values{}
+	query.Set("secret", EncodeSecret(secret))
+	query.Set("issuer", issuer)
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: query.Encode(),
+	}).String()
+}
+
+// EncodeSecret returns the base32 encoding of the secret, which users can type into an authenticator app when they
+// cannot scan the QR code.
+func EncodeSecret(secret []byte) string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+}
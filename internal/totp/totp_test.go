@@ -0,0 +1,142 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The secret of the test vectors of RFC 6238.
+var rfcSecret = []byte("12345678901234567890") //nolint:gochecknoglobals
+
+func TestCode(t *testing.T) {
+	// The test vectors of https://www.rfc-editor.org/rfc/rfc6238#appendix-B for SHA1, truncated to six digits.
+	tests := []struct {
+		unixTime int64
+		wantCode string
+	}{
+		{unixTime: 59, wantCode: "287082"},
+		{unixTime: 1111111109, wantCode: "081804"},
+		{unixTime: 1111111111, wantCode: "050471"},
+		{unixTime: 1234567890, wantCode: "005924"},
+		{unixTime: 2000000000, wantCode: "279037"},
+		{unixTime: 20000000000, wantCode: "353130"},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.wantCode, Code(rfcSecret, Counter(time.Unix(test.unixTime, 0))), "time %d", test.unixTime)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Counter(now)
+
+	tests := []struct {
+		name        string
+		code        string
+		lastCounter uint64
+		wantCounter uint64
+		wantErr     error
+	}{
+		{
+			name:        "current code",
+			code:        "005924",
+			wantCounter: current,
+		},
+		{
+			name:        "code of the previous period",
+			code:        Code(rfcSecret, current-1),
+			wantCounter: current - 1,
+		},
+		{
+			name:        "code of the next period",
+			code:        Code(rfcSecret, current+1),
+			wantCounter: current + 1,
+		},
+		{
+			name:    "code which is too old",
+			code:    Code(rfcSecret, current-2),
+			wantErr: ErrInvalidCode,
+		},
+		{
+			name:    "code which is too new",
+			code:    Code(rfcSecret, current+2),
+			wantErr: ErrInvalidCode,
+		},
+		{
+			name:        "replayed code",
+			code:        "005924",
+			lastCounter: current,
+			wantErr:     ErrCodeReplayed,
+		},
+		{
+			name:        "code which is older than the last accepted code",
+			code:        Code(rfcSecret, current-1),
+			lastCounter: current,
+			wantErr:     ErrCodeReplayed,
+		},
+		{
+			name:        "code which is newer than the last accepted code",
+			code:        "005924",
+			lastCounter: current - 1,
+			wantCounter: current,
+		},
+		{
+			name:    "wrong code",
+			code:    "123456",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			name:    "code with the wrong length",
+			code:    "05924",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			name:    "empty code",
+			wantErr: ErrInvalidCode,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			counter, err := Validate(rfcSecret, test.code, now, test.lastCounter)
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+				require.Zero(t, counter)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.wantCounter, counter)
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, SecretLength)
+
+	other, err := NewSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+}
+
+func TestProvisioningURL(t *testing.T) {
+	require.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", EncodeSecret(rfcSecret))
+
+	provisioningURL := ProvisioningURL(rfcSecret, "issuer.example.com", "pinny@example.com")
+	require.Equal(t,
+		"otpauth://totp/issuer.example.com:pinny@example.com?issuer=issuer.example.com&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		provisioningURL)
+
+	// Names with special characters are escaped.
+	parsed, err := url.Parse(ProvisioningURL(rfcSecret, "issuer.example.com", "cn=pinny, ou=users"))
+	require.NoError(t, err)
+	require.Equal(t, "/issuer.example.com:cn=pinny, ou=users", parsed.Path)
+	require.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", parsed.Query().Get("secret"))
+}
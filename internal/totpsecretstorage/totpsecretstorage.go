@@ -0,0 +1,214 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package totpsecretstorage stores the TOTP secrets which users have enrolled as a second factor. The secrets are
+// encrypted with a key of the Supervisor, so they cannot be used to generate codes by anyone who can only read the
+// Secrets in the Supervisor's namespace.
+package totpsecretstorage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/crud"
+)
+
+const (
+	TypeLabelValue = "totp-secret"
+
+	ErrTOTPSecretStorageVersion = constable.Error("TOTP secret storage data has wrong version")
+	ErrNoEncryptionKey          = constable.Error("TOTP encryption key is not available yet")
+	ErrDecryptionFailed         = constable.Error("TOTP secret could not be decrypted")
+
+	// Version 1 was the initial release of the TOTP second factor.
+	totpSecretStorageVersion = "1"
+)
+
+type TOTPSecretStorage struct {
+	storage crud.Storage
+	key     func() []byte
+}
+
+// storedTOTP defines the format of the TOTP secret of a user when stored in a Secret as a JSON string value.
+type storedTOTP struct {
+	Subject  string `json:"subject"`
+	Username string `json:"username"`
+	// The TOTP secret encrypted with AES-GCM, prefixed by the nonce.
+	EncryptedSecret   []byte       `json:"encryptedSecret"`
+	LastCounter       uint64       `json:"lastCounter"`
+	CreationTimestamp metav1.Time  `json:"created"`
+	LastUsedTimestamp *metav1.Time `json:"lastUsed,omitempty"`
+	// The format version. Take care when updating. We cannot simply bump the storage version and drop/ignore old data.
+	// Updating this would require some form of migration of existing stored data.
+	Version string `json:"version"`
+}
+
+// TOTP is the TOTP secret which a user has enrolled.
+type TOTP struct {
+	// Subject is the downstream subject of the user.
+	Subject string
+	// Username is the downstream username of the user when they enrolled the secret.
+	Username string
+	// Secret is the decrypted TOTP secret.
+	Secret []byte
+	// LastCounter is the counter of the code which was most recently accepted, to prevent replays of codes.
+	LastCounter uint64
+	// When the secret was enrolled.
+	CreationTimestamp metav1.Time
+	// When the secret was last used to log in.
+	LastUsedTimestamp *metav1.Time
+}
+
+// New returns the storage of the TOTP secrets, which are encrypted with the key which is returned by the key func.
+func New(secrets corev1client.SecretInterface, key func() []byte) *TOTPSecretStorage {
+	return &TOTPSecretStorage{
+		storage: crud.New(TypeLabelValue, secrets, nil, 0), // can use nil clock because we are using infinite lifetime
+		key:     key,
+	}
+}
+
+// Get returns the resourceVersion of the storage secret, the TOTP secret of the user with the given downstream
+// subject, and an error. When the storage secret is not found, it will simply return "", nil, nil to make it easy to
+// pass the results of Get directly to Set.
+func (s *TOTPSecretStorage) Get(ctx context.Context, subject string) (string, *TOTP, error) {
+	stored := &storedTOTP{}
+	rv, err := s.storage.Get(ctx, subjectToName(subject), stored)
+	if errors.IsNotFound(err) {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get TOTP secret: %w", err)
+	}
+	if err := validateVersion(stored); err != nil {
+		return "", nil, err
+	}
+
+	aead, err := s.aead()
+	if err != nil {
+		return "", nil, err
+	}
+	nonceSize := aead.NonceSize()
+	if len(stored.EncryptedSecret) < nonceSize {
+		return "", nil, ErrDecryptionFailed
+	}
+	secret, err := aead.Open(nil, stored.EncryptedSecret[:nonceSize], stored.EncryptedSecret[nonceSize:], []byte(subject))
+	if err != nil {
+		return "", nil, ErrDecryptionFailed
+	}
+
+	totp := fromStored(stored)
+	totp.Secret = secret
+	return rv, totp, nil
+}
+
+// Set will create or update the TOTP secret of the user.
+// Set takes the resourceVersion to know if we are doing a create or update and to ensure we do not edit an old
+// version of the storage secret.
+func (s *TOTPSecretStorage) Set(ctx context.Context, resourceVersion string, totp *TOTP) error {
+	aead, err := s.aead()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("could not generate nonce: %w", err)
+	}
+
+	// The subject is authenticated as additional data, so an encrypted secret cannot be copied to another user.
+	stored := &storedTOTP{
+		Subject:           totp.Subject,
+		Username:          totp.Username,
+		EncryptedSecret:   aead.Seal(nonce, nonce, totp.Secret, []byte(totp.Subject)),
+		LastCounter:       totp.LastCounter,
+		CreationTimestamp: totp.CreationTimestamp,
+		LastUsedTimestamp: totp.LastUsedTimestamp,
+		Version:           totpSecretStorageVersion,
+	}
+	name := subjectToName(totp.Subject)
+
+	if mustBeCreate := len(resourceVersion) == 0; mustBeCreate {
+		if _, err := s.storage.Create(ctx, name, stored, nil, nil); err != nil {
+			return fmt.Errorf("failed to create TOTP secret: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := s.storage.Update(ctx, name, resourceVersion, stored); err != nil {
+		return fmt.Errorf("failed to update TOTP secret: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the TOTP secret of the user with the given downstream subject, so the user must enroll a new one.
+// It is not an error when the user has no TOTP secret.
+func (s *TOTPSecretStorage) Delete(ctx context.Context, subject string) error {
+	if err := s.storage.Delete(ctx, subjectToName(subject)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete TOTP secret: %w", err)
+	}
+	return nil
+}
+
+// GetName returns the name of the Secret which would be used to store the TOTP secret of the given subject.
+func (s *TOTPSecretStorage) GetName(subject string) string {
+	return s.storage.GetName(subjectToName(subject))
+}
+
+// ReadFromSecret reads the TOTP secret of a user from a storage Secret, without decrypting it, i.e. the Secret field
+// of the result is nil.
+func ReadFromSecret(secret *corev1.Secret) (*TOTP, error) {
+	stored := &storedTOTP{}
+	if err := crud.FromSecret(TypeLabelValue, secret, stored); err != nil {
+		return nil, err
+	}
+	if err := validateVersion(stored); err != nil {
+		return nil, err
+	}
+	return fromStored(stored), nil
+}
+
+func (s *TOTPSecretStorage) aead() (cipher.AEAD, error) {
+	key := s.key()
+	if len(key) == 0 {
+		return nil, ErrNoEncryptionKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func validateVersion(stored *storedTOTP) error {
+	if stored.Version != totpSecretStorageVersion {
+		return fmt.Errorf("%w: TOTP secret storage has version %s instead of %s",
+			ErrTOTPSecretStorageVersion, stored.Version, totpSecretStorageVersion)
+	}
+	return nil
+}
+
+func fromStored(stored *storedTOTP) *TOTP {
+	return &TOTP{
+		Subject:           stored.Subject,
+		Username:          stored.Username,
+		LastCounter:       stored.LastCounter,
+		CreationTimestamp: stored.CreationTimestamp,
+		LastUsedTimestamp: stored.LastUsedTimestamp,
+	}
+}
+
+func subjectToName(subject string) string {
+	// Downstream subjects can be too long for the name of a Secret, so use their hash.
+	sum := sha256.Sum256([]byte(subject))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package totpsecretstorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testNamespace = "some-namespace"
+	testSubject   = "ldaps://ldap.example.com:636?base=ou%3Dusers%2Cdc%3Dexample%2Cdc%3Dcom&sub=c29tZS11aWQ"
+)
+
+var testKey = []byte("some-neato-32-byte-generated-key") //nolint:gochecknoglobals
+
+func TestGetSetAndDelete(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	storage := New(client.CoreV1().Secrets(testNamespace), func() []byte { return testKey })
+
+	// A user without a TOTP secret.
+	rv, totp, err := storage.Get(ctx, testSubject)
+	require.NoError(t, err)
+	require.Empty(t, rv)
+	require.Nil(t, totp)
+
+	enrolled := &TOTP{
+		Subject:           testSubject,
+		Username:          "pinny",
+		Secret:            []byte("12345678901234567890"),
+		LastCounter:       42,
+		CreationTimestamp: metav1.NewTime(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC).Local()),
+	}
+	require.NoError(t, storage.Set(ctx, rv, enrolled))
+
+	secret, err := client.CoreV1().Secrets(testNamespace).Get(ctx, storage.GetName(testSubject), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, corev1.SecretType("storage.pinniped.dev/totp-secret"), secret.Type)
+	require.Equal(t, "totp-secret", secret.Labels["storage.pinniped.dev/type"])
+	require.Empty(t, secret.Annotations) // the secrets never expire
+	require.LessOrEqual(t, len(secret.Name), 253)
+
+	// The TOTP secret is only stored encrypted.
+	data := secret.Data["pinniped-storage-data"]
+	require.False(t, bytes.Contains(data, enrolled.Secret))
+	var stored map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &stored))
+	require.Equal(t, map[string]interface{}{
+		"subject":         testSubject,
+		"username":        "pinny",
+		"encryptedSecret": stored["encryptedSecret"],
+		"lastCounter":     float64(42),
+		"created":         "2023-01-02T03:04:05Z",
+		"version":         "1",
+	}, stored)
+
+	// The fake client does not assign resource versions.
+	secret.ResourceVersion = "123"
+	secret, err = client.CoreV1().Secrets(testNamespace).Update(ctx, secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	rv, totp, err = storage.Get(ctx, testSubject)
+	require.NoError(t, err)
+	require.Equal(t, "123", rv)
+	require.Equal(t, enrolled, totp)
+
+	// The Secret can be read without the key, but without the TOTP secret.
+	read, err := ReadFromSecret(secret)
+	require.NoError(t, err)
+	withoutSecret := *enrolled
+	withoutSecret.Secret = nil
+	require.Equal(t, &withoutSecret, read)
+
+	// Another user has their own TOTP secret.
+	_, totp, err = storage.Get(ctx, testSubject+"-other")
+	require.NoError(t, err)
+	require.Nil(t, totp)
+
+	lastUsed := metav1.NewTime(time.Date(2023, 2, 3, 4, 5, 6, 0, time.UTC).Local())
+	enrolled.LastCounter = 43
+	enrolled.LastUsedTimestamp = &lastUsed
+	require.NoError(t, storage.Set(ctx, rv, enrolled))
+
+	_, totp, err = storage.Get(ctx, testSubject)
+	require.NoError(t, err)
+	require.Equal(t, enrolled, totp)
+
+	require.NoError(t, storage.Delete(ctx, testSubject))
+	rv, totp, err = storage.Get(ctx, testSubject)
+	require.NoError(t, err)
+	require.Empty(t, rv)
+	require.Nil(t, totp)
+
+	// Deleting is idempotent.
+	require.NoError(t, storage.Delete(ctx, testSubject))
+}
+
+func TestEncryption(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	key := []byte(nil)
+	storage := New(client.CoreV1().Secrets(testNamespace), func() []byte { return key })
+
+	enrolled := &TOTP{Subject: testSubject, Username: "pinny", Secret: []byte("12345678901234567890")}
+	require.ErrorIs(t, storage.Set(ctx, "", enrolled), ErrNoEncryptionKey)
+
+	key = testKey
+	require.NoError(t, storage.Set(ctx, "", enrolled))
+
+	key = nil
+	_, _, err := storage.Get(ctx, testSubject)
+	require.ErrorIs(t, err, ErrNoEncryptionKey)
+
+	key = []byte("some-other-32-byte-generated-key")
+	_, _, err = storage.Get(ctx, testSubject)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// The encrypted secret of one user cannot be used for another user.
+	key = testKey
+	secret, err := client.CoreV1().Secrets(testNamespace).Get(ctx, storage.GetName(testSubject), metav1.GetOptions{})
+	require.NoError(t, err)
+	secret.Name = storage.GetName(testSubject + "-other")
+	_, err = client.CoreV1().Secrets(testNamespace).Create(ctx, secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, _, err = storage.Get(ctx, testSubject+"-other")
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestGetWrongVersion(t *testing.T) {
+	ctx := context.Background()
+	storage := New(fake.NewSimpleClientset().CoreV1().Secrets(testNamespace), func() []byte { return testKey })
+
+	_, err := storage.storage.Create(ctx, subjectToName(testSubject), &storedTOTP{Version: "2"}, nil, nil)
+	require.NoError(t, err)
+
+	rv, totp, err := storage.Get(ctx, testSubject)
+	require.EqualError(t, err, "TOTP secret storage data has wrong version: TOTP secret storage has version 2 instead of 1")
+	require.Empty(t, rv)
+	require.Nil(t, totp)
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tracing provides OpenTelemetry tracing of the Supervisor's login flows, so that slow logins can be
+// attributed to either the upstream identity provider or the Supervisor itself.
+//
+// Each HTTP request of a login flow has its own trace. The requests of a single login are correlated by their
+// attributes: the authorize, login, and callback requests of a browser login share a login ID, and the callback
+// or CLI authorize request which starts a downstream session shares the session ID with the token requests which
+// exchange its authcode. When tracing is not configured, all spans are discarded.
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	instrumentationName = "go.pinniped.dev/internal/tracing"
+	serviceName         = "pinniped-supervisor"
+)
+
+// The attributes which are added to the spans of login flows.
+const (
+	SessionIDKey    = attribute.Key("pinniped.session_id")
+	LoginIDKey      = attribute.Key("pinniped.login_id")
+	ClientIDKey     = attribute.Key("pinniped.client_id")
+	UpstreamNameKey = attribute.Key("pinniped.upstream_name")
+	GrantTypeKey    = attribute.Key("pinniped.grant_type")
+)
+
+// Setup sends the spans of all login flows to the OTLP gRPC collector at endpoint, i.e. a host:port. When tlsConfig
+// is nil, the connection to the collector does not use TLS. Only the given ratio of traces are sampled, unless the
+// client which made the request has already decided whether its trace is sampled. The returned function flushes
+// any pending spans and stops sending spans.
+func Setup(ctx context.Context, endpoint string, tlsConfig *tls.Config, samplingRatio float64) (func(context.Context) error, error) {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if tlsConfig == nil {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	} else {
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tracerProvider.Shutdown, nil
+}
+
+// WrapHandler starts a span named operation for each request to handler.
+func WrapHandler(handler http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(handler, operation)
+}
+
+// Start starts a child of the span in ctx to measure how long an operation takes, e.g. a call to an upstream
+// identity provider. The span must be ended by calling End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) trace.Span {
+	_, span := otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+	return span
+}
+
+// End records err, when it is not nil, as the reason that the span failed, and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SetAttributes adds attributes to the span in ctx, i.e. usually the span of the whole request.
+func SetAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+}
+
+// AddEvent adds an event to the span in ctx, i.e. usually the span of the whole request.
+func AddEvent(ctx context.Context, name string, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+// LoginID returns an attribute which identifies a browser login by the nonce in its upstream state param.
+// The nonce is a secret, so it is hashed.
+func LoginID(nonce string) attribute.KeyValue {
+	sum := sha256.Sum256([]byte(nonce))
+	return LoginIDKey.String(hex.EncodeToString(sum[:16]))
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpansOfRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousTracerProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previousTracerProvider) })
+
+	handler := WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetAttributes(r.Context(), LoginID("some-nonce"), ClientIDKey.String("some-client"))
+
+		span := Start(r.Context(), "upstream token exchange", UpstreamNameKey.String("some-upstream"))
+		End(span, errors.New("some upstream error"))
+
+		AddEvent(r.Context(), "redirecting to upstream identity provider")
+		w.WriteHeader(http.StatusSeeOther)
+	}), "callback")
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/callback", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	upstreamSpan, requestSpan := spans[0], spans[1]
+	require.Equal(t, "upstream token exchange", upstreamSpan.Name())
+	require.Equal(t, requestSpan.SpanContext().SpanID(), upstreamSpan.Parent().SpanID())
+	require.Contains(t, upstreamSpan.Attributes(), UpstreamNameKey.String("some-upstream"))
+	require.Equal(t, sdktrace.Status{Code: codes.Error, Description: "some upstream error"}, upstreamSpan.Status())
+	require.Len(t, upstreamSpan.Events(), 1) // the recorded error
+
+	require.Equal(t, "callback", requestSpan.Name())
+	require.Contains(t, requestSpan.Attributes(), LoginIDKey.String("a5256cc9606ce3db6c046fbf63585843"))
+	require.Contains(t, requestSpan.Attributes(), ClientIDKey.String("some-client"))
+	require.Len(t, requestSpan.Events(), 1)
+	require.Equal(t, "redirecting to upstream identity provider", requestSpan.Events()[0].Name)
+}
+
+func TestLoginID(t *testing.T) {
+	require.Equal(t, LoginID("some-nonce"), LoginID("some-nonce"))
+	require.NotEqual(t, LoginID("some-nonce"), LoginID("some-other-nonce"))
+	require.Len(t, LoginID("some-nonce").Value.AsString(), 32)
+}
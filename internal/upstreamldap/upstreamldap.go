@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package upstreamldap implements an abstraction of upstream LDAP IDP interactions.
@@ -27,6 +27,7 @@ import (
 	"go.pinniped.dev/internal/authenticators"
 	"go.pinniped.dev/internal/crypto/ptls"
 	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/grouptransform"
 	"go.pinniped.dev/internal/oidc/downstreamsession"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/plog"
@@ -102,6 +103,13 @@ type ProviderConfig struct {
 	// BindPassword is the password to use when performing a bind with the upstream LDAP IDP.
 	BindPassword string
 
+	// PreviousBindUsername and PreviousBindPassword are the bind credentials which were used before the bind Secret
+	// was recently changed, or empty otherwise. While the credentials are being rotated, the Secret and the upstream
+	// LDAP IDP might not agree on the new credentials yet, so a bind which fails with the current credentials because
+	// they are invalid is retried with the previous credentials, to avoid failing logins and refreshes.
+	PreviousBindUsername string
+	PreviousBindPassword string
+
 	// UserSearch contains information about how to search for users in the upstream LDAP IDP.
 	UserSearch UserSearchConfig
 
@@ -157,6 +165,10 @@ type GroupSearchConfig struct {
 	// (every 5 minutes). This can be done if group search is very slow or resource intensive for the LDAP
 	// server.
 	SkipGroupRefresh bool
+
+	// Transformations transform the group names which were found by the group search, e.g. to strip them down to
+	// their common names. Empty means to use the group names as they were found.
+	Transformations grouptransform.Transformations
 }
 
 type Provider struct {
@@ -177,79 +189,115 @@ func (p *Provider) GetConfig() ProviderConfig {
 	return p.c
 }
 
-func (p *Provider) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, error) {
+func (p *Provider) PerformRefresh(ctx context.Context, storedRefreshAttributes provider.RefreshAttributes) ([]string, string, error) {
 	t := trace.FromContext(ctx).Nest("slow ldap refresh attempt", trace.Field{Key: "providerName", Value: p.GetName()})
 	defer t.LogIfLong(500 * time.Millisecond) // to help users debug slow LDAP searches
 	userDN := storedRefreshAttributes.DN
 
 	conn, err := p.dial(ctx)
 	if err != nil {
-		return nil, fmt.Errorf(`error dialing host %q: %w`, p.c.Host, err)
+		return nil, "", fmt.Errorf(`error dialing host %q: %w`, p.c.Host, err)
 	}
 	defer conn.Close()
 
-	err = conn.Bind(p.c.BindUsername, p.c.BindPassword)
+	err = p.bindAsServiceAccount(conn)
 	if err != nil {
-		return nil, fmt.Errorf(`error binding as %q before user search: %w`, p.c.BindUsername, err)
+		return nil, "", fmt.Errorf(`error binding as %q before user search: %w`, p.c.BindUsername, err)
 	}
 
 	searchResult, err := p.performUserRefreshSearch(conn, userDN)
 	if err != nil {
 		p.traceRefreshFailure(t, err)
-		return nil, err
+		return nil, "", err
+	}
+
+	if len(searchResult.Entries) == 0 && p.canFindMovedUsers() {
+		// The user's DN has changed since they logged in, e.g. because they were moved or renamed in the directory,
+		// so look them up by their username instead. The subject is compared below, so the user is only found when
+		// they still have the same value of the UID attribute, which should be an immutable attribute.
+		searchResult, err = p.performMovedUserRefreshSearch(conn, storedRefreshAttributes.Username)
+		if err != nil {
+			p.traceRefreshFailure(t, err)
+			return nil, "", err
+		}
 	}
 
 	// if any more or less than one entry, error.
 	// we don't need to worry about logging this because we know it's a dn.
 	if len(searchResult.Entries) != 1 {
-		return nil, fmt.Errorf(`searching for user %q resulted in %d search results, but expected 1 result`,
+		return nil, "", fmt.Errorf(`searching for user %q resulted in %d search results, but expected 1 result`,
 			userDN, len(searchResult.Entries),
 		)
 	}
 
 	userEntry := searchResult.Entries[0]
 	if len(userEntry.DN) == 0 {
-		return nil, fmt.Errorf(`searching for user with original DN %q resulted in search result without DN`, userDN)
+		return nil, "", fmt.Errorf(`searching for user with original DN %q resulted in search result without DN`, userDN)
 	}
 
 	newUsername, err := p.getSearchResultAttributeValue(p.c.UserSearch.UsernameAttribute, userEntry, userDN)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	if newUsername != storedRefreshAttributes.Username {
-		return nil, fmt.Errorf(`searching for user %q returned a different username than the previous value. expected: %q, actual: %q`,
+		return nil, "", fmt.Errorf(`searching for user %q returned a different username than the previous value. expected: %q, actual: %q`,
 			userDN, storedRefreshAttributes.Username, newUsername,
 		)
 	}
 
 	newUID, err := p.getSearchResultAttributeRawValueEncoded(p.c.UserSearch.UIDAttribute, userEntry, userDN)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	newSubject := downstreamsession.DownstreamLDAPSubject(newUID, *p.GetURL())
-	if newSubject != storedRefreshAttributes.Subject {
-		return nil, fmt.Errorf(`searching for user %q produced a different subject than the previous value. expected: %q, actual: %q`, userDN, storedRefreshAttributes.Subject, newSubject)
+	if newSubject != storedRefreshAttributes.Subject && !p.isSubjectOfUnmovedUserFromDN(storedRefreshAttributes, userEntry) {
+		return nil, "", fmt.Errorf(`searching for user %q produced a different subject than the previous value. expected: %q, actual: %q`, userDN, storedRefreshAttributes.Subject, newSubject)
 	}
 	for attribute, validateFunc := range p.c.RefreshAttributeChecks {
 		err = validateFunc(userEntry, storedRefreshAttributes)
 		if err != nil {
-			return nil, fmt.Errorf(`validation for attribute %q failed during upstream refresh: %w`, attribute, err)
+			return nil, "", fmt.Errorf(`validation for attribute %q failed during upstream refresh: %w`, attribute, err)
 		}
 	}
 
+	if userEntry.DN != userDN {
+		plog.Info("user's DN has changed since login",
+			"providerName", p.GetName(), "username", newUsername, "previousDN", userDN, "newDN", userEntry.DN)
+		userDN = userEntry.DN
+	}
+
 	if p.c.GroupSearch.SkipGroupRefresh {
-		return storedRefreshAttributes.Groups, nil
+		return storedRefreshAttributes.Groups, userDN, nil
 	}
 	// if we were not granted the groups scope, we should not search for groups or return any.
 	if !slices.Contains(storedRefreshAttributes.GrantedScopes, oidcapi.ScopeGroups) {
-		return nil, nil
+		return nil, userDN, nil
 	}
 
 	mappedGroupNames, err := p.searchGroupsForUserDN(conn, userDN)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	return mappedGroupNames, userDN, nil
+}
+
+// canFindMovedUsers returns whether users whose DN has changed since they logged in can be found during refreshes,
+// which is only possible when users are not identified by their DN.
+func (p *Provider) canFindMovedUsers() bool {
+	return p.c.UserSearch.UIDAttribute != distinguishedNameAttributeName &&
+		p.c.UserSearch.UsernameAttribute != distinguishedNameAttributeName
+}
+
+// isSubjectOfUnmovedUserFromDN returns whether the stored subject was made from the user's DN, which is the case for
+// sessions which were started while the UID attribute was the DN, and whether the user still has that DN. This
+// allows those sessions to be refreshed after the UID attribute is changed to an immutable attribute. They keep their
+// subject until the user logs in again.
+func (p *Provider) isSubjectOfUnmovedUserFromDN(storedRefreshAttributes provider.RefreshAttributes, userEntry *ldap.Entry) bool {
+	if userEntry.DN != storedRefreshAttributes.DN {
+		return false
 	}
-	return mappedGroupNames, nil
+	subjectFromDN := downstreamsession.DownstreamLDAPSubject(base64.RawURLEncoding.EncodeToString([]byte(userEntry.DN)), *p.GetURL())
+	return storedRefreshAttributes.Subject == subjectFromDN
 }
 
 func (p *Provider) performUserRefreshSearch(conn Conn, userDN string) (*ldap.SearchResult, error) {
@@ -258,11 +306,23 @@ func (p *Provider) performUserRefreshSearch(conn Conn, userDN string) (*ldap.Sea
 	searchResult, err := conn.Search(search)
 
 	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			// The user's DN does not exist anymore.
+			return &ldap.SearchResult{}, nil
+		}
 		return nil, fmt.Errorf(`error searching for user %q: %w`, userDN, err)
 	}
 	return searchResult, nil
 }
 
+func (p *Provider) performMovedUserRefreshSearch(conn Conn, username string) (*ldap.SearchResult, error) {
+	searchResult, err := conn.Search(p.movedUserRefreshSearchRequest(username))
+	if err != nil {
+		return nil, fmt.Errorf(`error searching for moved user %q: %w`, username, err)
+	}
+	return searchResult, nil
+}
+
 func (p *Provider) dial(ctx context.Context) (Conn, error) {
 	tlsAddr, err := endpointaddr.Parse(p.c.Host, defaultLDAPSPort)
 	if err != nil {
@@ -343,6 +403,23 @@ func (p *Provider) dialStartTLS(ctx context.Context, addr endpointaddr.HostPort)
 	return conn, nil
 }
 
+// bindAsServiceAccount binds using the bind credentials. When the current credentials are invalid and the bind Secret
+// was recently changed, it binds using the previous credentials instead, since the credentials in the upstream LDAP
+// IDP might not have been changed yet. The error of the current credentials is returned when both binds fail.
+// TestConnection does not use this, because it should only succeed when the current credentials work.
+func (p *Provider) bindAsServiceAccount(conn Conn) error {
+	err := conn.Bind(p.c.BindUsername, p.c.BindPassword)
+	if err == nil || p.c.PreviousBindUsername == "" || !ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return err
+	}
+	if previousErr := conn.Bind(p.c.PreviousBindUsername, p.c.PreviousBindPassword); previousErr != nil {
+		return err
+	}
+	plog.Info("bound to the upstream LDAP IDP using the previous bind credentials because the current bind credentials are invalid",
+		"providerName", p.GetName(), "bindUsername", p.c.BindUsername, "previousBindUsername", p.c.PreviousBindUsername)
+	return nil
+}
+
 func netDialer() *net.Dialer {
 	return &net.Dialer{Timeout: time.Minute}
 }
@@ -443,7 +520,7 @@ func (p *Provider) authenticateUserImpl(ctx context.Context, username string, gr
 	}
 	defer conn.Close()
 
-	err = conn.Bind(p.c.BindUsername, p.c.BindPassword)
+	err = p.bindAsServiceAccount(conn)
 	if err != nil {
 		p.traceAuthFailure(t, err)
 		return nil, false, fmt.Errorf(`error binding as %q before user search: %w`, p.c.BindUsername, err)
@@ -500,6 +577,10 @@ entries:
 		}
 		groups = append(groups, mappedGroupName)
 	}
+	groups, err = p.c.GroupSearch.Transformations.Apply(groups)
+	if err != nil {
+		return nil, fmt.Errorf(`error transforming group memberships for user with DN %q: %w`, userDN, err)
+	}
 	// de-duplicate the list of groups by turning it into a set,
 	// then turn it back into a sorted list.
 	return sets.NewString(groups...).List(), nil
@@ -524,7 +605,7 @@ func (p *Provider) SearchForDefaultNamingContext(ctx context.Context) (string, e
 	}
 	defer conn.Close()
 
-	err = conn.Bind(p.c.BindUsername, p.c.BindPassword)
+	err = p.bindAsServiceAccount(conn)
 	if err != nil {
 		p.traceSearchBaseDiscoveryFailure(t, err)
 		return "", fmt.Errorf(`error binding as %q before querying for defaultNamingContext: %w`, p.c.BindUsername, err)
@@ -698,6 +779,21 @@ func (p *Provider) refreshUserSearchRequest(dn string) *ldap.SearchRequest {
 	}
 }
 
+func (p *Provider) movedUserRefreshSearchRequest(username string) *ldap.SearchRequest {
+	// See https://ldap.com/the-ldap-search-operation for general documentation of LDAP search options.
+	return &ldap.SearchRequest{
+		BaseDN:       p.c.UserSearch.Base,
+		Scope:        ldap.ScopeWholeSubtree,
+		DerefAliases: ldap.NeverDerefAliases,
+		SizeLimit:    2,
+		TimeLimit:    90,
+		TypesOnly:    false,
+		Filter:       fmt.Sprintf("(%s=%s)", p.c.UserSearch.UsernameAttribute, ldap.EscapeFilter(username)),
+		Attributes:   p.userSearchRequestedAttributes(),
+		Controls:     nil, // this could be used to enable paging, but we're already limiting the result max size
+	}
+}
+
 func (p *Provider) userSearchRequestedAttributes() []string {
 	attributes := make([]string, 0, len(p.c.RefreshAttributeChecks)+2)
 	if p.c.UserSearch.UsernameAttribute != distinguishedNameAttributeName {
@@ -21,10 +21,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"k8s.io/apiserver/pkg/authentication/user"
 
+	"go.pinniped.dev/generated/latest/apis/supervisor/idp/v1alpha1"
 	"go.pinniped.dev/internal/authenticators"
 	"go.pinniped.dev/internal/certauthority"
 	"go.pinniped.dev/internal/crypto/ptls"
 	"go.pinniped.dev/internal/endpointaddr"
+	"go.pinniped.dev/internal/grouptransform"
 	"go.pinniped.dev/internal/mocks/mockldapconn"
 	"go.pinniped.dev/internal/oidc/provider"
 	"go.pinniped.dev/internal/testutil"
@@ -653,6 +655,74 @@ func TestEndUserAuthentication(t *testing.T) {
 				ExtraRefreshAttributes: map[string]string{},
 			},
 		},
+		{
+			name:     "when the group search has transformations then they are applied to the group names",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.Transformations = mustCompileGroupTransformations(t, []v1alpha1.GroupTransformation{
+					{Type: v1alpha1.GroupTransformationTypeDrop, Pattern: "b"},
+					{Type: v1alpha1.GroupTransformationTypeReplace, Pattern: "(.*)", Replacement: "group-$1"},
+				})
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(exampleUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).
+					Return(&ldap.SearchResult{
+						Entries: []*ldap.Entry{
+							{
+								DN: testGroupSearchResultDNValue1,
+								Attributes: []*ldap.EntryAttribute{
+									ldap.NewEntryAttribute(testGroupSearchGroupNameAttribute, []string{"c"}),
+								},
+							},
+							{
+								DN: testGroupSearchResultDNValue2,
+								Attributes: []*ldap.EntryAttribute{
+									ldap.NewEntryAttribute(testGroupSearchGroupNameAttribute, []string{"b"}),
+								},
+							},
+							{
+								DN: testGroupSearchResultDNValue2,
+								Attributes: []*ldap.EntryAttribute{
+									ldap.NewEntryAttribute(testGroupSearchGroupNameAttribute, []string{"a"}),
+								},
+							},
+						},
+						Referrals: []string{}, // note that we are not following referrals at this time
+						Controls:  []ldap.Control{},
+					}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			bindEndUserMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+			},
+			wantAuthResponse: expectedAuthResponse(func(r *authenticators.Response) {
+				info := r.User.(*user.DefaultInfo)
+				info.Groups = []string{"group-a", "group-c"}
+			}),
+		},
+		{
+			name:     "when the group search transformations fail",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.GroupSearch.Transformations = mustCompileGroupTransformations(t, []v1alpha1.GroupTransformation{
+					{Type: v1alpha1.GroupTransformationTypeExpression, Expression: "group.substring(100)"},
+				})
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(exampleUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).
+					Return(exampleGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantError: testutil.WantSprintfErrorString(
+				`error transforming group memberships for user with DN "%s": could not transform group "%s": could not evaluate expression: index out of range: 100`,
+				testUserSearchResultDNValue, testGroupSearchResultGroupNameAttributeValue1),
+		},
 		{
 			name:     "requesting additional refresh related attributes",
 			username: testUpstreamUsername,
@@ -743,6 +813,59 @@ func TestEndUserAuthentication(t *testing.T) {
 			},
 			wantError: testutil.WantSprintfErrorString(`error binding as "%s" before user search: some bind error`, testBindUsername),
 		},
+		{
+			name:     "when the bind credentials are invalid but the previous bind credentials work during a rotation",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.PreviousBindUsername = "some-previous-bind-username"
+				p.PreviousBindPassword = "some-previous-bind-password"
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).
+					Return(&ldap.Error{Err: errors.New("some bind error"), ResultCode: ldap.LDAPResultInvalidCredentials}).Times(1)
+				conn.EXPECT().Bind("some-previous-bind-username", "some-previous-bind-password").Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(exampleUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).
+					Return(exampleGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			bindEndUserMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testUserSearchResultDNValue, testUpstreamPassword).Times(1)
+			},
+			wantAuthResponse: expectedAuthResponse(nil),
+		},
+		{
+			name:     "when the bind credentials and the previous bind credentials are both invalid",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.PreviousBindUsername = "some-previous-bind-username"
+				p.PreviousBindPassword = "some-previous-bind-password"
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).
+					Return(&ldap.Error{Err: errors.New("some bind error"), ResultCode: ldap.LDAPResultInvalidCredentials}).Times(1)
+				conn.EXPECT().Bind("some-previous-bind-username", "some-previous-bind-password").
+					Return(&ldap.Error{Err: errors.New("some other bind error"), ResultCode: ldap.LDAPResultInvalidCredentials}).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantError: testutil.WantSprintfErrorString(`error binding as "%s" before user search: LDAP Result Code 49 "Invalid Credentials": some bind error`, testBindUsername),
+		},
+		{
+			name:     "when binding with the bind credentials returns an error other than invalid credentials during a rotation",
+			username: testUpstreamUsername,
+			password: testUpstreamPassword,
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.PreviousBindUsername = "some-previous-bind-username"
+				p.PreviousBindPassword = "some-previous-bind-password"
+			}),
+			searchMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Return(errors.New("some bind error")).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantError: testutil.WantSprintfErrorString(`error binding as "%s" before user search: some bind error`, testBindUsername),
+		},
 		{
 			name:           "when searching for the user returns an error",
 			username:       testUpstreamUsername,
@@ -1305,6 +1428,33 @@ func TestUpstreamRefresh(t *testing.T) {
 		},
 	}
 
+	movedUserDN := "some-moved-upstream-user-dn"
+
+	expectedMovedUserSearch := &ldap.SearchRequest{
+		BaseDN:       testUserSearchBase,
+		Scope:        ldap.ScopeWholeSubtree,
+		DerefAliases: ldap.NeverDerefAliases,
+		SizeLimit:    2,
+		TimeLimit:    90,
+		TypesOnly:    false,
+		Filter:       "(" + testUserSearchUsernameAttribute + "=" + testUserSearchResultUsernameAttributeValue + ")",
+		Attributes:   []string{testUserSearchUsernameAttribute, testUserSearchUIDAttribute, pwdLastSetAttribute},
+		Controls:     nil, // don't need paging because we set the SizeLimit so small
+	}
+
+	movedUserSearchResult := &ldap.SearchResult{
+		Entries: []*ldap.Entry{
+			{
+				DN:         movedUserDN,
+				Attributes: happyPathUserSearchResult.Entries[0].Attributes,
+			},
+		},
+	}
+
+	// The subject of sessions which were started while the DN was used as the UID attribute.
+	subjectFromDN := "ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=" +
+		base64.RawURLEncoding.EncodeToString([]byte(testUserSearchResultDNValue))
+
 	happyPathGroupSearchResult := &ldap.SearchResult{
 		Entries: []*ldap.Entry{
 			{
@@ -1358,9 +1508,11 @@ func TestUpstreamRefresh(t *testing.T) {
 		grantedScopes  []string
 		setupMocks     func(conn *mockldapconn.MockConn)
 		refreshUserDN  string
+		refreshSubject string
 		dialError      error
 		wantErr        string
 		wantGroups     []string
+		wantDN         string
 	}{
 		{
 			name: "happy path without group search where searching the dn returns a single entry",
@@ -1463,6 +1615,33 @@ func TestUpstreamRefresh(t *testing.T) {
 			refreshUserDN: testUserDNWithSpecialChars,
 			wantGroups:    []string{testGroupSearchResultGroupNameAttributeValue1, testGroupSearchResultGroupNameAttributeValue2},
 		},
+		{
+			name:           "happy path when the user was moved to a new DN since login then they are found by their username",
+			providerConfig: providerConfig(nil),
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))).Times(1)
+				conn.EXPECT().Search(expectedMovedUserSearch).Return(movedUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(func(r *ldap.SearchRequest) {
+					r.Filter = fmt.Sprintf("(some-group-filter=%s-and-more-filter=%s)", movedUserDN, movedUserDN)
+				}), expectedGroupSearchPageSize).Return(happyPathGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantGroups: []string{testGroupSearchResultGroupNameAttributeValue1, testGroupSearchResultGroupNameAttributeValue2},
+			wantDN:     movedUserDN,
+		},
+		{
+			name:           "happy path when the session was started while the UID attribute was the DN and the user was not moved",
+			providerConfig: providerConfig(nil),
+			refreshSubject: subjectFromDN,
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(happyPathUserSearchResult, nil).Times(1)
+				conn.EXPECT().SearchWithPaging(expectedGroupSearch(nil), expectedGroupSearchPageSize).Return(happyPathGroupSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantGroups: []string{testGroupSearchResultGroupNameAttributeValue1, testGroupSearchResultGroupNameAttributeValue2},
+		},
 		{
 			name:           "happy path where group search returns no groups",
 			providerConfig: providerConfig(nil),
@@ -1517,17 +1696,78 @@ func TestUpstreamRefresh(t *testing.T) {
 			wantErr: "error binding as \"cn=some-bind-username,dc=pinniped,dc=dev\" before user search: some bind error",
 		},
 		{
-			name:           "search result returns no entries",
+			name:           "search result returns no entries and searching by username returns no entries",
 			providerConfig: providerConfig(nil),
 			setupMocks: func(conn *mockldapconn.MockConn) {
 				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
 				conn.EXPECT().Search(expectedUserSearch(nil)).Return(&ldap.SearchResult{
 					Entries: []*ldap.Entry{},
 				}, nil).Times(1)
+				conn.EXPECT().Search(expectedMovedUserSearch).Return(&ldap.SearchResult{
+					Entries: []*ldap.Entry{},
+				}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantErr: "searching for user \"some-upstream-user-dn\" resulted in 0 search results, but expected 1 result",
+		},
+		{
+			name: "search result returns no entries when the UID attribute is the DN so the user is not searched for by username",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.UserSearch.UIDAttribute = "dn"
+			}),
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(func(r *ldap.SearchRequest) {
+					r.Attributes = []string{testUserSearchUsernameAttribute, pwdLastSetAttribute}
+				})).Return(nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))).Times(1)
 				conn.EXPECT().Close().Times(1)
 			},
 			wantErr: "searching for user \"some-upstream-user-dn\" resulted in 0 search results, but expected 1 result",
 		},
+		{
+			name:           "error searching for the moved user by username",
+			providerConfig: providerConfig(nil),
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))).Times(1)
+				conn.EXPECT().Search(expectedMovedUserSearch).Return(nil, errors.New("some search error")).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantErr: "error searching for moved user \"some-upstream-username-value\": some search error",
+		},
+		{
+			name:           "a different user with the same username is found after the user was moved",
+			providerConfig: providerConfig(nil),
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))).Times(1)
+				conn.EXPECT().Search(expectedMovedUserSearch).Return(&ldap.SearchResult{
+					Entries: []*ldap.Entry{
+						{
+							DN: movedUserDN,
+							Attributes: []*ldap.EntryAttribute{
+								ldap.NewEntryAttribute(testUserSearchUsernameAttribute, []string{testUserSearchResultUsernameAttributeValue}),
+								ldap.NewEntryAttribute(testUserSearchUIDAttribute, []string{"some-other-uid"}),
+							},
+						},
+					},
+				}, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantErr: "searching for user \"some-upstream-user-dn\" produced a different subject than the previous value. expected: \"ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=c29tZS11cHN0cmVhbS11aWQtdmFsdWU\", actual: \"ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=c29tZS1vdGhlci11aWQ\"",
+		},
+		{
+			name:           "the session was started while the UID attribute was the DN and the user was moved",
+			providerConfig: providerConfig(nil),
+			refreshSubject: subjectFromDN,
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).Times(1)
+				conn.EXPECT().Search(expectedUserSearch(nil)).Return(nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))).Times(1)
+				conn.EXPECT().Search(expectedMovedUserSearch).Return(movedUserSearchResult, nil).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantErr: "searching for user \"some-upstream-user-dn\" produced a different subject than the previous value. expected: \"ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=c29tZS11cHN0cmVhbS11c2VyLWRu\", actual: \"ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=c29tZS11cHN0cmVhbS11aWQtdmFsdWU\"",
+		},
 		{
 			name:           "error searching",
 			providerConfig: providerConfig(nil),
@@ -1810,10 +2050,12 @@ func TestUpstreamRefresh(t *testing.T) {
 			}
 			initialPwdLastSetEncoded := base64.RawURLEncoding.EncodeToString([]byte("132801740800000000"))
 			ldapProvider := New(*tt.providerConfig)
-			subject := "ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=c29tZS11cHN0cmVhbS11aWQtdmFsdWU"
-			groups, err := ldapProvider.PerformRefresh(context.Background(), provider.RefreshAttributes{
+			if tt.refreshSubject == "" {
+				tt.refreshSubject = "ldaps://ldap.example.com:8443?base=some-upstream-user-base-dn&sub=c29tZS11cHN0cmVhbS11aWQtdmFsdWU"
+			}
+			groups, dn, err := ldapProvider.PerformRefresh(context.Background(), provider.RefreshAttributes{
 				Username:             testUserSearchResultUsernameAttributeValue,
-				Subject:              subject,
+				Subject:              tt.refreshSubject,
 				DN:                   tt.refreshUserDN,
 				AdditionalAttributes: map[string]string{pwdLastSetAttribute: initialPwdLastSetEncoded},
 				GrantedScopes:        tt.grantedScopes,
@@ -1821,8 +2063,13 @@ func TestUpstreamRefresh(t *testing.T) {
 			if tt.wantErr != "" {
 				require.Error(t, err)
 				require.Equal(t, tt.wantErr, err.Error())
+				require.Empty(t, dn)
 			} else {
 				require.NoError(t, err)
+				if tt.wantDN == "" {
+					tt.wantDN = tt.refreshUserDN
+				}
+				require.Equal(t, tt.wantDN, dn)
 			}
 			require.Equal(t, true, dialWasAttempted)
 			require.Equal(t, tt.wantGroups, groups)
@@ -1878,6 +2125,19 @@ func TestTestConnection(t *testing.T) {
 			},
 			wantError: testutil.WantSprintfErrorString(`error binding as "%s": some bind error`, testBindUsername),
 		},
+		{
+			name: "when the bind credentials are invalid during a rotation, the previous bind credentials are not tried",
+			providerConfig: providerConfig(func(p *ProviderConfig) {
+				p.PreviousBindUsername = "some-previous-bind-username"
+				p.PreviousBindPassword = "some-previous-bind-password"
+			}),
+			setupMocks: func(conn *mockldapconn.MockConn) {
+				conn.EXPECT().Bind(testBindUsername, testBindPassword).
+					Return(&ldap.Error{Err: errors.New("some bind error"), ResultCode: ldap.LDAPResultInvalidCredentials}).Times(1)
+				conn.EXPECT().Close().Times(1)
+			},
+			wantError: testutil.WantSprintfErrorString(`error binding as "%s": LDAP Result Code 49 "Invalid Credentials": some bind error`, testBindUsername),
+		},
 		{
 			name: "when the config is invalid",
 			providerConfig: providerConfig(func(p *ProviderConfig) {
@@ -2199,3 +2459,10 @@ func TestAttributeUnchangedSinceLogin(t *testing.T) {
 		})
 	}
 }
+
+func mustCompileGroupTransformations(t *testing.T, rules []v1alpha1.GroupTransformation) grouptransform.Transformations {
+	t.Helper()
+	transformations, err := grouptransform.Compile(rules)
+	require.NoError(t, err)
+	return transformations
+}
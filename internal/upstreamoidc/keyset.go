@@ -0,0 +1,258 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	"gopkg.in/square/go-jose.v2"
+	"k8s.io/utils/clock"
+
+	"go.pinniped.dev/internal/plog"
+)
+
+const (
+	// keySetRefreshInterval is how often the keys of an upstream provider are refreshed in the background.
+	keySetRefreshInterval = time.Hour
+
+	// keySetRetryInterval is how soon a failed refresh is retried. The previous keys are used in the meantime.
+	keySetRetryInterval = time.Minute
+
+	// keySetMinRefetchInterval limits how often an ID token signed by an unknown key causes the keys to be
+	// fetched again, so that a burst of such ID tokens does not cause a burst of requests to the upstream provider.
+	keySetMinRefetchInterval = 10 * time.Second
+
+	// keySetFetchTimeout bounds background refreshes, which are not bound to the context of any request.
+	keySetFetchTimeout = time.Minute
+)
+
+// KeySet is a coreosoidc.KeySet which keeps the keys of an upstream provider in memory and refreshes them in the
+// background before they get old, so that ID token validation does not wait for the upstream provider. Each refresh
+// is scheduled with some jitter, so that the refreshes of many key sets do not happen at once. The keys are fetched
+// again right away when an ID token is signed by an unknown key, e.g. because the upstream provider rotated its keys,
+// but at most every few seconds.
+type KeySet struct {
+	jwksURL string
+	client  *http.Client
+	clock   clock.PassiveClock
+
+	// fetchLock serializes the fetches of the keys.
+	fetchLock sync.Mutex
+
+	lock       sync.Mutex
+	keys       []jose.JSONWebKey
+	fetchedAt  time.Time
+	refreshAt  time.Time
+	refreshing bool
+}
+
+var _ coreosoidc.KeySet = (*KeySet)(nil)
+
+// NewKeySet returns a KeySet for the JWKS of an upstream provider. The keys are fetched when they are first needed.
+func NewKeySet(client *http.Client, jwksURL string) *KeySet {
+	return &KeySet{jwksURL: jwksURL, client: client, clock: clock.RealClock{}}
+}
+
+// JWKSURL returns the URL from which the keys are fetched.
+func (k *KeySet) JWKSURL() string {
+	return k.jwksURL
+}
+
+// VerifySignature implements coreosoidc.KeySet.
+func (k *KeySet) VerifySignature(ctx context.Context, jwt string) ([]byte, error) {
+	jws, err := jose.ParseSigned(jwt)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: malformed jwt: %w", err)
+	}
+
+	// We don't support JWTs signed with multiple signatures.
+	keyID := ""
+	if len(jws.Signatures) > 0 {
+		keyID = jws.Signatures[0].Header.KeyID
+	}
+
+	keys, fetchedAt, err := k.cachedKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if payload, ok := verifyWithKeys(jws, keyID, keys); ok {
+		return payload, nil
+	}
+
+	// The upstream provider may have rotated its keys since they were fetched.
+	if k.clock.Since(fetchedAt) < keySetMinRefetchInterval {
+		return nil, errors.New("failed to verify id token signature")
+	}
+	keys, err = k.fetch(ctx, fetchedAt)
+	if err != nil {
+		return nil, fmt.Errorf("fetching keys %w", err)
+	}
+	if payload, ok := verifyWithKeys(jws, keyID, keys); ok {
+		return payload, nil
+	}
+	return nil, errors.New("failed to verify id token signature")
+}
+
+func verifyWithKeys(jws *jose.JSONWebSignature, keyID string, keys []jose.JSONWebKey) ([]byte, bool) {
+	for i := range keys {
+		if keyID == "" || keys[i].KeyID == keyID {
+			if payload, err := jws.Verify(&keys[i]); err == nil {
+				return payload, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// cachedKeys returns the keys in memory, along with the time at which they were fetched. It only waits for the keys
+// to be fetched when they have never been fetched. When it is time to refresh them, they are refreshed in the
+// background.
+func (k *KeySet) cachedKeys(ctx context.Context) ([]jose.JSONWebKey, time.Time, error) {
+	k.lock.Lock()
+	keys, fetchedAt := k.keys, k.fetchedAt
+	if fetchedAt.IsZero() {
+		k.lock.Unlock()
+		keys, err := k.fetch(ctx, fetchedAt)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("fetching keys %w", err)
+		}
+		return keys, k.clock.Now(), nil
+	}
+
+	if !k.refreshing && !k.clock.Now().Before(k.refreshAt) {
+		k.refreshing = true
+		go k.refresh(fetchedAt)
+	}
+	k.lock.Unlock()
+
+	return keys, fetchedAt, nil
+}
+
+func (k *KeySet) refresh(fetchedAt time.Time) {
+	defer func() {
+		k.lock.Lock()
+		defer k.lock.Unlock()
+		k.refreshing = false
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), keySetFetchTimeout)
+	defer cancel()
+
+	if _, err := k.fetch(ctx, fetchedAt); err != nil {
+		plog.Warning("could not refresh the keys of the upstream provider, using the previous keys", "jwksURL", k.jwksURL, "err", err)
+	}
+}
+
+// fetch fetches the keys, unless they were already fetched again since the given time, e.g. by a concurrent call.
+func (k *KeySet) fetch(ctx context.Context, fetchedAt time.Time) ([]jose.JSONWebKey, error) {
+	k.fetchLock.Lock()
+	defer k.fetchLock.Unlock()
+
+	k.lock.Lock()
+	if k.fetchedAt.After(fetchedAt) {
+		defer k.lock.Unlock()
+		return k.keys, nil
+	}
+	k.lock.Unlock()
+
+	keys, err := k.fetchFromRemote(ctx)
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if err != nil {
+		k.refreshAt = k.clock.Now().Add(jitter(keySetRetryInterval))
+		return nil, err
+	}
+	k.keys = keys
+	k.fetchedAt = k.clock.Now()
+	k.refreshAt = k.fetchedAt.Add(jitter(keySetRefreshInterval))
+	return keys, nil
+}
+
+func (k *KeySet) fetchFromRemote(ctx context.Context) ([]jose.JSONWebKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: can't create request: %w", err)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: get keys failed %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: get keys failed: %s %s", resp.Status, body)
+	}
+
+	var keySet jose.JSONWebKeySet
+	if err := json.Unmarshal(body, &keySet); err != nil {
+		return nil, fmt.Errorf("oidc: failed to decode keys: %w %s", err, body)
+	}
+	return keySet.Keys, nil
+}
+
+// jitter returns a duration between 80% and 100% of the given duration.
+func jitter(d time.Duration) time.Duration {
+	return d - time.Duration(rand.Int63n(int64(d)/5)) //nolint:gosec // this is not security sensitive
+}
+
+// KeySetProvider is a discovered upstream provider whose ID tokens are validated with a KeySet instead of with the
+// keys which the provider fetches on demand.
+type KeySetProvider struct {
+	*coreosoidc.Provider
+	issuer     string
+	algorithms []string
+	keySet     *KeySet
+}
+
+// NewKeySetProvider returns a KeySetProvider for the discovered provider.
+func NewKeySetProvider(provider *coreosoidc.Provider, keySet *KeySet) (*KeySetProvider, error) {
+	var claims struct {
+		Issuer     string   `json:"issuer"`
+		Algorithms []string `json:"id_token_signing_alg_values_supported"`
+	}
+	if err := provider.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	// Like coreosoidc, ignore the algorithms which it does not support, e.g. HS256 or none.
+	supported := map[string]bool{
+		coreosoidc.RS256: true, coreosoidc.RS384: true, coreosoidc.RS512: true,
+		coreosoidc.ES256: true, coreosoidc.ES384: true, coreosoidc.ES512: true,
+		coreosoidc.PS256: true, coreosoidc.PS384: true, coreosoidc.PS512: true,
+	}
+	var algorithms []string
+	for _, algorithm := range claims.Algorithms {
+		if supported[algorithm] {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+
+	return &KeySetProvider{Provider: provider, issuer: claims.Issuer, algorithms: algorithms, keySet: keySet}, nil
+}
+
+// Verifier is like coreosoidc.Provider.Verifier, except that the returned verifier uses the KeySet.
+func (p *KeySetProvider) Verifier(config *coreosoidc.Config) *coreosoidc.IDTokenVerifier {
+	if len(config.SupportedSigningAlgs) == 0 && len(p.algorithms) > 0 {
+		// Make a copy so we don't modify the config values.
+		cp := *config
+		cp.SupportedSigningAlgs = p.algorithms
+		config = &cp
+	}
+	return coreosoidc.NewVerifier(p.issuer, p.keySet, config)
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package upstreamoidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestKeySet(t *testing.T) {
+	newKey := func(t *testing.T, keyID string) jose.JSONWebKey {
+		t.Helper()
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		return jose.JSONWebKey{Key: privateKey, KeyID: keyID, Algorithm: string(jose.RS256), Use: "sig"}
+	}
+
+	sign := func(t *testing.T, key jose.JSONWebKey, payload string) string {
+		t.Helper()
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+		require.NoError(t, err)
+		jws, err := signer.Sign([]byte(payload))
+		require.NoError(t, err)
+		jwt, err := jws.CompactSerialize()
+		require.NoError(t, err)
+		return jwt
+	}
+
+	key1, key2 := newKey(t, "key-1"), newKey(t, "key-2")
+
+	var lock sync.Mutex
+	servedKeys := []jose.JSONWebKey{key1.Public()}
+	requests := 0
+	serveKeys := func(keys ...jose.JSONWebKey) {
+		lock.Lock()
+		defer lock.Unlock()
+		servedKeys = nil
+		for _, key := range keys {
+			servedKeys = append(servedKeys, key.Public())
+		}
+	}
+	requestCount := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return requests
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: servedKeys}))
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	keySet := NewKeySet(server.Client(), server.URL)
+	keySet.clock = fakeClock
+	require.Equal(t, server.URL, keySet.JWKSURL())
+
+	// The keys are fetched when they are first needed.
+	require.Zero(t, requestCount())
+	payload, err := keySet.VerifySignature(ctx, sign(t, key1, "first"))
+	require.NoError(t, err)
+	require.Equal(t, "first", string(payload))
+	require.Equal(t, 1, requestCount())
+
+	// The keys are not fetched again while they are fresh.
+	payload, err = keySet.VerifySignature(ctx, sign(t, key1, "second"))
+	require.NoError(t, err)
+	require.Equal(t, "second", string(payload))
+	require.Equal(t, 1, requestCount())
+
+	// The keys are refreshed in the background once they get old, while the old keys are still used.
+	fakeClock.Step(keySetRefreshInterval)
+	payload, err = keySet.VerifySignature(ctx, sign(t, key1, "third"))
+	require.NoError(t, err)
+	require.Equal(t, "third", string(payload))
+	require.Eventually(t, func() bool { return requestCount() == 2 }, 10*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		keySet.lock.Lock()
+		defer keySet.lock.Unlock()
+		return !keySet.refreshing
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// ID tokens signed by unknown keys only cause the keys to be fetched again every few seconds.
+	serveKeys(key1, key2)
+	_, err = keySet.VerifySignature(ctx, sign(t, key2, "fourth"))
+	require.EqualError(t, err, "failed to verify id token signature")
+	require.Equal(t, 2, requestCount())
+
+	fakeClock.Step(keySetMinRefetchInterval)
+	payload, err = keySet.VerifySignature(ctx, sign(t, key2, "fifth"))
+	require.NoError(t, err)
+	require.Equal(t, "fifth", string(payload))
+	require.Equal(t, 3, requestCount())
+
+	// ID tokens which are not signed by any of the keys are rejected.
+	fakeClock.Step(keySetMinRefetchInterval)
+	_, err = keySet.VerifySignature(ctx, sign(t, newKey(t, "key-3"), "sixth"))
+	require.EqualError(t, err, "failed to verify id token signature")
+	require.Equal(t, 4, requestCount())
+
+	_, err = keySet.VerifySignature(ctx, "not a jwt")
+	require.ErrorContains(t, err, "oidc: malformed jwt")
+}
+
+func TestKeySetFetchErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "some error", http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	keySet := NewKeySet(server.Client(), server.URL)
+	_, err := keySet.VerifySignature(context.Background(), "eyJhbGciOiJSUzI1NiJ9.e30.c2ln")
+	require.EqualError(t, err, "fetching keys oidc: get keys failed: 500 Internal Server Error some error\n")
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		jittered := jitter(time.Hour)
+		require.LessOrEqual(t, jittered, time.Hour)
+		require.Greater(t, jittered, 48*time.Minute)
+	}
+}
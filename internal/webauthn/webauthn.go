@@ -0,0 +1,285 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webauthn implements the parts of the Web Authentication (WebAuthn) protocol which the Supervisor needs to
+// enroll and verify security keys and platform authenticators as a second factor.
+//
+// Credentials are enrolled without attestation, so the browser reports the public key of a new credential in its
+// DER-encoded SubjectPublicKeyInfo form, which avoids parsing the CBOR-encoded attestation object. Only the ES256
+// and RS256 algorithms are supported, which are the algorithms supported by practically all authenticators.
+//
+// See https://www.w3.org/TR/webauthn-2/.
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.pinniped.dev/internal/constable"
+)
+
+const (
+	// AlgorithmES256 is the COSE algorithm identifier of ECDSA with the P-256 curve and SHA-256.
+	AlgorithmES256 = -7
+
+	// AlgorithmRS256 is the COSE algorithm identifier of RSASSA-PKCS1-v1_5 with SHA-256.
+	AlgorithmRS256 = -257
+
+	// ChallengeLength is the number of random bytes in a challenge.
+	ChallengeLength = 32
+
+	ErrClientDataInvalid        = constable.Error("client data is invalid")
+	ErrAuthenticatorDataInvalid = constable.Error("authenticator data is invalid")
+	ErrSignatureInvalid         = constable.Error("signature is invalid")
+	ErrUnknownCredential        = constable.Error("credential is not enrolled")
+	ErrSignCountNotIncreased    = constable.Error("signature counter did not increase, the authenticator may have been cloned")
+
+	clientDataTypeCreate = "webauthn.create"
+	clientDataTypeGet    = "webauthn.get"
+
+	flagUserPresent            = 0x01
+	flagAttestedCredentialData = 0x40
+
+	// The authenticator data starts with the SHA-256 hash of the RP ID, one byte of flags, and a four byte counter.
+	authenticatorDataMinLength = sha256.Size + 1 + 4
+	// The attested credential data starts with the 16 byte AAGUID and the two byte length of the credential ID.
+	attestedCredentialDataMinLength = 16 + 2
+)
+
+// Credential is an enrolled WebAuthn credential.
+type Credential struct {
+	// ID is the credential ID which was chosen by the authenticator.
+	ID []byte `json:"id"`
+	// PublicKey is the DER-encoded SubjectPublicKeyInfo of the public key of the credential.
+	PublicKey []byte `json:"publicKey"`
+	// Algorithm is the COSE algorithm identifier of the signatures of the credential.
+	Algorithm int `json:"algorithm"`
+	// SignCount is the signature counter which was most recently reported by the authenticator.
+	SignCount uint32 `json:"signCount"`
+}
+
+// Registration is the response of the browser to navigator.credentials.create().
+type Registration struct {
+	CredentialID       []byte
+	ClientDataJSON     []byte
+	AuthenticatorData  []byte
+	PublicKey          []byte
+	PublicKeyAlgorithm int
+}
+
+// Assertion is the response of the browser to navigator.credentials.get().
+type Assertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+}
+
+// RelyingParty identifies the website to which credentials are bound.
+type RelyingParty struct {
+	// ID is the RP ID, i.e. the hostname of the website.
+	ID string
+	// Origin is the web origin from which the browser must have made the requests, e.g. https://example.com:8443.
+	Origin string
+}
+
+// NewRelyingParty returns the relying party of an issuer URL, whose credentials are bound to the hostname of the issuer.
+func NewRelyingParty(issuer string) (*RelyingParty, error) {
+	issuerURL, err := url.Parse(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse issuer as URL: %w", err)
+	}
+	if issuerURL.Scheme != "https" || issuerURL.Hostname() == "" {
+		return nil, fmt.Errorf("issuer %q must be an https URL with a hostname", issuer)
+	}
+	// Browsers omit the default port from the origin.
+	return &RelyingParty{
+		ID:     issuerURL.Hostname(),
+		Origin: "https://" + strings.TrimSuffix(issuerURL.Host, ":443"),
+	}, nil
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+}
+
+// NewChallenge returns a random challenge, which must be used for only one registration or assertion.
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, ChallengeLength)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, fmt.Errorf("could not generate challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// VerifyRegistration verifies the response to a registration with the given challenge and returns the new
+// credential.
+func (rp RelyingParty) VerifyRegistration(challenge []byte, registration *Registration) (*Credential, error) {
+	if err := rp.verifyClientData(registration.ClientDataJSON, clientDataTypeCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	authData, err := rp.parseAuthenticatorData(registration.AuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	if authData.flags&flagAttestedCredentialData == 0 || !bytes.Equal(authData.credentialID, registration.CredentialID) {
+		return nil, fmt.Errorf("%w: does not contain the credential ID", ErrAuthenticatorDataInvalid)
+	}
+
+	if _, err := parsePublicKey(registration.PublicKeyAlgorithm, registration.PublicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        registration.CredentialID,
+		PublicKey: registration.PublicKey,
+		Algorithm: registration.PublicKeyAlgorithm,
+		SignCount: authData.signCount,
+	}, nil
+}
+
+// VerifyAssertion verifies the response to an assertion with the given challenge, which must have been signed by one
+// of the credentials. It returns the index of the credential which signed the assertion, and that credential with
+// its updated signature counter.
+func (rp RelyingParty) VerifyAssertion(challenge []byte, credentials []Credential, assertion *Assertion) (int, *Credential, error) {
+	index := -1
+	for i := range credentials {
+		if bytes.Equal(credentials[i].ID, assertion.CredentialID) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return -1, nil, ErrUnknownCredential
+	}
+	credential := credentials[index]
+
+	if err := rp.verifyClientData(assertion.ClientDataJSON, clientDataTypeGet, challenge); err != nil {
+		return -1, nil, err
+	}
+
+	authData, err := rp.parseAuthenticatorData(assertion.AuthenticatorData)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	publicKey, err := parsePublicKey(credential.Algorithm, credential.PublicKey)
+	if err != nil {
+		return -1, nil, err
+	}
+	clientDataHash := sha256.Sum256(assertion.ClientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, assertion.AuthenticatorData...), clientDataHash[:]...))
+	if !verifySignature(publicKey, digest[:], assertion.Signature) {
+		return -1, nil, ErrSignatureInvalid
+	}
+
+	// Authenticators which do not implement a signature counter always report zero.
+	if (authData.signCount != 0 || credential.SignCount != 0) && authData.signCount <= credential.SignCount {
+		return -1, nil, ErrSignCountNotIncreased
+	}
+	credential.SignCount = authData.signCount
+
+	return index, &credential, nil
+}
+
+func (rp RelyingParty) verifyClientData(clientDataJSON []byte, wantType string, challenge []byte) error {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil {
+		return fmt.Errorf("%w: %s", ErrClientDataInvalid, err.Error())
+	}
+	if data.Type != wantType {
+		return fmt.Errorf("%w: type %q is not %q", ErrClientDataInvalid, data.Type, wantType)
+	}
+	if subtle.ConstantTimeCompare([]byte(data.Challenge), []byte(base64.RawURLEncoding.EncodeToString(challenge))) != 1 {
+		return fmt.Errorf("%w: challenge does not match", ErrClientDataInvalid)
+	}
+	if data.Origin != rp.Origin {
+		return fmt.Errorf("%w: origin %q is not %q", ErrClientDataInvalid, data.Origin, rp.Origin)
+	}
+	return nil
+}
+
+func (rp RelyingParty) parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < authenticatorDataMinLength {
+		return nil, fmt.Errorf("%w: too short", ErrAuthenticatorDataInvalid)
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(data[:sha256.Size], rpIDHash[:]) != 1 {
+		return nil, fmt.Errorf("%w: RP ID hash does not match %q", ErrAuthenticatorDataInvalid, rp.ID)
+	}
+
+	authData := &authenticatorData{
+		flags:     data[sha256.Size],
+		signCount: binary.BigEndian.Uint32(data[sha256.Size+1 : authenticatorDataMinLength]),
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return nil, fmt.Errorf("%w: user was not present", ErrAuthenticatorDataInvalid)
+	}
+
+	if authData.flags&flagAttestedCredentialData != 0 {
+		attested := data[authenticatorDataMinLength:]
+		if len(attested) < attestedCredentialDataMinLength {
+			return nil, fmt.Errorf("%w: attested credential data is too short", ErrAuthenticatorDataInvalid)
+		}
+		idLength := int(binary.BigEndian.Uint16(attested[16:attestedCredentialDataMinLength]))
+		if len(attested) < attestedCredentialDataMinLength+idLength {
+			return nil, fmt.Errorf("%w: attested credential data is too short", ErrAuthenticatorDataInvalid)
+		}
+		authData.credentialID = attested[attestedCredentialDataMinLength : attestedCredentialDataMinLength+idLength]
+	}
+
+	return authData, nil
+}
+
+func parsePublicKey(algorithm int, der []byte) (crypto.PublicKey, error) {
+	publicKey, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse public key: %w", err)
+	}
+
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if algorithm == AlgorithmES256 && key.Curve == elliptic.P256() {
+			return key, nil
+		}
+	case *rsa.PublicKey:
+		if algorithm == AlgorithmRS256 {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported public key algorithm %d for key of type %T", algorithm, publicKey)
+}
+
+func verifySignature(publicKey crypto.PublicKey, digest, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, digest, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil
+	default:
+		return false
+	}
+}
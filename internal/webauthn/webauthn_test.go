@@ -0,0 +1,346 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:gochecknoglobals
+var testRP = RelyingParty{ID: "issuer.example.com", Origin: "https://issuer.example.com"}
+
+func testClientData(t *testing.T, typ string, challenge []byte, origin string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{
+		"type":        typ,
+		"challenge":   base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":      origin,
+		"crossOrigin": false,
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func testAuthenticatorData(rpID string, flags byte, signCount uint32, credentialID []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	if credentialID != nil {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(credentialID)))
+		data = append(data, credentialID...)
+		data = append(data, 0xa0) // an empty CBOR map stands in for the COSE public key
+	}
+	return data
+}
+
+func sign(t *testing.T, key crypto.Signer, authData, clientDataJSON []byte) []byte {
+	t.Helper()
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+	return signature
+}
+
+func TestNewChallenge(t *testing.T) {
+	first, err := NewChallenge()
+	require.NoError(t, err)
+	require.Len(t, first, ChallengeLength)
+
+	second, err := NewChallenge()
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+}
+
+func TestNewRelyingParty(t *testing.T) {
+	for _, tt := range []struct {
+		issuer    string
+		want      *RelyingParty
+		wantError string
+	}{
+		{issuer: "https://issuer.example.com/path", want: &RelyingParty{ID: "issuer.example.com", Origin: "https://issuer.example.com"}},
+		{issuer: "https://issuer.example.com:443", want: &RelyingParty{ID: "issuer.example.com", Origin: "https://issuer.example.com"}},
+		{issuer: "https://issuer.example.com:8443/path", want: &RelyingParty{ID: "issuer.example.com", Origin: "https://issuer.example.com:8443"}},
+		{issuer: "http://issuer.example.com", wantError: `issuer "http://issuer.example.com" must be an https URL with a hostname`},
+		{issuer: "https://bad host", wantError: `could not parse issuer as URL: parse "https://bad host": invalid character " " in host name`},
+	} {
+		rp, err := NewRelyingParty(tt.issuer)
+		if tt.wantError != "" {
+			require.EqualError(t, err, tt.wantError, tt.issuer)
+			continue
+		}
+		require.NoError(t, err, tt.issuer)
+		require.Equal(t, tt.want, rp, tt.issuer)
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecPublicKey, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	require.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	p384PublicKey, err := x509.MarshalPKIXPublicKey(p384Key.Public())
+	require.NoError(t, err)
+
+	challenge := []byte("some-challenge")
+	credentialID := []byte("some-credential-id")
+
+	validRegistration := func() *Registration {
+		return &Registration{
+			CredentialID:       credentialID,
+			ClientDataJSON:     testClientData(t, "webauthn.create", challenge, testRP.Origin),
+			AuthenticatorData:  testAuthenticatorData(testRP.ID, flagUserPresent|flagAttestedCredentialData, 0, credentialID),
+			PublicKey:          ecPublicKey,
+			PublicKeyAlgorithm: AlgorithmES256,
+		}
+	}
+
+	tests := []struct {
+		name            string
+		modify          func(r *Registration)
+		wantError       string
+		wantErrorPrefix string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name:      "wrong type",
+			modify:    func(r *Registration) { r.ClientDataJSON = testClientData(t, "webauthn.get", challenge, testRP.Origin) },
+			wantError: `client data is invalid: type "webauthn.get" is not "webauthn.create"`,
+		},
+		{
+			name: "wrong challenge",
+			modify: func(r *Registration) {
+				r.ClientDataJSON = testClientData(t, "webauthn.create", []byte("other"), testRP.Origin)
+			},
+			wantError: `client data is invalid: challenge does not match`,
+		},
+		{
+			name: "wrong origin",
+			modify: func(r *Registration) {
+				r.ClientDataJSON = testClientData(t, "webauthn.create", challenge, "https://evil.example.com")
+			},
+			wantError: `client data is invalid: origin "https://evil.example.com" is not "https://issuer.example.com"`,
+		},
+		{
+			name:      "invalid client data",
+			modify:    func(r *Registration) { r.ClientDataJSON = []byte("not json") },
+			wantError: `client data is invalid: invalid character 'o' in literal null (expecting 'u')`,
+		},
+		{
+			name: "wrong RP ID",
+			modify: func(r *Registration) {
+				r.AuthenticatorData = testAuthenticatorData("evil.example.com", flagUserPresent|flagAttestedCredentialData, 0, credentialID)
+			},
+			wantError: `authenticator data is invalid: RP ID hash does not match "issuer.example.com"`,
+		},
+		{
+			name: "user not present",
+			modify: func(r *Registration) {
+				r.AuthenticatorData = testAuthenticatorData(testRP.ID, flagAttestedCredentialData, 0, credentialID)
+			},
+			wantError: `authenticator data is invalid: user was not present`,
+		},
+		{
+			name:      "no attested credential data",
+			modify:    func(r *Registration) { r.AuthenticatorData = testAuthenticatorData(testRP.ID, flagUserPresent, 0, nil) },
+			wantError: `authenticator data is invalid: does not contain the credential ID`,
+		},
+		{
+			name:      "other credential ID",
+			modify:    func(r *Registration) { r.CredentialID = []byte("other-credential-id") },
+			wantError: `authenticator data is invalid: does not contain the credential ID`,
+		},
+		{
+			name:      "truncated authenticator data",
+			modify:    func(r *Registration) { r.AuthenticatorData = r.AuthenticatorData[:40] },
+			wantError: `authenticator data is invalid: attested credential data is too short`,
+		},
+		{
+			name:      "too short authenticator data",
+			modify:    func(r *Registration) { r.AuthenticatorData = r.AuthenticatorData[:36] },
+			wantError: `authenticator data is invalid: too short`,
+		},
+		{
+			name:      "algorithm does not match key",
+			modify:    func(r *Registration) { r.PublicKeyAlgorithm = AlgorithmRS256 },
+			wantError: `unsupported public key algorithm -257 for key of type *ecdsa.PublicKey`,
+		},
+		{
+			name:      "unsupported curve",
+			modify:    func(r *Registration) { r.PublicKey = p384PublicKey },
+			wantError: `unsupported public key algorithm -7 for key of type *ecdsa.PublicKey`,
+		},
+		{
+			name:            "invalid public key",
+			modify:          func(r *Registration) { r.PublicKey = []byte("not a key") },
+			wantErrorPrefix: `could not parse public key: asn1: structure error`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			registration := validRegistration()
+			if tt.modify != nil {
+				tt.modify(registration)
+			}
+
+			credential, err := testRP.VerifyRegistration(challenge, registration)
+			if tt.wantErrorPrefix != "" {
+				require.ErrorContains(t, err, tt.wantErrorPrefix)
+				require.Nil(t, credential)
+				return
+			}
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				require.Nil(t, credential)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &Credential{ID: credentialID, PublicKey: ecPublicKey, Algorithm: AlgorithmES256}, credential)
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecPublicKey, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPublicKey, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+	require.NoError(t, err)
+
+	challenge := []byte("some-challenge")
+	credentials := []Credential{
+		{ID: []byte("ec-credential"), PublicKey: ecPublicKey, Algorithm: AlgorithmES256, SignCount: 5},
+		{ID: []byte("rsa-credential"), PublicKey: rsaPublicKey, Algorithm: AlgorithmRS256},
+	}
+
+	assert := func(key crypto.Signer, credentialID []byte, signCount uint32) *Assertion {
+		clientDataJSON := testClientData(t, "webauthn.get", challenge, testRP.Origin)
+		authData := testAuthenticatorData(testRP.ID, flagUserPresent, signCount, nil)
+		return &Assertion{
+			CredentialID:      credentialID,
+			ClientDataJSON:    clientDataJSON,
+			AuthenticatorData: authData,
+			Signature:         sign(t, key, authData, clientDataJSON),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		assertion     func() *Assertion
+		wantIndex     int
+		wantSignCount uint32
+		wantError     string
+	}{
+		{
+			name:          "ES256 with increased counter",
+			assertion:     func() *Assertion { return assert(ecKey, []byte("ec-credential"), 6) },
+			wantIndex:     0,
+			wantSignCount: 6,
+		},
+		{
+			name:      "RS256 without counter",
+			assertion: func() *Assertion { return assert(rsaKey, []byte("rsa-credential"), 0) },
+			wantIndex: 1,
+		},
+		{
+			name:      "counter did not increase",
+			assertion: func() *Assertion { return assert(ecKey, []byte("ec-credential"), 5) },
+			wantError: "signature counter did not increase, the authenticator may have been cloned",
+		},
+		{
+			name:      "counter was reset",
+			assertion: func() *Assertion { return assert(ecKey, []byte("ec-credential"), 0) },
+			wantError: "signature counter did not increase, the authenticator may have been cloned",
+		},
+		{
+			name:      "unknown credential",
+			assertion: func() *Assertion { return assert(ecKey, []byte("other-credential"), 6) },
+			wantError: "credential is not enrolled",
+		},
+		{
+			name:      "signed by another key",
+			assertion: func() *Assertion { return assert(rsaKey, []byte("ec-credential"), 6) },
+			wantError: "signature is invalid",
+		},
+		{
+			name: "tampered authenticator data",
+			assertion: func() *Assertion {
+				a := assert(ecKey, []byte("ec-credential"), 6)
+				a.AuthenticatorData = testAuthenticatorData(testRP.ID, flagUserPresent, 7, nil)
+				return a
+			},
+			wantError: "signature is invalid",
+		},
+		{
+			name: "wrong type",
+			assertion: func() *Assertion {
+				a := assert(ecKey, []byte("ec-credential"), 6)
+				a.ClientDataJSON = testClientData(t, "webauthn.create", challenge, testRP.Origin)
+				return a
+			},
+			wantError: `client data is invalid: type "webauthn.create" is not "webauthn.get"`,
+		},
+		{
+			name: "wrong challenge",
+			assertion: func() *Assertion {
+				a := assert(ecKey, []byte("ec-credential"), 6)
+				a.ClientDataJSON = testClientData(t, "webauthn.get", []byte("other"), testRP.Origin)
+				return a
+			},
+			wantError: `client data is invalid: challenge does not match`,
+		},
+		{
+			name: "wrong RP ID",
+			assertion: func() *Assertion {
+				a := assert(ecKey, []byte("ec-credential"), 6)
+				a.AuthenticatorData = testAuthenticatorData("evil.example.com", flagUserPresent, 6, nil)
+				return a
+			},
+			wantError: `authenticator data is invalid: RP ID hash does not match "issuer.example.com"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			index, credential, err := testRP.VerifyAssertion(challenge, credentials, tt.assertion())
+			if tt.wantError != "" {
+				require.EqualError(t, err, tt.wantError)
+				require.Equal(t, -1, index)
+				require.Nil(t, credential)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantIndex, index)
+			require.Equal(t, credentials[tt.wantIndex].ID, credential.ID)
+			require.Equal(t, tt.wantSignCount, credential.SignCount)
+		})
+	}
+
+	// The stored credentials are not modified.
+	require.Equal(t, uint32(5), credentials[0].SignCount)
+}
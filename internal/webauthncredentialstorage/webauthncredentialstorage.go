@@ -0,0 +1,154 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webauthncredentialstorage stores the WebAuthn credentials which users have enrolled as a second factor.
+package webauthncredentialstorage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"go.pinniped.dev/internal/constable"
+	"go.pinniped.dev/internal/crud"
+	"go.pinniped.dev/internal/webauthn"
+)
+
+const (
+	TypeLabelValue = "webauthn-credential"
+
+	ErrWebAuthnCredentialStorageVersion = constable.Error("WebAuthn credential storage data has wrong version")
+
+	// Version 1 was the initial release of the WebAuthn second factor.
+	webAuthnCredentialStorageVersion = "1"
+)
+
+type WebAuthnCredentialStorage struct {
+	storage crud.Storage
+}
+
+// storedCredentials defines the format of the credentials of a user when stored in a Secret as a JSON string value.
+type storedCredentials struct {
+	UserCredentials
+	// The format version. Take care when updating. We cannot simply bump the storage version and drop/ignore old data.
+	// Updating this would require some form of migration of existing stored data.
+	Version string `json:"version"`
+}
+
+// UserCredentials are the WebAuthn credentials which a user has enrolled.
+type UserCredentials struct {
+	// Subject is the downstream subject of the user.
+	Subject string `json:"subject"`
+	// Username is the downstream username of the user when they most recently enrolled or used a credential.
+	Username    string       `json:"username"`
+	Credentials []Credential `json:"credentials"`
+}
+
+// Credential is a WebAuthn credential which a user has enrolled.
+type Credential struct {
+	webauthn.Credential
+	// RPID is the hostname of the issuer at which the credential was enrolled, since browsers only use a credential
+	// for the hostname to which it is bound.
+	RPID string `json:"rpID"`
+	// When the credential was enrolled.
+	CreationTimestamp metav1.Time `json:"created"`
+	// When the credential was last used to log in.
+	LastUsedTimestamp *metav1.Time `json:"lastUsed,omitempty"`
+}
+
+func New(secrets corev1client.SecretInterface) *WebAuthnCredentialStorage {
+	return &WebAuthnCredentialStorage{
+		storage: crud.New(TypeLabelValue, secrets, nil, 0), // can use nil clock because we are using infinite lifetime
+	}
+}
+
+// Get returns the resourceVersion of the storage secret, the credentials of the user with the given downstream
+// subject, and an error. When the storage secret is not found, it will simply return "", nil, nil to make it easy to
+// pass the results of Get directly to Set.
+func (s *WebAuthnCredentialStorage) Get(ctx context.Context, subject string) (string, []Credential, error) {
+	credentials := &storedCredentials{}
+	rv, err := s.storage.Get(ctx, subjectToName(subject), credentials)
+	if errors.IsNotFound(err) {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get WebAuthn credentials: %w", err)
+	}
+	if err := validateVersion(credentials); err != nil {
+		return "", nil, err
+	}
+	return rv, credentials.Credentials, nil
+}
+
+// Set will create or update the credentials of the user with the given downstream subject and username.
+// Set takes the resourceVersion to know if we are doing a create or update and to ensure we do not edit an old
+// version of the storage secret.
+func (s *WebAuthnCredentialStorage) Set(ctx context.Context, resourceVersion, subject, username string, credentials []Credential) error {
+	stored := &storedCredentials{
+		UserCredentials: UserCredentials{
+			Subject:     subject,
+			Username:    username,
+			Credentials: credentials,
+		},
+		Version: webAuthnCredentialStorageVersion,
+	}
+	name := subjectToName(subject)
+
+	if mustBeCreate := len(resourceVersion) == 0; mustBeCreate {
+		if _, err := s.storage.Create(ctx, name, stored, nil, nil); err != nil {
+			return fmt.Errorf("failed to create WebAuthn credentials: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := s.storage.Update(ctx, name, resourceVersion, stored); err != nil {
+		return fmt.Errorf("failed to update WebAuthn credentials: %w", err)
+	}
+	return nil
+}
+
+// Delete removes all credentials of the user with the given downstream subject, so the user must enroll a new one.
+// It is not an error when the user has no credentials.
+func (s *WebAuthnCredentialStorage) Delete(ctx context.Context, subject string) error {
+	if err := s.storage.Delete(ctx, subjectToName(subject)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete WebAuthn credentials: %w", err)
+	}
+	return nil
+}
+
+// GetName returns the name of the Secret which would be used to store the credentials of the given subject.
+func (s *WebAuthnCredentialStorage) GetName(subject string) string {
+	return s.storage.GetName(subjectToName(subject))
+}
+
+// ReadFromSecret reads the credentials of a user from a storage Secret.
+func ReadFromSecret(secret *corev1.Secret) (*UserCredentials, error) {
+	credentials := &storedCredentials{}
+	if err := crud.FromSecret(TypeLabelValue, secret, credentials); err != nil {
+		return nil, err
+	}
+	if err := validateVersion(credentials); err != nil {
+		return nil, err
+	}
+	return &credentials.UserCredentials, nil
+}
+
+func validateVersion(credentials *storedCredentials) error {
+	if credentials.Version != webAuthnCredentialStorageVersion {
+		return fmt.Errorf("%w: WebAuthn credential storage has version %s instead of %s",
+			ErrWebAuthnCredentialStorageVersion, credentials.Version, webAuthnCredentialStorageVersion)
+	}
+	return nil
+}
+
+func subjectToName(subject string) string {
+	// Downstream subjects can be too long for the name of a Secret, so use their hash.
+	sum := sha256.Sum256([]byte(subject))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
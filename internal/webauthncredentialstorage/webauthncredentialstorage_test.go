@@ -0,0 +1,111 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package webauthncredentialstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"go.pinniped.dev/internal/webauthn"
+)
+
+const (
+	testNamespace = "some-namespace"
+	testSubject   = "ldaps://ldap.example.com:636?base=ou%3Dusers%2Cdc%3Dexample%2Cdc%3Dcom&sub=c29tZS11aWQ"
+)
+
+func TestGetAndSet(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	storage := New(client.CoreV1().Secrets(testNamespace))
+
+	// A user without credentials.
+	rv, credentials, err := storage.Get(ctx, testSubject)
+	require.NoError(t, err)
+	require.Empty(t, rv)
+	require.Nil(t, credentials)
+
+	first := Credential{
+		Credential:        webauthn.Credential{ID: []byte("first-id"), PublicKey: []byte("first-key"), Algorithm: webauthn.AlgorithmES256},
+		RPID:              "issuer.example.com",
+		CreationTimestamp: metav1.NewTime(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC).Local()),
+	}
+	require.NoError(t, storage.Set(ctx, rv, testSubject, "pinny", []Credential{first}))
+
+	secret, err := client.CoreV1().Secrets(testNamespace).Get(ctx, storage.GetName(testSubject), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, corev1.SecretType("storage.pinniped.dev/webauthn-credential"), secret.Type)
+	require.Equal(t, "webauthn-credential", secret.Labels["storage.pinniped.dev/type"])
+	require.Empty(t, secret.Annotations) // the credentials never expire
+	require.LessOrEqual(t, len(secret.Name), 253)
+	require.JSONEq(t, `{
+		"subject": "`+testSubject+`",
+		"username": "pinny",
+		"credentials": [{
+			"id": "Zmlyc3QtaWQ=",
+			"publicKey": "Zmlyc3Qta2V5",
+			"algorithm": -7,
+			"signCount": 0,
+			"rpID": "issuer.example.com",
+			"created": "2023-01-02T03:04:05Z"
+		}],
+		"version": "1"
+	}`, string(secret.Data["pinniped-storage-data"]))
+
+	// The fake client does not assign resource versions.
+	secret.ResourceVersion = "123"
+	_, err = client.CoreV1().Secrets(testNamespace).Update(ctx, secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	rv, credentials, err = storage.Get(ctx, testSubject)
+	require.NoError(t, err)
+	require.Equal(t, "123", rv)
+	require.Equal(t, []Credential{first}, credentials)
+
+	userCredentials, err := ReadFromSecret(secret)
+	require.NoError(t, err)
+	require.Equal(t, &UserCredentials{Subject: testSubject, Username: "pinny", Credentials: []Credential{first}}, userCredentials)
+
+	// Another user has their own credentials.
+	_, credentials, err = storage.Get(ctx, testSubject+"-other")
+	require.NoError(t, err)
+	require.Nil(t, credentials)
+
+	lastUsed := metav1.NewTime(time.Date(2023, 2, 3, 4, 5, 6, 0, time.UTC).Local())
+	first.SignCount = 42
+	first.LastUsedTimestamp = &lastUsed
+	require.NoError(t, storage.Set(ctx, rv, testSubject, "pinny", []Credential{first}))
+
+	_, credentials, err = storage.Get(ctx, testSubject)
+	require.NoError(t, err)
+	require.Equal(t, []Credential{first}, credentials)
+
+	require.NoError(t, storage.Delete(ctx, testSubject))
+	rv, credentials, err = storage.Get(ctx, testSubject)
+	require.NoError(t, err)
+	require.Empty(t, rv)
+	require.Nil(t, credentials)
+
+	// Deleting is idempotent.
+	require.NoError(t, storage.Delete(ctx, testSubject))
+}
+
+func TestGetWrongVersion(t *testing.T) {
+	ctx := context.Background()
+	storage := New(fake.NewSimpleClientset().CoreV1().Secrets(testNamespace))
+
+	_, err := storage.storage.Create(ctx, subjectToName(testSubject), &storedCredentials{Version: "2"}, nil, nil)
+	require.NoError(t, err)
+
+	rv, credentials, err := storage.Get(ctx, testSubject)
+	require.EqualError(t, err, "WebAuthn credential storage data has wrong version: WebAuthn credential storage has version 2 instead of 1")
+	require.Empty(t, rv)
+	require.Nil(t, credentials)
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package conciergeclient provides login helpers for the Pinniped concierge.
@@ -9,6 +9,7 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 
@@ -38,6 +39,8 @@ type Client struct {
 	caBundle       string
 	endpoint       *url.URL
 	apiGroupSuffix string
+	proxy          func(*http.Request) (*url.URL, error)
+	tlsServerName  string
 }
 
 // WithAuthenticator configures the authenticator reference (spec.authenticator) of the TokenCredentialRequests.
@@ -116,6 +119,24 @@ func WithAPIGroupSuffix(apiGroupSuffix string) Option {
 	}
 }
 
+// WithProxy configures how to choose the proxy for the requests to the concierge (see http.Transport.Proxy). When it
+// is not specified, the proxy is chosen using the proxy environment variables.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *Client) error {
+		c.proxy = proxy
+		return nil
+	}
+}
+
+// WithTLSServerName configures the server name which is used to verify the certificate of the concierge, instead of
+// the hostname of the endpoint, e.g. when the concierge is reached through a TCP forwarder with a different hostname.
+func WithTLSServerName(tlsServerName string) Option {
+	return func(c *Client) error {
+		c.tlsServerName = tlsServerName
+		return nil
+	}
+}
+
 // New validates the specified options and returns a newly initialized *Client.
 func New(opts ...Option) (*Client, error) {
 	c := Client{apiGroupSuffix: groupsuffix.PinnipedDefaultSuffix}
@@ -140,6 +161,7 @@ func (c *Client) clientset() (conciergeclientset.Interface, error) {
 			"cluster": {
 				Server:                   c.endpoint.String(),
 				CertificateAuthorityData: []byte(c.caBundle),
+				TLSServerName:            c.tlsServerName,
 			},
 		},
 		Contexts: map[string]*clientcmdapi.Context{
@@ -155,6 +177,7 @@ func (c *Client) clientset() (conciergeclientset.Interface, error) {
 	if err != nil {
 		return nil, err
 	}
+	cfg.Proxy = c.proxy
 	client, err := kubeclient.New(
 		kubeclient.WithConfig(cfg),
 		kubeclient.WithMiddleware(groupsuffix.New(c.apiGroupSuffix)),
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package conciergeclient
@@ -131,6 +131,8 @@ func TestNew(t *testing.T) {
 				WithAuthenticator("jwt", "test-authenticator"),
 				WithAuthenticator("webhook", "test-authenticator"),
 				WithAPIGroupSuffix("suffix.com"),
+				WithProxy(http.ProxyFromEnvironment),
+				WithTLSServerName("concierge.example.com"),
 			},
 		},
 	}
@@ -214,6 +216,43 @@ func TestExchangeToken(t *testing.T) {
 		require.Nil(t, got)
 	})
 
+	t.Run("proxy error", func(t *testing.T) {
+		t.Parallel()
+		caBundle, endpoint := testutil.TLSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request to the server: %s", r.URL)
+		})
+
+		var sawProxyRequestURL string
+		client, err := New(WithEndpoint(endpoint), WithCABundle(caBundle), WithAuthenticator("jwt", "test-authenticator"),
+			WithProxy(func(r *http.Request) (*url.URL, error) {
+				sawProxyRequestURL = r.URL.String()
+				return nil, fmt.Errorf("some proxy error")
+			}),
+		)
+		require.NoError(t, err)
+
+		got, err := client.ExchangeToken(ctx, "test-token")
+		require.ErrorContains(t, err, "some proxy error")
+		require.Nil(t, got)
+		require.Equal(t, endpoint+"/apis/login.concierge.pinniped.dev/v1alpha1/tokencredentialrequests", sawProxyRequestURL)
+	})
+
+	t.Run("TLS server name does not match the certificate", func(t *testing.T) {
+		t.Parallel()
+		caBundle, endpoint := testutil.TLSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request to the server: %s", r.URL)
+		})
+
+		client, err := New(WithEndpoint(endpoint), WithCABundle(caBundle), WithAuthenticator("jwt", "test-authenticator"),
+			WithTLSServerName("concierge.example.org"),
+		)
+		require.NoError(t, err)
+
+		got, err := client.ExchangeToken(ctx, "test-token")
+		require.ErrorContains(t, err, "not concierge.example.org")
+		require.Nil(t, got)
+	})
+
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		expires := metav1.NewTime(time.Now().Truncate(time.Second))
@@ -259,7 +298,10 @@ func TestExchangeToken(t *testing.T) {
 			})
 		})
 
-		client, err := New(WithEndpoint(endpoint), WithCABundle(caBundle), WithAuthenticator("webhook", "test-webhook"))
+		// The certificate of the test server is valid for *.example.com.
+		client, err := New(WithEndpoint(endpoint), WithCABundle(caBundle), WithAuthenticator("webhook", "test-webhook"),
+			WithTLSServerName("concierge.example.com"),
+		)
 		require.NoError(t, err)
 
 		got, err := client.ExchangeToken(ctx, "test-token")
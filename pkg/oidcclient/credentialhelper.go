@@ -0,0 +1,150 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidcclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/pkg/oidcclient/nonce"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+// CredentialHelperAPIVersion is the version of the protocol between the login and a credential helper. It is sent
+// in each CredentialHelperRequest, and each CredentialHelperResponse must specify the same version.
+const CredentialHelperAPIVersion = "credentialhelper.pinniped.dev/v1alpha1"
+
+// credentialHelperGetAction is the argument which is appended to the credential helper command when the login needs
+// new tokens. Other actions may be added by future versions of the protocol.
+const credentialHelperGetAction = "get"
+
+// CredentialHelperRequest is written as JSON to the stdin of a credential helper. It describes the tokens which the
+// credential helper should obtain from the issuer.
+type CredentialHelperRequest struct {
+	APIVersion string `json:"apiVersion"`
+
+	// Issuer is the URL of the OpenID Connect issuer.
+	Issuer string `json:"issuer"`
+
+	// ClientID is the client ID to which the ID token must be issued.
+	ClientID string `json:"clientID"`
+
+	// Scopes are the OAuth2 scopes which should be granted.
+	Scopes []string `json:"scopes"`
+
+	// UpstreamIdentityProviderName and UpstreamIdentityProviderType are the upstream identity provider of a
+	// Pinniped Supervisor which should be used, if one was specified.
+	UpstreamIdentityProviderName string `json:"upstreamIdentityProviderName,omitempty"`
+	UpstreamIdentityProviderType string `json:"upstreamIdentityProviderType,omitempty"`
+}
+
+// CredentialHelperResponse is written as JSON to the stdout of a credential helper which exits successfully. It holds
+// the tokens which the credential helper obtained from the token endpoint of the issuer.
+type CredentialHelperResponse struct {
+	APIVersion string `json:"apiVersion"`
+
+	// IDToken is the ID token issued to the requested client ID. It is required.
+	IDToken string `json:"idToken"`
+
+	// AccessToken is the access token which was issued with the ID token. It is required.
+	AccessToken string `json:"accessToken"`
+
+	// AccessTokenExpiry is the time at which the access token expires, if it is known.
+	AccessTokenExpiry *time.Time `json:"accessTokenExpiry,omitempty"`
+
+	// RefreshToken is the refresh token which was issued with the ID token, if any. When it is returned, the login
+	// refreshes the tokens itself while the refresh token is valid, instead of running the credential helper again.
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+// WithCredentialHelper causes the login to obtain new tokens from the issuer by running the given command and
+// arguments, instead of performing a login flow itself. This lets organizations which use bespoke authentication
+// methods, such as hardware tokens, integrate them without changing the login.
+//
+// The command is run with the additional argument "get", a CredentialHelperRequest on stdin, and the stderr of the
+// current process, on which it may interact with the user. It must exit with status zero and write a
+// CredentialHelperResponse to stdout. The returned tokens are validated like the tokens of any other login flow, and
+// they are stored in the session cache.
+func WithCredentialHelper(command []string) Option {
+	return func(h *handlerState) error {
+		if len(command) == 0 || command[0] == "" {
+			return fmt.Errorf("credential helper command must not be empty")
+		}
+		h.credentialHelper = command
+		return nil
+	}
+}
+
+// credentialHelperAuth runs the credential helper and validates the tokens which it returns.
+func (h *handlerState) credentialHelperAuth() (*oidctypes.Token, error) {
+	h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Running credential helper.", "command", h.credentialHelper[0])
+	response, err := runCredentialHelper(h.ctx, h.credentialHelper, &CredentialHelperRequest{
+		APIVersion:                   CredentialHelperAPIVersion,
+		Issuer:                       h.issuer,
+		ClientID:                     h.clientID,
+		Scopes:                       h.scopes,
+		UpstreamIdentityProviderName: h.upstreamIdentityProviderName,
+		UpstreamIdentityProviderType: h.upstreamIdentityProviderType,
+	}, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	tok := &oauth2.Token{
+		AccessToken:  response.AccessToken,
+		TokenType:    "Bearer",
+		RefreshToken: response.RefreshToken,
+	}
+	if response.AccessTokenExpiry != nil {
+		tok.Expiry = *response.AccessTokenExpiry
+	}
+	token, err := h.getProvider(h.oauth2Config, h.provider, h.httpClient).
+		ValidateTokenAndMergeWithUserInfo(h.ctx, tok.WithExtra(map[string]interface{}{"id_token": response.IDToken}), nonce.Nonce(""), true, false)
+	if err != nil {
+		return nil, fmt.Errorf("credential helper returned invalid tokens: %w", err)
+	}
+	return token, nil
+}
+
+// runCredentialHelper runs the credential helper command with the "get" action, and decodes its response.
+func runCredentialHelper(ctx context.Context, command []string, request *CredentialHelperRequest, stderr io.Writer) (*CredentialHelperResponse, error) {
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode credential helper request: %w", err)
+	}
+
+	args := append(append([]string{}, command[1:]...), credentialHelperGetAction)
+	cmd := exec.CommandContext(ctx, command[0], args...) //nolint:gosec // the command was chosen by the user
+	var stdout bytes.Buffer
+	cmd.Stdin = bytes.NewReader(requestJSON)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential helper failed: %w", err)
+	}
+
+	var response CredentialHelperResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("could not decode credential helper response: %w", err)
+	}
+	if response.APIVersion != CredentialHelperAPIVersion {
+		return nil, fmt.Errorf("credential helper response has unsupported apiVersion %q (expected %q)", response.APIVersion, CredentialHelperAPIVersion)
+	}
+	if response.IDToken == "" {
+		return nil, fmt.Errorf("credential helper response is missing the ID token")
+	}
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("credential helper response is missing the access token")
+	}
+	return &response, nil
+}
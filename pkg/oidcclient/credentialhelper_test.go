@@ -0,0 +1,120 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidcclient
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/testutil"
+)
+
+func TestRunCredentialHelper(t *testing.T) {
+	expiry := time.Date(2035, 10, 12, 13, 14, 15, 0, time.UTC)
+	request := &CredentialHelperRequest{
+		APIVersion:                   CredentialHelperAPIVersion,
+		Issuer:                       "https://issuer.example.com",
+		ClientID:                     "test-client-id",
+		Scopes:                       []string{"openid", "offline_access"},
+		UpstreamIdentityProviderName: "some-upstream-name",
+		UpstreamIdentityProviderType: "oidc",
+	}
+
+	// helper returns a credential helper command which saves its arguments and stdin in dir, prints a message to stderr,
+	// and then runs the script.
+	helper := func(dir string, script string) []string {
+		return []string{"sh", "-c", `echo "$@" > "$0/args"; cat > "$0/stdin"; echo "touch your security key" >&2; ` + script, dir, "--some-arg"}
+	}
+
+	tests := []struct {
+		name         string
+		script       string
+		wantResponse *CredentialHelperResponse
+		wantErr      string
+	}{
+		{
+			name:   "success",
+			script: `echo '{"apiVersion": "credentialhelper.pinniped.dev/v1alpha1", "idToken": "test-id-token", "accessToken": "test-access-token", "accessTokenExpiry": "2035-10-12T13:14:15Z", "refreshToken": "test-refresh-token"}'`,
+			wantResponse: &CredentialHelperResponse{
+				APIVersion:        CredentialHelperAPIVersion,
+				IDToken:           "test-id-token",
+				AccessToken:       "test-access-token",
+				AccessTokenExpiry: &expiry,
+				RefreshToken:      "test-refresh-token",
+			},
+		},
+		{
+			name:    "command fails",
+			script:  `exit 3`,
+			wantErr: "credential helper failed: exit status 3",
+		},
+		{
+			name:    "invalid response",
+			script:  `echo 'not json'`,
+			wantErr: "could not decode credential helper response: invalid character 'o' in literal null (expecting 'u')",
+		},
+		{
+			name:    "unsupported API version",
+			script:  `echo '{"apiVersion": "credentialhelper.pinniped.dev/v2", "idToken": "test-id-token", "accessToken": "test-access-token"}'`,
+			wantErr: `credential helper response has unsupported apiVersion "credentialhelper.pinniped.dev/v2" (expected "credentialhelper.pinniped.dev/v1alpha1")`,
+		},
+		{
+			name:    "missing ID token",
+			script:  `echo '{"apiVersion": "credentialhelper.pinniped.dev/v1alpha1", "accessToken": "test-access-token"}'`,
+			wantErr: "credential helper response is missing the ID token",
+		},
+		{
+			name:    "missing access token",
+			script:  `echo '{"apiVersion": "credentialhelper.pinniped.dev/v1alpha1", "idToken": "test-id-token"}'`,
+			wantErr: "credential helper response is missing the access token",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testutil.TempDir(t)
+			var stderr bytes.Buffer
+			response, err := runCredentialHelper(context.Background(), helper(dir, tt.script), request, &stderr)
+			require.Equal(t, "touch your security key\n", stderr.String())
+
+			args, readErr := os.ReadFile(filepath.Join(dir, "args"))
+			require.NoError(t, readErr)
+			require.Equal(t, "--some-arg get\n", string(args))
+			stdin, readErr := os.ReadFile(filepath.Join(dir, "stdin"))
+			require.NoError(t, readErr)
+			require.JSONEq(t, `{
+				"apiVersion": "credentialhelper.pinniped.dev/v1alpha1",
+				"issuer": "https://issuer.example.com",
+				"clientID": "test-client-id",
+				"scopes": ["openid", "offline_access"],
+				"upstreamIdentityProviderName": "some-upstream-name",
+				"upstreamIdentityProviderType": "oidc"
+			}`, string(stdin))
+
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				require.Nil(t, response)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantResponse, response)
+		})
+	}
+
+	t.Run("command cannot be started", func(t *testing.T) {
+		_, err := runCredentialHelper(context.Background(), []string{"/does/not/exist"}, request, &bytes.Buffer{})
+		require.EqualError(t, err, "credential helper failed: fork/exec /does/not/exist: no such file or directory")
+	})
+
+	t.Run("empty command", func(t *testing.T) {
+		require.EqualError(t, WithCredentialHelper(nil)(&handlerState{}), "credential helper command must not be empty")
+		require.EqualError(t, WithCredentialHelper([]string{""})(&handlerState{}), "credential helper command must not be empty")
+	})
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package filesession implements the file format for session caches.
@@ -52,8 +52,9 @@ type (
 	}
 )
 
-// readSessionCache loads a sessionCache from a path on disk. If the requested path does not exist, it returns an empty cache.
-func readSessionCache(path string) (*sessionCache, error) {
+// readSessionCache loads a sessionCache from a path on disk, decrypting it with the crypter if one is provided. If the
+// requested path does not exist, it returns an empty cache.
+func readSessionCache(path string, crypter Crypter) (*sessionCache, error) {
 	cacheYAML, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -64,6 +65,13 @@ func readSessionCache(path string) (*sessionCache, error) {
 		return nil, fmt.Errorf("could not read session file: %w", err)
 	}
 
+	// Decrypt the file, if it should be encrypted.
+	if crypter != nil {
+		if cacheYAML, err = crypter.Decrypt(cacheYAML); err != nil {
+			return nil, fmt.Errorf("could not decrypt session file: %w", err)
+		}
+	}
+
 	// If we read the file successfully, unmarshal it from YAML.
 	var cache sessionCache
 	if err := yaml.Unmarshal(cacheYAML, &cache); err != nil {
@@ -85,10 +93,13 @@ func emptySessionCache() *sessionCache {
 	}
 }
 
-// writeTo writes the cache to the specified file path.
-func (c *sessionCache) writeTo(path string) error {
+// writeTo writes the cache to the specified file path, encrypting it with the crypter if one is provided.
+func (c *sessionCache) writeTo(path string, crypter Crypter) error {
 	// Marshal the session back to YAML and save it to the file.
 	cacheYAML, err := yaml.Marshal(c)
+	if err == nil && crypter != nil {
+		cacheYAML, err = crypter.Encrypt(cacheYAML)
+	}
 	if err == nil {
 		err = os.WriteFile(path, cacheYAML, 0600)
 	}
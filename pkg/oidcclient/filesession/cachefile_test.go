@@ -95,7 +95,7 @@ func TestReadSessionCache(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got, err := readSessionCache(tt.path)
+			got, err := readSessionCache(tt.path, nil)
 			if tt.wantErr != "" {
 				require.EqualError(t, err, tt.wantErr)
 				require.Nil(t, got)
@@ -122,13 +122,13 @@ func TestWriteTo(t *testing.T) {
 		t.Parallel()
 		tmp := testutil.TempDir(t) + "/sessions.yaml"
 		require.NoError(t, os.Mkdir(tmp, 0700))
-		err := validSession.writeTo(tmp)
+		err := validSession.writeTo(tmp, nil)
 		require.EqualError(t, err, "open "+tmp+": is a directory")
 	})
 
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
-		require.NoError(t, validSession.writeTo(testutil.TempDir(t)+"/sessions.yaml"))
+		require.NoError(t, validSession.writeTo(testutil.TempDir(t)+"/sessions.yaml", nil))
 	})
 }
 
@@ -1,4 +1,4 @@
-// Copyright 2020-2021 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package filesession implements a simple YAML file-based login.sessionCache.
@@ -25,6 +25,10 @@ const (
 
 	// defaultFileLockRetryInterval is how often we will poll while waiting for the file lock to become available.
 	defaultFileLockRetryInterval = 10 * time.Millisecond
+
+	// defaultSessionLockRetryInterval is how often we will poll while waiting for another process to finish refreshing
+	// a session or logging in, which usually takes much longer than reading or writing the session file.
+	defaultSessionLockRetryInterval = 100 * time.Millisecond
 )
 
 // Option configures a cache in New().
@@ -38,9 +42,25 @@ func WithErrorReporter(reporter func(error)) Option {
 	}
 }
 
-// New returns a login.SessionCache implementation backed by the specified file path.
+// Crypter encrypts and decrypts the contents of the session cache file.
+type Crypter interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(data []byte) ([]byte, error)
+}
+
+// WithCrypter is an Option that specifies a Crypter which will be used to encrypt the session cache file. By default,
+// the session cache file is not encrypted. Existing unencrypted session cache files will be encrypted when next written.
+func WithCrypter(crypter Crypter) Option {
+	return func(c *Cache) {
+		c.crypter = crypter
+	}
+}
+
+// New returns a login.SessionCache implementation backed by the specified file path. It is also a
+// login.SessionCacheLocker, so that processes which share the file do not refresh a session at the same time.
 func New(path string, options ...Option) *Cache {
 	lock := flock.New(path + ".lock")
+	sessionLock := flock.New(path + ".login.lock")
 	c := Cache{
 		path: path,
 		trylockFunc: func() error {
@@ -49,8 +69,13 @@ func New(path string, options ...Option) *Cache {
 			_, err := lock.TryLockContext(ctx, defaultFileLockRetryInterval)
 			return err
 		},
-		unlockFunc:  lock.Unlock,
-		errReporter: func(_ error) {},
+		unlockFunc: lock.Unlock,
+		lockSessionFunc: func(ctx context.Context) error {
+			_, err := sessionLock.TryLockContext(ctx, defaultSessionLockRetryInterval)
+			return err
+		},
+		unlockSessionFunc: sessionLock.Unlock,
+		errReporter:       func(_ error) {},
 	}
 	for _, opt := range options {
 		opt(&c)
@@ -60,9 +85,33 @@ func New(path string, options ...Option) *Cache {
 
 type Cache struct {
 	path        string
+	crypter     Crypter
 	errReporter func(error)
 	trylockFunc func() error
 	unlockFunc  func() error
+
+	lockSessionFunc   func(context.Context) error
+	unlockSessionFunc func() error
+}
+
+var _ oidcclient.SessionCacheLocker = (*Cache)(nil)
+
+// LockSession waits until no other process holds the lock, and then holds it until the returned function is called.
+// It uses a separate lock file from the other methods, since those are called while the lock is held. The lock is
+// shared by all sessions, so that there is only one such lock file.
+func (c *Cache) LockSession(ctx context.Context, _ oidcclient.SessionCacheKey) (func(), error) {
+	// Create the cache directory if it does not exist, so that the lock file can be created.
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("could not create session cache directory: %w", err)
+	}
+	if err := c.lockSessionFunc(ctx); err != nil {
+		return nil, fmt.Errorf("could not lock session: %w", err)
+	}
+	return func() {
+		if err := c.unlockSessionFunc(); err != nil {
+			c.errReporter(fmt.Errorf("could not unlock session: %w", err))
+		}
+	}, nil
 }
 
 // GetToken looks up the cached data for the given parameters. It may return nil if no valid matching session is cached.
@@ -113,6 +162,72 @@ func (c *Cache) PutToken(key oidcclient.SessionCacheKey, token *oidctypes.Token)
 	})
 }
 
+// Session is a session from the session cache, as returned by FindTokens and DeleteTokens.
+type Session struct {
+	Key    oidcclient.SessionCacheKey
+	Tokens oidctypes.Token
+}
+
+// FindTokens returns every session whose key is matched by the provided function, e.g. so that they can be inspected.
+// Unlike the other methods, it never writes to the session cache, so a session cache which cannot be read, e.g. because
+// it was encrypted with a different key, is left as it is. It does not return an error but may silently fail to read
+// the session cache, in which case it returns no sessions.
+func (c *Cache) FindTokens(matches func(oidcclient.SessionCacheKey) bool) []Session {
+	// If the cache file does not exist, exit immediately with no error log
+	if _, err := os.Stat(c.path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	// Grab the file lock so that the file is not read while it is being written.
+	if err := c.trylockFunc(); err != nil {
+		c.errReporter(fmt.Errorf("could not lock session file: %w", err))
+		return nil
+	}
+	defer func() {
+		if err := c.unlockFunc(); err != nil {
+			c.errReporter(fmt.Errorf("could not unlock session file: %w", err))
+		}
+	}()
+
+	cache, err := readSessionCache(c.path, c.crypter)
+	if err != nil {
+		c.errReporter(fmt.Errorf("failed to read cache: %w", err))
+		return nil
+	}
+
+	var found []Session
+	for _, entry := range cache.normalized().Sessions {
+		if matches(entry.Key) {
+			found = append(found, Session{Key: entry.Key, Tokens: entry.Tokens})
+		}
+	}
+	return found
+}
+
+// DeleteTokens removes every session whose key is matched by the provided function from the session cache, and returns
+// the removed sessions, e.g. so that their tokens can be revoked. It does not return an error but may silently fail to
+// update the session cache, in which case it returns no sessions.
+func (c *Cache) DeleteTokens(matches func(oidcclient.SessionCacheKey) bool) []Session {
+	// If the cache file does not exist, exit immediately with no error log
+	if _, err := os.Stat(c.path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	var deleted []Session
+	c.withCache(func(cache *sessionCache) {
+		remaining := make([]sessionEntry, 0, len(cache.Sessions))
+		for _, entry := range cache.Sessions {
+			if matches(entry.Key) {
+				deleted = append(deleted, Session{Key: entry.Key, Tokens: entry.Tokens})
+				continue
+			}
+			remaining = append(remaining, entry)
+		}
+		cache.Sessions = remaining
+	})
+	return deleted
+}
+
 // withCache is an internal helper which locks, reads the cache, processes/mutates it with the provided function, then
 // saves it back to the file.
 func (c *Cache) withCache(transact func(*sessionCache)) {
@@ -130,7 +245,7 @@ func (c *Cache) withCache(transact func(*sessionCache)) {
 	}()
 
 	// Try to read the existing cache.
-	cache, err := readSessionCache(c.path)
+	cache, err := readSessionCache(c.path, c.crypter)
 	if err != nil {
 		// If that fails, fall back to resetting to a blank slate.
 		c.errReporter(fmt.Errorf("failed to read cache, resetting: %w", err))
@@ -147,7 +262,7 @@ func (c *Cache) withCache(transact func(*sessionCache)) {
 	cache = cache.normalized()
 
 	// Marshal the session back to YAML and save it to the file.
-	if err := cache.writeTo(c.path); err != nil {
+	if err := cache.writeTo(c.path, c.crypter); err != nil {
 		c.errReporter(fmt.Errorf("could not write session cache: %w", err))
 	}
 }
@@ -1,9 +1,11 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package filesession
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -114,7 +116,7 @@ func TestGetToken(t *testing.T) {
 						},
 					},
 				})
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key: oidcclient.SessionCacheKey{
 				Issuer:      "test-issuer",
@@ -149,7 +151,7 @@ func TestGetToken(t *testing.T) {
 						},
 					},
 				})
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key: oidcclient.SessionCacheKey{
 				Issuer:      "test-issuer",
@@ -187,7 +189,7 @@ func TestGetToken(t *testing.T) {
 						},
 					},
 				})
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key: oidcclient.SessionCacheKey{
 				Issuer:      "test-issuer",
@@ -211,7 +213,7 @@ func TestGetToken(t *testing.T) {
 				},
 			},
 			wantTestFile: func(t *testing.T, tmp string) {
-				cache, err := readSessionCache(tmp)
+				cache, err := readSessionCache(tmp, nil)
 				require.NoError(t, err)
 				require.Len(t, cache.Sessions, 1)
 				require.Less(t, time.Since(cache.Sessions[0].LastUsedTimestamp.Time).Nanoseconds(), (5 * time.Second).Nanoseconds())
@@ -323,7 +325,7 @@ func TestPutToken(t *testing.T) {
 				})
 
 				require.NoError(t, os.MkdirAll(filepath.Dir(tmp), 0700))
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key: oidcclient.SessionCacheKey{
 				Issuer:      "test-issuer",
@@ -346,7 +348,7 @@ func TestPutToken(t *testing.T) {
 				},
 			},
 			wantTestFile: func(t *testing.T, tmp string) {
-				cache, err := readSessionCache(tmp)
+				cache, err := readSessionCache(tmp, nil)
 				require.NoError(t, err)
 				require.Len(t, cache.Sessions, 1)
 				require.Less(t, time.Since(cache.Sessions[0].LastUsedTimestamp.Time).Nanoseconds(), (5 * time.Second).Nanoseconds())
@@ -395,7 +397,7 @@ func TestPutToken(t *testing.T) {
 					},
 				})
 				require.NoError(t, os.MkdirAll(filepath.Dir(tmp), 0700))
-				require.NoError(t, validCache.writeTo(tmp))
+				require.NoError(t, validCache.writeTo(tmp, nil))
 			},
 			key: oidcclient.SessionCacheKey{
 				Issuer:      "test-issuer",
@@ -418,7 +420,7 @@ func TestPutToken(t *testing.T) {
 				},
 			},
 			wantTestFile: func(t *testing.T, tmp string) {
-				cache, err := readSessionCache(tmp)
+				cache, err := readSessionCache(tmp, nil)
 				require.NoError(t, err)
 				require.Len(t, cache.Sessions, 2)
 				require.Less(t, time.Since(cache.Sessions[1].LastUsedTimestamp.Time).Nanoseconds(), (5 * time.Second).Nanoseconds())
@@ -442,7 +444,7 @@ func TestPutToken(t *testing.T) {
 			name: "error writing cache",
 			makeTestFile: func(t *testing.T, tmp string) {
 				require.NoError(t, os.MkdirAll(tmp, 0700))
-				// require.NoError(t, emptySessionCache().writeTo(tmp))
+				// require.NoError(t, emptySessionCache().writeTo(tmp, nil))
 				// require.NoError(t, os.Chmod(tmp, 0400))
 			},
 			key: oidcclient.SessionCacheKey{
@@ -470,7 +472,7 @@ func TestPutToken(t *testing.T) {
 				"could not write session cache: open TEMPFILE: is a directory",
 			},
 			wantTestFile: func(t *testing.T, tmp string) {
-				// cache, err := readSessionCache(tmp)
+				// cache, err := readSessionCache(tmp, nil)
 				// require.NoError(t, err)
 				// require.Len(t, cache.Sessions, 0)
 			},
@@ -496,6 +498,153 @@ func TestPutToken(t *testing.T) {
 	}
 }
 
+func TestFindTokens(t *testing.T) {
+	t.Parallel()
+	tmp := testutil.TempDir(t) + "/sessions.yaml"
+	isIssuer1 := func(key oidcclient.SessionCacheKey) bool { return key.Issuer == "test-issuer-1" }
+
+	// Finding in a cache file which does not exist finds nothing, and does not create the file.
+	errors := errorCollector{t: t}
+	c := New(tmp, errors.collect())
+	require.Empty(t, c.FindTokens(isIssuer1))
+	require.NoFileExists(t, tmp)
+
+	key1 := oidcclient.SessionCacheKey{Issuer: "test-issuer-1", ClientID: "test-client-id-1"}
+	key2 := oidcclient.SessionCacheKey{Issuer: "test-issuer-2", ClientID: "test-client-id-1"}
+	token1 := &oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token-1"}}
+	token2 := &oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token-2"}}
+	c.PutToken(key1, token1)
+	c.PutToken(key2, token2)
+
+	// Only the matching sessions are returned, and they stay in the cache.
+	require.Equal(t, []Session{{Key: key1, Tokens: *token1}}, c.FindTokens(isIssuer1))
+	require.Equal(t, []Session{{Key: key1, Tokens: *token1}}, c.FindTokens(isIssuer1))
+	require.Equal(t, token2, c.GetToken(key2))
+	errors.require([]string{})
+
+	// A cache file which cannot be read is reported, and left as it is.
+	errors = errorCollector{t: t}
+	require.Empty(t, New(tmp, WithCrypter(&fakeCrypter{decryptErr: fmt.Errorf("some decryption error")}), errors.collect()).FindTokens(isIssuer1))
+	errors.require([]string{"failed to read cache: could not decrypt session file: some decryption error"})
+	require.Equal(t, []Session{{Key: key1, Tokens: *token1}}, c.FindTokens(isIssuer1))
+}
+
+func TestDeleteTokens(t *testing.T) {
+	t.Parallel()
+	tmp := testutil.TempDir(t) + "/sessions.yaml"
+	isIssuer1 := func(key oidcclient.SessionCacheKey) bool { return key.Issuer == "test-issuer-1" }
+
+	// Deleting from a cache file which does not exist does nothing.
+	errors := errorCollector{t: t}
+	c := New(tmp, errors.collect())
+	require.Empty(t, c.DeleteTokens(isIssuer1))
+	require.NoFileExists(t, tmp)
+
+	key1 := oidcclient.SessionCacheKey{Issuer: "test-issuer-1", ClientID: "test-client-id-1"}
+	key2 := oidcclient.SessionCacheKey{Issuer: "test-issuer-1", ClientID: "test-client-id-2"}
+	key3 := oidcclient.SessionCacheKey{Issuer: "test-issuer-2", ClientID: "test-client-id-1"}
+	token1 := &oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token-1"}}
+	token2 := &oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token-2"}}
+	token3 := &oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token-3"}}
+	c.PutToken(key1, token1)
+	c.PutToken(key2, token2)
+	c.PutToken(key3, token3)
+
+	// Only the matching sessions are deleted and returned.
+	require.Equal(t, []Session{{Key: key1, Tokens: *token1}, {Key: key2, Tokens: *token2}}, c.DeleteTokens(isIssuer1))
+	require.Nil(t, c.GetToken(key1))
+	require.Nil(t, c.GetToken(key2))
+	require.Equal(t, token3, c.GetToken(key3))
+
+	// Deleting again finds nothing to delete.
+	require.Empty(t, c.DeleteTokens(isIssuer1))
+	require.Equal(t, token3, c.GetToken(key3))
+	errors.require([]string{})
+}
+
+func TestLockSession(t *testing.T) {
+	t.Parallel()
+	tmp := testutil.TempDir(t) + "/subdir/sessions.yaml"
+	key := oidcclient.SessionCacheKey{Issuer: "test-issuer", ClientID: "test-client-id"}
+	token := &oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token"}}
+
+	// Locking creates the directory of the cache file, but not the cache file.
+	errors := errorCollector{t: t}
+	c1 := New(tmp, errors.collect())
+	unlock, err := c1.LockSession(context.Background(), key)
+	require.NoError(t, err)
+	require.NoFileExists(t, tmp)
+
+	// The session cache can still be used while the lock is held.
+	c1.PutToken(key, token)
+	require.Equal(t, token, c1.GetToken(key))
+
+	// Another cache for the same file waits for the lock, and gives up when its context is done.
+	c2 := New(tmp, errors.collect())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c2.LockSession(ctx, oidcclient.SessionCacheKey{Issuer: "some-other-issuer"})
+	require.EqualError(t, err, "could not lock session: context deadline exceeded")
+
+	// Once the lock is released, the other cache gets it.
+	unlock()
+	unlock, err = c2.LockSession(context.Background(), key)
+	require.NoError(t, err)
+	unlock()
+	errors.require([]string{})
+}
+
+func TestWithCrypter(t *testing.T) {
+	t.Parallel()
+	tmp := testutil.TempDir(t) + "/sessions.yaml"
+	key := oidcclient.SessionCacheKey{Issuer: "test-issuer", ClientID: "test-client-id"}
+	token := &oidctypes.Token{RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token"}}
+
+	// Start with an unencrypted cache file, as written by a cache without a Crypter.
+	New(tmp).PutToken(key, token)
+	fileContents, err := os.ReadFile(tmp)
+	require.NoError(t, err)
+	require.Contains(t, string(fileContents), "test-refresh-token")
+
+	// A cache with a Crypter can read the unencrypted file, and encrypts it when writing it back.
+	errors := errorCollector{t: t}
+	c := New(tmp, WithCrypter(&fakeCrypter{}), errors.collect())
+	require.Equal(t, token, c.GetToken(key))
+	errors.require([]string{})
+	fileContents, err = os.ReadFile(tmp)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(fileContents), "encrypted:"))
+	require.NotContains(t, string(fileContents), "test-refresh-token")
+
+	// The encrypted file can be read back by a cache with the same Crypter.
+	require.Equal(t, token, c.GetToken(key))
+	errors.require([]string{})
+
+	// A Crypter which fails to decrypt causes the cache to be reset.
+	errors = errorCollector{t: t}
+	require.Nil(t, New(tmp, WithCrypter(&fakeCrypter{decryptErr: fmt.Errorf("some decrypt error")}), errors.collect()).GetToken(key))
+	errors.require([]string{"failed to read cache, resetting: could not decrypt session file: some decrypt error"})
+}
+
+// fakeCrypter is a Crypter which only base64 encodes, to make its output easy to recognize.
+type fakeCrypter struct {
+	decryptErr error
+}
+
+func (*fakeCrypter) Encrypt(plaintext []byte) ([]byte, error) {
+	return []byte("encrypted:" + base64.StdEncoding.EncodeToString(plaintext)), nil
+}
+
+func (c *fakeCrypter) Decrypt(data []byte) ([]byte, error) {
+	if c.decryptErr != nil {
+		return nil, c.decryptErr
+	}
+	if !strings.HasPrefix(string(data), "encrypted:") {
+		return data, nil
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimPrefix(string(data), "encrypted:"))
+}
+
 type errorCollector struct {
 	t   *testing.T
 	saw []error
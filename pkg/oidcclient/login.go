@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package oidcclient implements a CLI OIDC login flow.
@@ -16,6 +16,8 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
@@ -28,7 +30,9 @@ import (
 	"golang.org/x/term"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	idpdiscoveryv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
 	oidcapi "go.pinniped.dev/generated/latest/apis/supervisor/oidc"
+	"go.pinniped.dev/internal/constable"
 	"go.pinniped.dev/internal/httputil/httperr"
 	"go.pinniped.dev/internal/httputil/securityheader"
 	"go.pinniped.dev/internal/net/phttp"
@@ -55,6 +59,10 @@ const (
 	// we set this to be relatively long.
 	overallTimeout = 90 * time.Minute
 
+	// sessionLockTimeout is how long to wait for another process which is refreshing the same session or logging in,
+	// when the session cache is a SessionCacheLocker. After that, the login continues without waiting any longer.
+	sessionLockTimeout = 5 * time.Minute
+
 	defaultLDAPUsernamePrompt = "Username: "
 	defaultLDAPPasswordPrompt = "Password: "
 
@@ -64,11 +72,35 @@ const (
 	defaultPasswordEnvVarName = "PINNIPED_PASSWORD" //nolint:gosec // this is not a credential
 
 	httpLocationHeaderName = "Location"
+
+	// deviceCodeGrantType is the grant_type used to poll the token endpoint during the device authorization grant.
+	// See https://datatracker.ietf.org/doc/html/rfc8628#section-3.4.
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// defaultDeviceCodePollInterval is how often to poll the token endpoint during the device authorization grant,
+	// when the issuer does not say otherwise, and deviceCodeSlowDownIncrement is how much to increase that interval
+	// whenever the issuer asks us to slow down. See https://datatracker.ietf.org/doc/html/rfc8628#section-3.5.
+	defaultDeviceCodePollInterval = 5 * time.Second
+	deviceCodeSlowDownIncrement   = 5 * time.Second
 )
 
 // stdin returns the file descriptor for stdin as an int.
 func stdin() int { return int(os.Stdin.Fd()) }
 
+// isHeadless returns true when it appears that the CLI cannot open a web browser, or that a web browser opened by the
+// CLI would not be able to reach its localhost callback listener. This is the case in an SSH session or a container
+// without a display, unless the user has configured a browser using the BROWSER environment variable.
+func isHeadless(getEnv func(key string) string, goos string) bool {
+	if getEnv("BROWSER") != "" || getEnv("DISPLAY") != "" || getEnv("WAYLAND_DISPLAY") != "" {
+		return false
+	}
+	if getEnv("SSH_CONNECTION") != "" || getEnv("SSH_CLIENT") != "" || getEnv("SSH_TTY") != "" {
+		return true
+	}
+	// macOS and Windows can open a web browser without a display server.
+	return goos != "darwin" && goos != "windows"
+}
+
 type handlerState struct {
 	// Basic parameters.
 	ctx      context.Context
@@ -78,9 +110,16 @@ type handlerState struct {
 	scopes   []string
 	cache    SessionCache
 
-	upstreamIdentityProviderName string
-	upstreamIdentityProviderType string
-	cliToSendCredentials         bool
+	upstreamIdentityProviderName   string
+	upstreamIdentityProviderType   string
+	chooseUpstreamIdentityProvider func(context.Context, []idpdiscoveryv1alpha1.PinnipedIDP) ([]Option, error)
+	cliToSendCredentials           bool
+	cliUsername                    string
+	cliPassword                    string
+	useDeviceAuthorizationGrant    bool
+	endSession                     bool
+	credentialHelper               []string
+	refreshOnly                    bool
 
 	requestedAudience string
 
@@ -91,12 +130,15 @@ type handlerState struct {
 	callbackPath string
 
 	// Generated parameters of a login flow.
-	provider     *coreosoidc.Provider
-	oauth2Config *oauth2.Config
-	useFormPost  bool
-	state        state.State
-	nonce        nonce.Nonce
-	pkce         pkce.Code
+	provider               *coreosoidc.Provider
+	oauth2Config           *oauth2.Config
+	useFormPost            bool
+	headless               bool
+	deviceAuthorizationURL string
+	state                  state.State
+	nonce                  nonce.Nonce
+	pkce                   pkce.Code
+	refreshRejected        bool
 
 	// External calls for things.
 	generateState   func() (state.State, error)
@@ -106,10 +148,12 @@ type handlerState struct {
 	getEnv          func(key string) string
 	listen          func(string, string) (net.Listener, error)
 	isTTY           func(int) bool
+	isHeadless      func() bool
 	getProvider     func(*oauth2.Config, *coreosoidc.Provider, *http.Client) provider.UpstreamOIDCIdentityProviderI
 	validateIDToken func(ctx context.Context, provider *coreosoidc.Provider, audience string, token string) (*coreosoidc.IDToken, error)
 	promptForValue  func(ctx context.Context, promptLabel string) (string, error)
 	promptForSecret func(promptLabel string) (string, error)
+	after           func(time.Duration) <-chan time.Time
 
 	callbacks chan callbackResult
 }
@@ -119,6 +163,24 @@ type callbackResult struct {
 	err   error
 }
 
+const (
+	// ErrRefreshRejected matches (using errors.Is) the error of a login which failed after the issuer rejected the
+	// refresh token of the cached session, e.g. because the session was revoked at the upstream identity provider. The
+	// user must then log in again, which is worth telling apart from other failures in non-interactive environments.
+	ErrRefreshRejected = constable.Error("the issuer rejected the refresh token of the cached session")
+
+	// ErrInteractiveLoginRequired is returned by a login using WithRefreshOnly when the cached session could not be
+	// used or refreshed, so that a new login, which might interact with the user, would be needed.
+	ErrInteractiveLoginRequired = constable.Error("the cached session could not be refreshed, and a new login is not allowed")
+)
+
+// refreshRejectedError wraps the error of a login which failed after the issuer rejected the refresh token.
+type refreshRejectedError struct{ err error }
+
+func (e *refreshRejectedError) Error() string        { return e.err.Error() }
+func (e *refreshRejectedError) Unwrap() error        { return e.err }
+func (e *refreshRejectedError) Is(target error) bool { return target == ErrRefreshRejected }
+
 // Option is an optional configuration for Login().
 type Option func(*handlerState) error
 
@@ -185,6 +247,22 @@ func WithSkipBrowserOpen() Option {
 	}
 }
 
+// WithBrowserCommand causes the login to open the authorize URL using the given command and arguments, instead of the
+// user's default web browser. An argument which is exactly "%s" is replaced by the URL. When there is no such argument,
+// the URL is appended as the last argument. The command is started in the background and its output is written to the
+// same writers as the output of the default web browser (browser.Stdout and browser.Stderr). Since the user has chosen
+// a way to open a web browser, it also skips the detection of environments without a web browser.
+func WithBrowserCommand(command []string) Option {
+	return func(h *handlerState) error {
+		if len(command) == 0 || command[0] == "" {
+			return fmt.Errorf("browser command must not be empty")
+		}
+		h.openURL = func(url string) error { return startBrowserCommand(command, url) }
+		h.isHeadless = func() bool { return false }
+		return nil
+	}
+}
+
 // WithSkipListen causes the login skip starting the localhost listener, forcing the manual copy/paste login flow.
 func WithSkipListen() Option {
 	return func(h *handlerState) error {
@@ -206,6 +284,25 @@ type SessionCache interface {
 	PutToken(SessionCacheKey, *oidctypes.Token)
 }
 
+// SessionCacheLocker may be implemented by a SessionCache which is shared by several processes. When it is, the login
+// holds the lock of a session while it refreshes the session or logs in, so that only one process at a time does so,
+// e.g. when many kubectl commands are started at once. The other processes wait for the lock, and then use the new
+// tokens from the session cache instead of refreshing or logging in again. LockSession must block until the lock is
+// acquired or the context is done, and the returned function releases the lock.
+type SessionCacheLocker interface {
+	LockSession(ctx context.Context, key SessionCacheKey) (unlock func(), err error)
+}
+
+// WithSkipHeadlessDetection causes the login to always use a web browser and a localhost listener for the authorization
+// code flow, even when it appears that no web browser is available. By default, the login instead falls back to the
+// device authorization grant (when the issuer supports it) or to the manual copy/paste login flow in that case.
+func WithSkipHeadlessDetection() Option {
+	return func(h *handlerState) error {
+		h.isHeadless = func() bool { return false }
+		return nil
+	}
+}
+
 // WithSessionCache sets the session cache backend for storing and retrieving previously-issued ID tokens and refresh tokens.
 func WithSessionCache(cache SessionCache) Option {
 	return func(h *handlerState) error {
@@ -244,6 +341,20 @@ func WithCLISendingCredentials() Option {
 	}
 }
 
+// WithCLICredentials causes the CLI-based login flow of WithCLISendingCredentials to use the given username and
+// password, instead of reading them from environment variables or prompting for them. This lets automation such as CI
+// systems log in without any user interaction. It has no effect unless the CLI-based login flow is used.
+func WithCLICredentials(username, password string) Option {
+	return func(h *handlerState) error {
+		if username == "" || password == "" {
+			return fmt.Errorf("username and password must not be empty")
+		}
+		h.cliUsername = username
+		h.cliPassword = password
+		return nil
+	}
+}
+
 // WithUpstreamIdentityProvider causes the specified name and type to be sent as custom query parameters to the
 // issuer's authorize endpoint. This is only intended to be used when the issuer is a Pinniped Supervisor, in which
 // case it provides a mechanism to choose among several upstream identity providers.
@@ -256,6 +367,44 @@ func WithUpstreamIdentityProvider(upstreamName, upstreamType string) Option {
 	}
 }
 
+// WithUpstreamIdentityProviderChooser causes the upstream identity providers of a Pinniped Supervisor issuer to be
+// discovered when a new login is needed and no upstream identity provider was specified using
+// WithUpstreamIdentityProvider. The choose function is called with the discovered upstream identity providers, and
+// returns the options to use for the chosen one, e.g. WithUpstreamIdentityProvider and WithCLISendingCredentials.
+// It is not called when the issuer does not support upstream identity provider discovery, or when the Supervisor does
+// not have any upstream identity providers. Since the choice is only made when a new login is needed, the user is not
+// asked to choose again while their session can be refreshed.
+func WithUpstreamIdentityProviderChooser(choose func(ctx context.Context, idps []idpdiscoveryv1alpha1.PinnipedIDP) ([]Option, error)) Option {
+	return func(h *handlerState) error {
+		h.chooseUpstreamIdentityProvider = choose
+		return nil
+	}
+}
+
+// WithDeviceAuthorizationGrant causes the login flow to use the OAuth 2.0 device authorization grant
+// (see https://datatracker.ietf.org/doc/html/rfc8628) instead of the authorization code flow. A verification URL and a
+// user code are printed, which the user may visit and enter using a web browser on any device, while the CLI polls the
+// issuer until the login is complete. This needs neither a web browser nor a localhost listener on the machine which
+// runs the CLI, so it is useful for SSH sessions and containers. The issuer must advertise a
+// device_authorization_endpoint in its OIDC discovery.
+func WithDeviceAuthorizationGrant() Option {
+	return func(h *handlerState) error {
+		h.useDeviceAuthorizationGrant = true
+		return nil
+	}
+}
+
+// WithRefreshOnly causes the login to only use or refresh the tokens of a cached session. Instead of performing any
+// login flow, all of which might interact with the user, or running a credential helper, the login fails with
+// ErrInteractiveLoginRequired. This lets a background process refresh the tokens before they expire without ever
+// prompting the user.
+func WithRefreshOnly() Option {
+	return func(h *handlerState) error {
+		h.refreshOnly = true
+		return nil
+	}
+}
+
 // nopCache is a SessionCache that doesn't actually do anything.
 type nopCache struct{}
 
@@ -290,13 +439,19 @@ func Login(issuer string, clientID string, opts ...Option) (*oidctypes.Token, er
 		},
 		promptForValue:  promptForValue,
 		promptForSecret: promptForSecret,
+		after:           time.After,
 	}
+	h.isHeadless = func() bool { return isHeadless(h.getEnv, runtime.GOOS) }
 	for _, opt := range opts {
 		if err := opt(&h); err != nil {
 			return nil, err
 		}
 	}
 
+	if h.cliToSendCredentials && h.useDeviceAuthorizationGrant {
+		return nil, fmt.Errorf("cannot use CLI-based prompts for credentials with the device authorization grant")
+	}
+
 	// Copy the configured HTTP client to set a request timeout (the Go default client has no timeout configured).
 	httpClientWithTimeout := *h.httpClient
 	httpClientWithTimeout.Timeout = httpRequestTimeout
@@ -326,6 +481,9 @@ func Login(issuer string, clientID string, opts ...Option) (*oidctypes.Token, er
 	// Do the basic login to get an access and ID token issued to our main client ID.
 	baseToken, err := h.baseLogin()
 	if err != nil {
+		if h.refreshRejected {
+			return nil, &refreshRejectedError{err: err}
+		}
 		return nil, err
 	}
 
@@ -354,11 +512,29 @@ func (h *handlerState) baseLogin() (*oidctypes.Token, error) {
 
 	// If the ID token is still valid for a bit, return it immediately and skip the rest of the flow.
 	cached := h.cache.GetToken(cacheKey)
-	if cached != nil && cached.IDToken != nil && time.Until(cached.IDToken.Expiry.Time) > minIDTokenValidity {
+	if hasUnexpiredIDToken(cached) {
 		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Found unexpired cached token.")
 		return cached, nil
 	}
 
+	// When the session cache is shared with other processes, wait for any of them which is refreshing the session or
+	// logging in, and then look at the session again, since it may have been refreshed in the meantime.
+	if locker, ok := h.cache.(SessionCacheLocker); ok {
+		lockCtx, cancel := context.WithTimeout(h.ctx, sessionLockTimeout)
+		unlock, err := locker.LockSession(lockCtx, cacheKey)
+		cancel()
+		if err != nil {
+			h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Could not lock session, continuing without the lock.", "error", err.Error())
+		} else {
+			defer unlock()
+			cached = h.cache.GetToken(cacheKey)
+			if hasUnexpiredIDToken(cached) {
+				h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Found unexpired cached token after waiting for the session lock.")
+				return cached, nil
+			}
+		}
+	}
+
 	// Perform OIDC discovery.
 	if err := h.initOIDCDiscovery(); err != nil {
 		return nil, err
@@ -377,6 +553,25 @@ func (h *handlerState) baseLogin() (*oidctypes.Token, error) {
 		}
 	}
 
+	if h.refreshOnly {
+		return nil, ErrInteractiveLoginRequired
+	}
+
+	// Let the credential helper obtain new tokens, if one was configured. The upstream identity provider is only passed
+	// to the credential helper when one was specified, since the credential helper may not be able to prompt the user.
+	if h.credentialHelper != nil {
+		token, err := h.credentialHelperAuth()
+		if err == nil {
+			h.cache.PutToken(cacheKey, token)
+		}
+		return token, err
+	}
+
+	// Choose among the upstream identity providers of a Supervisor, if none was specified.
+	if err := h.chooseUpstreamIdentityProviderIfNeeded(); err != nil {
+		return nil, err
+	}
+
 	// Prepare the common options for the authorization URL. We don't have the redirect URL yet though.
 	authorizeOptions := []oauth2.AuthCodeOption{
 		oauth2.AccessTypeOffline,
@@ -398,6 +593,12 @@ func (h *handlerState) baseLogin() (*oidctypes.Token, error) {
 	if h.cliToSendCredentials {
 		authFunc = h.cliBasedAuth
 	}
+	if h.useDeviceAuthorizationGrant {
+		authFunc = h.deviceAuthorizationGrantAuth
+	}
+	if !h.cliToSendCredentials && !h.useDeviceAuthorizationGrant && h.isHeadless() {
+		authFunc = h.headlessAuth
+	}
 
 	// Perform the authorize request and authcode exchange to get back OIDC tokens.
 	token, err := authFunc(&authorizeOptions)
@@ -410,6 +611,11 @@ func (h *handlerState) baseLogin() (*oidctypes.Token, error) {
 	return token, err
 }
 
+// hasUnexpiredIDToken returns true when the cached tokens have an ID token which is still valid for a bit.
+func hasUnexpiredIDToken(cached *oidctypes.Token) bool {
+	return cached != nil && cached.IDToken != nil && time.Until(cached.IDToken.Expiry.Time) > minIDTokenValidity
+}
+
 // Make a direct call to the authorize endpoint, including the user's username and password on custom http headers,
 // and parse the authcode from the response. Exchange the authcode for tokens. Return the tokens or an error.
 func (h *handlerState) cliBasedAuth(authorizeOptions *[]oauth2.AuthCodeOption) (*oidctypes.Token, error) {
@@ -510,6 +716,11 @@ func (h *handlerState) cliBasedAuth(authorizeOptions *[]oauth2.AuthCodeOption) (
 
 // Prompt for the user's username and password, or read them from env vars if they are available.
 func (h *handlerState) getUsernameAndPassword() (string, string, error) {
+	if h.cliUsername != "" {
+		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Using the username and password which were given to the login", "username", h.cliUsername)
+		return h.cliUsername, h.cliPassword, nil
+	}
+
 	var err error
 
 	username := h.getEnv(defaultUsernameEnvVarName)
@@ -535,6 +746,47 @@ func (h *handlerState) getUsernameAndPassword() (string, string, error) {
 	return username, password, nil
 }
 
+// startBrowserCommand starts the command of WithBrowserCommand to open the URL, without waiting for it to exit, since
+// some commands only exit when the web browser is closed.
+func startBrowserCommand(command []string, url string) error {
+	args := make([]string, 0, len(command))
+	substituted := false
+	for _, arg := range command[1:] {
+		if arg == "%s" {
+			arg, substituted = url, true
+		}
+		args = append(args, arg)
+	}
+	if !substituted {
+		args = append(args, url)
+	}
+
+	cmd := exec.Command(command[0], args...) //nolint:gosec // the command was chosen by the user
+	cmd.Stdout = browser.Stdout
+	cmd.Stderr = browser.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("could not start browser command: %w", err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// headlessAuth is used instead of webBrowserBasedAuth when no web browser can be opened, or when a web browser could not
+// reach the localhost callback listener. Use the device authorization grant when the issuer supports it, and otherwise
+// ask the user to visit the authorize endpoint on another device and to paste the authcode. Return the tokens or an error.
+func (h *handlerState) headlessAuth(authorizeOptions *[]oauth2.AuthCodeOption) (*oidctypes.Token, error) {
+	if h.deviceAuthorizationURL != "" {
+		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: No web browser is available, using the device authorization grant.")
+		return h.deviceAuthorizationGrantAuth(authorizeOptions)
+	}
+
+	h.logger.V(plog.KlogLevelDebug).Info("Pinniped: No web browser is available, using the manual copy/paste login flow.")
+	h.headless = true
+	h.openURL = func(_ string) error { return nil }
+	h.listen = func(string, string) (net.Listener, error) { return nil, nil }
+	return h.webBrowserBasedAuth(authorizeOptions)
+}
+
 // Open a web browser, or ask the user to open a web browser, to visit the authorize endpoint.
 // Create a localhost callback listener which exchanges the authcode for tokens. Return the tokens or an error.
 func (h *handlerState) webBrowserBasedAuth(authorizeOptions *[]oauth2.AuthCodeOption) (*oidctypes.Token, error) {
@@ -610,8 +862,13 @@ func (h *handlerState) promptForWebLogin(ctx context.Context, authorizeURL strin
 	}
 
 	// If the server didn't support response_mode=form_post, don't bother prompting for the manual
-	// code because the user isn't going to have any easy way to manually copy it anyway.
-	if !h.useFormPost {
+	// code because the user isn't going to have any easy way to manually copy it anyway. In a headless
+	// environment the prompt is the only way to finish the login, so the user must copy the code from
+	// the address bar of the failed redirect to the localhost callback.
+	promptLabel := "    Optionally, paste your authorization code: "
+	if h.headless {
+		promptLabel = "    Paste your authorization code: "
+	} else if !h.useFormPost {
 		return func() {}
 	}
 
@@ -626,7 +883,7 @@ func (h *handlerState) promptForWebLogin(ctx context.Context, authorizeURL strin
 
 			wg.Done()
 		}()
-		code, err := h.promptForValue(ctx, "    Optionally, paste your authorization code: ")
+		code, err := h.promptForValue(ctx, promptLabel)
 		if err != nil {
 			// Print a visual marker to show the the prompt is no longer waiting for user input, plus a trailing
 			// newline that simulates the user having pressed "enter".
@@ -697,6 +954,216 @@ func promptForSecret(promptLabel string) (string, error) {
 	return string(password), err
 }
 
+// deviceAuthorizationGrantAuth performs the device authorization grant described by RFC8628. It asks the issuer for a
+// device code and a user code, prints the verification URL and user code for the user, and then polls the token
+// endpoint until the user has finished logging in. Return the tokens or an error.
+func (h *handlerState) deviceAuthorizationGrantAuth(_ *[]oauth2.AuthCodeOption) (*oidctypes.Token, error) {
+	if h.deviceAuthorizationURL == "" {
+		return nil, fmt.Errorf("issuer %q does not support the device authorization grant (no device_authorization_endpoint in OIDC discovery)", h.issuer)
+	}
+	if err := validateURLUsesHTTPS(h.deviceAuthorizationURL, "discovered device authorization URL from issuer"); err != nil {
+		return nil, err
+	}
+
+	// Request a device code and a user code. See https://datatracker.ietf.org/doc/html/rfc8628#section-3.1.
+	deviceAuthorizationParams := url.Values{
+		"client_id": []string{h.clientID},
+		"scope":     []string{strings.Join(h.scopes, " ")},
+	}
+	if h.upstreamIdentityProviderName != "" {
+		deviceAuthorizationParams.Set(oidcapi.AuthorizeUpstreamIDPNameParamName, h.upstreamIdentityProviderName)
+		deviceAuthorizationParams.Set(oidcapi.AuthorizeUpstreamIDPTypeParamName, h.upstreamIdentityProviderType)
+	}
+	var deviceAuthorization struct {
+		DeviceCode              string `json:"device_code"`
+		UserCode                string `json:"user_code"`
+		VerificationURI         string `json:"verification_uri"`
+		VerificationURIComplete string `json:"verification_uri_complete"`
+		ExpiresIn               int64  `json:"expires_in"`
+		Interval                int64  `json:"interval"`
+	}
+	if err := h.postForm(h.ctx, h.deviceAuthorizationURL, deviceAuthorizationParams, &deviceAuthorization); err != nil {
+		return nil, fmt.Errorf("device authorization request failed: %w", err)
+	}
+	if deviceAuthorization.DeviceCode == "" || deviceAuthorization.UserCode == "" || deviceAuthorization.VerificationURI == "" {
+		return nil, fmt.Errorf("device authorization response is missing device_code, user_code, or verification_uri")
+	}
+
+	// Tell the user where to log in. Prefer the URL which already includes the user code, when there is one.
+	verificationURI := deviceAuthorization.VerificationURIComplete
+	if verificationURI == "" {
+		verificationURI = deviceAuthorization.VerificationURI
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Log in by visiting this link:\n\n    %s\n\nand entering this code when prompted: %s\n\n",
+		verificationURI, deviceAuthorization.UserCode)
+
+	// Stop polling once the device code has expired.
+	ctx := h.ctx
+	if deviceAuthorization.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(deviceAuthorization.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	interval := defaultDeviceCodePollInterval
+	if deviceAuthorization.Interval > 0 {
+		interval = time.Duration(deviceAuthorization.Interval) * time.Second
+	}
+
+	// Poll the token endpoint until the user finishes logging in. See https://datatracker.ietf.org/doc/html/rfc8628#section-3.4.
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for device authorization: %w", ctx.Err())
+		case <-h.after(interval):
+		}
+
+		var tokenResponse struct {
+			AccessToken  string `json:"access_token"`
+			TokenType    string `json:"token_type"`
+			RefreshToken string `json:"refresh_token"`
+			ExpiresIn    int64  `json:"expires_in"`
+			IDToken      string `json:"id_token"`
+		}
+		err := h.postForm(ctx, h.oauth2Config.Endpoint.TokenURL, url.Values{
+			"client_id":   []string{h.clientID},
+			"grant_type":  []string{deviceCodeGrantType},
+			"device_code": []string{deviceAuthorization.DeviceCode},
+		}, &tokenResponse)
+
+		var oauthErr *oauthErrorResponse
+		switch {
+		case errors.As(err, &oauthErr) && oauthErr.ErrorCode == "authorization_pending":
+			h.logger.V(plog.KlogLevelTrace).Info("Pinniped: Waiting for device authorization.")
+			continue
+		case errors.As(err, &oauthErr) && oauthErr.ErrorCode == "slow_down":
+			interval += deviceCodeSlowDownIncrement
+			h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Slowing down polling for device authorization.", "interval", interval.String())
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("device authorization failed: %w", err)
+		}
+
+		tok := &oauth2.Token{
+			AccessToken:  tokenResponse.AccessToken,
+			TokenType:    tokenResponse.TokenType,
+			RefreshToken: tokenResponse.RefreshToken,
+		}
+		if tokenResponse.ExpiresIn > 0 {
+			tok.Expiry = time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+		}
+		tok = tok.WithExtra(map[string]interface{}{"id_token": tokenResponse.IDToken})
+
+		// No nonce was sent during the device authorization grant, so there is no nonce to validate.
+		return h.getProvider(h.oauth2Config, h.provider, h.httpClient).
+			ValidateTokenAndMergeWithUserInfo(h.ctx, tok, "", true, false)
+	}
+}
+
+// oauthErrorResponse is an OAuth 2.0 error response from an issuer.
+// See https://datatracker.ietf.org/doc/html/rfc6749#section-5.2.
+type oauthErrorResponse struct {
+	ErrorCode        string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (e *oauthErrorResponse) Error() string {
+	if e.ErrorDescription != "" {
+		return fmt.Sprintf("login failed with code %q: %s", e.ErrorCode, e.ErrorDescription)
+	}
+	return fmt.Sprintf("login failed with code %q", e.ErrorCode)
+}
+
+// postForm makes an HTTP POST request with the given form parameters to the given endpoint, and decodes a successful
+// JSON response into respBody. When the issuer returns an OAuth 2.0 error response, it returns an *oauthErrorResponse.
+func (h *handlerState) postForm(ctx context.Context, endpoint string, params url.Values, respBody interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("could not build request: %w", err)
+	}
+	req.Header.Set("content-type", "application/x-www-form-urlencoded")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("content-type"))
+	if err != nil || mediaType != "application/json" {
+		return fmt.Errorf("unexpected HTTP response status %d with content type %q", resp.StatusCode, resp.Header.Get("content-type"))
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(respBody); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	case http.StatusBadRequest, http.StatusUnauthorized:
+		var oauthErr oauthErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&oauthErr); err != nil || oauthErr.ErrorCode == "" {
+			return fmt.Errorf("unexpected HTTP response status %d", resp.StatusCode)
+		}
+		return &oauthErr
+	default:
+		return fmt.Errorf("unexpected HTTP response status %d", resp.StatusCode)
+	}
+}
+
+// chooseUpstreamIdentityProviderIfNeeded discovers the upstream identity providers of a Supervisor issuer and applies
+// the options returned by the chooser, when there is a chooser and no upstream identity provider was specified.
+func (h *handlerState) chooseUpstreamIdentityProviderIfNeeded() error {
+	if h.chooseUpstreamIdentityProvider == nil || h.upstreamIdentityProviderName != "" {
+		return nil
+	}
+
+	var discoveryClaims idpdiscoveryv1alpha1.OIDCDiscoveryResponse
+	if err := h.provider.Claims(&discoveryClaims); err != nil {
+		return fmt.Errorf("could not decode the Supervisor discovery in OIDC discovery from %q: %w", h.issuer, err)
+	}
+	idpsEndpoint := discoveryClaims.SupervisorDiscovery.PinnipedIDPsEndpoint
+	if idpsEndpoint == "" {
+		// The issuer is not a Pinniped Supervisor which supports upstream IDP discovery.
+		return nil
+	}
+
+	h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Performing upstream identity provider discovery", "endpoint", idpsEndpoint)
+	req, err := http.NewRequestWithContext(h.ctx, http.MethodGet, idpsEndpoint, nil)
+	if err != nil {
+		return fmt.Errorf("could not build request: %w", err)
+	}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not discover upstream identity providers: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not discover upstream identity providers: unexpected HTTP response status %d", resp.StatusCode)
+	}
+	var discovery idpdiscoveryv1alpha1.IDPDiscoveryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return fmt.Errorf("could not discover upstream identity providers: failed to decode response: %w", err)
+	}
+	if len(discovery.PinnipedIDPs) == 0 {
+		return nil
+	}
+
+	opts, err := h.chooseUpstreamIdentityProvider(h.ctx, discovery.PinnipedIDPs)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return err
+		}
+	}
+	if h.cliToSendCredentials && h.useDeviceAuthorizationGrant {
+		return fmt.Errorf("cannot use CLI-based prompts for credentials with the device authorization grant")
+	}
+	return nil
+}
+
 func (h *handlerState) initOIDCDiscovery() error {
 	// Make this method idempotent so it can be called in multiple cases with no extra network requests.
 	if h.provider != nil {
@@ -742,6 +1209,15 @@ func (h *handlerState) initOIDCDiscovery() error {
 		return fmt.Errorf("could not decode response_modes_supported in OIDC discovery from %q: %w", h.issuer, err)
 	}
 	h.useFormPost = stringSliceContains(discoveryClaims.ResponseModesSupported, "form_post")
+
+	// Remember where to start the device authorization grant, if the provider supports it.
+	var deviceClaims struct {
+		DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	}
+	if err := h.provider.Claims(&deviceClaims); err != nil {
+		return fmt.Errorf("could not decode device_authorization_endpoint in OIDC discovery from %q: %w", h.issuer, err)
+	}
+	h.deviceAuthorizationURL = deviceClaims.DeviceAuthorizationEndpoint
 	return nil
 }
 
@@ -836,6 +1312,22 @@ func (h *handlerState) tokenExchangeRFC8693(baseToken *oidctypes.Token) (*oidcty
 	}}, nil
 }
 
+// isRefreshRejected returns true when the token endpoint rejected the refresh token itself (see RFC6749 section 5.2),
+// e.g. because the session was revoked, as opposed to failing for another reason, such as a network error.
+func isRefreshRejected(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) {
+		return false
+	}
+	var response struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(retrieveErr.Body, &response); err != nil {
+		return false
+	}
+	return response.Error == "invalid_grant"
+}
+
 func (h *handlerState) handleRefresh(ctx context.Context, refreshToken *oidctypes.RefreshToken) (*oidctypes.Token, error) {
 	h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Refreshing cached token.")
 	upstreamOIDCIdentityProvider := h.getProvider(h.oauth2Config, h.provider, h.httpClient)
@@ -844,6 +1336,7 @@ func (h *handlerState) handleRefresh(ctx context.Context, refreshToken *oidctype
 	if err != nil {
 		// Ignore errors during refresh, but return nil which will trigger the full login flow.
 		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Refresh failed.", "error", err.Error())
+		h.refreshRejected = isRefreshRejected(err)
 		return nil, nil
 	}
 
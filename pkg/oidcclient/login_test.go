@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package oidcclient
@@ -15,7 +15,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -28,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
+	idpdiscoveryv1alpha1 "go.pinniped.dev/generated/latest/apis/supervisor/idpdiscovery/v1alpha1"
 	"go.pinniped.dev/internal/httputil/httperr"
 	"go.pinniped.dev/internal/httputil/roundtripper"
 	"go.pinniped.dev/internal/mocks/mockupstreamoidcidentityprovider"
@@ -65,6 +69,25 @@ func (m *mockSessionCache) PutToken(key SessionCacheKey, token *oidctypes.Token)
 	m.sawPutTokens = append(m.sawPutTokens, token)
 }
 
+// mockLockingSessionCache is a mockSessionCache which is also a SessionCacheLocker. Once locked, GetToken returns
+// getReturnsTokenAfterLock, like a session cache which was updated by another process while waiting for the lock.
+type mockLockingSessionCache struct {
+	mockSessionCache
+	getReturnsTokenAfterLock *oidctypes.Token
+	lockErr                  error
+	sawLockKeys              []SessionCacheKey
+	sawUnlocks               int
+}
+
+func (m *mockLockingSessionCache) LockSession(_ context.Context, key SessionCacheKey) (func(), error) {
+	m.sawLockKeys = append(m.sawLockKeys, key)
+	if m.lockErr != nil {
+		return nil, m.lockErr
+	}
+	m.getReturnsToken = m.getReturnsTokenAfterLock
+	return func() { m.sawUnlocks++ }, nil
+}
+
 func newClientForServer(server *httptest.Server) *http.Client {
 	pool := x509.NewCertPool()
 	caPEMData := tlsserver.TLSTestServerCA(server)
@@ -238,6 +261,12 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 			response.RefreshToken = testToken.RefreshToken.Token
 			response.IDToken = testToken.IDToken.Token
 
+			if r.Form.Get("refresh_token") == "test-revoked-refresh-token" {
+				w.Header().Set("content-type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"the session was revoked"}`))
+				return
+			}
 			if r.Form.Get("refresh_token") == "test-refresh-token-returning-invalid-id-token" {
 				response.IDToken = "not a valid JWT"
 			} else if r.Form.Get("refresh_token") != "test-refresh-token" {
@@ -304,6 +333,145 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 	formPostProviderMux.HandleFunc("/.well-known/openid-configuration", discoveryHandler(formPostSuccessServer, []string{"query", "form_post"}))
 	formPostProviderMux.HandleFunc("/token", tokenHandler)
 
+	// Start a test server that supports the device authorization grant. The device code which it hands out is
+	// derived from the client ID, so each test can choose how the polling of the token endpoint will go.
+	deviceMux := http.NewServeMux()
+	deviceServer := tlsserver.TLSTestServer(t, deviceMux, nil)
+	deviceMux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(&struct {
+			Issuer                      string `json:"issuer"`
+			AuthURL                     string `json:"authorization_endpoint"`
+			TokenURL                    string `json:"token_endpoint"`
+			JWKSURL                     string `json:"jwks_uri"`
+			DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+			idpdiscoveryv1alpha1.OIDCDiscoveryResponse
+		}{
+			Issuer:                      deviceServer.URL,
+			AuthURL:                     deviceServer.URL + "/authorize",
+			TokenURL:                    deviceServer.URL + "/token",
+			JWKSURL:                     deviceServer.URL + "/keys",
+			DeviceAuthorizationEndpoint: deviceServer.URL + "/device_authorization",
+			OIDCDiscoveryResponse: idpdiscoveryv1alpha1.OIDCDiscoveryResponse{
+				SupervisorDiscovery: idpdiscoveryv1alpha1.OIDCDiscoveryResponseIDPEndpoint{
+					PinnipedIDPsEndpoint: deviceServer.URL + "/idps",
+				},
+			},
+		})
+	})
+	deviceMux.HandleFunc("/idps", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_, _ = w.Write([]byte(`{"pinniped_identity_providers":[{"name":"some-ldap-idp","type":"ldap"},{"name":"some-oidc-idp","type":"oidc"}]}`))
+	})
+	deviceMux.HandleFunc("/device_authorization", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("client_id") == "test-client-id-rejected" {
+			w.Header().Set("content-type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"some client error"}`))
+			return
+		}
+		if r.Form.Get("scope") != "test-scope" {
+			http.Error(w, "expected scope 'test-scope'", http.StatusBadRequest)
+			return
+		}
+		if strings.HasPrefix(r.Form.Get("client_id"), "test-client-id-chosen-idp") &&
+			(r.Form.Get("pinniped_idp_name") != "some-oidc-idp" || r.Form.Get("pinniped_idp_type") != "oidc") {
+			http.Error(w, "expected the chosen upstream identity provider", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"device_code":               "device-code-for-" + r.Form.Get("client_id"),
+			"user_code":                 "ABCD-EFGH",
+			"verification_uri":          deviceServer.URL + "/device",
+			"verification_uri_complete": deviceServer.URL + "/device?user_code=ABCD-EFGH",
+			"expires_in":                600,
+			"interval":                  1,
+		})
+	})
+	var devicePollsMutex sync.Mutex
+	devicePolls := map[string]int{}
+	deviceMux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" {
+			http.Error(w, fmt.Sprintf("invalid grant_type %q", r.Form.Get("grant_type")), http.StatusBadRequest)
+			return
+		}
+		deviceCode := r.Form.Get("device_code")
+		if deviceCode != "device-code-for-"+r.Form.Get("client_id") {
+			http.Error(w, "wrong device_code", http.StatusBadRequest)
+			return
+		}
+		devicePollsMutex.Lock()
+		devicePolls[deviceCode]++
+		polls := devicePolls[deviceCode]
+		devicePollsMutex.Unlock()
+
+		writeError := func(code string) {
+			w.Header().Set("content-type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(w, `{"error":%q}`, code)
+		}
+		switch {
+		case deviceCode == "device-code-for-test-client-id-denied":
+			writeError("access_denied")
+			return
+		case deviceCode == "device-code-for-test-client-id-slow-down" && polls == 1:
+			writeError("slow_down")
+			return
+		case polls < 3:
+			writeError("authorization_pending")
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  testToken.AccessToken.Token,
+			"token_type":    "Bearer",
+			"refresh_token": testToken.RefreshToken.Token,
+			"expires_in":    int64(time.Until(testToken.AccessToken.Expiry.Time).Seconds()),
+			"id_token":      testToken.IDToken.Token,
+		})
+	})
+	deviceTestOpts := func(t *testing.T, wantIntervals []time.Duration) func(h *handlerState) error {
+		return func(h *handlerState) error {
+			require.NoError(t, WithDeviceAuthorizationGrant()(h))
+			require.NoError(t, WithClient(newClientForServer(deviceServer))(h))
+
+			var sawIntervals []time.Duration
+			h.after = func(d time.Duration) <-chan time.Time {
+				sawIntervals = append(sawIntervals, d)
+				c := make(chan time.Time, 1)
+				c <- time.Now()
+				return c
+			}
+			if wantIntervals != nil {
+				t.Cleanup(func() {
+					require.Equal(t, wantIntervals, sawIntervals)
+				})
+			}
+
+			h.getProvider = func(config *oauth2.Config, provider *oidc.Provider, client *http.Client) provider.UpstreamOIDCIdentityProviderI {
+				mock := mockUpstream(t)
+				mock.EXPECT().
+					ValidateTokenAndMergeWithUserInfo(gomock.Any(), HasAccessToken(testToken.AccessToken.Token), nonce.Nonce(""), true, false).
+					Return(&testToken, nil)
+				return mock
+			}
+			return nil
+		}
+	}
+
 	defaultDiscoveryResponse := func(req *http.Request) (*http.Response, error) {
 		// Call the handler function from the test server to calculate the response.
 		handler, _ := providerMux.Handler(req)
@@ -356,6 +524,7 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 		issuer    string
 		clientID  string
 		wantErr   string
+		wantErrIs error
 		wantToken *oidctypes.Token
 		wantLogs  []string
 	}{
@@ -472,6 +641,71 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 			wantLogs:  []string{"\"level\"=4 \"msg\"=\"Pinniped: Found unexpired cached token.\""},
 			wantToken: &testToken,
 		},
+		{
+			name:     "session cache hit but token expired, and another process refreshed it while waiting for the lock",
+			issuer:   "test-issuer",
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					cache := &mockLockingSessionCache{
+						mockSessionCache: mockSessionCache{t: t, getReturnsToken: &oidctypes.Token{
+							IDToken: &oidctypes.IDToken{
+								Token:  "expired-test-id-token",
+								Expiry: metav1.Now(), // less than Now() + minIDTokenValidity
+							},
+							RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token"},
+						}},
+						getReturnsTokenAfterLock: &testToken,
+					}
+					t.Cleanup(func() {
+						cacheKey := SessionCacheKey{
+							Issuer:      "test-issuer",
+							ClientID:    "test-client-id",
+							Scopes:      []string{"test-scope"},
+							RedirectURI: "http://localhost:0/callback",
+						}
+						require.Equal(t, []SessionCacheKey{cacheKey}, cache.sawLockKeys)
+						require.Equal(t, 1, cache.sawUnlocks)
+						require.Equal(t, []SessionCacheKey{cacheKey, cacheKey}, cache.sawGetKeys)
+						require.Empty(t, cache.sawPutTokens)
+					})
+					return WithSessionCache(cache)(h)
+				}
+			},
+			wantLogs:  []string{"\"level\"=4 \"msg\"=\"Pinniped: Found unexpired cached token after waiting for the session lock.\""},
+			wantToken: &testToken,
+		},
+		{
+			name:     "session cache hit but token expired, and the session cannot be locked",
+			issuer:   errorServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(errorServer))(h))
+					cache := &mockLockingSessionCache{
+						mockSessionCache: mockSessionCache{t: t, getReturnsToken: &oidctypes.Token{
+							IDToken: &oidctypes.IDToken{
+								Token:  "test-id-token",
+								Expiry: metav1.NewTime(time.Now()), // less than Now() + minIDTokenValidity
+							},
+						}},
+						lockErr: fmt.Errorf("some lock error"),
+					}
+					t.Cleanup(func() {
+						require.Len(t, cache.sawLockKeys, 1)
+						require.Zero(t, cache.sawUnlocks)
+						require.Len(t, cache.sawGetKeys, 1)
+						require.Empty(t, cache.sawPutTokens)
+					})
+					return WithSessionCache(cache)(h)
+				}
+			},
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Could not lock session, continuing without the lock.\"  \"error\"=\"some lock error\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + errorServer.URL + "\"",
+			},
+			wantErr: "could not perform OIDC discovery for \"" + errorServer.URL + "\": 500 Internal Server Error: some discovery error\n",
+		},
 		{
 			name: "discovery failure due to 500 error",
 			opt: func(t *testing.T) Option {
@@ -622,6 +856,98 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 			// Expect this to fall through to the authorization code flow, so it fails here.
 			wantErr: "login failed: must have either a localhost listener or stdin must be a TTY",
 		},
+		{
+			name:     "refresh only, session cache hit but refresh fails",
+			issuer:   successServer.URL,
+			clientID: "not-the-test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					require.NoError(t, WithRefreshOnly()(h))
+
+					cache := &mockSessionCache{t: t, getReturnsToken: &oidctypes.Token{
+						IDToken: &oidctypes.IDToken{
+							Token:  "expired-test-id-token",
+							Expiry: metav1.Now(), // less than Now() + minIDTokenValidity
+						},
+						RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token"},
+					}}
+					t.Cleanup(func() {
+						require.Empty(t, cache.sawPutKeys)
+						require.Empty(t, cache.sawPutTokens)
+					})
+					h.cache = cache
+
+					h.listen = func(string, string) (net.Listener, error) {
+						t.Error("unexpected call to listen")
+						return nil, fmt.Errorf("unexpected call to listen")
+					}
+					return nil
+				}
+			},
+			wantLogs: []string{
+				`"level"=4 "msg"="Pinniped: Performing OIDC discovery"  "issuer"="` + successServer.URL + `"`,
+				`"level"=4 "msg"="Pinniped: Refreshing cached token."`,
+				`"level"=4 "msg"="Pinniped: Refresh failed."  "error"="oauth2: cannot fetch token: 400 Bad Request\nResponse: expected client_id 'test-client-id'\n"`,
+			},
+			// Expect this to fail instead of falling through to the authorization code flow.
+			wantErr:   "the cached session could not be refreshed, and a new login is not allowed",
+			wantErrIs: ErrInteractiveLoginRequired,
+		},
+		{
+			name:     "refresh only, no session cache hit",
+			issuer:   successServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					require.NoError(t, WithRefreshOnly()(h))
+					require.NoError(t, WithCredentialHelper([]string{"/does/not/exist"})(h))
+					return nil
+				}
+			},
+			wantLogs: []string{
+				`"level"=4 "msg"="Pinniped: Performing OIDC discovery"  "issuer"="` + successServer.URL + `"`,
+			},
+			wantErr:   "the cached session could not be refreshed, and a new login is not allowed",
+			wantErrIs: ErrInteractiveLoginRequired,
+		},
+		{
+			name:     "session cache hit but refresh is rejected, then login fails",
+			issuer:   successServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+
+					cache := &mockSessionCache{t: t, getReturnsToken: &oidctypes.Token{
+						IDToken: &oidctypes.IDToken{
+							Token:  "expired-test-id-token",
+							Expiry: metav1.Now(), // less than Now() + minIDTokenValidity
+						},
+						RefreshToken: &oidctypes.RefreshToken{Token: "test-revoked-refresh-token"},
+					}}
+					t.Cleanup(func() {
+						require.Empty(t, cache.sawPutKeys)
+						require.Empty(t, cache.sawPutTokens)
+					})
+					h.cache = cache
+
+					h.listen = func(string, string) (net.Listener, error) { return nil, fmt.Errorf("some listen error") }
+					h.isTTY = func(int) bool { return false }
+					return nil
+				}
+			},
+			wantLogs: []string{
+				`"level"=4 "msg"="Pinniped: Performing OIDC discovery"  "issuer"="` + successServer.URL + `"`,
+				`"level"=4 "msg"="Pinniped: Refreshing cached token."`,
+				`"level"=4 "msg"="Pinniped: Refresh failed."  "error"="oauth2: cannot fetch token: 400 Bad Request\nResponse: {\"error\":\"invalid_grant\",\"error_description\":\"the session was revoked\"}"`,
+				`"msg"="could not open callback listener" "error"="some listen error"`,
+			},
+			// Expect this to fall through to the authorization code flow, so it fails here.
+			wantErr:   "login failed: must have either a localhost listener or stdin must be a TTY",
+			wantErrIs: ErrRefreshRejected,
+		},
 		{
 			name: "issuer has invalid token URL",
 			opt: func(t *testing.T) Option {
@@ -741,114 +1067,444 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 					return nil
 				}
 			},
-			issuer: formPostSuccessServer.URL,
+			issuer: formPostSuccessServer.URL,
+			wantLogs: []string{
+				`"level"=4 "msg"="Pinniped: Performing OIDC discovery"  "issuer"="` + formPostSuccessServer.URL + `"`,
+				`"msg"="could not open callback listener" "error"="some listen error"`,
+			},
+			wantErr: "error handling callback: failed to prompt for manual authorization code: some prompt error",
+		},
+		{
+			name: "timeout waiting for callback",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+
+					ctx, cancel := context.WithCancel(h.ctx)
+					h.ctx = ctx
+
+					h.openURL = func(_ string) error {
+						cancel()
+						return nil
+					}
+					return nil
+				}
+			},
+			issuer:   successServer.URL,
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
+			wantErr:  "timed out waiting for token callback: context canceled",
+		},
+		{
+			name: "callback returns error",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					h.openURL = func(_ string) error {
+						go func() {
+							h.callbacks <- callbackResult{err: fmt.Errorf("some callback error")}
+						}()
+						return nil
+					}
+					return nil
+				}
+			},
+			issuer:   successServer.URL,
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
+			wantErr:  "error handling callback: some callback error",
+		},
+		{
+			name:     "callback returns success",
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					h.generateState = func() (state.State, error) { return "test-state", nil }
+					h.generatePKCE = func() (pkce.Code, error) { return "test-pkce", nil }
+					h.generateNonce = func() (nonce.Nonce, error) { return "test-nonce", nil }
+
+					cache := &mockSessionCache{t: t, getReturnsToken: nil}
+					cacheKey := SessionCacheKey{
+						Issuer:      successServer.URL,
+						ClientID:    "test-client-id",
+						Scopes:      []string{"test-scope"},
+						RedirectURI: "http://localhost:0/callback",
+					}
+					t.Cleanup(func() {
+						require.Equal(t, []SessionCacheKey{cacheKey}, cache.sawGetKeys)
+						require.Equal(t, []SessionCacheKey{cacheKey}, cache.sawPutKeys)
+						require.Equal(t, []*oidctypes.Token{&testToken}, cache.sawPutTokens)
+					})
+					require.NoError(t, WithSessionCache(cache)(h))
+
+					client := newClientForServer(successServer)
+					client.Timeout = 10 * time.Second
+					require.NoError(t, WithClient(client)(h))
+
+					h.openURL = func(actualURL string) error {
+						parsedActualURL, err := url.Parse(actualURL)
+						require.NoError(t, err)
+						actualParams := parsedActualURL.Query()
+
+						require.Contains(t, actualParams.Get("redirect_uri"), "http://127.0.0.1:")
+						actualParams.Del("redirect_uri")
+
+						require.Equal(t, url.Values{
+							// This is the PKCE challenge which is calculated as base64(sha256("test-pkce")). For example:
+							// $ echo -n test-pkce | shasum -a 256 | cut -d" " -f1 | xxd -r -p | base64 | cut -d"=" -f1
+							// VVaezYqum7reIhoavCHD1n2d+piN3r/mywoYj7fCR7g
+							"code_challenge":        []string{"VVaezYqum7reIhoavCHD1n2d-piN3r_mywoYj7fCR7g"},
+							"code_challenge_method": []string{"S256"},
+							"response_type":         []string{"code"},
+							"scope":                 []string{"test-scope"},
+							"nonce":                 []string{"test-nonce"},
+							"state":                 []string{"test-state"},
+							"access_type":           []string{"offline"},
+							"client_id":             []string{"test-client-id"},
+						}, actualParams)
+
+						parsedActualURL.RawQuery = ""
+						require.Equal(t, successServer.URL+"/authorize", parsedActualURL.String())
+
+						go func() {
+							h.callbacks <- callbackResult{token: &testToken}
+						}()
+						return nil
+					}
+					return nil
+				}
+			},
+			issuer:    successServer.URL,
+			wantLogs:  []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
+			wantToken: &testToken,
+		},
+		{
+			name:     "credential helper",
+			issuer:   successServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					cache := &mockSessionCache{t: t, getReturnsToken: nil}
+					t.Cleanup(func() {
+						require.Equal(t, []*oidctypes.Token{&testToken}, cache.sawPutTokens)
+					})
+					require.NoError(t, WithSessionCache(cache)(h))
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					require.NoError(t, WithCredentialHelper([]string{"sh", "-c",
+						`echo '{"apiVersion": "credentialhelper.pinniped.dev/v1alpha1", "idToken": "helper-id-token", "accessToken": "helper-access-token"}'`})(h))
+
+					h.getProvider = func(config *oauth2.Config, provider *oidc.Provider, client *http.Client) provider.UpstreamOIDCIdentityProviderI {
+						mock := mockUpstream(t)
+						mock.EXPECT().
+							ValidateTokenAndMergeWithUserInfo(gomock.Any(), HasAccessToken("helper-access-token"), nonce.Nonce(""), true, false).
+							Return(&testToken, nil)
+						return mock
+					}
+					return nil
+				}
+			},
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: Running credential helper.\"  \"command\"=\"sh\"",
+			},
+			wantToken: &testToken,
+		},
+		{
+			name:     "credential helper returns invalid tokens",
+			issuer:   successServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					require.NoError(t, WithCredentialHelper([]string{"sh", "-c",
+						`echo '{"apiVersion": "credentialhelper.pinniped.dev/v1alpha1", "idToken": "helper-id-token", "accessToken": "helper-access-token"}'`})(h))
+
+					h.getProvider = func(config *oauth2.Config, provider *oidc.Provider, client *http.Client) provider.UpstreamOIDCIdentityProviderI {
+						mock := mockUpstream(t)
+						mock.EXPECT().
+							ValidateTokenAndMergeWithUserInfo(gomock.Any(), HasAccessToken("helper-access-token"), nonce.Nonce(""), true, false).
+							Return(nil, fmt.Errorf("some validation error"))
+						return mock
+					}
+					return nil
+				}
+			},
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: Running credential helper.\"  \"command\"=\"sh\"",
+			},
+			wantErr: "credential helper returned invalid tokens: some validation error",
+		},
+		{
+			name:     "credential helper fails",
+			issuer:   successServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					return WithCredentialHelper([]string{"sh", "-c", "exit 1"})(h)
+				}
+			},
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: Running credential helper.\"  \"command\"=\"sh\"",
+			},
+			wantErr: "credential helper failed: exit status 1",
+		},
+		{
+			name:     "device authorization grant with CLI-based prompts",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					return WithCLISendingCredentials()(h)
+				}
+			},
+			wantErr: "cannot use CLI-based prompts for credentials with the device authorization grant",
+		},
+		{
+			name:     "device authorization grant when the issuer does not support it",
+			issuer:   successServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					return WithClient(newClientForServer(successServer))(h)
+				}
+			},
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
+			wantErr:  fmt.Sprintf("issuer %q does not support the device authorization grant (no device_authorization_endpoint in OIDC discovery)", successServer.URL),
+		},
+		{
+			name:     "device authorization request is rejected",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id-rejected",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					return WithClient(newClientForServer(deviceServer))(h)
+				}
+			},
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\""},
+			wantErr:  `device authorization request failed: login failed with code "invalid_client": some client error`,
+		},
+		{
+			name:     "device authorization grant is denied by the user",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id-denied",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					require.NoError(t, WithClient(newClientForServer(deviceServer))(h))
+					h.after = func(time.Duration) <-chan time.Time {
+						c := make(chan time.Time, 1)
+						c <- time.Now()
+						return c
+					}
+					return nil
+				}
+			},
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\""},
+			wantErr:  `device authorization failed: login failed with code "access_denied"`,
+		},
+		{
+			name:     "device authorization grant times out",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id-timeout",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					require.NoError(t, WithClient(newClientForServer(deviceServer))(h))
+					ctx, cancel := context.WithCancel(h.ctx)
+					h.ctx = ctx
+					h.after = func(time.Duration) <-chan time.Time {
+						cancel()
+						return make(chan time.Time)
+					}
+					return nil
+				}
+			},
+			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\""},
+			wantErr:  "timed out waiting for device authorization: context canceled",
+		},
+		{
+			name:     "headless login uses the device authorization grant when the issuer supports it",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id-headless",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, deviceTestOpts(t, []time.Duration{1 * time.Second, 1 * time.Second, 1 * time.Second})(h))
+					// The device authorization grant is chosen because there is no browser, not because it was requested.
+					h.useDeviceAuthorizationGrant = false
+					h.isHeadless = func() bool { return true }
+					return nil
+				}
+			},
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: No web browser is available, using the device authorization grant.\"",
+				"\"level\"=6 \"msg\"=\"Pinniped: Waiting for device authorization.\"",
+				"\"level\"=6 \"msg\"=\"Pinniped: Waiting for device authorization.\"",
+			},
+			wantToken: &testToken,
+		},
+		{
+			name:     "headless login prompts for the authorization code when the issuer does not support the device authorization grant",
+			issuer:   successServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					h.isHeadless = func() bool { return true }
+					h.isTTY = func(int) bool { return true }
+					h.openURL = func(string) error {
+						t.Error("the browser should not be opened")
+						return nil
+					}
+					h.listen = func(string, string) (net.Listener, error) {
+						t.Error("the localhost listener should not be started")
+						return nil, nil
+					}
+					h.promptForValue = func(_ context.Context, promptLabel string) (string, error) {
+						// The prompt is shown even though the issuer does not support response_mode=form_post.
+						require.Equal(t, "    Paste your authorization code: ", promptLabel)
+						return "", fmt.Errorf("some prompt error")
+					}
+					return nil
+				}
+			},
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: No web browser is available, using the manual copy/paste login flow.\"",
+			},
+			wantErr: "error handling callback: failed to prompt for manual authorization code: some prompt error",
+		},
+		{
+			name:     "headless login with non-tty stdin",
+			issuer:   successServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, WithClient(newClientForServer(successServer))(h))
+					h.isHeadless = func() bool { return true }
+					h.isTTY = func(int) bool { return false }
+					return nil
+				}
+			},
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: No web browser is available, using the manual copy/paste login flow.\"",
+			},
+			wantErr: "login failed: must have either a localhost listener or stdin must be a TTY",
+		},
+		{
+			name:     "device authorization grant succeeds after polling",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return deviceTestOpts(t, []time.Duration{1 * time.Second, 1 * time.Second, 1 * time.Second})
+			},
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\"",
+				"\"level\"=6 \"msg\"=\"Pinniped: Waiting for device authorization.\"",
+				"\"level\"=6 \"msg\"=\"Pinniped: Waiting for device authorization.\"",
+			},
+			wantToken: &testToken,
+		},
+		{
+			name:     "device authorization grant slows down when asked",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id-slow-down",
+			opt: func(t *testing.T) Option {
+				return deviceTestOpts(t, []time.Duration{1 * time.Second, 6 * time.Second, 6 * time.Second})
+			},
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: Slowing down polling for device authorization.\"  \"interval\"=\"6s\"",
+				"\"level\"=6 \"msg\"=\"Pinniped: Waiting for device authorization.\"",
+			},
+			wantToken: &testToken,
+		},
+		{
+			name:     "device authorization grant with an upstream identity provider chosen by the user",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id-chosen-idp",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					require.NoError(t, deviceTestOpts(t, nil)(h))
+					return WithUpstreamIdentityProviderChooser(func(_ context.Context, idps []idpdiscoveryv1alpha1.PinnipedIDP) ([]Option, error) {
+						require.Equal(t, []idpdiscoveryv1alpha1.PinnipedIDP{
+							{Name: "some-ldap-idp", Type: idpdiscoveryv1alpha1.IDPTypeLDAP},
+							{Name: "some-oidc-idp", Type: idpdiscoveryv1alpha1.IDPTypeOIDC},
+						}, idps)
+						return []Option{WithUpstreamIdentityProvider("some-oidc-idp", "oidc")}, nil
+					})(h)
+				}
+			},
 			wantLogs: []string{
-				`"level"=4 "msg"="Pinniped: Performing OIDC discovery"  "issuer"="` + formPostSuccessServer.URL + `"`,
-				`"msg"="could not open callback listener" "error"="some listen error"`,
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing upstream identity provider discovery\"  \"endpoint\"=\"" + deviceServer.URL + "/idps\"",
+				"\"level\"=6 \"msg\"=\"Pinniped: Waiting for device authorization.\"",
+				"\"level\"=6 \"msg\"=\"Pinniped: Waiting for device authorization.\"",
 			},
-			wantErr: "error handling callback: failed to prompt for manual authorization code: some prompt error",
+			wantToken: &testToken,
 		},
 		{
-			name: "timeout waiting for callback",
+			name:     "upstream identity provider chooser is not used when an upstream identity provider was specified",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id-chosen-idp-specified",
 			opt: func(t *testing.T) Option {
 				return func(h *handlerState) error {
-					require.NoError(t, WithClient(newClientForServer(successServer))(h))
-
-					ctx, cancel := context.WithCancel(h.ctx)
-					h.ctx = ctx
-
-					h.openURL = func(_ string) error {
-						cancel()
-						return nil
-					}
-					return nil
+					require.NoError(t, deviceTestOpts(t, nil)(h))
+					require.NoError(t, WithUpstreamIdentityProvider("some-oidc-idp", "oidc")(h))
+					return WithUpstreamIdentityProviderChooser(func(context.Context, []idpdiscoveryv1alpha1.PinnipedIDP) ([]Option, error) {
+						t.Error("unexpected call to the upstream identity provider chooser")
+						return nil, nil
+					})(h)
 				}
 			},
-			issuer:   successServer.URL,
-			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
-			wantErr:  "timed out waiting for token callback: context canceled",
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\"",
+				"\"level\"=6 \"msg\"=\"Pinniped: Waiting for device authorization.\"",
+				"\"level\"=6 \"msg\"=\"Pinniped: Waiting for device authorization.\"",
+			},
+			wantToken: &testToken,
 		},
 		{
-			name: "callback returns error",
+			name:     "upstream identity provider chooser fails",
+			issuer:   deviceServer.URL,
+			clientID: "test-client-id",
 			opt: func(t *testing.T) Option {
 				return func(h *handlerState) error {
-					require.NoError(t, WithClient(newClientForServer(successServer))(h))
-					h.openURL = func(_ string) error {
-						go func() {
-							h.callbacks <- callbackResult{err: fmt.Errorf("some callback error")}
-						}()
-						return nil
-					}
-					return nil
+					require.NoError(t, WithClient(newClientForServer(deviceServer))(h))
+					return WithUpstreamIdentityProviderChooser(func(context.Context, []idpdiscoveryv1alpha1.PinnipedIDP) ([]Option, error) {
+						return nil, errors.New("some chooser error")
+					})(h)
 				}
 			},
-			issuer:   successServer.URL,
-			wantLogs: []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
-			wantErr:  "error handling callback: some callback error",
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing upstream identity provider discovery\"  \"endpoint\"=\"" + deviceServer.URL + "/idps\"",
+			},
+			wantErr: "some chooser error",
 		},
 		{
-			name:     "callback returns success",
+			name:     "upstream identity provider chooser chooses CLI-based prompts with the device authorization grant",
+			issuer:   deviceServer.URL,
 			clientID: "test-client-id",
 			opt: func(t *testing.T) Option {
 				return func(h *handlerState) error {
-					h.generateState = func() (state.State, error) { return "test-state", nil }
-					h.generatePKCE = func() (pkce.Code, error) { return "test-pkce", nil }
-					h.generateNonce = func() (nonce.Nonce, error) { return "test-nonce", nil }
-
-					cache := &mockSessionCache{t: t, getReturnsToken: nil}
-					cacheKey := SessionCacheKey{
-						Issuer:      successServer.URL,
-						ClientID:    "test-client-id",
-						Scopes:      []string{"test-scope"},
-						RedirectURI: "http://localhost:0/callback",
-					}
-					t.Cleanup(func() {
-						require.Equal(t, []SessionCacheKey{cacheKey}, cache.sawGetKeys)
-						require.Equal(t, []SessionCacheKey{cacheKey}, cache.sawPutKeys)
-						require.Equal(t, []*oidctypes.Token{&testToken}, cache.sawPutTokens)
-					})
-					require.NoError(t, WithSessionCache(cache)(h))
-
-					client := newClientForServer(successServer)
-					client.Timeout = 10 * time.Second
-					require.NoError(t, WithClient(client)(h))
-
-					h.openURL = func(actualURL string) error {
-						parsedActualURL, err := url.Parse(actualURL)
-						require.NoError(t, err)
-						actualParams := parsedActualURL.Query()
-
-						require.Contains(t, actualParams.Get("redirect_uri"), "http://127.0.0.1:")
-						actualParams.Del("redirect_uri")
-
-						require.Equal(t, url.Values{
-							// This is the PKCE challenge which is calculated as base64(sha256("test-pkce")). For example:
-							// $ echo -n test-pkce | shasum -a 256 | cut -d" " -f1 | xxd -r -p | base64 | cut -d"=" -f1
-							// VVaezYqum7reIhoavCHD1n2d+piN3r/mywoYj7fCR7g
-							"code_challenge":        []string{"VVaezYqum7reIhoavCHD1n2d-piN3r_mywoYj7fCR7g"},
-							"code_challenge_method": []string{"S256"},
-							"response_type":         []string{"code"},
-							"scope":                 []string{"test-scope"},
-							"nonce":                 []string{"test-nonce"},
-							"state":                 []string{"test-state"},
-							"access_type":           []string{"offline"},
-							"client_id":             []string{"test-client-id"},
-						}, actualParams)
-
-						parsedActualURL.RawQuery = ""
-						require.Equal(t, successServer.URL+"/authorize", parsedActualURL.String())
-
-						go func() {
-							h.callbacks <- callbackResult{token: &testToken}
-						}()
-						return nil
-					}
-					return nil
+					require.NoError(t, WithDeviceAuthorizationGrant()(h))
+					require.NoError(t, WithClient(newClientForServer(deviceServer))(h))
+					return WithUpstreamIdentityProviderChooser(func(context.Context, []idpdiscoveryv1alpha1.PinnipedIDP) ([]Option, error) {
+						return []Option{WithUpstreamIdentityProvider("some-ldap-idp", "ldap"), WithCLISendingCredentials()}, nil
+					})(h)
 				}
 			},
-			issuer:    successServer.URL,
-			wantLogs:  []string{"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\""},
-			wantToken: &testToken,
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + deviceServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing upstream identity provider discovery\"  \"endpoint\"=\"" + deviceServer.URL + "/idps\"",
+			},
+			wantErr: "cannot use CLI-based prompts for credentials with the device authorization grant",
 		},
 		{
 			name:     "callback returns success with request_mode=form_post",
@@ -1385,6 +2041,117 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 			},
 			wantToken: &testToken,
 		},
+		{
+			name:     "successful ldap login with the username and password of a non-interactive login",
+			clientID: "test-client-id",
+			opt: func(t *testing.T) Option {
+				return func(h *handlerState) error {
+					fakeAuthCode := "test-authcode-value"
+
+					h.getProvider = func(_ *oauth2.Config, _ *oidc.Provider, _ *http.Client) provider.UpstreamOIDCIdentityProviderI {
+						mock := mockUpstream(t)
+						mock.EXPECT().
+							ExchangeAuthcodeAndValidateTokens(
+								gomock.Any(), fakeAuthCode, pkce.Code("test-pkce"), nonce.Nonce("test-nonce"), "http://127.0.0.1:0/callback").
+							Return(&testToken, nil)
+						return mock
+					}
+
+					h.generateState = func() (state.State, error) { return "test-state", nil }
+					h.generatePKCE = func() (pkce.Code, error) { return "test-pkce", nil }
+					h.generateNonce = func() (nonce.Nonce, error) { return "test-nonce", nil }
+					h.getEnv = func(key string) string {
+						switch key {
+						case "PINNIPED_USERNAME":
+							return "ignored-upstream-username"
+						case "PINNIPED_PASSWORD":
+							return "ignored-upstream-password"
+						default:
+							return "" // all other env vars are treated as if they are unset
+						}
+					}
+					h.promptForValue = func(_ context.Context, promptLabel string) (string, error) {
+						require.FailNow(t, fmt.Sprintf("saw unexpected prompt from the CLI: %q", promptLabel))
+						return "", nil
+					}
+					h.promptForSecret = func(promptLabel string) (string, error) {
+						require.FailNow(t, fmt.Sprintf("saw unexpected prompt from the CLI: %q", promptLabel))
+						return "", nil
+					}
+
+					cache := &mockSessionCache{t: t, getReturnsToken: nil}
+					cacheKey := SessionCacheKey{
+						Issuer:      successServer.URL,
+						ClientID:    "test-client-id",
+						Scopes:      []string{"test-scope"},
+						RedirectURI: "http://localhost:0/callback",
+					}
+					t.Cleanup(func() {
+						require.Equal(t, []SessionCacheKey{cacheKey}, cache.sawGetKeys)
+						require.Equal(t, []SessionCacheKey{cacheKey}, cache.sawPutKeys)
+						require.Equal(t, []*oidctypes.Token{&testToken}, cache.sawPutTokens)
+					})
+					require.NoError(t, WithSessionCache(cache)(h))
+					require.NoError(t, WithCLISendingCredentials()(h))
+					require.NoError(t, WithCLICredentials("some-upstream-username", "some-upstream-password")(h))
+					require.NoError(t, WithUpstreamIdentityProvider("some-upstream-name", "ldap")(h))
+
+					discoveryRequestWasMade := false
+					authorizeRequestWasMade := false
+					t.Cleanup(func() {
+						require.True(t, discoveryRequestWasMade, "should have made an discovery request")
+						require.True(t, authorizeRequestWasMade, "should have made an authorize request")
+					})
+
+					client := newClientForServer(successServer)
+					client.Transport = roundtripper.Func(func(req *http.Request) (*http.Response, error) {
+						switch req.URL.Scheme + "://" + req.URL.Host + req.URL.Path {
+						case "https://" + successServer.Listener.Addr().String() + "/.well-known/openid-configuration":
+							discoveryRequestWasMade = true
+							return defaultDiscoveryResponse(req)
+						case "https://" + successServer.Listener.Addr().String() + "/authorize":
+							authorizeRequestWasMade = true
+							require.Equal(t, "some-upstream-username", req.Header.Get("Pinniped-Username"))
+							require.Equal(t, "some-upstream-password", req.Header.Get("Pinniped-Password"))
+							require.Equal(t, url.Values{
+								// This is the PKCE challenge which is calculated as base64(sha256("test-pkce")). For example:
+								// $ echo -n test-pkce | shasum -a 256 | cut -d" " -f1 | xxd -r -p | base64 | cut -d"=" -f1
+								// VVaezYqum7reIhoavCHD1n2d+piN3r/mywoYj7fCR7g
+								"code_challenge":        []string{"VVaezYqum7reIhoavCHD1n2d-piN3r_mywoYj7fCR7g"},
+								"code_challenge_method": []string{"S256"},
+								"response_type":         []string{"code"},
+								"scope":                 []string{"test-scope"},
+								"nonce":                 []string{"test-nonce"},
+								"state":                 []string{"test-state"},
+								"access_type":           []string{"offline"},
+								"client_id":             []string{"test-client-id"},
+								"redirect_uri":          []string{"http://127.0.0.1:0/callback"},
+								"pinniped_idp_name":     []string{"some-upstream-name"},
+								"pinniped_idp_type":     []string{"ldap"},
+							}, req.URL.Query())
+							return &http.Response{
+								StatusCode: http.StatusFound,
+								Header: http.Header{"Location": []string{
+									fmt.Sprintf("http://127.0.0.1:0/callback?code=%s&state=test-state", fakeAuthCode),
+								}},
+							}, nil
+						default:
+							// Note that "/token" requests should not be made. They are mocked by mocking calls to ExchangeAuthcodeAndValidateTokens().
+							require.FailNow(t, fmt.Sprintf("saw unexpected http call from the CLI: %s", req.URL.String()))
+							return nil, nil
+						}
+					})
+					require.NoError(t, WithClient(client)(h))
+					return nil
+				}
+			},
+			issuer: successServer.URL,
+			wantLogs: []string{
+				"\"level\"=4 \"msg\"=\"Pinniped: Performing OIDC discovery\"  \"issuer\"=\"" + successServer.URL + "\"",
+				"\"level\"=4 \"msg\"=\"Pinniped: Using the username and password which were given to the login\"  \"username\"=\"some-upstream-username\"",
+			},
+			wantToken: &testToken,
+		},
 		{
 			name:     "successful ldap login with env vars for username and password, http.StatusSeeOther redirect",
 			clientID: "test-client-id",
@@ -1898,12 +2665,18 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 				WithListenPort(0),
 				WithScopes([]string{"test-scope"}),
 				WithSkipBrowserOpen(),
+				WithSkipHeadlessDetection(),
 				tt.opt(t),
 				WithLogger(testLogger.Logger),
 			)
 			testLogger.Expect(tt.wantLogs)
 			if tt.wantErr != "" {
 				require.EqualError(t, err, tt.wantErr)
+				if tt.wantErrIs != nil {
+					require.ErrorIs(t, err, tt.wantErrIs)
+				} else {
+					require.NotErrorIs(t, err, ErrRefreshRejected)
+				}
 				require.Nil(t, tok)
 				return
 			}
@@ -1935,6 +2708,95 @@ func TestLogin(t *testing.T) { //nolint:gocyclo
 	}
 }
 
+func TestIsHeadless(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		goos         string
+		wantHeadless bool
+	}{
+		{name: "linux with a display", goos: "linux", env: map[string]string{"DISPLAY": ":0"}},
+		{name: "linux with a wayland display", goos: "linux", env: map[string]string{"WAYLAND_DISPLAY": "wayland-0"}},
+		{name: "linux without a display", goos: "linux", wantHeadless: true},
+		{name: "linux without a display but with a browser", goos: "linux", env: map[string]string{"BROWSER": "some-browser"}},
+		{name: "ssh session", goos: "linux", env: map[string]string{"SSH_CONNECTION": "1.2.3.4 1234 5.6.7.8 22"}, wantHeadless: true},
+		{name: "ssh session with X11 forwarding", goos: "linux", env: map[string]string{"SSH_CLIENT": "1.2.3.4 1234 22", "DISPLAY": "localhost:10.0"}},
+		{name: "macOS", goos: "darwin"},
+		{name: "ssh session to macOS", goos: "darwin", env: map[string]string{"SSH_TTY": "/dev/ttys001"}, wantHeadless: true},
+		{name: "windows", goos: "windows"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			getEnv := func(key string) string { return tt.env[key] }
+			require.Equal(t, tt.wantHeadless, isHeadless(getEnv, tt.goos))
+		})
+	}
+}
+
+func TestWithBrowserCommand(t *testing.T) {
+	const authorizeURL = "https://issuer.example.com/authorize?client_id=test-client-id&state=test-state"
+
+	tests := []struct {
+		name     string
+		command  func(outputPath string) []string
+		wantErr  string
+		wantArgs string
+	}{
+		{
+			name:    "empty command",
+			command: func(string) []string { return nil },
+			wantErr: "browser command must not be empty",
+		},
+		{
+			name:    "empty command name",
+			command: func(string) []string { return []string{"", "some-arg"} },
+			wantErr: "browser command must not be empty",
+		},
+		{
+			name: "URL is appended",
+			command: func(outputPath string) []string {
+				return []string{"sh", "-c", `echo "$@" > "$0"`, outputPath, "--some-arg"}
+			},
+			wantArgs: "--some-arg " + authorizeURL,
+		},
+		{
+			name: "URL is substituted",
+			command: func(outputPath string) []string {
+				return []string{"sh", "-c", `echo "$@" > "$0"`, outputPath, "--url", "%s", "--some-arg"}
+			},
+			wantArgs: "--url " + authorizeURL + " --some-arg",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(testutil.TempDir(t), "args")
+			h := &handlerState{isHeadless: func() bool { return true }}
+
+			err := WithBrowserCommand(tt.command(outputPath))(h)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.False(t, h.isHeadless())
+
+			require.NoError(t, h.openURL(authorizeURL))
+			require.Eventually(t, func() bool {
+				got, err := os.ReadFile(outputPath)
+				return err == nil && strings.TrimSpace(string(got)) == tt.wantArgs
+			}, 10*time.Second, 10*time.Millisecond)
+		})
+	}
+
+	t.Run("command cannot be started", func(t *testing.T) {
+		h := &handlerState{}
+		require.NoError(t, WithBrowserCommand([]string{"/does/not/exist"})(h))
+		require.EqualError(t, h.openURL(authorizeURL), "could not start browser command: fork/exec /does/not/exist: no such file or directory")
+	})
+}
+
 func TestHandlePasteCallback(t *testing.T) {
 	const testRedirectURI = "http://127.0.0.1:12324/callback"
 
@@ -0,0 +1,159 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidcclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-logr/logr"
+	"github.com/pkg/browser"
+
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/plog"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+// WithEndSession causes Logout to also end the user's browser session at the issuer using OpenID Connect RP-Initiated
+// Logout, when the issuer advertises an end_session_endpoint in its OIDC discovery. The end session URL is opened in the
+// user's browser, unless WithSkipBrowserOpen is also specified, and is always printed. The Pinniped Supervisor does not
+// advertise an end_session_endpoint, so this option has no effect for its issuers.
+// See https://openid.net/specs/openid-connect-rpinitiated-1_0.html.
+func WithEndSession() Option {
+	return func(h *handlerState) error {
+		h.endSession = true
+		return nil
+	}
+}
+
+// Logout ends a session which was started by Login. It revokes the session's refresh token (or its access token, when
+// there is no refresh token) at the issuer's revocation_endpoint (RFC 7009), so that the session can no longer be
+// refreshed. Issuers which do not advertise a revocation_endpoint are skipped. Logout does not remove the session from
+// any SessionCache, which is left to the caller.
+func Logout(issuer string, clientID string, token *oidctypes.Token, opts ...Option) error {
+	h := handlerState{
+		issuer:     issuer,
+		clientID:   clientID,
+		ctx:        context.Background(),
+		logger:     logr.Discard(), // discard logs unless a logger is specified
+		httpClient: phttp.Default(nil),
+		openURL:    browser.OpenURL,
+	}
+	for _, opt := range opts {
+		if err := opt(&h); err != nil {
+			return err
+		}
+	}
+
+	// Copy the configured HTTP client to set a request timeout (the Go default client has no timeout configured).
+	httpClientWithTimeout := *h.httpClient
+	httpClientWithTimeout.Timeout = httpRequestTimeout
+	h.httpClient = &httpClientWithTimeout
+
+	ctx, cancel := context.WithTimeout(h.ctx, httpRequestTimeout)
+	defer cancel()
+	h.ctx = coreosoidc.ClientContext(ctx, h.httpClient)
+
+	if err := h.initOIDCDiscovery(); err != nil {
+		return err
+	}
+	var discoveryClaims struct {
+		RevocationEndpoint string `json:"revocation_endpoint"`
+		EndSessionEndpoint string `json:"end_session_endpoint"`
+	}
+	if err := h.provider.Claims(&discoveryClaims); err != nil {
+		return fmt.Errorf("could not decode revocation_endpoint or end_session_endpoint in OIDC discovery from %q: %w", h.issuer, err)
+	}
+
+	switch {
+	case discoveryClaims.RevocationEndpoint == "":
+		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Issuer does not support token revocation, skipping", "issuer", h.issuer)
+	case token.RefreshToken != nil && token.RefreshToken.Token != "":
+		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Revoking refresh token", "issuer", h.issuer)
+		if err := h.revokeToken(discoveryClaims.RevocationEndpoint, token.RefreshToken.Token, "refresh_token"); err != nil {
+			return fmt.Errorf("could not revoke refresh token: %w", err)
+		}
+	case token.AccessToken != nil && token.AccessToken.Token != "":
+		h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Revoking access token", "issuer", h.issuer)
+		if err := h.revokeToken(discoveryClaims.RevocationEndpoint, token.AccessToken.Token, "access_token"); err != nil {
+			return fmt.Errorf("could not revoke access token: %w", err)
+		}
+	}
+
+	if h.endSession {
+		if discoveryClaims.EndSessionEndpoint == "" {
+			h.logger.V(plog.KlogLevelDebug).Info("Pinniped: Issuer does not support RP-initiated logout, skipping", "issuer", h.issuer)
+			return nil
+		}
+		return h.openEndSessionURL(discoveryClaims.EndSessionEndpoint, token)
+	}
+	return nil
+}
+
+// revokeToken makes an RFC 7009 token revocation request. A successful response has no body.
+func (h *handlerState) revokeToken(endpoint string, token string, tokenTypeHint string) error {
+	if err := validateURLUsesHTTPS(endpoint, "discovered revocation URL from issuer"); err != nil {
+		return err
+	}
+
+	params := url.Values{
+		"token":           {token},
+		"token_type_hint": {tokenTypeHint},
+		"client_id":       {h.clientID},
+	}
+	req, err := http.NewRequestWithContext(h.ctx, http.MethodPost, endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("could not build request: %w", err)
+	}
+	req.Header.Set("content-type", "application/x-www-form-urlencoded")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var oauthErr oauthErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&oauthErr); err != nil || oauthErr.ErrorCode == "" {
+		return fmt.Errorf("unexpected HTTP response status %d", resp.StatusCode)
+	}
+	if oauthErr.ErrorDescription != "" {
+		return fmt.Errorf("revocation failed with code %q: %s", oauthErr.ErrorCode, oauthErr.ErrorDescription)
+	}
+	return fmt.Errorf("revocation failed with code %q", oauthErr.ErrorCode)
+}
+
+// openEndSessionURL sends the user's browser to the issuer's end_session_endpoint. The ID token is passed as a hint
+// of which session to end, even when it has expired, as allowed by the spec.
+func (h *handlerState) openEndSessionURL(endpoint string, token *oidctypes.Token) error {
+	if err := validateURLUsesHTTPS(endpoint, "discovered end session URL from issuer"); err != nil {
+		return err
+	}
+	endSessionURL, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("could not parse end session URL from issuer: %w", err)
+	}
+	params := endSessionURL.Query()
+	params.Set("client_id", h.clientID)
+	if token.IDToken != nil && token.IDToken.Token != "" {
+		params.Set("id_token_hint", token.IDToken.Token)
+	}
+	endSessionURL.RawQuery = params.Encode()
+
+	// Open the end session URL in the users browser, logging but otherwise ignoring any error.
+	if err := h.openURL(endSessionURL.String()); err != nil {
+		h.logger.V(plog.KlogLevelDebug).Error(err, "could not open browser")
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Log out of the issuer by visiting this link:\n\n    %s\n\n", endSessionURL.String())
+	return nil
+}
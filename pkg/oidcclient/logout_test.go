@@ -0,0 +1,195 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package oidcclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/testutil/tlsserver"
+	"go.pinniped.dev/pkg/oidcclient/oidctypes"
+)
+
+func TestLogout(t *testing.T) {
+	// Start a test server which advertises a revocation_endpoint and an end_session_endpoint, and records the
+	// revocation requests which it receives.
+	var revocationRequests []url.Values
+	logoutMux := http.NewServeMux()
+	logoutServer := tlsserver.TLSTestServer(t, logoutMux, nil)
+	logoutMux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(&struct {
+			Issuer             string `json:"issuer"`
+			AuthURL            string `json:"authorization_endpoint"`
+			TokenURL           string `json:"token_endpoint"`
+			JWKSURL            string `json:"jwks_uri"`
+			RevocationEndpoint string `json:"revocation_endpoint"`
+			EndSessionEndpoint string `json:"end_session_endpoint"`
+		}{
+			Issuer:             logoutServer.URL,
+			AuthURL:            logoutServer.URL + "/authorize",
+			TokenURL:           logoutServer.URL + "/token",
+			JWKSURL:            logoutServer.URL + "/keys",
+			RevocationEndpoint: logoutServer.URL + "/revoke",
+			EndSessionEndpoint: logoutServer.URL + "/logout?some=param",
+		})
+	})
+	logoutMux.HandleFunc("/revoke", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		revocationRequests = append(revocationRequests, r.PostForm)
+		switch r.PostForm.Get("client_id") {
+		case "test-client-id-rejected":
+			w.Header().Set("content-type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"some client error"}`))
+		case "test-client-id-broken":
+			http.Error(w, "some server error", http.StatusInternalServerError)
+		}
+	})
+
+	// Start a test server which supports neither revocation nor RP-initiated logout.
+	noLogoutMux := http.NewServeMux()
+	noLogoutServer := tlsserver.TLSTestServer(t, noLogoutMux, nil)
+	noLogoutMux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(&struct {
+			Issuer   string `json:"issuer"`
+			AuthURL  string `json:"authorization_endpoint"`
+			TokenURL string `json:"token_endpoint"`
+			JWKSURL  string `json:"jwks_uri"`
+		}{
+			Issuer:   noLogoutServer.URL,
+			AuthURL:  noLogoutServer.URL + "/authorize",
+			TokenURL: noLogoutServer.URL + "/token",
+			JWKSURL:  noLogoutServer.URL + "/keys",
+		})
+	})
+
+	token := &oidctypes.Token{
+		AccessToken:  &oidctypes.AccessToken{Token: "test-access-token"},
+		RefreshToken: &oidctypes.RefreshToken{Token: "test-refresh-token"},
+		IDToken:      &oidctypes.IDToken{Token: "test-id-token"},
+	}
+
+	tests := []struct {
+		name                   string
+		issuer                 string
+		clientID               string
+		token                  *oidctypes.Token
+		opts                   []Option
+		wantErr                string
+		wantRevocationRequests []url.Values
+		wantOpenedURLs         []string
+	}{
+		{
+			name:     "discovery failure",
+			issuer:   "https://127.0.0.1:1",
+			clientID: "test-client-id",
+			token:    token,
+			wantErr:  `could not perform OIDC discovery for "https://127.0.0.1:1": Get "https://127.0.0.1:1/.well-known/openid-configuration": dial tcp 127.0.0.1:1: connect: connection refused`,
+		},
+		{
+			name:     "revokes the refresh token",
+			issuer:   logoutServer.URL,
+			clientID: "test-client-id",
+			token:    token,
+			wantRevocationRequests: []url.Values{
+				{"token": {"test-refresh-token"}, "token_type_hint": {"refresh_token"}, "client_id": {"test-client-id"}},
+			},
+		},
+		{
+			name:     "revokes the access token when there is no refresh token",
+			issuer:   logoutServer.URL,
+			clientID: "test-client-id",
+			token:    &oidctypes.Token{AccessToken: &oidctypes.AccessToken{Token: "test-access-token"}},
+			wantRevocationRequests: []url.Values{
+				{"token": {"test-access-token"}, "token_type_hint": {"access_token"}, "client_id": {"test-client-id"}},
+			},
+		},
+		{
+			name:     "revocation rejected by issuer",
+			issuer:   logoutServer.URL,
+			clientID: "test-client-id-rejected",
+			token:    token,
+			wantErr:  `could not revoke refresh token: revocation failed with code "invalid_client": some client error`,
+			wantRevocationRequests: []url.Values{
+				{"token": {"test-refresh-token"}, "token_type_hint": {"refresh_token"}, "client_id": {"test-client-id-rejected"}},
+			},
+		},
+		{
+			name:     "revocation fails with unexpected response",
+			issuer:   logoutServer.URL,
+			clientID: "test-client-id-broken",
+			token:    token,
+			wantErr:  `could not revoke refresh token: unexpected HTTP response status 500`,
+			wantRevocationRequests: []url.Values{
+				{"token": {"test-refresh-token"}, "token_type_hint": {"refresh_token"}, "client_id": {"test-client-id-broken"}},
+			},
+		},
+		{
+			name:     "ends the session at the issuer",
+			issuer:   logoutServer.URL,
+			clientID: "test-client-id",
+			token:    token,
+			opts:     []Option{WithEndSession()},
+			wantRevocationRequests: []url.Values{
+				{"token": {"test-refresh-token"}, "token_type_hint": {"refresh_token"}, "client_id": {"test-client-id"}},
+			},
+			wantOpenedURLs: []string{logoutServer.URL + "/logout?client_id=test-client-id&id_token_hint=test-id-token&some=param"},
+		},
+		{
+			name:           "ends the session at the issuer without an ID token",
+			issuer:         logoutServer.URL,
+			clientID:       "test-client-id",
+			token:          &oidctypes.Token{},
+			opts:           []Option{WithEndSession()},
+			wantOpenedURLs: []string{logoutServer.URL + "/logout?client_id=test-client-id&some=param"},
+		},
+		{
+			name:     "issuer supports neither revocation nor RP-initiated logout",
+			issuer:   noLogoutServer.URL,
+			clientID: "test-client-id",
+			token:    token,
+			opts:     []Option{WithEndSession()},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			revocationRequests = nil
+			var openedURLs []string
+			opts := append([]Option{
+				WithClient(newClientForServer(logoutServer)),
+				WithBrowserOpen(func(url string) error {
+					openedURLs = append(openedURLs, url)
+					return fmt.Errorf("some browser error")
+				}),
+			}, tt.opts...)
+			if tt.issuer == noLogoutServer.URL {
+				opts[0] = WithClient(newClientForServer(noLogoutServer))
+			}
+
+			err := Logout(tt.issuer, tt.clientID, tt.token, opts...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantRevocationRequests, revocationRequests)
+			require.Equal(t, tt.wantOpenedURLs, openedURLs)
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 package integration
 
@@ -348,7 +348,7 @@ func runPinnipedLoginOIDC(
 	// assert that it contains the success message.
 	t.Logf("verifying success page")
 	browsertest.WaitForVisibleElements(t, page, "pre")
-	msg, err := page.First("pre").Text()
+	msg, err := page.Text("pre")
 	require.NoError(t, err)
 	require.Equal(t, "you have been logged in and may now close this tab", msg)
 
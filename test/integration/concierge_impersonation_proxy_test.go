@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package integration
@@ -614,6 +614,17 @@ func TestImpersonationProxy(t *testing.T) { //nolint:gocyclo // yeah, it's compl
 				whoAmI,
 			)
 
+			// check that the original user can also be reported on its own when asked for
+			whoAmI, err = nestedImpersonationClientAsSA.PinnipedConcierge.IdentityV1alpha1().WhoAmIRequests().
+				Create(ctx, &identityv1alpha1.WhoAmIRequest{
+					Spec: identityv1alpha1.WhoAmIRequestSpec{IncludeImpersonation: true},
+				}, metav1.CreateOptions{})
+			require.NoError(t, err)
+			require.Equal(t, &identityv1alpha1.UserInfo{
+				Username: env.TestUser.ExpectedUsername,
+				Groups:   expectedGroups,
+			}, whoAmI.Status.OriginalUser)
+
 			_, err = newImpersonationProxyClient(t, impersonationProxyURL, impersonationProxyCACertPEM,
 				&rest.ImpersonationConfig{
 					UserName: "system:serviceaccount:kube-system:generic-garbage-collector",
@@ -1040,7 +1051,7 @@ func TestImpersonationProxy(t *testing.T) { //nolint:gocyclo // yeah, it's compl
 			)
 			require.NoError(t, err)
 
-			if testutil.KubeServerSupportsCertificatesV1API(t, adminClient.Discovery()) {
+			if env.HasKubeFeature(testlib.KubeFeatureCertificatesV1API) {
 				saCSR, err := impersonationProxySAClient.Kubernetes.CertificatesV1().CertificateSigningRequests().Get(ctx, csrName, metav1.GetOptions{})
 				require.NoError(t, err)
 				err = adminClient.CertificatesV1().CertificateSigningRequests().Delete(ctx, csrName, metav1.DeleteOptions{})
@@ -1179,16 +1190,11 @@ func TestImpersonationProxy(t *testing.T) { //nolint:gocyclo // yeah, it's compl
 				"labelSelector":   {fmt.Sprintf("%s=%s", wantConfigMapLabelKey, wantConfigMapLabelValue)},
 				"resourceVersion": {"0"},
 			}.Encode()
+			impersonationProxyDialer := testlib.NewImpersonationProxyDialer(ctx, t, impersonationProxyURL)
 			dialer := websocket.Dialer{
 				TLSClientConfig: tlsConfig,
-			}
-			if !clusterSupportsLoadBalancers {
-				dialer.Proxy = func(req *http.Request) (*url.URL, error) {
-					proxyURL, err := url.Parse(env.Proxy)
-					require.NoError(t, err)
-					t.Logf("passing request for %s through proxy %s", testlib.RedactURLParams(req.URL), proxyURL.String())
-					return proxyURL, nil
-				}
+				Proxy:           impersonationProxyDialer.Proxy(),
+				NetDialContext:  impersonationProxyDialer.DialContext(),
 			}
 			var (
 				resp *http.Response
@@ -1266,14 +1272,7 @@ func TestImpersonationProxy(t *testing.T) { //nolint:gocyclo // yeah, it's compl
 			httpTransport := http.Transport{
 				TLSClientConfig: tlsConfig,
 			}
-			if !clusterSupportsLoadBalancers {
-				httpTransport.Proxy = func(req *http.Request) (*url.URL, error) {
-					proxyURL, err := url.Parse(env.Proxy)
-					require.NoError(t, err)
-					t.Logf("passing request for %s through proxy %s", testlib.RedactURLParams(req.URL), proxyURL.String())
-					return proxyURL, nil
-				}
-			}
+			testlib.NewImpersonationProxyDialer(ctx, t, impersonationProxyURL).ConfigureTransport(&httpTransport)
 			err = http2.ConfigureTransport(&httpTransport)
 			require.NoError(t, err)
 
@@ -1743,9 +1742,6 @@ func TestImpersonationProxy(t *testing.T) { //nolint:gocyclo // yeah, it's compl
 	})
 
 	t.Run("running impersonation proxy with ClusterIP service", func(t *testing.T) {
-		if env.Proxy == "" {
-			t.Skip("Skipping ClusterIP test because squid proxy is not present")
-		}
 		clusterIPServiceURL := fmt.Sprintf("%s.%s.svc.cluster.local", impersonationProxyClusterIPName(env), env.ConciergeNamespace)
 		updateCredentialIssuer(ctx, t, env, adminConciergeClient, conciergev1alpha.CredentialIssuerSpec{
 			ImpersonationProxy: &conciergev1alpha.ImpersonationProxySpec{
@@ -1764,10 +1760,11 @@ func TestImpersonationProxy(t *testing.T) { //nolint:gocyclo // yeah, it's compl
 		}, 30*time.Second, 500*time.Millisecond)
 		newImpersonationProxyURL, newImpersonationProxyCACertPEM := performImpersonatorDiscovery(ctx, t, env, adminClient, adminConciergeClient, refreshCredential)
 
-		anonymousClient := newAnonymousImpersonationProxyClientWithProxy(t, newImpersonationProxyURL, newImpersonationProxyCACertPEM, nil).PinnipedConcierge
+		// the ClusterIP service cannot be reached directly, so this goes through the squid proxy or a port-forward
+		anonymousClient := newAnonymousImpersonationProxyClient(t, newImpersonationProxyURL, newImpersonationProxyCACertPEM, nil).PinnipedConcierge
 		refreshedCredentials := refreshCredentialHelper(t, anonymousClient)
 
-		client := newImpersonationProxyClientWithCredentialsAndProxy(t, refreshedCredentials, newImpersonationProxyURL, newImpersonationProxyCACertPEM, nil).Kubernetes
+		client := newImpersonationProxyClientWithCredentials(t, refreshedCredentials, newImpersonationProxyURL, newImpersonationProxyCACertPEM, nil).Kubernetes
 
 		// everything should work properly through the cluster ip service
 		t.Run(
@@ -2309,16 +2306,11 @@ func newImpersonationProxyClientWithCredentials(t *testing.T, credentials *login
 func newImpersonationProxyConfigWithCredentials(t *testing.T, credentials *loginv1alpha1.ClusterCredential, impersonationProxyURL string, impersonationProxyCACertPEM []byte, nestedImpersonationConfig *rest.ImpersonationConfig) *rest.Config {
 	t.Helper()
 
-	env := testlib.IntegrationEnv(t)
-	clusterSupportsLoadBalancers := env.HasCapability(testlib.HasExternalLoadBalancerProvider)
-
 	kubeconfig := impersonationProxyRestConfig(credentials, impersonationProxyURL, impersonationProxyCACertPEM, nestedImpersonationConfig)
-	if !clusterSupportsLoadBalancers {
-		// Only if there is no possibility to send traffic through a load balancer, then send the traffic through the Squid proxy.
-		// Prefer to go through a load balancer because that's how the impersonator is intended to be used in the real world.
-		kubeconfig.Proxy = kubeconfigProxyFunc(t, env.Proxy)
-	}
-	return kubeconfig
+	// Prefer to go through a load balancer because that's how the impersonator is intended to be used in the real world.
+	// Only if there is no possibility to send traffic through a load balancer, then send the traffic through the Squid
+	// proxy, or through a port-forward when there is no Squid proxy.
+	return testlib.NewImpersonationProxyDialer(context.Background(), t, impersonationProxyURL).RestConfig(kubeconfig)
 }
 
 func newAnonymousImpersonationProxyClient(t *testing.T, impersonationProxyURL string, impersonationProxyCACertPEM []byte, nestedImpersonationConfig *rest.ImpersonationConfig) *kubeclient.Client {
@@ -2328,29 +2320,6 @@ func newAnonymousImpersonationProxyClient(t *testing.T, impersonationProxyURL st
 	return newImpersonationProxyClientWithCredentials(t, emptyCredentials, impersonationProxyURL, impersonationProxyCACertPEM, nestedImpersonationConfig)
 }
 
-func newImpersonationProxyClientWithCredentialsAndProxy(t *testing.T, credentials *loginv1alpha1.ClusterCredential, impersonationProxyURL string, impersonationProxyCACertPEM []byte, nestedImpersonationConfig *rest.ImpersonationConfig) *kubeclient.Client {
-	t.Helper()
-
-	env := testlib.IntegrationEnv(t)
-
-	kubeconfig := impersonationProxyRestConfig(credentials, impersonationProxyURL, impersonationProxyCACertPEM, nestedImpersonationConfig)
-	kubeconfig.Proxy = kubeconfigProxyFunc(t, env.Proxy)
-	return testlib.NewKubeclient(t, kubeconfig)
-}
-
-// this uses a proxy in all cases, the other will only use it if you don't have load balancer capabilities.
-func newAnonymousImpersonationProxyClientWithProxy(t *testing.T, impersonationProxyURL string, impersonationProxyCACertPEM []byte, nestedImpersonationConfig *rest.ImpersonationConfig) *kubeclient.Client {
-	t.Helper()
-	env := testlib.IntegrationEnv(t)
-
-	emptyCredentials := &loginv1alpha1.ClusterCredential{}
-	kubeconfig := impersonationProxyRestConfig(emptyCredentials, impersonationProxyURL, impersonationProxyCACertPEM, nestedImpersonationConfig)
-
-	kubeconfig.Proxy = kubeconfigProxyFunc(t, env.Proxy)
-
-	return testlib.NewKubeclient(t, kubeconfig)
-}
-
 func impersonationProxyViaSquidKubeClientWithoutCredential(t *testing.T, proxyServiceEndpoint string) kubernetes.Interface {
 	t.Helper()
 
@@ -2470,7 +2439,7 @@ func getUIDAndExtraViaCSR(ctx context.Context, t *testing.T, uid string, client
 	outUID := uid // in the future this may not be empty on some clusters
 	extrasAsStrings := map[string][]string{}
 
-	if testutil.KubeServerSupportsCertificatesV1API(t, client.Discovery()) {
+	if testlib.IntegrationEnv(t).HasKubeFeature(testlib.KubeFeatureCertificatesV1API) {
 		csReq, err := client.CertificatesV1().CertificateSigningRequests().Get(ctx, csrName, metav1.GetOptions{})
 		require.NoError(t, err)
 
@@ -1,4 +1,4 @@
-// Copyright 2021-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2021-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package integration
@@ -16,7 +16,6 @@ import (
 
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/token/hmac"
-	"github.com/sclevine/agouti"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -229,7 +228,7 @@ func formpostRandomParams(t *testing.T) url.Values {
 }
 
 // formpostExpectTitle asserts that the page has the expected title.
-func formpostExpectTitle(t *testing.T, page *agouti.Page, expected string) {
+func formpostExpectTitle(t *testing.T, page browsertest.Browser, expected string) {
 	t.Helper()
 	actual, err := page.Title()
 	require.NoError(t, err)
@@ -237,9 +236,9 @@ func formpostExpectTitle(t *testing.T, page *agouti.Page, expected string) {
 }
 
 // formpostExpectTitle asserts that the page has the expected SVG/emoji favicon.
-func formpostExpectFavicon(t *testing.T, page *agouti.Page, expected string) {
+func formpostExpectFavicon(t *testing.T, page browsertest.Browser, expected string) {
 	t.Helper()
-	iconURL, err := page.First("#favicon").Attribute("href")
+	iconURL, err := page.Attribute("#favicon", "href")
 	require.NoError(t, err)
 	require.True(t, strings.HasPrefix(iconURL, "data:image/svg+xml,<svg"))
 
@@ -254,7 +253,7 @@ func formpostExpectFavicon(t *testing.T, page *agouti.Page, expected string) {
 
 // formpostInitiate navigates to the template server endpoint and expects the
 // loading animation to be shown.
-func formpostInitiate(t *testing.T, page *agouti.Page, url string) {
+func formpostInitiate(t *testing.T, page browsertest.Browser, url string) {
 	t.Helper()
 	require.NoError(t, page.Reset())
 	t.Logf("navigating to mock form_post template URL %s...", url)
@@ -267,11 +266,11 @@ func formpostInitiate(t *testing.T, page *agouti.Page, url string) {
 }
 
 // formpostExpectSuccessState asserts that the page is in the "success" state.
-func formpostExpectSuccessState(t *testing.T, page *agouti.Page) {
+func formpostExpectSuccessState(t *testing.T, page browsertest.Browser) {
 	t.Helper()
 	t.Logf("expecting to see success message become visible...")
 	browsertest.WaitForVisibleElements(t, page, "#success")
-	successDivText, err := page.First("#success").Text()
+	successDivText, err := page.Text("#success")
 	require.NoError(t, err)
 	require.Contains(t, successDivText, "Login succeeded")
 	require.Contains(t, successDivText, "You have successfully logged in. You may now close this tab.")
@@ -280,11 +279,11 @@ func formpostExpectSuccessState(t *testing.T, page *agouti.Page) {
 }
 
 // formpostExpectManualState asserts that the page is in the "manual" state and returns the auth code.
-func formpostExpectManualState(t *testing.T, page *agouti.Page) string {
+func formpostExpectManualState(t *testing.T, page browsertest.Browser) string {
 	t.Helper()
 	t.Logf("expecting to see manual message become visible...")
 	browsertest.WaitForVisibleElements(t, page, "#manual")
-	manualDivText, err := page.First("#manual").Text()
+	manualDivText, err := page.Text("#manual")
 	require.NoError(t, err)
 	require.Contains(t, manualDivText, "Finish your login")
 	require.Contains(t, manualDivText, "To finish logging in, paste this authorization code into your command-line session:")
@@ -295,16 +294,16 @@ func formpostExpectManualState(t *testing.T, page *agouti.Page) string {
 	// headless Chrome does not have a real clipboard we can check, so we rely on  checking a
 	// console.log() statement that happens at the same time.
 	t.Logf("clicking the 'copy' button and expecting the clipboard event to fire...")
-	require.NoError(t, page.First("#manual-copy-button").Click())
+	require.NoError(t, page.Click("#manual-copy-button"))
 
 	var authCode string
 	consoleLogPattern := regexp.MustCompile(`code (.+) to clipboard`)
 	testlib.RequireEventually(t, func(requireEventually *require.Assertions) {
-		logs, err := page.ReadNewLogs("browser")
+		logs, err := page.ConsoleLogs()
 		requireEventually.NoError(err)
 
 		for _, log := range logs {
-			if match := consoleLogPattern.FindStringSubmatch(log.Message); match != nil {
+			if match := consoleLogPattern.FindStringSubmatch(log); match != nil {
 				authCode = match[1]
 				return
 			}
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package integration
@@ -57,6 +57,16 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 	idpSupervisorGV := makeGV("idp", "supervisor")
 	configSupervisorGV := makeGV("config", "supervisor")
 	clientSecretSupervisorGV := makeGV("clientsecret", "supervisor")
+	sessionSupervisorGV := makeGV("session", "supervisor")
+
+	// The custom resources are served in both v1alpha1 and v1beta1, and discovery prefers v1beta1.
+	makeV1beta1GV := func(gv schema.GroupVersion) schema.GroupVersion {
+		return schema.GroupVersion{Group: gv.Group, Version: "v1beta1"}
+	}
+	authenticationConciergeV1beta1GV := makeV1beta1GV(authenticationConciergeGV)
+	configConciergeV1beta1GV := makeV1beta1GV(configConciergeGV)
+	idpSupervisorV1beta1GV := makeV1beta1GV(idpSupervisorGV)
+	configSupervisorV1beta1GV := makeV1beta1GV(configSupervisorGV)
 
 	tests := []struct {
 		group             metav1.APIGroup
@@ -140,18 +150,55 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 				},
 			},
 		},
+		{
+			group: metav1.APIGroup{
+				Name: sessionSupervisorGV.Group,
+				Versions: []metav1.GroupVersionForDiscovery{
+					{
+						GroupVersion: sessionSupervisorGV.String(),
+						Version:      sessionSupervisorGV.Version,
+					},
+				},
+				PreferredVersion: metav1.GroupVersionForDiscovery{
+					GroupVersion: sessionSupervisorGV.String(),
+					Version:      sessionSupervisorGV.Version,
+				},
+			},
+			resourceByVersion: map[string][]metav1.APIResource{
+				sessionSupervisorGV.String(): {
+					{
+						Name:       "downstreamsessions",
+						Kind:       "DownstreamSession",
+						Verbs:      []string{"delete", "deletecollection", "get", "list"},
+						Namespaced: true,
+						Categories: []string{"pinniped"},
+					},
+					{
+						Name:       "secondfactors",
+						Kind:       "SecondFactor",
+						Verbs:      []string{"delete", "deletecollection", "get", "list"},
+						Namespaced: true,
+						Categories: []string{"pinniped"},
+					},
+				},
+			},
+		},
 		{
 			group: metav1.APIGroup{
 				Name: configSupervisorGV.Group,
 				Versions: []metav1.GroupVersionForDiscovery{
+					{
+						GroupVersion: configSupervisorV1beta1GV.String(),
+						Version:      configSupervisorV1beta1GV.Version,
+					},
 					{
 						GroupVersion: configSupervisorGV.String(),
 						Version:      configSupervisorGV.Version,
 					},
 				},
 				PreferredVersion: metav1.GroupVersionForDiscovery{
-					GroupVersion: configSupervisorGV.String(),
-					Version:      configSupervisorGV.Version,
+					GroupVersion: configSupervisorV1beta1GV.String(),
+					Version:      configSupervisorV1beta1GV.Version,
 				},
 			},
 			resourceByVersion: map[string][]metav1.APIResource{
@@ -184,6 +231,50 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 						Kind:       "OIDCClient",
 						Verbs:      []string{"get", "patch", "update"},
 					},
+					{
+						Name:         "supervisorconfigs",
+						SingularName: "supervisorconfig",
+						Namespaced:   false,
+						Kind:         "SupervisorConfig",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped"},
+					},
+					{
+						Name:       "supervisorconfigs/status",
+						Namespaced: false,
+						Kind:       "SupervisorConfig",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+				},
+				configSupervisorV1beta1GV.String(): {
+					{
+						Name:         "federationdomains",
+						SingularName: "federationdomain",
+						Namespaced:   true,
+						Kind:         "FederationDomain",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped"},
+					},
+					{
+						Name:       "federationdomains/status",
+						Namespaced: true,
+						Kind:       "FederationDomain",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+					{
+						Name:         "oidcclients",
+						SingularName: "oidcclient",
+						Namespaced:   true,
+						Kind:         "OIDCClient",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped"},
+					},
+					{
+						Name:       "oidcclients/status",
+						Namespaced: true,
+						Kind:       "OIDCClient",
+						Verbs:      []string{"get", "patch", "update"},
+					},
 				},
 			},
 		},
@@ -191,14 +282,18 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 			group: metav1.APIGroup{
 				Name: idpSupervisorGV.Group,
 				Versions: []metav1.GroupVersionForDiscovery{
+					{
+						GroupVersion: idpSupervisorV1beta1GV.String(),
+						Version:      idpSupervisorV1beta1GV.Version,
+					},
 					{
 						GroupVersion: idpSupervisorGV.String(),
 						Version:      idpSupervisorGV.Version,
 					},
 				},
 				PreferredVersion: metav1.GroupVersionForDiscovery{
-					GroupVersion: idpSupervisorGV.String(),
-					Version:      idpSupervisorGV.Version,
+					GroupVersion: idpSupervisorV1beta1GV.String(),
+					Version:      idpSupervisorV1beta1GV.Version,
 				},
 			},
 			resourceByVersion: map[string][]metav1.APIResource{
@@ -246,20 +341,68 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 						Verbs:      []string{"get", "patch", "update"},
 					},
 				},
+				idpSupervisorV1beta1GV.String(): {
+					{
+						Name:         "oidcidentityproviders",
+						SingularName: "oidcidentityprovider",
+						Namespaced:   true,
+						Kind:         "OIDCIdentityProvider",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped", "pinniped-idp", "pinniped-idps"},
+					},
+					{
+						Name:       "oidcidentityproviders/status",
+						Namespaced: true,
+						Kind:       "OIDCIdentityProvider",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+					{
+						Name:         "ldapidentityproviders",
+						SingularName: "ldapidentityprovider",
+						Namespaced:   true,
+						Kind:         "LDAPIdentityProvider",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped", "pinniped-idp", "pinniped-idps"},
+					},
+					{
+						Name:       "ldapidentityproviders/status",
+						Namespaced: true,
+						Kind:       "LDAPIdentityProvider",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+					{
+						Name:         "activedirectoryidentityproviders",
+						SingularName: "activedirectoryidentityprovider",
+						Namespaced:   true,
+						Kind:         "ActiveDirectoryIdentityProvider",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped", "pinniped-idp", "pinniped-idps"},
+					},
+					{
+						Name:       "activedirectoryidentityproviders/status",
+						Namespaced: true,
+						Kind:       "ActiveDirectoryIdentityProvider",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+				},
 			},
 		},
 		{
 			group: metav1.APIGroup{
 				Name: configConciergeGV.Group,
 				Versions: []metav1.GroupVersionForDiscovery{
+					{
+						GroupVersion: configConciergeV1beta1GV.String(),
+						Version:      configConciergeV1beta1GV.Version,
+					},
 					{
 						GroupVersion: configConciergeGV.String(),
 						Version:      configConciergeGV.Version,
 					},
 				},
 				PreferredVersion: metav1.GroupVersionForDiscovery{
-					GroupVersion: configConciergeGV.String(),
-					Version:      configConciergeGV.Version,
+					GroupVersion: configConciergeV1beta1GV.String(),
+					Version:      configConciergeV1beta1GV.Version,
 				},
 			},
 			resourceByVersion: map[string][]metav1.APIResource{
@@ -279,20 +422,40 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 						Verbs:      []string{"get", "patch", "update"},
 					},
 				},
+				configConciergeV1beta1GV.String(): {
+					{
+						Name:         "credentialissuers",
+						SingularName: "credentialissuer",
+						Namespaced:   false,
+						Kind:         "CredentialIssuer",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped"},
+					},
+					{
+						Name:       "credentialissuers/status",
+						Namespaced: false,
+						Kind:       "CredentialIssuer",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+				},
 			},
 		},
 		{
 			group: metav1.APIGroup{
 				Name: authenticationConciergeGV.Group,
 				Versions: []metav1.GroupVersionForDiscovery{
+					{
+						GroupVersion: authenticationConciergeV1beta1GV.String(),
+						Version:      authenticationConciergeV1beta1GV.Version,
+					},
 					{
 						GroupVersion: authenticationConciergeGV.String(),
 						Version:      authenticationConciergeGV.Version,
 					},
 				},
 				PreferredVersion: metav1.GroupVersionForDiscovery{
-					GroupVersion: authenticationConciergeGV.String(),
-					Version:      authenticationConciergeGV.Version,
+					GroupVersion: authenticationConciergeV1beta1GV.String(),
+					Version:      authenticationConciergeV1beta1GV.Version,
 				},
 			},
 			resourceByVersion: map[string][]metav1.APIResource{
@@ -326,6 +489,36 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 						Verbs:      []string{"get", "patch", "update"},
 					},
 				},
+				authenticationConciergeV1beta1GV.String(): {
+					{
+						Name:         "webhookauthenticators",
+						SingularName: "webhookauthenticator",
+						Namespaced:   false,
+						Kind:         "WebhookAuthenticator",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped", "pinniped-authenticator", "pinniped-authenticators"},
+					},
+					{
+						Name:       "webhookauthenticators/status",
+						Namespaced: false,
+						Kind:       "WebhookAuthenticator",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+					{
+						Name:         "jwtauthenticators",
+						SingularName: "jwtauthenticator",
+						Namespaced:   false,
+						Kind:         "JWTAuthenticator",
+						Verbs:        []string{"delete", "deletecollection", "get", "list", "patch", "create", "update", "watch"},
+						Categories:   []string{"pinniped", "pinniped-authenticator", "pinniped-authenticators"},
+					},
+					{
+						Name:       "jwtauthenticators/status",
+						Namespaced: false,
+						Kind:       "JWTAuthenticator",
+						Verbs:      []string{"get", "patch", "update"},
+					},
+				},
 			},
 		},
 	}
@@ -383,7 +576,7 @@ func TestGetAPIResourceList(t *testing.T) { //nolint:gocyclo // each t.Run is pr
 	t.Run("every API has a status subresource", func(t *testing.T) {
 		t.Parallel()
 
-		aggregatedAPIs := sets.NewString("tokencredentialrequests", "whoamirequests", "oidcclientsecretrequests")
+		aggregatedAPIs := sets.NewString("tokencredentialrequests", "whoamirequests", "oidcclientsecretrequests", "downstreamsessions", "secondfactors")
 
 		var regular, status []string
 
@@ -506,6 +699,11 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 				{Name: "DefaultStrategy", Type: "string", JSONPath: `.status.strategies[?(@.status == "Success")].type`},
 				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
 			},
+			"v1beta1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "ProxyMode", Type: "string", JSONPath: ".spec.impersonationProxy.mode"},
+				{Name: "DefaultStrategy", Type: "string", JSONPath: `.status.strategies[?(@.status == "Success")].type`},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
 		},
 		addSuffix("webhookauthenticators.authentication.concierge"): {
 			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
@@ -513,6 +711,10 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 				// Note that WebhookAuthenticators have a status type, but no controller currently sets the status, so we don't show it.
 				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
 			},
+			"v1beta1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Endpoint", Type: "string", JSONPath: ".spec.endpoint"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
 		},
 		addSuffix("jwtauthenticators.authentication.concierge"): {
 			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
@@ -521,6 +723,11 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 				// Note that JWTAuthenticators have a status type, but no controller currently sets the status, so we don't show it.
 				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
 			},
+			"v1beta1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Issuer", Type: "string", JSONPath: ".spec.issuer"},
+				{Name: "Audience", Type: "string", JSONPath: ".spec.audience"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
 		},
 		addSuffix("activedirectoryidentityproviders.idp.supervisor"): {
 			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
@@ -528,6 +735,11 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
 				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
 			},
+			"v1beta1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Host", Type: "string", JSONPath: ".spec.host"},
+				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
 		},
 		addSuffix("federationdomains.config.supervisor"): {
 			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
@@ -535,6 +747,11 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 				{Name: "Status", Type: "string", JSONPath: ".status.status"},
 				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
 			},
+			"v1beta1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Issuer", Type: "string", JSONPath: ".spec.issuer"},
+				{Name: "Status", Type: "string", JSONPath: ".status.status"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
 		},
 		addSuffix("ldapidentityproviders.idp.supervisor"): {
 			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
@@ -542,6 +759,11 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
 				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
 			},
+			"v1beta1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Host", Type: "string", JSONPath: ".spec.host"},
+				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
 		},
 		addSuffix("oidcidentityproviders.idp.supervisor"): {
 			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
@@ -549,6 +771,11 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
 				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
 			},
+			"v1beta1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Issuer", Type: "string", JSONPath: ".spec.issuer"},
+				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
 		},
 		addSuffix("oidcclients.config.supervisor"): {
 			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
@@ -557,11 +784,24 @@ func TestCRDAdditionalPrinterColumns_Parallel(t *testing.T) {
 				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
 				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
 			},
+			"v1beta1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Privileged Scopes", Type: "string", JSONPath: `.spec.allowedScopes[?(@ == "pinniped:request-audience")]`},
+				{Name: "Client Secrets", Type: "integer", JSONPath: ".status.totalClientSecrets"},
+				{Name: "Status", Type: "string", JSONPath: ".status.phase"},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
+		},
+		addSuffix("supervisorconfigs.config.supervisor"): {
+			"v1alpha1": []apiextensionsv1.CustomResourceColumnDefinition{
+				{Name: "Log Level", Type: "string", JSONPath: ".spec.log.level"},
+				{Name: "Status", Type: "string", JSONPath: `.status.conditions[?(@.type == "Ready")].status`},
+				{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			},
 		},
 	}
 
 	actualPinnipedCRDCount := 0
-	expectedPinnipedCRDCount := 8 // the current number of CRDs that we ship as part of Pinniped
+	expectedPinnipedCRDCount := 9 // the current number of CRDs that we ship as part of Pinniped
 
 	for _, crd := range crdList.Items {
 		if !strings.Contains(crd.Spec.Group, env.APIGroupSuffix) {
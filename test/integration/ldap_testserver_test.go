@@ -0,0 +1,127 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package integration
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/upstreamldap"
+	"go.pinniped.dev/test/testlib"
+)
+
+// TestLDAPClientWithTestServer_Parallel runs the Supervisor's LDAP client against an in-process LDAP server, so it
+// does not need the OpenLDAP server of the tools deployment and covers faults which cannot be provoked with it.
+func TestLDAPClientWithTestServer_Parallel(t *testing.T) {
+	_ = testlib.IntegrationEnv(t)
+
+	setup := func(t *testing.T, mode testlib.LDAPTestServerMode) (*testlib.LDAPTestServer, *upstreamldap.Provider) {
+		t.Helper()
+
+		server := testlib.NewLDAPTestServer(t, mode)
+		server.AddEntry(t, "dc=pinniped,dc=dev", map[string][]string{"objectClass": {"domain"}})
+		server.AddUser(t, "cn=admin,dc=pinniped,dc=dev", "admin-password", nil)
+		server.AddEntry(t, "ou=users,dc=pinniped,dc=dev", nil)
+		server.AddEntry(t, "ou=groups,dc=pinniped,dc=dev", nil)
+		server.AddUser(t, "cn=pinny,ou=users,dc=pinniped,dc=dev", "pinny-password", map[string][]string{
+			"objectClass": {"inetOrgPerson"},
+			"mail":        {"pinny@example.com"},
+			"uidNumber":   {"1000"},
+		})
+		server.AddGroup(t, "cn=seals,ou=groups,dc=pinniped,dc=dev", "cn=pinny,ou=users,dc=pinniped,dc=dev")
+		server.AddGroup(t, "cn=mammals,ou=groups,dc=pinniped,dc=dev", "cn=seals,ou=groups,dc=pinniped,dc=dev")
+
+		connectionProtocol := upstreamldap.TLS
+		if mode == testlib.LDAPTestServerStartTLS {
+			connectionProtocol = upstreamldap.StartTLS
+		}
+
+		return server, upstreamldap.New(upstreamldap.ProviderConfig{
+			Name:               "test-ldap-provider",
+			Host:               server.Host,
+			ConnectionProtocol: connectionProtocol,
+			CABundle:           server.CABundle,
+			BindUsername:       "cn=admin,dc=pinniped,dc=dev",
+			BindPassword:       "admin-password",
+			UserSearch: upstreamldap.UserSearchConfig{
+				Base:              "ou=users,dc=pinniped,dc=dev",
+				Filter:            "(&(objectClass=inetOrgPerson)(|(mail={})(cn={})))",
+				UsernameAttribute: "mail",
+				UIDAttribute:      "uidNumber",
+			},
+			GroupSearch: upstreamldap.GroupSearchConfig{
+				Base:               "ou=groups,dc=pinniped,dc=dev",
+				Filter:             "(member:1.2.840.113556.1.4.1941:={})",
+				GroupNameAttribute: "cn",
+			},
+		})
+	}
+
+	for _, mode := range []testlib.LDAPTestServerMode{testlib.LDAPTestServerLDAPS, testlib.LDAPTestServerStartTLS} {
+		mode := mode
+		name := "LDAPS"
+		if mode == testlib.LDAPTestServerStartTLS {
+			name = "StartTLS"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_, provider := setup(t, mode)
+
+			response, authenticated, err := provider.AuthenticateUser(ctx, "pinny", "pinny-password", []string{"groups"})
+			require.NoError(t, err)
+			require.True(t, authenticated)
+			require.Equal(t, "pinny@example.com", response.User.GetName())
+			require.Equal(t, base64.RawURLEncoding.EncodeToString([]byte("1000")), response.User.GetUID())
+			require.ElementsMatch(t, []string{"seals", "mammals"}, response.User.GetGroups())
+			require.Equal(t, "cn=pinny,ou=users,dc=pinniped,dc=dev", response.DN)
+
+			_, authenticated, err = provider.AuthenticateUser(ctx, "pinny", "wrong-password", []string{"groups"})
+			require.NoError(t, err)
+			require.False(t, authenticated)
+
+			_, authenticated, err = provider.AuthenticateUser(ctx, "not-pinny", "pinny-password", []string{"groups"})
+			require.NoError(t, err)
+			require.False(t, authenticated)
+		})
+	}
+
+	t.Run("slow binds", func(t *testing.T) {
+		t.Parallel()
+
+		server, provider := setup(t, testlib.LDAPTestServerLDAPS)
+		server.SetBindLatency(time.Second)
+
+		start := time.Now()
+		_, authenticated, err := provider.AuthenticateUser(context.Background(), "pinny", "pinny-password", []string{"groups"})
+		require.NoError(t, err)
+		require.True(t, authenticated)
+		// one bind as the bind user, and one bind as the end user
+		require.Equal(t, 2, server.Binds())
+		require.GreaterOrEqual(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("referrals", func(t *testing.T) {
+		t.Parallel()
+
+		server, provider := setup(t, testlib.LDAPTestServerStartTLS)
+		server.SetReferral("ldap://other.example.com/ou=users,dc=pinniped,dc=dev")
+
+		_, authenticated, err := provider.AuthenticateUser(context.Background(), "pinny", "pinny-password", []string{"groups"})
+		require.False(t, authenticated)
+		require.EqualError(t, err, `error searching for user: LDAP Result Code 10 "Referral": referral`)
+
+		server.SetReferral("")
+		_, authenticated, err = provider.AuthenticateUser(context.Background(), "pinny", "pinny-password", []string{"groups"})
+		require.NoError(t, err)
+		require.True(t, authenticated)
+	})
+}
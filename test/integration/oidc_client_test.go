@@ -0,0 +1,192 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package integration
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	coreosoidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"go.pinniped.dev/internal/net/phttp"
+	"go.pinniped.dev/internal/oidc/provider"
+	"go.pinniped.dev/internal/upstreamoidc"
+	"go.pinniped.dev/pkg/oidcclient/nonce"
+	"go.pinniped.dev/pkg/oidcclient/pkce"
+	"go.pinniped.dev/test/testlib"
+)
+
+// TestOIDCClientWithFakeUpstream_Parallel runs the Supervisor's upstream OIDC client against an in-process fake OIDC
+// provider, so it covers failure modes which cannot be provoked with a real identity provider.
+func TestOIDCClientWithFakeUpstream_Parallel(t *testing.T) {
+	_ = testlib.IntegrationEnv(t)
+
+	setup := func(t *testing.T) (*testlib.FakeOIDCProvider, *upstreamoidc.ProviderConfig) {
+		t.Helper()
+
+		fake := testlib.NewFakeOIDCProvider(t)
+		fake.AddUser("pinny", "password")
+
+		caPool := x509.NewCertPool()
+		require.True(t, caPool.AppendCertsFromPEM(fake.CABundle))
+		client := phttp.Default(caPool)
+		ctx := coreosoidc.ClientContext(context.Background(), client)
+		discovered, err := coreosoidc.NewProvider(ctx, fake.Issuer)
+		require.NoError(t, err)
+
+		revocationURL, err := url.Parse(fake.Issuer + testlib.FakeOIDCRevokeEndpoint)
+		require.NoError(t, err)
+
+		return fake, &upstreamoidc.ProviderConfig{
+			Name:               "fake-upstream",
+			UsernameClaim:      "email",
+			GroupsClaim:        "groups",
+			AllowPasswordGrant: true,
+			RevocationURL:      revocationURL,
+			Client:             client,
+			Provider:           discovered,
+			Config: &oauth2.Config{
+				ClientID:     fake.ClientID,
+				ClientSecret: fake.ClientSecret,
+				Endpoint:     discovered.Endpoint(),
+				RedirectURL:  "https://supervisor.example.com/callback",
+				Scopes:       []string{"openid", "email", "groups", "offline_access"},
+			},
+		}
+	}
+
+	authorize := func(t *testing.T, fake *testlib.FakeOIDCProvider, upstream *upstreamoidc.ProviderConfig, pkceCode pkce.Code, nonceParam nonce.Nonce) string {
+		t.Helper()
+
+		noRedirects := &http.Client{
+			Transport:     upstream.Client.Transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+		authorizeURL := upstream.Config.AuthCodeURL("some-state", pkceCode.Challenge(), pkceCode.Method(), nonceParam.Param())
+		require.Contains(t, authorizeURL, fake.Issuer+testlib.FakeOIDCAuthorizeEndpoint)
+
+		resp, err := noRedirects.Get(authorizeURL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+
+		location, err := resp.Location()
+		require.NoError(t, err)
+		require.Equal(t, "some-state", location.Query().Get("state"))
+		return location.Query().Get("code")
+	}
+
+	t.Run("authcode, userinfo, refresh and revocation", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		fake, upstream := setup(t)
+		fake.SetUserInfo(map[string]interface{}{"email": "fake-user@example.com", "groups": []string{"from-userinfo"}})
+
+		pkceCode, err := pkce.Generate()
+		require.NoError(t, err)
+		nonceParam, err := nonce.Generate()
+		require.NoError(t, err)
+
+		code := authorize(t, fake, upstream, pkceCode, nonceParam)
+		tokens, err := upstream.ExchangeAuthcodeAndValidateTokens(ctx, code, pkceCode, nonceParam, upstream.Config.RedirectURL)
+		require.NoError(t, err)
+		require.Equal(t, "fake-user", tokens.IDToken.Claims["sub"])
+		require.Equal(t, []interface{}{"from-userinfo"}, tokens.IDToken.Claims["groups"])
+		require.NotEmpty(t, tokens.RefreshToken.Token)
+
+		// authcodes can only be used once
+		_, err = upstream.ExchangeAuthcodeAndValidateTokens(ctx, code, pkceCode, nonceParam, upstream.Config.RedirectURL)
+		require.ErrorContains(t, err, "invalid_grant")
+
+		refreshed, err := upstream.PerformRefresh(ctx, tokens.RefreshToken.Token)
+		require.NoError(t, err)
+		require.NotEqual(t, tokens.RefreshToken.Token, refreshed.RefreshToken)
+
+		require.NoError(t, upstream.RevokeToken(ctx, refreshed.RefreshToken, provider.RefreshTokenType))
+		_, err = upstream.PerformRefresh(ctx, refreshed.RefreshToken)
+		require.ErrorContains(t, err, "invalid_grant")
+	})
+
+	t.Run("wrong PKCE verifier", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		fake, upstream := setup(t)
+
+		pkceCode, err := pkce.Generate()
+		require.NoError(t, err)
+		otherPKCECode, err := pkce.Generate()
+		require.NoError(t, err)
+		nonceParam, err := nonce.Generate()
+		require.NoError(t, err)
+
+		code := authorize(t, fake, upstream, pkceCode, nonceParam)
+		_, err = upstream.ExchangeAuthcodeAndValidateTokens(ctx, code, otherPKCECode, nonceParam, upstream.Config.RedirectURL)
+		require.ErrorContains(t, err, "invalid_grant")
+	})
+
+	t.Run("password grant", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, upstream := setup(t)
+
+		tokens, err := upstream.PasswordCredentialsGrantAndValidateTokens(ctx, "pinny", "password")
+		require.NoError(t, err)
+		require.Equal(t, "pinny", tokens.IDToken.Claims["sub"])
+
+		_, err = upstream.PasswordCredentialsGrantAndValidateTokens(ctx, "pinny", "wrong")
+		require.ErrorContains(t, err, "invalid_grant")
+	})
+
+	t.Run("failures and latency of the token endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		fake, upstream := setup(t)
+
+		// the oauth2 library retries with another client authentication style after a failure, so fail all requests
+		fake.FailRequests(testlib.FakeOIDCTokenEndpoint, http.StatusServiceUnavailable, -1)
+		_, err := upstream.PasswordCredentialsGrantAndValidateTokens(context.Background(), "pinny", "password")
+		require.ErrorContains(t, err, "server_error")
+		failedRequests := fake.Requests(testlib.FakeOIDCTokenEndpoint)
+		require.Positive(t, failedRequests)
+
+		fake.FailRequests(testlib.FakeOIDCTokenEndpoint, 0, 0)
+		_, err = upstream.PasswordCredentialsGrantAndValidateTokens(context.Background(), "pinny", "password")
+		require.NoError(t, err)
+		require.Equal(t, failedRequests+1, fake.Requests(testlib.FakeOIDCTokenEndpoint))
+
+		fake.SetLatency(testlib.FakeOIDCTokenEndpoint, time.Minute)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = upstream.PasswordCredentialsGrantAndValidateTokens(ctx, "pinny", "password")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("rotated signing key", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		fake, upstream := setup(t)
+
+		_, err := upstream.PasswordCredentialsGrantAndValidateTokens(ctx, "pinny", "password")
+		require.NoError(t, err)
+
+		// the verifier fetches the new JWKS when it sees an unknown key ID, so new tokens are still valid
+		fake.RotateSigningKey(t)
+		_, err = upstream.PasswordCredentialsGrantAndValidateTokens(ctx, "pinny", "password")
+		require.NoError(t, err)
+	})
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2022-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package integration
@@ -27,9 +27,9 @@ func TestOIDCClientStaticValidation_Parallel(t *testing.T) {
 
 	adminClient := testlib.NewKubernetesClientset(t)
 
-	needsErrFix := testutil.KubeServerMinorVersionInBetweenInclusive(t, adminClient.Discovery(), 0, 23)
-	reallyOld := testutil.KubeServerMinorVersionInBetweenInclusive(t, adminClient.Discovery(), 0, 19)
-	noSets := testutil.KubeServerMinorVersionInBetweenInclusive(t, adminClient.Discovery(), 0, 17)
+	needsErrFix := !env.KubeServerVersionAtLeast("1.24")
+	reallyOld := !env.KubeServerVersionAtLeast("1.20")
+	noSets := !env.KubeServerVersionAtLeast("1.18")
 
 	groupFix := strings.NewReplacer(".supervisor.pinniped.dev", ".supervisor."+env.APIGroupSuffix)
 	errFix := strings.NewReplacer(makeErrFix(reallyOld)...)
@@ -527,7 +527,7 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 		client         *supervisorconfigv1alpha1.OIDCClient
 		secret         *corev1.Secret
 		wantPhase      string
-		wantConditions []supervisorconfigv1alpha1.Condition
+		wantConditions []metav1.Condition
 	}{
 		{
 			name: "invalid AllowedGrantTypes and AllowedScopes (missing minimum required values), with no Secret",
@@ -542,7 +542,7 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 				},
 			},
 			wantPhase: "Error",
-			wantConditions: []supervisorconfigv1alpha1.Condition{
+			wantConditions: []metav1.Condition{
 				{
 					Type:    "AllowedGrantTypesValid",
 					Status:  "False",
@@ -561,6 +561,18 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 					Reason:  "NoClientSecretFound",
 					Message: `no client secret found (no Secret storage found)`,
 				},
+				{
+					Type:    "ClientSecretsNotExpiring",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `no client secrets will expire within 168h0m0s`,
+				},
+				{
+					Type:    "GroupsClaimValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"groupsClaim" is valid`,
+				},
 			},
 		},
 		{
@@ -577,7 +589,7 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 			},
 			secret:    testutil.OIDCClientSecretStorageSecretWithoutName(t, env.SupervisorNamespace, []string{}),
 			wantPhase: "Error",
-			wantConditions: []supervisorconfigv1alpha1.Condition{
+			wantConditions: []metav1.Condition{
 				{
 					Type:    "AllowedGrantTypesValid",
 					Status:  "True",
@@ -596,6 +608,18 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 					Reason:  "NoClientSecretFound",
 					Message: `no client secret found (empty list in storage)`,
 				},
+				{
+					Type:    "ClientSecretsNotExpiring",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `no client secrets will expire within 168h0m0s`,
+				},
+				{
+					Type:    "GroupsClaimValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"groupsClaim" is valid`,
+				},
 			},
 		},
 		{
@@ -612,7 +636,7 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 			},
 			secret:    testutil.OIDCClientSecretStorageSecretWithoutName(t, env.SupervisorNamespace, []string{testutil.HashedPassword1AtSupervisorMinCost}),
 			wantPhase: "Ready",
-			wantConditions: []supervisorconfigv1alpha1.Condition{
+			wantConditions: []metav1.Condition{
 				{
 					Type:    "AllowedGrantTypesValid",
 					Status:  "True",
@@ -631,6 +655,18 @@ func TestOIDCClientControllerValidations_Parallel(t *testing.T) {
 					Reason:  "Success",
 					Message: `1 client secret(s) found`,
 				},
+				{
+					Type:    "ClientSecretsNotExpiring",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `no client secrets will expire within 168h0m0s`,
+				},
+				{
+					Type:    "GroupsClaimValid",
+					Status:  "True",
+					Reason:  "Success",
+					Message: `"groupsClaim" is valid`,
+				},
 			},
 		},
 		// Note: there are many more possible combinations of these settings, but they are covered by the controller's
@@ -948,6 +948,8 @@ func TestCreateOIDCClientSecretRequest_Parallel(t *testing.T) {
 
 					require.Equalf(t, clientSecretRequestResponse.Status.TotalClientSecrets, ttt.wantSecretCount,
 						"expected secret count is incorrect on iteration %d", n)
+					require.Lenf(t, clientSecretRequestResponse.Status.Secrets, ttt.wantSecretCount,
+						"expected each secret to be listed on iteration %d", n)
 
 					if ttt.secretRequest.Spec.GenerateNewSecret {
 						require.Len(t, clientSecretRequestResponse.Status.GeneratedSecret, hex.EncodedLen(32),
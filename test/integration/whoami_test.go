@@ -26,7 +26,6 @@ import (
 	"k8s.io/client-go/util/keyutil"
 
 	identityv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/identity/v1alpha1"
-	"go.pinniped.dev/internal/testutil"
 	"go.pinniped.dev/test/testlib"
 )
 
@@ -284,7 +283,7 @@ func TestWhoAmI_CSR_Parallel(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	useCertificatesV1API := testutil.KubeServerSupportsCertificatesV1API(t, kubeClient.Discovery())
+	useCertificatesV1API := testlib.IntegrationEnv(t).HasKubeFeature(testlib.KubeFeatureCertificatesV1API)
 
 	t.Cleanup(func() {
 		if useCertificatesV1API {
@@ -1,4 +1,4 @@
-// Copyright 2020-2022 the Pinniped contributors. All Rights Reserved.
+// Copyright 2020-2023 the Pinniped contributors. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 // Package browsertest provides integration test helpers for our browser-based tests.
@@ -21,10 +21,51 @@ const (
 	operationPollingInterval = 100 * time.Millisecond
 )
 
-// Open a webdriver-driven browser and returns an *agouti.Page to control it. The browser will be automatically
+// Browser is a web browser which is driven by an integration test. Elements are found by CSS selectors, and when
+// a selector matches several elements, the first one is used.
+type Browser interface {
+	// Navigate opens the URL in the browser.
+	Navigate(url string) error
+
+	// Reset deletes all cookies and storage of the browser.
+	Reset() error
+
+	// URL returns the URL of the current page.
+	URL() (string, error)
+
+	// Title returns the title of the current page.
+	Title() (string, error)
+
+	// Text returns the text of an element of the current page.
+	Text(selector string) (string, error)
+
+	// Attribute returns the value of an attribute of an element of the current page.
+	Attribute(selector, attribute string) (string, error)
+
+	// Visible returns whether an element of the current page is visible.
+	Visible(selector string) (bool, error)
+
+	// Fill replaces the value of a form field of the current page.
+	Fill(selector, text string) error
+
+	// Click clicks an element of the current page.
+	Click(selector string) error
+
+	// ConsoleLogs returns the messages which were logged to the JavaScript console since the last call.
+	ConsoleLogs() ([]string, error)
+}
+
+// chromeBrowser is a Browser which drives headless Chrome via chromedriver.
+type chromeBrowser struct {
+	page *agouti.Page
+}
+
+var _ Browser = (*chromeBrowser)(nil)
+
+// Open a webdriver-driven headless Chrome and returns a Browser to control it. The browser will be automatically
 // closed at the end of the current test. It is configured for test purposes with the correct HTTP proxy and
 // in a mode that ignore certificate errors.
-func Open(t *testing.T) *agouti.Page {
+func Open(t *testing.T) Browser {
 	t.Helper()
 
 	// make it trivial to run all browser based tests via:
@@ -62,7 +103,55 @@ func Open(t *testing.T) *agouti.Page {
 	page, err := agoutiDriver.NewPage(agouti.Browser("chrome"))
 	require.NoError(t, err)
 	require.NoError(t, page.Reset())
-	return page
+	return &chromeBrowser{page: page}
+}
+
+func (b *chromeBrowser) Navigate(url string) error {
+	return b.page.Navigate(url)
+}
+
+func (b *chromeBrowser) Reset() error {
+	return b.page.Reset()
+}
+
+func (b *chromeBrowser) URL() (string, error) {
+	return b.page.URL()
+}
+
+func (b *chromeBrowser) Title() (string, error) {
+	return b.page.Title()
+}
+
+func (b *chromeBrowser) Text(selector string) (string, error) {
+	return b.page.First(selector).Text()
+}
+
+func (b *chromeBrowser) Attribute(selector, attribute string) (string, error) {
+	return b.page.First(selector).Attribute(attribute)
+}
+
+func (b *chromeBrowser) Visible(selector string) (bool, error) {
+	return b.page.First(selector).Visible()
+}
+
+func (b *chromeBrowser) Fill(selector, text string) error {
+	return b.page.First(selector).Fill(text)
+}
+
+func (b *chromeBrowser) Click(selector string) error {
+	return b.page.First(selector).Click()
+}
+
+func (b *chromeBrowser) ConsoleLogs() ([]string, error) {
+	logs, err := b.page.ReadNewLogs("browser")
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]string, 0, len(logs))
+	for _, log := range logs {
+		messages = append(messages, log.Message)
+	}
+	return messages, nil
 }
 
 func rootTestName(t *testing.T) string {
@@ -86,13 +175,13 @@ func rootTestName(t *testing.T) string {
 
 // WaitForVisibleElements expects the page to contain all the the elements specified by the selectors. It waits for this
 // to occur and times out, failing the test, if they never appear.
-func WaitForVisibleElements(t *testing.T, page *agouti.Page, selectors ...string) {
+func WaitForVisibleElements(t *testing.T, b Browser, selectors ...string) {
 	t.Helper()
 
 	testlib.RequireEventuallyf(t,
 		func(requireEventually *require.Assertions) {
 			for _, sel := range selectors {
-				vis, err := page.First(sel).Visible()
+				vis, err := b.Visible(sel)
 				requireEventually.NoError(err)
 				requireEventually.Truef(vis, "expected element %q to be visible", sel)
 			}
@@ -106,11 +195,11 @@ func WaitForVisibleElements(t *testing.T, page *agouti.Page, selectors ...string
 
 // WaitForURL expects the page to eventually navigate to a URL matching the specified pattern. It waits for this
 // to occur and times out, failing the test, if it never does.
-func WaitForURL(t *testing.T, page *agouti.Page, pat *regexp.Regexp) {
+func WaitForURL(t *testing.T, b Browser, pat *regexp.Regexp) {
 	var lastURL string
 	testlib.RequireEventuallyf(t,
 		func(requireEventually *require.Assertions) {
-			url, err := page.URL()
+			url, err := b.URL()
 			if url != lastURL {
 				t.Logf("saw URL %s", testlib.MaskTokens(url))
 				lastURL = url
@@ -127,7 +216,7 @@ func WaitForURL(t *testing.T, page *agouti.Page, pat *regexp.Regexp) {
 
 // LoginToUpstreamOIDC expects the page to be redirected to one of several known upstream IDPs.
 // It knows how to enter the test username/password and submit the upstream login form.
-func LoginToUpstreamOIDC(t *testing.T, page *agouti.Page, upstream testlib.TestOIDCUpstream) {
+func LoginToUpstreamOIDC(t *testing.T, b Browser, upstream testlib.TestOIDCUpstream) {
 	t.Helper()
 
 	type config struct {
@@ -171,21 +260,21 @@ func LoginToUpstreamOIDC(t *testing.T, page *agouti.Page, upstream testlib.TestO
 
 	// Expect to be redirected to the login page.
 	t.Logf("waiting for redirect to %s login page", cfg.Name)
-	WaitForURL(t, page, cfg.LoginPagePattern)
+	WaitForURL(t, b, cfg.LoginPagePattern)
 
 	// Wait for the login page to be rendered.
-	WaitForVisibleElements(t, page, cfg.UsernameSelector, cfg.PasswordSelector, cfg.LoginButtonSelector)
+	WaitForVisibleElements(t, b, cfg.UsernameSelector, cfg.PasswordSelector, cfg.LoginButtonSelector)
 
 	// Fill in the username and password and click "submit".
 	t.Logf("logging into %s", cfg.Name)
-	require.NoError(t, page.First(cfg.UsernameSelector).Fill(upstream.Username))
-	require.NoError(t, page.First(cfg.PasswordSelector).Fill(upstream.Password))
-	require.NoError(t, page.First(cfg.LoginButtonSelector).Click())
+	require.NoError(t, b.Fill(cfg.UsernameSelector, upstream.Username))
+	require.NoError(t, b.Fill(cfg.PasswordSelector, upstream.Password))
+	require.NoError(t, b.Click(cfg.LoginButtonSelector))
 }
 
 // LoginToUpstreamLDAP expects the page to be redirected to the Supervisor's login UI for an LDAP/AD IDP.
 // It knows how to enter the test username/password and submit the upstream login form.
-func LoginToUpstreamLDAP(t *testing.T, page *agouti.Page, issuer, username, password string) {
+func LoginToUpstreamLDAP(t *testing.T, b Browser, issuer, username, password string) {
 	t.Helper()
 
 	loginURLRegexp, err := regexp.Compile(`\A` + regexp.QuoteMeta(issuer+"/login") + `\?state=.+\z`)
@@ -193,34 +282,39 @@ func LoginToUpstreamLDAP(t *testing.T, page *agouti.Page, issuer, username, pass
 
 	// Expect to be redirected to the login page.
 	t.Logf("waiting for redirect to %s/login page", issuer)
-	WaitForURL(t, page, loginURLRegexp)
+	WaitForURL(t, b, loginURLRegexp)
 
 	// Wait for the login page to be rendered.
-	WaitForVisibleElements(t, page, "#username", "#password", "#submit")
+	WaitForVisibleElements(t, b, "#username", "#password", "#submit")
 
 	// Fill in the username and password and click "submit".
-	SubmitUpstreamLDAPLoginForm(t, page, username, password)
+	SubmitUpstreamLDAPLoginForm(t, b, username, password)
 }
 
-func SubmitUpstreamLDAPLoginForm(t *testing.T, page *agouti.Page, username string, password string) {
+func SubmitUpstreamLDAPLoginForm(t *testing.T, b Browser, username string, password string) {
 	t.Helper()
 
 	// Fill in the username and password and click "submit".
 	t.Logf("logging in via Supervisor's upstream LDAP/AD login UI page")
-	require.NoError(t, page.First("#username").Fill(username))
-	require.NoError(t, page.First("#password").Fill(password))
-	require.NoError(t, page.First("#submit").Click())
+	require.NoError(t, b.Fill("#username", username))
+	require.NoError(t, b.Fill("#password", password))
+	require.NoError(t, b.Click("#submit"))
 }
 
-func WaitForUpstreamLDAPLoginPageWithError(t *testing.T, page *agouti.Page, issuer string) {
+// WaitForUpstreamLDAPLoginPageWithError expects the page to be redirected back to the Supervisor's login UI for an
+// LDAP/AD IDP after a failed login, and expects the login UI to show the error message.
+func WaitForUpstreamLDAPLoginPageWithError(t *testing.T, b Browser, issuer string) {
 	t.Helper()
 
 	// Wait for redirect back to the login page again with an error.
 	t.Logf("waiting for redirect to back to login page with error message")
 	loginURLRegexp, err := regexp.Compile(`\A` + regexp.QuoteMeta(issuer+"/login") + `\?err=login_error&state=.+\z`)
 	require.NoError(t, err)
-	WaitForURL(t, page, loginURLRegexp)
+	WaitForURL(t, b, loginURLRegexp)
 
 	// Wait for the login page to be rendered again, this time also with an error message.
-	WaitForVisibleElements(t, page, "#username", "#password", "#submit", "#alert")
+	WaitForVisibleElements(t, b, "#username", "#password", "#submit", "#alert")
+	alert, err := b.Text("#alert")
+	require.NoError(t, err)
+	require.Equal(t, "Incorrect username or password.", alert)
 }
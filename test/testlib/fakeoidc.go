@@ -0,0 +1,518 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testlib
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"go.pinniped.dev/internal/testutil/tlsserver"
+)
+
+// The endpoints of a FakeOIDCProvider, relative to its issuer.
+const (
+	FakeOIDCDiscoveryEndpoint = "/.well-known/openid-configuration"
+	FakeOIDCJWKSEndpoint      = "/jwks.json"
+	FakeOIDCAuthorizeEndpoint = "/authorize"
+	FakeOIDCTokenEndpoint     = "/token"
+	FakeOIDCUserInfoEndpoint  = "/userinfo"
+	FakeOIDCRevokeEndpoint    = "/revoke"
+)
+
+// FakeOIDCProvider is an in-process upstream OIDC identity provider, so that the upstream flows of the Supervisor can
+// be tested without depending on Dex or an external identity provider. It approves every authorization request
+// without showing a login page, i.e. it redirects straight back to the redirect URI with an authorization code, and
+// it issues ES256 signed ID tokens with the configured claims. Its behavior can be changed while it is running.
+type FakeOIDCProvider struct {
+	// Issuer is the issuer URL of the provider, which serves the discovery document.
+	Issuer string
+	// CABundle is the PEM encoded CA bundle which verifies the TLS serving certificate of the provider.
+	CABundle []byte
+	// ClientID and ClientSecret are the credentials of the only client of the provider.
+	ClientID     string
+	ClientSecret string
+
+	mutex              sync.Mutex
+	signingKey         *ecdsa.PrivateKey
+	keyID              string
+	tokenLifetime      time.Duration
+	claims             map[string]interface{}
+	userInfo           map[string]interface{}
+	discoveryOverrides map[string]interface{}
+	passwords          map[string]string
+	latencies          map[string]time.Duration
+	failures           map[string]*fakeOIDCFailure
+	requests           map[string]int
+	authcodes          map[string]*fakeOIDCAuthcode
+	refreshTokens      map[string]string // refresh token to subject
+	accessTokens       map[string]string // access token to subject
+}
+
+type fakeOIDCFailure struct {
+	statusCode int
+	remaining  int
+}
+
+type fakeOIDCAuthcode struct {
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	scope         string
+}
+
+// NewFakeOIDCProvider starts a FakeOIDCProvider whose lifetime is bound to the test. By default, its ID tokens
+// have the subject "fake-user" and the claims email, email_verified and groups, and its userinfo endpoint returns
+// the same claims.
+func NewFakeOIDCProvider(t *testing.T) *FakeOIDCProvider {
+	t.Helper()
+
+	p := &FakeOIDCProvider{
+		ClientID:      "fake-client-id",
+		ClientSecret:  RandHex(t, 16),
+		tokenLifetime: time.Hour,
+		claims: map[string]interface{}{
+			"sub":            "fake-user",
+			"email":          "fake-user@example.com",
+			"email_verified": true,
+			"groups":         []string{"fake-group-1", "fake-group-2"},
+		},
+		passwords:     map[string]string{},
+		latencies:     map[string]time.Duration{},
+		failures:      map[string]*fakeOIDCFailure{},
+		requests:      map[string]int{},
+		authcodes:     map[string]*fakeOIDCAuthcode{},
+		refreshTokens: map[string]string{},
+		accessTokens:  map[string]string{},
+	}
+	p.userInfo = p.claims
+	p.RotateSigningKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(FakeOIDCDiscoveryEndpoint, p.handleDiscovery)
+	mux.HandleFunc(FakeOIDCJWKSEndpoint, p.handleJWKS)
+	mux.HandleFunc(FakeOIDCAuthorizeEndpoint, p.handleAuthorize)
+	mux.HandleFunc(FakeOIDCTokenEndpoint, p.handleToken)
+	mux.HandleFunc(FakeOIDCUserInfoEndpoint, p.handleUserInfo)
+	mux.HandleFunc(FakeOIDCRevokeEndpoint, p.handleRevoke)
+
+	server := tlsserver.TLSTestServer(t, p.withBehavior(mux), nil)
+	p.Issuer = server.URL
+	p.CABundle = tlsserver.TLSTestServerCA(server)
+
+	return p
+}
+
+// SetClaims replaces the claims of the ID tokens which are issued from now on. The sub claim is the subject.
+func (p *FakeOIDCProvider) SetClaims(claims map[string]interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.claims = claims
+}
+
+// SetUserInfo replaces the claims which are returned by the userinfo endpoint in addition to the subject. When nil,
+// the provider does not have a userinfo endpoint at all.
+func (p *FakeOIDCProvider) SetUserInfo(claims map[string]interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.userInfo = claims
+}
+
+// SetDiscoveryOverrides replaces fields of the discovery document, e.g. to point the token_endpoint elsewhere.
+// A nil value removes the field from the discovery document.
+func (p *FakeOIDCProvider) SetDiscoveryOverrides(overrides map[string]interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.discoveryOverrides = overrides
+}
+
+// SetTokenLifetime changes the lifetime of the ID tokens and access tokens which are issued from now on.
+func (p *FakeOIDCProvider) SetTokenLifetime(lifetime time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.tokenLifetime = lifetime
+}
+
+// AddUser allows the resource owner password credentials grant for the user. The ID tokens of the user have
+// the configured claims, except that the subject is the username.
+func (p *FakeOIDCProvider) AddUser(username, password string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.passwords[username] = password
+}
+
+// SetLatency delays every response of the endpoint, e.g. FakeOIDCTokenEndpoint, by the given duration.
+func (p *FakeOIDCProvider) SetLatency(endpoint string, latency time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.latencies[endpoint] = latency
+}
+
+// FailRequests makes the next count requests of the endpoint, e.g. FakeOIDCTokenEndpoint, fail with the given
+// HTTP status code and a server_error OAuth error response. A negative count makes all requests fail until
+// FailRequests is called again with a count of zero.
+func (p *FakeOIDCProvider) FailRequests(endpoint string, statusCode int, count int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.failures[endpoint] = &fakeOIDCFailure{statusCode: statusCode, remaining: count}
+}
+
+// Requests returns how many requests the endpoint, e.g. FakeOIDCTokenEndpoint, has received so far, including
+// requests which failed.
+func (p *FakeOIDCProvider) Requests(endpoint string) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.requests[endpoint]
+}
+
+// RotateSigningKey replaces the signing key, so that the ID tokens which were issued before can no longer be
+// verified using the JWKS of the provider.
+func (p *FakeOIDCProvider) RotateSigningKey(t *testing.T) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.signingKey = key
+	p.keyID = RandHex(t, 8)
+}
+
+// AuthorizeURL returns the URL of the authorize endpoint with the given query parameters.
+func (p *FakeOIDCProvider) AuthorizeURL(params url.Values) string {
+	return p.Issuer + FakeOIDCAuthorizeEndpoint + "?" + params.Encode()
+}
+
+func (p *FakeOIDCProvider) withBehavior(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mutex.Lock()
+		p.requests[r.URL.Path]++
+		latency := p.latencies[r.URL.Path]
+		var failWithStatus int
+		if failure := p.failures[r.URL.Path]; failure != nil && failure.remaining != 0 {
+			failWithStatus = failure.statusCode
+			if failure.remaining > 0 {
+				failure.remaining--
+			}
+		}
+		p.mutex.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if failWithStatus != 0 {
+			writeOAuthError(w, failWithStatus, "server_error", "injected failure")
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (p *FakeOIDCProvider) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	doc := map[string]interface{}{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + FakeOIDCAuthorizeEndpoint,
+		"token_endpoint":                        p.Issuer + FakeOIDCTokenEndpoint,
+		"jwks_uri":                              p.Issuer + FakeOIDCJWKSEndpoint,
+		"revocation_endpoint":                   p.Issuer + FakeOIDCRevokeEndpoint,
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"ES256"},
+		"scopes_supported":                      []string{"openid", "email", "profile", "groups", "offline_access"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token", "password"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+	}
+	if p.userInfo != nil {
+		doc["userinfo_endpoint"] = p.Issuer + FakeOIDCUserInfoEndpoint
+	}
+	for key, value := range p.discoveryOverrides {
+		if value == nil {
+			delete(doc, key)
+			continue
+		}
+		doc[key] = value
+	}
+
+	writeJSON(w, http.StatusOK, doc)
+}
+
+func (p *FakeOIDCProvider) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &p.signingKey.PublicKey,
+		KeyID:     p.keyID,
+		Algorithm: string(jose.ES256),
+		Use:       "sig",
+	}}})
+}
+
+func (p *FakeOIDCProvider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("client_id") != p.ClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" {
+		redirectWithParams(w, r, redirectURI, url.Values{"error": {"unsupported_response_type"}, "state": {query.Get("state")}})
+		return
+	}
+	if method := query.Get("code_challenge_method"); query.Get("code_challenge") != "" && method != "S256" {
+		redirectWithParams(w, r, redirectURI, url.Values{"error": {"invalid_request"}, "state": {query.Get("state")}})
+		return
+	}
+
+	code, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	p.mutex.Lock()
+	p.authcodes[code] = &fakeOIDCAuthcode{
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		scope:         query.Get("scope"),
+	}
+	p.mutex.Unlock()
+
+	redirectWithParams(w, r, redirectURI, url.Values{"code": {code}, "state": {query.Get("state")}})
+}
+
+func (p *FakeOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !p.authenticateClient(r) {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+
+	var subject, nonce, scope string
+	var ok bool
+
+	p.mutex.Lock()
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		authcode := p.authcodes[r.PostForm.Get("code")]
+		delete(p.authcodes, r.PostForm.Get("code")) // authcodes can only be used once
+		ok = authcode != nil &&
+			authcode.redirectURI == r.PostForm.Get("redirect_uri") &&
+			(authcode.codeChallenge == "" || authcode.codeChallenge == s256(r.PostForm.Get("code_verifier")))
+		if ok {
+			subject, _ = p.claims["sub"].(string)
+			nonce, scope = authcode.nonce, authcode.scope
+		}
+	case "refresh_token":
+		subject, ok = p.refreshTokens[r.PostForm.Get("refresh_token")]
+		delete(p.refreshTokens, r.PostForm.Get("refresh_token")) // refresh tokens are rotated
+		scope = r.PostForm.Get("scope")
+	case "password":
+		password, found := p.passwords[r.PostForm.Get("username")]
+		ok = found && password == r.PostForm.Get("password")
+		subject, scope = r.PostForm.Get("username"), r.PostForm.Get("scope")
+	default:
+		p.mutex.Unlock()
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+		return
+	}
+	p.mutex.Unlock()
+
+	if !ok {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "the grant is invalid, expired or revoked")
+		return
+	}
+
+	tokens, err := p.issueTokens(subject, nonce, scope)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+func (p *FakeOIDCProvider) issueTokens(subject, nonce, scope string) (map[string]interface{}, error) {
+	accessToken, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: p.signingKey},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", p.keyID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{}
+	for key, value := range p.claims {
+		claims[key] = value
+	}
+	claims["iss"] = p.Issuer
+	claims["sub"] = subject
+	claims["aud"] = p.ClientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(p.tokenLifetime).Unix()
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	idToken, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	if err != nil {
+		return nil, err
+	}
+
+	p.accessTokens[accessToken] = subject
+	p.refreshTokens[refreshToken] = subject
+
+	return map[string]interface{}{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int64(p.tokenLifetime.Seconds()),
+		"refresh_token": refreshToken,
+		"id_token":      idToken,
+		"scope":         scope,
+	}, nil
+}
+
+func (p *FakeOIDCProvider) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.userInfo == nil {
+		http.NotFound(w, r)
+		return
+	}
+	subject, ok := p.accessTokens[accessToken]
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "invalid access token", http.StatusUnauthorized)
+		return
+	}
+
+	claims := map[string]interface{}{}
+	for key, value := range p.userInfo {
+		claims[key] = value
+	}
+	claims["sub"] = subject
+
+	writeJSON(w, http.StatusOK, claims)
+}
+
+func (p *FakeOIDCProvider) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !p.authenticateClient(r) {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// like most providers, revoking an unknown token succeeds, see https://datatracker.ietf.org/doc/html/rfc7009#section-2.2
+	delete(p.refreshTokens, r.PostForm.Get("token"))
+	delete(p.accessTokens, r.PostForm.Get("token"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// authenticateClient checks the client credentials using either client_secret_basic or client_secret_post.
+func (p *FakeOIDCProvider) authenticateClient(r *http.Request) bool {
+	if err := r.ParseForm(); err != nil {
+		return false
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		// client_secret_basic form-encodes the credentials, see https://datatracker.ietf.org/doc/html/rfc6749#section-2.3.1
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	return clientID == p.ClientID && clientSecret == p.ClientSecret
+}
+
+func redirectWithParams(w http.ResponseWriter, r *http.Request, redirectURI *url.URL, params url.Values) {
+	redirectTo := *redirectURI
+	query := redirectTo.Query()
+	for key, values := range params {
+		if values[0] != "" {
+			query[key] = values
+		}
+	}
+	redirectTo.RawQuery = query.Encode()
+	http.Redirect(w, r, redirectTo.String(), http.StatusFound)
+}
+
+func writeOAuthError(w http.ResponseWriter, statusCode int, errorCode, description string) {
+	writeJSON(w, statusCode, map[string]string{"error": errorCode, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func s256(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testlib
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	conciergeconfigv1alpha1 "go.pinniped.dev/generated/latest/apis/concierge/config/v1alpha1"
+)
+
+// impersonationProxyPodPort is the port on which the Concierge pods serve the impersonation proxy.
+const impersonationProxyPodPort = 8444
+
+// ImpersonationProxyDialStrategy is how the test process reaches the impersonation proxy.
+type ImpersonationProxyDialStrategy string
+
+const (
+	// ImpersonationProxyDialDirect connects to the endpoint of the impersonation proxy, e.g. a load balancer.
+	ImpersonationProxyDialDirect ImpersonationProxyDialStrategy = "direct"
+
+	// ImpersonationProxyDialSquidProxy connects through the Squid proxy of the test environment, which can
+	// resolve and reach the cluster-internal endpoints of the impersonation proxy.
+	ImpersonationProxyDialSquidProxy ImpersonationProxyDialStrategy = "squid proxy"
+
+	// ImpersonationProxyDialPortForward connects through a port-forward to a Concierge pod, which works on any
+	// cluster, e.g. on kind or minikube without external IPs or a Squid proxy.
+	ImpersonationProxyDialPortForward ImpersonationProxyDialStrategy = "port-forward"
+)
+
+// ImpersonationProxyDialer reaches the impersonation proxy of the Concierge from the test process. Its clients
+// still use the endpoint of the impersonation proxy as their host, so that TLS verification works like it would
+// for real clients.
+type ImpersonationProxyDialer struct {
+	// Strategy is how the impersonation proxy is reached.
+	Strategy ImpersonationProxyDialStrategy
+
+	// Endpoint is the URL of the impersonation proxy.
+	Endpoint string
+
+	// CABundle is the PEM-encoded CA bundle of the impersonation proxy, or nil when it is unknown.
+	CABundle []byte
+
+	proxy       func(*http.Request) (*url.URL, error)
+	dialContext func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// DiscoverImpersonationProxyDialer waits until the CredentialIssuer of the Concierge has a successful impersonation
+// proxy strategy, and returns a dialer for the endpoint and CA bundle of its frontend.
+func DiscoverImpersonationProxyDialer(ctx context.Context, t *testing.T) *ImpersonationProxyDialer {
+	t.Helper()
+
+	env := IntegrationEnv(t)
+	client := NewConciergeClientset(t)
+	credentialIssuerName := env.ConciergeAppName + "-config"
+
+	var info *conciergeconfigv1alpha1.ImpersonationProxyInfo
+	RequireEventually(t, func(requireEventually *require.Assertions) {
+		credentialIssuer, err := client.ConfigV1alpha1().CredentialIssuers().Get(ctx, credentialIssuerName, metav1.GetOptions{})
+		requireEventually.NoError(err)
+
+		for _, strategy := range credentialIssuer.Status.Strategies {
+			if strategy.Type == conciergeconfigv1alpha1.ImpersonationProxyStrategyType &&
+				strategy.Status == conciergeconfigv1alpha1.SuccessStrategyStatus &&
+				strategy.Frontend != nil && strategy.Frontend.ImpersonationProxyInfo != nil {
+				info = strategy.Frontend.ImpersonationProxyInfo
+				return
+			}
+		}
+		requireEventually.FailNowf("no successful impersonation proxy strategy", "strategies: %s", Sdump(credentialIssuer.Status.Strategies))
+	}, 10*time.Minute, 10*time.Second)
+
+	caBundle, err := base64.StdEncoding.DecodeString(info.CertificateAuthorityData)
+	require.NoError(t, err)
+
+	dialer := NewImpersonationProxyDialer(ctx, t, info.Endpoint)
+	dialer.CABundle = caBundle
+	return dialer
+}
+
+// NewImpersonationProxyDialer returns a dialer for the impersonation proxy at the given endpoint. It connects
+// directly when the endpoint can be reached from outside the cluster, i.e. when it is not a cluster-internal
+// hostname and the cluster has load balancers. Otherwise, it connects through the Squid proxy of the test
+// environment when there is one, or through a port-forward to a Concierge pod, which is stopped when the test
+// finishes.
+func NewImpersonationProxyDialer(ctx context.Context, t *testing.T, endpoint string) *ImpersonationProxyDialer {
+	t.Helper()
+
+	env := IntegrationEnv(t)
+
+	endpointURL, err := url.Parse(endpoint)
+	require.NoError(t, err)
+
+	dialer := &ImpersonationProxyDialer{Endpoint: endpoint}
+	switch {
+	case env.HasCapability(HasExternalLoadBalancerProvider) && !isClusterInternalHostname(endpointURL.Hostname()):
+		dialer.Strategy = ImpersonationProxyDialDirect
+	case env.Proxy != "":
+		dialer.Strategy = ImpersonationProxyDialSquidProxy
+		proxyURL, err := url.Parse(env.Proxy)
+		require.NoError(t, err)
+		dialer.proxy = func(req *http.Request) (*url.URL, error) {
+			t.Logf("passing request for %s through proxy %s", RedactURLParams(req.URL), proxyURL.String())
+			return proxyURL, nil
+		}
+	default:
+		dialer.Strategy = ImpersonationProxyDialPortForward
+		localAddress := portForwardToConcierge(ctx, t, impersonationProxyPodPort)
+		var netDialer net.Dialer
+		dialer.dialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return netDialer.DialContext(ctx, network, localAddress)
+		}
+	}
+
+	return dialer
+}
+
+// RestConfig returns a copy of the config, whose host should be the endpoint of the impersonation proxy, which
+// reaches the impersonation proxy with the strategy of the dialer.
+func (d *ImpersonationProxyDialer) RestConfig(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	if d.proxy != nil {
+		config.Proxy = d.proxy
+	}
+	if d.dialContext != nil {
+		config.Dial = d.dialContext
+	}
+	return config
+}
+
+// ConfigureTransport configures the transport to reach the impersonation proxy with the strategy of the dialer.
+func (d *ImpersonationProxyDialer) ConfigureTransport(transport *http.Transport) {
+	if d.proxy != nil {
+		transport.Proxy = d.proxy
+	}
+	if d.dialContext != nil {
+		transport.DialContext = d.dialContext
+	}
+}
+
+// Proxy returns the proxy func of the dialer, or nil when it does not use a proxy.
+func (d *ImpersonationProxyDialer) Proxy() func(*http.Request) (*url.URL, error) {
+	return d.proxy
+}
+
+// DialContext returns the dial func of the dialer, or nil when it uses the default dialer.
+func (d *ImpersonationProxyDialer) DialContext() func(ctx context.Context, network, address string) (net.Conn, error) {
+	return d.dialContext
+}
+
+func isClusterInternalHostname(hostname string) bool {
+	return strings.HasSuffix(hostname, ".svc") || strings.HasSuffix(hostname, ".svc.cluster.local")
+}
+
+// portForwardToConcierge forwards a random localhost port to the port of a running Concierge pod, and returns the
+// localhost address. The port-forward is stopped when the test finishes.
+func portForwardToConcierge(ctx context.Context, t *testing.T, podPort int) string {
+	t.Helper()
+
+	env := IntegrationEnv(t)
+	config := NewClientConfig(t)
+	client := NewKubernetesClientset(t)
+
+	pods, err := client.CoreV1().Pods(env.ConciergeNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "deployment.pinniped.dev=concierge",
+		FieldSelector: "status.phase=" + string(corev1.PodRunning),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, pods.Items, "no running Concierge pods in namespace %q", env.ConciergeNamespace)
+	pod := pods.Items[0]
+
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	require.NoError(t, err)
+	portForwardURL := client.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("portforward").URL()
+	spdyDialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, portForwardURL)
+
+	stopChan, readyChan := make(chan struct{}), make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(spdyDialer, []string{"127.0.0.1"},
+		[]string{fmt.Sprintf("0:%d", podPort)}, stopChan, readyChan, io.Discard, io.Discard)
+	require.NoError(t, err)
+
+	var forwardErr error
+	forwardDone := make(chan struct{})
+	go func() {
+		defer close(forwardDone)
+		forwardErr = forwarder.ForwardPorts()
+	}()
+	t.Cleanup(func() {
+		close(stopChan)
+		<-forwardDone
+	})
+
+	select {
+	case <-readyChan:
+	case <-forwardDone:
+		require.FailNowf(t, "port-forward failed", "port-forward to pod %s/%s failed: %v", pod.Namespace, pod.Name, forwardErr)
+	case <-time.After(time.Minute):
+		require.FailNowf(t, "port-forward timed out", "port-forward to pod %s/%s did not become ready", pod.Namespace, pod.Name)
+	}
+
+	ports, err := forwarder.GetPorts()
+	require.NoError(t, err)
+	require.Len(t, ports, 1)
+
+	localAddress := fmt.Sprintf("127.0.0.1:%d", ports[0].Local)
+	t.Logf("forwarding %s to port %d of pod %s/%s", localAddress, podPort, pod.Namespace, pod.Name)
+	return localAddress
+}
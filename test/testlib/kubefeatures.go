@@ -0,0 +1,139 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testlib
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// KubeFeature is a feature of the Kubernetes API server of the test cluster, which depends on its version or on the
+// APIs which it serves. Unlike a Capability, a KubeFeature is detected from the cluster instead of being described
+// by the test environment.
+type KubeFeature string
+
+const (
+	// KubeFeatureCertificatesV1API is the certificates.k8s.io/v1 API, which is served since Kubernetes 1.19.
+	KubeFeatureCertificatesV1API KubeFeature = "certificates.k8s.io/v1 API"
+
+	// KubeFeatureUIDImpersonation is the Impersonate-Uid header, which is supported since Kubernetes 1.22.
+	KubeFeatureUIDImpersonation KubeFeature = "UID impersonation"
+
+	// KubeFeatureSelfSubjectReview is the SelfSubjectReview API of any version of authentication.k8s.io, which is
+	// served since Kubernetes 1.26 when its feature gate is enabled.
+	KubeFeatureSelfSubjectReview KubeFeature = "SelfSubjectReview API"
+
+	// KubeFeatureWebSocketExec is exec, attach and port-forward over WebSockets, which are enabled by default
+	// since Kubernetes 1.30.
+	KubeFeatureWebSocketExec KubeFeature = "WebSocket exec"
+)
+
+// kubeFeatures is the detected version and features of the test cluster. The cluster does not change while the
+// tests run, so it is only detected once.
+type kubeFeatures struct {
+	version  *version.Version
+	features map[KubeFeature]bool
+}
+
+var (
+	kubeFeaturesLock     sync.Mutex    //nolint:gochecknoglobals
+	detectedKubeFeatures *kubeFeatures //nolint:gochecknoglobals
+)
+
+func (e *TestEnv) kubeFeatures() *kubeFeatures {
+	e.t.Helper()
+
+	kubeFeaturesLock.Lock()
+	defer kubeFeaturesLock.Unlock()
+
+	if detectedKubeFeatures != nil {
+		return detectedKubeFeatures
+	}
+
+	discoveryClient := NewKubernetesClientset(e.t).Discovery()
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	require.NoError(e.t, err)
+	// some distributions add suffixes to the minor version, e.g. GKE reports "24+"
+	kubeVersion, err := version.ParseGeneric(fmt.Sprintf("%s.%s", serverVersion.Major, strings.TrimSuffix(serverVersion.Minor, "+")))
+	require.NoError(e.t, err)
+
+	groupList, err := discoveryClient.ServerGroups()
+	require.NoError(e.t, err)
+
+	hasCertificatesV1API := false
+	hasSelfSubjectReview := false
+	for _, group := range groupList.Groups {
+		for _, groupVersion := range group.Versions {
+			switch group.Name {
+			case certificatesv1.GroupName:
+				hasCertificatesV1API = hasCertificatesV1API || groupVersion.Version == "v1"
+			case authenticationv1.GroupName:
+				resources, err := discoveryClient.ServerResourcesForGroupVersion(groupVersion.GroupVersion)
+				require.NoError(e.t, err)
+				for _, resource := range resources.APIResources {
+					hasSelfSubjectReview = hasSelfSubjectReview || resource.Name == "selfsubjectreviews"
+				}
+			}
+		}
+	}
+
+	detectedKubeFeatures = &kubeFeatures{
+		version: kubeVersion,
+		features: map[KubeFeature]bool{
+			KubeFeatureCertificatesV1API: hasCertificatesV1API,
+			KubeFeatureUIDImpersonation:  kubeVersion.AtLeast(version.MustParseGeneric("1.22")),
+			KubeFeatureSelfSubjectReview: hasSelfSubjectReview,
+			KubeFeatureWebSocketExec:     kubeVersion.AtLeast(version.MustParseGeneric("1.30")),
+		},
+	}
+	e.t.Logf("detected Kubernetes %s with features %v", kubeVersion, detectedKubeFeatures.features)
+	return detectedKubeFeatures
+}
+
+// KubeServerVersion returns the major and minor version of the Kubernetes API server of the test cluster.
+func (e *TestEnv) KubeServerVersion() *version.Version {
+	e.t.Helper()
+	return e.kubeFeatures().version
+}
+
+// KubeServerVersionAtLeast returns whether the Kubernetes API server of the test cluster has at least the given
+// version, e.g. "1.24". Prefer HasKubeFeature when the test depends on a specific feature.
+func (e *TestEnv) KubeServerVersionAtLeast(minVersion string) bool {
+	e.t.Helper()
+	return e.KubeServerVersion().AtLeast(version.MustParseGeneric(minVersion))
+}
+
+// HasKubeFeature returns whether the Kubernetes API server of the test cluster supports the feature, so that tests
+// can switch their behavior based on it.
+func (e *TestEnv) HasKubeFeature(feature KubeFeature) bool {
+	e.t.Helper()
+	isSupported, featureIsKnown := e.kubeFeatures().features[feature]
+	require.Truef(e.t, featureIsKnown, "the %q Kubernetes feature is not detected", feature)
+	return isSupported
+}
+
+// WithKubeFeature skips the test unless the Kubernetes API server of the test cluster supports the feature.
+func (e *TestEnv) WithKubeFeature(feature KubeFeature) *TestEnv {
+	e.t.Helper()
+	if !e.HasKubeFeature(feature) {
+		e.t.Skipf("skipping integration test because the Kubernetes %s of the test cluster lacks the %q feature", e.KubeServerVersion(), feature)
+	}
+	return e
+}
+
+// WithoutKubeFeature skips the test if the Kubernetes API server of the test cluster supports the feature.
+func (e *TestEnv) WithoutKubeFeature(feature KubeFeature) *TestEnv {
+	e.t.Helper()
+	if e.HasKubeFeature(feature) {
+		e.t.Skipf("skipping integration test because the Kubernetes %s of the test cluster has the %q feature", e.KubeServerVersion(), feature)
+	}
+	return e
+}
@@ -0,0 +1,683 @@
+// Copyright 2023 the Pinniped contributors. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testlib
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+
+	"go.pinniped.dev/internal/certauthority"
+)
+
+// LDAPTestServerMode determines how clients establish TLS with an LDAPTestServer.
+type LDAPTestServerMode int
+
+const (
+	// LDAPTestServerLDAPS serves TLS right away, like port 636 of a real LDAP server.
+	LDAPTestServerLDAPS LDAPTestServerMode = iota
+
+	// LDAPTestServerStartTLS serves plaintext until the client sends a StartTLS request, like port 389 of a real
+	// LDAP server. Binds and searches are refused before StartTLS.
+	LDAPTestServerStartTLS
+)
+
+const (
+	ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+	// ldapMatchingRuleInChainOID is the Active Directory matching rule which matches group memberships transitively.
+	ldapMatchingRuleInChainOID = "1.2.840.113556.1.4.1941"
+
+	ldapApplicationAbandonRequest = 16
+)
+
+// LDAPTestServer is an in-memory LDAP server which implements the subset of LDAPv3 that Pinniped's LDAP client
+// uses, i.e. simple binds, StartTLS and searches. It can be seeded with users and groups, and it can inject faults
+// such as slow binds and referrals, so that tests of LDAPIdentityProviders do not need a real LDAP server.
+type LDAPTestServer struct {
+	// Host is the "host:port" of the server, to be used as the host of an LDAPIdentityProvider.
+	Host string
+
+	// CABundle is the PEM-encoded CA bundle which verifies the serving certificate of the server.
+	CABundle []byte
+
+	mode      LDAPTestServerMode
+	tlsConfig *tls.Config
+	listener  net.Listener
+
+	lock        sync.Mutex
+	entries     []*ldapTestEntry
+	passwords   map[string]string
+	bindLatency time.Duration
+	referral    string
+	binds       int
+	conns       map[net.Conn]struct{}
+}
+
+type ldapTestEntry struct {
+	dn         *ldap.DN
+	rawDN      string
+	attributes map[string][]string
+}
+
+// NewLDAPTestServer starts an LDAPTestServer on a random localhost port. It is stopped when the test finishes.
+func NewLDAPTestServer(t *testing.T, mode LDAPTestServerMode) *LDAPTestServer {
+	t.Helper()
+
+	ca, err := certauthority.New("LDAP Test Server CA", time.Hour)
+	require.NoError(t, err)
+	cert, err := ca.IssueServerCert([]string{"localhost"}, []net.IP{net.ParseIP("127.0.0.1")}, time.Hour)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &LDAPTestServer{
+		Host:     listener.Addr().String(),
+		CABundle: ca.Bundle(),
+		mode:     mode,
+		tlsConfig: &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{*cert},
+		},
+		listener:  listener,
+		passwords: map[string]string{},
+		conns:     map[net.Conn]struct{}{},
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.serve(&wg)
+	}()
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+		s.lock.Lock()
+		for conn := range s.conns {
+			_ = conn.Close()
+		}
+		s.lock.Unlock()
+		wg.Wait()
+	})
+
+	return s
+}
+
+// AddEntry adds an entry with the given attributes. The attributes of the first RDN of the DN are added
+// automatically when they are missing.
+func (s *LDAPTestServer) AddEntry(t *testing.T, dn string, attributes map[string][]string) {
+	t.Helper()
+
+	parsedDN, err := ldap.ParseDN(dn)
+	require.NoError(t, err)
+	require.NotEmpty(t, parsedDN.RDNs, "the DN of an entry must not be empty")
+
+	entry := &ldapTestEntry{dn: parsedDN, rawDN: dn, attributes: map[string][]string{}}
+	for name, values := range attributes {
+		entry.attributes[name] = append([]string(nil), values...)
+	}
+	for _, rdn := range parsedDN.RDNs[0].Attributes {
+		if !entry.hasValue(rdn.Type, rdn.Value) {
+			entry.add(rdn.Type, rdn.Value)
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, existing := range s.entries {
+		require.False(t, existing.dn.EqualFold(parsedDN), "entry %q already exists", dn)
+	}
+	s.entries = append(s.entries, entry)
+}
+
+// AddUser adds an entry which can bind with the given password.
+func (s *LDAPTestServer) AddUser(t *testing.T, dn, password string, attributes map[string][]string) {
+	t.Helper()
+
+	s.AddEntry(t, dn, attributes)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.passwords[normalizeDN(dn)] = password
+}
+
+// AddGroup adds a groupOfNames entry with the given member DNs, which may be users or other groups.
+func (s *LDAPTestServer) AddGroup(t *testing.T, dn string, memberDNs ...string) {
+	t.Helper()
+
+	s.AddEntry(t, dn, map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"member":      memberDNs,
+	})
+}
+
+// SetBindLatency delays the responses to all subsequent bind requests.
+func (s *LDAPTestServer) SetBindLatency(latency time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.bindLatency = latency
+}
+
+// SetReferral makes all subsequent searches fail with a referral to the given LDAP URL. An empty URL disables
+// referrals again.
+func (s *LDAPTestServer) SetReferral(url string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.referral = url
+}
+
+// Binds returns the number of bind requests which the server has received.
+func (s *LDAPTestServer) Binds() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.binds
+}
+
+func (s *LDAPTestServer) serve(wg *sync.WaitGroup) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return // the listener was closed
+		}
+
+		if s.mode == LDAPTestServerLDAPS {
+			conn = tls.Server(conn, s.tlsConfig)
+		}
+
+		s.lock.Lock()
+		s.conns[conn] = struct{}{}
+		s.lock.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+// ldapTestConn is the state of one client connection.
+type ldapTestConn struct {
+	conn     net.Conn
+	isTLS    bool
+	isBound  bool
+	writeErr error
+}
+
+func (s *LDAPTestServer) handleConn(conn net.Conn) {
+	c := &ldapTestConn{conn: conn, isTLS: s.mode == LDAPTestServerLDAPS}
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, c.conn)
+		s.lock.Unlock()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		packet, err := ber.ReadPacket(c.conn)
+		if err != nil {
+			return // the client closed the connection or sent garbage
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+
+		messageID, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+		request := packet.Children[1]
+		if request.ClassType != ber.ClassApplication {
+			return
+		}
+
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			s.handleBind(c, messageID, request)
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationSearchRequest:
+			s.handleSearch(c, messageID, request)
+		case ldap.ApplicationExtendedRequest:
+			if !s.handleExtended(c, messageID, request) {
+				return
+			}
+		case ldapApplicationAbandonRequest:
+			// all operations finish before the next request is read, so there is nothing to abandon
+		default:
+			c.writeResult(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "unsupported operation")
+		}
+
+		if c.writeErr != nil {
+			return
+		}
+	}
+}
+
+func (s *LDAPTestServer) handleBind(c *ldapTestConn, messageID int64, request *ber.Packet) {
+	s.lock.Lock()
+	s.binds++
+	latency := s.bindLatency
+	s.lock.Unlock()
+
+	time.Sleep(latency)
+
+	c.isBound = false
+
+	if !c.isTLS {
+		c.writeResult(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultConfidentialityRequired, "StartTLS is required")
+		return
+	}
+
+	if len(request.Children) < 3 || request.Children[2].ClassType != ber.ClassContext || request.Children[2].Tag != 0 {
+		c.writeResult(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultAuthMethodNotSupported, "only simple binds are supported")
+		return
+	}
+	dn, _ := request.Children[1].Value.(string)
+	password := request.Children[2].Data.String()
+
+	s.lock.Lock()
+	expectedPassword, ok := s.passwords[normalizeDN(dn)]
+	s.lock.Unlock()
+
+	if !ok || len(password) == 0 || password != expectedPassword {
+		c.writeResult(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "invalid credentials")
+		return
+	}
+
+	c.isBound = true
+	c.writeResult(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+}
+
+// handleExtended handles StartTLS requests, and returns false when the connection should be closed.
+func (s *LDAPTestServer) handleExtended(c *ldapTestConn, messageID int64, request *ber.Packet) bool {
+	if len(request.Children) < 1 || request.Children[0].Data.String() != ldapStartTLSOID {
+		c.writeResult(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "unsupported extended operation")
+		return true
+	}
+
+	if c.isTLS {
+		c.writeResult(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultOperationsError, "TLS is already established")
+		return true
+	}
+
+	c.writeResult(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess, "")
+	if c.writeErr != nil {
+		return false
+	}
+
+	tlsConn := tls.Server(c.conn, s.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return false
+	}
+
+	s.lock.Lock()
+	delete(s.conns, c.conn)
+	s.conns[tlsConn] = struct{}{}
+	s.lock.Unlock()
+
+	c.conn = tlsConn
+	c.isTLS = true
+	return true
+}
+
+func (s *LDAPTestServer) handleSearch(c *ldapTestConn, messageID int64, request *ber.Packet) {
+	if !c.isTLS {
+		c.writeResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultConfidentialityRequired, "StartTLS is required")
+		return
+	}
+	if !c.isBound {
+		c.writeResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights, "bind is required")
+		return
+	}
+	if len(request.Children) < 8 {
+		c.writeResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, "malformed search request")
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.referral != "" {
+		c.writeReferral(messageID, s.referral)
+		return
+	}
+
+	baseDN, _ := request.Children[0].Value.(string)
+	scope, _ := request.Children[1].Value.(int64)
+	sizeLimit, _ := request.Children[3].Value.(int64)
+	filter := request.Children[6]
+	var attributes []string
+	for _, attribute := range request.Children[7].Children {
+		name, _ := attribute.Value.(string)
+		attributes = append(attributes, name)
+	}
+
+	base, err := ldap.ParseDN(baseDN)
+	if err != nil {
+		c.writeResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultInvalidDNSyntax, err.Error())
+		return
+	}
+	if len(base.RDNs) != 0 && s.find(base) == nil {
+		c.writeResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultNoSuchObject, "base entry not found")
+		return
+	}
+
+	found := 0
+	for _, entry := range s.entries {
+		if !entry.inScope(base, scope) {
+			continue
+		}
+		matches, err := s.matches(entry, filter)
+		if err != nil {
+			c.writeResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, err.Error())
+			return
+		}
+		if !matches {
+			continue
+		}
+		if sizeLimit > 0 && int64(found) == sizeLimit {
+			c.writeResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded, "size limit exceeded")
+			return
+		}
+		found++
+		c.writeEntry(messageID, entry, attributes)
+	}
+
+	c.writeResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, "")
+}
+
+// find returns the entry with the given DN, or nil. The caller must hold the lock.
+func (s *LDAPTestServer) find(dn *ldap.DN) *ldapTestEntry {
+	for _, entry := range s.entries {
+		if entry.dn.EqualFold(dn) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// matches evaluates a BER encoded search filter. The caller must hold the lock.
+func (s *LDAPTestServer) matches(entry *ldapTestEntry, filter *ber.Packet) (bool, error) {
+	if filter.ClassType != ber.ClassContext {
+		return false, errors.New("malformed filter")
+	}
+
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			matches, err := s.matches(entry, child)
+			if err != nil || !matches {
+				return false, err
+			}
+		}
+		return true, nil
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			matches, err := s.matches(entry, child)
+			if err != nil || matches {
+				return matches, err
+			}
+		}
+		return false, nil
+	case ldap.FilterNot:
+		if len(filter.Children) != 1 {
+			return false, errors.New("malformed not filter")
+		}
+		matches, err := s.matches(entry, filter.Children[0])
+		return !matches, err
+	case ldap.FilterPresent:
+		return len(entry.get(filter.Data.String())) != 0, nil
+	case ldap.FilterEqualityMatch, ldap.FilterApproxMatch:
+		name, value, err := attributeValueAssertion(filter)
+		if err != nil {
+			return false, err
+		}
+		return entry.hasValue(name, value), nil
+	case ldap.FilterGreaterOrEqual, ldap.FilterLessOrEqual:
+		name, value, err := attributeValueAssertion(filter)
+		if err != nil {
+			return false, err
+		}
+		for _, v := range entry.get(name) {
+			c := strings.Compare(strings.ToLower(v), strings.ToLower(value))
+			if (filter.Tag == ldap.FilterGreaterOrEqual && c >= 0) || (filter.Tag == ldap.FilterLessOrEqual && c <= 0) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ldap.FilterSubstrings:
+		return substringsMatch(entry, filter)
+	case ldap.FilterExtensibleMatch:
+		return s.extensibleMatch(entry, filter)
+	default:
+		return false, fmt.Errorf("unsupported filter type %d", filter.Tag)
+	}
+}
+
+func (s *LDAPTestServer) extensibleMatch(entry *ldapTestEntry, filter *ber.Packet) (bool, error) {
+	var matchingRule, name, value string
+	for _, child := range filter.Children {
+		switch child.Tag {
+		case 1:
+			matchingRule = child.Data.String()
+		case 2:
+			name = child.Data.String()
+		case 3:
+			value = child.Data.String()
+		}
+	}
+
+	switch matchingRule {
+	case "":
+		return entry.hasValue(name, value), nil
+	case ldapMatchingRuleInChainOID:
+		target, err := ldap.ParseDN(value)
+		if err != nil {
+			return false, err
+		}
+		return s.inChain(entry, name, target, map[string]bool{}), nil
+	default:
+		return false, fmt.Errorf("unsupported matching rule %q", matchingRule)
+	}
+}
+
+// inChain returns whether the DN valued attribute of the entry refers to the target DN, either directly or through
+// the same attribute of the entries which it refers to. The caller must hold the lock.
+func (s *LDAPTestServer) inChain(entry *ldapTestEntry, name string, target *ldap.DN, visited map[string]bool) bool {
+	visited[normalizeDN(entry.rawDN)] = true
+	for _, value := range entry.get(name) {
+		dn, err := ldap.ParseDN(value)
+		if err != nil {
+			continue
+		}
+		if dn.EqualFold(target) {
+			return true
+		}
+		next := s.find(dn)
+		if next != nil && !visited[normalizeDN(next.rawDN)] && s.inChain(next, name, target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func attributeValueAssertion(filter *ber.Packet) (string, string, error) {
+	if len(filter.Children) != 2 {
+		return "", "", errors.New("malformed attribute value assertion")
+	}
+	name, _ := filter.Children[0].Value.(string)
+	value, _ := filter.Children[1].Value.(string)
+	return name, value, nil
+}
+
+func substringsMatch(entry *ldapTestEntry, filter *ber.Packet) (bool, error) {
+	if len(filter.Children) != 2 {
+		return false, errors.New("malformed substrings filter")
+	}
+	name, _ := filter.Children[0].Value.(string)
+
+	for _, value := range entry.get(name) {
+		rest := strings.ToLower(value)
+		matches := true
+		for _, substring := range filter.Children[1].Children {
+			part := strings.ToLower(substring.Data.String())
+			switch substring.Tag {
+			case ldap.FilterSubstringsInitial:
+				matches = strings.HasPrefix(rest, part)
+				rest = strings.TrimPrefix(rest, part)
+			case ldap.FilterSubstringsAny:
+				i := strings.Index(rest, part)
+				matches = i >= 0
+				if matches {
+					rest = rest[i+len(part):]
+				}
+			case ldap.FilterSubstringsFinal:
+				matches = strings.HasSuffix(rest, part)
+			}
+			if !matches {
+				break
+			}
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (e *ldapTestEntry) inScope(base *ldap.DN, scope int64) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return base.EqualFold(e.dn)
+	case ldap.ScopeSingleLevel:
+		return len(e.dn.RDNs) == len(base.RDNs)+1 && base.AncestorOfFold(e.dn)
+	default:
+		return base.EqualFold(e.dn) || base.AncestorOfFold(e.dn)
+	}
+}
+
+func (e *ldapTestEntry) get(name string) []string {
+	for attributeName, values := range e.attributes {
+		if strings.EqualFold(attributeName, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+func (e *ldapTestEntry) hasValue(name, value string) bool {
+	for _, v := range e.get(name) {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ldapTestEntry) add(name, value string) {
+	for attributeName := range e.attributes {
+		if strings.EqualFold(attributeName, name) {
+			e.attributes[attributeName] = append(e.attributes[attributeName], value)
+			return
+		}
+	}
+	e.attributes[name] = []string{value}
+}
+
+func (c *ldapTestConn) writeResult(messageID int64, tag ber.Tag, resultCode uint16, message string) {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	appendLDAPResult(response, resultCode, message)
+	c.write(messageID, response)
+}
+
+func (c *ldapTestConn) writeReferral(messageID int64, url string) {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultDone, nil, "Search Result Done")
+	appendLDAPResult(response, ldap.LDAPResultReferral, "referral")
+	referral := ber.Encode(ber.ClassContext, ber.TypeConstructed, 3, nil, "Referral")
+	referral.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, url, "URI"))
+	response.AppendChild(referral)
+	c.write(messageID, response)
+}
+
+// writeEntry writes a search result entry with the requested attributes, named like they were requested, since
+// the client looks up attributes by their exact names.
+func (c *ldapTestConn) writeEntry(messageID int64, entry *ldapTestEntry, requested []string) {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.rawDN, "DN"))
+
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	appendAttribute := func(name string, values []string) {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+
+	switch {
+	case len(requested) == 0 || (len(requested) == 1 && requested[0] == "*"):
+		for name, values := range entry.attributes {
+			appendAttribute(name, values)
+		}
+	case len(requested) == 1 && requested[0] == "1.1":
+		// no attributes were requested
+	default:
+		for _, name := range requested {
+			if values := entry.get(name); len(values) != 0 {
+				appendAttribute(name, values)
+			}
+		}
+	}
+
+	response.AppendChild(attributes)
+	c.write(messageID, response)
+}
+
+func (c *ldapTestConn) write(messageID int64, response *ber.Packet) {
+	if c.writeErr != nil {
+		return
+	}
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	packet.AppendChild(response)
+	_, c.writeErr = c.conn.Write(packet.Bytes())
+}
+
+func appendLDAPResult(response *ber.Packet, resultCode uint16, message string) {
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), "Result Code"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+}
+
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+	rdns := make([]string, 0, len(parsed.RDNs))
+	for _, rdn := range parsed.RDNs {
+		attributes := make([]string, 0, len(rdn.Attributes))
+		for _, attribute := range rdn.Attributes {
+			attributes = append(attributes, strings.ToLower(attribute.Type)+"="+strings.ToLower(attribute.Value))
+		}
+		rdns = append(rdns, strings.Join(attributes, "+"))
+	}
+	return strings.Join(rdns, ",")
+}